@@ -1,13 +1,30 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
-	"github.com/Jason-Omondi/ecomgo/cmd/service/user"
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
 	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/database"
+	"github.com/Jason-Omondi/ecomgo/internal/feature"
+	"github.com/Jason-Omondi/ecomgo/internal/geoip"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/i18n"
+	"github.com/Jason-Omondi/ecomgo/internal/limits"
+	"github.com/Jason-Omondi/ecomgo/internal/links"
 	"github.com/Jason-Omondi/ecomgo/internal/migrations"
-	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/quota"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -19,12 +36,37 @@ type APIServer struct {
 	router *mux.Router
 	log    *zap.Logger
 	config *config.Config
+	cache  cache.Cache
+	// limiter caps concurrent /api/v1 requests (HTTP_MAX_IN_FLIGHT), see internal/limits
+	limiter *limits.Limiter
+	// tokens verifies bearer tokens for the tenant of each request, see auth.Tenant
+	tokens *auth.TokenManager
+	// quotas caps each tenant's requests and orders per its plan (TENANT_QUOTAS_ENABLED), see internal/quota
+	quotas *quota.Quotas
+	// geo stores the client's country on each request (GEOIP_PROVIDER), see internal/geoip
+	geo *geoip.Resolver
+	// links resolves response _links against the /api/v1 subrouter, see internal/links
+	links *links.Builder
+	// modules are the pluggable features served by this instance (users, products, orders...)
+	modules []module.Module
+	// ready flips to true once startup warm-up is done, see /readyz
+	ready atomic.Bool
 }
 
-func NewAPIServer(port string, db *gorm.DB, cfg *config.Config, log *zap.Logger) *APIServer {
+func NewAPIServer(port string, db *gorm.DB, cfg *config.Config, log *zap.Logger,
+	appCache cache.Cache, tokens *auth.TokenManager, limiter *limits.Limiter, quotas *quota.Quotas, geo *geoip.Resolver, resourceLinks *links.Builder, modules []module.Module) *APIServer {
 	// create a single router instance and register health on it
 	router := mux.NewRouter()
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		// Cache backs rate limiting and sessions - report unhealthy if it's unreachable
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+		if err := appCache.Ping(ctx); err != nil {
+			log.Warn("Health check failed: cache unreachable", zap.Error(err))
+			http.Error(w, "cache unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
@@ -85,13 +127,22 @@ func NewAPIServer(port string, db *gorm.DB, cfg *config.Config, log *zap.Logger)
 		w.Write([]byte(html))
 	})
 
-	return &APIServer{
-		port:   port,
-		db:     db,
-		router: router,
-		log:    log,
-		config: cfg,
+	server := &APIServer{
+		port:    port,
+		db:      db,
+		router:  router,
+		log:     log,
+		config:  cfg,
+		cache:   appCache,
+		tokens:  tokens,
+		limiter: limiter,
+		quotas:  quotas,
+		geo:     geo,
+		links:   resourceLinks,
+		modules: modules,
 	}
+	router.HandleFunc("/readyz", server.handleReady)
+	return server
 }
 
 func (s *APIServer) Run() {
@@ -99,12 +150,13 @@ func (s *APIServer) Run() {
 
 	// Run database migrations before starting server
 	// Ensures schema is up-to-date before accepting requests
-	if err := migrations.MigrateDB(s.db, s.log); err != nil {
-		s.log.Fatal("Failed to run migrations", zap.Error(err))
+	// Each module contributes its own migrations, applied in registration order
+	var migrationList []migrations.Migration
+	for _, m := range s.modules {
+		migrationList = append(migrationList, m.Migrations()...)
 	}
-
-	if err := s.Start(); err != nil {
-		s.log.Fatal("Failed to start server", zap.Error(err))
+	if err := migrations.MigrateDB(s.db, s.log, migrationList, s.config.Database.MigrationLint); err != nil {
+		s.log.Fatal("Failed to run migrations", zap.Error(err))
 	}
 
 	// Get database version
@@ -113,27 +165,105 @@ func (s *APIServer) Run() {
 	if err := sqlDB.QueryRow("SELECT VERSION()").Scan(&version); err == nil {
 		s.log.Info("Connected to database", zap.String("version", version))
 	}
+
+	// Start blocks until SIGINT/SIGTERM and a graceful shutdown, or the listener fails
+	if err := s.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.log.Fatal("Server failed", zap.Error(err))
+	}
+	s.log.Info("Server stopped")
 }
 
 func (s *APIServer) Start() error {
-	// Initialize repositories - data access layer
-	// Repository pattern abstracts database logic, making it testable and maintainable
-	userRepo := repository.NewUserRepository(s.db, s.log)
-
-	// Initialize services - business logic layer
-	// Services contain core business logic and orchestrate between repositories and handlers
-	// Pass config to service if needed (e.g., for Keycloak integration)
-	userService := user.NewUserService(userRepo, s.log, s.config)
-
 	// initialize subrouter for versioned API routes (/api/v1/...)
 	subrouter := s.router.PathPrefix("/api/v1").Subrouter()
+	// Request ID and tenant go on the context first, so everything below can read them, see internal/httpctx
+	// The tenant is the one the caller's token was issued for, never a header the client picks
+	subrouter.Use(httpctx.RequestID, auth.Tenant(s.tokens))
+	// Route groups whose feature flag is off for the tenant answer 404 (FEATURE_ROUTES), see internal/feature
+	flags := feature.New(s.config.Features, "/api/v1")
+	if disabled := flags.Disabled(); len(disabled) > 0 {
+		s.log.Info("Feature flags off", zap.Strings("flags", disabled))
+	}
+	subrouter.Use(flags.Middleware())
+	// The client's address and country, for currency defaults, sales restrictions, audits and fraud checks
+	subrouter.Use(s.geo.Middleware)
+	// Accept-Language picks the locale; plain-text error messages are translated, see internal/i18n
+	subrouter.Use(i18n.Localize)
+	// Tenants over their plan's daily requests or monthly orders get 429, before taking a capacity slot
+	subrouter.Use(s.quotas.Middleware())
+	// Over capacity, requests queue briefly and then get 503 - health and readiness checks are exempt
+	subrouter.Use(limits.Middleware(s.limiter, s.config.Capacity.HTTPQueueTimeout))
+	// Each request gets a deadline (REQUEST_TIMEOUT, ROUTE_TIMEOUTS) that bounds its queries and outbound calls
+	subrouter.Use(limits.Deadlines(s.config.Timeouts.Default, s.config.Timeouts.Routes, "/api/v1"))
+	// While the database is unreachable, failed requests answer 503 naming it instead of 500
+	subrouter.Use(database.Circuit.Middleware)
+	// GET ...?fields=id,name,user(email) trims JSON responses to the requested fields
+	subrouter.Use(response.SelectFields)
 
-	// Initialize user handler and register routes
-	// Handlers receive HTTP requests and delegate to services
-	userHandler := user.NewHandler(userService, s.log)
-	userHandler.RegisterRoutes(subrouter)
+	// Each module registers its own handlers - adding a feature never touches Start
+	for _, m := range s.modules {
+		m.RegisterRoutes(subrouter)
+	}
+	s.links.Bind(subrouter)
+
+	// Start module background services; they stop after the HTTP server has drained
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var services sync.WaitGroup
+	s.startServices(ctx, &services)
+	go s.warmUp(ctx)
 
+	server := &http.Server{Addr: s.port, Handler: s.router}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
 	s.log.Info("Listening on port", zap.String("port", s.port))
 
-	return http.ListenAndServe(s.port, s.router)
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case err := <-serveErr:
+		return err
+	case <-signals.Done():
+	}
+
+	// Shutdown order: stop taking traffic, finish in-flight requests, then stop services
+	// so they can flush work those requests produced (e.g. buffered audit writes)
+	s.log.Info("Shutting down", zap.Duration("timeout", s.config.Server.ShutdownTimeout))
+	s.ready.Store(false)
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), s.config.Server.ShutdownTimeout)
+	defer cancelShutdown()
+	err := server.Shutdown(shutdownCtx)
+
+	cancel()
+	stopped := make(chan struct{})
+	go func() {
+		services.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		s.log.Info("Background services stopped")
+	case <-shutdownCtx.Done():
+		s.log.Warn("Background services did not stop before the shutdown timeout")
+	}
+	return err
+}
+
+// startServices runs every module's background services in their own goroutine
+// A failing service is logged but does not take the HTTP server down
+func (s *APIServer) startServices(ctx context.Context, wg *sync.WaitGroup) {
+	for _, m := range s.modules {
+		for _, svc := range m.Services() {
+			wg.Add(1)
+			go func(svc module.Service) {
+				defer wg.Done()
+				s.log.Info("Starting background service", zap.String("service", svc.Name()))
+				if err := svc.Run(ctx); err != nil && ctx.Err() == nil {
+					s.log.Error("Background service stopped", zap.String("service", svc.Name()), zap.Error(err))
+				}
+			}(svc)
+		}
+	}
 }
@@ -1,14 +1,97 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/Jason-Omondi/ecomgo/cmd/api"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/analytics"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/batch"
+	campaignadmin "github.com/Jason-Omondi/ecomgo/cmd/service/campaign"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/capacity"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/catalog"
+	channeladmin "github.com/Jason-Omondi/ecomgo/cmd/service/channel"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/currency"
+	groupadmin "github.com/Jason-Omondi/ecomgo/cmd/service/customergroup"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/dashboard"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/dispute"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/emailtemplate"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/files"
+	fraudreview "github.com/Jason-Omondi/ecomgo/cmd/service/fraud"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/identity"
+	inventoryadmin "github.com/Jason-Omondi/ecomgo/cmd/service/inventory"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/job"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/notification"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/oidc"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/order"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/page"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/question"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/quote"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/referral"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/segment"
+	settingsadmin "github.com/Jason-Omondi/ecomgo/cmd/service/settings"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/shipping"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/supplier"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/ticket"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/usage"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/user"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/vendor"
+	"github.com/Jason-Omondi/ecomgo/cmd/service/webhook"
 	_ "github.com/Jason-Omondi/ecomgo/docs"
+	"github.com/Jason-Omondi/ecomgo/internal/address"
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/bi"
+	"github.com/Jason-Omondi/ecomgo/internal/bulk"
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/campaign"
+	"github.com/Jason-Omondi/ecomgo/internal/captcha"
+	"github.com/Jason-Omondi/ecomgo/internal/channel"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
 	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/customergroup"
 	"github.com/Jason-Omondi/ecomgo/internal/database"
+	"github.com/Jason-Omondi/ecomgo/internal/devmode"
+	"github.com/Jason-Omondi/ecomgo/internal/disbursement"
+	"github.com/Jason-Omondi/ecomgo/internal/email"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/fiscal"
+	"github.com/Jason-Omondi/ecomgo/internal/fraud"
+	"github.com/Jason-Omondi/ecomgo/internal/fx"
+	"github.com/Jason-Omondi/ecomgo/internal/geoip"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+	"github.com/Jason-Omondi/ecomgo/internal/inventory"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/keycloak"
+	"github.com/Jason-Omondi/ecomgo/internal/limits"
+	"github.com/Jason-Omondi/ecomgo/internal/links"
+	"github.com/Jason-Omondi/ecomgo/internal/loadgen"
+	"github.com/Jason-Omondi/ecomgo/internal/lock"
 	"github.com/Jason-Omondi/ecomgo/internal/logger"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/notify"
+	"github.com/Jason-Omondi/ecomgo/internal/ordernumber"
+	"github.com/Jason-Omondi/ecomgo/internal/payment"
+	"github.com/Jason-Omondi/ecomgo/internal/phone"
+	"github.com/Jason-Omondi/ecomgo/internal/quota"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/sdkgen"
+	"github.com/Jason-Omondi/ecomgo/internal/search"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	shippingcarriers "github.com/Jason-Omondi/ecomgo/internal/shipping"
+	"github.com/Jason-Omondi/ecomgo/internal/smoke"
+	"github.com/Jason-Omondi/ecomgo/internal/sms"
+	"github.com/Jason-Omondi/ecomgo/internal/storage"
+	"github.com/Jason-Omondi/ecomgo/internal/taxid"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // @title EcomGo API
@@ -32,9 +115,24 @@ import (
 // @description Type "Bearer" followed by a space and JWT token.
 
 func main() {
+	// Every timestamp the service produces, stores or returns is UTC
+	clock.UseUTC()
+
 	// Initialize logger
 	appLogger, _ := logger.NewLogger()
 
+	// `main smoke` checks a deployed environment over HTTP only - no config or database needed
+	if len(os.Args) > 1 && os.Args[1] == "smoke" {
+		runSmoke(os.Args[2:], appLogger)
+		return
+	}
+
+	// `main sdk` regenerates the committed client SDKs from docs/swagger.json
+	if len(os.Args) > 1 && os.Args[1] == "sdk" {
+		runSDK(os.Args[2:], appLogger)
+		return
+	}
+
 	// Load configuration ONCE at application startup
 	// This is the single source of truth for all config throughout the app
 	cfg, err := config.LoadConfig()
@@ -42,21 +140,47 @@ func main() {
 		log.Fatal("Failed to load config:", err)
 	}
 
+	// `main serve --dev` runs the whole stack locally: in-memory SQLite, demo data, mock providers
+	if len(os.Args) > 2 && os.Args[1] == "serve" && os.Args[2] == "--dev" {
+		cfg.EnableDevMode()
+		appLogger.Warn("Development mode: in-memory database, mock providers and relaxed auth - never use in production")
+	}
+
 	// Validate database configuration
 	if cfg.Database.Type == "" {
 		log.Fatal("DB_TYPE environment variable not set")
 	}
-	if cfg.Database.User == "" {
-		log.Fatal("DB_USER environment variable not set")
+	if cfg.Database.Type != "sqlite" {
+		if cfg.Database.User == "" {
+			log.Fatal("DB_USER environment variable not set")
+		}
+		if cfg.Database.Name == "" {
+			log.Fatal("DB_NAME environment variable not set")
+		}
+		if cfg.Database.Host == "" {
+			log.Fatal("DB_HOST environment variable not set")
+		}
+		if cfg.Database.Password == "" {
+			log.Fatal("DB_PASSWORD environment variable not set - this is required")
+		}
+	}
+	if cfg.Auth.JWTSecret == "" {
+		log.Fatal("JWT_SECRET environment variable not set - this is required")
+	}
+	if !phone.KnownRegion(cfg.Accounts.PhoneRegion) {
+		log.Fatalf("PHONE_DEFAULT_REGION %q is not supported", cfg.Accounts.PhoneRegion)
+	}
+	if cfg.Orders.EventSourcing && cfg.Orders.SnapshotEvery < 1 {
+		log.Fatalf("ORDER_SNAPSHOT_EVERY must be at least 1, got %d", cfg.Orders.SnapshotEvery)
 	}
-	if cfg.Database.Name == "" {
-		log.Fatal("DB_NAME environment variable not set")
+	if cfg.Checkout.MaxAttempts < 1 || cfg.Checkout.RetryBackoff <= 0 || cfg.Checkout.RecoveryInterval <= 0 {
+		log.Fatalf("CHECKOUT_MAX_ATTEMPTS must be at least 1 and CHECKOUT_RETRY_BACKOFF and CHECKOUT_RECOVERY_INTERVAL positive")
 	}
-	if cfg.Database.Host == "" {
-		log.Fatal("DB_HOST environment variable not set")
+	if _, err := migrations.ParseLintMode(cfg.Database.MigrationLint); err != nil {
+		log.Fatalf("DB_MIGRATION_LINT %q is not supported: %v", cfg.Database.MigrationLint, err)
 	}
-	if cfg.Database.Password == "" {
-		log.Fatal("DB_PASSWORD environment variable not set - this is required")
+	if _, err := bi.ParseFormat(cfg.BIExport.Format); err != nil {
+		log.Fatalf("BI_EXPORT_FORMAT %q is not supported: %v", cfg.BIExport.Format, err)
 	}
 
 	appLogger.Info("Configuration loaded successfully",
@@ -79,10 +203,494 @@ func main() {
 			zap.String("db_user", cfg.Database.User),
 		)
 	}
+	// Unique-constraint violations surface as repository.ErrAlreadyExists in every repository
+	if err := repository.RegisterErrorTranslation(db); err != nil {
+		appLogger.Fatal("Failed to register database error translation", zap.Error(err))
+	}
 
 	appLogger.Info("Database connected successfully")
 
+	// Initialize cache - Redis when REDIS_ENABLED=true, in-memory otherwise
+	appCache, err := cache.New(cfg, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize cache", zap.Error(err))
+	}
+	defer appCache.Close()
+
+	// Initialize distributed locks - backend selected by LOCK_BACKEND
+	locker, err := lock.New(cfg, appCache, db, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize distributed locks", zap.Error(err))
+	}
+
+	// Concurrency limits, adjustable at runtime via /admin/capacity
+	// Outbound clients share one pool, so configure it before any provider creates a client
+	httpclient.Configure(cfg.Capacity)
+	httpLimiter := limits.NewLimiter("http", cfg.Capacity.HTTPMaxInFlight, cfg.Capacity.HTTPMaxQueued)
+	// Per-tenant plan quotas (TENANT_QUOTAS_ENABLED), counted in the shared cache
+	quotas := quota.New(cfg.Quotas, appCache, clock.System, "/api/v1", appLogger)
+
+	// Initialize event bus - backend selected by EVENTS_BACKEND
+	eventBus, err := events.New(cfg, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize event bus", zap.Error(err))
+	}
+	defer eventBus.Close()
+
+	// Initialize background jobs - queue backend selected by JOBS_BACKEND
+	jobQueue, err := jobs.NewQueue(cfg, db, appCache, clock.System, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize job queue", zap.Error(err))
+	}
+	processor := jobs.NewProcessor(jobQueue, cfg.Jobs, clock.System, clock.UUIDs, appLogger)
+	// Admin bulk actions run as jobs; modules register theirs when constructed
+	bulkActions := bulk.NewRegistry(processor, appLogger)
+
+	// Initialize transactional email - provider selected by EMAIL_PROVIDER
+	emailSender, err := email.NewSender(cfg.Email, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize email provider", zap.Error(err))
+	}
+	// Store settings (name, support email, currency...) are edited at runtime via /admin/settings
+	storeSettings := settings.NewStore(repository.NewSettingRepository(db, appLogger), appCache, cfg, appLogger)
+
+	// Order numbers (ORD-2025-000123) - counters reset per ORDER_NUMBER_RESET
+	orderNumbers, err := ordernumber.NewGenerator(repository.NewOrderSequenceRepository(db, appLogger),
+		storeSettings, clock.System, cfg.Orders)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize order numbers", zap.Error(err))
+	}
+
+	// Flash sale prices, precomputed into the cache as one schedule; managed via /admin/campaigns
+	campaigns := campaign.NewPricing(repository.NewCampaignRepository(db, appLogger), appCache, clock.System, appLogger)
+
+	// Sales channels (web, app, pos...) with per-channel visibility and prices; managed via /admin/channels
+	channels := channel.NewCatalog(repository.NewChannelRepository(db, appLogger), appCache, appLogger)
+
+	// Customer groups (retail, wholesale, vip...) with their price lists and tax treatment; managed via /admin/customer-groups
+	groups := customergroup.NewPricing(repository.NewCustomerGroupRepository(db, appLogger),
+		repository.NewTaxProfileRepository(db, appLogger), appCache, clock.System, appLogger)
+
+	// Email templates are embedded; admins can replace them at runtime via /admin/email-templates
+	emailTemplates, err := email.NewTemplateStore(repository.NewEmailTemplateRepository(db, appLogger), appCache, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to load email templates", zap.Error(err))
+	}
+	mailer := email.NewMailer(emailSender, emailTemplates, storeSettings, processor, appLogger)
+
+	// Initialize SMS/WhatsApp - provider selected by SMS_PROVIDER
+	smsSender, err := sms.NewSender(cfg.SMS, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize SMS provider", zap.Error(err))
+	}
+
+	// Notifier routes OTPs, order updates, payment confirmations and restock alerts to each user's preferred channel
+	notifier := notify.NewNotifier(
+		repository.NewNotificationRepository(db, appLogger),
+		repository.NewUserRepository(db, appLogger),
+		smsSender, mailer, appLogger,
+	)
+
+	// Exchange rates - provider selected by FX_PROVIDER, refreshed by the currency module
+	fxProvider, err := fx.NewProvider(cfg.FX)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize exchange rate provider", zap.Error(err))
+	}
+	converter := fx.NewConverter(fxProvider, repository.NewExchangeRateRepository(db, appLogger), appCache, cfg.FX, appLogger)
+
+	// Address validation - provider selected by ADDRESS_PROVIDER, zones from DELIVERY_ZONES
+	addressValidator, err := address.NewValidator(cfg.Address)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize address validator", zap.Error(err))
+	}
+	deliveryZones, err := address.NewZones(cfg.Address.DeliveryZones)
+	if err != nil {
+		appLogger.Fatal("Failed to parse delivery zones", zap.Error(err))
+	}
+
+	// VAT/TIN number checks - registry selected by TAX_ID_PROVIDER
+	taxIDs, err := taxid.NewValidator(cfg.TaxIDs)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tax ID validator", zap.Error(err))
+	}
+
+	// Warehouse allocation - default rule from INVENTORY_ALLOCATION, warehouses managed via /admin/warehouses
+	allocator, err := inventory.NewAllocator(repository.NewWarehouseRepository(db, appLogger), deliveryZones, cfg.Inventory, clock.System)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize inventory allocation", zap.Error(err))
+	}
+
+	// Shipping carriers enabled by SHIPPING_CARRIERS
+	carriers, err := shippingcarriers.NewCarriers(cfg.Shipping)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize shipping carriers", zap.Error(err))
+	}
+
+	// Object storage - backend selected by STORAGE_BACKEND
+	objectStorage, err := storage.New(cfg.Storage)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize object storage", zap.Error(err))
+	}
+
+	// Product search - engine selected by SEARCH_BACKEND (nil means database search)
+	searchEngine, err := search.New(cfg.Search)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize search engine", zap.Error(err))
+	}
+
+	// Fraud screening - checker selected by FRAUD_PROVIDER, assessments stored for review
+	fraudChecker, err := fraud.NewChecker(cfg.Fraud, appCache, converter, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize fraud checker", zap.Error(err))
+	}
+	fraudScreener := fraud.NewScreener(fraudChecker, repository.NewFraudRepository(db, appLogger), cfg.Fraud,
+		cfg.Server.TrustedProxies, appLogger)
+
+	// CAPTCHA - bot check on sign-up once a client exceeds CAPTCHA_FREE_ATTEMPTS
+	captchaVerifier, err := captcha.NewVerifier(cfg.Captcha)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize CAPTCHA verifier", zap.Error(err))
+	}
+	captchaGuard, err := captcha.NewGuard(cfg.Captcha, cfg.Server.TrustedProxies, captchaVerifier, appCache, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize CAPTCHA guard", zap.Error(err))
+	}
+
+	// GeoIP - client country on every API request, provider selected by GEOIP_PROVIDER
+	geoResolver, err := geoip.NewResolver(cfg.GeoIP, cfg.Server.TrustedProxies, appCache, appLogger)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize GeoIP", zap.Error(err))
+	}
+
+	// Payments - provider selected by PAYMENT_PROVIDER
+	paymentProvider, err := payment.New(cfg.Payment)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize payment provider", zap.Error(err))
+	}
+
+	// Vendor payouts - provider selected by PAYOUT_PROVIDER
+	payoutProvider, err := disbursement.New(cfg.Payouts)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize payout provider", zap.Error(err))
+	}
+
+	// Fiscal receipts - provider selected by FISCAL_PROVIDER
+	fiscalProvider, err := fiscal.New(cfg.Fiscal)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize fiscal receipt provider", zap.Error(err))
+	}
+
+	// Keycloak admin sync - provisions users and syncs roles when KEYCLOAK_SYNC_ENABLED=true
+	var keycloakAdmin *keycloak.AdminClient
+	if cfg.Keycloak.SyncEnabled {
+		keycloakAdmin, err = keycloak.NewAdminClient(cfg.Keycloak)
+		if err != nil {
+			appLogger.Fatal("Failed to initialize Keycloak admin client", zap.Error(err))
+		}
+	}
+
+	// Access tokens - revoked token IDs live in the shared cache so every instance refuses them
+	tokens := auth.NewTokenManager(cfg.Auth, clock.System)
+	tokens.UseRevocations(appCache)
+
+	// Shared infrastructure handed to every module
+	deps := module.Deps{
+		DB:       db,
+		Config:   cfg,
+		Log:      appLogger,
+		Clock:    clock.System,
+		IDs:      clock.UUIDs,
+		Cache:    appCache,
+		Locks:    locker,
+		Events:   eventBus,
+		Tokens:   tokens,
+		Mailer:   mailer,
+		Notifier: notifier,
+		Jobs:     processor,
+		Bulk:     bulkActions,
+		FX:       converter,
+		Settings: storeSettings,
+
+		OrderNumbers: orderNumbers,
+		Campaigns:    campaigns,
+		Channels:     channels,
+		Groups:       groups,
+		Inventory:    allocator,
+
+		Addresses: addressValidator,
+		Zones:     deliveryZones,
+		TaxIDs:    taxIDs,
+		Carriers:  carriers,
+		Keycloak:  keycloakAdmin,
+		Storage:   objectStorage,
+		Search:    searchEngine,
+		Fraud:     fraudScreener,
+		Captcha:   captchaGuard,
+		Payments:  paymentProvider,
+		Payouts:   payoutProvider,
+		Fiscal:    fiscalProvider,
+
+		HTTPLimiter: httpLimiter,
+		Quotas:      quotas,
+		Links:       links.NewBuilder(cfg.Server.ResourceLinks),
+	}
+
+	// Feature modules served by this instance
+	// Add new features (products, orders, cart, payments...) here
+	catalogModule := catalog.NewModule(deps)
+	settingsModule := settingsadmin.NewModule(deps)
+	modules := []module.Module{
+		user.NewModule(deps),
+		webhook.NewModule(deps),
+		notification.NewModule(deps),
+		job.NewModule(deps),
+		order.NewModule(deps),
+		dashboard.NewModule(deps),
+		currency.NewModule(deps),
+		shipping.NewModule(deps),
+		identity.NewModule(deps),
+		files.NewModule(deps),
+		catalogModule,
+		fraudreview.NewModule(deps),
+		capacity.NewModule(deps),
+		batch.NewModule(deps),
+		settingsModule,
+		campaignadmin.NewModule(deps),
+		inventoryadmin.NewModule(deps),
+		vendor.NewModule(deps),
+		segment.NewModule(deps),
+		referral.NewModule(deps),
+		question.NewModule(deps),
+		page.NewModule(deps),
+		emailtemplate.NewModule(deps),
+		channeladmin.NewModule(deps),
+		groupadmin.NewModule(deps),
+		quote.NewModule(deps),
+		supplier.NewModule(deps),
+		dispute.NewModule(deps),
+		ticket.NewModule(deps),
+		oidc.NewModule(deps),
+		analytics.NewModule(deps),
+		usage.NewModule(deps),
+	}
+
+	// `main worker` runs only the job workers (no HTTP server) so they can scale separately
+	// Module constructors above have already registered their job handlers
+	if len(os.Args) > 1 && os.Args[1] == "worker" {
+		runWorker(processor, appLogger)
+		return
+	}
+
+	// `main reindex` rebuilds the product search index and swaps it in with zero downtime
+	if len(os.Args) > 1 && os.Args[1] == "reindex" {
+		runReindex(catalogModule, appLogger)
+		return
+	}
+
+	// `main config export|import` copies store configuration between environments
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		runConfig(settingsModule.Bundles(), os.Args[2:], appLogger)
+		return
+	}
+
+	// `main loadgen` seeds load test data and writes k6/vegeta scenarios - never run it against production
+	if len(os.Args) > 1 && os.Args[1] == "loadgen" {
+		runLoadgen(db, cfg, deps.Tokens, os.Args[2:], appLogger)
+		return
+	}
+
+	// Dev mode seeds the fresh database and lets token-less requests act as the demo users
+	if cfg.DevMode {
+		runDevSeed(db, cfg, deps.Tokens, modules, appLogger)
+	}
+
 	// Pass config and GORM db to APIServer
-	apiServer := api.NewAPIServer(":"+cfg.Server.Port, db, cfg, appLogger)
+	apiServer := api.NewAPIServer(":"+cfg.Server.Port, db, cfg, appLogger, appCache, deps.Tokens, httpLimiter, quotas, geoResolver, deps.Links, modules)
 	apiServer.Run()
 }
+
+// runWorker processes background jobs until SIGINT/SIGTERM, letting in-flight jobs finish
+// Schema migrations are applied by the API process; start it at least once before workers
+func runWorker(processor *jobs.Processor, log *zap.Logger) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Info("Starting job worker")
+	if err := processor.Run(ctx); err != nil && ctx.Err() == nil {
+		log.Fatal("Job worker stopped", zap.Error(err))
+	}
+}
+
+// runReindex rebuilds the search index in the foreground; the API keeps serving the old index meanwhile
+func runReindex(catalogModule *catalog.Module, log *zap.Logger) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Info("Rebuilding search index")
+	if err := catalogModule.Reindex(ctx); err != nil {
+		log.Fatal("Reindex failed", zap.Error(err))
+	}
+}
+
+// runConfig writes the configuration bundle to a file (or stdout), or imports one and prints
+// what changed: `config export [file]`, `config import [-dry-run] file`
+func runConfig(bundles *settingsadmin.BundleService, args []string, log *zap.Logger) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if len(args) == 0 {
+		log.Fatal("Usage: config export [file] | config import [-dry-run] file")
+	}
+	switch args[0] {
+	case "export":
+		bundle, err := bundles.Export(ctx)
+		if err != nil {
+			log.Fatal("Configuration export failed", zap.Error(err))
+		}
+		out := os.Stdout
+		if len(args) > 1 {
+			if out, err = os.Create(args[1]); err != nil {
+				log.Fatal("Failed to create bundle file", zap.Error(err))
+			}
+			defer out.Close()
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(bundle); err != nil {
+			log.Fatal("Failed to write bundle", zap.Error(err))
+		}
+	case "import":
+		flags := flag.NewFlagSet("config import", flag.ExitOnError)
+		dryRun := flags.Bool("dry-run", false, "only list what would change")
+		_ = flags.Parse(args[1:])
+		if flags.NArg() != 1 {
+			log.Fatal("Usage: config import [-dry-run] file")
+		}
+		data, err := os.ReadFile(flags.Arg(0))
+		if err != nil {
+			log.Fatal("Failed to read bundle file", zap.Error(err))
+		}
+		var bundle models.ConfigBundle
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			log.Fatal("Bundle file is not valid JSON", zap.Error(err))
+		}
+		result, err := bundles.Import(ctx, &bundle, *dryRun, "")
+		if err != nil {
+			log.Fatal("Configuration import failed", zap.Error(err))
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(result)
+	default:
+		log.Fatal("Unknown config command", zap.String("command", args[0]))
+	}
+}
+
+// runLoadgen seeds users and products and writes load test scenarios for them
+// Like workers, it relies on the API process having applied schema migrations
+func runLoadgen(db *gorm.DB, cfg *config.Config, tokens *auth.TokenManager, args []string, log *zap.Logger) {
+	opts := loadgen.Options{Currency: cfg.FX.BaseCurrency}
+	flags := flag.NewFlagSet("loadgen", flag.ExitOnError)
+	flags.IntVar(&opts.Users, "users", 1000, "number of customers to seed")
+	flags.IntVar(&opts.Products, "products", 5000, "number of products to seed")
+	flags.IntVar(&opts.Orders, "orders", 10000, "number of past orders to seed")
+	flags.StringVar(&opts.Password, "password", "loadtest-password", "password of every seeded user")
+	flags.StringVar(&opts.PaymentMethod, "payment-method", "loadtest", "payment method checkouts pay with (sandbox: anything but decline and error)")
+	flags.StringVar(&opts.BaseURL, "base-url", "http://localhost:"+cfg.Server.Port+"/api/v1", "API base URL the scenarios target")
+	flags.StringVar(&opts.OutDir, "out", "loadtest", "directory scenarios are written to")
+	flags.Int64Var(&opts.Seed, "seed", 1, "random seed for generated data")
+	_ = flags.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	seeded, err := loadgen.Seed(ctx, db, opts, log)
+	if err != nil {
+		log.Fatal("Seeding load test data failed", zap.Error(err))
+	}
+	files, err := loadgen.WriteScenarios(seeded, opts, tokens)
+	if err != nil {
+		log.Fatal("Writing load test scenarios failed", zap.Error(err))
+	}
+	log.Info("Load test scenarios written", zap.String("dir", opts.OutDir), zap.Strings("files", files))
+}
+
+// runSmoke exits non-zero when any step fails so deploy pipelines can gate on it
+func runSmoke(args []string, log *zap.Logger) {
+	opts := smoke.Options{
+		Address: models.AddressRequest{
+			Label:     "smoke",
+			Recipient: "Smoke Test",
+			Phone:     "+254700000000",
+			Line1:     "Kenyatta Avenue",
+			City:      "Nairobi",
+			Region:    "Nairobi",
+			Country:   "KE",
+		},
+	}
+	flags := flag.NewFlagSet("smoke", flag.ExitOnError)
+	flags.StringVar(&opts.BaseURL, "base-url", "http://localhost:8085", "server root URL (the API is under /api/v1)")
+	flags.DurationVar(&opts.Timeout, "timeout", 10*time.Second, "timeout of each request")
+	flags.StringVar(&opts.Email, "email", "", "existing account to sign in as (default: register a new one)")
+	flags.StringVar(&opts.Password, "password", "smoke-test-password", "password of the smoke account")
+	flags.StringVar(&opts.Address.Country, "country", opts.Address.Country, "country of the checkout address (must be in a delivery zone)")
+	flags.StringVar(&opts.Address.City, "city", opts.Address.City, "city of the checkout address")
+	flags.StringVar(&opts.ProductID, "product", "", "product to buy at checkout (default: the first in-stock search hit)")
+	flags.StringVar(&opts.PaymentMethod, "payment-method", "smoke-test", "payment method of the checkout (sandbox: anything but decline and error)")
+	_ = flags.Parse(args)
+	opts.Address.Region = opts.Address.City
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if _, err := smoke.NewRunner(opts, log).Run(ctx); err != nil {
+		log.Error("Smoke test failed", zap.String("base_url", opts.BaseURL), zap.Error(err))
+		os.Exit(1)
+	}
+	log.Info("Smoke test passed", zap.String("base_url", opts.BaseURL))
+}
+
+// runSDK generates the Go and TypeScript clients from the swag output
+// Run `swag init` first (or `make sdk`, which does both) so the spec matches the handlers
+func runSDK(args []string, log *zap.Logger) {
+	var specPath, out string
+	flags := flag.NewFlagSet("sdk", flag.ExitOnError)
+	flags.StringVar(&specPath, "spec", "docs/swagger.json", "Swagger 2.0 document to generate from")
+	flags.StringVar(&out, "out", "sdk", "directory the clients are written below")
+	_ = flags.Parse(args)
+
+	spec, err := sdkgen.Load(specPath)
+	if err != nil {
+		log.Fatal("Failed to load API spec", zap.String("spec", specPath), zap.Error(err))
+	}
+	endpoints, skipped := spec.Endpoints()
+	if len(skipped) > 0 {
+		log.Warn("Operations with form parameters are not generated", zap.Strings("operations", skipped))
+	}
+
+	files, err := sdkgen.Generate(spec, out)
+	if err != nil {
+		log.Fatal("Failed to generate client SDKs", zap.Error(err))
+	}
+	log.Info("Client SDKs generated", zap.String("spec", specPath), zap.Int("operations", len(endpoints)),
+		zap.Int("models", len(spec.Definitions)), zap.Strings("files", files))
+}
+
+// runDevSeed creates the schema early so demo data can be inserted before the server starts
+// The server's own migration pass afterwards is a no-op
+func runDevSeed(db *gorm.DB, cfg *config.Config, tokens *auth.TokenManager, modules []module.Module, log *zap.Logger) {
+	var migrationList []migrations.Migration
+	for _, m := range modules {
+		migrationList = append(migrationList, m.Migrations()...)
+	}
+	if err := migrations.MigrateDB(db, log, migrationList, cfg.Database.MigrationLint); err != nil {
+		log.Fatal("Failed to run migrations", zap.Error(err))
+	}
+
+	identities, err := devmode.Seed(context.Background(), db, cfg.FX.BaseCurrency, log)
+	if err != nil {
+		log.Fatal("Failed to seed demo data", zap.Error(err))
+	}
+	tokens.EnableDevIdentities(identities)
+	log.Info("Requests without a bearer token act as the demo customer; send X-Dev-Role: admin or vendor for the others")
+}
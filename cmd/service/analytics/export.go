@@ -0,0 +1,341 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/bi"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/lock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/storage"
+	"go.uber.org/zap"
+)
+
+// JobExport exports every dataset's changes to object storage
+const JobExport = "analytics.bi_export"
+
+const (
+	// exportBatch is how many rows are read per query and encoded at a time
+	exportBatch = 1000
+	// exportLockKey keeps exports from overlapping, e.g. a manual one during a scheduled one
+	exportLockKey = "bi-export"
+	// exportLockTTL bounds how long a crashed export holds the lock
+	exportLockTTL = time.Hour
+)
+
+// ErrUnknownDataset is returned for a dataset other than orders, users or products
+var ErrUnknownDataset = errors.New("unknown dataset")
+
+// dataset exports one table's changes since its watermark
+type dataset interface {
+	name() string
+	export(ctx context.Context, format string, mark *models.BIExportWatermark, until time.Time, w io.Writer) (int64, error)
+}
+
+// table is a dataset of rows of T: the rows updated since the watermark, then (for tables with
+// soft deletes) the rows deleted since it, in one file. Deleted rows carry deleted_at.
+type table[T any] struct {
+	dataset string
+	columns []bi.Column[T]
+	changed changes[T]
+	deleted changes[T] // nil for tables without soft deletes
+}
+
+// changes is a repository iterator over rows changed after a cursor, returning the last row's cursor
+type changes[T any] func(ctx context.Context, after repository.Cursor, until time.Time, batchSize int, fn func([]T) error) (repository.Cursor, error)
+
+func (t *table[T]) name() string {
+	return t.dataset
+}
+
+// export writes the rows past mark to w and moves mark to the last of them
+func (t *table[T]) export(ctx context.Context, format string, mark *models.BIExportWatermark, until time.Time, w io.Writer) (int64, error) {
+	enc, err := bi.NewEncoder(format, w, t.columns)
+	if err != nil {
+		return 0, err
+	}
+
+	var rows int64
+	write := func(batch []T) error {
+		rows += int64(len(batch))
+		return enc.Write(batch)
+	}
+	changed, err := t.changed(ctx, cursorOf(mark.ChangedThrough, mark.ChangedID), until, exportBatch, write)
+	if err != nil {
+		return 0, err
+	}
+	deleted := cursorOf(mark.DeletedThrough, mark.DeletedID)
+	if t.deleted != nil {
+		if deleted, err = t.deleted(ctx, deleted, until, exportBatch, write); err != nil {
+			return 0, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return 0, err
+	}
+
+	if !changed.At.IsZero() {
+		mark.ChangedThrough, mark.ChangedID = &changed.At, changed.ID
+	}
+	if !deleted.At.IsZero() {
+		mark.DeletedThrough, mark.DeletedID = &deleted.At, deleted.ID
+	}
+	return rows, nil
+}
+
+// ExportService ships incremental snapshots of orders, users and products to object storage
+// for BI tools. Each run writes one file per dataset with the rows changed since that dataset's
+// watermark, under bi/<dataset>/dt=<date>/, then advances the watermark. A file is uploaded
+// before its watermark is saved, so a failure in between re-exports those rows next time:
+// consumers should keep the latest row per id by updated_at
+type ExportService struct {
+	repo     *repository.BIExportRepository
+	storage  storage.Storage
+	locks    lock.Locker
+	jobs     *jobs.Processor
+	format   string
+	interval time.Duration
+	lag      time.Duration
+	datasets []dataset
+	clock    clock.Clock
+	log      *zap.Logger
+}
+
+func NewExportService(repo *repository.BIExportRepository, objects storage.Storage, locks lock.Locker,
+	processor *jobs.Processor, cfg config.BIExport, clk clock.Clock, log *zap.Logger) *ExportService {
+	format, _ := bi.ParseFormat(cfg.Format) // validated at startup
+	return &ExportService{
+		repo:     repo,
+		storage:  objects,
+		locks:    locks,
+		jobs:     processor,
+		format:   format,
+		interval: cfg.Interval,
+		lag:      cfg.Lag,
+		datasets: []dataset{orderTable(repo), userTable(repo), productTable(repo)},
+		clock:    clk,
+		log:      log,
+	}
+}
+
+// Status returns the export format and every dataset's watermark (admin only)
+func (s *ExportService) Status(ctx context.Context) (*models.BIExportStatus, error) {
+	marks, err := s.repo.Watermarks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byDataset := make(map[string]models.BIExportWatermark, len(marks))
+	for _, mark := range marks {
+		byDataset[mark.Dataset] = mark
+	}
+	status := &models.BIExportStatus{Format: s.format, Datasets: make([]models.BIExportWatermark, 0, len(s.datasets))}
+	if s.interval > 0 {
+		status.Interval = s.interval.String()
+	}
+	for _, ds := range s.datasets {
+		mark, ok := byDataset[ds.name()]
+		if !ok {
+			mark = models.BIExportWatermark{Dataset: ds.name()}
+		}
+		status.Datasets = append(status.Datasets, mark)
+	}
+	return status, nil
+}
+
+// Export queues an export of every dataset now (admin only)
+func (s *ExportService) Export(ctx context.Context) (*models.Job, error) {
+	return s.jobs.Enqueue(ctx, JobExport, nil, jobs.MaxAttempts(1))
+}
+
+// Reset forgets a dataset's watermark, so the next export writes a full snapshot of it (admin only)
+func (s *ExportService) Reset(ctx context.Context, name string) error {
+	for _, ds := range s.datasets {
+		if ds.name() == name {
+			if err := s.repo.ResetWatermark(ctx, name); err != nil {
+				return err
+			}
+			s.log.Info("Export watermark reset", zap.String("dataset", name))
+			return nil
+		}
+	}
+	return ErrUnknownDataset
+}
+
+func (s *ExportService) handleExportJob(ctx context.Context, job *models.Job) error {
+	held, err := s.locks.TryLock(ctx, exportLockKey, exportLockTTL)
+	if errors.Is(err, lock.ErrNotAcquired) {
+		s.log.Info("Export already running; skipped")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer held.Release(context.WithoutCancel(ctx))
+
+	// One failing dataset doesn't hold up the rest
+	until := s.clock.Now().UTC().Add(-s.lag)
+	var firstErr error
+	for _, ds := range s.datasets {
+		if err := s.exportDataset(ctx, ds, until); err != nil {
+			s.log.Error("Failed to export dataset", zap.String("dataset", ds.name()), zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// exportDataset spools ds's changes through until to a temporary file and uploads it
+func (s *ExportService) exportDataset(ctx context.Context, ds dataset, until time.Time) error {
+	mark, err := s.repo.Watermark(ctx, ds.name())
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp("", "bi-export-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	rows, err := ds.export(ctx, s.format, mark, until, file)
+	if err != nil {
+		return err
+	}
+	now := s.clock.Now().UTC()
+	mark.LastRunAt = &now
+	if rows == 0 {
+		return s.repo.SaveWatermark(ctx, mark)
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	key := storage.Key(storage.PrefixBI, ds.name(), "dt="+until.Format("2006-01-02"),
+		fmt.Sprintf("%s-%s.%s", ds.name(), until.Format("20060102T150405Z"), bi.Extension(s.format)))
+	if err := s.storage.Put(ctx, key, file, size, bi.ContentType(s.format)); err != nil {
+		return err
+	}
+
+	mark.LastExportAt = &now
+	mark.LastObject = key
+	mark.LastRows = rows
+	mark.TotalRows += rows
+	if err := s.repo.SaveWatermark(ctx, mark); err != nil {
+		return err
+	}
+	s.log.Info("Dataset exported", zap.String("dataset", ds.name()), zap.String("key", key), zap.Int64("rows", rows))
+	return nil
+}
+
+func cursorOf(at *time.Time, id string) repository.Cursor {
+	if at == nil {
+		return repository.Cursor{}
+	}
+	return repository.Cursor{At: *at, ID: id}
+}
+
+func orderTable(repo *repository.BIExportRepository) *table[models.Order] {
+	return &table[models.Order]{
+		dataset: models.BIDatasetOrders,
+		columns: []bi.Column[models.Order]{
+			{Name: "id", Type: bi.String, Value: func(o models.Order) any { return o.ID }},
+			{Name: "order_number", Type: bi.String, Value: func(o models.Order) any { return o.OrderNumber }},
+			{Name: "user_id", Type: bi.String, Value: func(o models.Order) any { return o.UserID }},
+			{Name: "status", Type: bi.String, Value: func(o models.Order) any { return o.Status }},
+			{Name: "total", Type: bi.Int64, Value: func(o models.Order) any { return o.Total }},
+			{Name: "currency", Type: bi.String, Value: func(o models.Order) any { return o.Currency }},
+			{Name: "channel", Type: bi.String, Value: func(o models.Order) any { return o.Channel }},
+			{Name: "item_count", Type: bi.Int64, Value: func(o models.Order) any { return int64(len(o.Items)) }},
+			{Name: "items", Type: bi.String, Value: func(o models.Order) any { return jsonString(o.Items) }},
+			{Name: "quote_id", Type: bi.String, Value: func(o models.Order) any { return o.QuoteID }},
+			{Name: "tax_treatment", Type: bi.String, Value: func(o models.Order) any { return o.TaxTreatment }},
+			{Name: "fiscal_status", Type: bi.String, Value: func(o models.Order) any { return o.FiscalStatus }},
+			{Name: "placed_at", Type: bi.Timestamp, Value: func(o models.Order) any { return o.PlacedAt }},
+			{Name: "updated_at", Type: bi.Timestamp, Value: func(o models.Order) any { return o.UpdatedAt }},
+		},
+		changed: repo.EachOrderChanged,
+	}
+}
+
+// userTable exports account and profile fields; credentials and identity-provider links stay out
+func userTable(repo *repository.BIExportRepository) *table[models.User] {
+	return &table[models.User]{
+		dataset: models.BIDatasetUsers,
+		columns: []bi.Column[models.User]{
+			{Name: "id", Type: bi.String, Value: func(u models.User) any { return u.ID }},
+			{Name: "email", Type: bi.String, Value: func(u models.User) any { return u.Email }},
+			{Name: "username", Type: bi.String, Optional: true, Value: func(u models.User) any {
+				if u.Username == nil {
+					return nil
+				}
+				return *u.Username
+			}},
+			{Name: "first_name", Type: bi.String, Value: func(u models.User) any { return u.FirstName }},
+			{Name: "last_name", Type: bi.String, Value: func(u models.User) any { return u.LastName }},
+			{Name: "role", Type: bi.String, Value: func(u models.User) any { return u.Role }},
+			{Name: "locale", Type: bi.String, Value: func(u models.User) any { return u.Locale }},
+			{Name: "created_at", Type: bi.Timestamp, Value: func(u models.User) any { return u.CreatedAt }},
+			{Name: "updated_at", Type: bi.Timestamp, Value: func(u models.User) any { return u.UpdatedAt }},
+			{Name: "deleted_at", Type: bi.Timestamp, Optional: true, Value: func(u models.User) any {
+				if !u.DeletedAt.Valid {
+					return nil
+				}
+				return u.DeletedAt.Time
+			}},
+		},
+		changed: repo.EachUserChanged,
+		deleted: repo.EachUserDeleted,
+	}
+}
+
+func productTable(repo *repository.BIExportRepository) *table[models.Product] {
+	return &table[models.Product]{
+		dataset: models.BIDatasetProducts,
+		columns: []bi.Column[models.Product]{
+			{Name: "id", Type: bi.String, Value: func(p models.Product) any { return p.ID }},
+			{Name: "sku", Type: bi.String, Value: func(p models.Product) any { return p.SKU }},
+			{Name: "name", Type: bi.String, Value: func(p models.Product) any { return p.Name }},
+			{Name: "category", Type: bi.String, Value: func(p models.Product) any { return p.Category }},
+			{Name: "price", Type: bi.Int64, Value: func(p models.Product) any { return p.Price }},
+			{Name: "currency", Type: bi.String, Value: func(p models.Product) any { return p.Currency }},
+			{Name: "stock", Type: bi.Int64, Value: func(p models.Product) any { return int64(p.Stock) }},
+			{Name: "active", Type: bi.Bool, Value: func(p models.Product) any { return p.Active }},
+			{Name: "vendor_id", Type: bi.String, Value: func(p models.Product) any { return p.VendorID }},
+			{Name: "created_at", Type: bi.Timestamp, Value: func(p models.Product) any { return p.CreatedAt }},
+			{Name: "updated_at", Type: bi.Timestamp, Value: func(p models.Product) any { return p.UpdatedAt }},
+			{Name: "deleted_at", Type: bi.Timestamp, Optional: true, Value: func(p models.Product) any {
+				if !p.DeletedAt.Valid {
+					return nil
+				}
+				return p.DeletedAt.Time
+			}},
+		},
+		changed: repo.EachProductChanged,
+		deleted: repo.EachProductDeleted,
+	}
+}
+
+// jsonString encodes nested values (order lines) into a string column
+func jsonString(v any) string {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
@@ -0,0 +1,105 @@
+package analytics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type ExportHandler struct {
+	service *ExportService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewExportHandler(service *ExportService, tokens *auth.TokenManager, log *zap.Logger) *ExportHandler {
+	return &ExportHandler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers data warehouse export routes (admin only)
+func (h *ExportHandler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/bi/exports").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("", h.handleStatus).Methods("GET")
+	admin.HandleFunc("", h.handleExport).Methods("POST")
+	admin.HandleFunc("/{dataset}/reset", h.handleReset).Methods("POST")
+}
+
+// handleStatus handles GET /api/v1/admin/bi/exports
+// @Summary Data warehouse export status
+// @Description The export format and schedule, and how far each dataset (orders, users, products) has been exported
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.BIExportStatus
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/bi/exports [get]
+func (h *ExportHandler) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.service.Status(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, status)
+}
+
+// handleExport handles POST /api/v1/admin/bi/exports
+// @Summary Export to the data warehouse now
+// @Description Queues an export of every dataset's changes since its watermark. Track it via /admin/jobs/{id}.
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} models.Job
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/bi/exports [post]
+func (h *ExportHandler) handleExport(w http.ResponseWriter, r *http.Request) {
+	job, err := h.service.Export(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusAccepted, job)
+}
+
+// handleReset handles POST /api/v1/admin/bi/exports/{dataset}/reset
+// @Summary Reset a dataset's watermark
+// @Description The next export writes a full snapshot of the dataset, e.g. to rebuild a warehouse table
+// @Tags Reports
+// @Security BearerAuth
+// @Param dataset path string true "orders, users or products"
+// @Success 204 "Reset"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Unknown dataset"
+// @Router /admin/bi/exports/{dataset}/reset [post]
+func (h *ExportHandler) handleReset(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Reset(r.Context(), mux.Vars(r)["dataset"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError maps export errors to 404/500
+func (h *ExportHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownDataset):
+		http.Error(w, "Unknown dataset", http.StatusNotFound)
+	default:
+		h.log.Error("Export request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
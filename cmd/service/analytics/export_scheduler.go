@@ -0,0 +1,42 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"go.uber.org/zap"
+)
+
+// ExportScheduler queues a data warehouse export each BI_EXPORT_INTERVAL
+// It runs on the elected leader only, so one export is queued per interval; job workers run it
+type ExportScheduler struct {
+	jobs     *jobs.Processor
+	interval time.Duration
+	log      *zap.Logger
+}
+
+func NewExportScheduler(processor *jobs.Processor, interval time.Duration, log *zap.Logger) *ExportScheduler {
+	return &ExportScheduler{jobs: processor, interval: interval, log: log}
+}
+
+func (s *ExportScheduler) Name() string {
+	return "bi-export"
+}
+
+// Run queues exports until ctx is cancelled
+func (s *ExportScheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := s.jobs.Enqueue(ctx, JobExport, nil, jobs.MaxAttempts(1)); err != nil {
+				s.log.Error("Failed to queue data warehouse export", zap.Error(err))
+			}
+		}
+	}
+}
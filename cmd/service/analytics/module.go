@@ -0,0 +1,60 @@
+package analytics
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/lock"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+)
+
+// Module provides precomputed reports (signup-month cohorts with retention and lifetime value)
+// and incremental exports of orders, users and products to object storage for BI tools
+// The cohort report reads the segment module's customer order ledger and is rebuilt nightly by a job
+type Module struct {
+	handler    *Handler
+	exports    *ExportHandler
+	schedulers []module.Service // on the elected leader only; the nightly rebuild and periodic exports when enabled
+}
+
+func NewModule(deps module.Deps) *Module {
+	service := NewCohortService(repository.NewCohortRepository(deps.DB, deps.Log), deps.FX, deps.Settings,
+		deps.Jobs, deps.Clock, deps.Log)
+	exports := NewExportService(repository.NewBIExportRepository(deps.DB, deps.Log), deps.Storage, deps.Locks,
+		deps.Jobs, deps.Config.BIExport, deps.Clock, deps.Log)
+
+	deps.Jobs.Register(JobCohorts, service.handleCohortsJob)
+	deps.Jobs.Register(JobExport, exports.handleExportJob)
+
+	m := &Module{
+		handler: NewHandler(service, deps.Tokens, deps.Log),
+		exports: NewExportHandler(exports, deps.Tokens, deps.Log),
+	}
+	if hour := deps.Config.Analytics.CohortHour; hour >= 0 && hour < 24 {
+		m.schedulers = append(m.schedulers,
+			lock.Singleton(deps.Locks, NewScheduler(deps.Jobs, hour, deps.Log), deps.Config.Locks.LeaderTTL, deps.Log))
+	}
+	if interval := deps.Config.BIExport.Interval; interval > 0 {
+		m.schedulers = append(m.schedulers,
+			lock.Singleton(deps.Locks, NewExportScheduler(deps.Jobs, interval, deps.Log), deps.Config.Locks.LeaderTTL, deps.Log))
+	}
+	return m
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.CohortSummary{}, &models.CohortMonth{}),
+		migrations.AutoMigrate(&models.BIExportWatermark{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+	m.exports.RegisterRoutes(router)
+}
+
+// Services returns the enabled schedulers
+func (m *Module) Services() []module.Service {
+	return m.schedulers
+}
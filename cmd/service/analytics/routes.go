@@ -0,0 +1,89 @@
+package analytics
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *CohortService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *CohortService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers report routes (admin only)
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/reports").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/cohorts", h.handleCohorts).Methods("GET")
+	admin.HandleFunc("/cohorts/refresh", h.handleRefresh).Methods("POST")
+}
+
+// handleCohorts handles GET /api/v1/admin/reports/cohorts
+// @Summary Cohort retention report
+// @Description Customers grouped by signup month, with repeat purchase rate, monthly retention and revenue, and lifetime value to date and projected over the first 12 months. Served from the last nightly rebuild; defaults to the last 12 cohorts, at most 60.
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "First cohort, YYYY-MM"
+// @Param to query string false "Last cohort, YYYY-MM (default: this month)"
+// @Success 200 {object} models.CohortReport
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/reports/cohorts [get]
+func (h *Handler) handleCohorts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	report, err := h.service.Report(r.Context(), strings.TrimSpace(query.Get("from")), strings.TrimSpace(query.Get("to")))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, report)
+}
+
+// handleRefresh handles POST /api/v1/admin/reports/cohorts/refresh
+// @Summary Rebuild cohort report
+// @Description Queues a rebuild of the cohort report ahead of the nightly one. Track it via /admin/jobs/{id}.
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} models.Job
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/reports/cohorts/refresh [post]
+func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	job, err := h.service.Refresh(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusAccepted, job)
+}
+
+// writeError maps report errors to 400/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidReport):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.log.Error("Report request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
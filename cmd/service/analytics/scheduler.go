@@ -0,0 +1,53 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"go.uber.org/zap"
+)
+
+// Scheduler queues a cohort report rebuild once a day at COHORT_REPORT_HOUR (UTC)
+// It runs on the elected leader only, so one rebuild is queued per day; job workers run it
+type Scheduler struct {
+	jobs *jobs.Processor
+	hour int
+	log  *zap.Logger
+}
+
+func NewScheduler(processor *jobs.Processor, hour int, log *zap.Logger) *Scheduler {
+	return &Scheduler{jobs: processor, hour: hour, log: log}
+}
+
+func (s *Scheduler) Name() string {
+	return "cohort-report"
+}
+
+// Run queues rebuilds until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) error {
+	timer := time.NewTimer(time.Until(s.next(time.Now())))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			if _, err := s.jobs.Enqueue(ctx, JobCohorts, nil, jobs.MaxAttempts(1)); err != nil {
+				s.log.Error("Failed to queue cohort report rebuild", zap.Error(err))
+			}
+			timer.Reset(time.Until(s.next(time.Now())))
+		}
+	}
+}
+
+// next is the first run time after now
+func (s *Scheduler) next(now time.Time) time.Time {
+	now = now.UTC()
+	run := time.Date(now.Year(), now.Month(), now.Day(), s.hour, 0, 0, 0, time.UTC)
+	if !run.After(now) {
+		run = run.AddDate(0, 0, 1)
+	}
+	return run
+}
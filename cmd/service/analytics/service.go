@@ -0,0 +1,327 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/fx"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"go.uber.org/zap"
+)
+
+// JobCohorts rebuilds the cohort report
+const JobCohorts = "analytics.cohorts"
+
+const (
+	// cohortMonthLayout is how cohorts are keyed: the UTC signup month
+	cohortMonthLayout = "2006-01"
+	// scanBatch is how many customers or ledger rows are loaded per query during a rebuild
+	scanBatch = 1000
+	// defaultCohorts is how many cohorts GET /admin/reports/cohorts returns without a range
+	defaultCohorts = 12
+	// maxCohorts bounds one report's range
+	maxCohorts = 60
+)
+
+// ErrInvalidReport wraps problems with a report request
+var ErrInvalidReport = errors.New("invalid report request")
+
+// CohortService builds and serves the cohort report
+// Customers are grouped by signup month and followed through their first CohortHorizonMonths
+// months, from the segment module's customer order ledger. Rebuilding scans every customer
+// and ledger row, so it runs as a job (nightly, or on demand) and reads hit the summary tables
+type CohortService struct {
+	repo     *repository.CohortRepository
+	fx       *fx.Converter
+	settings *settings.Store
+	jobs     *jobs.Processor
+	clock    clock.Clock
+	log      *zap.Logger
+}
+
+func NewCohortService(repo *repository.CohortRepository, converter *fx.Converter, storeSettings *settings.Store,
+	processor *jobs.Processor, clk clock.Clock, log *zap.Logger) *CohortService {
+	return &CohortService{
+		repo:     repo,
+		fx:       converter,
+		settings: storeSettings,
+		jobs:     processor,
+		clock:    clk,
+		log:      log,
+	}
+}
+
+// Report returns the cohorts from through to (YYYY-MM, inclusive) as of the last rebuild
+// Empty bounds default to the last 12 signup months
+func (s *CohortService) Report(ctx context.Context, from, to string) (*models.CohortReport, error) {
+	now := s.clock.Now().UTC()
+	end := monthStart(now)
+	if to != "" {
+		t, err := time.Parse(cohortMonthLayout, to)
+		if err != nil {
+			return nil, fmt.Errorf("%w: to must be a month (YYYY-MM)", ErrInvalidReport)
+		}
+		end = t
+	}
+	start := end.AddDate(0, -(defaultCohorts - 1), 0)
+	if from != "" {
+		t, err := time.Parse(cohortMonthLayout, from)
+		if err != nil {
+			return nil, fmt.Errorf("%w: from must be a month (YYYY-MM)", ErrInvalidReport)
+		}
+		start = t
+	}
+	switch {
+	case start.After(end):
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidReport)
+	case monthIndex(end)-monthIndex(start) >= maxCohorts:
+		return nil, fmt.Errorf("%w: reports cover at most %d cohorts", ErrInvalidReport, maxCohorts)
+	}
+
+	cohorts, err := s.repo.List(ctx, start.Format(cohortMonthLayout), end.Format(cohortMonthLayout))
+	if err != nil {
+		return nil, err
+	}
+	report := &models.CohortReport{
+		Currency:      s.settings.DefaultCurrency(ctx),
+		HorizonMonths: models.CohortHorizonMonths,
+		Cohorts:       cohorts,
+	}
+	if report.Cohorts == nil {
+		report.Cohorts = []models.CohortSummary{}
+	}
+	latest, err := s.repo.Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		report.Currency = latest.Currency
+		report.ComputedAt = &latest.ComputedAt
+	}
+	return report, nil
+}
+
+// Refresh queues a rebuild of the report (admin only)
+func (s *CohortService) Refresh(ctx context.Context) (*models.Job, error) {
+	return s.jobs.Enqueue(ctx, JobCohorts, nil, jobs.MaxAttempts(1))
+}
+
+// cohortKey identifies a cohort's activity in one month after signup
+type cohortKey struct {
+	cohort string
+	month  int
+}
+
+// revenueKey identifies revenue in one currency, before conversion
+type revenueKey struct {
+	cohort   string
+	month    int // -1 totals the cohort across all months
+	currency string
+}
+
+// customerActivity is what a rebuild tracks per customer
+type customerActivity struct {
+	cohort string
+	signup int    // monthIndex of the signup month
+	orders int    // orders over the whole ledger
+	active uint16 // bit m set when they ordered m months after signup, within the horizon
+}
+
+// Rebuild recomputes the report from every customer and ledger row and replaces the stored one
+func (s *CohortService) Rebuild(ctx context.Context) error {
+	now := s.clock.Now().UTC()
+	currency := s.settings.DefaultCurrency(ctx)
+
+	customers := make(map[string]*customerActivity)
+	sizes := make(map[string]int64)
+	err := s.repo.EachCustomer(ctx, scanBatch, func(users []models.User) error {
+		for _, user := range users {
+			signup := user.CreatedAt.UTC()
+			cohort := signup.Format(cohortMonthLayout)
+			customers[user.ID] = &customerActivity{cohort: cohort, signup: monthIndex(signup)}
+			sizes[cohort]++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	orders := make(map[cohortKey]int64)
+	cohortOrders := make(map[string]int64)
+	revenue := make(map[revenueKey]int64)
+	ordersIn := make(map[revenueKey]int64) // orders per cohort and currency, to count unconverted ones
+	err = s.repo.EachOrder(ctx, scanBatch, func(rows []models.CustomerOrder) error {
+		for _, row := range rows {
+			customer, ok := customers[row.UserID]
+			if !ok {
+				continue // not a customer (staff test orders) or the account is gone
+			}
+			month := max(monthIndex(row.PlacedAt.UTC())-customer.signup, 0)
+			code := strings.ToUpper(row.Currency)
+			net := row.Total - row.Refunded
+
+			customer.orders++
+			cohortOrders[customer.cohort]++
+			total := revenueKey{cohort: customer.cohort, month: -1, currency: code}
+			revenue[total] += net
+			ordersIn[total]++
+			if month < models.CohortHorizonMonths {
+				customer.active |= 1 << month
+				orders[cohortKey{customer.cohort, month}]++
+				revenue[revenueKey{cohort: customer.cohort, month: month, currency: code}] += net
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Convert once per cohort, month and currency rather than per order; a currency without
+	// a rate is left out of revenue and counted against the cohort instead
+	converted := make(map[cohortKey]int64)
+	unconverted := make(map[string]int64)
+	for key, amount := range revenue {
+		value, _, err := s.fx.Convert(ctx, amount, key.currency, currency)
+		if err != nil {
+			if key.month < 0 {
+				unconverted[key.cohort] += ordersIn[key]
+				s.log.Warn("No exchange rate for cohort revenue; left out of the report",
+					zap.String("cohort", key.cohort), zap.String("currency", key.currency), zap.Error(err))
+			}
+			continue
+		}
+		converted[cohortKey{key.cohort, key.month}] += value
+	}
+
+	buyers := make(map[string]int64)
+	repeat := make(map[string]int64)
+	active := make(map[cohortKey]int64)
+	for _, customer := range customers {
+		if customer.orders == 0 {
+			continue
+		}
+		buyers[customer.cohort]++
+		if customer.orders > 1 {
+			repeat[customer.cohort]++
+		}
+		for month := 0; month < models.CohortHorizonMonths; month++ {
+			if customer.active&(1<<month) != 0 {
+				active[cohortKey{customer.cohort, month}]++
+			}
+		}
+	}
+
+	// Revenue per customer at each month after signup over the cohorts that have completed
+	// that month, weighted by cohort size; it stands in for the months younger cohorts haven't reached
+	current := monthIndex(now)
+	var benchmark [models.CohortHorizonMonths]float64
+	for month := range benchmark {
+		var spend, size int64
+		for cohort, customers := range sizes {
+			if age(cohort, current) > month {
+				spend += converted[cohortKey{cohort, month}]
+				size += customers
+			}
+		}
+		if size > 0 {
+			benchmark[month] = float64(spend) / float64(size)
+		}
+	}
+
+	summaries := make([]models.CohortSummary, 0, len(sizes))
+	var months []models.CohortMonth
+	for cohort, size := range sizes {
+		summary := models.CohortSummary{
+			Cohort:       cohort,
+			Customers:    size,
+			Buyers:       buyers[cohort],
+			RepeatBuyers: repeat[cohort],
+			Orders:       cohortOrders[cohort],
+			Revenue:      converted[cohortKey{cohort, -1}],
+			Currency:     currency,
+			LTV:          perCustomer(converted[cohortKey{cohort, -1}], size),
+			Unconverted:  unconverted[cohort],
+			ComputedAt:   now,
+		}
+		if summary.Buyers > 0 {
+			summary.RepeatRate = ratio(summary.RepeatBuyers, summary.Buyers)
+		}
+
+		elapsed := age(cohort, current)
+		var cumulative int64
+		var projected float64
+		for month := 0; month < models.CohortHorizonMonths && month <= elapsed; month++ {
+			key := cohortKey{cohort, month}
+			cumulative += converted[key]
+			if month < elapsed {
+				projected += float64(converted[key]) / float64(size)
+			}
+			months = append(months, models.CohortMonth{
+				Cohort:        cohort,
+				Month:         month,
+				ActiveBuyers:  active[key],
+				Orders:        orders[key],
+				Revenue:       converted[key],
+				Retention:     ratio(active[key], size),
+				CumulativeLTV: perCustomer(cumulative, size),
+			})
+		}
+		for month := elapsed; month < models.CohortHorizonMonths; month++ {
+			projected += benchmark[month]
+		}
+		summary.ProjectedLTV = max(int64(math.Round(projected)), perCustomer(cumulative, size))
+		summaries = append(summaries, summary)
+	}
+
+	if err := s.repo.Replace(ctx, summaries, months); err != nil {
+		return err
+	}
+	s.log.Info("Cohort report rebuilt", zap.Int("cohorts", len(summaries)), zap.Int("customers", len(customers)))
+	return nil
+}
+
+func (s *CohortService) handleCohortsJob(ctx context.Context, job *models.Job) error {
+	return s.Rebuild(ctx)
+}
+
+// monthIndex numbers months consecutively, so month differences are plain subtraction
+func monthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// age is how many whole months a cohort has completed since its signup month
+func age(cohort string, current int) int {
+	t, err := time.Parse(cohortMonthLayout, cohort)
+	if err != nil {
+		return 0
+	}
+	return current - monthIndex(t)
+}
+
+func perCustomer(amount, customers int64) int64 {
+	if customers == 0 {
+		return 0
+	}
+	return int64(math.Round(float64(amount) / float64(customers)))
+}
+
+func ratio(n, d int64) float64 {
+	if d == 0 {
+		return 0
+	}
+	return math.Round(float64(n)/float64(d)*10000) / 10000
+}
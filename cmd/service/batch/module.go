@@ -0,0 +1,31 @@
+package batch
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/gorilla/mux"
+)
+
+// Module serves POST /batch, which runs many API calls in one request
+// Operations go through the same routes, auth and validation as direct calls
+type Module struct {
+	handler *Handler
+}
+
+func NewModule(deps module.Deps) *Module {
+	return &Module{
+		handler: NewHandler(deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return nil
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
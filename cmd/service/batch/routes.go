@@ -0,0 +1,200 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/captcha"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// MaxOperations caps the operations of one batch
+const MaxOperations = 100
+
+// maxBodySize caps the whole batch request body
+const maxBodySize = 4 << 20
+
+// forwardedHeaders are copied from the batch request to each operation, so every
+// operation is authenticated as the caller, acts for the caller's tenant and answers in the
+// caller's language. Together with RemoteAddr, the proxy, CAPTCHA and device headers make each
+// operation's client IP, CAPTCHA check and fraud signals those of the caller rather than of the
+// load balancer. The request ID, GeoIP country and capacity slot need no header: operations run
+// on the batch request's context.
+var forwardedHeaders = []string{
+	"Authorization", "X-Dev-Role", "X-Tenant-ID", "Accept-Language",
+	httpctx.ForwardedForHeader, "CF-IPCountry", captcha.TokenHeader, captcha.BypassHeader, "X-Device-ID",
+}
+
+// operationHeaders may be set per operation (BatchOperation.Headers); they identify one call,
+// so the batch request's own value would be wrong for all but one of its operations
+var operationHeaders = []string{"Idempotency-Key"}
+
+type Handler struct {
+	router *mux.Router // the versioned API router operations are dispatched to
+	log    *zap.Logger
+}
+
+func NewHandler(log *zap.Logger) *Handler {
+	return &Handler{log: log}
+}
+
+// RegisterRoutes registers the batch route and keeps router to dispatch operations on
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	h.router = router
+	router.HandleFunc("/batch", h.handleBatch).Methods("POST")
+}
+
+// handleBatch handles POST /api/v1/batch
+// @Summary Run a batch of API calls
+// @Description Runs up to 100 operations in order, e.g. updating many product prices, each with the caller's credentials. A failed operation does not undo or stop the others unless stop_on_error is set; check each result's status.
+// @Tags Batch
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.BatchRequest true "Operations"
+// @Success 207 {object} models.BatchResponse
+// @Failure 400 {string} string "Invalid request"
+// @Router /batch [post]
+func (h *Handler) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var req models.BatchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if len(req.Operations) == 0 {
+		http.Error(w, "operations is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Operations) > MaxOperations {
+		http.Error(w, fmt.Sprintf("at most %d operations per batch", MaxOperations), http.StatusBadRequest)
+		return
+	}
+	for i, op := range req.Operations {
+		if err := validate(op); err != nil {
+			http.Error(w, fmt.Sprintf("operations[%d]: %s", i, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// The API root the batch route is mounted under, e.g. /api/v1
+	root := strings.TrimSuffix(r.URL.Path, "/batch")
+
+	resp := models.BatchResponse{Results: make([]models.BatchResult, 0, len(req.Operations))}
+	failed := false
+	for _, op := range req.Operations {
+		var result models.BatchResult
+		switch {
+		case r.Context().Err() != nil:
+			return // client went away; the remaining operations are not run
+		case failed && req.StopOnError:
+			result = models.BatchResult{ID: op.ID, Status: http.StatusFailedDependency, Error: "Skipped after an earlier operation failed"}
+		default:
+			result = h.run(r, root, op)
+		}
+
+		if result.Status >= 200 && result.Status < 300 {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+			failed = true
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	h.log.Info("Batch processed", zap.Int("operations", len(req.Operations)),
+		zap.Int("succeeded", resp.Succeeded), zap.Int("failed", resp.Failed))
+	response.JSON(w, http.StatusMultiStatus, resp)
+}
+
+func validate(op models.BatchOperation) error {
+	switch op.Method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return fmt.Errorf("unsupported method %q", op.Method)
+	}
+	if !strings.HasPrefix(op.Path, "/") {
+		return fmt.Errorf("path must start with /")
+	}
+	if path, _, _ := strings.Cut(op.Path, "?"); path == "/batch" {
+		return fmt.Errorf("batches cannot be nested")
+	}
+	for name := range op.Headers {
+		if !slices.Contains(operationHeaders, http.CanonicalHeaderKey(name)) {
+			return fmt.Errorf("header %s cannot be set per operation (allowed: %s)", name, strings.Join(operationHeaders, ", "))
+		}
+	}
+	return nil
+}
+
+// run dispatches one operation to the API router and captures its response
+func (h *Handler) run(r *http.Request, root string, op models.BatchOperation) models.BatchResult {
+	sub, err := http.NewRequestWithContext(r.Context(), op.Method, root+op.Path, bytes.NewReader(op.Body))
+	if err != nil {
+		return models.BatchResult{ID: op.ID, Status: http.StatusBadRequest, Error: "Invalid path"}
+	}
+	sub.RemoteAddr = r.RemoteAddr
+	for _, name := range forwardedHeaders {
+		if value := r.Header.Get(name); value != "" {
+			sub.Header.Set(name, value)
+		}
+	}
+	for name, value := range op.Headers {
+		sub.Header.Set(name, value)
+	}
+	if len(op.Body) > 0 {
+		sub.Header.Set("Content-Type", "application/json")
+	}
+
+	rec := newRecorder()
+	h.router.ServeHTTP(rec, sub)
+
+	result := models.BatchResult{ID: op.ID, Status: rec.status}
+	payload := bytes.TrimSpace(rec.body.Bytes())
+	switch {
+	case len(payload) == 0:
+	case strings.HasPrefix(rec.header.Get("Content-Type"), "application/json") && json.Valid(payload):
+		result.Body = json.RawMessage(payload)
+	default:
+		result.Error = string(payload)
+	}
+	return result
+}
+
+// recorder captures an operation's response in memory
+// It deliberately implements neither http.Flusher nor http.Hijacker, so streaming
+// endpoints refuse to run inside a batch instead of blocking it
+type recorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: http.Header{}, status: http.StatusOK}
+}
+
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.wroteHeader = true
+	rec.status = status
+}
+
+func (rec *recorder) Write(p []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(p)
+}
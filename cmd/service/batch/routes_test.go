@@ -0,0 +1,171 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/captcha"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/geoip"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/limits"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// balancer is the load balancer every request arrives from; it is a trusted proxy and in
+// CAPTCHA_BYPASS_CIDRS, like an internal network would be
+const balancer = "10.0.0.5:4000"
+
+type stubVerifier struct{}
+
+func (stubVerifier) Name() string { return "stub" }
+
+func (stubVerifier) Verify(_ context.Context, token, _ string) error {
+	if token != "solved" {
+		return captcha.ErrRejected
+	}
+	return nil
+}
+
+// probe is what an operation saw of its caller
+type probe struct {
+	IP             string `json:"ip"`
+	Country        string `json:"country"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+// newAPI mounts the batch route next to a probe route, plain and CAPTCHA-protected, behind
+// the GeoIP and capacity middleware, with room for a single request at a time
+func newAPI(t *testing.T) *mux.Router {
+	t.Helper()
+	_, lan, _ := net.ParseCIDR("10.0.0.0/8")
+	proxies := []*net.IPNet{lan}
+	geo, err := geoip.NewResolver(config.GeoIP{Provider: "header", Header: "CF-IPCountry", TrustProxy: true}, proxies, nil, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	guard, err := captcha.NewGuard(config.Captcha{
+		FreeAttempts: 1, Window: time.Minute, TrustProxy: true, BypassCIDRs: []string{"10.0.0.0/8"},
+	}, proxies, stubVerifier{}, cache.NewMemoryCache("batch-test"), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api/v1").Subrouter()
+	api.Use(geo.Middleware, limits.Middleware(limits.NewLimiter("batch-test", 1, 0), 0))
+	NewHandler(zap.NewNop()).RegisterRoutes(api)
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.JSON(w, http.StatusOK, probe{
+			IP:             httpctx.ClientIP(r, true, proxies),
+			Country:        httpctx.GeoFromContext(r.Context()).Country,
+			IdempotencyKey: r.Header.Get("Idempotency-Key"),
+		})
+	})
+	api.Handle("/probe", echo)
+	api.Handle("/protected", guard.Protect("probe")(echo))
+	return router
+}
+
+// runBatch posts ops as a client behind the load balancer
+func runBatch(t *testing.T, router *mux.Router, client string, header http.Header, ops ...models.BatchOperation) []models.BatchResult {
+	t.Helper()
+	req := testutil.NewRequest(t, "POST", "/api/v1/batch", models.BatchRequest{Operations: ops})
+	req.RemoteAddr = balancer
+	req.Header.Set(httpctx.ForwardedForHeader, client)
+	req.Header.Set("CF-IPCountry", "KE")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	var resp models.BatchResponse
+	testutil.DecodeJSON(t, testutil.Serve(router, req), http.StatusMultiStatus, &resp)
+	return resp.Results
+}
+
+func probed(t *testing.T, result models.BatchResult) probe {
+	t.Helper()
+	if result.Status != http.StatusOK {
+		t.Fatalf("operation %s: status %d: %s", result.ID, result.Status, result.Error)
+	}
+	var p probe
+	if err := json.Unmarshal(result.Body, &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// TestBatchOperationsActForTheCaller checks that operations see the caller's address, country and
+// CAPTCHA token rather than the load balancer's, carry their own Idempotency-Key, and run inside
+// the capacity slot the batch already holds
+func TestBatchOperationsActForTheCaller(t *testing.T) {
+	get := func(id string, headers map[string]string) models.BatchOperation {
+		return models.BatchOperation{ID: id, Method: "GET", Path: "/probe", Headers: headers}
+	}
+	protected := func(id string) models.BatchOperation {
+		return models.BatchOperation{ID: id, Method: "GET", Path: "/protected"}
+	}
+
+	t.Run("client address and country", func(t *testing.T) {
+		results := runBatch(t, newAPI(t), "203.0.113.7", nil, get("a", nil))
+		if p := probed(t, results[0]); p.IP != "203.0.113.7" || p.Country != "KE" {
+			t.Fatalf("operation saw %s in %q, want 203.0.113.7 in KE", p.IP, p.Country)
+		}
+	})
+
+	t.Run("capacity slot", func(t *testing.T) {
+		// The only slot is the batch's; an operation taking another would get 503
+		results := runBatch(t, newAPI(t), "203.0.113.7", nil, get("a", nil), get("b", nil), get("c", nil))
+		for _, result := range results {
+			probed(t, result)
+		}
+	})
+
+	t.Run("captcha is not bypassed through the balancer", func(t *testing.T) {
+		results := runBatch(t, newAPI(t), "203.0.113.7", nil, protected("free"), protected("unsolved"))
+		probed(t, results[0])
+		if results[1].Status != http.StatusForbidden {
+			t.Fatalf("operation past the free attempts without a token: status %d, want 403", results[1].Status)
+		}
+	})
+
+	t.Run("captcha token", func(t *testing.T) {
+		results := runBatch(t, newAPI(t), "203.0.113.7", http.Header{captcha.TokenHeader: {"solved"}},
+			protected("free"), protected("solved"))
+		probed(t, results[1])
+	})
+
+	t.Run("attempts counted per client", func(t *testing.T) {
+		router := newAPI(t)
+		probed(t, runBatch(t, router, "203.0.113.7", nil, protected("a"))[0])
+		// A different client behind the same balancer still has its own free attempt
+		probed(t, runBatch(t, router, "198.51.100.9", nil, protected("b"))[0])
+	})
+
+	t.Run("idempotency key per operation", func(t *testing.T) {
+		results := runBatch(t, newAPI(t), "203.0.113.7", http.Header{"Idempotency-Key": {"batch"}},
+			get("a", map[string]string{"Idempotency-Key": "key-a"}), get("b", map[string]string{"idempotency-key": "key-b"}))
+		for i, want := range []string{"key-a", "key-b"} {
+			if p := probed(t, results[i]); p.IdempotencyKey != want {
+				t.Errorf("operation %d carried Idempotency-Key %q, want %q", i, p.IdempotencyKey, want)
+			}
+		}
+	})
+
+	t.Run("other operation headers", func(t *testing.T) {
+		req := testutil.NewRequest(t, "POST", "/api/v1/batch", models.BatchRequest{Operations: []models.BatchOperation{
+			get("a", map[string]string{"Authorization": "Bearer other"}),
+		}})
+		if rec := testutil.Serve(newAPI(t), req); rec.Code != http.StatusBadRequest {
+			t.Fatalf("Authorization set per operation: status %d, want 400", rec.Code)
+		}
+	})
+}
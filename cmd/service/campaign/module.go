@@ -0,0 +1,49 @@
+package campaign
+
+import (
+	"context"
+
+	"github.com/Jason-Omondi/ecomgo/internal/campaign"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+)
+
+// Module provides flash sale campaigns
+// The catalog applies campaign prices through deps.Campaigns; this module manages them
+type Module struct {
+	handler *Handler
+	pricing *campaign.Pricing
+}
+
+func NewModule(deps module.Deps) *Module {
+	service := NewCampaignService(repository.NewCampaignRepository(deps.DB, deps.Log),
+		repository.NewProductRepository(deps.DB, deps.Log), repository.NewSegmentRepository(deps.DB, deps.Log),
+		deps.Campaigns, deps.Clock, deps.Log)
+
+	return &Module{
+		handler: NewHandler(service, deps.Campaigns, deps.Tokens, deps.Log),
+		pricing: deps.Campaigns,
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.Campaign{}, &models.CampaignItem{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+// Warm loads the price schedule so the first product reads don't wait on it
+func (m *Module) Warm(ctx context.Context) error {
+	return m.pricing.Warm(ctx)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
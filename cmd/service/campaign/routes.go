@@ -0,0 +1,233 @@
+package campaign
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/campaign"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *CampaignService
+	pricing *campaign.Pricing
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *CampaignService, pricing *campaign.Pricing, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		pricing: pricing,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers campaign routes
+// Running and upcoming campaigns are public, offers targeted at the caller's segments need
+// a sign-in; managing them is admin-only
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/campaigns", h.handlePublicList).Methods("GET")
+
+	mine := router.PathPrefix("/campaigns/mine").Subrouter()
+	mine.Use(auth.Authenticate(h.tokens))
+	mine.HandleFunc("", h.handleOffers).Methods("GET")
+
+	admin := router.PathPrefix("/admin/campaigns").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("", h.handleCreate).Methods("POST")
+	admin.HandleFunc("", h.handleList).Methods("GET")
+	admin.HandleFunc("/{id}", h.handleGet).Methods("GET")
+	admin.HandleFunc("/{id}", h.handleUpdate).Methods("PUT")
+	admin.HandleFunc("/{id}", h.handleDelete).Methods("DELETE")
+}
+
+// handlePublicList handles GET /api/v1/campaigns
+// @Summary Running and upcoming campaigns
+// @Description Flash sales that are running or scheduled, soonest to end first, with their sale prices. Served from the precomputed price schedule.
+// @Tags Campaigns
+// @Produce json
+// @Success 200 {array} models.PublicCampaign
+// @Failure 500 {string} string "Internal server error"
+// @Router /campaigns [get]
+func (h *Handler) handlePublicList(w http.ResponseWriter, r *http.Request) {
+	campaigns, err := h.pricing.Campaigns(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, campaigns)
+}
+
+// handleOffers handles GET /api/v1/campaigns/mine
+// @Summary Own offers
+// @Description Running and upcoming campaigns targeted at customer segments the caller belongs to, soonest to end first. Their prices apply to the caller only and don't show on product pages.
+// @Tags Campaigns
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.PublicCampaign
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /campaigns/mine [get]
+func (h *Handler) handleOffers(w http.ResponseWriter, r *http.Request) {
+	offers, err := h.service.Offers(r.Context(), auth.ClaimsFromContext(r.Context()).UserID())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, offers)
+}
+
+// handleCreate handles POST /api/v1/admin/campaigns
+// @Summary Create campaign
+// @Description Schedules a flash sale. Between starts_at and ends_at each item's price replaces its product's list price on product pages, listings and search. With segment_id the sale is a targeted offer: its prices apply to the segment's members only (see GET /campaigns/mine).
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CampaignRequest true "Campaign"
+// @Success 201 {object} models.Campaign
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/campaigns [post]
+func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req models.CampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	c, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, c)
+}
+
+// handleList handles GET /api/v1/admin/campaigns
+// @Summary List campaigns
+// @Tags Campaigns
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "scheduled, active or ended"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.CampaignListResponse
+// @Failure 400 {string} string "Invalid status"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/campaigns [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.CampaignScheduled, models.CampaignActive, models.CampaignEnded:
+	default:
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.List(r.Context(), status, limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleGet handles GET /api/v1/admin/campaigns/{id}
+// @Summary Get campaign
+// @Tags Campaigns
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} models.Campaign
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Campaign not found"
+// @Router /admin/campaigns/{id} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, c)
+}
+
+// handleUpdate handles PUT /api/v1/admin/campaigns/{id}
+// @Summary Update campaign
+// @Description Replaces the name, segment, window and items. Running campaigns can be changed; prices update at once.
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Param request body models.CampaignRequest true "Campaign"
+// @Success 200 {object} models.Campaign
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Campaign not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/campaigns/{id} [put]
+func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req models.CampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	c, err := h.service.Update(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, c)
+}
+
+// handleDelete handles DELETE /api/v1/admin/campaigns/{id}
+// @Summary Delete campaign
+// @Description Cancels a campaign. A running sale ends immediately.
+// @Tags Campaigns
+// @Security BearerAuth
+// @Param id path string true "Campaign ID"
+// @Success 204
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Campaign not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/campaigns/{id} [delete]
+func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError maps campaign errors to 400/404/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidCampaign):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrCampaignNotFound):
+		http.Error(w, "Campaign not found", http.StatusNotFound)
+	default:
+		h.log.Error("Campaign request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,206 @@
+package campaign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/campaign"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// maxItems keeps a campaign (and the price schedule every instance holds) bounded
+const maxItems = 1000
+
+// ErrInvalidCampaign wraps validation problems with a campaign request
+var ErrInvalidCampaign = errors.New("invalid campaign")
+
+// CampaignService manages flash sale campaigns
+// Every write invalidates the price schedule; starting and ending need no write at all
+type CampaignService struct {
+	repo     *repository.CampaignRepository
+	products repository.ProductStore
+	segments *repository.SegmentRepository
+	pricing  *campaign.Pricing
+	clock    clock.Clock
+	log      *zap.Logger
+}
+
+func NewCampaignService(repo *repository.CampaignRepository, products repository.ProductStore,
+	segments *repository.SegmentRepository, pricing *campaign.Pricing, clk clock.Clock, log *zap.Logger) *CampaignService {
+	return &CampaignService{
+		repo:     repo,
+		products: products,
+		segments: segments,
+		pricing:  pricing,
+		clock:    clk,
+		log:      log,
+	}
+}
+
+// Create validates req and schedules a campaign
+func (s *CampaignService) Create(ctx context.Context, req *models.CampaignRequest) (*models.Campaign, error) {
+	c := &models.Campaign{}
+	if err := s.apply(ctx, c, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, c); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Campaign created", zap.String("id", c.ID), zap.String("name", c.Name),
+		zap.Time("starts_at", c.StartsAt), zap.Time("ends_at", c.EndsAt), zap.Int("items", len(c.Items)))
+	s.invalidate(ctx)
+	c.SetStatus(s.clock.Now())
+	return c, nil
+}
+
+// Update replaces a campaign's name, segment, window and items
+// Running campaigns can be changed too, e.g. to extend a sale or drop a product that sold out
+func (s *CampaignService) Update(ctx context.Context, id string, req *models.CampaignRequest) (*models.Campaign, error) {
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, c, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Replace(ctx, c); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Campaign updated", zap.String("id", c.ID))
+	s.invalidate(ctx)
+	return s.Get(ctx, id)
+}
+
+// Delete cancels a campaign; if it is running its prices end at once
+func (s *CampaignService) Delete(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.log.Info("Campaign deleted", zap.String("id", id))
+	s.invalidate(ctx)
+	return nil
+}
+
+// Get returns a campaign with its items
+func (s *CampaignService) Get(ctx context.Context, id string) (*models.Campaign, error) {
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.SetStatus(s.clock.Now())
+	return c, nil
+}
+
+// List returns a page of campaigns, latest start first, optionally filtered by status
+func (s *CampaignService) List(ctx context.Context, status string, limit, offset int) (*models.CampaignListResponse, error) {
+	now := s.clock.Now()
+	campaigns, total, err := s.repo.List(ctx, status, now, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for i := range campaigns {
+		campaigns[i].SetStatus(now)
+	}
+	if campaigns == nil {
+		campaigns = []models.Campaign{}
+	}
+	return &models.CampaignListResponse{Campaigns: campaigns, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Offers returns the running and upcoming campaigns targeted at the segments userID is in
+func (s *CampaignService) Offers(ctx context.Context, userID string) ([]models.PublicCampaign, error) {
+	segmentIDs, err := s.segments.SegmentsOf(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(segmentIDs) == 0 {
+		return []models.PublicCampaign{}, nil
+	}
+	return s.pricing.Offers(ctx, segmentIDs)
+}
+
+// apply validates req and copies it onto c
+func (s *CampaignService) apply(ctx context.Context, c *models.Campaign, req *models.CampaignRequest) error {
+	name := strings.TrimSpace(req.Name)
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidCampaign)
+	case len(name) > 255:
+		return fmt.Errorf("%w: name must be at most 255 characters", ErrInvalidCampaign)
+	case req.StartsAt.IsZero() || req.EndsAt.IsZero():
+		return fmt.Errorf("%w: starts_at and ends_at are required", ErrInvalidCampaign)
+	case !req.EndsAt.After(req.StartsAt):
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidCampaign)
+	case !req.EndsAt.After(s.clock.Now()):
+		return fmt.Errorf("%w: ends_at must be in the future", ErrInvalidCampaign)
+	case len(req.Items) == 0:
+		return fmt.Errorf("%w: items are required", ErrInvalidCampaign)
+	case len(req.Items) > maxItems:
+		return fmt.Errorf("%w: at most %d items", ErrInvalidCampaign, maxItems)
+	}
+
+	items := make([]models.CampaignItem, 0, len(req.Items))
+	ids := make([]string, 0, len(req.Items))
+	seen := make(map[string]bool, len(req.Items))
+	for _, item := range req.Items {
+		productID := strings.TrimSpace(item.ProductID)
+		switch {
+		case productID == "":
+			return fmt.Errorf("%w: product_id is required", ErrInvalidCampaign)
+		case seen[productID]:
+			return fmt.Errorf("%w: product %s is listed twice", ErrInvalidCampaign, productID)
+		case item.Price < 0:
+			return fmt.Errorf("%w: price cannot be negative", ErrInvalidCampaign)
+		}
+		seen[productID] = true
+		ids = append(ids, productID)
+		items = append(items, models.CampaignItem{CampaignID: c.ID, ProductID: productID, Price: item.Price})
+	}
+
+	segmentID := strings.TrimSpace(req.SegmentID)
+	if segmentID != "" {
+		if _, err := s.segments.GetByID(ctx, segmentID); errors.Is(err, repository.ErrSegmentNotFound) {
+			return fmt.Errorf("%w: segment %s not found", ErrInvalidCampaign, segmentID)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	products, err := s.products.GetByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	found := make(map[string]bool, len(products))
+	for _, product := range products {
+		found[product.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return fmt.Errorf("%w: product %s not found", ErrInvalidCampaign, id)
+		}
+	}
+
+	c.Name = name
+	c.SegmentID = segmentID
+	c.StartsAt = req.StartsAt.UTC().Truncate(time.Millisecond)
+	c.EndsAt = req.EndsAt.UTC().Truncate(time.Millisecond)
+	c.Items = items
+	return nil
+}
+
+// invalidate drops the price schedule so the change applies at once
+// Failures are logged only; the schedule expires after its TTL anyway
+func (s *CampaignService) invalidate(ctx context.Context) {
+	if err := s.pricing.Invalidate(ctx); err != nil {
+		s.log.Warn("Failed to invalidate campaign prices", zap.Error(err))
+	}
+}
@@ -0,0 +1,40 @@
+package capacity
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Module lets operators inspect and tune concurrency limits at runtime:
+// API request admission, outbound HTTP calls and the job worker pool
+type Module struct {
+	handler *Handler
+}
+
+// NewModule subscribes every instance to capacity changes, so one PUT retunes the fleet
+func NewModule(deps module.Deps) *Module {
+	controller := NewController(deps.HTTPLimiter, httpclient.Limiter, deps.Jobs, deps.Events, deps.Log)
+	if err := deps.Events.SubscribeAll(events.TypeCapacityChanged, controller.HandleCapacityChanged); err != nil {
+		deps.Log.Error("Failed to subscribe to capacity changes", zap.Error(err))
+	}
+
+	return &Module{
+		handler: NewHandler(controller, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return nil
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
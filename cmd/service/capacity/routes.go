@@ -0,0 +1,80 @@
+package capacity
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	controller *Controller
+	tokens     *auth.TokenManager
+	log        *zap.Logger
+}
+
+func NewHandler(controller *Controller, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		controller: controller,
+		tokens:     tokens,
+		log:        log,
+	}
+}
+
+// RegisterRoutes registers capacity routes (admin only)
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/capacity").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+
+	admin.HandleFunc("", h.handleGet).Methods("GET")
+	admin.HandleFunc("", h.handleUpdate).Methods("PUT")
+}
+
+// handleGet handles GET /api/v1/admin/capacity
+// @Summary Get capacity limits
+// @Description Concurrency limits of the instance that served the request, with in-flight, queued and rejected counts
+// @Tags Capacity
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} capacity.Status
+// @Router /admin/capacity [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.controller.Status())
+}
+
+// handleUpdate handles PUT /api/v1/admin/capacity
+// @Summary Change capacity limits
+// @Description Applies new limits on every instance without a restart. They last until the instance restarts; set the env vars to keep them.
+// @Tags Capacity
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateCapacityRequest true "Limits to change"
+// @Success 200 {object} capacity.Status
+// @Failure 400 {string} string "Invalid request"
+// @Router /admin/capacity [put]
+func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateCapacityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.controller.Update(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidLimits) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.log.Error("Capacity update failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, status)
+}
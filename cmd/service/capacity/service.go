@@ -0,0 +1,129 @@
+package capacity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/limits"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidLimits wraps validation problems with a capacity update
+var ErrInvalidLimits = errors.New("invalid capacity limits")
+
+// Status is an instance's limits and how close it is to them
+type Status struct {
+	Limits      models.CapacityLimits  `json:"limits"`
+	JobsRunning int                    `json:"jobs_running"` // job_concurrency means every worker is busy
+	Limiters    []limits.Stats         `json:"limiters"`     // API requests and outbound calls
+	Upstreams   []httpclient.HostStats `json:"upstreams"`    // circuit state and retries per external host
+}
+
+// Controller reads and changes the concurrency limits of this instance
+// Changes go out as capacity.changed so every instance applies them
+type Controller struct {
+	http      *limits.Limiter
+	outbound  *limits.Limiter
+	jobs      *jobs.Processor
+	publisher events.Publisher
+	log       *zap.Logger
+}
+
+func NewController(httpLimiter, outbound *limits.Limiter, processor *jobs.Processor,
+	publisher events.Publisher, log *zap.Logger) *Controller {
+	return &Controller{
+		http:      httpLimiter,
+		outbound:  outbound,
+		jobs:      processor,
+		publisher: publisher,
+		log:       log,
+	}
+}
+
+// Limits returns the limits in effect on this instance
+func (c *Controller) Limits() models.CapacityLimits {
+	var l models.CapacityLimits
+	l.HTTPMaxInFlight, l.HTTPMaxQueued = c.http.Limits()
+	l.OutboundMaxInFlight, l.OutboundMaxQueued = c.outbound.Limits()
+	l.JobConcurrency = c.jobs.Concurrency()
+	return l
+}
+
+func (c *Controller) Status() *Status {
+	return &Status{
+		Limits:      c.Limits(),
+		JobsRunning: c.jobs.Running(),
+		Limiters:    limits.AllStats(),
+		Upstreams:   httpclient.AllHostStats(),
+	}
+}
+
+// Update validates req, applies it here and broadcasts it to the other instances
+// A lost broadcast leaves other instances on their old limits - it is logged and can be re-sent
+func (c *Controller) Update(ctx context.Context, req *models.UpdateCapacityRequest) (*Status, error) {
+	for name, v := range map[string]*int{
+		"http_max_in_flight":     req.HTTPMaxInFlight,
+		"http_max_queued":        req.HTTPMaxQueued,
+		"outbound_max_in_flight": req.OutboundMaxInFlight,
+		"outbound_max_queued":    req.OutboundMaxQueued,
+	} {
+		if v != nil && *v < 0 {
+			return nil, fmt.Errorf("%w: %s cannot be negative", ErrInvalidLimits, name)
+		}
+	}
+	if req.JobConcurrency != nil && *req.JobConcurrency < 1 {
+		return nil, fmt.Errorf("%w: job_concurrency must be at least 1", ErrInvalidLimits)
+	}
+
+	change := events.CapacityChanged{
+		HTTPMaxInFlight:     req.HTTPMaxInFlight,
+		HTTPMaxQueued:       req.HTTPMaxQueued,
+		OutboundMaxInFlight: req.OutboundMaxInFlight,
+		OutboundMaxQueued:   req.OutboundMaxQueued,
+		JobConcurrency:      req.JobConcurrency,
+	}
+	c.apply(change)
+	_ = events.Publish(ctx, c.publisher, c.log, events.TypeCapacityChanged, change)
+	return c.Status(), nil
+}
+
+// HandleCapacityChanged applies a change broadcast by any instance, this one included
+func (c *Controller) HandleCapacityChanged(ctx context.Context, event events.Event) error {
+	var change events.CapacityChanged
+	if err := event.Decode(&change); err != nil {
+		return err
+	}
+	c.apply(change)
+	return nil
+}
+
+// apply is idempotent, so receiving our own broadcast is harmless
+func (c *Controller) apply(change events.CapacityChanged) {
+	httpLimit, httpQueue := c.http.Limits()
+	c.http.SetLimits(valueOr(change.HTTPMaxInFlight, httpLimit), valueOr(change.HTTPMaxQueued, httpQueue))
+
+	outLimit, outQueue := c.outbound.Limits()
+	c.outbound.SetLimits(valueOr(change.OutboundMaxInFlight, outLimit), valueOr(change.OutboundMaxQueued, outQueue))
+
+	if change.JobConcurrency != nil {
+		c.jobs.SetConcurrency(*change.JobConcurrency)
+	}
+
+	l := c.Limits()
+	c.log.Info("Capacity limits applied",
+		zap.Int("http_max_in_flight", l.HTTPMaxInFlight), zap.Int("http_max_queued", l.HTTPMaxQueued),
+		zap.Int("outbound_max_in_flight", l.OutboundMaxInFlight), zap.Int("outbound_max_queued", l.OutboundMaxQueued),
+		zap.Int("job_concurrency", l.JobConcurrency))
+}
+
+func valueOr(v *int, fallback int) int {
+	if v == nil {
+		return fallback
+	}
+	return *v
+}
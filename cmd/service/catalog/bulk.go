@@ -0,0 +1,253 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/bulk"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Catalog bulk actions, run through internal/bulk on the job workers
+const (
+	BulkRepriceCategory = "reprice_category"
+	BulkArchiveProducts = "archive_products"
+)
+
+// bulkBatchSize is how many products a bulk action changes between progress reports
+const bulkBatchSize = 100
+
+// maxBulkProductIDs caps archive_products by ID; larger sets go by category
+const maxBulkProductIDs = 10000
+
+// RepriceParams are the parameters of reprice_category
+type RepriceParams struct {
+	Category  string `json:"category"`
+	ChangeBPS int64  `json:"change_bps"` // price change in basis points: 500 raises by 5%, -1000 cuts by 10%
+}
+
+// ArchiveParams are the parameters of archive_products: either product IDs or a category
+type ArchiveParams struct {
+	ProductIDs []string `json:"product_ids,omitempty"`
+	Category   string   `json:"category,omitempty"`
+}
+
+// bulkState is what a catalog bulk action has done so far; it is saved with each progress
+// report, so a retried run resumes after the last batch, and becomes the job's result
+type bulkState struct {
+	AfterID  string `json:"after_id,omitempty"` // last product handled when walking a category
+	Next     int    `json:"next,omitempty"`     // next index when working through product_ids
+	Changed  int    `json:"changed"`
+	Skipped  int    `json:"skipped"` // changed by someone else since the action was queued, gone, or already done
+	Category string `json:"category,omitempty"`
+}
+
+// BulkActions are the catalog's admin bulk actions
+// Products are changed one conditional UPDATE at a time, so a sale or an admin edit made
+// while the action runs is never overwritten, and a retried batch never applies twice
+type BulkActions struct {
+	service *CatalogService
+	repo    repository.ProductStore
+	log     *zap.Logger
+}
+
+func NewBulkActions(service *CatalogService, repo repository.ProductStore, log *zap.Logger) *BulkActions {
+	return &BulkActions{service: service, repo: repo, log: log}
+}
+
+// Register adds the catalog's actions to registry
+func (b *BulkActions) Register(registry *bulk.Registry) {
+	registry.Register(bulk.Action{
+		Name: BulkRepriceCategory,
+		Description: `Changes the price of every product in a category by a percentage: ` +
+			`{"category": "shoes", "change_bps": 500} raises prices by 5%. Products edited after the action was queued are skipped.`,
+		Validate: b.validateReprice,
+		Run:      b.reprice,
+	})
+	registry.Register(bulk.Action{
+		Name: BulkArchiveProducts,
+		Description: `Deactivates products so they leave the storefront, by ID or by category: ` +
+			`{"product_ids": ["..."]} or {"category": "discontinued"}.`,
+		Validate: b.validateArchive,
+		Run:      b.archive,
+	})
+}
+
+func (b *BulkActions) validateReprice(ctx context.Context, raw json.RawMessage) error {
+	var params RepriceParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return errors.New("params must be a JSON object")
+	}
+	if strings.TrimSpace(params.Category) == "" {
+		return errors.New("category is required")
+	}
+	if params.ChangeBPS == 0 {
+		return errors.New("change_bps must not be zero")
+	}
+	if params.ChangeBPS <= -10000 || params.ChangeBPS > 100000 {
+		return errors.New("change_bps must be greater than -10000 and at most 100000")
+	}
+	return nil
+}
+
+func (b *BulkActions) reprice(ctx context.Context, run *bulk.Run) (interface{}, error) {
+	var params RepriceParams
+	if err := run.Params(&params); err != nil {
+		return nil, err
+	}
+	state := bulkState{Category: params.Category}
+	if err := run.State(&state); err != nil {
+		return nil, err
+	}
+	total, err := b.repo.CountInCategory(ctx, params.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		products, err := b.repo.ListInCategoryAfter(ctx, params.Category, state.AfterID, bulkBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(products) == 0 {
+			return state, nil
+		}
+
+		var changed []string
+		for _, product := range products {
+			ok, err := b.repo.Reprice(ctx, product.ID, repriced(product.Price, params.ChangeBPS), run.QueuedAt)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				changed = append(changed, product.ID)
+			}
+		}
+		b.afterBatch(ctx, changed)
+
+		state.AfterID = products[len(products)-1].ID
+		state.Changed += len(changed)
+		state.Skipped += len(products) - len(changed)
+		done := state.Changed + state.Skipped
+		if err := run.Progress(ctx, done, max(int(total), done), state); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (b *BulkActions) validateArchive(ctx context.Context, raw json.RawMessage) error {
+	var params ArchiveParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return errors.New("params must be a JSON object")
+	}
+	hasCategory := strings.TrimSpace(params.Category) != ""
+	if hasCategory == (len(params.ProductIDs) > 0) {
+		return errors.New("exactly one of product_ids and category is required")
+	}
+	if len(params.ProductIDs) > maxBulkProductIDs {
+		return errors.New("too many product_ids; archive by category instead")
+	}
+	return nil
+}
+
+func (b *BulkActions) archive(ctx context.Context, run *bulk.Run) (interface{}, error) {
+	var params ArchiveParams
+	if err := run.Params(&params); err != nil {
+		return nil, err
+	}
+	state := bulkState{Category: params.Category}
+	if err := run.State(&state); err != nil {
+		return nil, err
+	}
+
+	// By ID: work through the list in order
+	if len(params.ProductIDs) > 0 {
+		for state.Next < len(params.ProductIDs) {
+			end := min(state.Next+bulkBatchSize, len(params.ProductIDs))
+			changed, err := b.deactivate(ctx, params.ProductIDs[state.Next:end])
+			if err != nil {
+				return nil, err
+			}
+			state.Changed += len(changed)
+			state.Skipped += end - state.Next - len(changed)
+			state.Next = end
+			if err := run.Progress(ctx, end, len(params.ProductIDs), state); err != nil {
+				return nil, err
+			}
+		}
+		return state, nil
+	}
+
+	// By category: walk it by ID
+	total, err := b.repo.CountInCategory(ctx, params.Category)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		products, err := b.repo.ListInCategoryAfter(ctx, params.Category, state.AfterID, bulkBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(products) == 0 {
+			return state, nil
+		}
+		ids := make([]string, len(products))
+		for i, product := range products {
+			ids[i] = product.ID
+		}
+		changed, err := b.deactivate(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		state.AfterID = ids[len(ids)-1]
+		state.Changed += len(changed)
+		state.Skipped += len(ids) - len(changed)
+		done := state.Changed + state.Skipped
+		if err := run.Progress(ctx, done, max(int(total), done), state); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// deactivate archives ids and returns those that were active before
+func (b *BulkActions) deactivate(ctx context.Context, ids []string) ([]string, error) {
+	var changed []string
+	for _, id := range ids {
+		ok, err := b.repo.Deactivate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			changed = append(changed, id)
+		}
+	}
+	b.afterBatch(ctx, changed)
+	return changed, nil
+}
+
+// afterBatch drops changed products from the cache and publishes product.updated for them,
+// like a single product edit does, so listings, search and stock alerts catch up
+func (b *BulkActions) afterBatch(ctx context.Context, changed []string) {
+	if len(changed) == 0 {
+		return
+	}
+	b.service.invalidate(ctx, changed...)
+	for _, id := range changed {
+		b.service.publishUpdated(ctx, id)
+	}
+}
+
+// repriced applies a change in basis points to price, rounding half up
+// Free products stay free, and priced ones never drop below one minor unit
+func repriced(price, changeBPS int64) int64 {
+	if price <= 0 {
+		return price
+	}
+	scaled := price * (10000 + changeBPS)
+	rounded := (scaled + 5000) / 10000
+	return max(rounded, 1)
+}
@@ -0,0 +1,235 @@
+package catalog
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrCartChanged is returned by Reprice when prices or availability changed since the shopper
+	// last saw the cart; it comes with the changes and a new quote
+	ErrCartChanged = errors.New("cart changed")
+	// ErrMixedCurrency is returned for carts holding products priced in different currencies
+	ErrMixedCurrency = errors.New("cart mixes currencies")
+)
+
+// quoteClaims is the signed content of a quote token: the unit prices it holds and until when
+type quoteClaims struct {
+	Currency  string           `json:"c"`
+	Prices    map[string]int64 `json:"p"`
+	ExpiresAt int64            `json:"e"` // unix seconds
+}
+
+// CartService prices carts and keeps quoted prices honest through checkout
+// A quote signs the prices the shopper saw; until it expires checkout charges no more than those
+// prices, even if a sale ended or a price went up meanwhile. Expired or missing quotes are re-priced, and any line
+// whose price or availability moved comes back as a change for the shopper to confirm.
+type CartService struct {
+	catalog *CatalogService
+	secret  []byte // signs quote tokens
+	ttl     time.Duration
+	clock   clock.Clock
+	log     *zap.Logger
+}
+
+func NewCartService(catalog *CatalogService, secret string, ttl time.Duration, clk clock.Clock, log *zap.Logger) *CartService {
+	return &CartService{
+		catalog: catalog,
+		secret:  []byte(secret),
+		ttl:     ttl,
+		clock:   clk,
+		log:     log,
+	}
+}
+
+// CartChanges carries the changes and the new quote of ErrCartChanged
+type CartChanges struct {
+	Response models.CartChangedResponse
+}
+
+func (c *CartChanges) Error() string {
+	return fmt.Sprintf("%s: %d lines changed", ErrCartChanged, len(c.Response.Changes))
+}
+
+func (c *CartChanges) Unwrap() error {
+	return ErrCartChanged
+}
+
+// Reprice quotes a cart at current prices on channelCode, honoring req.QuoteToken while it is valid
+// Storefronts call it (POST /cart/quote) when the cart is shown; checkout calls it before charging
+// and charges the returned quote. When a line's price differs from the unit_price the shopper saw,
+// or a product can't be sold any more, it fails with *CartChanges (errors.Is ErrCartChanged).
+func (s *CartService) Reprice(ctx context.Context, channelCode string, req *models.CartQuoteRequest) (*models.CartQuote, error) {
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("%w: the cart is empty", ErrQuantityNotAllowed)
+	}
+
+	now := s.clock.Now()
+	held, expired := s.parseToken(req.QuoteToken, now)
+
+	// Lines of the same product were checked together; the shopper saw one price per product
+	seen := make(map[string]int64, len(req.Items))
+	quantities := make(map[string]int, len(req.Items))
+	var order []string
+	for _, line := range req.Items {
+		if line.ProductID == "" || line.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: every line needs a product_id and a positive quantity", ErrQuantityNotAllowed)
+		}
+		if _, ok := quantities[line.ProductID]; !ok {
+			order = append(order, line.ProductID)
+		}
+		quantities[line.ProductID] += line.Quantity
+		if line.UnitPrice > 0 {
+			seen[line.ProductID] = line.UnitPrice
+		}
+	}
+
+	// GetProduct prices every line for the caller's group, so the quote is in that group's terms
+	group := s.catalog.groups.ForUser(ctx, callerID(ctx))
+	quote := &models.CartQuote{
+		Items:         make([]models.CartQuoteLine, 0, len(order)),
+		CustomerGroup: group.Group,
+		TaxTreatment:  group.TaxTreatment,
+	}
+	bases := make(map[string]int64, len(order)) // unit prices before price tiers, held by the token
+	var changes []models.CartChange
+	for _, id := range order {
+		product, err := s.catalog.GetProduct(ctx, channelCode, id)
+		if errors.Is(err, repository.ErrProductNotFound) || (err == nil && !product.Active) {
+			changes = append(changes, models.CartChange{ProductID: id, Reason: models.CartUnavailable, PreviousPrice: seen[id]})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if product.Restricted {
+			changes = append(changes, models.CartChange{ProductID: id, Reason: models.CartRestricted, PreviousPrice: seen[id]})
+			continue
+		}
+
+		switch {
+		case quote.Currency == "":
+			quote.Currency = product.Currency
+		case quote.Currency != product.Currency:
+			return nil, fmt.Errorf("%w: %s and %s", ErrMixedCurrency, quote.Currency, product.Currency)
+		}
+
+		// A held price only protects the shopper from increases; a cheaper current price wins
+		base, sale := product.Price, product.Sale
+		if heldPrice, ok := held.Prices[id]; ok && held.Currency == product.Currency && heldPrice < base {
+			base, sale = heldPrice, nil
+		}
+		bases[id] = base
+
+		// Quantity breaks apply on top, so a held price keeps its tier discount as quantities change
+		quantity := quantities[id]
+		price := base
+		tier := product.TierFor(quantity)
+		if tier != nil {
+			price = tier.Discount(base)
+			tier.UnitPrice = price
+		}
+		if previous, ok := seen[id]; ok && previous != price {
+			changes = append(changes, models.CartChange{
+				ProductID:     id,
+				Reason:        models.CartPriceChanged,
+				PreviousPrice: previous,
+				CurrentPrice:  price,
+			})
+		}
+
+		quote.Items = append(quote.Items, models.CartQuoteLine{
+			ProductID: id,
+			SKU:       product.SKU,
+			Name:      product.Name,
+			Quantity:  quantity,
+			UnitPrice: price,
+			LineTotal: price * int64(quantity),
+			Sale:      sale,
+			Tier:      tier,
+		})
+		quote.Subtotal += price * int64(quantity)
+	}
+
+	// Quantity rules apply to what can still be bought; unavailable lines are already reported
+	if len(quote.Items) > 0 {
+		lines := make([]models.CartLine, 0, len(quote.Items))
+		for _, line := range quote.Items {
+			lines = append(lines, models.CartLine{ProductID: line.ProductID, Quantity: line.Quantity})
+		}
+		if err := s.catalog.ValidateCart(ctx, lines); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.sign(quote, bases, held, now); err != nil {
+		return nil, err
+	}
+	if len(changes) > 0 {
+		s.log.Info("Cart changed since it was quoted", zap.Int("changes", len(changes)), zap.Bool("expired", expired))
+		return nil, &CartChanges{Response: models.CartChangedResponse{
+			Error:   "cart_changed",
+			Expired: expired,
+			Changes: changes,
+			Quote:   quote,
+		}}
+	}
+	return quote, nil
+}
+
+// sign issues the quote's token for the pre-tier unit prices in bases
+// Prices honored from a still-valid token keep that token's expiry, so re-quoting never extends them
+func (s *CartService) sign(quote *models.CartQuote, bases map[string]int64, held quoteClaims, now time.Time) error {
+	claims := quoteClaims{Currency: quote.Currency, Prices: make(map[string]int64, len(quote.Items)), ExpiresAt: now.Add(s.ttl).Unix()}
+	for _, line := range quote.Items {
+		base := bases[line.ProductID]
+		claims.Prices[line.ProductID] = base
+		if heldPrice, ok := held.Prices[line.ProductID]; ok && heldPrice == base && held.ExpiresAt < claims.ExpiresAt {
+			claims.ExpiresAt = held.ExpiresAt
+		}
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	quote.Token = encoded + "." + s.mac(encoded)
+	quote.ExpiresAt = time.Unix(claims.ExpiresAt, 0).UTC()
+	return nil
+}
+
+// parseToken returns the prices held by a valid, unexpired token
+// Returns: expired is true for a genuine token past its expiry; forged or malformed tokens hold nothing
+func (s *CartService) parseToken(token string, now time.Time) (claims quoteClaims, expired bool) {
+	encoded, mac, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(mac), []byte(s.mac(encoded))) {
+		return quoteClaims{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(payload, &claims) != nil {
+		return quoteClaims{}, false
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return quoteClaims{}, true
+	}
+	return claims, false
+}
+
+func (s *CartService) mac(value string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("cart-quote:" + value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
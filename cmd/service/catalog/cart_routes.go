@@ -0,0 +1,110 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/channel"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type CartHandler struct {
+	service *CartService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewCartHandler(service *CartService, tokens *auth.TokenManager, log *zap.Logger) *CartHandler {
+	return &CartHandler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers the public cart checks storefronts run before checkout
+// Quotes are priced for signed-in callers' customer segments when a token is sent
+func (h *CartHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/cart/validate", h.handleValidate).Methods("POST")
+	router.Handle("/cart/quote", auth.Identify(h.tokens)(http.HandlerFunc(h.handleQuote))).Methods("POST")
+}
+
+// handleValidate handles POST /api/v1/cart/validate
+// @Summary Validate cart quantities
+// @Description Checks cart lines against each product's min_quantity, max_quantity and quantity_step and the store's max_order_quantity and max_order_lines settings. Storefronts call it when a line is added or changed; checkout applies the same rules.
+// @Tags Cart
+// @Accept json
+// @Param request body models.CartValidationRequest true "Cart"
+// @Success 204 "Cart is valid"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Product not found"
+// @Failure 422 {string} string "Quantity not allowed"
+// @Router /cart/validate [post]
+func (h *CartHandler) handleValidate(w http.ResponseWriter, r *http.Request) {
+	var req models.CartValidationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.catalog.ValidateCart(r.Context(), req.Items); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleQuote handles POST /api/v1/cart/quote
+// @Summary Quote cart
+// @Description Prices the cart on the caller's channel, with quantity-break price tiers (the caller's segment tiers when signed in). Send the unit_price each line was shown at and the quote_token of the last quote: prices of an unexpired quote are honored, and lines whose price or availability changed since are answered with 409 and a re-priced quote to confirm.
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param X-Channel header string false "Sales channel (default web)"
+// @Param request body models.CartQuoteRequest true "Cart"
+// @Success 200 {object} models.CartQuote
+// @Failure 400 {string} string "Invalid request"
+// @Failure 409 {object} models.CartChangedResponse
+// @Failure 422 {string} string "Quantity not allowed"
+// @Router /cart/quote [post]
+func (h *CartHandler) handleQuote(w http.ResponseWriter, r *http.Request) {
+	var req models.CartQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	quote, err := h.service.Reprice(r.Context(), channel.FromRequest(r), &req)
+	var changed *CartChanges
+	if errors.As(err, &changed) {
+		response.JSON(w, http.StatusConflict, changed.Response)
+		return
+	}
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, quote)
+}
+
+// writeError maps cart errors to HTTP status codes
+func (h *CartHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrQuantityNotAllowed), errors.Is(err, ErrMixedCurrency):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, channel.ErrUnknownChannel):
+		http.Error(w, "Unknown channel", http.StatusBadRequest)
+	case errors.Is(err, repository.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	default:
+		h.log.Error("Cart request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,370 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/inventory"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/ordernumber"
+	"github.com/Jason-Omondi/ecomgo/internal/payment"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/saga"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// sagaCheckout is the saga type of checkouts
+	sagaCheckout = "checkout"
+	// maxCheckoutKey bounds the Idempotency-Key header
+	maxCheckoutKey = 128
+	// recoveryBatch is how many checkouts one recovery pass resumes at most
+	recoveryBatch = 100
+)
+
+var (
+	// ErrInvalidCheckout wraps problems with a checkout request
+	ErrInvalidCheckout = errors.New("invalid checkout")
+	// ErrCheckoutNotFound is returned for checkouts that don't exist or aren't the caller's
+	ErrCheckoutNotFound = errors.New("checkout not found")
+)
+
+// checkoutState is the checkout saga's data, persisted after every step
+type checkoutState struct {
+	UserID        string             `json:"user_id"`
+	OrderID       string             `json:"order_id"`
+	Channel       string             `json:"channel"`
+	Items         []events.OrderItem `json:"items"`
+	Total         int64              `json:"total"`
+	Currency      string             `json:"currency"`
+	TaxTreatment  string             `json:"tax_treatment,omitempty"`
+	Allocation    *models.Allocation `json:"allocation"`
+	PaymentMethod string             `json:"payment_method,omitempty"` // dropped once captured
+	PaymentID     string             `json:"payment_id,omitempty"`
+	Provider      string             `json:"provider,omitempty"`
+	OrderNumber   string             `json:"order_number,omitempty"`
+	PlacedAt      time.Time          `json:"placed_at"`
+	Reason        string             `json:"reason,omitempty"` // set when a step fails
+}
+
+// CheckoutService turns a quoted cart into a paid order
+// The cart is re-priced and checked, then a saga reserves the stock, captures the payment and
+// confirms the order, publishing order.placed and payment.captured. If a step fails for good the
+// completed ones are undone: the capture refunded, the stock put back. Steps that fail
+// transiently are retried by CheckoutRecovery, which also resumes checkouts of crashed instances.
+type CheckoutService struct {
+	catalog   *CatalogService
+	carts     *CartService
+	addresses *repository.AddressRepository
+	inventory *inventory.Allocator
+	payments  payment.Provider
+	numbers   *ordernumber.Generator
+	publisher events.Publisher
+	saga      *saga.Orchestrator[checkoutState]
+	ids       clock.IDGenerator
+	clock     clock.Clock
+	log       *zap.Logger
+}
+
+func NewCheckoutService(catalog *CatalogService, carts *CartService, addresses *repository.AddressRepository,
+	allocator *inventory.Allocator, payments payment.Provider, numbers *ordernumber.Generator, publisher events.Publisher,
+	sagas *repository.SagaRepository, cfg config.Checkout, ids clock.IDGenerator, clk clock.Clock,
+	log *zap.Logger) *CheckoutService {
+	s := &CheckoutService{
+		catalog:   catalog,
+		carts:     carts,
+		addresses: addresses,
+		inventory: allocator,
+		payments:  payments,
+		numbers:   numbers,
+		publisher: publisher,
+		ids:       ids,
+		clock:     clk,
+		log:       log,
+	}
+	s.saga = saga.New(sagaCheckout, []saga.Step[checkoutState]{
+		{Name: "reserve_stock", Local: s.reserveStock, CompensateLocal: s.releaseStock},
+		{Name: "capture_payment", Action: s.capturePayment, Compensate: s.refundPayment},
+		{Name: "confirm_order", Local: s.confirmOrder},
+		{Name: "publish_events", Action: s.publishEvents},
+	}, sagas, ids, clk, cfg.MaxAttempts, cfg.RetryBackoff, log)
+	return s
+}
+
+// Checkout re-prices userID's cart on channelCode and pays for it
+// key (the Idempotency-Key header) makes retries of one checkout return it instead of paying twice
+// Returns: *CartChanges when the cart changed since the quote, ErrInsufficientStock when the
+// warehouses can't cover it; a payment failure comes back as a failed checkout, not an error
+func (s *CheckoutService) Checkout(ctx context.Context, userID, channelCode, key string,
+	req *models.CheckoutRequest) (*models.Checkout, error) {
+	key = strings.TrimSpace(key)
+	switch {
+	case len(key) > maxCheckoutKey:
+		return nil, fmt.Errorf("%w: Idempotency-Key must be at most %d characters", ErrInvalidCheckout, maxCheckoutKey)
+	case req.AddressID == "":
+		return nil, fmt.Errorf("%w: address_id is required", ErrInvalidCheckout)
+	case strings.TrimSpace(req.PaymentMethod) == "":
+		return nil, fmt.Errorf("%w: payment_method is required", ErrInvalidCheckout)
+	}
+	if key != "" {
+		// Keys are the caller's own; another customer's key never finds this checkout.
+		// A retried checkout is answered before its cart is checked again, since the stock
+		// and prices it already paid for may have moved since
+		key = userID + ":" + key
+		record, err := s.saga.ResumeKey(ctx, key)
+		if err == nil {
+			return s.view(record, userID)
+		}
+		if !errors.Is(err, repository.ErrSagaNotFound) {
+			return nil, err
+		}
+	}
+
+	address, err := s.addresses.GetByID(ctx, req.AddressID, userID)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := s.carts.Reprice(ctx, channelCode, &models.CartQuoteRequest{Items: req.Items, QuoteToken: req.QuoteToken})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]events.OrderItem, 0, len(quote.Items))
+	wanted := make([]models.AllocationItem, 0, len(quote.Items))
+	for _, line := range quote.Items {
+		if err := s.catalog.CheckSellable(ctx, line.ProductID, address.Country); err != nil {
+			return nil, err
+		}
+		items = append(items, events.OrderItem{ProductID: line.ProductID, Quantity: line.Quantity, UnitPrice: line.UnitPrice})
+		wanted = append(wanted, models.AllocationItem{ProductID: line.ProductID, Quantity: line.Quantity})
+	}
+	allocation, err := s.inventory.Allocate(ctx, *address, wanted, "")
+	if err != nil {
+		return nil, err
+	}
+
+	state := &checkoutState{
+		UserID:        userID,
+		OrderID:       s.ids.NewID(),
+		Channel:       channelCode,
+		Items:         items,
+		Total:         quote.Subtotal,
+		Currency:      quote.Currency,
+		TaxTreatment:  quote.TaxTreatment,
+		Allocation:    allocation,
+		PaymentMethod: strings.TrimSpace(req.PaymentMethod),
+	}
+	record, err := s.saga.Start(ctx, key, state)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(record, userID)
+}
+
+// Get returns one of userID's checkouts
+func (s *CheckoutService) Get(ctx context.Context, userID, id string) (*models.Checkout, error) {
+	record, err := s.saga.Get(ctx, id)
+	if errors.Is(err, repository.ErrSagaNotFound) {
+		return nil, ErrCheckoutNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.view(record, userID)
+}
+
+// Recover resumes checkouts waiting for a retry and those left behind by crashed instances
+func (s *CheckoutService) Recover(ctx context.Context) (int, error) {
+	return s.saga.Recover(ctx, recoveryBatch)
+}
+
+// view is what the customer sees of a checkout saga
+func (s *CheckoutService) view(record *models.Saga, userID string) (*models.Checkout, error) {
+	state, err := saga.Decode[checkoutState](record)
+	if err != nil {
+		return nil, err
+	}
+	if state.UserID != userID {
+		return nil, ErrCheckoutNotFound
+	}
+
+	checkout := &models.Checkout{
+		ID:        record.ID,
+		OrderID:   state.OrderID,
+		Total:     state.Total,
+		Currency:  state.Currency,
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+	}
+	switch record.Status {
+	case models.SagaCompleted:
+		checkout.Status = models.CheckoutCompleted
+		checkout.OrderNumber = state.OrderNumber
+	case models.SagaCompensated, models.SagaFailed:
+		checkout.Status = models.CheckoutFailed
+		checkout.Reason = state.Reason
+	default:
+		checkout.Status = models.CheckoutProcessing
+	}
+	return checkout, nil
+}
+
+// reserveStock takes the allocation out of the warehouses with the step's record
+func (s *CheckoutService) reserveStock(ctx context.Context, tx *gorm.DB, state *checkoutState) error {
+	err := s.inventory.Commit(ctx, tx, state.Allocation)
+	if errors.Is(err, inventory.ErrInsufficientStock) {
+		// Sold since the allocation was planned
+		state.Reason = models.CheckoutOutOfStock
+		return saga.Abort(err)
+	}
+	return err
+}
+
+func (s *CheckoutService) releaseStock(ctx context.Context, tx *gorm.DB, state *checkoutState) error {
+	return s.inventory.Release(ctx, tx, state.Allocation)
+}
+
+// capturePayment charges the order total; the order ID keys the capture, so repeating the
+// step after a crash returns the original payment instead of charging twice
+func (s *CheckoutService) capturePayment(ctx context.Context, state *checkoutState) error {
+	if state.PaymentID != "" {
+		return nil
+	}
+	captured, err := s.payments.Capture(ctx, payment.CaptureRequest{
+		IdempotencyKey: "checkout:" + state.OrderID,
+		OrderID:        state.OrderID,
+		Amount:         state.Total,
+		Currency:       state.Currency,
+		Method:         state.PaymentMethod,
+	})
+	switch {
+	case errors.Is(err, payment.ErrDeclined), errors.Is(err, payment.ErrIdempotencyConflict):
+		state.Reason = models.CheckoutPaymentDeclined
+		return saga.Abort(err)
+	case err != nil:
+		state.Reason = models.CheckoutPaymentFailed
+		return err
+	}
+	state.PaymentID = captured.ID
+	state.Provider = s.payments.Name()
+	state.PaymentMethod = ""
+	state.Reason = ""
+	return nil
+}
+
+// refundPayment refunds the capture in full
+func (s *CheckoutService) refundPayment(ctx context.Context, state *checkoutState) error {
+	if state.PaymentID == "" {
+		// The provider kept failing, so whether the last attempt charged is unknown; its own
+		// records and webhooks settle that
+		s.log.Warn("Compensating checkout without a payment ID", zap.String("order_id", state.OrderID))
+		return nil
+	}
+	_, err := s.payments.Refund(ctx, payment.RefundRequest{
+		IdempotencyKey: "checkout:" + state.OrderID + ":refund",
+		PaymentID:      state.PaymentID,
+		Amount:         state.Total,
+		Reason:         "checkout failed",
+	})
+	return err
+}
+
+// confirmOrder numbers the order; nothing after it can fail for good
+func (s *CheckoutService) confirmOrder(ctx context.Context, tx *gorm.DB, state *checkoutState) error {
+	number, err := s.numbers.NextTx(ctx, tx)
+	if err != nil {
+		return err
+	}
+	state.OrderNumber = number
+	state.PlacedAt = s.clock.Now().UTC()
+	return nil
+}
+
+// publishEvents announces the order and its payment
+// Event IDs derive from the order, so events published again after a crash are duplicates
+// that consumers recognize, not new orders
+func (s *CheckoutService) publishEvents(ctx context.Context, state *checkoutState) error {
+	placed := events.OrderPlaced{
+		OrderID:      state.OrderID,
+		OrderNumber:  state.OrderNumber,
+		UserID:       state.UserID,
+		Total:        state.Total,
+		Currency:     state.Currency,
+		Channel:      state.Channel,
+		Items:        state.Items,
+		TaxTreatment: state.TaxTreatment,
+	}
+	captured := events.PaymentCaptured{
+		PaymentID:   state.PaymentID,
+		OrderID:     state.OrderID,
+		OrderNumber: state.OrderNumber,
+		UserID:      state.UserID,
+		Provider:    state.Provider,
+		Amount:      state.Total,
+		Currency:    state.Currency,
+	}
+	for _, payload := range []struct {
+		eventType string
+		data      interface{}
+	}{
+		{events.TypeOrderPlaced, placed},
+		{events.TypePaymentCaptured, captured},
+	} {
+		event, err := events.NewEvent(payload.eventType, payload.data)
+		if err != nil {
+			return err
+		}
+		event.ID = uuid.NewSHA1(uuid.NameSpaceURL, []byte("checkout:"+state.OrderID+":"+payload.eventType)).String()
+		event.OccurredAt = state.PlacedAt
+		if err := s.publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	s.log.Info("Checkout completed", zap.String("order_id", state.OrderID), zap.String("order_number", state.OrderNumber))
+	return nil
+}
+
+// CheckoutRecovery resumes checkouts every CHECKOUT_RECOVERY_INTERVAL: steps waiting for a
+// retry, and checkouts whose instance crashed mid-saga once their lease runs out
+// It runs on the elected leader only; leases would keep concurrent runs apart anyway
+type CheckoutRecovery struct {
+	service  *CheckoutService
+	interval time.Duration
+	log      *zap.Logger
+}
+
+func NewCheckoutRecovery(service *CheckoutService, interval time.Duration, log *zap.Logger) *CheckoutRecovery {
+	return &CheckoutRecovery{service: service, interval: interval, log: log}
+}
+
+func (r *CheckoutRecovery) Name() string {
+	return "checkout-recovery"
+}
+
+// Run resumes due checkouts until ctx is cancelled
+func (r *CheckoutRecovery) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			resumed, err := r.service.Recover(ctx)
+			if err != nil {
+				r.log.Error("Checkout recovery failed", zap.Error(err))
+			} else if resumed > 0 {
+				r.log.Info("Resumed checkouts", zap.Int("checkouts", resumed))
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/channel"
+	"github.com/Jason-Omondi/ecomgo/internal/inventory"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type CheckoutHandler struct {
+	service *CheckoutService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewCheckoutHandler(service *CheckoutService, tokens *auth.TokenManager, log *zap.Logger) *CheckoutHandler {
+	return &CheckoutHandler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers checkout; checkouts are the signed-in customer's own
+func (h *CheckoutHandler) RegisterRoutes(router *mux.Router) {
+	checkout := router.PathPrefix("/checkout").Subrouter()
+	checkout.Use(auth.Authenticate(h.tokens))
+	checkout.HandleFunc("", h.handleCheckout).Methods("POST")
+	checkout.HandleFunc("/{id}", h.handleGet).Methods("GET")
+}
+
+// handleCheckout handles POST /api/v1/checkout
+// @Summary Check out
+// @Description Re-prices the cart like POST /cart/quote, then reserves the stock, captures the payment and confirms the order. A step that fails for good undoes the ones before it (the charge is refunded, the stock put back) and the checkout fails with a reason. A step that fails transiently is retried in the background; the checkout is then answered with 202 and polled with GET /checkout/{id}. Send an Idempotency-Key to make retries of one checkout return it instead of paying twice.
+// @Tags Checkout
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Channel header string false "Sales channel (default web)"
+// @Param Idempotency-Key header string false "Client key identifying this checkout"
+// @Param request body models.CheckoutRequest true "Cart, address and payment method"
+// @Success 201 {object} models.Checkout "Paid and ordered"
+// @Success 202 {object} models.Checkout "Processing"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 402 {object} models.Checkout "Payment declined"
+// @Failure 404 {string} string "Address or product not found"
+// @Failure 409 {object} models.CartChangedResponse "Cart changed, or out of stock"
+// @Failure 422 {string} string "Quantity not allowed or product not sold to the address"
+// @Router /checkout [post]
+func (h *CheckoutHandler) handleCheckout(w http.ResponseWriter, r *http.Request) {
+	var req models.CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	checkout, err := h.service.Checkout(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), channel.FromRequest(r),
+		r.Header.Get("Idempotency-Key"), &req)
+	var changed *CartChanges
+	if errors.As(err, &changed) {
+		response.JSON(w, http.StatusConflict, changed.Response)
+		return
+	}
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	status := http.StatusCreated
+	switch {
+	case checkout.Status == models.CheckoutProcessing:
+		status = http.StatusAccepted
+	case checkout.Reason == models.CheckoutOutOfStock:
+		status = http.StatusConflict
+	case checkout.Status == models.CheckoutFailed:
+		status = http.StatusPaymentRequired
+	}
+	response.JSON(w, status, checkout)
+}
+
+// handleGet handles GET /api/v1/checkout/{id}
+// @Summary Get checkout
+// @Description Returns where one of the caller's checkouts stands: processing, completed (with the order number) or failed (with the reason)
+// @Tags Checkout
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Checkout ID"
+// @Success 200 {object} models.Checkout
+// @Failure 404 {string} string "Checkout not found"
+// @Router /checkout/{id} [get]
+func (h *CheckoutHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	checkout, err := h.service.Get(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, checkout)
+}
+
+// writeError maps checkout errors to HTTP status codes
+func (h *CheckoutHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidCheckout):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrQuantityNotAllowed), errors.Is(err, ErrMixedCurrency), errors.Is(err, ErrRestrictedCountry):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, inventory.ErrInsufficientStock):
+		http.Error(w, "Not enough stock to ship the cart", http.StatusConflict)
+	case errors.Is(err, channel.ErrUnknownChannel):
+		http.Error(w, "Unknown channel", http.StatusBadRequest)
+	case errors.Is(err, ErrCheckoutNotFound):
+		http.Error(w, "Checkout not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrAddressNotFound):
+		http.Error(w, "Address not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	default:
+		h.log.Error("Checkout request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
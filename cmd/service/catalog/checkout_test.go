@@ -0,0 +1,322 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/inventory"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/ordernumber"
+	"github.com/Jason-Omondi/ecomgo/internal/payment"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	checkoutAttempts = 2
+	checkoutBackoff  = time.Minute
+	// checkoutLease is how long a crashed instance keeps its checkouts, see saga
+	checkoutLease = 2 * time.Minute
+
+	warehouseID = "0b7f9a8e-0000-4000-8000-000000000001"
+	productID   = "0b7f9a8e-0000-4000-8000-000000000002"
+	inStock     = 10
+)
+
+type prefix string
+
+func (p prefix) OrderNumberPrefix(context.Context) string { return string(p) }
+
+// recordingProvider is the sandbox provider, keeping the payments it captured and refunded
+// crash makes the next capture stop the instance dead once the sandbox has charged
+type recordingProvider struct {
+	*payment.Sandbox
+	db *gorm.DB
+
+	mu       sync.Mutex
+	captures []string // payment IDs returned
+	refunds  []string
+	// stockAtRefund is the stock left when the refund was made, to tell compensation order
+	stockAtRefund int
+	crash         bool
+}
+
+func (p *recordingProvider) Capture(ctx context.Context, req payment.CaptureRequest) (*payment.Payment, error) {
+	captured, err := p.Sandbox.Capture(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.captures = append(p.captures, captured.ID)
+	if p.crash {
+		p.crash = false
+		panic("instance crashed")
+	}
+	return captured, nil
+}
+
+func (p *recordingProvider) Refund(ctx context.Context, req payment.RefundRequest) (*payment.Refund, error) {
+	refund, err := p.Sandbox.Refund(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.refunds = append(p.refunds, req.PaymentID)
+	var product models.Product
+	if err := p.db.Where("id = ?", productID).First(&product).Error; err != nil {
+		return nil, err
+	}
+	p.stockAtRefund = product.Stock
+	return refund, nil
+}
+
+// recordingPublisher keeps published events; fail makes it fail instead
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []events.Event
+	fail   error
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event events.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail != nil {
+		return p.fail
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+type checkouts struct {
+	service   *CheckoutService
+	db        *gorm.DB
+	payments  *recordingProvider
+	publisher *recordingPublisher
+	clock     *clock.Fake
+}
+
+// newCheckouts returns a checkout service over one warehouse holding inStock of the product
+// The steps before the saga (re-pricing the cart, planning the allocation) aren't wired;
+// tests start checkouts with start
+func newCheckouts(t *testing.T) *checkouts {
+	t.Helper()
+	db := testutil.NewDB(t, &models.Product{}, &models.WarehouseStock{}, &models.OrderSequence{}, &models.Saga{})
+	product := &models.Product{ID: productID, SKU: "TEA-1", Name: "Tea", Price: 500, Currency: "KES", Stock: inStock, Active: true}
+	if err := db.Create(product).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&models.WarehouseStock{WarehouseID: warehouseID, ProductID: productID, Quantity: inStock}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	clk := clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	allocator, err := inventory.NewAllocator(repository.NewWarehouseRepository(db, zap.NewNop()), nil,
+		config.Inventory{Allocation: models.AllocateNearest}, clk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numbers, err := ordernumber.NewGenerator(repository.NewOrderSequenceRepository(db, zap.NewNop()), prefix("ORD-"), clk,
+		config.Orders{NumberReset: ordernumber.ResetYearly, NumberDigits: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &checkouts{
+		db:        db,
+		payments:  &recordingProvider{Sandbox: payment.NewSandbox("webhook-secret"), db: db},
+		publisher: &recordingPublisher{},
+		clock:     clk,
+	}
+	c.service = NewCheckoutService(nil, nil, nil, allocator, c.payments, numbers, c.publisher,
+		repository.NewSagaRepository(db, zap.NewNop()),
+		config.Checkout{MaxAttempts: checkoutAttempts, RetryBackoff: checkoutBackoff},
+		clock.NewSequence("checkout"), clk, zap.NewNop())
+	return c
+}
+
+// start runs a checkout of two units paid with method, as Checkout does once the cart and
+// the allocation are settled
+func (c *checkouts) start(t *testing.T, key, method string) *models.Checkout {
+	t.Helper()
+	state := &checkoutState{
+		UserID:   "user-1",
+		OrderID:  "order-1",
+		Channel:  "web",
+		Items:    []events.OrderItem{{ProductID: productID, Quantity: 2, UnitPrice: 500}},
+		Total:    1000,
+		Currency: "KES",
+		Allocation: &models.Allocation{Rule: models.AllocateNearest, Shipments: []models.AllocationShipment{{
+			WarehouseID:   warehouseID,
+			WarehouseCode: "NBO-1",
+			Items:         []models.AllocationItem{{ProductID: productID, Quantity: 2}},
+		}}},
+		PaymentMethod: method,
+	}
+	record, err := c.service.saga.Start(context.Background(), "user-1:"+key, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkout, err := c.service.view(record, "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return checkout
+}
+
+func (c *checkouts) get(t *testing.T, id string) *models.Checkout {
+	t.Helper()
+	checkout, err := c.service.Get(context.Background(), "user-1", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return checkout
+}
+
+func (c *checkouts) recover(t *testing.T) int {
+	t.Helper()
+	resumed, err := c.service.Recover(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resumed
+}
+
+// wantStock checks the warehouse and the product total both hold want
+func (c *checkouts) wantStock(t *testing.T, want int) {
+	t.Helper()
+	var product models.Product
+	if err := c.db.Where("id = ?", productID).First(&product).Error; err != nil {
+		t.Fatal(err)
+	}
+	var stock models.WarehouseStock
+	if err := c.db.Where("warehouse_id = ? AND product_id = ?", warehouseID, productID).First(&stock).Error; err != nil {
+		t.Fatal(err)
+	}
+	if product.Stock != want || stock.Quantity != want {
+		t.Fatalf("stock = %d (warehouse %d), want %d", product.Stock, stock.Quantity, want)
+	}
+}
+
+func TestCheckoutCompletes(t *testing.T) {
+	c := newCheckouts(t)
+	checkout := c.start(t, "key-1", "card")
+
+	if checkout.Status != models.CheckoutCompleted || checkout.OrderNumber != "ORD-2026-000001" {
+		t.Fatalf("checkout = %+v", checkout)
+	}
+	c.wantStock(t, inStock-2)
+	if len(c.payments.captures) != 1 || len(c.payments.refunds) != 0 {
+		t.Fatalf("captured %v, refunded %v", c.payments.captures, c.payments.refunds)
+	}
+	if len(c.publisher.events) != 2 || c.publisher.events[0].Type != events.TypeOrderPlaced ||
+		c.publisher.events[1].Type != events.TypePaymentCaptured {
+		t.Fatalf("published %+v", c.publisher.events)
+	}
+}
+
+func TestCheckoutDeclinedReleasesStock(t *testing.T) {
+	c := newCheckouts(t)
+	checkout := c.start(t, "key-1", payment.SandboxMethodDecline)
+
+	if checkout.Status != models.CheckoutFailed || checkout.Reason != models.CheckoutPaymentDeclined {
+		t.Fatalf("checkout = %+v", checkout)
+	}
+	c.wantStock(t, inStock)
+	if len(c.payments.refunds) != 0 || len(c.publisher.events) != 0 {
+		t.Fatalf("refunded %v, published %+v", c.payments.refunds, c.publisher.events)
+	}
+}
+
+// TestCheckoutCompensatesInReverse fails the last step until it runs out of retries: the
+// payment is refunded first, while the stock is still reserved, and the stock put back last
+func TestCheckoutCompensatesInReverse(t *testing.T) {
+	c := newCheckouts(t)
+	c.publisher.fail = errors.New("broker unavailable")
+	checkout := c.start(t, "key-1", "card")
+	if checkout.Status != models.CheckoutProcessing {
+		t.Fatalf("checkout waiting for a retry = %+v", checkout)
+	}
+	c.wantStock(t, inStock-2)
+
+	c.clock.Advance(checkoutBackoff)
+	if resumed := c.recover(t); resumed != 1 {
+		t.Fatalf("resumed %d checkouts, want 1", resumed)
+	}
+
+	if checkout := c.get(t, checkout.ID); checkout.Status != models.CheckoutFailed {
+		t.Fatalf("checkout out of retries = %+v", checkout)
+	}
+	if len(c.payments.refunds) != 1 || c.payments.refunds[0] != c.payments.captures[0] {
+		t.Fatalf("captured %v, refunded %v", c.payments.captures, c.payments.refunds)
+	}
+	if c.payments.stockAtRefund != inStock-2 {
+		t.Fatalf("stock at the refund = %d, want %d: stock was released before the refund", c.payments.stockAtRefund, inStock-2)
+	}
+	c.wantStock(t, inStock)
+}
+
+// TestCheckoutResumesAfterCrash stops the instance right after the provider charged, before the
+// capture was recorded, and has recovery finish the checkout once the lease runs out
+func TestCheckoutResumesAfterCrash(t *testing.T) {
+	c := newCheckouts(t)
+	c.payments.crash = true
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("the capture did not crash")
+			}
+		}()
+		c.start(t, "key-1", "card")
+	}()
+
+	if resumed := c.recover(t); resumed != 0 {
+		t.Fatalf("resumed %d checkouts under a live lease", resumed)
+	}
+	c.clock.Advance(checkoutLease)
+	if resumed := c.recover(t); resumed != 1 {
+		t.Fatalf("resumed %d checkouts after the lease ran out, want 1", resumed)
+	}
+
+	record, err := c.service.saga.ResumeKey(context.Background(), "user-1:key-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkout := c.get(t, record.ID)
+	if checkout.Status != models.CheckoutCompleted || checkout.OrderNumber != "ORD-2026-000001" {
+		t.Fatalf("checkout = %+v", checkout)
+	}
+	// The capture was repeated under its idempotency key, so it is the same payment
+	if len(c.payments.captures) != 2 || c.payments.captures[0] != c.payments.captures[1] {
+		t.Fatalf("captures = %v, want the same payment twice", c.payments.captures)
+	}
+	c.wantStock(t, inStock-2)
+}
+
+func TestCheckoutRetryIsIdempotent(t *testing.T) {
+	c := newCheckouts(t)
+	first := c.start(t, "key-1", "card")
+
+	// A retry is answered from the saga before the cart or address is looked at again
+	req := &models.CheckoutRequest{AddressID: "address-1", PaymentMethod: "card"}
+	again, err := c.service.Checkout(context.Background(), "user-1", "web", " key-1 ", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != first.ID || again.Status != models.CheckoutCompleted || again.OrderNumber != first.OrderNumber {
+		t.Fatalf("retried checkout = %+v, want %+v", again, first)
+	}
+	if len(c.payments.captures) != 1 || len(c.publisher.events) != 2 {
+		t.Fatalf("captured %v and published %d events", c.payments.captures, len(c.publisher.events))
+	}
+	c.wantStock(t, inStock-2)
+}
@@ -0,0 +1,176 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/search"
+	"go.uber.org/zap"
+)
+
+// Job types handled by the indexer
+const (
+	JobIndexProduct = "search.index_product"
+	JobReindex      = "search.reindex"
+)
+
+// reindexBatch is how many products are loaded and sent to the engine per request
+const reindexBatch = 500
+
+// indexJob is the payload of JobIndexProduct
+type indexJob struct {
+	ProductID string `json:"product_id"`
+}
+
+// Indexer keeps the search index in step with the catalog
+// Product events are turned into jobs, so indexing runs on the job workers with retries
+// Documents are always rebuilt from the database - events only say which product changed
+type Indexer struct {
+	repo   repository.ProductStore
+	engine search.Engine
+	alias  string
+	jobs   *jobs.Processor
+	log    *zap.Logger
+}
+
+func NewIndexer(repo repository.ProductStore, engine search.Engine, alias string,
+	processor *jobs.Processor, log *zap.Logger) *Indexer {
+	return &Indexer{
+		repo:   repo,
+		engine: engine,
+		alias:  alias,
+		jobs:   processor,
+		log:    log,
+	}
+}
+
+// HandleProductChanged queues re-indexing for product.updated and product.deleted
+// Both payloads carry product_id; the job decides between upsert and delete
+func (i *Indexer) HandleProductChanged(ctx context.Context, event events.Event) error {
+	var payload events.ProductUpdated
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	_, err := i.jobs.Enqueue(ctx, JobIndexProduct, indexJob{ProductID: payload.ProductID})
+	return err
+}
+
+// handleIndexJob upserts an active product or removes a deleted/inactive one
+func (i *Indexer) handleIndexJob(ctx context.Context, job *models.Job) error {
+	var payload indexJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	product, err := i.repo.GetByID(ctx, payload.ProductID)
+	if errors.Is(err, repository.ErrProductNotFound) || (err == nil && !product.Active) {
+		return i.engine.Delete(ctx, i.alias, []string{payload.ProductID})
+	}
+	if err != nil {
+		return err
+	}
+	return i.engine.Upsert(ctx, i.alias, []models.ProductDocument{models.NewProductDocument(product)})
+}
+
+func (i *Indexer) handleReindexJob(ctx context.Context, job *models.Job) error {
+	return i.Reindex(ctx)
+}
+
+// Reindex rebuilds the index from scratch without downtime:
+// the catalog is streamed into a new index, which is then swapped in behind the alias.
+// Changes made while the rebuild ran are replayed onto the new index afterwards.
+func (i *Indexer) Reindex(ctx context.Context) error {
+	started := time.Now()
+	index := search.BuildName(i.alias)
+
+	if err := i.engine.CreateIndex(ctx, index); err != nil {
+		return err
+	}
+	i.log.Info("Reindexing products", zap.String("engine", i.engine.Name()), zap.String("index", index))
+
+	count := 0
+	afterID := ""
+	for {
+		products, err := i.repo.ListAfter(ctx, afterID, reindexBatch)
+		if err != nil {
+			return err
+		}
+		docs := make([]models.ProductDocument, 0, len(products))
+		for p := range products {
+			docs = append(docs, models.NewProductDocument(&products[p]))
+		}
+		if err := i.engine.Upsert(ctx, index, docs); err != nil {
+			return err
+		}
+
+		count += len(products)
+		if len(products) < reindexBatch {
+			break
+		}
+		afterID = products[len(products)-1].ID
+	}
+
+	if err := i.engine.Promote(ctx, i.alias, index); err != nil {
+		return err
+	}
+
+	// Catch up on writes that went to the old index during the rebuild
+	// A second's margin covers clock skew between app and database
+	if err := i.replayChanges(ctx, started.Add(-time.Second)); err != nil {
+		return err
+	}
+
+	i.log.Info("Reindex finished", zap.String("index", index), zap.Int("products", count),
+		zap.Duration("took", time.Since(started)))
+	return nil
+}
+
+// replayChanges re-applies products changed since `since` to the live alias
+func (i *Indexer) replayChanges(ctx context.Context, since time.Time) error {
+	products, err := i.repo.ListChangedSince(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	var upserts []models.ProductDocument
+	var deletes []string
+	for p := range products {
+		if products[p].DeletedAt.Valid || !products[p].Active {
+			deletes = append(deletes, products[p].ID)
+			continue
+		}
+		upserts = append(upserts, models.NewProductDocument(&products[p]))
+	}
+
+	if err := i.engine.Upsert(ctx, i.alias, upserts); err != nil {
+		return err
+	}
+	return i.engine.Delete(ctx, i.alias, deletes)
+}
+
+func (i *Indexer) Name() string {
+	return "search-index-setup"
+}
+
+// Run makes sure the alias exists so searches work before the first reindex
+// Retries until the engine is reachable, then exits
+func (i *Indexer) Run(ctx context.Context) error {
+	for {
+		err := i.engine.EnsureIndex(ctx, i.alias)
+		if err == nil {
+			return nil
+		}
+		i.log.Warn("Search index not ready, retrying", zap.String("engine", i.engine.Name()), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
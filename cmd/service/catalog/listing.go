@@ -0,0 +1,84 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// JobProjectListing rebuilds one product's row in the listing read model
+const JobProjectListing = "catalog.project_listing"
+
+// ListingProjector keeps the product_listings read model in step with the catalog
+// Like the search indexer, events only say which product changed: rows are rebuilt
+// from the database on the job workers, so redelivered or reordered events converge
+type ListingProjector struct {
+	products repository.ProductStore
+	listings *repository.ProductListingRepository
+	jobs     *jobs.Processor
+	log      *zap.Logger
+}
+
+func NewListingProjector(products repository.ProductStore, listings *repository.ProductListingRepository,
+	processor *jobs.Processor, log *zap.Logger) *ListingProjector {
+	return &ListingProjector{
+		products: products,
+		listings: listings,
+		jobs:     processor,
+		log:      log,
+	}
+}
+
+// HandleProductChanged queues a projection for product.updated and product.deleted
+func (p *ListingProjector) HandleProductChanged(ctx context.Context, event events.Event) error {
+	var payload events.ProductUpdated
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	_, err := p.jobs.Enqueue(ctx, JobProjectListing, indexJob{ProductID: payload.ProductID})
+	return err
+}
+
+// handleProjectJob upserts an active product's listing or removes a deleted/inactive one
+func (p *ListingProjector) handleProjectJob(ctx context.Context, job *models.Job) error {
+	var payload indexJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	product, err := p.products.GetByID(ctx, payload.ProductID)
+	if errors.Is(err, repository.ErrProductNotFound) || (err == nil && !product.Active) {
+		return p.listings.Delete(ctx, payload.ProductID)
+	}
+	if err != nil {
+		return err
+	}
+	listing := models.NewProductListing(product)
+	return p.listings.Upsert(ctx, &listing)
+}
+
+// Migrate creates product_listings and fills it from the catalog the first time
+// Afterwards events keep it current, so the backfill never runs again
+func (p *ListingProjector) Migrate(db *gorm.DB) error {
+	existed := db.Migrator().HasTable(&models.ProductListing{})
+	if err := migrations.AutoMigrate(&models.ProductListing{})(db); err != nil {
+		return err
+	}
+	if existed {
+		return nil
+	}
+
+	rows, err := p.listings.Backfill(context.Background())
+	if err != nil {
+		return err
+	}
+	p.log.Info("Product listing read model created", zap.Int64("products", rows))
+	return nil
+}
@@ -0,0 +1,152 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/lock"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// ErrSearchDisabled is returned by Reindex when SEARCH_BACKEND=none
+var ErrSearchDisabled = errors.New("search engine not configured")
+
+// Module provides the product catalog and product search, including vendors' own products, and checkout
+// With a search engine configured, product events drive an indexer running on the job workers
+type Module struct {
+	handler         *Handler
+	stockHandler    *StockSubscriptionHandler
+	cartHandler     *CartHandler
+	savedHandler    *SavedCartHandler
+	checkoutHandler *CheckoutHandler
+	vendorHandler   *VendorProductHandler
+	service         *CatalogService
+	indexer         *Indexer
+	projector       *ListingProjector
+	recovery        module.Service
+}
+
+func NewModule(deps module.Deps) *Module {
+	repo := repository.NewProductRepository(deps.DB, deps.Log)
+	listings := repository.NewProductListingRepository(deps.DB, deps.Log)
+	index := deps.Config.Search.Index
+	service := NewCatalogService(repo, listings, deps.Cache, deps.Search, index, deps.Settings, deps.Campaigns,
+		deps.Channels, repository.NewSegmentRepository(deps.DB, deps.Log), deps.Groups, deps.Events, deps.Log)
+
+	// Product events also come from other modules (warehouse stock), so the cache follows them
+	for _, eventType := range []string{events.TypeProductUpdated, events.TypeProductDeleted} {
+		if err := deps.Events.Subscribe(eventType, "catalog-cache", service.HandleProductChanged); err != nil {
+			deps.Log.Error("Failed to subscribe catalog cache to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+
+	// The listing read model is maintained whether or not a search engine is configured
+	projector := NewListingProjector(repo, listings, deps.Jobs, deps.Log)
+	deps.Jobs.Register(JobProjectListing, projector.handleProjectJob)
+	for _, eventType := range []string{events.TypeProductUpdated, events.TypeProductDeleted} {
+		if err := deps.Events.Subscribe(eventType, "catalog-listing", projector.HandleProductChanged); err != nil {
+			deps.Log.Error("Failed to subscribe listing projector to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+
+	// Restock alerts: product events queue a job that notifies waiting customers
+	stockAlerts := NewStockSubscriptionService(repository.NewStockSubscriptionRepository(deps.DB, deps.Log), repo,
+		deps.Notifier, deps.Jobs, deps.Clock, deps.Log)
+	deps.Jobs.Register(JobNotifyRestock, stockAlerts.handleRestockJob)
+	for _, eventType := range []string{events.TypeProductUpdated, events.TypeProductDeleted} {
+		if err := deps.Events.Subscribe(eventType, "stock-alerts", stockAlerts.HandleProductChanged); err != nil {
+			deps.Log.Error("Failed to subscribe stock alerts to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+
+	// Vendors manage their own products through the same service
+	vendors := repository.NewVendorRepository(deps.DB, deps.Log)
+
+	var indexer *Indexer
+	if deps.Search != nil {
+		indexer = NewIndexer(repo, deps.Search, index, deps.Jobs, deps.Log)
+		deps.Jobs.Register(JobIndexProduct, indexer.handleIndexJob)
+		deps.Jobs.Register(JobReindex, indexer.handleReindexJob)
+
+		for _, eventType := range []string{events.TypeProductUpdated, events.TypeProductDeleted} {
+			if err := deps.Events.Subscribe(eventType, "search-indexer", indexer.HandleProductChanged); err != nil {
+				deps.Log.Error("Failed to subscribe search indexer to event", zap.String("type", eventType), zap.Error(err))
+			}
+		}
+	}
+
+	// Admin bulk actions over many products
+	NewBulkActions(service, repo, deps.Log).Register(deps.Bulk)
+
+	// Cart quotes re-price carts at checkout against the prices the shopper was shown
+	carts := NewCartService(service, deps.Config.Auth.JWTSecret, deps.Config.Orders.QuoteTTL, deps.Clock, deps.Log)
+	// Saved carts and reorders are rebuilt through the same pricing
+	saved := NewSavedCartService(repository.NewSavedCartRepository(deps.DB, deps.Log),
+		repository.NewOrderRepository(deps.DB, deps.Log), carts, deps.Log)
+	// Checkout is a saga over the same pricing; the leader resumes retries and crashed checkouts
+	checkout := NewCheckoutService(service, carts, repository.NewAddressRepository(deps.DB, deps.Log), deps.Inventory,
+		deps.Payments, deps.OrderNumbers, deps.Events, repository.NewSagaRepository(deps.DB, deps.Log), deps.Config.Checkout,
+		deps.IDs, deps.Clock, deps.Log)
+
+	return &Module{
+		handler:         NewHandler(service, deps.Jobs, indexer, deps.Tokens, deps.Links, deps.Log),
+		stockHandler:    NewStockSubscriptionHandler(stockAlerts, deps.Tokens, deps.Log),
+		cartHandler:     NewCartHandler(carts, deps.Tokens, deps.Log),
+		savedHandler:    NewSavedCartHandler(saved, deps.Tokens, deps.Log),
+		checkoutHandler: NewCheckoutHandler(checkout, deps.Tokens, deps.Log),
+		vendorHandler:   NewVendorProductHandler(service, vendors, deps.Tokens, deps.Links, deps.Log),
+		service:         service,
+		indexer:         indexer,
+		projector:       projector,
+		recovery: lock.Singleton(deps.Locks, NewCheckoutRecovery(checkout, deps.Config.Checkout.RecoveryInterval, deps.Log),
+			deps.Config.Locks.LeaderTTL, deps.Log),
+	}
+}
+
+// Reindex rebuilds the search index in the foreground (`main reindex`)
+func (m *Module) Reindex(ctx context.Context) error {
+	if m.indexer == nil {
+		return ErrSearchDisabled
+	}
+	return m.indexer.Reindex(ctx)
+}
+
+// Warm loads the category tree into the cache before the instance takes traffic
+func (m *Module) Warm(ctx context.Context) error {
+	_, err := m.service.Categories(ctx)
+	return err
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.Product{}),
+		migrations.AutoMigrate(&models.StockSubscription{}),
+		migrations.AutoMigrate(&models.SavedCart{}),
+		migrations.AutoMigrate(&models.Saga{}),
+		m.projector.Migrate,
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+	m.stockHandler.RegisterRoutes(router)
+	m.cartHandler.RegisterRoutes(router)
+	m.savedHandler.RegisterRoutes(router)
+	m.checkoutHandler.RegisterRoutes(router)
+	m.vendorHandler.RegisterRoutes(router)
+}
+
+// Services runs checkout recovery and, when a search engine is configured, makes sure the index exists
+func (m *Module) Services() []module.Service {
+	services := []module.Service{m.recovery}
+	if m.indexer != nil {
+		services = append(services, m.indexer)
+	}
+	return services
+}
@@ -0,0 +1,369 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/channel"
+	"github.com/Jason-Omondi/ecomgo/internal/export"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/links"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/Jason-Omondi/ecomgo/internal/search"
+	"github.com/Jason-Omondi/ecomgo/internal/timezone"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *CatalogService
+	jobs    *jobs.Processor
+	indexer *Indexer // nil when SEARCH_BACKEND=none
+	tokens  *auth.TokenManager
+	links   *links.Builder
+	log     *zap.Logger
+}
+
+func NewHandler(service *CatalogService, processor *jobs.Processor, indexer *Indexer,
+	tokens *auth.TokenManager, resourceLinks *links.Builder, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		jobs:    processor,
+		indexer: indexer,
+		tokens:  tokens,
+		links:   resourceLinks,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers catalog routes
+// Browsing and search are public; product management and reindexing are admin-only
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/products", h.handleList).Methods("GET").Name(links.RouteProducts)
+	router.HandleFunc("/products/search", h.handleSearch).Methods("GET")
+	router.HandleFunc("/products/categories", h.handleCategories).Methods("GET")
+	router.Handle("/products/{id}", auth.Identify(h.tokens)(http.HandlerFunc(h.handleGet))).Methods("GET").Name(links.RouteProduct)
+
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.ScopeByMethod("products"), auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/products", h.handleCreate).Methods("POST")
+	admin.HandleFunc("/products/export", h.handleExport).Methods("GET")
+	admin.HandleFunc("/products/{id}", h.handleUpdate).Methods("PUT")
+	admin.HandleFunc("/products/{id}/stock", h.handleAdjustStock).Methods("POST")
+	admin.HandleFunc("/products/{id}", h.handleDelete).Methods("DELETE")
+	if h.indexer != nil {
+		admin.HandleFunc("/search/reindex", h.handleReindex).Methods("POST")
+	}
+}
+
+// handleList handles GET /api/v1/products
+// @Summary List products
+// @Description Active products on the caller's channel ordered by name, at channel prices, served from a denormalized read model updated by product events
+// @Tags Catalog
+// @Produce json
+// @Param X-Channel header string false "Sales channel (default web)"
+// @Param category query string false "Category filter (exact path)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.ProductListingResponse
+// @Failure 400 {string} string "Unknown channel"
+// @Failure 500 {string} string "Internal server error"
+// @Router /products [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	resp, err := h.service.ListProducts(r.Context(), channel.FromRequest(r), strings.TrimSpace(r.URL.Query().Get("category")), limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	if h.links.Enabled() {
+		resp.Links = h.links.Page(r, limit, offset, resp.HasMore)
+		for i := range resp.Products {
+			resp.Products[i].Links = h.links.Product(resp.Products[i].ProductID, resp.Products[i].Category)
+		}
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleSearch handles GET /api/v1/products/search
+// @Summary Search products
+// @Description Full-text product search (Meilisearch/Elasticsearch, or a simple database match when none is configured)
+// @Tags Catalog
+// @Produce json
+// @Param X-Channel header string false "Sales channel (default web)"
+// @Param q query string false "Search text"
+// @Param category query string false "Category filter"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.ProductSearchResponse
+// @Failure 400 {string} string "Unknown channel"
+// @Failure 500 {string} string "Internal server error"
+// @Router /products/search [get]
+func (h *Handler) handleSearch(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	query := r.URL.Query()
+
+	resp, err := h.service.Search(r.Context(), channel.FromRequest(r), search.Query{
+		Text:     strings.TrimSpace(query.Get("q")),
+		Category: strings.TrimSpace(query.Get("category")),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleCategories handles GET /api/v1/products/categories
+// @Summary List categories
+// @Description Category tree built from product categories ("fashion/shoes" nests under "fashion") with active product counts
+// @Tags Catalog
+// @Produce json
+// @Success 200 {array} models.Category
+// @Failure 500 {string} string "Internal server error"
+// @Router /products/categories [get]
+func (h *Handler) handleCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.service.Categories(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, categories)
+}
+
+// handleGet handles GET /api/v1/products/{id}
+// @Summary Get product
+// @Description A product at its price on the caller's channel; products hidden there are not found. Signed-in callers get their customer group's price (customer_group, tax_treatment). restricted is true when the product isn't sold in the caller's country (GeoIP). price_tiers lists the quantity breaks open to the caller: public ones, plus their customer segments' when signed in.
+// @Tags Catalog
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param X-Channel header string false "Sales channel (default web)"
+// @Success 200 {object} models.Product
+// @Failure 400 {string} string "Unknown channel"
+// @Failure 404 {string} string "Product not found"
+// @Router /products/{id} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	product, err := h.service.GetProduct(r.Context(), channel.FromRequest(r), mux.Vars(r)["id"])
+	if err == nil && !product.Active {
+		err = repository.ErrProductNotFound
+	}
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	product.Links = h.links.Product(product.ID, product.Category)
+	response.JSON(w, http.StatusOK, product)
+}
+
+// handleCreate handles POST /api/v1/admin/products
+// @Summary Create product
+// @Tags Catalog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ProductRequest true "Product"
+// @Success 201 {object} models.Product
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 409 {string} string "SKU already in use"
+// @Router /admin/products [post]
+func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req models.ProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.CreateProduct(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	product.Links = h.links.Product(product.ID, product.Category)
+	response.JSON(w, http.StatusCreated, product)
+}
+
+// handleUpdate handles PUT /api/v1/admin/products/{id}
+// @Summary Update product
+// @Tags Catalog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body models.ProductRequest true "Product"
+// @Success 200 {object} models.Product
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Insufficient stock or SKU already in use"
+// @Router /admin/products/{id} [put]
+func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req models.ProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.UpdateProduct(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	product.Links = h.links.Product(product.ID, product.Category)
+	response.JSON(w, http.StatusOK, product)
+}
+
+// handleAdjustStock handles POST /api/v1/admin/products/{id}/stock
+// @Summary Adjust product stock
+// @Description Adds delta to the current stock in one atomic update. A decrement larger than the stock left is rejected.
+// @Tags Catalog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body models.StockAdjustmentRequest true "Stock change"
+// @Success 200 {object} models.Product
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Insufficient stock"
+// @Router /admin/products/{id}/stock [post]
+func (h *Handler) handleAdjustStock(w http.ResponseWriter, r *http.Request) {
+	var req models.StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.AdjustStock(r.Context(), mux.Vars(r)["id"], req.Delta)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	product.Links = h.links.Product(product.ID, product.Category)
+	response.JSON(w, http.StatusOK, product)
+}
+
+// handleDelete handles DELETE /api/v1/admin/products/{id}
+// @Summary Delete product
+// @Tags Catalog
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 204 "Deleted"
+// @Failure 404 {string} string "Product not found"
+// @Router /admin/products/{id} [delete]
+func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteProduct(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleReindex handles POST /api/v1/admin/search/reindex
+// @Summary Rebuild search index
+// @Description Queues a zero-downtime rebuild of the product index. Track it via /admin/jobs/{id}.
+// @Tags Catalog
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} models.Job
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/search/reindex [post]
+func (h *Handler) handleReindex(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobs.Enqueue(r.Context(), JobReindex, nil, jobs.MaxAttempts(1))
+	if err != nil {
+		h.log.Error("Failed to queue reindex", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, job)
+}
+
+// productColumns are the CSV columns of a product export; prices are in minor units, times in loc
+func productColumns(loc *time.Location) []export.Column[models.Product] {
+	return []export.Column[models.Product]{
+		{Name: "id", Value: func(p models.Product) string { return p.ID }},
+		{Name: "sku", Value: func(p models.Product) string { return p.SKU }},
+		{Name: "name", Value: func(p models.Product) string { return p.Name }},
+		{Name: "category", Value: func(p models.Product) string { return p.Category }},
+		{Name: "price", Value: func(p models.Product) string { return export.Int(p.Price) }},
+		{Name: "currency", Value: func(p models.Product) string { return p.Currency }},
+		{Name: "stock", Value: func(p models.Product) string { return export.Int(p.Stock) }},
+		{Name: "active", Value: func(p models.Product) string { return strconv.FormatBool(p.Active) }},
+		{Name: "restricted_countries", Value: func(p models.Product) string { return strings.Join(p.RestrictedCountries, " ") }},
+		{Name: "min_quantity", Value: func(p models.Product) string { return export.Int(p.MinQuantity) }},
+		{Name: "max_quantity", Value: func(p models.Product) string { return export.Int(p.MaxQuantity) }},
+		{Name: "quantity_step", Value: func(p models.Product) string { return export.Int(p.QuantityStep) }},
+		{Name: "created_at", Value: func(p models.Product) string { return export.TimeIn(p.CreatedAt, loc) }},
+		{Name: "updated_at", Value: func(p models.Product) string { return export.TimeIn(p.UpdatedAt, loc) }},
+	}
+}
+
+// handleExport handles GET /api/v1/admin/products/export
+// @Summary Export products
+// @Description Streams the whole catalog, inactive products included, as CSV or NDJSON (chunked). Stops when the client disconnects.
+// @Tags Catalog
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Security BearerAuth
+// @Param format query string false "csv (default) or ndjson"
+// @Param tz query string false "IANA timezone for CSV timestamps, e.g. Africa/Nairobi (default UTC; also read from the Time-Zone header)"
+// @Success 200 {string} string "Export file"
+// @Failure 400 {string} string "Unsupported format"
+// @Router /admin/products/export [get]
+func (h *Handler) handleExport(w http.ResponseWriter, r *http.Request) {
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	loc, err := timezone.FromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := export.Stream(r.Context(), w, format, "products", productColumns(loc), h.service.EachProduct)
+	if err != nil {
+		h.log.Warn("Product export aborted", zap.Int("rows", rows), zap.Error(err))
+		return
+	}
+	h.log.Info("Products exported", zap.String("format", format), zap.Int("rows", rows))
+}
+
+// writeError maps catalog errors to HTTP status codes
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidProduct):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, channel.ErrUnknownChannel):
+		http.Error(w, "Unknown channel", http.StatusBadRequest)
+	case errors.Is(err, repository.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrInsufficientStock):
+		http.Error(w, "Insufficient stock", http.StatusConflict)
+	case errors.Is(err, ErrSKUTaken):
+		http.Error(w, "SKU already in use", http.StatusConflict)
+	default:
+		h.log.Error("Catalog request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,232 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/channel"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type SavedCartHandler struct {
+	service *SavedCartService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewSavedCartHandler(service *SavedCartService, tokens *auth.TokenManager, log *zap.Logger) *SavedCartHandler {
+	return &SavedCartHandler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers saved carts and reordering; both are the signed-in customer's own
+func (h *SavedCartHandler) RegisterRoutes(router *mux.Router) {
+	orders := router.PathPrefix("/orders").Subrouter()
+	orders.Use(auth.ScopeByMethod("orders"), auth.Authenticate(h.tokens))
+	orders.HandleFunc("/{id}/reorder", h.handleReorder).Methods("POST")
+
+	carts := router.PathPrefix("/users/me/saved-carts").Subrouter()
+	carts.Use(auth.Authenticate(h.tokens))
+	carts.HandleFunc("", h.handleList).Methods("GET")
+	carts.HandleFunc("", h.handleCreate).Methods("POST")
+	carts.HandleFunc("/{id}", h.handleGet).Methods("GET")
+	carts.HandleFunc("/{id}", h.handleUpdate).Methods("PUT")
+	carts.HandleFunc("/{id}", h.handleDelete).Methods("DELETE")
+	carts.HandleFunc("/{id}/cart", h.handleRebuild).Methods("POST")
+}
+
+// handleReorder handles POST /api/v1/orders/{id}/reorder
+// @Summary Reorder
+// @Description Rebuilds a cart from one of the caller's past orders at current prices on the caller's channel. Lines are trimmed to the stock on hand and the products' quantity rules; prices that moved since the order, and products that can't be bought any more, are listed as changes. With save_as the cart is also saved under that name.
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-Channel header string false "Sales channel (default web)"
+// @Param id path string true "Order ID"
+// @Param request body models.ReorderRequest false "Save the cart"
+// @Success 200 {object} models.CartRebuild
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Order not found"
+// @Failure 409 {string} string "Saved cart name already in use"
+// @Failure 422 {string} string "Quantity not allowed"
+// @Router /orders/{id}/reorder [post]
+func (h *SavedCartHandler) handleReorder(w http.ResponseWriter, r *http.Request) {
+	var req models.ReorderRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	rebuild, err := h.service.Reorder(r.Context(), channel.FromRequest(r), auth.ClaimsFromContext(r.Context()).UserID(),
+		mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, rebuild)
+}
+
+// handleList handles GET /api/v1/users/me/saved-carts
+// @Summary List saved carts
+// @Tags Cart
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.SavedCart
+// @Router /users/me/saved-carts [get]
+func (h *SavedCartHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	carts, err := h.service.List(r.Context(), auth.ClaimsFromContext(r.Context()).UserID())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, carts)
+}
+
+// handleCreate handles POST /api/v1/users/me/saved-carts
+// @Summary Save a cart
+// @Description Saves a named list of products and quantities for recurring purchases. Quantities must meet the products' quantity rules; prices aren't kept.
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.SavedCartRequest true "Saved cart"
+// @Success 201 {object} models.SavedCart
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Saved cart name already in use"
+// @Failure 422 {string} string "Quantity not allowed"
+// @Router /users/me/saved-carts [post]
+func (h *SavedCartHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req models.SavedCartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	cart, err := h.service.Create(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, cart)
+}
+
+// handleGet handles GET /api/v1/users/me/saved-carts/{id}
+// @Summary Get a saved cart
+// @Tags Cart
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Saved cart ID"
+// @Success 200 {object} models.SavedCart
+// @Failure 404 {string} string "Saved cart not found"
+// @Router /users/me/saved-carts/{id} [get]
+func (h *SavedCartHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	cart, err := h.service.Get(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, cart)
+}
+
+// handleUpdate handles PUT /api/v1/users/me/saved-carts/{id}
+// @Summary Replace a saved cart
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Saved cart ID"
+// @Param request body models.SavedCartRequest true "Saved cart"
+// @Success 200 {object} models.SavedCart
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Saved cart or product not found"
+// @Failure 409 {string} string "Saved cart name already in use"
+// @Failure 422 {string} string "Quantity not allowed"
+// @Router /users/me/saved-carts/{id} [put]
+func (h *SavedCartHandler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req models.SavedCartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	cart, err := h.service.Update(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, cart)
+}
+
+// handleDelete handles DELETE /api/v1/users/me/saved-carts/{id}
+// @Summary Delete a saved cart
+// @Tags Cart
+// @Security BearerAuth
+// @Param id path string true "Saved cart ID"
+// @Success 204 "Deleted"
+// @Failure 404 {string} string "Saved cart not found"
+// @Router /users/me/saved-carts/{id} [delete]
+func (h *SavedCartHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRebuild handles POST /api/v1/users/me/saved-carts/{id}/cart
+// @Summary Build a cart from a saved cart
+// @Description Prices a saved cart on the caller's channel, trimmed to the stock on hand and the products' quantity rules. Products that can't be bought any more are listed as changes.
+// @Tags Cart
+// @Produce json
+// @Security BearerAuth
+// @Param X-Channel header string false "Sales channel (default web)"
+// @Param id path string true "Saved cart ID"
+// @Success 200 {object} models.CartRebuild
+// @Failure 404 {string} string "Saved cart not found"
+// @Failure 422 {string} string "Quantity not allowed"
+// @Router /users/me/saved-carts/{id}/cart [post]
+func (h *SavedCartHandler) handleRebuild(w http.ResponseWriter, r *http.Request) {
+	rebuild, err := h.service.Rebuild(r.Context(), channel.FromRequest(r), auth.ClaimsFromContext(r.Context()).UserID(),
+		mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, rebuild)
+}
+
+// writeError maps saved cart and reorder errors to HTTP status codes
+func (h *SavedCartHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidSavedCart):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrQuantityNotAllowed), errors.Is(err, ErrMixedCurrency):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, channel.ErrUnknownChannel):
+		http.Error(w, "Unknown channel", http.StatusBadRequest)
+	case errors.Is(err, ErrSavedCartExists):
+		http.Error(w, "Saved cart name already in use", http.StatusConflict)
+	case errors.Is(err, repository.ErrSavedCartNotFound):
+		http.Error(w, "Saved cart not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrOrderNotFound):
+		http.Error(w, "Order not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	default:
+		h.log.Error("Saved cart request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,299 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	maxSavedCarts     = 50
+	maxSavedCartName  = 100
+	maxSavedCartLines = 200 // when the max_order_lines setting is off
+)
+
+var (
+	// ErrInvalidSavedCart wraps validation problems with a saved cart
+	ErrInvalidSavedCart = errors.New("invalid saved cart")
+	// ErrSavedCartExists is returned when the customer already has a saved cart with the name
+	ErrSavedCartExists = errors.New("saved cart name already in use")
+)
+
+// SavedCartService keeps customers' saved carts and rebuilds carts from them and from past orders
+// A rebuilt cart holds what can be bought now: lines are trimmed to the stock on hand and the
+// product's current quantity rules, then priced like POST /cart/quote. Every difference from the
+// order or saved cart is reported as a change, so the shopper sees what moved before checkout.
+type SavedCartService struct {
+	repo   *repository.SavedCartRepository
+	orders *repository.OrderRepository
+	carts  *CartService
+	log    *zap.Logger
+}
+
+func NewSavedCartService(repo *repository.SavedCartRepository, orders *repository.OrderRepository, carts *CartService,
+	log *zap.Logger) *SavedCartService {
+	return &SavedCartService{
+		repo:   repo,
+		orders: orders,
+		carts:  carts,
+		log:    log,
+	}
+}
+
+// List returns the customer's saved carts by name
+func (s *SavedCartService) List(ctx context.Context, userID string) ([]models.SavedCart, error) {
+	carts, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if carts == nil {
+		carts = []models.SavedCart{}
+	}
+	return carts, nil
+}
+
+func (s *SavedCartService) Get(ctx context.Context, userID, id string) (*models.SavedCart, error) {
+	return s.repo.Get(ctx, userID, id)
+}
+
+// Create saves a named cart for the customer
+func (s *SavedCartService) Create(ctx context.Context, userID string, req *models.SavedCartRequest) (*models.SavedCart, error) {
+	cart := &models.SavedCart{UserID: userID}
+	if err := s.apply(ctx, cart, req); err != nil {
+		return nil, err
+	}
+	count, err := s.repo.Count(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxSavedCarts {
+		return nil, fmt.Errorf("%w: at most %d saved carts", ErrInvalidSavedCart, maxSavedCarts)
+	}
+
+	if err := s.repo.Create(ctx, cart); err != nil {
+		return nil, nameInUse(err)
+	}
+	s.log.Info("Cart saved", zap.String("id", cart.ID), zap.String("user_id", userID), zap.Int("lines", len(cart.Items)))
+	return cart, nil
+}
+
+// Update replaces a saved cart's name and items
+func (s *SavedCartService) Update(ctx context.Context, userID, id string, req *models.SavedCartRequest) (*models.SavedCart, error) {
+	cart, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, cart, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, cart); err != nil {
+		return nil, nameInUse(err)
+	}
+	return s.repo.Get(ctx, userID, id)
+}
+
+func (s *SavedCartService) Delete(ctx context.Context, userID, id string) error {
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		return err
+	}
+	s.log.Info("Saved cart deleted", zap.String("id", id), zap.String("user_id", userID))
+	return nil
+}
+
+// Rebuild prices a saved cart on channelCode at current prices and stock
+func (s *SavedCartService) Rebuild(ctx context.Context, channelCode, userID, id string) (*models.CartRebuild, error) {
+	cart, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	rebuild, err := s.rebuild(ctx, channelCode, cart.Items)
+	if err != nil {
+		return nil, err
+	}
+	rebuild.SavedCart = cart
+	return rebuild, nil
+}
+
+// Reorder rebuilds a cart from one of the customer's past orders
+// Lines carry the price paid, so every price that moved since is reported. With req.SaveAs
+// the rebuilt cart is also saved under that name.
+func (s *SavedCartService) Reorder(ctx context.Context, channelCode, userID, orderID string, req *models.ReorderRequest) (*models.CartRebuild, error) {
+	order, err := s.orders.Get(ctx, orderID, false)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, repository.ErrOrderNotFound
+	}
+
+	lines := make([]models.CartLine, 0, len(order.Items))
+	for _, item := range order.Items {
+		lines = append(lines, models.CartLine{ProductID: item.ProductID, Quantity: item.Quantity, UnitPrice: item.UnitPrice})
+	}
+	rebuild, err := s.rebuild(ctx, channelCode, lines)
+	if err != nil {
+		return nil, err
+	}
+	rebuild.OrderID = order.ID
+
+	if name := strings.TrimSpace(req.SaveAs); name != "" {
+		if len(rebuild.Items) == 0 {
+			return nil, fmt.Errorf("%w: nothing from the order can be bought now", ErrInvalidSavedCart)
+		}
+		saved, err := s.Create(ctx, userID, &models.SavedCartRequest{Name: name, Items: rebuild.Items})
+		if err != nil {
+			return nil, err
+		}
+		rebuild.SavedCart = saved
+	}
+	s.log.Info("Order rebuilt as cart", zap.String("order_id", order.ID), zap.String("user_id", userID),
+		zap.Int("lines", len(rebuild.Items)), zap.Int("changes", len(rebuild.Changes)))
+	return rebuild, nil
+}
+
+// rebuild trims lines to what can be bought now and prices them
+// Lines of the same product are added up first; a line's unit_price is the price to compare with
+func (s *SavedCartService) rebuild(ctx context.Context, channelCode string, lines []models.CartLine) (*models.CartRebuild, error) {
+	quantities := make(map[string]int, len(lines))
+	seen := make(map[string]int64, len(lines))
+	var order []string
+	for _, line := range lines {
+		if _, ok := quantities[line.ProductID]; !ok {
+			order = append(order, line.ProductID)
+		}
+		quantities[line.ProductID] += line.Quantity
+		if line.UnitPrice > 0 {
+			seen[line.ProductID] = line.UnitPrice
+		}
+	}
+
+	rebuild := &models.CartRebuild{Items: []models.CartLine{}, Changes: []models.CartChange{}}
+	var kept []models.CartLine
+	for _, id := range order {
+		quantity := quantities[id]
+		product, err := s.carts.catalog.GetProduct(ctx, channelCode, id)
+		if err != nil && !errors.Is(err, repository.ErrProductNotFound) {
+			return nil, err
+		}
+		// Unavailable products are left in for Reprice to report
+		if err == nil && product.Active && !product.Restricted {
+			fitted, reason := fitQuantity(product, quantity)
+			if fitted != quantity {
+				rebuild.Changes = append(rebuild.Changes, models.CartChange{
+					ProductID:        id,
+					Reason:           reason,
+					PreviousPrice:    seen[id],
+					PreviousQuantity: quantity,
+					CurrentQuantity:  fitted,
+				})
+			}
+			if fitted == 0 {
+				continue
+			}
+			quantity = fitted
+		}
+		kept = append(kept, models.CartLine{ProductID: id, Quantity: quantity, UnitPrice: seen[id]})
+	}
+	if len(kept) == 0 {
+		return rebuild, nil
+	}
+
+	quote, err := s.carts.Reprice(ctx, channelCode, &models.CartQuoteRequest{Items: kept})
+	var changed *CartChanges
+	if errors.As(err, &changed) {
+		quote = changed.Response.Quote
+		rebuild.Changes = append(rebuild.Changes, changed.Response.Changes...)
+	} else if err != nil {
+		return nil, err
+	}
+	if len(quote.Items) == 0 {
+		return rebuild, nil
+	}
+	for _, line := range quote.Items {
+		rebuild.Items = append(rebuild.Items, models.CartLine{ProductID: line.ProductID, Quantity: line.Quantity, UnitPrice: line.UnitPrice})
+	}
+	rebuild.Quote = quote
+	return rebuild, nil
+}
+
+// fitQuantity returns the most of quantity that can be bought now: no more than the stock on hand
+// and allowed by the product's quantity rules; 0 when none can
+// Returns: the reason when it is less than quantity
+func fitQuantity(product *models.Product, quantity int) (int, string) {
+	fitted, reason := quantity, ""
+	if fitted > product.Stock {
+		fitted, reason = max(product.Stock, 0), models.CartOutOfStock
+	}
+	if product.MaxQuantity > 0 && fitted > product.MaxQuantity {
+		fitted, reason = product.MaxQuantity, models.CartQuantityAdjusted
+	}
+	if product.QuantityStep > 1 && fitted%product.QuantityStep != 0 {
+		fitted -= fitted % product.QuantityStep
+		if reason == "" {
+			reason = models.CartQuantityAdjusted
+		}
+	}
+	if fitted > 0 && product.MinQuantity > 0 && fitted < product.MinQuantity {
+		fitted = 0
+		if reason == "" {
+			reason = models.CartQuantityAdjusted
+		}
+	}
+	return fitted, reason
+}
+
+// apply validates req and copies it onto cart
+// Items must be buyable as they are: products exist and quantities meet their rules
+func (s *SavedCartService) apply(ctx context.Context, cart *models.SavedCart, req *models.SavedCartRequest) error {
+	name := strings.TrimSpace(req.Name)
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidSavedCart)
+	case len(name) > maxSavedCartName:
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidSavedCart, maxSavedCartName)
+	case len(req.Items) == 0:
+		return fmt.Errorf("%w: a saved cart needs at least one item", ErrInvalidSavedCart)
+	case len(req.Items) > maxSavedCartLines:
+		return fmt.Errorf("%w: at most %d items", ErrInvalidSavedCart, maxSavedCartLines)
+	}
+
+	quantities := make(map[string]int, len(req.Items))
+	var order []string
+	for _, item := range req.Items {
+		if item.ProductID == "" || item.Quantity <= 0 {
+			return fmt.Errorf("%w: every item needs a product_id and a positive quantity", ErrInvalidSavedCart)
+		}
+		if _, ok := quantities[item.ProductID]; !ok {
+			order = append(order, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+	items := make([]models.CartLine, 0, len(order))
+	for _, id := range order {
+		items = append(items, models.CartLine{ProductID: id, Quantity: quantities[id]})
+	}
+	if err := s.carts.catalog.ValidateCart(ctx, items); err != nil {
+		return err
+	}
+
+	if existing, err := s.repo.GetByName(ctx, cart.UserID, name); err == nil && existing.ID != cart.ID {
+		return ErrSavedCartExists
+	} else if err != nil && !errors.Is(err, repository.ErrSavedCartNotFound) {
+		return err
+	}
+	cart.Name, cart.Items = name, items
+	return nil
+}
+
+// nameInUse reports a write that raced another for the same name as ErrSavedCartExists
+func nameInUse(err error) error {
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		return ErrSavedCartExists
+	}
+	return err
+}
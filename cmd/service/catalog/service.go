@@ -0,0 +1,565 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/campaign"
+	"github.com/Jason-Omondi/ecomgo/internal/channel"
+	"github.com/Jason-Omondi/ecomgo/internal/customergroup"
+	"github.com/Jason-Omondi/ecomgo/internal/database"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/search"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"go.uber.org/zap"
+)
+
+// Cached reads; writes invalidate them, the TTLs only bound staleness from other writers
+const (
+	productCacheTTL    = 5 * time.Minute
+	categoriesCacheKey = "catalog:categories"
+	categoriesCacheTTL = 10 * time.Minute
+
+	// staleCacheTTL is how long product detail and the category tree can still be served
+	// from cache while the database is unreachable
+	staleCacheTTL = 24 * time.Hour
+)
+
+var (
+	// ErrInvalidProduct wraps validation problems with a product request
+	ErrInvalidProduct = errors.New("invalid product")
+	// ErrSKUTaken is returned when another product already has the SKU
+	ErrSKUTaken = errors.New("sku is already in use")
+	// ErrRestrictedCountry is returned by CheckSellable for products not sold to the shopper's country
+	ErrRestrictedCountry = errors.New("product is not sold in this country")
+	// ErrQuantityNotAllowed is returned by CheckQuantity and ValidateCart for quantities breaking
+	// a product's or the store's quantity rules
+	ErrQuantityNotAllowed = errors.New("quantity not allowed")
+)
+
+// CatalogService manages products and serves product search
+// Every change publishes product.updated / product.deleted; the search indexer consumes them
+// Stored prices are list prices: public reads apply channel and campaign prices on the way out,
+// so a sale starting or ending never touches the product cache, the listing model or the index
+type CatalogService struct {
+	repo      repository.ProductStore
+	listings  *repository.ProductListingRepository // read model behind ListProducts
+	cache     *cache.Loader                        // product detail and category tree, one DB load per key on concurrent misses
+	engine    search.Engine                        // nil when SEARCH_BACKEND=none
+	index     string
+	settings  *settings.Store // default currency of new products
+	pricing   *campaign.Pricing
+	channels  *channel.Catalog              // products hidden or repriced on the caller's channel
+	segments  *repository.SegmentRepository // price tiers limited to a customer segment
+	groups    *customergroup.Pricing        // customer group price lists
+	publisher events.Publisher
+	log       *zap.Logger
+}
+
+func NewCatalogService(repo repository.ProductStore, listings *repository.ProductListingRepository, appCache cache.Cache,
+	engine search.Engine, index string, storeSettings *settings.Store, pricing *campaign.Pricing,
+	channels *channel.Catalog, segments *repository.SegmentRepository, groups *customergroup.Pricing,
+	publisher events.Publisher, log *zap.Logger) *CatalogService {
+	return &CatalogService{
+		repo:      repo,
+		listings:  listings,
+		cache:     cache.NewLoader(appCache),
+		engine:    engine,
+		index:     index,
+		settings:  storeSettings,
+		pricing:   pricing,
+		channels:  channels,
+		segments:  segments,
+		groups:    groups,
+		publisher: publisher,
+		log:       log,
+	}
+}
+
+// CreateProduct validates req and adds a product
+func (s *CatalogService) CreateProduct(ctx context.Context, req *models.ProductRequest) (*models.Product, error) {
+	return s.createProduct(ctx, &models.Product{}, req)
+}
+
+func (s *CatalogService) createProduct(ctx context.Context, product *models.Product, req *models.ProductRequest) (*models.Product, error) {
+	if err := applyRequest(product, req, s.settings.DefaultCurrency(ctx)); err != nil {
+		return nil, err
+	}
+	if err := s.checkTierSegments(ctx, product.PriceTiers); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, product); err != nil {
+		return nil, skuTaken(err)
+	}
+
+	s.log.Info("Product created", zap.String("id", product.ID), zap.String("sku", product.SKU))
+	s.invalidate(ctx)
+	s.publishUpdated(ctx, product.ID)
+	return product, nil
+}
+
+// UpdateProduct replaces a product's fields with req
+// Stock is applied as the difference to the value read, so sales made in between aren't undone
+func (s *CatalogService) UpdateProduct(ctx context.Context, id string, req *models.ProductRequest) (*models.Product, error) {
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	previousStock := product.Stock
+	if err := applyRequest(product, req, product.Currency); err != nil {
+		return nil, err
+	}
+	if err := s.checkTierSegments(ctx, product.PriceTiers); err != nil {
+		return nil, err
+	}
+
+	// Stock first: when it can't be applied nothing else is written either
+	if delta := product.Stock - previousStock; delta != 0 {
+		if err := s.repo.AdjustStock(ctx, id, delta); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Update(ctx, product); err != nil {
+		return nil, skuTaken(err)
+	}
+
+	s.log.Info("Product updated", zap.String("id", product.ID))
+	s.invalidate(ctx, product.ID)
+	s.publishUpdated(ctx, product.ID)
+	return s.repo.GetByID(ctx, id)
+}
+
+// AdjustStock adds delta to a product's stock; a negative delta fails with
+// repository.ErrInsufficientStock instead of going below zero
+func (s *CatalogService) AdjustStock(ctx context.Context, id string, delta int) (*models.Product, error) {
+	if delta == 0 {
+		return nil, fmt.Errorf("%w: delta must not be zero", ErrInvalidProduct)
+	}
+	if err := s.repo.AdjustStock(ctx, id, delta); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Product stock adjusted", zap.String("id", id), zap.Int("delta", delta))
+	s.invalidate(ctx, id)
+	s.publishUpdated(ctx, id)
+	return s.repo.GetByID(ctx, id)
+}
+
+// DeleteProduct removes a product from the catalog
+func (s *CatalogService) DeleteProduct(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.log.Info("Product deleted", zap.String("id", id))
+	s.invalidate(ctx, id)
+	_ = events.Publish(ctx, s.publisher, s.log, events.TypeProductDeleted, events.ProductDeleted{ProductID: id})
+	return nil
+}
+
+// GetProduct returns a product by ID at its current price on a channel, from cache when possible
+// Returns: repository.ErrProductNotFound for products hidden on the channel,
+// channel.ErrUnknownChannel for missing and inactive channels
+func (s *CatalogService) GetProduct(ctx context.Context, channelCode, id string) (*models.Product, error) {
+	rules, err := s.channels.Resolve(ctx, channelCode)
+	if err != nil {
+		return nil, err
+	}
+	if !rules.Visible(id) {
+		return nil, repository.ErrProductNotFound
+	}
+	product, err := cache.LoadJSONOrStale(ctx, s.cache, productCacheKey(id), productCacheTTL, staleCacheTTL, database.IsOutage,
+		func(ctx context.Context) (*models.Product, error) {
+			return s.repo.GetByID(ctx, id)
+		})
+	if err != nil {
+		return nil, err
+	}
+	// A group price replaces the list or channel price; a flash sale still wins when it is lower
+	group := s.groups.ForUser(ctx, callerID(ctx))
+	product.Price, product.Sale = s.pricing.Current(ctx).Sale(product.ID, group.Price(product.ID, rules.Price(product.ID, product.Price)))
+	product.CustomerGroup, product.TaxTreatment = group.Group, group.TaxTreatment
+	product.Restricted = !product.SellableIn(httpctx.GeoFromContext(ctx).Country)
+	product.PriceTiers = s.callerTiers(ctx, product)
+	return product, nil
+}
+
+// callerTiers keeps the price tiers open to the caller and prices them from the product's current price
+// Segment tiers need a signed-in caller (auth.Identify); anonymous callers see public tiers only
+func (s *CatalogService) callerTiers(ctx context.Context, product *models.Product) []models.PriceTier {
+	if len(product.PriceTiers) == 0 {
+		return nil
+	}
+
+	var segments []string
+	if userID := callerID(ctx); userID != "" {
+		ids, err := s.segments.SegmentsOf(ctx, userID)
+		if err != nil {
+			s.log.Warn("Failed to load customer segments, showing public price tiers", zap.Error(err))
+		}
+		segments = ids
+	}
+
+	tiers := make([]models.PriceTier, 0, len(product.PriceTiers))
+	for _, tier := range product.PriceTiers {
+		if tier.SegmentID != "" && !slices.Contains(segments, tier.SegmentID) {
+			continue
+		}
+		tier.UnitPrice = tier.Discount(product.Price)
+		tiers = append(tiers, tier)
+	}
+	return tiers
+}
+
+// callerID is the signed-in caller, or "" for anonymous requests (see auth.Identify)
+func callerID(ctx context.Context) string {
+	if user, ok := httpctx.UserFromContext(ctx); ok {
+		return user.ID
+	}
+	return ""
+}
+
+// CheckSellable fails with ErrRestrictedCountry when the product may not be sold to country
+// Checkout calls it for every line with the shipping country, or the GeoIP country
+// (httpctx.GeoFromContext) when there is no shipping address yet
+func (s *CatalogService) CheckSellable(ctx context.Context, id, country string) error {
+	product, err := cache.LoadJSON(ctx, s.cache, productCacheKey(id), productCacheTTL,
+		func(ctx context.Context) (*models.Product, error) {
+			return s.repo.GetByID(ctx, id)
+		})
+	if err != nil {
+		return err
+	}
+	if !product.SellableIn(strings.ToUpper(country)) {
+		return fmt.Errorf("%w: %s", ErrRestrictedCountry, strings.ToUpper(country))
+	}
+	return nil
+}
+
+// CheckQuantity fails with ErrQuantityNotAllowed when quantity of the product can't be ordered
+// The cart calls it when a line is added or changed, with the line's new quantity
+func (s *CatalogService) CheckQuantity(ctx context.Context, id string, quantity int) error {
+	product, err := cache.LoadJSON(ctx, s.cache, productCacheKey(id), productCacheTTL,
+		func(ctx context.Context) (*models.Product, error) {
+			return s.repo.GetByID(ctx, id)
+		})
+	if err != nil {
+		return err
+	}
+	if problem := product.QuantityProblem(quantity); problem != "" {
+		return fmt.Errorf("%w: %s: %s", ErrQuantityNotAllowed, product.SKU, problem)
+	}
+	return nil
+}
+
+// ValidateCart checks every line of a cart against its product's quantity rules and the whole
+// cart against the max_order_quantity and max_order_lines settings
+// Lines of the same product are added up first. Checkout calls it before reserving stock.
+func (s *CatalogService) ValidateCart(ctx context.Context, lines []models.CartLine) error {
+	if len(lines) == 0 {
+		return fmt.Errorf("%w: the cart is empty", ErrQuantityNotAllowed)
+	}
+
+	quantities := make(map[string]int, len(lines))
+	var order []string
+	total := 0
+	for _, line := range lines {
+		if line.ProductID == "" || line.Quantity <= 0 {
+			return fmt.Errorf("%w: every line needs a product_id and a positive quantity", ErrQuantityNotAllowed)
+		}
+		if _, seen := quantities[line.ProductID]; !seen {
+			order = append(order, line.ProductID)
+		}
+		quantities[line.ProductID] += line.Quantity
+		total += line.Quantity
+	}
+
+	if limit := s.settings.MaxOrderLines(ctx); limit > 0 && len(order) > limit {
+		return fmt.Errorf("%w: at most %d different products per order", ErrQuantityNotAllowed, limit)
+	}
+	if limit := s.settings.MaxOrderQuantity(ctx); limit > 0 && total > limit {
+		return fmt.Errorf("%w: at most %d items per order", ErrQuantityNotAllowed, limit)
+	}
+	for _, id := range order {
+		if err := s.CheckQuantity(ctx, id, quantities[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListProducts returns a page of active products on a channel ordered by name, from the listing read model
+// The read model trails writes by one event delivery
+func (s *CatalogService) ListProducts(ctx context.Context, channelCode, category string, limit, offset int) (*models.ProductListingResponse, error) {
+	rules, err := s.channels.Resolve(ctx, channelCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// One extra row tells whether another page exists
+	listings, err := s.listings.List(ctx, category, rules.Hidden(), limit+1, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.ProductListingResponse{Products: listings, Limit: limit, Offset: offset}
+	if len(listings) > limit {
+		resp.Products, resp.HasMore = listings[:limit], true
+	}
+	if resp.Products == nil {
+		resp.Products = []models.ProductListing{}
+	}
+
+	prices := s.pricing.Current(ctx)
+	for i := range resp.Products {
+		listing := &resp.Products[i]
+		listing.Price, listing.Sale = prices.Sale(listing.ProductID, rules.Price(listing.ProductID, listing.Price))
+	}
+	return resp, nil
+}
+
+// Categories returns the category tree with active product counts, from cache when possible
+func (s *CatalogService) Categories(ctx context.Context) ([]models.Category, error) {
+	return cache.LoadJSONOrStale(ctx, s.cache, categoriesCacheKey, categoriesCacheTTL, staleCacheTTL, database.IsOutage,
+		func(ctx context.Context) ([]models.Category, error) {
+			counts, err := s.repo.CategoryCounts(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return buildCategoryTree(counts), nil
+		})
+}
+
+// buildCategoryTree nests "a/b/c" category paths; counts roll up into every ancestor
+// counts must be sorted by category so siblings come out in alphabetical order
+func buildCategoryTree(counts []models.CategoryCount) []models.Category {
+	roots := []models.Category{}
+	for _, c := range counts {
+		level := &roots
+		var path []string
+		for _, segment := range strings.Split(c.Category, "/") {
+			segment = strings.TrimSpace(segment)
+			if segment == "" {
+				continue
+			}
+			path = append(path, segment)
+
+			var node *models.Category
+			for i := range *level {
+				if (*level)[i].Name == segment {
+					node = &(*level)[i]
+					break
+				}
+			}
+			if node == nil {
+				*level = append(*level, models.Category{Name: segment, Path: strings.Join(path, "/")})
+				node = &(*level)[len(*level)-1]
+			}
+			node.ProductCount += c.Count
+			level = &node.Children
+		}
+	}
+	return roots
+}
+
+// invalidate drops the category tree and the given products from the cache
+// Failures are logged only; entries expire after their TTL anyway
+func (s *CatalogService) invalidate(ctx context.Context, productIDs ...string) {
+	keys := []string{categoriesCacheKey}
+	for _, id := range productIDs {
+		keys = append(keys, productCacheKey(id))
+	}
+	if err := s.cache.Invalidate(ctx, keys...); err != nil {
+		s.log.Warn("Failed to invalidate catalog cache", zap.Strings("keys", keys), zap.Error(err))
+	}
+}
+
+// HandleProductChanged drops a product from the cache when it changes outside this service,
+// e.g. warehouse receipts moving its stock
+func (s *CatalogService) HandleProductChanged(ctx context.Context, event events.Event) error {
+	var payload events.ProductUpdated
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	s.invalidate(ctx, payload.ProductID)
+	return nil
+}
+
+// skuTaken reports a product write that hit the unique SKU index as ErrSKUTaken
+func skuTaken(err error) error {
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		return ErrSKUTaken
+	}
+	return err
+}
+
+func productCacheKey(id string) string {
+	return "catalog:product:" + id
+}
+
+// EachProduct feeds every product, including inactive ones, to fn in batches for streaming exports
+func (s *CatalogService) EachProduct(ctx context.Context, batchSize int, fn func([]models.Product) error) error {
+	return s.repo.Each(ctx, batchSize, fn)
+}
+
+// Search queries the search engine, or the database when none is configured
+func (s *CatalogService) Search(ctx context.Context, channelCode string, q search.Query) (*models.ProductSearchResponse, error) {
+	rules, err := s.channels.Resolve(ctx, channelCode)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.ProductSearchResponse{Limit: q.Limit, Offset: q.Offset}
+
+	if s.engine != nil {
+		results, err := s.engine.Search(ctx, s.index, q)
+		if err != nil {
+			s.log.Error("Search engine query failed", zap.String("engine", s.engine.Name()), zap.Error(err))
+			return nil, err
+		}
+		resp.Hits, resp.Total = results.Hits, results.Total
+	} else {
+		products, total, err := s.repo.Search(ctx, q.Text, q.Category, q.Limit, q.Offset)
+		if err != nil {
+			return nil, err
+		}
+		resp.Hits = make([]models.ProductDocument, 0, len(products))
+		for i := range products {
+			resp.Hits = append(resp.Hits, models.NewProductDocument(&products[i]))
+		}
+		resp.Total = total
+	}
+
+	// The index doesn't know about channels, so hidden products are dropped from the page here;
+	// Total still counts them
+	prices := s.pricing.Current(ctx)
+	hits := resp.Hits[:0]
+	for _, hit := range resp.Hits {
+		if !rules.Visible(hit.ID) {
+			continue
+		}
+		hit.Price, hit.Sale = prices.Sale(hit.ID, rules.Price(hit.ID, hit.Price))
+		hits = append(hits, hit)
+	}
+	resp.Hits = hits
+	return resp, nil
+}
+
+// publishUpdated is best effort - the next reindex repairs a lost event
+func (s *CatalogService) publishUpdated(ctx context.Context, id string) {
+	_ = events.Publish(ctx, s.publisher, s.log, events.TypeProductUpdated, events.ProductUpdated{ProductID: id})
+}
+
+// checkTierSegments makes sure the segments price tiers are limited to exist
+func (s *CatalogService) checkTierSegments(ctx context.Context, tiers []models.PriceTier) error {
+	for _, tier := range tiers {
+		if tier.SegmentID == "" {
+			continue
+		}
+		if _, err := s.segments.GetByID(ctx, tier.SegmentID); errors.Is(err, repository.ErrSegmentNotFound) {
+			return fmt.Errorf("%w: segment %s not found", ErrInvalidProduct, tier.SegmentID)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyRequest validates req and copies it onto product
+// defaultCurrency applies when req omits the currency
+func applyRequest(product *models.Product, req *models.ProductRequest, defaultCurrency string) error {
+	sku := strings.TrimSpace(req.SKU)
+	name := strings.TrimSpace(req.Name)
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = defaultCurrency
+	}
+
+	switch {
+	case sku == "" || name == "":
+		return fmt.Errorf("%w: sku and name are required", ErrInvalidProduct)
+	case len(currency) != 3:
+		return fmt.Errorf("%w: currency must be an ISO 4217 code", ErrInvalidProduct)
+	case req.Price < 0:
+		return fmt.Errorf("%w: price cannot be negative", ErrInvalidProduct)
+	case req.Stock < 0:
+		return fmt.Errorf("%w: stock cannot be negative", ErrInvalidProduct)
+	case req.MinQuantity < 0 || req.MaxQuantity < 0 || req.QuantityStep < 0:
+		return fmt.Errorf("%w: quantity rules cannot be negative", ErrInvalidProduct)
+	case req.MaxQuantity > 0 && req.MaxQuantity < req.MinQuantity:
+		return fmt.Errorf("%w: max_quantity is below min_quantity", ErrInvalidProduct)
+	case req.QuantityStep > 1 && (req.MinQuantity%req.QuantityStep != 0 || req.MaxQuantity%req.QuantityStep != 0):
+		return fmt.Errorf("%w: min_quantity and max_quantity must be multiples of quantity_step", ErrInvalidProduct)
+	}
+
+	var restricted []string
+	for _, country := range req.RestrictedCountries {
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if len(country) != 2 || strings.Trim(country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return fmt.Errorf("%w: restricted_countries must be ISO 3166-1 alpha-2 codes", ErrInvalidProduct)
+		}
+		if !slices.Contains(restricted, country) {
+			restricted = append(restricted, country)
+		}
+	}
+
+	tiers, err := priceTiers(req.PriceTiers)
+	if err != nil {
+		return err
+	}
+
+	product.SKU = sku
+	product.Name = name
+	product.Description = strings.TrimSpace(req.Description)
+	product.Category = strings.TrimSpace(req.Category)
+	product.Price = req.Price
+	product.Currency = currency
+	product.Stock = req.Stock
+	product.Active = req.Active == nil || *req.Active
+	product.RestrictedCountries = restricted
+	product.MinQuantity = req.MinQuantity
+	product.MaxQuantity = req.MaxQuantity
+	product.QuantityStep = req.QuantityStep
+	product.PriceTiers = tiers
+	return nil
+}
+
+// priceTiers validates price tiers and orders them by segment, then quantity
+// Each segment (and the public tiers) may have one tier per quantity break
+func priceTiers(requested []models.PriceTier) ([]models.PriceTier, error) {
+	tiers := make([]models.PriceTier, 0, len(requested))
+	for _, tier := range requested {
+		tier.SegmentID = strings.TrimSpace(tier.SegmentID)
+		tier.UnitPrice = 0
+		switch {
+		case tier.MinQuantity < 2:
+			return nil, fmt.Errorf("%w: price tier min_quantity must be at least 2", ErrInvalidProduct)
+		case tier.DiscountBPS <= 0 || tier.DiscountBPS >= 10000:
+			return nil, fmt.Errorf("%w: price tier discount_bps must be between 1 and 9999", ErrInvalidProduct)
+		}
+		for _, other := range tiers {
+			if other.SegmentID == tier.SegmentID && other.MinQuantity == tier.MinQuantity {
+				return nil, fmt.Errorf("%w: two price tiers start at %d", ErrInvalidProduct, tier.MinQuantity)
+			}
+		}
+		tiers = append(tiers, tier)
+	}
+
+	slices.SortFunc(tiers, func(a, b models.PriceTier) int {
+		if a.SegmentID != b.SegmentID {
+			return strings.Compare(a.SegmentID, b.SegmentID)
+		}
+		return a.MinQuantity - b.MinQuantity
+	})
+	if len(tiers) == 0 {
+		return nil, nil
+	}
+	return tiers, nil
+}
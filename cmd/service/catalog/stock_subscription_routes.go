@@ -0,0 +1,165 @@
+package catalog
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type StockSubscriptionHandler struct {
+	service *StockSubscriptionService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewStockSubscriptionHandler(service *StockSubscriptionService, tokens *auth.TokenManager, log *zap.Logger) *StockSubscriptionHandler {
+	return &StockSubscriptionHandler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers back-in-stock subscriptions for the caller and the admin demand view
+func (h *StockSubscriptionHandler) RegisterRoutes(router *mux.Router) {
+	product := router.PathPrefix("/products/{id}/stock-subscription").Subrouter()
+	product.Use(auth.Authenticate(h.tokens))
+	product.HandleFunc("", h.handleSubscribe).Methods("POST")
+	product.HandleFunc("", h.handleUnsubscribe).Methods("DELETE")
+
+	me := router.PathPrefix("/users/me/stock-subscriptions").Subrouter()
+	me.Use(auth.Authenticate(h.tokens))
+	me.HandleFunc("", h.handleList).Methods("GET")
+
+	admin := router.PathPrefix("/admin/products/{id}/stock-subscriptions").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("", h.handleDemand).Methods("GET")
+}
+
+// handleSubscribe handles POST /api/v1/products/{id}/stock-subscription
+// @Summary Subscribe to a restock alert
+// @Description Notifies the caller once, on their stock_alerts channel, when the out-of-stock product is available again. Subscribing again after an alert waits for the next restock.
+// @Tags Catalog
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} models.StockSubscription "Already subscribed"
+// @Success 201 {object} models.StockSubscription
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Product is in stock"
+// @Failure 500 {string} string "Internal server error"
+// @Router /products/{id}/stock-subscription [post]
+func (h *StockSubscriptionHandler) handleSubscribe(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	sub, created, err := h.service.Subscribe(r.Context(), claims.UserID(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	response.JSON(w, status, sub)
+}
+
+// handleUnsubscribe handles DELETE /api/v1/products/{id}/stock-subscription
+// @Summary Cancel a restock alert
+// @Tags Catalog
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 204
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Stock subscription not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /products/{id}/stock-subscription [delete]
+func (h *StockSubscriptionHandler) handleUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	if err := h.service.Unsubscribe(r.Context(), claims.UserID(), mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleList handles GET /api/v1/users/me/stock-subscriptions
+// @Summary List my restock alerts
+// @Description The caller's stock subscriptions, newest first, with whether each product is in stock now
+// @Tags Catalog
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "active or notified"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.StockSubscriptionListResponse
+// @Failure 400 {string} string "Invalid status"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/me/stock-subscriptions [get]
+func (h *StockSubscriptionHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+	limit, offset := pagination.FromRequest(r)
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.StockSubscriptionActive, models.StockSubscriptionNotified:
+	default:
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.List(r.Context(), claims.UserID(), status, limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleDemand handles GET /api/v1/admin/products/{id}/stock-subscriptions
+// @Summary Restock demand
+// @Description How many customers are waiting to be told the product is back in stock
+// @Tags Catalog
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} models.StockDemandResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/products/{id}/stock-subscriptions [get]
+func (h *StockSubscriptionHandler) handleDemand(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.Demand(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// writeError maps stock subscription errors to 404/409/500
+func (h *StockSubscriptionHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrStockSubscriptionNotFound):
+		http.Error(w, "Stock subscription not found", http.StatusNotFound)
+	case errors.Is(err, ErrProductInStock):
+		http.Error(w, "Product is in stock", http.StatusConflict)
+	default:
+		h.log.Error("Stock subscription request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
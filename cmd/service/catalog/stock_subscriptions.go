@@ -0,0 +1,215 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/notify"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// JobNotifyRestock alerts the customers waiting for a product that is in stock again
+const JobNotifyRestock = "catalog.notify_restock"
+
+// restockBatch is how many subscribers are loaded per query while alerting
+const restockBatch = 100
+
+// ErrProductInStock is returned when subscribing to a product that can be bought right now
+var ErrProductInStock = errors.New("product is in stock")
+
+// restockJob is the payload of JobNotifyRestock
+type restockJob struct {
+	ProductID string `json:"product_id"`
+}
+
+// StockSubscriptionService lets customers wait for out-of-stock products
+// Product events queue JobNotifyRestock; the job alerts subscribers oldest first on their
+// stock_alerts channel and marks each subscription notified, so every one fires once
+type StockSubscriptionService struct {
+	repo     *repository.StockSubscriptionRepository
+	products repository.ProductStore
+	notifier *notify.Notifier
+	jobs     *jobs.Processor
+	clock    clock.Clock
+	log      *zap.Logger
+}
+
+func NewStockSubscriptionService(repo *repository.StockSubscriptionRepository, products repository.ProductStore,
+	notifier *notify.Notifier, processor *jobs.Processor, clk clock.Clock, log *zap.Logger) *StockSubscriptionService {
+	return &StockSubscriptionService{
+		repo:     repo,
+		products: products,
+		notifier: notifier,
+		jobs:     processor,
+		clock:    clk,
+		log:      log,
+	}
+}
+
+// Subscribe asks for an alert when productID is restocked
+// Returns: the subscription and whether it is new (or reactivated after an earlier alert);
+// ErrProductInStock, or repository.ErrProductNotFound for unknown and inactive products
+func (s *StockSubscriptionService) Subscribe(ctx context.Context, userID, productID string) (*models.StockSubscription, bool, error) {
+	product, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !product.Active {
+		return nil, false, repository.ErrProductNotFound
+	}
+	if product.Stock > 0 {
+		return nil, false, ErrProductInStock
+	}
+
+	sub, err := s.repo.Get(ctx, userID, productID)
+	switch {
+	case errors.Is(err, repository.ErrStockSubscriptionNotFound):
+		sub = &models.StockSubscription{UserID: userID, ProductID: productID}
+		if err := s.repo.Create(ctx, sub); err != nil {
+			return nil, false, err
+		}
+	case err != nil:
+		return nil, false, err
+	case sub.NotifiedAt == nil:
+		sub.SetStatus()
+		return sub, false, nil
+	default:
+		if err := s.repo.Reactivate(ctx, sub, s.clock.Now()); err != nil {
+			return nil, false, err
+		}
+	}
+
+	s.log.Info("Stock subscription created", zap.String("user_id", userID), zap.String("product_id", productID))
+	sub.SetStatus()
+	return sub, true, nil
+}
+
+// Unsubscribe removes userID's subscription to productID
+// Returns: repository.ErrStockSubscriptionNotFound if there is none
+func (s *StockSubscriptionService) Unsubscribe(ctx context.Context, userID, productID string) error {
+	return s.repo.Delete(ctx, userID, productID)
+}
+
+// List returns a page of userID's subscriptions with a summary of each product
+// Subscriptions to deleted products are listed without one
+func (s *StockSubscriptionService) List(ctx context.Context, userID, status string,
+	limit, offset int) (*models.StockSubscriptionListResponse, error) {
+	subs, total, err := s.repo.ListByUser(ctx, userID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range subs {
+		subs[i].SetStatus()
+		product, err := s.products.GetByID(ctx, subs[i].ProductID)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		subs[i].Product = &models.StockSubscriptionProduct{Name: product.Name, InStock: product.Active && product.Stock > 0}
+	}
+
+	return &models.StockSubscriptionListResponse{
+		Subscriptions: subs,
+		Total:         total,
+		Limit:         limit,
+		Offset:        offset,
+	}, nil
+}
+
+// Demand returns how many customers wait for productID
+func (s *StockSubscriptionService) Demand(ctx context.Context, productID string) (*models.StockDemandResponse, error) {
+	if _, err := s.products.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	waiting, err := s.repo.CountWaiting(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.StockDemandResponse{ProductID: productID, Waiting: waiting}, nil
+}
+
+// HandleProductChanged queues alerts for product.updated and product.deleted when anyone waits
+// The job decides whether the product is actually back (or gone, which drops its subscriptions)
+func (s *StockSubscriptionService) HandleProductChanged(ctx context.Context, event events.Event) error {
+	var payload events.ProductUpdated
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	waiting, err := s.repo.CountWaiting(ctx, payload.ProductID)
+	if err != nil || waiting == 0 {
+		return err
+	}
+	_, err = s.jobs.Enqueue(ctx, JobNotifyRestock, restockJob{ProductID: payload.ProductID})
+	return err
+}
+
+// handleRestockJob alerts waiting subscribers while the product stays in stock
+// Each subscription is claimed before its alert is sent, so concurrent jobs for the same
+// product never alert anyone twice; a failed delivery releases the claim and the job retries
+func (s *StockSubscriptionService) handleRestockJob(ctx context.Context, job *models.Job) error {
+	var payload restockJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	for {
+		product, err := s.products.GetByID(ctx, payload.ProductID)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			removed, err := s.repo.DeleteByProduct(ctx, payload.ProductID)
+			if err == nil && removed > 0 {
+				s.log.Info("Removed stock subscriptions of deleted product",
+					zap.String("product_id", payload.ProductID), zap.Int64("count", removed))
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		// Sold out again (or hidden) mid-way: the rest wait for the next restock
+		if !product.Active || product.Stock <= 0 {
+			return nil
+		}
+
+		subs, err := s.repo.ListWaiting(ctx, product.ID, restockBatch)
+		if err != nil || len(subs) == 0 {
+			return err
+		}
+		for _, sub := range subs {
+			if err := s.alert(ctx, product, &sub); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// alert claims sub and notifies its owner
+func (s *StockSubscriptionService) alert(ctx context.Context, product *models.Product, sub *models.StockSubscription) error {
+	claimed, err := s.repo.MarkNotified(ctx, sub.ID, s.clock.Now())
+	if err != nil || !claimed {
+		return err
+	}
+
+	err = s.notifier.Notify(ctx, sub.UserID, notify.Notification{
+		Category: models.NotifyStockAlerts,
+		Subject:  fmt.Sprintf("%s is back in stock", product.Name),
+		Body:     fmt.Sprintf("Good news: %s is back in stock. Quantities may be limited.", product.Name),
+	})
+	if err != nil {
+		if unmarkErr := s.repo.Unmark(ctx, sub.ID); unmarkErr != nil {
+			s.log.Error("Failed to release stock subscription after failed alert",
+				zap.String("id", sub.ID), zap.Error(unmarkErr))
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/links"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/Jason-Omondi/ecomgo/internal/vendor"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type VendorProductHandler struct {
+	service *CatalogService
+	vendors *repository.VendorRepository
+	tokens  *auth.TokenManager
+	links   *links.Builder
+	log     *zap.Logger
+}
+
+func NewVendorProductHandler(service *CatalogService, vendors *repository.VendorRepository, tokens *auth.TokenManager,
+	resourceLinks *links.Builder, log *zap.Logger) *VendorProductHandler {
+	return &VendorProductHandler{
+		service: service,
+		vendors: vendors,
+		tokens:  tokens,
+		links:   resourceLinks,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers the vendor dashboard's product management
+func (h *VendorProductHandler) RegisterRoutes(router *mux.Router) {
+	products := router.PathPrefix("/vendor/products").Subrouter()
+	products.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleVendor), vendor.Require(h.vendors, h.log))
+	products.HandleFunc("", h.handleList).Methods("GET")
+	products.HandleFunc("", h.handleCreate).Methods("POST")
+	products.HandleFunc("/{id}", h.handleGet).Methods("GET")
+	products.HandleFunc("/{id}", h.handleUpdate).Methods("PUT")
+	products.HandleFunc("/{id}/stock", h.handleAdjustStock).Methods("POST")
+	products.HandleFunc("/{id}", h.handleDelete).Methods("DELETE")
+}
+
+// handleList handles GET /api/v1/vendor/products
+// @Summary List own products
+// @Description The caller's products by name, including inactive ones
+// @Tags Vendors
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.VendorProductListResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a vendor"
+// @Failure 500 {string} string "Internal server error"
+// @Router /vendor/products [get]
+func (h *VendorProductHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	resp, err := h.service.ListVendorProducts(r.Context(), vendor.FromContext(r.Context()).ID, limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleCreate handles POST /api/v1/vendor/products
+// @Summary Create own product
+// @Description Adds a product sold by the caller. It is listed, searched and priced like any other product.
+// @Tags Vendors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ProductRequest true "Product"
+// @Success 201 {object} models.Product
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a vendor"
+// @Router /vendor/products [post]
+func (h *VendorProductHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req models.ProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.CreateVendorProduct(r.Context(), vendor.FromContext(r.Context()).ID, &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	product.Links = h.links.Product(product.ID, product.Category)
+	response.JSON(w, http.StatusCreated, product)
+}
+
+// handleGet handles GET /api/v1/vendor/products/{id}
+// @Summary Get own product
+// @Tags Vendors
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} models.Product
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a vendor"
+// @Failure 404 {string} string "Product not found"
+// @Router /vendor/products/{id} [get]
+func (h *VendorProductHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	product, err := h.service.GetVendorProduct(r.Context(), vendor.FromContext(r.Context()).ID, mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	product.Links = h.links.Product(product.ID, product.Category)
+	response.JSON(w, http.StatusOK, product)
+}
+
+// handleUpdate handles PUT /api/v1/vendor/products/{id}
+// @Summary Update own product
+// @Tags Vendors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body models.ProductRequest true "Product"
+// @Success 200 {object} models.Product
+// @Failure 400 {string} string "Invalid request"
+// @Failure 403 {string} string "Not a vendor"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Insufficient stock"
+// @Router /vendor/products/{id} [put]
+func (h *VendorProductHandler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req models.ProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.UpdateVendorProduct(r.Context(), vendor.FromContext(r.Context()).ID, mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	product.Links = h.links.Product(product.ID, product.Category)
+	response.JSON(w, http.StatusOK, product)
+}
+
+// handleAdjustStock handles POST /api/v1/vendor/products/{id}/stock
+// @Summary Adjust own product stock
+// @Description Adds delta to the current stock in one atomic update. A decrement larger than the stock left is rejected.
+// @Tags Vendors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body models.StockAdjustmentRequest true "Stock change"
+// @Success 200 {object} models.Product
+// @Failure 400 {string} string "Invalid request"
+// @Failure 403 {string} string "Not a vendor"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Insufficient stock"
+// @Router /vendor/products/{id}/stock [post]
+func (h *VendorProductHandler) handleAdjustStock(w http.ResponseWriter, r *http.Request) {
+	var req models.StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.AdjustVendorStock(r.Context(), vendor.FromContext(r.Context()).ID, mux.Vars(r)["id"], req.Delta)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	product.Links = h.links.Product(product.ID, product.Category)
+	response.JSON(w, http.StatusOK, product)
+}
+
+// handleDelete handles DELETE /api/v1/vendor/products/{id}
+// @Summary Delete own product
+// @Description Past sales of the product still count toward the vendor's earnings.
+// @Tags Vendors
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 204 "Deleted"
+// @Failure 403 {string} string "Not a vendor"
+// @Failure 404 {string} string "Product not found"
+// @Router /vendor/products/{id} [delete]
+func (h *VendorProductHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteVendorProduct(r.Context(), vendor.FromContext(r.Context()).ID, mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError maps catalog errors to HTTP status codes
+func (h *VendorProductHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidProduct):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrInsufficientStock):
+		http.Error(w, "Insufficient stock", http.StatusConflict)
+	default:
+		h.log.Error("Vendor product request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,70 @@
+package catalog
+
+import (
+	"context"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+)
+
+// Vendor-scoped product management: the same writes as the admin endpoints, limited to
+// products the vendor owns. Another vendor's product (or the store's own) is reported as
+// not found, so vendors can't probe each other's catalog.
+
+// ListVendorProducts returns a page of a vendor's products, including inactive ones
+func (s *CatalogService) ListVendorProducts(ctx context.Context, vendorID string, limit, offset int) (*models.VendorProductListResponse, error) {
+	products, total, err := s.repo.ListByVendor(ctx, vendorID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if products == nil {
+		products = []models.Product{}
+	}
+	return &models.VendorProductListResponse{Products: products, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// CreateVendorProduct adds a product owned by vendorID
+func (s *CatalogService) CreateVendorProduct(ctx context.Context, vendorID string, req *models.ProductRequest) (*models.Product, error) {
+	return s.createProduct(ctx, &models.Product{VendorID: vendorID}, req)
+}
+
+// UpdateVendorProduct replaces one of the vendor's products, see UpdateProduct
+func (s *CatalogService) UpdateVendorProduct(ctx context.Context, vendorID, id string, req *models.ProductRequest) (*models.Product, error) {
+	if err := s.checkOwner(ctx, vendorID, id); err != nil {
+		return nil, err
+	}
+	return s.UpdateProduct(ctx, id, req)
+}
+
+// AdjustVendorStock changes the stock of one of the vendor's products, see AdjustStock
+func (s *CatalogService) AdjustVendorStock(ctx context.Context, vendorID, id string, delta int) (*models.Product, error) {
+	if err := s.checkOwner(ctx, vendorID, id); err != nil {
+		return nil, err
+	}
+	return s.AdjustStock(ctx, id, delta)
+}
+
+// DeleteVendorProduct removes one of the vendor's products
+func (s *CatalogService) DeleteVendorProduct(ctx context.Context, vendorID, id string) error {
+	if err := s.checkOwner(ctx, vendorID, id); err != nil {
+		return err
+	}
+	return s.DeleteProduct(ctx, id)
+}
+
+// GetVendorProduct returns one of the vendor's products at its list price
+func (s *CatalogService) GetVendorProduct(ctx context.Context, vendorID, id string) (*models.Product, error) {
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if product.VendorID != vendorID {
+		return nil, repository.ErrProductNotFound
+	}
+	return product, nil
+}
+
+func (s *CatalogService) checkOwner(ctx context.Context, vendorID, id string) error {
+	_, err := s.GetVendorProduct(ctx, vendorID, id)
+	return err
+}
@@ -0,0 +1,64 @@
+package channel
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Module provides sales channels (web, app, pos...): admin management of channels and their
+// product overrides, and sales per channel recorded from order events
+// The catalog applies the overrides through deps.Channels
+type Module struct {
+	handler *Handler
+}
+
+func NewModule(deps module.Deps) *Module {
+	service := NewChannelService(repository.NewChannelRepository(deps.DB, deps.Log),
+		repository.NewProductRepository(deps.DB, deps.Log), deps.Channels, deps.Clock, deps.Log)
+
+	handlers := map[string]events.Handler{
+		events.TypeOrderPlaced:    service.HandleOrderPlaced,
+		events.TypeProductDeleted: service.HandleProductDeleted,
+	}
+	for eventType, handler := range handlers {
+		if err := deps.Events.Subscribe(eventType, "channels", handler); err != nil {
+			deps.Log.Error("Failed to subscribe channels to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+
+	return &Module{
+		handler: NewHandler(service, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.Channel{}, &models.ChannelProduct{}, &models.ChannelOrder{}),
+		seedChannels,
+	}
+}
+
+// seedChannels creates the built-in channels; existing ones are left as they are
+func seedChannels(db *gorm.DB) error {
+	builtIn := []models.Channel{
+		{Code: models.ChannelWeb, Name: "Web store", Active: true},
+		{Code: models.ChannelApp, Name: "Mobile app", Active: true},
+		{Code: models.ChannelPOS, Name: "Point of sale", Active: true},
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&builtIn).Error
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
@@ -0,0 +1,274 @@
+package channel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *ChannelService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *ChannelService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers sales channel routes; all of them are admin-only
+// Clients pick their channel with the X-Channel header on catalog requests
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/channels", h.handleCreate).Methods("POST")
+	admin.HandleFunc("/channels", h.handleList).Methods("GET")
+	admin.HandleFunc("/channels/{code}", h.handleGet).Methods("GET")
+	admin.HandleFunc("/channels/{code}", h.handleUpdate).Methods("PATCH")
+	admin.HandleFunc("/channels/{code}/products", h.handleProducts).Methods("GET")
+	admin.HandleFunc("/channels/{code}/products/{productId}", h.handleSetProduct).Methods("PUT")
+	admin.HandleFunc("/channels/{code}/products/{productId}", h.handleDeleteProduct).Methods("DELETE")
+	admin.HandleFunc("/reports/channels", h.handleReport).Methods("GET")
+}
+
+// handleCreate handles POST /api/v1/admin/channels
+// @Summary Create channel
+// @Description Adds a sales channel clients can name with the X-Channel header. web, app and pos exist from the start.
+// @Tags Channels
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ChannelRequest true "Channel"
+// @Success 201 {object} models.Channel
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 409 {string} string "Channel already exists"
+// @Router /admin/channels [post]
+func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req models.ChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	ch, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, ch)
+}
+
+// handleList handles GET /api/v1/admin/channels
+// @Summary List channels
+// @Tags Channels
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Channel
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/channels [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	channels, err := h.service.List(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, channels)
+}
+
+// handleGet handles GET /api/v1/admin/channels/{code}
+// @Summary Get channel
+// @Tags Channels
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Channel code"
+// @Success 200 {object} models.Channel
+// @Failure 404 {string} string "Channel not found"
+// @Router /admin/channels/{code} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	ch, err := h.service.Get(r.Context(), mux.Vars(r)["code"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, ch)
+}
+
+// handleUpdate handles PATCH /api/v1/admin/channels/{code}
+// @Summary Update channel
+// @Description Renames a channel or (de)activates it. Catalog requests naming an inactive channel are refused; web can't be deactivated.
+// @Tags Channels
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Channel code"
+// @Param request body models.ChannelRequest true "Fields to change"
+// @Success 200 {object} models.Channel
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Channel not found"
+// @Router /admin/channels/{code} [patch]
+func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req models.ChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	ch, err := h.service.Update(r.Context(), mux.Vars(r)["code"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, ch)
+}
+
+// handleProducts handles GET /api/v1/admin/channels/{code}/products
+// @Summary List channel product overrides
+// @Description Products hidden or repriced on the channel; every other product sells as in the catalog
+// @Tags Channels
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Channel code"
+// @Success 200 {array} models.ChannelProduct
+// @Failure 404 {string} string "Channel not found"
+// @Router /admin/channels/{code}/products [get]
+func (h *Handler) handleProducts(w http.ResponseWriter, r *http.Request) {
+	overrides, err := h.service.Products(r.Context(), mux.Vars(r)["code"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, overrides)
+}
+
+// handleSetProduct handles PUT /api/v1/admin/channels/{code}/products/{productId}
+// @Summary Set channel product override
+// @Description Hides a product on the channel or sets its list price there. Flash sale prices still apply on top of a channel price.
+// @Tags Channels
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Channel code"
+// @Param productId path string true "Product ID"
+// @Param request body models.ChannelProductRequest true "Override"
+// @Success 200 {object} models.ChannelProduct
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Channel or product not found"
+// @Router /admin/channels/{code}/products/{productId} [put]
+func (h *Handler) handleSetProduct(w http.ResponseWriter, r *http.Request) {
+	var req models.ChannelProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	vars := mux.Vars(r)
+	override, err := h.service.SetProduct(r.Context(), vars["code"], vars["productId"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, override)
+}
+
+// handleDeleteProduct handles DELETE /api/v1/admin/channels/{code}/products/{productId}
+// @Summary Remove channel product override
+// @Tags Channels
+// @Security BearerAuth
+// @Param code path string true "Channel code"
+// @Param productId path string true "Product ID"
+// @Success 204
+// @Failure 404 {string} string "Override not found"
+// @Router /admin/channels/{code}/products/{productId} [delete]
+func (h *Handler) handleDeleteProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.service.DeleteProduct(r.Context(), vars["code"], vars["productId"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleReport handles GET /api/v1/admin/reports/channels
+// @Summary Sales by channel
+// @Description Orders and revenue per channel and currency placed in [from, to). Defaults to the last 30 days; at most 366 days.
+// @Tags Channels
+// @Produce json
+// @Security BearerAuth
+// @Param channel query string false "Only this channel"
+// @Param from query string false "Start, RFC 3339"
+// @Param to query string false "End (exclusive), RFC 3339"
+// @Success 200 {object} models.ChannelSalesReport
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/reports/channels [get]
+func (h *Handler) handleReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, err := parseTime(query, "from")
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	to, err := parseTime(query, "to")
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	report, err := h.service.Report(r.Context(), strings.ToLower(strings.TrimSpace(query.Get("channel"))), from, to)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, report)
+}
+
+// parseTime reads an optional RFC 3339 query parameter; absent means the zero time
+func parseTime(query url.Values, name string) (time.Time, error) {
+	raw := strings.TrimSpace(query.Get(name))
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be an RFC 3339 time", ErrInvalidChannel, name)
+	}
+	return t, nil
+}
+
+// writeError maps channel errors to 400/404/409/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidChannel):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrChannelNotFound):
+		http.Error(w, "Channel not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrChannelProductNotFound):
+		http.Error(w, "Override not found", http.StatusNotFound)
+	case errors.Is(err, ErrChannelExists):
+		http.Error(w, "Channel already exists", http.StatusConflict)
+	default:
+		h.log.Error("Channel request failed", zap.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
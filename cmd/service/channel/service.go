@@ -0,0 +1,234 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/channel"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// maxReportRange bounds GET /admin/reports/channels so one report can't scan years of orders
+const maxReportRange = 366 * 24 * time.Hour
+
+var (
+	// ErrInvalidChannel wraps validation problems with channel requests
+	ErrInvalidChannel = errors.New("invalid channel request")
+	// ErrChannelExists is returned when creating a channel whose code is taken
+	ErrChannelExists = errors.New("channel already exists")
+)
+
+var channelCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// ChannelService manages sales channels and their product overrides, and reports sales per channel
+// Every change invalidates the channel's cached rules, which the catalog reads per request
+type ChannelService struct {
+	repo     *repository.ChannelRepository
+	products repository.ProductStore
+	catalog  *channel.Catalog
+	clock    clock.Clock
+	log      *zap.Logger
+}
+
+func NewChannelService(repo *repository.ChannelRepository, products repository.ProductStore, catalog *channel.Catalog,
+	clk clock.Clock, log *zap.Logger) *ChannelService {
+	return &ChannelService{
+		repo:     repo,
+		products: products,
+		catalog:  catalog,
+		clock:    clk,
+		log:      log,
+	}
+}
+
+// List returns every channel
+func (s *ChannelService) List(ctx context.Context) ([]models.Channel, error) {
+	channels, err := s.repo.List(ctx)
+	if channels == nil && err == nil {
+		channels = []models.Channel{}
+	}
+	return channels, err
+}
+
+// Get returns one channel
+func (s *ChannelService) Get(ctx context.Context, code string) (*models.Channel, error) {
+	return s.repo.Get(ctx, code)
+}
+
+// Create adds a channel, active unless req says otherwise
+func (s *ChannelService) Create(ctx context.Context, req *models.ChannelRequest) (*models.Channel, error) {
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	name := strings.TrimSpace(req.Name)
+	switch {
+	case !channelCodePattern.MatchString(code):
+		return nil, fmt.Errorf("%w: code must be up to 32 characters of a-z, 0-9, - and _, starting with a letter", ErrInvalidChannel)
+	case name == "":
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidChannel)
+	}
+
+	ch := &models.Channel{Code: code, Name: name, Active: req.Active == nil || *req.Active}
+	created, err := s.repo.Create(ctx, ch)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrChannelExists
+	}
+
+	s.log.Info("Channel created", zap.String("code", code))
+	s.invalidate(ctx, code)
+	return ch, nil
+}
+
+// Update renames a channel or (de)activates it; its code never changes
+func (s *ChannelService) Update(ctx context.Context, code string, req *models.ChannelRequest) (*models.Channel, error) {
+	ch, err := s.repo.Get(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		ch.Name = name
+	}
+	if req.Active != nil {
+		if !*req.Active && code == models.ChannelWeb {
+			return nil, fmt.Errorf("%w: the web channel is the default and can't be deactivated", ErrInvalidChannel)
+		}
+		ch.Active = *req.Active
+	}
+	if err := s.repo.Update(ctx, ch); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Channel updated", zap.String("code", code), zap.Bool("active", ch.Active))
+	s.invalidate(ctx, code)
+	return ch, nil
+}
+
+// Products returns the product overrides of a channel
+func (s *ChannelService) Products(ctx context.Context, code string) ([]models.ChannelProduct, error) {
+	if _, err := s.repo.Get(ctx, code); err != nil {
+		return nil, err
+	}
+	overrides, err := s.repo.Products(ctx, code)
+	if overrides == nil && err == nil {
+		overrides = []models.ChannelProduct{}
+	}
+	return overrides, err
+}
+
+// SetProduct hides a product on a channel or gives it a channel price
+func (s *ChannelService) SetProduct(ctx context.Context, code, productID string, req *models.ChannelProductRequest) (*models.ChannelProduct, error) {
+	if req.Price != nil && *req.Price < 0 {
+		return nil, fmt.Errorf("%w: price cannot be negative", ErrInvalidChannel)
+	}
+	if _, err := s.repo.Get(ctx, code); err != nil {
+		return nil, err
+	}
+	if _, err := s.products.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	override := &models.ChannelProduct{ChannelCode: code, ProductID: productID, Hidden: req.Hidden, Price: req.Price}
+	if err := s.repo.SetProduct(ctx, override); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, code)
+	return override, nil
+}
+
+// DeleteProduct removes a product's override, so the channel sells it like the catalog does
+func (s *ChannelService) DeleteProduct(ctx context.Context, code, productID string) error {
+	if err := s.repo.DeleteProduct(ctx, code, productID); err != nil {
+		return err
+	}
+	s.invalidate(ctx, code)
+	return nil
+}
+
+// Report totals orders placed in [from, to) per channel and currency, optionally on one channel
+// Zero times default to the last 30 days
+func (s *ChannelService) Report(ctx context.Context, code string, from, to time.Time) (*models.ChannelSalesReport, error) {
+	if to.IsZero() {
+		to = s.clock.Now()
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	switch {
+	case !from.Before(to):
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidChannel)
+	case to.Sub(from) > maxReportRange:
+		return nil, fmt.Errorf("%w: reports cover at most 366 days", ErrInvalidChannel)
+	}
+
+	sales, err := s.repo.Sales(ctx, code, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	if sales == nil {
+		sales = []models.ChannelSales{}
+	}
+	return &models.ChannelSalesReport{From: from.UTC(), To: to.UTC(), Sales: sales}, nil
+}
+
+// HandleOrderPlaced records which channel an order came through, for reporting
+func (s *ChannelService) HandleOrderPlaced(ctx context.Context, event events.Event) error {
+	var payload events.OrderPlaced
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	if payload.OrderID == "" {
+		return nil
+	}
+	code := strings.ToLower(strings.TrimSpace(payload.Channel))
+	if code == "" {
+		code = models.ChannelWeb
+	}
+	placedAt := event.OccurredAt
+	if placedAt.IsZero() {
+		placedAt = s.clock.Now()
+	}
+	return s.repo.RecordOrder(ctx, &models.ChannelOrder{
+		OrderID:     payload.OrderID,
+		ChannelCode: code,
+		Total:       payload.Total,
+		Currency:    strings.ToUpper(payload.Currency),
+		PlacedAt:    placedAt.UTC(),
+	})
+}
+
+// HandleProductDeleted removes a deleted product's overrides on every channel
+func (s *ChannelService) HandleProductDeleted(ctx context.Context, event events.Event) error {
+	var payload events.ProductDeleted
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	removed, err := s.repo.DeleteProductEverywhere(ctx, payload.ProductID)
+	if err != nil || removed == 0 {
+		return err
+	}
+	channels, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ch := range channels {
+		s.invalidate(ctx, ch.Code)
+	}
+	return nil
+}
+
+// invalidate drops a channel's cached rules
+// Failures are logged only; the rules expire after their TTL anyway
+func (s *ChannelService) invalidate(ctx context.Context, code string) {
+	if err := s.catalog.Invalidate(ctx, code); err != nil {
+		s.log.Warn("Failed to invalidate channel rules", zap.String("channel", code), zap.Error(err))
+	}
+}
@@ -0,0 +1,54 @@
+package currency
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jason-Omondi/ecomgo/internal/fx"
+	"github.com/Jason-Omondi/ecomgo/internal/lock"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/gorilla/mux"
+)
+
+// Module provides exchange rate and shopper country endpoints and runs the scheduled rate refresh
+// Other modules convert prices through deps.FX directly
+type Module struct {
+	handler   *Handler
+	converter *fx.Converter
+	refresher lock.Service // converter.Run on the elected leader only
+}
+
+func NewModule(deps module.Deps) *Module {
+	return &Module{
+		handler:   NewHandler(deps.FX, deps.Settings, deps.Log),
+		converter: deps.FX,
+		refresher: lock.Singleton(deps.Locks, deps.FX, deps.Config.Locks.LeaderTTL, deps.Log),
+	}
+}
+
+// Warm loads the latest persisted exchange rates into the cache so the first priced request doesn't
+// With none persisted yet (first deploy) there is nothing to prime - the refresher fetches them
+func (m *Module) Warm(ctx context.Context) error {
+	_, err := m.converter.Latest(ctx)
+	if errors.Is(err, fx.ErrRatesUnavailable) {
+		return nil
+	}
+	return err
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.ExchangeRate{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+// Services runs the scheduled rate refresh
+func (m *Module) Services() []module.Service {
+	return []module.Service{m.refresher}
+}
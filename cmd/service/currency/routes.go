@@ -0,0 +1,150 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/fx"
+	"github.com/Jason-Omondi/ecomgo/internal/geoip"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	converter *fx.Converter
+	settings  *settings.Store // default currency when the shopper's has no rate
+	log       *zap.Logger
+}
+
+func NewHandler(converter *fx.Converter, storeSettings *settings.Store, log *zap.Logger) *Handler {
+	return &Handler{
+		converter: converter,
+		settings:  storeSettings,
+		log:       log,
+	}
+}
+
+// RegisterRoutes registers exchange rate routes
+// Public: storefronts use them to display prices in the shopper's currency
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/fx/rates", h.handleRates).Methods("GET")
+	router.HandleFunc("/fx/convert", h.handleConvert).Methods("GET")
+	router.HandleFunc("/geo", h.handleGeo).Methods("GET")
+}
+
+// handleRates handles GET /api/v1/fx/rates
+// @Summary Current exchange rates
+// @Description Latest rates against the store's base currency
+// @Tags Currency
+// @Produce json
+// @Success 200 {object} models.ExchangeRatesResponse
+// @Failure 503 {string} string "Exchange rates unavailable"
+// @Router /fx/rates [get]
+func (h *Handler) handleRates(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := h.converter.Latest(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, models.ExchangeRatesResponse{
+		Base:      snapshot.Base,
+		Rates:     snapshot.Rates,
+		FetchedAt: snapshot.FetchedAt,
+		Provider:  h.converter.ProviderName(),
+	})
+}
+
+// handleConvert handles GET /api/v1/fx/convert
+// @Summary Convert amount between currencies
+// @Description Converts an amount in minor units (cents) of one currency into minor units of another
+// @Tags Currency
+// @Produce json
+// @Param amount query int true "Amount in minor units of from"
+// @Param from query string true "ISO 4217 source currency"
+// @Param to query string false "ISO 4217 target currency (default: the shopper's, see /geo)"
+// @Success 200 {object} models.ConversionResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 503 {string} string "Exchange rates unavailable"
+// @Router /fx/convert [get]
+func (h *Handler) handleConvert(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from := strings.ToUpper(query.Get("from"))
+	to := strings.ToUpper(query.Get("to"))
+	amount, err := strconv.ParseInt(query.Get("amount"), 10, 64)
+	if err != nil || from == "" {
+		http.Error(w, "amount and from are required", http.StatusBadRequest)
+		return
+	}
+	if to == "" {
+		to = h.localCurrency(r.Context(), httpctx.GeoFromContext(r.Context()).Country)
+	}
+
+	converted, rate, err := h.converter.Convert(r.Context(), amount, from, to)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, models.ConversionResponse{
+		From:            from,
+		To:              to,
+		Amount:          amount,
+		ConvertedAmount: converted,
+		Rate:            rate,
+	})
+}
+
+// handleGeo handles GET /api/v1/geo
+// @Summary Shopper's country defaults
+// @Description The caller's country from GeoIP, the currency to display prices in and the tax region to estimate taxes for. country and tax_region are empty when the country is unknown; currency is then the store's default.
+// @Tags Currency
+// @Produce json
+// @Success 200 {object} models.GeoResponse
+// @Router /geo [get]
+func (h *Handler) handleGeo(w http.ResponseWriter, r *http.Request) {
+	country := httpctx.GeoFromContext(r.Context()).Country
+	response.JSON(w, http.StatusOK, models.GeoResponse{
+		Country:   country,
+		Currency:  h.localCurrency(r.Context(), country),
+		TaxRegion: country,
+	})
+}
+
+// localCurrency returns the currency shoppers in country pay in when prices can be shown in it,
+// and the store's default currency otherwise
+func (h *Handler) localCurrency(ctx context.Context, country string) string {
+	defaultCurrency := h.settings.DefaultCurrency(ctx)
+	currency := geoip.CurrencyOf(country)
+	if currency == "" || currency == defaultCurrency {
+		return defaultCurrency
+	}
+	snapshot, err := h.converter.Latest(ctx)
+	if err != nil {
+		return defaultCurrency
+	}
+	if _, ok := snapshot.Rates[currency]; !ok && currency != snapshot.Base {
+		return defaultCurrency
+	}
+	return currency
+}
+
+// writeError maps converter errors to 400/503/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, fx.ErrUnknownCurrency):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, fx.ErrRatesUnavailable):
+		http.Error(w, "Exchange rates unavailable", http.StatusServiceUnavailable)
+	default:
+		h.log.Error("Exchange rate lookup failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,65 @@
+package customergroup
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Module provides customer groups (retail, wholesale, VIP...): admin management of groups,
+// their price lists and who is in them, and customers' tax profiles (VAT numbers and exemptions)
+// The catalog prices signed-in shoppers for their group through deps.Groups
+type Module struct {
+	handler    *Handler
+	taxHandler *TaxProfileHandler
+}
+
+func NewModule(deps module.Deps) *Module {
+	users := repository.NewUserRepository(deps.DB, deps.Log)
+	service := NewGroupService(repository.NewCustomerGroupRepository(deps.DB, deps.Log),
+		repository.NewProductRepository(deps.DB, deps.Log), users, deps.Groups, deps.Log)
+	taxProfiles := NewTaxProfileService(repository.NewTaxProfileRepository(deps.DB, deps.Log), users,
+		deps.TaxIDs, deps.Groups, deps.Clock, deps.Log)
+
+	if err := deps.Events.Subscribe(events.TypeProductDeleted, "customer-groups", service.HandleProductDeleted); err != nil {
+		deps.Log.Error("Failed to subscribe customer groups to event", zap.String("type", events.TypeProductDeleted), zap.Error(err))
+	}
+
+	return &Module{
+		handler:    NewHandler(service, deps.Tokens, deps.Log),
+		taxHandler: NewTaxProfileHandler(taxProfiles, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.CustomerGroup{}, &models.CustomerGroupPrice{}, &models.CustomerGroupMember{},
+			&models.TaxProfile{}),
+		seedGroups,
+	}
+}
+
+// seedGroups creates the built-in groups; existing ones are left as they are
+func seedGroups(db *gorm.DB) error {
+	builtIn := []models.CustomerGroup{
+		{Code: models.GroupRetail, Name: "Retail", TaxTreatment: models.TaxInclusive},
+		{Code: models.GroupWholesale, Name: "Wholesale", TaxTreatment: models.TaxExclusive},
+		{Code: models.GroupVIP, Name: "VIP", TaxTreatment: models.TaxInclusive},
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&builtIn).Error
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+	m.taxHandler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
@@ -0,0 +1,272 @@
+package customergroup
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *GroupService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *GroupService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers customer group routes; all of them are admin-only
+// Shoppers get their group's prices on product detail and cart quotes when signed in
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/customer-groups", h.handleCreate).Methods("POST")
+	admin.HandleFunc("/customer-groups", h.handleList).Methods("GET")
+	admin.HandleFunc("/customer-groups/{code}", h.handleGet).Methods("GET")
+	admin.HandleFunc("/customer-groups/{code}", h.handleUpdate).Methods("PATCH")
+	admin.HandleFunc("/customer-groups/{code}/prices", h.handlePrices).Methods("GET")
+	admin.HandleFunc("/customer-groups/{code}/prices/{productId}", h.handleSetPrice).Methods("PUT")
+	admin.HandleFunc("/customer-groups/{code}/prices/{productId}", h.handleDeletePrice).Methods("DELETE")
+	admin.HandleFunc("/users/{id}/customer-group", h.handleMembership).Methods("GET")
+	admin.HandleFunc("/users/{id}/customer-group", h.handleAssign).Methods("PUT")
+}
+
+// handleCreate handles POST /api/v1/admin/customer-groups
+// @Summary Create customer group
+// @Description Adds a customer group with its own price list and tax treatment. retail, wholesale and vip exist from the start.
+// @Tags Customer Groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CustomerGroupRequest true "Customer group"
+// @Success 201 {object} models.CustomerGroup
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 409 {string} string "Customer group already exists"
+// @Router /admin/customer-groups [post]
+func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req models.CustomerGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	group, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, group)
+}
+
+// handleList handles GET /api/v1/admin/customer-groups
+// @Summary List customer groups
+// @Tags Customer Groups
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.CustomerGroup
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/customer-groups [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.service.List(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, groups)
+}
+
+// handleGet handles GET /api/v1/admin/customer-groups/{code}
+// @Summary Get customer group
+// @Tags Customer Groups
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Group code"
+// @Success 200 {object} models.CustomerGroup
+// @Failure 404 {string} string "Customer group not found"
+// @Router /admin/customer-groups/{code} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	group, err := h.service.Get(r.Context(), mux.Vars(r)["code"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, group)
+}
+
+// handleUpdate handles PATCH /api/v1/admin/customer-groups/{code}
+// @Summary Update customer group
+// @Description Renames a group or changes its tax treatment (inclusive, exclusive or exempt)
+// @Tags Customer Groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Group code"
+// @Param request body models.CustomerGroupRequest true "Fields to change"
+// @Success 200 {object} models.CustomerGroup
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Customer group not found"
+// @Router /admin/customer-groups/{code} [patch]
+func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req models.CustomerGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	group, err := h.service.Update(r.Context(), mux.Vars(r)["code"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, group)
+}
+
+// handlePrices handles GET /api/v1/admin/customer-groups/{code}/prices
+// @Summary List customer group prices
+// @Description The group's price list; every other product sells to the group at its usual price
+// @Tags Customer Groups
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Group code"
+// @Success 200 {array} models.CustomerGroupPrice
+// @Failure 404 {string} string "Customer group not found"
+// @Router /admin/customer-groups/{code}/prices [get]
+func (h *Handler) handlePrices(w http.ResponseWriter, r *http.Request) {
+	prices, err := h.service.Prices(r.Context(), mux.Vars(r)["code"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, prices)
+}
+
+// handleSetPrice handles PUT /api/v1/admin/customer-groups/{code}/prices/{productId}
+// @Summary Set customer group price
+// @Description Sets the product's price for the group, replacing its list and channel price. Flash sale prices still apply when lower.
+// @Tags Customer Groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Group code"
+// @Param productId path string true "Product ID"
+// @Param request body models.CustomerGroupPriceRequest true "Price"
+// @Success 200 {object} models.CustomerGroupPrice
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Customer group or product not found"
+// @Router /admin/customer-groups/{code}/prices/{productId} [put]
+func (h *Handler) handleSetPrice(w http.ResponseWriter, r *http.Request) {
+	var req models.CustomerGroupPriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	vars := mux.Vars(r)
+	price, err := h.service.SetPrice(r.Context(), vars["code"], vars["productId"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, price)
+}
+
+// handleDeletePrice handles DELETE /api/v1/admin/customer-groups/{code}/prices/{productId}
+// @Summary Remove customer group price
+// @Tags Customer Groups
+// @Security BearerAuth
+// @Param code path string true "Group code"
+// @Param productId path string true "Product ID"
+// @Success 204
+// @Failure 404 {string} string "Price not found"
+// @Router /admin/customer-groups/{code}/prices/{productId} [delete]
+func (h *Handler) handleDeletePrice(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.service.DeletePrice(r.Context(), vars["code"], vars["productId"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleMembership handles GET /api/v1/admin/users/{id}/customer-group
+// @Summary Get a customer's group
+// @Tags Customer Groups
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} models.CustomerGroupMembership
+// @Failure 404 {string} string "User not found"
+// @Router /admin/users/{id}/customer-group [get]
+func (h *Handler) handleMembership(w http.ResponseWriter, r *http.Request) {
+	membership, err := h.service.Membership(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, membership)
+}
+
+// handleAssign handles PUT /api/v1/admin/users/{id}/customer-group
+// @Summary Assign a customer's group
+// @Description Moves the customer into a group; retail (or an empty group) takes them out of theirs. Their next request is priced for the new group.
+// @Tags Customer Groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body models.CustomerGroupAssignRequest true "Group"
+// @Success 200 {object} models.CustomerGroupMembership
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "User or customer group not found"
+// @Router /admin/users/{id}/customer-group [put]
+func (h *Handler) handleAssign(w http.ResponseWriter, r *http.Request) {
+	var req models.CustomerGroupAssignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	adminID := auth.ClaimsFromContext(r.Context()).UserID()
+	membership, err := h.service.Assign(r.Context(), mux.Vars(r)["id"], adminID, &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, membership)
+}
+
+// writeError maps customer group errors to 400/404/409/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidGroup):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrCustomerGroupNotFound):
+		http.Error(w, "Customer group not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrUserNotFound):
+		http.Error(w, "User not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrCustomerGroupPriceNotFound):
+		http.Error(w, "Price not found", http.StatusNotFound)
+	case errors.Is(err, ErrGroupExists):
+		http.Error(w, "Customer group already exists", http.StatusConflict)
+	default:
+		h.log.Error("Customer group request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,218 @@
+package customergroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/customergroup"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidGroup wraps validation problems with customer group requests
+	ErrInvalidGroup = errors.New("invalid customer group request")
+	// ErrGroupExists is returned when creating a group whose code is taken
+	ErrGroupExists = errors.New("customer group already exists")
+)
+
+var groupCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+var taxTreatments = []string{models.TaxInclusive, models.TaxExclusive, models.TaxExempt}
+
+// GroupService manages customer groups, their price lists and who is in them
+// Every change invalidates the cached rules or membership the catalog reads per request
+type GroupService struct {
+	repo     *repository.CustomerGroupRepository
+	products repository.ProductStore
+	users    *repository.UserRepository
+	pricing  *customergroup.Pricing
+	log      *zap.Logger
+}
+
+func NewGroupService(repo *repository.CustomerGroupRepository, products repository.ProductStore,
+	users *repository.UserRepository, pricing *customergroup.Pricing, log *zap.Logger) *GroupService {
+	return &GroupService{
+		repo:     repo,
+		products: products,
+		users:    users,
+		pricing:  pricing,
+		log:      log,
+	}
+}
+
+// List returns every group
+func (s *GroupService) List(ctx context.Context) ([]models.CustomerGroup, error) {
+	groups, err := s.repo.List(ctx)
+	if groups == nil && err == nil {
+		groups = []models.CustomerGroup{}
+	}
+	return groups, err
+}
+
+// Get returns one group
+func (s *GroupService) Get(ctx context.Context, code string) (*models.CustomerGroup, error) {
+	return s.repo.Get(ctx, code)
+}
+
+// Create adds a group; its prices include tax unless req says otherwise
+func (s *GroupService) Create(ctx context.Context, req *models.CustomerGroupRequest) (*models.CustomerGroup, error) {
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	name := strings.TrimSpace(req.Name)
+	treatment := strings.ToLower(strings.TrimSpace(req.TaxTreatment))
+	if treatment == "" {
+		treatment = models.TaxInclusive
+	}
+	switch {
+	case !groupCodePattern.MatchString(code):
+		return nil, fmt.Errorf("%w: code must be up to 32 characters of a-z, 0-9, - and _, starting with a letter", ErrInvalidGroup)
+	case name == "":
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidGroup)
+	case !slices.Contains(taxTreatments, treatment):
+		return nil, fmt.Errorf("%w: tax_treatment must be inclusive, exclusive or exempt", ErrInvalidGroup)
+	}
+
+	group := &models.CustomerGroup{Code: code, Name: name, TaxTreatment: treatment}
+	created, err := s.repo.Create(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrGroupExists
+	}
+
+	s.log.Info("Customer group created", zap.String("code", code))
+	s.invalidate(ctx, code)
+	return group, nil
+}
+
+// Update renames a group or changes its tax treatment; its code never changes
+func (s *GroupService) Update(ctx context.Context, code string, req *models.CustomerGroupRequest) (*models.CustomerGroup, error) {
+	group, err := s.repo.Get(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		group.Name = name
+	}
+	if treatment := strings.ToLower(strings.TrimSpace(req.TaxTreatment)); treatment != "" {
+		if !slices.Contains(taxTreatments, treatment) {
+			return nil, fmt.Errorf("%w: tax_treatment must be inclusive, exclusive or exempt", ErrInvalidGroup)
+		}
+		group.TaxTreatment = treatment
+	}
+	if err := s.repo.Update(ctx, group); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Customer group updated", zap.String("code", code), zap.String("tax_treatment", group.TaxTreatment))
+	s.invalidate(ctx, code)
+	return group, nil
+}
+
+// Prices returns the price list of a group
+func (s *GroupService) Prices(ctx context.Context, code string) ([]models.CustomerGroupPrice, error) {
+	if _, err := s.repo.Get(ctx, code); err != nil {
+		return nil, err
+	}
+	prices, err := s.repo.Prices(ctx, code)
+	if prices == nil && err == nil {
+		prices = []models.CustomerGroupPrice{}
+	}
+	return prices, err
+}
+
+// SetPrice gives a product a price for the group
+func (s *GroupService) SetPrice(ctx context.Context, code, productID string, req *models.CustomerGroupPriceRequest) (*models.CustomerGroupPrice, error) {
+	if req.Price < 0 {
+		return nil, fmt.Errorf("%w: price cannot be negative", ErrInvalidGroup)
+	}
+	if _, err := s.repo.Get(ctx, code); err != nil {
+		return nil, err
+	}
+	if _, err := s.products.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	price := &models.CustomerGroupPrice{GroupCode: code, ProductID: productID, Price: req.Price}
+	if err := s.repo.SetPrice(ctx, price); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, code)
+	return price, nil
+}
+
+// DeletePrice removes a product from the group's price list, so the group pays the usual price
+func (s *GroupService) DeletePrice(ctx context.Context, code, productID string) error {
+	if err := s.repo.DeletePrice(ctx, code, productID); err != nil {
+		return err
+	}
+	s.invalidate(ctx, code)
+	return nil
+}
+
+// Membership returns a customer's group; customers without one are retail
+func (s *GroupService) Membership(ctx context.Context, userID string) (*models.CustomerGroupMembership, error) {
+	if _, err := s.users.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	rules := s.pricing.ForUser(ctx, userID)
+	return &models.CustomerGroupMembership{UserID: userID, Group: rules.Group, TaxTreatment: rules.TaxTreatment}, nil
+}
+
+// Assign moves a customer into a group; retail (or "") takes them out of their group
+func (s *GroupService) Assign(ctx context.Context, userID, adminID string, req *models.CustomerGroupAssignRequest) (*models.CustomerGroupMembership, error) {
+	if _, err := s.users.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	code := strings.ToLower(strings.TrimSpace(req.Group))
+	if code == "" || code == models.GroupRetail {
+		if err := s.repo.Unassign(ctx, userID); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := s.repo.Get(ctx, code); err != nil {
+			return nil, err
+		}
+		if err := s.repo.Assign(ctx, &models.CustomerGroupMember{UserID: userID, GroupCode: code, AssignedBy: adminID}); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.pricing.InvalidateMember(ctx, userID); err != nil {
+		s.log.Warn("Failed to invalidate customer group membership", zap.String("user_id", userID), zap.Error(err))
+	}
+
+	s.log.Info("Customer group assigned", zap.String("user_id", userID), zap.String("group", code), zap.String("admin_id", adminID))
+	return s.Membership(ctx, userID)
+}
+
+// HandleProductDeleted removes a deleted product from every group's price list
+func (s *GroupService) HandleProductDeleted(ctx context.Context, event events.Event) error {
+	var payload events.ProductDeleted
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	codes, err := s.repo.DeleteProductEverywhere(ctx, payload.ProductID)
+	if err != nil {
+		return err
+	}
+	for _, code := range codes {
+		s.invalidate(ctx, code)
+	}
+	return nil
+}
+
+// invalidate drops a group's cached rules
+// Failures are logged only; the rules expire after their TTL anyway
+func (s *GroupService) invalidate(ctx context.Context, code string) {
+	if err := s.pricing.Invalidate(ctx, code); err != nil {
+		s.log.Warn("Failed to invalidate customer group rules", zap.String("group", code), zap.Error(err))
+	}
+}
@@ -0,0 +1,189 @@
+package customergroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/customergroup"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/taxid"
+	"go.uber.org/zap"
+)
+
+const maxExemptReasonLength = 255
+
+var (
+	// ErrInvalidTaxProfile wraps validation problems with tax profile requests
+	ErrInvalidTaxProfile = errors.New("invalid tax profile request")
+	// ErrVATIDRejected is returned when the tax registry doesn't know a new VAT number
+	ErrVATIDRejected = errors.New("VAT number not found in the tax registry")
+)
+
+// TaxProfileService manages customers' VAT/TIN numbers and tax exemptions
+// Numbers are checked with the configured registry; a registry that can't answer leaves them
+// unverified instead of turning the customer away. Every change invalidates the customer's
+// cached membership, so their next request is priced with it
+type TaxProfileService struct {
+	repo      *repository.TaxProfileRepository
+	users     *repository.UserRepository
+	validator taxid.Validator
+	pricing   *customergroup.Pricing
+	clock     clock.Clock
+	log       *zap.Logger
+}
+
+func NewTaxProfileService(repo *repository.TaxProfileRepository, users *repository.UserRepository,
+	validator taxid.Validator, pricing *customergroup.Pricing, clk clock.Clock, log *zap.Logger) *TaxProfileService {
+	return &TaxProfileService{
+		repo:      repo,
+		users:     users,
+		validator: validator,
+		pricing:   pricing,
+		clock:     clk,
+		log:       log,
+	}
+}
+
+// Get returns userID's tax profile; customers who never set one get an empty profile
+func (s *TaxProfileService) Get(ctx context.Context, userID string) (*models.TaxProfile, error) {
+	profile, err := s.repo.Get(ctx, userID)
+	if errors.Is(err, repository.ErrTaxProfileNotFound) {
+		return &models.TaxProfile{UserID: userID}, nil
+	}
+	return profile, err
+}
+
+// GetAny returns a customer's tax profile for admins
+func (s *TaxProfileService) GetAny(ctx context.Context, userID string) (*models.TaxProfile, error) {
+	if _, err := s.users.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID)
+}
+
+// Update replaces userID's VAT number, and with asAdmin their exemption
+// A new number must pass the format check and, when the registry answers, be registered
+func (s *TaxProfileService) Update(ctx context.Context, userID, adminID string, asAdmin bool, req *models.TaxProfileRequest) (*models.TaxProfile, error) {
+	reason := strings.TrimSpace(req.ExemptReason)
+	if asAdmin {
+		switch {
+		case req.Exempt && reason == "":
+			return nil, fmt.Errorf("%w: exempt_reason is required for an exemption", ErrInvalidTaxProfile)
+		case len(reason) > maxExemptReasonLength:
+			return nil, fmt.Errorf("%w: exempt_reason must be at most %d characters", ErrInvalidTaxProfile, maxExemptReasonLength)
+		case req.Exempt && req.ExemptUntil != nil && !req.ExemptUntil.After(s.clock.Now()):
+			return nil, fmt.Errorf("%w: exempt_until must be in the future", ErrInvalidTaxProfile)
+		}
+		if _, err := s.users.GetUserByID(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	profile, err := s.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(req.VATID) == "" {
+		profile.VATID, profile.Country, profile.VATStatus = "", "", ""
+		profile.VerifiedName, profile.VerifiedAddress, profile.CheckedAt = "", "", nil
+	} else {
+		id, err := taxid.Parse(req.VATID, req.Country)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTaxProfile, err)
+		}
+		// An unchanged number keeps its last registry answer; admins re-check with Verify
+		if id.String() != profile.VATID || id.Country != profile.Country {
+			profile.VATID, profile.Country = id.String(), id.Country
+			s.check(ctx, profile, id)
+			if profile.VATStatus == models.VATInvalid {
+				return nil, ErrVATIDRejected
+			}
+		}
+	}
+
+	if asAdmin {
+		if req.Exempt != profile.Exempt || reason != profile.ExemptReason || !sameTime(req.ExemptUntil, profile.ExemptUntil) {
+			profile.ExemptSetBy = adminID
+		}
+		profile.Exempt, profile.ExemptReason, profile.ExemptUntil = req.Exempt, reason, req.ExemptUntil
+		if !req.Exempt {
+			profile.ExemptReason, profile.ExemptUntil = "", nil
+		}
+	}
+
+	if err := s.save(ctx, profile); err != nil {
+		return nil, err
+	}
+	s.log.Info("Tax profile updated", zap.String("user_id", userID), zap.String("vat_status", profile.VATStatus),
+		zap.Bool("exempt", profile.Exempt), zap.String("admin_id", adminID))
+	return profile, nil
+}
+
+// Verify checks a customer's number with the registry again, e.g. before a filing (admin only)
+// Unlike Update, an unregistered number is recorded as invalid rather than rejected
+func (s *TaxProfileService) Verify(ctx context.Context, userID string) (*models.TaxProfile, error) {
+	profile, err := s.GetAny(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if profile.VATID == "" {
+		return nil, fmt.Errorf("%w: the customer has no VAT number", ErrInvalidTaxProfile)
+	}
+	id, err := taxid.Parse(profile.VATID, profile.Country)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTaxProfile, err)
+	}
+
+	s.check(ctx, profile, id)
+	if err := s.save(ctx, profile); err != nil {
+		return nil, err
+	}
+	s.log.Info("VAT number verified", zap.String("user_id", userID), zap.String("vat_status", profile.VATStatus))
+	return profile, nil
+}
+
+// check records the registry's answer for id on profile
+// Registry errors leave the number unverified; they are logged, not returned
+func (s *TaxProfileService) check(ctx context.Context, profile *models.TaxProfile, id taxid.ID) {
+	profile.VATStatus, profile.VerifiedName, profile.VerifiedAddress, profile.CheckedAt = models.VATUnverified, "", "", nil
+
+	result, err := s.validator.Check(ctx, id)
+	if errors.Is(err, taxid.ErrUnsupported) {
+		return
+	}
+	if err != nil {
+		s.log.Warn("Tax registry check failed, keeping VAT number unverified", zap.String("user_id", profile.UserID),
+			zap.String("country", id.Country), zap.Error(err))
+		return
+	}
+
+	now := s.clock.Now()
+	profile.CheckedAt = &now
+	if !result.Valid {
+		profile.VATStatus = models.VATInvalid
+		return
+	}
+	profile.VATStatus, profile.VerifiedName, profile.VerifiedAddress = models.VATValid, result.Name, result.Address
+}
+
+func (s *TaxProfileService) save(ctx context.Context, profile *models.TaxProfile) error {
+	if err := s.repo.Save(ctx, profile); err != nil {
+		return err
+	}
+	if err := s.pricing.InvalidateMember(ctx, profile.UserID); err != nil {
+		s.log.Warn("Failed to invalidate customer group membership", zap.String("user_id", profile.UserID), zap.Error(err))
+	}
+	return nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
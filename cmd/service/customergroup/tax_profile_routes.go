@@ -0,0 +1,169 @@
+package customergroup
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type TaxProfileHandler struct {
+	service *TaxProfileService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewTaxProfileHandler(service *TaxProfileService, tokens *auth.TokenManager, log *zap.Logger) *TaxProfileHandler {
+	return &TaxProfileHandler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers the caller's own tax profile and the admin routes that manage exemptions
+func (h *TaxProfileHandler) RegisterRoutes(router *mux.Router) {
+	me := router.PathPrefix("/users/me/tax-profile").Subrouter()
+	me.Use(auth.Authenticate(h.tokens))
+	me.HandleFunc("", h.handleGetMine).Methods("GET")
+	me.HandleFunc("", h.handleUpdateMine).Methods("PUT")
+
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/users/{id}/tax-profile", h.handleGet).Methods("GET")
+	admin.HandleFunc("/users/{id}/tax-profile", h.handleUpdate).Methods("PUT")
+	admin.HandleFunc("/users/{id}/tax-profile/verify", h.handleVerify).Methods("POST")
+}
+
+// handleGetMine handles GET /api/v1/users/me/tax-profile
+// @Summary Get my tax profile
+// @Description The caller's VAT/TIN number with its registry status, and their tax exemption if an admin granted one
+// @Tags Customer Groups
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.TaxProfile
+// @Router /users/me/tax-profile [get]
+func (h *TaxProfileHandler) handleGetMine(w http.ResponseWriter, r *http.Request) {
+	profile, err := h.service.Get(r.Context(), auth.ClaimsFromContext(r.Context()).UserID())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, profile)
+}
+
+// handleUpdateMine handles PUT /api/v1/users/me/tax-profile
+// @Summary Set my VAT number
+// @Description Sets or removes the caller's VAT/TIN number. Prefixed EU numbers (DE123456789) carry their country; other numbers need country, e.g. a KRA PIN with KE. A new number is checked with the tax registry when TAX_ID_PROVIDER has one for its country; otherwise it is kept as unverified. Exemption fields are ignored.
+// @Tags Customer Groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TaxProfileRequest true "VAT number"
+// @Success 200 {object} models.TaxProfile
+// @Failure 400 {string} string "Invalid request"
+// @Failure 422 {string} string "VAT number not found in the tax registry"
+// @Router /users/me/tax-profile [put]
+func (h *TaxProfileHandler) handleUpdateMine(w http.ResponseWriter, r *http.Request) {
+	var req models.TaxProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	profile, err := h.service.Update(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), "", false, &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, profile)
+}
+
+// handleGet handles GET /api/v1/admin/users/{id}/tax-profile
+// @Summary Get a customer's tax profile
+// @Tags Customer Groups
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} models.TaxProfile
+// @Failure 404 {string} string "User not found"
+// @Router /admin/users/{id}/tax-profile [get]
+func (h *TaxProfileHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	profile, err := h.service.GetAny(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, profile)
+}
+
+// handleUpdate handles PUT /api/v1/admin/users/{id}/tax-profile
+// @Summary Set a customer's tax profile
+// @Description Replaces the customer's VAT number and tax exemption. An exempt customer is priced with the exempt tax treatment whatever their group, until exempt_until; their orders record the exemption and VAT number for filings.
+// @Tags Customer Groups
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body models.TaxProfileRequest true "Tax profile"
+// @Success 200 {object} models.TaxProfile
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "User not found"
+// @Failure 422 {string} string "VAT number not found in the tax registry"
+// @Router /admin/users/{id}/tax-profile [put]
+func (h *TaxProfileHandler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req models.TaxProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	adminID := auth.ClaimsFromContext(r.Context()).UserID()
+	profile, err := h.service.Update(r.Context(), mux.Vars(r)["id"], adminID, true, &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, profile)
+}
+
+// handleVerify handles POST /api/v1/admin/users/{id}/tax-profile/verify
+// @Summary Re-check a customer's VAT number
+// @Description Asks the tax registry about the customer's number again and records the answer; a deregistered number becomes invalid. The exemption is left for the admin to review.
+// @Tags Customer Groups
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} models.TaxProfile
+// @Failure 400 {string} string "The customer has no VAT number"
+// @Failure 404 {string} string "User not found"
+// @Router /admin/users/{id}/tax-profile/verify [post]
+func (h *TaxProfileHandler) handleVerify(w http.ResponseWriter, r *http.Request) {
+	profile, err := h.service.Verify(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, profile)
+}
+
+// writeError maps tax profile errors to 400/404/422/500
+func (h *TaxProfileHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidTaxProfile):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrUserNotFound):
+		http.Error(w, "User not found", http.StatusNotFound)
+	case errors.Is(err, ErrVATIDRejected):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		h.log.Error("Tax profile request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
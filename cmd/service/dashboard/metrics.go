@@ -0,0 +1,114 @@
+package dashboard
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/batchwriter"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/limits"
+	"github.com/Jason-Omondi/ecomgo/internal/realtime"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TypeMetrics is the message type of periodic metric snapshots sent to dashboards
+const TypeMetrics = "metrics"
+
+// Metrics is a point-in-time snapshot of this instance
+type Metrics struct {
+	UptimeSeconds   int64  `json:"uptime_seconds"`
+	Goroutines      int    `json:"goroutines"`
+	HeapAllocBytes  uint64 `json:"heap_alloc_bytes"`
+	DBOpenConns     int    `json:"db_open_conns"`
+	DBInUseConns    int    `json:"db_in_use_conns"`
+	DBWaitCount     int64  `json:"db_wait_count"`
+	DashboardConns  int64  `json:"dashboard_conns"`
+	OrdersSinceBoot int64  `json:"orders_since_boot"`
+
+	AsyncWrites []batchwriter.Stats `json:"async_writes"` // buffered/dropped/failed audit inserts
+
+	// Saturation: jobs_running == job_concurrency, or limiters in_flight == limit with a growing queue
+	JobConcurrency int            `json:"job_concurrency"`
+	JobsRunning    int            `json:"jobs_running"`
+	Limiters       []limits.Stats `json:"limiters"` // API request admission and outbound HTTP calls
+}
+
+// MetricsCollector publishes a Metrics snapshot to connected dashboards every interval
+// Runs as a module service; snapshots are only collected while someone is watching
+type MetricsCollector struct {
+	hub      *realtime.Hub
+	db       *gorm.DB
+	jobs     *jobs.Processor
+	interval time.Duration
+	started  time.Time
+	log      *zap.Logger
+
+	conns  atomic.Int64
+	orders atomic.Int64
+}
+
+func NewMetricsCollector(hub *realtime.Hub, db *gorm.DB, processor *jobs.Processor, interval time.Duration,
+	log *zap.Logger) *MetricsCollector {
+	return &MetricsCollector{
+		hub:      hub,
+		db:       db,
+		jobs:     processor,
+		interval: interval,
+		started:  time.Now(),
+		log:      log,
+	}
+}
+
+func (c *MetricsCollector) Name() string {
+	return "dashboard-metrics"
+}
+
+func (c *MetricsCollector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if c.conns.Load() == 0 {
+				continue
+			}
+			event, err := events.NewEvent(TypeMetrics, c.snapshot())
+			if err != nil {
+				c.log.Error("Failed to encode dashboard metrics", zap.Error(err))
+				continue
+			}
+			c.hub.Publish(adminTopic, event)
+		}
+	}
+}
+
+func (c *MetricsCollector) snapshot() Metrics {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	m := Metrics{
+		UptimeSeconds:   int64(time.Since(c.started).Seconds()),
+		Goroutines:      runtime.NumGoroutine(),
+		HeapAllocBytes:  mem.HeapAlloc,
+		DashboardConns:  c.conns.Load(),
+		OrdersSinceBoot: c.orders.Load(),
+		AsyncWrites:     batchwriter.AllStats(),
+		JobConcurrency:  c.jobs.Concurrency(),
+		JobsRunning:     c.jobs.Running(),
+		Limiters:        limits.AllStats(),
+	}
+	if sqlDB, err := c.db.DB(); err == nil {
+		stats := sqlDB.Stats()
+		m.DBOpenConns = stats.OpenConnections
+		m.DBInUseConns = stats.InUse
+		m.DBWaitCount = stats.WaitCount
+	}
+	return m
+}
@@ -0,0 +1,74 @@
+package dashboard
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/lock"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/realtime"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// metricsInterval is how often connected dashboards receive a metrics snapshot
+const metricsInterval = 5 * time.Second
+
+// Module provides the admin live dashboard WebSocket, and dashboard reports served from read
+// models that the projector keeps up to date from domain events
+type Module struct {
+	handler   *Handler
+	reports   *ReportHandler
+	collector *MetricsCollector
+	pruner    module.Service // on the elected leader only
+}
+
+// NewModule relays new orders from every instance's bus subscription to local dashboard sockets,
+// and subscribes the projections in their own consumer group
+func NewModule(deps module.Deps) *Module {
+	hub := realtime.NewHub(deps.Log)
+	collector := NewMetricsCollector(hub, deps.DB, deps.Jobs, metricsInterval, deps.Log)
+
+	err := deps.Events.SubscribeAll(events.TypeOrderPlaced, func(ctx context.Context, event events.Event) error {
+		collector.orders.Add(1)
+		hub.Publish(adminTopic, event)
+		return nil
+	})
+	if err != nil {
+		deps.Log.Error("Failed to subscribe dashboard to orders", zap.Error(err))
+	}
+
+	repo := repository.NewDashboardRepository(deps.DB, deps.Log)
+	NewProjector(repo, deps.Log).Subscribe(deps.Events)
+	reports := NewReportService(repo, repository.NewProductRepository(deps.DB, deps.Log),
+		repository.NewUserRepository(deps.DB, deps.Log), deps.Settings, deps.Clock, deps.Log)
+
+	return &Module{
+		handler:   NewHandler(hub, collector, deps.Tokens, deps.Log),
+		reports:   NewReportHandler(reports, deps.Tokens, deps.Log),
+		collector: collector,
+		pruner:    lock.Singleton(deps.Locks, NewProjectionPruner(repo, deps.Log), deps.Config.Locks.LeaderTTL, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.DailySales{}, &models.DailyProductSales{}, &models.DailyCustomers{},
+			&models.CustomerStats{}, &models.CustomerSpend{}, &models.ProjectedEvent{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+	m.reports.RegisterRoutes(router)
+}
+
+// Services runs the metrics collector that feeds connected dashboards, and the pruner of
+// projected event IDs
+func (m *Module) Services() []module.Service {
+	return []module.Service{m.collector, m.pruner}
+}
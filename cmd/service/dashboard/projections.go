@@ -0,0 +1,140 @@
+package dashboard
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// projectionGroup is the consumer group of the projections; each event is applied by one instance
+	projectionGroup = "dashboard-projections"
+	// projectedRetention is how long applied event IDs are kept to skip redeliveries
+	projectedRetention = 7 * 24 * time.Hour
+	// pruneInterval is how often the pruner runs
+	pruneInterval = time.Hour
+)
+
+// Projector keeps the dashboard read models (daily sales, product sales, customer stats) up to
+// date from domain events, so dashboard reads never aggregate the transactional tables
+// Events published before the module first ran aren't backfilled
+type Projector struct {
+	repo *repository.DashboardRepository
+	log  *zap.Logger
+}
+
+func NewProjector(repo *repository.DashboardRepository, log *zap.Logger) *Projector {
+	return &Projector{repo: repo, log: log}
+}
+
+// Subscribe registers the projections' event handlers
+func (p *Projector) Subscribe(subscriber events.Subscriber) {
+	handlers := map[string]events.Handler{
+		events.TypeUserRegistered: p.HandleUserRegistered,
+		events.TypeOrderPlaced:    p.HandleOrderPlaced,
+		events.TypeRefundIssued:   p.HandleRefundIssued,
+	}
+	for eventType, handler := range handlers {
+		if err := subscriber.Subscribe(eventType, projectionGroup, handler); err != nil {
+			p.log.Error("Failed to subscribe dashboard projections to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+}
+
+// HandleUserRegistered counts a signup
+func (p *Projector) HandleUserRegistered(ctx context.Context, event events.Event) error {
+	_, err := p.repo.ProjectSignup(ctx, event.ID, occurredAt(event))
+	return err
+}
+
+// HandleOrderPlaced adds an order to sales, product sales and its customer's stats
+func (p *Projector) HandleOrderPlaced(ctx context.Context, event events.Event) error {
+	var payload events.OrderPlaced
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	if payload.OrderID == "" {
+		return nil
+	}
+	order := repository.ProjectedOrder{
+		UserID:   payload.UserID,
+		Currency: strings.ToUpper(payload.Currency),
+		Total:    payload.Total,
+		Items:    make([]models.OrderLine, 0, len(payload.Items)),
+		PlacedAt: occurredAt(event),
+	}
+	for _, item := range payload.Items {
+		order.Items = append(order.Items, models.OrderLine{ProductID: item.ProductID, Quantity: item.Quantity, UnitPrice: item.UnitPrice})
+	}
+	_, err := p.repo.ProjectOrder(ctx, event.ID, order)
+	return err
+}
+
+// HandleRefundIssued adds a refund to sales, product sales when it lists items, and its customer's stats
+func (p *Projector) HandleRefundIssued(ctx context.Context, event events.Event) error {
+	var payload events.RefundIssued
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	if payload.Amount <= 0 && len(payload.Items) == 0 {
+		return nil
+	}
+	refund := repository.ProjectedRefund{
+		UserID:   payload.UserID,
+		Currency: strings.ToUpper(payload.Currency),
+		Amount:   max(payload.Amount, 0),
+		Items:    make([]models.OrderLine, 0, len(payload.Items)),
+		IssuedAt: occurredAt(event),
+	}
+	for _, item := range payload.Items {
+		refund.Items = append(refund.Items, models.OrderLine{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	_, err := p.repo.ProjectRefund(ctx, event.ID, refund)
+	return err
+}
+
+// occurredAt is when event happened, or now for events published without a time
+func occurredAt(event events.Event) time.Time {
+	if event.OccurredAt.IsZero() {
+		return time.Now().UTC()
+	}
+	return event.OccurredAt
+}
+
+// ProjectionPruner forgets applied event IDs older than projectedRetention every pruneInterval
+// It runs on the elected leader only
+type ProjectionPruner struct {
+	repo *repository.DashboardRepository
+	log  *zap.Logger
+}
+
+func NewProjectionPruner(repo *repository.DashboardRepository, log *zap.Logger) *ProjectionPruner {
+	return &ProjectionPruner{repo: repo, log: log}
+}
+
+func (p *ProjectionPruner) Name() string {
+	return "dashboard-projection-prune"
+}
+
+// Run prunes until ctx is cancelled
+func (p *ProjectionPruner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			pruned, err := p.repo.PruneProjected(ctx, time.Now().Add(-projectedRetention))
+			if err == nil && pruned > 0 {
+				p.log.Info("Pruned projected event IDs", zap.Int64("rows", pruned))
+			}
+		}
+	}
+}
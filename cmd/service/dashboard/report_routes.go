@@ -0,0 +1,149 @@
+package dashboard
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type ReportHandler struct {
+	service *ReportService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewReportHandler(service *ReportService, tokens *auth.TokenManager, log *zap.Logger) *ReportHandler {
+	return &ReportHandler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers the dashboard reports (admin only)
+func (h *ReportHandler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/dashboard").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/sales", h.handleSales).Methods("GET")
+	admin.HandleFunc("/top-products", h.handleTopProducts).Methods("GET")
+	admin.HandleFunc("/customers", h.handleCustomers).Methods("GET")
+}
+
+// handleSales handles GET /api/v1/admin/dashboard/sales
+// @Summary Daily sales
+// @Description Orders, units, gross revenue and refunds per UTC day and currency, with totals per currency. Served from read models kept up to date from order and refund events. Defaults to the last 30 days; at most 366.
+// @Tags Dashboard
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD (default: today)"
+// @Success 200 {object} models.SalesReport
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/dashboard/sales [get]
+func (h *ReportHandler) handleSales(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	report, err := h.service.Sales(r.Context(), strings.TrimSpace(query.Get("from")), strings.TrimSpace(query.Get("to")))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, report)
+}
+
+// handleTopProducts handles GET /api/v1/admin/dashboard/top-products
+// @Summary Top products
+// @Description Best-selling products in one currency over a range of days, by units or revenue. Defaults to the last 30 days and the store currency.
+// @Tags Dashboard
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD (default: today)"
+// @Param currency query string false "ISO 4217 code (default: store currency)"
+// @Param sort query string false "units (default) or revenue"
+// @Param limit query int false "Products to return (default 10, max 100)"
+// @Success 200 {object} models.TopProductsReport
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/dashboard/top-products [get]
+func (h *ReportHandler) handleTopProducts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, err := parseLimit(query)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	report, err := h.service.TopProducts(r.Context(), strings.TrimSpace(query.Get("from")), strings.TrimSpace(query.Get("to")),
+		query.Get("currency"), strings.TrimSpace(query.Get("sort")), limit)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, report)
+}
+
+// handleCustomers handles GET /api/v1/admin/dashboard/customers
+// @Summary Customer stats
+// @Description Daily signups and first and repeat orders over a range of days, how many customers have ordered and reordered, and the top customers by net spend in one currency.
+// @Tags Dashboard
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD (default: today)"
+// @Param currency query string false "ISO 4217 code for top customers (default: store currency)"
+// @Param limit query int false "Top customers to return (default 10, max 100)"
+// @Success 200 {object} models.CustomerReport
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/dashboard/customers [get]
+func (h *ReportHandler) handleCustomers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, err := parseLimit(query)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	report, err := h.service.Customers(r.Context(), strings.TrimSpace(query.Get("from")), strings.TrimSpace(query.Get("to")),
+		query.Get("currency"), limit)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, report)
+}
+
+// parseLimit reads the optional limit parameter; absent means 0 (the default)
+func parseLimit(query url.Values) (int, error) {
+	raw := strings.TrimSpace(query.Get("limit"))
+	if raw == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: limit must be a number", ErrInvalidDashboard)
+	}
+	return limit, nil
+}
+
+// writeError maps dashboard errors to 400/500
+func (h *ReportHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidDashboard):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.log.Error("Dashboard request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
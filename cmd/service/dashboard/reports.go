@@ -0,0 +1,239 @@
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"go.uber.org/zap"
+)
+
+const (
+	// dayLayout is how report ranges and read model rows name days
+	dayLayout = "2006-01-02"
+	// defaultDays is the range reports cover without one
+	defaultDays = 30
+	// maxDays bounds one report's range
+	maxDays = 366
+	// defaultTop and maxTop bound how many products or customers a report ranks
+	defaultTop = 10
+	maxTop     = 100
+)
+
+// ErrInvalidDashboard wraps problems with a dashboard report request
+var ErrInvalidDashboard = errors.New("invalid dashboard request")
+
+// ReportService serves dashboard reports from the read models the Projector keeps
+type ReportService struct {
+	repo     *repository.DashboardRepository
+	products repository.ProductStore
+	users    *repository.UserRepository
+	settings *settings.Store
+	clock    clock.Clock
+	log      *zap.Logger
+}
+
+func NewReportService(repo *repository.DashboardRepository, products repository.ProductStore, users *repository.UserRepository,
+	storeSettings *settings.Store, clk clock.Clock, log *zap.Logger) *ReportService {
+	return &ReportService{
+		repo:     repo,
+		products: products,
+		users:    users,
+		settings: storeSettings,
+		clock:    clk,
+		log:      log,
+	}
+}
+
+// Sales returns daily orders, revenue and refunds per currency from through to (YYYY-MM-DD, inclusive)
+func (s *ReportService) Sales(ctx context.Context, from, to string) (*models.SalesReport, error) {
+	from, to, err := s.dayRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.repo.Sales(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.SalesReport{From: from, To: to, Days: make([]models.DailySales, 0, len(days)), Totals: []models.DailySales{}}
+	totals := make(map[string]int)
+	for _, day := range days {
+		day.Net = day.Gross - day.Refunded
+		report.Days = append(report.Days, day)
+
+		i, ok := totals[day.Currency]
+		if !ok {
+			i = len(report.Totals)
+			totals[day.Currency] = i
+			report.Totals = append(report.Totals, models.DailySales{Currency: day.Currency})
+		}
+		total := &report.Totals[i]
+		total.Orders += day.Orders
+		total.Units += day.Units
+		total.Gross += day.Gross
+		total.Refunds += day.Refunds
+		total.Refunded += day.Refunded
+		total.Net += day.Net
+	}
+	return report, nil
+}
+
+// TopProducts ranks the products sold in currency (default: the store currency) from through to
+// by units (default) or revenue
+func (s *ReportService) TopProducts(ctx context.Context, from, to, currency, sort string, limit int) (*models.TopProductsReport, error) {
+	from, to, err := s.dayRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	switch sort {
+	case "":
+		sort = "units"
+	case "units", "revenue":
+	default:
+		return nil, fmt.Errorf("%w: sort must be units or revenue", ErrInvalidDashboard)
+	}
+	currency, limit, err = s.ranking(ctx, currency, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.repo.TopProducts(ctx, from, to, currency, sort == "revenue", limit)
+	if err != nil {
+		return nil, err
+	}
+	if products == nil {
+		products = []models.TopProduct{}
+	}
+	ids := make([]string, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ProductID)
+	}
+	if len(ids) > 0 {
+		found, err := s.products.GetByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		names := make(map[string]string, len(found))
+		for _, product := range found {
+			names[product.ID] = product.Name
+		}
+		for i := range products {
+			products[i].Name = names[products[i].ProductID]
+		}
+	}
+	return &models.TopProductsReport{From: from, To: to, Currency: currency, Sort: sort, Products: products}, nil
+}
+
+// Customers returns daily signups and first and repeat orders from through to, with customer
+// totals and the top customers by net spend in currency (default: the store currency)
+func (s *ReportService) Customers(ctx context.Context, from, to, currency string, limit int) (*models.CustomerReport, error) {
+	from, to, err := s.dayRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	currency, limit, err = s.ranking(ctx, currency, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := s.repo.CustomerDays(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report := &models.CustomerReport{From: from, To: to, Days: days, Currency: currency}
+	if report.Days == nil {
+		report.Days = []models.DailyCustomers{}
+	}
+	for _, day := range days {
+		report.Totals.Signups += day.Signups
+		report.Totals.FirstOrders += day.FirstOrders
+		report.Totals.RepeatOrders += day.RepeatOrders
+	}
+	if report.Customers, report.RepeatCustomers, err = s.repo.CustomerCounts(ctx); err != nil {
+		return nil, err
+	}
+
+	if report.TopCustomers, err = s.repo.TopCustomers(ctx, currency, limit); err != nil {
+		return nil, err
+	}
+	if report.TopCustomers == nil {
+		report.TopCustomers = []models.TopCustomer{}
+	}
+	ids := make([]string, 0, len(report.TopCustomers))
+	for _, customer := range report.TopCustomers {
+		ids = append(ids, customer.UserID)
+	}
+	if len(ids) > 0 {
+		users, err := s.users.GetUsersByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[string]models.User, len(users))
+		for _, user := range users {
+			byID[user.ID] = user
+		}
+		for i := range report.TopCustomers {
+			if user, ok := byID[report.TopCustomers[i].UserID]; ok {
+				report.TopCustomers[i].Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+				report.TopCustomers[i].Email = user.Email
+			}
+		}
+	}
+	return report, nil
+}
+
+// dayRange validates a report range; empty bounds default to the last 30 days through today (UTC)
+func (s *ReportService) dayRange(from, to string) (string, string, error) {
+	end, err := parseDay(to, "to", s.clock.Now().UTC())
+	if err != nil {
+		return "", "", err
+	}
+	start, err := parseDay(from, "from", end.AddDate(0, 0, -(defaultDays-1)))
+	if err != nil {
+		return "", "", err
+	}
+	switch {
+	case start.After(end):
+		return "", "", fmt.Errorf("%w: from must not be after to", ErrInvalidDashboard)
+	case end.Sub(start) >= maxDays*24*time.Hour:
+		return "", "", fmt.Errorf("%w: reports cover at most %d days", ErrInvalidDashboard, maxDays)
+	}
+	return start.Format(dayLayout), end.Format(dayLayout), nil
+}
+
+// ranking validates a ranked report's currency and size
+func (s *ReportService) ranking(ctx context.Context, currency string, limit int) (string, int, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	switch {
+	case currency == "":
+		currency = s.settings.DefaultCurrency(ctx)
+	case len(currency) != 3:
+		return "", 0, fmt.Errorf("%w: currency must be a 3-letter code", ErrInvalidDashboard)
+	}
+	switch {
+	case limit == 0:
+		limit = defaultTop
+	case limit < 0 || limit > maxTop:
+		return "", 0, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidDashboard, maxTop)
+	}
+	return currency, limit, nil
+}
+
+// parseDay reads a YYYY-MM-DD bound; empty means fallback's day
+func parseDay(value, name string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Date(fallback.Year(), fallback.Month(), fallback.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	day, err := time.Parse(dayLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be a date (YYYY-MM-DD)", ErrInvalidDashboard, name)
+	}
+	return day, nil
+}
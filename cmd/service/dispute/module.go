@@ -0,0 +1,51 @@
+package dispute
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/lock"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+)
+
+// Module provides the payment provider webhook and chargeback tracking: disputes linked to their
+// orders, evidence deadlines with reminders, and the admin dispute queue.
+type Module struct {
+	handler   *Handler
+	scheduler lock.Service // deadline scans on the elected leader only; nil when DISPUTE_SCAN_INTERVAL=0
+}
+
+func NewModule(deps module.Deps) *Module {
+	service := NewDisputeService(repository.NewDisputeRepository(deps.DB, deps.Log),
+		repository.NewOrderRepository(deps.DB, deps.Log), repository.NewUserRepository(deps.DB, deps.Log),
+		deps.Payments, deps.Notifier, deps.Config.Payment.DisputeReminder, deps.Clock, deps.Log)
+
+	deps.Jobs.Register(JobDeadlineReminders, service.handleReminderJob)
+
+	m := &Module{
+		handler: NewHandler(service, deps.Tokens, deps.Log),
+	}
+	if interval := deps.Config.Payment.DisputeScanInterval; interval > 0 {
+		m.scheduler = lock.Singleton(deps.Locks, NewScheduler(deps.Jobs, interval, deps.Log), deps.Config.Locks.LeaderTTL, deps.Log)
+	}
+	return m
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.Dispute{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+// Services returns the deadline scheduler when reminders are enabled
+func (m *Module) Services() []module.Service {
+	if m.scheduler == nil {
+		return nil
+	}
+	return []module.Service{m.scheduler}
+}
@@ -0,0 +1,192 @@
+package dispute
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/payment"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// maxWebhookBody caps payment provider notifications
+const maxWebhookBody = 1 << 20
+
+type Handler struct {
+	service *DisputeService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *DisputeService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers the payment webhook and the admin dispute routes
+// The webhook is public and authenticated by signature
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/payments/webhooks/{provider}", h.handleWebhook).Methods("POST")
+
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/disputes", h.handleList).Methods("GET")
+	admin.HandleFunc("/disputes/reminders", h.handleReminders).Methods("POST")
+	admin.HandleFunc("/disputes/{id}", h.handleGet).Methods("GET")
+	admin.HandleFunc("/disputes/{id}/evidence", h.handleEvidence).Methods("POST")
+}
+
+// handleWebhook handles POST /api/v1/payments/webhooks/{provider}
+// @Summary Payment provider webhook
+// @Description Receives payment provider notifications. Dispute events open, update and close chargebacks; other events are acknowledged. The sandbox provider authenticates with an X-Signature HMAC.
+// @Tags Disputes
+// @Accept json
+// @Param provider path string true "Provider code"
+// @Success 204
+// @Failure 401 {string} string "Invalid signature"
+// @Failure 404 {string} string "Unknown provider"
+// @Router /payments/webhooks/{provider} [post]
+func (h *Handler) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	provider := mux.Vars(r)["provider"]
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.HandleWebhook(r.Context(), provider, r, body)
+	switch {
+	case errors.Is(err, ErrUnknownProvider):
+		http.Error(w, "Unknown provider", http.StatusNotFound)
+		return
+	case errors.Is(err, payment.ErrInvalidSignature):
+		h.log.Warn("Rejected payment webhook", zap.String("provider", provider))
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	case err != nil:
+		// 5xx makes the provider retry later
+		h.log.Error("Failed to process payment webhook", zap.String("provider", provider), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleList handles GET /api/v1/admin/disputes
+// @Summary List disputes
+// @Description Chargebacks, nearest evidence deadline first. status=open lists the ones still waiting for a decision.
+// @Tags Disputes
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "open, needs_response, under_review, won or lost"
+// @Param order_id query string false "Only this order's disputes"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.DisputeListResponse
+// @Failure 400 {string} string "Invalid request"
+// @Router /admin/disputes [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	query := r.URL.Query()
+
+	resp, err := h.service.List(r.Context(), repository.DisputeFilter{
+		Status:  query.Get("status"),
+		OrderID: query.Get("order_id"),
+	}, limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleGet handles GET /api/v1/admin/disputes/{id}
+// @Summary Get dispute
+// @Tags Disputes
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Dispute ID"
+// @Success 200 {object} models.Dispute
+// @Failure 404 {string} string "Dispute not found"
+// @Router /admin/disputes/{id} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	dispute, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, dispute)
+}
+
+// handleEvidence handles POST /api/v1/admin/disputes/{id}/evidence
+// @Summary Record evidence submission
+// @Description Records that evidence was submitted in the provider's dashboard and moves the dispute to under_review. Deadline reminders stop.
+// @Tags Disputes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Dispute ID"
+// @Param request body models.DisputeEvidenceRequest true "What was submitted"
+// @Success 200 {object} models.Dispute
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Dispute not found"
+// @Failure 409 {string} string "Dispute is not accepting evidence"
+// @Router /admin/disputes/{id}/evidence [post]
+func (h *Handler) handleEvidence(w http.ResponseWriter, r *http.Request) {
+	var req models.DisputeEvidenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	dispute, err := h.service.SubmitEvidence(r.Context(), mux.Vars(r)["id"], auth.ClaimsFromContext(r.Context()).UserID(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, dispute)
+}
+
+// handleReminders handles POST /api/v1/admin/disputes/reminders
+// @Summary Send deadline reminders now
+// @Description Runs the scheduled scan: reminds admins about disputes whose evidence deadline is within DISPUTE_REMINDER_WINDOW
+// @Tags Disputes
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.DisputeReminders
+// @Router /admin/disputes/reminders [post]
+func (h *Handler) handleReminders(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.SendReminders(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, result)
+}
+
+// writeError maps dispute errors to 400/404/409/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidDispute):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrDisputeNotFound):
+		http.Error(w, "Dispute not found", http.StatusNotFound)
+	case errors.Is(err, ErrEvidenceNotAccepted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.log.Error("Dispute request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
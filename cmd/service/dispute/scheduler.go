@@ -0,0 +1,45 @@
+package dispute
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"go.uber.org/zap"
+)
+
+// JobDeadlineReminders reminds admins about near evidence deadlines; queued every DISPUTE_SCAN_INTERVAL
+const JobDeadlineReminders = "dispute.deadline_reminders"
+
+// Scheduler queues a deadline scan every DISPUTE_SCAN_INTERVAL
+// It runs on the elected leader only, so one scan is queued per interval; job workers run it
+type Scheduler struct {
+	jobs     *jobs.Processor
+	interval time.Duration
+	log      *zap.Logger
+}
+
+func NewScheduler(processor *jobs.Processor, interval time.Duration, log *zap.Logger) *Scheduler {
+	return &Scheduler{jobs: processor, interval: interval, log: log}
+}
+
+func (s *Scheduler) Name() string {
+	return "dispute-deadline-scan"
+}
+
+// Run queues scans until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := s.jobs.Enqueue(ctx, JobDeadlineReminders, nil, jobs.MaxAttempts(1)); err != nil {
+				s.log.Error("Failed to queue dispute deadline scan", zap.Error(err))
+			}
+		}
+	}
+}
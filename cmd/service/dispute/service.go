@@ -0,0 +1,365 @@
+package dispute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/notify"
+	"github.com/Jason-Omondi/ecomgo/internal/payment"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+const maxEvidenceNote = 2000
+
+var (
+	// ErrInvalidDispute wraps validation problems with a dispute request
+	ErrInvalidDispute = errors.New("invalid dispute request")
+	// ErrUnknownProvider is returned for webhooks of a provider other than PAYMENT_PROVIDER
+	ErrUnknownProvider = errors.New("unknown payment provider")
+	// ErrEvidenceNotAccepted is returned when evidence was already submitted, the dispute is
+	// decided or its deadline has passed
+	ErrEvidenceNotAccepted = errors.New("dispute is not accepting evidence")
+)
+
+// DisputeService tracks chargebacks from the payment provider's webhooks
+// A dispute is linked to the order its payment was for and watched until the bank decides it:
+// admins are notified when one opens and when it closes, and reminded once when its evidence
+// deadline is within DISPUTE_REMINDER_WINDOW and nobody has submitted evidence yet.
+type DisputeService struct {
+	repo     *repository.DisputeRepository
+	orders   *repository.OrderRepository
+	users    *repository.UserRepository // admins to notify
+	provider payment.Provider
+	notifier *notify.Notifier
+	reminder time.Duration
+	clock    clock.Clock
+	log      *zap.Logger
+}
+
+func NewDisputeService(repo *repository.DisputeRepository, orders *repository.OrderRepository, users *repository.UserRepository,
+	provider payment.Provider, notifier *notify.Notifier, reminder time.Duration, clk clock.Clock, log *zap.Logger) *DisputeService {
+	return &DisputeService{
+		repo:     repo,
+		orders:   orders,
+		users:    users,
+		provider: provider,
+		notifier: notifier,
+		reminder: reminder,
+		clock:    clk,
+		log:      log,
+	}
+}
+
+// List returns a page of disputes, nearest evidence deadline first
+func (s *DisputeService) List(ctx context.Context, filter repository.DisputeFilter, limit, offset int) (*models.DisputeListResponse, error) {
+	if _, ok := statusSet[filter.Status]; filter.Status != "" && !ok {
+		return nil, fmt.Errorf("%w: status must be open, needs_response, under_review, won or lost", ErrInvalidDispute)
+	}
+	disputes, total, err := s.repo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if disputes == nil {
+		disputes = []models.Dispute{}
+	}
+	return &models.DisputeListResponse{Disputes: disputes, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+func (s *DisputeService) Get(ctx context.Context, id string) (*models.Dispute, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// SubmitEvidence records that an admin sent evidence to the provider, moving the dispute to under_review
+// Evidence itself is uploaded in the provider's dashboard; the note says what was sent
+func (s *DisputeService) SubmitEvidence(ctx context.Context, id, userID string, req *models.DisputeEvidenceRequest) (*models.Dispute, error) {
+	note := strings.TrimSpace(req.Note)
+	switch {
+	case note == "":
+		return nil, fmt.Errorf("%w: note is required", ErrInvalidDispute)
+	case len(note) > maxEvidenceNote:
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalidDispute, maxEvidenceNote)
+	}
+	dispute, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now().UTC()
+	if dispute.EvidenceDueBy != nil && now.After(*dispute.EvidenceDueBy) {
+		return nil, fmt.Errorf("%w: the deadline passed on %s", ErrEvidenceNotAccepted, dispute.EvidenceDueBy.Format(time.DateOnly))
+	}
+
+	moved, err := s.repo.SubmitEvidence(ctx, id, userID, note, now)
+	if err != nil {
+		return nil, err
+	}
+	if !moved {
+		return nil, fmt.Errorf("%w: it is %s", ErrEvidenceNotAccepted, dispute.Status)
+	}
+	s.log.Info("Dispute evidence submitted", zap.String("id", id), zap.String("user_id", userID))
+	return s.repo.Get(ctx, id)
+}
+
+// HandleWebhook authenticates a payment provider notification and applies its dispute events
+// Other event types are acknowledged and ignored. Redeliveries are harmless: disputes are keyed
+// by the provider's dispute ID and every state change is conditional.
+// Returns: ErrUnknownProvider, payment.ErrInvalidSignature, or nil once applied
+func (s *DisputeService) HandleWebhook(ctx context.Context, providerName string, r *http.Request, body []byte) error {
+	if !strings.EqualFold(providerName, s.provider.Name()) {
+		return fmt.Errorf("%w: %s", ErrUnknownProvider, providerName)
+	}
+	event, err := s.provider.ParseWebhook(r, body)
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case payment.EventDisputeOpened, payment.EventDisputeUpdated, payment.EventDisputeClosed:
+	default:
+		return nil
+	}
+	if event.DisputeID == "" {
+		// Nothing to key it by - acknowledge so the provider stops retrying
+		s.log.Warn("Dispute webhook without dispute ID", zap.String("provider", providerName), zap.String("event_id", event.ID))
+		return nil
+	}
+
+	dispute, err := s.repo.GetByProviderRef(ctx, s.provider.Name(), event.DisputeID)
+	if errors.Is(err, repository.ErrDisputeNotFound) {
+		return s.open(ctx, event)
+	}
+	if err != nil {
+		return err
+	}
+	return s.update(ctx, dispute, event)
+}
+
+// open records a dispute the first time any of its events arrives
+// Events can arrive out of order, so a dispute first seen closed is stored closed
+func (s *DisputeService) open(ctx context.Context, event *payment.WebhookEvent) error {
+	openedAt := event.OccurredAt
+	if openedAt.IsZero() {
+		openedAt = s.clock.Now()
+	}
+	dispute := &models.Dispute{
+		Provider:          s.provider.Name(),
+		ProviderDisputeID: event.DisputeID,
+		PaymentID:         event.PaymentID,
+		OrderID:           event.OrderID,
+		Amount:            event.Amount,
+		Currency:          strings.ToUpper(event.Currency),
+		Reason:            event.Reason,
+		Status:            models.DisputeNeedsResponse,
+		EvidenceDueBy:     utc(event.EvidenceDueBy),
+		OpenedAt:          openedAt.UTC(),
+	}
+	if event.Type == payment.EventDisputeClosed {
+		status, ok := outcomeStatus[event.Outcome]
+		if !ok {
+			s.log.Warn("Dispute closed with unknown outcome", zap.String("dispute_id", event.DisputeID), zap.String("outcome", event.Outcome))
+			return nil
+		}
+		closedAt := openedAt.UTC()
+		dispute.Status, dispute.ClosedAt = status, &closedAt
+	}
+	s.linkOrder(ctx, dispute)
+
+	if err := s.repo.Create(ctx, dispute); err != nil {
+		if !errors.Is(err, repository.ErrAlreadyExists) {
+			return err
+		}
+		// A redelivery won the race; apply this event as an update to it
+		existing, err := s.repo.GetByProviderRef(ctx, dispute.Provider, dispute.ProviderDisputeID)
+		if err != nil {
+			return err
+		}
+		return s.update(ctx, existing, event)
+	}
+	s.log.Info("Dispute opened", zap.String("id", dispute.ID), zap.String("order_id", dispute.OrderID),
+		zap.Int64("amount", dispute.Amount), zap.String("status", dispute.Status))
+	if dispute.Open() {
+		s.alertAdmins(ctx, "Chargeback opened: "+disputeLabel(dispute), openedBody(dispute))
+	} else {
+		s.alertAdmins(ctx, "Chargeback "+dispute.Status+": "+disputeLabel(dispute), closedBody(dispute))
+	}
+	return nil
+}
+
+// update applies a later event to a known dispute; decided disputes are left alone
+func (s *DisputeService) update(ctx context.Context, dispute *models.Dispute, event *payment.WebhookEvent) error {
+	switch event.Type {
+	case payment.EventDisputeUpdated:
+		if event.Amount > 0 {
+			dispute.Amount = event.Amount
+		}
+		if event.Reason != "" {
+			dispute.Reason = event.Reason
+		}
+		if due := utc(event.EvidenceDueBy); due != nil && (dispute.EvidenceDueBy == nil || !due.Equal(*dispute.EvidenceDueBy)) {
+			// A moved deadline gets its own reminder
+			dispute.EvidenceDueBy, dispute.RemindedAt = due, nil
+		}
+		_, err := s.repo.Revise(ctx, dispute)
+		return err
+
+	case payment.EventDisputeClosed:
+		status, ok := outcomeStatus[event.Outcome]
+		if !ok {
+			s.log.Warn("Dispute closed with unknown outcome", zap.String("dispute_id", event.DisputeID), zap.String("outcome", event.Outcome))
+			return nil
+		}
+		closedAt := event.OccurredAt
+		if closedAt.IsZero() {
+			closedAt = s.clock.Now()
+		}
+		closed, err := s.repo.Close(ctx, dispute.ID, status, closedAt.UTC())
+		if err != nil || !closed {
+			return err
+		}
+		dispute.Status = status
+		s.log.Info("Dispute closed", zap.String("id", dispute.ID), zap.String("status", status))
+		s.alertAdmins(ctx, "Chargeback "+status+": "+disputeLabel(dispute), closedBody(dispute))
+	}
+	return nil
+}
+
+// linkOrder fills in the order number and customer from the order record
+// Disputes on payments for orders this store has no record of are kept unlinked
+func (s *DisputeService) linkOrder(ctx context.Context, dispute *models.Dispute) {
+	if dispute.OrderID == "" {
+		return
+	}
+	order, err := s.orders.Get(ctx, dispute.OrderID, false)
+	if err != nil {
+		if !errors.Is(err, repository.ErrOrderNotFound) {
+			s.log.Warn("Failed to look up disputed order", zap.String("order_id", dispute.OrderID), zap.Error(err))
+		}
+		return
+	}
+	dispute.OrderNumber, dispute.UserID = order.OrderNumber, order.UserID
+}
+
+// SendReminders reminds admins about disputes whose evidence deadline is near
+// Each deadline is reminded once; a deadline the provider moves is reminded again
+func (s *DisputeService) SendReminders(ctx context.Context) (*models.DisputeReminders, error) {
+	now := s.clock.Now().UTC()
+	due, err := s.repo.DueForReminder(ctx, now.Add(s.reminder))
+	if err != nil {
+		return nil, err
+	}
+	if len(due) == 0 {
+		return &models.DisputeReminders{}, nil
+	}
+
+	var body strings.Builder
+	body.WriteString("Evidence is due soon for these chargebacks:\n")
+	ids := make([]string, 0, len(due))
+	for _, dispute := range due {
+		fmt.Fprintf(&body, "\n%s: %s %s, due %s", disputeLabel(&dispute), dispute.Currency, amount(dispute.Amount),
+			dispute.EvidenceDueBy.UTC().Format(time.RFC3339))
+		ids = append(ids, dispute.ID)
+	}
+	subject := fmt.Sprintf("Chargeback evidence due: %d disputes", len(due))
+	if len(due) == 1 {
+		subject = "Chargeback evidence due: " + disputeLabel(&due[0])
+	}
+	s.alertAdmins(ctx, subject, body.String())
+
+	if err := s.repo.MarkReminded(ctx, ids, now); err != nil {
+		return nil, err
+	}
+	s.log.Info("Dispute deadline reminders sent", zap.Int("disputes", len(due)))
+	return &models.DisputeReminders{Reminded: len(due)}, nil
+}
+
+// handleReminderJob runs a deadline scan queued by the scheduler
+func (s *DisputeService) handleReminderJob(ctx context.Context, job *models.Job) error {
+	_, err := s.SendReminders(ctx)
+	return err
+}
+
+// alertAdmins notifies every admin on their order_updates channel
+// Delivery failures are logged; the alert isn't retried
+func (s *DisputeService) alertAdmins(ctx context.Context, subject, body string) {
+	admins, err := s.users.GetUsersByRole(ctx, models.RoleAdmin)
+	if err != nil {
+		s.log.Error("Failed to load admins for dispute alert", zap.Error(err))
+		return
+	}
+	for _, admin := range admins {
+		err := s.notifier.Notify(ctx, admin.ID, notify.Notification{
+			Category: models.NotifyOrderUpdates,
+			Subject:  subject,
+			Body:     body,
+		})
+		if err != nil {
+			s.log.Warn("Failed to send dispute alert", zap.String("user_id", admin.ID), zap.Error(err))
+		}
+	}
+}
+
+func openedBody(dispute *models.Dispute) string {
+	body := fmt.Sprintf("A chargeback of %s %s was opened on payment %s", dispute.Currency, amount(dispute.Amount), dispute.PaymentID)
+	if dispute.Reason != "" {
+		body += " (" + dispute.Reason + ")"
+	}
+	body += "."
+	if dispute.EvidenceDueBy != nil {
+		body += " Evidence is due by " + dispute.EvidenceDueBy.UTC().Format(time.RFC3339) + "."
+	}
+	return body
+}
+
+func closedBody(dispute *models.Dispute) string {
+	if dispute.Status == models.DisputeWon {
+		return fmt.Sprintf("The chargeback of %s %s on payment %s was decided in the store's favour.",
+			dispute.Currency, amount(dispute.Amount), dispute.PaymentID)
+	}
+	return fmt.Sprintf("The chargeback of %s %s on payment %s was lost; the money went back to the customer.",
+		dispute.Currency, amount(dispute.Amount), dispute.PaymentID)
+}
+
+// disputeLabel names a dispute by its order when it has one
+func disputeLabel(dispute *models.Dispute) string {
+	switch {
+	case dispute.OrderNumber != "":
+		return "order " + dispute.OrderNumber
+	case dispute.OrderID != "":
+		return "order " + dispute.OrderID
+	}
+	return "payment " + dispute.PaymentID
+}
+
+// amount formats minor units as 1234.50
+func amount(minor int64) string {
+	return fmt.Sprintf("%d.%02d", minor/100, minor%100)
+}
+
+func utc(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// outcomeStatus maps a provider outcome to the dispute's closed state
+var outcomeStatus = map[string]string{
+	payment.DisputeWon:  models.DisputeWon,
+	payment.DisputeLost: models.DisputeLost,
+}
+
+// statusSet holds the states a listing can filter by
+var statusSet = map[string]struct{}{
+	"open":                      {},
+	models.DisputeNeedsResponse: {},
+	models.DisputeUnderReview:   {},
+	models.DisputeWon:           {},
+	models.DisputeLost:          {},
+}
@@ -0,0 +1,34 @@
+package emailtemplate
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/gorilla/mux"
+)
+
+// Module provides the admin endpoints for editing, versioning and previewing email templates
+// The mailer reads the active versions itself (internal/email), so edits apply without a deploy
+type Module struct {
+	handler *Handler
+}
+
+func NewModule(deps module.Deps) *Module {
+	return &Module{
+		handler: NewHandler(deps.Mailer, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.EmailTemplate{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
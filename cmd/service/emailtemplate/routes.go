@@ -0,0 +1,242 @@
+package emailtemplate
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/email"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	mailer *email.Mailer
+	tokens *auth.TokenManager
+	log    *zap.Logger
+}
+
+func NewHandler(mailer *email.Mailer, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		mailer: mailer,
+		tokens: tokens,
+		log:    log,
+	}
+}
+
+// RegisterRoutes registers email template routes (admin only)
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/email-templates").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+
+	admin.HandleFunc("", h.handleList).Methods("GET")
+	admin.HandleFunc("/{name}", h.handleGet).Methods("GET")
+	admin.HandleFunc("/{name}", h.handleReset).Methods("DELETE")
+	admin.HandleFunc("/{name}/versions", h.handleListVersions).Methods("GET")
+	admin.HandleFunc("/{name}/versions", h.handleSave).Methods("POST")
+	admin.HandleFunc("/{name}/versions/{version:[0-9]+}/activate", h.handleActivate).Methods("POST")
+	admin.HandleFunc("/{name}/preview", h.handlePreview).Methods("POST")
+}
+
+// handleList handles GET /api/v1/admin/email-templates
+// @Summary List email templates
+// @Description Every email template in every supported locale, with its active version (0 when the built-in one is in use)
+// @Tags Email Templates
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.EmailTemplateSummary
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/email-templates [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	summaries, err := h.mailer.Templates().Summaries(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, summaries)
+}
+
+// handleGet handles GET /api/v1/admin/email-templates/{name}
+// @Summary Get email template
+// @Description The sources the template currently renders with: the active version, or the built-in template
+// @Tags Email Templates
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Template name, e.g. welcome"
+// @Param locale query string false "Locale (default en)"
+// @Success 200 {object} models.EmailTemplateSource
+// @Failure 404 {string} string "Unknown email template"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/email-templates/{name} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	source, err := h.mailer.Templates().Current(r.Context(), mux.Vars(r)["name"], r.URL.Query().Get("locale"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, source)
+}
+
+// handleListVersions handles GET /api/v1/admin/email-templates/{name}/versions
+// @Summary List email template versions
+// @Description Saved versions of the template in one locale, newest first
+// @Tags Email Templates
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Template name"
+// @Param locale query string false "Locale (default en)"
+// @Success 200 {array} models.EmailTemplate
+// @Failure 404 {string} string "Unknown email template"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/email-templates/{name}/versions [get]
+func (h *Handler) handleListVersions(w http.ResponseWriter, r *http.Request) {
+	versions, err := h.mailer.Templates().Versions(r.Context(), mux.Vars(r)["name"], r.URL.Query().Get("locale"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	if versions == nil {
+		versions = []models.EmailTemplate{}
+	}
+	response.JSON(w, http.StatusOK, versions)
+}
+
+// handleSave handles POST /api/v1/admin/email-templates/{name}/versions
+// @Summary Save email template version
+// @Description Saves the sources as the next version after rendering them with sample data. The text part must
+// @Description define the subject with {{define "subject"}}...{{end}}. With activate=true outgoing email uses it at once.
+// @Tags Email Templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Template name"
+// @Param request body models.SaveEmailTemplateRequest true "Template sources"
+// @Success 201 {object} models.EmailTemplate
+// @Failure 400 {string} string "Invalid email template"
+// @Failure 404 {string} string "Unknown email template"
+// @Failure 409 {string} string "Saved concurrently, retry"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/email-templates/{name}/versions [post]
+func (h *Handler) handleSave(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	var req models.SaveEmailTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.mailer.Templates().Save(r.Context(), mux.Vars(r)["name"], &req, claims.UserID())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, template)
+}
+
+// handleActivate handles POST /api/v1/admin/email-templates/{name}/versions/{version}/activate
+// @Summary Activate email template version
+// @Description Puts a saved version in use, e.g. to roll back
+// @Tags Email Templates
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Template name"
+// @Param version path int true "Version"
+// @Param locale query string false "Locale (default en)"
+// @Success 200 {object} models.EmailTemplate
+// @Failure 404 {string} string "Unknown email template or version"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/email-templates/{name}/versions/{version}/activate [post]
+func (h *Handler) handleActivate(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+	vars := mux.Vars(r)
+
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		http.Error(w, "Email template not found", http.StatusNotFound)
+		return
+	}
+
+	template, err := h.mailer.Templates().Activate(r.Context(), vars["name"], r.URL.Query().Get("locale"), version, claims.UserID())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, template)
+}
+
+// handleReset handles DELETE /api/v1/admin/email-templates/{name}
+// @Summary Reset email template
+// @Description Puts the built-in template back in use for one locale; saved versions are kept
+// @Tags Email Templates
+// @Security BearerAuth
+// @Param name path string true "Template name"
+// @Param locale query string false "Locale (default en)"
+// @Success 204
+// @Failure 404 {string} string "Unknown email template"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/email-templates/{name} [delete]
+func (h *Handler) handleReset(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	if err := h.mailer.Templates().Reset(r.Context(), mux.Vars(r)["name"], r.URL.Query().Get("locale"), claims.UserID()); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePreview handles POST /api/v1/admin/email-templates/{name}/preview
+// @Summary Preview email template
+// @Description Renders the template with sample data and the store's branding, without sending anything.
+// @Description Pass text and html to preview an unsaved draft, version for a saved one, or neither for the one in use.
+// @Tags Email Templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Template name"
+// @Param request body models.EmailTemplatePreviewRequest false "What to preview"
+// @Success 200 {object} models.EmailTemplatePreview
+// @Failure 400 {string} string "Invalid email template"
+// @Failure 404 {string} string "Unknown email template or version"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/email-templates/{name}/preview [post]
+func (h *Handler) handlePreview(w http.ResponseWriter, r *http.Request) {
+	var req models.EmailTemplatePreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	preview, err := h.mailer.Preview(r.Context(), mux.Vars(r)["name"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, preview)
+}
+
+// writeError maps template errors to 400/404/409/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, email.ErrUnknownTemplate):
+		http.Error(w, "Unknown email template", http.StatusNotFound)
+	case errors.Is(err, repository.ErrEmailTemplateNotFound):
+		http.Error(w, "Email template not found", http.StatusNotFound)
+	case errors.Is(err, email.ErrInvalidTemplate):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrAlreadyExists):
+		http.Error(w, "Email template was saved concurrently, retry", http.StatusConflict)
+	default:
+		h.log.Error("Email template request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,32 @@
+package files
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/gorilla/mux"
+)
+
+// Module exposes object storage over HTTP: presigned uploads for admins and,
+// with the local backend, the routes its presigned links point at
+type Module struct {
+	handler *Handler
+}
+
+func NewModule(deps module.Deps) *Module {
+	cfg := deps.Config.Storage
+	return &Module{
+		handler: NewHandler(deps.Storage, cfg.PresignTTL, cfg.MaxUploadSize, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return nil
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
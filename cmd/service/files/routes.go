@@ -0,0 +1,170 @@
+package files
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/Jason-Omondi/ecomgo/internal/storage"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// uploadPrefixes are the prefixes admins may upload to directly
+// Invoices, exports and labels are generated server-side
+var uploadPrefixes = map[string]bool{
+	storage.PrefixProductImages:   true,
+	storage.PrefixDigitalProducts: true,
+}
+
+type Handler struct {
+	store     storage.Storage
+	local     *storage.Local // set when STORAGE_BACKEND=local; serves presigned links itself
+	ttl       time.Duration
+	maxUpload int64
+	tokens    *auth.TokenManager
+	log       *zap.Logger
+}
+
+func NewHandler(store storage.Storage, ttl time.Duration, maxUpload int64, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	local, _ := store.(*storage.Local)
+	return &Handler{
+		store:     store,
+		local:     local,
+		ttl:       ttl,
+		maxUpload: maxUpload,
+		tokens:    tokens,
+		log:       log,
+	}
+}
+
+// RegisterRoutes registers presigned upload and local file routes
+// /files is only mounted for the local backend - cloud backends serve presigned URLs themselves
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/uploads").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("", h.handlePresignUpload).Methods("POST")
+
+	if h.local != nil {
+		router.HandleFunc("/files/{key:.+}", h.handleDownload).Methods("GET")
+		router.HandleFunc("/files/{key:.+}", h.handleUpload).Methods("PUT")
+	}
+}
+
+// handlePresignUpload handles POST /api/v1/admin/uploads
+// @Summary Presign file upload
+// @Description Returns a URL the client PUTs the file to directly (product images, digital product files)
+// @Tags Files
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.PresignUploadRequest true "Upload"
+// @Success 201 {object} models.PresignUploadResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/uploads [post]
+func (h *Handler) handlePresignUpload(w http.ResponseWriter, r *http.Request) {
+	var req models.PresignUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if !uploadPrefixes[req.Prefix] {
+		http.Error(w, "prefix must be products or digital", http.StatusBadRequest)
+		return
+	}
+	if req.ContentType == "" {
+		req.ContentType = "application/octet-stream"
+	}
+
+	// Random names avoid collisions and don't leak customer-chosen filenames
+	key := storage.Key(req.Prefix, uuid.NewString()+strings.ToLower(path.Ext(req.Filename)))
+	if err := storage.ValidateKey(key); err != nil {
+		http.Error(w, "Invalid filename", http.StatusBadRequest)
+		return
+	}
+
+	uploadURL, err := h.store.PresignPut(r.Context(), key, req.ContentType, h.ttl)
+	if err != nil {
+		h.log.Error("Failed to presign upload", zap.String("key", key), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, models.PresignUploadResponse{
+		Key:         key,
+		UploadURL:   uploadURL,
+		Method:      http.MethodPut,
+		ContentType: req.ContentType,
+		ExpiresAt:   time.Now().Add(h.ttl),
+	})
+}
+
+// handleDownload handles GET /api/v1/files/{key} for local presigned download links
+func (h *Handler) handleDownload(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	if !h.verify(w, r, http.MethodGet, key) {
+		return
+	}
+
+	body, obj, err := h.store.Get(r.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to read file", zap.String("key", key), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", obj.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(obj.Size, 10))
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, body)
+}
+
+// handleUpload handles PUT /api/v1/files/{key} for local presigned upload links
+func (h *Handler) handleUpload(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	if !h.verify(w, r, http.MethodPut, key) {
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, h.maxUpload)
+	err := h.store.Put(r.Context(), key, body, r.ContentLength, r.Header.Get("Content-Type"))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "File too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to store upload", zap.String("key", key), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// verify checks the link's signature, writing 403 when it doesn't match
+func (h *Handler) verify(w http.ResponseWriter, r *http.Request, method, key string) bool {
+	query := r.URL.Query()
+	if err := h.local.Verify(method, key, query.Get("expires"), query.Get("signature")); err != nil {
+		http.Error(w, "Link invalid or expired", http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package fraud
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+)
+
+// Module stores checkout fraud assessments and serves the admin review queue
+// Scoring itself happens in checkout through deps.Fraud (internal/fraud)
+type Module struct {
+	handler *Handler
+}
+
+func NewModule(deps module.Deps) *Module {
+	service := NewReviewService(repository.NewFraudRepository(deps.DB, deps.Log), deps.Events, deps.Clock, deps.Log)
+	return &Module{
+		handler: NewHandler(service, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.FraudAssessment{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
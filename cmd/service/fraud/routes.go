@@ -0,0 +1,135 @@
+package fraud
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *ReviewService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *ReviewService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers the fraud review queue (admin only)
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/fraud/assessments").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+
+	admin.HandleFunc("", h.handleList).Methods("GET")
+	admin.HandleFunc("/{id}", h.handleGet).Methods("GET")
+	admin.HandleFunc("/{id}/resolve", h.handleResolve).Methods("POST")
+}
+
+// handleList handles GET /api/v1/admin/fraud/assessments
+// @Summary List fraud assessments
+// @Description Checkout risk assessments, newest first. Use decision=review&pending=true for the review queue.
+// @Tags Fraud
+// @Produce json
+// @Security BearerAuth
+// @Param decision query string false "Filter by decision (allow, review, deny)"
+// @Param pending query bool false "Only unresolved assessments"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {array} models.FraudAssessment
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/fraud/assessments [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	query := r.URL.Query()
+
+	list, err := h.service.List(r.Context(), query.Get("decision"), query.Get("pending") == "true", limit, offset)
+	if err != nil {
+		h.log.Error("Failed to list fraud assessments", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []models.FraudAssessment{}
+	}
+
+	response.JSON(w, http.StatusOK, list)
+}
+
+// handleGet handles GET /api/v1/admin/fraud/assessments/{id}
+// @Summary Get fraud assessment
+// @Tags Fraud
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Assessment ID"
+// @Success 200 {object} models.FraudAssessment
+// @Failure 404 {string} string "Assessment not found"
+// @Router /admin/fraud/assessments/{id} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	assessment, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, assessment)
+}
+
+// handleResolve handles POST /api/v1/admin/fraud/assessments/{id}/resolve
+// @Summary Resolve fraud review
+// @Description Allows or denies an order held for review; checkout then captures or voids the payment
+// @Tags Fraud
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Assessment ID"
+// @Param request body models.ResolveFraudReviewRequest true "Verdict"
+// @Success 200 {object} models.FraudAssessment
+// @Failure 400 {string} string "Invalid decision"
+// @Failure 404 {string} string "Assessment not found"
+// @Failure 409 {string} string "Not a pending review"
+// @Router /admin/fraud/assessments/{id}/resolve [post]
+func (h *Handler) handleResolve(w http.ResponseWriter, r *http.Request) {
+	var req models.ResolveFraudReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	claims := auth.ClaimsFromContext(r.Context())
+	assessment, err := h.service.Resolve(r.Context(), mux.Vars(r)["id"], req.Decision, claims.UserID())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, assessment)
+}
+
+// writeError maps review errors to HTTP status codes
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidResolution):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotReviewable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, repository.ErrAssessmentNotFound):
+		http.Error(w, "Assessment not found", http.StatusNotFound)
+	default:
+		h.log.Error("Fraud review request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,80 @@
+package fraud
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidResolution is returned when a review is resolved with anything but allow or deny
+var ErrInvalidResolution = errors.New("decision must be allow or deny")
+
+// ErrNotReviewable is returned when resolving an assessment that isn't a pending review
+var ErrNotReviewable = errors.New("assessment is not a pending review")
+
+// ReviewService lists fraud assessments and resolves orders held for review
+type ReviewService struct {
+	repo      *repository.FraudRepository
+	publisher events.Publisher
+	clock     clock.Clock
+	log       *zap.Logger
+}
+
+func NewReviewService(repo *repository.FraudRepository, publisher events.Publisher, clk clock.Clock, log *zap.Logger) *ReviewService {
+	return &ReviewService{
+		repo:      repo,
+		publisher: publisher,
+		clock:     clk,
+		log:       log,
+	}
+}
+
+// List returns assessments, optionally filtered by decision; pending limits reviews to unresolved ones
+func (s *ReviewService) List(ctx context.Context, decision string, pending bool, limit, offset int) ([]models.FraudAssessment, error) {
+	return s.repo.List(ctx, decision, pending, limit, offset)
+}
+
+func (s *ReviewService) Get(ctx context.Context, id string) (*models.FraudAssessment, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Resolve records an admin's verdict and publishes fraud.review_resolved for checkout to act on
+func (s *ReviewService) Resolve(ctx context.Context, id, decision, adminID string) (*models.FraudAssessment, error) {
+	if decision != models.FraudAllow && decision != models.FraudDeny {
+		return nil, ErrInvalidResolution
+	}
+
+	assessment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if assessment.Decision != models.FraudReview {
+		return nil, ErrNotReviewable
+	}
+
+	now := s.clock.Now()
+	resolved, err := s.repo.Resolve(ctx, id, decision, adminID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !resolved {
+		return nil, ErrNotReviewable
+	}
+	assessment.Resolution, assessment.ResolvedBy, assessment.ResolvedAt = decision, adminID, &now
+
+	s.log.Info("Fraud review resolved", zap.String("assessment_id", id), zap.String("order_id", assessment.OrderID),
+		zap.String("decision", decision), zap.String("admin_id", adminID))
+
+	_ = events.Publish(ctx, s.publisher, s.log, events.TypeFraudReviewResolved, events.FraudReviewResolved{
+		AssessmentID: id,
+		OrderID:      assessment.OrderID,
+		UserID:       assessment.UserID,
+		Decision:     decision,
+	})
+	return assessment, nil
+}
@@ -0,0 +1,70 @@
+package identity
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/lock"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Module syncs local users to Keycloak: provisions accounts on registration,
+// keeps customer/admin realm roles in step and periodically repairs drift
+// Does nothing unless KEYCLOAK_SYNC_ENABLED=true
+type Module struct {
+	syncer     *Syncer
+	reconciler lock.Service // syncer.Run on the elected leader only
+	handler    *Handler
+}
+
+func NewModule(deps module.Deps) *Module {
+	if deps.Keycloak == nil {
+		return &Module{}
+	}
+
+	cfg := deps.Config.Keycloak
+	syncer := NewSyncer(repository.NewUserRepository(deps.DB, deps.Log), deps.Keycloak, deps.Jobs,
+		cfg.RoleAuthority, cfg.SyncInterval, deps.Log)
+
+	deps.Jobs.Register(JobProvisionUser, syncer.handleUserJob)
+	deps.Jobs.Register(JobSyncRole, syncer.handleUserJob)
+	deps.Jobs.Register(JobReconcile, syncer.handleReconcileJob)
+
+	// Event handlers only queue jobs - Keycloak calls are retried by the job workers
+	handlers := map[string]events.Handler{
+		events.TypeUserRegistered:  syncer.HandleUserRegistered,
+		events.TypeUserRoleChanged: syncer.HandleUserRoleChanged,
+	}
+	for eventType, handler := range handlers {
+		if err := deps.Events.Subscribe(eventType, "keycloak-sync", handler); err != nil {
+			deps.Log.Error("Failed to subscribe Keycloak sync to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+
+	return &Module{
+		syncer:     syncer,
+		reconciler: lock.Singleton(deps.Locks, syncer, deps.Config.Locks.LeaderTTL, deps.Log),
+		handler:    NewHandler(deps.Jobs, deps.Tokens, deps.Log),
+	}
+}
+
+// Migrations returns nil - sync state lives on the users table
+func (m *Module) Migrations() []migrations.Migration {
+	return nil
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	if m.handler != nil {
+		m.handler.RegisterRoutes(router)
+	}
+}
+
+// Services returns the periodic reconciler when sync is enabled
+func (m *Module) Services() []module.Service {
+	if m.syncer == nil {
+		return nil
+	}
+	return []module.Service{m.reconciler}
+}
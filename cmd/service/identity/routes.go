@@ -0,0 +1,58 @@
+package identity
+
+import (
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	jobs   *jobs.Processor
+	tokens *auth.TokenManager
+	log    *zap.Logger
+}
+
+func NewHandler(processor *jobs.Processor, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		jobs:   processor,
+		tokens: tokens,
+		log:    log,
+	}
+}
+
+// RegisterRoutes registers Keycloak sync admin routes
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/keycloak").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+
+	admin.HandleFunc("/reconcile", h.handleReconcile).Methods("POST")
+}
+
+// handleReconcile handles POST /api/v1/admin/keycloak/reconcile
+// @Summary Reconcile users with Keycloak
+// @Description Queues a full reconciliation: provisions users missing from Keycloak and resolves role drift. Track progress via /admin/jobs/{id}.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} models.Job
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/keycloak/reconcile [post]
+func (h *Handler) handleReconcile(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobs.Enqueue(r.Context(), JobReconcile, nil, jobs.MaxAttempts(1))
+	if err != nil {
+		h.log.Error("Failed to queue Keycloak reconciliation", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.log.Info("Keycloak reconciliation queued", zap.String("job_id", job.ID))
+
+	response.JSON(w, http.StatusAccepted, job)
+}
@@ -0,0 +1,311 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/keycloak"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Job types handled by the syncer
+const (
+	JobProvisionUser = "keycloak.provision_user"
+	JobSyncRole      = "keycloak.sync_role"
+	JobReconcile     = "keycloak.reconcile"
+)
+
+// reconcileBatch is how many users are loaded per page during reconciliation
+const reconcileBatch = 100
+
+// userJob is the payload of provision and role sync jobs
+type userJob struct {
+	UserID string `json:"user_id"`
+}
+
+// Syncer keeps local users and Keycloak realm users in step
+// Only the managed roles (customer, admin) are synced; other realm roles are left untouched
+type Syncer struct {
+	users     repository.UserStore
+	admin     *keycloak.AdminClient
+	jobs      *jobs.Processor
+	authority string // local or keycloak - wins when a role changed on both sides
+	interval  time.Duration
+	log       *zap.Logger
+}
+
+func NewSyncer(users repository.UserStore, admin *keycloak.AdminClient, processor *jobs.Processor,
+	authority string, interval time.Duration, log *zap.Logger) *Syncer {
+	return &Syncer{
+		users:     users,
+		admin:     admin,
+		jobs:      processor,
+		authority: authority,
+		interval:  interval,
+		log:       log,
+	}
+}
+
+// HandleUserRegistered queues provisioning of a newly registered user
+func (s *Syncer) HandleUserRegistered(ctx context.Context, event events.Event) error {
+	var payload events.UserRegistered
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	_, err := s.jobs.Enqueue(ctx, JobProvisionUser, userJob{UserID: payload.UserID})
+	return err
+}
+
+// HandleUserRoleChanged queues pushing a locally changed role to Keycloak
+func (s *Syncer) HandleUserRoleChanged(ctx context.Context, event events.Event) error {
+	var payload events.UserRoleChanged
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	_, err := s.jobs.Enqueue(ctx, JobSyncRole, userJob{UserID: payload.UserID})
+	return err
+}
+
+// handleUserJob runs provisioning and role sync jobs; both converge on the same state
+func (s *Syncer) handleUserJob(ctx context.Context, job *models.Job) error {
+	var payload userJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	user, err := s.users.GetUserByID(ctx, payload.UserID)
+	if err != nil {
+		return err
+	}
+	return s.syncUser(ctx, user)
+}
+
+// handleReconcileJob walks every user and repairs drift
+func (s *Syncer) handleReconcileJob(ctx context.Context, job *models.Job) error {
+	return s.Reconcile(ctx)
+}
+
+// Reconcile provisions users missing from Keycloak and resolves role drift for all users
+// A failing user is logged and skipped so one bad record doesn't block the rest
+func (s *Syncer) Reconcile(ctx context.Context) error {
+	var checked, failed int
+	afterID := ""
+	for {
+		users, err := s.users.ListUsersAfter(ctx, afterID, reconcileBatch)
+		if err != nil {
+			return err
+		}
+		for i := range users {
+			checked++
+			if err := s.syncUser(ctx, &users[i]); err != nil {
+				failed++
+				s.log.Warn("Failed to sync user with Keycloak", zap.String("user_id", users[i].ID), zap.Error(err))
+			}
+		}
+		if len(users) < reconcileBatch {
+			break
+		}
+		afterID = users[len(users)-1].ID
+	}
+
+	s.log.Info("Keycloak reconciliation finished", zap.Int("checked", checked), zap.Int("failed", failed))
+	return nil
+}
+
+// syncUser provisions user if needed, then merges local and remote roles
+func (s *Syncer) syncUser(ctx context.Context, user *models.User) error {
+	if user.KeycloakID == "" {
+		return s.provision(ctx, user)
+	}
+
+	err := s.syncRole(ctx, user)
+	if errors.Is(err, keycloak.ErrUserNotFound) {
+		// Deleted in Keycloak - create it again rather than leave a dangling link
+		s.log.Warn("Keycloak user missing, re-provisioning", zap.String("user_id", user.ID))
+		user.KeycloakID = ""
+		return s.provision(ctx, user)
+	}
+	return err
+}
+
+// provision creates the Keycloak user for user, or links an existing one with the same email
+func (s *Syncer) provision(ctx context.Context, user *models.User) error {
+	keycloakID, err := s.admin.CreateUser(ctx, keycloak.User{
+		Username:  user.Email,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Enabled:   true,
+		Attributes: map[string][]string{
+			"ecomgo_user_id": {user.ID},
+		},
+	})
+	linked := errors.Is(err, keycloak.ErrConflict)
+	if linked {
+		existing, findErr := s.admin.FindUserByEmail(ctx, user.Email)
+		if findErr != nil {
+			return findErr
+		}
+		keycloakID, err = existing.ID, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.users.UpdateFields(ctx, user.ID, map[string]interface{}{"keycloak_id": keycloakID}); err != nil {
+		return err
+	}
+	user.KeycloakID = keycloakID
+
+	s.log.Info("User provisioned in Keycloak", zap.String("user_id", user.ID),
+		zap.String("keycloak_id", keycloakID), zap.Bool("linked_existing", linked))
+
+	// A linked user may already hold roles; with no sync history the authority decides
+	if linked {
+		return s.syncRole(ctx, user)
+	}
+
+	// A new Keycloak user has no managed roles yet - assign the local one
+	if err := s.setRemoteRole(ctx, keycloakID, user.Role, nil); err != nil {
+		return err
+	}
+	return s.recordSynced(ctx, user, user.Role)
+}
+
+// syncRole merges the local role with the user's Keycloak realm roles
+// SyncedRole records the last agreed value, so a side that still matches it hasn't changed
+func (s *Syncer) syncRole(ctx context.Context, user *models.User) error {
+	assigned, err := s.admin.RealmRoles(ctx, user.KeycloakID)
+	if err != nil {
+		return err
+	}
+	remote := effectiveRole(assigned)
+	local := user.Role
+
+	if local == remote {
+		if user.SyncedRole != local {
+			return s.recordSynced(ctx, user, local)
+		}
+		return nil
+	}
+
+	localChanged := local != user.SyncedRole
+	remoteChanged := remote != user.SyncedRole
+	pushLocal := localChanged && !remoteChanged
+	if localChanged == remoteChanged {
+		pushLocal = s.authority == "local"
+	}
+
+	if pushLocal {
+		if err := s.setRemoteRole(ctx, user.KeycloakID, local, assigned); err != nil {
+			return err
+		}
+		s.log.Info("Pushed role to Keycloak", zap.String("user_id", user.ID), zap.String("role", local))
+		return s.recordSynced(ctx, user, local)
+	}
+
+	// Pulled roles are not republished as user.role_changed - that would echo back here
+	if err := s.users.UpdateFields(ctx, user.ID, map[string]interface{}{"role": remote, "synced_role": remote}); err != nil {
+		return err
+	}
+	user.Role, user.SyncedRole = remote, remote
+	s.log.Info("Pulled role from Keycloak", zap.String("user_id", user.ID), zap.String("role", remote))
+	return nil
+}
+
+func (s *Syncer) recordSynced(ctx context.Context, user *models.User, role string) error {
+	if err := s.users.UpdateFields(ctx, user.ID, map[string]interface{}{"synced_role": role}); err != nil {
+		return err
+	}
+	user.SyncedRole = role
+	return nil
+}
+
+// setRemoteRole makes role the user's effective managed role in Keycloak
+// customer is always assigned; the elevated roles (admin, vendor) are added or removed on top of it
+func (s *Syncer) setRemoteRole(ctx context.Context, keycloakID, role string, assigned []keycloak.Role) error {
+	if !hasRole(assigned, models.RoleCustomer) {
+		if err := s.assign(ctx, keycloakID, models.RoleCustomer); err != nil {
+			return err
+		}
+	}
+
+	for _, elevated := range elevatedRoles {
+		switch {
+		case role == elevated && !hasRole(assigned, elevated):
+			if err := s.assign(ctx, keycloakID, elevated); err != nil {
+				return err
+			}
+		case role != elevated && hasRole(assigned, elevated):
+			remove, err := s.admin.Role(ctx, elevated)
+			if err != nil {
+				return err
+			}
+			if err := s.admin.RemoveRealmRoles(ctx, keycloakID, []keycloak.Role{*remove}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Syncer) assign(ctx context.Context, keycloakID, name string) error {
+	role, err := s.admin.Role(ctx, name)
+	if err != nil {
+		return err
+	}
+	return s.admin.AddRealmRoles(ctx, keycloakID, []keycloak.Role{*role})
+}
+
+// elevatedRoles are the managed roles above customer, highest first
+var elevatedRoles = []string{models.RoleAdmin, models.RoleVendor}
+
+// effectiveRole maps Keycloak realm roles to the local role: the highest elevated role wins, otherwise customer
+func effectiveRole(roles []keycloak.Role) string {
+	for _, elevated := range elevatedRoles {
+		if hasRole(roles, elevated) {
+			return elevated
+		}
+	}
+	return models.RoleCustomer
+}
+
+func hasRole(roles []keycloak.Role, name string) bool {
+	for _, role := range roles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Syncer) Name() string {
+	return "keycloak-reconciler"
+}
+
+// Run queues a reconciliation every KEYCLOAK_SYNC_INTERVAL until ctx is cancelled
+// It runs on the elected leader only, so one reconciliation is queued per interval; job workers do the work
+func (s *Syncer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.scheduleReconcile(ctx)
+		}
+	}
+}
+
+func (s *Syncer) scheduleReconcile(ctx context.Context) {
+	if _, err := s.jobs.Enqueue(ctx, JobReconcile, nil, jobs.MaxAttempts(1)); err != nil {
+		s.log.Error("Failed to queue Keycloak reconciliation", zap.Error(err))
+	}
+}
@@ -0,0 +1,47 @@
+package inventory
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+)
+
+// Module provides warehouses, per-warehouse stock and transfers, plus manual adjustments and
+// stocktakes recorded in the stock audit log
+// Checkout allocates through deps.Inventory; this module manages what it allocates from
+type Module struct {
+	handler          *Handler
+	stocktakeHandler *StocktakeHandler
+}
+
+func NewModule(deps module.Deps) *Module {
+	warehouses := repository.NewWarehouseRepository(deps.DB, deps.Log)
+	products := repository.NewProductRepository(deps.DB, deps.Log)
+	service := NewInventoryService(warehouses, products, repository.NewAddressRepository(deps.DB, deps.Log),
+		deps.Addresses, deps.Inventory, deps.Events, deps.Log)
+	stocktakes := NewStocktakeService(repository.NewStocktakeRepository(deps.DB, deps.Log), warehouses, products,
+		deps.Events, deps.Clock, deps.Log)
+
+	return &Module{
+		handler:          NewHandler(service, deps.Tokens, deps.Log),
+		stocktakeHandler: NewStocktakeHandler(stocktakes, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.Warehouse{}, &models.WarehouseRate{}, &models.WarehouseStock{}, &models.StockTransfer{},
+			&models.StockAdjustment{}, &models.Stocktake{}, &models.StocktakeLine{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+	m.stocktakeHandler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
@@ -0,0 +1,392 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/inventory"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *InventoryService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *InventoryService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers inventory routes
+// Warehouses, their stock and transfers are admin-only; allocation previews need a signed-in user
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	allocation := router.PathPrefix("/inventory/allocation").Subrouter()
+	allocation.Use(auth.Authenticate(h.tokens))
+	allocation.HandleFunc("", h.handleAllocate).Methods("POST")
+	allocation.HandleFunc("/split", h.handleAllocateSplit).Methods("POST")
+
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.ScopeByMethod("inventory"), auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/warehouses", h.handleCreate).Methods("POST")
+	admin.HandleFunc("/warehouses", h.handleList).Methods("GET")
+	admin.HandleFunc("/warehouses/{id}", h.handleGet).Methods("GET")
+	admin.HandleFunc("/warehouses/{id}", h.handleUpdate).Methods("PUT")
+	admin.HandleFunc("/warehouses/{id}", h.handleDelete).Methods("DELETE")
+	admin.HandleFunc("/warehouses/{id}/stock", h.handleListStock).Methods("GET")
+	admin.HandleFunc("/warehouses/{id}/stock", h.handleAdjustStock).Methods("POST")
+	admin.HandleFunc("/products/{id}/warehouse-stock", h.handleProductStock).Methods("GET")
+	admin.HandleFunc("/stock-transfers", h.handleTransfer).Methods("POST")
+	admin.HandleFunc("/stock-transfers", h.handleListTransfers).Methods("GET")
+}
+
+// handleCreate handles POST /api/v1/admin/warehouses
+// @Summary Create warehouse
+// @Description Adds a stock location. Without latitude/longitude the address is geocoded; rates give the shipping cost to each delivery zone for cheapest allocation.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.WarehouseRequest true "Warehouse"
+// @Success 201 {object} models.Warehouse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 409 {string} string "Warehouse code is already in use"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/warehouses [post]
+func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req models.WarehouseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	warehouse, err := h.service.CreateWarehouse(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, warehouse)
+}
+
+// handleList handles GET /api/v1/admin/warehouses
+// @Summary List warehouses
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Warehouse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/warehouses [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	warehouses, err := h.service.ListWarehouses(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, warehouses)
+}
+
+// handleGet handles GET /api/v1/admin/warehouses/{id}
+// @Summary Get warehouse
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Warehouse ID"
+// @Success 200 {object} models.Warehouse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Warehouse not found"
+// @Router /admin/warehouses/{id} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	warehouse, err := h.service.GetWarehouse(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, warehouse)
+}
+
+// handleUpdate handles PUT /api/v1/admin/warehouses/{id}
+// @Summary Update warehouse
+// @Description Replaces the warehouse's details and rates. Stock is unchanged; inactive warehouses keep it but are never allocated.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Warehouse ID"
+// @Param request body models.WarehouseRequest true "Warehouse"
+// @Success 200 {object} models.Warehouse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Warehouse not found"
+// @Failure 409 {string} string "Warehouse code is already in use"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/warehouses/{id} [put]
+func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req models.WarehouseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	warehouse, err := h.service.UpdateWarehouse(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, warehouse)
+}
+
+// handleDelete handles DELETE /api/v1/admin/warehouses/{id}
+// @Summary Delete warehouse
+// @Description Only empty warehouses can be deleted; transfer or write off their stock first.
+// @Tags Inventory
+// @Security BearerAuth
+// @Param id path string true "Warehouse ID"
+// @Success 204
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Warehouse not found"
+// @Failure 409 {string} string "Warehouse still holds stock"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/warehouses/{id} [delete]
+func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteWarehouse(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListStock handles GET /api/v1/admin/warehouses/{id}/stock
+// @Summary Warehouse stock
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Warehouse ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.WarehouseStockListResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Warehouse not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/warehouses/{id}/stock [get]
+func (h *Handler) handleListStock(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	resp, err := h.service.ListStock(r.Context(), mux.Vars(r)["id"], limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleAdjustStock handles POST /api/v1/admin/warehouses/{id}/stock
+// @Summary Adjust warehouse stock
+// @Description Receives (positive delta) or writes off (negative delta) units; the product's total stock moves with it. With assign, places units the product already counts but no warehouse holds.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Warehouse ID"
+// @Param request body models.WarehouseStockRequest true "Adjustment"
+// @Success 200 {object} models.ProductStockResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Warehouse not found"
+// @Failure 409 {string} string "Insufficient stock"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/warehouses/{id}/stock [post]
+func (h *Handler) handleAdjustStock(w http.ResponseWriter, r *http.Request) {
+	var req models.WarehouseStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.AdjustStock(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleProductStock handles GET /api/v1/admin/products/{id}/warehouse-stock
+// @Summary Product stock by warehouse
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} models.ProductStockResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/products/{id}/warehouse-stock [get]
+func (h *Handler) handleProductStock(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.ProductStock(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleTransfer handles POST /api/v1/admin/stock-transfers
+// @Summary Transfer stock
+// @Description Moves units of a product from one warehouse to another. The product's total stock is unchanged.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.StockTransferRequest true "Transfer"
+// @Success 201 {object} models.StockTransfer
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Warehouse not found"
+// @Failure 409 {string} string "Insufficient stock"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/stock-transfers [post]
+func (h *Handler) handleTransfer(w http.ResponseWriter, r *http.Request) {
+	var req models.StockTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	transfer, err := h.service.Transfer(r.Context(), &req, auth.ClaimsFromContext(r.Context()).UserID())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, transfer)
+}
+
+// handleListTransfers handles GET /api/v1/admin/stock-transfers
+// @Summary List stock transfers
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Param product_id query string false "Only this product"
+// @Param warehouse_id query string false "Only transfers into or out of this warehouse"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.StockTransferListResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/stock-transfers [get]
+func (h *Handler) handleListTransfers(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	query := r.URL.Query()
+
+	resp, err := h.service.ListTransfers(r.Context(), query.Get("product_id"), query.Get("warehouse_id"), limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleAllocate handles POST /api/v1/inventory/allocation
+// @Summary Preview allocation
+// @Description Shows which warehouses would ship the items to one of the caller's addresses under the nearest or cheapest rule. Nothing is reserved.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.AllocationRequest true "Basket and address"
+// @Success 200 {object} models.Allocation
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Address not found"
+// @Failure 409 {string} string "Insufficient stock"
+// @Failure 500 {string} string "Internal server error"
+// @Router /inventory/allocation [post]
+func (h *Handler) handleAllocate(w http.ResponseWriter, r *http.Request) {
+	var req models.AllocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	alloc, err := h.service.Allocate(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, alloc)
+}
+
+// handleAllocateSplit handles POST /api/v1/inventory/allocation/split
+// @Summary Preview a split allocation
+// @Description Splits one checkout's items across several of the caller's addresses. Each address gets a shipment group with its own shipments, shipping cost and delivery estimate; groups draw on the same stock in the order given. Nothing is reserved.
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.SplitAllocationRequest true "Items per address"
+// @Success 200 {object} models.SplitAllocation
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Address not found"
+// @Failure 409 {string} string "Insufficient stock"
+// @Failure 500 {string} string "Internal server error"
+// @Router /inventory/allocation/split [post]
+func (h *Handler) handleAllocateSplit(w http.ResponseWriter, r *http.Request) {
+	var req models.SplitAllocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	split, err := h.service.AllocateSplit(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, split)
+}
+
+// writeError maps inventory errors to 400/404/409/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidWarehouse), errors.Is(err, inventory.ErrInvalidAllocation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrWarehouseNotFound):
+		http.Error(w, "Warehouse not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrAddressNotFound):
+		http.Error(w, "Address not found", http.StatusNotFound)
+	case errors.Is(err, ErrWarehouseCodeTaken):
+		http.Error(w, "Warehouse code is already in use", http.StatusConflict)
+	case errors.Is(err, ErrWarehouseNotEmpty):
+		http.Error(w, "Warehouse still holds stock", http.StatusConflict)
+	case errors.Is(err, repository.ErrInsufficientStock):
+		http.Error(w, "Insufficient stock", http.StatusConflict)
+	default:
+		h.log.Error("Inventory request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,393 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/address"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/inventory"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidWarehouse wraps validation problems with a warehouse or stock request
+	ErrInvalidWarehouse = errors.New("invalid warehouse request")
+	// ErrWarehouseCodeTaken is returned when another warehouse already uses the code
+	ErrWarehouseCodeTaken = errors.New("warehouse code is already in use")
+	// ErrWarehouseNotEmpty is returned when deleting a warehouse that still holds stock
+	ErrWarehouseNotEmpty = errors.New("warehouse still holds stock")
+)
+
+var warehouseCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{0,31}$`)
+
+// maxShipmentGroups caps how many addresses one checkout can ship to
+const maxShipmentGroups = 10
+
+// InventoryService manages warehouses, their stock and transfers between them
+// Stock changes keep the product's total in step and publish product.updated, so listings,
+// search and restock alerts see warehouse receipts like any other stock change
+type InventoryService struct {
+	repo      *repository.WarehouseRepository
+	products  repository.ProductStore
+	addresses repository.AddressStore
+	validator address.Validator
+	allocator *inventory.Allocator
+	publisher events.Publisher
+	log       *zap.Logger
+}
+
+func NewInventoryService(repo *repository.WarehouseRepository, products repository.ProductStore,
+	addresses repository.AddressStore, validator address.Validator, allocator *inventory.Allocator,
+	publisher events.Publisher, log *zap.Logger) *InventoryService {
+	return &InventoryService{
+		repo:      repo,
+		products:  products,
+		addresses: addresses,
+		validator: validator,
+		allocator: allocator,
+		publisher: publisher,
+		log:       log,
+	}
+}
+
+// CreateWarehouse validates req and adds a warehouse
+func (s *InventoryService) CreateWarehouse(ctx context.Context, req *models.WarehouseRequest) (*models.Warehouse, error) {
+	warehouse := &models.Warehouse{}
+	if err := s.apply(ctx, warehouse, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, warehouse); err != nil {
+		return nil, codeTaken(err)
+	}
+
+	s.log.Info("Warehouse created", zap.String("id", warehouse.ID), zap.String("code", warehouse.Code))
+	return warehouse, nil
+}
+
+// UpdateWarehouse replaces a warehouse's details and rates; its stock is untouched
+func (s *InventoryService) UpdateWarehouse(ctx context.Context, id string, req *models.WarehouseRequest) (*models.Warehouse, error) {
+	warehouse, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, warehouse, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Replace(ctx, warehouse); err != nil {
+		return nil, codeTaken(err)
+	}
+
+	s.log.Info("Warehouse updated", zap.String("id", warehouse.ID), zap.String("code", warehouse.Code))
+	return s.repo.GetByID(ctx, id)
+}
+
+// DeleteWarehouse removes an empty warehouse
+// Returns: ErrWarehouseNotEmpty while it holds stock - transfer or write it off first
+func (s *InventoryService) DeleteWarehouse(ctx context.Context, id string) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	units, err := s.repo.Units(ctx, id)
+	if err != nil {
+		return err
+	}
+	if units > 0 {
+		return ErrWarehouseNotEmpty
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.log.Info("Warehouse deleted", zap.String("id", id))
+	return nil
+}
+
+func (s *InventoryService) GetWarehouse(ctx context.Context, id string) (*models.Warehouse, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListWarehouses returns every warehouse, active or not, by code
+func (s *InventoryService) ListWarehouses(ctx context.Context) ([]models.Warehouse, error) {
+	warehouses, err := s.repo.List(ctx, false)
+	if warehouses == nil {
+		warehouses = []models.Warehouse{}
+	}
+	return warehouses, err
+}
+
+// ListStock returns a page of what a warehouse holds
+func (s *InventoryService) ListStock(ctx context.Context, warehouseID string,
+	limit, offset int) (*models.WarehouseStockListResponse, error) {
+	if _, err := s.repo.GetByID(ctx, warehouseID); err != nil {
+		return nil, err
+	}
+	rows, total, err := s.repo.ListStock(ctx, warehouseID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []models.WarehouseStock{}
+	}
+	return &models.WarehouseStockListResponse{Stock: rows, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// AdjustStock receives (positive delta) or writes off (negative) stock at a warehouse
+// With req.Assign, positive delta places the product's unassigned units instead of adding new ones
+// Returns: the product's stock breakdown; repository.ErrInsufficientStock when the units aren't there
+func (s *InventoryService) AdjustStock(ctx context.Context, warehouseID string,
+	req *models.WarehouseStockRequest) (*models.ProductStockResponse, error) {
+	switch {
+	case req.ProductID == "":
+		return nil, fmt.Errorf("%w: product_id is required", ErrInvalidWarehouse)
+	case req.Delta == 0:
+		return nil, fmt.Errorf("%w: delta must not be zero", ErrInvalidWarehouse)
+	case req.Assign && req.Delta < 0:
+		return nil, fmt.Errorf("%w: assign needs a positive delta", ErrInvalidWarehouse)
+	}
+	if _, err := s.repo.GetByID(ctx, warehouseID); err != nil {
+		return nil, err
+	}
+	if _, err := s.products.GetByID(ctx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AdjustStock(ctx, warehouseID, req.ProductID, req.Delta, req.Assign); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Warehouse stock adjusted", zap.String("warehouse_id", warehouseID),
+		zap.String("product_id", req.ProductID), zap.Int("delta", req.Delta), zap.Bool("assign", req.Assign))
+	if !req.Assign {
+		s.publishUpdated(ctx, req.ProductID)
+	}
+	return s.ProductStock(ctx, req.ProductID)
+}
+
+// Transfer moves stock between warehouses; the product's total doesn't change
+func (s *InventoryService) Transfer(ctx context.Context, req *models.StockTransferRequest, adminID string) (*models.StockTransfer, error) {
+	note := strings.TrimSpace(req.Note)
+	switch {
+	case req.ProductID == "" || req.FromWarehouseID == "" || req.ToWarehouseID == "":
+		return nil, fmt.Errorf("%w: product_id, from_warehouse_id and to_warehouse_id are required", ErrInvalidWarehouse)
+	case req.FromWarehouseID == req.ToWarehouseID:
+		return nil, fmt.Errorf("%w: from_warehouse_id and to_warehouse_id must differ", ErrInvalidWarehouse)
+	case req.Quantity <= 0:
+		return nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidWarehouse)
+	case len(note) > 255:
+		return nil, fmt.Errorf("%w: note must be at most 255 characters", ErrInvalidWarehouse)
+	}
+	for _, id := range []string{req.FromWarehouseID, req.ToWarehouseID} {
+		if _, err := s.repo.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.products.GetByID(ctx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	transfer := &models.StockTransfer{
+		ProductID:       req.ProductID,
+		FromWarehouseID: req.FromWarehouseID,
+		ToWarehouseID:   req.ToWarehouseID,
+		Quantity:        req.Quantity,
+		Note:            note,
+		CreatedBy:       adminID,
+	}
+	if err := s.repo.Transfer(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Stock transferred", zap.String("id", transfer.ID), zap.String("product_id", transfer.ProductID),
+		zap.String("from", transfer.FromWarehouseID), zap.String("to", transfer.ToWarehouseID),
+		zap.Int("quantity", transfer.Quantity))
+	return transfer, nil
+}
+
+// ListTransfers returns a page of transfers, newest first
+func (s *InventoryService) ListTransfers(ctx context.Context, productID, warehouseID string,
+	limit, offset int) (*models.StockTransferListResponse, error) {
+	transfers, total, err := s.repo.ListTransfers(ctx, productID, warehouseID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if transfers == nil {
+		transfers = []models.StockTransfer{}
+	}
+	return &models.StockTransferListResponse{Transfers: transfers, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// ProductStock breaks a product's stock down by warehouse
+func (s *InventoryService) ProductStock(ctx context.Context, productID string) (*models.ProductStockResponse, error) {
+	product, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.StockOf(ctx, []string{productID})
+	if err != nil {
+		return nil, err
+	}
+	warehouses, err := s.repo.List(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	codes := make(map[string]string, len(warehouses))
+	for _, w := range warehouses {
+		codes[w.ID] = w.Code
+	}
+
+	resp := &models.ProductStockResponse{
+		ProductID:  productID,
+		Total:      product.Stock,
+		Unassigned: product.Stock,
+		Warehouses: []models.ProductStockByPlace{},
+	}
+	for _, row := range rows {
+		resp.Unassigned -= row.Quantity
+		resp.Warehouses = append(resp.Warehouses, models.ProductStockByPlace{
+			WarehouseID:   row.WarehouseID,
+			WarehouseCode: codes[row.WarehouseID],
+			Quantity:      row.Quantity,
+		})
+	}
+	return resp, nil
+}
+
+// Allocate previews which warehouses would ship a basket to one of the user's addresses
+func (s *InventoryService) Allocate(ctx context.Context, userID string, req *models.AllocationRequest) (*models.Allocation, error) {
+	if req.AddressID == "" {
+		return nil, fmt.Errorf("%w: address_id is required", inventory.ErrInvalidAllocation)
+	}
+	dest, err := s.addresses.GetByID(ctx, req.AddressID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.allocator.Allocate(ctx, *dest, req.Items, strings.ToLower(strings.TrimSpace(req.Rule)))
+}
+
+// AllocateSplit previews a checkout split across several of the user's addresses
+func (s *InventoryService) AllocateSplit(ctx context.Context, userID string, req *models.SplitAllocationRequest) (*models.SplitAllocation, error) {
+	switch {
+	case len(req.Groups) == 0:
+		return nil, fmt.Errorf("%w: groups are required", inventory.ErrInvalidAllocation)
+	case len(req.Groups) > maxShipmentGroups:
+		return nil, fmt.Errorf("%w: at most %d groups", inventory.ErrInvalidAllocation, maxShipmentGroups)
+	}
+
+	dests := make([]inventory.Destination, 0, len(req.Groups))
+	seen := make(map[string]bool, len(req.Groups))
+	for _, group := range req.Groups {
+		switch {
+		case group.AddressID == "":
+			return nil, fmt.Errorf("%w: every group needs an address_id", inventory.ErrInvalidAllocation)
+		case seen[group.AddressID]:
+			return nil, fmt.Errorf("%w: address %s is in two groups", inventory.ErrInvalidAllocation, group.AddressID)
+		}
+		seen[group.AddressID] = true
+		dest, err := s.addresses.GetByID(ctx, group.AddressID, userID)
+		if err != nil {
+			return nil, err
+		}
+		dests = append(dests, inventory.Destination{Address: *dest, Items: group.Items})
+	}
+	return s.allocator.AllocateSplit(ctx, dests, strings.ToLower(strings.TrimSpace(req.Rule)))
+}
+
+// apply validates req and copies it onto warehouse, geocoding the address when no
+// coordinates are given
+func (s *InventoryService) apply(ctx context.Context, warehouse *models.Warehouse, req *models.WarehouseRequest) error {
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	name := strings.TrimSpace(req.Name)
+	line1 := strings.TrimSpace(req.Line1)
+	country := strings.ToUpper(strings.TrimSpace(req.Country))
+
+	switch {
+	case !warehouseCodePattern.MatchString(code):
+		return fmt.Errorf("%w: code must be up to 32 characters of A-Z, 0-9, - and _", ErrInvalidWarehouse)
+	case name == "" || line1 == "":
+		return fmt.Errorf("%w: name and line1 are required", ErrInvalidWarehouse)
+	case len(country) != 2:
+		return fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", ErrInvalidWarehouse)
+	case (req.Latitude == nil) != (req.Longitude == nil):
+		return fmt.Errorf("%w: latitude and longitude go together", ErrInvalidWarehouse)
+	case req.Latitude != nil && (*req.Latitude < -90 || *req.Latitude > 90 || *req.Longitude < -180 || *req.Longitude > 180):
+		return fmt.Errorf("%w: coordinates out of range", ErrInvalidWarehouse)
+	}
+
+	rates := make([]models.WarehouseRate, 0, len(req.Rates))
+	seen := make(map[string]bool, len(req.Rates))
+	for _, rate := range req.Rates {
+		zone := strings.TrimSpace(rate.Zone)
+		switch {
+		case zone == "":
+			return fmt.Errorf("%w: every rate needs a zone", ErrInvalidWarehouse)
+		case seen[zone]:
+			return fmt.Errorf("%w: zone %s has two rates", ErrInvalidWarehouse, zone)
+		case rate.Cost < 0:
+			return fmt.Errorf("%w: cost cannot be negative", ErrInvalidWarehouse)
+		case rate.MinDays < 0 || rate.MaxDays < 0:
+			return fmt.Errorf("%w: min_days and max_days cannot be negative", ErrInvalidWarehouse)
+		case rate.MaxDays > 0 && rate.MinDays > rate.MaxDays:
+			return fmt.Errorf("%w: min_days cannot be more than max_days", ErrInvalidWarehouse)
+		}
+		seen[zone] = true
+		maxDays := rate.MaxDays
+		if maxDays == 0 {
+			maxDays = rate.MinDays
+		}
+		rates = append(rates, models.WarehouseRate{WarehouseID: warehouse.ID, Zone: zone, Cost: rate.Cost,
+			MinDays: rate.MinDays, MaxDays: maxDays})
+	}
+
+	if existing, err := s.repo.GetByCode(ctx, code); err == nil && existing.ID != warehouse.ID {
+		return ErrWarehouseCodeTaken
+	} else if err != nil && !errors.Is(err, repository.ErrWarehouseNotFound) {
+		return err
+	}
+
+	warehouse.Code = code
+	warehouse.Name = name
+	warehouse.Phone = strings.TrimSpace(req.Phone)
+	warehouse.Line1 = line1
+	warehouse.City = strings.TrimSpace(req.City)
+	warehouse.Region = strings.TrimSpace(req.Region)
+	warehouse.PostalCode = strings.TrimSpace(req.PostalCode)
+	warehouse.Country = country
+	warehouse.Latitude, warehouse.Longitude = req.Latitude, req.Longitude
+	warehouse.Active = req.Active == nil || *req.Active
+	warehouse.Rates = rates
+
+	if warehouse.Latitude == nil {
+		s.geocode(ctx, warehouse)
+	}
+	return nil
+}
+
+// geocode fills in coordinates from the address validator
+// Best effort: without coordinates the warehouse still works, it just ranks last for nearest
+func (s *InventoryService) geocode(ctx context.Context, warehouse *models.Warehouse) {
+	result, err := s.validator.Validate(ctx, warehouse.Address())
+	if err != nil {
+		s.log.Warn("Could not geocode warehouse address", zap.String("code", warehouse.Code), zap.Error(err))
+		return
+	}
+	warehouse.Latitude, warehouse.Longitude = result.Address.Latitude, result.Address.Longitude
+}
+
+// publishUpdated is best effort - the next reindex repairs a lost event
+func (s *InventoryService) publishUpdated(ctx context.Context, productID string) {
+	_ = events.Publish(ctx, s.publisher, s.log, events.TypeProductUpdated, events.ProductUpdated{ProductID: productID})
+}
+
+// codeTaken reports a write that raced another for the same warehouse code as ErrWarehouseCodeTaken
+func codeTaken(err error) error {
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		return ErrWarehouseCodeTaken
+	}
+	return err
+}
@@ -0,0 +1,300 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	maxAdjustmentNote = 500
+	maxStocktakeLines = 5000
+	maxCountsPerCall  = 500
+)
+
+var (
+	// ErrInvalidAdjustment wraps validation problems with a stock adjustment
+	ErrInvalidAdjustment = errors.New("invalid stock adjustment")
+	// ErrInvalidStocktake wraps validation problems with a stocktake or its counts
+	ErrInvalidStocktake = errors.New("invalid stocktake request")
+)
+
+// manualReasons are the reason codes admins can give a manual adjustment
+var manualReasons = map[string]bool{
+	models.AdjustDamaged:    true,
+	models.AdjustLost:       true,
+	models.AdjustTheft:      true,
+	models.AdjustExpired:    true,
+	models.AdjustFound:      true,
+	models.AdjustReturned:   true,
+	models.AdjustCorrection: true,
+}
+
+// StocktakeService records manual stock adjustments and runs stocktakes
+// Both write the stock audit log (stock_adjustments) in the same transaction as the stock change,
+// so the log always explains the stock. Changes publish product.updated like any other.
+type StocktakeService struct {
+	repo       *repository.StocktakeRepository
+	warehouses *repository.WarehouseRepository
+	products   repository.ProductStore
+	publisher  events.Publisher
+	clock      clock.Clock
+	log        *zap.Logger
+}
+
+func NewStocktakeService(repo *repository.StocktakeRepository, warehouses *repository.WarehouseRepository,
+	products repository.ProductStore, publisher events.Publisher, clk clock.Clock, log *zap.Logger) *StocktakeService {
+	return &StocktakeService{
+		repo:       repo,
+		warehouses: warehouses,
+		products:   products,
+		publisher:  publisher,
+		clock:      clk,
+		log:        log,
+	}
+}
+
+// Adjust changes a product's stock by hand, with a reason code, and logs it
+// Returns: the audit log entry; repository.ErrInsufficientStock when the units aren't there
+func (s *StocktakeService) Adjust(ctx context.Context, adminID string, req *models.StockAdjustRequest) (*models.StockAdjustment, error) {
+	note := strings.TrimSpace(req.Note)
+	reason := strings.ToLower(strings.TrimSpace(req.Reason))
+	switch {
+	case req.ProductID == "":
+		return nil, fmt.Errorf("%w: product_id is required", ErrInvalidAdjustment)
+	case req.Delta == 0:
+		return nil, fmt.Errorf("%w: delta must not be zero", ErrInvalidAdjustment)
+	case !manualReasons[reason]:
+		return nil, fmt.Errorf("%w: reason must be damaged, lost, theft, expired, found, returned or correction", ErrInvalidAdjustment)
+	case len(note) > maxAdjustmentNote:
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalidAdjustment, maxAdjustmentNote)
+	}
+	if req.WarehouseID != "" {
+		if _, err := s.warehouses.GetByID(ctx, req.WarehouseID); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.products.GetByID(ctx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	adjustment := &models.StockAdjustment{
+		ProductID:   req.ProductID,
+		WarehouseID: req.WarehouseID,
+		Reason:      reason,
+		Delta:       req.Delta,
+		Note:        note,
+		CreatedBy:   adminID,
+	}
+	if err := s.repo.Adjust(ctx, adjustment); err != nil {
+		return nil, err
+	}
+	s.log.Info("Stock adjusted", zap.String("product_id", req.ProductID), zap.String("warehouse_id", req.WarehouseID),
+		zap.String("reason", reason), zap.Int("delta", req.Delta), zap.String("admin_id", adminID))
+	s.publishUpdated(ctx, req.ProductID)
+	return adjustment, nil
+}
+
+// Adjustments returns a page of the stock audit log, newest first
+func (s *StocktakeService) Adjustments(ctx context.Context, filter repository.StockAdjustmentFilter,
+	limit, offset int) (*models.StockAdjustmentListResponse, error) {
+	adjustments, total, err := s.repo.ListAdjustments(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if adjustments == nil {
+		adjustments = []models.StockAdjustment{}
+	}
+	return &models.StockAdjustmentListResponse{Adjustments: adjustments, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Start opens a stocktake of a warehouse: of req.ProductIDs, or of everything it holds
+func (s *StocktakeService) Start(ctx context.Context, adminID string, req *models.StocktakeRequest) (*models.Stocktake, error) {
+	note := strings.TrimSpace(req.Note)
+	switch {
+	case req.WarehouseID == "":
+		return nil, fmt.Errorf("%w: warehouse_id is required", ErrInvalidStocktake)
+	case len(req.ProductIDs) > maxStocktakeLines:
+		return nil, fmt.Errorf("%w: at most %d product_ids", ErrInvalidStocktake, maxStocktakeLines)
+	case len(note) > maxAdjustmentNote:
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalidStocktake, maxAdjustmentNote)
+	}
+	if _, err := s.warehouses.GetByID(ctx, req.WarehouseID); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(req.ProductIDs))
+	for _, id := range req.ProductIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("%w: product %s is listed twice", ErrInvalidStocktake, id)
+		}
+		seen[id] = true
+		if _, err := s.products.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	stocktake := &models.Stocktake{
+		WarehouseID: req.WarehouseID,
+		Status:      models.StocktakeOpen,
+		Note:        note,
+		CreatedBy:   adminID,
+	}
+	if err := s.repo.Create(ctx, stocktake, req.ProductIDs); err != nil {
+		return nil, err
+	}
+	s.log.Info("Stocktake started", zap.String("id", stocktake.ID), zap.String("warehouse_id", stocktake.WarehouseID),
+		zap.Int("lines", len(stocktake.Lines)))
+	return s.repo.Get(ctx, stocktake.ID)
+}
+
+// Get returns a stocktake with its lines
+func (s *StocktakeService) Get(ctx context.Context, id string) (*models.Stocktake, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// List returns a page of stocktakes, optionally of one warehouse and in one state
+func (s *StocktakeService) List(ctx context.Context, warehouseID, status string, limit, offset int) (*models.StocktakeListResponse, error) {
+	switch status {
+	case "", models.StocktakeOpen, models.StocktakeCompleted, models.StocktakeCancelled:
+	default:
+		return nil, fmt.Errorf("%w: status must be open, completed or cancelled", ErrInvalidStocktake)
+	}
+	stocktakes, total, err := s.repo.List(ctx, warehouseID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if stocktakes == nil {
+		stocktakes = []models.Stocktake{}
+	}
+	return &models.StocktakeListResponse{Stocktakes: stocktakes, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Count records counted quantities on an open stocktake
+// Each count is compared with what the warehouse holds as it is recorded
+func (s *StocktakeService) Count(ctx context.Context, id string, req *models.StocktakeCountRequest) (*models.Stocktake, error) {
+	if len(req.Counts) == 0 || len(req.Counts) > maxCountsPerCall {
+		return nil, fmt.Errorf("%w: send 1 to %d counts", ErrInvalidStocktake, maxCountsPerCall)
+	}
+	stocktake, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make(map[string]bool, len(stocktake.Lines))
+	for _, line := range stocktake.Lines {
+		listed[line.ProductID] = true
+	}
+	seen := make(map[string]bool, len(req.Counts))
+	for _, count := range req.Counts {
+		switch {
+		case count.ProductID == "" || count.Counted < 0:
+			return nil, fmt.Errorf("%w: every count needs a product_id and a counted quantity of 0 or more", ErrInvalidStocktake)
+		case seen[count.ProductID]:
+			return nil, fmt.Errorf("%w: product %s is counted twice", ErrInvalidStocktake, count.ProductID)
+		}
+		seen[count.ProductID] = true
+		if !listed[count.ProductID] {
+			if _, err := s.products.GetByID(ctx, count.ProductID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.repo.RecordCounts(ctx, stocktake, req.Counts, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// Variance reports how a stocktake's counts differ from the system quantities
+func (s *StocktakeService) Variance(ctx context.Context, id string) (*models.VarianceReport, error) {
+	stocktake, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.VarianceReport{
+		StocktakeID: stocktake.ID,
+		WarehouseID: stocktake.WarehouseID,
+		Status:      stocktake.Status,
+		Lines:       len(stocktake.Lines),
+		Variances:   []models.StocktakeLine{},
+		Uncounted:   []string{},
+	}
+	for _, line := range stocktake.Lines {
+		switch {
+		case line.Counted == nil:
+			report.Uncounted = append(report.Uncounted, line.ProductID)
+			continue
+		case line.Variance > 0:
+			report.Over++
+			report.UnitsOver += line.Variance
+		case line.Variance < 0:
+			report.Short++
+			report.UnitsShort -= line.Variance
+		default:
+			report.Matched++
+		}
+		report.Counted++
+		if line.Variance != 0 {
+			report.Variances = append(report.Variances, line)
+		}
+	}
+	report.NetVariance = report.UnitsOver - report.UnitsShort
+	sort.SliceStable(report.Variances, func(i, j int) bool {
+		return abs(report.Variances[i].Variance) > abs(report.Variances[j].Variance)
+	})
+	return report, nil
+}
+
+// Complete closes a stocktake and books its counted variances into stock, logged as stocktake adjustments
+// Uncounted products keep their stock
+func (s *StocktakeService) Complete(ctx context.Context, id, adminID string) (*models.VarianceReport, error) {
+	stocktake, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	adjustments, err := s.repo.Complete(ctx, stocktake, adminID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Stocktake completed", zap.String("id", id), zap.String("warehouse_id", stocktake.WarehouseID),
+		zap.Int("adjustments", len(adjustments)), zap.String("admin_id", adminID))
+	for _, adjustment := range adjustments {
+		s.publishUpdated(ctx, adjustment.ProductID)
+	}
+	return s.Variance(ctx, id)
+}
+
+// Cancel closes an open stocktake without changing stock
+func (s *StocktakeService) Cancel(ctx context.Context, id string) (*models.Stocktake, error) {
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Cancel(ctx, id, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	s.log.Info("Stocktake cancelled", zap.String("id", id))
+	return s.repo.Get(ctx, id)
+}
+
+// publishUpdated is best effort - the next reindex repairs a lost event
+func (s *StocktakeService) publishUpdated(ctx context.Context, productID string) {
+	_ = events.Publish(ctx, s.publisher, s.log, events.TypeProductUpdated, events.ProductUpdated{ProductID: productID})
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,281 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type StocktakeHandler struct {
+	service *StocktakeService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewStocktakeHandler(service *StocktakeService, tokens *auth.TokenManager, log *zap.Logger) *StocktakeHandler {
+	return &StocktakeHandler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers stock adjustments, their audit log and stocktakes (admin only)
+func (h *StocktakeHandler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.ScopeByMethod("inventory"), auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/stock-adjustments", h.handleAdjust).Methods("POST")
+	admin.HandleFunc("/stock-adjustments", h.handleListAdjustments).Methods("GET")
+	admin.HandleFunc("/stocktakes", h.handleStart).Methods("POST")
+	admin.HandleFunc("/stocktakes", h.handleList).Methods("GET")
+	admin.HandleFunc("/stocktakes/{id}", h.handleGet).Methods("GET")
+	admin.HandleFunc("/stocktakes/{id}/counts", h.handleCount).Methods("POST")
+	admin.HandleFunc("/stocktakes/{id}/variance", h.handleVariance).Methods("GET")
+	admin.HandleFunc("/stocktakes/{id}/complete", h.handleComplete).Methods("POST")
+	admin.HandleFunc("/stocktakes/{id}/cancel", h.handleCancel).Methods("POST")
+}
+
+// handleAdjust handles POST /api/v1/admin/stock-adjustments
+// @Summary Adjust stock
+// @Description Changes a product's stock at a warehouse (or its unassigned stock without warehouse_id) with a reason code, and records it in the stock audit log
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.StockAdjustRequest true "Adjustment"
+// @Success 201 {object} models.StockAdjustment
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Warehouse or product not found"
+// @Failure 409 {string} string "Insufficient stock"
+// @Router /admin/stock-adjustments [post]
+func (h *StocktakeHandler) handleAdjust(w http.ResponseWriter, r *http.Request) {
+	var req models.StockAdjustRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	adjustment, err := h.service.Adjust(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, adjustment)
+}
+
+// handleListAdjustments handles GET /api/v1/admin/stock-adjustments
+// @Summary Stock audit log
+// @Description Manual adjustments and stocktake variances, newest first
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Param product_id query string false "Only this product"
+// @Param warehouse_id query string false "Only this warehouse"
+// @Param reason query string false "Only this reason code"
+// @Param stocktake_id query string false "Only this stocktake's variances"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.StockAdjustmentListResponse
+// @Router /admin/stock-adjustments [get]
+func (h *StocktakeHandler) handleListAdjustments(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	query := r.URL.Query()
+	filter := repository.StockAdjustmentFilter{
+		ProductID:   query.Get("product_id"),
+		WarehouseID: query.Get("warehouse_id"),
+		Reason:      query.Get("reason"),
+		StocktakeID: query.Get("stocktake_id"),
+	}
+
+	resp, err := h.service.Adjustments(r.Context(), filter, limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleStart handles POST /api/v1/admin/stocktakes
+// @Summary Start stocktake
+// @Description Opens a count of a warehouse: of product_ids, or of every product it holds
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.StocktakeRequest true "Stocktake"
+// @Success 201 {object} models.Stocktake
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Warehouse or product not found"
+// @Router /admin/stocktakes [post]
+func (h *StocktakeHandler) handleStart(w http.ResponseWriter, r *http.Request) {
+	var req models.StocktakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	stocktake, err := h.service.Start(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, stocktake)
+}
+
+// handleList handles GET /api/v1/admin/stocktakes
+// @Summary List stocktakes
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Param warehouse_id query string false "Only this warehouse"
+// @Param status query string false "open, completed or cancelled"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.StocktakeListResponse
+// @Failure 400 {string} string "Invalid request"
+// @Router /admin/stocktakes [get]
+func (h *StocktakeHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	query := r.URL.Query()
+
+	resp, err := h.service.List(r.Context(), query.Get("warehouse_id"), query.Get("status"), limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleGet handles GET /api/v1/admin/stocktakes/{id}
+// @Summary Get stocktake
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Stocktake ID"
+// @Success 200 {object} models.Stocktake
+// @Failure 404 {string} string "Stocktake not found"
+// @Router /admin/stocktakes/{id} [get]
+func (h *StocktakeHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	stocktake, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, stocktake)
+}
+
+// handleCount handles POST /api/v1/admin/stocktakes/{id}/counts
+// @Summary Record stocktake counts
+// @Description Records counted quantities against what the warehouse holds now; counting a product again replaces its count, and products not on the stocktake are added
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Stocktake ID"
+// @Param request body models.StocktakeCountRequest true "Counts"
+// @Success 200 {object} models.Stocktake
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Stocktake or product not found"
+// @Failure 409 {string} string "Stocktake is not open"
+// @Router /admin/stocktakes/{id}/counts [post]
+func (h *StocktakeHandler) handleCount(w http.ResponseWriter, r *http.Request) {
+	var req models.StocktakeCountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	stocktake, err := h.service.Count(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, stocktake)
+}
+
+// handleVariance handles GET /api/v1/admin/stocktakes/{id}/variance
+// @Summary Stocktake variance report
+// @Description Counted vs. system quantities: lines over and short, unit totals, the variances largest first and what is still uncounted
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Stocktake ID"
+// @Success 200 {object} models.VarianceReport
+// @Failure 404 {string} string "Stocktake not found"
+// @Router /admin/stocktakes/{id}/variance [get]
+func (h *StocktakeHandler) handleVariance(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.Variance(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, report)
+}
+
+// handleComplete handles POST /api/v1/admin/stocktakes/{id}/complete
+// @Summary Complete stocktake
+// @Description Books every counted variance into the warehouse as a stocktake adjustment in the audit log; uncounted products keep their stock
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Stocktake ID"
+// @Success 200 {object} models.VarianceReport
+// @Failure 404 {string} string "Stocktake not found"
+// @Failure 409 {string} string "Stocktake is not open"
+// @Router /admin/stocktakes/{id}/complete [post]
+func (h *StocktakeHandler) handleComplete(w http.ResponseWriter, r *http.Request) {
+	report, err := h.service.Complete(r.Context(), mux.Vars(r)["id"], auth.ClaimsFromContext(r.Context()).UserID())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, report)
+}
+
+// handleCancel handles POST /api/v1/admin/stocktakes/{id}/cancel
+// @Summary Cancel stocktake
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Stocktake ID"
+// @Success 200 {object} models.Stocktake
+// @Failure 404 {string} string "Stocktake not found"
+// @Failure 409 {string} string "Stocktake is not open"
+// @Router /admin/stocktakes/{id}/cancel [post]
+func (h *StocktakeHandler) handleCancel(w http.ResponseWriter, r *http.Request) {
+	stocktake, err := h.service.Cancel(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, stocktake)
+}
+
+// writeError maps adjustment and stocktake errors to 400/404/409/500
+func (h *StocktakeHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidAdjustment), errors.Is(err, ErrInvalidStocktake):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrStocktakeNotFound):
+		http.Error(w, "Stocktake not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrWarehouseNotFound):
+		http.Error(w, "Warehouse not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrStocktakeClosed):
+		http.Error(w, "Stocktake is not open", http.StatusConflict)
+	case errors.Is(err, repository.ErrInsufficientStock):
+		http.Error(w, "Insufficient stock", http.StatusConflict)
+	default:
+		h.log.Error("Stock request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
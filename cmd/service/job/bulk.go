@@ -0,0 +1,115 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/bulk"
+	"github.com/Jason-Omondi/ecomgo/internal/email"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+// BulkResendEmails requeues dead-lettered emails, e.g. after a provider outage
+const BulkResendEmails = "resend_emails"
+
+const resendPageSize = 100
+
+// ResendParams are the parameters of resend_emails; both filters are optional
+type ResendParams struct {
+	FailedSince *time.Time `json:"failed_since,omitempty"` // only emails that died at or after this time
+	To          string     `json:"to,omitempty"`           // only emails to this address
+}
+
+// resendState is saved with each progress report and becomes the job's result
+type resendState struct {
+	Offset   int `json:"offset,omitempty"` // dead jobs passed over so far; requeued ones leave the list
+	Requeued int `json:"requeued"`
+	Skipped  int `json:"skipped"` // retried by someone else in the meantime
+}
+
+// registerBulkActions adds the dead letter queue's actions to registry
+func registerBulkActions(registry *bulk.Registry, queue jobs.Queue) {
+	registry.Register(bulk.Action{
+		Name: BulkResendEmails,
+		Description: `Requeues dead-lettered emails with a fresh attempt budget, optionally only those that failed ` +
+			`since a time or were sent to one address: {"failed_since": "2024-05-01T00:00:00Z", "to": "jane@example.com"}.`,
+		Validate: validateResend,
+		Run: func(ctx context.Context, run *bulk.Run) (interface{}, error) {
+			return resendEmails(ctx, queue, run)
+		},
+	})
+}
+
+func validateResend(ctx context.Context, raw json.RawMessage) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	var params ResendParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return errors.New("params must be a JSON object with an RFC 3339 failed_since")
+	}
+	return nil
+}
+
+// resendEmails walks the dead letter queue and requeues the emails that match
+// Only emails that died before the action was queued are resent, so a message failing
+// again while the action runs isn't picked up a second time
+func resendEmails(ctx context.Context, queue jobs.Queue, run *bulk.Run) (interface{}, error) {
+	var params ResendParams
+	if err := run.Params(&params); err != nil {
+		return nil, err
+	}
+	var state resendState
+	if err := run.State(&state); err != nil {
+		return nil, err
+	}
+
+	for {
+		page, err := queue.List(ctx, models.JobDead, resendPageSize, state.Offset)
+		if err != nil {
+			return nil, err
+		}
+		if len(page) == 0 {
+			return state, nil
+		}
+
+		for _, job := range page {
+			if !resendMatches(&job, params, run.QueuedAt) {
+				state.Offset++
+				continue
+			}
+			_, err := queue.Requeue(ctx, job.ID)
+			switch {
+			case err == nil:
+				state.Requeued++
+			case errors.Is(err, jobs.ErrJobNotDead), errors.Is(err, jobs.ErrJobNotFound):
+				state.Skipped++
+			default:
+				return nil, err
+			}
+		}
+		if err := run.Progress(ctx, state.Requeued+state.Skipped, 0, state); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func resendMatches(job *models.Job, params ResendParams, queuedAt time.Time) bool {
+	if job.Type != email.JobSend || job.FinishedAt == nil || job.FinishedAt.After(queuedAt) {
+		return false
+	}
+	if params.FailedSince != nil && job.FinishedAt.Before(*params.FailedSince) {
+		return false
+	}
+	if params.To != "" {
+		var msg email.Message
+		if err := job.Decode(&msg); err != nil || !strings.EqualFold(msg.To, params.To) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package job
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/bulk"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type BulkHandler struct {
+	registry *bulk.Registry
+	tokens   *auth.TokenManager
+	log      *zap.Logger
+}
+
+func NewBulkHandler(registry *bulk.Registry, tokens *auth.TokenManager, log *zap.Logger) *BulkHandler {
+	return &BulkHandler{
+		registry: registry,
+		tokens:   tokens,
+		log:      log,
+	}
+}
+
+// RegisterRoutes registers bulk action routes; all require an admin token
+func (h *BulkHandler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/bulk-actions").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+
+	admin.HandleFunc("", h.handleList).Methods("GET")
+	admin.HandleFunc("/{action}", h.handleStart).Methods("POST")
+}
+
+// handleList handles GET /api/v1/admin/bulk-actions
+// @Summary List bulk actions
+// @Description Lists the bulk actions that can be started, with the parameters each takes
+// @Tags Jobs
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.BulkAction
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/bulk-actions [get]
+func (h *BulkHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.registry.Actions())
+}
+
+// handleStart handles POST /api/v1/admin/bulk-actions/{action}
+// @Summary Start bulk action
+// @Description Validates the parameters and queues the action as a background job, returning the job at once.
+// @Description Follow its status and progress (done of total) via GET /admin/jobs/{id}; once it succeeds, result holds its summary.
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param action path string true "Action name, see GET /admin/bulk-actions"
+// @Param params body object false "Action parameters"
+// @Success 202 {object} models.Job
+// @Failure 400 {string} string "Invalid parameters"
+// @Failure 404 {string} string "Unknown bulk action"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/bulk-actions/{action} [post]
+func (h *BulkHandler) handleStart(w http.ResponseWriter, r *http.Request) {
+	var params json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	claims := auth.ClaimsFromContext(r.Context())
+	job, err := h.registry.Start(r.Context(), mux.Vars(r)["action"], params, claims.UserID())
+	switch {
+	case errors.Is(err, bulk.ErrUnknownAction):
+		http.Error(w, "Unknown bulk action", http.StatusNotFound)
+		return
+	case errors.Is(err, bulk.ErrInvalidParams):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		h.log.Error("Failed to queue bulk action", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, job)
+}
@@ -0,0 +1,49 @@
+package job
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/gorilla/mux"
+)
+
+// Module provides background job status and bulk action endpoints and, unless JOBS_RUN_IN_API=false,
+// runs the job workers inside the API process
+type Module struct {
+	handler   *Handler
+	bulk      *BulkHandler
+	processor *jobs.Processor
+	inServer  bool
+}
+
+func NewModule(deps module.Deps) *Module {
+	registerBulkActions(deps.Bulk, deps.Jobs.Queue())
+
+	return &Module{
+		handler:   NewHandler(deps.Jobs.Queue(), deps.Tokens, deps.Log),
+		bulk:      NewBulkHandler(deps.Bulk, deps.Tokens, deps.Log),
+		processor: deps.Jobs,
+		inServer:  deps.Config.Jobs.RunInAPI,
+	}
+}
+
+// Migrations creates the jobs table (used by the db backend; harmless with redis)
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.Job{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+	m.bulk.RegisterRoutes(router)
+}
+
+// Services runs the worker pool unless workers run as separate `worker` processes
+func (m *Module) Services() []module.Service {
+	if !m.inServer {
+		return nil
+	}
+	return []module.Service{m.processor}
+}
@@ -0,0 +1,128 @@
+package job
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	queue  jobs.Queue
+	tokens *auth.TokenManager
+	log    *zap.Logger
+}
+
+func NewHandler(queue jobs.Queue, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		queue:  queue,
+		tokens: tokens,
+		log:    log,
+	}
+}
+
+// RegisterRoutes registers job status routes
+// All routes require an admin token - job payloads may contain customer data
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/jobs").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+
+	admin.HandleFunc("", h.handleList).Methods("GET")
+	admin.HandleFunc("/{id}", h.handleGet).Methods("GET")
+	admin.HandleFunc("/{id}/retry", h.handleRetry).Methods("POST")
+}
+
+// handleList handles GET /api/v1/admin/jobs
+// @Summary List background jobs
+// @Description Lists jobs by status, newest first. Use status=dead to inspect the dead letter queue.
+// @Tags Jobs
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status (queued, running, succeeded, dead)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {array} models.Job
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/jobs [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	list, err := h.queue.List(r.Context(), r.URL.Query().Get("status"), limit, offset)
+	if err != nil {
+		h.log.Error("Failed to list jobs", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []models.Job{}
+	}
+
+	response.JSON(w, http.StatusOK, list)
+}
+
+// handleGet handles GET /api/v1/admin/jobs/{id}
+// @Summary Get job status
+// @Description Returns a job's status, attempts and last error
+// @Tags Jobs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.Job
+// @Failure 404 {string} string "Job not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/jobs/{id} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	job, err := h.queue.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeLookupError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, job)
+}
+
+// handleRetry handles POST /api/v1/admin/jobs/{id}/retry
+// @Summary Retry dead job
+// @Description Moves a dead-lettered job back to the queue with a fresh attempt budget
+// @Tags Jobs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Success 200 {object} models.Job
+// @Failure 404 {string} string "Job not found"
+// @Failure 409 {string} string "Job is not dead"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/jobs/{id}/retry [post]
+func (h *Handler) handleRetry(w http.ResponseWriter, r *http.Request) {
+	job, err := h.queue.Requeue(r.Context(), mux.Vars(r)["id"])
+	if errors.Is(err, jobs.ErrJobNotDead) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.writeLookupError(w, err)
+		return
+	}
+
+	h.log.Info("Dead job requeued", zap.String("job_id", job.ID), zap.String("type", job.Type))
+
+	response.JSON(w, http.StatusOK, job)
+}
+
+// writeLookupError maps queue errors to 404/500
+func (h *Handler) writeLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	h.log.Error("Job lookup failed", zap.Error(err))
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+}
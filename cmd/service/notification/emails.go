@@ -0,0 +1,199 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/email"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/fx"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"go.uber.org/zap"
+)
+
+// sentTTL is how long an event is remembered as emailed; it outlasts the bus's redelivery
+const sentTTL = 24 * time.Hour
+
+// OrderEmails sends the customer's order emails from order lifecycle events: a confirmation
+// on order.placed, a receipt on payment.captured, and shipped and delivered updates. Each is
+// switched on or off per store with an email_* setting and rendered in the customer's locale.
+// These are transactional, so they go out whatever the customer's notification preferences
+type OrderEmails struct {
+	mailer   *email.Mailer
+	settings *settings.Store
+	users    repository.UserStore
+	products repository.ProductStore
+	cache    cache.Cache
+	log      *zap.Logger
+}
+
+func NewOrderEmails(mailer *email.Mailer, store *settings.Store, users repository.UserStore, products repository.ProductStore,
+	appCache cache.Cache, log *zap.Logger) *OrderEmails {
+	return &OrderEmails{
+		mailer:   mailer,
+		settings: store,
+		users:    users,
+		products: products,
+		cache:    appCache,
+		log:      log,
+	}
+}
+
+// HandleOrderPlaced emails the order confirmation with its lines
+func (e *OrderEmails) HandleOrderPlaced(ctx context.Context, event events.Event) error {
+	if !e.settings.Bool(ctx, settings.KeyEmailOrderConfirmation) {
+		return nil
+	}
+	var payload events.OrderPlaced
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	ids := make([]string, len(payload.Items))
+	for i, item := range payload.Items {
+		ids[i] = item.ProductID
+	}
+	products, err := e.products.GetByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string, len(products))
+	for _, product := range products {
+		names[product.ID] = product.Name
+	}
+
+	items := make([]map[string]interface{}, len(payload.Items))
+	for i, item := range payload.Items {
+		items[i] = map[string]interface{}{
+			"Name":     names[item.ProductID],
+			"Quantity": item.Quantity,
+			"Total":    money(item.UnitPrice*int64(item.Quantity), payload.Currency),
+		}
+	}
+
+	return e.send(ctx, event, payload.UserID, email.TemplateOrderConfirmation, email.Data{
+		"OrderNumber": orderNumber(payload.OrderNumber, payload.OrderID),
+		"OrderTotal":  money(payload.Total, payload.Currency),
+		"Items":       items,
+	})
+}
+
+// HandlePaymentCaptured emails the payment receipt
+func (e *OrderEmails) HandlePaymentCaptured(ctx context.Context, event events.Event) error {
+	if !e.settings.Bool(ctx, settings.KeyEmailPaymentReceipt) {
+		return nil
+	}
+	var payload events.PaymentCaptured
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	return e.send(ctx, event, payload.UserID, email.TemplatePaymentReceipt, email.Data{
+		"OrderNumber":      orderNumber(payload.OrderNumber, payload.OrderID),
+		"Amount":           money(payload.Amount, payload.Currency),
+		"PaymentReference": payload.PaymentID,
+	})
+}
+
+// HandleOrderShipped emails the carrier and tracking number
+func (e *OrderEmails) HandleOrderShipped(ctx context.Context, event events.Event) error {
+	if !e.settings.Bool(ctx, settings.KeyEmailOrderShipped) {
+		return nil
+	}
+	var payload events.OrderShipped
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	return e.send(ctx, event, payload.UserID, email.TemplateOrderShipped, email.Data{
+		"OrderNumber":    orderNumber(payload.OrderNumber, payload.OrderID),
+		"Carrier":        payload.Carrier,
+		"TrackingNumber": payload.TrackingNumber,
+	})
+}
+
+// HandleOrderDelivered emails the delivery confirmation
+func (e *OrderEmails) HandleOrderDelivered(ctx context.Context, event events.Event) error {
+	if !e.settings.Bool(ctx, settings.KeyEmailOrderDelivered) {
+		return nil
+	}
+	var payload events.OrderDelivered
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	return e.send(ctx, event, payload.UserID, email.TemplateOrderDelivered, email.Data{
+		"OrderNumber": orderNumber(payload.OrderNumber, payload.OrderID),
+		"DeliveredAt": payload.DeliveredAt.UTC().Format("2006-01-02"),
+	})
+}
+
+// send queues template to the user once per event: the bus delivers at least once, and a
+// redelivered event must not email the customer twice
+func (e *OrderEmails) send(ctx context.Context, event events.Event, userID, template string, data email.Data) error {
+	user, err := e.users.GetUserByID(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		e.log.Warn("Skipping order email for unknown user", zap.String("user_id", userID), zap.String("event_id", event.ID))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	key := "order-email:" + event.ID
+	count, err := e.cache.Incr(ctx, key, sentTTL)
+	if err != nil {
+		return err
+	}
+	if count > 1 {
+		return nil
+	}
+
+	if err := e.mailer.SendAsync(ctx, template, user.Locale, user.Email, user.FirstName, data); err != nil {
+		// Forget the event so the bus's retry sends it
+		if delErr := e.cache.Delete(ctx, key); delErr != nil {
+			e.log.Warn("Failed to clear order email marker", zap.String("event_id", event.ID), zap.Error(delErr))
+		}
+		return err
+	}
+	return nil
+}
+
+func orderNumber(number, id string) string {
+	if number != "" {
+		return number
+	}
+	return id
+}
+
+// money formats minor units as "KES 3,600.00", with the currency's number of decimals
+func money(amount int64, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	decimals := fx.MinorUnits(currency)
+	digits := strconv.FormatInt(amount, 10)
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-decimals], digits[len(digits)-decimals:]
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	if fraction != "" {
+		return fmt.Sprintf("%s %s%s.%s", currency, sign, grouped.String(), fraction)
+	}
+	return fmt.Sprintf("%s %s%s", currency, sign, grouped.String())
+}
@@ -0,0 +1,71 @@
+package notification
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Module provides the in-app notification center, per-user notification settings and the
+// customers' order emails
+// Email/SMS delivery lives in internal/notify so any module can send notifications
+type Module struct {
+	handler *Handler
+}
+
+func NewModule(deps module.Deps) *Module {
+	repo := repository.NewNotificationRepository(deps.DB, deps.Log)
+	service := NewNotificationService(repo, deps.Clock, deps.Log)
+
+	// In-app notifications are fed by domain events
+	handlers := map[string]events.Handler{
+		events.TypeOrderShipped:     service.HandleOrderShipped,
+		events.TypeOrderDelivered:   service.HandleOrderDelivered,
+		events.TypeRefundIssued:     service.HandleRefundIssued,
+		events.TypeQuestionAnswered: service.HandleQuestionAnswered,
+		events.TypeQuestionRejected: service.HandleQuestionRejected,
+		events.TypeQuoteReviewed:    service.HandleQuoteReviewed,
+	}
+	for eventType, handler := range handlers {
+		if err := deps.Events.Subscribe(eventType, "notification-center", handler); err != nil {
+			deps.Log.Error("Failed to subscribe notification center to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+
+	// Order emails subscribe as their own group, so a mail outage doesn't hold up in-app notifications
+	emails := NewOrderEmails(deps.Mailer, deps.Settings, repository.NewUserRepository(deps.DB, deps.Log),
+		repository.NewProductRepository(deps.DB, deps.Log), deps.Cache, deps.Log)
+	emailHandlers := map[string]events.Handler{
+		events.TypeOrderPlaced:     emails.HandleOrderPlaced,
+		events.TypePaymentCaptured: emails.HandlePaymentCaptured,
+		events.TypeOrderShipped:    emails.HandleOrderShipped,
+		events.TypeOrderDelivered:  emails.HandleOrderDelivered,
+	}
+	for eventType, handler := range emailHandlers {
+		if err := deps.Events.Subscribe(eventType, "order-emails", handler); err != nil {
+			deps.Log.Error("Failed to subscribe order emails to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+
+	return &Module{
+		handler: NewHandler(service, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.NotificationPreference{}, &models.Notification{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
@@ -0,0 +1,191 @@
+package notification
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/links"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *NotificationService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *NotificationService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers notification routes for the authenticated user
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	notifications := router.PathPrefix("/notifications").Subrouter()
+	notifications.Use(auth.Authenticate(h.tokens))
+
+	notifications.HandleFunc("", h.handleList).Methods("GET").Name(links.RouteNotifications)
+	notifications.HandleFunc("/unread-count", h.handleUnreadCount).Methods("GET")
+	notifications.HandleFunc("/read-all", h.handleMarkAllRead).Methods("POST")
+	notifications.HandleFunc("/preferences", h.handleGetPreferences).Methods("GET")
+	notifications.HandleFunc("/preferences", h.handleUpdatePreferences).Methods("PUT")
+	notifications.HandleFunc("/{id}/read", h.handleMarkRead).Methods("POST")
+}
+
+// handleList handles GET /api/v1/notifications
+// @Summary List notifications
+// @Description Returns the caller's in-app notifications (newest first) with total and unread counts
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "Only unread notifications"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.NotificationListResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /notifications [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+	limit, offset := pagination.FromRequest(r)
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	resp, err := h.service.ListNotifications(r.Context(), claims.UserID(), unreadOnly, limit, offset)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleUnreadCount handles GET /api/v1/notifications/unread-count
+// @Summary Unread notification count
+// @Description Returns the number of unread notifications (for the badge)
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]int64
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /notifications/unread-count [get]
+func (h *Handler) handleUnreadCount(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	count, err := h.service.UnreadCount(r.Context(), claims.UserID())
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]int64{"unread_count": count})
+}
+
+// handleMarkRead handles POST /api/v1/notifications/{id}/read
+// @Summary Mark notification read
+// @Tags Notifications
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 204
+// @Failure 404 {string} string "Notification not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /notifications/{id}/read [post]
+func (h *Handler) handleMarkRead(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+	id := mux.Vars(r)["id"]
+
+	if err := h.service.MarkRead(r.Context(), claims.UserID(), id); err != nil {
+		if errors.Is(err, repository.ErrNotificationNotFound) {
+			http.Error(w, "Notification not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleMarkAllRead handles POST /api/v1/notifications/read-all
+// @Summary Mark all notifications read
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]int64
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /notifications/read-all [post]
+func (h *Handler) handleMarkAllRead(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	updated, err := h.service.MarkAllRead(r.Context(), claims.UserID())
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]int64{"updated": updated})
+}
+
+// handleGetPreferences handles GET /api/v1/notifications/preferences
+// @Summary Get notification preferences
+// @Description Returns which channel (email, sms, whatsapp, none) each notification category uses
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.NotificationPreference
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /notifications/preferences [get]
+func (h *Handler) handleGetPreferences(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	pref, err := h.service.GetPreferences(r.Context(), claims.UserID())
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, pref)
+}
+
+// handleUpdatePreferences handles PUT /api/v1/notifications/preferences
+// @Summary Update notification preferences
+// @Description Partially updates the phone number and per-category channels
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateNotificationPreferenceRequest true "Preferences update"
+// @Success 200 {object} models.NotificationPreference
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /notifications/preferences [put]
+func (h *Handler) handleUpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	var req models.UpdateNotificationPreferenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid preferences request", zap.Error(err))
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	pref, err := h.service.UpdatePreferences(r.Context(), claims.UserID(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, pref)
+}
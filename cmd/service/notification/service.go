@@ -0,0 +1,251 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/phone"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// NotificationService manages the in-app notification center and per-user channel preferences
+type NotificationService struct {
+	repo  *repository.NotificationRepository
+	clock clock.Clock
+	log   *zap.Logger
+}
+
+func NewNotificationService(repo *repository.NotificationRepository, clk clock.Clock, log *zap.Logger) *NotificationService {
+	return &NotificationService{
+		repo:  repo,
+		clock: clk,
+		log:   log,
+	}
+}
+
+// GetPreferences returns userID's preferences (defaults if never saved)
+func (s *NotificationService) GetPreferences(ctx context.Context, userID string) (*models.NotificationPreference, error) {
+	return s.repo.GetPreferences(ctx, userID)
+}
+
+// UpdatePreferences applies a partial update after validating channels and phone format
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID string,
+	req *models.UpdateNotificationPreferenceRequest) (*models.NotificationPreference, error) {
+	pref, err := s.repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Phone != nil {
+		number := *req.Phone
+		if number != "" {
+			// International format only - the SMS providers need the country code
+			normalized, err := phone.Normalize(number, "")
+			if err != nil {
+				return nil, err
+			}
+			number = normalized
+		}
+		pref.Phone = number
+	}
+	if req.OTPChannel != nil {
+		// OTP codes are required to sign in - opting out is not allowed
+		if *req.OTPChannel == models.ChannelNone {
+			return nil, errors.New("otp_channel cannot be none")
+		}
+		pref.OTPChannel = *req.OTPChannel
+	}
+	if req.OrderUpdatesChannel != nil {
+		pref.OrderUpdatesChannel = *req.OrderUpdatesChannel
+	}
+	if req.PaymentConfirmationsChannel != nil {
+		pref.PaymentConfirmationsChannel = *req.PaymentConfirmationsChannel
+	}
+	if req.StockAlertsChannel != nil {
+		pref.StockAlertsChannel = *req.StockAlertsChannel
+	}
+	if req.MarketingChannel != nil {
+		pref.MarketingChannel = *req.MarketingChannel
+	}
+	if req.QuestionsChannel != nil {
+		pref.QuestionsChannel = *req.QuestionsChannel
+	}
+
+	for _, channel := range []string{pref.OTPChannel, pref.OrderUpdatesChannel, pref.PaymentConfirmationsChannel,
+		pref.StockAlertsChannel, pref.MarketingChannel, pref.QuestionsChannel} {
+		if !validChannel(channel) {
+			return nil, fmt.Errorf("unsupported channel: %s (must be email, sms, whatsapp or none)", channel)
+		}
+	}
+
+	if err := s.repo.SavePreferences(ctx, pref); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Notification preferences updated", zap.String("user_id", userID))
+	return pref, nil
+}
+
+func validChannel(channel string) bool {
+	switch channel {
+	case models.ChannelEmail, models.ChannelSMS, models.ChannelWhatsApp, models.ChannelNone:
+		return true
+	}
+	return false
+}
+
+// ListNotifications returns a page of userID's notifications with total and unread counters
+func (s *NotificationService) ListNotifications(ctx context.Context, userID string, unreadOnly bool,
+	limit, offset int) (*models.NotificationListResponse, error) {
+	notifications, total, err := s.repo.ListNotifications(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.NotificationListResponse{
+		Notifications: notifications,
+		Total:         total,
+		UnreadCount:   unread,
+		Limit:         limit,
+		Offset:        offset,
+	}, nil
+}
+
+// UnreadCount returns the badge count for userID
+func (s *NotificationService) UnreadCount(ctx context.Context, userID string) (int64, error) {
+	return s.repo.CountUnread(ctx, userID)
+}
+
+// MarkRead marks a single notification as read
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id string) error {
+	return s.repo.MarkRead(ctx, userID, id, s.clock.Now())
+}
+
+// MarkAllRead clears the unread badge for userID
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	return s.repo.MarkAllRead(ctx, userID, s.clock.Now())
+}
+
+// HandleOrderShipped creates an in-app notification from an order.shipped event
+func (s *NotificationService) HandleOrderShipped(ctx context.Context, event events.Event) error {
+	var payload events.OrderShipped
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	body := "Your order is on its way."
+	if payload.TrackingNumber != "" {
+		body = fmt.Sprintf("Your order is on its way with %s. Tracking number: %s.", payload.Carrier, payload.TrackingNumber)
+	}
+
+	return s.repo.CreateNotification(ctx, &models.Notification{
+		UserID: payload.UserID,
+		Type:   event.Type,
+		Title:  "Order shipped",
+		Body:   body,
+		Link:   "/orders/" + payload.OrderID,
+	})
+}
+
+// HandleOrderDelivered creates an in-app notification from an order.delivered event
+func (s *NotificationService) HandleOrderDelivered(ctx context.Context, event events.Event) error {
+	var payload events.OrderDelivered
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	return s.repo.CreateNotification(ctx, &models.Notification{
+		UserID: payload.UserID,
+		Type:   event.Type,
+		Title:  "Order delivered",
+		Body:   "Your order has been delivered. Enjoy!",
+		Link:   "/orders/" + payload.OrderID,
+	})
+}
+
+// HandleRefundIssued creates an in-app notification from a refund.issued event
+func (s *NotificationService) HandleRefundIssued(ctx context.Context, event events.Event) error {
+	var payload events.RefundIssued
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	return s.repo.CreateNotification(ctx, &models.Notification{
+		UserID: payload.UserID,
+		Type:   event.Type,
+		Title:  "Refund issued",
+		Body: fmt.Sprintf("We've refunded %s %d.%02d to your original payment method.",
+			payload.Currency, payload.Amount/100, payload.Amount%100),
+		Link: "/orders/" + payload.OrderID,
+	})
+}
+
+// HandleQuestionAnswered creates an in-app notification from a question.answered event
+func (s *NotificationService) HandleQuestionAnswered(ctx context.Context, event events.Event) error {
+	var payload events.QuestionAnswered
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	return s.repo.CreateNotification(ctx, &models.Notification{
+		UserID: payload.UserID,
+		Type:   event.Type,
+		Title:  "Question answered",
+		Body:   fmt.Sprintf("%s answered your question about %s.", payload.AnsweredBy, payload.ProductName),
+		Link:   "/products/" + payload.ProductID,
+	})
+}
+
+// HandleQuestionRejected creates an in-app notification from a question.rejected event
+func (s *NotificationService) HandleQuestionRejected(ctx context.Context, event events.Event) error {
+	var payload events.QuestionRejected
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Your question about %s wasn't published.", payload.ProductName)
+	if payload.Reason != "" {
+		body += " Reason: " + payload.Reason
+	}
+	return s.repo.CreateNotification(ctx, &models.Notification{
+		UserID: payload.UserID,
+		Type:   event.Type,
+		Title:  "Question not published",
+		Body:   body,
+		Link:   "/products/" + payload.ProductID,
+	})
+}
+
+// HandleQuoteReviewed creates an in-app notification from a quote.reviewed event
+func (s *NotificationService) HandleQuoteReviewed(ctx context.Context, event events.Event) error {
+	var payload events.QuoteReviewed
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	title, body := "Quote approved", fmt.Sprintf("Your quote for %s %d.%02d is ready to order.",
+		payload.Currency, payload.Subtotal/100, payload.Subtotal%100)
+	if payload.Status == models.QuoteRejected {
+		title, body = "Quote not approved", "We couldn't offer a quote for your request."
+		if payload.Response != "" {
+			body += " Reason: " + payload.Response
+		}
+	}
+	return s.repo.CreateNotification(ctx, &models.Notification{
+		UserID: payload.UserID,
+		Type:   event.Type,
+		Title:  title,
+		Body:   body,
+		Link:   "/quotes/" + payload.QuoteID,
+	})
+}
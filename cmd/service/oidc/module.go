@@ -0,0 +1,43 @@
+package oidc
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Module provides a minimal OpenID Connect provider (discovery, authorize, token, userinfo,
+// JWKS) so first-party tools sign in with store accounts; codes live in the cache, nothing is stored
+type Module struct {
+	handler *Handler
+}
+
+func NewModule(deps module.Deps) *Module {
+	service, err := NewOIDCService(repository.NewUserRepository(deps.DB, deps.Log), deps.Cache, deps.Clock,
+		deps.Config.OIDC, deps.Log)
+	if err != nil {
+		deps.Log.Fatal("Failed to initialize OpenID Connect provider", zap.Error(err))
+	}
+	if len(deps.Config.OIDC.Clients) > 0 {
+		deps.Log.Info("OpenID Connect provider enabled", zap.String("issuer", deps.Config.OIDC.Issuer),
+			zap.Int("clients", len(deps.Config.OIDC.Clients)))
+	}
+
+	return &Module{
+		handler: NewHandler(service, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return nil
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
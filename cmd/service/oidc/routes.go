@@ -0,0 +1,242 @@
+package oidc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *OIDCService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *OIDCService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers the OpenID Connect provider endpoints
+// Only POST /oidc/authorize takes the API's own bearer token: the login page's, for the user it signed in
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	oidc := router.PathPrefix("/oidc").Subrouter()
+	oidc.HandleFunc("/.well-known/openid-configuration", h.handleDiscovery).Methods("GET")
+	oidc.HandleFunc("/jwks", h.handleJWKS).Methods("GET")
+	oidc.HandleFunc("/authorize", h.handleAuthorize).Methods("GET")
+	oidc.HandleFunc("/token", h.handleToken).Methods("POST")
+	oidc.HandleFunc("/userinfo", h.handleUserInfo).Methods("GET", "POST")
+
+	login := oidc.PathPrefix("/authorize").Subrouter()
+	login.Use(auth.Authenticate(h.tokens))
+	login.HandleFunc("", h.handleCompleteAuthorize).Methods("POST")
+}
+
+// handleDiscovery handles GET /api/v1/oidc/.well-known/openid-configuration
+// @Summary OpenID provider metadata
+// @Description Endpoints and capabilities of the OpenID Connect provider, for client libraries
+// @Tags OpenID Connect
+// @Produce json
+// @Success 200 {object} models.OIDCDiscovery
+// @Router /oidc/.well-known/openid-configuration [get]
+func (h *Handler) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	response.JSON(w, http.StatusOK, h.service.Discovery())
+}
+
+// handleJWKS handles GET /api/v1/oidc/jwks
+// @Summary OpenID provider signing keys
+// @Description The public key ID and access tokens are signed with (RS256)
+// @Tags OpenID Connect
+// @Produce json
+// @Success 200 {object} models.JSONWebKeySet
+// @Router /oidc/jwks [get]
+func (h *Handler) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	response.JSON(w, http.StatusOK, h.service.JWKS())
+}
+
+// handleAuthorize handles GET /api/v1/oidc/authorize
+// @Summary Start an OpenID Connect login
+// @Description Checks the authorization request and redirects the browser to the login page (OIDC_LOGIN_URL) with it. Problems are redirected back to the client as error and state, except an unknown client or redirect_uri, which is answered here.
+// @Tags OpenID Connect
+// @Param response_type query string true "code"
+// @Param client_id query string true "Client ID from OIDC_CLIENTS"
+// @Param redirect_uri query string true "One of the client's redirect URIs, exactly"
+// @Param scope query string true "openid, optionally email and profile"
+// @Param state query string false "Returned to the client as is"
+// @Param nonce query string false "Copied into the ID token"
+// @Param code_challenge query string false "PKCE challenge; required for clients without a secret"
+// @Param code_challenge_method query string false "S256"
+// @Success 302 {string} string "Redirect to the login page"
+// @Failure 400 {string} string "Unknown client or redirect_uri"
+// @Router /oidc/authorize [get]
+func (h *Handler) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := &models.OIDCAuthorizeRequest{
+		ResponseType:        query.Get("response_type"),
+		ClientID:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		Nonce:               query.Get("nonce"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+	}
+
+	location, err := h.service.LoginRedirect(req)
+	var oauthErr *Error
+	switch {
+	case errors.As(err, &oauthErr):
+		location = ErrorRedirect(req, oauthErr)
+	case err != nil:
+		h.writeError(w, err)
+		return
+	}
+	http.Redirect(w, r, location, http.StatusFound)
+}
+
+// handleCompleteAuthorize handles POST /api/v1/oidc/authorize
+// @Summary Complete an OpenID Connect login
+// @Description Called by the login page once the user is signed in, with the authorization request it was sent. Returns where to send the browser: the client's redirect URI with an authorization code, or with an error.
+// @Tags OpenID Connect
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.OIDCAuthorizeRequest true "Authorization request from the login page's query string"
+// @Success 200 {object} models.OIDCAuthorizeResponse
+// @Failure 400 {string} string "Unknown client or redirect_uri"
+// @Failure 403 {string} string "Impersonation token"
+// @Router /oidc/authorize [post]
+func (h *Handler) handleCompleteAuthorize(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+	if claims.Impersonator != "" {
+		// Impersonation is for support inside the store, never for signing into other tools
+		http.Error(w, "Impersonation tokens cannot sign in to other applications", http.StatusForbidden)
+		return
+	}
+
+	var req models.OIDCAuthorizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.Authorize(r.Context(), claims.UserID(), &req)
+	var oauthErr *Error
+	switch {
+	case errors.As(err, &oauthErr):
+		resp = &models.OIDCAuthorizeResponse{RedirectTo: ErrorRedirect(&req, oauthErr)}
+	case err != nil:
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleToken handles POST /api/v1/oidc/token
+// @Summary Exchange an authorization code
+// @Description Exchanges an authorization code for an ID token and an access token for the userinfo endpoint. Confidential clients authenticate with HTTP Basic or client_secret in the form; public clients send client_id and code_verifier.
+// @Tags OpenID Connect
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code"
+// @Param code formData string true "Authorization code"
+// @Param redirect_uri formData string true "The redirect_uri of the authorization request"
+// @Param client_id formData string false "Client ID, without HTTP Basic"
+// @Param client_secret formData string false "Client secret, without HTTP Basic"
+// @Param code_verifier formData string false "PKCE verifier"
+// @Success 200 {object} models.OIDCTokenResponse
+// @Failure 400 {object} models.OIDCError
+// @Failure 401 {object} models.OIDCError
+// @Router /oidc/token [post]
+func (h *Handler) handleToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, oauthError("invalid_request", "invalid form body"))
+		return
+	}
+
+	clientID, secret, basic := r.BasicAuth()
+	if basic {
+		// RFC 6749 2.3.1: both are form-encoded before Basic encoding
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	resp, err := h.service.Token(r.Context(), clientID, secret, r.PostForm)
+	var oauthErr *Error
+	switch {
+	case errors.As(err, &oauthErr) && oauthErr.Code == "invalid_client":
+		if basic {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oidc"`)
+		}
+		writeOAuthError(w, http.StatusUnauthorized, oauthErr)
+	case errors.As(err, &oauthErr):
+		writeOAuthError(w, http.StatusBadRequest, oauthErr)
+	case err != nil:
+		h.log.Error("OIDC token request failed", zap.Error(err))
+		writeOAuthError(w, http.StatusInternalServerError, oauthError("server_error", "internal server error"))
+	default:
+		response.JSON(w, http.StatusOK, resp)
+	}
+}
+
+// handleUserInfo handles GET and POST /api/v1/oidc/userinfo
+// @Summary OpenID Connect user info
+// @Description The signed-in user's claims for the granted scopes, read from the user store on every call
+// @Tags OpenID Connect
+// @Produce json
+// @Param Authorization header string true "Bearer access token from /oidc/token"
+// @Success 200 {object} models.OIDCUserInfo
+// @Failure 401 {string} string "Invalid access token"
+// @Router /oidc/userinfo [get]
+func (h *Handler) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="oidc"`)
+		http.Error(w, "Missing bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	info, err := h.service.UserInfo(r.Context(), token)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, info)
+}
+
+// writeError maps OIDC errors to 400/401/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidRedirect):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrInvalidToken):
+		w.Header().Set("WWW-Authenticate", `Bearer realm="oidc", error="invalid_token"`)
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+	default:
+		h.log.Error("OIDC request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// writeOAuthError answers the token endpoint's errors as RFC 6749 JSON
+func writeOAuthError(w http.ResponseWriter, status int, err *Error) {
+	response.JSON(w, status, models.OIDCError{Error: err.Code, Description: err.Description})
+}
@@ -0,0 +1,304 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository/memory"
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const (
+	issuer      = "https://shop.test/api/v1/oidc"
+	wikiURI     = "https://wiki.test/callback"
+	cliURI      = "http://127.0.0.1:8400/callback"
+	codeTTL     = time.Minute
+	wikiSecret  = "wiki-secret"
+	cliVerifier = "cli-verifier-0123456789-0123456789-0123456789"
+)
+
+// provider is the OIDC provider with a confidential client (wiki) and a public one (cli)
+type provider struct {
+	router  *mux.Router
+	service *OIDCService
+	tokens  *auth.TokenManager
+	clock   *clock.Fake
+	user    *models.User
+}
+
+func newProvider(t *testing.T) *provider {
+	t.Helper()
+	clk := clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	codes := cache.NewMemoryCache("oidc-test")
+	codes.UseClock(clk)
+
+	users := memory.NewUserRepository()
+	ada := &models.User{Email: "ada@example.com", FirstName: "Ada", LastName: "Lovelace", Role: models.RoleCustomer}
+	if err := users.CreateUser(context.Background(), ada); err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := NewOIDCService(users, codes, clk, config.OIDC{
+		Issuer:   issuer,
+		LoginURL: "https://shop.test/login",
+		CodeTTL:  codeTTL,
+		TokenTTL: time.Hour,
+		Clients: map[string]config.OIDCClient{
+			"wiki": {Secret: wikiSecret, RedirectURIs: []string{wikiURI}},
+			"cli":  {RedirectURIs: []string{cliURI}},
+		},
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := testutil.NewTokenManager(clk)
+	router := mux.NewRouter()
+	NewHandler(service, tokens, zap.NewNop()).RegisterRoutes(router)
+	return &provider{router: router, service: service, tokens: tokens, clock: clk, user: ada}
+}
+
+func wikiRequest() models.OIDCAuthorizeRequest {
+	return models.OIDCAuthorizeRequest{
+		ResponseType: "code", ClientID: "wiki", RedirectURI: wikiURI, Scope: "openid email", State: "s1", Nonce: "n1",
+	}
+}
+
+func cliRequest(verifier string) models.OIDCAuthorizeRequest {
+	sum := sha256.Sum256([]byte(verifier))
+	return models.OIDCAuthorizeRequest{
+		ResponseType: "code", ClientID: "cli", RedirectURI: cliURI, Scope: "openid",
+		CodeChallenge: base64.RawURLEncoding.EncodeToString(sum[:]), CodeChallengeMethod: "S256",
+	}
+}
+
+// authorize completes req as the login page does for the provider's user, and returns the code
+func (p *provider) authorize(t *testing.T, req models.OIDCAuthorizeRequest) string {
+	t.Helper()
+	rec := testutil.Serve(p.router, testutil.CustomerRequest(t, p.tokens, p.user.ID, "POST", "/oidc/authorize", req))
+	var resp models.OIDCAuthorizeResponse
+	testutil.DecodeJSON(t, rec, http.StatusOK, &resp)
+	location, err := url.Parse(resp.RedirectTo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redirect := location.Scheme + "://" + location.Host + location.Path; redirect != req.RedirectURI {
+		t.Fatalf("redirected to %s, want %s", redirect, req.RedirectURI)
+	}
+	if location.Query().Get("state") != req.State {
+		t.Fatalf("state = %q, want %q", location.Query().Get("state"), req.State)
+	}
+	code := location.Query().Get("code")
+	if code == "" {
+		t.Fatalf("no code in %s", resp.RedirectTo)
+	}
+	return code
+}
+
+// exchange posts form to the token endpoint, with the wiki's credentials over HTTP Basic
+// unless form names a client_id itself
+func (p *provider) exchange(t *testing.T, form url.Values) (int, models.OIDCTokenResponse, models.OIDCError) {
+	t.Helper()
+	form.Set("grant_type", "authorization_code")
+	req := testutil.NewRequest(t, "POST", "/oidc/token", form.Encode())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if form.Get("client_id") == "" {
+		req.SetBasicAuth("wiki", wikiSecret)
+	}
+	rec := testutil.Serve(p.router, req)
+
+	var tokens models.OIDCTokenResponse
+	var oauthErr models.OIDCError
+	if rec.Code == http.StatusOK {
+		testutil.DecodeJSON(t, rec, http.StatusOK, &tokens)
+	} else {
+		testutil.DecodeJSON(t, rec, rec.Code, &oauthErr)
+	}
+	return rec.Code, tokens, oauthErr
+}
+
+func (p *provider) userInfo(t *testing.T, token string) *http.Response {
+	t.Helper()
+	req := testutil.NewRequest(t, "GET", "/oidc/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return testutil.Serve(p.router, req).Result()
+}
+
+func assertInvalidGrant(t *testing.T, status int, oauthErr models.OIDCError, description string) {
+	t.Helper()
+	if status != http.StatusBadRequest || oauthErr.Error != "invalid_grant" {
+		t.Fatalf("exchange answered %d %q, want 400 invalid_grant", status, oauthErr.Error)
+	}
+	if !strings.Contains(oauthErr.Description, description) {
+		t.Fatalf("error_description = %q, want it to mention %q", oauthErr.Description, description)
+	}
+}
+
+func TestCodeExchange(t *testing.T) {
+	p := newProvider(t)
+	code := p.authorize(t, wikiRequest())
+
+	status, tokens, oauthErr := p.exchange(t, url.Values{"code": {code}, "redirect_uri": {wikiURI}})
+	if status != http.StatusOK {
+		t.Fatalf("exchange answered %d: %+v", status, oauthErr)
+	}
+	if tokens.TokenType != "Bearer" || tokens.Scope != "openid email" || tokens.ExpiresIn != 3600 {
+		t.Fatalf("token response = %+v", tokens)
+	}
+
+	id := &idClaims{}
+	_, err := jwt.ParseWithClaims(tokens.IDToken, id, func(*jwt.Token) (interface{}, error) {
+		return &p.service.key.PublicKey, nil
+	}, jwt.WithIssuer(issuer), jwt.WithAudience("wiki"), jwt.WithTimeFunc(p.clock.Now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Subject != p.user.ID || id.Nonce != "n1" || id.Email != "ada@example.com" || id.GivenName != "" {
+		t.Fatalf("ID token claims = %+v, want the user's email and nonce, no profile", id)
+	}
+
+	resp := p.userInfo(t, tokens.AccessToken)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("userinfo with the access token: status %d", resp.StatusCode)
+	}
+}
+
+func TestPKCE(t *testing.T) {
+	p := newProvider(t)
+
+	t.Run("matching verifier", func(t *testing.T) {
+		code := p.authorize(t, cliRequest(cliVerifier))
+		status, _, oauthErr := p.exchange(t, url.Values{
+			"code": {code}, "redirect_uri": {cliURI}, "client_id": {"cli"}, "code_verifier": {cliVerifier},
+		})
+		if status != http.StatusOK {
+			t.Fatalf("exchange answered %d: %+v", status, oauthErr)
+		}
+	})
+
+	t.Run("S256 mismatch", func(t *testing.T) {
+		code := p.authorize(t, cliRequest(cliVerifier))
+		status, _, oauthErr := p.exchange(t, url.Values{
+			"code": {code}, "redirect_uri": {cliURI}, "client_id": {"cli"}, "code_verifier": {cliVerifier + "-other"},
+		})
+		assertInvalidGrant(t, status, oauthErr, "code_verifier")
+	})
+
+	t.Run("missing verifier", func(t *testing.T) {
+		code := p.authorize(t, cliRequest(cliVerifier))
+		status, _, oauthErr := p.exchange(t, url.Values{"code": {code}, "redirect_uri": {cliURI}, "client_id": {"cli"}})
+		assertInvalidGrant(t, status, oauthErr, "code_verifier")
+	})
+
+	t.Run("plain method refused", func(t *testing.T) {
+		req := cliRequest(cliVerifier)
+		req.CodeChallengeMethod = "plain"
+		rec := testutil.Serve(p.router, testutil.CustomerRequest(t, p.tokens, p.user.ID, "POST", "/oidc/authorize", req))
+		var resp models.OIDCAuthorizeResponse
+		testutil.DecodeJSON(t, rec, http.StatusOK, &resp)
+		if !strings.Contains(resp.RedirectTo, "error=invalid_request") {
+			t.Fatalf("redirected to %s, want an invalid_request error", resp.RedirectTo)
+		}
+	})
+}
+
+func TestCodeIsSingleUse(t *testing.T) {
+	p := newProvider(t)
+	code := p.authorize(t, wikiRequest())
+	form := url.Values{"code": {code}, "redirect_uri": {wikiURI}}
+
+	if status, _, oauthErr := p.exchange(t, form); status != http.StatusOK {
+		t.Fatalf("first exchange answered %d: %+v", status, oauthErr)
+	}
+	status, _, oauthErr := p.exchange(t, form)
+	assertInvalidGrant(t, status, oauthErr, "already used")
+}
+
+func TestRedirectURIMustBeRegistered(t *testing.T) {
+	p := newProvider(t)
+	req := wikiRequest()
+	req.RedirectURI = "https://attacker.test/callback"
+
+	t.Run("login page", func(t *testing.T) {
+		rec := testutil.Serve(p.router, testutil.CustomerRequest(t, p.tokens, p.user.ID, "POST", "/oidc/authorize", req))
+		testutil.AssertError(t, rec, http.StatusBadRequest, ErrInvalidRedirect.Error())
+	})
+
+	t.Run("authorization endpoint", func(t *testing.T) {
+		query := url.Values{"response_type": {"code"}, "client_id": {"wiki"}, "redirect_uri": {req.RedirectURI}, "scope": {"openid"}}
+		rec := testutil.Serve(p.router, testutil.NewRequest(t, "GET", "/oidc/authorize?"+query.Encode(), nil))
+		if rec.Code != http.StatusBadRequest || rec.Header().Get("Location") != "" {
+			t.Fatalf("status %d, Location %q: want 400 without a redirect", rec.Code, rec.Header().Get("Location"))
+		}
+	})
+
+	t.Run("another client's URI", func(t *testing.T) {
+		req := wikiRequest()
+		req.RedirectURI = cliURI
+		rec := testutil.Serve(p.router, testutil.CustomerRequest(t, p.tokens, p.user.ID, "POST", "/oidc/authorize", req))
+		testutil.AssertError(t, rec, http.StatusBadRequest, ErrInvalidRedirect.Error())
+	})
+
+	t.Run("exchanged for another URI", func(t *testing.T) {
+		code := p.authorize(t, wikiRequest())
+		status, _, oauthErr := p.exchange(t, url.Values{"code": {code}, "redirect_uri": {wikiURI + "/other"}})
+		assertInvalidGrant(t, status, oauthErr, "redirect_uri")
+	})
+}
+
+func TestCodeExpires(t *testing.T) {
+	p := newProvider(t)
+	code := p.authorize(t, wikiRequest())
+
+	p.clock.Advance(codeTTL + time.Second)
+	status, _, oauthErr := p.exchange(t, url.Values{"code": {code}, "redirect_uri": {wikiURI}})
+	assertInvalidGrant(t, status, oauthErr, "expired")
+}
+
+func TestUserInfoTakesAccessTokensOnly(t *testing.T) {
+	p := newProvider(t)
+	code := p.authorize(t, wikiRequest())
+	status, tokens, oauthErr := p.exchange(t, url.Values{"code": {code}, "redirect_uri": {wikiURI}})
+	if status != http.StatusOK {
+		t.Fatalf("exchange answered %d: %+v", status, oauthErr)
+	}
+	signIn, _, err := p.tokens.Issue(p.user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{
+		"ID token":          tokens.IDToken,
+		"API sign-in token": signIn,
+	} {
+		t.Run(name, func(t *testing.T) {
+			resp := p.userInfo(t, token)
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Fatalf("status %d, want 401", resp.StatusCode)
+			}
+			if !strings.Contains(resp.Header.Get("WWW-Authenticate"), `error="invalid_token"`) {
+				t.Fatalf("WWW-Authenticate = %q", resp.Header.Get("WWW-Authenticate"))
+			}
+		})
+	}
+
+	t.Run("expired access token", func(t *testing.T) {
+		p.clock.Advance(time.Hour + time.Second)
+		if resp := p.userInfo(t, tokens.AccessToken); resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("status %d, want 401", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,519 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// Scopes a client can ask for; openid is required
+const (
+	ScopeOpenID  = "openid"
+	ScopeEmail   = "email"
+	ScopeProfile = "profile"
+)
+
+// accessTokenType marks access tokens in their typ header (RFC 9068) so an ID token can't
+// stand in for one at the userinfo endpoint
+const accessTokenType = "at+jwt"
+
+var supportedScopes = []string{ScopeOpenID, ScopeEmail, ScopeProfile}
+
+var (
+	// ErrInvalidRedirect is returned when an authorization request names an unknown client or a
+	// redirect URI the client didn't register; the error is shown, never sent to the URI
+	ErrInvalidRedirect = errors.New("unknown client or redirect_uri")
+	// ErrInvalidToken is returned by UserInfo for bad, expired or foreign access tokens
+	ErrInvalidToken = errors.New("invalid access token")
+)
+
+// Error is an OAuth 2.0 error answered to the client, by redirect or in the token response
+type Error struct {
+	Code        string // invalid_request, invalid_client, invalid_grant...
+	Description string
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func oauthError(code, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
+
+// grant is what an authorization code stands for, kept in the cache until it is exchanged
+type grant struct {
+	ClientID    string `json:"client_id"`
+	RedirectURI string `json:"redirect_uri"`
+	UserID      string `json:"user_id"`
+	Scope       string `json:"scope"`
+	Nonce       string `json:"nonce,omitempty"`
+	Challenge   string `json:"challenge,omitempty"`
+	AuthTime    int64  `json:"auth_time"`
+}
+
+// accessClaims is the payload of access tokens; they are only good for the userinfo endpoint
+type accessClaims struct {
+	Scope    string `json:"scope"`
+	ClientID string `json:"client_id"`
+	jwt.RegisteredClaims
+}
+
+// idClaims is the payload of ID tokens: the user's claims for the granted scopes
+type idClaims struct {
+	Nonce             string `json:"nonce,omitempty"`
+	AuthTime          int64  `json:"auth_time"`
+	Email             string `json:"email,omitempty"`
+	Name              string `json:"name,omitempty"`
+	GivenName         string `json:"given_name,omitempty"`
+	FamilyName        string `json:"family_name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Locale            string `json:"locale,omitempty"`
+	Role              string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// OIDCService is a minimal OpenID Connect provider over the user store, so internal tools sign
+// in with store accounts without a Keycloak realm. It offers the authorization code flow only:
+// the browser is sent to the configured login page, which signs the user in as the storefront
+// does and completes the authorization with their token. Tokens are RS256-signed with a key
+// published at the JWKS endpoint; the API's own HS256 tokens are never handed to a client.
+type OIDCService struct {
+	users repository.UserStore
+	cache cache.Cache
+	clock clock.Clock
+	cfg   config.OIDC
+	key   *rsa.PrivateKey
+	kid   string
+	log   *zap.Logger
+}
+
+// NewOIDCService loads cfg.SigningKey, or generates a key when none is set
+func NewOIDCService(users repository.UserStore, appCache cache.Cache, clk clock.Clock, cfg config.OIDC,
+	log *zap.Logger) (*OIDCService, error) {
+	key, err := signingKey(cfg.SigningKey)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SigningKey == "" && len(cfg.Clients) > 0 {
+		log.Warn("OIDC_SIGNING_KEY is not set: tokens are signed with a key generated for this process " +
+			"and stop verifying after a restart or on other instances")
+	}
+	return &OIDCService{
+		users: users,
+		cache: appCache,
+		clock: clk,
+		cfg:   cfg,
+		key:   key,
+		kid:   thumbprint(&key.PublicKey),
+		log:   log,
+	}, nil
+}
+
+// Discovery returns the provider metadata
+func (s *OIDCService) Discovery() *models.OIDCDiscovery {
+	return &models.OIDCDiscovery{
+		Issuer:                            s.cfg.Issuer,
+		AuthorizationEndpoint:             s.cfg.Issuer + "/authorize",
+		TokenEndpoint:                     s.cfg.Issuer + "/token",
+		UserinfoEndpoint:                  s.cfg.Issuer + "/userinfo",
+		JWKSURI:                           s.cfg.Issuer + "/jwks",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{jwt.SigningMethodRS256.Alg()},
+		ScopesSupported:                   supportedScopes,
+		ClaimsSupported:                   []string{"sub", "email", "name", "given_name", "family_name", "preferred_username", "locale", "role"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+	}
+}
+
+// JWKS returns the public key tokens are signed with
+func (s *OIDCService) JWKS() *models.JSONWebKeySet {
+	pub := &s.key.PublicKey
+	return &models.JSONWebKeySet{Keys: []models.JSONWebKey{{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: jwt.SigningMethodRS256.Alg(),
+		KeyID:     s.kid,
+		Modulus:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}}
+}
+
+// LoginRedirect checks an authorization request and returns the login page URL carrying it
+// Returns: ErrInvalidRedirect, or an *Error to send back with ErrorRedirect
+func (s *OIDCService) LoginRedirect(req *models.OIDCAuthorizeRequest) (string, error) {
+	if err := s.validate(req); err != nil {
+		return "", err
+	}
+	if s.cfg.LoginURL == "" {
+		return "", oauthError("server_error", "OIDC_LOGIN_URL is not configured")
+	}
+	return withQuery(s.cfg.LoginURL, authorizeQuery(req)), nil
+}
+
+// Authorize issues an authorization code for userID, who signed in on the login page
+// Returns: the client's redirect URI with the code and state; ErrInvalidRedirect or an *Error otherwise
+func (s *OIDCService) Authorize(ctx context.Context, userID string, req *models.OIDCAuthorizeRequest) (*models.OIDCAuthorizeResponse, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+
+	code, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	err = cache.SetJSON(ctx, s.cache, codeKey(code), grant{
+		ClientID:    req.ClientID,
+		RedirectURI: req.RedirectURI,
+		UserID:      userID,
+		Scope:       normalizeScope(req.Scope),
+		Nonce:       req.Nonce,
+		Challenge:   req.CodeChallenge,
+		AuthTime:    s.clock.Now().Unix(),
+	}, s.cfg.CodeTTL)
+	if err != nil {
+		return nil, err
+	}
+	s.log.Info("OIDC authorization granted", zap.String("client_id", req.ClientID), zap.String("user_id", userID))
+
+	query := url.Values{"code": {code}}
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	return &models.OIDCAuthorizeResponse{RedirectTo: withQuery(req.RedirectURI, query)}, nil
+}
+
+// ErrorRedirect returns the client's redirect URI carrying err and the request's state
+func ErrorRedirect(req *models.OIDCAuthorizeRequest, err *Error) string {
+	query := url.Values{"error": {err.Code}, "error_description": {err.Description}}
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	return withQuery(req.RedirectURI, query)
+}
+
+// Token exchanges an authorization code for an ID token and an access token
+// clientID and secret come from HTTP Basic authentication or the form; secret is empty for public clients
+// Returns: an *Error for every problem the client caused
+func (s *OIDCService) Token(ctx context.Context, clientID, secret string, form url.Values) (*models.OIDCTokenResponse, error) {
+	client, ok := s.cfg.Clients[clientID]
+	if !ok || subtle.ConstantTimeCompare([]byte(client.Secret), []byte(secret)) != 1 {
+		return nil, oauthError("invalid_client", "unknown client or wrong secret")
+	}
+	if grantType := form.Get("grant_type"); grantType != "authorization_code" {
+		return nil, oauthError("unsupported_grant_type", "only authorization_code is supported")
+	}
+	code := form.Get("code")
+	if code == "" {
+		return nil, oauthError("invalid_request", "code is required")
+	}
+
+	g, err := s.redeem(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if g.ClientID != clientID || g.RedirectURI != form.Get("redirect_uri") {
+		return nil, oauthError("invalid_grant", "code was issued to another client or redirect_uri")
+	}
+	if g.Challenge != "" && !verifyChallenge(g.Challenge, form.Get("code_verifier")) {
+		return nil, oauthError("invalid_grant", "code_verifier does not match the code_challenge")
+	}
+
+	user, err := s.users.GetUserByID(ctx, g.UserID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, oauthError("invalid_grant", "the account no longer exists")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	expiresAt := now.Add(s.cfg.TokenTTL)
+	registered := jwt.RegisteredClaims{
+		Issuer:    s.cfg.Issuer,
+		Subject:   user.ID,
+		Audience:  jwt.ClaimStrings{clientID},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	access, err := s.sign(accessTokenType, &accessClaims{Scope: g.Scope, ClientID: clientID, RegisteredClaims: registered})
+	if err != nil {
+		return nil, err
+	}
+	info := userInfo(user, g.Scope)
+	id, err := s.sign("JWT", &idClaims{
+		Nonce:             g.Nonce,
+		AuthTime:          g.AuthTime,
+		Email:             info.Email,
+		Name:              info.Name,
+		GivenName:         info.GivenName,
+		FamilyName:        info.FamilyName,
+		PreferredUsername: info.PreferredUsername,
+		Locale:            info.Locale,
+		Role:              info.Role,
+		RegisteredClaims:  registered,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("OIDC tokens issued", zap.String("client_id", clientID), zap.String("user_id", user.ID))
+	return &models.OIDCTokenResponse{
+		AccessToken: access,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.cfg.TokenTTL.Seconds()),
+		IDToken:     id,
+		Scope:       g.Scope,
+	}, nil
+}
+
+// UserInfo returns the claims of the access token's user, as of now
+// Returns: ErrInvalidToken for bad, expired or foreign tokens and for deleted accounts
+func (s *OIDCService) UserInfo(ctx context.Context, token string) (*models.OIDCUserInfo, error) {
+	claims := &accessClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return &s.key.PublicKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(s.cfg.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.clock.Now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if typ, _ := parsed.Header["typ"].(string); typ != accessTokenType {
+		return nil, fmt.Errorf("%w: not an access token", ErrInvalidToken)
+	}
+	if _, ok := s.cfg.Clients[claims.ClientID]; !ok {
+		return nil, fmt.Errorf("%w: client %q is no longer configured", ErrInvalidToken, claims.ClientID)
+	}
+
+	user, err := s.users.GetUserByID(ctx, claims.Subject)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, fmt.Errorf("%w: the account no longer exists", ErrInvalidToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+	info := userInfo(user, claims.Scope)
+	return &info, nil
+}
+
+// validate checks an authorization request against the configured clients
+func (s *OIDCService) validate(req *models.OIDCAuthorizeRequest) error {
+	client, ok := s.cfg.Clients[req.ClientID]
+	if !ok || req.RedirectURI == "" || !slices.Contains(client.RedirectURIs, req.RedirectURI) {
+		return ErrInvalidRedirect
+	}
+	if req.ResponseType != "code" {
+		return oauthError("unsupported_response_type", "only response_type=code is supported")
+	}
+	scopes := strings.Fields(req.Scope)
+	if !slices.Contains(scopes, ScopeOpenID) {
+		return oauthError("invalid_scope", "scope must include openid")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(supportedScopes, scope) {
+			return oauthError("invalid_scope", fmt.Sprintf("unsupported scope %q", scope))
+		}
+	}
+	switch {
+	case req.CodeChallenge == "" && client.Secret == "":
+		return oauthError("invalid_request", "public clients must send a code_challenge")
+	case req.CodeChallenge != "" && req.CodeChallengeMethod != "S256":
+		return oauthError("invalid_request", "code_challenge_method must be S256")
+	}
+	return nil
+}
+
+// redeem returns the grant of code and forgets it; a code is good for one exchange only
+func (s *OIDCService) redeem(ctx context.Context, code string) (*grant, error) {
+	key := codeKey(code)
+	count, err := s.cache.Incr(ctx, key+":used", s.cfg.CodeTTL)
+	if err != nil {
+		return nil, err
+	}
+	if count > 1 {
+		return nil, oauthError("invalid_grant", "code was already used")
+	}
+
+	var g grant
+	err = cache.GetJSON(ctx, s.cache, key, &g)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return nil, oauthError("invalid_grant", "code is invalid or expired")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.Delete(ctx, key); err != nil {
+		s.log.Warn("Failed to delete used OIDC code", zap.Error(err))
+	}
+	return &g, nil
+}
+
+func (s *OIDCService) sign(typ string, claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["typ"] = typ
+	token.Header["kid"] = s.kid
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// userInfo returns the claims of user that scope grants
+func userInfo(user *models.User, scope string) models.OIDCUserInfo {
+	scopes := strings.Fields(scope)
+	info := models.OIDCUserInfo{Subject: user.ID, Role: user.Role}
+	if slices.Contains(scopes, ScopeEmail) {
+		info.Email = user.Email
+	}
+	if slices.Contains(scopes, ScopeProfile) {
+		info.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		info.GivenName = user.FirstName
+		info.FamilyName = user.LastName
+		info.Locale = user.Locale
+		if user.Username != nil {
+			info.PreferredUsername = *user.Username
+		}
+	}
+	return info
+}
+
+// signingKey parses a PEM RSA private key (PKCS#1 or PKCS#8), given inline or as a file path
+// An empty value generates a 2048-bit key
+func signingKey(value string) (*rsa.PrivateKey, error) {
+	if value == "" {
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+	data := []byte(value)
+	if !strings.Contains(value, "-----BEGIN") {
+		var err error
+		if data, err = os.ReadFile(value); err != nil {
+			return nil, fmt.Errorf("read OIDC_SIGNING_KEY: %w", err)
+		}
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("OIDC_SIGNING_KEY is not a PEM key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse OIDC_SIGNING_KEY: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("OIDC_SIGNING_KEY must be an RSA key")
+	}
+	return key, nil
+}
+
+// thumbprint is the RFC 7638 thumbprint of key, used as its kid
+func thumbprint(key *rsa.PublicKey) string {
+	// Members in lexicographic order, no whitespace, as the RFC requires
+	data, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		Kty: "RSA",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+	})
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// verifyChallenge checks a PKCE code_verifier against its S256 code_challenge (RFC 7636)
+func verifyChallenge(challenge, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
+
+// codeKey is the cache key of a code; only its hash is stored
+func codeKey(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return "oidc:code:" + hex.EncodeToString(sum[:])
+}
+
+// normalizeScope de-duplicates scope, keeping the client's order
+func normalizeScope(scope string) string {
+	var scopes []string
+	for _, s := range strings.Fields(scope) {
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return strings.Join(scopes, " ")
+}
+
+func authorizeQuery(req *models.OIDCAuthorizeRequest) url.Values {
+	query := url.Values{
+		"response_type": {req.ResponseType},
+		"client_id":     {req.ClientID},
+		"redirect_uri":  {req.RedirectURI},
+		"scope":         {req.Scope},
+	}
+	for name, value := range map[string]string{
+		"state":                 req.State,
+		"nonce":                 req.Nonce,
+		"code_challenge":        req.CodeChallenge,
+		"code_challenge_method": req.CodeChallengeMethod,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	return query
+}
+
+// withQuery appends query to base, which may already have one
+func withQuery(base string, query url.Values) string {
+	separator := "?"
+	if strings.Contains(base, "?") {
+		separator = "&"
+	}
+	return base + separator + query.Encode()
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,202 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/fiscal"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// JobFiscalReceipt issues a paid order's fiscal receipt
+const JobFiscalReceipt = "order.fiscal_receipt"
+
+// maxFiscalError caps the provider error kept on the order
+const maxFiscalError = 512
+
+var (
+	// ErrFiscalDisabled is returned when retrying a receipt while FISCAL_PROVIDER=none
+	ErrFiscalDisabled = errors.New("fiscal receipts are not enabled")
+	// ErrReceiptIssued is returned when retrying an order that already has its receipt
+	ErrReceiptIssued = errors.New("fiscal receipt already issued")
+)
+
+// receiptJob is the payload of fiscal receipt jobs
+type receiptJob struct {
+	OrderID string    `json:"order_id"`
+	PaidAt  time.Time `json:"paid_at"`
+}
+
+// FiscalService issues fiscal receipts for paid orders through the fiscal provider
+// payment.captured enqueues a job per order; the job queue retries failed attempts with
+// backoff, including while order.placed hasn't been recorded yet. Rejected sales and
+// orders out of attempts are marked failed for an admin to retry
+type FiscalService struct {
+	repo     *repository.OrderRepository
+	products repository.ProductStore
+	provider fiscal.Provider
+	jobs     *jobs.Processor
+	clock    clock.Clock
+	log      *zap.Logger
+}
+
+func NewFiscalService(repo *repository.OrderRepository, products repository.ProductStore, provider fiscal.Provider,
+	processor *jobs.Processor, clk clock.Clock, log *zap.Logger) *FiscalService {
+	return &FiscalService{
+		repo:     repo,
+		products: products,
+		provider: provider,
+		jobs:     processor,
+		clock:    clk,
+		log:      log,
+	}
+}
+
+// HandlePaymentCaptured queues the paid order's fiscal receipt
+func (s *FiscalService) HandlePaymentCaptured(ctx context.Context, event events.Event) error {
+	var payload events.PaymentCaptured
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	if payload.OrderID == "" {
+		return nil
+	}
+	_, err := s.jobs.Enqueue(ctx, JobFiscalReceipt, receiptJob{OrderID: payload.OrderID, PaidAt: event.OccurredAt})
+	return err
+}
+
+// Retry queues another attempt at an order's fiscal receipt, e.g. after fixing a rejected sale (admin only)
+func (s *FiscalService) Retry(ctx context.Context, orderID string) (*models.Order, error) {
+	if s.provider == nil {
+		return nil, ErrFiscalDisabled
+	}
+	order, err := s.repo.Get(ctx, orderID, true)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case order.FiscalStatus == models.FiscalIssued:
+		return nil, ErrReceiptIssued
+	case order.Status == models.OrderStatusPlaced:
+		return nil, fmt.Errorf("%w: the order isn't paid", ErrInvalidOrderRequest)
+	}
+
+	if _, err := s.repo.SetFiscalStatus(ctx, orderID, models.FiscalPending, s.provider.Name(), ""); err != nil {
+		return nil, err
+	}
+	if _, err := s.jobs.Enqueue(ctx, JobFiscalReceipt, receiptJob{OrderID: orderID}); err != nil {
+		return nil, err
+	}
+	s.log.Info("Fiscal receipt retry queued", zap.String("order_id", orderID))
+	return s.repo.Get(ctx, orderID, true)
+}
+
+func (s *FiscalService) handleReceiptJob(ctx context.Context, job *models.Job) error {
+	var payload receiptJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	lastAttempt := job.Attempts >= job.MaxAttempts
+
+	order, err := s.repo.Get(ctx, payload.OrderID, false)
+	if errors.Is(err, repository.ErrOrderNotFound) && lastAttempt {
+		s.log.Error("No order record for a paid order; its fiscal receipt was not issued", zap.String("order_id", payload.OrderID))
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if order.FiscalStatus == models.FiscalIssued {
+		return nil // a redelivered payment.captured or an earlier attempt
+	}
+
+	sale, err := s.sale(ctx, order, payload.PaidAt)
+	if err != nil {
+		return err
+	}
+	receipt, err := s.provider.Issue(ctx, *sale)
+	switch {
+	case errors.Is(err, fiscal.ErrRejected):
+		s.log.Warn("Fiscal receipt rejected", zap.String("order_id", order.ID), zap.Error(err))
+		_, err = s.repo.SetFiscalStatus(ctx, order.ID, models.FiscalFailed, s.provider.Name(), truncate(err.Error()))
+		return err
+	case err != nil && lastAttempt:
+		s.log.Error("Fiscal receipt failed after its last attempt; retry it from the admin API",
+			zap.String("order_id", order.ID), zap.Error(err))
+		if _, markErr := s.repo.SetFiscalStatus(ctx, order.ID, models.FiscalFailed, s.provider.Name(), truncate(err.Error())); markErr != nil {
+			return markErr
+		}
+		return err
+	case err != nil:
+		if _, markErr := s.repo.SetFiscalStatus(ctx, order.ID, models.FiscalPending, s.provider.Name(), truncate(err.Error())); markErr != nil {
+			return markErr
+		}
+		return err
+	}
+
+	if _, err := s.repo.SetFiscalReceipt(ctx, order.ID, s.provider.Name(), receipt.Number, receipt.Signature, receipt.IssuedAt); err != nil {
+		return err
+	}
+	s.log.Info("Fiscal receipt issued", zap.String("order_id", order.ID), zap.String("receipt_number", receipt.Number))
+	return nil
+}
+
+// sale describes order to the provider; lines are named from the catalog as it is now
+// Retries don't know when the order was paid and report it as paid now
+func (s *FiscalService) sale(ctx context.Context, order *models.Order, paidAt time.Time) (*fiscal.Sale, error) {
+	ids := make([]string, 0, len(order.Items))
+	for _, item := range order.Items {
+		ids = append(ids, item.ProductID)
+	}
+	products, err := s.products.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	if paidAt.IsZero() {
+		paidAt = s.clock.Now()
+	}
+	invoice := order.OrderNumber
+	if invoice == "" {
+		invoice = order.ID
+	}
+	sale := &fiscal.Sale{
+		OrderID:       order.ID,
+		InvoiceNumber: invoice,
+		Currency:      order.Currency,
+		Total:         order.Total,
+		TaxTreatment:  order.TaxTreatment,
+		BuyerTIN:      order.VATID,
+		Lines:         make([]fiscal.Line, 0, len(order.Items)),
+		PaidAt:        paidAt,
+	}
+	for _, item := range order.Items {
+		line := fiscal.Line{Code: item.ProductID, Name: item.ProductID, Quantity: item.Quantity, UnitPrice: item.UnitPrice}
+		if product, ok := byID[item.ProductID]; ok {
+			line.Name = product.Name
+			if product.SKU != "" {
+				line.Code = product.SKU
+			}
+		}
+		sale.Lines = append(sale.Lines, line)
+	}
+	return sale, nil
+}
+
+func truncate(message string) string {
+	if len(message) > maxFiscalError {
+		return message[:maxFiscalError]
+	}
+	return message
+}
@@ -0,0 +1,70 @@
+package order
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type FiscalHandler struct {
+	service *FiscalService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewFiscalHandler(service *FiscalService, tokens *auth.TokenManager, log *zap.Logger) *FiscalHandler {
+	return &FiscalHandler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers the admin fiscal receipt retry; receipts are otherwise issued after payment
+func (h *FiscalHandler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.ScopeByMethod("orders"), auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/orders/{id}/fiscal-receipt", h.handleRetry).Methods("POST")
+}
+
+// handleRetry handles POST /api/v1/admin/orders/{id}/fiscal-receipt
+// @Summary Retry a fiscal receipt
+// @Description Queues another attempt at the order's fiscal receipt, e.g. after a rejection or once the tax authority is back. The order's fiscal_status moves to pending.
+// @Tags Orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 202 {object} models.Order
+// @Failure 400 {string} string "The order isn't paid"
+// @Failure 404 {string} string "Order not found"
+// @Failure 409 {string} string "Fiscal receipt already issued, or fiscal receipts are not enabled"
+// @Router /admin/orders/{id}/fiscal-receipt [post]
+func (h *FiscalHandler) handleRetry(w http.ResponseWriter, r *http.Request) {
+	order, err := h.service.Retry(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusAccepted, order)
+}
+
+// writeError maps fiscal receipt errors to 400/404/409/500
+func (h *FiscalHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidOrderRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrOrderNotFound):
+		http.Error(w, "Order not found", http.StatusNotFound)
+	case errors.Is(err, ErrReceiptIssued), errors.Is(err, ErrFiscalDisabled):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.log.Error("Fiscal receipt request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
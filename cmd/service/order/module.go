@@ -0,0 +1,70 @@
+package order
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Module provides order endpoints: order records with customer notes and internal comments,
+// and the real-time status stream, both fed by order events
+// It owns the order number counters handed out through deps.OrderNumbers, and issues fiscal
+// receipts for paid orders when FISCAL_PROVIDER is set. With ORDER_EVENT_SOURCING every change
+// to an order is also appended to its event stream (order_events), see repository.OrderRepository.WithEventStream
+type Module struct {
+	handler       *Handler
+	fiscalHandler *FiscalHandler
+}
+
+func NewModule(deps module.Deps) *Module {
+	repo := repository.NewOrderRepository(deps.DB, deps.Log)
+	if deps.Config.Orders.EventSourcing {
+		repo = repo.WithEventStream(deps.Config.Orders.SnapshotEvery)
+	}
+	service := NewOrderService(repo, repository.NewUserRepository(deps.DB, deps.Log), deps.Config.Orders.EventSourcing,
+		deps.Clock, deps.Log)
+	stream := NewStatusStream(deps.Events, deps.Log)
+	receipts := NewFiscalService(repo, repository.NewProductRepository(deps.DB, deps.Log), deps.Fiscal, deps.Jobs, deps.Clock, deps.Log)
+
+	for eventType := range orderStatusByEvent {
+		handler := service.HandleStatusEvent
+		if eventType == events.TypeOrderPlaced {
+			handler = service.HandleOrderPlaced
+		}
+		if err := deps.Events.Subscribe(eventType, "orders", handler); err != nil {
+			deps.Log.Error("Failed to subscribe orders to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+
+	if deps.Fiscal != nil {
+		deps.Jobs.Register(JobFiscalReceipt, receipts.handleReceiptJob)
+		if err := deps.Events.Subscribe(events.TypePaymentCaptured, "order-fiscal", receipts.HandlePaymentCaptured); err != nil {
+			deps.Log.Error("Failed to subscribe fiscal receipts to event", zap.String("type", events.TypePaymentCaptured), zap.Error(err))
+		}
+	}
+
+	return &Module{
+		handler:       NewHandler(service, stream, deps.Tokens, deps.Links, deps.Log),
+		fiscalHandler: NewFiscalHandler(receipts, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.OrderSequence{}, &models.Order{}, &models.OrderNote{}),
+		migrations.AutoMigrate(&models.OrderEvent{}, &models.OrderSnapshot{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+	m.fiscalHandler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
@@ -0,0 +1,389 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/export"
+	"github.com/Jason-Omondi/ecomgo/internal/links"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/realtime"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/Jason-Omondi/ecomgo/internal/timezone"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// heartbeatInterval keeps idle streams alive through proxies and load balancers
+const heartbeatInterval = 15 * time.Second
+
+type Handler struct {
+	service *OrderService
+	stream  *StatusStream
+	tokens  *auth.TokenManager
+	links   *links.Builder
+	log     *zap.Logger
+}
+
+func NewHandler(service *OrderService, stream *StatusStream, tokens *auth.TokenManager, resourceLinks *links.Builder, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		stream:  stream,
+		tokens:  tokens,
+		links:   resourceLinks,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers order routes
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	stream := router.PathPrefix("/orders").Subrouter()
+	stream.Use(auth.ScopeByMethod("orders"), auth.AuthenticateStream(h.tokens))
+	stream.HandleFunc("/{id}/events", h.handleEvents).Methods("GET").Name(links.RouteOrderEvents)
+
+	orders := router.PathPrefix("/orders").Subrouter()
+	orders.Use(auth.ScopeByMethod("orders"), auth.Authenticate(h.tokens))
+	orders.HandleFunc("", h.handleListMine).Methods("GET")
+	orders.HandleFunc("/{id}", h.handleGet).Methods("GET").Name(links.RouteOrder)
+	orders.HandleFunc("/{id}/notes", h.handleAddNote).Methods("POST")
+
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.ScopeByMethod("orders"), auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/orders", h.handleAdminList).Methods("GET")
+	admin.HandleFunc("/orders/export", h.handleExport).Methods("GET")
+	admin.HandleFunc("/orders/{id}", h.handleAdminGet).Methods("GET")
+	admin.HandleFunc("/orders/{id}/history", h.handleAdminHistory).Methods("GET")
+	admin.HandleFunc("/orders/{id}/notes", h.handleAdminAddNote).Methods("POST")
+}
+
+// handleListMine handles GET /api/v1/orders
+// @Summary List my orders
+// @Description The caller's orders, newest first, without notes
+// @Tags Orders
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "placed, paid, shipped, delivered or refunded"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.OrderListResponse
+// @Failure 400 {string} string "Invalid request"
+// @Router /orders [get]
+func (h *Handler) handleListMine(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	filter := repository.OrderFilter{
+		UserID: auth.ClaimsFromContext(r.Context()).UserID(),
+		Status: r.URL.Query().Get("status"),
+	}
+	h.list(w, r, filter, limit, offset)
+}
+
+// handleGet handles GET /api/v1/orders/{id}
+// @Summary Get my order
+// @Description One of the caller's orders with its notes; internal comments are left out
+// @Tags Orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} models.Order
+// @Failure 404 {string} string "Order not found"
+// @Router /orders/{id} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	h.get(w, r, false)
+}
+
+// handleAddNote handles POST /api/v1/orders/{id}/notes
+// @Summary Add a note to my order
+// @Description Adds a note to one of the caller's orders; customer notes are always visible to the customer
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param request body models.OrderNoteRequest true "Note (internal is ignored)"
+// @Success 201 {object} models.OrderNote
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Order not found"
+// @Router /orders/{id}/notes [post]
+func (h *Handler) handleAddNote(w http.ResponseWriter, r *http.Request) {
+	h.addNote(w, r, false)
+}
+
+// handleAdminList handles GET /api/v1/admin/orders
+// @Summary List orders
+// @Description Every order, newest first, without notes
+// @Tags Orders
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query string false "Only this customer's orders"
+// @Param status query string false "placed, paid, shipped, delivered or refunded"
+// @Param tax_treatment query string false "inclusive, exclusive or exempt; exempt lists the orders to report as tax-exempt"
+// @Param fiscal_status query string false "pending, issued or failed; failed lists the fiscal receipts to retry"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.OrderListResponse
+// @Failure 400 {string} string "Invalid request"
+// @Router /admin/orders [get]
+func (h *Handler) handleAdminList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	query := r.URL.Query()
+	filter := repository.OrderFilter{
+		UserID:       query.Get("user_id"),
+		Status:       query.Get("status"),
+		TaxTreatment: query.Get("tax_treatment"),
+		FiscalStatus: query.Get("fiscal_status"),
+	}
+	h.list(w, r, filter, limit, offset)
+}
+
+// orderColumns are the CSV columns of an order export; totals are in minor units, times in loc
+func orderColumns(loc *time.Location) []export.Column[models.Order] {
+	return []export.Column[models.Order]{
+		{Name: "id", Value: func(o models.Order) string { return o.ID }},
+		{Name: "order_number", Value: func(o models.Order) string { return o.OrderNumber }},
+		{Name: "user_id", Value: func(o models.Order) string { return o.UserID }},
+		{Name: "status", Value: func(o models.Order) string { return o.Status }},
+		{Name: "total", Value: func(o models.Order) string { return export.Int(o.Total) }},
+		{Name: "currency", Value: func(o models.Order) string { return o.Currency }},
+		{Name: "channel", Value: func(o models.Order) string { return o.Channel }},
+		{Name: "lines", Value: func(o models.Order) string { return export.Int(len(o.Items)) }},
+		{Name: "tax_treatment", Value: func(o models.Order) string { return o.TaxTreatment }},
+		{Name: "vat_id", Value: func(o models.Order) string { return o.VATID }},
+		{Name: "fiscal_status", Value: func(o models.Order) string { return o.FiscalStatus }},
+		{Name: "fiscal_receipt_number", Value: func(o models.Order) string { return o.FiscalReceiptNumber }},
+		{Name: "placed_at", Value: func(o models.Order) string { return export.TimeIn(o.PlacedAt, loc) }},
+		{Name: "updated_at", Value: func(o models.Order) string { return export.TimeIn(o.UpdatedAt, loc) }},
+	}
+}
+
+// handleExport handles GET /api/v1/admin/orders/export
+// @Summary Export orders
+// @Description Streams every order matching the filters, without notes, as CSV or NDJSON (chunked). Stops when the client disconnects.
+// @Tags Orders
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Security BearerAuth
+// @Param format query string false "csv (default) or ndjson"
+// @Param tz query string false "IANA timezone for CSV timestamps, e.g. Africa/Nairobi (default UTC; also read from the Time-Zone header)"
+// @Param user_id query string false "Only this customer's orders"
+// @Param status query string false "placed, paid, shipped, delivered or refunded"
+// @Param tax_treatment query string false "inclusive, exclusive or exempt"
+// @Param fiscal_status query string false "pending, issued or failed"
+// @Success 200 {string} string "Export file"
+// @Failure 400 {string} string "Unsupported format"
+// @Router /admin/orders/export [get]
+func (h *Handler) handleExport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format, err := export.ParseFormat(query.Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	loc, err := timezone.FromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter := repository.OrderFilter{
+		UserID:       query.Get("user_id"),
+		Status:       query.Get("status"),
+		TaxTreatment: query.Get("tax_treatment"),
+		FiscalStatus: query.Get("fiscal_status"),
+	}
+	if err := validateFilter(filter); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	each := func(ctx context.Context, batchSize int, fn func([]models.Order) error) error {
+		return h.service.EachOrder(ctx, filter, batchSize, fn)
+	}
+	rows, err := export.Stream(r.Context(), w, format, "orders", orderColumns(loc), each)
+	if err != nil {
+		h.log.Warn("Order export aborted", zap.Int("rows", rows), zap.Error(err))
+		return
+	}
+	h.log.Info("Orders exported", zap.String("format", format), zap.Int("rows", rows))
+}
+
+// handleAdminGet handles GET /api/v1/admin/orders/{id}
+// @Summary Get order
+// @Description An order with all its notes, internal comments included. With as_of, the order as it was then, rebuilt from its event stream (ORDER_EVENT_SOURCING)
+// @Tags Orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param as_of query string false "RFC 3339 time, e.g. 2025-03-14T09:00:00Z"
+// @Success 200 {object} models.Order
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Order not found"
+// @Failure 409 {string} string "Event sourcing not enabled"
+// @Router /admin/orders/{id} [get]
+func (h *Handler) handleAdminGet(w http.ResponseWriter, r *http.Request) {
+	asOf := r.URL.Query().Get("as_of")
+	if asOf == "" {
+		h.get(w, r, true)
+		return
+	}
+	at, err := time.Parse(time.RFC3339, asOf)
+	if err != nil {
+		http.Error(w, "as_of must be an RFC 3339 time", http.StatusBadRequest)
+		return
+	}
+	order, err := h.service.AsOf(r.Context(), mux.Vars(r)["id"], at)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	order.Links = h.links.Order(order.ID)
+	response.JSON(w, http.StatusOK, order)
+}
+
+// handleAdminHistory handles GET /api/v1/admin/orders/{id}/history
+// @Summary Get order history
+// @Description Every change to the order, oldest first, from its event stream (ORDER_EVENT_SOURCING)
+// @Tags Orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} models.OrderHistory
+// @Failure 404 {string} string "Order not found"
+// @Failure 409 {string} string "Event sourcing not enabled"
+// @Router /admin/orders/{id}/history [get]
+func (h *Handler) handleAdminHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := h.service.History(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, history)
+}
+
+// handleAdminAddNote handles POST /api/v1/admin/orders/{id}/notes
+// @Summary Add an order note or internal comment
+// @Description Adds a note the customer sees, or with internal=true a comment only admins see
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param request body models.OrderNoteRequest true "Note"
+// @Success 201 {object} models.OrderNote
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Order not found"
+// @Router /admin/orders/{id}/notes [post]
+func (h *Handler) handleAdminAddNote(w http.ResponseWriter, r *http.Request) {
+	h.addNote(w, r, true)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request, filter repository.OrderFilter, limit, offset int) {
+	resp, err := h.service.List(r.Context(), filter, limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	for i := range resp.Orders {
+		resp.Orders[i].Links = h.links.Order(resp.Orders[i].ID)
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// get writes an order; internal comments are only included on the admin route
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, isAdmin bool) {
+	order, err := h.service.Get(r.Context(), mux.Vars(r)["id"], auth.ClaimsFromContext(r.Context()).UserID(), isAdmin)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	order.Links = h.links.Order(order.ID)
+	response.JSON(w, http.StatusOK, order)
+}
+
+// addNote adds a note as the caller; only the admin route can note any order or write internal comments
+func (h *Handler) addNote(w http.ResponseWriter, r *http.Request, asAdmin bool) {
+	var req models.OrderNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	claims := auth.ClaimsFromContext(r.Context())
+	note, err := h.service.AddNote(r.Context(), mux.Vars(r)["id"], claims.UserID(), claims.Role, asAdmin, &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, note)
+}
+
+// writeError maps order errors to 400/404/409/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidOrderRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrEventSourcingDisabled):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, repository.ErrOrderNotFound):
+		http.Error(w, "Order not found", http.StatusNotFound)
+	default:
+		h.log.Error("Order request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// handleEvents handles GET /api/v1/orders/{id}/events
+// @Summary Stream order status updates
+// @Description Server-Sent Events stream of order state transitions (placed, paid, shipped, delivered, refunded). Only transitions of the caller's own orders are sent (admins see all). Events published while disconnected are not replayed.
+// @Tags Orders
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param access_token query string false "Access token for EventSource clients that can't set headers"
+// @Success 200 {object} models.OrderStatusUpdate "One SSE data frame per transition"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Streaming unsupported"
+// @Router /orders/{id}/events [get]
+func (h *Handler) handleEvents(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+	orderID := mux.Vars(r)["id"]
+	isAdmin := claims.Role == models.RoleAdmin
+
+	// Subscribe before sending headers so no event slips between the two
+	sub := h.stream.Subscribe(orderID)
+	defer sub.Close()
+
+	sse, err := realtime.NewSSE(w)
+	if err != nil {
+		h.log.Error("Failed to start order event stream", zap.Error(err))
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if err := sse.Heartbeat(); err != nil {
+				return
+			}
+		case event := <-sub.C:
+			update, ok := toStatusUpdate(event, claims.UserID(), isAdmin)
+			if !ok {
+				continue
+			}
+			update.Links = h.links.Order(update.OrderID)
+			if err := sse.Send(event.ID, update.Status, update); err != nil {
+				return
+			}
+		}
+	}
+}
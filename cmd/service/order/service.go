@@ -0,0 +1,246 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+const maxNoteLength = 2000
+
+var (
+	// ErrInvalidOrderRequest wraps validation problems with an order listing or note
+	ErrInvalidOrderRequest = errors.New("invalid order request")
+	// ErrEventSourcingDisabled is returned for order history while ORDER_EVENT_SOURCING is off
+	ErrEventSourcingDisabled = errors.New("order event sourcing is not enabled")
+)
+
+// OrderService keeps the local order records and their notes
+// Customers see their own orders and the notes on them that aren't internal;
+// admins see every order with all notes, internal comments included, and with event sourcing
+// its history and its state as of a past date
+type OrderService struct {
+	repo          *repository.OrderRepository
+	users         *repository.UserRepository // note author names
+	eventSourcing bool                       // repo appends changes to order event streams
+	clock         clock.Clock
+	log           *zap.Logger
+}
+
+func NewOrderService(repo *repository.OrderRepository, users *repository.UserRepository, eventSourcing bool,
+	clk clock.Clock, log *zap.Logger) *OrderService {
+	return &OrderService{
+		repo:          repo,
+		users:         users,
+		eventSourcing: eventSourcing,
+		clock:         clk,
+		log:           log,
+	}
+}
+
+// Get returns an order with its notes; internal comments only for admins
+// Customers get repository.ErrOrderNotFound for orders that aren't theirs
+func (s *OrderService) Get(ctx context.Context, id, userID string, isAdmin bool) (*models.Order, error) {
+	order, err := s.repo.Get(ctx, id, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && order.UserID != userID {
+		return nil, repository.ErrOrderNotFound
+	}
+	if order.Notes == nil {
+		order.Notes = []models.OrderNote{}
+	}
+	return order, nil
+}
+
+// History returns an order's event stream, oldest first (admin only)
+// Orders recorded before event sourcing was turned on have an empty history until they next change
+func (s *OrderService) History(ctx context.Context, id string) (*models.OrderHistory, error) {
+	if !s.eventSourcing {
+		return nil, ErrEventSourcingDisabled
+	}
+	if _, err := s.repo.Get(ctx, id, false); err != nil {
+		return nil, err
+	}
+	events, err := s.repo.Events(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []models.OrderEvent{}
+	}
+	return &models.OrderHistory{OrderID: id, Events: events}, nil
+}
+
+// AsOf returns an order as it was at a point in time, rebuilt from its event stream (admin only)
+// Returns: repository.ErrOrderNotFound when nothing was recorded for the order by then
+func (s *OrderService) AsOf(ctx context.Context, id string, at time.Time) (*models.Order, error) {
+	if !s.eventSourcing {
+		return nil, ErrEventSourcingDisabled
+	}
+	if at.After(s.clock.Now()) {
+		return nil, fmt.Errorf("%w: as_of must not be in the future", ErrInvalidOrderRequest)
+	}
+	order, err := s.repo.StateAt(ctx, id, at)
+	if err != nil {
+		return nil, err
+	}
+	if order.Notes == nil {
+		order.Notes = []models.OrderNote{}
+	}
+	return order, nil
+}
+
+// List returns a page of orders, newest first
+func (s *OrderService) List(ctx context.Context, filter repository.OrderFilter, limit, offset int) (*models.OrderListResponse, error) {
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+	orders, total, err := s.repo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if orders == nil {
+		orders = []models.Order{}
+	}
+	return &models.OrderListResponse{Orders: orders, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// EachOrder feeds the orders matching filter to fn in batches for streaming exports
+// The filter is not validated here: check it with validateFilter before the response starts
+func (s *OrderService) EachOrder(ctx context.Context, filter repository.OrderFilter, batchSize int, fn func([]models.Order) error) error {
+	return s.repo.Each(ctx, filter, batchSize, fn)
+}
+
+// validateFilter rejects filter values no order can have, so typos aren't answered with nothing
+func validateFilter(filter repository.OrderFilter) error {
+	if filter.Status != "" {
+		if _, ok := statusSet[filter.Status]; !ok {
+			return fmt.Errorf("%w: status must be placed, paid, shipped, delivered or refunded", ErrInvalidOrderRequest)
+		}
+	}
+	switch filter.TaxTreatment {
+	case "", models.TaxInclusive, models.TaxExclusive, models.TaxExempt:
+	default:
+		return fmt.Errorf("%w: tax_treatment must be inclusive, exclusive or exempt", ErrInvalidOrderRequest)
+	}
+	switch filter.FiscalStatus {
+	case "", models.FiscalPending, models.FiscalIssued, models.FiscalFailed:
+	default:
+		return fmt.Errorf("%w: fiscal_status must be pending, issued or failed", ErrInvalidOrderRequest)
+	}
+	return nil
+}
+
+// AddNote adds a note to an order, attributed to its author and their role
+// Only asAdmin can note other people's orders or write internal comments
+func (s *OrderService) AddNote(ctx context.Context, orderID, userID, role string, asAdmin bool, req *models.OrderNoteRequest) (*models.OrderNote, error) {
+	body := strings.TrimSpace(req.Body)
+	switch {
+	case body == "":
+		return nil, fmt.Errorf("%w: body is required", ErrInvalidOrderRequest)
+	case len(body) > maxNoteLength:
+		return nil, fmt.Errorf("%w: body must be at most %d characters", ErrInvalidOrderRequest, maxNoteLength)
+	}
+	if _, err := s.Get(ctx, orderID, userID, asAdmin); err != nil {
+		return nil, err
+	}
+
+	note := &models.OrderNote{
+		OrderID:    orderID,
+		AuthorID:   userID,
+		AuthorName: s.authorName(ctx, userID),
+		AuthorRole: role,
+		Body:       body,
+		Internal:   asAdmin && req.Internal,
+	}
+	if err := s.repo.AddNote(ctx, note); err != nil {
+		return nil, err
+	}
+	s.log.Info("Order note added", zap.String("order_id", orderID), zap.String("author_id", userID),
+		zap.Bool("internal", note.Internal))
+	return note, nil
+}
+
+// authorName is the author's name as notes show it; empty when it can't be looked up
+func (s *OrderService) authorName(ctx context.Context, userID string) string {
+	if userID == "" {
+		return ""
+	}
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		s.log.Warn("Failed to look up order note author", zap.String("user_id", userID), zap.Error(err))
+		return ""
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+// HandleOrderPlaced records a placed order
+func (s *OrderService) HandleOrderPlaced(ctx context.Context, event events.Event) error {
+	var payload events.OrderPlaced
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	if payload.OrderID == "" || payload.UserID == "" {
+		return nil
+	}
+	placedAt := event.OccurredAt
+	if placedAt.IsZero() {
+		placedAt = s.clock.Now()
+	}
+
+	items := make([]models.OrderLine, 0, len(payload.Items))
+	for _, item := range payload.Items {
+		items = append(items, models.OrderLine{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			GiftWrap:  item.GiftWrap,
+		})
+	}
+	return s.repo.Record(ctx, &models.Order{
+		ID:           payload.OrderID,
+		OrderNumber:  payload.OrderNumber,
+		UserID:       payload.UserID,
+		Status:       models.OrderStatusPlaced,
+		Total:        payload.Total,
+		Currency:     strings.ToUpper(payload.Currency),
+		Channel:      payload.Channel,
+		Items:        items,
+		QuoteID:      payload.QuoteID,
+		TaxTreatment: payload.TaxTreatment,
+		VATID:        payload.VATID,
+		PlacedAt:     placedAt.UTC(),
+	})
+}
+
+// HandleStatusEvent moves an order to the status its payment, shipping, delivery or refund event represents
+func (s *OrderService) HandleStatusEvent(ctx context.Context, event events.Event) error {
+	var ref orderRef
+	if err := event.Decode(&ref); err != nil {
+		return err
+	}
+	if ref.OrderID == "" {
+		return nil
+	}
+	_, err := s.repo.UpdateStatus(ctx, ref.OrderID, orderStatusByEvent[event.Type])
+	return err
+}
+
+// statusSet holds the order states a listing can filter by
+var statusSet = map[string]struct{}{
+	models.OrderStatusPlaced:    {},
+	models.OrderStatusPaid:      {},
+	models.OrderStatusShipped:   {},
+	models.OrderStatusDelivered: {},
+	models.OrderStatusRefunded:  {},
+}
@@ -0,0 +1,84 @@
+package order
+
+import (
+	"context"
+
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/realtime"
+	"go.uber.org/zap"
+)
+
+// orderStatusByEvent maps domain events to the order state they represent
+var orderStatusByEvent = map[string]string{
+	events.TypeOrderPlaced:     models.OrderStatusPlaced,
+	events.TypePaymentCaptured: models.OrderStatusPaid,
+	events.TypeOrderShipped:    models.OrderStatusShipped,
+	events.TypeOrderDelivered:  models.OrderStatusDelivered,
+	events.TypeRefundIssued:    models.OrderStatusRefunded,
+}
+
+// orderRef holds the fields every order event payload shares
+type orderRef struct {
+	OrderID     string `json:"order_id"`
+	OrderNumber string `json:"order_number"`
+	UserID      string `json:"user_id"`
+}
+
+// StatusStream relays order events from the bus to clients connected to this instance
+type StatusStream struct {
+	hub *realtime.Hub
+	log *zap.Logger
+}
+
+// NewStatusStream subscribes to order events on every instance (SubscribeAll),
+// since a client's SSE connection can be held by any of them
+func NewStatusStream(subscriber events.Subscriber, log *zap.Logger) *StatusStream {
+	s := &StatusStream{
+		hub: realtime.NewHub(log),
+		log: log,
+	}
+
+	for eventType := range orderStatusByEvent {
+		if err := subscriber.SubscribeAll(eventType, s.handleEvent); err != nil {
+			log.Error("Failed to subscribe order stream to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+	return s
+}
+
+// Subscribe returns a listener for one order's events
+func (s *StatusStream) Subscribe(orderID string) *realtime.Subscription {
+	return s.hub.Subscribe(orderID, 16)
+}
+
+func (s *StatusStream) handleEvent(ctx context.Context, event events.Event) error {
+	var ref orderRef
+	if err := event.Decode(&ref); err != nil {
+		return err
+	}
+	if ref.OrderID != "" {
+		s.hub.Publish(ref.OrderID, event)
+	}
+	return nil
+}
+
+// toStatusUpdate converts a relayed event into the client-facing payload
+// Returns: false if the event isn't visible to userID (unless admin)
+func toStatusUpdate(event events.Event, userID string, isAdmin bool) (models.OrderStatusUpdate, bool) {
+	var ref orderRef
+	if err := event.Decode(&ref); err != nil {
+		return models.OrderStatusUpdate{}, false
+	}
+	if !isAdmin && ref.UserID != userID {
+		return models.OrderStatusUpdate{}, false
+	}
+
+	return models.OrderStatusUpdate{
+		OrderID:     ref.OrderID,
+		OrderNumber: ref.OrderNumber,
+		Status:      orderStatusByEvent[event.Type],
+		OccurredAt:  event.OccurredAt,
+		Details:     event.Payload,
+	}, true
+}
@@ -0,0 +1,212 @@
+package page
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// publicCacheControl lets browsers and CDNs keep public pages briefly; edits show up within it
+const publicCacheControl = "public, max-age=300"
+
+type Handler struct {
+	service *PageService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *PageService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers content page routes
+// Published pages are public; managing them is admin-only
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/pages", h.handlePublicList).Methods("GET")
+	router.HandleFunc("/pages/{slug}", h.handlePublicGet).Methods("GET")
+
+	admin := router.PathPrefix("/admin/pages").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("", h.handleCreate).Methods("POST")
+	admin.HandleFunc("", h.handleList).Methods("GET")
+	admin.HandleFunc("/{id}", h.handleGet).Methods("GET")
+	admin.HandleFunc("/{id}", h.handleUpdate).Methods("PUT")
+	admin.HandleFunc("/{id}", h.handleDelete).Methods("DELETE")
+}
+
+// handlePublicList handles GET /api/v1/pages
+// @Summary List pages
+// @Description Published content pages without their bodies, ordered by slug, e.g. for footer links
+// @Tags Pages
+// @Produce json
+// @Success 200 {array} models.PageSummary
+// @Failure 500 {string} string "Internal server error"
+// @Router /pages [get]
+func (h *Handler) handlePublicList(w http.ResponseWriter, r *http.Request) {
+	pages, err := h.service.PublishedList(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", publicCacheControl)
+	response.JSON(w, http.StatusOK, pages)
+}
+
+// handlePublicGet handles GET /api/v1/pages/{slug}
+// @Summary Get page
+// @Description A published content page by slug, e.g. about, shipping or returns
+// @Tags Pages
+// @Produce json
+// @Param slug path string true "Page slug"
+// @Success 200 {object} models.Page
+// @Failure 404 {string} string "Page not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /pages/{slug} [get]
+func (h *Handler) handlePublicGet(w http.ResponseWriter, r *http.Request) {
+	page, err := h.service.Published(r.Context(), mux.Vars(r)["slug"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", publicCacheControl)
+	response.JSON(w, http.StatusOK, page)
+}
+
+// handleCreate handles POST /api/v1/admin/pages
+// @Summary Create page
+// @Description Adds a content page, published or as a draft
+// @Tags Pages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.PageRequest true "Page"
+// @Success 201 {object} models.Page
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 409 {string} string "Slug already in use"
+// @Router /admin/pages [post]
+func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req models.PageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.Create(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, page)
+}
+
+// handleList handles GET /api/v1/admin/pages
+// @Summary List all pages
+// @Description Content pages in any state with their bodies, ordered by slug
+// @Tags Pages
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "draft or published"
+// @Success 200 {array} models.Page
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/pages [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	pages, err := h.service.List(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, pages)
+}
+
+// handleGet handles GET /api/v1/admin/pages/{id}
+// @Summary Get page by ID
+// @Tags Pages
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Page ID"
+// @Success 200 {object} models.Page
+// @Failure 404 {string} string "Page not found"
+// @Router /admin/pages/{id} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	page, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, page)
+}
+
+// handleUpdate handles PUT /api/v1/admin/pages/{id}
+// @Summary Replace page
+// @Description Replaces a page's slug, title, body and publish state. publish=false takes a live page down.
+// @Tags Pages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Page ID"
+// @Param request body models.PageRequest true "Page"
+// @Success 200 {object} models.Page
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Page not found"
+// @Failure 409 {string} string "Slug already in use"
+// @Router /admin/pages/{id} [put]
+func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req models.PageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.Update(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, page)
+}
+
+// handleDelete handles DELETE /api/v1/admin/pages/{id}
+// @Summary Delete page
+// @Tags Pages
+// @Security BearerAuth
+// @Param id path string true "Page ID"
+// @Success 204
+// @Failure 404 {string} string "Page not found"
+// @Router /admin/pages/{id} [delete]
+func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError maps page errors to 400/404/409/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidPage):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrPageNotFound):
+		http.Error(w, "Page not found", http.StatusNotFound)
+	case errors.Is(err, ErrPageSlugTaken):
+		http.Error(w, "Slug already in use", http.StatusConflict)
+	default:
+		h.log.Error("Page request failed", zap.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,213 @@
+package page
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Cached public reads; writes invalidate them, the TTL only bounds staleness from other writers
+const (
+	pagesCacheKey = "pages:published"
+	pageCacheTTL  = 10 * time.Minute
+
+	maxTitleLength = 255
+	maxBodyLength  = 200_000
+)
+
+var (
+	// ErrInvalidPage wraps validation problems with a page request
+	ErrInvalidPage = errors.New("invalid page")
+	// ErrPageSlugTaken is returned when another page already uses the slug
+	ErrPageSlugTaken = errors.New("page slug is already in use")
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// PageService manages content pages and serves the published ones to the storefront
+// Published pages are cached by slug, along with the list of them; every admin write
+// invalidates what it touched
+type PageService struct {
+	repo  *repository.PageRepository
+	cache *cache.Loader
+	clock clock.Clock
+	log   *zap.Logger
+}
+
+func NewPageService(repo *repository.PageRepository, appCache cache.Cache, clk clock.Clock, log *zap.Logger) *PageService {
+	return &PageService{
+		repo:  repo,
+		cache: cache.NewLoader(appCache),
+		clock: clk,
+		log:   log,
+	}
+}
+
+func pageCacheKey(slug string) string {
+	return "pages:slug:" + slug
+}
+
+// Published returns the published page at slug, from cache when possible
+// Returns: repository.ErrPageNotFound for unknown slugs and drafts
+func (s *PageService) Published(ctx context.Context, slug string) (*models.Page, error) {
+	if !slugPattern.MatchString(slug) {
+		return nil, repository.ErrPageNotFound
+	}
+	page, err := cache.LoadJSON(ctx, s.cache, pageCacheKey(slug), pageCacheTTL, func(ctx context.Context) (*models.Page, error) {
+		return s.repo.GetBySlug(ctx, slug)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if page.Status != models.PagePublished {
+		return nil, repository.ErrPageNotFound
+	}
+	// Which admin edited a page is for staff only
+	page.UpdatedBy = ""
+	return page, nil
+}
+
+// PublishedList returns the published pages without their bodies, from cache when possible
+func (s *PageService) PublishedList(ctx context.Context) ([]models.PageSummary, error) {
+	summaries, err := cache.LoadJSON(ctx, s.cache, pagesCacheKey, pageCacheTTL, func(ctx context.Context) ([]models.PageSummary, error) {
+		return s.repo.Summaries(ctx)
+	})
+	if summaries == nil && err == nil {
+		summaries = []models.PageSummary{}
+	}
+	return summaries, err
+}
+
+// List returns every page for admins, optionally only drafts or published ones
+func (s *PageService) List(ctx context.Context, status string) ([]models.Page, error) {
+	switch status {
+	case "", models.PageDraft, models.PagePublished:
+	default:
+		return nil, fmt.Errorf("%w: status must be draft or published", ErrInvalidPage)
+	}
+	pages, err := s.repo.List(ctx, status)
+	if pages == nil && err == nil {
+		pages = []models.Page{}
+	}
+	return pages, err
+}
+
+// Get returns a page in any state (admin only)
+func (s *PageService) Get(ctx context.Context, id string) (*models.Page, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Create validates req and adds a page, published or as a draft
+func (s *PageService) Create(ctx context.Context, adminID string, req *models.PageRequest) (*models.Page, error) {
+	page := &models.Page{}
+	if err := s.apply(ctx, page, adminID, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, page); err != nil {
+		return nil, slugTaken(err)
+	}
+
+	s.log.Info("Page created", zap.String("id", page.ID), zap.String("slug", page.Slug), zap.String("status", page.Status))
+	s.invalidate(ctx, page.Slug)
+	return page, nil
+}
+
+// Update replaces a page's slug, title, body and publish state
+// A changed slug takes effect at once; the old one stops resolving
+func (s *PageService) Update(ctx context.Context, adminID, id string, req *models.PageRequest) (*models.Page, error) {
+	page, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	oldSlug := page.Slug
+	if err := s.apply(ctx, page, adminID, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, page); err != nil {
+		return nil, slugTaken(err)
+	}
+
+	s.log.Info("Page updated", zap.String("id", page.ID), zap.String("slug", page.Slug), zap.String("status", page.Status))
+	s.invalidate(ctx, oldSlug, page.Slug)
+	return page, nil
+}
+
+// Delete removes a page
+func (s *PageService) Delete(ctx context.Context, id string) error {
+	page, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.log.Info("Page deleted", zap.String("id", id), zap.String("slug", page.Slug))
+	s.invalidate(ctx, page.Slug)
+	return nil
+}
+
+// apply validates req onto page
+func (s *PageService) apply(ctx context.Context, page *models.Page, adminID string, req *models.PageRequest) error {
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	title := strings.TrimSpace(req.Title)
+	switch {
+	case len(slug) > 64 || !slugPattern.MatchString(slug):
+		return fmt.Errorf("%w: slug must be up to 64 lowercase letters, digits and single dashes", ErrInvalidPage)
+	case title == "":
+		return fmt.Errorf("%w: title is required", ErrInvalidPage)
+	case len(title) > maxTitleLength:
+		return fmt.Errorf("%w: title must be at most %d characters", ErrInvalidPage, maxTitleLength)
+	case len(req.Body) > maxBodyLength:
+		return fmt.Errorf("%w: body must be at most %d bytes", ErrInvalidPage, maxBodyLength)
+	}
+
+	if existing, err := s.repo.GetBySlug(ctx, slug); err == nil && existing.ID != page.ID {
+		return ErrPageSlugTaken
+	} else if err != nil && !errors.Is(err, repository.ErrPageNotFound) {
+		return err
+	}
+
+	page.Slug = slug
+	page.Title = title
+	page.Body = req.Body
+	page.UpdatedBy = adminID
+	page.Status = models.PageDraft
+	if req.Publish {
+		page.Status = models.PagePublished
+		if page.PublishedAt == nil {
+			now := s.clock.Now()
+			page.PublishedAt = &now
+		}
+	}
+	return nil
+}
+
+// invalidate drops the page list and the given slugs from the cache
+// Failures are logged only; entries expire after their TTL anyway
+func (s *PageService) invalidate(ctx context.Context, slugs ...string) {
+	keys := []string{pagesCacheKey}
+	for _, slug := range slugs {
+		keys = append(keys, pageCacheKey(slug))
+	}
+	if err := s.cache.Invalidate(ctx, keys...); err != nil {
+		s.log.Warn("Failed to invalidate page cache", zap.Strings("keys", keys), zap.Error(err))
+	}
+}
+
+// slugTaken reports a write that raced another for the same slug as ErrPageSlugTaken
+func slugTaken(err error) error {
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		return ErrPageSlugTaken
+	}
+	return err
+}
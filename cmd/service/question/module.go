@@ -0,0 +1,52 @@
+package question
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Module provides product Q&A: customers ask on product pages, admins moderate and answer
+// as the store, vendors answer on their own products, and askers hear about it by event
+type Module struct {
+	handler *Handler
+}
+
+func NewModule(deps module.Deps) *Module {
+	service := NewQuestionService(repository.NewQuestionRepository(deps.DB, deps.Log),
+		repository.NewProductRepository(deps.DB, deps.Log), repository.NewUserRepository(deps.DB, deps.Log),
+		deps.Settings, deps.Events, deps.Notifier, deps.Clock, deps.Log)
+
+	handlers := map[string]events.Handler{
+		events.TypeQuestionAnswered: service.HandleQuestionAnswered,
+		events.TypeQuestionRejected: service.HandleQuestionRejected,
+		events.TypeProductDeleted:   service.HandleProductDeleted,
+	}
+	for eventType, handler := range handlers {
+		if err := deps.Events.Subscribe(eventType, "questions", handler); err != nil {
+			deps.Log.Error("Failed to subscribe questions to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+
+	return &Module{
+		handler: NewHandler(service, repository.NewVendorRepository(deps.DB, deps.Log), deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.ProductQuestion{}, &models.ProductAnswer{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
@@ -0,0 +1,372 @@
+package question
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/Jason-Omondi/ecomgo/internal/vendor"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *QuestionService
+	vendors *repository.VendorRepository
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *QuestionService, vendors *repository.VendorRepository, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		vendors: vendors,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers product Q&A routes
+// Published questions are public; asking needs a sign-in; vendors answer on their own products;
+// moderation is admin-only
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/products/{id}/questions", h.handleProductQuestions).Methods("GET")
+
+	ask := router.PathPrefix("/products/{id}/questions").Subrouter()
+	ask.Use(auth.Authenticate(h.tokens))
+	ask.HandleFunc("", h.handleAsk).Methods("POST")
+
+	me := router.PathPrefix("/users/me/questions").Subrouter()
+	me.Use(auth.Authenticate(h.tokens))
+	me.HandleFunc("", h.handleMine).Methods("GET")
+
+	vendorRoutes := router.PathPrefix("/vendor/questions").Subrouter()
+	vendorRoutes.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleVendor), vendor.Require(h.vendors, h.log))
+	vendorRoutes.HandleFunc("", h.handleVendorList).Methods("GET")
+	vendorRoutes.HandleFunc("/{id}/answers", h.handleVendorAnswer).Methods("POST")
+
+	admin := router.PathPrefix("/admin/questions").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("", h.handleList).Methods("GET")
+	admin.HandleFunc("/{id}", h.handleGet).Methods("GET")
+	admin.HandleFunc("/{id}", h.handleDelete).Methods("DELETE")
+	admin.HandleFunc("/{id}/publish", h.handlePublish).Methods("POST")
+	admin.HandleFunc("/{id}/reject", h.handleReject).Methods("POST")
+	admin.HandleFunc("/{id}/answers", h.handleAnswer).Methods("POST")
+	admin.HandleFunc("/{id}/answers/{answerId}", h.handleDeleteAnswer).Methods("DELETE")
+}
+
+// handleProductQuestions handles GET /api/v1/products/{id}/questions
+// @Summary List product questions
+// @Description Published questions on a product with their answers, newest first
+// @Tags Questions
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.QuestionListResponse
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /products/{id}/questions [get]
+func (h *Handler) handleProductQuestions(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	resp, err := h.service.ProductQuestions(r.Context(), mux.Vars(r)["id"], limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleAsk handles POST /api/v1/products/{id}/questions
+// @Summary Ask a question
+// @Description Asks about a product. The question is pending until an admin publishes it, unless the question_moderation setting is off. The asker is notified of answers on their questions channel.
+// @Tags Questions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body models.QuestionRequest true "Question"
+// @Success 201 {object} models.ProductQuestion
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Product not found"
+// @Router /products/{id}/questions [post]
+func (h *Handler) handleAsk(w http.ResponseWriter, r *http.Request) {
+	var req models.QuestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	question, err := h.service.Ask(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, question)
+}
+
+// handleMine handles GET /api/v1/users/me/questions
+// @Summary List own questions
+// @Description The caller's questions in any state, with answers, newest first
+// @Tags Questions
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.QuestionListResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/me/questions [get]
+func (h *Handler) handleMine(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	resp, err := h.service.Mine(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleVendorList handles GET /api/v1/vendor/questions
+// @Summary List questions on own products
+// @Tags Vendors
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending, published or rejected"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.QuestionListResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a vendor"
+// @Router /vendor/questions [get]
+func (h *Handler) handleVendorList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	resp, err := h.service.VendorQuestions(r.Context(), vendor.FromContext(r.Context()).ID, r.URL.Query().Get("status"), limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleVendorAnswer handles POST /api/v1/vendor/questions/{id}/answers
+// @Summary Answer a question on an own product
+// @Description Answers a published question on one of the caller's products, as the vendor
+// @Tags Vendors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Question ID"
+// @Param request body models.AnswerRequest true "Answer"
+// @Success 201 {object} models.ProductQuestion
+// @Failure 400 {string} string "Invalid request"
+// @Failure 403 {string} string "Not a vendor"
+// @Failure 404 {string} string "Question not found"
+// @Failure 409 {string} string "Question is not published"
+// @Router /vendor/questions/{id}/answers [post]
+func (h *Handler) handleVendorAnswer(w http.ResponseWriter, r *http.Request) {
+	var req models.AnswerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	question, err := h.service.AnswerAsVendor(r.Context(), vendor.FromContext(r.Context()), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, question)
+}
+
+// handleList handles GET /api/v1/admin/questions
+// @Summary List questions
+// @Description Questions in any state, newest first. Use status=pending for the moderation queue.
+// @Tags Questions
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending, published or rejected"
+// @Param product_id query string false "Only questions on this product"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.QuestionListResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/questions [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	query := r.URL.Query()
+
+	resp, err := h.service.List(r.Context(), query.Get("product_id"), query.Get("status"), limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleGet handles GET /api/v1/admin/questions/{id}
+// @Summary Get question
+// @Tags Questions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Question ID"
+// @Success 200 {object} models.ProductQuestion
+// @Failure 404 {string} string "Question not found"
+// @Router /admin/questions/{id} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	question, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, question)
+}
+
+// handleDelete handles DELETE /api/v1/admin/questions/{id}
+// @Summary Delete question
+// @Description Deletes a question with its answers, without telling the asker
+// @Tags Questions
+// @Security BearerAuth
+// @Param id path string true "Question ID"
+// @Success 204
+// @Failure 404 {string} string "Question not found"
+// @Router /admin/questions/{id} [delete]
+func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePublish handles POST /api/v1/admin/questions/{id}/publish
+// @Summary Publish question
+// @Description Shows a pending or rejected question on the product page
+// @Tags Questions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Question ID"
+// @Success 200 {object} models.ProductQuestion
+// @Failure 404 {string} string "Question not found"
+// @Failure 409 {string} string "Question is already in that state"
+// @Router /admin/questions/{id}/publish [post]
+func (h *Handler) handlePublish(w http.ResponseWriter, r *http.Request) {
+	question, err := h.service.Publish(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, question)
+}
+
+// handleReject handles POST /api/v1/admin/questions/{id}/reject
+// @Summary Reject question
+// @Description Hides a pending or published question. The asker is notified, with the reason if one is given.
+// @Tags Questions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Question ID"
+// @Param request body models.RejectQuestionRequest false "Reason"
+// @Success 200 {object} models.ProductQuestion
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Question not found"
+// @Failure 409 {string} string "Question is already in that state"
+// @Router /admin/questions/{id}/reject [post]
+func (h *Handler) handleReject(w http.ResponseWriter, r *http.Request) {
+	var req models.RejectQuestionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	question, err := h.service.Reject(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, question)
+}
+
+// handleAnswer handles POST /api/v1/admin/questions/{id}/answers
+// @Summary Answer question
+// @Description Answers as the store (named by the store_name setting). Answering a pending question publishes it.
+// @Tags Questions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Question ID"
+// @Param request body models.AnswerRequest true "Answer"
+// @Success 201 {object} models.ProductQuestion
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Question not found"
+// @Failure 409 {string} string "Question is not published"
+// @Router /admin/questions/{id}/answers [post]
+func (h *Handler) handleAnswer(w http.ResponseWriter, r *http.Request) {
+	var req models.AnswerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	question, err := h.service.AnswerAsStore(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, question)
+}
+
+// handleDeleteAnswer handles DELETE /api/v1/admin/questions/{id}/answers/{answerId}
+// @Summary Delete answer
+// @Tags Questions
+// @Security BearerAuth
+// @Param id path string true "Question ID"
+// @Param answerId path string true "Answer ID"
+// @Success 204
+// @Failure 404 {string} string "Answer not found"
+// @Router /admin/questions/{id}/answers/{answerId} [delete]
+func (h *Handler) handleDeleteAnswer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.service.DeleteAnswer(r.Context(), vars["id"], vars["answerId"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError maps Q&A errors to 400/404/409/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidQuestion):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrQuestionNotFound):
+		http.Error(w, "Question not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrAnswerNotFound):
+		http.Error(w, "Answer not found", http.StatusNotFound)
+	case errors.Is(err, ErrQuestionNotPublished):
+		http.Error(w, "Question is not published", http.StatusConflict)
+	case errors.Is(err, ErrQuestionState):
+		http.Error(w, "Question is already in that state", http.StatusConflict)
+	default:
+		h.log.Error("Question request failed", zap.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,360 @@
+package question
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/notify"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"go.uber.org/zap"
+)
+
+const (
+	maxQuestionLength = 1000
+	maxAnswerLength   = 2000
+	maxReasonLength   = 255
+)
+
+var (
+	// ErrInvalidQuestion wraps validation problems with questions, answers and rejections
+	ErrInvalidQuestion = errors.New("invalid question")
+	// ErrQuestionNotPublished is returned when answering a question that isn't shown on the product page
+	ErrQuestionNotPublished = errors.New("question is not published")
+	// ErrQuestionState is returned when moderating a question that is already in the requested state
+	ErrQuestionState = errors.New("question is already in that state")
+)
+
+// QuestionService runs product Q&A: customers ask, admins and the product's vendor answer
+// Questions wait for moderation unless the question_moderation setting is off. Answers and
+// rejections are published as events; the asker hears about them on their questions channel
+// and in the notification center.
+type QuestionService struct {
+	repo      *repository.QuestionRepository
+	products  repository.ProductStore
+	users     repository.UserStore
+	settings  *settings.Store
+	publisher events.Publisher
+	notifier  *notify.Notifier
+	clock     clock.Clock
+	log       *zap.Logger
+}
+
+func NewQuestionService(repo *repository.QuestionRepository, products repository.ProductStore, users repository.UserStore,
+	storeSettings *settings.Store, publisher events.Publisher, notifier *notify.Notifier, clk clock.Clock, log *zap.Logger) *QuestionService {
+	return &QuestionService{
+		repo:      repo,
+		products:  products,
+		users:     users,
+		settings:  storeSettings,
+		publisher: publisher,
+		notifier:  notifier,
+		clock:     clk,
+		log:       log,
+	}
+}
+
+// Ask records userID's question about productID
+// Returns: repository.ErrProductNotFound for unknown and inactive products
+func (s *QuestionService) Ask(ctx context.Context, userID, productID string, req *models.QuestionRequest) (*models.ProductQuestion, error) {
+	body, err := text("body", req.Body, maxQuestionLength)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.activeProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	question := &models.ProductQuestion{
+		ProductID:  productID,
+		UserID:     userID,
+		AuthorName: displayName(user),
+		Body:       body,
+		Status:     models.QuestionPending,
+		Answers:    []models.ProductAnswer{},
+	}
+	if !s.settings.QuestionModeration(ctx) {
+		question.Status = models.QuestionPublished
+	}
+	if err := s.repo.Create(ctx, question); err != nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+// ProductQuestions returns a page of productID's published questions with their answers
+func (s *QuestionService) ProductQuestions(ctx context.Context, productID string, limit, offset int) (*models.QuestionListResponse, error) {
+	if _, err := s.activeProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.list(ctx, repository.QuestionFilter{ProductID: productID, Status: models.QuestionPublished}, limit, offset)
+}
+
+// Mine returns a page of userID's questions in any state
+func (s *QuestionService) Mine(ctx context.Context, userID string, limit, offset int) (*models.QuestionListResponse, error) {
+	return s.list(ctx, repository.QuestionFilter{UserID: userID}, limit, offset)
+}
+
+// List returns a page of questions for admins, optionally on one product and in one state
+func (s *QuestionService) List(ctx context.Context, productID, status string, limit, offset int) (*models.QuestionListResponse, error) {
+	if err := checkStatus(status); err != nil {
+		return nil, err
+	}
+	return s.list(ctx, repository.QuestionFilter{ProductID: productID, Status: status}, limit, offset)
+}
+
+// VendorQuestions returns a page of questions on vendorID's products, optionally in one state
+func (s *QuestionService) VendorQuestions(ctx context.Context, vendorID, status string, limit, offset int) (*models.QuestionListResponse, error) {
+	if err := checkStatus(status); err != nil {
+		return nil, err
+	}
+	return s.list(ctx, repository.QuestionFilter{VendorID: vendorID, Status: status}, limit, offset)
+}
+
+func (s *QuestionService) list(ctx context.Context, filter repository.QuestionFilter, limit, offset int) (*models.QuestionListResponse, error) {
+	questions, total, err := s.repo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if questions == nil {
+		questions = []models.ProductQuestion{}
+	}
+	for i := range questions {
+		if questions[i].Answers == nil {
+			questions[i].Answers = []models.ProductAnswer{}
+		}
+	}
+	return &models.QuestionListResponse{Questions: questions, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Get returns a question with its answers (admin only)
+func (s *QuestionService) Get(ctx context.Context, id string) (*models.ProductQuestion, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// AnswerAsStore answers a question for the store; a pending question is published with it
+func (s *QuestionService) AnswerAsStore(ctx context.Context, adminID, questionID string, req *models.AnswerRequest) (*models.ProductQuestion, error) {
+	answer := &models.ProductAnswer{
+		UserID:     adminID,
+		AuthorType: models.AnswerByStore,
+		AuthorName: s.settings.StoreName(ctx),
+	}
+	return s.answer(ctx, questionID, answer, req, true)
+}
+
+// AnswerAsVendor answers a published question on one of the vendor's products
+// Returns: repository.ErrQuestionNotFound for questions on other sellers' products
+func (s *QuestionService) AnswerAsVendor(ctx context.Context, vendor *models.Vendor, questionID string, req *models.AnswerRequest) (*models.ProductQuestion, error) {
+	question, err := s.repo.GetByID(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	product, err := s.products.GetByID(ctx, question.ProductID)
+	if errors.Is(err, repository.ErrProductNotFound) || (err == nil && product.VendorID != vendor.ID) {
+		return nil, repository.ErrQuestionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	answer := &models.ProductAnswer{
+		UserID:     vendor.UserID,
+		AuthorType: models.AnswerByVendor,
+		AuthorName: vendor.Name,
+	}
+	return s.answer(ctx, questionID, answer, req, false)
+}
+
+// answer saves answer to questionID and tells the asker
+// With publish, a pending question is published first; otherwise it must be published already
+func (s *QuestionService) answer(ctx context.Context, questionID string, answer *models.ProductAnswer,
+	req *models.AnswerRequest, publish bool) (*models.ProductQuestion, error) {
+	body, err := text("body", req.Body, maxAnswerLength)
+	if err != nil {
+		return nil, err
+	}
+	question, err := s.repo.GetByID(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+
+	answer.QuestionID = question.ID
+	answer.Body = body
+	added, err := s.repo.AddAnswer(ctx, answer, publish, answer.UserID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, ErrQuestionNotPublished
+	}
+
+	_ = events.Publish(ctx, s.publisher, s.log, events.TypeQuestionAnswered, events.QuestionAnswered{
+		QuestionID:  question.ID,
+		AnswerID:    answer.ID,
+		ProductID:   question.ProductID,
+		ProductName: s.productName(ctx, question.ProductID),
+		UserID:      question.UserID,
+		AnsweredBy:  answer.AuthorName,
+	})
+	return s.repo.GetByID(ctx, questionID)
+}
+
+// Publish shows a pending or rejected question on the product page
+func (s *QuestionService) Publish(ctx context.Context, adminID, id string) (*models.ProductQuestion, error) {
+	return s.moderate(ctx, adminID, id, models.QuestionPublished, "",
+		[]string{models.QuestionPending, models.QuestionRejected})
+}
+
+// Reject hides a pending or published question and tells the asker why
+func (s *QuestionService) Reject(ctx context.Context, adminID, id string, req *models.RejectQuestionRequest) (*models.ProductQuestion, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > maxReasonLength {
+		return nil, fmt.Errorf("%w: reason must be at most 255 characters", ErrInvalidQuestion)
+	}
+	question, err := s.moderate(ctx, adminID, id, models.QuestionRejected, reason,
+		[]string{models.QuestionPending, models.QuestionPublished})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = events.Publish(ctx, s.publisher, s.log, events.TypeQuestionRejected, events.QuestionRejected{
+		QuestionID:  question.ID,
+		ProductID:   question.ProductID,
+		ProductName: s.productName(ctx, question.ProductID),
+		UserID:      question.UserID,
+		Reason:      reason,
+	})
+	return question, nil
+}
+
+func (s *QuestionService) moderate(ctx context.Context, adminID, id, status, reason string, from []string) (*models.ProductQuestion, error) {
+	moved, err := s.repo.Moderate(ctx, id, from, status, reason, adminID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	question, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !moved {
+		return nil, ErrQuestionState
+	}
+	return question, nil
+}
+
+// Delete removes a question with its answers (admin only)
+func (s *QuestionService) Delete(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// DeleteAnswer removes one answer from a question (admin only)
+func (s *QuestionService) DeleteAnswer(ctx context.Context, questionID, answerID string) error {
+	return s.repo.DeleteAnswer(ctx, questionID, answerID)
+}
+
+// HandleQuestionAnswered tells the asker their question has an answer
+// It runs as an event consumer so a slow SMS or email provider never holds up answering,
+// and failed deliveries are retried with the event
+func (s *QuestionService) HandleQuestionAnswered(ctx context.Context, event events.Event) error {
+	var payload events.QuestionAnswered
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	return s.notifier.Notify(ctx, payload.UserID, notify.Notification{
+		Category: models.NotifyQuestions,
+		Subject:  fmt.Sprintf("Your question about %s was answered", payload.ProductName),
+		Body:     fmt.Sprintf("%s answered your question about %s. See the answer on the product page.", payload.AnsweredBy, payload.ProductName),
+	})
+}
+
+// HandleQuestionRejected tells the asker their question won't be shown
+func (s *QuestionService) HandleQuestionRejected(ctx context.Context, event events.Event) error {
+	var payload events.QuestionRejected
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Your question about %s wasn't published.", payload.ProductName)
+	if payload.Reason != "" {
+		body += " Reason: " + payload.Reason
+	}
+	return s.notifier.Notify(ctx, payload.UserID, notify.Notification{
+		Category: models.NotifyQuestions,
+		Subject:  fmt.Sprintf("Your question about %s", payload.ProductName),
+		Body:     body,
+	})
+}
+
+// HandleProductDeleted removes the questions on a deleted product
+func (s *QuestionService) HandleProductDeleted(ctx context.Context, event events.Event) error {
+	var payload events.ProductDeleted
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	removed, err := s.repo.DeleteForProduct(ctx, payload.ProductID)
+	if err == nil && removed > 0 {
+		s.log.Info("Removed questions of deleted product", zap.String("product_id", payload.ProductID), zap.Int64("count", removed))
+	}
+	return err
+}
+
+// activeProduct returns productID if customers can see it
+func (s *QuestionService) activeProduct(ctx context.Context, productID string) (*models.Product, error) {
+	product, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !product.Active {
+		return nil, repository.ErrProductNotFound
+	}
+	return product, nil
+}
+
+// productName names a product in notifications, falling back to a generic phrase
+func (s *QuestionService) productName(ctx context.Context, productID string) string {
+	product, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return "a product"
+	}
+	return product.Name
+}
+
+// displayName is how an asker is shown: first name and last initial
+func displayName(user *models.User) string {
+	name := strings.TrimSpace(user.FirstName)
+	if name == "" {
+		name = "Customer"
+	}
+	if initial := []rune(strings.TrimSpace(user.LastName)); len(initial) > 0 {
+		name += " " + string(initial[0]) + "."
+	}
+	return name
+}
+
+func checkStatus(status string) error {
+	switch status {
+	case "", models.QuestionPending, models.QuestionPublished, models.QuestionRejected:
+		return nil
+	}
+	return fmt.Errorf("%w: status must be pending, published or rejected", ErrInvalidQuestion)
+}
+
+// text trims and checks a required free-text field
+func text(field, value string, max int) (string, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return "", fmt.Errorf("%w: %s is required", ErrInvalidQuestion, field)
+	case len(value) > max:
+		return "", fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidQuestion, field, max)
+	}
+	return value, nil
+}
@@ -0,0 +1,45 @@
+package quote
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Module provides B2B requests for quote: business customers ask for prices, admins adjust and
+// approve them, and approved quotes are ordered at the locked prices
+type Module struct {
+	handler *Handler
+}
+
+func NewModule(deps module.Deps) *Module {
+	service := NewQuoteService(repository.NewQuoteRepository(deps.DB, deps.Log),
+		repository.NewProductRepository(deps.DB, deps.Log), deps.Groups, deps.OrderNumbers, deps.Settings,
+		deps.Events, deps.Notifier, deps.Clock, deps.IDs, deps.Log)
+
+	if err := deps.Events.Subscribe(events.TypeQuoteReviewed, "quotes", service.HandleQuoteReviewed); err != nil {
+		deps.Log.Error("Failed to subscribe quotes to event", zap.String("type", events.TypeQuoteReviewed), zap.Error(err))
+	}
+
+	return &Module{
+		handler: NewHandler(service, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.Quote{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
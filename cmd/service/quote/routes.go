@@ -0,0 +1,315 @@
+package quote
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *QuoteService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *QuoteService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers quote routes
+// Business customers ask for and order their own quotes; pricing and approval are admin-only
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	quotes := router.PathPrefix("/quotes").Subrouter()
+	quotes.Use(auth.Authenticate(h.tokens))
+	quotes.HandleFunc("", h.handleRequest).Methods("POST")
+	quotes.HandleFunc("/{id}", h.handleGet).Methods("GET")
+	quotes.HandleFunc("/{id}/cancel", h.handleCancel).Methods("POST")
+	quotes.HandleFunc("/{id}/order", h.handleOrder).Methods("POST")
+
+	me := router.PathPrefix("/users/me/quotes").Subrouter()
+	me.Use(auth.Authenticate(h.tokens))
+	me.HandleFunc("", h.handleMine).Methods("GET")
+
+	admin := router.PathPrefix("/admin/quotes").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("", h.handleList).Methods("GET")
+	admin.HandleFunc("/{id}", h.handleAdminGet).Methods("GET")
+	admin.HandleFunc("/{id}", h.handleSetPrices).Methods("PATCH")
+	admin.HandleFunc("/{id}/approve", h.handleApprove).Methods("POST")
+	admin.HandleFunc("/{id}/reject", h.handleReject).Methods("POST")
+}
+
+// handleRequest handles POST /api/v1/quotes
+// @Summary Request a quote
+// @Description Asks the store for prices on a set of products. Lines start at the caller's customer group price; an admin adjusts and approves them. Only customers in a group other than retail can ask.
+// @Tags Quotes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.QuoteRequest true "Products and quantities"
+// @Success 201 {object} models.Quote
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Quotes are for business customers"
+// @Failure 404 {string} string "Product not found"
+// @Router /quotes [post]
+func (h *Handler) handleRequest(w http.ResponseWriter, r *http.Request) {
+	var req models.QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	quote, err := h.service.Request(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, quote)
+}
+
+// handleGet handles GET /api/v1/quotes/{id}
+// @Summary Get own quote
+// @Tags Quotes
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Quote ID"
+// @Success 200 {object} models.Quote
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Quote not found"
+// @Router /quotes/{id} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	quote, err := h.service.Get(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, quote)
+}
+
+// handleCancel handles POST /api/v1/quotes/{id}/cancel
+// @Summary Cancel own quote
+// @Description Withdraws a quote that is waiting for review or approved but not yet ordered
+// @Tags Quotes
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Quote ID"
+// @Success 200 {object} models.Quote
+// @Failure 404 {string} string "Quote not found"
+// @Failure 409 {string} string "Quote can't be changed in its current state"
+// @Router /quotes/{id}/cancel [post]
+func (h *Handler) handleCancel(w http.ResponseWriter, r *http.Request) {
+	quote, err := h.service.Cancel(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, quote)
+}
+
+// handleOrder handles POST /api/v1/quotes/{id}/order
+// @Summary Order an approved quote
+// @Description Places an order for the quote's lines at the quoted prices. The quote must be approved and not past valid_until; the response carries the new order_id and order_number.
+// @Tags Quotes
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Quote ID"
+// @Success 200 {object} models.Quote
+// @Failure 404 {string} string "Quote not found"
+// @Failure 409 {string} string "Quote can't be changed in its current state, or has expired"
+// @Router /quotes/{id}/order [post]
+func (h *Handler) handleOrder(w http.ResponseWriter, r *http.Request) {
+	quote, err := h.service.Order(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, quote)
+}
+
+// handleMine handles GET /api/v1/users/me/quotes
+// @Summary List own quotes
+// @Description The caller's quotes in any state, newest first
+// @Tags Quotes
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.QuoteListResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Router /users/me/quotes [get]
+func (h *Handler) handleMine(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	resp, err := h.service.Mine(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleList handles GET /api/v1/admin/quotes
+// @Summary List quotes
+// @Description Quotes from every customer, newest first. Use status=requested for the review queue.
+// @Tags Quotes
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "requested, approved, rejected, ordered or cancelled"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.QuoteListResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/quotes [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	resp, err := h.service.List(r.Context(), r.URL.Query().Get("status"), limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleAdminGet handles GET /api/v1/admin/quotes/{id}
+// @Summary Get quote
+// @Tags Quotes
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Quote ID"
+// @Success 200 {object} models.Quote
+// @Failure 404 {string} string "Quote not found"
+// @Router /admin/quotes/{id} [get]
+func (h *Handler) handleAdminGet(w http.ResponseWriter, r *http.Request) {
+	quote, err := h.service.GetAny(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, quote)
+}
+
+// handleSetPrices handles PATCH /api/v1/admin/quotes/{id}
+// @Summary Adjust quoted prices
+// @Description Sets unit prices of a quote waiting for review, and optionally the message to the customer
+// @Tags Quotes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Quote ID"
+// @Param request body models.QuotePricesRequest true "Prices"
+// @Success 200 {object} models.Quote
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Quote not found"
+// @Failure 409 {string} string "Quote can't be changed in its current state"
+// @Router /admin/quotes/{id} [patch]
+func (h *Handler) handleSetPrices(w http.ResponseWriter, r *http.Request) {
+	var req models.QuotePricesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	quote, err := h.service.SetPrices(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, quote)
+}
+
+// handleApprove handles POST /api/v1/admin/quotes/{id}/approve
+// @Summary Approve quote
+// @Description Offers the quote to the customer at its current prices until valid_until (default: quote_validity_days from now). The customer is notified.
+// @Tags Quotes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Quote ID"
+// @Param request body models.ApproveQuoteRequest false "Validity and message"
+// @Success 200 {object} models.Quote
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Quote not found"
+// @Failure 409 {string} string "Quote can't be changed in its current state"
+// @Router /admin/quotes/{id}/approve [post]
+func (h *Handler) handleApprove(w http.ResponseWriter, r *http.Request) {
+	var req models.ApproveQuoteRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	quote, err := h.service.Approve(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, quote)
+}
+
+// handleReject handles POST /api/v1/admin/quotes/{id}/reject
+// @Summary Reject quote
+// @Tags Quotes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Quote ID"
+// @Param request body models.RejectQuoteRequest false "Reason"
+// @Success 200 {object} models.Quote
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Quote not found"
+// @Failure 409 {string} string "Quote can't be changed in its current state"
+// @Router /admin/quotes/{id}/reject [post]
+func (h *Handler) handleReject(w http.ResponseWriter, r *http.Request) {
+	var req models.RejectQuoteRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	quote, err := h.service.Reject(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, quote)
+}
+
+// writeError maps quote errors to 400/403/404/409/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidQuote):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotBusinessCustomer):
+		http.Error(w, "Quotes are for business customers", http.StatusForbidden)
+	case errors.Is(err, repository.ErrQuoteNotFound):
+		http.Error(w, "Quote not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, ErrQuoteExpired):
+		http.Error(w, "Quote has expired", http.StatusConflict)
+	case errors.Is(err, ErrQuoteState):
+		http.Error(w, "Quote can't be changed in its current state", http.StatusConflict)
+	default:
+		h.log.Error("Quote request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,404 @@
+package quote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/customergroup"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/notify"
+	"github.com/Jason-Omondi/ecomgo/internal/ordernumber"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	maxQuoteLines     = 100
+	maxNoteLength     = 2000
+	maxResponseLength = 1000
+)
+
+var (
+	// ErrInvalidQuote wraps validation problems with quote requests and reviews
+	ErrInvalidQuote = errors.New("invalid quote")
+	// ErrNotBusinessCustomer is returned when a retail customer asks for a quote
+	ErrNotBusinessCustomer = errors.New("quotes are for business customers")
+	// ErrQuoteState is returned when a quote can't make the requested move from its current state
+	ErrQuoteState = errors.New("quote can't be changed in its current state")
+	// ErrQuoteExpired is returned when ordering an approved quote past its valid_until
+	ErrQuoteExpired = errors.New("quote has expired")
+)
+
+// QuoteService runs B2B requests for quote: a business customer asks for prices on a set of
+// products, an admin adjusts them and approves, and the customer orders the quote at exactly the
+// approved prices while it is valid. Only customers in a customer group other than retail can ask.
+type QuoteService struct {
+	repo      *repository.QuoteRepository
+	products  repository.ProductStore
+	groups    *customergroup.Pricing
+	numbers   *ordernumber.Generator
+	settings  *settings.Store
+	publisher events.Publisher
+	notifier  *notify.Notifier
+	clock     clock.Clock
+	ids       clock.IDGenerator
+	log       *zap.Logger
+}
+
+func NewQuoteService(repo *repository.QuoteRepository, products repository.ProductStore, groups *customergroup.Pricing,
+	numbers *ordernumber.Generator, storeSettings *settings.Store, publisher events.Publisher, notifier *notify.Notifier,
+	clk clock.Clock, ids clock.IDGenerator, log *zap.Logger) *QuoteService {
+	return &QuoteService{
+		repo:      repo,
+		products:  products,
+		groups:    groups,
+		numbers:   numbers,
+		settings:  storeSettings,
+		publisher: publisher,
+		notifier:  notifier,
+		clock:     clk,
+		ids:       ids,
+		log:       log,
+	}
+}
+
+// Request records userID's request for a quote on req.Items
+// Lines start at the customer's group price; products must be active and meet their quantity rules
+func (s *QuoteService) Request(ctx context.Context, userID string, req *models.QuoteRequest) (*models.Quote, error) {
+	group := s.groups.ForUser(ctx, userID)
+	if group.Group == models.GroupRetail {
+		return nil, ErrNotBusinessCustomer
+	}
+
+	note := strings.TrimSpace(req.Note)
+	if len(note) > maxNoteLength {
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalidQuote, maxNoteLength)
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("%w: a quote needs at least one item", ErrInvalidQuote)
+	}
+
+	quantities := make(map[string]int, len(req.Items))
+	var order []string
+	for _, item := range req.Items {
+		if item.ProductID == "" || item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: every item needs a product_id and a positive quantity", ErrInvalidQuote)
+		}
+		if _, ok := quantities[item.ProductID]; !ok {
+			order = append(order, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+	maxLines := s.settings.MaxOrderLines(ctx)
+	if len(order) > maxQuoteLines || (maxLines > 0 && len(order) > maxLines) {
+		return nil, fmt.Errorf("%w: too many products in one quote", ErrInvalidQuote)
+	}
+
+	quote := &models.Quote{
+		UserID:        userID,
+		Status:        models.QuoteRequested,
+		CustomerGroup: group.Group,
+		TaxTreatment:  group.TaxTreatment,
+		VATID:         group.VATID,
+		Note:          note,
+		Lines:         make([]models.QuoteLine, 0, len(order)),
+	}
+	for _, id := range order {
+		product, err := s.products.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !product.Active {
+			return nil, repository.ErrProductNotFound
+		}
+		if problem := product.QuantityProblem(quantities[id]); problem != "" {
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalidQuote, product.SKU, problem)
+		}
+		switch {
+		case quote.Currency == "":
+			quote.Currency = product.Currency
+		case quote.Currency != product.Currency:
+			return nil, fmt.Errorf("%w: products are priced in %s and %s; ask for separate quotes", ErrInvalidQuote, quote.Currency, product.Currency)
+		}
+
+		price := group.Price(id, product.Price)
+		quote.Lines = append(quote.Lines, models.QuoteLine{
+			ProductID: id,
+			SKU:       product.SKU,
+			Name:      product.Name,
+			Quantity:  quantities[id],
+			ListPrice: price,
+			UnitPrice: price,
+		})
+	}
+	total(quote)
+
+	if err := s.repo.Create(ctx, quote); err != nil {
+		return nil, err
+	}
+	s.log.Info("Quote requested", zap.String("quote_id", quote.ID), zap.String("user_id", userID), zap.Int("lines", len(quote.Lines)))
+	return quote, nil
+}
+
+// Get returns one of userID's quotes
+func (s *QuoteService) Get(ctx context.Context, userID, id string) (*models.Quote, error) {
+	quote, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if quote.UserID != userID {
+		return nil, repository.ErrQuoteNotFound
+	}
+	return quote, nil
+}
+
+// GetAny returns a quote whoever asked for it (admin only)
+func (s *QuoteService) GetAny(ctx context.Context, id string) (*models.Quote, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Mine returns a page of userID's quotes in any state
+func (s *QuoteService) Mine(ctx context.Context, userID string, limit, offset int) (*models.QuoteListResponse, error) {
+	return s.list(ctx, repository.QuoteFilter{UserID: userID}, limit, offset)
+}
+
+// List returns a page of quotes for admins, optionally in one state
+func (s *QuoteService) List(ctx context.Context, status string, limit, offset int) (*models.QuoteListResponse, error) {
+	switch status {
+	case "", models.QuoteRequested, models.QuoteApproved, models.QuoteRejected, models.QuoteOrdered, models.QuoteCancelled:
+	default:
+		return nil, fmt.Errorf("%w: status must be requested, approved, rejected, ordered or cancelled", ErrInvalidQuote)
+	}
+	return s.list(ctx, repository.QuoteFilter{Status: status}, limit, offset)
+}
+
+func (s *QuoteService) list(ctx context.Context, filter repository.QuoteFilter, limit, offset int) (*models.QuoteListResponse, error) {
+	quotes, total, err := s.repo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if quotes == nil {
+		quotes = []models.Quote{}
+	}
+	return &models.QuoteListResponse{Quotes: quotes, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// SetPrices changes quoted unit prices while the quote waits for review (admin only)
+func (s *QuoteService) SetPrices(ctx context.Context, id string, req *models.QuotePricesRequest) (*models.Quote, error) {
+	quote, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Status != models.QuoteRequested {
+		return nil, ErrQuoteState
+	}
+
+	lines := make(map[string]*models.QuoteLine, len(quote.Lines))
+	for i := range quote.Lines {
+		lines[quote.Lines[i].ProductID] = &quote.Lines[i]
+	}
+	for _, price := range req.Lines {
+		line, ok := lines[price.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("%w: product %s is not on the quote", ErrInvalidQuote, price.ProductID)
+		}
+		if price.UnitPrice < 0 {
+			return nil, fmt.Errorf("%w: unit_price cannot be negative", ErrInvalidQuote)
+		}
+		line.UnitPrice = price.UnitPrice
+	}
+	if req.Response != nil {
+		response, err := optionalText("response", *req.Response, maxResponseLength)
+		if err != nil {
+			return nil, err
+		}
+		quote.Response = response
+	}
+	total(quote)
+
+	saved, err := s.repo.SetLines(ctx, quote)
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, ErrQuoteState
+	}
+	return quote, nil
+}
+
+// Approve offers a quote to its customer at its current prices until req.ValidUntil (admin only)
+func (s *QuoteService) Approve(ctx context.Context, adminID, id string, req *models.ApproveQuoteRequest) (*models.Quote, error) {
+	response, err := optionalText("response", req.Response, maxResponseLength)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	validUntil := now.AddDate(0, 0, s.settings.QuoteValidityDays(ctx))
+	if req.ValidUntil != nil {
+		if !req.ValidUntil.After(now) {
+			return nil, fmt.Errorf("%w: valid_until must be in the future", ErrInvalidQuote)
+		}
+		validUntil = req.ValidUntil.UTC()
+	}
+
+	fields := map[string]interface{}{"status": models.QuoteApproved, "valid_until": validUntil, "reviewed_by": adminID, "reviewed_at": now}
+	if response != "" {
+		fields["response"] = response
+	}
+	return s.review(ctx, id, fields)
+}
+
+// Reject turns a quote down (admin only); the reason is told to the customer
+func (s *QuoteService) Reject(ctx context.Context, adminID, id string, req *models.RejectQuoteRequest) (*models.Quote, error) {
+	reason, err := optionalText("reason", req.Reason, maxResponseLength)
+	if err != nil {
+		return nil, err
+	}
+	return s.review(ctx, id, map[string]interface{}{
+		"status": models.QuoteRejected, "response": reason, "reviewed_by": adminID, "reviewed_at": s.clock.Now(),
+	})
+}
+
+func (s *QuoteService) review(ctx context.Context, id string, fields map[string]interface{}) (*models.Quote, error) {
+	moved, err := s.repo.Update(ctx, id, []string{models.QuoteRequested}, fields)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !moved {
+		return nil, ErrQuoteState
+	}
+
+	s.log.Info("Quote reviewed", zap.String("quote_id", quote.ID), zap.String("status", quote.Status))
+	_ = events.Publish(ctx, s.publisher, s.log, events.TypeQuoteReviewed, events.QuoteReviewed{
+		QuoteID:    quote.ID,
+		UserID:     quote.UserID,
+		Status:     quote.Status,
+		Subtotal:   quote.Subtotal,
+		Currency:   quote.Currency,
+		ValidUntil: quote.ValidUntil,
+		Response:   quote.Response,
+	})
+	return quote, nil
+}
+
+// Cancel withdraws one of userID's quotes that hasn't been ordered or rejected
+func (s *QuoteService) Cancel(ctx context.Context, userID, id string) (*models.Quote, error) {
+	if _, err := s.Get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	moved, err := s.repo.Update(ctx, id, []string{models.QuoteRequested, models.QuoteApproved},
+		map[string]interface{}{"status": models.QuoteCancelled})
+	if err != nil {
+		return nil, err
+	}
+	quote, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !moved {
+		return nil, ErrQuoteState
+	}
+	return quote, nil
+}
+
+// Order turns one of userID's approved quotes into an order at the quoted prices
+// The order is placed like checkout places one: a number from the order sequence, then
+// order.placed with the quote's lines, so fulfilment, vendors and webhooks pick it up as usual.
+// Quoted prices are final; campaigns, price changes and price tiers don't touch them.
+func (s *QuoteService) Order(ctx context.Context, userID, id string) (*models.Quote, error) {
+	now := s.clock.Now()
+	orderID := s.ids.NewID()
+	orderNumber, err := s.repo.MarkOrdered(ctx, id, userID, orderID, now, func(tx *gorm.DB) (string, error) {
+		return s.numbers.NextTx(ctx, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if orderNumber == "" {
+		if quote.Status == models.QuoteApproved && quote.ValidUntil != nil && !quote.ValidUntil.After(now) {
+			return nil, ErrQuoteExpired
+		}
+		return nil, ErrQuoteState
+	}
+
+	items := make([]events.OrderItem, 0, len(quote.Lines))
+	for _, line := range quote.Lines {
+		items = append(items, events.OrderItem{ProductID: line.ProductID, Quantity: line.Quantity, UnitPrice: line.UnitPrice})
+	}
+	s.log.Info("Quote ordered", zap.String("quote_id", quote.ID), zap.String("order_id", orderID), zap.String("order_number", orderNumber))
+	_ = events.Publish(ctx, s.publisher, s.log, events.TypeOrderPlaced, events.OrderPlaced{
+		OrderID:      orderID,
+		OrderNumber:  orderNumber,
+		UserID:       userID,
+		Total:        quote.Subtotal,
+		Currency:     quote.Currency,
+		Items:        items,
+		QuoteID:      quote.ID,
+		TaxTreatment: quote.TaxTreatment,
+		VATID:        quote.VATID,
+	})
+	return quote, nil
+}
+
+// HandleQuoteReviewed tells the customer their quote was approved or rejected
+func (s *QuoteService) HandleQuoteReviewed(ctx context.Context, event events.Event) error {
+	var payload events.QuoteReviewed
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	notification := notify.Notification{Category: models.NotifyOrderUpdates}
+	switch payload.Status {
+	case models.QuoteApproved:
+		notification.Subject = "Your quote is ready"
+		notification.Body = fmt.Sprintf("Your quote for %s %d.%02d is approved. Order it by %s to get these prices.",
+			payload.Currency, payload.Subtotal/100, payload.Subtotal%100, payload.ValidUntil.Format(time.DateOnly))
+		if payload.Response != "" {
+			notification.Body += " " + payload.Response
+		}
+	case models.QuoteRejected:
+		notification.Subject = "Your quote request"
+		notification.Body = "We couldn't offer a quote for your request."
+		if payload.Response != "" {
+			notification.Body += " Reason: " + payload.Response
+		}
+	default:
+		return nil
+	}
+	return s.notifier.Notify(ctx, payload.UserID, notification)
+}
+
+// total recomputes the line totals and subtotal of a quote
+func total(quote *models.Quote) {
+	quote.Subtotal = 0
+	for i := range quote.Lines {
+		line := &quote.Lines[i]
+		line.LineTotal = line.UnitPrice * int64(line.Quantity)
+		quote.Subtotal += line.LineTotal
+	}
+}
+
+// optionalText trims a free-text field and checks its length
+func optionalText(field, value string, max int) (string, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > max {
+		return "", fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidQuote, field, max)
+	}
+	return value, nil
+}
@@ -0,0 +1,50 @@
+package referral
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Module provides the referral program: per-customer codes and links, signups and first
+// orders attributed from user and order events, and rewards set in the store settings
+type Module struct {
+	handler *Handler
+}
+
+func NewModule(deps module.Deps) *Module {
+	service := NewReferralService(repository.NewReferralRepository(deps.DB, deps.Log), deps.Settings,
+		deps.Config.Referrals.LinkURL, deps.Clock, deps.Log)
+
+	handlers := map[string]events.Handler{
+		events.TypeUserRegistered: service.HandleUserRegistered,
+		events.TypeOrderPlaced:    service.HandleOrderPlaced,
+	}
+	for eventType, handler := range handlers {
+		if err := deps.Events.Subscribe(eventType, "referrals", handler); err != nil {
+			deps.Log.Error("Failed to subscribe referrals to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+
+	return &Module{
+		handler: NewHandler(service, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.ReferralCode{}, &models.Referral{}, &models.ReferralReward{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
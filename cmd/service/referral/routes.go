@@ -0,0 +1,163 @@
+package referral
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *ReferralService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *ReferralService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers referral routes
+// Code lookups are public for the signup page; own stats need a sign-in; program stats are admin-only
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/referrals/codes/{code}", h.handleLookup).Methods("GET")
+
+	mine := router.PathPrefix("/referrals/me").Subrouter()
+	mine.Use(auth.Authenticate(h.tokens))
+	mine.HandleFunc("", h.handleSummary).Methods("GET")
+	mine.HandleFunc("/referrals", h.handleReferred).Methods("GET")
+
+	admin := router.PathPrefix("/admin/referrals").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("", h.handleList).Methods("GET")
+	admin.HandleFunc("/stats", h.handleStats).Methods("GET")
+}
+
+// handleLookup handles GET /api/v1/referrals/codes/{code}
+// @Summary Check referral code
+// @Description Confirms a code before signup and names who shared it. Codes are case-insensitive.
+// @Tags Referrals
+// @Produce json
+// @Param code path string true "Referral code"
+// @Success 200 {object} models.ReferralCodeResponse
+// @Failure 404 {string} string "Referral code not found"
+// @Router /referrals/codes/{code} [get]
+func (h *Handler) handleLookup(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.Lookup(r.Context(), mux.Vars(r)["code"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleSummary handles GET /api/v1/referrals/me
+// @Summary Own referral code and stats
+// @Description The caller's code and signup link (created on first use), how many customers signed up and ordered with it, and the rewards earned
+// @Tags Referrals
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.ReferralSummaryResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /referrals/me [get]
+func (h *Handler) handleSummary(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.Summary(r.Context(), auth.ClaimsFromContext(r.Context()).UserID())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleReferred handles GET /api/v1/referrals/me/referrals
+// @Summary List own referrals
+// @Description Customers who signed up with the caller's code, newest first, by first name and last initial
+// @Tags Referrals
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.ReferredCustomerListResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /referrals/me/referrals [get]
+func (h *Handler) handleReferred(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	resp, err := h.service.Referred(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleList handles GET /api/v1/admin/referrals
+// @Summary List referrals
+// @Tags Referrals
+// @Produce json
+// @Security BearerAuth
+// @Param referrer_id query string false "Only this referrer's referrals"
+// @Param status query string false "signed_up or ordered"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.ReferralListResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/referrals [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	query := r.URL.Query()
+
+	resp, err := h.service.List(r.Context(), query.Get("referrer_id"), query.Get("status"), limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleStats handles GET /api/v1/admin/referrals/stats
+// @Summary Referral program stats
+// @Description Signups and first orders from referrals, rewards granted, and the top referrers
+// @Tags Referrals
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.ReferralStatsResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/referrals/stats [get]
+func (h *Handler) handleStats(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.Stats(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// writeError maps referral errors to 400/404/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidReferral):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrReferralCodeNotFound):
+		http.Error(w, "Referral code not found", http.StatusNotFound)
+	default:
+		h.log.Error("Referral request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,285 @@
+package referral
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"go.uber.org/zap"
+)
+
+const (
+	// codeAlphabet leaves out 0/O and 1/I so codes survive being read aloud or retyped
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	codeLength   = 8
+	// codeAttempts bounds retries after a generated code turns out to be taken
+	codeAttempts = 5
+	// topReferrers is how many referrers the program stats rank
+	topReferrers = 10
+)
+
+// ErrInvalidReferral wraps validation problems with referral requests
+var ErrInvalidReferral = errors.New("invalid referral request")
+
+// ReferralService runs the referral program: a code per customer, signups and first orders
+// attributed to the code's owner, and rewards granted as the store settings say
+// Attribution follows user and order events, so registration and checkout never wait on it
+type ReferralService struct {
+	repo     *repository.ReferralRepository
+	settings *settings.Store
+	linkURL  string
+	clock    clock.Clock
+	log      *zap.Logger
+}
+
+func NewReferralService(repo *repository.ReferralRepository, storeSettings *settings.Store, linkURL string,
+	clk clock.Clock, log *zap.Logger) *ReferralService {
+	return &ReferralService{
+		repo:     repo,
+		settings: storeSettings,
+		linkURL:  linkURL,
+		clock:    clk,
+		log:      log,
+	}
+}
+
+// Summary returns userID's code and link, creating the code on first use, with what it earned
+func (s *ReferralService) Summary(ctx context.Context, userID string) (*models.ReferralSummaryResponse, error) {
+	code, err := s.ensureCode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	signups, ordered, err := s.repo.Counts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	points, credit, err := s.repo.Earned(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ReferralSummaryResponse{
+		Code:        code,
+		Link:        s.link(code),
+		Signups:     signups,
+		FirstOrders: ordered,
+		Points:      points,
+		Credit:      credit,
+	}, nil
+}
+
+// Lookup checks a code for the signup page
+// Returns: repository.ErrReferralCodeNotFound for unknown codes
+func (s *ReferralService) Lookup(ctx context.Context, code string) (*models.ReferralCodeResponse, error) {
+	owner, firstName, err := s.repo.GetCode(ctx, normalizeCode(code))
+	if err != nil {
+		return nil, err
+	}
+	return &models.ReferralCodeResponse{Code: owner.Code, Referrer: firstName}, nil
+}
+
+// Referred returns a page of the customers userID brought in
+func (s *ReferralService) Referred(ctx context.Context, userID string, limit, offset int) (*models.ReferredCustomerListResponse, error) {
+	referred, total, err := s.repo.Referred(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ReferredCustomerListResponse{Referrals: referred, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// List returns a page of referrals for admins, optionally of one referrer and in one state
+func (s *ReferralService) List(ctx context.Context, referrerID, status string, limit, offset int) (*models.ReferralListResponse, error) {
+	if status != "" && status != models.ReferralSignedUp && status != models.ReferralOrdered {
+		return nil, fmt.Errorf("%w: status must be signed_up or ordered", ErrInvalidReferral)
+	}
+	referrals, total, err := s.repo.List(ctx, referrerID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if referrals == nil {
+		referrals = []models.Referral{}
+	}
+	return &models.ReferralListResponse{Referrals: referrals, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Stats sums up the program for admins
+func (s *ReferralService) Stats(ctx context.Context) (*models.ReferralStatsResponse, error) {
+	return s.repo.Stats(ctx, topReferrers)
+}
+
+// HandleUserRegistered gives the new customer a code and attributes the signup to the owner of
+// the code they signed up with, if any
+func (s *ReferralService) HandleUserRegistered(ctx context.Context, event events.Event) error {
+	var payload events.UserRegistered
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	if payload.UserID == "" {
+		return nil
+	}
+	if _, err := s.ensureCode(ctx, payload.UserID); err != nil {
+		return err
+	}
+
+	code := normalizeCode(payload.ReferralCode)
+	if code == "" {
+		return nil
+	}
+	owner, _, err := s.repo.GetCode(ctx, code)
+	if errors.Is(err, repository.ErrReferralCodeNotFound) {
+		s.log.Info("Ignoring unknown referral code", zap.String("user_id", payload.UserID), zap.String("code", code))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if owner.UserID == payload.UserID {
+		return nil
+	}
+
+	signedUpAt := event.OccurredAt
+	if signedUpAt.IsZero() {
+		signedUpAt = s.clock.Now()
+	}
+	referral := &models.Referral{
+		ReferrerID: owner.UserID,
+		RefereeID:  payload.UserID,
+		Code:       owner.Code,
+		Status:     models.ReferralSignedUp,
+		SignedUpAt: signedUpAt.UTC(),
+	}
+	rewards := s.rewards(ctx, referral, []reward{{owner.UserID, models.ReferralRewardSignup, settings.KeyReferralSignupReward}})
+	created, err := s.repo.Attribute(ctx, referral, rewards)
+	if err == nil && created {
+		s.log.Info("Referral signup recorded", zap.String("referrer_id", owner.UserID), zap.String("referee_id", payload.UserID))
+	}
+	return err
+}
+
+// HandleOrderPlaced rewards a referral's first order, once
+func (s *ReferralService) HandleOrderPlaced(ctx context.Context, event events.Event) error {
+	var payload events.OrderPlaced
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	if payload.UserID == "" || payload.OrderID == "" {
+		return nil
+	}
+	referral, err := s.repo.GetByReferee(ctx, payload.UserID)
+	if errors.Is(err, repository.ErrReferralNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if referral.Status != models.ReferralSignedUp {
+		return nil
+	}
+
+	orderedAt := event.OccurredAt
+	if orderedAt.IsZero() {
+		orderedAt = s.clock.Now()
+	}
+	rewards := s.rewards(ctx, referral, []reward{
+		{referral.ReferrerID, models.ReferralRewardOrder, settings.KeyReferralOrderReward},
+		{referral.RefereeID, models.ReferralRewardWelcome, settings.KeyReferralWelcome},
+	})
+	qualified, err := s.repo.Qualify(ctx, referral, payload.OrderID, orderedAt.UTC(), rewards)
+	if err == nil && qualified {
+		s.log.Info("Referral first order recorded", zap.String("referral_id", referral.ID), zap.String("order_id", payload.OrderID))
+	}
+	return err
+}
+
+// reward names who gets a reward, why, and the setting holding its amount
+type reward struct {
+	userID string
+	reason string
+	key    string
+}
+
+// rewards prices the given rewards at the current settings; zero amounts are left out
+func (s *ReferralService) rewards(ctx context.Context, referral *models.Referral, wanted []reward) []models.ReferralReward {
+	rewardType := s.settings.ReferralRewardType(ctx)
+	currency := ""
+	if rewardType == settings.RewardCredit {
+		currency = s.settings.DefaultCurrency(ctx)
+	}
+
+	var granted []models.ReferralReward
+	for _, w := range wanted {
+		amount := s.settings.Int(ctx, w.key)
+		if amount <= 0 {
+			continue
+		}
+		granted = append(granted, models.ReferralReward{
+			UserID:     w.userID,
+			ReferralID: referral.ID,
+			Reason:     w.reason,
+			Type:       rewardType,
+			Amount:     amount,
+			Currency:   currency,
+		})
+	}
+	return granted
+}
+
+// ensureCode returns userID's code, generating one if the user has none
+func (s *ReferralService) ensureCode(ctx context.Context, userID string) (string, error) {
+	for attempt := 0; attempt < codeAttempts; attempt++ {
+		existing, err := s.repo.CodeOf(ctx, userID)
+		if err == nil {
+			return existing.Code, nil
+		}
+		if !errors.Is(err, repository.ErrReferralCodeNotFound) {
+			return "", err
+		}
+
+		code, err := generateCode()
+		if err != nil {
+			return "", err
+		}
+		// Not created means a concurrent request gave the user a code, or the code is
+		// taken; the next attempt tells which
+		created, err := s.repo.CreateCode(ctx, &models.ReferralCode{UserID: userID, Code: code})
+		if err != nil {
+			return "", err
+		}
+		if created {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("no free referral code after %d attempts", codeAttempts)
+}
+
+// link is the signup URL carrying code, or "" when REFERRAL_LINK_URL is unset
+func (s *ReferralService) link(code string) string {
+	if s.linkURL == "" {
+		return ""
+	}
+	return strings.ReplaceAll(s.linkURL, "{code}", code)
+}
+
+// generateCode returns a random code of codeLength characters from codeAlphabet
+func generateCode() (string, error) {
+	buf := make([]byte, codeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	// len(codeAlphabet) divides 256, so every character is equally likely
+	for i, b := range buf {
+		buf[i] = codeAlphabet[int(b)%len(codeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// normalizeCode makes codes case-insensitive and tolerant of surrounding spaces
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,65 @@
+package segment
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/lock"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Module provides customer segments: rules over spend, order recency and location, with
+// membership materialized for targeted campaigns (campaign module) and email campaigns
+// Order and refund events feed the customer order ledger the rules are evaluated over
+type Module struct {
+	handler   *Handler
+	scheduler lock.Service // periodic refresh on the elected leader only; nil when SEGMENT_REFRESH_INTERVAL=0
+}
+
+func NewModule(deps module.Deps) *Module {
+	service := NewSegmentService(repository.NewSegmentRepository(deps.DB, deps.Log), deps.Settings,
+		deps.Notifier, deps.Jobs, deps.Clock, deps.Log)
+
+	deps.Jobs.Register(JobRefresh, service.handleRefreshJob)
+	deps.Jobs.Register(JobSendEmail, service.handleSendEmailJob)
+
+	handlers := map[string]events.Handler{
+		events.TypeOrderPlaced:  service.HandleOrderPlaced,
+		events.TypeRefundIssued: service.HandleRefundIssued,
+	}
+	for eventType, handler := range handlers {
+		if err := deps.Events.Subscribe(eventType, "segments", handler); err != nil {
+			deps.Log.Error("Failed to subscribe segments to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+
+	m := &Module{
+		handler: NewHandler(service, deps.Tokens, deps.Log),
+	}
+	if interval := deps.Config.Segments.RefreshInterval; interval > 0 {
+		m.scheduler = lock.Singleton(deps.Locks, NewScheduler(deps.Jobs, interval, deps.Log), deps.Config.Locks.LeaderTTL, deps.Log)
+	}
+	return m
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.Segment{}, &models.SegmentMember{}, &models.CustomerOrder{},
+			&models.CustomerRefund{}, &models.SegmentEmail{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+// Services returns the refresh scheduler when periodic refreshes are enabled
+func (m *Module) Services() []module.Service {
+	if m.scheduler == nil {
+		return nil
+	}
+	return []module.Service{m.scheduler}
+}
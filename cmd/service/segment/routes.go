@@ -0,0 +1,302 @@
+package segment
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *SegmentService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *SegmentService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers segment routes (admin only)
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/segments").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("", h.handleCreate).Methods("POST")
+	admin.HandleFunc("", h.handleList).Methods("GET")
+	admin.HandleFunc("/preview", h.handlePreview).Methods("POST")
+	admin.HandleFunc("/{id}", h.handleGet).Methods("GET")
+	admin.HandleFunc("/{id}", h.handleUpdate).Methods("PUT")
+	admin.HandleFunc("/{id}", h.handleDelete).Methods("DELETE")
+	admin.HandleFunc("/{id}/refresh", h.handleRefresh).Methods("POST")
+	admin.HandleFunc("/{id}/members", h.handleMembers).Methods("GET")
+	admin.HandleFunc("/{id}/emails", h.handleSendEmail).Methods("POST")
+	admin.HandleFunc("/{id}/emails", h.handleEmails).Methods("GET")
+}
+
+// handleCreate handles POST /api/v1/admin/segments
+// @Summary Create segment
+// @Description Defines a customer segment by rules that must all match, and computes its members at once
+// @Tags Segments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.SegmentRequest true "Segment"
+// @Success 201 {object} models.Segment
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 409 {string} string "Segment name already in use"
+// @Router /admin/segments [post]
+func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req models.SegmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	segment, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, segment)
+}
+
+// handleList handles GET /api/v1/admin/segments
+// @Summary List segments
+// @Tags Segments
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.SegmentListResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/segments [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	resp, err := h.service.List(r.Context(), limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handlePreview handles POST /api/v1/admin/segments/preview
+// @Summary Preview segment rules
+// @Description Counts the customers the rules match now without saving a segment
+// @Tags Segments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.SegmentPreviewRequest true "Rules"
+// @Success 200 {object} models.SegmentPreviewResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/segments/preview [post]
+func (h *Handler) handlePreview(w http.ResponseWriter, r *http.Request) {
+	var req models.SegmentPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.Preview(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleGet handles GET /api/v1/admin/segments/{id}
+// @Summary Get segment
+// @Tags Segments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Segment ID"
+// @Success 200 {object} models.Segment
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Segment not found"
+// @Router /admin/segments/{id} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	segment, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, segment)
+}
+
+// handleUpdate handles PUT /api/v1/admin/segments/{id}
+// @Summary Update segment
+// @Description Replaces the name, description and rules, and recomputes the members
+// @Tags Segments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Segment ID"
+// @Param request body models.SegmentRequest true "Segment"
+// @Success 200 {object} models.Segment
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Segment not found"
+// @Failure 409 {string} string "Segment name already in use"
+// @Router /admin/segments/{id} [put]
+func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req models.SegmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	segment, err := h.service.Update(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, segment)
+}
+
+// handleDelete handles DELETE /api/v1/admin/segments/{id}
+// @Summary Delete segment
+// @Description Deletes a segment with its members and email campaigns. Segments targeted by a running or upcoming campaign can't be deleted.
+// @Tags Segments
+// @Security BearerAuth
+// @Param id path string true "Segment ID"
+// @Success 204
+// @Failure 404 {string} string "Segment not found"
+// @Failure 409 {string} string "Segment is used by a campaign"
+// @Router /admin/segments/{id} [delete]
+func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRefresh handles POST /api/v1/admin/segments/{id}/refresh
+// @Summary Refresh segment
+// @Description Recomputes the members now instead of waiting for the next scheduled refresh
+// @Tags Segments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Segment ID"
+// @Success 200 {object} models.Segment
+// @Failure 404 {string} string "Segment not found"
+// @Router /admin/segments/{id}/refresh [post]
+func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	segment, err := h.service.Refresh(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, segment)
+}
+
+// handleMembers handles GET /api/v1/admin/segments/{id}/members
+// @Summary List segment members
+// @Description Members as of the last refresh, longest-standing first
+// @Tags Segments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Segment ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.SegmentMemberListResponse
+// @Failure 404 {string} string "Segment not found"
+// @Router /admin/segments/{id}/members [get]
+func (h *Handler) handleMembers(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	resp, err := h.service.Members(r.Context(), mux.Vars(r)["id"], limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleSendEmail handles POST /api/v1/admin/segments/{id}/emails
+// @Summary Send email campaign
+// @Description Queues a message to the segment's current members. Each member gets it on their marketing channel (email by default); members who opted out are skipped.
+// @Tags Segments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Segment ID"
+// @Param request body models.SegmentEmailRequest true "Message"
+// @Success 202 {object} models.SegmentEmail
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Segment not found"
+// @Router /admin/segments/{id}/emails [post]
+func (h *Handler) handleSendEmail(w http.ResponseWriter, r *http.Request) {
+	var req models.SegmentEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	email, err := h.service.SendEmail(r.Context(), mux.Vars(r)["id"], &req, auth.ClaimsFromContext(r.Context()).UserID())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusAccepted, email)
+}
+
+// handleEmails handles GET /api/v1/admin/segments/{id}/emails
+// @Summary List email campaigns
+// @Description A segment's email campaigns with their progress, newest first
+// @Tags Segments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Segment ID"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.SegmentEmailListResponse
+// @Failure 404 {string} string "Segment not found"
+// @Router /admin/segments/{id}/emails [get]
+func (h *Handler) handleEmails(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+
+	resp, err := h.service.Emails(r.Context(), mux.Vars(r)["id"], limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// writeError maps segment errors to 400/404/409/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidSegment):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrSegmentNotFound):
+		http.Error(w, "Segment not found", http.StatusNotFound)
+	case errors.Is(err, ErrSegmentExists):
+		http.Error(w, "Segment name already in use", http.StatusConflict)
+	case errors.Is(err, repository.ErrSegmentInUse):
+		http.Error(w, "Segment is used by a campaign", http.StatusConflict)
+	default:
+		h.log.Error("Segment request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
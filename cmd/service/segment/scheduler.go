@@ -0,0 +1,42 @@
+package segment
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"go.uber.org/zap"
+)
+
+// Scheduler queues a refresh of every segment each SEGMENT_REFRESH_INTERVAL
+// It runs on the elected leader only, so one refresh is queued per interval; job workers run it
+type Scheduler struct {
+	jobs     *jobs.Processor
+	interval time.Duration
+	log      *zap.Logger
+}
+
+func NewScheduler(processor *jobs.Processor, interval time.Duration, log *zap.Logger) *Scheduler {
+	return &Scheduler{jobs: processor, interval: interval, log: log}
+}
+
+func (s *Scheduler) Name() string {
+	return "segment-refresh"
+}
+
+// Run queues refreshes until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := s.jobs.Enqueue(ctx, JobRefresh, nil, jobs.MaxAttempts(1)); err != nil {
+				s.log.Error("Failed to queue segment refresh", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,436 @@
+package segment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/notify"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"go.uber.org/zap"
+)
+
+// Job types handled by the segments module
+const (
+	JobRefresh   = "segment.refresh"    // recompute every segment's membership
+	JobSendEmail = "segment.send_email" // deliver an email campaign to a segment's members
+)
+
+const (
+	maxRules    = 20
+	maxValues   = 100
+	maxBodySize = 10000
+	// emailPage is how many members an email campaign handles between progress saves
+	emailPage = 200
+)
+
+var (
+	// ErrInvalidSegment wraps validation problems with a segment or email request
+	ErrInvalidSegment = errors.New("invalid segment")
+	// ErrSegmentExists is returned when another segment has the name
+	ErrSegmentExists = errors.New("segment name already in use")
+)
+
+// emailJob is the payload of email campaign jobs
+type emailJob struct {
+	EmailID string `json:"email_id"`
+}
+
+// SegmentService manages customer segments and their materialized membership
+// Spend, order count and recency come from the customer order ledger, which follows order
+// and refund events; location comes from each customer's default address
+type SegmentService struct {
+	repo     *repository.SegmentRepository
+	settings *settings.Store
+	notifier *notify.Notifier
+	jobs     *jobs.Processor
+	clock    clock.Clock
+	log      *zap.Logger
+}
+
+func NewSegmentService(repo *repository.SegmentRepository, storeSettings *settings.Store, notifier *notify.Notifier,
+	processor *jobs.Processor, clk clock.Clock, log *zap.Logger) *SegmentService {
+	return &SegmentService{
+		repo:     repo,
+		settings: storeSettings,
+		notifier: notifier,
+		jobs:     processor,
+		clock:    clk,
+		log:      log,
+	}
+}
+
+// Create validates req, saves the segment and computes its members
+func (s *SegmentService) Create(ctx context.Context, req *models.SegmentRequest) (*models.Segment, error) {
+	segment := &models.Segment{}
+	if err := s.apply(ctx, segment, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkName(ctx, segment.Name, ""); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, segment); err != nil {
+		return nil, nameTaken(err)
+	}
+
+	s.log.Info("Segment created", zap.String("id", segment.ID), zap.String("name", segment.Name))
+	return s.Refresh(ctx, segment.ID)
+}
+
+// Update replaces a segment's name, description and rules and recomputes its members
+func (s *SegmentService) Update(ctx context.Context, id string, req *models.SegmentRequest) (*models.Segment, error) {
+	segment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, segment, req); err != nil {
+		return nil, err
+	}
+	if err := s.checkName(ctx, segment.Name, id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, segment); err != nil {
+		return nil, nameTaken(err)
+	}
+
+	s.log.Info("Segment updated", zap.String("id", id))
+	return s.Refresh(ctx, id)
+}
+
+// Delete removes a segment unless a campaign that hasn't ended targets it
+func (s *SegmentService) Delete(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id, s.clock.Now()); err != nil {
+		return err
+	}
+	s.log.Info("Segment deleted", zap.String("id", id))
+	return nil
+}
+
+func (s *SegmentService) Get(ctx context.Context, id string) (*models.Segment, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns a page of segments by name
+func (s *SegmentService) List(ctx context.Context, limit, offset int) (*models.SegmentListResponse, error) {
+	segments, total, err := s.repo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if segments == nil {
+		segments = []models.Segment{}
+	}
+	return &models.SegmentListResponse{Segments: segments, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Members returns a page of a segment's members as of its last evaluation
+func (s *SegmentService) Members(ctx context.Context, id string, limit, offset int) (*models.SegmentMemberListResponse, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	members, total, err := s.repo.Members(ctx, id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if members == nil {
+		members = []models.SegmentMemberResponse{}
+	}
+	return &models.SegmentMemberListResponse{Members: members, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Preview counts the customers rules match now, without saving anything
+func (s *SegmentService) Preview(ctx context.Context, req *models.SegmentPreviewRequest) (*models.SegmentPreviewResponse, error) {
+	rules, err := s.rules(ctx, req.Rules)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := s.repo.Match(ctx, rules, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &models.SegmentPreviewResponse{Members: len(ids)}, nil
+}
+
+// Refresh re-evaluates a segment's rules and replaces its members
+func (s *SegmentService) Refresh(ctx context.Context, id string) (*models.Segment, error) {
+	segment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	ids, err := s.repo.Match(ctx, segment.Rules, now)
+	if err != nil {
+		return nil, err
+	}
+	added, removed, err := s.repo.ReplaceMembers(ctx, id, ids, now)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Segment refreshed", zap.String("id", id), zap.Int("members", len(ids)),
+		zap.Int("added", added), zap.Int("removed", removed))
+	return s.repo.GetByID(ctx, id)
+}
+
+// handleRefreshJob recomputes every segment; one failing segment doesn't hold up the rest
+func (s *SegmentService) handleRefreshJob(ctx context.Context, job *models.Job) error {
+	ids, err := s.repo.IDs(ctx)
+	if err != nil {
+		return err
+	}
+	var firstErr error
+	for _, id := range ids {
+		if _, err := s.Refresh(ctx, id); err != nil && !errors.Is(err, repository.ErrSegmentNotFound) {
+			s.log.Error("Failed to refresh segment", zap.String("id", id), zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// SendEmail queues an email campaign to the segment's current members
+func (s *SegmentService) SendEmail(ctx context.Context, id string, req *models.SegmentEmailRequest, adminID string) (*models.SegmentEmail, error) {
+	subject := strings.TrimSpace(req.Subject)
+	body := strings.TrimSpace(req.Body)
+	switch {
+	case subject == "":
+		return nil, fmt.Errorf("%w: subject is required", ErrInvalidSegment)
+	case len(subject) > 255:
+		return nil, fmt.Errorf("%w: subject must be at most 255 characters", ErrInvalidSegment)
+	case body == "":
+		return nil, fmt.Errorf("%w: body is required", ErrInvalidSegment)
+	case len(body) > maxBodySize:
+		return nil, fmt.Errorf("%w: body must be at most %d characters", ErrInvalidSegment, maxBodySize)
+	}
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	email := &models.SegmentEmail{
+		SegmentID: id,
+		Subject:   subject,
+		Body:      body,
+		Status:    models.SegmentEmailQueued,
+		CreatedBy: adminID,
+	}
+	if err := s.repo.CreateEmail(ctx, email); err != nil {
+		return nil, err
+	}
+	if _, err := s.jobs.Enqueue(ctx, JobSendEmail, emailJob{EmailID: email.ID}); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Segment email queued", zap.String("id", email.ID), zap.String("segment_id", id))
+	return email, nil
+}
+
+// Emails returns a page of a segment's email campaigns, newest first
+func (s *SegmentService) Emails(ctx context.Context, id string, limit, offset int) (*models.SegmentEmailListResponse, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	emails, total, err := s.repo.ListEmails(ctx, id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if emails == nil {
+		emails = []models.SegmentEmail{}
+	}
+	return &models.SegmentEmailListResponse{Emails: emails, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// handleSendEmailJob notifies the members page by page on their marketing channel
+// Progress is saved after every page, so a retried job resumes where the last attempt stopped
+// instead of messaging members twice. A member the notifier fails for is counted and skipped.
+func (s *SegmentService) handleSendEmailJob(ctx context.Context, job *models.Job) error {
+	var payload emailJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	email, err := s.repo.GetEmail(ctx, payload.EmailID)
+	if errors.Is(err, repository.ErrSegmentEmailNotFound) {
+		return nil // the segment was deleted
+	}
+	if err != nil || email.Status == models.SegmentEmailSent {
+		return err
+	}
+
+	notification := notify.Notification{Category: models.NotifyMarketing, Subject: email.Subject, Body: email.Body}
+	email.Status = models.SegmentEmailSending
+	for {
+		ids, err := s.repo.MemberIDs(ctx, email.SegmentID, email.Cursor, emailPage)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		for _, userID := range ids {
+			if err := s.notifier.Notify(ctx, userID, notification); err != nil {
+				email.Failed++
+			} else {
+				email.Recipients++
+			}
+		}
+		email.Cursor = ids[len(ids)-1]
+		if err := s.repo.SaveEmailProgress(ctx, email); err != nil {
+			return err
+		}
+	}
+
+	now := s.clock.Now()
+	email.Status = models.SegmentEmailSent
+	email.SentAt = &now
+	if err := s.repo.SaveEmailProgress(ctx, email); err != nil {
+		return err
+	}
+	s.log.Info("Segment email sent", zap.String("id", email.ID), zap.Int("recipients", email.Recipients),
+		zap.Int("failed", email.Failed))
+	return nil
+}
+
+// HandleOrderPlaced adds the order to its customer's spend and recency
+func (s *SegmentService) HandleOrderPlaced(ctx context.Context, event events.Event) error {
+	var payload events.OrderPlaced
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	if payload.UserID == "" || payload.OrderID == "" {
+		return nil
+	}
+	placedAt := event.OccurredAt
+	if placedAt.IsZero() {
+		placedAt = s.clock.Now()
+	}
+	return s.repo.RecordOrder(ctx, &models.CustomerOrder{
+		OrderID:  payload.OrderID,
+		UserID:   payload.UserID,
+		Total:    payload.Total,
+		Currency: strings.ToUpper(payload.Currency),
+		PlacedAt: placedAt.UTC(),
+	})
+}
+
+// HandleRefundIssued takes a refund off its order's spend
+func (s *SegmentService) HandleRefundIssued(ctx context.Context, event events.Event) error {
+	var payload events.RefundIssued
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	if payload.RefundID == "" || payload.Amount <= 0 {
+		return nil
+	}
+	_, err := s.repo.RecordRefund(ctx, &models.CustomerRefund{RefundID: payload.RefundID, OrderID: payload.OrderID, Amount: payload.Amount})
+	return err
+}
+
+// apply validates req and copies it onto segment
+func (s *SegmentService) apply(ctx context.Context, segment *models.Segment, req *models.SegmentRequest) error {
+	name := strings.TrimSpace(req.Name)
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidSegment)
+	case len(name) > 255:
+		return fmt.Errorf("%w: name must be at most 255 characters", ErrInvalidSegment)
+	case len(req.Description) > 1000:
+		return fmt.Errorf("%w: description must be at most 1000 characters", ErrInvalidSegment)
+	}
+	rules, err := s.rules(ctx, req.Rules)
+	if err != nil {
+		return err
+	}
+
+	segment.Name = name
+	segment.Description = strings.TrimSpace(req.Description)
+	segment.Rules = rules
+	return nil
+}
+
+// rules validates and normalizes segment rules; total_spend without a currency gets the store's
+func (s *SegmentService) rules(ctx context.Context, in []models.SegmentRule) ([]models.SegmentRule, error) {
+	switch {
+	case len(in) == 0:
+		return nil, fmt.Errorf("%w: rules are required", ErrInvalidSegment)
+	case len(in) > maxRules:
+		return nil, fmt.Errorf("%w: at most %d rules", ErrInvalidSegment, maxRules)
+	}
+
+	rules := make([]models.SegmentRule, 0, len(in))
+	for _, rule := range in {
+		rule.Field = strings.ToLower(strings.TrimSpace(rule.Field))
+		rule.Op = strings.ToLower(strings.TrimSpace(rule.Op))
+
+		switch rule.Field {
+		case models.SegmentFieldTotalSpend, models.SegmentFieldOrderCount, models.SegmentFieldLastOrder:
+			switch {
+			case rule.Op != models.SegmentOpGte && rule.Op != models.SegmentOpLte:
+				return nil, fmt.Errorf("%w: %s takes gte or lte", ErrInvalidSegment, rule.Field)
+			case rule.Value < 0:
+				return nil, fmt.Errorf("%w: %s value cannot be negative", ErrInvalidSegment, rule.Field)
+			case len(rule.Values) > 0:
+				return nil, fmt.Errorf("%w: %s takes value, not values", ErrInvalidSegment, rule.Field)
+			}
+			rule.Currency = strings.ToUpper(strings.TrimSpace(rule.Currency))
+			if rule.Field != models.SegmentFieldTotalSpend {
+				rule.Currency = ""
+			} else if rule.Currency == "" {
+				rule.Currency = s.settings.DefaultCurrency(ctx)
+			} else if len(rule.Currency) != 3 {
+				return nil, fmt.Errorf("%w: currency must be an ISO 4217 code", ErrInvalidSegment)
+			}
+		case models.SegmentFieldCountry, models.SegmentFieldRegion, models.SegmentFieldCity, models.SegmentFieldZone:
+			switch {
+			case rule.Op != models.SegmentOpIn && rule.Op != models.SegmentOpNotIn:
+				return nil, fmt.Errorf("%w: %s takes in or not_in", ErrInvalidSegment, rule.Field)
+			case len(rule.Values) == 0:
+				return nil, fmt.Errorf("%w: %s needs values", ErrInvalidSegment, rule.Field)
+			case len(rule.Values) > maxValues:
+				return nil, fmt.Errorf("%w: at most %d values per rule", ErrInvalidSegment, maxValues)
+			}
+			values := make([]string, 0, len(rule.Values))
+			for _, v := range rule.Values {
+				if v = strings.TrimSpace(v); v != "" {
+					values = append(values, v)
+				}
+			}
+			if len(values) == 0 {
+				return nil, fmt.Errorf("%w: %s needs values", ErrInvalidSegment, rule.Field)
+			}
+			rule.Values, rule.Value, rule.Currency = values, 0, ""
+		default:
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidSegment, rule.Field)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// nameTaken reports a write that raced another for the same name as ErrSegmentExists
+func nameTaken(err error) error {
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		return ErrSegmentExists
+	}
+	return err
+}
+
+// checkName rejects a name another segment already has
+func (s *SegmentService) checkName(ctx context.Context, name, id string) error {
+	existing, err := s.repo.GetByName(ctx, name)
+	if errors.Is(err, repository.ErrSegmentNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != id {
+		return ErrSegmentExists
+	}
+	return nil
+}
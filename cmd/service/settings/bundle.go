@@ -0,0 +1,291 @@
+package settings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/customergroup"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidBundle wraps problems with an imported configuration bundle
+var ErrInvalidBundle = errors.New("invalid configuration bundle")
+
+// groupCodePattern is the customer group code rule of the customergroup module
+var groupCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+var taxTreatments = []string{models.TaxInclusive, models.TaxExclusive, models.TaxExempt}
+
+// BundleService exports the store's configuration as a models.ConfigBundle and imports one
+// from another environment. Settings, customer groups (with their tax treatment) and admin
+// roles are applied; shipping comes from environment variables, so differences there are
+// only reported. Import never deletes: groups and admins missing from the bundle are kept.
+type BundleService struct {
+	store     *settings.Store
+	groups    *repository.CustomerGroupRepository
+	pricing   *customergroup.Pricing
+	users     *repository.UserRepository
+	cfg       *config.Config
+	publisher events.Publisher
+	clock     clock.Clock
+	log       *zap.Logger
+}
+
+func NewBundleService(store *settings.Store, groups *repository.CustomerGroupRepository, pricing *customergroup.Pricing,
+	users *repository.UserRepository, cfg *config.Config, publisher events.Publisher, clk clock.Clock, log *zap.Logger) *BundleService {
+	return &BundleService{
+		store:     store,
+		groups:    groups,
+		pricing:   pricing,
+		users:     users,
+		cfg:       cfg,
+		publisher: publisher,
+		clock:     clk,
+		log:       log,
+	}
+}
+
+// Export returns this environment's configuration
+func (s *BundleService) Export(ctx context.Context) (*models.ConfigBundle, error) {
+	list, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{}, len(list))
+	for _, setting := range list {
+		values[setting.Key] = setting.Value
+	}
+
+	groups, err := s.groups.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bundleGroups := make([]models.ConfigCustomerGroup, len(groups))
+	for i, group := range groups {
+		bundleGroups[i] = models.ConfigCustomerGroup{Code: group.Code, Name: group.Name, TaxTreatment: group.TaxTreatment}
+	}
+
+	admins, err := s.users.GetUsersByRole(ctx, models.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	emails := make([]string, 0, len(admins))
+	for _, admin := range admins {
+		emails = append(emails, admin.Email)
+	}
+	sort.Strings(emails)
+
+	return &models.ConfigBundle{
+		Version:        models.ConfigBundleVersion,
+		ExportedAt:     s.clock.Now().UTC(),
+		Settings:       values,
+		CustomerGroups: bundleGroups,
+		Admins:         emails,
+		Shipping:       s.shipping(),
+	}, nil
+}
+
+// Import compares bundle with this environment and, unless dryRun, applies the differences
+// The whole bundle is validated before anything is written; settings are then saved in one
+// transaction, groups and roles one at a time
+// Returns: ErrInvalidBundle, settings.ErrUnknownSetting or settings.ErrInvalidValue without changing anything
+func (s *BundleService) Import(ctx context.Context, bundle *models.ConfigBundle, dryRun bool, adminID string) (*models.ConfigImportResult, error) {
+	if bundle.Version != models.ConfigBundleVersion {
+		return nil, fmt.Errorf("%w: version must be %d", ErrInvalidBundle, models.ConfigBundleVersion)
+	}
+
+	settingChanges, err := s.store.Diff(ctx, bundle.Settings)
+	if err != nil {
+		return nil, err
+	}
+	groupChanges, err := s.diffGroups(ctx, bundle.CustomerGroups)
+	if err != nil {
+		return nil, err
+	}
+	adminChanges, err := s.diffAdmins(ctx, bundle.Admins)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := append(settingChanges, groupChanges...)
+	changes = append(changes, adminChanges...)
+	changes = append(changes, s.diffShipping(bundle.Shipping)...)
+	result := &models.ConfigImportResult{DryRun: dryRun, Changes: changes}
+	if result.Changes == nil {
+		result.Changes = []models.ConfigChange{}
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	if len(settingChanges) > 0 {
+		req := make(models.UpdateSettingsRequest, len(settingChanges))
+		for _, change := range settingChanges {
+			req[change.Key] = bundle.Settings[change.Key]
+		}
+		if _, err := s.store.Update(ctx, req, adminID); err != nil {
+			return nil, err
+		}
+	}
+	for _, change := range groupChanges {
+		if err := s.applyGroup(ctx, change); err != nil {
+			return nil, err
+		}
+	}
+	for _, change := range adminChanges {
+		if err := s.applyAdmin(ctx, change); err != nil {
+			return nil, err
+		}
+	}
+
+	s.log.Info("Configuration imported", zap.String("admin_id", adminID), zap.Int("changes", len(changes)))
+	return result, nil
+}
+
+func (s *BundleService) diffGroups(ctx context.Context, groups []models.ConfigCustomerGroup) ([]models.ConfigChange, error) {
+	var changes []models.ConfigChange
+	seen := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		group.Code = strings.ToLower(strings.TrimSpace(group.Code))
+		group.Name = strings.TrimSpace(group.Name)
+		group.TaxTreatment = strings.ToLower(strings.TrimSpace(group.TaxTreatment))
+		switch {
+		case !groupCodePattern.MatchString(group.Code):
+			return nil, fmt.Errorf("%w: customer group code %q is not valid", ErrInvalidBundle, group.Code)
+		case seen[group.Code]:
+			return nil, fmt.Errorf("%w: customer group %s is listed twice", ErrInvalidBundle, group.Code)
+		case group.Name == "":
+			return nil, fmt.Errorf("%w: customer group %s has no name", ErrInvalidBundle, group.Code)
+		case !slices.Contains(taxTreatments, group.TaxTreatment):
+			return nil, fmt.Errorf("%w: customer group %s has an unknown tax_treatment", ErrInvalidBundle, group.Code)
+		}
+		seen[group.Code] = true
+
+		existing, err := s.groups.Get(ctx, group.Code)
+		if errors.Is(err, repository.ErrCustomerGroupNotFound) {
+			changes = append(changes, models.ConfigChange{Section: models.ConfigSectionCustomerGroups, Key: group.Code,
+				Action: models.ConfigCreate, To: group})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		current := models.ConfigCustomerGroup{Code: existing.Code, Name: existing.Name, TaxTreatment: existing.TaxTreatment}
+		if current != group {
+			changes = append(changes, models.ConfigChange{Section: models.ConfigSectionCustomerGroups, Key: group.Code,
+				Action: models.ConfigUpdate, From: current, To: group})
+		}
+	}
+	return changes, nil
+}
+
+func (s *BundleService) applyGroup(ctx context.Context, change models.ConfigChange) error {
+	bundled := change.To.(models.ConfigCustomerGroup)
+	group := &models.CustomerGroup{Code: bundled.Code, Name: bundled.Name, TaxTreatment: bundled.TaxTreatment}
+	if change.Action == models.ConfigCreate {
+		if _, err := s.groups.Create(ctx, group); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	existing, err := s.groups.Get(ctx, group.Code)
+	if err != nil {
+		return err
+	}
+	existing.Name, existing.TaxTreatment = group.Name, group.TaxTreatment
+	if err := s.groups.Update(ctx, existing); err != nil {
+		return err
+	}
+	if err := s.pricing.Invalidate(ctx, group.Code); err != nil {
+		s.log.Warn("Failed to invalidate customer group pricing", zap.String("code", group.Code), zap.Error(err))
+	}
+	return nil
+}
+
+// diffAdmins promotes the accounts the bundle lists as admins; admins the bundle doesn't
+// list keep their role, and emails without an account here are skipped
+func (s *BundleService) diffAdmins(ctx context.Context, emails []string) ([]models.ConfigChange, error) {
+	var changes []models.ConfigChange
+	for _, email := range emails {
+		email = strings.TrimSpace(email)
+		if email == "" {
+			continue
+		}
+		user, err := s.users.GetUserByEmail(ctx, email)
+		if errors.Is(err, repository.ErrUserNotFound) {
+			changes = append(changes, models.ConfigChange{Section: models.ConfigSectionAdmins, Key: email,
+				Action: models.ConfigSkip, Note: "no account with this email"})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if user.Role != models.RoleAdmin {
+			changes = append(changes, models.ConfigChange{Section: models.ConfigSectionAdmins, Key: email,
+				Action: models.ConfigUpdate, From: user.Role, To: models.RoleAdmin})
+		}
+	}
+	return changes, nil
+}
+
+// applyAdmin grants the admin role like an admin role change does, so Keycloak sync follows
+func (s *BundleService) applyAdmin(ctx context.Context, change models.ConfigChange) error {
+	if change.Action != models.ConfigUpdate {
+		return nil
+	}
+	user, err := s.users.GetUserByEmail(ctx, change.Key)
+	if err != nil {
+		return err
+	}
+	if err := s.users.UpdateFields(ctx, user.ID, map[string]interface{}{"role": models.RoleAdmin}); err != nil {
+		return err
+	}
+	s.log.Info("User role changed", zap.String("id", user.ID), zap.String("role", models.RoleAdmin))
+	_ = events.Publish(ctx, s.publisher, s.log, events.TypeUserRoleChanged, events.UserRoleChanged{
+		UserID: user.ID,
+		Role:   models.RoleAdmin,
+	})
+	return nil
+}
+
+func (s *BundleService) shipping() models.ConfigShipping {
+	return models.ConfigShipping{
+		Carriers:      s.cfg.Shipping.Carriers,
+		DeliveryZones: s.cfg.Address.DeliveryZones,
+	}
+}
+
+// diffShipping reports shipping setup that differs; it lives in environment variables
+func (s *BundleService) diffShipping(bundled models.ConfigShipping) []models.ConfigChange {
+	current := s.shipping()
+	var changes []models.ConfigChange
+	if !sameSet(current.Carriers, bundled.Carriers) {
+		changes = append(changes, models.ConfigChange{Section: models.ConfigSectionShipping, Key: "carriers",
+			Action: models.ConfigManual, From: current.Carriers, To: bundled.Carriers, Note: "set SHIPPING_CARRIERS"})
+	}
+	// Zone rules are matched in order, so order matters here
+	if !slices.Equal(current.DeliveryZones, bundled.DeliveryZones) {
+		changes = append(changes, models.ConfigChange{Section: models.ConfigSectionShipping, Key: "delivery_zones",
+			Action: models.ConfigManual, From: current.DeliveryZones, To: bundled.DeliveryZones, Note: "set DELIVERY_ZONES"})
+	}
+	return changes
+}
+
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
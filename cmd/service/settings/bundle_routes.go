@@ -0,0 +1,104 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type BundleHandler struct {
+	bundles *BundleService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewBundleHandler(bundles *BundleService, tokens *auth.TokenManager, log *zap.Logger) *BundleHandler {
+	return &BundleHandler{
+		bundles: bundles,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers configuration export/import routes (admin only)
+func (h *BundleHandler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/config").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+
+	admin.HandleFunc("/export", h.handleExport).Methods("GET")
+	admin.HandleFunc("/import", h.handleImport).Methods("POST")
+}
+
+// handleExport handles GET /api/v1/admin/config/export
+// @Summary Export store configuration
+// @Description Settings, customer groups with their tax treatment, admin accounts and shipping setup as one JSON bundle
+// @Tags Settings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.ConfigBundle
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/config/export [get]
+func (h *BundleHandler) handleExport(w http.ResponseWriter, r *http.Request) {
+	bundle, err := h.bundles.Export(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="store-config-`+bundle.ExportedAt.Format("20060102")+`.json"`)
+	response.JSON(w, http.StatusOK, bundle)
+}
+
+// handleImport handles POST /api/v1/admin/config/import
+// @Summary Import store configuration
+// @Description Applies a bundle from GET /admin/config/export and lists what changed. With dry_run=true nothing is
+// @Description written and the list shows what would change. Nothing is deleted, and shipping differences are only reported.
+// @Tags Settings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param dry_run query bool false "Only compare"
+// @Param bundle body models.ConfigBundle true "Configuration bundle"
+// @Success 200 {object} models.ConfigImportResult
+// @Failure 400 {string} string "Invalid bundle"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/config/import [post]
+func (h *BundleHandler) handleImport(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	var bundle models.ConfigBundle
+	if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	result, err := h.bundles.Import(r.Context(), &bundle, dryRun, claims.UserID())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, result)
+}
+
+// writeError maps bundle errors to 400/500
+func (h *BundleHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidBundle), errors.Is(err, settings.ErrUnknownSetting), errors.Is(err, settings.ErrInvalidValue):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.log.Error("Configuration bundle request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,48 @@
+package settings
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+)
+
+// Module provides the admin endpoints for store-wide settings and for exporting and importing
+// the store's configuration; other modules read the values through deps.Settings directly
+type Module struct {
+	handler       *Handler
+	bundleHandler *BundleHandler
+	bundles       *BundleService
+}
+
+func NewModule(deps module.Deps) *Module {
+	bundles := NewBundleService(deps.Settings, repository.NewCustomerGroupRepository(deps.DB, deps.Log), deps.Groups,
+		repository.NewUserRepository(deps.DB, deps.Log), deps.Config, deps.Events, deps.Clock, deps.Log)
+
+	return &Module{
+		handler:       NewHandler(deps.Settings, deps.Tokens, deps.Log),
+		bundleHandler: NewBundleHandler(bundles, deps.Tokens, deps.Log),
+		bundles:       bundles,
+	}
+}
+
+// Bundles exports and imports the store's configuration (`main config`)
+func (m *Module) Bundles() *BundleService {
+	return m.bundles
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.Setting{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+	m.bundleHandler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
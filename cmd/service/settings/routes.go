@@ -0,0 +1,99 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	store  *settings.Store
+	tokens *auth.TokenManager
+	log    *zap.Logger
+}
+
+func NewHandler(store *settings.Store, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		store:  store,
+		tokens: tokens,
+		log:    log,
+	}
+}
+
+// RegisterRoutes registers settings routes (admin only)
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/settings").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+
+	admin.HandleFunc("", h.handleList).Methods("GET")
+	admin.HandleFunc("", h.handleUpdate).Methods("PATCH")
+}
+
+// handleList handles GET /api/v1/admin/settings
+// @Summary List store settings
+// @Description Every store-wide setting with its type, current value and default
+// @Tags Settings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.SettingResponse
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/settings [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	list, err := h.store.List(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, list)
+}
+
+// handleUpdate handles PATCH /api/v1/admin/settings
+// @Summary Change store settings
+// @Description Sets the given settings, keyed by setting key. All values are validated before any is saved; null restores the default. Changes apply to every instance without a restart.
+// @Tags Settings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateSettingsRequest true "Settings to change"
+// @Success 200 {array} models.SettingResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/settings [patch]
+func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	var req models.UpdateSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req) == 0 {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.store.Update(r.Context(), req, claims.UserID())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, list)
+}
+
+// writeError maps settings errors to 400/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, settings.ErrUnknownSetting), errors.Is(err, settings.ErrInvalidValue):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.log.Error("Settings request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,42 @@
+package shipping
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+)
+
+// Module provides carrier bookings, labels and tracking webhooks
+// Tracking updates publish order.shipped / order.delivered for notifications and order streams
+type Module struct {
+	handler *Handler
+}
+
+func NewModule(deps module.Deps) *Module {
+	service := NewShippingService(
+		repository.NewShipmentRepository(deps.DB, deps.Log),
+		repository.NewAddressRepository(deps.DB, deps.Log),
+		repository.NewWarehouseRepository(deps.DB, deps.Log),
+		deps.Carriers, deps.Config.Shipping.Origin, deps.Storage, deps.Events, deps.IDs, deps.Log,
+	)
+
+	return &Module{
+		handler: NewHandler(service, deps.Config.Storage.PresignTTL, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.Shipment{}, &models.ShipmentEvent{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
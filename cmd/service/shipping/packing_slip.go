@@ -0,0 +1,68 @@
+package shipping
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/fx"
+	"github.com/Jason-Omondi/ecomgo/internal/gift"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+// validatePackingSlip checks the slip lines and gift message of a shipment request
+func validatePackingSlip(req *models.CreateShipmentRequest) error {
+	for _, item := range req.Items {
+		if strings.TrimSpace(item.Name) == "" || item.Quantity <= 0 {
+			return fmt.Errorf("%w: every item needs a name and a positive quantity", ErrInvalidShipment)
+		}
+	}
+	message, err := gift.NormalizeMessage(req.GiftMessage)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidShipment, err)
+	}
+	req.GiftMessage = message
+	if message != "" {
+		req.Gift = true
+	}
+	return nil
+}
+
+// renderPackingSlip prints the plain-text slip packed with a shipment
+// Gift slips leave out prices so the recipient doesn't see what was paid, and carry the message
+func renderPackingSlip(shipment *models.Shipment, req *models.CreateShipmentRequest) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "PACKING SLIP\nORDER: %s\nSHIPMENT: %s\nTRACKING: %s\n\nSHIP TO: %s\n%s\n%s %s\n\n",
+		shipment.OrderID, shipment.ID, shipment.TrackingNumber,
+		shipment.Recipient, shipment.Line1, shipment.City, shipment.Country)
+
+	for _, item := range req.Items {
+		fmt.Fprintf(&b, "%3d x %s", item.Quantity, item.Name)
+		if item.SKU != "" {
+			fmt.Fprintf(&b, " (%s)", item.SKU)
+		}
+		if !req.Gift {
+			fmt.Fprintf(&b, "  %s", formatAmount(item.UnitPrice, req.Currency))
+		}
+		if item.GiftWrap {
+			b.WriteString("  [GIFT WRAP]")
+		}
+		b.WriteString("\n")
+	}
+
+	if req.Gift {
+		b.WriteString("\nTHIS IS A GIFT\n")
+		if req.GiftMessage != "" {
+			fmt.Fprintf(&b, "\n%s\n", req.GiftMessage)
+		}
+	}
+	return []byte(b.String())
+}
+
+// formatAmount prints minor units with the currency's decimal places, e.g. 1250 KES as "12.50 KES"
+func formatAmount(amount int64, currency string) string {
+	currency = strings.ToUpper(currency)
+	digits := fx.MinorUnits(currency)
+	value := fmt.Sprintf("%.*f", digits, float64(amount)/math.Pow10(digits))
+	return strings.TrimSpace(value + " " + currency)
+}
@@ -0,0 +1,208 @@
+package shipping
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/Jason-Omondi/ecomgo/internal/shipping"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// maxWebhookBody caps carrier callback payloads
+const maxWebhookBody = 1 << 20
+
+type Handler struct {
+	service  *ShippingService
+	labelTTL time.Duration // lifetime of presigned label links
+	tokens   *auth.TokenManager
+	log      *zap.Logger
+}
+
+func NewHandler(service *ShippingService, labelTTL time.Duration, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service:  service,
+		labelTTL: labelTTL,
+		tokens:   tokens,
+		log:      log,
+	}
+}
+
+// RegisterRoutes registers shipment routes
+// Booking and labels are admin-only; customers see their own order's shipments;
+// carrier webhooks are public and authenticated by signature
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/shipments").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("", h.handleCreate).Methods("POST")
+	admin.HandleFunc("/{id}/label", h.handleLabel).Methods("GET")
+	admin.HandleFunc("/{id}/packing-slip", h.handlePackingSlip).Methods("GET")
+
+	orders := router.PathPrefix("/orders/{id}/shipments").Subrouter()
+	orders.Use(auth.Authenticate(h.tokens))
+	orders.HandleFunc("", h.handleListForOrder).Methods("GET")
+
+	router.HandleFunc("/shipping/webhooks/{carrier}", h.handleWebhook).Methods("POST")
+}
+
+// handleCreate handles POST /api/v1/shipments
+// @Summary Book shipment
+// @Description Books a shipment for an order with a carrier (dhl, sendy, manual) and stores its label. With warehouse_id it ships from that warehouse instead of the store origin. With items it also prints a packing slip; gift shipments get a slip without prices that carries the gift message.
+// @Tags Shipping
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateShipmentRequest true "Shipment"
+// @Success 201 {object} models.Shipment
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Address or warehouse not found"
+// @Failure 502 {string} string "Carrier error"
+// @Router /shipments [post]
+func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateShipmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	shipment, err := h.service.CreateShipment(r.Context(), &req)
+	switch {
+	case errors.Is(err, ErrInvalidShipment), errors.Is(err, shipping.ErrUnknownCarrier):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, repository.ErrAddressNotFound):
+		http.Error(w, "Address not found", http.StatusNotFound)
+		return
+	case errors.Is(err, repository.ErrWarehouseNotFound):
+		http.Error(w, "Warehouse not found", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, "Carrier error", http.StatusBadGateway)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, shipment)
+}
+
+// handleLabel handles GET /api/v1/shipments/{id}/label
+// @Summary Download shipping label
+// @Description Redirects to a short-lived presigned URL of the label in object storage
+// @Tags Shipping
+// @Security BearerAuth
+// @Param id path string true "Shipment ID"
+// @Success 302 {string} string "Redirect to label"
+// @Failure 404 {string} string "Label not found"
+// @Router /shipments/{id}/label [get]
+func (h *Handler) handleLabel(w http.ResponseWriter, r *http.Request) {
+	labelURL, err := h.service.GetLabelURL(r.Context(), mux.Vars(r)["id"], h.labelTTL)
+	if errors.Is(err, repository.ErrShipmentNotFound) {
+		http.Error(w, "Label not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to sign shipping label URL", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, labelURL, http.StatusFound)
+}
+
+// handlePackingSlip handles GET /api/v1/shipments/{id}/packing-slip
+// @Summary Download packing slip
+// @Description Redirects to a short-lived presigned URL of the packing slip in object storage
+// @Tags Shipping
+// @Security BearerAuth
+// @Param id path string true "Shipment ID"
+// @Success 302 {string} string "Redirect to packing slip"
+// @Failure 404 {string} string "Packing slip not found"
+// @Router /shipments/{id}/packing-slip [get]
+func (h *Handler) handlePackingSlip(w http.ResponseWriter, r *http.Request) {
+	slipURL, err := h.service.GetPackingSlipURL(r.Context(), mux.Vars(r)["id"], h.labelTTL)
+	if errors.Is(err, repository.ErrShipmentNotFound) {
+		http.Error(w, "Packing slip not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to sign packing slip URL", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, slipURL, http.StatusFound)
+}
+
+// handleListForOrder handles GET /api/v1/orders/{id}/shipments
+// @Summary Order shipments
+// @Description Shipments of an order with tracking history. Customers only see their own orders.
+// @Tags Shipping
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {array} models.Shipment
+// @Failure 500 {string} string "Internal server error"
+// @Router /orders/{id}/shipments [get]
+func (h *Handler) handleListForOrder(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+	ownerID := claims.UserID()
+	if claims.Role == models.RoleAdmin {
+		ownerID = ""
+	}
+
+	shipments, err := h.service.ListForOrder(r.Context(), mux.Vars(r)["id"], ownerID)
+	if err != nil {
+		h.log.Error("Failed to list shipments", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if shipments == nil {
+		shipments = []models.Shipment{}
+	}
+
+	response.JSON(w, http.StatusOK, shipments)
+}
+
+// handleWebhook handles POST /api/v1/shipping/webhooks/{carrier}
+// @Summary Carrier tracking webhook
+// @Description Receives tracking updates from a carrier. Authenticated by the carrier's HMAC signature header.
+// @Tags Shipping
+// @Accept json
+// @Param carrier path string true "Carrier code"
+// @Success 204
+// @Failure 401 {string} string "Invalid signature"
+// @Failure 404 {string} string "Unknown carrier"
+// @Router /shipping/webhooks/{carrier} [post]
+func (h *Handler) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	carrier := mux.Vars(r)["carrier"]
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.HandleWebhook(r.Context(), carrier, r, body)
+	switch {
+	case errors.Is(err, shipping.ErrUnknownCarrier):
+		http.Error(w, "Unknown carrier", http.StatusNotFound)
+		return
+	case errors.Is(err, shipping.ErrInvalidSignature):
+		h.log.Warn("Rejected carrier webhook", zap.String("carrier", carrier))
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	case err != nil:
+		// 5xx makes the carrier retry later
+		h.log.Error("Failed to process carrier webhook", zap.String("carrier", carrier), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,297 @@
+package shipping
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/shipping"
+	"github.com/Jason-Omondi/ecomgo/internal/storage"
+	"go.uber.org/zap"
+)
+
+// ErrInvalidShipment wraps problems with a shipment request
+var ErrInvalidShipment = errors.New("invalid shipment")
+
+// statusRank orders the normal shipment lifecycle; exception sits outside it
+var statusRank = map[string]int{
+	models.ShipmentCreated:        0,
+	models.ShipmentInTransit:      1,
+	models.ShipmentOutForDelivery: 2,
+	models.ShipmentDelivered:      3,
+}
+
+// ShippingService books shipments with carriers and turns tracking updates into order state changes
+type ShippingService struct {
+	repo       *repository.ShipmentRepository
+	addresses  repository.AddressStore
+	warehouses *repository.WarehouseRepository
+	carriers   shipping.Carriers
+	origin     models.Address
+	labels     storage.Storage
+	publisher  events.Publisher
+	ids        clock.IDGenerator
+	log        *zap.Logger
+}
+
+func NewShippingService(repo *repository.ShipmentRepository, addresses repository.AddressStore,
+	warehouses *repository.WarehouseRepository, carriers shipping.Carriers, origin config.Origin, labels storage.Storage, publisher events.Publisher,
+	ids clock.IDGenerator, log *zap.Logger) *ShippingService {
+	from := models.Address{
+		Recipient:  origin.Name,
+		Phone:      origin.Phone,
+		Line1:      origin.Line1,
+		City:       origin.City,
+		PostalCode: origin.PostalCode,
+		Country:    origin.Country,
+	}
+	if origin.Latitude != 0 || origin.Longitude != 0 {
+		from.Latitude, from.Longitude = &origin.Latitude, &origin.Longitude
+	}
+
+	return &ShippingService{
+		repo:       repo,
+		addresses:  addresses,
+		warehouses: warehouses,
+		carriers:   carriers,
+		origin:     from,
+		labels:     labels,
+		publisher:  publisher,
+		ids:        ids,
+		log:        log,
+	}
+}
+
+// CreateShipment books a shipment with the requested carrier and stores its label
+// It ships from req.WarehouseID when set (the warehouse allocation picked), else SHIPPING_ORIGIN
+func (s *ShippingService) CreateShipment(ctx context.Context, req *models.CreateShipmentRequest) (*models.Shipment, error) {
+	if req.OrderID == "" || req.UserID == "" || req.AddressID == "" {
+		return nil, fmt.Errorf("%w: order_id, user_id and address_id are required", ErrInvalidShipment)
+	}
+	if req.WeightGrams <= 0 {
+		return nil, fmt.Errorf("%w: weight_grams must be positive", ErrInvalidShipment)
+	}
+	if err := validatePackingSlip(req); err != nil {
+		return nil, err
+	}
+
+	carrier, err := s.carriers.Get(req.Carrier)
+	if err != nil {
+		return nil, err
+	}
+	dest, err := s.addresses.GetByID(ctx, req.AddressID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	origin := s.origin
+	if req.WarehouseID != "" {
+		warehouse, err := s.warehouses.GetByID(ctx, req.WarehouseID)
+		if err != nil {
+			return nil, err
+		}
+		origin = warehouse.Address()
+	}
+
+	shipmentID := s.ids.NewID()
+	result, err := carrier.CreateShipment(ctx, shipping.ShipmentRequest{
+		Reference:   shipmentID,
+		Origin:      origin,
+		Destination: *dest,
+		WeightGrams: req.WeightGrams,
+	})
+	if err != nil {
+		s.log.Error("Carrier rejected shipment", zap.String("carrier", carrier.Name()),
+			zap.String("order_id", req.OrderID), zap.Error(err))
+		return nil, err
+	}
+
+	shipment := &models.Shipment{
+		ID:             shipmentID,
+		OrderID:        req.OrderID,
+		UserID:         req.UserID,
+		Carrier:        carrier.Name(),
+		TrackingNumber: result.TrackingNumber,
+		Status:         models.ShipmentCreated,
+		Recipient:      dest.Recipient,
+		Line1:          dest.Line1,
+		City:           dest.City,
+		Country:        dest.Country,
+		WeightGrams:    req.WeightGrams,
+		WarehouseID:    req.WarehouseID,
+		Gift:           req.Gift,
+	}
+
+	// Labels go to object storage; a failed upload leaves the booking without a label
+	// rather than losing a shipment the carrier already accepted
+	if len(result.Label) > 0 {
+		key := storage.Key(storage.PrefixShippingLabels, shipmentID+labelExtension(result.LabelFormat))
+		err := s.labels.Put(ctx, key, bytes.NewReader(result.Label), int64(len(result.Label)), result.LabelFormat)
+		if err != nil {
+			s.log.Error("Failed to store shipping label", zap.String("shipment_id", shipmentID), zap.Error(err))
+		} else {
+			shipment.LabelKey = key
+		}
+	}
+	if len(req.Items) > 0 {
+		slip := renderPackingSlip(shipment, req)
+		key := storage.Key(storage.PrefixPackingSlips, shipmentID+".txt")
+		err := s.labels.Put(ctx, key, bytes.NewReader(slip), int64(len(slip)), "text/plain; charset=utf-8")
+		if err != nil {
+			s.log.Error("Failed to store packing slip", zap.String("shipment_id", shipmentID), zap.Error(err))
+		} else {
+			shipment.PackingSlipKey = key
+		}
+	}
+
+	if err := s.repo.Create(ctx, shipment); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Shipment booked", zap.String("shipment_id", shipment.ID), zap.String("carrier", shipment.Carrier),
+		zap.String("tracking_number", shipment.TrackingNumber))
+	return shipment, nil
+}
+
+// GetLabelURL returns a short-lived download URL for a shipment's printable label
+func (s *ShippingService) GetLabelURL(ctx context.Context, id string, ttl time.Duration) (string, error) {
+	shipment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if shipment.LabelKey == "" {
+		return "", repository.ErrShipmentNotFound
+	}
+	return s.labels.PresignGet(ctx, shipment.LabelKey, ttl)
+}
+
+// GetPackingSlipURL returns a short-lived download URL for a shipment's packing slip
+func (s *ShippingService) GetPackingSlipURL(ctx context.Context, id string, ttl time.Duration) (string, error) {
+	shipment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if shipment.PackingSlipKey == "" {
+		return "", repository.ErrShipmentNotFound
+	}
+	return s.labels.PresignGet(ctx, shipment.PackingSlipKey, ttl)
+}
+
+// labelExtension picks a file extension for a label's MIME type (.pdf for application/pdf)
+func labelExtension(format string) string {
+	if format == "application/pdf" {
+		return ".pdf"
+	}
+	if exts, _ := mime.ExtensionsByType(format); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// ListForOrder returns an order's shipments; userID "" lists any owner's (admin)
+func (s *ShippingService) ListForOrder(ctx context.Context, orderID, userID string) ([]models.Shipment, error) {
+	return s.repo.ListByOrder(ctx, orderID, userID)
+}
+
+// HandleWebhook authenticates a carrier's tracking callback and applies its updates
+// Returns: shipping.ErrInvalidSignature / ErrUnknownCarrier, or nil once all updates are processed
+func (s *ShippingService) HandleWebhook(ctx context.Context, carrierName string, r *http.Request, body []byte) error {
+	carrier, err := s.carriers.Get(carrierName)
+	if err != nil {
+		return err
+	}
+	updates, err := carrier.ParseWebhook(r, body)
+	if err != nil {
+		return err
+	}
+
+	for _, update := range updates {
+		if err := s.applyUpdate(ctx, carrier.Name(), update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyUpdate records a tracking scan and advances the shipment (and order) state
+// Out-of-order or repeated scans are kept in history but never move the status backwards
+func (s *ShippingService) applyUpdate(ctx context.Context, carrier string, update shipping.TrackingUpdate) error {
+	shipment, err := s.repo.GetByTracking(ctx, carrier, update.TrackingNumber)
+	if errors.Is(err, repository.ErrShipmentNotFound) {
+		// Not ours (or booked outside the platform) - acknowledge so the carrier stops retrying
+		s.log.Warn("Tracking update for unknown shipment", zap.String("carrier", carrier),
+			zap.String("tracking_number", update.TrackingNumber))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	previous := shipment.Status
+	wasShipped := shipment.ShippedAt != nil
+	if advances(previous, update.Status) {
+		shipment.Status = update.Status
+		if update.Status != models.ShipmentException && !wasShipped && statusRank[update.Status] > 0 {
+			shipment.ShippedAt = &update.OccurredAt
+		}
+		if update.Status == models.ShipmentDelivered {
+			shipment.DeliveredAt = &update.OccurredAt
+		}
+	}
+
+	recorded, err := s.repo.RecordEvent(ctx, shipment, &models.ShipmentEvent{
+		Status:      update.Status,
+		Description: update.Description,
+		Location:    update.Location,
+		OccurredAt:  update.OccurredAt,
+	})
+	if err != nil || !recorded {
+		return err
+	}
+
+	if shipment.Status != previous {
+		s.log.Info("Shipment status changed", zap.String("shipment_id", shipment.ID),
+			zap.String("from", previous), zap.String("to", shipment.Status))
+	}
+	if !wasShipped && shipment.ShippedAt != nil {
+		_ = events.Publish(ctx, s.publisher, s.log, events.TypeOrderShipped, events.OrderShipped{
+			OrderID:        shipment.OrderID,
+			UserID:         shipment.UserID,
+			Carrier:        shipment.Carrier,
+			TrackingNumber: shipment.TrackingNumber,
+		})
+	}
+	if previous != models.ShipmentDelivered && shipment.Status == models.ShipmentDelivered {
+		_ = events.Publish(ctx, s.publisher, s.log, events.TypeOrderDelivered, events.OrderDelivered{
+			OrderID:        shipment.OrderID,
+			UserID:         shipment.UserID,
+			Carrier:        shipment.Carrier,
+			TrackingNumber: shipment.TrackingNumber,
+			DeliveredAt:    update.OccurredAt,
+		})
+	}
+	return nil
+}
+
+// advances reports whether a shipment in state from should move to state to
+// Delivered is final; exception can interrupt any state and be followed by a re-attempt
+func advances(from, to string) bool {
+	if from == models.ShipmentDelivered || from == to {
+		return false
+	}
+	if _, known := statusRank[to]; !known && to != models.ShipmentException {
+		return false
+	}
+	if to == models.ShipmentException || from == models.ShipmentException {
+		return true
+	}
+	return statusRank[to] > statusRank[from]
+}
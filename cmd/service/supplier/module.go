@@ -0,0 +1,60 @@
+package supplier
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/lock"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Module provides suppliers and purchase orders: what each supplier charges and how fast they
+// deliver, and restock orders whose receipts add stock to a warehouse. Reorder points alert
+// admins and vendors about low stock and can draft the restock orders themselves.
+type Module struct {
+	handler   *Handler
+	scheduler lock.Service // low-stock scans on the elected leader only; nil when LOW_STOCK_SCAN_INTERVAL=0
+}
+
+func NewModule(deps module.Deps) *Module {
+	service := NewSupplierService(repository.NewSupplierRepository(deps.DB, deps.Log),
+		repository.NewProductRepository(deps.DB, deps.Log), repository.NewWarehouseRepository(deps.DB, deps.Log),
+		repository.NewUserRepository(deps.DB, deps.Log), repository.NewVendorRepository(deps.DB, deps.Log),
+		deps.Notifier, deps.Settings, deps.Events, deps.Clock, deps.Log)
+
+	deps.Jobs.Register(JobLowStockScan, service.handleScanJob)
+
+	if err := deps.Events.Subscribe(events.TypeProductDeleted, "suppliers", service.HandleProductDeleted); err != nil {
+		deps.Log.Error("Failed to subscribe suppliers to event", zap.String("type", events.TypeProductDeleted), zap.Error(err))
+	}
+
+	m := &Module{
+		handler: NewHandler(service, deps.Tokens, deps.Log),
+	}
+	if interval := deps.Config.Inventory.LowStockInterval; interval > 0 {
+		m.scheduler = lock.Singleton(deps.Locks, NewScheduler(deps.Jobs, interval, deps.Log), deps.Config.Locks.LeaderTTL, deps.Log)
+	}
+	return m
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.Supplier{}, &models.SupplierProduct{}, &models.PurchaseOrder{}, &models.PurchaseOrderLine{},
+			&models.ReorderRule{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+// Services returns the low-stock scheduler when scans are enabled
+func (m *Module) Services() []module.Service {
+	if m.scheduler == nil {
+		return nil
+	}
+	return []module.Service{m.scheduler}
+}
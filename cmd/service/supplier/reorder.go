@@ -0,0 +1,237 @@
+package supplier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/notify"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// JobLowStockScan checks stock against reorder points; queued by the scheduler every LOW_STOCK_SCAN_INTERVAL
+const JobLowStockScan = "supplier.low_stock_scan"
+
+// autoDraftNote marks the purchase orders drafted by ScanLowStock
+const autoDraftNote = "Drafted automatically for products at or below their reorder point"
+
+// GetReorderRule returns a product's reorder point
+func (s *SupplierService) GetReorderRule(ctx context.Context, productID string) (*models.ReorderRule, error) {
+	return s.repo.GetReorderRule(ctx, productID)
+}
+
+// SetReorderRule sets a product's reorder point and what to do when stock falls to it
+// Auto-drafting needs a preferred supplier and a reorder quantity
+func (s *SupplierService) SetReorderRule(ctx context.Context, productID string, req *models.ReorderRuleRequest) (*models.ReorderRule, error) {
+	switch {
+	case req.ReorderPoint < 0:
+		return nil, fmt.Errorf("%w: reorder_point cannot be negative", ErrInvalidSupplier)
+	case req.ReorderQuantity < 0:
+		return nil, fmt.Errorf("%w: reorder_quantity cannot be negative", ErrInvalidSupplier)
+	case req.AutoDraft && (req.SupplierID == "" || req.ReorderQuantity == 0):
+		return nil, fmt.Errorf("%w: auto_draft needs a supplier_id and a positive reorder_quantity", ErrInvalidSupplier)
+	}
+	if _, err := s.products.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	if req.SupplierID != "" {
+		if _, err := s.repo.GetByID(ctx, req.SupplierID); err != nil {
+			return nil, err
+		}
+	}
+
+	rule := &models.ReorderRule{
+		ProductID:       productID,
+		ReorderPoint:    req.ReorderPoint,
+		ReorderQuantity: req.ReorderQuantity,
+		SupplierID:      req.SupplierID,
+		AutoDraft:       req.AutoDraft,
+	}
+	if err := s.repo.SetReorderRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.log.Info("Reorder point set", zap.String("product_id", productID), zap.Int("reorder_point", rule.ReorderPoint),
+		zap.Bool("auto_draft", rule.AutoDraft))
+	return s.repo.GetReorderRule(ctx, productID)
+}
+
+// DeleteReorderRule stops watching a product's stock
+func (s *SupplierService) DeleteReorderRule(ctx context.Context, productID string) error {
+	return s.repo.DeleteReorderRule(ctx, productID)
+}
+
+// LowStock returns the products at or below their reorder point
+func (s *SupplierService) LowStock(ctx context.Context) ([]models.LowStockItem, error) {
+	items, err := s.repo.LowStock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.LowStockItem{}
+	}
+	return items, nil
+}
+
+// ScanLowStock checks stock against reorder points
+// Products newly at or below their point are alerted once, to every admin and to the product's
+// vendor; they are alerted again only after stock went back above the point. Products with
+// auto_draft whose stock plus units on order is still at or below the point get a draft purchase
+// order to their preferred supplier, one per supplier, so a scan never drafts what is already on order.
+func (s *SupplierService) ScanLowStock(ctx context.Context) (*models.LowStockScan, error) {
+	if err := s.repo.ClearRecoveredAlerts(ctx); err != nil {
+		return nil, err
+	}
+	items, err := s.repo.LowStock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scan := &models.LowStockScan{LowStock: len(items), PurchaseOrders: []string{}}
+	var fresh []models.LowStockItem
+	for _, item := range items {
+		if item.AlertedAt == nil {
+			fresh = append(fresh, item)
+		}
+	}
+	if len(fresh) > 0 {
+		s.alertLowStock(ctx, fresh)
+		productIDs := make([]string, 0, len(fresh))
+		for _, item := range fresh {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		if err := s.repo.MarkAlerted(ctx, productIDs, s.clock.Now()); err != nil {
+			return nil, err
+		}
+		scan.Alerted = len(fresh)
+	}
+
+	scan.PurchaseOrders = append(scan.PurchaseOrders, s.draftRestocks(ctx, items)...)
+	s.log.Info("Low stock scanned", zap.Int("low_stock", scan.LowStock), zap.Int("alerted", scan.Alerted),
+		zap.Int("drafted", len(scan.PurchaseOrders)))
+	return scan, nil
+}
+
+// alertLowStock sends admins every newly low product and each active vendor their own
+// Delivery failures are logged; the alert isn't retried
+func (s *SupplierService) alertLowStock(ctx context.Context, items []models.LowStockItem) {
+	admins, err := s.users.GetUsersByRole(ctx, models.RoleAdmin)
+	if err != nil {
+		s.log.Error("Failed to load admins for low-stock alert", zap.Error(err))
+	}
+	for _, admin := range admins {
+		s.sendLowStock(ctx, admin.ID, items)
+	}
+
+	byVendor := make(map[string][]models.LowStockItem)
+	var vendorIDs []string
+	for _, item := range items {
+		if item.VendorID == "" {
+			continue
+		}
+		if _, ok := byVendor[item.VendorID]; !ok {
+			vendorIDs = append(vendorIDs, item.VendorID)
+		}
+		byVendor[item.VendorID] = append(byVendor[item.VendorID], item)
+	}
+	if len(vendorIDs) == 0 {
+		return
+	}
+	vendors, err := s.vendors.GetByIDs(ctx, vendorIDs)
+	if err != nil {
+		s.log.Error("Failed to load vendors for low-stock alert", zap.Error(err))
+		return
+	}
+	for _, vendor := range vendors {
+		if vendor.Status == models.VendorActive {
+			s.sendLowStock(ctx, vendor.UserID, byVendor[vendor.ID])
+		}
+	}
+}
+
+func (s *SupplierService) sendLowStock(ctx context.Context, userID string, items []models.LowStockItem) {
+	var body strings.Builder
+	body.WriteString("These products are at or below their reorder point:\n")
+	for _, item := range items {
+		fmt.Fprintf(&body, "\n%s %s: %d in stock (reorder point %d, %d on order)",
+			item.SKU, item.Name, item.Stock, item.ReorderPoint, item.OnOrder)
+	}
+	subject := fmt.Sprintf("Low stock: %d products", len(items))
+	if len(items) == 1 {
+		subject = "Low stock: " + items[0].Name
+	}
+	err := s.notifier.Notify(ctx, userID, notify.Notification{
+		Category: models.NotifyStockAlerts,
+		Subject:  subject,
+		Body:     body.String(),
+	})
+	if err != nil {
+		s.log.Warn("Failed to send low-stock alert", zap.String("user_id", userID), zap.Error(err))
+	}
+}
+
+// draftRestocks drafts one purchase order per preferred supplier for the auto_draft products still
+// short after what is on order, and returns their IDs
+// Products the supplier has no cost on file for are left out; a supplier that can't take the
+// order (inactive, removed) is skipped until the next scan
+func (s *SupplierService) draftRestocks(ctx context.Context, items []models.LowStockItem) []string {
+	bySupplier := make(map[string][]models.LowStockItem)
+	for _, item := range items {
+		if item.AutoDraft && item.SupplierID != "" && item.ReorderQuantity > 0 && item.Stock+item.OnOrder <= item.ReorderPoint {
+			bySupplier[item.SupplierID] = append(bySupplier[item.SupplierID], item)
+		}
+	}
+	supplierIDs := make([]string, 0, len(bySupplier))
+	for id := range bySupplier {
+		supplierIDs = append(supplierIDs, id)
+	}
+	sort.Strings(supplierIDs)
+
+	var drafted []string
+	for _, supplierID := range supplierIDs {
+		short := bySupplier[supplierID]
+		productIDs := make([]string, 0, len(short))
+		for _, item := range short {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		terms, err := s.repo.ProductTerms(ctx, supplierID, productIDs)
+		if err != nil {
+			s.log.Error("Failed to load supplier terms for restock", zap.String("supplier_id", supplierID), zap.Error(err))
+			continue
+		}
+
+		req := &models.PurchaseOrderRequest{SupplierID: supplierID, Note: autoDraftNote}
+		for _, item := range short {
+			if _, ok := terms[item.ProductID]; !ok {
+				s.log.Warn("Preferred supplier has no cost for low-stock product, not drafting it",
+					zap.String("supplier_id", supplierID), zap.String("product_id", item.ProductID))
+				continue
+			}
+			req.Lines = append(req.Lines, models.PurchaseOrderLineItem{ProductID: item.ProductID, Quantity: item.ReorderQuantity})
+		}
+		if len(req.Lines) == 0 {
+			continue
+		}
+
+		order, err := s.CreatePurchaseOrder(ctx, "", req)
+		if err != nil {
+			if errors.Is(err, ErrSupplierInactive) || errors.Is(err, repository.ErrSupplierNotFound) {
+				s.log.Warn("Preferred supplier can't take a restock order", zap.String("supplier_id", supplierID), zap.Error(err))
+			} else {
+				s.log.Error("Failed to draft restock order", zap.String("supplier_id", supplierID), zap.Error(err))
+			}
+			continue
+		}
+		drafted = append(drafted, order.ID)
+	}
+	return drafted
+}
+
+// handleScanJob runs a scan queued by the scheduler
+func (s *SupplierService) handleScanJob(ctx context.Context, job *models.Job) error {
+	_, err := s.ScanLowStock(ctx)
+	return err
+}
@@ -0,0 +1,476 @@
+package supplier
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *SupplierService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *SupplierService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers supplier, purchase order and reorder point routes; all of them are admin-only
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/suppliers", h.handleCreate).Methods("POST")
+	admin.HandleFunc("/suppliers", h.handleList).Methods("GET")
+	admin.HandleFunc("/suppliers/{id}", h.handleGet).Methods("GET")
+	admin.HandleFunc("/suppliers/{id}", h.handleUpdate).Methods("PUT")
+	admin.HandleFunc("/suppliers/{id}/products", h.handleProducts).Methods("GET")
+	admin.HandleFunc("/suppliers/{id}/products/{productId}", h.handleSetProduct).Methods("PUT")
+	admin.HandleFunc("/suppliers/{id}/products/{productId}", h.handleDeleteProduct).Methods("DELETE")
+
+	admin.HandleFunc("/purchase-orders", h.handleCreateOrder).Methods("POST")
+	admin.HandleFunc("/purchase-orders", h.handleListOrders).Methods("GET")
+	admin.HandleFunc("/purchase-orders/{id}", h.handleGetOrder).Methods("GET")
+	admin.HandleFunc("/purchase-orders/{id}/submit", h.handleSubmit).Methods("POST")
+	admin.HandleFunc("/purchase-orders/{id}/receive", h.handleReceive).Methods("POST")
+	admin.HandleFunc("/purchase-orders/{id}/cancel", h.handleCancel).Methods("POST")
+
+	admin.HandleFunc("/products/{id}/reorder-point", h.handleGetReorderRule).Methods("GET")
+	admin.HandleFunc("/products/{id}/reorder-point", h.handleSetReorderRule).Methods("PUT")
+	admin.HandleFunc("/products/{id}/reorder-point", h.handleDeleteReorderRule).Methods("DELETE")
+	admin.HandleFunc("/low-stock", h.handleLowStock).Methods("GET")
+	admin.HandleFunc("/low-stock/scan", h.handleScan).Methods("POST")
+}
+
+// handleCreate handles POST /api/v1/admin/suppliers
+// @Summary Create supplier
+// @Tags Suppliers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.SupplierRequest true "Supplier"
+// @Success 201 {object} models.Supplier
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/suppliers [post]
+func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req models.SupplierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	supplier, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, supplier)
+}
+
+// handleList handles GET /api/v1/admin/suppliers
+// @Summary List suppliers
+// @Tags Suppliers
+// @Produce json
+// @Security BearerAuth
+// @Param active query bool false "Only active suppliers"
+// @Success 200 {array} models.Supplier
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/suppliers [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	suppliers, err := h.service.List(r.Context(), r.URL.Query().Get("active") == "true")
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, suppliers)
+}
+
+// handleGet handles GET /api/v1/admin/suppliers/{id}
+// @Summary Get supplier
+// @Description A supplier with actual_lead_time_days: the average time their latest fully received purchase orders took
+// @Tags Suppliers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Supplier ID"
+// @Success 200 {object} models.Supplier
+// @Failure 404 {string} string "Supplier not found"
+// @Router /admin/suppliers/{id} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	supplier, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, supplier)
+}
+
+// handleUpdate handles PUT /api/v1/admin/suppliers/{id}
+// @Summary Replace supplier
+// @Description Replaces the supplier's details. Inactive suppliers keep their purchase orders but get no new ones.
+// @Tags Suppliers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Supplier ID"
+// @Param request body models.SupplierRequest true "Supplier"
+// @Success 200 {object} models.Supplier
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Supplier not found"
+// @Router /admin/suppliers/{id} [put]
+func (h *Handler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req models.SupplierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	supplier, err := h.service.Update(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, supplier)
+}
+
+// handleProducts handles GET /api/v1/admin/suppliers/{id}/products
+// @Summary List supplier products
+// @Description What the supplier charges for each product they supply, and their promised lead time
+// @Tags Suppliers
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Supplier ID"
+// @Success 200 {array} models.SupplierProduct
+// @Failure 404 {string} string "Supplier not found"
+// @Router /admin/suppliers/{id}/products [get]
+func (h *Handler) handleProducts(w http.ResponseWriter, r *http.Request) {
+	products, err := h.service.Products(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, products)
+}
+
+// handleSetProduct handles PUT /api/v1/admin/suppliers/{id}/products/{productId}
+// @Summary Set supplier product terms
+// @Description Sets the supplier's unit cost and lead time for a product (0 days uses the supplier's). Receiving a purchase order updates the cost to what was paid.
+// @Tags Suppliers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Supplier ID"
+// @Param productId path string true "Product ID"
+// @Param request body models.SupplierProductRequest true "Terms"
+// @Success 200 {object} models.SupplierProduct
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Supplier or product not found"
+// @Router /admin/suppliers/{id}/products/{productId} [put]
+func (h *Handler) handleSetProduct(w http.ResponseWriter, r *http.Request) {
+	var req models.SupplierProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	vars := mux.Vars(r)
+	product, err := h.service.SetProduct(r.Context(), vars["id"], vars["productId"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, product)
+}
+
+// handleDeleteProduct handles DELETE /api/v1/admin/suppliers/{id}/products/{productId}
+// @Summary Remove supplier product
+// @Tags Suppliers
+// @Security BearerAuth
+// @Param id path string true "Supplier ID"
+// @Param productId path string true "Product ID"
+// @Success 204
+// @Failure 404 {string} string "Supplier product not found"
+// @Router /admin/suppliers/{id}/products/{productId} [delete]
+func (h *Handler) handleDeleteProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.service.DeleteProduct(r.Context(), vars["id"], vars["productId"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCreateOrder handles POST /api/v1/admin/purchase-orders
+// @Summary Draft purchase order
+// @Description Drafts a restock order in the supplier's currency. Lines without unit_cost take the supplier's cost on file. Without warehouse_id, received units go to the products' unassigned stock.
+// @Tags Purchase Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.PurchaseOrderRequest true "Purchase order"
+// @Success 201 {object} models.PurchaseOrder
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Supplier, warehouse or product not found"
+// @Failure 409 {string} string "Supplier is inactive"
+// @Router /admin/purchase-orders [post]
+func (h *Handler) handleCreateOrder(w http.ResponseWriter, r *http.Request) {
+	var req models.PurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.CreatePurchaseOrder(r.Context(), auth.ClaimsFromContext(r.Context()).UserID(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, order)
+}
+
+// handleListOrders handles GET /api/v1/admin/purchase-orders
+// @Summary List purchase orders
+// @Tags Purchase Orders
+// @Produce json
+// @Security BearerAuth
+// @Param supplier_id query string false "Only this supplier's orders"
+// @Param status query string false "draft, ordered, partially_received, received or cancelled"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.PurchaseOrderListResponse
+// @Failure 400 {string} string "Invalid request"
+// @Router /admin/purchase-orders [get]
+func (h *Handler) handleListOrders(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	query := r.URL.Query()
+
+	resp, err := h.service.ListPurchaseOrders(r.Context(), query.Get("supplier_id"), query.Get("status"), limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleGetOrder handles GET /api/v1/admin/purchase-orders/{id}
+// @Summary Get purchase order
+// @Tags Purchase Orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 404 {string} string "Purchase order not found"
+// @Router /admin/purchase-orders/{id} [get]
+func (h *Handler) handleGetOrder(w http.ResponseWriter, r *http.Request) {
+	order, err := h.service.GetPurchaseOrder(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, order)
+}
+
+// handleSubmit handles POST /api/v1/admin/purchase-orders/{id}/submit
+// @Summary Submit purchase order
+// @Description Marks a draft as ordered from the supplier and sets expected_at from the promised lead times
+// @Tags Purchase Orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 404 {string} string "Purchase order not found"
+// @Failure 409 {string} string "Purchase order can't be changed in its current state"
+// @Router /admin/purchase-orders/{id}/submit [post]
+func (h *Handler) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	order, err := h.service.Submit(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, order)
+}
+
+// handleReceive handles POST /api/v1/admin/purchase-orders/{id}/receive
+// @Summary Receive purchase order stock
+// @Description Adds arrived units to the order's warehouse and the products' stock. Deliveries can be partial; a line can't receive more than was ordered.
+// @Tags Purchase Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Purchase order ID"
+// @Param request body models.ReceiveRequest true "Units arrived"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Purchase order not found"
+// @Failure 409 {string} string "Purchase order can't receive these units"
+// @Router /admin/purchase-orders/{id}/receive [post]
+func (h *Handler) handleReceive(w http.ResponseWriter, r *http.Request) {
+	var req models.ReceiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.Receive(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, order)
+}
+
+// handleCancel handles POST /api/v1/admin/purchase-orders/{id}/cancel
+// @Summary Cancel purchase order
+// @Description Closes an order that isn't fully received; units already received stay in stock
+// @Tags Purchase Orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 404 {string} string "Purchase order not found"
+// @Failure 409 {string} string "Purchase order can't be changed in its current state"
+// @Router /admin/purchase-orders/{id}/cancel [post]
+func (h *Handler) handleCancel(w http.ResponseWriter, r *http.Request) {
+	order, err := h.service.Cancel(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, order)
+}
+
+// handleGetReorderRule handles GET /api/v1/admin/products/{id}/reorder-point
+// @Summary Get product reorder point
+// @Tags Purchase Orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 200 {object} models.ReorderRule
+// @Failure 404 {string} string "Reorder point not found"
+// @Router /admin/products/{id}/reorder-point [get]
+func (h *Handler) handleGetReorderRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := h.service.GetReorderRule(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, rule)
+}
+
+// handleSetReorderRule handles PUT /api/v1/admin/products/{id}/reorder-point
+// @Summary Set product reorder point
+// @Description At or below reorder_point units in stock, the low-stock scan alerts admins and the product's vendor. With auto_draft it also drafts a purchase order for reorder_quantity units to the preferred supplier.
+// @Tags Purchase Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Param request body models.ReorderRuleRequest true "Reorder point"
+// @Success 200 {object} models.ReorderRule
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Product or supplier not found"
+// @Router /admin/products/{id}/reorder-point [put]
+func (h *Handler) handleSetReorderRule(w http.ResponseWriter, r *http.Request) {
+	var req models.ReorderRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.service.SetReorderRule(r.Context(), mux.Vars(r)["id"], &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, rule)
+}
+
+// handleDeleteReorderRule handles DELETE /api/v1/admin/products/{id}/reorder-point
+// @Summary Remove product reorder point
+// @Tags Purchase Orders
+// @Security BearerAuth
+// @Param id path string true "Product ID"
+// @Success 204
+// @Failure 404 {string} string "Reorder point not found"
+// @Router /admin/products/{id}/reorder-point [delete]
+func (h *Handler) handleDeleteReorderRule(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteReorderRule(r.Context(), mux.Vars(r)["id"]); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleLowStock handles GET /api/v1/admin/low-stock
+// @Summary List low-stock products
+// @Description Products at or below their reorder point, by SKU, with the units on open purchase orders
+// @Tags Purchase Orders
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.LowStockItem
+// @Router /admin/low-stock [get]
+func (h *Handler) handleLowStock(w http.ResponseWriter, r *http.Request) {
+	items, err := h.service.LowStock(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, items)
+}
+
+// handleScan handles POST /api/v1/admin/low-stock/scan
+// @Summary Scan for low stock now
+// @Description Runs the scheduled low-stock scan: alerts newly low products and drafts restock orders
+// @Tags Purchase Orders
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.LowStockScan
+// @Router /admin/low-stock/scan [post]
+func (h *Handler) handleScan(w http.ResponseWriter, r *http.Request) {
+	scan, err := h.service.ScanLowStock(r.Context())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, scan)
+}
+
+// writeError maps supplier errors to 400/404/409/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidSupplier):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrSupplierNotFound):
+		http.Error(w, "Supplier not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrSupplierProductNotFound):
+		http.Error(w, "Supplier product not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrPurchaseOrderNotFound):
+		http.Error(w, "Purchase order not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrReorderRuleNotFound):
+		http.Error(w, "Reorder point not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrWarehouseNotFound):
+		http.Error(w, "Warehouse not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrProductNotFound):
+		http.Error(w, "Product not found", http.StatusNotFound)
+	case errors.Is(err, ErrSupplierInactive):
+		http.Error(w, "Supplier is inactive", http.StatusConflict)
+	case errors.Is(err, ErrPurchaseOrderState):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.log.Error("Supplier request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,42 @@
+package supplier
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"go.uber.org/zap"
+)
+
+// Scheduler queues a low-stock scan every LOW_STOCK_SCAN_INTERVAL
+// It runs on the elected leader only, so one scan is queued per interval; job workers run it
+type Scheduler struct {
+	jobs     *jobs.Processor
+	interval time.Duration
+	log      *zap.Logger
+}
+
+func NewScheduler(processor *jobs.Processor, interval time.Duration, log *zap.Logger) *Scheduler {
+	return &Scheduler{jobs: processor, interval: interval, log: log}
+}
+
+func (s *Scheduler) Name() string {
+	return "low-stock-scan"
+}
+
+// Run queues scans until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := s.jobs.Enqueue(ctx, JobLowStockScan, nil, jobs.MaxAttempts(1)); err != nil {
+				s.log.Error("Failed to queue low-stock scan", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,432 @@
+package supplier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/notify"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"go.uber.org/zap"
+)
+
+const (
+	maxPurchaseOrderLines = 200
+	maxNoteLength         = 1000
+	maxLeadTimeDays       = 365
+)
+
+var (
+	// ErrInvalidSupplier wraps validation problems with supplier and purchase order requests
+	ErrInvalidSupplier = errors.New("invalid supplier request")
+	// ErrSupplierInactive is returned when drafting a purchase order for an inactive supplier
+	ErrSupplierInactive = errors.New("supplier is inactive")
+	// ErrPurchaseOrderState is returned when a purchase order can't make the requested move from its state
+	ErrPurchaseOrderState = errors.New("purchase order can't be changed in its current state")
+)
+
+// SupplierService manages suppliers, what they charge, and purchase orders for restocking
+// Receiving a purchase order adds the units to its warehouse and the products' stock and
+// publishes product.updated, like any other stock change. Lead times are tracked both as
+// promised (per supplier and product) and as delivered (from received orders).
+// Reorder points close the loop: see ScanLowStock.
+type SupplierService struct {
+	repo       *repository.SupplierRepository
+	products   repository.ProductStore
+	warehouses *repository.WarehouseRepository
+	users      *repository.UserRepository   // admins to alert
+	vendors    *repository.VendorRepository // vendors to alert about their products
+	notifier   *notify.Notifier
+	settings   *settings.Store // currency of new suppliers
+	publisher  events.Publisher
+	clock      clock.Clock
+	log        *zap.Logger
+}
+
+func NewSupplierService(repo *repository.SupplierRepository, products repository.ProductStore,
+	warehouses *repository.WarehouseRepository, users *repository.UserRepository, vendors *repository.VendorRepository,
+	notifier *notify.Notifier, storeSettings *settings.Store, publisher events.Publisher,
+	clk clock.Clock, log *zap.Logger) *SupplierService {
+	return &SupplierService{
+		repo:       repo,
+		products:   products,
+		warehouses: warehouses,
+		users:      users,
+		vendors:    vendors,
+		notifier:   notifier,
+		settings:   storeSettings,
+		publisher:  publisher,
+		clock:      clk,
+		log:        log,
+	}
+}
+
+// Create validates req and adds a supplier
+func (s *SupplierService) Create(ctx context.Context, req *models.SupplierRequest) (*models.Supplier, error) {
+	supplier := &models.Supplier{}
+	if err := s.apply(ctx, supplier, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, supplier); err != nil {
+		return nil, err
+	}
+	s.log.Info("Supplier created", zap.String("id", supplier.ID), zap.String("name", supplier.Name))
+	return supplier, nil
+}
+
+// Update replaces a supplier's details
+func (s *SupplierService) Update(ctx context.Context, id string, req *models.SupplierRequest) (*models.Supplier, error) {
+	supplier, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, supplier, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, supplier); err != nil {
+		return nil, err
+	}
+	s.log.Info("Supplier updated", zap.String("id", supplier.ID))
+	return s.withLeadTimes(ctx, supplier)
+}
+
+// Get returns a supplier with their delivered lead time
+func (s *SupplierService) Get(ctx context.Context, id string) (*models.Supplier, error) {
+	supplier, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withLeadTimes(ctx, supplier)
+}
+
+// List returns suppliers by name; activeOnly skips inactive ones
+func (s *SupplierService) List(ctx context.Context, activeOnly bool) ([]models.Supplier, error) {
+	suppliers, err := s.repo.List(ctx, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	if suppliers == nil {
+		suppliers = []models.Supplier{}
+	}
+	return suppliers, nil
+}
+
+// withLeadTimes sets how long the supplier's latest received orders took on average
+func (s *SupplierService) withLeadTimes(ctx context.Context, supplier *models.Supplier) (*models.Supplier, error) {
+	durations, err := s.repo.LeadTimes(ctx, supplier.ID)
+	if err != nil {
+		return nil, err
+	}
+	supplier.ReceivedOrders = len(durations)
+	if len(durations) > 0 {
+		var sum time.Duration
+		for _, d := range durations {
+			sum += d
+		}
+		days := math.Round(sum.Hours()/24/float64(len(durations))*10) / 10
+		supplier.ActualLeadTimeDays = &days
+	}
+	return supplier, nil
+}
+
+func (s *SupplierService) apply(ctx context.Context, supplier *models.Supplier, req *models.SupplierRequest) error {
+	name := strings.TrimSpace(req.Name)
+	email := strings.TrimSpace(req.Email)
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = s.settings.DefaultCurrency(ctx)
+	}
+
+	switch {
+	case name == "" || len(name) > 255:
+		return fmt.Errorf("%w: name is required and at most 255 characters", ErrInvalidSupplier)
+	case len(currency) != 3:
+		return fmt.Errorf("%w: currency must be an ISO 4217 code", ErrInvalidSupplier)
+	case req.LeadTimeDays < 0 || req.LeadTimeDays > maxLeadTimeDays:
+		return fmt.Errorf("%w: lead_time_days must be between 0 and %d", ErrInvalidSupplier, maxLeadTimeDays)
+	}
+	if email != "" {
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			return fmt.Errorf("%w: email is not a valid address", ErrInvalidSupplier)
+		}
+	}
+
+	supplier.Name = name
+	supplier.Email = email
+	supplier.Phone = strings.TrimSpace(req.Phone)
+	supplier.Currency = currency
+	supplier.LeadTimeDays = req.LeadTimeDays
+	supplier.Active = req.Active == nil || *req.Active
+	supplier.Notes = strings.TrimSpace(req.Notes)
+	return nil
+}
+
+// Products returns the supplier's terms for each product they supply
+func (s *SupplierService) Products(ctx context.Context, supplierID string) ([]models.SupplierProduct, error) {
+	if _, err := s.repo.GetByID(ctx, supplierID); err != nil {
+		return nil, err
+	}
+	products, err := s.repo.Products(ctx, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	if products == nil {
+		products = []models.SupplierProduct{}
+	}
+	return products, nil
+}
+
+// SetProduct records what a supplier charges for a product and how fast they deliver it
+func (s *SupplierService) SetProduct(ctx context.Context, supplierID, productID string, req *models.SupplierProductRequest) (*models.SupplierProduct, error) {
+	switch {
+	case req.UnitCost < 0:
+		return nil, fmt.Errorf("%w: unit_cost cannot be negative", ErrInvalidSupplier)
+	case req.LeadTimeDays < 0 || req.LeadTimeDays > maxLeadTimeDays:
+		return nil, fmt.Errorf("%w: lead_time_days must be between 0 and %d", ErrInvalidSupplier, maxLeadTimeDays)
+	case len(strings.TrimSpace(req.SupplierSKU)) > 64:
+		return nil, fmt.Errorf("%w: supplier_sku must be at most 64 characters", ErrInvalidSupplier)
+	}
+	if _, err := s.repo.GetByID(ctx, supplierID); err != nil {
+		return nil, err
+	}
+	if _, err := s.products.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	product := &models.SupplierProduct{
+		SupplierID:   supplierID,
+		ProductID:    productID,
+		SupplierSKU:  strings.TrimSpace(req.SupplierSKU),
+		UnitCost:     req.UnitCost,
+		LeadTimeDays: req.LeadTimeDays,
+	}
+	if err := s.repo.SetProduct(ctx, product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// DeleteProduct removes a product from a supplier's list
+func (s *SupplierService) DeleteProduct(ctx context.Context, supplierID, productID string) error {
+	return s.repo.DeleteProduct(ctx, supplierID, productID)
+}
+
+// CreatePurchaseOrder drafts a purchase order from an active supplier
+// Lines without a unit_cost take the supplier's cost for the product, which must then be on file
+func (s *SupplierService) CreatePurchaseOrder(ctx context.Context, adminID string, req *models.PurchaseOrderRequest) (*models.PurchaseOrder, error) {
+	note := strings.TrimSpace(req.Note)
+	switch {
+	case req.SupplierID == "":
+		return nil, fmt.Errorf("%w: supplier_id is required", ErrInvalidSupplier)
+	case len(req.Lines) == 0 || len(req.Lines) > maxPurchaseOrderLines:
+		return nil, fmt.Errorf("%w: a purchase order needs 1 to %d lines", ErrInvalidSupplier, maxPurchaseOrderLines)
+	case len(note) > maxNoteLength:
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalidSupplier, maxNoteLength)
+	}
+
+	supplier, err := s.repo.GetByID(ctx, req.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+	if !supplier.Active {
+		return nil, ErrSupplierInactive
+	}
+	if req.WarehouseID != "" {
+		if _, err := s.warehouses.GetByID(ctx, req.WarehouseID); err != nil {
+			return nil, err
+		}
+	}
+
+	productIDs := make([]string, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		productIDs = append(productIDs, line.ProductID)
+	}
+	terms, err := s.repo.ProductTerms(ctx, supplier.ID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &models.PurchaseOrder{
+		SupplierID:  supplier.ID,
+		WarehouseID: req.WarehouseID,
+		Status:      models.PurchaseOrderDraft,
+		Currency:    supplier.Currency,
+		Note:        note,
+		CreatedBy:   adminID,
+		Lines:       make([]models.PurchaseOrderLine, 0, len(req.Lines)),
+	}
+	seen := make(map[string]bool, len(req.Lines))
+	for _, item := range req.Lines {
+		switch {
+		case item.ProductID == "" || item.Quantity <= 0:
+			return nil, fmt.Errorf("%w: every line needs a product_id and a positive quantity", ErrInvalidSupplier)
+		case seen[item.ProductID]:
+			return nil, fmt.Errorf("%w: product %s is on two lines", ErrInvalidSupplier, item.ProductID)
+		case item.UnitCost != nil && *item.UnitCost < 0:
+			return nil, fmt.Errorf("%w: unit_cost cannot be negative", ErrInvalidSupplier)
+		}
+		seen[item.ProductID] = true
+		if _, err := s.products.GetByID(ctx, item.ProductID); err != nil {
+			return nil, err
+		}
+
+		line := models.PurchaseOrderLine{ProductID: item.ProductID, Quantity: item.Quantity}
+		if item.UnitCost != nil {
+			line.UnitCost = *item.UnitCost
+		} else if term, ok := terms[item.ProductID]; ok {
+			line.UnitCost = term.UnitCost
+		} else {
+			return nil, fmt.Errorf("%w: no unit_cost given or on file for product %s", ErrInvalidSupplier, item.ProductID)
+		}
+		order.Lines = append(order.Lines, line)
+		order.Total += line.UnitCost * int64(line.Quantity)
+	}
+
+	if err := s.repo.CreatePurchaseOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	s.log.Info("Purchase order drafted", zap.String("id", order.ID), zap.String("supplier_id", supplier.ID),
+		zap.Int("lines", len(order.Lines)), zap.Int64("total", order.Total))
+	return order, nil
+}
+
+// GetPurchaseOrder returns a purchase order with its lines
+func (s *SupplierService) GetPurchaseOrder(ctx context.Context, id string) (*models.PurchaseOrder, error) {
+	return s.repo.GetPurchaseOrder(ctx, id)
+}
+
+// ListPurchaseOrders returns a page of purchase orders, optionally of one supplier and in one state
+func (s *SupplierService) ListPurchaseOrders(ctx context.Context, supplierID, status string,
+	limit, offset int) (*models.PurchaseOrderListResponse, error) {
+	switch status {
+	case "", models.PurchaseOrderDraft, models.PurchaseOrderOrdered, models.PurchaseOrderPartial,
+		models.PurchaseOrderReceived, models.PurchaseOrderCancelled:
+	default:
+		return nil, fmt.Errorf("%w: status must be draft, ordered, partially_received, received or cancelled", ErrInvalidSupplier)
+	}
+
+	orders, total, err := s.repo.ListPurchaseOrders(ctx, repository.PurchaseOrderFilter{SupplierID: supplierID, Status: status}, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if orders == nil {
+		orders = []models.PurchaseOrder{}
+	}
+	return &models.PurchaseOrderListResponse{PurchaseOrders: orders, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Submit marks a draft as sent to the supplier and sets when it is expected: ordered_at plus the
+// longest promised lead time of its products (or the supplier's)
+func (s *SupplierService) Submit(ctx context.Context, id string) (*models.PurchaseOrder, error) {
+	order, err := s.repo.GetPurchaseOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	supplier, err := s.repo.GetByID(ctx, order.SupplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]string, 0, len(order.Lines))
+	for _, line := range order.Lines {
+		productIDs = append(productIDs, line.ProductID)
+	}
+	terms, err := s.repo.ProductTerms(ctx, supplier.ID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	leadTime := 0
+	for _, id := range productIDs {
+		days := supplier.LeadTimeDays
+		if term, ok := terms[id]; ok && term.LeadTimeDays > 0 {
+			days = term.LeadTimeDays
+		}
+		leadTime = max(leadTime, days)
+	}
+
+	now := s.clock.Now()
+	fields := map[string]interface{}{"status": models.PurchaseOrderOrdered, "ordered_at": now, "expected_at": now.AddDate(0, 0, leadTime)}
+	return s.move(ctx, id, []string{models.PurchaseOrderDraft}, fields)
+}
+
+// Cancel closes a purchase order; units already received stay in stock
+func (s *SupplierService) Cancel(ctx context.Context, id string) (*models.PurchaseOrder, error) {
+	from := []string{models.PurchaseOrderDraft, models.PurchaseOrderOrdered, models.PurchaseOrderPartial}
+	return s.move(ctx, id, from, map[string]interface{}{"status": models.PurchaseOrderCancelled})
+}
+
+func (s *SupplierService) move(ctx context.Context, id string, from []string, fields map[string]interface{}) (*models.PurchaseOrder, error) {
+	moved, err := s.repo.UpdatePurchaseOrder(ctx, id, from, fields)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.repo.GetPurchaseOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !moved {
+		return nil, ErrPurchaseOrderState
+	}
+	s.log.Info("Purchase order moved", zap.String("id", id), zap.String("status", order.Status))
+	return order, nil
+}
+
+// Receive books arrived units of an ordered purchase order into stock
+// Partial deliveries are fine; each line can receive up to what was ordered
+func (s *SupplierService) Receive(ctx context.Context, id string, req *models.ReceiveRequest) (*models.PurchaseOrder, error) {
+	if len(req.Lines) == 0 {
+		return nil, fmt.Errorf("%w: lines are required", ErrInvalidSupplier)
+	}
+	order, err := s.repo.GetPurchaseOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make(map[string]bool, len(order.Lines))
+	for _, line := range order.Lines {
+		ordered[line.ProductID] = true
+	}
+	seen := make(map[string]bool, len(req.Lines))
+	for _, item := range req.Lines {
+		switch {
+		case item.Quantity <= 0:
+			return nil, fmt.Errorf("%w: every line needs a positive quantity", ErrInvalidSupplier)
+		case !ordered[item.ProductID]:
+			return nil, fmt.Errorf("%w: product %s is not on the purchase order", ErrInvalidSupplier, item.ProductID)
+		case seen[item.ProductID]:
+			return nil, fmt.Errorf("%w: product %s is on two lines", ErrInvalidSupplier, item.ProductID)
+		}
+		seen[item.ProductID] = true
+	}
+
+	if err := s.repo.Receive(ctx, order, req.Lines, s.clock.Now()); err != nil {
+		if errors.Is(err, repository.ErrNotReceivable) {
+			return nil, fmt.Errorf("%w: only ordered purchase orders receive stock, up to the quantity ordered", ErrPurchaseOrderState)
+		}
+		return nil, err
+	}
+
+	for _, item := range req.Lines {
+		s.log.Info("Purchase order stock received", zap.String("id", id),
+			zap.String("product_id", item.ProductID), zap.Int("quantity", item.Quantity))
+		_ = events.Publish(ctx, s.publisher, s.log, events.TypeProductUpdated, events.ProductUpdated{ProductID: item.ProductID})
+	}
+	return s.repo.GetPurchaseOrder(ctx, id)
+}
+
+// HandleProductDeleted removes a deleted product from every supplier's list and drops its reorder point
+func (s *SupplierService) HandleProductDeleted(ctx context.Context, event events.Event) error {
+	var payload events.ProductDeleted
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+	return s.repo.DeleteProductEverywhere(ctx, payload.ProductID)
+}
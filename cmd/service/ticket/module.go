@@ -0,0 +1,45 @@
+package ticket
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Module provides customer support tickets: customers raise them, optionally about an order,
+// admins answer from the ticket queue, and store replies are emailed to the customer
+type Module struct {
+	handler *Handler
+}
+
+func NewModule(deps module.Deps) *Module {
+	service := NewTicketService(repository.NewTicketRepository(deps.DB, deps.Log),
+		repository.NewOrderRepository(deps.DB, deps.Log), repository.NewUserRepository(deps.DB, deps.Log),
+		deps.Mailer, deps.Notifier, deps.Events, deps.Cache, deps.Clock, deps.Log)
+
+	if err := deps.Events.Subscribe(events.TypeTicketMessagePosted, "tickets", service.HandleMessagePosted); err != nil {
+		deps.Log.Error("Failed to subscribe tickets to event", zap.String("type", events.TypeTicketMessagePosted), zap.Error(err))
+	}
+
+	return &Module{
+		handler: NewHandler(service, deps.Tokens, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.Ticket{}, &models.TicketMessage{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+func (m *Module) Services() []module.Service {
+	return nil
+}
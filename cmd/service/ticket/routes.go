@@ -0,0 +1,266 @@
+package ticket
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *TicketService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *TicketService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers the customer ticket routes and the admin ticket queue
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	tickets := router.PathPrefix("/tickets").Subrouter()
+	tickets.Use(auth.Authenticate(h.tokens))
+	tickets.HandleFunc("", h.handleListMine).Methods("GET")
+	tickets.HandleFunc("", h.handleCreate).Methods("POST")
+	tickets.HandleFunc("/{id}", h.handleGet).Methods("GET")
+	tickets.HandleFunc("/{id}/messages", h.handleReply).Methods("POST")
+	tickets.HandleFunc("/{id}/close", h.handleClose).Methods("POST")
+
+	admin := router.PathPrefix("/admin/tickets").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("", h.handleAdminList).Methods("GET")
+	admin.HandleFunc("/{id}", h.handleAdminGet).Methods("GET")
+	admin.HandleFunc("/{id}/messages", h.handleAdminReply).Methods("POST")
+	admin.HandleFunc("/{id}/close", h.handleAdminClose).Methods("POST")
+}
+
+// handleListMine handles GET /api/v1/tickets
+// @Summary List my tickets
+// @Description The caller's support tickets, most recent activity first, without messages
+// @Tags Tickets
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "open, answered or closed"
+// @Param order_id query string false "Only tickets about this order"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.TicketListResponse
+// @Failure 400 {string} string "Invalid request"
+// @Router /tickets [get]
+func (h *Handler) handleListMine(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	query := r.URL.Query()
+	h.list(w, r, repository.TicketFilter{
+		UserID:  auth.ClaimsFromContext(r.Context()).UserID(),
+		Status:  query.Get("status"),
+		OrderID: query.Get("order_id"),
+	}, limit, offset)
+}
+
+// handleCreate handles POST /api/v1/tickets
+// @Summary Raise a ticket
+// @Description Opens a support ticket with its first message, optionally about one of the caller's orders. Admins are notified.
+// @Tags Tickets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateTicketRequest true "Ticket"
+// @Success 201 {object} models.Ticket
+// @Failure 400 {string} string "Invalid request"
+// @Router /tickets [post]
+func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	claims := auth.ClaimsFromContext(r.Context())
+	ticket, err := h.service.Create(r.Context(), claims.UserID(), claims.Role, &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, ticket)
+}
+
+// handleGet handles GET /api/v1/tickets/{id}
+// @Summary Get my ticket
+// @Description One of the caller's tickets with its messages, oldest first
+// @Tags Tickets
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Ticket ID"
+// @Success 200 {object} models.Ticket
+// @Failure 404 {string} string "Ticket not found"
+// @Router /tickets/{id} [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	h.get(w, r, false)
+}
+
+// handleReply handles POST /api/v1/tickets/{id}/messages
+// @Summary Reply to my ticket
+// @Description Adds the caller's message to one of their tickets; the ticket goes back to open, also when it was closed
+// @Tags Tickets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Ticket ID"
+// @Param request body models.TicketReplyRequest true "Message"
+// @Success 201 {object} models.TicketMessage
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Ticket not found"
+// @Router /tickets/{id}/messages [post]
+func (h *Handler) handleReply(w http.ResponseWriter, r *http.Request) {
+	h.reply(w, r, false)
+}
+
+// handleClose handles POST /api/v1/tickets/{id}/close
+// @Summary Close my ticket
+// @Tags Tickets
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Ticket ID"
+// @Success 200 {object} models.Ticket
+// @Failure 404 {string} string "Ticket not found"
+// @Router /tickets/{id}/close [post]
+func (h *Handler) handleClose(w http.ResponseWriter, r *http.Request) {
+	h.close(w, r, false)
+}
+
+// handleAdminList handles GET /api/v1/admin/tickets
+// @Summary List tickets
+// @Description Every support ticket, most recent activity first, without messages. status=open lists the ones waiting for the store.
+// @Tags Tickets
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "open, answered or closed"
+// @Param user_id query string false "Only this customer's tickets"
+// @Param order_id query string false "Only tickets about this order"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {object} models.TicketListResponse
+// @Failure 400 {string} string "Invalid request"
+// @Router /admin/tickets [get]
+func (h *Handler) handleAdminList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	query := r.URL.Query()
+	h.list(w, r, repository.TicketFilter{
+		UserID:  query.Get("user_id"),
+		Status:  query.Get("status"),
+		OrderID: query.Get("order_id"),
+	}, limit, offset)
+}
+
+// handleAdminGet handles GET /api/v1/admin/tickets/{id}
+// @Summary Get ticket
+// @Tags Tickets
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Ticket ID"
+// @Success 200 {object} models.Ticket
+// @Failure 404 {string} string "Ticket not found"
+// @Router /admin/tickets/{id} [get]
+func (h *Handler) handleAdminGet(w http.ResponseWriter, r *http.Request) {
+	h.get(w, r, true)
+}
+
+// handleAdminReply handles POST /api/v1/admin/tickets/{id}/messages
+// @Summary Reply to ticket
+// @Description Adds the store's reply; the ticket becomes answered and the customer is emailed the reply
+// @Tags Tickets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Ticket ID"
+// @Param request body models.TicketReplyRequest true "Message"
+// @Success 201 {object} models.TicketMessage
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Ticket not found"
+// @Router /admin/tickets/{id}/messages [post]
+func (h *Handler) handleAdminReply(w http.ResponseWriter, r *http.Request) {
+	h.reply(w, r, true)
+}
+
+// handleAdminClose handles POST /api/v1/admin/tickets/{id}/close
+// @Summary Close ticket
+// @Tags Tickets
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Ticket ID"
+// @Success 200 {object} models.Ticket
+// @Failure 404 {string} string "Ticket not found"
+// @Router /admin/tickets/{id}/close [post]
+func (h *Handler) handleAdminClose(w http.ResponseWriter, r *http.Request) {
+	h.close(w, r, true)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request, filter repository.TicketFilter, limit, offset int) {
+	resp, err := h.service.List(r.Context(), filter, limit, offset)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, asAdmin bool) {
+	claims := auth.ClaimsFromContext(r.Context())
+	ticket, err := h.service.Get(r.Context(), mux.Vars(r)["id"], claims.UserID(), asAdmin)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, ticket)
+}
+
+func (h *Handler) reply(w http.ResponseWriter, r *http.Request, asAdmin bool) {
+	var req models.TicketReplyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	claims := auth.ClaimsFromContext(r.Context())
+	message, err := h.service.Reply(r.Context(), mux.Vars(r)["id"], claims.UserID(), claims.Role, asAdmin, &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, message)
+}
+
+func (h *Handler) close(w http.ResponseWriter, r *http.Request, asAdmin bool) {
+	claims := auth.ClaimsFromContext(r.Context())
+	ticket, err := h.service.Close(r.Context(), mux.Vars(r)["id"], claims.UserID(), asAdmin)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, ticket)
+}
+
+// writeError maps ticket errors to 400/404/500
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidTicket):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrTicketNotFound):
+		http.Error(w, "Ticket not found", http.StatusNotFound)
+	default:
+		h.log.Error("Ticket request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,304 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/email"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/notify"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	maxSubjectLength = 200
+	maxMessageLength = 5000
+
+	// sentTTL is how long a reply is remembered as emailed; it outlasts the bus's redelivery
+	sentTTL = 24 * time.Hour
+)
+
+// ErrInvalidTicket wraps validation problems with ticket requests
+var ErrInvalidTicket = errors.New("invalid ticket")
+
+var statusSet = map[string]struct{}{
+	models.TicketOpen:     {},
+	models.TicketAnswered: {},
+	models.TicketClosed:   {},
+}
+
+// TicketService runs customer support tickets: a customer raises one, optionally about one of
+// their orders, and the thread goes back and forth with the store until either side closes it.
+// A customer message leaves the ticket open for the store; an admin reply marks it answered and
+// emails the customer. A message on a closed ticket opens it again.
+type TicketService struct {
+	repo      *repository.TicketRepository
+	orders    *repository.OrderRepository
+	users     *repository.UserRepository
+	mailer    *email.Mailer
+	notifier  *notify.Notifier
+	publisher events.Publisher
+	cache     cache.Cache
+	clock     clock.Clock
+	log       *zap.Logger
+}
+
+func NewTicketService(repo *repository.TicketRepository, orders *repository.OrderRepository, users *repository.UserRepository,
+	mailer *email.Mailer, notifier *notify.Notifier, publisher events.Publisher, appCache cache.Cache, clk clock.Clock, log *zap.Logger) *TicketService {
+	return &TicketService{
+		repo:      repo,
+		orders:    orders,
+		users:     users,
+		mailer:    mailer,
+		notifier:  notifier,
+		publisher: publisher,
+		cache:     appCache,
+		clock:     clk,
+		log:       log,
+	}
+}
+
+// Create raises a ticket for userID with its first message
+// req.OrderID, when set, must be one of userID's orders
+func (s *TicketService) Create(ctx context.Context, userID, role string, req *models.CreateTicketRequest) (*models.Ticket, error) {
+	subject := strings.TrimSpace(req.Subject)
+	switch {
+	case subject == "":
+		return nil, fmt.Errorf("%w: subject is required", ErrInvalidTicket)
+	case len(subject) > maxSubjectLength:
+		return nil, fmt.Errorf("%w: subject must be at most %d characters", ErrInvalidTicket, maxSubjectLength)
+	}
+	body, err := messageBody(req.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now().UTC()
+	ticket := &models.Ticket{
+		UserID:        userID,
+		Subject:       subject,
+		Status:        models.TicketOpen,
+		LastMessageAt: now,
+	}
+	if orderID := strings.TrimSpace(req.OrderID); orderID != "" {
+		order, err := s.orders.Get(ctx, orderID, false)
+		if errors.Is(err, repository.ErrOrderNotFound) || (err == nil && order.UserID != userID) {
+			return nil, fmt.Errorf("%w: order_id is not one of your orders", ErrInvalidTicket)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ticket.OrderID, ticket.OrderNumber = order.ID, order.OrderNumber
+	}
+
+	message := &models.TicketMessage{
+		AuthorID:   userID,
+		AuthorName: s.authorName(ctx, userID),
+		AuthorRole: role,
+		Body:       body,
+		CreatedAt:  now,
+	}
+	if err := s.repo.Create(ctx, ticket, message); err != nil {
+		return nil, err
+	}
+	s.log.Info("Ticket raised", zap.String("ticket_id", ticket.ID), zap.String("user_id", userID),
+		zap.String("order_id", ticket.OrderID))
+	s.publish(ctx, ticket, message, false, true)
+
+	ticket.Messages = []models.TicketMessage{*message}
+	return ticket, nil
+}
+
+// Get returns a ticket with its messages; customers only see their own
+func (s *TicketService) Get(ctx context.Context, id, userID string, isAdmin bool) (*models.Ticket, error) {
+	ticket, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && ticket.UserID != userID {
+		return nil, repository.ErrTicketNotFound
+	}
+	if ticket.Messages == nil {
+		ticket.Messages = []models.TicketMessage{}
+	}
+	return ticket, nil
+}
+
+// List returns a page of tickets, most recent activity first
+func (s *TicketService) List(ctx context.Context, filter repository.TicketFilter, limit, offset int) (*models.TicketListResponse, error) {
+	if _, ok := statusSet[filter.Status]; filter.Status != "" && !ok {
+		return nil, fmt.Errorf("%w: status must be open, answered or closed", ErrInvalidTicket)
+	}
+	tickets, total, err := s.repo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if tickets == nil {
+		tickets = []models.Ticket{}
+	}
+	return &models.TicketListResponse{Tickets: tickets, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Reply adds a message to a ticket: from the customer when asAdmin is false, from the store otherwise
+func (s *TicketService) Reply(ctx context.Context, id, userID, role string, asAdmin bool, req *models.TicketReplyRequest) (*models.TicketMessage, error) {
+	body, err := messageBody(req.Message)
+	if err != nil {
+		return nil, err
+	}
+	ticket, err := s.Get(ctx, id, userID, asAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	status := models.TicketOpen
+	if asAdmin {
+		status = models.TicketAnswered
+	}
+	now := s.clock.Now().UTC()
+	message := &models.TicketMessage{
+		TicketID:   ticket.ID,
+		AuthorID:   userID,
+		AuthorName: s.authorName(ctx, userID),
+		AuthorRole: role,
+		Body:       body,
+		CreatedAt:  now,
+	}
+	if err := s.repo.AddMessage(ctx, message, status, now); err != nil {
+		return nil, err
+	}
+	s.log.Info("Ticket message added", zap.String("ticket_id", ticket.ID), zap.String("author_id", userID),
+		zap.Bool("from_store", asAdmin))
+	s.publish(ctx, ticket, message, asAdmin, false)
+	return message, nil
+}
+
+// Close closes a ticket; closing a closed ticket changes nothing
+func (s *TicketService) Close(ctx context.Context, id, userID string, isAdmin bool) (*models.Ticket, error) {
+	if _, err := s.Get(ctx, id, userID, isAdmin); err != nil {
+		return nil, err
+	}
+	closed, err := s.repo.Close(ctx, id, s.clock.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if closed {
+		s.log.Info("Ticket closed", zap.String("ticket_id", id), zap.String("user_id", userID))
+	}
+	return s.Get(ctx, id, userID, isAdmin)
+}
+
+// HandleMessagePosted emails the customer the store's reply, or alerts admins to a customer's message
+// Replies are transactional email, sent whatever the customer's notification preferences, and
+// sent once per event even when the bus delivers it again
+func (s *TicketService) HandleMessagePosted(ctx context.Context, event events.Event) error {
+	var payload events.TicketMessagePosted
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	if !payload.FromStore {
+		subject := "Ticket updated: " + payload.Subject
+		if payload.First {
+			subject = "New ticket: " + payload.Subject
+		}
+		body := payload.Body
+		if payload.OrderNumber != "" {
+			body = "Order " + payload.OrderNumber + ". " + body
+		}
+		s.alertAdmins(ctx, subject, body)
+		return nil
+	}
+
+	user, err := s.users.GetUserByID(ctx, payload.UserID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		s.log.Warn("Skipping ticket reply email for unknown user", zap.String("user_id", payload.UserID),
+			zap.String("ticket_id", payload.TicketID))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	key := "ticket-email:" + event.ID
+	count, err := s.cache.Incr(ctx, key, sentTTL)
+	if err != nil {
+		return err
+	}
+	if count > 1 {
+		return nil
+	}
+	err = s.mailer.SendAsync(ctx, email.TemplateTicketReply, user.Locale, user.Email, user.FirstName, email.Data{
+		"TicketSubject": payload.Subject,
+		"OrderNumber":   payload.OrderNumber,
+		"Message":       payload.Body,
+	})
+	if err != nil {
+		// Forget the event so the bus's retry sends it
+		if delErr := s.cache.Delete(ctx, key); delErr != nil {
+			s.log.Warn("Failed to clear ticket email marker", zap.String("event_id", event.ID), zap.Error(delErr))
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *TicketService) publish(ctx context.Context, ticket *models.Ticket, message *models.TicketMessage, fromStore, first bool) {
+	_ = events.Publish(ctx, s.publisher, s.log, events.TypeTicketMessagePosted, events.TicketMessagePosted{
+		TicketID:    ticket.ID,
+		MessageID:   message.ID,
+		UserID:      ticket.UserID,
+		Subject:     ticket.Subject,
+		OrderNumber: ticket.OrderNumber,
+		Body:        message.Body,
+		FromStore:   fromStore,
+		First:       first,
+	})
+}
+
+// alertAdmins notifies every admin on their order_updates channel
+// Failures are logged; the message is already saved and shows in the admin ticket queue
+func (s *TicketService) alertAdmins(ctx context.Context, subject, body string) {
+	admins, err := s.users.GetUsersByRole(ctx, models.RoleAdmin)
+	if err != nil {
+		s.log.Error("Failed to load admins for ticket alert", zap.Error(err))
+		return
+	}
+	for _, admin := range admins {
+		err := s.notifier.Notify(ctx, admin.ID, notify.Notification{
+			Category: models.NotifyOrderUpdates,
+			Subject:  subject,
+			Body:     body,
+		})
+		if err != nil {
+			s.log.Warn("Failed to send ticket alert", zap.String("user_id", admin.ID), zap.Error(err))
+		}
+	}
+}
+
+// authorName is the author's name as messages show it; empty when it can't be looked up
+func (s *TicketService) authorName(ctx context.Context, userID string) string {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		s.log.Warn("Failed to look up ticket message author", zap.String("user_id", userID), zap.Error(err))
+		return ""
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+func messageBody(message string) (string, error) {
+	body := strings.TrimSpace(message)
+	switch {
+	case body == "":
+		return "", fmt.Errorf("%w: message is required", ErrInvalidTicket)
+	case len(body) > maxMessageLength:
+		return "", fmt.Errorf("%w: message must be at most %d characters", ErrInvalidTicket, maxMessageLength)
+	}
+	return body, nil
+}
@@ -0,0 +1,65 @@
+package usage
+
+import (
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/quota"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	quotas *quota.Quotas
+	tokens *auth.TokenManager
+	log    *zap.Logger
+}
+
+func NewHandler(quotas *quota.Quotas, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		quotas: quotas,
+		tokens: tokens,
+		log:    log,
+	}
+}
+
+// RegisterRoutes registers the usage report (admin only)
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin/usage").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+
+	admin.HandleFunc("", h.handleGet).Methods("GET")
+}
+
+// handleGet handles GET /api/v1/admin/usage
+// @Summary Get quota usage
+// @Description The plan of the tenant in the query (or the caller's token's tenant, or the default tenant) and what it has used: API requests today and orders placed this month, both in UTC, with their limits and when they reset. A limit of 0 means unlimited. enforced is false while TENANT_QUOTAS_ENABLED is off; usage is then not counted.
+// @Tags Usage
+// @Produce json
+// @Security BearerAuth
+// @Param tenant query string false "Tenant (the caller's own when left out)"
+// @Success 200 {object} models.QuotaUsage
+// @Failure 400 {string} string "Invalid tenant"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Router /admin/usage [get]
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	tenant := r.URL.Query().Get("tenant")
+	if tenant == "" {
+		tenant = httpctx.TenantFromContext(r.Context())
+	} else if !httpctx.ValidTenant(tenant) {
+		http.Error(w, "Invalid tenant", http.StatusBadRequest)
+		return
+	}
+	usage, err := h.quotas.Usage(r.Context(), tenant)
+	if err != nil {
+		h.log.Error("Failed to read quota usage", zap.String("tenant", tenant), zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	response.JSON(w, http.StatusOK, usage)
+}
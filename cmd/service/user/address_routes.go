@@ -0,0 +1,163 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/address"
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/links"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type AddressHandler struct {
+	service *AddressService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewAddressHandler(service *AddressService, tokens *auth.TokenManager, log *zap.Logger) *AddressHandler {
+	return &AddressHandler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers the caller's address book and the checkout validation route
+func (h *AddressHandler) RegisterRoutes(router *mux.Router) {
+	addresses := router.PathPrefix("/users/me/addresses").Subrouter()
+	addresses.Use(auth.Authenticate(h.tokens))
+	addresses.HandleFunc("", h.handleList).Methods("GET").Name(links.RouteAddresses)
+	addresses.HandleFunc("", h.handleCreate).Methods("POST")
+	addresses.HandleFunc("/{id}", h.handleDelete).Methods("DELETE")
+
+	validate := router.PathPrefix("/addresses/validate").Subrouter()
+	validate.Use(auth.Authenticate(h.tokens))
+	validate.HandleFunc("", h.handleValidate).Methods("POST")
+}
+
+// handleList handles GET /api/v1/users/me/addresses
+// @Summary List saved addresses
+// @Description Returns the caller's address book, default address first
+// @Tags Addresses
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Address
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/me/addresses [get]
+func (h *AddressHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	addresses, err := h.service.List(r.Context(), claims.UserID())
+	if err != nil {
+		h.log.Error("Failed to list addresses", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, addresses)
+}
+
+// handleCreate handles POST /api/v1/users/me/addresses
+// @Summary Save address
+// @Description Validates, normalizes and saves an address with its delivery zone
+// @Tags Addresses
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.AddressRequest true "Address"
+// @Success 201 {object} models.Address
+// @Failure 400 {string} string "Invalid address"
+// @Failure 422 {string} string "Address not deliverable"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/me/addresses [post]
+func (h *AddressHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	var req models.AddressRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	addr, err := h.service.Create(r.Context(), claims.UserID(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, addr)
+}
+
+// handleDelete handles DELETE /api/v1/users/me/addresses/{id}
+// @Summary Delete address
+// @Tags Addresses
+// @Security BearerAuth
+// @Param id path string true "Address ID"
+// @Success 204
+// @Failure 404 {string} string "Address not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/me/addresses/{id} [delete]
+func (h *AddressHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	if err := h.service.Delete(r.Context(), mux.Vars(r)["id"], claims.UserID()); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleValidate handles POST /api/v1/addresses/validate
+// @Summary Validate address
+// @Description Normalizes an address and returns its delivery zone without saving it (checkout)
+// @Tags Addresses
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.AddressRequest true "Address"
+// @Success 200 {object} models.AddressValidationResponse
+// @Failure 400 {string} string "Invalid address"
+// @Failure 422 {string} string "Address not deliverable"
+// @Router /addresses/validate [post]
+func (h *AddressHandler) handleValidate(w http.ResponseWriter, r *http.Request) {
+	var req models.AddressRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	addr, verified, err := h.service.Validate(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, models.AddressValidationResponse{
+		Address:  addr,
+		Verified: verified,
+		Zone:     addr.Zone,
+	})
+}
+
+// writeError maps address errors to 400/404/422/500
+func (h *AddressHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidAddress):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, address.ErrUndeliverable), errors.Is(err, ErrOutsideDeliveryArea):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, repository.ErrAddressNotFound):
+		http.Error(w, "Address not found", http.StatusNotFound)
+	default:
+		h.log.Error("Address request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,56 @@
+package user
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Jason-Omondi/ecomgo/internal/address"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository/memory"
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// FuzzValidateAddress feeds arbitrary bodies to POST /addresses/validate: every one is answered
+// 200, 400 or 422, never 500, and an accepted address comes back normalized
+func FuzzValidateAddress(f *testing.F) {
+	f.Add(`{"recipient":"Ada","line1":" 1 Moi Avenue ","city":"Nairobi","country":"ke"}`)
+	f.Add(`{"line1":"1 Main St","country":"US"}`)
+	f.Add(`{"line1":"","country":"KE"}`)
+	f.Add(`{"line1":"x","country":"kenya"}`)
+	f.Add(`{"line1":"x","country":"ß"}`)
+	f.Add(`{"line1":1,"country":"KE"}`)
+	f.Add(`[]`)
+
+	zones, err := address.NewZones([]string{"nairobi=KE/Nairobi", "kenya=KE"})
+	if err != nil {
+		f.Fatal(err)
+	}
+	tokens := testutil.NewTokenManager(clock.System)
+	service := NewAddressService(memory.NewAddressRepository(), address.NoopValidator{}, zones, zap.NewNop())
+	router := mux.NewRouter()
+	NewAddressHandler(service, tokens, zap.NewNop()).RegisterRoutes(router)
+
+	f.Fuzz(func(t *testing.T, body string) {
+		rec := testutil.Serve(router, testutil.CustomerRequest(t, tokens, "user-1", "POST", "/addresses/validate", body))
+		switch rec.Code {
+		case http.StatusOK:
+			var resp models.AddressValidationResponse
+			testutil.DecodeJSON(t, rec, http.StatusOK, &resp)
+			addr := resp.Address
+			if addr == nil || addr.Line1 == "" || addr.Line1 != strings.TrimSpace(addr.Line1) {
+				t.Fatalf("accepted address without a trimmed line1: %s", rec.Body.String())
+			}
+			if addr.Country != "KE" || resp.Zone == "" || resp.Zone != addr.Zone {
+				t.Fatalf("accepted address outside the delivery zones: %s", rec.Body.String())
+			}
+		case http.StatusBadRequest, http.StatusUnprocessableEntity:
+			testutil.DecodeError(t, rec)
+		default:
+			t.Fatalf("status = %d for body %q: %s", rec.Code, body, rec.Body.String())
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/address"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// maxAddressesPerUser bounds address books (abuse protection, keeps checkout pickers usable)
+const maxAddressesPerUser = 20
+
+var (
+	// ErrInvalidAddress wraps input problems (missing fields, full address book)
+	ErrInvalidAddress = errors.New("invalid address")
+
+	// ErrOutsideDeliveryArea is returned when an address matches no delivery zone
+	ErrOutsideDeliveryArea = errors.New("we don't deliver to this address yet")
+)
+
+// AddressService manages customer address books
+// Every address passes through the configured validator and is assigned a delivery zone
+type AddressService struct {
+	repo      repository.AddressStore
+	validator address.Validator
+	zones     *address.Zones
+	log       *zap.Logger
+}
+
+func NewAddressService(repo repository.AddressStore, validator address.Validator,
+	zones *address.Zones, log *zap.Logger) *AddressService {
+	return &AddressService{
+		repo:      repo,
+		validator: validator,
+		zones:     zones,
+		log:       log,
+	}
+}
+
+// Validate normalizes req and resolves its delivery zone without saving it
+// Used at checkout for guest or edited addresses
+// Returns: ErrUndeliverable / ErrOutsideDeliveryArea for addresses we can't ship to
+func (s *AddressService) Validate(ctx context.Context, req *models.AddressRequest) (*models.Address, bool, error) {
+	addr := models.Address{
+		Label:      strings.TrimSpace(req.Label),
+		Recipient:  strings.TrimSpace(req.Recipient),
+		Phone:      strings.TrimSpace(req.Phone),
+		Line1:      strings.TrimSpace(req.Line1),
+		Line2:      strings.TrimSpace(req.Line2),
+		City:       strings.TrimSpace(req.City),
+		Region:     strings.TrimSpace(req.Region),
+		PostalCode: strings.TrimSpace(req.PostalCode),
+		Country:    strings.ToUpper(strings.TrimSpace(req.Country)),
+		IsDefault:  req.IsDefault,
+	}
+	if addr.Line1 == "" {
+		return nil, false, fmt.Errorf("%w: line1 is required", ErrInvalidAddress)
+	}
+	if len(addr.Country) != 2 {
+		return nil, false, fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", ErrInvalidAddress)
+	}
+
+	result, err := s.validator.Validate(ctx, addr)
+	switch {
+	case errors.Is(err, address.ErrUndeliverable):
+		return nil, false, err
+	case err != nil:
+		// Provider outage must not block customers - keep the address as entered, unverified
+		s.log.Warn("Address validation unavailable, accepting unverified address", zap.Error(err))
+		result = &address.Result{Address: addr}
+	}
+
+	normalized := result.Address
+	normalized.Verified = result.Verified
+	normalized.Zone = s.zones.Resolve(normalized)
+	if normalized.Zone == "" {
+		return nil, false, ErrOutsideDeliveryArea
+	}
+	return &normalized, result.Verified, nil
+}
+
+// Create validates and saves an address for userID
+// The first address a user saves becomes their default
+func (s *AddressService) Create(ctx context.Context, userID string, req *models.AddressRequest) (*models.Address, error) {
+	count, err := s.repo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAddressesPerUser {
+		return nil, fmt.Errorf("%w: address book is full (max %d addresses)", ErrInvalidAddress, maxAddressesPerUser)
+	}
+
+	addr, _, err := s.Validate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	addr.UserID = userID
+	addr.IsDefault = addr.IsDefault || count == 0
+
+	if err := s.repo.Create(ctx, addr); err != nil {
+		return nil, err
+	}
+	return addr, nil
+}
+
+func (s *AddressService) List(ctx context.Context, userID string) ([]models.Address, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *AddressService) Delete(ctx context.Context, id, userID string) error {
+	return s.repo.Delete(ctx, id, userID)
+}
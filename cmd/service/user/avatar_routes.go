@@ -0,0 +1,165 @@
+package user
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/avatar"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type AvatarHandler struct {
+	service *AvatarService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewAvatarHandler(service *AvatarService, tokens *auth.TokenManager, log *zap.Logger) *AvatarHandler {
+	return &AvatarHandler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers the caller's avatar upload and the public avatar images
+func (h *AvatarHandler) RegisterRoutes(router *mux.Router) {
+	me := router.PathPrefix("/users/me/avatar").Subrouter()
+	me.Use(auth.Authenticate(h.tokens))
+	me.HandleFunc("", h.handleUpload).Methods("PUT")
+	me.HandleFunc("", h.handleRemove).Methods("DELETE")
+
+	router.HandleFunc("/users/{id}/avatar", h.handleGet).Methods("GET")
+}
+
+// handleUpload handles PUT /api/v1/users/me/avatar
+// @Summary Upload avatar
+// @Description Sets the caller's profile picture from a JPEG, PNG or GIF, sent as the raw body or as the "avatar" field of a multipart form. The image is cropped to a square, resized to 64, 128, 256 and 512 px and stripped of EXIF metadata.
+// @Tags Users
+// @Accept image/jpeg,image/png,image/gif,multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param avatar formData file false "Image (multipart uploads)"
+// @Success 200 {object} models.User
+// @Failure 400 {string} string "Unsupported or oversized image"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 413 {string} string "File too large"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/me/avatar [put]
+func (h *AvatarHandler) handleUpload(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	body := io.Reader(r.Body)
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, "Invalid request", http.StatusBadRequest)
+			return
+		}
+		// Stream the "avatar" part rather than buffering the whole form to disk
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				http.Error(w, "avatar file is required", http.StatusBadRequest)
+				return
+			}
+			if part.FormName() == "avatar" {
+				body = part
+				break
+			}
+		}
+	}
+
+	user, err := h.service.Upload(r.Context(), claims.UserID(), body)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	user.AvatarURL = h.service.URL(user)
+	response.JSON(w, http.StatusOK, user)
+}
+
+// handleRemove handles DELETE /api/v1/users/me/avatar
+// @Summary Remove avatar
+// @Description Deletes the caller's profile picture; avatar_url then serves the identicon
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.User
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/me/avatar [delete]
+func (h *AvatarHandler) handleRemove(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	user, err := h.service.Remove(r.Context(), claims.UserID())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	user.AvatarURL = h.service.URL(user)
+	response.JSON(w, http.StatusOK, user)
+}
+
+// handleGet handles GET /api/v1/users/{id}/avatar
+// @Summary Get avatar
+// @Description Returns a user's profile picture (JPEG), or their identicon (PNG) when none was uploaded. This is the avatar_url of user responses.
+// @Tags Users
+// @Produce image/jpeg,image/png
+// @Param id path string true "User ID"
+// @Param size query int false "Edge length in px; rounded up to 64, 128, 256 or 512 (default 128)"
+// @Success 200 {file} file
+// @Failure 404 {string} string "User not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users/{id}/avatar [get]
+func (h *AvatarHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	size := avatar.DefaultSize
+	if raw := r.URL.Query().Get("size"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			size = n
+		}
+	}
+
+	img, err := h.service.Open(r.Context(), mux.Vars(r)["id"], size)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	defer img.Body.Close()
+
+	// Versioned URLs (?v=) of uploaded avatars never change content; identicons and
+	// unversioned requests do once a picture is uploaded, so they're cached briefly
+	cache := "public, max-age=31536000, immutable"
+	if img.Identicon || r.URL.Query().Get("v") == "" {
+		cache = "public, max-age=3600"
+	}
+	w.Header().Set("Cache-Control", cache)
+	w.Header().Set("Content-Type", img.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(img.Size, 10))
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, img.Body)
+}
+
+// writeError maps avatar errors to 400/404/413/500
+func (h *AvatarHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, avatar.ErrUnsupportedFormat), errors.Is(err, avatar.ErrTooLarge):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, avatar.ErrFileTooLarge):
+		http.Error(w, "File too large", http.StatusRequestEntityTooLarge)
+	case errors.Is(err, repository.ErrUserNotFound):
+		http.Error(w, "User not found", http.StatusNotFound)
+	default:
+		h.log.Error("Avatar request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
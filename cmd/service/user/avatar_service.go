@@ -0,0 +1,158 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Jason-Omondi/ecomgo/internal/avatar"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/storage"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AvatarService stores profile pictures and serves them, or identicons, by user ID
+// Each upload gets a fresh storage prefix (avatars/<user>/<version>/<size>.jpg), so the
+// versioned avatar_url can be cached forever and a new upload changes the URL
+type AvatarService struct {
+	users   repository.UserStore
+	store   storage.Storage
+	baseURL string // public API root, e.g. https://shop.example.com/api/v1
+	maxSize int64
+	log     *zap.Logger
+}
+
+func NewAvatarService(users repository.UserStore, store storage.Storage, baseURL string,
+	maxSize int64, log *zap.Logger) *AvatarService {
+	return &AvatarService{
+		users:   users,
+		store:   store,
+		baseURL: baseURL,
+		maxSize: maxSize,
+		log:     log,
+	}
+}
+
+// URL is the avatar_url for user: the uploaded picture, or the identicon when there is none
+func (s *AvatarService) URL(user *models.User) string {
+	url := s.baseURL + "/users/" + user.ID + "/avatar"
+	if user.AvatarKey != "" {
+		// The version segment of the key busts caches after a new upload
+		url += "?v=" + user.AvatarKey[len(user.AvatarKey)-8:]
+	}
+	return url
+}
+
+// Upload processes the image in r and makes it userID's avatar
+// The previous avatar's files are deleted once the user points at the new ones
+// Returns: the updated user, avatar.ErrFileTooLarge, or an avatar format error
+func (s *AvatarService) Upload(ctx context.Context, userID string, r io.Reader) (*models.User, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := avatar.ReadLimited(r, s.maxSize)
+	if err != nil {
+		return nil, err
+	}
+	images, err := avatar.Process(data)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := storage.Key(storage.PrefixAvatars, userID, uuid.NewString())
+	for size, img := range images {
+		if err := s.store.Put(ctx, sizeKey(prefix, size), bytes.NewReader(img), int64(len(img)), "image/jpeg"); err != nil {
+			s.deleteSizes(ctx, prefix)
+			return nil, fmt.Errorf("store %dpx avatar: %w", size, err)
+		}
+	}
+
+	previous := user.AvatarKey
+	if err := s.users.UpdateFields(ctx, userID, map[string]interface{}{"avatar_key": prefix}); err != nil {
+		s.deleteSizes(ctx, prefix)
+		return nil, err
+	}
+	s.deleteSizes(ctx, previous)
+
+	s.log.Info("Avatar updated", zap.String("user_id", userID), zap.Int("bytes", len(data)))
+	user.AvatarKey = prefix
+	return user, nil
+}
+
+// Remove goes back to the identicon
+func (s *AvatarService) Remove(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.AvatarKey == "" {
+		return user, nil
+	}
+
+	if err := s.users.UpdateFields(ctx, userID, map[string]interface{}{"avatar_key": ""}); err != nil {
+		return nil, err
+	}
+	s.deleteSizes(ctx, user.AvatarKey)
+	user.AvatarKey = ""
+	return user, nil
+}
+
+// Image is an avatar ready to send
+type Image struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+	Identicon   bool // generated, not uploaded
+}
+
+// Open returns userID's avatar at the stored size nearest to size
+// Users without an uploaded avatar (or whose files are missing) get their identicon
+func (s *AvatarService) Open(ctx context.Context, userID string, size int) (*Image, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	size = avatar.SizeFor(size)
+
+	if user.AvatarKey != "" {
+		body, obj, err := s.store.Get(ctx, sizeKey(user.AvatarKey, size))
+		switch {
+		case err == nil:
+			return &Image{Body: body, ContentType: "image/jpeg", Size: obj.Size}, nil
+		case !errors.Is(err, storage.ErrNotFound):
+			return nil, err
+		}
+		s.log.Warn("Avatar files missing, serving identicon", zap.String("user_id", userID),
+			zap.String("key", user.AvatarKey))
+	}
+
+	png, err := avatar.Identicon(user.ID, size)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{Body: io.NopCloser(bytes.NewReader(png)), ContentType: "image/png",
+		Size: int64(len(png)), Identicon: true}, nil
+}
+
+// deleteSizes removes every size stored under prefix; failures only leave orphans behind
+func (s *AvatarService) deleteSizes(ctx context.Context, prefix string) {
+	if prefix == "" {
+		return
+	}
+	for _, size := range avatar.Sizes {
+		if err := s.store.Delete(ctx, sizeKey(prefix, size)); err != nil {
+			s.log.Warn("Failed to delete avatar file", zap.String("key", sizeKey(prefix, size)), zap.Error(err))
+		}
+	}
+}
+
+func sizeKey(prefix string, size int) string {
+	return storage.Key(prefix, strconv.Itoa(size)+".jpg")
+}
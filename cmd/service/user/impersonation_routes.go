@@ -0,0 +1,124 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type ImpersonationHandler struct {
+	service *ImpersonationService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewImpersonationHandler(service *ImpersonationService, tokens *auth.TokenManager, log *zap.Logger) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers support impersonation and its audit log (admin only)
+func (h *ImpersonationHandler) RegisterRoutes(router *mux.Router) {
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/impersonate/{userID}", h.handleImpersonate).Methods("POST")
+	admin.HandleFunc("/impersonations", h.handleList).Methods("GET")
+}
+
+// handleImpersonate handles POST /api/v1/admin/impersonate/{userID}
+// @Summary Impersonate a customer
+// @Description Issues a short-lived token acting as the user, for reproducing support issues. The token carries an imp claim with the admin's ID; the session and every request made with it are audit-logged. The token is read-only: it is refused on anything but GET, HEAD and OPTIONS, except POST /logout. Admins cannot be impersonated.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Param request body models.ImpersonateRequest true "Reason, e.g. support ticket"
+// @Success 200 {object} models.ImpersonateResponse
+// @Failure 400 {string} string "Reason is required"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not found"
+// @Router /admin/impersonate/{userID} [post]
+func (h *ImpersonationHandler) handleImpersonate(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+	// An impersonation token never carries the admin role, but refuse nesting explicitly
+	if claims.Impersonator != "" {
+		http.Error(w, "Cannot impersonate while impersonating", http.StatusForbidden)
+		return
+	}
+
+	var req models.ImpersonateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	ip := httpctx.GeoFromContext(r.Context()).IP
+	if ip == "" {
+		ip, _, _ = net.SplitHostPort(r.RemoteAddr)
+	}
+	resp, err := h.service.Start(r.Context(), claims.UserID(), mux.Vars(r)["userID"], req.Reason, ip)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleList handles GET /api/v1/admin/impersonations
+// @Summary Impersonation audit log
+// @Description Impersonation sessions and the requests made with them, newest first
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query string false "Impersonated user"
+// @Param admin_id query string false "Impersonating admin"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {array} models.ImpersonationAudit
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/impersonations [get]
+func (h *ImpersonationHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination.FromRequest(r)
+	query := r.URL.Query()
+
+	audits, err := h.service.List(r.Context(), query.Get("user_id"), query.Get("admin_id"), limit, offset)
+	if err != nil {
+		h.log.Error("Failed to list impersonation audits", zap.Error(err))
+		http.Error(w, "Failed to list impersonations", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, audits)
+}
+
+func (h *ImpersonationHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrReasonRequired):
+		http.Error(w, "Reason is required", http.StatusBadRequest)
+	case errors.Is(err, ErrCannotImpersonate):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, repository.ErrUserNotFound):
+		http.Error(w, "User not found", http.StatusNotFound)
+	default:
+		h.log.Error("Impersonation failed", zap.Error(err))
+		http.Error(w, "Impersonation failed", http.StatusInternalServerError)
+	}
+}
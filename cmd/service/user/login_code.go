@@ -0,0 +1,263 @@
+package user
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/notify"
+	"github.com/Jason-Omondi/ecomgo/internal/phone"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidLoginCode covers wrong, expired, used and locked codes and links alike,
+	// so a guesser learns nothing from the answer
+	ErrInvalidLoginCode = errors.New("invalid or expired code")
+	// ErrLoginCodeRequired is returned when verifying with neither a code nor a link token
+	ErrLoginCodeRequired = errors.New("code or token is required")
+	// ErrMagicLinkDisabled is returned when a link is requested without MAGIC_LINK_URL
+	ErrMagicLinkDisabled = errors.New("magic links are not enabled")
+	// ErrTooManyLoginCodes is returned when an account or client IP is over its limit
+	ErrTooManyLoginCodes = errors.New("too many sign-in attempts, try again later")
+)
+
+// LoginCodeService signs users in without a password, with a 6-digit code or a magic link
+// delivered on their OTP notification channel
+// Brute force is bounded three ways: each code dies after LOGIN_CODE_MAX_ATTEMPTS wrong guesses,
+// a new code replaces the old one, and sends and verifications are rate limited per account and IP
+type LoginCodeService struct {
+	users    *UserService // account lookup by email, username or phone, as for password sign-in
+	codes    *repository.LoginCodeRepository
+	notifier *notify.Notifier
+	tokens   *auth.TokenManager
+	cache    cache.Cache
+	clock    clock.Clock
+	secret   []byte // keys the stored HMACs of codes and link tokens
+	cfg      config.Accounts
+	log      *zap.Logger
+}
+
+func NewLoginCodeService(users *UserService, codes *repository.LoginCodeRepository, notifier *notify.Notifier,
+	tokens *auth.TokenManager, appCache cache.Cache, clk clock.Clock, secret string, cfg config.Accounts,
+	log *zap.Logger) *LoginCodeService {
+	return &LoginCodeService{
+		users:    users,
+		codes:    codes,
+		notifier: notifier,
+		tokens:   tokens,
+		cache:    appCache,
+		clock:    clk,
+		secret:   []byte(secret),
+		cfg:      cfg,
+		log:      log,
+	}
+}
+
+// Request sends a sign-in code to the account matching req
+// The answer is the same whether or not the account exists, so it can't be used to find accounts
+func (s *LoginCodeService) Request(ctx context.Context, req *models.LoginCodeRequest) (*models.LoginCodeResponse, error) {
+	resp := &models.LoginCodeResponse{ExpiresIn: int64(s.cfg.LoginCodeTTL.Seconds())}
+	if req.Link && s.cfg.MagicLinkURL == "" {
+		return nil, ErrMagicLinkDisabled
+	}
+	if err := s.allow(ctx, "send:ip:"+httpctx.GeoFromContext(ctx).IP, s.cfg.LoginCodeSendLimit); err != nil {
+		return nil, err
+	}
+
+	user, err := s.findUser(ctx, req.Email, req.Username, req.Phone)
+	if err != nil {
+		if errors.Is(err, ErrInvalidLoginCode) {
+			s.log.Info("Sign-in code requested for unknown account")
+			return resp, nil
+		}
+		return nil, err
+	}
+	if err := s.allow(ctx, "send:user:"+user.ID, s.cfg.LoginCodeSendLimit); err != nil {
+		return nil, err
+	}
+
+	code, err := randomCode()
+	if err != nil {
+		return nil, err
+	}
+	record := &models.LoginCode{
+		UserID:    user.ID,
+		CodeHash:  s.hash(user.ID + ":" + code),
+		ExpiresAt: s.clock.Now().Add(s.cfg.LoginCodeTTL),
+	}
+	body := fmt.Sprintf("Your sign-in code is %s. It expires in %d minutes. Never share it with anyone.",
+		code, int(s.cfg.LoginCodeTTL.Minutes()))
+	if req.Link {
+		link, err := randomToken()
+		if err != nil {
+			return nil, err
+		}
+		record.LinkHash = s.hash("link:" + link)
+		body += " Or sign in with this link: " + magicLink(s.cfg.MagicLinkURL, link)
+	}
+
+	if err := s.codes.Replace(ctx, record); err != nil {
+		return nil, err
+	}
+	if err := s.notifier.Notify(ctx, user.ID, notify.Notification{
+		Category: models.NotifyOTP,
+		Subject:  "Your sign-in code",
+		Body:     body,
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Sign-in code sent", zap.String("user_id", user.ID), zap.Bool("link", req.Link))
+	return resp, nil
+}
+
+// Verify signs in with a code or a magic link token and issues the same token as password sign-in
+func (s *LoginCodeService) Verify(ctx context.Context, req *models.LoginCodeVerifyRequest) (*models.AuthResponse, error) {
+	if err := s.allow(ctx, "verify:ip:"+httpctx.GeoFromContext(ctx).IP, s.cfg.LoginCodeVerifyLimit); err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	var (
+		user   *models.User
+		record *models.LoginCode
+		err    error
+	)
+	switch token, code := strings.TrimSpace(req.Token), strings.TrimSpace(req.Code); {
+	case token != "":
+		record, err = s.codes.GetLiveByLink(ctx, s.hash("link:"+token), s.cfg.LoginCodeMaxAttempts, now)
+		if err != nil {
+			return nil, invalidCode(err)
+		}
+		if user, err = s.users.userRepo.GetUserByID(ctx, record.UserID); err != nil {
+			return nil, invalidCode(err)
+		}
+	case code != "":
+		if user, err = s.findUser(ctx, req.Email, req.Username, req.Phone); err != nil {
+			return nil, err
+		}
+		record, err = s.codes.GetLive(ctx, user.ID, s.cfg.LoginCodeMaxAttempts, now)
+		if err != nil {
+			return nil, invalidCode(err)
+		}
+		if !hmac.Equal([]byte(s.hash(user.ID+":"+code)), []byte(record.CodeHash)) {
+			if err := s.codes.RecordFailure(ctx, record.ID); err != nil {
+				return nil, err
+			}
+			s.log.Warn("Sign-in code rejected", zap.String("user_id", user.ID), zap.Int("attempts", record.Attempts+1))
+			return nil, ErrInvalidLoginCode
+		}
+	default:
+		return nil, ErrLoginCodeRequired
+	}
+
+	used, err := s.codes.Use(ctx, record.ID, s.cfg.LoginCodeMaxAttempts, now)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrInvalidLoginCode
+	}
+
+	token, expiresAt, err := s.tokens.Issue(user)
+	if err != nil {
+		s.log.Error("Failed to issue token", zap.String("email", user.Email), zap.Error(err))
+		return nil, err
+	}
+
+	s.log.Info("User signed in with code", zap.String("user_id", user.ID), zap.Bool("link", req.Token != ""))
+	return &models.AuthResponse{
+		Token:     token,
+		User:      models.AccountOf(user),
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
+}
+
+// findUser looks the account up like password sign-in does
+// Returns: ErrInvalidLoginCode when there is no such account
+func (s *LoginCodeService) findUser(ctx context.Context, email, username, number string) (*models.User, error) {
+	user, err := s.users.findLoginUser(ctx, &models.LoginRequest{Email: email, Username: username, Phone: number})
+	switch {
+	case err == nil:
+		return user, nil
+	case errors.Is(err, ErrLoginIdentifierRequired) || errors.Is(err, ErrPhoneLoginDisabled):
+		return nil, err
+	case errors.Is(err, repository.ErrUserNotFound) || errors.Is(err, phone.ErrInvalid):
+		return nil, ErrInvalidLoginCode
+	default:
+		return nil, err
+	}
+}
+
+// allow counts one attempt against key and fails with ErrTooManyLoginCodes over limit
+// When the counter is unavailable the attempt is let through: per-code attempt limits still apply
+func (s *LoginCodeService) allow(ctx context.Context, key string, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+	count, err := s.cache.Incr(ctx, "login_code:"+key, s.cfg.LoginCodeWindow)
+	if err != nil {
+		s.log.Warn("Sign-in code rate limit unavailable", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	if count > int64(limit) {
+		return ErrTooManyLoginCodes
+	}
+	return nil
+}
+
+func (s *LoginCodeService) hash(value string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// invalidCode hides why a lookup failed, except for real errors
+func invalidCode(err error) error {
+	if errors.Is(err, repository.ErrLoginCodeNotFound) || errors.Is(err, repository.ErrUserNotFound) {
+		return ErrInvalidLoginCode
+	}
+	return err
+}
+
+// randomCode returns a uniformly random 6-digit code
+func randomCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// randomToken returns a 256-bit URL-safe magic link token
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func magicLink(base, token string) string {
+	separator := "?"
+	if strings.Contains(base, "?") {
+		separator = "&"
+	}
+	return base + separator + "token=" + url.QueryEscape(token)
+}
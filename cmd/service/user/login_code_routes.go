@@ -0,0 +1,84 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"go.uber.org/zap"
+)
+
+// handleRequestLoginCode handles POST /api/v1/login/code
+// @Summary Request a sign-in code
+// @Description Sends a 6-digit code, and a magic link when link is true, on the account's OTP notification channel. The answer is the same whether or not the account exists.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.LoginCodeRequest true "Account email, username or phone"
+// @Param X-Captcha-Token header string false "CAPTCHA widget token, required once the client IP used its free attempts"
+// @Success 202 {object} models.LoginCodeResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 403 {string} string "CAPTCHA required or verification failed"
+// @Failure 429 {string} string "Too many sign-in attempts"
+// @Router /login/code [post]
+func (h *Handler) handleRequestLoginCode(w http.ResponseWriter, r *http.Request) {
+	var req models.LoginCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.codes.Request(r.Context(), &req)
+	if err != nil {
+		h.writeLoginCodeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusAccepted, resp)
+}
+
+// handleVerifyLoginCode handles POST /api/v1/login/code/verify
+// @Summary Sign in with a code or magic link
+// @Description Send the code with the email, username or phone it was requested for, or the token from a magic link. Codes are single use and stop working after too many wrong guesses.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.LoginCodeVerifyRequest true "Code and account, or link token"
+// @Success 200 {object} models.AuthResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Invalid or expired code"
+// @Failure 429 {string} string "Too many sign-in attempts"
+// @Router /login/code/verify [post]
+func (h *Handler) handleVerifyLoginCode(w http.ResponseWriter, r *http.Request) {
+	var req models.LoginCodeVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	authResp, err := h.codes.Verify(r.Context(), &req)
+	if err != nil {
+		h.writeLoginCodeError(w, err)
+		return
+	}
+
+	h.present(authResp.User.User, true)
+	response.JSON(w, http.StatusOK, authResp)
+}
+
+// writeLoginCodeError maps passwordless sign-in errors to HTTP status codes
+func (h *Handler) writeLoginCodeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrLoginIdentifierRequired), errors.Is(err, ErrPhoneLoginDisabled),
+		errors.Is(err, ErrLoginCodeRequired), errors.Is(err, ErrMagicLinkDisabled):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrInvalidLoginCode):
+		http.Error(w, "Invalid or expired code", http.StatusUnauthorized)
+	case errors.Is(err, ErrTooManyLoginCodes):
+		http.Error(w, "Too many sign-in attempts, try again later", http.StatusTooManyRequests)
+	default:
+		h.log.Error("Sign-in code request failed", zap.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
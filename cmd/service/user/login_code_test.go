@@ -0,0 +1,244 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/notify"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/repository/memory"
+	"github.com/Jason-Omondi/ecomgo/internal/sms"
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"go.uber.org/zap"
+)
+
+const (
+	loginCodeTTL    = 10 * time.Minute
+	loginCodeWindow = time.Hour
+	maxAttempts     = 3
+	sendLimit       = 3
+)
+
+var codePattern = regexp.MustCompile(`\b\d{6}\b`)
+
+// inbox is an SMS sender that keeps the messages it is given
+type inbox struct {
+	mu       sync.Mutex
+	messages []sms.Message
+}
+
+func (i *inbox) Send(_ context.Context, msg sms.Message) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.messages = append(i.messages, msg)
+	return nil
+}
+
+// lastCode returns the code in the latest message
+func (i *inbox) lastCode(t *testing.T) string {
+	t.Helper()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if len(i.messages) == 0 {
+		t.Fatal("no sign-in code was sent")
+	}
+	code := codePattern.FindString(i.messages[len(i.messages)-1].Body)
+	if code == "" {
+		t.Fatalf("no code in %q", i.messages[len(i.messages)-1].Body)
+	}
+	return code
+}
+
+type loginCodes struct {
+	service *LoginCodeService
+	inbox   *inbox
+	clock   *clock.Fake
+}
+
+// newLoginCodes returns the sign-in code service for ada@example.com, who gets codes by SMS,
+// with the clock driving code expiry and the rate-limit windows
+func newLoginCodes(t *testing.T) *loginCodes {
+	t.Helper()
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	counters := cache.NewMemoryCache("login-code-test")
+	counters.UseClock(clk)
+
+	users := memory.NewUserRepository()
+	ada := &models.User{Email: "ada@example.com", Role: models.RoleCustomer}
+	if err := users.CreateUser(ctx, ada); err != nil {
+		t.Fatal(err)
+	}
+	db := testutil.NewDB(t, &models.LoginCode{}, &models.NotificationPreference{})
+	prefs := repository.NewNotificationRepository(db, zap.NewNop())
+	pref := models.DefaultNotificationPreference(ada.ID)
+	pref.Phone = "+254700000001"
+	pref.OTPChannel = models.ChannelSMS
+	if err := prefs.SavePreferences(ctx, pref); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := &inbox{}
+	tokens := testutil.NewTokenManager(clk)
+	service := NewLoginCodeService(
+		NewUserService(users, nil, tokens, nil, zap.NewNop(), &config.Config{}),
+		repository.NewLoginCodeRepository(db, zap.NewNop()),
+		notify.NewNotifier(prefs, users, messages, nil, zap.NewNop()),
+		tokens, counters, clk, "login-code-secret",
+		config.Accounts{
+			LoginCodeTTL:         loginCodeTTL,
+			LoginCodeMaxAttempts: maxAttempts,
+			LoginCodeSendLimit:   sendLimit,
+			LoginCodeVerifyLimit: 100,
+			LoginCodeWindow:      loginCodeWindow,
+		}, zap.NewNop())
+	return &loginCodes{service: service, inbox: messages, clock: clk}
+}
+
+// from is a request context for a client at ip
+func from(ip string) context.Context {
+	return httpctx.WithGeo(context.Background(), httpctx.Geo{IP: ip})
+}
+
+// send requests a code for ada from ip and returns it
+func (l *loginCodes) send(t *testing.T, ip string) string {
+	t.Helper()
+	if _, err := l.service.Request(from(ip), &models.LoginCodeRequest{Email: "ada@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	return l.inbox.lastCode(t)
+}
+
+func (l *loginCodes) verify(code string) error {
+	_, err := l.service.Verify(from("203.0.113.7"), &models.LoginCodeVerifyRequest{Email: "ada@example.com", Code: code})
+	return err
+}
+
+// wrong returns a code other than code
+func wrong(code string) string {
+	if code == "000000" {
+		return "000001"
+	}
+	return "000000"
+}
+
+func TestLoginCodeSignsIn(t *testing.T) {
+	l := newLoginCodes(t)
+	code := l.send(t, "203.0.113.7")
+
+	resp, err := l.service.Verify(from("203.0.113.7"), &models.LoginCodeVerifyRequest{Email: "ada@example.com", Code: code})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Token == "" || resp.User.Email != "ada@example.com" {
+		t.Fatalf("sign-in response = %+v", resp)
+	}
+}
+
+func TestLoginCodeIsSingleUse(t *testing.T) {
+	l := newLoginCodes(t)
+	code := l.send(t, "203.0.113.7")
+
+	if err := l.verify(code); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.verify(code); !errors.Is(err, ErrInvalidLoginCode) {
+		t.Fatalf("second sign-in with the code = %v, want ErrInvalidLoginCode", err)
+	}
+}
+
+func TestLoginCodeAttemptLimit(t *testing.T) {
+	l := newLoginCodes(t)
+	code := l.send(t, "203.0.113.7")
+
+	for i := 0; i < maxAttempts; i++ {
+		if err := l.verify(wrong(code)); !errors.Is(err, ErrInvalidLoginCode) {
+			t.Fatalf("wrong guess %d = %v, want ErrInvalidLoginCode", i+1, err)
+		}
+	}
+	if err := l.verify(code); !errors.Is(err, ErrInvalidLoginCode) {
+		t.Fatalf("right code after %d wrong guesses = %v, want ErrInvalidLoginCode", maxAttempts, err)
+	}
+
+	// A new code starts with a clean slate
+	if err := l.verify(l.send(t, "203.0.113.7")); err != nil {
+		t.Fatalf("new code after a locked one = %v", err)
+	}
+}
+
+func TestLoginCodeExpires(t *testing.T) {
+	t.Run("just before", func(t *testing.T) {
+		l := newLoginCodes(t)
+		code := l.send(t, "203.0.113.7")
+		l.clock.Advance(loginCodeTTL - time.Second)
+		if err := l.verify(code); err != nil {
+			t.Fatalf("code a second before it expires = %v", err)
+		}
+	})
+
+	t.Run("after", func(t *testing.T) {
+		l := newLoginCodes(t)
+		code := l.send(t, "203.0.113.7")
+		l.clock.Advance(loginCodeTTL)
+		if err := l.verify(code); !errors.Is(err, ErrInvalidLoginCode) {
+			t.Fatalf("expired code = %v, want ErrInvalidLoginCode", err)
+		}
+	})
+}
+
+func TestNewLoginCodeReplacesOld(t *testing.T) {
+	l := newLoginCodes(t)
+	first := l.send(t, "203.0.113.7")
+	second := l.send(t, "203.0.113.7")
+	for second == first {
+		second = l.send(t, "203.0.113.7")
+	}
+
+	if err := l.verify(first); !errors.Is(err, ErrInvalidLoginCode) {
+		t.Fatalf("replaced code = %v, want ErrInvalidLoginCode", err)
+	}
+	if err := l.verify(second); err != nil {
+		t.Fatalf("latest code = %v", err)
+	}
+}
+
+func TestLoginCodeSendLimitPerAddress(t *testing.T) {
+	l := newLoginCodes(t)
+	request := func(ip, email string) error {
+		_, err := l.service.Request(from(ip), &models.LoginCodeRequest{Email: email})
+		return err
+	}
+
+	// Unknown accounts count against the address too, so it can't probe for accounts
+	for i := 0; i < sendLimit; i++ {
+		if err := request("203.0.113.7", "nobody@example.com"); err != nil {
+			t.Fatalf("request %d = %v", i+1, err)
+		}
+	}
+	if err := request("203.0.113.7", "ada@example.com"); !errors.Is(err, ErrTooManyLoginCodes) {
+		t.Fatalf("request over the limit = %v, want ErrTooManyLoginCodes", err)
+	}
+	if len(l.inbox.messages) != 0 {
+		t.Fatalf("%d codes sent over the limit", len(l.inbox.messages))
+	}
+
+	// Another address has its own allowance
+	if err := request("198.51.100.9", "ada@example.com"); err != nil {
+		t.Fatalf("request from another address = %v", err)
+	}
+
+	// And the limited one gets a new allowance with the next window
+	l.clock.Advance(loginCodeWindow + time.Second)
+	if err := request("203.0.113.7", "ada@example.com"); err != nil {
+		t.Fatalf("request in the next window = %v", err)
+	}
+}
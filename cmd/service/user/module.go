@@ -0,0 +1,86 @@
+package user
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/batchwriter"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+)
+
+// Module wires the user repository, service and handler together
+// Implements module.Module so APIServer can mount it without knowing its internals
+type Module struct {
+	handler              *Handler
+	addressHandler       *AddressHandler
+	avatarHandler        *AvatarHandler
+	impersonationHandler *ImpersonationHandler
+	tokenHandler         *TokenHandler
+	requestAudit         *batchwriter.Writer[models.ImpersonationAudit]
+}
+
+// NewModule builds the user module from shared dependencies
+func NewModule(deps module.Deps) *Module {
+	// Repository - data access layer
+	userRepo := repository.NewUserRepository(deps.DB, deps.Log)
+
+	// Service - business logic layer
+	userService := NewUserService(userRepo, deps.Events, deps.Tokens, deps.Mailer, deps.Log, deps.Config)
+
+	// Avatars - resized copies in object storage, identicons for everyone else
+	avatarService := NewAvatarService(userRepo, deps.Storage, deps.Config.Storage.PublicURL,
+		deps.Config.Accounts.AvatarMaxSize, deps.Log)
+
+	// Address book - validated by the configured provider, zoned for shipping
+	addressService := NewAddressService(repository.NewAddressRepository(deps.DB, deps.Log),
+		deps.Addresses, deps.Zones, deps.Log)
+
+	// Support impersonation - audits every request made with an impersonation token
+	requestAudit := batchwriter.NewWriter[models.ImpersonationAudit](deps.DB, "impersonation_audits",
+		deps.Config.AsyncWrites, deps.Log)
+	impersonationService := NewImpersonationService(userRepo,
+		repository.NewImpersonationRepository(deps.DB, deps.Log), requestAudit,
+		deps.Tokens, deps.Clock, deps.Config.Auth.ImpersonationTTL, deps.Log)
+
+	// Passwordless sign-in - one-time codes and magic links on the user's OTP channel
+	loginCodeService := NewLoginCodeService(userService, repository.NewLoginCodeRepository(deps.DB, deps.Log),
+		deps.Notifier, deps.Tokens, deps.Cache, deps.Clock, deps.Config.Auth.JWTSecret, deps.Config.Accounts, deps.Log)
+
+	// Password reset - one-time links on the same channel and send limits as sign-in codes
+	passwordResetService := NewPasswordResetService(userService, loginCodeService, deps.Notifier, deps.Cache,
+		deps.Config.Auth.JWTSecret, deps.Config.Accounts, deps.Log)
+
+	// Scoped tokens - least-privilege credentials for integrations
+	tokenService := NewTokenService(userRepo, deps.Tokens, deps.Config.Auth.ScopedTokenTTL, deps.Log)
+
+	return &Module{
+		handler:              NewHandler(userService, loginCodeService, passwordResetService, avatarService, deps.Tokens, deps.Captcha, deps.Links, deps.Log),
+		addressHandler:       NewAddressHandler(addressService, deps.Tokens, deps.Log),
+		avatarHandler:        NewAvatarHandler(avatarService, deps.Tokens, deps.Log),
+		impersonationHandler: NewImpersonationHandler(impersonationService, deps.Tokens, deps.Log),
+		tokenHandler:         NewTokenHandler(tokenService, deps.Tokens, deps.Log),
+		requestAudit:         requestAudit,
+	}
+}
+
+// Migrations creates/updates the users, addresses, impersonation audit and sign-in code tables
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.User{}, &models.Address{}, &models.ImpersonationAudit{}, &models.LoginCode{}),
+	}
+}
+
+// RegisterRoutes mounts /register, /login, /login/code, /users, /tokens, avatar and address routes
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+	m.addressHandler.RegisterRoutes(router)
+	m.avatarHandler.RegisterRoutes(router)
+	m.impersonationHandler.RegisterRoutes(router)
+	m.tokenHandler.RegisterRoutes(router)
+}
+
+// Services flushes buffered impersonation request audits
+func (m *Module) Services() []module.Service {
+	return []module.Service{m.requestAudit}
+}
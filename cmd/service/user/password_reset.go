@@ -0,0 +1,174 @@
+package user
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/notify"
+	"go.uber.org/zap"
+)
+
+const minPasswordLength = 6
+
+var (
+	// ErrInvalidResetToken covers wrong, expired, used and superseded reset links alike
+	ErrInvalidResetToken = errors.New("invalid or expired reset link")
+	// ErrPasswordResetDisabled is returned when a reset is requested without PASSWORD_RESET_URL
+	ErrPasswordResetDisabled = errors.New("password resets are not enabled")
+	// ErrWeakPassword is returned for new passwords that are too short
+	ErrWeakPassword = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+)
+
+// PasswordResetService lets users who forgot their password set a new one through a one-time
+// link sent on their OTP notification channel, the same way magic links are sent
+// Links live in the cache: only the newest link of an account works, and only once
+type PasswordResetService struct {
+	users    *UserService
+	codes    *LoginCodeService // account lookup and send limits, shared with sign-in codes
+	notifier *notify.Notifier
+	cache    cache.Cache
+	secret   []byte // keys the HMACs links are stored under
+	cfg      config.Accounts
+	log      *zap.Logger
+}
+
+func NewPasswordResetService(users *UserService, codes *LoginCodeService, notifier *notify.Notifier,
+	appCache cache.Cache, secret string, cfg config.Accounts, log *zap.Logger) *PasswordResetService {
+	return &PasswordResetService{
+		users:    users,
+		codes:    codes,
+		notifier: notifier,
+		cache:    appCache,
+		secret:   []byte(secret),
+		cfg:      cfg,
+		log:      log,
+	}
+}
+
+// Request sends a reset link to the account matching req
+// The answer is the same whether or not the account exists, so it can't be used to find accounts
+func (s *PasswordResetService) Request(ctx context.Context, req *models.PasswordForgotRequest) (*models.LoginCodeResponse, error) {
+	if s.cfg.PasswordResetURL == "" {
+		return nil, ErrPasswordResetDisabled
+	}
+	resp := &models.LoginCodeResponse{ExpiresIn: int64(s.cfg.PasswordResetTTL.Seconds())}
+	if err := s.codes.allow(ctx, "send:ip:"+httpctx.GeoFromContext(ctx).IP, s.cfg.LoginCodeSendLimit); err != nil {
+		return nil, err
+	}
+
+	user, err := s.codes.findUser(ctx, req.Email, req.Username, req.Phone)
+	if err != nil {
+		if errors.Is(err, ErrInvalidLoginCode) {
+			s.log.Info("Password reset requested for unknown account")
+			return resp, nil
+		}
+		return nil, err
+	}
+	if err := s.codes.allow(ctx, "send:user:"+user.ID, s.cfg.LoginCodeSendLimit); err != nil {
+		return nil, err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	hash := s.hash(token)
+	// The user key points at the newest link, which supersedes the ones sent before it
+	if err := s.cache.Set(ctx, resetUserKey(user.ID), []byte(hash), s.cfg.PasswordResetTTL); err != nil {
+		return nil, err
+	}
+	if err := s.cache.Set(ctx, resetTokenKey(hash), []byte(user.ID), s.cfg.PasswordResetTTL); err != nil {
+		return nil, err
+	}
+
+	if err := s.notifier.Notify(ctx, user.ID, notify.Notification{
+		Category: models.NotifyOTP,
+		Subject:  "Reset your password",
+		Body: fmt.Sprintf("Set a new password with this link within %d minutes: %s. "+
+			"If you didn't ask to reset your password, ignore this message.",
+			int(s.cfg.PasswordResetTTL.Minutes()), magicLink(s.cfg.PasswordResetURL, token)),
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Password reset link sent", zap.String("user_id", user.ID))
+	return resp, nil
+}
+
+// Reset sets a new password with the token of the account's newest reset link
+func (s *PasswordResetService) Reset(ctx context.Context, req *models.PasswordResetRequest) error {
+	token := strings.TrimSpace(req.Token)
+	if token == "" {
+		return ErrInvalidResetToken
+	}
+	if len(req.Password) < minPasswordLength {
+		return ErrWeakPassword
+	}
+
+	hash := s.hash(token)
+	userID, err := s.cache.Get(ctx, resetTokenKey(hash))
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	newest, err := s.cache.Get(ctx, resetUserKey(string(userID)))
+	if errors.Is(err, cache.ErrCacheMiss) || (err == nil && !hmac.Equal(newest, []byte(hash))) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	// Two requests racing with one link: only the first to count it goes on
+	used, err := s.cache.Incr(ctx, resetTokenKey(hash)+":used", s.cfg.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+	if used > 1 {
+		return ErrInvalidResetToken
+	}
+
+	id := string(userID)
+	if err := s.users.userRepo.UpdateFields(ctx, id, map[string]interface{}{
+		"password_hash": s.users.hashPassword(req.Password),
+	}); err != nil {
+		return err
+	}
+	if err := s.cache.Delete(ctx, resetTokenKey(hash), resetUserKey(id)); err != nil {
+		s.log.Warn("Failed to delete used password reset link", zap.String("user_id", id), zap.Error(err))
+	}
+
+	s.log.Info("Password reset", zap.String("user_id", id))
+	if err := s.notifier.Notify(ctx, id, notify.Notification{
+		Category: models.NotifyOTP,
+		Subject:  "Your password was changed",
+		Body:     "Your password was just changed. If this wasn't you, contact support right away.",
+	}); err != nil {
+		s.log.Warn("Failed to send password change notice", zap.String("user_id", id), zap.Error(err))
+	}
+	return nil
+}
+
+func (s *PasswordResetService) hash(token string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("password-reset:" + token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func resetTokenKey(hash string) string {
+	return "password_reset:token:" + hash
+}
+
+func resetUserKey(userID string) string {
+	return "password_reset:user:" + userID
+}
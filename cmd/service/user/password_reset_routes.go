@@ -0,0 +1,79 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"go.uber.org/zap"
+)
+
+// handleForgotPassword handles POST /api/v1/password/forgot
+// @Summary Request a password reset link
+// @Description Sends a one-time link to PASSWORD_RESET_URL on the account's OTP notification channel. The answer is the same whether or not the account exists.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.PasswordForgotRequest true "Account email, username or phone"
+// @Param X-Captcha-Token header string false "CAPTCHA widget token, required once the client IP used its free attempts"
+// @Success 202 {object} models.LoginCodeResponse
+// @Failure 400 {string} string "Invalid request, or password resets not enabled"
+// @Failure 403 {string} string "CAPTCHA required or verification failed"
+// @Failure 429 {string} string "Too many attempts"
+// @Router /password/forgot [post]
+func (h *Handler) handleForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.PasswordForgotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.resets.Request(r.Context(), &req)
+	if err != nil {
+		h.writePasswordResetError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusAccepted, resp)
+}
+
+// handleResetPassword handles POST /api/v1/password/reset
+// @Summary Set a new password
+// @Description Sets a new password with the token of the account's newest reset link. Links are single use.
+// @Tags Authentication
+// @Accept json
+// @Param request body models.PasswordResetRequest true "Link token and new password"
+// @Success 204 "Password changed"
+// @Failure 400 {string} string "Invalid request or password too short"
+// @Failure 401 {string} string "Invalid or expired reset link"
+// @Router /password/reset [post]
+func (h *Handler) handleResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.resets.Reset(r.Context(), &req); err != nil {
+		h.writePasswordResetError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writePasswordResetError maps password reset errors to HTTP status codes
+func (h *Handler) writePasswordResetError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrLoginIdentifierRequired), errors.Is(err, ErrPhoneLoginDisabled),
+		errors.Is(err, ErrPasswordResetDisabled), errors.Is(err, ErrWeakPassword):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrInvalidResetToken):
+		http.Error(w, "Invalid or expired reset link", http.StatusUnauthorized)
+	case errors.Is(err, ErrTooManyLoginCodes):
+		http.Error(w, "Too many sign-in attempts, try again later", http.StatusTooManyRequests)
+	default:
+		h.log.Error("Password reset failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
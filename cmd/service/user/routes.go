@@ -1,11 +1,20 @@
 package user
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/captcha"
+	"github.com/Jason-Omondi/ecomgo/internal/export"
+	"github.com/Jason-Omondi/ecomgo/internal/i18n"
+	"github.com/Jason-Omondi/ecomgo/internal/links"
 	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/Jason-Omondi/ecomgo/internal/timezone"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
@@ -14,12 +23,25 @@ type Handler struct {
 	// Service layer handles business logic
 	// Handler only coordinates HTTP request/response and delegates to service
 	service *UserService
+	codes   *LoginCodeService
+	resets  *PasswordResetService
+	avatars *AvatarService // fills avatar_url
+	tokens  *auth.TokenManager
+	captcha *captcha.Guard // bot check on sign-up, sign-in codes and password resets
+	links   *links.Builder
 	log     *zap.Logger
 }
 
-func NewHandler(service *UserService, log *zap.Logger) *Handler {
+func NewHandler(service *UserService, codes *LoginCodeService, resets *PasswordResetService, avatars *AvatarService,
+	tokens *auth.TokenManager, captchaGuard *captcha.Guard, resourceLinks *links.Builder, log *zap.Logger) *Handler {
 	return &Handler{
 		service: service,
+		codes:   codes,
+		resets:  resets,
+		avatars: avatars,
+		tokens:  tokens,
+		captcha: captchaGuard,
+		links:   resourceLinks,
 		log:     log,
 	}
 }
@@ -27,9 +49,31 @@ func NewHandler(service *UserService, log *zap.Logger) *Handler {
 // RegisterRoutes registers user-related routes to the given router
 // Routes define HTTP endpoints and map them to handler methods
 func (h *Handler) RegisterRoutes(router *mux.Router) {
-	router.HandleFunc("/register", h.handleRegister).Methods("POST")
+	router.Handle("/register", h.captcha.Protect("register")(http.HandlerFunc(h.handleRegister))).Methods("POST")
 	router.HandleFunc("/login", h.handleLogin).Methods("POST")
-	router.HandleFunc("/users/{id}", h.handleGetUser).Methods("GET")
+	router.Handle("/login/code", h.captcha.Protect("login_code")(http.HandlerFunc(h.handleRequestLoginCode))).Methods("POST")
+	router.HandleFunc("/login/code/verify", h.handleVerifyLoginCode).Methods("POST")
+	router.Handle("/password/forgot", h.captcha.Protect("password_forgot")(http.HandlerFunc(h.handleForgotPassword))).Methods("POST")
+	router.HandleFunc("/password/reset", h.handleResetPassword).Methods("POST")
+	router.HandleFunc("/username-available", h.handleUsernameAvailable).Methods("GET")
+	router.HandleFunc("/users", h.handleGetUsers).Methods("GET")
+	router.Handle("/users/{id}", auth.Identify(h.tokens)(http.HandlerFunc(h.handleGetUser))).Methods("GET").Name(links.RouteUser)
+
+	admin := router.PathPrefix("/admin/users").Subrouter()
+	admin.Use(auth.ScopeByMethod("users"), auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+	admin.HandleFunc("/export", h.handleExport).Methods("GET")
+	admin.HandleFunc("/{id}/role", h.handleUpdateRole).Methods("PUT")
+}
+
+// present fills in the computed fields of a user response
+// current is true when the user is the caller, who also gets links to their own resources
+func (h *Handler) present(user *models.User, current bool) {
+	user.AvatarURL = h.avatars.URL(user)
+	if current {
+		user.Links = h.links.CurrentUser(user)
+	} else {
+		user.Links = h.links.User(user)
+	}
 }
 
 // handleRegister handles POST /api/v1/register
@@ -39,8 +83,11 @@ func (h *Handler) RegisterRoutes(router *mux.Router) {
 // @Accept json
 // @Produce json
 // @Param request body models.RegisterRequest true "Registration request"
+// @Param X-Captcha-Token header string false "CAPTCHA widget token, required once the client IP used its free attempts"
 // @Success 201 {object} models.AuthResponse
-// @Failure 400 {string} string "Invalid request or user already exists"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 403 {string} string "CAPTCHA required or verification failed"
+// @Failure 409 {string} string "User already exists, or username or phone number in use"
 // @Failure 500 {string} string "Internal server error"
 // @Router /register [post]
 func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
@@ -55,22 +102,25 @@ func (h *Handler) handleRegister(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call service to handle registration logic
-	authResp, err := h.service.Register(context.Background(), &req)
+	authResp, err := h.service.Register(r.Context(), &req)
 	if err != nil {
 		h.log.Error("Registration failed", zap.Error(err))
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrUserExists) || errors.Is(err, ErrUsernameTaken) || errors.Is(err, ErrPhoneTaken) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
 
 	// Return successful response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(authResp)
+	h.present(authResp.User.User, true)
+	response.JSON(w, http.StatusCreated, authResp)
 }
 
 // handleLogin handles POST /api/v1/login
 // @Summary Login user
-// @Description Authenticates user and returns auth token
+// @Description Authenticates user by email, username or phone number and returns auth token
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -92,27 +142,31 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Call service to handle login logic
-	authResp, err := h.service.Login(context.Background(), &req)
+	authResp, err := h.service.Login(r.Context(), &req)
 	if err != nil {
 		h.log.Warn("Login failed", zap.Error(err))
+		if errors.Is(err, ErrLoginIdentifierRequired) || errors.Is(err, ErrPhoneLoginDisabled) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
 		return
 	}
 
 	// Return successful response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(authResp)
+	h.present(authResp.User.User, true)
+	response.JSON(w, http.StatusOK, authResp)
 }
 
 // handleGetUser handles GET /api/v1/users/{id}
 // @Summary Get user by ID
-// @Description Retrieves user data by ID
+// @Description Retrieves a user's public profile by ID. The user themselves and admins get the full account, with email and phone.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
-// @Success 200 {object} models.User
+// @Success 200 {object} models.UserProfile "Public profile, or a models.Account for the user themselves and admins"
+// @Failure 401 {string} string "Invalid or expired token"
 // @Failure 404 {string} string "User not found"
 // @Failure 500 {string} string "Internal server error"
 // @Router /users/{id} [get]
@@ -124,15 +178,168 @@ func (h *Handler) handleGetUser(w http.ResponseWriter, r *http.Request) {
 	h.log.Info("Get user endpoint called", zap.String("id", userID))
 
 	// Call service to fetch user
-	user, err := h.service.GetUserByID(context.Background(), userID)
+	user, err := h.service.GetUserByID(r.Context(), userID)
 	if err != nil {
 		h.log.Warn("User not found", zap.String("id", userID), zap.Error(err))
 		http.Error(w, "User not found", http.StatusNotFound)
 		return
 	}
 
-	// Return successful response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(user)
+	// Contact details only go to the user themselves and to admins
+	claims := auth.ClaimsFromContext(r.Context())
+	self := claims != nil && claims.UserID() == user.ID
+	h.present(user, self)
+	if self || claims != nil && claims.Role == models.RoleAdmin {
+		response.JSON(w, http.StatusOK, models.AccountOf(user))
+		return
+	}
+	response.JSON(w, http.StatusOK, models.ProfileOf(user))
+}
+
+// handleUsernameAvailable handles GET /api/v1/username-available?username=...
+// @Summary Check username availability
+// @Description Reports whether a username can be registered, e.g. while the sign-up form is typed. Unavailable names come with the reason (invalid, reserved or taken).
+// @Tags Authentication
+// @Produce json
+// @Param username query string true "Username to check"
+// @Success 200 {object} models.UsernameAvailability
+// @Failure 400 {string} string "username is required"
+// @Failure 500 {string} string "Internal server error"
+// @Router /username-available [get]
+func (h *Handler) handleUsernameAvailable(w http.ResponseWriter, r *http.Request) {
+	username := r.URL.Query().Get("username")
+	if strings.TrimSpace(username) == "" {
+		http.Error(w, "username is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.service.UsernameAvailability(r.Context(), username)
+	if err != nil {
+		h.log.Error("Failed to check username", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	result.Reason = i18n.T(r.Context(), result.Reason)
+	response.JSON(w, http.StatusOK, result)
+}
+
+// handleGetUsers handles GET /api/v1/users?ids=a,b,c
+// @Summary Get users by IDs
+// @Description Resolves the public profiles of up to 100 users in one request, e.g. the authors of a review listing
+// @Tags Users
+// @Produce json
+// @Param ids query string true "Comma-separated user IDs"
+// @Success 200 {object} models.UserBatchResponse
+// @Failure 400 {string} string "Missing or too many ids"
+// @Failure 500 {string} string "Internal server error"
+// @Router /users [get]
+func (h *Handler) handleGetUsers(w http.ResponseWriter, r *http.Request) {
+	ids := strings.Split(r.URL.Query().Get("ids"), ",")
+	for i := range ids {
+		ids[i] = strings.TrimSpace(ids[i])
+	}
+
+	users, missing, err := h.service.GetUsersByIDs(r.Context(), ids)
+	if errors.Is(err, ErrNoUserIDs) || errors.Is(err, ErrTooManyUserIDs) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to fetch users", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	resp := models.UserBatchResponse{Users: make([]models.UserProfile, len(users)), Missing: missing}
+	for i := range users {
+		h.present(&users[i], false)
+		resp.Users[i] = models.ProfileOf(&users[i])
+	}
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// handleUpdateRole handles PUT /api/v1/admin/users/{id}/role
+// @Summary Change user role
+// @Description Sets a user's role to customer or admin. Synced to Keycloak when admin sync is enabled.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body models.UpdateRoleRequest true "New role"
+// @Success 200 {object} models.Account
+// @Failure 400 {string} string "Invalid role"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not found"
+// @Router /admin/users/{id}/role [put]
+func (h *Handler) handleUpdateRole(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["id"]
+
+	var req models.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.service.UpdateRole(r.Context(), userID, req.Role)
+	if errors.Is(err, ErrInvalidRole) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.log.Warn("Failed to update user role", zap.String("id", userID), zap.Error(err))
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	h.present(user, false)
+	response.JSON(w, http.StatusOK, models.AccountOf(user))
+}
+
+// userColumns are the CSV columns of a user export, times in loc; credentials and sync state are never exported
+func userColumns(loc *time.Location) []export.Column[models.User] {
+	return []export.Column[models.User]{
+		{Name: "id", Value: func(u models.User) string { return u.ID }},
+		{Name: "email", Value: func(u models.User) string { return u.Email }},
+		{Name: "first_name", Value: func(u models.User) string { return u.FirstName }},
+		{Name: "last_name", Value: func(u models.User) string { return u.LastName }},
+		{Name: "role", Value: func(u models.User) string { return u.Role }},
+		{Name: "created_at", Value: func(u models.User) string { return export.TimeIn(u.CreatedAt, loc) }},
+		{Name: "updated_at", Value: func(u models.User) string { return export.TimeIn(u.UpdatedAt, loc) }},
+	}
+}
+
+// handleExport handles GET /api/v1/admin/users/export
+// @Summary Export users
+// @Description Streams every user as CSV or NDJSON (chunked). Stops when the client disconnects.
+// @Tags Users
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Security BearerAuth
+// @Param format query string false "csv (default) or ndjson"
+// @Param tz query string false "IANA timezone for CSV timestamps, e.g. Africa/Nairobi (default UTC; also read from the Time-Zone header)"
+// @Success 200 {string} string "Export file"
+// @Failure 400 {string} string "Unsupported format"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /admin/users/export [get]
+func (h *Handler) handleExport(w http.ResponseWriter, r *http.Request) {
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	loc, err := timezone.FromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := export.Stream(r.Context(), w, format, "users", userColumns(loc), h.service.EachUser)
+	if err != nil {
+		h.log.Warn("User export aborted", zap.Int("rows", rows), zap.Error(err))
+		return
+	}
+	h.log.Info("Users exported", zap.String("format", format), zap.Int("rows", rows))
 }
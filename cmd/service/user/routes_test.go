@@ -0,0 +1,118 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository/memory"
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// FuzzLogin feeds arbitrary bodies to POST /login: every one is answered 200, 400 or 401,
+// never 500, and a 200 always carries a token for the seeded account
+func FuzzLogin(f *testing.F) {
+	f.Add(`{"email":"ada@example.com","password":"password"}`)
+	f.Add(`{"username":"ada","password":"password"}`)
+	f.Add(`{"phone":"+254700000001","password":"x"}`)
+	f.Add(`{"email":["ada@example.com"]}`)
+	f.Add(`{"email":"ada@example.com","password":"password"}{"trailing":true}`)
+	f.Add(`null`)
+	f.Add(``)
+
+	users := memory.NewUserRepository()
+	tokens := testutil.NewTokenManager(clock.System)
+	service := NewUserService(users, nil, tokens, nil, zap.NewNop(), &config.Config{})
+	username := "ada"
+	if err := users.CreateUser(context.Background(), &models.User{
+		Email: "ada@example.com", Username: &username, PasswordHash: service.hashPassword("password"), Role: models.RoleCustomer,
+	}); err != nil {
+		f.Fatal(err)
+	}
+	h := &Handler{service: service, avatars: &AvatarService{}, log: zap.NewNop()}
+
+	f.Fuzz(func(t *testing.T, body string) {
+		rec := testutil.Serve(http.HandlerFunc(h.handleLogin), testutil.NewRequest(t, "POST", "/login", body))
+		switch rec.Code {
+		case http.StatusOK:
+			var resp models.AuthResponse
+			testutil.DecodeJSON(t, rec, http.StatusOK, &resp)
+			if resp.Token == "" || resp.User == nil || resp.User.Email != "ada@example.com" {
+				t.Fatalf("login answered 200 without a token for the seeded user: %s", rec.Body.String())
+			}
+		case http.StatusBadRequest, http.StatusUnauthorized:
+			testutil.DecodeError(t, rec)
+		default:
+			t.Fatalf("status = %d for body %q: %s", rec.Code, body, rec.Body.String())
+		}
+	})
+}
+
+// TestGetUserContactDetails checks that a user's email and phone reach only the user
+// themselves and admins; everyone else, signed in or not, gets the public profile
+func TestGetUserContactDetails(t *testing.T) {
+	users := memory.NewUserRepository()
+	tokens := testutil.NewTokenManager(clock.System)
+	username := "ada"
+	ada := &models.User{Email: "ada@example.com", Username: &username, FirstName: "Ada", Phone: "+254700000001"}
+	if err := users.CreateUser(context.Background(), ada); err != nil {
+		t.Fatal(err)
+	}
+	service := NewUserService(users, nil, tokens, nil, zap.NewNop(), &config.Config{})
+	router := mux.NewRouter()
+	NewHandler(service, nil, nil, &AvatarService{}, tokens, nil, nil, zap.NewNop()).RegisterRoutes(router)
+
+	tests := []struct {
+		name    string
+		req     *http.Request
+		private bool
+	}{
+		{"anonymous", testutil.NewRequest(t, "GET", "/users/"+ada.ID, nil), false},
+		{"anonymous batch", testutil.NewRequest(t, "GET", "/users?ids="+ada.ID, nil), false},
+		{"other customer", testutil.CustomerRequest(t, tokens, "user-2", "GET", "/users/"+ada.ID, nil), false},
+		{"self batch", testutil.CustomerRequest(t, tokens, ada.ID, "GET", "/users?ids="+ada.ID, nil), false},
+		{"self", testutil.CustomerRequest(t, tokens, ada.ID, "GET", "/users/"+ada.ID, nil), true},
+		{"admin", testutil.AdminRequest(t, tokens, "admin-1", "GET", "/users/"+ada.ID, nil), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := testutil.Serve(router, tt.req)
+			var user map[string]interface{}
+			if tt.req.URL.Query().Has("ids") {
+				var resp struct {
+					Users []map[string]interface{} `json:"users"`
+				}
+				testutil.DecodeJSON(t, rec, http.StatusOK, &resp)
+				if len(resp.Users) != 1 {
+					t.Fatalf("got %d users, want 1: %s", len(resp.Users), rec.Body.String())
+				}
+				user = resp.Users[0]
+			} else {
+				testutil.DecodeJSON(t, rec, http.StatusOK, &user)
+			}
+
+			if user["id"] != ada.ID || user["username"] != "ada" || user["first_name"] != "Ada" {
+				t.Fatalf("profile = %s, want Ada's id, username and first name", rec.Body.String())
+			}
+			_, hasEmail := user["email"]
+			_, hasPhone := user["phone"]
+			if hasEmail != tt.private || hasPhone != tt.private {
+				t.Fatalf("email shown %v, phone shown %v; want %v: %s", hasEmail, hasPhone, tt.private, rec.Body.String())
+			}
+			if !tt.private {
+				for key := range user {
+					switch key {
+					case "id", "username", "first_name", "avatar_url", "_links":
+					default:
+						t.Errorf("public profile has %q: %s", key, rec.Body.String())
+					}
+				}
+			}
+		})
+	}
+}
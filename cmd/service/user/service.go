@@ -5,9 +5,15 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"strings"
 
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
 	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/email"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/i18n"
 	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/phone"
 	"github.com/Jason-Omondi/ecomgo/internal/repository"
 	"go.uber.org/zap"
 )
@@ -16,17 +22,23 @@ import (
 // Service layer: coordinates between HTTP handlers and data repositories
 // Config is injected once and reused for all operations
 type UserService struct {
-	userRepo *repository.UserRepository
-	log      *zap.Logger
-	config   *config.Config // Store config for Keycloak, external services, etc.
+	userRepo  repository.UserStore
+	publisher events.Publisher // Domain events (user.registered) for other modules
+	tokens    *auth.TokenManager
+	mailer    *email.Mailer
+	log       *zap.Logger
+	config    *config.Config // Store config for Keycloak, external services, etc.
 }
 
-func NewUserService(userRepo *repository.UserRepository,
-	log *zap.Logger, cfg *config.Config) *UserService {
+func NewUserService(userRepo repository.UserStore, publisher events.Publisher,
+	tokens *auth.TokenManager, mailer *email.Mailer, log *zap.Logger, cfg *config.Config) *UserService {
 	return &UserService{
-		userRepo: userRepo,
-		log:      log,
-		config:   cfg,
+		userRepo:  userRepo,
+		publisher: publisher,
+		tokens:    tokens,
+		mailer:    mailer,
+		log:       log,
+		config:    cfg,
 	}
 }
 
@@ -42,35 +54,82 @@ func (s *UserService) Register(ctx context.Context,
 	if existingUser != nil {
 		s.log.Warn("Registration failed: user already exists",
 			zap.String("email", req.Email))
-		return nil, errors.New("user already exists")
+		return nil, ErrUserExists
+	}
+
+	phoneNumber, err := s.checkPhone(ctx, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	var username *string
+	if req.Username != "" {
+		name, err := s.checkUsername(ctx, req.Username)
+		if err != nil {
+			return nil, err
+		}
+		username = &name
 	}
 
 	// Hash password - use bcrypt in production for security
 	// SHA256 used here for demo; replace with golang.org/x/crypto/bcrypt for production
 	hashedPassword := s.hashPassword(req.Password)
 
+	// An explicit locale wins; otherwise keep the one negotiated for this request
+	locale := i18n.FromContext(ctx)
+	if req.Locale != "" {
+		locale = i18n.Negotiate(req.Locale)
+	}
+
 	user := &models.User{
 		Email:        req.Email,
+		Username:     username,
 		PasswordHash: hashedPassword,
 		FirstName:    req.FirstName,
 		LastName:     req.LastName,
+		Phone:        phoneNumber,
+		Locale:       locale,
 	}
 
 	// Create user in database
+	// Two registrations racing past the check above meet at the unique indexes
 	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			s.log.Warn("Registration failed: user already exists", zap.String("email", req.Email))
+			return nil, ErrUserExists
+		}
 		s.log.Error("Failed to create user",
 			zap.String("email", req.Email), zap.Error(err))
 		return nil, err
 	}
 
-	// Use config.Keycloak settings when generating real OAuth tokens
-	token := s.generateToken(user.ID)
+	token, expiresAt, err := s.tokens.Issue(user)
+	if err != nil {
+		s.log.Error("Failed to issue token", zap.String("email", user.Email), zap.Error(err))
+		return nil, err
+	}
 
 	s.log.Info("User registered successfully", zap.String("email", user.Email))
 
+	// Notify other modules (welcome email, analytics...)
+	// Publish failure is logged only - the account already exists
+	_ = events.Publish(ctx, s.publisher, s.log, events.TypeUserRegistered, events.UserRegistered{
+		UserID:       user.ID,
+		Email:        user.Email,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		ReferralCode: req.ReferralCode,
+	})
+
+	// Welcome email is sent in the background - provider latency never slows registration
+	if err := s.mailer.SendAsync(ctx, email.TemplateWelcome, user.Locale, user.Email, user.FirstName, nil); err != nil {
+		s.log.Warn("Failed to queue welcome email", zap.String("email", user.Email), zap.Error(err))
+	}
+
 	return &models.AuthResponse{
-		Token: token,
-		User:  user,
+		Token:     token,
+		User:      models.AccountOf(user),
+		ExpiresAt: expiresAt.Unix(),
 	}, nil
 }
 
@@ -78,35 +137,139 @@ func (s *UserService) Register(ctx context.Context,
 // Uses config for token expiry, Keycloak realm, etc.
 func (s *UserService) Login(ctx context.Context,
 	req *models.LoginRequest) (*models.AuthResponse, error) {
-	s.log.Info("User login attempt", zap.String("email", req.Email))
+	s.log.Info("User login attempt", zap.String("email", req.Email), zap.String("username", req.Username),
+		zap.Bool("by_phone", req.Phone != ""))
 
-	// Fetch user by email
-	user, err := s.userRepo.GetUserByEmail(ctx, req.Email)
+	// Fetch user by email, username or phone number
+	user, err := s.findLoginUser(ctx, req)
 	if err != nil {
+		if errors.Is(err, ErrLoginIdentifierRequired) || errors.Is(err, ErrPhoneLoginDisabled) {
+			return nil, err
+		}
 		s.log.Warn("Login failed: user not found",
-			zap.String("email", req.Email))
-		return nil, errors.New("invalid credentials")
+			zap.String("email", req.Email), zap.Error(err))
+		return nil, ErrInvalidCredentials
 	}
 
 	// Verify password
 	if !s.verifyPassword(req.Password, user.PasswordHash) {
 		s.log.Warn("Login failed: invalid password",
-			zap.String("email", req.Email))
-		return nil, errors.New("invalid credentials")
+			zap.String("email", user.Email))
+		return nil, ErrInvalidCredentials
 	}
 
-	// Access config.Keycloak.URL, config.Keycloak.Realm etc. here
-	token := s.generateToken(user.ID)
+	token, expiresAt, err := s.tokens.Issue(user)
+	if err != nil {
+		s.log.Error("Failed to issue token", zap.String("email", user.Email), zap.Error(err))
+		return nil, err
+	}
 
 	s.log.Info("User logged in successfully",
 		zap.String("email", user.Email))
 
 	return &models.AuthResponse{
-		Token: token,
-		User:  user,
+		Token:     token,
+		User:      models.AccountOf(user),
+		ExpiresAt: expiresAt.Unix(),
 	}, nil
 }
 
+var (
+	ErrUserExists              = errors.New("user already exists")
+	ErrInvalidCredentials      = errors.New("invalid credentials")
+	ErrLoginIdentifierRequired = errors.New("email, username or phone is required")
+	ErrPhoneLoginDisabled      = errors.New("signing in with a phone number is not enabled")
+	ErrPhoneTaken              = errors.New("phone number is already in use")
+)
+
+// findLoginUser looks the user up by email, else username, else phone
+// A single sign-in field can post a username as email - anything without @ is treated as one
+// Phone sign-in needs PHONE_UNIQUE, otherwise a number could match several accounts
+func (s *UserService) findLoginUser(ctx context.Context, req *models.LoginRequest) (*models.User, error) {
+	switch {
+	case strings.Contains(req.Email, "@"):
+		return s.userRepo.GetUserByEmail(ctx, req.Email)
+	case req.Email != "" || req.Username != "":
+		name := req.Username
+		if name == "" {
+			name = req.Email
+		}
+		return s.userRepo.GetUserByUsername(ctx, usernameKey(name))
+	case req.Phone == "":
+		return nil, ErrLoginIdentifierRequired
+	case !s.config.Accounts.UniquePhone:
+		return nil, ErrPhoneLoginDisabled
+	}
+
+	number, err := phone.Normalize(req.Phone, s.config.Accounts.PhoneRegion)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.userRepo.GetUsersByPhone(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) != 1 {
+		// Duplicates predate PHONE_UNIQUE - refuse rather than guess
+		return nil, repository.ErrUserNotFound
+	}
+	return &users[0], nil
+}
+
+// checkPhone normalizes a phone number for a new account and, with PHONE_UNIQUE, makes sure
+// no other account uses it
+// Returns: the E.164 number, or "" when raw is empty
+func (s *UserService) checkPhone(ctx context.Context, raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	number, err := phone.Normalize(raw, s.config.Accounts.PhoneRegion)
+	if err != nil {
+		return "", err
+	}
+	if s.config.Accounts.UniquePhone {
+		users, err := s.userRepo.GetUsersByPhone(ctx, number)
+		if err != nil {
+			return "", err
+		}
+		if len(users) > 0 {
+			return "", ErrPhoneTaken
+		}
+	}
+	return number, nil
+}
+
+// checkUsername normalizes a requested username and makes sure no other account has it
+func (s *UserService) checkUsername(ctx context.Context, raw string) (string, error) {
+	username, err := normalizeUsername(raw)
+	if err != nil {
+		return "", err
+	}
+	_, err = s.userRepo.GetUserByUsername(ctx, username)
+	switch {
+	case err == nil:
+		return "", ErrUsernameTaken
+	case !errors.Is(err, repository.ErrUserNotFound):
+		return "", err
+	}
+	return username, nil
+}
+
+// UsernameAvailability reports whether raw could be registered right now
+// Invalid, reserved and taken names are all "unavailable", with the reason
+func (s *UserService) UsernameAvailability(ctx context.Context, raw string) (*models.UsernameAvailability, error) {
+	_, err := s.checkUsername(ctx, raw)
+	result := &models.UsernameAvailability{Username: usernameKey(raw), Available: err == nil}
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrInvalidUsername), errors.Is(err, ErrReservedUsername), errors.Is(err, ErrUsernameTaken):
+		result.Reason = err.Error()
+	default:
+		return nil, err
+	}
+	return result, nil
+}
+
 // GetUserByID retrieves user data by ID
 // Returns: user object if found, error if not found
 // Why here: delegates to repository after validating context
@@ -123,6 +286,93 @@ func (s *UserService) GetUserByID(ctx context.Context,
 	return user, nil
 }
 
+// MaxBatchUsers caps GET /users?ids=... so one request can't turn into an unbounded IN query
+const MaxBatchUsers = 100
+
+var (
+	ErrNoUserIDs      = errors.New("ids is required")
+	ErrTooManyUserIDs = errors.New("too many ids (max 100)")
+)
+
+// GetUsersByIDs resolves many users in one query
+// Duplicate and blank IDs are ignored; results keep the order of the first occurrence of each ID
+func (s *UserService) GetUsersByIDs(ctx context.Context, ids []string) (found []models.User, missing []string, err error) {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	if len(unique) == 0 {
+		return nil, nil, ErrNoUserIDs
+	}
+	if len(unique) > MaxBatchUsers {
+		return nil, nil, ErrTooManyUserIDs
+	}
+
+	users, err := s.userRepo.GetUsersByIDs(ctx, unique)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[string]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	found = make([]models.User, 0, len(users))
+	missing = []string{}
+	for _, id := range unique {
+		if user, ok := byID[id]; ok {
+			found = append(found, user)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return found, missing, nil
+}
+
+// EachUser feeds every user to fn in batches, for streaming exports
+func (s *UserService) EachUser(ctx context.Context, batchSize int, fn func([]models.User) error) error {
+	return s.userRepo.EachUser(ctx, batchSize, fn)
+}
+
+// ErrInvalidRole is returned when a role other than customer or admin is requested
+var ErrInvalidRole = errors.New("role must be customer or admin")
+
+// UpdateRole changes a user's role and publishes user.role_changed
+// Existing tokens keep the old role until they expire
+func (s *UserService) UpdateRole(ctx context.Context, id, role string) (*models.User, error) {
+	if role != models.RoleCustomer && role != models.RoleAdmin {
+		return nil, ErrInvalidRole
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	if err := s.userRepo.UpdateFields(ctx, id, map[string]interface{}{"role": role}); err != nil {
+		return nil, err
+	}
+	user.Role = role
+
+	s.log.Info("User role changed", zap.String("id", id), zap.String("role", role))
+
+	// Keycloak sync (when enabled) pushes the new role to the realm
+	_ = events.Publish(ctx, s.publisher, s.log, events.TypeUserRoleChanged, events.UserRoleChanged{
+		UserID: id,
+		Role:   role,
+	})
+
+	return user, nil
+}
+
 // hashPassword hashes password using SHA256
 // For production: use golang.org/x/crypto/bcrypt instead
 // Returns: hex-encoded hash string
@@ -136,12 +386,3 @@ func (s *UserService) hashPassword(password string) string {
 func (s *UserService) verifyPassword(password, hash string) bool {
 	return s.hashPassword(password) == hash
 }
-
-// generateToken creates a simple JWT-like token (implement real JWT with Keycloak in production)
-// For production: integrate with Keycloak OAuth2 token endpoint
-// Returns: token string
-func (s *UserService) generateToken(userID string) string {
-	// TODO: Use s.config.Keycloak to call real OAuth2 token endpoint
-	// Example: s.config.Keycloak.URL, s.config.Keycloak.ClientID
-	return "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." + userID
-}
@@ -0,0 +1,11 @@
+package user
+
+import "testing"
+
+func BenchmarkHashPassword(b *testing.B) {
+	s := &UserService{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.hashPassword("correct horse battery staple")
+	}
+}
@@ -0,0 +1,104 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type TokenHandler struct {
+	service *TokenService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewTokenHandler(service *TokenService, tokens *auth.TokenManager, log *zap.Logger) *TokenHandler {
+	return &TokenHandler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers scoped token issuance and sign-out for the signed-in user
+func (h *TokenHandler) RegisterRoutes(router *mux.Router) {
+	// Any token can revoke itself, so a leaked integration token can be shut off with itself
+	logout := auth.Authenticate(h.tokens)(http.HandlerFunc(h.handleLogout))
+	router.Handle("/logout", auth.RequireScope(auth.KnownScopes...)(auth.AllowImpersonation(logout))).Methods("POST")
+
+	tokens := router.PathPrefix("/tokens").Subrouter()
+	tokens.Use(auth.Authenticate(h.tokens))
+	tokens.HandleFunc("", h.handleIssue).Methods("POST")
+}
+
+// handleIssue handles POST /api/v1/tokens
+// @Summary Issue scoped token
+// @Description Issues a token limited to the given scopes, for API integrations. It works only on routes that accept one of its scopes, and never grants more than the caller's role. Requires a sign-in token; scoped and impersonation tokens can't issue tokens. Admins can issue tokens for a tenant, whose quotas and feature flags then apply to its requests.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ScopedTokenRequest true "Scopes and lifetime"
+// @Success 201 {object} models.ScopedTokenResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Scoped and impersonation tokens cannot issue tokens, or tenant requested by a non-admin"
+// @Router /tokens [post]
+func (h *TokenHandler) handleIssue(w http.ResponseWriter, r *http.Request) {
+	var req models.ScopedTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.Issue(r.Context(), auth.ClaimsFromContext(r.Context()), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusCreated, resp)
+}
+
+// handleLogout handles POST /api/v1/logout
+// @Summary Sign out
+// @Description Revokes the token the request is made with: every instance refuses it from now on, until it would have expired. Other tokens of the same user stay valid. Works with sign-in, impersonation and scoped tokens.
+// @Tags Authentication
+// @Security BearerAuth
+// @Success 204 "Token revoked"
+// @Failure 400 {string} string "Token cannot be revoked"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /logout [post]
+func (h *TokenHandler) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Revoke(r.Context(), auth.ClaimsFromContext(r.Context())); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TokenHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidTokenRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrTokenNotIssuable):
+		http.Error(w, "Scoped and impersonation tokens cannot issue tokens", http.StatusForbidden)
+	case errors.Is(err, ErrTenantTokenForbidden):
+		http.Error(w, "Only admins can issue tokens for a tenant", http.StatusForbidden)
+	case errors.Is(err, auth.ErrNotRevocable):
+		http.Error(w, "Token cannot be revoked", http.StatusBadRequest)
+	case errors.Is(err, repository.ErrUserNotFound):
+		http.Error(w, "User not found", http.StatusNotFound)
+	default:
+		h.log.Error("Token request failed", zap.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
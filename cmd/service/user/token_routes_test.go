@@ -0,0 +1,130 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository/memory"
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// TestLogout checks that POST /logout revokes the token it is called with, and only that one
+func TestLogout(t *testing.T) {
+	users := memory.NewUserRepository()
+	ada := &models.User{Email: "ada@example.com", Role: models.RoleCustomer}
+	if err := users.CreateUser(context.Background(), ada); err != nil {
+		t.Fatal(err)
+	}
+	tokens := testutil.NewTokenManager(clock.System)
+	router := mux.NewRouter()
+	NewTokenHandler(NewTokenService(users, tokens, time.Hour, zap.NewNop()), tokens, zap.NewNop()).RegisterRoutes(router)
+
+	issue := func(t *testing.T) string {
+		t.Helper()
+		token, _, err := tokens.Issue(ada)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	call := func(t *testing.T, token, method, target string, body interface{}) int {
+		t.Helper()
+		req := testutil.NewRequest(t, method, target, body)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return testutil.Serve(router, req).Code
+	}
+
+	t.Run("token refused after logout", func(t *testing.T) {
+		token := issue(t)
+		if code := call(t, token, "POST", "/logout", nil); code != http.StatusNoContent {
+			t.Fatalf("logout: status %d, want 204", code)
+		}
+		scopes := models.ScopedTokenRequest{Scopes: []string{auth.ScopeReadOrders}}
+		if code := call(t, token, "POST", "/tokens", scopes); code != http.StatusUnauthorized {
+			t.Fatalf("request after logout: status %d, want 401", code)
+		}
+		if code := call(t, token, "POST", "/logout", nil); code != http.StatusUnauthorized {
+			t.Fatalf("second logout: status %d, want 401", code)
+		}
+	})
+
+	t.Run("other sessions stay signed in", func(t *testing.T) {
+		phone, laptop := issue(t), issue(t)
+		if code := call(t, phone, "POST", "/logout", nil); code != http.StatusNoContent {
+			t.Fatalf("logout: status %d, want 204", code)
+		}
+		if code := call(t, laptop, "POST", "/logout", nil); code != http.StatusNoContent {
+			t.Fatalf("logout of the other session: status %d, want 204", code)
+		}
+	})
+
+	t.Run("scoped token revokes itself", func(t *testing.T) {
+		token, _, err := tokens.IssueScoped(ada, []string{auth.ScopeReadProducts}, "", time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code := call(t, token, "POST", "/logout", nil); code != http.StatusNoContent {
+			t.Fatalf("logout with a scoped token: status %d, want 204", code)
+		}
+		if _, err := tokens.Verify(context.Background(), token); err == nil {
+			t.Fatal("scoped token still valid after logout")
+		}
+	})
+
+	t.Run("without a token", func(t *testing.T) {
+		rec := testutil.Serve(router, testutil.NewRequest(t, "POST", "/logout", nil))
+		testutil.AssertError(t, rec, http.StatusUnauthorized, "Missing bearer token")
+	})
+}
+
+// TestImpersonationIsReadOnly checks that support can look at a customer's account with an
+// impersonation token but not change it, and can still end the session
+func TestImpersonationIsReadOnly(t *testing.T) {
+	users := memory.NewUserRepository()
+	ada := &models.User{Email: "ada@example.com", Role: models.RoleCustomer}
+	if err := users.CreateUser(context.Background(), ada); err != nil {
+		t.Fatal(err)
+	}
+	tokens := testutil.NewTokenManager(clock.System)
+	router := mux.NewRouter()
+	NewAddressHandler(NewAddressService(memory.NewAddressRepository(), nil, nil, zap.NewNop()), tokens, zap.NewNop()).
+		RegisterRoutes(router)
+	NewAvatarHandler(nil, tokens, zap.NewNop()).RegisterRoutes(router)
+	NewTokenHandler(NewTokenService(users, tokens, time.Hour, zap.NewNop()), tokens, zap.NewNop()).RegisterRoutes(router)
+
+	token, _, err := tokens.IssueImpersonation(ada, "admin-1", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := func(t *testing.T, method, target string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		req := testutil.NewRequest(t, method, target, body)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return testutil.Serve(router, req)
+	}
+
+	if rec := call(t, "GET", "/users/me/addresses", nil); rec.Code != http.StatusOK {
+		t.Fatalf("GET addresses: status %d, want 200", rec.Code)
+	}
+	for _, write := range []struct{ method, target string }{
+		{"POST", "/users/me/addresses"},
+		{"DELETE", "/users/me/addresses/address-1"},
+		{"PUT", "/users/me/avatar"},
+		{"DELETE", "/users/me/avatar"},
+		{"POST", "/tokens"},
+	} {
+		rec := call(t, write.method, write.target, "{}")
+		testutil.AssertError(t, rec, http.StatusForbidden, "Impersonation tokens are read-only")
+	}
+	if rec := call(t, "POST", "/logout", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("logout: status %d, want 204", rec.Code)
+	}
+}
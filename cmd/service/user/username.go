@@ -0,0 +1,41 @@
+package user
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidUsername  = errors.New("username must be 3-30 characters of a-z, 0-9, _ and ., starting with a letter")
+	ErrReservedUsername = errors.New("username is reserved")
+	ErrUsernameTaken    = errors.New("username is already taken")
+)
+
+// usernamePattern: starts with a letter, no leading/trailing or doubled dots
+var usernamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z0-9_]+)*$`)
+
+// reservedUsernames would be confusing in profile URLs or support conversations
+var reservedUsernames = map[string]bool{
+	"admin": true, "administrator": true, "api": true, "help": true, "me": true, "moderator": true,
+	"null": true, "root": true, "security": true, "staff": true, "support": true, "system": true,
+}
+
+// usernameKey is the stored form of raw: lower case without a leading @,
+// so @Amina and amina are the same name
+func usernameKey(raw string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(raw), "@"))
+}
+
+// normalizeUsername returns the stored form of raw after checking it can be used
+// Returns: ErrInvalidUsername or ErrReservedUsername when it can't
+func normalizeUsername(raw string) (string, error) {
+	username := usernameKey(raw)
+	if len(username) < 3 || len(username) > 30 || !usernamePattern.MatchString(username) {
+		return username, ErrInvalidUsername
+	}
+	if reservedUsernames[username] {
+		return username, ErrReservedUsername
+	}
+	return username, nil
+}
@@ -0,0 +1,166 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	pollInterval    = 5 * time.Second
+	batchSize       = 50
+	maxAttempts     = 8
+	baseBackoff     = 30 * time.Second
+	maxBackoff      = 6 * time.Hour
+	maxResponseBody = 1024 // bytes of consumer response kept in the delivery log
+)
+
+// Dispatcher sends queued webhook deliveries and schedules retries with exponential backoff
+// Implements module.Service - runs alongside the HTTP server
+type Dispatcher struct {
+	repo   *repository.WebhookRepository
+	client *http.Client
+	clock  clock.Clock
+	log    *zap.Logger
+}
+
+func NewDispatcher(repo *repository.WebhookRepository, clk clock.Clock, log *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		client: httpclient.New(10 * time.Second),
+		clock:  clk,
+		log:    log,
+	}
+}
+
+func (d *Dispatcher) Name() string {
+	return "webhook-dispatcher"
+}
+
+// Run polls for due deliveries until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			d.dispatchDue(ctx)
+		}
+	}
+}
+
+// dispatchDue sends one batch of due deliveries
+// The dispatcher runs on the elected leader only, so deliveries are never sent twice
+func (d *Dispatcher) dispatchDue(ctx context.Context) {
+	deliveries, err := d.repo.ListDueDeliveries(ctx, d.clock.Now(), batchSize)
+	if err != nil {
+		return
+	}
+
+	for i := range deliveries {
+		d.deliver(ctx, &deliveries[i])
+	}
+}
+
+// deliver performs one attempt and records the outcome
+func (d *Dispatcher) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	sub, err := d.repo.GetSubscriptionByID(ctx, delivery.SubscriptionID)
+	if err != nil || !sub.Active {
+		delivery.Status = models.DeliveryFailed
+		delivery.LastError = "subscription removed or inactive"
+		d.repo.UpdateDelivery(ctx, delivery)
+		return
+	}
+
+	delivery.Attempts++
+	code, body, err := d.send(ctx, sub, delivery)
+	delivery.ResponseCode = code
+	delivery.ResponseBody = body
+
+	if err == nil {
+		now := d.clock.Now()
+		delivery.Status = models.DeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		d.log.Info("Webhook delivered", zap.String("delivery_id", delivery.ID),
+			zap.String("event_type", delivery.EventType), zap.Int("attempts", delivery.Attempts))
+	} else {
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= maxAttempts {
+			delivery.Status = models.DeliveryFailed
+			d.log.Warn("Webhook delivery failed permanently", zap.String("delivery_id", delivery.ID),
+				zap.String("url", sub.URL), zap.Error(err))
+		} else {
+			delivery.NextAttemptAt = d.clock.Now().Add(backoff(delivery.Attempts))
+			d.log.Warn("Webhook delivery failed, will retry", zap.String("delivery_id", delivery.ID),
+				zap.Int("attempts", delivery.Attempts), zap.Time("next_attempt_at", delivery.NextAttemptAt), zap.Error(err))
+		}
+	}
+
+	d.repo.UpdateDelivery(ctx, delivery)
+}
+
+// send POSTs the signed payload
+// Returns: response status, truncated body, and error for transport failures or non-2xx responses
+func (d *Dispatcher) send(ctx context.Context, sub *models.WebhookSubscription,
+	delivery *models.WebhookDelivery) (int, string, error) {
+	timestamp := strconv.FormatInt(d.clock.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "EcomGo-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Id", delivery.ID)
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Signature", "t="+timestamp+",v1="+Sign(sub.Secret, timestamp, delivery.Payload))
+	if delivery.ReplayOf != "" {
+		req.Header.Set("X-Webhook-Replay-Of", delivery.ReplayOf)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(body), fmt.Errorf("consumer responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// Sign computes the delivery signature: hex(HMAC-SHA256(secret, "<timestamp>.<payload>"))
+// Consumers recompute it with their secret and reject mismatches or stale timestamps (replay protection)
+func Sign(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff returns the delay before retry number attempts: 30s, 1m, 2m, 4m... capped at 6h
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff << (attempts - 1)
+	if delay <= 0 || delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
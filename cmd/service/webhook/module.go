@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"github.com/Jason-Omondi/ecomgo/internal/lock"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/module"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Module provides outbound webhooks: subscription management, event fan-out and signed delivery
+type Module struct {
+	handler    *Handler
+	dispatcher lock.Service
+}
+
+// NewModule builds the webhook module and subscribes it to the domain events it forwards
+func NewModule(deps module.Deps) *Module {
+	repo := repository.NewWebhookRepository(deps.DB, deps.Log)
+	service := NewWebhookService(repo, deps.Clock, deps.Log)
+
+	// Queue deliveries for every forwardable event
+	for eventType := range publicEventTypes {
+		if err := deps.Events.Subscribe(eventType, "webhooks", service.HandleEvent); err != nil {
+			deps.Log.Error("Failed to subscribe webhooks to event", zap.String("type", eventType), zap.Error(err))
+		}
+	}
+
+	// Only the elected leader dispatches, so a delivery is never sent by two instances
+	dispatcher := NewDispatcher(repo, deps.Clock, deps.Log)
+
+	return &Module{
+		handler:    NewHandler(service, deps.Tokens, deps.Log),
+		dispatcher: lock.Singleton(deps.Locks, dispatcher, deps.Config.Locks.LeaderTTL, deps.Log),
+	}
+}
+
+func (m *Module) Migrations() []migrations.Migration {
+	return []migrations.Migration{
+		migrations.AutoMigrate(&models.WebhookSubscription{}, &models.WebhookDelivery{}),
+	}
+}
+
+func (m *Module) RegisterRoutes(router *mux.Router) {
+	m.handler.RegisterRoutes(router)
+}
+
+// Services runs the delivery dispatcher (sends and retries queued deliveries)
+func (m *Module) Services() []module.Service {
+	return []module.Service{m.dispatcher}
+}
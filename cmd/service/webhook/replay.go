@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+)
+
+// maxReplay is the most deliveries one range replay queues
+const maxReplay = 1000
+
+var (
+	// ErrDeliveryPending is returned when replaying a delivery the dispatcher is still working on
+	ErrDeliveryPending = errors.New("delivery is still pending")
+	// ErrWebhookInactive is returned when replaying to a subscription that no longer receives deliveries
+	ErrWebhookInactive = errors.New("webhook is inactive")
+	// ErrInvalidReplay wraps problems with a replay request
+	ErrInvalidReplay = errors.New("invalid replay")
+)
+
+// replayStatuses maps a replay request's status to the delivery states it selects
+var replayStatuses = map[string][]string{
+	"":                       {models.DeliveryFailed},
+	models.DeliveryFailed:    {models.DeliveryFailed},
+	models.DeliverySucceeded: {models.DeliverySucceeded},
+	"all":                    {models.DeliveryFailed, models.DeliverySucceeded},
+}
+
+// ListOwnerDeliveries returns the delivery log across all of ownerID's subscriptions
+func (s *WebhookService) ListOwnerDeliveries(ctx context.Context, ownerID, status string, limit, offset int) ([]models.WebhookDelivery, error) {
+	return s.repo.ListDeliveriesByOwner(ctx, ownerID, status, limit, offset)
+}
+
+// GetDelivery returns one delivery of one of ownerID's subscriptions, with its payload and
+// the consumer's last response
+func (s *WebhookService) GetDelivery(ctx context.Context, id, deliveryID, ownerID string) (*models.WebhookDelivery, error) {
+	if _, err := s.repo.GetSubscription(ctx, id, ownerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDelivery(ctx, id, deliveryID)
+}
+
+// ReplayDelivery queues a new delivery of the same event and payload; the original keeps its
+// outcome in the log. The replay is signed afresh when sent and names the original in
+// X-Webhook-Replay-Of; the payload's event id is unchanged, so consumers can deduplicate
+func (s *WebhookService) ReplayDelivery(ctx context.Context, id, deliveryID, ownerID string) (*models.WebhookDelivery, error) {
+	sub, err := s.repo.GetSubscription(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if !sub.Active {
+		return nil, ErrWebhookInactive
+	}
+	original, err := s.repo.GetDelivery(ctx, id, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original.Status == models.DeliveryPending {
+		return nil, ErrDeliveryPending
+	}
+
+	replays := []models.WebhookDelivery{s.replay(original)}
+	if err := s.repo.CreateDeliveries(ctx, replays); err != nil {
+		return nil, err
+	}
+	s.log.Info("Webhook delivery replayed", zap.String("delivery_id", original.ID), zap.String("replay_id", replays[0].ID),
+		zap.String("owner_id", ownerID))
+	return &replays[0], nil
+}
+
+// ReplayRange replays the subscription's original deliveries created in the request's time range
+// Returns: ErrInvalidReplay when the range is empty or matches more than maxReplay deliveries
+func (s *WebhookService) ReplayRange(ctx context.Context, id, ownerID string, req *models.WebhookReplayRequest) (*models.WebhookReplayResponse, error) {
+	sub, err := s.repo.GetSubscription(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if !sub.Active {
+		return nil, ErrWebhookInactive
+	}
+	statuses, ok := replayStatuses[req.Status]
+	if !ok {
+		return nil, fmt.Errorf("%w: status must be failed, succeeded or all", ErrInvalidReplay)
+	}
+	to := req.To
+	if to.IsZero() {
+		to = s.clock.Now()
+	}
+	if req.From.IsZero() || !req.From.Before(to) {
+		return nil, fmt.Errorf("%w: from is required and must be before to", ErrInvalidReplay)
+	}
+
+	originals, err := s.repo.ListReplayable(ctx, id, statuses, req.From, to, maxReplay+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(originals) > maxReplay {
+		return nil, fmt.Errorf("%w: more than %d deliveries match, narrow the time range", ErrInvalidReplay, maxReplay)
+	}
+
+	replays := make([]models.WebhookDelivery, len(originals))
+	for i := range originals {
+		replays[i] = s.replay(&originals[i])
+	}
+	if err := s.repo.CreateDeliveries(ctx, replays); err != nil {
+		return nil, err
+	}
+	s.log.Info("Webhook deliveries replayed", zap.String("subscription_id", id), zap.Int("count", len(replays)),
+		zap.Time("from", req.From), zap.Time("to", to), zap.String("owner_id", ownerID))
+	return &models.WebhookReplayResponse{Replayed: len(replays), Deliveries: replays}, nil
+}
+
+func (s *WebhookService) replay(original *models.WebhookDelivery) models.WebhookDelivery {
+	return models.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.DeliveryPending,
+		NextAttemptAt:  s.clock.Now(),
+		ReplayOf:       original.ID,
+	}
+}
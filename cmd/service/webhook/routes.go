@@ -0,0 +1,280 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/pagination"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service *WebhookService
+	tokens  *auth.TokenManager
+	log     *zap.Logger
+}
+
+func NewHandler(service *WebhookService, tokens *auth.TokenManager, log *zap.Logger) *Handler {
+	return &Handler{
+		service: service,
+		tokens:  tokens,
+		log:     log,
+	}
+}
+
+// RegisterRoutes registers webhook management routes
+// All routes require an admin token - webhooks expose store-wide order and product events
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	webhooks := router.PathPrefix("/webhooks").Subrouter()
+	webhooks.Use(auth.Authenticate(h.tokens), auth.RequireRole(models.RoleAdmin))
+
+	webhooks.HandleFunc("", h.handleCreate).Methods("POST")
+	webhooks.HandleFunc("", h.handleList).Methods("GET")
+	webhooks.HandleFunc("/deliveries", h.handleListAllDeliveries).Methods("GET")
+	webhooks.HandleFunc("/{id}", h.handleDelete).Methods("DELETE")
+	webhooks.HandleFunc("/{id}/deliveries", h.handleListDeliveries).Methods("GET")
+	webhooks.HandleFunc("/{id}/deliveries/{deliveryID}", h.handleGetDelivery).Methods("GET")
+	webhooks.HandleFunc("/{id}/deliveries/{deliveryID}/replay", h.handleReplayDelivery).Methods("POST")
+	webhooks.HandleFunc("/{id}/replay", h.handleReplayRange).Methods("POST")
+}
+
+// handleCreate handles POST /api/v1/webhooks
+// @Summary Register webhook
+// @Description Registers a callback URL for event types (order.created, order.paid, product.updated). The signing secret is only returned once.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateWebhookRequest true "Webhook registration"
+// @Success 201 {object} models.CreateWebhookResponse
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Forbidden"
+// @Router /webhooks [post]
+func (h *Handler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log.Warn("Invalid webhook request", zap.Error(err))
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.CreateSubscription(r.Context(), claims.UserID(), &req)
+	if err != nil {
+		h.log.Warn("Webhook registration failed", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, resp)
+}
+
+// handleList handles GET /api/v1/webhooks
+// @Summary List webhooks
+// @Description Lists the caller's webhook subscriptions
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.WebhookSubscription
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /webhooks [get]
+func (h *Handler) handleList(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	subs, err := h.service.ListSubscriptions(r.Context(), claims.UserID())
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, subs)
+}
+
+// handleDelete handles DELETE /api/v1/webhooks/{id}
+// @Summary Delete webhook
+// @Description Removes a webhook subscription and its delivery log
+// @Tags Webhooks
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 204
+// @Failure 404 {string} string "Webhook not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /webhooks/{id} [delete]
+func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+	id := mux.Vars(r)["id"]
+
+	if err := h.service.DeleteSubscription(r.Context(), id, claims.UserID()); err != nil {
+		h.writeLookupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListDeliveries handles GET /api/v1/webhooks/{id}/deliveries
+// @Summary List webhook deliveries
+// @Description Returns the delivery log (status, attempts, consumer response) for a subscription
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param status query string false "Filter by status (pending, succeeded, failed)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 404 {string} string "Webhook not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *Handler) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+	id := mux.Vars(r)["id"]
+	limit, offset := pagination.FromRequest(r)
+
+	deliveries, err := h.service.ListDeliveries(r.Context(), id, claims.UserID(),
+		r.URL.Query().Get("status"), limit, offset)
+	if err != nil {
+		h.writeLookupError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, deliveries)
+}
+
+// handleListAllDeliveries handles GET /api/v1/webhooks/deliveries
+// @Summary List deliveries of all webhooks
+// @Description Returns the delivery log across the caller's subscriptions, newest first. Use status=failed to find deliveries to replay.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status (pending, succeeded, failed)"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Items to skip"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 500 {string} string "Internal server error"
+// @Router /webhooks/deliveries [get]
+func (h *Handler) handleListAllDeliveries(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+	limit, offset := pagination.FromRequest(r)
+
+	deliveries, err := h.service.ListOwnerDeliveries(r.Context(), claims.UserID(), r.URL.Query().Get("status"), limit, offset)
+	if err != nil {
+		h.writeLookupError(w, err)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+
+	response.JSON(w, http.StatusOK, deliveries)
+}
+
+// handleGetDelivery handles GET /api/v1/webhooks/{id}/deliveries/{deliveryID}
+// @Summary Get webhook delivery
+// @Description Returns one delivery with the payload sent and the consumer's last response
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param deliveryID path string true "Delivery ID"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 404 {string} string "Webhook or delivery not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /webhooks/{id}/deliveries/{deliveryID} [get]
+func (h *Handler) handleGetDelivery(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+	vars := mux.Vars(r)
+
+	delivery, err := h.service.GetDelivery(r.Context(), vars["id"], vars["deliveryID"], claims.UserID())
+	if err != nil {
+		h.writeLookupError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, delivery)
+}
+
+// handleReplayDelivery handles POST /api/v1/webhooks/{id}/deliveries/{deliveryID}/replay
+// @Summary Replay webhook delivery
+// @Description Queues the delivery's event again as a new delivery (replay_of names the original), sent with the X-Webhook-Replay-Of header
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param deliveryID path string true "Delivery ID"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 404 {string} string "Webhook or delivery not found"
+// @Failure 409 {string} string "Delivery is still pending, or the webhook is inactive"
+// @Failure 500 {string} string "Internal server error"
+// @Router /webhooks/{id}/deliveries/{deliveryID}/replay [post]
+func (h *Handler) handleReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+	vars := mux.Vars(r)
+
+	delivery, err := h.service.ReplayDelivery(r.Context(), vars["id"], vars["deliveryID"], claims.UserID())
+	if err != nil {
+		h.writeLookupError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, delivery)
+}
+
+// handleReplayRange handles POST /api/v1/webhooks/{id}/replay
+// @Summary Replay webhook deliveries in a time range
+// @Description Queues again every original delivery created in [from, to) with the given status (failed by default), up to 1000
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param request body models.WebhookReplayRequest true "Time range and status"
+// @Success 202 {object} models.WebhookReplayResponse
+// @Failure 400 {string} string "Invalid replay"
+// @Failure 404 {string} string "Webhook not found"
+// @Failure 409 {string} string "Webhook is inactive"
+// @Failure 500 {string} string "Internal server error"
+// @Router /webhooks/{id}/replay [post]
+func (h *Handler) handleReplayRange(w http.ResponseWriter, r *http.Request) {
+	claims := auth.ClaimsFromContext(r.Context())
+
+	var req models.WebhookReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.service.ReplayRange(r.Context(), mux.Vars(r)["id"], claims.UserID(), &req)
+	if err != nil {
+		h.writeLookupError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, resp)
+}
+
+// writeLookupError maps service and repository errors to 400/404/409/500
+func (h *Handler) writeLookupError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrWebhookNotFound):
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrWebhookDeliveryNotFound):
+		http.Error(w, "Delivery not found", http.StatusNotFound)
+	case errors.Is(err, ErrDeliveryPending), errors.Is(err, ErrWebhookInactive):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, ErrInvalidReplay):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.log.Error("Webhook request failed", zap.Error(err))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// publicEventTypes maps internal domain events to the webhook event names consumers subscribe to
+// Keeps our internal naming free to change without breaking integrations
+var publicEventTypes = map[string]string{
+	events.TypeOrderPlaced:     models.WebhookEventOrderCreated,
+	events.TypePaymentCaptured: models.WebhookEventOrderPaid,
+	events.TypeProductUpdated:  models.WebhookEventProductUpdated,
+}
+
+// WebhookService manages subscriptions and turns domain events into queued deliveries
+type WebhookService struct {
+	repo  *repository.WebhookRepository
+	clock clock.Clock
+	log   *zap.Logger
+}
+
+func NewWebhookService(repo *repository.WebhookRepository, clk clock.Clock, log *zap.Logger) *WebhookService {
+	return &WebhookService{
+		repo:  repo,
+		clock: clk,
+		log:   log,
+	}
+}
+
+// CreateSubscription validates and registers a callback URL for ownerID
+// Returns: subscription and its freshly generated signing secret
+func (s *WebhookService) CreateSubscription(ctx context.Context, ownerID string,
+	req *models.CreateWebhookRequest) (*models.CreateWebhookResponse, error) {
+	if err := validateCallbackURL(req.URL); err != nil {
+		return nil, err
+	}
+	if len(req.EventTypes) == 0 {
+		return nil, errors.New("at least one event type is required")
+	}
+	for _, t := range req.EventTypes {
+		if !isPublicEventType(t) {
+			return nil, fmt.Errorf("unsupported event type: %s", t)
+		}
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &models.WebhookSubscription{
+		OwnerID:    ownerID,
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     secret,
+		Active:     true,
+	}
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Webhook registered", zap.String("id", sub.ID), zap.String("owner_id", ownerID),
+		zap.Strings("event_types", sub.EventTypes))
+
+	return &models.CreateWebhookResponse{WebhookSubscription: sub, Secret: secret}, nil
+}
+
+// ListSubscriptions returns ownerID's subscriptions
+func (s *WebhookService) ListSubscriptions(ctx context.Context, ownerID string) ([]models.WebhookSubscription, error) {
+	return s.repo.ListSubscriptionsByOwner(ctx, ownerID)
+}
+
+// DeleteSubscription removes one of ownerID's subscriptions
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id, ownerID string) error {
+	if err := s.repo.DeleteSubscription(ctx, id, ownerID); err != nil {
+		return err
+	}
+	s.log.Info("Webhook deleted", zap.String("id", id), zap.String("owner_id", ownerID))
+	return nil
+}
+
+// ListDeliveries returns the delivery log of one of ownerID's subscriptions
+func (s *WebhookService) ListDeliveries(ctx context.Context, id, ownerID, status string,
+	limit, offset int) ([]models.WebhookDelivery, error) {
+	// Ownership check - consumers may only read their own logs
+	if _, err := s.repo.GetSubscription(ctx, id, ownerID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, id, status, limit, offset)
+}
+
+// HandleEvent queues a delivery for every active subscription interested in event
+// Subscribed to the event bus; actual HTTP calls happen in the dispatcher
+func (s *WebhookService) HandleEvent(ctx context.Context, event events.Event) error {
+	publicType, ok := publicEventTypes[event.Type]
+	if !ok {
+		return nil
+	}
+
+	subs, err := s.repo.ListActiveSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		ID:        event.ID,
+		Type:      publicType,
+		CreatedAt: event.OccurredAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	var deliveries []models.WebhookDelivery
+	for _, sub := range subs {
+		if !sub.Subscribes(publicType) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      publicType,
+			Payload:        string(body),
+			Status:         models.DeliveryPending,
+			NextAttemptAt:  now,
+		})
+	}
+
+	return s.repo.CreateDeliveries(ctx, deliveries)
+}
+
+// webhookPayload is the JSON body POSTed to consumers
+type webhookPayload struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func isPublicEventType(t string) bool {
+	for _, public := range publicEventTypes {
+		if public == t {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCallbackURL accepts absolute http(s) URLs only
+func validateCallbackURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// newSecret returns a random 32-byte hex signing secret
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
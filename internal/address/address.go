@@ -0,0 +1,56 @@
+package address
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+// ErrUndeliverable is returned when the provider can't locate the address precisely enough to deliver to
+var ErrUndeliverable = errors.New("address could not be verified for delivery")
+
+// Result is a validated address
+type Result struct {
+	Address  models.Address // normalized copy of the input (lines, city, region, postal code, coordinates)
+	Verified bool           // false when no provider is configured
+}
+
+// Validator checks and normalizes customer addresses
+// Called when customers save addresses and again at checkout
+type Validator interface {
+	// Validate returns the normalized address, ErrUndeliverable, or a provider error
+	// Callers should treat provider errors as "unverified" rather than block the customer
+	Validate(ctx context.Context, addr models.Address) (*Result, error)
+}
+
+// NewValidator creates the validator selected by ADDRESS_PROVIDER
+// Returns: error if the provider is unknown or missing its API key
+func NewValidator(cfg config.Address) (Validator, error) {
+	switch cfg.Provider {
+	case "google":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("ADDRESS_API_KEY must be set for ADDRESS_PROVIDER=google")
+		}
+		return NewGoogleValidator(cfg.APIKey), nil
+	case "here":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("ADDRESS_API_KEY must be set for ADDRESS_PROVIDER=here")
+		}
+		return NewHereValidator(cfg.APIKey), nil
+	case "none", "":
+		return NoopValidator{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported ADDRESS_PROVIDER: %s (must be 'none', 'google' or 'here')", cfg.Provider)
+	}
+}
+
+// NoopValidator accepts every address unchanged and unverified
+// Default until a geocoding provider is configured
+type NoopValidator struct{}
+
+func (NoopValidator) Validate(ctx context.Context, addr models.Address) (*Result, error) {
+	return &Result{Address: addr}, nil
+}
@@ -0,0 +1,132 @@
+package address
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+const googleValidateURL = "https://addressvalidation.googleapis.com/v1:validateAddress"
+
+// deliverableGranularity lists Google validation granularities precise enough for a courier
+var deliverableGranularity = map[string]bool{
+	"SUB_PREMISE":       true,
+	"PREMISE":           true,
+	"PREMISE_PROXIMITY": true,
+	"BLOCK":             true,
+	"ROUTE":             true,
+}
+
+// GoogleValidator uses the Google Maps Address Validation API
+type GoogleValidator struct {
+	apiKey string
+	client *http.Client
+}
+
+func NewGoogleValidator(apiKey string) *GoogleValidator {
+	return &GoogleValidator{apiKey: apiKey, client: httpclient.New(10 * time.Second)}
+}
+
+type googlePostalAddress struct {
+	RegionCode         string   `json:"regionCode"`
+	PostalCode         string   `json:"postalCode,omitempty"`
+	AdministrativeArea string   `json:"administrativeArea,omitempty"`
+	Locality           string   `json:"locality,omitempty"`
+	AddressLines       []string `json:"addressLines"`
+}
+
+type googleValidateResponse struct {
+	Result struct {
+		Verdict struct {
+			ValidationGranularity string `json:"validationGranularity"`
+		} `json:"verdict"`
+		Address struct {
+			PostalAddress googlePostalAddress `json:"postalAddress"`
+		} `json:"address"`
+		Geocode struct {
+			Location struct {
+				Latitude  float64 `json:"latitude"`
+				Longitude float64 `json:"longitude"`
+			} `json:"location"`
+		} `json:"geocode"`
+	} `json:"result"`
+}
+
+func (v *GoogleValidator) Validate(ctx context.Context, addr models.Address) (*Result, error) {
+	lines := []string{addr.Line1}
+	if addr.Line2 != "" {
+		lines = append(lines, addr.Line2)
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"address": googlePostalAddress{
+			RegionCode:         addr.Country,
+			PostalCode:         addr.PostalCode,
+			AdministrativeArea: addr.Region,
+			Locality:           addr.City,
+			AddressLines:       lines,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := googleValidateURL + "?key=" + url.QueryEscape(v.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("google address validation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("google address validation: status %d: %s", resp.StatusCode, detail)
+	}
+
+	var out googleValidateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("google address validation: decode: %w", err)
+	}
+	if !deliverableGranularity[out.Result.Verdict.ValidationGranularity] {
+		return nil, ErrUndeliverable
+	}
+
+	normalized := addr
+	postal := out.Result.Address.PostalAddress
+	if len(postal.AddressLines) > 0 {
+		normalized.Line1 = postal.AddressLines[0]
+		normalized.Line2 = strings.Join(postal.AddressLines[1:], ", ")
+	}
+	normalized.City = firstNonEmpty(postal.Locality, addr.City)
+	normalized.Region = firstNonEmpty(postal.AdministrativeArea, addr.Region)
+	normalized.PostalCode = firstNonEmpty(postal.PostalCode, addr.PostalCode)
+	normalized.Country = firstNonEmpty(postal.RegionCode, addr.Country)
+
+	location := out.Result.Geocode.Location
+	normalized.Latitude, normalized.Longitude = &location.Latitude, &location.Longitude
+
+	return &Result{Address: normalized, Verified: true}, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package address
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+const (
+	hereGeocodeURL = "https://geocode.search.hereapi.com/v1/geocode"
+
+	// hereMinScore is the lowest query score accepted as a confident match
+	hereMinScore = 0.8
+)
+
+// HereValidator uses the HERE Geocoding & Search API
+// Good coverage for regions where postal addressing is informal (landmark-based addresses)
+type HereValidator struct {
+	apiKey string
+	client *http.Client
+}
+
+func NewHereValidator(apiKey string) *HereValidator {
+	return &HereValidator{apiKey: apiKey, client: httpclient.New(10 * time.Second)}
+}
+
+type hereGeocodeResponse struct {
+	Items []struct {
+		Address struct {
+			Street      string `json:"street"`
+			HouseNumber string `json:"houseNumber"`
+			District    string `json:"district"`
+			City        string `json:"city"`
+			State       string `json:"state"`
+			County      string `json:"county"`
+			PostalCode  string `json:"postalCode"`
+		} `json:"address"`
+		Position struct {
+			Lat float64 `json:"lat"`
+			Lng float64 `json:"lng"`
+		} `json:"position"`
+		Scoring struct {
+			QueryScore float64 `json:"queryScore"`
+		} `json:"scoring"`
+	} `json:"items"`
+}
+
+func (v *HereValidator) Validate(ctx context.Context, addr models.Address) (*Result, error) {
+	parts := []string{addr.Line1, addr.Line2, addr.City, addr.Region, addr.PostalCode, addr.Country}
+	var query []string
+	for _, part := range parts {
+		if part != "" {
+			query = append(query, part)
+		}
+	}
+
+	params := url.Values{}
+	params.Set("q", strings.Join(query, ", "))
+	params.Set("limit", "1")
+	params.Set("apiKey", v.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hereGeocodeURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("here geocode: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("here geocode: status %d: %s", resp.StatusCode, detail)
+	}
+
+	var out hereGeocodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("here geocode: decode: %w", err)
+	}
+	if len(out.Items) == 0 || out.Items[0].Scoring.QueryScore < hereMinScore {
+		return nil, ErrUndeliverable
+	}
+
+	item := out.Items[0]
+	normalized := addr
+	if item.Address.Street != "" {
+		normalized.Line1 = strings.TrimSpace(item.Address.HouseNumber + " " + item.Address.Street)
+	}
+	normalized.City = firstNonEmpty(item.Address.City, addr.City)
+	normalized.Region = firstNonEmpty(item.Address.State, item.Address.County, addr.Region)
+	normalized.PostalCode = firstNonEmpty(item.Address.PostalCode, addr.PostalCode)
+	normalized.Latitude, normalized.Longitude = &item.Position.Lat, &item.Position.Lng
+
+	return &Result{Address: normalized, Verified: true}, nil
+}
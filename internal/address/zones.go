@@ -0,0 +1,56 @@
+package address
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+// zoneRule matches addresses in Country (and optionally Region/City) to a delivery zone
+type zoneRule struct {
+	zone    string
+	country string // ISO alpha-2 or "*"
+	area    string // region or city; empty matches the whole country
+}
+
+// Zones assigns delivery zones (used for shipping rates and lead times) to addresses
+// Rules come from DELIVERY_ZONES and are evaluated in order; the first match wins
+type Zones struct {
+	rules []zoneRule
+}
+
+// NewZones parses rules of the form zone=CC, zone=CC/Area or zone=*
+// Example: nairobi=KE/Nairobi,kenya=KE,international=*
+func NewZones(specs []string) (*Zones, error) {
+	zones := &Zones{}
+	for _, spec := range specs {
+		name, target, ok := strings.Cut(spec, "=")
+		name, target = strings.TrimSpace(name), strings.TrimSpace(target)
+		if !ok || name == "" || target == "" {
+			return nil, fmt.Errorf("invalid DELIVERY_ZONES entry %q (want zone=CC[/Area])", spec)
+		}
+
+		country, area, _ := strings.Cut(target, "/")
+		zones.rules = append(zones.rules, zoneRule{
+			zone:    name,
+			country: strings.ToUpper(strings.TrimSpace(country)),
+			area:    strings.TrimSpace(area),
+		})
+	}
+	return zones, nil
+}
+
+// Resolve returns the delivery zone of addr, or "" if no rule matches (not deliverable)
+func (z *Zones) Resolve(addr models.Address) string {
+	for _, rule := range z.rules {
+		if rule.country != "*" && rule.country != strings.ToUpper(addr.Country) {
+			continue
+		}
+		if rule.area != "" && !strings.EqualFold(rule.area, addr.Region) && !strings.EqualFold(rule.area, addr.City) {
+			continue
+		}
+		return rule.zone
+	}
+	return ""
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/gorilla/mux"
+)
+
+type contextKey struct{}
+
+// ClaimsFromContext returns the authenticated caller's claims
+// Returns: nil if the request did not pass through Authenticate
+func ClaimsFromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(contextKey{}).(*Claims)
+	return claims
+}
+
+// WithClaims stores claims in ctx (used by Authenticate and tests)
+// The caller is also stored as an httpctx.User, for code that only needs to know who is calling
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	ctx = httpctx.WithUser(ctx, httpctx.User{
+		ID:           claims.UserID(),
+		Email:        claims.Email,
+		Role:         claims.Role,
+		Impersonator: claims.Impersonator,
+		Scopes:       claims.Scopes(),
+	})
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// Authenticate requires a valid "Authorization: Bearer <token>" header
+// Verified claims are stored in the request context for handlers (see ClaimsFromContext)
+// Scoped tokens are only accepted on routes that declare a scope they grant, see RequireScope
+// Impersonation tokens are read-only, see AllowImpersonation
+func Authenticate(tokens *TokenManager) mux.MiddlewareFunc {
+	return authenticate(tokens, false, false)
+}
+
+// Identify is Authenticate for public routes that answer signed-in callers differently,
+// e.g. with their customer segment's prices
+// Requests without a token pass through anonymously; invalid tokens are still refused
+func Identify(tokens *TokenManager) mux.MiddlewareFunc {
+	return authenticate(tokens, false, true)
+}
+
+// AuthenticateStream is Authenticate that also accepts an ?access_token= query parameter
+// Browsers can't set headers on EventSource or WebSocket connections
+// Only use it on streaming routes - query strings end up in access logs
+func AuthenticateStream(tokens *TokenManager) mux.MiddlewareFunc {
+	return authenticate(tokens, true, false)
+}
+
+func authenticate(tokens *TokenManager, allowQuery, optional bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok {
+				token = ""
+				if allowQuery {
+					token = r.URL.Query().Get("access_token")
+				}
+			}
+			if token == "" {
+				if claims := tokens.devIdentity(r.Header.Get("X-Dev-Role")); claims != nil {
+					next.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), claims)))
+					return
+				}
+				if optional {
+					next.ServeHTTP(w, r)
+					return
+				}
+				http.Error(w, "Missing bearer token", http.StatusUnauthorized)
+				return
+			}
+
+			claims, err := tokens.Verify(r.Context(), token)
+			if errors.Is(err, ErrInvalidToken) {
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+			if err != nil {
+				http.Error(w, "Cannot check token, retry shortly", http.StatusServiceUnavailable)
+				return
+			}
+			if ok, required := allowScopes(r.Context(), claims); !ok {
+				writeInsufficientScope(w, required)
+				return
+			}
+			// Refused writes are audited too: they show what the session tried to do
+			if claims.Impersonator != "" && tokens.onImpersonated != nil {
+				tokens.onImpersonated(r, claims)
+			}
+			if !allowImpersonation(r, claims) {
+				http.Error(w, "Impersonation tokens are read-only", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), claims)))
+		})
+	}
+}
+
+// Tenant stores the tenant the caller's token was issued for (Claims.Tenant) on the context, see
+// httpctx.TenantFromContext. It runs on every request ahead of the routes' own authentication, so
+// quotas and feature flags know the tenant: requests without a valid token act for the default
+// tenant, and Authenticate still refuses invalid tokens on routes that need one.
+// The X-Tenant-ID header is anyone's to set, so it is only honored for token-less requests in
+// `serve --dev`, like X-Dev-Role (see httpctx.Tenant).
+func Tenant(tokens *TokenManager) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		devTenant := httpctx.Tenant(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				if tokens.devIdentities != nil {
+					devTenant.ServeHTTP(w, r)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			if claims, err := tokens.Verify(r.Context(), token); err == nil && claims.Tenant != "" {
+				r = r.WithContext(httpctx.WithTenant(r.Context(), claims.Tenant))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRole allows the request only if the caller has one of roles
+// Must run after Authenticate
+func RequireRole(roles ...string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := ClaimsFromContext(r.Context())
+			if claims == nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			for _, role := range roles {
+				if claims.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		})
+	}
+}
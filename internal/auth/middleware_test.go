@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+func TestTenant(t *testing.T) {
+	tokens := newTestTokens()
+	signIn, _, err := tokens.Issue(benchUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acme, _, err := tokens.IssueScoped(benchUser, []string{"read:orders"}, "acme", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		header string // X-Tenant-ID
+		dev    bool   // serve --dev
+		want   string
+	}{
+		{"anonymous", "", "", false, ""},
+		{"anonymous header ignored", "", "acme", false, ""},
+		{"sign-in token", signIn, "", false, ""},
+		{"sign-in token header ignored", signIn, "acme", false, ""},
+		{"tenant token", acme, "", false, "acme"},
+		{"tenant token header ignored", acme, "globex", false, "acme"},
+		{"invalid token header ignored", "not-a-token", "acme", false, ""},
+		{"dev header", "", "acme", true, "acme"},
+		{"dev tenant token wins", acme, "globex", true, "acme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := *tokens
+			if tt.dev {
+				m.EnableDevIdentities(map[string]*Claims{models.RoleCustomer: {Email: "dev@example.com", Role: models.RoleCustomer}})
+			}
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.header != "" {
+				r.Header.Set(httpctx.TenantHeader, tt.header)
+			}
+
+			got := "unset"
+			rec := httptest.NewRecorder()
+			Tenant(&m)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = httpctx.TenantFromContext(r.Context())
+			})).ServeHTTP(rec, r)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200 (routes refuse bad tokens, not Tenant)", rec.Code)
+			}
+			if got != tt.want {
+				t.Fatalf("tenant = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImpersonationReadOnly(t *testing.T) {
+	tokens := newTestTokens()
+	signIn, _, err := tokens.Issue(benchUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	impersonation, _, err := tokens.IssueImpersonation(benchUser, "admin-id", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		allow  bool // route declares AllowImpersonation
+		want   int
+	}{
+		{"impersonation GET", impersonation, "GET", false, http.StatusOK},
+		{"impersonation HEAD", impersonation, "HEAD", false, http.StatusOK},
+		{"impersonation POST", impersonation, "POST", false, http.StatusForbidden},
+		{"impersonation PUT", impersonation, "PUT", false, http.StatusForbidden},
+		{"impersonation PATCH", impersonation, "PATCH", false, http.StatusForbidden},
+		{"impersonation DELETE", impersonation, "DELETE", false, http.StatusForbidden},
+		{"impersonation POST allowed", impersonation, "POST", true, http.StatusOK},
+		{"sign-in POST", signIn, "POST", false, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audited := 0
+			m := *tokens
+			m.OnImpersonatedRequest(func(*http.Request, *Claims) { audited++ })
+
+			handler := Authenticate(&m)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			if tt.allow {
+				handler = AllowImpersonation(handler)
+			}
+			r := httptest.NewRequest(tt.method, "/", nil)
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if wantAudited := tt.token == impersonation; (audited == 1) != wantAudited {
+				t.Fatalf("audited %d times, want audited %v (refused writes included)", audited, wantAudited)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidToken is returned for malformed, expired, revoked or wrongly-signed tokens
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrNotRevocable is returned by Revoke for tokens without an ID (jti), e.g. dev identities
+	ErrNotRevocable = errors.New("token cannot be revoked")
+)
+
+// Claims is the payload carried by access tokens
+// Subject (sub) holds the user ID; ID (jti) names the token for revocation
+type Claims struct {
+	Email    string `json:"email"`
+	Username string `json:"username,omitempty"` // empty for accounts without a username
+	Role     string `json:"role"`
+
+	// Impersonator is the admin acting as the subject (support impersonation); empty otherwise
+	Impersonator string `json:"imp,omitempty"`
+
+	// Scope limits the token to these space-separated scopes (see RequireScope); empty for sign-in tokens
+	Scope string `json:"scope,omitempty"`
+
+	// Tenant is the tenant the token acts for (see Tenant); empty for the default tenant
+	Tenant string `json:"tid,omitempty"`
+
+	jwt.RegisteredClaims
+}
+
+// UserID returns the authenticated user's ID
+func (c *Claims) UserID() string {
+	return c.Subject
+}
+
+// TokenManager issues and verifies HS256-signed JWT access tokens
+// Secret comes from JWT_SECRET so tokens stay valid across instances and restarts
+type TokenManager struct {
+	secret []byte
+	issuer string
+	ttl    time.Duration
+	clock  clock.Clock // drives issued-at, expiry and expiry checks
+
+	// onImpersonated audits requests made with impersonation tokens
+	onImpersonated func(r *http.Request, claims *Claims)
+
+	// devIdentities maps a role to the seeded user that token-less requests act as (serve --dev only)
+	devIdentities map[string]*Claims
+
+	// revoked holds the IDs of revoked tokens until they expire; nil disables revocation
+	revoked cache.Cache
+}
+
+func NewTokenManager(cfg config.Auth, clk clock.Clock) *TokenManager {
+	return &TokenManager{
+		secret: []byte(cfg.JWTSecret),
+		issuer: cfg.Issuer,
+		ttl:    cfg.TokenTTL,
+		clock:  clk,
+	}
+}
+
+// Issue creates a signed token for the given user
+// Returns: token string and its expiry time
+func (m *TokenManager) Issue(user *models.User) (string, time.Time, error) {
+	return m.issue(userClaims(user), user.ID, m.ttl)
+}
+
+// IssueImpersonation creates a token acting as the given user on behalf of impersonatorID
+// ttl should be short; the token carries the user's role, never the admin's
+func (m *TokenManager) IssueImpersonation(user *models.User, impersonatorID string, ttl time.Duration) (string, time.Time, error) {
+	claims := userClaims(user)
+	claims.Impersonator = impersonatorID
+	return m.issue(claims, user.ID, ttl)
+}
+
+// IssueScoped creates a token for the given user limited to scopes, for API integrations
+// The token carries the user's role too: it never grants more than the user has
+// tenant ("" for the default tenant) is who the token's requests count for; callers check who may pick one
+func (m *TokenManager) IssueScoped(user *models.User, scopes []string, tenant string, ttl time.Duration) (string, time.Time, error) {
+	if len(scopes) == 0 {
+		return "", time.Time{}, fmt.Errorf("%w: at least one scope is required", ErrUnknownScope)
+	}
+	claims := userClaims(user)
+	claims.Scope = strings.Join(scopes, " ")
+	claims.Tenant = tenant
+	return m.issue(claims, user.ID, ttl)
+}
+
+func userClaims(user *models.User) *Claims {
+	claims := &Claims{Email: user.Email, Role: user.Role}
+	if user.Username != nil {
+		claims.Username = *user.Username
+	}
+	return claims
+}
+
+func (m *TokenManager) issue(claims *Claims, userID string, ttl time.Duration) (string, time.Time, error) {
+	now := m.clock.Now()
+	expiresAt := now.Add(ttl)
+
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Subject:   userID,
+		Issuer:    m.issuer,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("sign token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// Verify parses token and checks signature, issuer, expiry and revocation
+// Returns: claims if valid, ErrInvalidToken otherwise; other errors mean revocation couldn't be checked
+func (m *TokenManager) Verify(ctx context.Context, token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return m.secret, nil
+	},
+		// Pin the algorithm so a token can't downgrade to "none" or switch to RS256
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(m.clock.Now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	// Tokens issued before jti was added can't be revoked; they run out within the TTL
+	if m.revoked != nil && claims.ID != "" {
+		_, err := m.revoked.Get(ctx, revokedKey(claims.ID))
+		switch {
+		case err == nil:
+			return nil, fmt.Errorf("%w: token revoked", ErrInvalidToken)
+		case !errors.Is(err, cache.ErrCacheMiss):
+			return nil, fmt.Errorf("check token revocation: %w", err)
+		}
+	}
+	return claims, nil
+}
+
+// Revoke makes the token claims were verified from invalid for the rest of its lifetime
+// The token ID is kept in the revocation store until the token would have expired anyway
+func (m *TokenManager) Revoke(ctx context.Context, claims *Claims) error {
+	if m.revoked == nil {
+		return errors.New("token revocation is not enabled")
+	}
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return ErrNotRevocable
+	}
+	ttl := claims.ExpiresAt.Sub(m.clock.Now())
+	if ttl <= 0 {
+		return nil
+	}
+	return m.revoked.Set(ctx, revokedKey(claims.ID), []byte("1"), ttl)
+}
+
+// UseRevocations keeps revoked token IDs in store, shared by every instance so a token
+// revoked on one is refused by all
+func (m *TokenManager) UseRevocations(store cache.Cache) {
+	m.revoked = store
+}
+
+func revokedKey(tokenID string) string {
+	return "auth:revoked:" + tokenID
+}
+
+// OnImpersonatedRequest registers hook, called by Authenticate for every request made with an
+// impersonation token before the handler runs
+func (m *TokenManager) OnImpersonatedRequest(hook func(r *http.Request, claims *Claims)) {
+	m.onImpersonated = hook
+}
+
+// EnableDevIdentities relaxes authentication for local development: requests without a
+// bearer token act as the seeded user for the role in X-Dev-Role (default customer).
+// Only `serve --dev` calls this; production servers always require a token.
+func (m *TokenManager) EnableDevIdentities(identities map[string]*Claims) {
+	m.devIdentities = identities
+}
+
+// devIdentity returns the dev-mode caller for role, or nil when dev identities are disabled
+func (m *TokenManager) devIdentity(role string) *Claims {
+	if m.devIdentities == nil {
+		return nil
+	}
+	if role == "" {
+		role = models.RoleCustomer
+	}
+	return m.devIdentities[role]
+}
@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+// benchUser is the subject of the token benchmarks and tests
+var benchUser = &models.User{ID: "user-id", Email: "bench@example.com", Role: models.RoleCustomer}
+
+func newTestTokens() *TokenManager {
+	return NewTokenManager(config.Auth{JWTSecret: "bench-secret", Issuer: "ecomgo-bench", TokenTTL: time.Hour}, clock.System)
+}
+
+func BenchmarkIssue(b *testing.B) {
+	tokens := newTestTokens()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := tokens.Issue(benchUser); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkVerify is the cost every authenticated request pays
+func BenchmarkVerify(b *testing.B) {
+	tokens := newTestTokens()
+	token, _, err := tokens.Issue(benchUser)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := tokens.Verify(context.Background(), token); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// unreachableCache is a revocation store that can't be read, like Redis during an outage
+type unreachableCache struct{ cache.Cache }
+
+func (unreachableCache) Get(context.Context, string) ([]byte, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	tokens := NewTokenManager(config.Auth{JWTSecret: "test-secret", Issuer: "ecomgo-test", TokenTTL: time.Hour}, clk)
+	tokens.UseRevocations(cache.NewMemoryCache("test"))
+
+	issue := func() (string, *Claims) {
+		t.Helper()
+		token, _, err := tokens.Issue(benchUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+		claims, err := tokens.Verify(ctx, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token, claims
+	}
+
+	t.Run("revoked token refused", func(t *testing.T) {
+		token, claims := issue()
+		if err := tokens.Revoke(ctx, claims); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tokens.Verify(ctx, token); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Verify(revoked) = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("other tokens of the user stay valid", func(t *testing.T) {
+		_, claims := issue()
+		other, otherClaims := issue()
+		if claims.ID == otherClaims.ID {
+			t.Fatalf("two tokens share the ID %q", claims.ID)
+		}
+		if err := tokens.Revoke(ctx, claims); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tokens.Verify(ctx, other); err != nil {
+			t.Fatalf("Verify(other token) = %v", err)
+		}
+	})
+
+	t.Run("token without an ID", func(t *testing.T) {
+		if err := tokens.Revoke(ctx, &Claims{Email: "dev@example.com"}); !errors.Is(err, ErrNotRevocable) {
+			t.Fatalf("Revoke(dev identity) = %v, want ErrNotRevocable", err)
+		}
+	})
+
+	t.Run("revocation disabled", func(t *testing.T) {
+		plain := NewTokenManager(config.Auth{JWTSecret: "test-secret", Issuer: "ecomgo-test", TokenTTL: time.Hour}, clk)
+		_, claims := issue()
+		if err := plain.Revoke(ctx, claims); err == nil {
+			t.Fatal("Revoke without a revocation store succeeded")
+		}
+	})
+
+	t.Run("store unreachable", func(t *testing.T) {
+		m := *tokens
+		m.UseRevocations(unreachableCache{})
+		token, _ := issue()
+		_, err := m.Verify(ctx, token)
+		if err == nil || errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Verify with the store down = %v, want an error other than ErrInvalidToken", err)
+		}
+
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		Authenticate(&m)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			t.Fatal("handler ran without a revocation check")
+		})).ServeHTTP(rec, r)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("status = %d, want 503", rec.Code)
+		}
+	})
+}
+
+func TestTokenExpiry(t *testing.T) {
+	ctx := context.Background()
+	issued := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		ttl   time.Duration
+		issue func(m *TokenManager) (string, time.Time, error)
+	}{
+		{"session", time.Hour, func(m *TokenManager) (string, time.Time, error) {
+			return m.Issue(benchUser)
+		}},
+		{"impersonation", 15 * time.Minute, func(m *TokenManager) (string, time.Time, error) {
+			return m.IssueImpersonation(benchUser, "admin-id", 15*time.Minute)
+		}},
+		{"scoped", 24 * time.Hour, func(m *TokenManager) (string, time.Time, error) {
+			return m.IssueScoped(benchUser, []string{"orders:read"}, "", 24*time.Hour)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFake(issued)
+			tokens := NewTokenManager(config.Auth{JWTSecret: "test-secret", Issuer: "ecomgo-test", TokenTTL: time.Hour}, clk)
+			token, expiresAt, err := tt.issue(tokens)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !expiresAt.Equal(issued.Add(tt.ttl)) {
+				t.Fatalf("expires at %v, want %v", expiresAt, issued.Add(tt.ttl))
+			}
+
+			clk.Set(expiresAt.Add(-time.Second))
+			if _, err := tokens.Verify(ctx, token); err != nil {
+				t.Fatalf("Verify a second before expiry = %v", err)
+			}
+			clk.Set(expiresAt)
+			if _, err := tokens.Verify(ctx, token); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("Verify at expiry = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+// TestRevocationExpires checks that a revoked token is only remembered while it could still be
+// used, so the revocation store doesn't grow without bound
+func TestRevocationExpires(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := cache.NewMemoryCache("test")
+	store.UseClock(clk)
+	tokens := NewTokenManager(config.Auth{JWTSecret: "test-secret", Issuer: "ecomgo-test", TokenTTL: time.Hour}, clk)
+	tokens.UseRevocations(store)
+
+	token, _, err := tokens.Issue(benchUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := tokens.Verify(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk.Advance(40 * time.Minute)
+	if err := tokens.Revoke(ctx, claims); err != nil {
+		t.Fatal(err)
+	}
+
+	clk.Advance(20*time.Minute - time.Second)
+	if _, err := store.Get(ctx, revokedKey(claims.ID)); err != nil {
+		t.Fatalf("revocation before the token expired = %v", err)
+	}
+	clk.Advance(2 * time.Second)
+	if _, err := store.Get(ctx, revokedKey(claims.ID)); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Fatalf("revocation after the token expired = %v, want ErrCacheMiss", err)
+	}
+
+	// Revoking an expired token has nothing to remember
+	if err := tokens.Revoke(ctx, claims); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, revokedKey(claims.ID)); !errors.Is(err, cache.ErrCacheMiss) {
+		t.Fatalf("revocation of an expired token = %v, want ErrCacheMiss", err)
+	}
+}
@@ -0,0 +1,148 @@
+// Package avatar turns uploaded profile pictures into square JPEGs at standard sizes
+// and draws identicons for users without one. Only the standard library is used:
+// re-encoding drops every metadata segment (EXIF, GPS, XMP), after the EXIF
+// orientation has been applied to the pixels.
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // register decoders for image.Decode
+	"image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+// Sizes are the square edge lengths, in pixels, every avatar is stored at
+var Sizes = []int{64, 128, 256, 512}
+
+// DefaultSize is served when a request doesn't ask for a size
+const DefaultSize = 128
+
+// maxPixels bounds decoded images (about 40 megapixels) so a small, highly compressed
+// upload can't expand into gigabytes of memory
+const maxPixels = 40_000_000
+
+var (
+	ErrUnsupportedFormat = errors.New("avatar must be a JPEG, PNG or GIF image")
+	ErrTooLarge          = errors.New("avatar dimensions are too large")
+	ErrFileTooLarge      = errors.New("avatar file is too large")
+)
+
+// Process decodes an uploaded image and renders it at every size in Sizes
+// The image is turned upright per its EXIF orientation, center-cropped to a square
+// and flattened onto white (JPEG has no transparency)
+// Returns: JPEG bytes keyed by size
+func Process(data []byte) (map[int][]byte, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode %s avatar: %w", format, err)
+	}
+
+	square := cropSquare(img)
+	if format == "jpeg" {
+		square = orient(square, exifOrientation(data))
+	}
+	out := make(map[int][]byte, len(Sizes))
+	for _, size := range Sizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resize(square, size), &jpeg.Options{Quality: 85}); err != nil {
+			return nil, fmt.Errorf("encode %dpx avatar: %w", size, err)
+		}
+		out[size] = buf.Bytes()
+	}
+	return out, nil
+}
+
+// ReadLimited reads at most max bytes from r
+// Returns: ErrFileTooLarge when r holds more
+func ReadLimited(r io.Reader, max int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, ErrFileTooLarge
+	}
+	return data, nil
+}
+
+// SizeFor returns the stored size closest to requested, rounding up
+func SizeFor(requested int) int {
+	for _, size := range Sizes {
+		if requested <= size {
+			return size
+		}
+	}
+	return Sizes[len(Sizes)-1]
+}
+
+// cropSquare copies the centered square of img onto a white RGBA canvas
+func cropSquare(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	edge := b.Dx()
+	if b.Dy() < edge {
+		edge = b.Dy()
+	}
+	origin := image.Pt(b.Min.X+(b.Dx()-edge)/2, b.Min.Y+(b.Dy()-edge)/2)
+
+	square := image.NewRGBA(image.Rect(0, 0, edge, edge))
+	draw.Draw(square, square.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(square, square.Bounds(), img, origin, draw.Over)
+	return square
+}
+
+// resize scales a square image to size x size by averaging the source pixels each
+// destination pixel covers (box filter); enlarging repeats pixels
+func resize(src *image.RGBA, size int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	n := src.Bounds().Dx()
+
+	for y := 0; y < size; y++ {
+		y0, y1 := span(y, size, n)
+		for x := 0; x < size; x++ {
+			x0, x1 := span(x, size, n)
+
+			var r, g, b, a, count uint32
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					b += uint32(p[2])
+					a += uint32(p[3])
+					count++
+				}
+			}
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / count)
+			dst.Pix[i+1] = uint8(g / count)
+			dst.Pix[i+2] = uint8(b / count)
+			dst.Pix[i+3] = uint8(a / count)
+		}
+	}
+	return dst
+}
+
+// span maps destination pixel i of size onto the source range [start, end) of n pixels
+func span(i, size, n int) (int, int) {
+	start := i * n / size
+	end := (i + 1) * n / size
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
@@ -0,0 +1,86 @@
+package avatar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+)
+
+// identiconGrid is the number of cells per side; the left half is mirrored onto the right
+const identiconGrid = 5
+
+// Identicon draws the default avatar for seed (the user ID) as a size x size PNG
+// The same seed always gives the same picture, so it's stable until a photo is uploaded
+func Identicon(seed string, size int) ([]byte, error) {
+	sum := sha256.Sum256([]byte(seed))
+
+	// Hue from the hash, fixed saturation/lightness so every identicon is readable on white
+	fg := hsl(float64(uint16(sum[0])<<8|uint16(sum[1]))/65536*360, 0.55, 0.55)
+	bg := color.RGBA{0xF2, 0xF2, 0xF2, 0xFF}
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	// A margin of half a cell on every side
+	cell := size / (identiconGrid + 1)
+	margin := (size - cell*identiconGrid) / 2
+	bit := 16 // bits 0-15 picked the colour
+	for col := 0; col < (identiconGrid+1)/2; col++ {
+		for row := 0; row < identiconGrid; row++ {
+			on := sum[bit/8]>>(bit%8)&1 == 1
+			bit++
+			if !on {
+				continue
+			}
+			for _, c := range []int{col, identiconGrid - 1 - col} {
+				r := image.Rect(margin+c*cell, margin+row*cell, margin+(c+1)*cell, margin+(row+1)*cell)
+				draw.Draw(img, r, image.NewUniform(fg), image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// hsl converts hue (degrees), saturation and lightness (0-1) to RGB
+func hsl(h, s, l float64) color.RGBA {
+	c := (1 - abs(2*l-1)) * s
+	hp := h / 60
+	x := c * (1 - abs(mod2(hp)-1))
+	var r, g, b float64
+	switch {
+	case hp < 1:
+		r, g = c, x
+	case hp < 2:
+		r, g = x, c
+	case hp < 3:
+		g, b = c, x
+	case hp < 4:
+		g, b = x, c
+	case hp < 5:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+	m := l - c/2
+	return color.RGBA{uint8((r + m) * 255), uint8((g + m) * 255), uint8((b + m) * 255), 0xFF}
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// mod2 is v modulo 2 for non-negative v
+func mod2(v float64) float64 {
+	return v - 2*float64(int(v/2))
+}
@@ -0,0 +1,176 @@
+// Package batchwriter moves fire-and-forget inserts (audit trails, analytics) off the request path
+// Records go into a bounded buffer and a background flusher inserts them in batches. When the
+// buffer is full new records are dropped and counted rather than making requests wait; on shutdown
+// the flusher drains whatever is buffered before returning.
+package batchwriter
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Stats are a writer's counters since startup
+type Stats struct {
+	Name     string `json:"name"`
+	Buffered int    `json:"buffered"` // records waiting to be flushed
+	Written  int64  `json:"written"`
+	Dropped  int64  `json:"dropped"` // buffer was full
+	Failed   int64  `json:"failed"`  // batch insert failed
+}
+
+// statsSource is implemented by every Writer, whatever its record type
+type statsSource interface {
+	Stats() Stats
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []statsSource
+)
+
+// AllStats returns the counters of every writer created in this process, sorted by name
+func AllStats() []Stats {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	stats := make([]Stats, 0, len(registry))
+	for _, w := range registry {
+		stats = append(stats, w.Stats())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// Writer buffers records of one table and inserts them in batches
+// It is a module.Service: return it from the owning module's Services so it runs and drains
+type Writer[T any] struct {
+	db       *gorm.DB
+	name     string
+	buffer   chan T
+	batch    int
+	interval time.Duration
+	drain    time.Duration
+	log      *zap.Logger
+
+	written atomic.Int64
+	dropped atomic.Int64
+	failed  atomic.Int64
+}
+
+func NewWriter[T any](db *gorm.DB, name string, cfg config.AsyncWrites, log *zap.Logger) *Writer[T] {
+	w := &Writer[T]{
+		db:       db,
+		name:     name,
+		buffer:   make(chan T, cfg.BufferSize),
+		batch:    cfg.BatchSize,
+		interval: cfg.FlushInterval,
+		drain:    cfg.DrainTimeout,
+		log:      log,
+	}
+
+	registryMu.Lock()
+	registry = append(registry, w)
+	registryMu.Unlock()
+	return w
+}
+
+// Write queues record for insertion without blocking
+// Returns: false if the buffer is full and the record was dropped
+func (w *Writer[T]) Write(record T) bool {
+	select {
+	case w.buffer <- record:
+		return true
+	default:
+		w.dropped.Add(1)
+		return false
+	}
+}
+
+func (w *Writer[T]) Stats() Stats {
+	return Stats{
+		Name:     w.name,
+		Buffered: len(w.buffer),
+		Written:  w.written.Load(),
+		Dropped:  w.dropped.Load(),
+		Failed:   w.failed.Load(),
+	}
+}
+
+func (w *Writer[T]) Name() string {
+	return "batch-writer:" + w.name
+}
+
+// Run flushes a batch whenever it is full or ASYNC_WRITE_FLUSH_INTERVAL has passed
+// When ctx is cancelled the remaining buffer is flushed within ASYNC_WRITE_DRAIN_TIMEOUT
+func (w *Writer[T]) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	// An insert already running when shutdown starts is allowed to finish
+	flushCtx := context.WithoutCancel(ctx)
+	pending := make([]T, 0, w.batch)
+	var reportedDrops int64
+	for {
+		select {
+		case record := <-w.buffer:
+			pending = append(pending, record)
+			if len(pending) >= w.batch {
+				pending = w.flush(flushCtx, pending)
+			}
+		case <-ticker.C:
+			pending = w.flush(flushCtx, pending)
+			if dropped := w.dropped.Load(); dropped > reportedDrops {
+				w.log.Warn("Async write buffer overflowed, records dropped", zap.String("writer", w.name),
+					zap.Int64("dropped", dropped-reportedDrops), zap.Int("buffer_size", cap(w.buffer)))
+				reportedDrops = dropped
+			}
+		case <-ctx.Done():
+			return w.drainBuffer(pending)
+		}
+	}
+}
+
+// drainBuffer writes everything still buffered, giving up after the drain timeout
+// Runs after the HTTP server has stopped, so nothing is being added anymore
+func (w *Writer[T]) drainBuffer(pending []T) error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.drain)
+	defer cancel()
+
+	for len(w.buffer) > 0 && ctx.Err() == nil {
+		pending = append(pending, <-w.buffer)
+		if len(pending) >= w.batch {
+			pending = w.flush(ctx, pending)
+		}
+	}
+	w.flush(ctx, pending)
+
+	stats := w.Stats()
+	w.log.Info("Async writer drained", zap.String("writer", w.name), zap.Int64("written", stats.Written),
+		zap.Int64("dropped", stats.Dropped), zap.Int64("failed", stats.Failed))
+	return nil
+}
+
+// flush inserts pending in one statement and returns the emptied slice for reuse
+// A failed batch is logged and counted, not retried, so a database outage can't back up requests
+func (w *Writer[T]) flush(ctx context.Context, pending []T) []T {
+	if len(pending) == 0 {
+		return pending
+	}
+
+	if err := w.db.WithContext(ctx).CreateInBatches(pending, w.batch).Error; err != nil {
+		w.failed.Add(int64(len(pending)))
+		w.log.Error("Async batch insert failed", zap.String("writer", w.name), zap.Int("records", len(pending)), zap.Error(err))
+	} else {
+		w.written.Add(int64(len(pending)))
+	}
+
+	clear(pending)
+	return pending[:0]
+}
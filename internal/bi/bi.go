@@ -0,0 +1,117 @@
+// Package bi encodes incremental table snapshots for data warehouses and BI tools
+// Files are gzipped NDJSON or Parquet, written batch by batch to an io.Writer (the exporter
+// spools them to a temporary file before uploading to object storage). Columns are declared
+// once per table and drive both formats, so the two always carry the same fields.
+package bi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Formats
+const (
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+// ErrUnsupportedFormat is returned for a format other than ndjson or parquet
+var ErrUnsupportedFormat = errors.New("format must be ndjson or parquet")
+
+// Type is a column's value type
+type Type int
+
+// Column types and the Go values Column.Value returns for them
+const (
+	String    Type = iota // string
+	Int64                 // int64
+	Bool                  // bool
+	Timestamp             // time.Time, written in UTC with millisecond precision
+)
+
+// Column is one field of an exported row
+// Value returns nil for a null; only Optional columns may be null
+type Column[T any] struct {
+	Name     string
+	Type     Type
+	Optional bool
+	Value    func(T) any
+}
+
+// Encoder writes rows of T to a file in one format
+type Encoder[T any] interface {
+	// Write appends rows to the file
+	Write(rows []T) error
+
+	// Close finishes the file (gzip trailer, Parquet footer); it doesn't close the underlying writer
+	Close() error
+}
+
+// ParseFormat validates BI_EXPORT_FORMAT; empty means Parquet
+func ParseFormat(format string) (string, error) {
+	switch format {
+	case "", FormatParquet:
+		return FormatParquet, nil
+	case FormatNDJSON:
+		return FormatNDJSON, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// NewEncoder returns an encoder writing rows of T to w in format
+func NewEncoder[T any](format string, w io.Writer, columns []Column[T]) (Encoder[T], error) {
+	switch format {
+	case FormatNDJSON:
+		return newNDJSONEncoder(w, columns), nil
+	case FormatParquet:
+		return newParquetEncoder(w, columns), nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// Extension is the file name extension of format, e.g. "parquet"
+func Extension(format string) string {
+	if format == FormatNDJSON {
+		return "ndjson.gz"
+	}
+	return "parquet"
+}
+
+// ContentType is the media type objects in format are stored with
+func ContentType(format string) string {
+	if format == FormatNDJSON {
+		return "application/gzip"
+	}
+	return "application/vnd.apache.parquet"
+}
+
+// value returns column's value in row, checked against its type
+func value[T any](column Column[T], row T) (any, error) {
+	v := column.Value(row)
+	if v == nil {
+		if !column.Optional {
+			return nil, fmt.Errorf("bi: column %s is required but null", column.Name)
+		}
+		return nil, nil
+	}
+	var ok bool
+	switch column.Type {
+	case String:
+		_, ok = v.(string)
+	case Int64:
+		_, ok = v.(int64)
+	case Bool:
+		_, ok = v.(bool)
+	case Timestamp:
+		var t time.Time
+		if t, ok = v.(time.Time); ok {
+			v = t.UTC().Truncate(time.Millisecond)
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("bi: column %s has a %T value", column.Name, v)
+	}
+	return v, nil
+}
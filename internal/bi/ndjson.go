@@ -0,0 +1,63 @@
+package bi
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// ndjsonEncoder writes one JSON object per row, fields in column order, gzip-compressed
+// Timestamps are RFC 3339 strings in UTC
+type ndjsonEncoder[T any] struct {
+	columns []Column[T]
+	gz      *gzip.Writer
+	buf     *bufio.Writer
+	names   [][]byte // JSON-encoded column names, with their colon
+}
+
+func newNDJSONEncoder[T any](w io.Writer, columns []Column[T]) *ndjsonEncoder[T] {
+	gz := gzip.NewWriter(w)
+	names := make([][]byte, len(columns))
+	for i, column := range columns {
+		name, _ := json.Marshal(column.Name)
+		names[i] = append(name, ':')
+	}
+	return &ndjsonEncoder[T]{columns: columns, gz: gz, buf: bufio.NewWriter(gz), names: names}
+}
+
+func (e *ndjsonEncoder[T]) Write(rows []T) error {
+	for _, row := range rows {
+		e.buf.WriteByte('{')
+		for i, column := range e.columns {
+			v, err := value(column, row)
+			if err != nil {
+				return err
+			}
+			if t, ok := v.(time.Time); ok {
+				v = t.Format(time.RFC3339Nano)
+			}
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			e.buf.Write(e.names[i])
+			e.buf.Write(encoded)
+		}
+		if _, err := e.buf.WriteString("}\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *ndjsonEncoder[T]) Close() error {
+	if err := e.buf.Flush(); err != nil {
+		return err
+	}
+	return e.gz.Close()
+}
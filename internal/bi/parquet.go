@@ -0,0 +1,393 @@
+package bi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// rowGroupSize is how many rows are buffered per Parquet row group; each row group holds one
+// gzip-compressed page per column, so it bounds the encoder's memory
+const rowGroupSize = 50000
+
+// parquetMagic opens and closes every Parquet file
+const parquetMagic = "PAR1"
+
+// Parquet enum values used here (see parquet.thrift in apache/parquet-format)
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetUTF8            = 0 // ConvertedType
+	parquetTimestampMillis = 9 // ConvertedType
+
+	parquetPlain = 0 // Encoding
+	parquetRLE   = 3 // Encoding
+
+	parquetGzip     = 2 // CompressionCodec
+	parquetDataPage = 0 // PageType
+)
+
+// parquetEncoder writes a flat Parquet file: one row group per rowGroupSize rows, one PLAIN
+// data page (v1) per column chunk. Optional columns carry RLE definition levels; strings are
+// UTF8 byte arrays and timestamps INT64 milliseconds since the epoch (TIMESTAMP_MILLIS)
+type parquetEncoder[T any] struct {
+	w       *countingWriter
+	columns []Column[T]
+	chunks  []*columnBuffer
+	rows    int
+	groups  []rowGroupMeta
+	total   int64
+	err     error
+}
+
+// columnBuffer holds one column's values for the row group being built
+type columnBuffer struct {
+	defined []bool // per row, optional columns only
+	values  bytes.Buffer
+	bools   []bool
+}
+
+type columnChunkMeta struct {
+	offset       int64
+	values       int64
+	uncompressed int64
+	compressed   int64
+}
+
+type rowGroupMeta struct {
+	rows    int64
+	bytes   int64
+	columns []columnChunkMeta
+}
+
+func newParquetEncoder[T any](w io.Writer, columns []Column[T]) *parquetEncoder[T] {
+	e := &parquetEncoder[T]{w: &countingWriter{w: w}, columns: columns, chunks: make([]*columnBuffer, len(columns))}
+	for i := range e.chunks {
+		e.chunks[i] = &columnBuffer{}
+	}
+	return e
+}
+
+func (e *parquetEncoder[T]) Write(rows []T) error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.w.n == 0 {
+		if _, err := io.WriteString(e.w, parquetMagic); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		for i, column := range e.columns {
+			v, err := value(column, row)
+			if err != nil {
+				return err
+			}
+			chunk := e.chunks[i]
+			if column.Optional {
+				chunk.defined = append(chunk.defined, v != nil)
+			}
+			switch v := v.(type) {
+			case nil:
+			case string:
+				binary.Write(&chunk.values, binary.LittleEndian, uint32(len(v)))
+				chunk.values.WriteString(v)
+			case int64:
+				binary.Write(&chunk.values, binary.LittleEndian, v)
+			case bool:
+				chunk.bools = append(chunk.bools, v)
+			case time.Time:
+				binary.Write(&chunk.values, binary.LittleEndian, v.UnixMilli())
+			}
+		}
+		e.rows++
+		if e.rows == rowGroupSize {
+			if err := e.flush(); err != nil {
+				e.err = err
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// flush writes the buffered rows as a row group
+func (e *parquetEncoder[T]) flush() error {
+	if e.rows == 0 {
+		return nil
+	}
+	group := rowGroupMeta{rows: int64(e.rows), columns: make([]columnChunkMeta, len(e.columns))}
+	for i, column := range e.columns {
+		chunk := e.chunks[i]
+		var page bytes.Buffer
+		if column.Optional {
+			levels := rleBits(chunk.defined)
+			binary.Write(&page, binary.LittleEndian, uint32(len(levels)))
+			page.Write(levels)
+		}
+		if column.Type == Bool {
+			page.Write(packBits(chunk.bools))
+		} else {
+			page.Write(chunk.values.Bytes())
+		}
+
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		if _, err := gz.Write(page.Bytes()); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+
+		header := pageHeader(e.rows, page.Len(), compressed.Len())
+		meta := columnChunkMeta{
+			offset:       e.w.n,
+			values:       int64(e.rows),
+			uncompressed: int64(len(header) + page.Len()),
+			compressed:   int64(len(header) + compressed.Len()),
+		}
+		if _, err := e.w.Write(header); err != nil {
+			return err
+		}
+		if _, err := e.w.Write(compressed.Bytes()); err != nil {
+			return err
+		}
+		group.columns[i] = meta
+		group.bytes += meta.uncompressed
+		e.chunks[i] = &columnBuffer{}
+	}
+	e.groups = append(e.groups, group)
+	e.total += int64(e.rows)
+	e.rows = 0
+	return nil
+}
+
+func (e *parquetEncoder[T]) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.w.n == 0 {
+		if _, err := io.WriteString(e.w, parquetMagic); err != nil {
+			return err
+		}
+	}
+	if err := e.flush(); err != nil {
+		return err
+	}
+	footer := e.footer()
+	if _, err := e.w.Write(footer); err != nil {
+		return err
+	}
+	if err := binary.Write(e.w, binary.LittleEndian, uint32(len(footer))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, parquetMagic)
+	return err
+}
+
+// footer encodes the FileMetaData struct
+func (e *parquetEncoder[T]) footer() []byte {
+	t := &thriftWriter{}
+	t.structBegin()
+	t.i32(1, 1) // version
+	t.listBegin(2, thriftStruct, len(e.columns)+1)
+	t.structBegin() // root of the schema
+	t.binary(4, "schema")
+	t.i32(5, int32(len(e.columns)))
+	t.structEnd()
+	for _, column := range e.columns {
+		t.structBegin()
+		t.i32(1, parquetType(column.Type))
+		repetition := int32(parquetRequired)
+		if column.Optional {
+			repetition = parquetOptional
+		}
+		t.i32(3, repetition)
+		t.binary(4, column.Name)
+		switch column.Type {
+		case String:
+			t.i32(6, parquetUTF8)
+		case Timestamp:
+			t.i32(6, parquetTimestampMillis)
+		}
+		t.structEnd()
+	}
+	t.i64(3, e.total)
+	t.listBegin(4, thriftStruct, len(e.groups))
+	for _, group := range e.groups {
+		t.structBegin()
+		t.listBegin(1, thriftStruct, len(group.columns))
+		for i, chunk := range group.columns {
+			t.structBegin()
+			t.i64(2, chunk.offset)
+			t.fieldBegin(3, thriftStruct)
+			t.structBegin()
+			t.i32(1, parquetType(e.columns[i].Type))
+			t.listBegin(2, thriftI32, 2)
+			t.varint(zigzag(parquetPlain))
+			t.varint(zigzag(parquetRLE))
+			t.listBegin(3, thriftBinary, 1)
+			t.varint(uint64(len(e.columns[i].Name)))
+			t.buf.WriteString(e.columns[i].Name)
+			t.i32(4, parquetGzip)
+			t.i64(5, chunk.values)
+			t.i64(6, chunk.uncompressed)
+			t.i64(7, chunk.compressed)
+			t.i64(9, chunk.offset)
+			t.structEnd()
+			t.structEnd()
+		}
+		t.i64(2, group.bytes)
+		t.i64(3, group.rows)
+		t.structEnd()
+	}
+	t.binary(6, "ecomgo bi export")
+	t.structEnd()
+	return t.buf.Bytes()
+}
+
+// pageHeader encodes the PageHeader of a v1 data page of rows values
+func pageHeader(rows, uncompressed, compressed int) []byte {
+	t := &thriftWriter{}
+	t.structBegin()
+	t.i32(1, parquetDataPage)
+	t.i32(2, int32(uncompressed))
+	t.i32(3, int32(compressed))
+	t.fieldBegin(5, thriftStruct)
+	t.structBegin()
+	t.i32(1, int32(rows))
+	t.i32(2, parquetPlain)
+	t.i32(3, parquetRLE)
+	t.i32(4, parquetRLE)
+	t.structEnd()
+	t.structEnd()
+	return t.buf.Bytes()
+}
+
+func parquetType(typ Type) int32 {
+	switch typ {
+	case Bool:
+		return parquetBoolean
+	case Int64, Timestamp:
+		return parquetInt64
+	}
+	return parquetByteArray
+}
+
+// rleBits encodes 0/1 levels with the RLE/bit-packed hybrid encoding at bit width 1, as runs
+func rleBits(levels []bool) []byte {
+	var out []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		if levels[i] {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+		i = j
+	}
+	return out
+}
+
+// packBits packs booleans least significant bit first, as PLAIN encodes them
+func packBits(values []bool) []byte {
+	out := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			out[i/8] |= 1 << (i % 8)
+		}
+	}
+	return out
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Thrift compact protocol type codes
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the few Thrift compact protocol constructs Parquet metadata needs
+type thriftWriter struct {
+	buf  bytes.Buffer
+	last []int16 // previous field ID of each open struct, for delta-encoded field headers
+}
+
+func (t *thriftWriter) structBegin() {
+	t.last = append(t.last, 0)
+}
+
+func (t *thriftWriter) structEnd() {
+	t.buf.WriteByte(0) // stop
+	t.last = t.last[:len(t.last)-1]
+}
+
+func (t *thriftWriter) fieldBegin(id int16, typ byte) {
+	top := len(t.last) - 1
+	if delta := id - t.last[top]; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(zigzag(int64(id)))
+	}
+	t.last[top] = id
+}
+
+func (t *thriftWriter) listBegin(id int16, elem byte, size int) {
+	t.fieldBegin(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elem)
+		return
+	}
+	t.buf.WriteByte(0xf0 | elem)
+	t.varint(uint64(size))
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldBegin(id, thriftI32)
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldBegin(id, thriftI64)
+	t.varint(zigzag(v))
+}
+
+func (t *thriftWriter) binary(id int16, v string) {
+	t.fieldBegin(id, thriftBinary)
+	t.varint(uint64(len(v)))
+	t.buf.WriteString(v)
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	t.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func zigzag(n int64) uint64 {
+	return uint64((n << 1) ^ (n >> 63))
+}
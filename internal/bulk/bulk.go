@@ -0,0 +1,168 @@
+// Package bulk runs admin bulk actions - changes over many records, like repricing a category -
+// as background jobs. Modules register their actions at startup; starting one validates its
+// parameters, queues a "bulk.<action>" job and returns it straight away, and the admin follows
+// its progress through GET /admin/jobs/{id}.
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+)
+
+// JobPrefix starts the job type of every bulk action
+const JobPrefix = "bulk."
+
+var (
+	// ErrUnknownAction is returned by Start for an action no module registered
+	ErrUnknownAction = errors.New("unknown bulk action")
+	// ErrInvalidParams wraps what an action's Validate finds wrong with its parameters
+	ErrInvalidParams = errors.New("invalid bulk action parameters")
+)
+
+// Action is one kind of bulk change an admin can start
+type Action struct {
+	Name        string
+	Description string // shown by GET /admin/bulk-actions, including the parameters it takes
+
+	// Validate checks the parameters before anything is queued; errors it returns are
+	// reported to the admin as ErrInvalidParams
+	Validate func(ctx context.Context, params json.RawMessage) error
+
+	// Run does the work on a job worker and returns the summary stored as the job's result
+	// A failed or crashed run is retried, so Run must be safe to repeat: it can pick up
+	// where it stopped with Run.State, and must not apply the same change twice
+	Run func(ctx context.Context, run *Run) (interface{}, error)
+}
+
+// Run is one execution of a bulk action
+type Run struct {
+	Job         *models.Job
+	RequestedBy string    // user ID of the admin who started it
+	QueuedAt    time.Time // records changed after this were changed by someone else since
+
+	params json.RawMessage
+	jobs   *jobs.Processor
+}
+
+// payload is the job payload of a bulk action
+type payload struct {
+	Params      json.RawMessage `json:"params,omitempty"`
+	RequestedBy string          `json:"requested_by,omitempty"`
+}
+
+// Params decodes the action's parameters into dest
+func (r *Run) Params(dest interface{}) error {
+	if len(r.params) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.params, dest)
+}
+
+// State decodes the state an earlier attempt saved with Progress into dest
+// dest is left alone on the first attempt
+func (r *Run) State(dest interface{}) error {
+	if len(r.Job.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Job.Result, dest)
+}
+
+// Progress saves that done of total items are processed, with state as the summary so far
+// total is 0 while unknown. Call it after each batch: it also keeps the job's lock alive.
+func (r *Run) Progress(ctx context.Context, done, total int, state interface{}) error {
+	return r.jobs.Progress(ctx, r.Job, done, total, state)
+}
+
+// Registry holds the bulk actions modules registered
+type Registry struct {
+	jobs    *jobs.Processor
+	log     *zap.Logger
+	mu      sync.RWMutex
+	actions map[string]Action
+}
+
+func NewRegistry(processor *jobs.Processor, log *zap.Logger) *Registry {
+	return &Registry{jobs: processor, log: log, actions: make(map[string]Action)}
+}
+
+// Register makes action available to admins and registers the job handler that runs it
+func (r *Registry) Register(action Action) {
+	r.mu.Lock()
+	r.actions[action.Name] = action
+	r.mu.Unlock()
+	r.jobs.Register(JobPrefix+action.Name, r.handler(action))
+}
+
+// Actions lists the registered actions by name
+func (r *Registry) Actions() []models.BulkAction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]models.BulkAction, 0, len(r.actions))
+	for _, action := range r.actions {
+		list = append(list, models.BulkAction{Name: action.Name, Description: action.Description})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Start validates params and queues the action, returning its job
+// Nothing has been changed yet when it returns; the job's status and progress tell how it goes
+func (r *Registry) Start(ctx context.Context, name string, params json.RawMessage, requestedBy string) (*models.Job, error) {
+	r.mu.RLock()
+	action, ok := r.actions[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownAction
+	}
+	if action.Validate != nil {
+		if err := action.Validate(ctx, params); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidParams, err)
+		}
+	}
+
+	job, err := r.jobs.Enqueue(ctx, JobPrefix+name, payload{Params: params, RequestedBy: requestedBy})
+	if err != nil {
+		return nil, err
+	}
+	r.log.Info("Bulk action queued", zap.String("action", name), zap.String("job_id", job.ID),
+		zap.String("requested_by", requestedBy))
+	return job, nil
+}
+
+func (r *Registry) handler(action Action) jobs.Handler {
+	return func(ctx context.Context, job *models.Job) error {
+		var p payload
+		if err := job.Decode(&p); err != nil {
+			return err
+		}
+
+		run := &Run{Job: job, RequestedBy: p.RequestedBy, QueuedAt: job.CreatedAt, params: p.Params, jobs: r.jobs}
+		summary, err := action.Run(ctx, run)
+		if err != nil {
+			return err
+		}
+
+		// The summary becomes the result the job completes with
+		if summary != nil {
+			data, err := json.Marshal(summary)
+			if err != nil {
+				return err
+			}
+			job.Result = data
+		}
+		job.Done = max(job.Done, job.Total)
+		r.log.Info("Bulk action finished", zap.String("action", action.Name), zap.String("job_id", job.ID),
+			zap.Int("done", job.Done))
+		return nil
+	}
+}
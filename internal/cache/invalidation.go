@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Invalidator carries cache invalidations between instances
+// Every instance receives every message, its own included; TieredCache skips its own
+type Invalidator interface {
+	// Publish tells every instance to drop keys from its local tier
+	Publish(ctx context.Context, msg Invalidation) error
+
+	// Listen calls handle for each message until ctx is cancelled
+	// It calls resync whenever messages may have been missed (on connect and reconnect),
+	// so the listener can drop everything it holds instead
+	Listen(ctx context.Context, handle func(Invalidation), resync func()) error
+}
+
+// Invalidation is one broadcast: the keys a write on instance Origin changed
+type Invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+// RedisInvalidator broadcasts invalidations over a Redis pub/sub channel
+// Pub/sub is fire-and-forget: a subscriber that is disconnected misses messages, which is
+// why Listen resyncs on every (re)subscribe
+type RedisInvalidator struct {
+	client  *redis.Client
+	channel string
+	log     *zap.Logger
+}
+
+func NewRedisInvalidator(client *redis.Client, channel string, log *zap.Logger) *RedisInvalidator {
+	return &RedisInvalidator{client: client, channel: channel, log: log}
+}
+
+func (i *RedisInvalidator) Publish(ctx context.Context, msg Invalidation) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return i.client.Publish(ctx, i.channel, data).Err()
+}
+
+func (i *RedisInvalidator) Listen(ctx context.Context, handle func(Invalidation), resync func()) error {
+	sub := i.client.Subscribe(ctx, i.channel)
+	defer sub.Close()
+
+	for {
+		// Receive reconnects by itself and answers each (re)subscribe with a *redis.Subscription
+		received, err := sub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			i.log.Warn("Cache invalidation channel failed; retrying", zap.String("channel", i.channel), zap.Error(err))
+			resync()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		switch m := received.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				resync()
+			}
+		case *redis.Message:
+			var msg Invalidation
+			if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+				i.log.Warn("Ignoring malformed cache invalidation", zap.Error(err))
+				continue
+			}
+			handle(msg)
+		}
+	}
+}
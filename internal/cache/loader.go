@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Loader is a read-through cache that collapses concurrent misses for the same key into one load
+// Without it, a popular key expiring or being invalidated sends every in-flight request to the
+// database at once (thundering herd); with it, one request loads and the rest share the result.
+// Deduplication is per process - each instance still loads once per key.
+type Loader struct {
+	cache Cache
+	group singleflight.Group
+}
+
+func NewLoader(c Cache) *Loader {
+	return &Loader{cache: c}
+}
+
+// Invalidate deletes keys and detaches in-flight loads of them, so callers arriving after a
+// write don't join a load that may have read the old row
+// Stale copies kept by LoadJSONOrStale go too.
+func (l *Loader) Invalidate(ctx context.Context, keys ...string) error {
+	all := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		l.group.Forget(key)
+		all = append(all, key, staleKey(key))
+	}
+	return l.cache.Delete(ctx, all...)
+}
+
+// LoadJSON returns the value cached at key, or calls load once for all concurrent callers,
+// caches the result as JSON for ttl and decodes it for each caller
+// Errors from load are returned to every waiting caller and are not cached. The load runs
+// detached from the leader's cancellation so one client disconnecting doesn't fail the others;
+// each caller still stops waiting when its own ctx is done.
+func LoadJSON[T any](ctx context.Context, l *Loader, key string, ttl time.Duration,
+	load func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	if err := GetJSON(ctx, l.cache, key, &value); err == nil {
+		return value, nil
+	}
+
+	result := l.group.DoChan(key, func() (interface{}, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		loaded, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(loaded)
+		if err != nil {
+			return nil, err
+		}
+		// A failed cache write only costs the next caller a load
+		_ = l.cache.Set(loadCtx, key, data, ttl)
+		return data, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return value, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return value, res.Err
+		}
+		// Every caller decodes its own copy, so callers can't mutate each other's results
+		err := json.Unmarshal(res.Val.([]byte), &value)
+		return value, err
+	}
+}
+
+// LoadJSONOrStale is LoadJSON that also keeps each loaded value for staleTTL, well past ttl, and
+// serves that copy when load fails with an error serveStale accepts (typically a database outage)
+// Better a product page a few minutes old than an error page while the database is down.
+func LoadJSONOrStale[T any](ctx context.Context, l *Loader, key string, ttl, staleTTL time.Duration,
+	serveStale func(error) bool, load func(ctx context.Context) (T, error)) (T, error) {
+	value, err := LoadJSON(ctx, l, key, ttl, func(ctx context.Context) (T, error) {
+		loaded, err := load(ctx)
+		if err == nil {
+			_ = SetJSON(ctx, l.cache, staleKey(key), loaded, staleTTL)
+		}
+		return loaded, err
+	})
+	if err != nil && serveStale(err) {
+		var stale T
+		if GetJSON(ctx, l.cache, staleKey(key), &stale) == nil {
+			return stale, nil
+		}
+	}
+	return value, err
+}
+
+func staleKey(key string) string {
+	return "stale:" + key
+}
@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+)
+
+// MemoryCache implements Cache in process memory
+// Used when Redis is disabled: local development, tests, single-instance deployments
+// NOT shared between instances - locks and counters only protect this process
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	prefix  string
+	clock   clock.Clock // decides when entries expire
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time // zero means no expiry
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// NewMemoryCache creates an empty in-process cache
+func NewMemoryCache(prefix string) *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]memoryEntry),
+		prefix:  prefix,
+		clock:   clock.System,
+	}
+}
+
+// UseClock makes entries expire by clk instead of the wall clock
+// Tests pass a clock.Fake to expire codes, counters and locks without sleeping
+func (c *MemoryCache) UseClock(clk clock.Clock) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.clock = clk
+}
+
+// get returns a live entry, evicting it if expired (caller holds mu)
+func (c *MemoryCache) get(key string) (memoryEntry, bool) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return memoryEntry{}, false
+	}
+	if entry.expired(c.clock.Now()) {
+		delete(c.entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *MemoryCache) set(key string, value []byte, ttl time.Duration) {
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = c.clock.Now().Add(ttl)
+	}
+	c.entries[key] = entry
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.get(c.prefix + key)
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	// Copy so callers can't mutate the stored value
+	return append([]byte(nil), entry.value...), nil
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(c.prefix+key, append([]byte(nil), value...), ttl)
+	return nil
+}
+
+func (c *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		delete(c.entries, c.prefix+key)
+	}
+	return nil
+}
+
+func (c *MemoryCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key = c.prefix + key
+	entry, ok := c.get(key)
+	if !ok {
+		c.set(key, []byte("1"), ttl)
+		return 1, nil
+	}
+
+	count, err := strconv.ParseInt(string(entry.value), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	count++
+	// Keep the original expiry so the rate-limit window doesn't slide
+	entry.value = []byte(strconv.FormatInt(count, 10))
+	c.entries[key] = entry
+	return count, nil
+}
+
+func (c *MemoryCache) Lock(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key = c.prefix + "lock:" + key
+	if _, held := c.get(key); held {
+		return nil, ErrLockNotAcquired
+	}
+	c.set(key, []byte(token), ttl)
+
+	return &memoryLock{cache: c, key: key, token: token}, nil
+}
+
+// flush drops every entry
+func (c *MemoryCache) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]memoryEntry)
+}
+
+func (c *MemoryCache) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (c *MemoryCache) Close() error {
+	return nil
+}
+
+// memoryLock is a lock held in a MemoryCache
+type memoryLock struct {
+	cache *MemoryCache
+	key   string
+	token string
+}
+
+func (l *memoryLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	l.cache.mu.Lock()
+	defer l.cache.mu.Unlock()
+
+	entry, ok := l.cache.get(l.key)
+	if !ok || string(entry.value) != l.token {
+		return ErrLockLost
+	}
+	l.cache.set(l.key, entry.value, ttl)
+	return nil
+}
+
+func (l *memoryLock) Release(ctx context.Context) error {
+	l.cache.mu.Lock()
+	defer l.cache.mu.Unlock()
+
+	// Only delete if we still own it (it may have expired and been re-acquired)
+	if entry, ok := l.cache.get(l.key); ok && string(entry.value) == l.token {
+		delete(l.cache.entries, l.key)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseScript deletes the lock key only if it still holds our token
+// Runs atomically inside Redis to avoid check-then-delete races
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// refreshScript extends the lock key's expiry only if it still holds our token
+var refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// incrScript increments a counter and gives it ttl (ARGV[1], milliseconds) if it has no expiry:
+// on the first increment, or when an earlier expiry never got set. One script runs atomically, so
+// no crash or failed call between the two commands can leave a counter that never expires.
+var incrScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if tonumber(ARGV[1]) > 0 and redis.call("PTTL", KEYS[1]) == -1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// RedisCache implements Cache on top of a Redis server
+// Safe for use across multiple API instances
+type RedisCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisCache creates a Redis-backed cache (connections are opened lazily)
+func NewRedisCache(cfg config.Redis) *RedisCache {
+	return &RedisCache{
+		client: redis.NewClient(&redis.Options{
+			Addr:     cfg.Addr,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		}),
+		prefix: cfg.KeyPrefix,
+	}
+}
+
+// Client exposes the underlying Redis client for features that need raw commands (pub/sub, streams)
+func (c *RedisCache) Client() *redis.Client {
+	return c.client
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	}
+	return value, err
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return c.client.Set(ctx, c.prefix+key, value, ttl).Err()
+}
+
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
+
+func (c *RedisCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	// First increment opens the window; later increments keep the original expiry
+	return incrScript.Run(ctx, c.client, []string{c.prefix + key}, ttl.Milliseconds()).Int64()
+}
+
+func (c *RedisCache) Lock(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	key = c.prefix + "lock:" + key
+	ok, err := c.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrLockNotAcquired
+	}
+
+	return &redisLock{client: c.client, key: key, token: token}, nil
+}
+
+func (c *RedisCache) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// redisLock is a lock held via SET NX PX
+type redisLock struct {
+	client *redis.Client
+	key    string
+	token  string
+}
+
+func (l *redisLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	extended, err := refreshScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if extended == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (l *redisLock) Release(ctx context.Context) error {
+	return releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Err()
+}
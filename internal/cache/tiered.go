@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TieredCache is a RedisCache that also keeps hot keys in process memory (CACHE_LOCAL_TTL)
+// Only keys under the configured prefixes get the local tier; counters, locks, one-time codes
+// and revoked tokens always go to Redis. A write to a local key is broadcast through the
+// Invalidator, and every other instance drops its copy; without an invalidator, copies live
+// out the local ttl.
+type TieredCache struct {
+	*RedisCache
+	local       *MemoryCache
+	prefixes    []string
+	ttl         time.Duration
+	invalidator Invalidator
+	origin      string // tells this instance's broadcasts apart from the others'
+	log         *zap.Logger
+
+	// generation moves on every invalidation, so a Redis read that raced one isn't kept locally
+	generation atomic.Uint64
+
+	stop context.CancelFunc
+	done sync.WaitGroup
+}
+
+// NewTieredCache puts a local tier in front of remote and starts listening for invalidations
+// invalidator may be nil (CACHE_INVALIDATION=none)
+func NewTieredCache(remote *RedisCache, prefixes []string, ttl time.Duration, invalidator Invalidator,
+	log *zap.Logger) (*TieredCache, error) {
+	origin := make([]byte, 8)
+	if _, err := rand.Read(origin); err != nil {
+		return nil, err
+	}
+	t := &TieredCache{
+		RedisCache:  remote,
+		local:       NewMemoryCache(""),
+		prefixes:    prefixes,
+		ttl:         ttl,
+		invalidator: invalidator,
+		origin:      hex.EncodeToString(origin),
+		log:         log,
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	t.stop = stop
+	if invalidator != nil {
+		t.done.Add(1)
+		go func() {
+			defer t.done.Done()
+			if err := invalidator.Listen(ctx, t.handle, t.resync); err != nil && ctx.Err() == nil {
+				log.Error("Cache invalidation listener stopped", zap.Error(err))
+			}
+		}()
+	}
+	return t, nil
+}
+
+func (t *TieredCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if !t.isLocal(key) {
+		return t.RedisCache.Get(ctx, key)
+	}
+	if value, err := t.local.Get(ctx, key); err == nil {
+		return value, nil
+	}
+
+	generation := t.generation.Load()
+	value, err := t.RedisCache.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if t.generation.Load() == generation {
+		_ = t.local.Set(ctx, key, value, t.ttl)
+	}
+	return value, nil
+}
+
+// Set writes through to Redis; the local copy is dropped here and on every other instance,
+// and the next read loads the new value
+func (t *TieredCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := t.RedisCache.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	t.invalidate(ctx, []string{key})
+	return nil
+}
+
+func (t *TieredCache) Delete(ctx context.Context, keys ...string) error {
+	if err := t.RedisCache.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	t.invalidate(ctx, keys)
+	return nil
+}
+
+// Close stops the invalidation listener and closes the Redis connections
+func (t *TieredCache) Close() error {
+	t.stop()
+	t.done.Wait()
+	return t.RedisCache.Close()
+}
+
+// invalidate drops the local keys among keys here and broadcasts them to the other instances
+// A failed broadcast is logged: the other instances then serve the old value until their copy expires
+func (t *TieredCache) invalidate(ctx context.Context, keys []string) {
+	var local []string
+	for _, key := range keys {
+		if t.isLocal(key) {
+			local = append(local, key)
+		}
+	}
+	if len(local) == 0 {
+		return
+	}
+	t.drop(local)
+
+	if t.invalidator == nil {
+		return
+	}
+	if err := t.invalidator.Publish(ctx, Invalidation{Origin: t.origin, Keys: local}); err != nil {
+		t.log.Warn("Failed to broadcast cache invalidation", zap.Strings("keys", local), zap.Error(err))
+	}
+}
+
+// handle applies another instance's invalidation
+func (t *TieredCache) handle(msg Invalidation) {
+	if msg.Origin == t.origin {
+		return
+	}
+	t.drop(msg.Keys)
+}
+
+// resync drops the whole local tier after invalidations may have been missed
+func (t *TieredCache) resync() {
+	t.generation.Add(1)
+	t.local.flush()
+}
+
+func (t *TieredCache) drop(keys []string) {
+	t.generation.Add(1)
+	_ = t.local.Delete(context.Background(), keys...)
+}
+
+func (t *TieredCache) isLocal(key string) bool {
+	for _, prefix := range t.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+// Package campaign resolves flash sale prices. The schedule of every campaign price that
+// hasn't ended is precomputed into the cache once; which entry applies is decided in memory
+// from the current time, so a campaign starting or ending costs no query and invalidates nothing.
+package campaign
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	scheduleKey = "campaigns:schedule"
+	// scheduleTTL bounds how long a change made outside the API takes to show up;
+	// changes through the admin endpoints invalidate the schedule immediately
+	scheduleTTL = 10 * time.Minute
+	// localTTL is how long an instance reuses its decoded schedule before reading the cache
+	// again, and so how long another instance's change can take to reach it
+	localTTL = 5 * time.Second
+)
+
+// Pricing serves the campaign prices in force
+// Lookups fall back to list prices when the schedule can't be loaded: a cache or database
+// outage must not take the catalog down with it
+type Pricing struct {
+	repo  *repository.CampaignRepository
+	cache *cache.Loader
+	clock clock.Clock
+	log   *zap.Logger
+
+	mu       sync.Mutex
+	local    *schedule
+	loadedAt time.Time
+	version  uint64 // bumped by Invalidate so a load that started before it isn't kept
+}
+
+// schedule is the cached price list indexed by product
+type schedule struct {
+	prices    []models.CampaignPrice
+	byProduct map[string][]models.CampaignPrice
+}
+
+func NewPricing(repo *repository.CampaignRepository, c cache.Cache, clk clock.Clock, log *zap.Logger) *Pricing {
+	return &Pricing{
+		repo:  repo,
+		cache: cache.NewLoader(c),
+		clock: clk,
+		log:   log,
+	}
+}
+
+// schedule returns the decoded schedule, from memory for localTTL and from the cache after that
+func (p *Pricing) schedule(ctx context.Context) (*schedule, error) {
+	now := p.clock.Now()
+	p.mu.Lock()
+	if p.local != nil && now.Sub(p.loadedAt) < localTTL {
+		defer p.mu.Unlock()
+		return p.local, nil
+	}
+	version := p.version
+	p.mu.Unlock()
+
+	prices, err := cache.LoadJSON(ctx, p.cache, scheduleKey, scheduleTTL, func(ctx context.Context) ([]models.CampaignPrice, error) {
+		return p.repo.Schedule(ctx, p.clock.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &schedule{prices: prices, byProduct: make(map[string][]models.CampaignPrice)}
+	for _, price := range prices {
+		s.byProduct[price.ProductID] = append(s.byProduct[price.ProductID], price)
+	}
+
+	p.mu.Lock()
+	if p.version == version {
+		p.local, p.loadedAt = s, now
+	}
+	p.mu.Unlock()
+	return s, nil
+}
+
+// Prices is the schedule as of one instant; use one per request so every product in a
+// response is priced at the same moment
+type Prices struct {
+	at       time.Time
+	schedule *schedule
+	segments map[string]bool // targeted campaigns that apply; public prices have none
+}
+
+// Current returns the public prices in force now; targeted campaigns don't apply
+func (p *Pricing) Current(ctx context.Context) Prices {
+	s, err := p.schedule(ctx)
+	if err != nil {
+		p.log.Warn("Failed to load campaign prices, using list prices", zap.Error(err))
+	}
+	return Prices{at: p.clock.Now(), schedule: s}
+}
+
+// For returns the prices in force now for a customer in the given segments: the public
+// prices plus those of campaigns targeted at any of the segments
+func (p *Pricing) For(ctx context.Context, segmentIDs []string) Prices {
+	prices := p.Current(ctx)
+	prices.segments = make(map[string]bool, len(segmentIDs))
+	for _, id := range segmentIDs {
+		prices.segments[id] = true
+	}
+	return prices
+}
+
+// Lookup returns the lowest campaign price of productID in force, if any
+func (p Prices) Lookup(productID string) (models.CampaignPrice, bool) {
+	var best models.CampaignPrice
+	found := false
+	if p.schedule == nil {
+		return best, false
+	}
+	for _, price := range p.schedule.byProduct[productID] {
+		if p.at.Before(price.StartsAt) || !p.at.Before(price.EndsAt) {
+			continue
+		}
+		if price.SegmentID != "" && !p.segments[price.SegmentID] {
+			continue
+		}
+		if !found || price.Price < best.Price {
+			best, found = price, true
+		}
+	}
+	return best, found
+}
+
+// Sale returns the price to charge for a product listed at listPrice, and the sale it comes
+// from (nil at list price). Campaigns only ever lower a price: when the list price has
+// dropped below the campaign's, the list price applies.
+func (p Prices) Sale(productID string, listPrice int64) (int64, *models.ProductSale) {
+	price, ok := p.Lookup(productID)
+	if !ok || price.Price >= listPrice {
+		return listPrice, nil
+	}
+	return price.Price, &models.ProductSale{
+		CampaignID: price.CampaignID,
+		Campaign:   price.Campaign,
+		ListPrice:  listPrice,
+		EndsAt:     price.EndsAt,
+	}
+}
+
+// Campaigns returns the running and upcoming public campaigns, soonest to end first
+func (p *Pricing) Campaigns(ctx context.Context) ([]models.PublicCampaign, error) {
+	return p.campaigns(ctx, func(price models.CampaignPrice) bool { return price.SegmentID == "" })
+}
+
+// Offers returns the running and upcoming campaigns targeted at any of the given segments,
+// soonest to end first
+func (p *Pricing) Offers(ctx context.Context, segmentIDs []string) ([]models.PublicCampaign, error) {
+	segments := make(map[string]bool, len(segmentIDs))
+	for _, id := range segmentIDs {
+		segments[id] = true
+	}
+	return p.campaigns(ctx, func(price models.CampaignPrice) bool { return segments[price.SegmentID] })
+}
+
+// campaigns groups the schedule entries that haven't ended and pass include by campaign
+func (p *Pricing) campaigns(ctx context.Context, include func(models.CampaignPrice) bool) ([]models.PublicCampaign, error) {
+	s, err := p.schedule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := p.clock.Now()
+
+	campaigns := []models.PublicCampaign{}
+	index := make(map[string]int)
+	for _, price := range s.prices {
+		if !now.Before(price.EndsAt) || !include(price) {
+			continue
+		}
+		i, ok := index[price.CampaignID]
+		if !ok {
+			c := models.Campaign{StartsAt: price.StartsAt, EndsAt: price.EndsAt}
+			c.SetStatus(now)
+			campaigns = append(campaigns, models.PublicCampaign{
+				ID:       price.CampaignID,
+				Name:     price.Campaign,
+				Status:   c.Status,
+				StartsAt: price.StartsAt,
+				EndsAt:   price.EndsAt,
+			})
+			i = len(campaigns) - 1
+			index[price.CampaignID] = i
+		}
+		campaigns[i].Items = append(campaigns[i].Items, models.PublicCampaignItem{ProductID: price.ProductID, Price: price.Price})
+	}
+
+	sort.SliceStable(campaigns, func(i, j int) bool { return campaigns[i].EndsAt.Before(campaigns[j].EndsAt) })
+	return campaigns, nil
+}
+
+// Invalidate drops the schedule after a campaign changes
+// Other instances pick the change up within localTTL
+func (p *Pricing) Invalidate(ctx context.Context) error {
+	p.mu.Lock()
+	p.local = nil
+	p.version++
+	p.mu.Unlock()
+	return p.cache.Invalidate(ctx, scheduleKey)
+}
+
+// Warm loads the schedule before the instance takes traffic
+func (p *Pricing) Warm(ctx context.Context) error {
+	_, err := p.schedule(ctx)
+	return err
+}
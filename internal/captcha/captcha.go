@@ -0,0 +1,110 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+)
+
+// ErrRejected is returned by Verify when the provider judges the token invalid
+// Other errors mean the provider could not be asked
+var ErrRejected = errors.New("captcha token rejected")
+
+// Verifier checks a token produced by a CAPTCHA widget in the browser
+type Verifier interface {
+	// Name returns the provider name, e.g. "turnstile"
+	Name() string
+
+	// Verify checks token, solved by the client at remoteIP
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// siteverify endpoints; all three providers share the same form-encoded protocol
+var siteverifyURLs = map[string]string{
+	"turnstile": "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+	"hcaptcha":  "https://api.hcaptcha.com/siteverify",
+	"recaptcha": "https://www.google.com/recaptcha/api/siteverify",
+}
+
+// NewVerifier returns the verifier selected by CAPTCHA_PROVIDER, or nil when CAPTCHA is disabled
+func NewVerifier(cfg config.Captcha) (Verifier, error) {
+	if cfg.Provider == "" || cfg.Provider == "none" {
+		return nil, nil
+	}
+	endpoint, ok := siteverifyURLs[cfg.Provider]
+	if !ok {
+		return nil, fmt.Errorf("unsupported CAPTCHA_PROVIDER: %s (must be none, turnstile, hcaptcha or recaptcha)", cfg.Provider)
+	}
+	if cfg.Secret == "" {
+		return nil, errors.New("CAPTCHA_SECRET must be set when CAPTCHA_PROVIDER is enabled")
+	}
+	return NewSiteverify(cfg.Provider, endpoint, cfg.Secret), nil
+}
+
+// Siteverify verifies tokens with a provider's siteverify endpoint
+// Request: POST secret, response and remoteip as a form
+// Response: {"success": bool, "error-codes": [...]}
+type Siteverify struct {
+	name     string
+	endpoint string
+	secret   string
+	client   *http.Client
+}
+
+func NewSiteverify(name, endpoint, secret string) *Siteverify {
+	return &Siteverify{
+		name:     name,
+		endpoint: endpoint,
+		secret:   secret,
+		client:   httpclient.New(5 * time.Second),
+	}
+}
+
+func (v *Siteverify) Name() string {
+	return v.name
+}
+
+func (v *Siteverify) Verify(ctx context.Context, token, remoteIP string) error {
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s siteverify returned %d: %s", v.name, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode %s siteverify response: %w", v.name, err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrRejected, strings.Join(result.ErrorCodes, ", "))
+	}
+	return nil
+}
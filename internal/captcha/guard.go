@@ -0,0 +1,142 @@
+package captcha
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const (
+	// TokenHeader carries the token produced by the CAPTCHA widget
+	TokenHeader = "X-Captcha-Token"
+
+	// BypassHeader carries CAPTCHA_BYPASS_KEY for trusted internal clients
+	BypassHeader = "X-Captcha-Bypass"
+
+	// RequiredHeader names the provider on responses refused for a missing or rejected token,
+	// so clients know to render the widget and retry
+	RequiredHeader = "X-Captcha-Required"
+)
+
+// Guard puts a CAPTCHA in front of abuse-prone endpoints once a client exceeds a soft limit
+type Guard struct {
+	verifier     Verifier
+	cache        cache.Cache
+	freeAttempts int
+	window       time.Duration
+	trustProxy   bool
+	proxies      []*net.IPNet
+	bypassNets   []*net.IPNet
+	bypassKey    string
+	log          *zap.Logger
+}
+
+// NewGuard builds a guard from config; with a nil verifier it lets every request through
+// proxies are the trusted proxies skipped in X-Forwarded-For (SERVER_TRUSTED_PROXIES)
+func NewGuard(cfg config.Captcha, proxies []*net.IPNet, verifier Verifier, appCache cache.Cache, log *zap.Logger) (*Guard, error) {
+	g := &Guard{
+		verifier:     verifier,
+		cache:        appCache,
+		freeAttempts: cfg.FreeAttempts,
+		window:       cfg.Window,
+		trustProxy:   cfg.TrustProxy,
+		proxies:      proxies,
+		bypassKey:    cfg.BypassKey,
+		log:          log,
+	}
+	for _, cidr := range cfg.BypassCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CAPTCHA_BYPASS_CIDRS entry %q: %w", cidr, err)
+		}
+		g.bypassNets = append(g.bypassNets, network)
+	}
+	return g, nil
+}
+
+// Protect requires a CAPTCHA token on action once the client IP has used its free attempts
+// Every request counts towards the limit, whether or not it succeeds. When the provider
+// cannot be reached the request is let through, so an outage never blocks sign-up.
+func (g *Guard) Protect(action string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if g.verifier == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ip := g.clientIP(r)
+			if g.trusted(r, ip) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if g.freeAttempts > 0 {
+				count, err := g.cache.Incr(r.Context(), "captcha:"+action+":"+ip, g.window)
+				if err != nil {
+					g.log.Warn("CAPTCHA attempt counter unavailable", zap.String("action", action), zap.Error(err))
+				} else if count <= int64(g.freeAttempts) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			token := strings.TrimSpace(r.Header.Get(TokenHeader))
+			if token == "" {
+				g.refuse(w, "CAPTCHA required")
+				return
+			}
+
+			if err := g.verifier.Verify(r.Context(), token, ip); err != nil {
+				if errors.Is(err, ErrRejected) {
+					g.log.Info("CAPTCHA rejected", zap.String("action", action), zap.String("ip", ip), zap.Error(err))
+					g.refuse(w, "CAPTCHA verification failed")
+					return
+				}
+				g.log.Warn("CAPTCHA provider unavailable, letting request through",
+					zap.String("provider", g.verifier.Name()), zap.String("action", action), zap.Error(err))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (g *Guard) refuse(w http.ResponseWriter, message string) {
+	w.Header().Set(RequiredHeader, g.verifier.Name())
+	http.Error(w, message, http.StatusForbidden)
+}
+
+// trusted tells whether the request comes from an internal client exempt from CAPTCHA
+func (g *Guard) trusted(r *http.Request, ip string) bool {
+	if g.bypassKey != "" {
+		key := r.Header.Get(BypassHeader)
+		if key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(g.bypassKey)) == 1 {
+			return true
+		}
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range g.bypassNets {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the caller's address; X-Forwarded-For only with CAPTCHA_TRUST_PROXY=true
+func (g *Guard) clientIP(r *http.Request) string {
+	return httpctx.ClientIP(r, g.trustProxy, g.proxies)
+}
@@ -0,0 +1,140 @@
+// Package channel resolves the sales channel of a request (web, app, pos...) and what the
+// catalog looks like there: which products are hidden and which sell at a channel price.
+// Each channel's rules are cached as one entry, invalidated when an admin changes them.
+package channel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Header names the channel a client sells through, e.g. X-Channel: pos
+const Header = "X-Channel"
+
+const (
+	// rulesTTL bounds how long a change made outside the API takes to show up;
+	// changes through the admin endpoints invalidate the rules immediately
+	rulesTTL = 10 * time.Minute
+)
+
+// ErrUnknownChannel is returned for channels that don't exist or are inactive
+var ErrUnknownChannel = errors.New("unknown channel")
+
+// FromRequest returns the channel code from the channel query parameter or the X-Channel header
+// Returns: models.ChannelWeb when neither is set
+func FromRequest(r *http.Request) string {
+	code := r.URL.Query().Get("channel")
+	if code == "" {
+		code = r.Header.Get(Header)
+	}
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return models.ChannelWeb
+	}
+	return code
+}
+
+// Catalog serves per-channel product rules
+// Lookups fall back to the plain catalog when the rules can't be loaded: a cache or database
+// outage must not take the storefront down with it
+type Catalog struct {
+	repo  *repository.ChannelRepository
+	cache *cache.Loader
+	log   *zap.Logger
+}
+
+func NewCatalog(repo *repository.ChannelRepository, c cache.Cache, log *zap.Logger) *Catalog {
+	return &Catalog{repo: repo, cache: cache.NewLoader(c), log: log}
+}
+
+// snapshot is what is cached per channel
+type snapshot struct {
+	Active    bool                    `json:"active"`
+	Overrides []models.ChannelProduct `json:"overrides"`
+}
+
+func rulesKey(code string) string {
+	return "channels:rules:" + code
+}
+
+// Rules is one channel's view of the catalog; use one per request
+type Rules struct {
+	Channel string
+	hidden  map[string]bool
+	prices  map[string]int64
+}
+
+// Resolve returns the rules of the channel named code
+// Returns: ErrUnknownChannel for missing and inactive channels
+func (c *Catalog) Resolve(ctx context.Context, code string) (Rules, error) {
+	rules := Rules{Channel: code}
+	snap, err := cache.LoadJSON(ctx, c.cache, rulesKey(code), rulesTTL, func(ctx context.Context) (*snapshot, error) {
+		channel, err := c.repo.Get(ctx, code)
+		if errors.Is(err, repository.ErrChannelNotFound) {
+			return &snapshot{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		overrides, err := c.repo.Products(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		return &snapshot{Active: channel.Active, Overrides: overrides}, nil
+	})
+	if err != nil {
+		c.log.Warn("Failed to load channel rules, using the plain catalog", zap.String("channel", code), zap.Error(err))
+		return rules, nil
+	}
+	if !snap.Active {
+		return rules, ErrUnknownChannel
+	}
+
+	rules.hidden = make(map[string]bool)
+	rules.prices = make(map[string]int64)
+	for _, o := range snap.Overrides {
+		if o.Hidden {
+			rules.hidden[o.ProductID] = true
+		}
+		if o.Price != nil {
+			rules.prices[o.ProductID] = *o.Price
+		}
+	}
+	return rules, nil
+}
+
+// Visible tells whether productID is sold on the channel
+func (r Rules) Visible(productID string) bool {
+	return !r.hidden[productID]
+}
+
+// Hidden returns the products not sold on the channel
+func (r Rules) Hidden() []string {
+	ids := make([]string, 0, len(r.hidden))
+	for id := range r.hidden {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Price returns productID's list price on the channel: its channel price, or listPrice
+// Flash sale prices are applied to the result, see campaign.Prices.Sale
+func (r Rules) Price(productID string, listPrice int64) int64 {
+	if price, ok := r.prices[productID]; ok {
+		return price
+	}
+	return listPrice
+}
+
+// Invalidate drops a channel's cached rules after it or its overrides change
+func (c *Catalog) Invalidate(ctx context.Context, code string) error {
+	return c.cache.Invalidate(ctx, rulesKey(code))
+}
@@ -0,0 +1,97 @@
+// Package clock abstracts the current time and ID generation so services can be
+// driven deterministically: token expiry, retry schedules and timestamps follow a
+// Fake clock, and generated IDs come from a predictable Sequence.
+package clock
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Clock reports the current time
+type Clock interface {
+	Now() time.Time
+}
+
+// IDGenerator creates unique identifiers for new records, jobs and events
+type IDGenerator interface {
+	NewID() string
+}
+
+// System is the real wall clock; it reads in UTC
+var System Clock = systemClock{}
+
+// UUIDs generates random UUIDv4 strings (fits the char(36) id columns)
+var UUIDs IDGenerator = uuidGenerator{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// UseUTC makes UTC the process's local timezone
+// Called first thing in main, so time.Now, database drivers and zone-less parsing never
+// produce server-local times whatever TZ the host sets; responses then always carry UTC
+func UseUTC() {
+	time.Local = time.UTC
+}
+
+type uuidGenerator struct{}
+
+func (uuidGenerator) NewID() string {
+	return uuid.NewString()
+}
+
+// Fake is a manually advanced clock for tests and simulations
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Set moves the clock to now
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// Advance moves the clock forward by d and returns the new time
+func (f *Fake) Advance(d time.Duration) time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	return f.now
+}
+
+// Sequence generates predictable IDs: <prefix>-000001, <prefix>-000002...
+type Sequence struct {
+	mu     sync.Mutex
+	prefix string
+	next   int
+}
+
+func NewSequence(prefix string) *Sequence {
+	return &Sequence{prefix: prefix, next: 1}
+}
+
+func (s *Sequence) NewID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := fmt.Sprintf("%s-%06d", s.prefix, s.next)
+	s.next++
+	return id
+}
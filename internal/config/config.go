@@ -2,10 +2,15 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/joho/godotenv"
 )
 
@@ -15,20 +20,88 @@ type Config struct {
 	Database Database
 	Server   Server
 	Keycloak Keycloak
+	Redis    Redis
+	Cache    Cache
+	Events   Events
+	Auth     Auth
+	Accounts Accounts
+	Email    Email
+	SMS      SMS
+	Jobs     Jobs
+	FX       FX
+	Address  Address
+	TaxIDs   TaxIDs
+	Shipping Shipping
+	Storage  Storage
+	Search   Search
+	Fraud    Fraud
+	Captcha  Captcha
+	GeoIP    GeoIP
+	Payment  Payment
+	Orders   Orders
+	Checkout Checkout
+
+	Inventory   Inventory
+	Payouts     Payouts
+	Fiscal      Fiscal
+	Segments    Segments
+	Analytics   Analytics
+	BIExport    BIExport
+	Referrals   Referrals
+	AsyncWrites AsyncWrites
+	Locks       Locks
+	Capacity    Capacity
+	Timeouts    Timeouts
+	Quotas      Quotas
+	Features    Features
+	OIDC        OIDC
+
+	// DevMode is set by `serve --dev`: in-memory SQLite, seeded demo data, mock providers, relaxed auth
+	DevMode bool
 }
 
 type Database struct {
-	Type     string // mysql or postgres
+	Type     string // mysql, postgres or sqlite (development only)
 	User     string
 	Password string
 	Name     string
 	Host     string
 	Port     string
 	SSLMode  string
+
+	// Statement caching: skips re-parsing/planning the same SQL on every call
+	// mysql/sqlite use GORM PrepareStmt; postgres uses pgx's own per-connection statement cache
+	PrepareStmt   bool
+	StmtCacheSize int           // max cached statements per pool (GORM) or per connection (pgx)
+	StmtCacheTTL  time.Duration // GORM only: unused statements are closed after this
+	PGExecMode    string        // pgx default_query_exec_mode; simple_protocol behind PgBouncer transaction pooling
+
+	// Outage circuit breaker: after BreakerFailures consecutive connection failures queries fail
+	// fast with database.ErrUnavailable for BreakerCooldown; 0 failures disables it
+	BreakerFailures int
+	BreakerCooldown time.Duration
+
+	// Rolling-deploy safety of migrations: enforce rejects destructive or locking DDL that isn't
+	// wrapped in migrations.Unsafe, warn only logs it, off skips the checks
+	MigrationLint string
 }
 
 type Server struct {
 	Port string
+
+	// Startup warm-up before /readyz reports ready
+	WarmupConns   int           // database connections opened ahead of the first requests
+	WarmupTimeout time.Duration // ready anyway after this, warm or not
+
+	// Graceful shutdown on SIGINT/SIGTERM: in-flight requests finish, then background services stop
+	ShutdownTimeout time.Duration
+
+	// ResourceLinks embeds HAL-style _links (self, related collections, pages) in user, product and order responses
+	ResourceLinks bool
+
+	// TrustedProxies are the proxies and CDN ranges in front of the API; their X-Forwarded-For
+	// entries are skipped when a *_TRUST_PROXY setting reads the client's address (httpctx.ClientIP)
+	TrustedProxies []*net.IPNet
 }
 
 type Keycloak struct {
@@ -36,6 +109,425 @@ type Keycloak struct {
 	Realm        string
 	ClientID     string
 	ClientSecret string
+
+	// Admin sync: provision local users in Keycloak and keep realm roles in step
+	SyncEnabled   bool
+	SyncInterval  time.Duration // how often the reconciler repairs drift
+	RoleAuthority string        // local or keycloak - wins when a role changed on both sides
+}
+
+// Redis holds cache/lock server settings
+// When disabled, internal/cache falls back to an in-process store (single instance only)
+type Redis struct {
+	Enabled   bool
+	Addr      string // host:port
+	Password  string
+	DB        int
+	KeyPrefix string // namespaces keys when Redis is shared between apps/environments
+}
+
+// Cache holds the in-process tier kept in front of Redis
+// With LocalTTL > 0 every instance also keeps keys under LocalPrefixes in memory, sparing a
+// Redis round trip on the hottest reads (store settings, the category tree, channel rules).
+// Invalidation: redis (default) broadcasts changed keys over pub/sub so every instance drops its
+// copy at once; none leaves other instances' copies to expire after LocalTTL
+type Cache struct {
+	LocalTTL      time.Duration
+	LocalPrefixes []string
+	Invalidation  string
+}
+
+// Events holds domain event bus settings
+// Backend: memory (in-process, default), kafka or nats
+type Events struct {
+	Backend       string
+	KafkaBrokers  []string
+	NATSURL       string
+	TopicPrefix   string // prepended to event types to build topic/subject names
+	ConsumerGroup string // base consumer/queue group shared by all instances
+}
+
+// Auth holds access token settings
+// JWTSecret signs tokens - must be identical on every instance and never committed
+type Auth struct {
+	JWTSecret string
+	Issuer    string
+	TokenTTL  time.Duration
+
+	ImpersonationTTL time.Duration // lifetime of support impersonation tokens
+	ScopedTokenTTL   time.Duration // longest lifetime of scoped integration tokens (POST /tokens)
+}
+
+// Accounts holds sign-up and sign-in rules for user accounts
+// PhoneRegion parses numbers entered without a country code (0712 345 678 in KE)
+type Accounts struct {
+	PhoneRegion string // ISO 3166-1 alpha-2, see phone.KnownRegion
+	UniquePhone bool   // one account per phone number; signing in by phone requires it
+
+	AvatarMaxSize int64 // bytes accepted by avatar uploads
+
+	// Passwordless sign-in (POST /login/code): 6-digit codes, optionally with a magic link
+	LoginCodeTTL         time.Duration
+	LoginCodeMaxAttempts int // wrong guesses before a code stops working
+	LoginCodeSendLimit   int // codes sent per account, and per client IP, within LoginCodeWindow
+	LoginCodeVerifyLimit int // sign-in attempts per client IP within LoginCodeWindow
+	LoginCodeWindow      time.Duration
+	MagicLinkURL         string // storefront page that posts ?token= to /login/code/verify; empty disables links
+
+	// Password reset (POST /password/forgot): a one-time link to PasswordResetURL, sent like sign-in codes
+	PasswordResetTTL time.Duration
+	PasswordResetURL string // storefront page that posts ?token= and the new password to /password/reset; empty disables resets
+}
+
+// Email holds transactional email settings
+// Provider: log (development, default), smtp, ses (via SMTP interface) or sendgrid
+type Email struct {
+	Provider       string
+	From           string
+	FromName       string
+	SMTPHost       string
+	SMTPPort       string
+	SMTPUser       string
+	SMTPPassword   string
+	SendGridAPIKey string
+}
+
+// SMS holds text/WhatsApp messaging settings
+// Provider: log (development, default), twilio or africastalking
+type SMS struct {
+	Provider           string
+	TwilioAccountSID   string
+	TwilioAuthToken    string
+	TwilioFrom         string // E.164 sender number
+	TwilioWhatsAppFrom string // E.164 WhatsApp-enabled sender number
+	ATUsername         string // "sandbox" selects the Africa's Talking test environment
+	ATAPIKey           string
+	ATSenderID         string // optional alphanumeric sender ID / short code
+}
+
+// Jobs holds background job queue settings
+// Backend: db (default, uses the primary database) or redis (requires REDIS_ENABLED)
+type Jobs struct {
+	Backend      string
+	Concurrency  int           // worker goroutines per process
+	PollInterval time.Duration // how long idle workers wait before checking the queue again
+	MaxAttempts  int           // attempts before a job is moved to the dead letter queue
+	RunInAPI     bool          // false when jobs are processed by separate `worker` processes
+}
+
+// FX holds exchange-rate settings
+// Provider: static (rates from FX_STATIC_RATES, default), frankfurter (ECB, no key) or openexchangerates
+type FX struct {
+	Provider        string
+	BaseCurrency    string        // ISO 4217 code all rates are quoted against
+	APIKey          string        // openexchangerates app ID
+	StaticRates     []string      // CODE=rate pairs for the static provider, e.g. EUR=0.92
+	RefreshInterval time.Duration // how often rates are fetched from the provider
+	MaxAge          time.Duration // older rates are refused rather than used for pricing
+}
+
+// Address holds address validation/geocoding settings
+// Provider: none (accept as entered, default), google or here
+type Address struct {
+	Provider      string
+	APIKey        string
+	DeliveryZones []string // zone=CC[/Area] rules, first match wins (see internal/address)
+}
+
+// TaxIDs selects how customers' VAT/TIN numbers are checked
+// Provider: none (format check only, default) or vies (EU VIES registry for EU numbers)
+type TaxIDs struct {
+	Provider string
+	VIESURL  string
+}
+
+// Shipping holds carrier integration settings
+// Carriers: enabled carrier codes (manual, dhl, sendy); webhook secrets authenticate tracking callbacks
+type Shipping struct {
+	Carriers            []string
+	Origin              Origin // ship-from address handed to carriers
+	ManualWebhookSecret string
+	DHLAPIKey           string
+	DHLAPISecret        string
+	DHLAccountNumber    string
+	DHLWebhookSecret    string
+	SendyAPIKey         string
+	SendyUsername       string
+	SendyWebhookSecret  string
+}
+
+// Origin is the warehouse/store address shipments are collected from
+type Origin struct {
+	Name       string
+	Phone      string
+	Line1      string
+	City       string
+	PostalCode string
+	Country    string
+	Latitude   float64
+	Longitude  float64
+}
+
+// Storage holds object storage settings (product images, invoices, exports, digital goods, labels)
+// Backend: local (files under LocalDir), s3, minio or gcs (via its S3-compatible XML API and HMAC keys)
+type Storage struct {
+	Backend   string
+	Bucket    string
+	Region    string
+	Endpoint  string // host[:port]; defaults per backend (s3.amazonaws.com, storage.googleapis.com)
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+
+	LocalDir      string // root directory of the local backend
+	PublicURL     string // base URL of the API, used to build local presigned links
+	SigningKey    string // HMAC key for local presigned links
+	MaxUploadSize int64  // bytes accepted by local presigned uploads
+	PresignTTL    time.Duration
+}
+
+// Search holds product search engine settings
+// Backend: none (SQL LIKE fallback), meilisearch or elasticsearch
+// Index is the alias reads and writes go through; rebuilds swap a fresh index in behind it
+type Search struct {
+	Backend  string
+	URL      string
+	APIKey   string // Meilisearch master/admin key or Elasticsearch API key
+	Username string // Elasticsearch basic auth (when no API key)
+	Password string
+	Index    string
+}
+
+// Payment selects the payment provider used at checkout
+// Provider: sandbox (in-process, development and tests)
+type Payment struct {
+	Provider      string
+	WebhookSecret string        // verifies provider notifications
+	WebhookDedup  time.Duration // how long delivered webhook event IDs are remembered
+
+	DisputeScanInterval time.Duration // how often evidence deadlines are checked; 0 disables reminders
+	DisputeReminder     time.Duration // admins are reminded when a deadline is this close
+}
+
+// Orders holds order number, cart quote and event sourcing settings; the prefix is the order_number_prefix store setting
+// NumberReset: yearly (ORD-2025-000123, default), daily (ORD-20250314-0042) or never (ORD-000123)
+type Orders struct {
+	NumberReset  string
+	NumberDigits int           // zero-padded width of the counter; longer numbers still fit
+	QuoteTTL     time.Duration // how long a cart quote's prices are honored at checkout
+
+	// Event sourcing: every change to an order is also appended to its stream in order_events,
+	// which serves the order's history and its state as of a past date
+	EventSourcing bool
+	SnapshotEvery int // events between snapshots of an order's replayed state
+}
+
+// Checkout holds the retry policy of the checkout saga
+// A step that fails transiently (payment provider outage, lost database connection) is retried
+// RetryBackoff later, doubling each time; after MaxAttempts failures the checkout is compensated
+type Checkout struct {
+	MaxAttempts      int
+	RetryBackoff     time.Duration
+	RecoveryInterval time.Duration // how often due retries and checkouts left by crashed instances are resumed
+}
+
+// Inventory holds multi-warehouse settings
+// Allocation: nearest (default) ships from the closest warehouse with the stock, cheapest from
+// the one with the lowest rate to the delivery zone; checkout can still ask for either
+type Inventory struct {
+	Allocation       string
+	LowStockInterval time.Duration // how often stock is checked against reorder points; 0 disables it
+}
+
+// Payouts selects how vendor payouts are sent and how often batches run
+// Provider: manual (admins send the money and confirm each payout, default), sandbox, mpesa (M-Pesa B2C)
+// or bank (bank transfer gateway)
+type Payouts struct {
+	Provider       string
+	Interval       time.Duration // how often a batch pays vendors their settled earnings; 0 disables batches
+	Hold           time.Duration // delivered order lines are settled (paid by batches) after this long
+	CallbackURL    string        // public URL of /payouts/callbacks/{provider}, for providers that confirm later
+	CallbackSecret string        // authenticates provider callbacks
+
+	MpesaEnvironment        string // sandbox or production
+	MpesaConsumerKey        string
+	MpesaConsumerSecret     string
+	MpesaShortcode          string // B2C paybill the money is sent from
+	MpesaInitiator          string // API operator username
+	MpesaSecurityCredential string // initiator password encrypted with Safaricom's certificate
+
+	BankURL    string // base URL of the bank's transfer API
+	BankAPIKey string
+}
+
+// Fiscal selects who issues fiscal receipts for paid orders
+// Provider: none (no fiscal receipts, default), sandbox or etims (KRA eTIMS through a VSCU/OSCU)
+type Fiscal struct {
+	Provider string
+
+	EtimsURL      string // base URL of the eTIMS VSCU/OSCU API
+	EtimsTIN      string // the store's KRA PIN
+	EtimsBranchID string // branch the device is registered to (00 for head office)
+	EtimsCMCKey   string // communication key issued when the device was initialized
+}
+
+// Analytics holds precomputed report settings
+// CohortHour is the hour of day (UTC) the cohort report is rebuilt; outside 0-23 (e.g. -1) disables the nightly rebuild
+type Analytics struct {
+	CohortHour int
+}
+
+// BIExport holds data warehouse (BI) export settings
+// Every Interval, rows of orders, users and products changed since the last export are written
+// to object storage as Format (parquet or ndjson); 0 turns scheduled exports off. Rows changed in
+// the last Lag are left for the next run, so slow transactions can't commit behind the watermark
+type BIExport struct {
+	Interval time.Duration
+	Format   string
+	Lag      time.Duration
+}
+
+// Segments holds customer segmentation settings
+type Segments struct {
+	RefreshInterval time.Duration // how often every segment's membership is recomputed; 0 disables it
+}
+
+// Referrals holds referral program settings; reward amounts are store settings (/admin/settings)
+type Referrals struct {
+	LinkURL string // storefront signup URL shared by referrers; {code} is replaced by their code
+}
+
+// AsyncWrites tunes the buffered writers used for audit/analytics inserts (internal/batchwriter)
+type AsyncWrites struct {
+	BufferSize    int           // records held per writer; more are dropped and counted
+	BatchSize     int           // rows per INSERT
+	FlushInterval time.Duration // max time a record waits in the buffer
+	DrainTimeout  time.Duration // how long shutdown waits for the buffer to be written
+}
+
+// Locks selects the distributed lock backend used for leader election and mutual exclusion
+// Backend: auto (Redis if enabled, else database advisory locks), redis, db or memory (single instance)
+type Locks struct {
+	Backend   string
+	LeaderTTL time.Duration // a crashed leader is replaced after at most this long (cache backends)
+}
+
+// Capacity bounds concurrent work per instance (internal/limits); 0 means unlimited
+// Limits and queues can be changed at runtime via PUT /admin/capacity - these are the startup values
+type Capacity struct {
+	HTTPMaxInFlight  int           // API requests served at once
+	HTTPMaxQueued    int           // requests waiting for a slot; beyond this they get 503
+	HTTPQueueTimeout time.Duration // longest a request waits for a slot
+
+	OutboundMaxInFlight    int // requests to external APIs (providers, webhooks) at once
+	OutboundMaxQueued      int // outbound requests waiting for a slot; beyond this they fail fast
+	OutboundMaxIdlePerHost int // kept-alive connections per upstream host (startup only)
+
+	OutboundRetries         int           // extra attempts for idempotent outbound requests
+	OutboundRetryBackoff    time.Duration // wait before the first retry; doubles after each
+	OutboundBreakerFailures int           // consecutive failures that open a host's circuit; 0 disables it
+	OutboundBreakerCooldown time.Duration // how long an open circuit fails fast before a trial call
+}
+
+// Timeouts bounds how long API requests may run (limits.Deadlines); 0 means no deadline
+// Routes are keyed "METHOD /path/{template}" or "/path/{template}" (any method), relative to /api/v1
+// and written as in the route definitions; WebSocket and event-stream requests never get a deadline
+type Timeouts struct {
+	Default time.Duration
+	Routes  map[string]time.Duration
+}
+
+// Quotas caps what each tenant (X-Tenant-ID) may use per its plan, see internal/quota
+// Tenants not in Tenants are on DefaultPlan, the default tenant included; "" there means unlimited
+// OrderRoutes are keyed like Timeouts.Routes; a 2xx answer from one counts as an order placed
+type Quotas struct {
+	Enabled     bool
+	Plans       map[string]QuotaPlan
+	Tenants     map[string]string // tenant -> plan
+	DefaultPlan string
+	OrderRoutes []string
+}
+
+// Features puts route groups behind flags, so a module can ship switched off and be launched per
+// environment or per tenant without a code change, see internal/feature
+// Groups are path prefixes relative to /api/v1 written as in the route definitions; "/marketplace"
+// covers "/marketplace" and everything under it. Flags not in Enabled are on.
+type Features struct {
+	Groups  map[string][]string        // flag -> path prefixes
+	Enabled map[string]bool            // flag -> on for every tenant
+	Tenants map[string]map[string]bool // tenant -> flag -> on, overriding Enabled for that tenant
+}
+
+// OIDC runs a minimal OpenID Connect provider so first-party tools sign in with store accounts,
+// see cmd/service/oidc. Only the authorization code flow is offered; public clients (no secret)
+// must use PKCE. LoginURL is the page that signs the user in and completes the authorization,
+// like MagicLinkURL; without it browsers can't start a login.
+type OIDC struct {
+	Issuer     string // public URL of /api/v1/oidc, the iss of every token
+	SigningKey string // PEM RSA private key, or a path to one; empty generates a key per process
+	LoginURL   string // gets the authorization request's query string
+	CodeTTL    time.Duration
+	TokenTTL   time.Duration // access and ID tokens
+	Clients    map[string]OIDCClient
+}
+
+// OIDCClient is a tool allowed to sign users in
+type OIDCClient struct {
+	Secret       string   // empty for public clients
+	RedirectURIs []string // compared exactly
+}
+
+// QuotaPlan is one plan's limits; 0 means unlimited
+type QuotaPlan struct {
+	RequestsPerDay int64 // API requests per UTC day
+	OrdersPerMonth int64 // orders placed per UTC calendar month
+}
+
+// Fraud holds checkout risk scoring settings
+// Provider: rules (built-in) or http (external scoring service, rules used when it is unreachable)
+// Scores run 0-100; orders at or above ReviewScore are held for review, at or above DenyScore rejected
+type Fraud struct {
+	Provider    string
+	ReviewScore int
+	DenyScore   int
+
+	VelocityLimit       int // max checkouts per user, IP or device within VelocityWindow
+	VelocityWindow      time.Duration
+	HighValueFirstOrder int64 // first-order amount (minor units of FX base currency) considered risky
+	TrustProxy          bool  // read client IP/country from X-Forwarded-For / CF-IPCountry
+
+	ProviderURL    string // http provider endpoint
+	ProviderSecret string // signs requests to the http provider (X-Signature, HMAC-SHA256)
+}
+
+// Captcha holds the bot check on sign-up
+// Provider: none (disabled, default), turnstile, hcaptcha or recaptcha
+// Soft limit: each client IP gets FreeAttempts per Window before a CAPTCHA token is required;
+// 0 requires one on every attempt. Trusted internal clients (BypassCIDRs, or X-Captcha-Bypass
+// matching BypassKey) are never asked.
+type Captcha struct {
+	Provider     string
+	Secret       string // provider secret key used to verify tokens
+	FreeAttempts int
+	Window       time.Duration
+	TrustProxy   bool // read client IP from X-Forwarded-For
+
+	BypassCIDRs []string
+	BypassKey   string
+}
+
+// GeoIP holds client geolocation settings
+// Provider: none (default), header (country set by a CDN/proxy, e.g. CF-IPCountry)
+// or maxmind (GeoIP2 Country web service, looked up per client IP and cached for CacheTTL)
+type GeoIP struct {
+	Provider   string
+	Header     string // header provider: request header holding the ISO country code
+	TrustProxy bool   // read client IP from X-Forwarded-For
+
+	MaxMindURL        string
+	MaxMindAccountID  string
+	MaxMindLicenseKey string
+	CacheTTL          time.Duration
 }
 
 // LoadConfig reads configuration from .env file and environment variables
@@ -60,15 +552,286 @@ func LoadConfig() (*Config, error) {
 			Host:     strings.TrimSpace(getEnv("DB_HOST", "localhost")),
 			Port:     strings.TrimSpace(getEnv("DB_PORT", "3306")),
 			SSLMode:  strings.TrimSpace(getEnv("DB_SSLMODE", "disable")),
+
+			PrepareStmt:   getEnvBool("DB_PREPARE_STMT", true),
+			StmtCacheSize: getEnvInt("DB_STMT_CACHE_SIZE", 256),
+			StmtCacheTTL:  getEnvDuration("DB_STMT_CACHE_TTL", time.Hour),
+			PGExecMode:    strings.ToLower(strings.TrimSpace(getEnv("DB_PG_EXEC_MODE", ""))),
+
+			BreakerFailures: getEnvInt("DB_BREAKER_FAILURES", 5),
+			BreakerCooldown: getEnvDuration("DB_BREAKER_COOLDOWN", 10*time.Second),
+
+			MigrationLint: strings.ToLower(strings.TrimSpace(getEnv("DB_MIGRATION_LINT", "enforce"))),
 		},
 		Server: Server{
-			Port: strings.TrimSpace(getEnv("SERVER_PORT", "8085")),
+			Port:          strings.TrimSpace(getEnv("SERVER_PORT", "8085")),
+			WarmupConns:   getEnvInt("SERVER_WARMUP_CONNS", 5),
+			WarmupTimeout: getEnvDuration("SERVER_WARMUP_TIMEOUT", 30*time.Second),
+
+			ShutdownTimeout: getEnvDuration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+
+			ResourceLinks: getEnvBool("SERVER_RESOURCE_LINKS", false),
 		},
 		Keycloak: Keycloak{
 			URL:          strings.TrimSpace(getEnv("KEYCLOAK_URL", "http://localhost:8080")),
 			Realm:        strings.TrimSpace(getEnv("KEYCLOAK_REALM", "master")),
 			ClientID:     strings.TrimSpace(getEnv("KEYCLOAK_CLIENT_ID", "ecomgo")),
 			ClientSecret: strings.TrimSpace(getEnv("KEYCLOAK_CLIENT_SECRET", "")),
+
+			SyncEnabled:   getEnvBool("KEYCLOAK_SYNC_ENABLED", false),
+			SyncInterval:  getEnvDuration("KEYCLOAK_SYNC_INTERVAL", 15*time.Minute),
+			RoleAuthority: strings.TrimSpace(getEnv("KEYCLOAK_ROLE_AUTHORITY", "keycloak")),
+		},
+		Redis: Redis{
+			Enabled:   getEnvBool("REDIS_ENABLED", false),
+			Addr:      strings.TrimSpace(getEnv("REDIS_ADDR", "localhost:6379")),
+			Password:  strings.TrimSpace(getEnv("REDIS_PASSWORD", "")),
+			DB:        getEnvInt("REDIS_DB", 0),
+			KeyPrefix: strings.TrimSpace(getEnv("REDIS_KEY_PREFIX", "ecomgo:")),
+		},
+		Cache: Cache{
+			LocalTTL: getEnvDuration("CACHE_LOCAL_TTL", 0),
+			LocalPrefixes: getEnvList("CACHE_LOCAL_KEYS", []string{"settings:", "catalog:categories", "channels:",
+				"customer_groups:rules:", "campaigns:", "pages:"}),
+			Invalidation: strings.ToLower(strings.TrimSpace(getEnv("CACHE_INVALIDATION", "redis"))),
+		},
+		Events: Events{
+			Backend:       strings.TrimSpace(getEnv("EVENTS_BACKEND", "memory")),
+			KafkaBrokers:  getEnvList("KAFKA_BROKERS", []string{"localhost:9092"}),
+			NATSURL:       strings.TrimSpace(getEnv("NATS_URL", "nats://localhost:4222")),
+			TopicPrefix:   strings.TrimSpace(getEnv("EVENTS_TOPIC_PREFIX", "ecomgo.")),
+			ConsumerGroup: strings.TrimSpace(getEnv("EVENTS_CONSUMER_GROUP", "ecomgo")),
+		},
+		Auth: Auth{
+			JWTSecret: strings.TrimSpace(getEnv("JWT_SECRET", "")),
+			Issuer:    strings.TrimSpace(getEnv("JWT_ISSUER", "ecomgo")),
+			TokenTTL:  getEnvDuration("JWT_TTL", 24*time.Hour),
+
+			ImpersonationTTL: getEnvDuration("IMPERSONATION_TTL", 15*time.Minute),
+			ScopedTokenTTL:   getEnvDuration("SCOPED_TOKEN_MAX_TTL", 30*24*time.Hour),
+		},
+		Accounts: Accounts{
+			PhoneRegion: strings.ToUpper(strings.TrimSpace(getEnv("PHONE_DEFAULT_REGION", "KE"))),
+			UniquePhone: getEnvBool("PHONE_UNIQUE", true),
+
+			AvatarMaxSize: int64(getEnvInt("AVATAR_MAX_MB", 5)) << 20,
+
+			LoginCodeTTL:         getEnvDuration("LOGIN_CODE_TTL", 10*time.Minute),
+			LoginCodeMaxAttempts: getEnvInt("LOGIN_CODE_MAX_ATTEMPTS", 5),
+			LoginCodeSendLimit:   getEnvInt("LOGIN_CODE_SEND_LIMIT", 5),
+			LoginCodeVerifyLimit: getEnvInt("LOGIN_CODE_VERIFY_LIMIT", 30),
+			LoginCodeWindow:      getEnvDuration("LOGIN_CODE_WINDOW", time.Hour),
+			MagicLinkURL:         strings.TrimSpace(getEnv("MAGIC_LINK_URL", "")),
+			PasswordResetTTL:     getEnvDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+			PasswordResetURL:     strings.TrimSpace(getEnv("PASSWORD_RESET_URL", "")),
+		},
+		Email: Email{
+			Provider:       strings.TrimSpace(getEnv("EMAIL_PROVIDER", "log")),
+			From:           strings.TrimSpace(getEnv("EMAIL_FROM", "no-reply@example.com")),
+			FromName:       strings.TrimSpace(getEnv("EMAIL_FROM_NAME", "EcomGo")),
+			SMTPHost:       strings.TrimSpace(getEnv("SMTP_HOST", "")),
+			SMTPPort:       strings.TrimSpace(getEnv("SMTP_PORT", "587")),
+			SMTPUser:       strings.TrimSpace(getEnv("SMTP_USER", "")),
+			SMTPPassword:   strings.TrimSpace(getEnv("SMTP_PASSWORD", "")),
+			SendGridAPIKey: strings.TrimSpace(getEnv("SENDGRID_API_KEY", "")),
+		},
+		SMS: SMS{
+			Provider:           strings.TrimSpace(getEnv("SMS_PROVIDER", "log")),
+			TwilioAccountSID:   strings.TrimSpace(getEnv("TWILIO_ACCOUNT_SID", "")),
+			TwilioAuthToken:    strings.TrimSpace(getEnv("TWILIO_AUTH_TOKEN", "")),
+			TwilioFrom:         strings.TrimSpace(getEnv("TWILIO_FROM", "")),
+			TwilioWhatsAppFrom: strings.TrimSpace(getEnv("TWILIO_WHATSAPP_FROM", "")),
+			ATUsername:         strings.TrimSpace(getEnv("AT_USERNAME", "")),
+			ATAPIKey:           strings.TrimSpace(getEnv("AT_API_KEY", "")),
+			ATSenderID:         strings.TrimSpace(getEnv("AT_SENDER_ID", "")),
+		},
+		Jobs: Jobs{
+			Backend:      strings.TrimSpace(getEnv("JOBS_BACKEND", "db")),
+			Concurrency:  getEnvInt("JOBS_CONCURRENCY", 4),
+			PollInterval: getEnvDuration("JOBS_POLL_INTERVAL", time.Second),
+			MaxAttempts:  getEnvInt("JOBS_MAX_ATTEMPTS", 5),
+			RunInAPI:     getEnvBool("JOBS_RUN_IN_API", true),
+		},
+		FX: FX{
+			Provider:        strings.TrimSpace(getEnv("FX_PROVIDER", "static")),
+			BaseCurrency:    strings.ToUpper(strings.TrimSpace(getEnv("FX_BASE_CURRENCY", "USD"))),
+			APIKey:          strings.TrimSpace(getEnv("FX_API_KEY", "")),
+			StaticRates:     getEnvList("FX_STATIC_RATES", nil),
+			RefreshInterval: getEnvDuration("FX_REFRESH_INTERVAL", time.Hour),
+			MaxAge:          getEnvDuration("FX_MAX_AGE", 48*time.Hour),
+		},
+		Address: Address{
+			Provider:      strings.TrimSpace(getEnv("ADDRESS_PROVIDER", "none")),
+			APIKey:        strings.TrimSpace(getEnv("ADDRESS_API_KEY", "")),
+			DeliveryZones: getEnvList("DELIVERY_ZONES", []string{"default=*"}),
+		},
+		TaxIDs: TaxIDs{
+			Provider: strings.ToLower(strings.TrimSpace(getEnv("TAX_ID_PROVIDER", "none"))),
+			VIESURL:  strings.TrimSpace(getEnv("TAX_ID_VIES_URL", "https://ec.europa.eu/taxation_customs/vies/rest-api/check-vat-number")),
+		},
+		Shipping: Shipping{
+			Carriers: getEnvList("SHIPPING_CARRIERS", []string{"manual"}),
+			Origin: Origin{
+				Name:       strings.TrimSpace(getEnv("SHIPPING_ORIGIN_NAME", "")),
+				Phone:      strings.TrimSpace(getEnv("SHIPPING_ORIGIN_PHONE", "")),
+				Line1:      strings.TrimSpace(getEnv("SHIPPING_ORIGIN_LINE1", "")),
+				City:       strings.TrimSpace(getEnv("SHIPPING_ORIGIN_CITY", "")),
+				PostalCode: strings.TrimSpace(getEnv("SHIPPING_ORIGIN_POSTAL_CODE", "")),
+				Country:    strings.ToUpper(strings.TrimSpace(getEnv("SHIPPING_ORIGIN_COUNTRY", "KE"))),
+				Latitude:   getEnvFloat("SHIPPING_ORIGIN_LAT", 0),
+				Longitude:  getEnvFloat("SHIPPING_ORIGIN_LNG", 0),
+			},
+			ManualWebhookSecret: strings.TrimSpace(getEnv("SHIPPING_MANUAL_WEBHOOK_SECRET", "")),
+			DHLAPIKey:           strings.TrimSpace(getEnv("DHL_API_KEY", "")),
+			DHLAPISecret:        strings.TrimSpace(getEnv("DHL_API_SECRET", "")),
+			DHLAccountNumber:    strings.TrimSpace(getEnv("DHL_ACCOUNT_NUMBER", "")),
+			DHLWebhookSecret:    strings.TrimSpace(getEnv("DHL_WEBHOOK_SECRET", "")),
+			SendyAPIKey:         strings.TrimSpace(getEnv("SENDY_API_KEY", "")),
+			SendyUsername:       strings.TrimSpace(getEnv("SENDY_USERNAME", "")),
+			SendyWebhookSecret:  strings.TrimSpace(getEnv("SENDY_WEBHOOK_SECRET", "")),
+		},
+		Storage: Storage{
+			Backend:   strings.ToLower(strings.TrimSpace(getEnv("STORAGE_BACKEND", "local"))),
+			Bucket:    strings.TrimSpace(getEnv("STORAGE_BUCKET", "")),
+			Region:    strings.TrimSpace(getEnv("STORAGE_REGION", "us-east-1")),
+			Endpoint:  strings.TrimSpace(getEnv("STORAGE_ENDPOINT", "")),
+			AccessKey: strings.TrimSpace(getEnv("STORAGE_ACCESS_KEY", "")),
+			SecretKey: strings.TrimSpace(getEnv("STORAGE_SECRET_KEY", "")),
+			UseSSL:    getEnvBool("STORAGE_USE_SSL", true),
+
+			LocalDir:      getEnv("STORAGE_LOCAL_DIR", "./data/storage"),
+			PublicURL:     strings.TrimRight(strings.TrimSpace(getEnv("STORAGE_PUBLIC_URL", "http://localhost:8085/api/v1")), "/"),
+			SigningKey:    strings.TrimSpace(getEnv("STORAGE_SIGNING_KEY", "")),
+			MaxUploadSize: int64(getEnvInt("STORAGE_MAX_UPLOAD_MB", 25)) << 20,
+			PresignTTL:    getEnvDuration("STORAGE_PRESIGN_TTL", 15*time.Minute),
+		},
+		Search: Search{
+			Backend:  strings.ToLower(strings.TrimSpace(getEnv("SEARCH_BACKEND", "none"))),
+			URL:      strings.TrimRight(strings.TrimSpace(getEnv("SEARCH_URL", "")), "/"),
+			APIKey:   strings.TrimSpace(getEnv("SEARCH_API_KEY", "")),
+			Username: strings.TrimSpace(getEnv("SEARCH_USERNAME", "")),
+			Password: strings.TrimSpace(getEnv("SEARCH_PASSWORD", "")),
+			Index:    strings.TrimSpace(getEnv("SEARCH_INDEX", "products")),
+		},
+		Fraud: Fraud{
+			Provider:    strings.ToLower(strings.TrimSpace(getEnv("FRAUD_PROVIDER", "rules"))),
+			ReviewScore: getEnvInt("FRAUD_REVIEW_SCORE", 50),
+			DenyScore:   getEnvInt("FRAUD_DENY_SCORE", 80),
+
+			VelocityLimit:       getEnvInt("FRAUD_VELOCITY_LIMIT", 5),
+			VelocityWindow:      getEnvDuration("FRAUD_VELOCITY_WINDOW", time.Hour),
+			HighValueFirstOrder: int64(getEnvInt("FRAUD_HIGH_VALUE_FIRST_ORDER", 50000)),
+			TrustProxy:          getEnvBool("FRAUD_TRUST_PROXY", false),
+
+			ProviderURL:    strings.TrimSpace(getEnv("FRAUD_PROVIDER_URL", "")),
+			ProviderSecret: strings.TrimSpace(getEnv("FRAUD_PROVIDER_SECRET", "")),
+		},
+		GeoIP: GeoIP{
+			Provider:   strings.ToLower(strings.TrimSpace(getEnv("GEOIP_PROVIDER", "none"))),
+			Header:     strings.TrimSpace(getEnv("GEOIP_HEADER", "CF-IPCountry")),
+			TrustProxy: getEnvBool("GEOIP_TRUST_PROXY", false),
+
+			MaxMindURL:        strings.TrimSpace(getEnv("GEOIP_MAXMIND_URL", "https://geolite.info/geoip/v2.1/country/")),
+			MaxMindAccountID:  strings.TrimSpace(getEnv("GEOIP_MAXMIND_ACCOUNT_ID", "")),
+			MaxMindLicenseKey: strings.TrimSpace(getEnv("GEOIP_MAXMIND_LICENSE_KEY", "")),
+			CacheTTL:          getEnvDuration("GEOIP_CACHE_TTL", 24*time.Hour),
+		},
+		Captcha: Captcha{
+			Provider:     strings.ToLower(strings.TrimSpace(getEnv("CAPTCHA_PROVIDER", "none"))),
+			Secret:       strings.TrimSpace(getEnv("CAPTCHA_SECRET", "")),
+			FreeAttempts: getEnvInt("CAPTCHA_FREE_ATTEMPTS", 3),
+			Window:       getEnvDuration("CAPTCHA_WINDOW", time.Hour),
+			TrustProxy:   getEnvBool("CAPTCHA_TRUST_PROXY", false),
+
+			BypassCIDRs: getEnvList("CAPTCHA_BYPASS_CIDRS", nil),
+			BypassKey:   strings.TrimSpace(getEnv("CAPTCHA_BYPASS_KEY", "")),
+		},
+		Payment: Payment{
+			Provider:      strings.ToLower(strings.TrimSpace(getEnv("PAYMENT_PROVIDER", "sandbox"))),
+			WebhookSecret: strings.TrimSpace(getEnv("PAYMENT_WEBHOOK_SECRET", "")),
+			WebhookDedup:  getEnvDuration("PAYMENT_WEBHOOK_DEDUP_TTL", 72*time.Hour),
+
+			DisputeScanInterval: getEnvDuration("DISPUTE_SCAN_INTERVAL", time.Hour),
+			DisputeReminder:     getEnvDuration("DISPUTE_REMINDER_WINDOW", 72*time.Hour),
+		},
+		Orders: Orders{
+			NumberReset:  strings.ToLower(strings.TrimSpace(getEnv("ORDER_NUMBER_RESET", "yearly"))),
+			NumberDigits: getEnvInt("ORDER_NUMBER_DIGITS", 6),
+			QuoteTTL:     getEnvDuration("CART_QUOTE_TTL", 15*time.Minute),
+
+			EventSourcing: getEnvBool("ORDER_EVENT_SOURCING", false),
+			SnapshotEvery: getEnvInt("ORDER_SNAPSHOT_EVERY", 20),
+		},
+		Checkout: Checkout{
+			MaxAttempts:      getEnvInt("CHECKOUT_MAX_ATTEMPTS", 5),
+			RetryBackoff:     getEnvDuration("CHECKOUT_RETRY_BACKOFF", 30*time.Second),
+			RecoveryInterval: getEnvDuration("CHECKOUT_RECOVERY_INTERVAL", 30*time.Second),
+		},
+		Inventory: Inventory{
+			Allocation:       strings.ToLower(strings.TrimSpace(getEnv("INVENTORY_ALLOCATION", "nearest"))),
+			LowStockInterval: getEnvDuration("LOW_STOCK_SCAN_INTERVAL", time.Hour),
+		},
+		Fiscal: Fiscal{
+			Provider:      strings.ToLower(strings.TrimSpace(getEnv("FISCAL_PROVIDER", "none"))),
+			EtimsURL:      strings.TrimRight(strings.TrimSpace(getEnv("ETIMS_URL", "")), "/"),
+			EtimsTIN:      strings.TrimSpace(getEnv("ETIMS_TIN", "")),
+			EtimsBranchID: strings.TrimSpace(getEnv("ETIMS_BRANCH_ID", "00")),
+			EtimsCMCKey:   strings.TrimSpace(getEnv("ETIMS_CMC_KEY", "")),
+		},
+		Payouts: Payouts{
+			Provider:                strings.ToLower(strings.TrimSpace(getEnv("PAYOUT_PROVIDER", "manual"))),
+			Interval:                getEnvDuration("PAYOUT_INTERVAL", 24*time.Hour),
+			Hold:                    getEnvDuration("PAYOUT_HOLD", 7*24*time.Hour),
+			CallbackURL:             strings.TrimSpace(getEnv("PAYOUT_CALLBACK_URL", "")),
+			CallbackSecret:          strings.TrimSpace(getEnv("PAYOUT_CALLBACK_SECRET", "")),
+			MpesaEnvironment:        strings.ToLower(strings.TrimSpace(getEnv("MPESA_B2C_ENVIRONMENT", "sandbox"))),
+			MpesaConsumerKey:        strings.TrimSpace(getEnv("MPESA_B2C_CONSUMER_KEY", "")),
+			MpesaConsumerSecret:     strings.TrimSpace(getEnv("MPESA_B2C_CONSUMER_SECRET", "")),
+			MpesaShortcode:          strings.TrimSpace(getEnv("MPESA_B2C_SHORTCODE", "")),
+			MpesaInitiator:          strings.TrimSpace(getEnv("MPESA_B2C_INITIATOR", "")),
+			MpesaSecurityCredential: strings.TrimSpace(getEnv("MPESA_B2C_SECURITY_CREDENTIAL", "")),
+			BankURL:                 strings.TrimRight(strings.TrimSpace(getEnv("PAYOUT_BANK_URL", "")), "/"),
+			BankAPIKey:              strings.TrimSpace(getEnv("PAYOUT_BANK_API_KEY", "")),
+		},
+		Segments: Segments{
+			RefreshInterval: getEnvDuration("SEGMENT_REFRESH_INTERVAL", time.Hour),
+		},
+		Analytics: Analytics{
+			CohortHour: getEnvInt("COHORT_REPORT_HOUR", 2),
+		},
+		BIExport: BIExport{
+			Interval: getEnvDuration("BI_EXPORT_INTERVAL", 0),
+			Format:   strings.ToLower(strings.TrimSpace(getEnv("BI_EXPORT_FORMAT", "parquet"))),
+			Lag:      getEnvDuration("BI_EXPORT_LAG", time.Minute),
+		},
+		Referrals: Referrals{
+			LinkURL: strings.TrimSpace(getEnv("REFERRAL_LINK_URL", "http://localhost:3000/signup?ref={code}")),
+		},
+		Locks: Locks{
+			Backend:   strings.ToLower(strings.TrimSpace(getEnv("LOCK_BACKEND", "auto"))),
+			LeaderTTL: getEnvDuration("LEADER_LEASE_TTL", 30*time.Second),
+		},
+		Capacity: Capacity{
+			HTTPMaxInFlight:        getEnvInt("HTTP_MAX_IN_FLIGHT", 0),
+			HTTPMaxQueued:          getEnvInt("HTTP_MAX_QUEUED", 100),
+			HTTPQueueTimeout:       getEnvDuration("HTTP_QUEUE_TIMEOUT", 2*time.Second),
+			OutboundMaxInFlight:    getEnvInt("OUTBOUND_MAX_IN_FLIGHT", 0),
+			OutboundMaxQueued:      getEnvInt("OUTBOUND_MAX_QUEUED", 100),
+			OutboundMaxIdlePerHost: getEnvInt("OUTBOUND_MAX_IDLE_PER_HOST", 16),
+
+			OutboundRetries:         getEnvInt("OUTBOUND_RETRIES", 2),
+			OutboundRetryBackoff:    getEnvDuration("OUTBOUND_RETRY_BACKOFF", 200*time.Millisecond),
+			OutboundBreakerFailures: getEnvInt("OUTBOUND_BREAKER_FAILURES", 5),
+			OutboundBreakerCooldown: getEnvDuration("OUTBOUND_BREAKER_COOLDOWN", 30*time.Second),
+		},
+		Timeouts: Timeouts{
+			Default: getEnvDuration("REQUEST_TIMEOUT", 30*time.Second),
+		},
+		AsyncWrites: AsyncWrites{
+			BufferSize:    getEnvInt("ASYNC_WRITE_BUFFER", 10000),
+			BatchSize:     getEnvInt("ASYNC_WRITE_BATCH_SIZE", 200),
+			FlushInterval: getEnvDuration("ASYNC_WRITE_FLUSH_INTERVAL", time.Second),
+			DrainTimeout:  getEnvDuration("ASYNC_WRITE_DRAIN_TIMEOUT", 10*time.Second),
 		},
 	}
 
@@ -76,10 +839,256 @@ func LoadConfig() (*Config, error) {
 	if cfg.Database.Type != "mysql" && cfg.Database.Type != "postgres" {
 		return nil, fmt.Errorf("invalid DB_TYPE: %s (must be 'mysql' or 'postgres')", cfg.Database.Type)
 	}
+	switch cfg.Database.PGExecMode {
+	case "", "cache_statement", "cache_describe", "describe_exec", "exec", "simple_protocol":
+	default:
+		return nil, fmt.Errorf("invalid DB_PG_EXEC_MODE: %s", cfg.Database.PGExecMode)
+	}
+	routeTimeouts, err := parseRouteTimeouts(getEnvList("ROUTE_TIMEOUTS", defaultRouteTimeouts))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Timeouts.Routes = routeTimeouts
+
+	quotas, err := parseQuotas()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Quotas = quotas
+
+	features, err := parseFeatures()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Features = features
+
+	proxies, err := parseNetworks("SERVER_TRUSTED_PROXIES")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Server.TrustedProxies = proxies
+
+	oidc, err := parseOIDC()
+	if err != nil {
+		return nil, err
+	}
+	cfg.OIDC = oidc
 
 	return cfg, nil
 }
 
+// parseQuotas reads the TENANT_* quota settings
+// TENANT_PLANS entries are "plan=requests_per_day/orders_per_month", TENANT_PLAN_ASSIGNMENTS "tenant=plan"
+func parseQuotas() (Quotas, error) {
+	quotas := Quotas{
+		Enabled:     getEnvBool("TENANT_QUOTAS_ENABLED", false),
+		Plans:       make(map[string]QuotaPlan),
+		Tenants:     make(map[string]string),
+		DefaultPlan: strings.TrimSpace(getEnv("TENANT_DEFAULT_PLAN", "")),
+		OrderRoutes: getEnvList("TENANT_QUOTA_ORDER_ROUTES", []string{"POST /checkout", "POST /quotes/{id}/order"}),
+	}
+
+	for _, entry := range getEnvList("TENANT_PLANS", []string{"free=10000/100", "standard=250000/5000", "unlimited=0/0"}) {
+		name, limits, ok := strings.Cut(entry, "=")
+		requests, orders, found := strings.Cut(limits, "/")
+		perDay, err1 := strconv.ParseInt(strings.TrimSpace(requests), 10, 64)
+		perMonth, err2 := strconv.ParseInt(strings.TrimSpace(orders), 10, 64)
+		name = strings.TrimSpace(name)
+		if !ok || !found || name == "" || err1 != nil || err2 != nil || perDay < 0 || perMonth < 0 {
+			return Quotas{}, fmt.Errorf("invalid TENANT_PLANS entry: %q (want \"plan=requests_per_day/orders_per_month\")", entry)
+		}
+		quotas.Plans[name] = QuotaPlan{RequestsPerDay: perDay, OrdersPerMonth: perMonth}
+	}
+
+	for _, entry := range getEnvList("TENANT_PLAN_ASSIGNMENTS", nil) {
+		tenant, plan, ok := strings.Cut(entry, "=")
+		tenant, plan = strings.TrimSpace(tenant), strings.TrimSpace(plan)
+		if !ok || tenant == "" {
+			return Quotas{}, fmt.Errorf("invalid TENANT_PLAN_ASSIGNMENTS entry: %q (want \"tenant=plan\")", entry)
+		}
+		if _, known := quotas.Plans[plan]; !known {
+			return Quotas{}, fmt.Errorf("TENANT_PLAN_ASSIGNMENTS: tenant %q is on unknown plan %q", tenant, plan)
+		}
+		quotas.Tenants[tenant] = plan
+	}
+	if _, known := quotas.Plans[quotas.DefaultPlan]; quotas.DefaultPlan != "" && !known {
+		return Quotas{}, fmt.Errorf("TENANT_DEFAULT_PLAN: unknown plan %q", quotas.DefaultPlan)
+	}
+	return quotas, nil
+}
+
+// parseFeatures reads the FEATURE_* settings
+// FEATURE_ROUTES entries are "flag=/prefix|/other/prefix", FEATURE_FLAGS "flag=on|off" and
+// FEATURE_TENANT_FLAGS "tenant:flag=on|off"; flags can only be switched once they have routes
+func parseFeatures() (Features, error) {
+	features := Features{
+		Groups:  make(map[string][]string),
+		Enabled: make(map[string]bool),
+		Tenants: make(map[string]map[string]bool),
+	}
+
+	for _, entry := range getEnvList("FEATURE_ROUTES", nil) {
+		flag, prefixes, ok := strings.Cut(entry, "=")
+		flag = strings.TrimSpace(flag)
+		if !ok || flag == "" {
+			return Features{}, fmt.Errorf("invalid FEATURE_ROUTES entry: %q (want \"flag=/prefix|/other/prefix\")", entry)
+		}
+		for _, prefix := range strings.Split(prefixes, "|") {
+			prefix = strings.TrimSpace(prefix)
+			if !strings.HasPrefix(prefix, "/") || (len(prefix) > 1 && strings.HasSuffix(prefix, "/")) {
+				return Features{}, fmt.Errorf("invalid FEATURE_ROUTES entry: %q (prefixes start with / and don't end with one)", entry)
+			}
+			features.Groups[flag] = append(features.Groups[flag], prefix)
+		}
+	}
+
+	for _, entry := range getEnvList("FEATURE_FLAGS", nil) {
+		flag, on, err := parseFlag(features, entry)
+		if err != nil {
+			return Features{}, fmt.Errorf("FEATURE_FLAGS: %w", err)
+		}
+		features.Enabled[flag] = on
+	}
+
+	for _, entry := range getEnvList("FEATURE_TENANT_FLAGS", nil) {
+		tenant, setting, ok := strings.Cut(entry, ":")
+		tenant = strings.TrimSpace(tenant)
+		if !ok || tenant == "" {
+			return Features{}, fmt.Errorf("invalid FEATURE_TENANT_FLAGS entry: %q (want \"tenant:flag=on|off\")", entry)
+		}
+		flag, on, err := parseFlag(features, setting)
+		if err != nil {
+			return Features{}, fmt.Errorf("FEATURE_TENANT_FLAGS: %w", err)
+		}
+		if features.Tenants[tenant] == nil {
+			features.Tenants[tenant] = make(map[string]bool)
+		}
+		features.Tenants[tenant][flag] = on
+	}
+	return features, nil
+}
+
+// parseNetworks reads a comma-separated list of CIDRs; a bare address is a network of one
+func parseNetworks(name string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range getEnvList(name, nil) {
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: want a CIDR or an IP address", name, entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// parseOIDC reads the OIDC_* settings
+// OIDC_CLIENTS entries are "client_id=redirect_uri|redirect_uri", OIDC_CLIENT_SECRETS "client_id=secret"
+func parseOIDC() (OIDC, error) {
+	oidc := OIDC{
+		Issuer:     strings.TrimRight(strings.TrimSpace(getEnv("OIDC_ISSUER", "http://localhost:8085/api/v1/oidc")), "/"),
+		SigningKey: strings.TrimSpace(getEnv("OIDC_SIGNING_KEY", "")),
+		LoginURL:   strings.TrimSpace(getEnv("OIDC_LOGIN_URL", "")),
+		CodeTTL:    getEnvDuration("OIDC_CODE_TTL", time.Minute),
+		TokenTTL:   getEnvDuration("OIDC_TOKEN_TTL", time.Hour),
+		Clients:    make(map[string]OIDCClient),
+	}
+
+	for _, entry := range getEnvList("OIDC_CLIENTS", nil) {
+		id, uris, ok := strings.Cut(entry, "=")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return OIDC{}, fmt.Errorf("invalid OIDC_CLIENTS entry: %q (want \"client_id=redirect_uri|redirect_uri\")", entry)
+		}
+		var client OIDCClient
+		for _, uri := range strings.Split(uris, "|") {
+			uri = strings.TrimSpace(uri)
+			parsed, err := url.Parse(uri)
+			if err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+				return OIDC{}, fmt.Errorf("OIDC_CLIENTS: client %q has an invalid redirect URI %q", id, uri)
+			}
+			client.RedirectURIs = append(client.RedirectURIs, uri)
+		}
+		oidc.Clients[id] = client
+	}
+
+	for _, entry := range getEnvList("OIDC_CLIENT_SECRETS", nil) {
+		id, secret, ok := strings.Cut(entry, "=")
+		id, secret = strings.TrimSpace(id), strings.TrimSpace(secret)
+		client, known := oidc.Clients[id]
+		if !ok || secret == "" {
+			return OIDC{}, fmt.Errorf("invalid OIDC_CLIENT_SECRETS entry for %q (want \"client_id=secret\")", id)
+		}
+		if !known {
+			return OIDC{}, fmt.Errorf("OIDC_CLIENT_SECRETS: client %q has no OIDC_CLIENTS entry", id)
+		}
+		client.Secret = secret
+		oidc.Clients[id] = client
+	}
+
+	if len(oidc.Clients) > 0 && oidc.LoginURL == "" {
+		return OIDC{}, fmt.Errorf("OIDC_LOGIN_URL is required when OIDC_CLIENTS is set")
+	}
+	if oidc.CodeTTL <= 0 || oidc.TokenTTL <= 0 {
+		return OIDC{}, fmt.Errorf("OIDC_CODE_TTL and OIDC_TOKEN_TTL must be positive")
+	}
+	return oidc, nil
+}
+
+// parseFlag parses "flag=on" or "flag=off" (true/false and 1/0 work too) for a flag in FEATURE_ROUTES
+func parseFlag(features Features, entry string) (string, bool, error) {
+	flag, value, ok := strings.Cut(entry, "=")
+	flag = strings.TrimSpace(flag)
+	var on bool
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "on", "true", "1":
+		on = true
+	case "off", "false", "0":
+	default:
+		ok = false
+	}
+	if !ok || flag == "" {
+		return "", false, fmt.Errorf("invalid entry %q (want \"flag=on\" or \"flag=off\")", entry)
+	}
+	if _, known := features.Groups[flag]; !known {
+		return "", false, fmt.Errorf("flag %q has no FEATURE_ROUTES entry", flag)
+	}
+	return flag, on, nil
+}
+
+// defaultRouteTimeouts keeps logins snappy and gives exports time to stream
+var defaultRouteTimeouts = []string{
+	"POST /login=5s",
+	"GET /admin/users/export=10m",
+	"GET /admin/products/export=10m",
+	"GET /admin/orders/export=10m",
+}
+
+// parseRouteTimeouts parses ROUTE_TIMEOUTS entries such as "POST /login=2s" or "/admin/import=30s"
+func parseRouteTimeouts(entries []string) (map[string]time.Duration, error) {
+	routes := make(map[string]time.Duration, len(entries))
+	for _, entry := range entries {
+		route, raw, ok := strings.Cut(entry, "=")
+		route = strings.Join(strings.Fields(route), " ")
+		timeout, err := time.ParseDuration(strings.TrimSpace(raw))
+		if !ok || err != nil || timeout < 0 || !strings.Contains(route, "/") {
+			return nil, fmt.Errorf("invalid ROUTE_TIMEOUTS entry: %q (want \"METHOD /path=duration\")", entry)
+		}
+		if method, path, found := strings.Cut(route, " "); found {
+			route = strings.ToUpper(method) + " " + path
+		}
+		routes[route] = timeout
+	}
+	return routes, nil
+}
+
 // loadDotEnvFromRoot searches for and loads .env file from project root
 // Walks up directory tree until .env is found or filesystem root is reached
 // Handles running from any subdirectory (cmd/, internal/, etc.)
@@ -112,32 +1121,117 @@ func loadDotEnvFromRoot() error {
 	return nil
 }
 
+// EnableDevMode switches cfg to a self-contained local setup for `serve --dev`:
+// in-memory SQLite, in-process cache/events/jobs, log email/SMS, sandbox payments and
+// local storage. External integrations are turned off so nothing needs credentials.
+// Never use it in production - see auth.TokenManager.EnableDevIdentities.
+func (c *Config) EnableDevMode() {
+	c.DevMode = true
+
+	c.Database = Database{
+		Type:          "sqlite",
+		PrepareStmt:   c.Database.PrepareStmt,
+		StmtCacheSize: c.Database.StmtCacheSize,
+		StmtCacheTTL:  c.Database.StmtCacheTTL,
+
+		BreakerFailures: c.Database.BreakerFailures,
+		BreakerCooldown: c.Database.BreakerCooldown,
+	}
+	c.Redis.Enabled = false
+	c.Events.Backend = "memory"
+	c.Jobs.Backend = "db"
+	c.Jobs.RunInAPI = true
+	c.Keycloak.SyncEnabled = false
+
+	if c.Auth.JWTSecret == "" {
+		c.Auth.JWTSecret = "ecomgo-dev-secret"
+	}
+	c.Auth.TokenTTL = 30 * 24 * time.Hour
+
+	c.Email.Provider = "log"
+	c.SMS.Provider = "log"
+	c.Payment.Provider = "sandbox"
+	c.Payouts.Provider = "sandbox"
+	c.Fiscal.Provider = "sandbox"
+	if c.Payment.WebhookSecret == "" {
+		c.Payment.WebhookSecret = "ecomgo-dev-webhook-secret"
+	}
+	c.FX.Provider = "static"
+	c.Address.Provider = "none"
+	c.Shipping.Carriers = []string{"manual"}
+	c.Storage.Backend = "local"
+	c.Search.Backend = "none"
+	c.Fraud.Provider = "rules"
+	c.Captcha.Provider = "none"
+	if c.GeoIP.Provider == "maxmind" {
+		c.GeoIP.Provider = "header"
+	}
+}
+
 // GetDSN builds database connection string from config
 // Returns: DSN string formatted for specific database type
+// Credentials and names are escaped, so passwords may contain @, /, :, spaces or quotes
 func (db Database) GetDSN() string {
 	switch db.Type {
 	case "mysql":
-		return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local&allowNativePasswords=true",
-			db.User,
-			db.Password,
-			db.Host,
-			db.Port,
-			db.Name,
-		)
+		cfg := mysql.NewConfig()
+		cfg.User = db.User
+		cfg.Passwd = db.Password
+		cfg.Net = "tcp"
+		cfg.Addr = net.JoinHostPort(db.Host, db.Port) // brackets IPv6 hosts
+		cfg.DBName = db.Name
+		cfg.ParseTime = true
+		cfg.Loc = time.UTC // DATETIME columns hold UTC
+		cfg.Params = map[string]string{"time_zone": "'+00:00'"}
+		cfg.AllowNativePasswords = true
+		cfg.Params["charset"] = "utf8mb4"
+		return cfg.FormatDSN()
+	case "sqlite":
+		// Name is a file path; empty means a private in-memory database shared by the pool
+		if db.Name == "" || db.Name == ":memory:" {
+			return "file:ecomgo?mode=memory&cache=shared&_pragma=busy_timeout(5000)"
+		}
+		return "file:" + db.Name + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
 	case "postgres":
-		return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-			db.Host,
-			db.Port,
-			db.User,
-			db.Password,
-			db.Name,
-			db.SSLMode,
-		)
+		return strings.Join([]string{
+			"host=" + quotePostgresValue(db.Host),
+			"port=" + quotePostgresValue(db.Port),
+			"user=" + quotePostgresValue(db.User),
+			"password=" + quotePostgresValue(db.Password),
+			"dbname=" + quotePostgresValue(db.Name),
+			"sslmode=" + quotePostgresValue(db.SSLMode),
+			"statement_cache_capacity=" + strconv.Itoa(db.StmtCacheSize),
+			"default_query_exec_mode=" + db.postgresExecMode(),
+			"timezone=UTC",
+		}, " ")
 	default:
 		return ""
 	}
 }
 
+// postgresExecMode picks how pgx runs queries: cached prepared statements unless disabled
+// exec still uses the extended protocol (typed parameters) but prepares nothing server-side
+func (db Database) postgresExecMode() string {
+	if db.PGExecMode != "" {
+		return db.PGExecMode
+	}
+	if db.PrepareStmt && db.StmtCacheSize > 0 {
+		return "cache_statement"
+	}
+	return "exec"
+}
+
+// quotePostgresValue quotes a libpq keyword/value when needed:
+// empty values and values with spaces, quotes or backslashes are wrapped in single quotes
+// with ' and \ backslash-escaped
+func quotePostgresValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`+"\t\n\v\f\r") {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + replacer.Replace(value) + "'"
+}
+
 // getEnv retrieves environment variable with fallback default
 // Returns: env var value if set, otherwise default
 func getEnv(key, defaultValue string) string {
@@ -146,3 +1240,60 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvBool retrieves boolean environment variable with fallback default
+// Accepts: 1, t, true, 0, f, false (case-insensitive); invalid values use default
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvInt retrieves integer environment variable with fallback default
+// Returns: default if unset or not a valid integer
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvFloat retrieves floating point environment variable with fallback default
+// Returns: default if unset or not a valid number
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv(key)), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvList retrieves comma-separated environment variable with fallback default
+// Returns: trimmed, non-empty items; default if unset
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvDuration retrieves duration environment variable (e.g. "15m", "24h") with fallback default
+// Returns: default if unset or not a valid duration
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(strings.TrimSpace(os.Getenv(key)))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
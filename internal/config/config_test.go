@@ -0,0 +1,61 @@
+package config
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// FuzzGetDSN checks that credentials survive GetDSN: whatever the user, password, database and
+// host, the drivers parse the DSN back to the same values instead of silently connecting as
+// someone else or to another database
+func FuzzGetDSN(f *testing.F) {
+	f.Add("ecomgo", "s3cr3t", "ecomgo", "localhost")
+	f.Add("app user", `p@ss:w/rd?x=1&y='2' \ "q"`, "shop db", "db.internal")
+	f.Add("o'brien", "tab\tnew\nline", "naïve", "::1")
+	f.Add("root", "=starts=with=equals", "a/b?c#d", "127.0.0.1")
+
+	f.Fuzz(func(t *testing.T, user, password, name, host string) {
+		// Neither protocol can carry NUL in its startup message
+		if strings.ContainsRune(user+password+name+host, 0) {
+			t.Skip()
+		}
+		db := Database{User: user, Password: password, Name: name, Host: host, Port: "5432", SSLMode: "disable"}
+
+		// The MySQL DSN has no escape for ':' in user names and no password without a user, and
+		// host names never contain the characters that delimit the address
+		if user != "" && !strings.Contains(user, ":") && !strings.ContainsAny(host, "()/@[]") {
+			db.Type = "mysql"
+			cfg, err := mysql.ParseDSN(db.GetDSN())
+			if err != nil {
+				t.Fatalf("mysql.ParseDSN(%q): %v", db.GetDSN(), err)
+			}
+			if cfg.User != user || cfg.Passwd != password || cfg.DBName != name {
+				t.Fatalf("mysql round trip = %q/%q/%q, want %q/%q/%q", cfg.User, cfg.Passwd, cfg.DBName, user, password, name)
+			}
+			if want := net.JoinHostPort(host, db.Port); cfg.Addr != want {
+				t.Fatalf("mysql addr = %q, want %q", cfg.Addr, want)
+			}
+		}
+
+		// pgconn fills empty settings from PG* variables and defaults, and reads a comma in
+		// host as a list of fallback hosts
+		if user == "" || password == "" || name == "" || host == "" || strings.Contains(host, ",") {
+			return
+		}
+		db.Type = "postgres"
+		cfg, err := pgconn.ParseConfig(db.GetDSN())
+		if err != nil {
+			t.Fatalf("pgconn.ParseConfig(%q): %v", db.GetDSN(), err)
+		}
+		if cfg.User != user || cfg.Password != password || cfg.Database != name {
+			t.Fatalf("postgres round trip = %q/%q/%q, want %q/%q/%q", cfg.User, cfg.Password, cfg.Database, user, password, name)
+		}
+		if cfg.Host != host {
+			t.Fatalf("postgres host = %q, want %q", cfg.Host, host)
+		}
+	})
+}
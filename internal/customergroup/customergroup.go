@@ -0,0 +1,171 @@
+// Package customergroup resolves the customer group of a shopper (retail, wholesale, VIP...) and
+// what it changes about pricing: the group's price list and its tax treatment. A customer's own
+// tax exemption overrides their group's treatment. Each group's rules and each customer's
+// membership are cached, invalidated when an admin changes them.
+package customergroup
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// rulesTTL bounds how long a change made outside the API takes to show up;
+	// changes through the admin endpoints invalidate the cache immediately
+	rulesTTL = 10 * time.Minute
+)
+
+// Pricing serves per-group price lists
+// Lookups fall back to retail when the rules can't be loaded: a cache or database outage
+// must not take the storefront down with it
+type Pricing struct {
+	repo     *repository.CustomerGroupRepository
+	profiles *repository.TaxProfileRepository
+	cache    *cache.Loader
+	clock    clock.Clock
+	log      *zap.Logger
+}
+
+func NewPricing(repo *repository.CustomerGroupRepository, profiles *repository.TaxProfileRepository, c cache.Cache,
+	clk clock.Clock, log *zap.Logger) *Pricing {
+	return &Pricing{repo: repo, profiles: profiles, cache: cache.NewLoader(c), clock: clk, log: log}
+}
+
+// snapshot is what is cached per group
+type snapshot struct {
+	Found        bool                        `json:"found"`
+	TaxTreatment string                      `json:"tax_treatment"`
+	Prices       []models.CustomerGroupPrice `json:"prices"`
+}
+
+// membership is what is cached per customer
+// The exemption's end date is kept rather than whether it is in force, so it lapses on time
+type membership struct {
+	Group       string     `json:"group"`
+	VATID       string     `json:"vat_id,omitempty"`
+	Exempt      bool       `json:"exempt,omitempty"`
+	ExemptUntil *time.Time `json:"exempt_until,omitempty"`
+}
+
+// exemptAt reports whether the customer's tax exemption is in force at now
+func (m *membership) exemptAt(now time.Time) bool {
+	return m.Exempt && (m.ExemptUntil == nil || now.Before(*m.ExemptUntil))
+}
+
+func rulesKey(code string) string {
+	return "customer_groups:rules:" + code
+}
+
+func memberKey(userID string) string {
+	return "customer_groups:member:" + userID
+}
+
+// Rules is one group's view of prices; use one per request
+type Rules struct {
+	Group        string
+	TaxTreatment string
+	VATID        string // the customer's VAT/TIN number, for the order's tax records; set by ForUser
+	prices       map[string]int64
+}
+
+// retail is the view of customers without a group, and of anonymous shoppers
+func retail() Rules {
+	return Rules{Group: models.GroupRetail, TaxTreatment: models.TaxInclusive}
+}
+
+// ForUser returns the rules of userID's group; "" (anonymous) and customers without a group get retail
+// Customers with a tax exemption in force get the exempt tax treatment
+func (p *Pricing) ForUser(ctx context.Context, userID string) Rules {
+	if userID == "" {
+		return retail()
+	}
+	member, err := cache.LoadJSON(ctx, p.cache, memberKey(userID), rulesTTL, func(ctx context.Context) (*membership, error) {
+		code, err := p.repo.GroupOf(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		member := &membership{Group: code}
+		profile, err := p.profiles.Get(ctx, userID)
+		switch {
+		case errors.Is(err, repository.ErrTaxProfileNotFound):
+		case err != nil:
+			return nil, err
+		default:
+			member.VATID, member.Exempt, member.ExemptUntil = profile.VATID, profile.Exempt, profile.ExemptUntil
+		}
+		return member, nil
+	})
+	if err != nil {
+		p.log.Warn("Failed to load customer group, using retail prices", zap.String("user_id", userID), zap.Error(err))
+		return retail()
+	}
+
+	code := member.Group
+	if code == "" {
+		code = models.GroupRetail
+	}
+	rules := p.Resolve(ctx, code)
+	rules.VATID = member.VATID
+	if member.exemptAt(p.clock.Now()) {
+		rules.TaxTreatment = models.TaxExempt
+	}
+	return rules
+}
+
+// Resolve returns the rules of the group named code
+// A group that no longer exists prices like retail
+func (p *Pricing) Resolve(ctx context.Context, code string) Rules {
+	snap, err := cache.LoadJSON(ctx, p.cache, rulesKey(code), rulesTTL, func(ctx context.Context) (*snapshot, error) {
+		group, err := p.repo.Get(ctx, code)
+		if errors.Is(err, repository.ErrCustomerGroupNotFound) {
+			return &snapshot{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		prices, err := p.repo.Prices(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		return &snapshot{Found: true, TaxTreatment: group.TaxTreatment, Prices: prices}, nil
+	})
+	if err != nil {
+		p.log.Warn("Failed to load customer group prices, using retail prices", zap.String("group", code), zap.Error(err))
+		return retail()
+	}
+	if !snap.Found {
+		return retail()
+	}
+
+	rules := Rules{Group: code, TaxTreatment: snap.TaxTreatment, prices: make(map[string]int64, len(snap.Prices))}
+	for _, price := range snap.Prices {
+		rules.prices[price.ProductID] = price.Price
+	}
+	return rules
+}
+
+// Price returns productID's price for the group: its group price, or price (the list or channel price)
+// Flash sale prices are applied to the result, see campaign.Prices.Sale
+func (r Rules) Price(productID string, price int64) int64 {
+	if groupPrice, ok := r.prices[productID]; ok {
+		return groupPrice
+	}
+	return price
+}
+
+// Invalidate drops a group's cached rules after it or its price list change
+func (p *Pricing) Invalidate(ctx context.Context, code string) error {
+	return p.cache.Invalidate(ctx, rulesKey(code))
+}
+
+// InvalidateMember drops a customer's cached group after they are moved or their tax profile changes
+func (p *Pricing) InvalidateMember(ctx context.Context, userID string) error {
+	return p.cache.Invalidate(ctx, memberKey(userID))
+}
@@ -0,0 +1,199 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/response"
+	"gorm.io/gorm"
+)
+
+// ErrUnavailable is returned without querying while the database circuit is open
+var ErrUnavailable = errors.New("database unavailable")
+
+// Circuit watches every query for signs of a database outage; InitDatabase configures it
+// While it is open, queries fail fast with ErrUnavailable instead of each request waiting out
+// connection timeouts, and the API answers 503 (see Middleware)
+var Circuit = &Breaker{}
+
+// Breaker opens after consecutive connection failures and stays open for a cooldown; after that
+// queries go through again and the first one to fail reopens it
+// Query errors the database answered with (not found, constraint violations) don't count.
+type Breaker struct {
+	mu           sync.Mutex
+	failureLimit int
+	cooldown     time.Duration
+	failures     int       // consecutive
+	openUntil    time.Time // zero while closed
+}
+
+// Configure sets when the breaker opens and for how long; failureLimit 0 disables it
+func (b *Breaker) Configure(failureLimit int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failureLimit, b.cooldown = failureLimit, cooldown
+}
+
+// Open tells whether queries currently fail fast
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Now().Before(b.openUntil)
+}
+
+// retryAfter returns how long until queries are tried again
+func (b *Breaker) retryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Until(b.openUntil)
+}
+
+// record counts the outcome of a query
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil || !IsOutage(err) {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		return
+	}
+	b.failures++
+	if b.failureLimit > 0 && b.failures >= b.failureLimit {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// IsOutage tells whether err means the database couldn't be reached, rather than it refusing a query
+// Deadlines count: a database too slow to answer within the request budget is as good as down
+func IsOutage(err error) bool {
+	if errors.Is(err, ErrUnavailable) || errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, sign := range []string{"connection refused", "broken pipe", "bad connection", "connection reset",
+		"too many connections", "the database system is shutting down", "server closed the connection"} {
+		if strings.Contains(msg, sign) {
+			return true
+		}
+	}
+	return false
+}
+
+// register hooks the breaker into every GORM operation
+func (b *Breaker) register(db *gorm.DB) error {
+	before := func(tx *gorm.DB) {
+		if b.Open() {
+			// GORM's own callbacks skip the statement once the error is set
+			tx.AddError(ErrUnavailable)
+		}
+	}
+	after := func(tx *gorm.DB) {
+		if !errors.Is(tx.Error, ErrUnavailable) {
+			b.record(tx.Error)
+		}
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:begin_transaction").Register("ecomgo:breaker_before_create", before),
+		callbacks.Create().After("gorm:commit_or_rollback_transaction").Register("ecomgo:breaker_after_create", after),
+		callbacks.Query().Before("gorm:query").Register("ecomgo:breaker_before_query", before),
+		callbacks.Query().After("gorm:query").Register("ecomgo:breaker_after_query", after),
+		callbacks.Update().Before("gorm:begin_transaction").Register("ecomgo:breaker_before_update", before),
+		callbacks.Update().After("gorm:commit_or_rollback_transaction").Register("ecomgo:breaker_after_update", after),
+		callbacks.Delete().Before("gorm:begin_transaction").Register("ecomgo:breaker_before_delete", before),
+		callbacks.Delete().After("gorm:commit_or_rollback_transaction").Register("ecomgo:breaker_after_delete", after),
+		callbacks.Row().Before("gorm:row").Register("ecomgo:breaker_before_row", before),
+		callbacks.Row().After("gorm:row").Register("ecomgo:breaker_after_row", after),
+		callbacks.Raw().Before("gorm:raw").Register("ecomgo:breaker_before_raw", before),
+		callbacks.Raw().After("gorm:raw").Register("ecomgo:breaker_after_raw", after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Middleware answers 503 with the failing dependency instead of the 500 a handler writes while
+// the circuit is open, so clients and load balancers can tell an outage from a bug
+// Responses that don't fail (cached catalog reads) pass through untouched.
+func (b *Breaker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			next.ServeHTTP(w, r) // the upgrade needs the connection, not a wrapper
+			return
+		}
+		next.ServeHTTP(&outageWriter{ResponseWriter: w, breaker: b}, r)
+	})
+}
+
+// Unavailable is the body of a 503 caused by a dependency outage
+type Unavailable struct {
+	Error      string `json:"error"`
+	Dependency string `json:"dependency"`
+	RetryAfter int    `json:"retry_after"` // seconds
+}
+
+type outageWriter struct {
+	http.ResponseWriter
+	breaker  *Breaker
+	replaced bool
+	wrote    bool
+}
+
+func (w *outageWriter) WriteHeader(status int) {
+	if w.wrote {
+		return
+	}
+	w.wrote = true
+	if status != http.StatusInternalServerError || !w.breaker.Open() {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	w.replaced = true
+	retryAfter := int(w.breaker.retryAfter().Seconds()) + 1
+	w.Header().Del("Content-Length")
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	response.JSON(w.ResponseWriter, http.StatusServiceUnavailable, Unavailable{
+		Error:      "Service temporarily unavailable",
+		Dependency: "database",
+		RetryAfter: retryAfter,
+	})
+}
+
+func (w *outageWriter) Write(b []byte) (int, error) {
+	if !w.wrote {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.replaced {
+		return len(b), nil // the 503 body is already written
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush keeps streamed responses (exports) streaming
+func (w *outageWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *outageWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/glebarez/sqlite"
 	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
@@ -23,15 +24,8 @@ func InitDatabase(cfg *config.Config, log *zap.Logger) (*gorm.DB, error) {
 	switch cfg.Database.Type {
 	case "mysql":
 		// MySQL DSN format for GORM: username:password@protocol(address)/dbname?param=value
-		// IMPORTANT: Must not have spaces, all characters matter
-		dsn := fmt.Sprintf(
-			"%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local&allowNativePasswords=true",
-			cfg.Database.User,
-			cfg.Database.Password,
-			cfg.Database.Host,
-			cfg.Database.Port,
-			cfg.Database.Name,
-		)
+		// Built by GetDSN so special characters in the password can't break it
+		dsn := cfg.Database.GetDSN()
 
 		// Log masked DSN for debugging (never log real password)
 		log.Info("Connecting to MySQL",
@@ -44,16 +38,8 @@ func InitDatabase(cfg *config.Config, log *zap.Logger) (*gorm.DB, error) {
 		dialector = mysql.Open(dsn)
 
 	case "postgres":
-		// PostgreSQL connection string format
-		dsn := fmt.Sprintf(
-			"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-			cfg.Database.Host,
-			cfg.Database.Port,
-			cfg.Database.User,
-			cfg.Database.Password,
-			cfg.Database.Name,
-			cfg.Database.SSLMode,
-		)
+		// PostgreSQL keyword/value connection string, values quoted by GetDSN
+		dsn := cfg.Database.GetDSN()
 
 		log.Info("Connecting to PostgreSQL",
 			zap.String("user", cfg.Database.User),
@@ -64,15 +50,29 @@ func InitDatabase(cfg *config.Config, log *zap.Logger) (*gorm.DB, error) {
 
 		dialector = postgres.Open(dsn)
 
+	case "sqlite":
+		// Development only (serve --dev): pure Go driver, no cgo or database server needed
+		log.Info("Using SQLite database", zap.String("database", cfg.Database.Name))
+		dialector = sqlite.Open(cfg.Database.GetDSN())
+
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", cfg.Database.Type)
 	}
 
 	// Connect to database with GORM
-	// GORM handles connection pooling, prepared statements, etc.
-	db, err := gorm.Open(dialector, &gorm.Config{
-		Logger: &GormLogger{log: log},
-	})
+	// GORM handles connection pooling; prepared statements are cached per DB_PREPARE_STMT
+	// Postgres is left to pgx's statement cache (configured in the DSN) so statements aren't prepared twice
+	gormConfig := &gorm.Config{
+		Logger:  &GormLogger{log: log},
+		NowFunc: nowUTC,
+	}
+	if cfg.Database.PrepareStmt && cfg.Database.Type != "postgres" {
+		// Hot lookups (user by email, product detail) then cost one round trip instead of prepare+exec+close
+		gormConfig.PrepareStmt = true
+		gormConfig.PrepareStmtMaxSize = cfg.Database.StmtCacheSize
+		gormConfig.PrepareStmtTTL = cfg.Database.StmtCacheTTL
+	}
+	db, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
 		log.Error("Failed to connect to database",
 			zap.Error(err),
@@ -82,7 +82,18 @@ func InitDatabase(cfg *config.Config, log *zap.Logger) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	log.Info("Database connection established successfully")
+	if err := registerUTC(db); err != nil {
+		return nil, err
+	}
+	Circuit.Configure(cfg.Database.BreakerFailures, cfg.Database.BreakerCooldown)
+	if err := Circuit.register(db); err != nil {
+		return nil, err
+	}
+
+	log.Info("Database connection established successfully",
+		zap.Bool("prepare_stmt", cfg.Database.PrepareStmt),
+		zap.Int("stmt_cache_size", cfg.Database.StmtCacheSize),
+	)
 
 	// Get underlying SQL database and set connection pool settings
 	sqlDB, err := db.DB()
@@ -97,6 +108,11 @@ func InitDatabase(cfg *config.Config, log *zap.Logger) (*gorm.DB, error) {
 	sqlDB.SetMaxOpenConns(25)
 	sqlDB.SetMaxIdleConns(5)
 	sqlDB.SetConnMaxLifetime(5 * time.Minute)
+	if cfg.Database.Type == "sqlite" {
+		// SQLite allows one writer; a single connection also keeps the in-memory database alive
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+	}
 
 	return db, nil
 }
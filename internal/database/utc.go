@@ -0,0 +1,67 @@
+package database
+
+import (
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	timePtrType = reflect.TypeOf(&time.Time{})
+)
+
+// nowUTC is GORM's clock for autoCreateTime/autoUpdateTime columns
+// Millisecond precision matches the columns (datetime(3) on MySQL), so a record
+// serializes the same in the response that created it as when it is read back
+func nowUTC() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// registerUTC converts the time fields of every loaded model to UTC
+// Drivers return times in the session zone or, for SQLite, with the offset they were
+// written with; normalizing here means no module can serve a record with a local offset
+func registerUTC(db *gorm.DB) error {
+	return db.Callback().Query().After("gorm:query").Register("ecomgo:utc_times", func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Schema == nil {
+			return
+		}
+		rv := reflect.Indirect(tx.Statement.ReflectValue)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				toUTC(tx, reflect.Indirect(rv.Index(i)))
+			}
+		case reflect.Struct:
+			toUTC(tx, rv)
+		}
+	})
+}
+
+func toUTC(tx *gorm.DB, rv reflect.Value) {
+	schema := tx.Statement.Schema
+	if rv.Kind() != reflect.Struct || rv.Type() != schema.ModelType || !rv.CanAddr() {
+		return
+	}
+	for _, field := range schema.Fields {
+		if field.FieldType != timeType && field.FieldType != timePtrType {
+			continue
+		}
+		fv := field.ReflectValueOf(tx.Statement.Context, rv)
+		if !fv.CanSet() {
+			continue
+		}
+		switch v := fv.Addr().Interface().(type) {
+		case *time.Time:
+			if !v.IsZero() {
+				*v = v.UTC()
+			}
+		case **time.Time:
+			if *v != nil {
+				utc := (*v).UTC()
+				*v = &utc
+			}
+		}
+	}
+}
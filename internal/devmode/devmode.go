@@ -0,0 +1,97 @@
+// Package devmode seeds the demo data used by `serve --dev`, so frontend developers get
+// a working store (accounts, a stocked catalog, address book, a vendor) from an empty in-memory database.
+package devmode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/loadgen"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DemoPassword signs in every demo account
+const DemoPassword = "password"
+
+// demoProducts is the size of the seeded catalog
+const demoProducts = 60
+
+// Demo accounts, one per role
+var demoAccounts = []models.User{
+	{Email: "admin@example.com", FirstName: "Ada", LastName: "Admin", Role: models.RoleAdmin},
+	{Email: "customer@example.com", FirstName: "Wanjiku", LastName: "Customer", Role: models.RoleCustomer},
+	{Email: "vendor@example.com", FirstName: "Otieno", LastName: "Vendor", Role: models.RoleVendor},
+}
+
+// Seed creates the demo accounts, a catalog, the customer's address book and the vendor's profile
+// Returns: the claims token-less requests act as, by role (see auth.TokenManager.EnableDevIdentities)
+func Seed(ctx context.Context, db *gorm.DB, currency string, log *zap.Logger) (map[string]*auth.Claims, error) {
+	identities := make(map[string]*auth.Claims)
+	var customer, seller models.User
+	for _, account := range demoAccounts {
+		user := account
+		user.PasswordHash = loadgen.PasswordHash(DemoPassword)
+		err := db.WithContext(ctx).Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "email"}}, DoNothing: true}).
+			Create(&user).Error
+		if err != nil {
+			return nil, fmt.Errorf("seed %s: %w", account.Email, err)
+		}
+		if err := db.WithContext(ctx).Where("email = ?", account.Email).First(&user).Error; err != nil {
+			return nil, err
+		}
+
+		claims := &auth.Claims{Email: user.Email, Role: user.Role}
+		claims.Subject = user.ID
+		identities[user.Role] = claims
+		switch user.Role {
+		case models.RoleCustomer:
+			customer = user
+		case models.RoleVendor:
+			seller = user
+		}
+	}
+
+	var addresses int64
+	if err := db.WithContext(ctx).Model(&models.Address{}).Where("user_id = ?", customer.ID).Count(&addresses).Error; err != nil {
+		return nil, err
+	}
+	if addresses == 0 {
+		err := db.WithContext(ctx).Create(&models.Address{
+			UserID:     customer.ID,
+			Label:      "Home",
+			Recipient:  customer.FirstName + " " + customer.LastName,
+			Phone:      "+254712345678",
+			Line1:      "Kenyatta Avenue 1",
+			City:       "Nairobi",
+			Region:     "Nairobi",
+			PostalCode: "00100",
+			Country:    "KE",
+			Zone:       "nairobi",
+			IsDefault:  true,
+		}).Error
+		if err != nil {
+			return nil, fmt.Errorf("seed address: %w", err)
+		}
+	}
+
+	err := db.WithContext(ctx).Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}}, DoNothing: true}).
+		Create(&models.Vendor{UserID: seller.ID, Name: "Otieno Crafts", Email: seller.Email,
+			PayoutAccount: "+254712345678", Status: models.VendorActive}).Error
+	if err != nil {
+		return nil, fmt.Errorf("seed vendor: %w", err)
+	}
+
+	if _, err := loadgen.Seed(ctx, db, loadgen.Options{Products: demoProducts, Currency: currency, Seed: 1}, log); err != nil {
+		return nil, err
+	}
+
+	for _, account := range demoAccounts {
+		log.Info("Demo account ready", zap.String("email", account.Email), zap.String("password", DemoPassword),
+			zap.String("role", account.Role))
+	}
+	return identities, nil
+}
@@ -0,0 +1,144 @@
+package disbursement
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/hmacsig"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+)
+
+// Bank sends payouts through a bank's transfer API (corporate banking or a payments gateway)
+// The contract is small on purpose so most gateways fit behind an adapter:
+//   - POST {PAYOUT_BANK_URL}/transfers with a bearer key and Idempotency-Key, answering
+//     {"id", "status": completed|pending|failed, "reason"}; 4xx means the transfer is refused
+//   - later results are POSTed to PAYOUT_CALLBACK_URL as {"reference", "id", "status", "reason"},
+//     signed with X-Signature (hex HMAC-SHA256 of the body with PAYOUT_CALLBACK_SECRET)
+type Bank struct {
+	baseURL       string
+	apiKey        string
+	callbackURL   string
+	webhookSecret string
+	client        *http.Client
+}
+
+func NewBank(cfg config.Payouts) *Bank {
+	return &Bank{
+		baseURL:       cfg.BankURL,
+		apiKey:        cfg.BankAPIKey,
+		callbackURL:   cfg.CallbackURL,
+		webhookSecret: cfg.CallbackSecret,
+		client:        httpclient.New(30 * time.Second),
+	}
+}
+
+func (b *Bank) Name() string {
+	return "bank"
+}
+
+func (b *Bank) Quantum(currency string) int64 {
+	return 1
+}
+
+// bankTransfer is the gateway's view of a transfer, in responses and callbacks
+type bankTransfer struct {
+	ID        string `json:"id"`
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason"`
+	Error     string `json:"error"`
+}
+
+func (b *Bank) Send(ctx context.Context, req Request) (*Result, error) {
+	account := strings.TrimSpace(req.Recipient.Account)
+	if account == "" {
+		return nil, rejected("bank: recipient has no payout account")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"reference":           req.ID,
+		"amount":              req.Amount,
+		"currency":            req.Currency,
+		"beneficiary_name":    req.Recipient.Name,
+		"beneficiary_account": account,
+		"narration":           req.Remarks,
+		"callback_url":        b.callbackURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, b.baseURL+"/transfers", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+b.apiKey)
+	httpReq.Header.Set("Idempotency-Key", req.ID)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("bank transfer %s: %w", req.ID, err)
+	}
+	defer resp.Body.Close()
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var out bankTransfer
+	_ = json.Unmarshal(detail, &out)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusTooManyRequests:
+		return nil, fmt.Errorf("bank transfer %s: status %d: %s", req.ID, resp.StatusCode, detail)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return nil, rejected("bank: %s", firstNonEmpty(out.Error, out.Reason, string(detail)))
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return nil, fmt.Errorf("bank transfer %s: status %d: %s", req.ID, resp.StatusCode, detail)
+	}
+
+	switch out.Status {
+	case "completed":
+		return &Result{Status: StatusPaid, Reference: out.ID}, nil
+	case "failed":
+		return nil, rejected("bank: %s", out.Reason)
+	default:
+		return &Result{Status: StatusPending, Reference: out.ID}, nil
+	}
+}
+
+// ParseCallback verifies X-Signature and reads the transfer result
+// Interim statuses (pending, processing) carry no result
+func (b *Bank) ParseCallback(r *http.Request, body []byte) (*Callback, error) {
+	if !hmacsig.Verify(b.webhookSecret, r.Header.Get("X-Signature"), body) {
+		return nil, ErrInvalidSignature
+	}
+
+	var payload bankTransfer
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	switch payload.Status {
+	case "completed":
+		return &Callback{ID: payload.Reference, Status: StatusPaid, Reference: payload.ID}, nil
+	case "failed":
+		return &Callback{ID: payload.Reference, Status: StatusFailed, Reference: payload.ID, Reason: payload.Reason}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
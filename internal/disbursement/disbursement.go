@@ -0,0 +1,114 @@
+// Package disbursement sends money out of the store: vendor payouts by M-Pesa B2C or bank
+// transfer. Providers may confirm later; their callbacks are parsed here and applied by the
+// vendor module.
+package disbursement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+)
+
+var (
+	// ErrRejected is returned when the provider refuses a transfer for good (bad recipient,
+	// unsupported currency); retrying won't help
+	ErrRejected = errors.New("transfer rejected")
+
+	// ErrInvalidSignature is returned when a provider callback fails authentication
+	ErrInvalidSignature = errors.New("invalid callback signature")
+)
+
+// Transfer statuses reported by providers
+const (
+	StatusPaid    = "paid"    // money sent
+	StatusPending = "pending" // accepted; the result arrives by callback (or admin confirmation)
+	StatusFailed  = "failed"
+)
+
+// Recipient is who the money goes to
+type Recipient struct {
+	Name    string
+	Phone   string // E.164, for mobile money
+	Account string // bank account details, free text as entered by the admin
+}
+
+// Request sends Amount to Recipient
+// Amounts are in minor units (cents) to avoid floating point rounding
+type Request struct {
+	ID        string // our payout ID; providers use it for idempotency and echo it in callbacks
+	Amount    int64
+	Currency  string
+	Recipient Recipient
+	Remarks   string // shown on the recipient's statement where supported
+}
+
+// Result is the provider's answer to a transfer request
+type Result struct {
+	Status    string // StatusPaid or StatusPending
+	Reference string // provider's transaction reference, may be empty while pending
+}
+
+// Callback is a provider's final word on a pending transfer
+type Callback struct {
+	ID        string // our payout ID
+	Status    string // StatusPaid or StatusFailed
+	Reference string
+	Reason    string // why it failed
+}
+
+// Provider sends transfers with one payment service
+type Provider interface {
+	// Name is the provider code stored on payouts and used in callback URLs (manual, mpesa, bank)
+	Name() string
+
+	// Quantum is the smallest amount the provider can send in currency, in minor units
+	// Payouts are rounded down to it; the remainder is carried to the next payout
+	Quantum(currency string) int64
+
+	// Send transfers req.Amount; it must be safe to repeat with the same req.ID
+	// Returns: ErrRejected when the transfer can never succeed, other errors are retried
+	Send(ctx context.Context, req Request) (*Result, error)
+
+	// ParseCallback authenticates a provider notification and normalizes it
+	// Returns: ErrInvalidSignature when authentication fails, nil when it carries no result
+	ParseCallback(r *http.Request, body []byte) (*Callback, error)
+}
+
+// New creates the provider selected by PAYOUT_PROVIDER
+// Returns: error if the provider is unknown or missing credentials
+func New(cfg config.Payouts) (Provider, error) {
+	switch cfg.Provider {
+	case "manual", "":
+		return NewManual(), nil
+	case "sandbox":
+		return NewSandbox(), nil
+	case "mpesa":
+		if cfg.MpesaConsumerKey == "" || cfg.MpesaConsumerSecret == "" || cfg.MpesaShortcode == "" ||
+			cfg.MpesaInitiator == "" || cfg.MpesaSecurityCredential == "" {
+			return nil, fmt.Errorf("MPESA_B2C_CONSUMER_KEY, MPESA_B2C_CONSUMER_SECRET, MPESA_B2C_SHORTCODE, " +
+				"MPESA_B2C_INITIATOR and MPESA_B2C_SECURITY_CREDENTIAL must be set for PAYOUT_PROVIDER=mpesa")
+		}
+		if cfg.CallbackURL == "" || cfg.CallbackSecret == "" {
+			return nil, fmt.Errorf("PAYOUT_CALLBACK_URL and PAYOUT_CALLBACK_SECRET must be set for PAYOUT_PROVIDER=mpesa")
+		}
+		if cfg.MpesaEnvironment != "sandbox" && cfg.MpesaEnvironment != "production" {
+			return nil, fmt.Errorf("unsupported MPESA_B2C_ENVIRONMENT: %s (must be 'sandbox' or 'production')", cfg.MpesaEnvironment)
+		}
+		return NewMpesa(cfg), nil
+	case "bank":
+		if cfg.BankURL == "" || cfg.BankAPIKey == "" {
+			return nil, fmt.Errorf("PAYOUT_BANK_URL and PAYOUT_BANK_API_KEY must be set for PAYOUT_PROVIDER=bank")
+		}
+		return NewBank(cfg), nil
+	default:
+		return nil, fmt.Errorf("unsupported PAYOUT_PROVIDER: %s (must be 'manual', 'sandbox', 'mpesa' or 'bank')", cfg.Provider)
+	}
+}
+
+// rejected wraps a provider's refusal in ErrRejected
+func rejected(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrRejected, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,233 @@
+package disbursement
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+)
+
+const (
+	mpesaSandboxURL    = "https://sandbox.safaricom.co.ke"
+	mpesaProductionURL = "https://api.safaricom.co.ke"
+)
+
+// Mpesa sends payouts to the recipient's phone with Safaricom's M-Pesa B2C API (Daraja)
+// B2C pays whole shillings in KES only. Results arrive on PAYOUT_CALLBACK_URL, which carries
+// PAYOUT_CALLBACK_SECRET as the token query parameter because Daraja doesn't sign callbacks
+type Mpesa struct {
+	baseURL     string
+	key         string
+	secret      string
+	shortcode   string
+	initiator   string
+	credential  string
+	callbackURL string // result URL; the queue timeout URL adds timeout=1
+	token       string // callback token
+	client      *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expires     time.Time
+}
+
+func NewMpesa(cfg config.Payouts) *Mpesa {
+	baseURL := mpesaSandboxURL
+	if cfg.MpesaEnvironment == "production" {
+		baseURL = mpesaProductionURL
+	}
+
+	return &Mpesa{
+		baseURL:     baseURL,
+		key:         cfg.MpesaConsumerKey,
+		secret:      cfg.MpesaConsumerSecret,
+		shortcode:   cfg.MpesaShortcode,
+		initiator:   cfg.MpesaInitiator,
+		credential:  cfg.MpesaSecurityCredential,
+		callbackURL: cfg.CallbackURL,
+		token:       cfg.CallbackSecret,
+		client:      httpclient.New(30 * time.Second),
+	}
+}
+
+func (m *Mpesa) Name() string {
+	return "mpesa"
+}
+
+// Quantum is one shilling: B2C amounts are whole numbers
+func (m *Mpesa) Quantum(currency string) int64 {
+	if currency == "KES" {
+		return 100
+	}
+	return 1
+}
+
+// authenticate returns a cached OAuth access token, fetching a new one shortly before expiry
+func (m *Mpesa) authenticate(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.accessToken != "" && time.Now().Before(m.expires) {
+		return m.accessToken, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.baseURL+"/oauth/v1/generate?grant_type=client_credentials", nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(m.key, m.secret)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("mpesa auth: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("mpesa auth: status %d: %s", resp.StatusCode, detail)
+	}
+
+	var out struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   string `json:"expires_in"` // seconds, as a string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("mpesa auth: decode: %w", err)
+	}
+	seconds, _ := strconv.Atoi(out.ExpiresIn)
+	if seconds <= 60 {
+		seconds = 3600
+	}
+
+	m.accessToken = out.AccessToken
+	m.expires = time.Now().Add(time.Duration(seconds-60) * time.Second)
+	return m.accessToken, nil
+}
+
+func (m *Mpesa) Send(ctx context.Context, req Request) (*Result, error) {
+	switch {
+	case req.Currency != "KES":
+		return nil, rejected("mpesa: B2C pays KES only, not %s", req.Currency)
+	case req.Amount < 100:
+		return nil, rejected("mpesa: amount must be at least KES 1")
+	case req.Recipient.Phone == "":
+		return nil, rejected("mpesa: recipient has no phone number")
+	}
+
+	timeoutURL := m.callbackURL + "?" + url.Values{"token": {m.token}, "timeout": {"1"}}.Encode()
+	resultURL := m.callbackURL + "?" + url.Values{"token": {m.token}}.Encode()
+	body, err := json.Marshal(map[string]interface{}{
+		"OriginatorConversationID": req.ID,
+		"InitiatorName":            m.initiator,
+		"SecurityCredential":       m.credential,
+		"CommandID":                "BusinessPayment",
+		"Amount":                   req.Amount / 100,
+		"PartyA":                   m.shortcode,
+		"PartyB":                   strings.TrimPrefix(req.Recipient.Phone, "+"),
+		"Remarks":                  truncate(req.Remarks, 100),
+		"QueueTimeOutURL":          timeoutURL,
+		"ResultURL":                resultURL,
+		"Occassion":                truncate(req.ID, 100), // sic, Daraja's spelling
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := m.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.baseURL+"/mpesa/b2c/v3/paymentrequest", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("mpesa b2c %s: %w", req.ID, err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		ConversationID      string `json:"ConversationID"`
+		ResponseCode        string `json:"ResponseCode"`
+		ResponseDescription string `json:"ResponseDescription"`
+		ErrorMessage        string `json:"errorMessage"`
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	_ = json.Unmarshal(detail, &out)
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		m.mu.Lock()
+		m.accessToken = "" // expired early; the retry fetches a new one
+		m.mu.Unlock()
+		return nil, fmt.Errorf("mpesa b2c %s: access token rejected", req.ID)
+	case resp.StatusCode == http.StatusBadRequest:
+		return nil, rejected("mpesa: %s", out.ErrorMessage)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("mpesa b2c %s: status %d: %s", req.ID, resp.StatusCode, detail)
+	case out.ResponseCode != "0":
+		return nil, rejected("mpesa: %s", out.ResponseDescription)
+	}
+
+	return &Result{Status: StatusPending, Reference: out.ConversationID}, nil
+}
+
+// mpesaResult is Daraja's B2C result callback
+type mpesaResult struct {
+	Result struct {
+		ResultCode               int    `json:"ResultCode"`
+		ResultDesc               string `json:"ResultDesc"`
+		OriginatorConversationID string `json:"OriginatorConversationID"`
+		TransactionID            string `json:"TransactionID"`
+	} `json:"Result"`
+}
+
+// ParseCallback checks the token query parameter and reads the transfer result
+// Queue timeouts carry no result - the transfer may still go through - so they are ignored
+// and the payout stays pending until an admin settles it
+func (m *Mpesa) ParseCallback(r *http.Request, body []byte) (*Callback, error) {
+	token := r.URL.Query().Get("token")
+	if m.token == "" || !hmac.Equal([]byte(token), []byte(m.token)) {
+		return nil, ErrInvalidSignature
+	}
+	if r.URL.Query().Get("timeout") != "" {
+		return nil, nil
+	}
+
+	var payload mpesaResult
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	result := payload.Result
+	if result.OriginatorConversationID == "" {
+		return nil, nil
+	}
+
+	if result.ResultCode != 0 {
+		return &Callback{ID: result.OriginatorConversationID, Status: StatusFailed, Reason: result.ResultDesc}, nil
+	}
+	return &Callback{ID: result.OriginatorConversationID, Status: StatusPaid, Reference: result.TransactionID}, nil
+}
+
+// truncate cuts s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
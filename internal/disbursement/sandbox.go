@@ -0,0 +1,62 @@
+package disbursement
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// SandboxRejectAccount is a payout account the sandbox refuses, to exercise failed payouts
+const SandboxRejectAccount = "reject"
+
+// Sandbox stands in for a mobile money or bank transfer API: every transfer is paid at once, so
+// no callback follows, and sending a payout again returns its first reference.
+// Give the vendor no phone or payout account, or the account SandboxRejectAccount, to have the
+// transfer rejected and the payout failed.
+type Sandbox struct {
+	mu   sync.Mutex
+	sent map[string]string // payout ID -> reference, so repeats don't pay twice
+}
+
+func NewSandbox() *Sandbox {
+	return &Sandbox{sent: make(map[string]string)}
+}
+
+func (s *Sandbox) Name() string {
+	return "sandbox"
+}
+
+func (s *Sandbox) Quantum(currency string) int64 {
+	return 1
+}
+
+func (s *Sandbox) Send(ctx context.Context, req Request) (*Result, error) {
+	account := strings.TrimSpace(req.Recipient.Account)
+	switch {
+	case req.Amount <= 0:
+		return nil, rejected("sandbox: amount must be positive")
+	case account == "" && req.Recipient.Phone == "":
+		return nil, rejected("sandbox: recipient has no payout account or phone")
+	case strings.EqualFold(account, SandboxRejectAccount):
+		return nil, rejected("sandbox: account rejected")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reference, ok := s.sent[req.ID]
+	if !ok {
+		buf := make([]byte, 6)
+		_, _ = rand.Read(buf)
+		reference = "sbx_" + hex.EncodeToString(buf)
+		s.sent[req.ID] = reference
+	}
+	return &Result{Status: StatusPaid, Reference: reference}, nil
+}
+
+// ParseCallback rejects every callback - sandbox transfers complete immediately
+func (s *Sandbox) ParseCallback(r *http.Request, body []byte) (*Callback, error) {
+	return nil, ErrInvalidSignature
+}
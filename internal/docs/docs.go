@@ -65,7 +65,7 @@ const SwaggerInfo = `{
     "/users/{id}": {
       "get": {
         "summary": "Get user",
-        "description": "Retrieves user data by ID",
+        "description": "Retrieves a user's public profile by ID; the user themselves and admins get the full account",
         "parameters": [
           {
             "in": "path",
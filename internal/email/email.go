@@ -0,0 +1,46 @@
+package email
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"go.uber.org/zap"
+)
+
+// Message is a fully rendered email ready to hand to a provider
+type Message struct {
+	To      string
+	ToName  string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Sender delivers a single message through an email provider
+// Implementations: SMTP (also Amazon SES SMTP interface), SendGrid, log (development)
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender creates the sender selected by EMAIL_PROVIDER
+// Returns: error if the provider is unknown or misconfigured
+func NewSender(cfg config.Email, log *zap.Logger) (Sender, error) {
+	switch cfg.Provider {
+	case "smtp", "ses":
+		// SES is used through its SMTP interface (email-smtp.<region>.amazonaws.com)
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST must be set for EMAIL_PROVIDER=%s", cfg.Provider)
+		}
+		return NewSMTPSender(cfg), nil
+	case "sendgrid":
+		if cfg.SendGridAPIKey == "" {
+			return nil, fmt.Errorf("SENDGRID_API_KEY must be set for EMAIL_PROVIDER=sendgrid")
+		}
+		return NewSendGridSender(cfg), nil
+	case "log", "":
+		return NewLogSender(log), nil
+	default:
+		return nil, fmt.Errorf("unsupported EMAIL_PROVIDER: %s (must be 'smtp', 'ses', 'sendgrid' or 'log')", cfg.Provider)
+	}
+}
@@ -0,0 +1,26 @@
+package email
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// LogSender writes emails to the application log instead of sending them
+// Default provider for local development - no credentials needed, nothing leaves the machine
+type LogSender struct {
+	log *zap.Logger
+}
+
+func NewLogSender(log *zap.Logger) *LogSender {
+	return &LogSender{log: log}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	s.log.Info("Email (log provider, not sent)",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("text", msg.Text),
+	)
+	return nil
+}
@@ -0,0 +1,120 @@
+package email
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+)
+
+// JobSend is the background job type that delivers one rendered message
+const JobSend = "email.send"
+
+const sendTimeout = 30 * time.Second
+
+// Branding supplies the store details every template can show (AppName, SupportEmail)
+// Implemented by settings.Store, so admins can rename the store without a restart
+type Branding interface {
+	StoreName(ctx context.Context) string
+	SupportEmail(ctx context.Context) string
+}
+
+// Mailer renders templated emails and sends them through the configured provider
+// SendAsync keeps provider latency off the request path
+type Mailer struct {
+	sender    Sender
+	templates *TemplateStore
+	branding  Branding
+	jobs      *jobs.Processor
+	log       *zap.Logger
+}
+
+// NewMailer registers the email.send job handler
+// Queued messages survive restarts and are retried with backoff by the job workers
+func NewMailer(sender Sender, templates *TemplateStore, branding Branding, processor *jobs.Processor, log *zap.Logger) *Mailer {
+	m := &Mailer{
+		sender:    sender,
+		templates: templates,
+		branding:  branding,
+		jobs:      processor,
+		log:       log,
+	}
+	processor.Register(JobSend, m.handleSendJob)
+
+	return m
+}
+
+// Templates returns the templates the mailer renders, for editing them at runtime
+func (m *Mailer) Templates() *TemplateStore {
+	return m.templates
+}
+
+// Render builds a ready-to-send message from template name in the recipient's locale
+// An empty or unsupported locale renders the English template
+func (m *Mailer) Render(ctx context.Context, name, locale, to, toName string, data Data) (Message, error) {
+	if data == nil {
+		data = Data{}
+	}
+	m.brand(ctx, data)
+	if _, ok := data["Name"]; !ok {
+		data["Name"] = toName
+	}
+
+	subject, text, html, err := m.templates.Render(ctx, name, locale, data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{To: to, ToName: toName, Subject: subject, Text: text, HTML: html}, nil
+}
+
+// Preview renders template name from SampleData, the keys of req.Data replacing sample values,
+// and the store's branding; nothing is sent
+func (m *Mailer) Preview(ctx context.Context, name string, req *models.EmailTemplatePreviewRequest) (*models.EmailTemplatePreview, error) {
+	data := SampleData(name)
+	for key, value := range req.Data {
+		data[key] = value
+	}
+	m.brand(ctx, data)
+	return m.templates.Preview(ctx, name, req, data)
+}
+
+func (m *Mailer) brand(ctx context.Context, data Data) {
+	data["AppName"] = m.branding.StoreName(ctx)
+	data["SupportEmail"] = m.branding.SupportEmail(ctx)
+}
+
+// Send renders and sends synchronously
+func (m *Mailer) Send(ctx context.Context, name, locale, to, toName string, data Data) error {
+	msg, err := m.Render(ctx, name, locale, to, toName, data)
+	if err != nil {
+		return err
+	}
+	return m.sender.Send(ctx, msg)
+}
+
+// SendAsync renders now (so template errors surface to the caller) and enqueues delivery
+// Returns: error if the message could not be queued
+func (m *Mailer) SendAsync(ctx context.Context, name, locale, to, toName string, data Data) error {
+	msg, err := m.Render(ctx, name, locale, to, toName, data)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.jobs.Enqueue(ctx, JobSend, msg)
+	return err
+}
+
+// handleSendJob delivers a queued message; errors are retried by the job workers
+func (m *Mailer) handleSendJob(ctx context.Context, job *models.Job) error {
+	var msg Message
+	if err := job.Decode(&msg); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	return m.sender.Send(ctx, msg)
+}
@@ -0,0 +1,97 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+)
+
+const sendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// SendGridSender sends email through the SendGrid v3 Web API
+type SendGridSender struct {
+	apiKey   string
+	from     string
+	fromName string
+	client   *http.Client
+}
+
+func NewSendGridSender(cfg config.Email) *SendGridSender {
+	return &SendGridSender{
+		apiKey:   cfg.SendGridAPIKey,
+		from:     cfg.From,
+		fromName: cfg.FromName,
+		client:   httpclient.New(10 * time.Second),
+	}
+}
+
+// sendGridAddress / sendGridContent mirror the v3 mail/send JSON schema
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridRequest struct {
+	Personalizations []struct {
+		To []sendGridAddress `json:"to"`
+	} `json:"personalizations"`
+	From    sendGridAddress   `json:"from"`
+	Subject string            `json:"subject"`
+	Content []sendGridContent `json:"content"`
+}
+
+// Send posts msg to SendGrid; any non-2xx response is returned as an error
+func (s *SendGridSender) Send(ctx context.Context, msg Message) error {
+	payload := sendGridRequest{
+		From:    sendGridAddress{Email: s.from, Name: s.fromName},
+		Subject: msg.Subject,
+	}
+	payload.Personalizations = make([]struct {
+		To []sendGridAddress `json:"to"`
+	}, 1)
+	payload.Personalizations[0].To = []sendGridAddress{{Email: msg.To, Name: msg.ToName}}
+
+	// SendGrid requires text/plain before text/html
+	if msg.Text != "" {
+		payload.Content = append(payload.Content, sendGridContent{Type: "text/plain", Value: msg.Text})
+	}
+	if msg.HTML != "" {
+		payload.Content = append(payload.Content, sendGridContent{Type: "text/html", Value: msg.HTML})
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sendGridEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sendgrid send to %s: %w", msg.To, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sendgrid send to %s: status %d: %s", msg.To, resp.StatusCode, detail)
+	}
+	return nil
+}
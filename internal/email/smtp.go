@@ -0,0 +1,100 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+)
+
+// SMTPSender sends email through any SMTP server (Postfix, Mailgun, Amazon SES SMTP...)
+type SMTPSender struct {
+	addr string
+	host string
+	auth smtp.Auth
+	from mail.Address
+}
+
+func NewSMTPSender(cfg config.Email) *SMTPSender {
+	var auth smtp.Auth
+	if cfg.SMTPUser != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return &SMTPSender{
+		addr: net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort),
+		host: cfg.SMTPHost,
+		auth: auth,
+		from: mail.Address{Name: cfg.FromName, Address: cfg.From},
+	}
+}
+
+// Send delivers msg as multipart/alternative (text + HTML)
+// net/smtp upgrades to STARTTLS automatically when the server supports it
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	body, err := s.build(msg)
+	if err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from.Address, []string{msg.To}, body); err != nil {
+		return fmt.Errorf("smtp send to %s: %w", msg.To, err)
+	}
+	return nil
+}
+
+// build renders RFC 5322 headers and a multipart/alternative body
+func (s *SMTPSender) build(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	to := mail.Address{Name: msg.ToName, Address: msg.To}
+	fmt.Fprintf(&buf, "From: %s\r\n", s.from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mimeHeader(msg.Subject))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for _, part := range parts {
+		if part.content == "" {
+			continue
+		}
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		qp.Close()
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mimeHeader encodes non-ASCII subjects (RFC 2047); ASCII passes through unchanged
+func mimeHeader(value string) string {
+	return mime.QEncoding.Encode("utf-8", value)
+}
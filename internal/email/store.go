@@ -0,0 +1,374 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/i18n"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	activeCacheKey = "email_templates:active"
+	// activeCacheTTL bounds how long a change made outside the API takes to show up;
+	// changes through the store invalidate the cache immediately
+	activeCacheTTL = 10 * time.Minute
+
+	maxTemplateLength = 100_000
+)
+
+var (
+	// ErrUnknownTemplate is returned for template names and locales this build doesn't send
+	ErrUnknownTemplate = errors.New("unknown email template")
+	// ErrInvalidTemplate wraps template sources that don't parse or render
+	ErrInvalidTemplate = errors.New("invalid email template")
+)
+
+// TemplateStore renders email templates, preferring the versions admins activated over the
+// embedded ones, so wording can change without a deploy. The active versions are cached as one
+// list and compiled once per version; saving or activating invalidates the list, so every
+// instance sending mail picks the change up on its next render.
+// Read failures fall back to the embedded templates: a broken template table must not stop email
+type TemplateStore struct {
+	repo     *repository.EmailTemplateRepository
+	builtin  *Templates
+	cache    *cache.Loader
+	compiled sync.Map // version ID -> *compiled; versions never change once saved
+	log      *zap.Logger
+}
+
+// NewTemplateStore loads the embedded templates
+// Returns: error if any embedded template is malformed
+func NewTemplateStore(repo *repository.EmailTemplateRepository, c cache.Cache, log *zap.Logger) (*TemplateStore, error) {
+	builtin, err := LoadTemplates()
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateStore{
+		repo:    repo,
+		builtin: builtin,
+		cache:   cache.NewLoader(c),
+		log:     log,
+	}, nil
+}
+
+// templateKey is the Templates key of name in locale
+func templateKey(name, locale string) string {
+	if locale == i18n.Default {
+		return name
+	}
+	return locale + "/" + name
+}
+
+// active returns the active versions by template key
+func (s *TemplateStore) active(ctx context.Context) (map[string]models.EmailTemplate, error) {
+	return cache.LoadJSON(ctx, s.cache, activeCacheKey, activeCacheTTL, func(ctx context.Context) (map[string]models.EmailTemplate, error) {
+		rows, err := s.repo.ListActive(ctx)
+		if err != nil {
+			return nil, err
+		}
+		active := make(map[string]models.EmailTemplate, len(rows))
+		for _, row := range rows {
+			active[templateKey(row.Name, row.Locale)] = row
+		}
+		return active, nil
+	})
+}
+
+func (s *TemplateStore) compile(template *models.EmailTemplate) (*compiled, error) {
+	if c, ok := s.compiled.Load(template.ID); ok {
+		return c.(*compiled), nil
+	}
+	c, err := compile(template.Text, template.HTML)
+	if err != nil {
+		return nil, err
+	}
+	s.compiled.Store(template.ID, c)
+	return c, nil
+}
+
+// Render executes template name in locale with data. An edited version beats the embedded
+// template of the same locale, and a locale beats the English fallback
+// Returns: subject, plain-text body and HTML body
+func (s *TemplateStore) Render(ctx context.Context, name, locale string, data Data) (subject, text, html string, err error) {
+	active, err := s.active(ctx)
+	if err != nil {
+		s.log.Warn("Failed to load edited email templates, using built-in", zap.Error(err))
+	}
+
+	keys := []string{name}
+	if locale != "" && locale != i18n.Default {
+		keys = []string{locale + "/" + name, name}
+	}
+	for _, key := range keys {
+		edited, ok := active[key]
+		if !ok {
+			if _, ok := s.builtin.compiled[key]; ok {
+				break
+			}
+			continue
+		}
+		c, err := s.compile(&edited)
+		if err == nil {
+			subject, text, html, err = c.render(data)
+		}
+		if err == nil {
+			return subject, text, html, nil
+		}
+		// Saved versions were checked against sample data; real data can still trip them
+		s.log.Error("Edited email template failed, using built-in", zap.String("name", edited.Name),
+			zap.String("locale", edited.Locale), zap.Int("version", edited.Version), zap.Error(err))
+		break
+	}
+	return s.builtin.Render(name, locale, data)
+}
+
+// validate checks name and locale; an empty locale means English
+func validate(name, locale string) (string, error) {
+	if locale == "" {
+		locale = i18n.Default
+	}
+	if !slices.Contains(templateNames, name) || !i18n.IsSupported(locale) {
+		return "", ErrUnknownTemplate
+	}
+	return locale, nil
+}
+
+// Summaries lists every template in every supported locale with its active and latest version
+func (s *TemplateStore) Summaries(ctx context.Context) ([]models.EmailTemplateSummary, error) {
+	active, err := s.repo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := s.repo.LatestVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*models.EmailTemplateSummary)
+	var summaries []models.EmailTemplateSummary
+	for _, name := range templateNames {
+		for _, locale := range i18n.Supported() {
+			summaries = append(summaries, models.EmailTemplateSummary{Name: name, Locale: locale})
+		}
+	}
+	for i := range summaries {
+		byKey[templateKey(summaries[i].Name, summaries[i].Locale)] = &summaries[i]
+	}
+	for _, row := range active {
+		if summary, ok := byKey[templateKey(row.Name, row.Locale)]; ok {
+			summary.ActiveVersion = row.Version
+		}
+	}
+	for _, row := range latest {
+		if summary, ok := byKey[templateKey(row.Name, row.Locale)]; ok {
+			summary.LatestVersion = row.LatestVersion
+		}
+	}
+	return summaries, nil
+}
+
+// Current returns the sources name renders with in locale: the active version, or else the
+// embedded template
+func (s *TemplateStore) Current(ctx context.Context, name, locale string) (*models.EmailTemplateSource, error) {
+	locale, err := validate(name, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	edited, err := s.repo.GetActive(ctx, name, locale)
+	if err == nil {
+		return &models.EmailTemplateSource{Name: name, Locale: locale, Version: edited.Version, Text: edited.Text, HTML: edited.HTML}, nil
+	}
+	if !errors.Is(err, repository.ErrEmailTemplateNotFound) {
+		return nil, err
+	}
+	text, html, _ := s.builtin.Builtin(name, locale)
+	return &models.EmailTemplateSource{Name: name, Locale: locale, Text: text, HTML: html}, nil
+}
+
+// Versions returns the saved versions of name in locale, newest first
+func (s *TemplateStore) Versions(ctx context.Context, name, locale string) ([]models.EmailTemplate, error) {
+	locale, err := validate(name, locale)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListVersions(ctx, name, locale)
+}
+
+// Save stores req as the next version of name after rendering it with sample data
+// Returns: ErrUnknownTemplate, or ErrInvalidTemplate with the parse or render error
+func (s *TemplateStore) Save(ctx context.Context, name string, req *models.SaveEmailTemplateRequest, adminID string) (*models.EmailTemplate, error) {
+	locale, err := validate(name, req.Locale)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(name, req.Text, req.HTML); err != nil {
+		return nil, err
+	}
+
+	template := &models.EmailTemplate{
+		Name:      name,
+		Locale:    locale,
+		Text:      req.Text,
+		HTML:      req.HTML,
+		Active:    req.Activate,
+		CreatedBy: adminID,
+	}
+	if err := s.repo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+	if template.Active {
+		s.invalidate(ctx)
+	}
+	s.log.Info("Email template saved", zap.String("name", name), zap.String("locale", locale),
+		zap.Int("version", template.Version), zap.Bool("active", template.Active), zap.String("admin_id", adminID))
+	return template, nil
+}
+
+// Activate puts a saved version in use, e.g. to roll back to an earlier one
+func (s *TemplateStore) Activate(ctx context.Context, name, locale string, version int, adminID string) (*models.EmailTemplate, error) {
+	locale, err := validate(name, locale)
+	if err != nil {
+		return nil, err
+	}
+	template, err := s.repo.Activate(ctx, name, locale, version)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+	s.log.Info("Email template activated", zap.String("name", name), zap.String("locale", locale),
+		zap.Int("version", version), zap.String("admin_id", adminID))
+	return template, nil
+}
+
+// Reset puts the embedded template back in use for name in locale; saved versions are kept
+func (s *TemplateStore) Reset(ctx context.Context, name, locale, adminID string) error {
+	locale, err := validate(name, locale)
+	if err != nil {
+		return err
+	}
+	changed, err := s.repo.Deactivate(ctx, name, locale)
+	if err != nil {
+		return err
+	}
+	if changed {
+		s.invalidate(ctx)
+		s.log.Info("Email template reset to built-in", zap.String("name", name), zap.String("locale", locale),
+			zap.String("admin_id", adminID))
+	}
+	return nil
+}
+
+// Preview renders name with data: the draft sources in req when given, else the saved version
+// req names, else the template currently in use
+// Returns: ErrInvalidTemplate with the parse or render error of a draft
+func (s *TemplateStore) Preview(ctx context.Context, name string, req *models.EmailTemplatePreviewRequest, data Data) (*models.EmailTemplatePreview, error) {
+	locale, err := validate(name, req.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	var subject, text, html string
+	switch {
+	case req.Text != "" || req.HTML != "":
+		c, err := compile(req.Text, req.HTML)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+		}
+		subject, text, html, err = c.render(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+		}
+	case req.Version > 0:
+		template, err := s.repo.GetVersion(ctx, name, locale, req.Version)
+		if err != nil {
+			return nil, err
+		}
+		c, err := s.compile(template)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+		}
+		subject, text, html, err = c.render(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+		}
+	default:
+		subject, text, html, err = s.Render(ctx, name, locale, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &models.EmailTemplatePreview{Subject: subject, Text: text, HTML: html}, nil
+}
+
+// check parses the sources and renders them with name's sample data
+func check(name, text, html string) error {
+	switch {
+	case strings.TrimSpace(text) == "" || strings.TrimSpace(html) == "":
+		return fmt.Errorf("%w: text and html are required", ErrInvalidTemplate)
+	case len(text) > maxTemplateLength || len(html) > maxTemplateLength:
+		return fmt.Errorf("%w: text and html must be at most %d bytes each", ErrInvalidTemplate, maxTemplateLength)
+	}
+	c, err := compile(text, html)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	data := SampleData(name)
+	data["AppName"], data["SupportEmail"] = "Acme", "support@acme.example"
+	if _, _, _, err := c.render(data); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return nil
+}
+
+func (s *TemplateStore) invalidate(ctx context.Context) {
+	if err := s.cache.Invalidate(ctx, activeCacheKey); err != nil {
+		s.log.Warn("Failed to invalidate email template cache", zap.Error(err))
+	}
+}
+
+// SampleData returns realistic values for every key template name uses, for previews and for
+// checking edited templates before they are saved
+func SampleData(name string) Data {
+	data := Data{"Name": "Amina"}
+	switch name {
+	case TemplateVerifyEmail, TemplatePasswordReset:
+		data["ActionURL"] = "https://shop.example/reset?token=sample"
+		data["ExpiresIn"] = "1 hour"
+	case TemplateOrderConfirmation:
+		data["OrderNumber"] = "ORD-2025-000123"
+		data["OrderTotal"] = "KES 3,600.00"
+		data["Items"] = []map[string]interface{}{
+			{"Name": "Cotton T-shirt", "Quantity": 2, "Total": "KES 2,400.00"},
+			{"Name": "Canvas tote", "Quantity": 1, "Total": "KES 1,200.00"},
+		}
+	case TemplatePaymentReceipt:
+		data["OrderNumber"] = "ORD-2025-000123"
+		data["Amount"] = "KES 3,600.00"
+		data["PaymentReference"] = "pay_7Hq2Lm"
+	case TemplateOrderShipped:
+		data["OrderNumber"] = "ORD-2025-000123"
+		data["Carrier"] = "sendy"
+		data["TrackingNumber"] = "SND-448120"
+	case TemplateOrderDelivered:
+		data["OrderNumber"] = "ORD-2025-000123"
+		data["DeliveredAt"] = "2025-03-14"
+	case TemplateTicketReply:
+		data["TicketSubject"] = "Wrong size delivered"
+		data["OrderNumber"] = "ORD-2025-000123"
+		data["Message"] = "Sorry about that! A courier will collect it tomorrow and bring the right size."
+	case TemplateNotification:
+		data["Subject"] = "Your order has shipped"
+		data["Body"] = "Order ORD-2025-000123 is on its way."
+	}
+	return data
+}
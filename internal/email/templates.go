@@ -0,0 +1,173 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/Jason-Omondi/ecomgo/internal/i18n"
+)
+
+// Template names
+// Each has <name>.txt.tmpl (defines "subject" + plain-text body) and <name>.html.tmpl;
+// translations live in templates/<locale>/ and fall back to the English ones when missing
+const (
+	TemplateWelcome           = "welcome"
+	TemplateVerifyEmail       = "verify_email"
+	TemplatePasswordReset     = "password_reset"
+	TemplateOrderConfirmation = "order_confirmation"
+	TemplatePaymentReceipt    = "payment_receipt"
+	TemplateOrderShipped      = "order_shipped"
+	TemplateOrderDelivered    = "order_delivered"
+	TemplateTicketReply       = "ticket_reply"
+	TemplateNotification      = "notification" // generic Subject + Body, used by notify.Notifier
+)
+
+//go:embed templates/*.tmpl templates/*/*.tmpl
+var templateFS embed.FS
+
+var templateNames = []string{TemplateWelcome, TemplateVerifyEmail, TemplatePasswordReset, TemplateOrderConfirmation,
+	TemplatePaymentReceipt, TemplateOrderShipped, TemplateOrderDelivered, TemplateTicketReply, TemplateNotification}
+
+// Data is the template context; AppName and SupportEmail are filled in automatically
+// Common keys: Name, ActionURL, ExpiresIn, OrderNumber, OrderTotal, Items, Amount, Carrier, TrackingNumber,
+// TicketSubject, Message
+type Data map[string]interface{}
+
+// Templates renders embedded email templates
+// HTML bodies use html/template so user-supplied values (names, addresses) are escaped
+// Keyed by name for English and <locale>/<name> for translations
+type Templates struct {
+	compiled map[string]*compiled
+}
+
+// compiled is one template's parsed text (with its "subject") and HTML parts
+type compiled struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// LoadTemplates parses all embedded templates once at startup
+// Returns: error if any template is malformed (fail fast instead of at send time)
+func LoadTemplates() (*Templates, error) {
+	t := &Templates{compiled: make(map[string]*compiled)}
+
+	for _, name := range templateNames {
+		if err := t.load(name, "templates/"+name); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, locale := range i18n.Supported() {
+		for _, name := range templateNames {
+			base := "templates/" + locale + "/" + name
+			if _, err := fs.Stat(templateFS, base+".txt.tmpl"); err != nil {
+				continue
+			}
+			if err := t.load(locale+"/"+name, base); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return t, nil
+}
+
+// load parses base.txt.tmpl and base.html.tmpl under key
+func (t *Templates) load(key, base string) error {
+	text, html, err := embeddedSource(base)
+	if err != nil {
+		return fmt.Errorf("read %s template: %w", key, err)
+	}
+	c, err := compile(text, html)
+	if err != nil {
+		return fmt.Errorf("parse %s %w", key, err)
+	}
+	t.compiled[key] = c
+	return nil
+}
+
+func embeddedSource(base string) (text, html string, err error) {
+	textSource, err := fs.ReadFile(templateFS, base+".txt.tmpl")
+	if err != nil {
+		return "", "", err
+	}
+	htmlSource, err := fs.ReadFile(templateFS, base+".html.tmpl")
+	if err != nil {
+		return "", "", err
+	}
+	return string(textSource), string(htmlSource), nil
+}
+
+// compile parses a template's sources; the text part must define "subject"
+func compile(text, html string) (*compiled, error) {
+	textTmpl, err := texttemplate.New("text").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("text template: %w", err)
+	}
+	if textTmpl.Lookup("subject") == nil {
+		return nil, fmt.Errorf(`text template: no {{define "subject"}}`)
+	}
+	htmlTmpl, err := htmltemplate.New("html").Parse(html)
+	if err != nil {
+		return nil, fmt.Errorf("html template: %w", err)
+	}
+	return &compiled{text: textTmpl, html: htmlTmpl}, nil
+}
+
+// Builtin returns the embedded sources of template name in locale, falling back to English
+// Returns: false for unknown names
+func (t *Templates) Builtin(name, locale string) (text, html string, ok bool) {
+	if _, ok := t.compiled[name]; !ok {
+		return "", "", false
+	}
+	base := "templates/" + name
+	if _, ok := t.compiled[locale+"/"+name]; ok {
+		base = "templates/" + locale + "/" + name
+	}
+	text, html, err := embeddedSource(base)
+	return text, html, err == nil
+}
+
+// Render executes template name in locale with data, falling back to English
+// Returns: subject, plain-text body and HTML body
+func (t *Templates) Render(name, locale string, data Data) (subject, text, html string, err error) {
+	if _, ok := t.compiled[locale+"/"+name]; ok {
+		name = locale + "/" + name
+	}
+	c, ok := t.compiled[name]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown email template: %s", name)
+	}
+	subject, text, html, err = c.render(data)
+	if err != nil {
+		return "", "", "", fmt.Errorf("email template %s: %w", name, err)
+	}
+	return subject, text, html, nil
+}
+
+// render executes c with data
+func (c *compiled) render(data Data) (subject, text, html string, err error) {
+	var buf bytes.Buffer
+	if err := c.text.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", "", fmt.Errorf("render subject: %w", err)
+	}
+	subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := c.text.Execute(&buf, data); err != nil {
+		return "", "", "", fmt.Errorf("render text: %w", err)
+	}
+	text = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := c.html.Execute(&buf, data); err != nil {
+		return "", "", "", fmt.Errorf("render html: %w", err)
+	}
+	html = buf.String()
+
+	return subject, text, html, nil
+}
@@ -0,0 +1,107 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Event is a domain event published by a service after a state change
+// Payload holds the JSON-encoded event body (see types.go)
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// Decode unmarshals the event payload into dest
+func (e Event) Decode(dest interface{}) error {
+	return json.Unmarshal(e.Payload, dest)
+}
+
+// NewEvent builds an event of the given type with a fresh ID
+// Returns: error if payload cannot be encoded as JSON
+func NewEvent(eventType string, payload interface{}) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("encode %s payload: %w", eventType, err)
+	}
+
+	return Event{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Payload:    data,
+	}, nil
+}
+
+// Handler processes a delivered event
+// Returning an error logs the failure; delivery is at-least-once so handlers must be idempotent
+type Handler func(ctx context.Context, event Event) error
+
+// Publisher sends domain events to the bus
+// Services depend on this interface only, so the backend can change without touching them
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Subscriber registers handlers for event types
+// name identifies the consumer: instances sharing a name split the work (consumer group / queue group),
+// different names each receive every event
+type Subscriber interface {
+	Subscribe(eventType, name string, handler Handler) error
+
+	// SubscribeAll delivers every event of eventType to this instance, regardless of other instances
+	// For per-connection fan-out (SSE, WebSocket); events published while the instance is down are not replayed
+	SubscribeAll(eventType string, handler Handler) error
+}
+
+// Bus is a Publisher and Subscriber backed by Kafka, NATS or in-process delivery
+type Bus interface {
+	Publisher
+	Subscriber
+	Close() error
+}
+
+// New creates the event bus selected by EVENTS_BACKEND
+// Returns: Kafka, NATS or in-process bus; error if the backend is unknown or unreachable
+// Why here: single construction point so services never know which broker is used
+func New(cfg *config.Config, log *zap.Logger) (Bus, error) {
+	switch cfg.Events.Backend {
+	case "kafka":
+		log.Info("Using Kafka event bus", zap.Strings("brokers", cfg.Events.KafkaBrokers))
+		return NewKafkaBus(cfg.Events, log), nil
+	case "nats":
+		log.Info("Using NATS event bus", zap.String("url", cfg.Events.NATSURL))
+		return NewNATSBus(cfg.Events, log)
+	case "memory", "":
+		log.Info("Using in-process event bus")
+		return NewMemoryBus(log), nil
+	default:
+		return nil, fmt.Errorf("unsupported EVENTS_BACKEND: %s (must be 'memory', 'kafka' or 'nats')", cfg.Events.Backend)
+	}
+}
+
+// Publish encodes payload as an event of eventType and publishes it
+// Failures are logged and returned; callers usually treat them as non-fatal
+// so a broker outage doesn't fail the user-facing request
+func Publish(ctx context.Context, p Publisher, log *zap.Logger, eventType string, payload interface{}) error {
+	event, err := NewEvent(eventType, payload)
+	if err != nil {
+		log.Error("Failed to build event", zap.String("type", eventType), zap.Error(err))
+		return err
+	}
+
+	if err := p.Publish(ctx, event); err != nil {
+		log.Error("Failed to publish event",
+			zap.String("type", eventType), zap.String("event_id", event.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
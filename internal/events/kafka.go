@@ -0,0 +1,138 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// KafkaBus publishes each event type to its own topic (<prefix><type>)
+// Subscribers join a consumer group (<group>.<name>) so each instance handles a share of partitions
+type KafkaBus struct {
+	cfg        config.Events
+	writer     *kafka.Writer
+	log        *zap.Logger
+	instanceID string // names this process's SubscribeAll consumer groups
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	readers []*kafka.Reader
+}
+
+// NewKafkaBus creates a Kafka-backed bus (connections are opened lazily)
+func NewKafkaBus(cfg config.Events, log *zap.Logger) *KafkaBus {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &KafkaBus{
+		cfg: cfg,
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(cfg.KafkaBrokers...),
+			Balancer:               &kafka.Hash{}, // same key -> same partition -> ordered per event ID
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+		log:        log,
+		instanceID: uuid.NewString(),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Publish writes the event to its type's topic
+func (b *KafkaBus) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return b.writer.WriteMessages(ctx, kafka.Message{
+		Topic: b.cfg.TopicPrefix + event.Type,
+		Key:   []byte(event.ID),
+		Value: data,
+	})
+}
+
+// Subscribe starts a consumer-group reader for eventType in the background
+func (b *KafkaBus) Subscribe(eventType, name string, handler Handler) error {
+	b.startReader(kafka.ReaderConfig{
+		Brokers: b.cfg.KafkaBrokers,
+		GroupID: b.cfg.ConsumerGroup + "." + name,
+		Topic:   b.cfg.TopicPrefix + eventType,
+	}, handler)
+	return nil
+}
+
+// SubscribeAll reads eventType in a consumer group unique to this process, starting at the newest offset
+// so every instance sees every new event without replaying the topic's history
+func (b *KafkaBus) SubscribeAll(eventType string, handler Handler) error {
+	b.startReader(kafka.ReaderConfig{
+		Brokers:     b.cfg.KafkaBrokers,
+		GroupID:     b.cfg.ConsumerGroup + ".instance." + b.instanceID,
+		Topic:       b.cfg.TopicPrefix + eventType,
+		StartOffset: kafka.LastOffset,
+	}, handler)
+	return nil
+}
+
+func (b *KafkaBus) startReader(cfg kafka.ReaderConfig, handler Handler) {
+	reader := kafka.NewReader(cfg)
+
+	b.mu.Lock()
+	b.readers = append(b.readers, reader)
+	b.mu.Unlock()
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.consume(reader, handler)
+	}()
+}
+
+// consume fetches, handles and commits messages until the bus is closed
+// Offsets are committed even when the handler fails so a poison message can't block the partition
+func (b *KafkaBus) consume(reader *kafka.Reader, handler Handler) {
+	for {
+		msg, err := reader.FetchMessage(b.ctx)
+		if err != nil {
+			if b.ctx.Err() != nil {
+				return // bus closed
+			}
+			b.log.Error("Failed to fetch Kafka message", zap.String("topic", reader.Config().Topic), zap.Error(err))
+			continue
+		}
+
+		var event Event
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			b.log.Error("Dropping malformed event", zap.String("topic", msg.Topic), zap.Error(err))
+		} else if err := handler(b.ctx, event); err != nil {
+			b.log.Error("Event handler failed",
+				zap.String("type", event.Type), zap.String("event_id", event.ID), zap.Error(err))
+		}
+
+		if err := reader.CommitMessages(b.ctx, msg); err != nil && b.ctx.Err() == nil {
+			b.log.Error("Failed to commit Kafka offset", zap.String("topic", msg.Topic), zap.Error(err))
+		}
+	}
+}
+
+// Close stops consumers and flushes pending writes
+func (b *KafkaBus) Close() error {
+	b.cancel()
+	b.wg.Wait()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var errs []error
+	for _, reader := range b.readers {
+		errs = append(errs, reader.Close())
+	}
+	errs = append(errs, b.writer.Close())
+	return errors.Join(errs...)
+}
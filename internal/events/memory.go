@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// MemoryBus delivers events to handlers in the same process
+// Fallback when no broker is configured: local development, tests, single-instance deployments
+// Events are lost on restart and are NOT shared between instances
+type MemoryBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	wg       sync.WaitGroup
+	log      *zap.Logger
+}
+
+// NewMemoryBus creates an empty in-process bus
+func NewMemoryBus(log *zap.Logger) *MemoryBus {
+	return &MemoryBus{
+		handlers: make(map[string][]Handler),
+		log:      log,
+	}
+}
+
+// Publish dispatches the event to every handler asynchronously
+// Handlers run detached from the request context so they outlive the HTTP request
+func (b *MemoryBus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	handlers := b.handlers[event.Type]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		b.wg.Add(1)
+		go func(handler Handler) {
+			defer b.wg.Done()
+			if err := handler(context.Background(), event); err != nil {
+				b.log.Error("Event handler failed",
+					zap.String("type", event.Type), zap.String("event_id", event.ID), zap.Error(err))
+			}
+		}(handler)
+	}
+	return nil
+}
+
+// Subscribe registers handler for eventType
+// name is ignored: there is only one consumer per process
+func (b *MemoryBus) Subscribe(eventType, name string, handler Handler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+	return nil
+}
+
+// SubscribeAll is Subscribe: every handler in the process already sees every event
+func (b *MemoryBus) SubscribeAll(eventType string, handler Handler) error {
+	return b.Subscribe(eventType, "", handler)
+}
+
+// Close waits for in-flight handlers to finish
+func (b *MemoryBus) Close() error {
+	b.wg.Wait()
+	return nil
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// NATSBus publishes each event type on subject <prefix><type>
+// Subscribers use queue groups (<group>.<name>) so each event is handled once per consumer name
+type NATSBus struct {
+	conn   *nats.Conn
+	prefix string
+	group  string
+	log    *zap.Logger
+}
+
+// NewNATSBus connects to the NATS server
+// Returns: error if the server is unreachable
+func NewNATSBus(cfg config.Events, log *zap.Logger) (*NATSBus, error) {
+	conn, err := nats.Connect(cfg.NATSURL,
+		nats.Name("ecomgo"),
+		nats.MaxReconnects(-1), // keep retrying; publishes are buffered while reconnecting
+	)
+	if err != nil {
+		log.Error("Failed to connect to NATS", zap.String("url", cfg.NATSURL), zap.Error(err))
+		return nil, err
+	}
+
+	return &NATSBus{
+		conn:   conn,
+		prefix: cfg.TopicPrefix,
+		group:  cfg.ConsumerGroup,
+		log:    log,
+	}, nil
+}
+
+// Publish sends the event on its type's subject
+func (b *NATSBus) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.conn.Publish(b.prefix+event.Type, data)
+}
+
+// Subscribe joins the queue group for eventType
+func (b *NATSBus) Subscribe(eventType, name string, handler Handler) error {
+	_, err := b.conn.QueueSubscribe(b.prefix+eventType, b.group+"."+name, b.deliver(handler))
+	return err
+}
+
+// SubscribeAll subscribes outside any queue group so this instance receives every message
+func (b *NATSBus) SubscribeAll(eventType string, handler Handler) error {
+	_, err := b.conn.Subscribe(b.prefix+eventType, b.deliver(handler))
+	return err
+}
+
+// deliver decodes messages and runs handler, logging failures
+func (b *NATSBus) deliver(handler Handler) nats.MsgHandler {
+	return func(msg *nats.Msg) {
+		var event Event
+		if err := json.Unmarshal(msg.Data, &event); err != nil {
+			b.log.Error("Dropping malformed event", zap.String("subject", msg.Subject), zap.Error(err))
+			return
+		}
+		if err := handler(context.Background(), event); err != nil {
+			b.log.Error("Event handler failed",
+				zap.String("type", event.Type), zap.String("event_id", event.ID), zap.Error(err))
+		}
+	}
+}
+
+// Close drains subscriptions (letting in-flight handlers finish) and closes the connection
+func (b *NATSBus) Close() error {
+	return b.conn.Drain()
+}
@@ -0,0 +1,178 @@
+package events
+
+import "time"
+
+// Domain event types
+// Naming: <aggregate>.<past-tense verb>; also used as Kafka topic / NATS subject suffix
+const (
+	TypeUserRegistered      = "user.registered"
+	TypeUserRoleChanged     = "user.role_changed"
+	TypeOrderPlaced         = "order.placed"
+	TypePaymentCaptured     = "payment.captured"
+	TypeProductUpdated      = "product.updated"
+	TypeProductDeleted      = "product.deleted"
+	TypeOrderShipped        = "order.shipped"
+	TypeOrderDelivered      = "order.delivered"
+	TypeRefundIssued        = "refund.issued"
+	TypeFraudReviewResolved = "fraud.review_resolved"
+	TypeCapacityChanged     = "capacity.changed"
+	TypeQuestionAnswered    = "question.answered"
+	TypeQuestionRejected    = "question.rejected"
+	TypeQuoteReviewed       = "quote.reviewed"
+	TypeTicketMessagePosted = "ticket.message_posted"
+)
+
+// UserRegistered is published after a new account is created
+type UserRegistered struct {
+	UserID       string `json:"user_id"`
+	Email        string `json:"email"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	ReferralCode string `json:"referral_code,omitempty"` // as given at signup, unchecked; see the referral module
+}
+
+// UserRoleChanged is published when an admin changes a user's role
+type UserRoleChanged struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// OrderPlaced is published when checkout creates an order
+// Amounts are in minor units (cents) to avoid floating point rounding
+// Order events carry the customer-facing OrderNumber (ordernumber.Generator) next to the ID
+type OrderPlaced struct {
+	OrderID     string      `json:"order_id"`
+	OrderNumber string      `json:"order_number,omitempty"`
+	UserID      string      `json:"user_id"`
+	Total       int64       `json:"total"`
+	Currency    string      `json:"currency"`
+	Channel     string      `json:"channel,omitempty"` // sales channel the order came through; empty means web
+	Items       []OrderItem `json:"items,omitempty"`   // order lines; vendor commissions are computed from them
+	Gift        bool        `json:"gift,omitempty"`    // packing slips hide prices
+	GiftMessage string      `json:"gift_message,omitempty"`
+	GiftWrapFee int64       `json:"gift_wrap_fee,omitempty"` // included in Total, see internal/gift
+	QuoteID     string      `json:"quote_id,omitempty"`      // set on orders placed from an approved B2B quote
+	// Tax records for filings: the buyer's tax treatment (inclusive, exclusive or exempt) and VAT number
+	TaxTreatment string `json:"tax_treatment,omitempty"`
+	VATID        string `json:"vat_id,omitempty"`
+}
+
+// OrderItem is one line of an order
+type OrderItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice int64  `json:"unit_price"` // price paid per unit, after campaigns and discounts
+	GiftWrap  bool   `json:"gift_wrap,omitempty"`
+}
+
+// PaymentCaptured is published when a payment provider confirms funds
+type PaymentCaptured struct {
+	PaymentID   string `json:"payment_id"`
+	OrderID     string `json:"order_id"`
+	OrderNumber string `json:"order_number,omitempty"`
+	UserID      string `json:"user_id"`
+	Provider    string `json:"provider"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+}
+
+// ProductUpdated is published when catalog data (price, stock, details) changes
+type ProductUpdated struct {
+	ProductID string `json:"product_id"`
+}
+
+// ProductDeleted is published when a product is removed from the catalog
+type ProductDeleted struct {
+	ProductID string `json:"product_id"`
+}
+
+// OrderShipped is published when a shipment leaves the warehouse
+type OrderShipped struct {
+	OrderID        string `json:"order_id"`
+	OrderNumber    string `json:"order_number,omitempty"`
+	UserID         string `json:"user_id"`
+	Carrier        string `json:"carrier"`
+	TrackingNumber string `json:"tracking_number"`
+}
+
+// OrderDelivered is published when the carrier confirms delivery
+type OrderDelivered struct {
+	OrderID        string    `json:"order_id"`
+	OrderNumber    string    `json:"order_number,omitempty"`
+	UserID         string    `json:"user_id"`
+	Carrier        string    `json:"carrier"`
+	TrackingNumber string    `json:"tracking_number"`
+	DeliveredAt    time.Time `json:"delivered_at"`
+}
+
+// RefundIssued is published when money is returned to the customer
+type RefundIssued struct {
+	RefundID    string      `json:"refund_id"`
+	OrderID     string      `json:"order_id"`
+	OrderNumber string      `json:"order_number,omitempty"`
+	UserID      string      `json:"user_id"`
+	Amount      int64       `json:"amount"`
+	Currency    string      `json:"currency"`
+	Items       []OrderItem `json:"items,omitempty"` // refunded lines (unit_price unused); vendor sales are only reduced for listed items
+}
+
+// FraudReviewResolved is published when an admin allows or denies an order held by fraud screening
+// Checkout captures or voids the held payment in response
+type FraudReviewResolved struct {
+	AssessmentID string `json:"assessment_id"`
+	OrderID      string `json:"order_id"`
+	UserID       string `json:"user_id"`
+	Decision     string `json:"decision"` // allow or deny
+}
+
+// CapacityChanged is published when an admin changes concurrency limits at runtime
+// Every instance applies it (SubscribeAll); nil fields are left unchanged
+type CapacityChanged struct {
+	HTTPMaxInFlight     *int `json:"http_max_in_flight,omitempty"`
+	HTTPMaxQueued       *int `json:"http_max_queued,omitempty"`
+	OutboundMaxInFlight *int `json:"outbound_max_in_flight,omitempty"`
+	OutboundMaxQueued   *int `json:"outbound_max_queued,omitempty"`
+	JobConcurrency      *int `json:"job_concurrency,omitempty"`
+}
+
+// QuestionAnswered is published when the store or a vendor answers a product question
+type QuestionAnswered struct {
+	QuestionID  string `json:"question_id"`
+	AnswerID    string `json:"answer_id"`
+	ProductID   string `json:"product_id"`
+	ProductName string `json:"product_name"`
+	UserID      string `json:"user_id"` // the asker
+	AnsweredBy  string `json:"answered_by"`
+}
+
+// QuestionRejected is published when an admin rejects a product question
+type QuestionRejected struct {
+	QuestionID  string `json:"question_id"`
+	ProductID   string `json:"product_id"`
+	ProductName string `json:"product_name"`
+	UserID      string `json:"user_id"` // the asker
+	Reason      string `json:"reason,omitempty"`
+}
+
+// QuoteReviewed is published when an admin approves or rejects a B2B quote
+type QuoteReviewed struct {
+	QuoteID    string     `json:"quote_id"`
+	UserID     string     `json:"user_id"` // the customer who asked
+	Status     string     `json:"status"`  // approved or rejected
+	Subtotal   int64      `json:"subtotal"`
+	Currency   string     `json:"currency"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	Response   string     `json:"response,omitempty"`
+}
+
+// TicketMessagePosted is published when a support ticket is raised or gets a new message
+type TicketMessagePosted struct {
+	TicketID    string `json:"ticket_id"`
+	MessageID   string `json:"message_id"`
+	UserID      string `json:"user_id"` // the ticket's customer
+	Subject     string `json:"subject"`
+	OrderNumber string `json:"order_number,omitempty"`
+	Body        string `json:"body"`
+	FromStore   bool   `json:"from_store"` // an admin replied; otherwise the customer wrote
+	First       bool   `json:"first"`      // the message that raised the ticket
+}
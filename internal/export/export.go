@@ -0,0 +1,139 @@
+// Package export streams admin exports as CSV or NDJSON
+// Rows are written and flushed batch by batch, so memory stays flat however large the table is
+// and the response uses chunked encoding; a client disconnect cancels the request context,
+// which stops the repository iterator feeding the export.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Formats
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// BatchSize is how many rows are loaded per query and written between flushes
+const BatchSize = 500
+
+// ErrUnsupportedFormat is returned for a format other than csv or ndjson
+var ErrUnsupportedFormat = errors.New("format must be csv or ndjson")
+
+// Column is one CSV column; NDJSON rows use the record's JSON encoding instead
+type Column[T any] struct {
+	Name  string
+	Value func(T) string
+}
+
+// Iterator calls fn with consecutive batches of records until the table is exhausted or fn fails
+type Iterator[T any] func(ctx context.Context, batchSize int, fn func([]T) error) error
+
+// ParseFormat validates a format query parameter; empty means CSV
+func ParseFormat(format string) (string, error) {
+	switch format {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatNDJSON:
+		return FormatNDJSON, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// Stream writes every record produced by each to w as a download named name.<format>
+// Returns: rows written, and any error - after the first chunk the status is already sent,
+// so a failure can only cut the download short and the caller should just log it
+func Stream[T any](ctx context.Context, w http.ResponseWriter, format, name string,
+	columns []Column[T], each Iterator[T]) (int, error) {
+	flusher, _ := w.(http.Flusher)
+
+	w.Header().Set("Content-Type", contentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.%s"`,
+		name, time.Now().UTC().Format("20060102-150405"), format))
+	w.Header().Set("Cache-Control", "no-store")
+	// Lets proxies like nginx pass chunks through instead of buffering the whole export
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	var write func(T) error
+	var flush func() error
+	switch format {
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		write = func(record T) error { return enc.Encode(record) }
+		flush = func() error { return nil }
+	default:
+		cw := csv.NewWriter(w)
+		header := make([]string, len(columns))
+		for i, col := range columns {
+			header[i] = col.Name
+		}
+		if err := cw.Write(header); err != nil {
+			return 0, err
+		}
+		row := make([]string, len(columns))
+		write = func(record T) error {
+			for i, col := range columns {
+				row[i] = col.Value(record)
+			}
+			return cw.Write(row)
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	}
+
+	rows := 0
+	err := each(ctx, BatchSize, func(batch []T) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for _, record := range batch {
+			if err := write(record); err != nil {
+				return err
+			}
+			rows++
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return rows, err
+	}
+	return rows, flush()
+}
+
+func contentType(format string) string {
+	if format == FormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Time formats t as RFC 3339 in UTC for CSV cells
+func Time(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// TimeIn formats t as RFC 3339 in loc, for exports rendered in the client's timezone
+// (see internal/timezone); the offset is kept so cells still name an exact instant
+func TimeIn(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(time.RFC3339)
+}
+
+// Int formats n for CSV cells
+func Int[N ~int | ~int64](n N) string {
+	return strconv.FormatInt(int64(n), 10)
+}
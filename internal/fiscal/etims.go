@@ -0,0 +1,203 @@
+package fiscal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+)
+
+// eTIMS tax types: B is standard-rated VAT (16%), A is exempt
+const (
+	etimsTaxExempt   = "A"
+	etimsTaxStandard = "B"
+	etimsVATRate     = 16
+)
+
+// etimsTime is eTIMS's timestamp layout, in Kenya time
+const etimsTime = "20060102150405"
+
+var nairobi = time.FixedZone("EAT", 3*60*60)
+
+// Etims reports sales to KRA eTIMS through the store's VSCU/OSCU (POST /trnsSales/saveSales)
+// Prices are reported VAT-inclusive at the standard rate, or exempt for exempt buyers.
+// eTIMS keys a sale by its numeric invoice number, so the order number's digits are used
+// (ORD-2025-000123 is invoice 2025000123); order numbers need at least one digit
+type Etims struct {
+	baseURL  string
+	tin      string
+	branchID string
+	cmcKey   string
+	client   *http.Client
+}
+
+func NewEtims(cfg config.Fiscal) *Etims {
+	return &Etims{
+		baseURL:  cfg.EtimsURL,
+		tin:      cfg.EtimsTIN,
+		branchID: cfg.EtimsBranchID,
+		cmcKey:   cfg.EtimsCMCKey,
+		client:   httpclient.New(30 * time.Second),
+	}
+}
+
+func (e *Etims) Name() string {
+	return "etims"
+}
+
+type etimsItem struct {
+	ItemSeq  int     `json:"itemSeq"`
+	ItemCd   string  `json:"itemCd"`
+	ItemNm   string  `json:"itemNm"`
+	Qty      float64 `json:"qty"`
+	Prc      float64 `json:"prc"`
+	SplyAmt  float64 `json:"splyAmt"`
+	DcRt     float64 `json:"dcRt"`
+	DcAmt    float64 `json:"dcAmt"`
+	TaxTyCd  string  `json:"taxTyCd"`
+	TaxblAmt float64 `json:"taxblAmt"`
+	TaxAmt   float64 `json:"taxAmt"`
+	TotAmt   float64 `json:"totAmt"`
+}
+
+type etimsResponse struct {
+	ResultCd  string `json:"resultCd"`
+	ResultMsg string `json:"resultMsg"`
+	Data      *struct {
+		CurRcptNo   int    `json:"curRcptNo"`
+		RcptSign    string `json:"rcptSign"`
+		SdcDateTime string `json:"sdcDateTime"`
+	} `json:"data"`
+}
+
+func (e *Etims) Issue(ctx context.Context, sale Sale) (*Receipt, error) {
+	invoice, err := invoiceSequence(sale.InvoiceNumber)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(sale.Currency, "KES") {
+		return nil, rejected("etims: sales must be in KES, not %s", sale.Currency)
+	}
+
+	taxType := etimsTaxStandard
+	if sale.TaxTreatment == "exempt" {
+		taxType = etimsTaxExempt
+	}
+	items := make([]etimsItem, 0, len(sale.Lines))
+	var total, tax float64
+	for i, line := range sale.Lines {
+		amount := major(line.UnitPrice * int64(line.Quantity))
+		item := etimsItem{
+			ItemSeq:  i + 1,
+			ItemCd:   line.Code,
+			ItemNm:   line.Name,
+			Qty:      float64(line.Quantity),
+			Prc:      major(line.UnitPrice),
+			SplyAmt:  amount,
+			TaxTyCd:  taxType,
+			TaxblAmt: amount,
+			TotAmt:   amount,
+		}
+		if taxType == etimsTaxStandard {
+			item.TaxAmt = math.Round(amount*etimsVATRate/(100+etimsVATRate)*100) / 100
+		}
+		items = append(items, item)
+		total += amount
+		tax += item.TaxAmt
+	}
+
+	payload := map[string]interface{}{
+		"tin":          e.tin,
+		"bhfId":        e.branchID,
+		"trdInvcNo":    sale.InvoiceNumber,
+		"invcNo":       invoice,
+		"orgInvcNo":    0,
+		"custTin":      sale.BuyerTIN,
+		"salesTyCd":    "N", // normal sale
+		"rcptTyCd":     "S", // sale receipt
+		"salesSttsCd":  "02",
+		"cfmDt":        sale.PaidAt.In(nairobi).Format(etimsTime),
+		"salesDt":      sale.PaidAt.In(nairobi).Format("20060102"),
+		"totItemCnt":   len(items),
+		"totTaxblAmt":  total,
+		"totTaxAmt":    tax,
+		"totAmt":       total,
+		"prchrAcptcYn": "N",
+		"itemList":     items,
+	}
+	if taxType == etimsTaxStandard {
+		payload["taxblAmtB"], payload["taxRtB"], payload["taxAmtB"] = total, etimsVATRate, tax
+	} else {
+		payload["taxblAmtA"], payload["taxRtA"], payload["taxAmtA"] = total, 0, 0
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/trnsSales/saveSales", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("tin", e.tin)
+	req.Header.Set("bhfId", e.branchID)
+	req.Header.Set("cmcKey", e.cmcKey)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("etims sale %s: %w", sale.InvoiceNumber, err)
+	}
+	defer resp.Body.Close()
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("etims sale %s: status %d: %s", sale.InvoiceNumber, resp.StatusCode, detail)
+	}
+	var out etimsResponse
+	if err := json.Unmarshal(detail, &out); err != nil {
+		return nil, fmt.Errorf("etims sale %s: %w", sale.InvoiceNumber, err)
+	}
+	if out.ResultCd != "000" || out.Data == nil {
+		return nil, rejected("etims: %s %s", out.ResultCd, out.ResultMsg)
+	}
+
+	issuedAt, err := time.ParseInLocation(etimsTime, out.Data.SdcDateTime, nairobi)
+	if err != nil {
+		issuedAt = time.Now()
+	}
+	return &Receipt{
+		Number:    strconv.Itoa(out.Data.CurRcptNo),
+		Signature: out.Data.RcptSign,
+		IssuedAt:  issuedAt.UTC(),
+	}, nil
+}
+
+// invoiceSequence is the numeric invoice number eTIMS keys sales by: the order number's digits
+func invoiceSequence(orderNumber string) (int64, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, orderNumber)
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, rejected("etims: order number %q has no invoice sequence", orderNumber)
+	}
+	return n, nil
+}
+
+// major converts minor units to the decimal amounts eTIMS expects
+func major(amount int64) float64 {
+	return float64(amount) / 100
+}
@@ -0,0 +1,78 @@
+// Package fiscal issues fiscal receipts: the tax authority's record of a sale, required for
+// every paid order in jurisdictions with electronic fiscal receipts (e.g. KRA eTIMS in Kenya).
+// The order module calls the Provider after payment and stores the receipt on the order.
+package fiscal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+)
+
+// ErrRejected is returned when the tax authority refuses a sale for good (invalid buyer PIN,
+// malformed lines); retrying won't help
+var ErrRejected = errors.New("fiscal receipt rejected")
+
+// Line is one line of a sale
+// Amounts are in minor units (cents) to avoid floating point rounding
+type Line struct {
+	Code      string // SKU, or the product ID when the product is gone
+	Name      string
+	Quantity  int
+	UnitPrice int64
+}
+
+// Sale is a paid order to report
+type Sale struct {
+	OrderID       string // providers use it for idempotency
+	InvoiceNumber string // customer-facing order number, printed on the receipt
+	Currency      string
+	Total         int64
+	TaxTreatment  string // inclusive, exclusive or exempt (see models.TaxInclusive)
+	BuyerTIN      string // the customer's VAT/TIN number, if they gave one
+	Lines         []Line
+	PaidAt        time.Time
+}
+
+// Receipt is the tax authority's acknowledgement of a sale
+type Receipt struct {
+	Number    string    // receipt number issued by the authority
+	Signature string    // receipt signature or verification code printed (or QR-encoded) on the receipt
+	IssuedAt  time.Time // as stamped by the authority
+}
+
+// Provider reports sales to a tax authority
+type Provider interface {
+	// Name is the provider code stored on orders (sandbox, etims)
+	Name() string
+
+	// Issue reports sale and returns its receipt; it must be safe to repeat with the same OrderID
+	// Returns: ErrRejected when the sale can never be accepted, other errors are retried
+	Issue(ctx context.Context, sale Sale) (*Receipt, error)
+}
+
+// New creates the provider selected by FISCAL_PROVIDER
+// Returns: nil when FISCAL_PROVIDER=none; error if the provider is unknown or missing credentials
+func New(cfg config.Fiscal) (Provider, error) {
+	switch cfg.Provider {
+	case "none", "":
+		return nil, nil
+	case "sandbox":
+		return NewSandbox(), nil
+	case "etims":
+		if cfg.EtimsURL == "" || cfg.EtimsTIN == "" || cfg.EtimsBranchID == "" || cfg.EtimsCMCKey == "" {
+			return nil, fmt.Errorf("ETIMS_URL, ETIMS_TIN, ETIMS_BRANCH_ID and ETIMS_CMC_KEY must be set for FISCAL_PROVIDER=etims")
+		}
+		return NewEtims(cfg), nil
+	default:
+		return nil, fmt.Errorf("unsupported FISCAL_PROVIDER: %s (must be 'none', 'sandbox' or 'etims')", cfg.Provider)
+	}
+}
+
+// rejected wraps a tax authority's refusal in ErrRejected
+func rejected(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrRejected, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,51 @@
+package fiscal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Sandbox stands in for a tax authority's e-receipt service: receipts are numbered SBX-000001
+// onwards with a random signature, and issuing for an order again returns its first receipt.
+// A sale without lines, or with a total of zero or less, is rejected: use one to exercise a
+// refused receipt.
+type Sandbox struct {
+	mu     sync.Mutex
+	issued map[string]*Receipt // order ID -> receipt, so repeats return the same receipt
+}
+
+func NewSandbox() *Sandbox {
+	return &Sandbox{issued: make(map[string]*Receipt)}
+}
+
+func (s *Sandbox) Name() string {
+	return "sandbox"
+}
+
+func (s *Sandbox) Issue(ctx context.Context, sale Sale) (*Receipt, error) {
+	switch {
+	case len(sale.Lines) == 0:
+		return nil, rejected("sandbox: sale has no lines")
+	case sale.Total <= 0:
+		return nil, rejected("sandbox: total must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if receipt, ok := s.issued[sale.OrderID]; ok {
+		return receipt, nil
+	}
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	receipt := &Receipt{
+		Number:    fmt.Sprintf("SBX-%06d", len(s.issued)+1),
+		Signature: hex.EncodeToString(buf),
+		IssuedAt:  time.Now().UTC(),
+	}
+	s.issued[sale.OrderID] = receipt
+	return receipt, nil
+}
@@ -0,0 +1,171 @@
+package fraud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/fx"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+// ErrDenied is returned by Screen when an order must not be charged
+var ErrDenied = errors.New("order declined by fraud screening")
+
+// Signals describe a checkout about to be charged
+// Order and user fields come from the checkout; device fields from the HTTP request (see RequestSignals)
+type Signals struct {
+	OrderID  string `json:"order_id"`
+	Amount   int64  `json:"amount"` // minor units of Currency
+	Currency string `json:"currency"`
+
+	UserID           string    `json:"user_id"`
+	Email            string    `json:"email"`
+	AccountCreatedAt time.Time `json:"account_created_at"`
+	PreviousOrders   int       `json:"previous_orders"` // completed orders before this one
+
+	BillingCountry  string `json:"billing_country"`
+	ShippingCountry string `json:"shipping_country"`
+
+	IP        string `json:"ip"`
+	IPCountry string `json:"ip_country"` // from the CDN/proxy geolocation header, if any
+	DeviceID  string `json:"device_id"`  // client-generated device fingerprint (X-Device-ID)
+	UserAgent string `json:"user_agent"`
+}
+
+// Assessment is a checker's verdict
+type Assessment struct {
+	Score    int      `json:"score"` // 0 (safe) - 100 (certain fraud)
+	Decision string   `json:"decision"`
+	Reasons  []string `json:"reasons"`
+}
+
+// Checker scores a checkout before payment capture
+type Checker interface {
+	// Name returns the provider name recorded with assessments, e.g. "rules"
+	Name() string
+
+	// Check scores signals; Decision may be left empty to let thresholds decide
+	Check(ctx context.Context, signals Signals) (*Assessment, error)
+}
+
+// NewChecker returns the checker selected by FRAUD_PROVIDER
+func NewChecker(cfg config.Fraud, appCache cache.Cache, converter *fx.Converter, log *zap.Logger) (Checker, error) {
+	rules := NewRulesChecker(cfg, appCache, converter)
+	switch cfg.Provider {
+	case "rules":
+		return rules, nil
+	case "http":
+		if cfg.ProviderURL == "" || cfg.ProviderSecret == "" {
+			return nil, errors.New("FRAUD_PROVIDER_URL and FRAUD_PROVIDER_SECRET must be set for the http fraud provider")
+		}
+		return NewHTTPChecker(cfg.ProviderURL, cfg.ProviderSecret, rules, log), nil
+	default:
+		return nil, fmt.Errorf("unsupported FRAUD_PROVIDER: %s (must be rules or http)", cfg.Provider)
+	}
+}
+
+// Screener runs the configured checker, applies thresholds and records the assessment
+// Checkout calls Screen before capturing payment:
+//   - ErrDenied: don't charge
+//   - models.FraudReview: authorize only / hold the order until an admin resolves it
+//   - models.FraudAllow: capture
+type Screener struct {
+	checker     Checker
+	repo        *repository.FraudRepository
+	reviewScore int
+	denyScore   int
+	trustProxy  bool
+	proxies     []*net.IPNet
+	log         *zap.Logger
+}
+
+// NewScreener builds a screener; proxies are the trusted proxies skipped in X-Forwarded-For (SERVER_TRUSTED_PROXIES)
+func NewScreener(checker Checker, repo *repository.FraudRepository, cfg config.Fraud, proxies []*net.IPNet, log *zap.Logger) *Screener {
+	return &Screener{
+		checker:     checker,
+		repo:        repo,
+		reviewScore: cfg.ReviewScore,
+		denyScore:   cfg.DenyScore,
+		trustProxy:  cfg.TrustProxy,
+		proxies:     proxies,
+		log:         log,
+	}
+}
+
+// Screen scores a checkout and returns the stored assessment
+// Returns: ErrDenied (with the assessment) when the order must not be charged
+func (s *Screener) Screen(ctx context.Context, signals Signals) (*models.FraudAssessment, error) {
+	result, err := s.checker.Check(ctx, signals)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := result.Decision
+	if decision == "" {
+		decision = s.decide(result.Score)
+	}
+
+	assessment := &models.FraudAssessment{
+		OrderID:  signals.OrderID,
+		UserID:   signals.UserID,
+		Provider: s.checker.Name(),
+		Score:    result.Score,
+		Decision: decision,
+		Reasons:  result.Reasons,
+		Amount:   signals.Amount,
+		Currency: signals.Currency,
+		IP:       signals.IP,
+		DeviceID: signals.DeviceID,
+	}
+	if err := s.repo.Create(ctx, assessment); err != nil {
+		return nil, err
+	}
+
+	if decision != models.FraudAllow {
+		s.log.Warn("Checkout flagged by fraud screening", zap.String("order_id", signals.OrderID),
+			zap.String("decision", decision), zap.Int("score", result.Score), zap.Strings("reasons", result.Reasons))
+	}
+	if decision == models.FraudDeny {
+		return assessment, ErrDenied
+	}
+	return assessment, nil
+}
+
+func (s *Screener) decide(score int) string {
+	switch {
+	case score >= s.denyScore:
+		return models.FraudDeny
+	case score >= s.reviewScore:
+		return models.FraudReview
+	default:
+		return models.FraudAllow
+	}
+}
+
+// RequestSignals fills the device fields of signals from an HTTP request
+// The IP country comes from GeoIP (httpctx.GeoFromContext) when it resolved one.
+// Proxy headers are only trusted with FRAUD_TRUST_PROXY=true, since clients can forge them
+func (s *Screener) RequestSignals(r *http.Request, signals *Signals) {
+	signals.UserAgent = r.UserAgent()
+	signals.DeviceID = strings.TrimSpace(r.Header.Get("X-Device-ID"))
+
+	geo := httpctx.GeoFromContext(r.Context())
+	signals.IP = httpctx.ClientIP(r, s.trustProxy, s.proxies)
+	signals.IPCountry = geo.Country
+	if !s.trustProxy {
+		return
+	}
+	if signals.IPCountry == "" {
+		signals.IPCountry = strings.ToUpper(strings.TrimSpace(r.Header.Get("CF-IPCountry")))
+	}
+}
@@ -0,0 +1,94 @@
+package fraud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/hmacsig"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+)
+
+// HTTPChecker delegates scoring to an external fraud service
+// Request: POST signals as JSON, signed with X-Signature: hex(HMAC-SHA256(secret, body))
+// Response: {"score": 0-100, "decision": "allow|review|deny" (optional), "reasons": [...]}
+// When the service fails the built-in rules are used, so an outage never blocks checkout
+type HTTPChecker struct {
+	url      string
+	secret   string
+	fallback Checker
+	client   *http.Client
+	log      *zap.Logger
+}
+
+func NewHTTPChecker(url, secret string, fallback Checker, log *zap.Logger) *HTTPChecker {
+	return &HTTPChecker{
+		url:      url,
+		secret:   secret,
+		fallback: fallback,
+		client:   httpclient.New(3 * time.Second),
+		log:      log,
+	}
+}
+
+func (c *HTTPChecker) Name() string {
+	return "http"
+}
+
+func (c *HTTPChecker) Check(ctx context.Context, signals Signals) (*Assessment, error) {
+	result, err := c.call(ctx, signals)
+	if err != nil {
+		c.log.Warn("Fraud provider unavailable, using built-in rules", zap.String("order_id", signals.OrderID), zap.Error(err))
+		result, err = c.fallback.Check(ctx, signals)
+		if err != nil {
+			return nil, err
+		}
+		result.Reasons = append(result.Reasons, "provider unavailable: scored by built-in rules")
+	}
+	return result, nil
+}
+
+func (c *HTTPChecker) call(ctx context.Context, signals Signals) (*Assessment, error) {
+	body, err := json.Marshal(signals)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", hmacsig.Sign(c.secret, body))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("fraud provider: status %d: %s", resp.StatusCode, detail)
+	}
+
+	var result Assessment
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("fraud provider: decode: %w", err)
+	}
+	if result.Score < 0 || result.Score > 100 {
+		return nil, fmt.Errorf("fraud provider: score %d out of range", result.Score)
+	}
+	switch result.Decision {
+	case "", models.FraudAllow, models.FraudReview, models.FraudDeny:
+	default:
+		return nil, fmt.Errorf("fraud provider: unknown decision %q", result.Decision)
+	}
+	return &result, nil
+}
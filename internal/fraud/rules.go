@@ -0,0 +1,104 @@
+package fraud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/fx"
+)
+
+// Rule weights - added up and capped at 100
+const (
+	scoreVelocity          = 40
+	scoreBillingMismatch   = 20
+	scoreIPCountryMismatch = 25
+	scoreHighValueFirst    = 35
+)
+
+// RulesChecker is the built-in scorer: velocity, country mismatches and high-value first orders
+type RulesChecker struct {
+	cache          cache.Cache
+	converter      *fx.Converter
+	velocityLimit  int
+	velocityWindow time.Duration
+	highValue      int64
+}
+
+func NewRulesChecker(cfg config.Fraud, appCache cache.Cache, converter *fx.Converter) *RulesChecker {
+	return &RulesChecker{
+		cache:          appCache,
+		converter:      converter,
+		velocityLimit:  cfg.VelocityLimit,
+		velocityWindow: cfg.VelocityWindow,
+		highValue:      cfg.HighValueFirstOrder,
+	}
+}
+
+func (c *RulesChecker) Name() string {
+	return "rules"
+}
+
+func (c *RulesChecker) Check(ctx context.Context, signals Signals) (*Assessment, error) {
+	result := &Assessment{Reasons: []string{}}
+	add := func(score int, reason string) {
+		result.Score += score
+		result.Reasons = append(result.Reasons, reason)
+	}
+
+	// Velocity: many checkouts from one account, IP or device in a short window
+	for _, key := range []struct{ kind, value string }{
+		{"user", signals.UserID}, {"ip", signals.IP}, {"device", signals.DeviceID},
+	} {
+		if key.value == "" {
+			continue
+		}
+		count, err := c.cache.Incr(ctx, "fraud:velocity:"+key.kind+":"+key.value, c.velocityWindow)
+		if err != nil {
+			return nil, err
+		}
+		if count > int64(c.velocityLimit) {
+			add(scoreVelocity, fmt.Sprintf("velocity: %d checkouts from this %s", count, key.kind))
+			break
+		}
+	}
+
+	shipping := strings.ToUpper(signals.ShippingCountry)
+	if billing := strings.ToUpper(signals.BillingCountry); billing != "" && shipping != "" && billing != shipping {
+		add(scoreBillingMismatch, "billing country differs from shipping country")
+	}
+	if signals.IPCountry != "" && shipping != "" && !strings.EqualFold(signals.IPCountry, shipping) {
+		add(scoreIPCountryMismatch, "IP country differs from shipping country")
+	}
+
+	if signals.PreviousOrders == 0 && c.highValue > 0 {
+		if c.baseAmount(ctx, signals) >= c.highValue {
+			add(scoreHighValueFirst, "high-value first order")
+		}
+	}
+
+	if result.Score > 100 {
+		result.Score = 100
+	}
+	return result, nil
+}
+
+// baseAmount converts the order amount to the FX base currency the threshold is set in
+// Falls back to the unconverted amount when rates are unavailable - screening must not block checkout
+func (c *RulesChecker) baseAmount(ctx context.Context, signals Signals) int64 {
+	if c.converter == nil {
+		return signals.Amount
+	}
+	snapshot, err := c.converter.Latest(ctx)
+	if err != nil {
+		return signals.Amount
+	}
+	amount, _, err := c.converter.Convert(ctx, signals.Amount, signals.Currency, snapshot.Base)
+	if err != nil {
+		return signals.Amount
+	}
+	return amount
+}
@@ -0,0 +1,222 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrUnknownCurrency is returned when no rate exists for a currency
+	ErrUnknownCurrency = errors.New("unknown currency")
+
+	// ErrRatesUnavailable is returned when rates were never fetched or are older than FX_MAX_AGE
+	ErrRatesUnavailable = errors.New("exchange rates unavailable")
+)
+
+// Snapshot is one fetch of rates against Base (1 Base = Rates[code] code)
+type Snapshot struct {
+	Base      string             `json:"base"`
+	Rates     map[string]float64 `json:"rates"`
+	FetchedAt time.Time          `json:"fetched_at"`
+}
+
+// Rate returns how many units of to one unit of from buys, via the base currency
+func (s *Snapshot) Rate(from, to string) (float64, error) {
+	fromRate, ok := s.Rates[from]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, from)
+	}
+	toRate, ok := s.Rates[to]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, to)
+	}
+	return toRate / fromRate, nil
+}
+
+// Converter serves exchange rates and currency conversion to pricing and reporting
+// Rates are fetched on a schedule (Run) and read from cache, falling back to the database
+// so a provider outage never blocks checkout
+type Converter struct {
+	provider Provider
+	repo     *repository.ExchangeRateRepository
+	cache    cache.Cache
+	base     string
+	interval time.Duration
+	maxAge   time.Duration
+	log      *zap.Logger
+}
+
+func NewConverter(provider Provider, repo *repository.ExchangeRateRepository, c cache.Cache,
+	cfg config.FX, log *zap.Logger) *Converter {
+	return &Converter{
+		provider: provider,
+		repo:     repo,
+		cache:    c,
+		base:     cfg.BaseCurrency,
+		interval: cfg.RefreshInterval,
+		maxAge:   cfg.MaxAge,
+		log:      log,
+	}
+}
+
+// ProviderName reports where rates come from
+func (c *Converter) ProviderName() string {
+	return c.provider.Name()
+}
+
+func (c *Converter) cacheKey() string {
+	return "fx:rates:" + c.base
+}
+
+// Latest returns the current rate snapshot
+// Returns: ErrRatesUnavailable if nothing was fetched yet or the rates are older than FX_MAX_AGE
+func (c *Converter) Latest(ctx context.Context) (*Snapshot, error) {
+	var snapshot Snapshot
+	err := cache.GetJSON(ctx, c.cache, c.cacheKey(), &snapshot)
+	if err != nil {
+		if !errors.Is(err, cache.ErrCacheMiss) {
+			c.log.Warn("Failed to read exchange rates from cache", zap.Error(err))
+		}
+		if err := c.loadFromDB(ctx, &snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(snapshot.Rates) == 0 || time.Since(snapshot.FetchedAt) > c.maxAge {
+		return nil, ErrRatesUnavailable
+	}
+	return &snapshot, nil
+}
+
+// loadFromDB rebuilds the snapshot from the last persisted fetch and re-populates the cache
+func (c *Converter) loadFromDB(ctx context.Context, snapshot *Snapshot) error {
+	rows, err := c.repo.ListRates(ctx, c.base)
+	if err != nil {
+		return err
+	}
+
+	snapshot.Base = c.base
+	snapshot.Rates = make(map[string]float64, len(rows))
+	for _, row := range rows {
+		snapshot.Rates[row.Currency] = row.Rate
+		if row.FetchedAt.After(snapshot.FetchedAt) {
+			snapshot.FetchedAt = row.FetchedAt
+		}
+	}
+
+	if len(rows) > 0 {
+		if err := cache.SetJSON(ctx, c.cache, c.cacheKey(), snapshot, c.interval*2); err != nil {
+			c.log.Warn("Failed to cache exchange rates", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Rate returns how many units of to one unit of from buys
+func (c *Converter) Rate(ctx context.Context, from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, nil
+	}
+
+	snapshot, err := c.Latest(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return snapshot.Rate(from, to)
+}
+
+// Convert converts amount (minor units of from) into minor units of to, rounding half away from zero
+// Example: 1000 USD cents at 129.5 KES/USD -> 129500 KES cents
+func (c *Converter) Convert(ctx context.Context, amount int64, from, to string) (int64, float64, error) {
+	rate, err := c.Rate(ctx, from, to)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	major := float64(amount) / math.Pow10(MinorUnits(from))
+	converted := math.Round(major * rate * math.Pow10(MinorUnits(to)))
+	return int64(converted), rate, nil
+}
+
+// Refresh fetches rates from the provider, persists them and updates the cache
+func (c *Converter) Refresh(ctx context.Context) error {
+	rates, err := c.provider.Fetch(ctx, c.base)
+	if err != nil {
+		return err
+	}
+	rates[c.base] = 1
+
+	snapshot := Snapshot{Base: c.base, Rates: rates, FetchedAt: time.Now().UTC()}
+	if err := c.repo.SaveRates(ctx, c.base, rates, snapshot.FetchedAt); err != nil {
+		return err
+	}
+	if err := cache.SetJSON(ctx, c.cache, c.cacheKey(), snapshot, c.interval*2); err != nil {
+		c.log.Warn("Failed to cache exchange rates", zap.Error(err))
+	}
+
+	c.log.Info("Exchange rates refreshed",
+		zap.String("provider", c.provider.Name()), zap.String("base", c.base), zap.Int("currencies", len(rates)))
+	return nil
+}
+
+func (c *Converter) Name() string {
+	return "fx-refresher"
+}
+
+// Run refreshes rates on startup and then every FX_REFRESH_INTERVAL until ctx is cancelled
+// Run it through lock.Singleton so only one instance calls the provider
+func (c *Converter) Run(ctx context.Context) error {
+	c.refreshOnce(ctx)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			c.refreshOnce(ctx)
+		}
+	}
+}
+
+func (c *Converter) refreshOnce(ctx context.Context) {
+	if err := c.Refresh(ctx); err != nil {
+		c.log.Error("Failed to refresh exchange rates", zap.String("provider", c.provider.Name()), zap.Error(err))
+	}
+}
+
+// zeroDecimal and threeDecimal list ISO 4217 currencies whose minor unit isn't 1/100
+var (
+	zeroDecimal = map[string]bool{
+		"BIF": true, "CLP": true, "DJF": true, "GNF": true, "ISK": true, "JPY": true, "KMF": true,
+		"KRW": true, "PYG": true, "RWF": true, "UGX": true, "VND": true, "VUV": true, "XAF": true,
+		"XOF": true, "XPF": true,
+	}
+	threeDecimal = map[string]bool{
+		"BHD": true, "IQD": true, "JOD": true, "KWD": true, "LYD": true, "OMR": true, "TND": true,
+	}
+)
+
+// MinorUnits returns the number of decimal places of currency (2 unless listed otherwise)
+func MinorUnits(currency string) int {
+	switch currency = strings.ToUpper(currency); {
+	case zeroDecimal[currency]:
+		return 0
+	case threeDecimal[currency]:
+		return 3
+	default:
+		return 2
+	}
+}
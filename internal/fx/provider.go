@@ -0,0 +1,162 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+)
+
+// Provider fetches current exchange rates
+type Provider interface {
+	// Name identifies the provider in logs and API responses
+	Name() string
+
+	// Fetch returns rates against base (1 base = rate currency)
+	Fetch(ctx context.Context, base string) (map[string]float64, error)
+}
+
+// NewProvider creates the provider selected by FX_PROVIDER
+// Returns: error if the provider is unknown or missing credentials
+func NewProvider(cfg config.FX) (Provider, error) {
+	switch cfg.Provider {
+	case "frankfurter":
+		return NewFrankfurterProvider(), nil
+	case "openexchangerates":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("FX_API_KEY must be set for FX_PROVIDER=openexchangerates")
+		}
+		return NewOpenExchangeRatesProvider(cfg.APIKey), nil
+	case "static", "":
+		return NewStaticProvider(cfg.BaseCurrency, cfg.StaticRates)
+	default:
+		return nil, fmt.Errorf("unsupported FX_PROVIDER: %s (must be 'static', 'frankfurter' or 'openexchangerates')", cfg.Provider)
+	}
+}
+
+// StaticProvider serves fixed rates from configuration (development, tests, pegged currencies)
+type StaticProvider struct {
+	base  string
+	rates map[string]float64
+}
+
+// NewStaticProvider parses CODE=rate pairs quoted against base
+func NewStaticProvider(base string, pairs []string) (*StaticProvider, error) {
+	rates := make(map[string]float64, len(pairs))
+	for _, pair := range pairs {
+		code, value, ok := strings.Cut(pair, "=")
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid FX_STATIC_RATES entry %q (want CODE=rate)", pair)
+		}
+		rates[strings.ToUpper(strings.TrimSpace(code))] = rate
+	}
+	return &StaticProvider{base: base, rates: rates}, nil
+}
+
+func (p *StaticProvider) Name() string {
+	return "static"
+}
+
+func (p *StaticProvider) Fetch(ctx context.Context, base string) (map[string]float64, error) {
+	if base != p.base {
+		return nil, fmt.Errorf("static rates are quoted against %s, not %s", p.base, base)
+	}
+	rates := make(map[string]float64, len(p.rates))
+	for code, rate := range p.rates {
+		rates[code] = rate
+	}
+	return rates, nil
+}
+
+// ratesResponse is the subset of the provider payloads we use (both share this shape)
+type ratesResponse struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// fetchJSON GETs endpoint and decodes a ratesResponse
+func fetchJSON(ctx context.Context, client *http.Client, provider, endpoint string) (*ratesResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s fetch: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%s fetch: status %d: %s", provider, resp.StatusCode, detail)
+	}
+
+	var body ratesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%s fetch: decode: %w", provider, err)
+	}
+	return &body, nil
+}
+
+const frankfurterAPI = "https://api.frankfurter.app/latest"
+
+// FrankfurterProvider uses the free European Central Bank reference rates (updated once per working day)
+type FrankfurterProvider struct {
+	client *http.Client
+}
+
+func NewFrankfurterProvider() *FrankfurterProvider {
+	return &FrankfurterProvider{client: httpclient.New(10 * time.Second)}
+}
+
+func (p *FrankfurterProvider) Name() string {
+	return "frankfurter"
+}
+
+func (p *FrankfurterProvider) Fetch(ctx context.Context, base string) (map[string]float64, error) {
+	body, err := fetchJSON(ctx, p.client, p.Name(), frankfurterAPI+"?from="+url.QueryEscape(base))
+	if err != nil {
+		return nil, err
+	}
+	return body.Rates, nil
+}
+
+const openExchangeRatesAPI = "https://openexchangerates.org/api/latest.json"
+
+// OpenExchangeRatesProvider covers ~170 currencies (incl. KES, UGX, TZS)
+// Non-USD bases require a paid plan
+type OpenExchangeRatesProvider struct {
+	appID  string
+	client *http.Client
+}
+
+func NewOpenExchangeRatesProvider(appID string) *OpenExchangeRatesProvider {
+	return &OpenExchangeRatesProvider{appID: appID, client: httpclient.New(10 * time.Second)}
+}
+
+func (p *OpenExchangeRatesProvider) Name() string {
+	return "openexchangerates"
+}
+
+func (p *OpenExchangeRatesProvider) Fetch(ctx context.Context, base string) (map[string]float64, error) {
+	query := url.Values{}
+	query.Set("app_id", p.appID)
+	query.Set("base", base)
+
+	body, err := fetchJSON(ctx, p.client, p.Name(), openExchangeRatesAPI+"?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	return body.Rates, nil
+}
@@ -0,0 +1,27 @@
+package geoip
+
+// currencies maps countries to the currency shoppers there expect prices in
+// Countries not listed get the store's default currency
+var currencies = map[string]string{
+	// East Africa first: the store's home market
+	"KE": "KES", "UG": "UGX", "TZ": "TZS", "RW": "RWF", "BI": "BIF", "ET": "ETB", "SO": "SOS", "SS": "SSP",
+	"NG": "NGN", "GH": "GHS", "ZA": "ZAR", "EG": "EGP", "MA": "MAD", "ZM": "ZMW", "MW": "MWK", "MZ": "MZN",
+	"BW": "BWP", "NA": "NAD", "ZW": "USD", "CD": "CDF", "SN": "XOF", "CI": "XOF", "CM": "XAF",
+
+	"US": "USD", "CA": "CAD", "MX": "MXN", "BR": "BRL", "AR": "ARS", "CL": "CLP", "CO": "COP", "PE": "PEN",
+
+	"GB": "GBP", "CH": "CHF", "NO": "NOK", "SE": "SEK", "DK": "DKK", "PL": "PLN", "CZ": "CZK", "HU": "HUF",
+	"RO": "RON", "BG": "BGN", "TR": "TRY", "UA": "UAH",
+	"AT": "EUR", "BE": "EUR", "CY": "EUR", "DE": "EUR", "EE": "EUR", "ES": "EUR", "FI": "EUR", "FR": "EUR",
+	"GR": "EUR", "HR": "EUR", "IE": "EUR", "IT": "EUR", "LT": "EUR", "LU": "EUR", "LV": "EUR", "MT": "EUR",
+	"NL": "EUR", "PT": "EUR", "SI": "EUR", "SK": "EUR",
+
+	"AE": "AED", "SA": "SAR", "QA": "QAR", "IL": "ILS", "IN": "INR", "PK": "PKR", "CN": "CNY", "JP": "JPY",
+	"KR": "KRW", "SG": "SGD", "HK": "HKD", "MY": "MYR", "ID": "IDR", "TH": "THB", "PH": "PHP", "VN": "VND",
+	"AU": "AUD", "NZ": "NZD",
+}
+
+// CurrencyOf returns the ISO 4217 currency used in country, or "" when not known
+func CurrencyOf(country string) string {
+	return currencies[country]
+}
@@ -0,0 +1,100 @@
+// Package geoip resolves the country a request comes from, once per request, and stores it on
+// the context (httpctx.GeoFromContext). Storefront defaults (display currency, tax region),
+// country sales restrictions, audit logs and fraud signals read it from there.
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"go.uber.org/zap"
+)
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// Locator maps a client IP to a country
+type Locator interface {
+	// Name returns the provider name, e.g. "maxmind"
+	Name() string
+
+	// Country returns the ISO 3166-1 alpha-2 country of ip, or "" when it is unknown
+	Country(ctx context.Context, ip string) (string, error)
+}
+
+// Resolver is the middleware that stores httpctx.Geo on every request
+type Resolver struct {
+	locator    Locator // nil for the none and header providers
+	header     string  // set for the header provider
+	trustProxy bool
+	proxies    []*net.IPNet
+	log        *zap.Logger
+}
+
+// NewResolver returns the resolver selected by GEOIP_PROVIDER
+// proxies are the trusted proxies skipped in X-Forwarded-For (SERVER_TRUSTED_PROXIES)
+func NewResolver(cfg config.GeoIP, proxies []*net.IPNet, appCache cache.Cache, log *zap.Logger) (*Resolver, error) {
+	r := &Resolver{trustProxy: cfg.TrustProxy, proxies: proxies, log: log}
+	switch cfg.Provider {
+	case "", "none":
+	case "header":
+		if cfg.Header == "" {
+			return nil, fmt.Errorf("GEOIP_HEADER must be set for the header geoip provider")
+		}
+		r.header = cfg.Header
+	case "maxmind":
+		if cfg.MaxMindAccountID == "" || cfg.MaxMindLicenseKey == "" {
+			return nil, fmt.Errorf("GEOIP_MAXMIND_ACCOUNT_ID and GEOIP_MAXMIND_LICENSE_KEY must be set for the maxmind geoip provider")
+		}
+		r.locator = NewMaxMind(cfg.MaxMindURL, cfg.MaxMindAccountID, cfg.MaxMindLicenseKey, appCache, cfg.CacheTTL)
+	default:
+		return nil, fmt.Errorf("unsupported GEOIP_PROVIDER: %s (must be none, header or maxmind)", cfg.Provider)
+	}
+	return r, nil
+}
+
+// Middleware stores the client's address and country on the request context
+// A failed lookup leaves the country empty: geolocation only ever refines defaults and checks,
+// it never fails a request. Requests dispatched internally (POST /batch) keep the caller's Geo
+// already on their context.
+func (g *Resolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if httpctx.GeoFromContext(r.Context()).IP != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		geo := httpctx.Geo{IP: g.clientIP(r)}
+		switch {
+		case g.header != "":
+			geo.Country = normalize(r.Header.Get(g.header))
+		case g.locator != nil && geo.IP != "":
+			country, err := g.locator.Country(r.Context(), geo.IP)
+			if err != nil {
+				g.log.Debug("GeoIP lookup failed", zap.String("provider", g.locator.Name()), zap.Error(err))
+			}
+			geo.Country = normalize(country)
+		}
+		next.ServeHTTP(w, r.WithContext(httpctx.WithGeo(r.Context(), geo)))
+	})
+}
+
+// clientIP returns the caller's address; X-Forwarded-For only with GEOIP_TRUST_PROXY=true
+func (g *Resolver) clientIP(r *http.Request) string {
+	return httpctx.ClientIP(r, g.trustProxy, g.proxies)
+}
+
+// normalize upper-cases a country code; anything that isn't one (including Cloudflare's
+// XX for unknown and T1 for Tor) becomes ""
+func normalize(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if !countryPattern.MatchString(country) || country == "XX" || country == "T1" {
+		return ""
+	}
+	return country
+}
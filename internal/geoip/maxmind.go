@@ -0,0 +1,90 @@
+package geoip
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+)
+
+// MaxMind looks countries up with the GeoIP2 / GeoLite2 Country web service
+// Results are cached per IP: addresses rarely move, and the service is billed per query
+type MaxMind struct {
+	url        string
+	accountID  string
+	licenseKey string
+	cache      *cache.Loader
+	ttl        time.Duration
+	client     *http.Client
+}
+
+func NewMaxMind(url, accountID, licenseKey string, appCache cache.Cache, ttl time.Duration) *MaxMind {
+	return &MaxMind{
+		url:        strings.TrimSuffix(url, "/") + "/",
+		accountID:  accountID,
+		licenseKey: licenseKey,
+		cache:      cache.NewLoader(appCache),
+		ttl:        ttl,
+		client:     httpclient.New(2 * time.Second),
+	}
+}
+
+func (m *MaxMind) Name() string {
+	return "maxmind"
+}
+
+func (m *MaxMind) Country(ctx context.Context, ip string) (string, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.IsLoopback() || parsed.IsPrivate() || parsed.IsUnspecified() {
+		return "", nil
+	}
+	country, err := cache.LoadJSON(ctx, m.cache, "geoip:"+parsed.String(), m.ttl, func(ctx context.Context) (*string, error) {
+		country, err := m.lookup(ctx, parsed.String())
+		return &country, err
+	})
+	if err != nil {
+		return "", err
+	}
+	return *country, nil
+}
+
+func (m *MaxMind) lookup(ctx context.Context, ip string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.url+ip, nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(m.accountID, m.licenseKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// Reserved and unknown addresses are an answer, not a failure: cache them as unknown
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("maxmind returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		Country struct {
+			ISOCode string `json:"iso_code"`
+		} `json:"country"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decode maxmind response: %w", err)
+	}
+	return result.Country.ISOCode, nil
+}
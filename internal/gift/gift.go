@@ -0,0 +1,78 @@
+// Package gift validates gift options and prices gift wrapping. Checkout applies it to an
+// order before publishing order.placed; shipping reads the result to print gift packing slips.
+package gift
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+)
+
+// MaxMessageLength is the longest gift message accepted, in characters
+// It has to fit the card printed with the packing slip
+const MaxMessageLength = 300
+
+// ErrInvalidGift wraps problems with an order's gift options
+var ErrInvalidGift = errors.New("invalid gift options")
+
+// NormalizeMessage trims a gift message and checks it can be printed
+// Line breaks are kept; other control characters are rejected
+func NormalizeMessage(message string) (string, error) {
+	message = strings.TrimSpace(message)
+	if !utf8.ValidString(message) {
+		return "", fmt.Errorf("%w: gift message is not valid text", ErrInvalidGift)
+	}
+	if utf8.RuneCountInString(message) > MaxMessageLength {
+		return "", fmt.Errorf("%w: gift message is longer than %d characters", ErrInvalidGift, MaxMessageLength)
+	}
+	for _, r := range message {
+		if unicode.IsControl(r) && r != '\n' {
+			return "", fmt.Errorf("%w: gift message contains control characters", ErrInvalidGift)
+		}
+	}
+	return message, nil
+}
+
+// WrapFee is the gift-wrap charge for items: feePerItem for every wrapped unit
+func WrapFee(items []events.OrderItem, feePerItem int64) int64 {
+	var fee int64
+	for _, item := range items {
+		if item.GiftWrap {
+			fee += feePerItem * int64(item.Quantity)
+		}
+	}
+	return fee
+}
+
+// Apply validates an order's gift options and prices its wrapping
+// A gift message or a wrapped line marks the whole order as a gift. The wrap fee is recorded
+// in order.GiftWrapFee and added to order.Total, so checkout calls it before charging.
+func Apply(order *events.OrderPlaced, feePerItem int64) error {
+	message, err := NormalizeMessage(order.GiftMessage)
+	if err != nil {
+		return err
+	}
+	order.GiftMessage = message
+
+	fee := WrapFee(order.Items, feePerItem)
+	if message != "" || wrapsAny(order.Items) {
+		order.Gift = true
+	}
+	order.Total += fee - order.GiftWrapFee
+	order.GiftWrapFee = fee
+	return nil
+}
+
+// wrapsAny tells whether any line is gift wrapped (the fee can be 0 when wrapping is free)
+func wrapsAny(items []events.OrderItem) bool {
+	for _, item := range items {
+		if item.GiftWrap {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+// Package hmacsig signs and verifies request bodies with a hex-encoded HMAC-SHA256, the scheme
+// payment, carrier and disbursement providers use for their webhooks and callbacks.
+package hmacsig
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Sign returns the hex-encoded HMAC-SHA256 of body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is body's HMAC-SHA256 under secret, compared in constant time
+// The signature may carry a "sha256=" prefix and be in either case. An empty secret or signature
+// never verifies, so a provider without a configured secret rejects every webhook.
+func Verify(secret, signature string, body []byte) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	signature = strings.ToLower(strings.TrimPrefix(signature, "sha256="))
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
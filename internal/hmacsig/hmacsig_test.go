@@ -0,0 +1,34 @@
+package hmacsig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"evt_1"}`)
+	valid := Sign("secret", body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		body      []byte
+		want      bool
+	}{
+		{"valid", "secret", valid, body, true},
+		{"prefixed", "secret", "sha256=" + valid, body, true},
+		{"upper case", "secret", strings.ToUpper(valid), body, true},
+		{"tampered body", "secret", valid, []byte(`{"id":"evt_2"}`), false},
+		{"wrong secret", "other", valid, body, false},
+		{"no signature", "secret", "", body, false},
+		{"no secret", "", Sign("", body), body, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.signature, tt.body); got != tt.want {
+				t.Fatalf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package httpclient
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the upstream while its host's circuit is open
+var ErrCircuitOpen = errors.New("circuit open: upstream is failing")
+
+// Circuit states, as reported in HostStats
+const (
+	StateClosed   = "closed"    // calls go through
+	StateOpen     = "open"      // calls fail fast with ErrCircuitOpen until the cooldown ends
+	StateHalfOpen = "half_open" // one trial call decides whether to close or reopen
+)
+
+// breaker is the circuit breaker and counters of one upstream host
+// failureLimit consecutive failures (transport errors and 5xx answers) open it for cooldown;
+// after that one trial call goes through and its outcome closes or reopens the circuit
+type breaker struct {
+	mu       sync.Mutex
+	host     string
+	state    string
+	failures int       // consecutive
+	openedAt time.Time // when the circuit last opened
+	trial    bool      // a half-open trial call is in flight
+
+	requests, failed, retries, rejected int64
+}
+
+// allow tells whether a call may go to the host now
+func (b *breaker) allow(now time.Time, cooldown time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateOpen:
+		if now.Sub(b.openedAt) < cooldown {
+			b.rejected++
+			return false
+		}
+		b.state = StateHalfOpen
+		b.trial = true
+	case StateHalfOpen:
+		if b.trial {
+			b.rejected++
+			return false
+		}
+		b.trial = true
+	}
+	b.requests++
+	return true
+}
+
+// record counts the outcome of an allowed call
+func (b *breaker) record(ok bool, now time.Time, failureLimit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if ok {
+		b.failures = 0
+		b.state = StateClosed
+		return
+	}
+	b.failed++
+	b.failures++
+	if b.state == StateHalfOpen || (failureLimit > 0 && b.failures >= failureLimit) {
+		b.state = StateOpen
+		b.openedAt = now
+	}
+}
+
+// release ends an allowed call that never reached the host, counting no outcome
+func (b *breaker) release() {
+	b.mu.Lock()
+	b.trial = false
+	b.requests--
+	b.mu.Unlock()
+}
+
+func (b *breaker) retried() {
+	b.mu.Lock()
+	b.retries++
+	b.mu.Unlock()
+}
+
+// HostStats are the circuit state of one upstream host and its counters since startup
+type HostStats struct {
+	Host     string `json:"host"`
+	State    string `json:"state"`
+	Requests int64  `json:"requests"` // attempts sent, retries included
+	Failed   int64  `json:"failed"`   // transport errors and 5xx answers
+	Retries  int64  `json:"retries"`
+	Rejected int64  `json:"rejected"` // failed fast while the circuit was open
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = map[string]*breaker{}
+)
+
+// breakerFor returns the breaker of host, shared by every client in the process
+func breakerFor(host string) *breaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	b, ok := breakers[host]
+	if !ok {
+		b = &breaker{host: host, state: StateClosed}
+		breakers[host] = b
+	}
+	return b
+}
+
+// AllHostStats returns the stats of every upstream host called since startup, sorted by host
+func AllHostStats() []HostStats {
+	breakersMu.Lock()
+	list := make([]*breaker, 0, len(breakers))
+	for _, b := range breakers {
+		list = append(list, b)
+	}
+	breakersMu.Unlock()
+
+	stats := make([]HostStats, 0, len(list))
+	for _, b := range list {
+		b.mu.Lock()
+		stats = append(stats, HostStats{
+			Host: b.host, State: b.state, Requests: b.requests,
+			Failed: b.failed, Retries: b.retries, Rejected: b.rejected,
+		})
+		b.mu.Unlock()
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Host < stats[j].Host })
+	return stats
+}
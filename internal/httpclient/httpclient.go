@@ -0,0 +1,59 @@
+// Package httpclient builds the HTTP clients used to call external APIs
+// Every client shares one connection pool and one limits.Limiter, so a slow upstream can
+// only tie up OUTBOUND_MAX_IN_FLIGHT goroutines across the whole process.
+// Clients retry idempotent requests that failed in transit or got 502/503/504, and stop calling a
+// host whose circuit opened after repeated failures (ErrCircuitOpen) until it has had time to recover.
+// Outbound requests carry the X-Request-ID of the API request they were made for.
+package httpclient
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/limits"
+)
+
+// Limiter caps concurrent outbound requests; unlimited until Configure is called
+var Limiter = limits.NewLimiter("outbound_http", 0, 0)
+
+var (
+	mu       sync.Mutex
+	pool     http.RoundTripper = http.DefaultTransport
+	settings                   = policy{retries: 2, backoff: 200 * time.Millisecond, failureLimit: 5, cooldown: 30 * time.Second}
+)
+
+// Configure applies the outbound capacity settings
+// Call it before creating clients: the connection pool of existing clients is not replaced
+func Configure(cfg config.Capacity) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = cfg.OutboundMaxIdlePerHost
+
+	mu.Lock()
+	pool = transport
+	settings = policy{
+		retries:      cfg.OutboundRetries,
+		backoff:      cfg.OutboundRetryBackoff,
+		failureLimit: cfg.OutboundBreakerFailures,
+		cooldown:     cfg.OutboundBreakerCooldown,
+	}
+	mu.Unlock()
+	Limiter.SetLimits(cfg.OutboundMaxInFlight, cfg.OutboundMaxQueued)
+}
+
+// New returns a client with the given overall request timeout
+func New(timeout time.Duration) *http.Client {
+	mu.Lock()
+	defer mu.Unlock()
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &resilientTransport{base: limits.Transport(Limiter, pool), policy: currentPolicy},
+	}
+}
+
+func currentPolicy() policy {
+	mu.Lock()
+	defer mu.Unlock()
+	return settings
+}
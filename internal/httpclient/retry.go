@@ -0,0 +1,106 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/limits"
+)
+
+// policy is how clients retry and when they stop calling a failing host
+type policy struct {
+	retries      int           // extra attempts for idempotent requests
+	backoff      time.Duration // wait before the first retry; doubles after each
+	failureLimit int           // consecutive failures that open a host's circuit; 0 never opens it
+	cooldown     time.Duration // how long an open circuit fails fast
+}
+
+// resilientTransport adds per-host circuit breaking, retries of idempotent requests and
+// request ID propagation to base
+type resilientTransport struct {
+	base   http.RoundTripper
+	policy func() policy
+}
+
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := t.policy()
+	b := breakerFor(req.URL.Host)
+	if id := httpctx.RequestIDFromContext(req.Context()); id != "" && req.Header.Get(httpctx.RequestIDHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(httpctx.RequestIDHeader, id)
+	}
+
+	attempts := 1
+	if retryable(req) {
+		attempts += max(p.retries, 0)
+	}
+	wait := p.backoff
+	for attempt := 1; ; attempt++ {
+		if !b.allow(time.Now(), p.cooldown) {
+			return nil, fmt.Errorf("%s: %w", req.URL.Host, ErrCircuitOpen)
+		}
+		resp, err := t.base.RoundTrip(req)
+		if errors.Is(err, limits.ErrSaturated) {
+			b.release() // our limit, not the host's fault
+			return nil, err
+		}
+		failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		b.record(!failed, time.Now(), p.failureLimit)
+		if !failed || attempt >= attempts || !shouldRetry(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		// Retry: discard this answer, rewind the body and back off (with jitter)
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+			resp.Body.Close()
+		}
+		if req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		b.retried()
+		timer := time.NewTimer(wait/2 + rand.N(wait/2+1))
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		wait *= 2
+	}
+}
+
+// retryable tells whether req can safely be sent twice: idempotent methods, and POSTs carrying an
+// Idempotency-Key the upstream deduplicates on. Bodies must be replayable.
+func retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// shouldRetry tells whether a failed attempt may succeed if repeated
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package httpctx
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ForwardedForHeader lists the addresses a request passed through, each proxy appending its peer's
+const ForwardedForHeader = "X-Forwarded-For"
+
+// ClientIP returns the address of the client that sent r
+// Without trustProxy it is the peer's address: anyone can send X-Forwarded-For. With it the peer
+// is a proxy, and X-Forwarded-For is read from the right, where proxies append: entries in
+// proxies (SERVER_TRUSTED_PROXIES) are skipped and the first other address is the client.
+// Entries left of it were written by the client itself and are never used, so a forged header
+// can't pose as an internal network or dodge per-IP limits.
+func ClientIP(r *http.Request, trustProxy bool, proxies []*net.IPNet) string {
+	peer := peerIP(r)
+	if !trustProxy {
+		return peer
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values(ForwardedForHeader) {
+		for _, hop := range strings.Split(header, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(hops[i])
+		if ip == nil {
+			// Everything to its right is a trusted proxy, so one of them wrote this; don't guess
+			return peer
+		}
+		if !contains(proxies, ip) || i == 0 {
+			return ip.String()
+		}
+	}
+	return peer
+}
+
+func peerIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+func contains(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package httpctx
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	_, lb, _ := net.ParseCIDR("10.0.0.0/8")
+	_, cdn, _ := net.ParseCIDR("203.0.113.0/24")
+	proxies := []*net.IPNet{lb, cdn}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		trustProxy bool
+		want       string
+	}{
+		{"peer without trust", "198.51.100.7:5000", []string{"10.1.1.1"}, false, "198.51.100.7"},
+		{"no header", "10.0.0.2:5000", nil, true, "10.0.0.2"},
+		{"one proxy", "10.0.0.2:5000", []string{"198.51.100.7"}, true, "198.51.100.7"},
+		{"forged entry on the left", "10.0.0.2:5000", []string{"10.9.9.9, 198.51.100.7"}, true, "198.51.100.7"},
+		{"trusted hops skipped", "10.0.0.2:5000", []string{"1.2.3.4, 198.51.100.7, 203.0.113.5"}, true, "198.51.100.7"},
+		{"several headers", "10.0.0.2:5000", []string{"1.2.3.4", "198.51.100.7, 203.0.113.5"}, true, "198.51.100.7"},
+		{"all trusted", "10.0.0.2:5000", []string{"10.0.0.9, 203.0.113.5"}, true, "10.0.0.9"},
+		{"garbage from a proxy", "10.0.0.2:5000", []string{"1.2.3.4, not-an-ip"}, true, "10.0.0.2"},
+		{"ipv6", "10.0.0.2:5000", []string{"2001:db8::1"}, true, "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				r.Header.Add(ForwardedForHeader, value)
+			}
+			if got := ClientIP(r, tt.trustProxy, proxies); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Package httpctx is the contract for request-scoped values carried on a context: who is
+// calling, which request this is, which tenant it belongs to and where the client is. Middleware sets them once per
+// request; handlers, services and repositories read them with the typed accessors instead of
+// looking at headers or token claims again.
+package httpctx
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// Headers read and written by the setter middleware
+const (
+	RequestIDHeader = "X-Request-ID"
+	TenantHeader    = "X-Tenant-ID"
+)
+
+var (
+	requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+	tenantPattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+)
+
+type (
+	userKey      struct{}
+	requestIDKey struct{}
+	tenantKey    struct{}
+	geoKey       struct{}
+)
+
+// User is the authenticated caller, as verified by auth.Authenticate
+type User struct {
+	ID           string
+	Email        string
+	Role         string
+	Impersonator string   // admin acting as the user; empty unless impersonating
+	Scopes       []string // set for tokens limited to scopes (integrations); empty for sign-in tokens
+}
+
+// WithUser stores the authenticated caller on ctx (used by auth.Authenticate)
+func WithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext returns the authenticated caller
+// Returns: false for anonymous requests and contexts that never passed through auth
+func UserFromContext(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userKey{}).(User)
+	return user, ok
+}
+
+// WithRequestID stores the request's correlation ID on ctx
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request's correlation ID, or "" outside a request
+// (background jobs, event consumers)
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithTenant stores the tenant a request acts for on ctx
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// ValidTenant reports whether tenant is a well-formed tenant ID: lowercase letters, digits and
+// "-", up to 63 characters, starting with a letter or digit
+func ValidTenant(tenant string) bool {
+	return tenantPattern.MatchString(tenant)
+}
+
+// TenantFromContext returns the tenant a request acts for
+// Returns: "" for the default tenant, which is every request of a single-tenant deployment
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// Geo is where a request comes from, as resolved by geoip.Resolver
+type Geo struct {
+	IP      string // client address; the X-Forwarded-For client behind a trusted proxy
+	Country string // ISO 3166-1 alpha-2, upper case; "" when unknown
+}
+
+// WithGeo stores the client's address and country on ctx
+func WithGeo(ctx context.Context, geo Geo) context.Context {
+	return context.WithValue(ctx, geoKey{}, geo)
+}
+
+// GeoFromContext returns where the request comes from
+// Returns: the zero Geo outside a request, or when the lookup is disabled
+func GeoFromContext(ctx context.Context) Geo {
+	geo, _ := ctx.Value(geoKey{}).(Geo)
+	return geo
+}
+
+// RequestID gives every request a correlation ID and echoes it in the X-Request-ID response header
+// A well-formed X-Request-ID from the client or a proxy is kept, so one ID follows a call across
+// services; otherwise a new one is generated. Requests dispatched internally (POST /batch) keep
+// the ID already on their context.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := RequestIDFromContext(r.Context())
+		if id == "" {
+			id = r.Header.Get(RequestIDHeader)
+			if !requestIDPattern.MatchString(id) {
+				id = uuid.NewString()
+			}
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), id)))
+	})
+}
+
+// Tenant stores the tenant named by the X-Tenant-ID header; without the header the request
+// acts for the default tenant (or keeps the tenant already on its context)
+// Malformed tenant IDs are refused with 400 rather than silently served as the default tenant.
+// Nothing checks the caller may act for the tenant, so the API only uses it in development, see
+// auth.Tenant for the tenant of signed tokens.
+func Tenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := r.Header.Get(TenantHeader)
+		if tenant == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !ValidTenant(tenant) {
+			http.Error(w, "Invalid tenant", http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithTenant(r.Context(), tenant)))
+	})
+}
@@ -0,0 +1,112 @@
+package httpctx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string // X-Request-ID sent by the client
+		onCtx    string // ID already on the context (POST /batch operations)
+		want     string // "" means a freshly generated UUID
+	}{
+		{"generated", "", "", ""},
+		{"valid incoming kept", "req-42.a_b:c", "", "req-42.a_b:c"},
+		{"uuid incoming kept", "2b1f6c1e-8f0a-4d4e-9d3b-6f5b0a7c9e21", "", "2b1f6c1e-8f0a-4d4e-9d3b-6f5b0a7c9e21"},
+		{"longest valid kept", strings.Repeat("a", 128), "", strings.Repeat("a", 128)},
+		{"too long regenerated", strings.Repeat("a", 129), "", ""},
+		{"spaces regenerated", "req 42", "", ""},
+		{"header injection regenerated", "req-42\r\nSet-Cookie: x=y", "", ""},
+		{"markup regenerated", "<script>", "", ""},
+		{"context wins over header", "req-from-client", "batch-id", "batch-id"},
+		{"context kept without header", "", "batch-id", "batch-id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.incoming != "" {
+				r.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			if tt.onCtx != "" {
+				r = r.WithContext(WithRequestID(r.Context(), tt.onCtx))
+			}
+
+			var got string
+			rec := httptest.NewRecorder()
+			RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = RequestIDFromContext(r.Context())
+			})).ServeHTTP(rec, r)
+
+			if tt.want == "" {
+				if _, err := uuid.Parse(got); err != nil || got == tt.incoming {
+					t.Fatalf("request ID = %q, want a new UUID", got)
+				}
+			} else if got != tt.want {
+				t.Fatalf("request ID = %q, want %q", got, tt.want)
+			}
+			if echoed := rec.Header().Get(RequestIDHeader); echoed != got {
+				t.Fatalf("%s response header = %q, want %q", RequestIDHeader, echoed, got)
+			}
+		})
+	}
+}
+
+func TestTenant(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		onCtx      string
+		wantStatus int
+		want       string
+	}{
+		{"default tenant", "", "", http.StatusOK, ""},
+		{"header", "acme", "", http.StatusOK, "acme"},
+		{"digits and dashes", "0-shop-2", "", http.StatusOK, "0-shop-2"},
+		{"longest valid", "a" + strings.Repeat("b", 62), "", http.StatusOK, "a" + strings.Repeat("b", 62)},
+		{"context kept without header", "", "acme", http.StatusOK, "acme"},
+		{"header replaces context", "globex", "acme", http.StatusOK, "globex"},
+		{"upper case refused", "Acme", "", http.StatusBadRequest, ""},
+		{"leading dash refused", "-acme", "", http.StatusBadRequest, ""},
+		{"too long refused", "a" + strings.Repeat("b", 63), "", http.StatusBadRequest, ""},
+		{"path refused", "acme/../globex", "", http.StatusBadRequest, ""},
+		{"underscore refused", "_", "", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				r.Header.Set(TenantHeader, tt.header)
+			}
+			if tt.onCtx != "" {
+				r = r.WithContext(WithTenant(r.Context(), tt.onCtx))
+			}
+
+			var got string
+			called := false
+			rec := httptest.NewRecorder()
+			Tenant(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				got = TenantFromContext(r.Context())
+			})).ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if called {
+					t.Fatal("malformed tenant reached the handler")
+				}
+				return
+			}
+			if got != tt.want {
+				t.Fatalf("tenant = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
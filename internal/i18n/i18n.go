@@ -0,0 +1,134 @@
+// Package i18n localizes API messages and emails
+// Catalogs in locales/<tag>.json map the English message to its translation, so code keeps
+// writing plain English and anything missing from a catalog falls back to it. The locale
+// comes from Accept-Language (see Localize) or, for emails, from the user's saved locale.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Default is the source language of every message and the fallback for unsupported locales
+const Default = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalogs maps a locale tag (sw, fr) to its English -> translation messages
+// Loaded once at init; a malformed catalog fails at startup, not mid-request
+var catalogs = mustLoad()
+
+// matcher picks the best supported locale for a list of requested ones
+var matcher = language.NewMatcher(supportedTags())
+
+func mustLoad() map[string]map[string]string {
+	files, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	loaded := make(map[string]map[string]string, len(files))
+	for _, f := range files {
+		data, err := localeFS.ReadFile("locales/" + f.Name())
+		if err != nil {
+			panic(err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: parse %s: %v", f.Name(), err))
+		}
+		loaded[strings.TrimSuffix(f.Name(), path.Ext(f.Name()))] = messages
+	}
+	return loaded
+}
+
+// Supported lists the available locales, English first
+func Supported() []string {
+	locales := make([]string, 0, len(catalogs)+1)
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return append([]string{Default}, locales...)
+}
+
+func supportedTags() []language.Tag {
+	locales := Supported()
+	tags := make([]language.Tag, len(locales))
+	for i, locale := range locales {
+		tags[i] = language.MustParse(locale)
+	}
+	return tags
+}
+
+// Negotiate returns the supported locale that best matches an Accept-Language value
+// such as "sw-KE,sw;q=0.9,en;q=0.8"; a bare tag ("fr", "fr-CA") works too
+// Returns: Default when nothing requested is supported or the header is malformed
+func Negotiate(acceptLanguage string) string {
+	requested, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(requested) == 0 {
+		return Default
+	}
+	_, index, confidence := matcher.Match(requested...)
+	if confidence == language.No {
+		return Default
+	}
+	return Supported()[index]
+}
+
+// IsSupported reports whether locale has a catalog (or is the default)
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok || locale == Default
+}
+
+// Translate returns message in locale, or message itself when there is no translation
+// Wrapped errors ("invalid product: price cannot be negative") are translated part by part
+func Translate(locale, message string) string {
+	messages := catalogs[locale]
+	if messages == nil || message == "" {
+		return message
+	}
+	if translated, ok := messages[message]; ok {
+		return translated
+	}
+
+	parts := strings.Split(message, ": ")
+	if len(parts) == 1 {
+		return message
+	}
+	for i, part := range parts {
+		if translated, ok := messages[part]; ok {
+			parts[i] = translated
+		}
+	}
+	return strings.Join(parts, ": ")
+}
+
+type localeKey struct{}
+
+// WithLocale stores the negotiated locale on ctx
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext returns the locale stored by WithLocale, or Default
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok {
+		return locale
+	}
+	return Default
+}
+
+// T translates message into the request's locale
+func T(ctx context.Context, message string) string {
+	return Translate(FromContext(ctx), message)
+}
@@ -0,0 +1,87 @@
+package i18n
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Localize negotiates the locale from Accept-Language, stores it on the request context
+// and translates plain-text error responses (http.Error) into it
+// JSON bodies are left alone - handlers that build localized JSON call T themselves
+func Localize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := Negotiate(r.Header.Get("Accept-Language"))
+		w.Header().Add("Vary", "Accept-Language")
+		w.Header().Set("Content-Language", locale)
+		r = r.WithContext(WithLocale(r.Context(), locale))
+
+		if locale == Default {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		lw := &localizedWriter{ResponseWriter: w}
+		next.ServeHTTP(lw, r)
+		if !lw.buffering {
+			return
+		}
+
+		message := Translate(locale, strings.TrimSuffix(lw.body.String(), "\n"))
+		w.WriteHeader(lw.status)
+		w.Write([]byte(message + "\n"))
+	})
+}
+
+// localizedWriter holds back plain-text error bodies so Localize can translate them
+type localizedWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+func (lw *localizedWriter) WriteHeader(status int) {
+	if lw.wroteHeader {
+		return
+	}
+	lw.wroteHeader = true
+	lw.status = status
+	lw.buffering = status >= 400 && strings.HasPrefix(lw.Header().Get("Content-Type"), "text/plain")
+	if !lw.buffering {
+		lw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (lw *localizedWriter) Write(p []byte) (int, error) {
+	if !lw.wroteHeader {
+		lw.WriteHeader(http.StatusOK)
+	}
+	if lw.buffering {
+		return lw.body.Write(p)
+	}
+	return lw.ResponseWriter.Write(p)
+}
+
+// Flush keeps streaming responses working; errors are short and sent once complete
+func (lw *localizedWriter) Flush() {
+	if lw.buffering {
+		return
+	}
+	if f, ok := lw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the dashboard websocket upgrade through the middleware
+func (lw *localizedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := lw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("i18n: response writer does not support hijacking")
+	}
+	return h.Hijack()
+}
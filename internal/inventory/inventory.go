@@ -0,0 +1,432 @@
+// Package inventory decides which warehouses ship an order. Warehouses are ranked by the
+// allocation rule (nearest to the delivery address, or cheapest rate to its delivery zone);
+// the best one that holds the whole basket ships it, otherwise the basket is split across
+// warehouses in rank order so it goes out in as few shipments as the stock allows. A checkout
+// split across several addresses is planned group by group against the same stock.
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/address"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidAllocation wraps problems with the basket or rule
+	ErrInvalidAllocation = errors.New("invalid allocation")
+	// ErrInsufficientStock is returned when the warehouses together can't cover the basket
+	ErrInsufficientStock = repository.ErrInsufficientStock
+)
+
+// earthRadiusKm is the mean Earth radius used for great-circle distances
+const earthRadiusKm = 6371.0
+
+// Allocator plans and commits warehouse allocations
+type Allocator struct {
+	repo  *repository.WarehouseRepository
+	zones *address.Zones
+	rule  string // default rule, INVENTORY_ALLOCATION
+	clock clock.Clock
+}
+
+func NewAllocator(repo *repository.WarehouseRepository, zones *address.Zones, cfg config.Inventory, clk clock.Clock) (*Allocator, error) {
+	if !validRule(cfg.Allocation) {
+		return nil, fmt.Errorf("unknown INVENTORY_ALLOCATION %q (use nearest or cheapest)", cfg.Allocation)
+	}
+	return &Allocator{repo: repo, zones: zones, rule: cfg.Allocation, clock: clk}, nil
+}
+
+func validRule(rule string) bool {
+	return rule == models.AllocateNearest || rule == models.AllocateCheapest
+}
+
+// candidate is an active warehouse with its rank keys for one destination
+type candidate struct {
+	warehouse *models.Warehouse
+	distance  *float64
+	cost      *int64
+	rate      *models.WarehouseRate // for the destination's zone
+}
+
+// Destination is one address of a split checkout and the part of the basket going there
+type Destination struct {
+	Address models.Address
+	Items   []models.AllocationItem
+}
+
+// Allocate plans which warehouses ship items to dest; rule "" means the configured default
+// Nothing is reserved: call Commit in the order transaction, which fails with
+// ErrInsufficientStock if the stock was sold in the meantime
+func (a *Allocator) Allocate(ctx context.Context, dest models.Address, items []models.AllocationItem,
+	rule string) (*models.Allocation, error) {
+	rule, err := a.resolveRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	productIDs, need, err := mergeItems(items)
+	if err != nil {
+		return nil, err
+	}
+	stock, err := a.stockOf(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	return a.plan(ctx, dest, productIDs, need, stock, rule)
+}
+
+// AllocateSplit plans a checkout whose basket goes to several addresses: one shipment group
+// per destination, each with its own shipments, shipping cost and delivery estimate
+// Groups draw on the same stock in the order given, so a unit is never promised twice;
+// commit the whole plan with CommitSplit
+func (a *Allocator) AllocateSplit(ctx context.Context, dests []Destination, rule string) (*models.SplitAllocation, error) {
+	rule, err := a.resolveRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	if len(dests) == 0 {
+		return nil, fmt.Errorf("%w: groups are required", ErrInvalidAllocation)
+	}
+
+	var allIDs []string
+	seen := make(map[string]bool)
+	ids := make([][]string, len(dests))
+	needs := make([]map[string]int, len(dests))
+	for i, dest := range dests {
+		if ids[i], needs[i], err = mergeItems(dest.Items); err != nil {
+			return nil, err
+		}
+		for _, id := range ids[i] {
+			if !seen[id] {
+				seen[id] = true
+				allIDs = append(allIDs, id)
+			}
+		}
+	}
+	stock, err := a.stockOf(ctx, allIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	split := &models.SplitAllocation{Rule: rule, Groups: make([]models.ShipmentGroup, 0, len(dests))}
+	total, totalKnown := int64(0), true
+	for i, dest := range dests {
+		alloc, err := a.plan(ctx, dest.Address, ids[i], needs[i], stock, rule)
+		if err != nil {
+			return nil, fmt.Errorf("group %d: %w", i+1, err)
+		}
+		group := models.ShipmentGroup{AddressID: dest.Address.ID, Zone: alloc.Zone, Shipments: alloc.Shipments}
+		group.ShippingCost, group.Delivery = groupCost(alloc.Shipments), a.groupDelivery(alloc.Shipments)
+		if group.ShippingCost == nil {
+			totalKnown = false
+		} else {
+			total += *group.ShippingCost
+		}
+		split.Groups = append(split.Groups, group)
+	}
+	if totalKnown {
+		split.ShippingCost = &total
+	}
+	return split, nil
+}
+
+func (a *Allocator) resolveRule(rule string) (string, error) {
+	if rule == "" {
+		rule = a.rule
+	}
+	if !validRule(rule) {
+		return "", fmt.Errorf("%w: rule must be nearest or cheapest", ErrInvalidAllocation)
+	}
+	return rule, nil
+}
+
+// mergeItems adds up repeated products; order of first appearance is kept
+func mergeItems(items []models.AllocationItem) ([]string, map[string]int, error) {
+	var productIDs []string
+	need := make(map[string]int)
+	for _, item := range items {
+		if item.ProductID == "" || item.Quantity <= 0 {
+			return nil, nil, fmt.Errorf("%w: every item needs a product_id and a positive quantity", ErrInvalidAllocation)
+		}
+		if _, seen := need[item.ProductID]; !seen {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		need[item.ProductID] += item.Quantity
+	}
+	if len(productIDs) == 0 {
+		return nil, nil, fmt.Errorf("%w: items are required", ErrInvalidAllocation)
+	}
+	return productIDs, need, nil
+}
+
+// stockOf loads what each warehouse holds of the products: warehouse -> product -> quantity
+func (a *Allocator) stockOf(ctx context.Context, productIDs []string) (map[string]map[string]int, error) {
+	rows, err := a.repo.StockOf(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	stock := make(map[string]map[string]int)
+	for _, row := range rows {
+		if stock[row.WarehouseID] == nil {
+			stock[row.WarehouseID] = make(map[string]int)
+		}
+		stock[row.WarehouseID][row.ProductID] = row.Quantity
+	}
+	return stock, nil
+}
+
+// plan allocates need to dest from stock and takes the planned units out of stock,
+// so later plans against the same stock see only what is left
+func (a *Allocator) plan(ctx context.Context, dest models.Address, productIDs []string, need map[string]int,
+	stock map[string]map[string]int, rule string) (*models.Allocation, error) {
+	zone := dest.Zone
+	if zone == "" {
+		zone = a.zones.Resolve(dest)
+	}
+	candidates, err := a.rank(ctx, dest, zone, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	alloc := &models.Allocation{Rule: rule, Zone: zone, Shipments: []models.AllocationShipment{}}
+
+	// One shipment from the best warehouse holding everything, when there is one
+	for _, c := range candidates {
+		held := stock[c.warehouse.ID]
+		if holdsAll(held, need) {
+			shipment := a.newShipment(c)
+			for _, id := range productIDs {
+				held[id] -= need[id]
+				shipment.Items = append(shipment.Items, models.AllocationItem{ProductID: id, Quantity: need[id]})
+			}
+			alloc.Shipments = append(alloc.Shipments, shipment)
+			return alloc, nil
+		}
+	}
+
+	// Otherwise fill from each warehouse in rank order
+	remaining := make(map[string]int, len(need))
+	for id, quantity := range need {
+		remaining[id] = quantity
+	}
+	for _, c := range candidates {
+		held := stock[c.warehouse.ID]
+		shipment := a.newShipment(c)
+		for _, id := range productIDs {
+			take := min(remaining[id], held[id])
+			if take <= 0 {
+				continue
+			}
+			remaining[id] -= take
+			held[id] -= take
+			shipment.Items = append(shipment.Items, models.AllocationItem{ProductID: id, Quantity: take})
+		}
+		if len(shipment.Items) > 0 {
+			alloc.Shipments = append(alloc.Shipments, shipment)
+		}
+	}
+	for _, id := range productIDs {
+		if remaining[id] > 0 {
+			return nil, fmt.Errorf("%w: %d more of product %s needed", ErrInsufficientStock, remaining[id], id)
+		}
+	}
+	return alloc, nil
+}
+
+// Commit deducts an allocation from its warehouses and the products' totals
+// Pass the order transaction as tx so a failed checkout puts the stock back; nil runs alone
+func (a *Allocator) Commit(ctx context.Context, tx *gorm.DB, alloc *models.Allocation) error {
+	return a.repo.Deduct(ctx, tx, stockMoves(alloc))
+}
+
+// Release puts a committed allocation's stock back, e.g. when a checkout fails after Commit
+// Like Commit it joins tx when given one
+func (a *Allocator) Release(ctx context.Context, tx *gorm.DB, alloc *models.Allocation) error {
+	return a.repo.Restock(ctx, tx, stockMoves(alloc))
+}
+
+// stockMoves lists what an allocation takes out of each warehouse
+func stockMoves(alloc *models.Allocation) []repository.StockMove {
+	var moves []repository.StockMove
+	for _, shipment := range alloc.Shipments {
+		for _, item := range shipment.Items {
+			moves = append(moves, repository.StockMove{
+				WarehouseID: shipment.WarehouseID,
+				ProductID:   item.ProductID,
+				Quantity:    item.Quantity,
+			})
+		}
+	}
+	return moves
+}
+
+// CommitSplit deducts every group of a split allocation in one go; see Commit
+func (a *Allocator) CommitSplit(ctx context.Context, tx *gorm.DB, split *models.SplitAllocation) error {
+	var shipments []models.AllocationShipment
+	for _, group := range split.Groups {
+		shipments = append(shipments, group.Shipments...)
+	}
+	return a.Commit(ctx, tx, &models.Allocation{Rule: split.Rule, Shipments: shipments})
+}
+
+// rank orders the active warehouses for dest, best first
+// Warehouses without the rule's key (no coordinates, no rate for the zone) go last,
+// ordered by the other key; code breaks ties so plans are deterministic
+func (a *Allocator) rank(ctx context.Context, dest models.Address, zone, rule string) ([]candidate, error) {
+	warehouses, err := a.repo.List(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]candidate, 0, len(warehouses))
+	for i := range warehouses {
+		w := &warehouses[i]
+		c := candidate{warehouse: w}
+		if w.Latitude != nil && w.Longitude != nil && dest.Latitude != nil && dest.Longitude != nil {
+			d := distanceKm(*w.Latitude, *w.Longitude, *dest.Latitude, *dest.Longitude)
+			c.distance = &d
+		}
+		for j, rate := range w.Rates {
+			if rate.Zone == zone {
+				cost := rate.Cost
+				c.cost, c.rate = &cost, &w.Rates[j]
+				break
+			}
+		}
+		candidates = append(candidates, c)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		var order []int
+		if rule == models.AllocateCheapest {
+			order = []int{compareInt(ci.cost, cj.cost), compareFloat(ci.distance, cj.distance)}
+		} else {
+			order = []int{compareFloat(ci.distance, cj.distance), compareInt(ci.cost, cj.cost)}
+		}
+		for _, cmp := range order {
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return ci.warehouse.Code < cj.warehouse.Code
+	})
+	return candidates, nil
+}
+
+func (a *Allocator) newShipment(c candidate) models.AllocationShipment {
+	shipment := models.AllocationShipment{
+		WarehouseID:   c.warehouse.ID,
+		WarehouseCode: c.warehouse.Code,
+		ShippingCost:  c.cost,
+	}
+	if c.distance != nil {
+		d := math.Round(*c.distance*10) / 10
+		shipment.DistanceKm = &d
+	}
+	if c.rate != nil && c.rate.MaxDays > 0 {
+		shipment.Delivery = a.estimate(c.rate.MinDays, c.rate.MaxDays)
+	}
+	return shipment
+}
+
+// estimate dates a delivery minDays to maxDays from today
+func (a *Allocator) estimate(minDays, maxDays int) *models.DeliveryEstimate {
+	today := a.clock.Now().UTC()
+	return &models.DeliveryEstimate{
+		MinDays:  minDays,
+		MaxDays:  maxDays,
+		Earliest: today.AddDate(0, 0, minDays).Format(time.DateOnly),
+		Latest:   today.AddDate(0, 0, maxDays).Format(time.DateOnly),
+	}
+}
+
+// groupCost is the sum of the shipments' costs; nil when any is unknown
+func groupCost(shipments []models.AllocationShipment) *int64 {
+	var total int64
+	for _, shipment := range shipments {
+		if shipment.ShippingCost == nil {
+			return nil
+		}
+		total += *shipment.ShippingCost
+	}
+	return &total
+}
+
+// groupDelivery is when a group has arrived in full: the slowest shipment's window;
+// nil when any shipment has no estimate
+func (a *Allocator) groupDelivery(shipments []models.AllocationShipment) *models.DeliveryEstimate {
+	minDays, maxDays := 0, 0
+	for _, shipment := range shipments {
+		if shipment.Delivery == nil {
+			return nil
+		}
+		minDays = max(minDays, shipment.Delivery.MinDays)
+		maxDays = max(maxDays, shipment.Delivery.MaxDays)
+	}
+	if maxDays == 0 {
+		return nil
+	}
+	return a.estimate(minDays, maxDays)
+}
+
+func holdsAll(held map[string]int, need map[string]int) bool {
+	for id, quantity := range need {
+		if held[id] < quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// compareFloat orders known values ascending, unknown (nil) last
+func compareFloat(a, b *float64) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	case *a < *b:
+		return -1
+	case *a > *b:
+		return 1
+	}
+	return 0
+}
+
+func compareInt(a, b *int64) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	case *a < *b:
+		return -1
+	case *a > *b:
+		return 1
+	}
+	return 0
+}
+
+// distanceKm is the great-circle (haversine) distance between two coordinates
+func distanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DBQueue stores jobs in the jobs table
+// Claiming uses SELECT ... FOR UPDATE SKIP LOCKED (MySQL 8+, PostgreSQL) so
+// multiple workers and instances never claim the same job
+type DBQueue struct {
+	db    *gorm.DB
+	clock clock.Clock
+}
+
+func NewDBQueue(db *gorm.DB, clk clock.Clock) *DBQueue {
+	return &DBQueue{db: db, clock: clk}
+}
+
+func (q *DBQueue) Enqueue(ctx context.Context, job *models.Job) error {
+	return q.db.WithContext(ctx).Create(job).Error
+}
+
+func (q *DBQueue) Claim(ctx context.Context, lockFor time.Duration) (*models.Job, error) {
+	var claimed *models.Job
+
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := q.clock.Now()
+
+		var candidates []models.Job
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_until <= ?)",
+				models.JobQueued, now, models.JobRunning, now).
+			Order("run_at ASC").
+			Limit(1).
+			Find(&candidates).Error
+		if err != nil || len(candidates) == 0 {
+			return err
+		}
+
+		job := candidates[0]
+		lockedUntil := now.Add(lockFor)
+		job.Status = models.JobRunning
+		job.Attempts++
+		job.LockedUntil = &lockedUntil
+		job.StartedAt = &now
+		if err := tx.Save(&job).Error; err != nil {
+			return err
+		}
+
+		claimed = &job
+		return nil
+	})
+	return claimed, err
+}
+
+func (q *DBQueue) Complete(ctx context.Context, job *models.Job) error {
+	now := q.clock.Now()
+	job.Status = models.JobSucceeded
+	job.FinishedAt = &now
+	job.LockedUntil = nil
+	return q.db.WithContext(ctx).Save(job).Error
+}
+
+func (q *DBQueue) Progress(ctx context.Context, job *models.Job, lockFor time.Duration) error {
+	lockedUntil := q.clock.Now().Add(lockFor)
+	job.LockedUntil = &lockedUntil
+	return q.db.WithContext(ctx).Model(job).Select("done", "total", "result", "locked_until").Updates(job).Error
+}
+
+func (q *DBQueue) Retry(ctx context.Context, job *models.Job, runAt time.Time, cause error) error {
+	job.Status = models.JobQueued
+	job.RunAt = runAt
+	job.LockedUntil = nil
+	job.LastError = cause.Error()
+	return q.db.WithContext(ctx).Save(job).Error
+}
+
+func (q *DBQueue) Bury(ctx context.Context, job *models.Job, cause error) error {
+	now := q.clock.Now()
+	job.Status = models.JobDead
+	job.FinishedAt = &now
+	job.LockedUntil = nil
+	job.LastError = cause.Error()
+	return q.db.WithContext(ctx).Save(job).Error
+}
+
+func (q *DBQueue) Requeue(ctx context.Context, id string) (*models.Job, error) {
+	job, err := q.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.JobDead {
+		return nil, ErrJobNotDead
+	}
+
+	job.Status = models.JobQueued
+	job.Attempts = 0
+	job.RunAt = q.clock.Now()
+	job.FinishedAt = nil
+	if err := q.db.WithContext(ctx).Save(job).Error; err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (q *DBQueue) Get(ctx context.Context, id string) (*models.Job, error) {
+	job := &models.Job{}
+	if err := q.db.WithContext(ctx).Where("id = ?", id).First(job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func (q *DBQueue) List(ctx context.Context, status string, limit, offset int) ([]models.Job, error) {
+	query := q.db.WithContext(ctx).Model(&models.Job{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var jobs []models.Job
+	err := query.Order("updated_at DESC").Limit(limit).Offset(offset).Find(&jobs).Error
+	return jobs, err
+}
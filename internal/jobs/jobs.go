@@ -0,0 +1,109 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrJobNotFound is returned when a job ID doesn't exist (or has expired from Redis)
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrJobNotDead is returned when retrying a job that hasn't exhausted its attempts
+var ErrJobNotDead = errors.New("only dead jobs can be retried")
+
+// Queue stores jobs and hands them to workers
+// Implementations: DBQueue (jobs table, SKIP LOCKED claiming) and RedisQueue (sorted sets)
+type Queue interface {
+	// Enqueue stores a new job ready to run at job.RunAt
+	Enqueue(ctx context.Context, job *models.Job) error
+
+	// Claim locks the next due job for lockFor and marks it running
+	// Returns: nil job (and nil error) when nothing is due
+	// Jobs whose lock expired (crashed worker) are claimed again
+	Claim(ctx context.Context, lockFor time.Duration) (*models.Job, error)
+
+	// Complete marks a claimed job succeeded
+	Complete(ctx context.Context, job *models.Job) error
+
+	// Retry schedules a failed job to run again at runAt
+	Retry(ctx context.Context, job *models.Job, runAt time.Time, cause error) error
+
+	// Bury moves a job that exhausted its attempts to the dead letter state
+	Bury(ctx context.Context, job *models.Job, cause error) error
+
+	// Progress saves a running job's Done, Total and Result and extends its lock by lockFor,
+	// so a long job that keeps reporting is not taken for crashed
+	Progress(ctx context.Context, job *models.Job, lockFor time.Duration) error
+
+	// Requeue moves a dead job back to queued with a fresh attempt budget (manual retry)
+	Requeue(ctx context.Context, id string) (*models.Job, error)
+
+	// Get returns a job by ID, or ErrJobNotFound
+	Get(ctx context.Context, id string) (*models.Job, error)
+
+	// List returns jobs in status, most recent first
+	List(ctx context.Context, status string, limit, offset int) ([]models.Job, error)
+}
+
+// EnqueueOption customizes a job before it is stored
+type EnqueueOption func(*models.Job)
+
+// RunAt delays the job until t
+func RunAt(t time.Time) EnqueueOption {
+	return func(j *models.Job) { j.RunAt = t }
+}
+
+// MaxAttempts overrides the default attempt budget
+func MaxAttempts(n int) EnqueueOption {
+	return func(j *models.Job) { j.MaxAttempts = n }
+}
+
+// NewQueue creates the queue backend selected by JOBS_BACKEND
+// Returns: error if redis is selected but the cache is not Redis-backed
+func NewQueue(cfg *config.Config, db *gorm.DB, c cache.Cache, clk clock.Clock, log *zap.Logger) (Queue, error) {
+	switch cfg.Jobs.Backend {
+	case "redis":
+		redisCache, ok := c.(cache.RedisBacked)
+		if !ok {
+			return nil, errors.New("JOBS_BACKEND=redis requires REDIS_ENABLED=true")
+		}
+		log.Info("Using Redis job queue")
+		return NewRedisQueue(redisCache.Client(), cfg.Redis.KeyPrefix+"jobs:", clk), nil
+	case "db", "":
+		log.Info("Using database job queue")
+		return NewDBQueue(db, clk), nil
+	default:
+		return nil, fmt.Errorf("unsupported JOBS_BACKEND: %s (must be 'db' or 'redis')", cfg.Jobs.Backend)
+	}
+}
+
+// newJob builds a queued job with defaults applied
+func newJob(jobType string, payload interface{}, defaultMaxAttempts int, now time.Time, id string, opts []EnqueueOption) (*models.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s payload: %w", jobType, err)
+	}
+
+	job := &models.Job{
+		ID:          id,
+		Type:        jobType,
+		Payload:     data,
+		Status:      models.JobQueued,
+		MaxAttempts: defaultMaxAttempts,
+		RunAt:       now,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	return job, nil
+}
@@ -0,0 +1,247 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	lockDuration = 5 * time.Minute // a job not finished within this is assumed crashed and re-claimed
+	baseBackoff  = 10 * time.Second
+	maxBackoff   = time.Hour
+)
+
+// Handler runs one job; returning an error schedules a retry (or dead-letters it)
+// Handlers must be idempotent - a job may run more than once after a crash
+type Handler func(ctx context.Context, job *models.Job) error
+
+// Processor enqueues jobs and runs a worker pool that executes them
+// Implements module.Service so it can run inside the API process or standalone (`worker` command)
+type Processor struct {
+	queue       Queue
+	concurrency atomic.Int64 // target worker count, see SetConcurrency
+	running     atomic.Int64 // workers executing a job right now
+	resize      chan struct{}
+	pollEvery   time.Duration
+	maxAttempts int
+	clock       clock.Clock
+	ids         clock.IDGenerator
+	log         *zap.Logger
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewProcessor(queue Queue, cfg config.Jobs, clk clock.Clock, ids clock.IDGenerator, log *zap.Logger) *Processor {
+	p := &Processor{
+		queue:       queue,
+		resize:      make(chan struct{}, 1),
+		pollEvery:   cfg.PollInterval,
+		maxAttempts: cfg.MaxAttempts,
+		clock:       clk,
+		ids:         ids,
+		log:         log,
+		handlers:    make(map[string]Handler),
+	}
+	p.concurrency.Store(int64(cfg.Concurrency))
+	return p
+}
+
+// Concurrency returns the target number of workers
+func (p *Processor) Concurrency() int {
+	return int(p.concurrency.Load())
+}
+
+// Running returns how many workers are executing a job right now
+// Running == Concurrency for long means the pool is saturated
+func (p *Processor) Running() int {
+	return int(p.running.Load())
+}
+
+// SetConcurrency resizes a running worker pool (or sets the size Run starts with)
+// Surplus workers stop after finishing their current job
+func (p *Processor) SetConcurrency(n int) {
+	if p.concurrency.Swap(int64(n)) == int64(n) {
+		return
+	}
+	select {
+	case p.resize <- struct{}{}:
+	default: // a resize is already pending; it reads the latest value
+	}
+}
+
+// Queue exposes the underlying queue for status queries
+func (p *Processor) Queue() Queue {
+	return p.queue
+}
+
+// Register installs the handler for jobType
+// Modules call this from their constructors, before workers start
+func (p *Processor) Register(jobType string, handler Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[jobType] = handler
+}
+
+// Enqueue stores a job of jobType with payload encoded as JSON
+// Returns: the stored job (its ID can be handed to clients for status polling)
+func (p *Processor) Enqueue(ctx context.Context, jobType string, payload interface{}, opts ...EnqueueOption) (*models.Job, error) {
+	job, err := newJob(jobType, payload, p.maxAttempts, p.clock.Now(), p.ids.NewID(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.queue.Enqueue(ctx, job); err != nil {
+		p.log.Error("Failed to enqueue job", zap.String("type", jobType), zap.Error(err))
+		return nil, err
+	}
+	return job, nil
+}
+
+// Progress records that a running job has done of total items, with result as its summary so far
+// Long handlers call it as they go; each call also extends the job's lock, so a job that keeps
+// reporting is never re-claimed as crashed. result may be nil to keep the previous one.
+func (p *Processor) Progress(ctx context.Context, job *models.Job, done, total int, result interface{}) error {
+	job.Done, job.Total = done, total
+	if result != nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		job.Result = data
+	}
+	if err := p.queue.Progress(ctx, job, lockDuration); err != nil {
+		p.log.Warn("Failed to save job progress", zap.String("job_id", job.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (p *Processor) Name() string {
+	return "job-workers"
+}
+
+// Run starts the worker pool and blocks until ctx is cancelled
+// In-flight jobs finish before Run returns (graceful drain)
+func (p *Processor) Run(ctx context.Context) error {
+	p.log.Info("Job workers started", zap.Int("concurrency", p.Concurrency()))
+
+	var wg sync.WaitGroup
+	var workers []context.CancelFunc // one per worker, newest last
+	scale := func(n int) {
+		for len(workers) < n {
+			workerCtx, stop := context.WithCancel(ctx)
+			workers = append(workers, stop)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.work(workerCtx)
+			}()
+		}
+		for len(workers) > n {
+			workers[len(workers)-1]()
+			workers = workers[:len(workers)-1]
+		}
+	}
+	scale(p.Concurrency())
+
+	for running := true; running; {
+		select {
+		case <-ctx.Done():
+			running = false
+		case <-p.resize:
+			from := len(workers)
+			scale(p.Concurrency())
+			p.log.Info("Job worker pool resized", zap.Int("from", from), zap.Int("to", len(workers)))
+		}
+	}
+	wg.Wait()
+
+	p.log.Info("Job workers stopped")
+	return ctx.Err()
+}
+
+// work claims and runs jobs until ctx is cancelled, sleeping when the queue is empty
+func (p *Processor) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := p.queue.Claim(ctx, lockDuration)
+		if err != nil && ctx.Err() == nil {
+			p.log.Error("Failed to claim job", zap.Error(err))
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(p.pollEvery):
+			}
+			continue
+		}
+
+		// Jobs run on a context that survives shutdown signals so they can finish cleanly
+		p.running.Add(1)
+		p.execute(context.WithoutCancel(ctx), job)
+		p.running.Add(-1)
+	}
+}
+
+// execute runs the handler and records success, retry or dead-letter
+func (p *Processor) execute(ctx context.Context, job *models.Job) {
+	p.mu.RLock()
+	handler, ok := p.handlers[job.Type]
+	p.mu.RUnlock()
+
+	err := fmt.Errorf("no handler registered for job type %s", job.Type)
+	if ok {
+		err = p.safeRun(ctx, handler, job)
+	}
+
+	switch {
+	case err == nil:
+		if err := p.queue.Complete(ctx, job); err != nil {
+			p.log.Error("Failed to mark job complete", zap.String("job_id", job.ID), zap.Error(err))
+		}
+	case job.Attempts >= job.MaxAttempts:
+		p.log.Error("Job moved to dead letter", zap.String("job_id", job.ID), zap.String("type", job.Type),
+			zap.Int("attempts", job.Attempts), zap.Error(err))
+		if err := p.queue.Bury(ctx, job, err); err != nil {
+			p.log.Error("Failed to dead-letter job", zap.String("job_id", job.ID), zap.Error(err))
+		}
+	default:
+		runAt := p.clock.Now().Add(backoff(job.Attempts))
+		p.log.Warn("Job failed, will retry", zap.String("job_id", job.ID), zap.String("type", job.Type),
+			zap.Int("attempts", job.Attempts), zap.Time("run_at", runAt), zap.Error(err))
+		if err := p.queue.Retry(ctx, job, runAt, err); err != nil {
+			p.log.Error("Failed to schedule job retry", zap.String("job_id", job.ID), zap.Error(err))
+		}
+	}
+}
+
+// safeRun converts handler panics into errors so one bad job can't kill a worker
+func (p *Processor) safeRun(ctx context.Context, handler Handler, job *models.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// backoff returns exponential delay with jitter: ~10s, 20s, 40s... capped at 1h
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff << (attempts - 1)
+	if delay <= 0 || delay > maxBackoff {
+		delay = maxBackoff
+	}
+	// +/-20% jitter spreads retries of jobs that failed together
+	jitter := time.Duration(rand.Int63n(int64(delay)/5*2+1)) - delay/5
+	return delay + jitter
+}
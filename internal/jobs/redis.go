@@ -0,0 +1,222 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// finishedRetention is how long succeeded jobs stay queryable in Redis
+const finishedRetention = 7 * 24 * time.Hour
+
+// claimScript atomically picks the next claimable job ID and moves it to the running set
+// Expired running jobs (crashed worker) take priority over newly due ones
+// KEYS[1]=queued zset (score=run_at), KEYS[2]=running zset (score=locked_until)
+// ARGV[1]=now (ms), ARGV[2]=locked_until (ms)
+var claimScript = redis.NewScript(`
+local id = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", ARGV[1], "LIMIT", 0, 1)[1]
+if not id then
+	id = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, 1)[1]
+end
+if not id then
+	return false
+end
+redis.call("ZREM", KEYS[1], id)
+redis.call("ZADD", KEYS[2], ARGV[2], id)
+return id
+`)
+
+// RedisQueue stores jobs as JSON strings with one sorted set per status as the index
+// Lower latency than the DB queue and keeps job churn out of the primary database
+type RedisQueue struct {
+	client *redis.Client
+	prefix string
+	clock  clock.Clock
+}
+
+func NewRedisQueue(client *redis.Client, prefix string, clk clock.Clock) *RedisQueue {
+	return &RedisQueue{client: client, prefix: prefix, clock: clk}
+}
+
+func (q *RedisQueue) jobKey(id string) string {
+	return q.prefix + "job:" + id
+}
+
+func (q *RedisQueue) statusKey(status string) string {
+	return q.prefix + "status:" + status
+}
+
+func millis(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
+
+// save writes job JSON and moves its ID from one status index to another in one transaction
+func (q *RedisQueue) save(ctx context.Context, job *models.Job, fromStatus string, score float64, ttl time.Duration) error {
+	job.UpdatedAt = q.clock.Now()
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, q.jobKey(job.ID), data, ttl)
+		if fromStatus != "" && fromStatus != job.Status {
+			pipe.ZRem(ctx, q.statusKey(fromStatus), job.ID)
+		}
+		pipe.ZAdd(ctx, q.statusKey(job.Status), redis.Z{Score: score, Member: job.ID})
+		return nil
+	})
+	return err
+}
+
+func (q *RedisQueue) Enqueue(ctx context.Context, job *models.Job) error {
+	job.CreatedAt = q.clock.Now()
+	return q.save(ctx, job, "", millis(job.RunAt), 0)
+}
+
+func (q *RedisQueue) Claim(ctx context.Context, lockFor time.Duration) (*models.Job, error) {
+	now := q.clock.Now()
+	lockedUntil := now.Add(lockFor)
+
+	id, err := claimScript.Run(ctx, q.client,
+		[]string{q.statusKey(models.JobQueued), q.statusKey(models.JobRunning)},
+		strconv.FormatInt(now.UnixMilli(), 10), strconv.FormatInt(lockedUntil.UnixMilli(), 10),
+	).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := q.Get(ctx, id)
+	if errors.Is(err, ErrJobNotFound) {
+		// Index entry without data (expired) - drop it
+		q.client.ZRem(ctx, q.statusKey(models.JobRunning), id)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job.Status = models.JobRunning
+	job.Attempts++
+	job.LockedUntil = &lockedUntil
+	job.StartedAt = &now
+	if err := q.save(ctx, job, models.JobRunning, millis(lockedUntil), 0); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (q *RedisQueue) Complete(ctx context.Context, job *models.Job) error {
+	now := q.clock.Now()
+	job.Status = models.JobSucceeded
+	job.FinishedAt = &now
+	job.LockedUntil = nil
+	if err := q.save(ctx, job, models.JobRunning, millis(now), finishedRetention); err != nil {
+		return err
+	}
+
+	// Trim index entries whose job data has expired
+	cutoff := strconv.FormatInt(now.Add(-finishedRetention).UnixMilli(), 10)
+	return q.client.ZRemRangeByScore(ctx, q.statusKey(models.JobSucceeded), "-inf", cutoff).Err()
+}
+
+func (q *RedisQueue) Progress(ctx context.Context, job *models.Job, lockFor time.Duration) error {
+	lockedUntil := q.clock.Now().Add(lockFor)
+	job.LockedUntil = &lockedUntil
+	return q.save(ctx, job, models.JobRunning, millis(lockedUntil), 0)
+}
+
+func (q *RedisQueue) Retry(ctx context.Context, job *models.Job, runAt time.Time, cause error) error {
+	job.Status = models.JobQueued
+	job.RunAt = runAt
+	job.LockedUntil = nil
+	job.LastError = cause.Error()
+	return q.save(ctx, job, models.JobRunning, millis(runAt), 0)
+}
+
+func (q *RedisQueue) Bury(ctx context.Context, job *models.Job, cause error) error {
+	now := q.clock.Now()
+	job.Status = models.JobDead
+	job.FinishedAt = &now
+	job.LockedUntil = nil
+	job.LastError = cause.Error()
+	return q.save(ctx, job, models.JobRunning, millis(now), 0)
+}
+
+func (q *RedisQueue) Requeue(ctx context.Context, id string) (*models.Job, error) {
+	job, err := q.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.JobDead {
+		return nil, ErrJobNotDead
+	}
+
+	job.Status = models.JobQueued
+	job.Attempts = 0
+	job.RunAt = q.clock.Now()
+	job.FinishedAt = nil
+	if err := q.save(ctx, job, models.JobDead, millis(job.RunAt), 0); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (q *RedisQueue) Get(ctx context.Context, id string) (*models.Job, error) {
+	data, err := q.client.Get(ctx, q.jobKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.Job{}
+	if err := json.Unmarshal(data, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// List requires a status - Redis indexes jobs per status only
+func (q *RedisQueue) List(ctx context.Context, status string, limit, offset int) ([]models.Job, error) {
+	if status == "" {
+		status = models.JobDead
+	}
+
+	ids, err := q.client.ZRevRange(ctx, q.statusKey(status), int64(offset), int64(offset+limit-1)).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = q.jobKey(id)
+	}
+	values, err := q.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]models.Job, 0, len(values))
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // expired between ZREVRANGE and MGET
+		}
+		var job models.Job
+		if err := json.Unmarshal([]byte(data), &job); err == nil {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
@@ -0,0 +1,229 @@
+package keycloak
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+)
+
+// ErrUserNotFound is returned when no Keycloak user matches a lookup
+var ErrUserNotFound = errors.New("keycloak user not found")
+
+// ErrRoleNotFound is returned when a managed realm role (customer, admin) doesn't exist in the realm
+var ErrRoleNotFound = errors.New("keycloak realm role not found")
+
+// ErrConflict is returned when creating a user whose username/email already exists
+var ErrConflict = errors.New("keycloak user already exists")
+
+// User is the subset of Keycloak's UserRepresentation we manage
+type User struct {
+	ID            string              `json:"id,omitempty"`
+	Username      string              `json:"username"`
+	Email         string              `json:"email"`
+	FirstName     string              `json:"firstName,omitempty"`
+	LastName      string              `json:"lastName,omitempty"`
+	Enabled       bool                `json:"enabled"`
+	EmailVerified bool                `json:"emailVerified"`
+	Attributes    map[string][]string `json:"attributes,omitempty"`
+}
+
+// Role is a Keycloak realm role (RoleRepresentation)
+type Role struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// AdminClient calls the Keycloak Admin REST API as the configured client's service account
+// The client needs the realm-management roles manage-users and view-realm
+type AdminClient struct {
+	baseURL      string
+	realm        string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewAdminClient returns a client for cfg's realm
+// Returns: error if the URL, realm or client credentials are missing
+func NewAdminClient(cfg config.Keycloak) (*AdminClient, error) {
+	if cfg.URL == "" || cfg.Realm == "" || cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return nil, errors.New("KEYCLOAK_URL, KEYCLOAK_REALM, KEYCLOAK_CLIENT_ID and KEYCLOAK_CLIENT_SECRET must be set for admin sync")
+	}
+	if cfg.RoleAuthority != "local" && cfg.RoleAuthority != "keycloak" {
+		return nil, fmt.Errorf("unsupported KEYCLOAK_ROLE_AUTHORITY: %s (must be local or keycloak)", cfg.RoleAuthority)
+	}
+
+	return &AdminClient{
+		baseURL:      strings.TrimRight(cfg.URL, "/"),
+		realm:        cfg.Realm,
+		clientID:     cfg.ClientID,
+		clientSecret: cfg.ClientSecret,
+		client:       httpclient.New(15 * time.Second),
+	}, nil
+}
+
+// accessToken returns a cached service-account token, fetching a new one shortly before expiry
+func (c *AdminClient) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", c.clientID)
+	form.Set("client_secret", c.clientSecret)
+
+	endpoint := c.baseURL + "/realms/" + url.PathEscape(c.realm) + "/protocol/openid-connect/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("keycloak token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("keycloak token: status %d: %s", resp.StatusCode, detail)
+	}
+
+	var out struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("keycloak token: decode: %w", err)
+	}
+
+	c.token = out.AccessToken
+	c.tokenExpiry = time.Now().Add(time.Duration(out.ExpiresIn)*time.Second - 30*time.Second)
+	return c.token, nil
+}
+
+// do sends an admin API request; body and out are JSON (either may be nil)
+// Returns: the response (body already consumed) so callers can read headers
+func (c *AdminClient) do(ctx context.Context, method, endpoint string, body, out interface{}) (*http.Response, error) {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	target := c.baseURL + "/admin/realms/" + url.PathEscape(c.realm) + endpoint
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("keycloak %s %s: %w", method, endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return resp, ErrUserNotFound
+	case resp.StatusCode == http.StatusConflict:
+		return resp, ErrConflict
+	case resp.StatusCode >= 300:
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp, fmt.Errorf("keycloak %s %s: status %d: %s", method, endpoint, resp.StatusCode, detail)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, fmt.Errorf("keycloak %s %s: decode: %w", method, endpoint, err)
+		}
+	}
+	return resp, nil
+}
+
+// CreateUser creates user and returns its Keycloak ID (taken from the Location header)
+// Returns: ErrConflict if the username or email is taken
+func (c *AdminClient) CreateUser(ctx context.Context, user User) (string, error) {
+	resp, err := c.do(ctx, http.MethodPost, "/users", user, nil)
+	if err != nil {
+		return "", err
+	}
+	return path.Base(resp.Header.Get("Location")), nil
+}
+
+// FindUserByEmail returns the user with exactly this email
+func (c *AdminClient) FindUserByEmail(ctx context.Context, email string) (*User, error) {
+	var users []User
+	if _, err := c.do(ctx, http.MethodGet, "/users?exact=true&email="+url.QueryEscape(email), nil, &users); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, ErrUserNotFound
+	}
+	return &users[0], nil
+}
+
+// RealmRoles returns the realm roles directly assigned to a user
+func (c *AdminClient) RealmRoles(ctx context.Context, userID string) ([]Role, error) {
+	var roles []Role
+	_, err := c.do(ctx, http.MethodGet, "/users/"+url.PathEscape(userID)+"/role-mappings/realm", nil, &roles)
+	return roles, err
+}
+
+// Role looks up a realm role by name (assignments need its ID)
+func (c *AdminClient) Role(ctx context.Context, name string) (*Role, error) {
+	var role Role
+	_, err := c.do(ctx, http.MethodGet, "/roles/"+url.PathEscape(name), nil, &role)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrRoleNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// AddRealmRoles assigns realm roles to a user
+func (c *AdminClient) AddRealmRoles(ctx context.Context, userID string, roles []Role) error {
+	_, err := c.do(ctx, http.MethodPost, "/users/"+url.PathEscape(userID)+"/role-mappings/realm", roles, nil)
+	return err
+}
+
+// RemoveRealmRoles removes realm role assignments from a user
+func (c *AdminClient) RemoveRealmRoles(ctx context.Context, userID string, roles []Role) error {
+	_, err := c.do(ctx, http.MethodDelete, "/users/"+url.PathEscape(userID)+"/role-mappings/realm", roles, nil)
+	return err
+}
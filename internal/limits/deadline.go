@@ -0,0 +1,95 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Deadlines gives every request a deadline: the timeout of its route from routes, or defaultTimeout
+// Routes are keyed "METHOD /path/{template}" or "/path/{template}" relative to prefix; 0 means no deadline.
+// The deadline is on the request context, so database queries and outbound calls made with it
+// fail fast once the budget is spent. A handler that then fails with a 5xx answers 504 instead.
+// WebSocket and event-stream requests are exempt, like they are from the HTTP limiter.
+func Deadlines(defaultTimeout time.Duration, routes map[string]time.Duration, prefix string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout := routeTimeout(r, defaultTimeout, routes, prefix)
+			if timeout <= 0 || isLongLived(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(&deadlineWriter{ResponseWriter: w, ctx: ctx}, r.WithContext(ctx))
+		})
+	}
+}
+
+// routeTimeout looks up the timeout of the route r matched
+func routeTimeout(r *http.Request, defaultTimeout time.Duration, routes map[string]time.Duration, prefix string) time.Duration {
+	route := mux.CurrentRoute(r)
+	if route == nil || len(routes) == 0 {
+		return defaultTimeout
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return defaultTimeout
+	}
+	template = strings.TrimPrefix(template, prefix)
+	if timeout, ok := routes[r.Method+" "+template]; ok {
+		return timeout
+	}
+	if timeout, ok := routes[template]; ok {
+		return timeout
+	}
+	return defaultTimeout
+}
+
+// deadlineWriter turns server errors written after the deadline passed into 504 Gateway Timeout
+type deadlineWriter struct {
+	http.ResponseWriter
+	ctx      context.Context
+	timedOut bool
+	wrote    bool
+}
+
+func (w *deadlineWriter) WriteHeader(status int) {
+	if w.wrote {
+		return
+	}
+	w.wrote = true
+	if status >= http.StatusInternalServerError && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut = true
+		http.Error(w.ResponseWriter, "Request timed out", http.StatusGatewayTimeout)
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *deadlineWriter) Write(b []byte) (int, error) {
+	if !w.wrote {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.timedOut {
+		return len(b), nil // the 504 body is already written
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush keeps streamed responses (exports) streaming
+func (w *deadlineWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *deadlineWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,104 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Middleware runs at most the limiter's limit of requests at once; others wait up to wait
+// for a slot and get 503 with Retry-After when the queue is full or the wait runs out
+// WebSocket and event-stream requests stay open indefinitely and bypass the limiter, as do
+// requests dispatched internally by one already admitted (POST /batch sub-operations)
+func Middleware(l *Limiter, wait time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isLongLived(r) || r.Context().Value(admittedKey{}) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), wait)
+			err := l.Acquire(ctx)
+			cancel()
+			if err != nil {
+				if r.Context().Err() != nil {
+					return // client went away while queued
+				}
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, "Server busy, retry shortly", http.StatusServiceUnavailable)
+				return
+			}
+			defer l.Release()
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), admittedKey{}, true)))
+		})
+	}
+}
+
+// admittedKey marks a request context that already holds a slot
+type admittedKey struct{}
+
+func isLongLived(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// Transport limits concurrent requests made through base
+// A slot is held until the response body is closed, since reading it still ties up the upstream
+func Transport(l *Limiter, base http.RoundTripper) http.RoundTripper {
+	return &transport{limiter: l, base: base}
+}
+
+type transport struct {
+	limiter *Limiter
+	base    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Acquire(req.Context()); err != nil {
+		if errors.Is(err, ErrSaturated) {
+			return nil, &saturatedError{host: req.URL.Host}
+		}
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.limiter.Release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: t.limiter.Release}
+	return resp, nil
+}
+
+// saturatedError names the host a request was refused for; it unwraps to ErrSaturated
+type saturatedError struct {
+	host string
+}
+
+func (e *saturatedError) Error() string {
+	return "outbound request to " + e.host + ": " + ErrSaturated.Error()
+}
+
+func (e *saturatedError) Unwrap() error {
+	return ErrSaturated
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
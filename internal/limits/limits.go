@@ -0,0 +1,171 @@
+// Package limits bounds how much work a process takes on at once
+// A Limiter admits up to Limit concurrent callers and lets up to QueueLimit more wait in
+// FIFO order; anyone beyond that is turned away with ErrSaturated instead of piling up.
+// Both bounds can be changed while the process runs (see PUT /admin/capacity).
+package limits
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrSaturated is returned when every slot is taken and the wait queue is full
+var ErrSaturated = errors.New("capacity saturated")
+
+// Stats are a limiter's bounds, current usage and counters since startup
+type Stats struct {
+	Name       string `json:"name"`
+	Limit      int    `json:"limit"` // 0 = unlimited
+	InFlight   int    `json:"in_flight"`
+	QueueLimit int    `json:"queue_limit"`
+	Queued     int    `json:"queued"`
+	Admitted   int64  `json:"admitted"`
+	Rejected   int64  `json:"rejected"`  // queue was full
+	Abandoned  int64  `json:"abandoned"` // gave up waiting (timeout or cancelled)
+	Saturated  bool   `json:"saturated"` // every slot is in use right now
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []*Limiter
+)
+
+// AllStats returns the stats of every limiter created in this process, sorted by name
+func AllStats() []Stats {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	stats := make([]Stats, 0, len(registry))
+	for _, l := range registry {
+		stats = append(stats, l.Stats())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// Limiter is a resizable counting semaphore with a bounded FIFO wait queue
+type Limiter struct {
+	name string
+
+	mu         sync.Mutex
+	limit      int
+	queueLimit int
+	inFlight   int
+	waiters    []chan struct{}
+	admitted   int64
+	rejected   int64
+	abandoned  int64
+}
+
+// NewLimiter creates a limiter admitting limit concurrent callers (0 = unlimited)
+// with up to queueLimit more waiting, and registers it for AllStats
+func NewLimiter(name string, limit, queueLimit int) *Limiter {
+	l := &Limiter{name: name, limit: limit, queueLimit: queueLimit}
+
+	registryMu.Lock()
+	registry = append(registry, l)
+	registryMu.Unlock()
+	return l
+}
+
+// Acquire takes a slot, waiting in line until one frees up or ctx is done
+// Returns: ErrSaturated when the queue is full, ctx.Err() when waiting was abandoned
+// Every successful Acquire must be paired with a Release
+func (l *Limiter) Acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if l.hasRoom() && len(l.waiters) == 0 {
+		l.inFlight++
+		l.admitted++
+		l.mu.Unlock()
+		return nil
+	}
+	if len(l.waiters) >= l.queueLimit {
+		l.rejected++
+		l.mu.Unlock()
+		return ErrSaturated
+	}
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-ready:
+		// Handed a slot just as we gave up - pass it on to the next in line
+		l.inFlight--
+		l.admitNext()
+	default:
+		for i, w := range l.waiters {
+			if w == ready {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				break
+			}
+		}
+	}
+	l.abandoned++
+	return ctx.Err()
+}
+
+// Release frees a slot taken by Acquire, handing it to the longest waiting caller
+func (l *Limiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.admitNext()
+}
+
+// SetLimits changes both bounds; callers already in flight keep their slots
+// Raising the limit admits waiting callers straight away
+func (l *Limiter) SetLimits(limit, queueLimit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit, l.queueLimit = limit, queueLimit
+	l.admitNext()
+}
+
+// Limits returns the current bounds
+func (l *Limiter) Limits() (limit, queueLimit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit, l.queueLimit
+}
+
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return Stats{
+		Name:       l.name,
+		Limit:      l.limit,
+		InFlight:   l.inFlight,
+		QueueLimit: l.queueLimit,
+		Queued:     len(l.waiters),
+		Admitted:   l.admitted,
+		Rejected:   l.rejected,
+		Abandoned:  l.abandoned,
+		Saturated:  !l.hasRoom(),
+	}
+}
+
+func (l *Limiter) hasRoom() bool {
+	return l.limit <= 0 || l.inFlight < l.limit
+}
+
+// admitNext hands free slots to waiters in arrival order; l.mu must be held
+func (l *Limiter) admitNext() {
+	for len(l.waiters) > 0 && l.hasRoom() {
+		ready := l.waiters[0]
+		l.waiters = l.waiters[1:]
+		l.inFlight++
+		l.admitted++
+		close(ready)
+	}
+}
@@ -0,0 +1,163 @@
+// Package links builds the HAL-style _links embedded in user, product and order responses
+// (SERVER_RESOURCE_LINKS=true). Links are resolved from named routes on the versioned API
+// router, so they always match the mounted prefix (/api/v1) and the registered paths.
+package links
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// Route names; handlers name their routes with these so links can be built from them
+const (
+	RouteUser          = "user"
+	RouteAddresses     = "user.addresses"
+	RouteNotifications = "notifications"
+	RouteProducts      = "products"
+	RouteProduct       = "product"
+	RouteOrder         = "order"
+	RouteOrderEvents   = "order.events"
+)
+
+// Builder builds links from the versioned router
+// A nil or disabled Builder returns nil Links, which responses omit
+type Builder struct {
+	enabled bool
+
+	mu     sync.RWMutex
+	router *mux.Router
+}
+
+func NewBuilder(enabled bool) *Builder {
+	return &Builder{enabled: enabled}
+}
+
+// Bind sets the router links are resolved against; the API server binds its /api/v1 subrouter
+// once every module has registered its routes
+func (b *Builder) Bind(router *mux.Router) {
+	b.mu.Lock()
+	b.router = router
+	b.mu.Unlock()
+}
+
+// Enabled reports whether responses should carry links
+func (b *Builder) Enabled() bool {
+	return b != nil && b.enabled
+}
+
+// Href returns the path of the named route with its variables filled in (name, value pairs)
+// It returns "" when the route isn't registered, e.g. a module that is not enabled
+func (b *Builder) Href(name string, pairs ...string) string {
+	b.mu.RLock()
+	router := b.router
+	b.mu.RUnlock()
+	if router == nil {
+		return ""
+	}
+	route := router.Get(name)
+	if route == nil {
+		return ""
+	}
+	u, err := route.URLPath(pairs...)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+// User links a user to itself
+func (b *Builder) User(user *models.User) models.Links {
+	if !b.Enabled() || user == nil {
+		return nil
+	}
+	return b.collect(map[string]string{
+		"self": b.Href(RouteUser, "id", user.ID),
+	})
+}
+
+// CurrentUser links the signed-in user to itself and to the collections only they can see
+func (b *Builder) CurrentUser(user *models.User) models.Links {
+	links := b.User(user)
+	if links == nil {
+		return nil
+	}
+	for rel, href := range map[string]string{
+		"addresses":     b.Href(RouteAddresses),
+		"notifications": b.Href(RouteNotifications),
+	} {
+		if href != "" {
+			links[rel] = models.Link{Href: href}
+		}
+	}
+	return links
+}
+
+// Product links a product to itself, the catalog and the rest of its category
+func (b *Builder) Product(id, category string) models.Links {
+	if !b.Enabled() {
+		return nil
+	}
+	products := b.Href(RouteProducts)
+	rels := map[string]string{
+		"self":       b.Href(RouteProduct, "id", id),
+		"collection": products,
+	}
+	if category != "" && products != "" {
+		rels["category"] = products + "?category=" + url.QueryEscape(category)
+	}
+	return b.collect(rels)
+}
+
+// Order links an order to itself and its status event stream
+func (b *Builder) Order(id string) models.Links {
+	if !b.Enabled() {
+		return nil
+	}
+	return b.collect(map[string]string{
+		"self":   b.Href(RouteOrder, "id", id),
+		"events": b.Href(RouteOrderEvents, "id", id),
+	})
+}
+
+// Page links an offset-paginated response to itself and its neighbouring pages
+// The request's other query parameters (filters) are kept
+func (b *Builder) Page(r *http.Request, limit, offset int, hasMore bool) models.Links {
+	if !b.Enabled() {
+		return nil
+	}
+	page := func(offset int) string {
+		query := r.URL.Query()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("offset", strconv.Itoa(offset))
+		return r.URL.Path + "?" + query.Encode()
+	}
+
+	links := models.Links{"self": {Href: page(offset)}}
+	if offset > 0 {
+		links["first"] = models.Link{Href: page(0)}
+		links["prev"] = models.Link{Href: page(max(offset-limit, 0))}
+	}
+	if hasMore {
+		links["next"] = models.Link{Href: page(offset + limit)}
+	}
+	return links
+}
+
+// collect drops relations whose route isn't registered
+func (b *Builder) collect(rels map[string]string) models.Links {
+	links := make(models.Links, len(rels))
+	for rel, href := range rels {
+		if href != "" {
+			links[rel] = models.Link{Href: href}
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return links
+}
@@ -0,0 +1,323 @@
+package loadgen
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+// vegetaTargetsPerScenario caps the size of each vegeta targets file; vegeta loops over it
+const vegetaTargetsPerScenario = 5000
+
+// checkoutMinStock keeps nearly sold-out products out of checkout carts, so checkouts under
+// load aren't refused for stock the scenario itself used up
+const checkoutMinStock = 50
+
+// checkoutAddress is the Nairobi address seeded users check out to, inside the example DELIVERY_ZONES
+var checkoutAddress = models.AddressRequest{
+	Label:      "Load test",
+	Recipient:  "Load Test",
+	Phone:      "+254700000000",
+	Line1:      "Kenyatta Avenue 1",
+	City:       "Nairobi",
+	Region:     "Nairobi",
+	PostalCode: "00100",
+	Country:    "KE",
+}
+
+// k6Data is written to data.json and read by k6.js
+type k6Data struct {
+	BaseURL            string                `json:"base_url"`
+	Password           string                `json:"password"`
+	Users              []string              `json:"users"`
+	AddressIDs         []string              `json:"address_ids"` // address_ids[i] belongs to users[i]
+	ProductIDs         []string              `json:"product_ids"`
+	CheckoutProductIDs []string              `json:"checkout_product_ids"`
+	SearchTerms        []string              `json:"search_terms"`
+	Categories         []string              `json:"categories"`
+	Address            models.AddressRequest `json:"address"`
+	PaymentMethod      string                `json:"payment_method"`
+}
+
+// vegetaTarget is one line of vegeta's JSON target format (vegeta attack -format=json)
+type vegetaTarget struct {
+	Method string              `json:"method"`
+	URL    string              `json:"url"`
+	Body   string              `json:"body,omitempty"` // base64
+	Header map[string][]string `json:"header,omitempty"`
+}
+
+// WriteScenarios writes the k6 script and its data file plus vegeta targets for the
+// login, browse and checkout scenarios to opts.OutDir
+// Checkout quotes a cart (POST /cart/quote) and pays for it (POST /checkout) to the user's
+// seeded address, so every run places real orders against the configured payment provider.
+func WriteScenarios(seeded *Seeded, opts Options, tokens *auth.TokenManager) ([]string, error) {
+	if len(seeded.Users) == 0 || len(seeded.Products) == 0 {
+		return nil, fmt.Errorf("scenarios need at least one seeded user and product")
+	}
+	var stocked []models.Product
+	for _, product := range seeded.Products {
+		if product.Stock >= checkoutMinStock {
+			stocked = append(stocked, product)
+		}
+	}
+	if len(stocked) == 0 {
+		return nil, fmt.Errorf("checkout needs a seeded product with at least %d units in stock", checkoutMinStock)
+	}
+	if err := os.MkdirAll(opts.OutDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	baseURL := strings.TrimSuffix(opts.BaseURL, "/")
+	rng := rand.New(rand.NewSource(opts.Seed))
+	var written []string
+
+	data := k6Data{
+		BaseURL:       baseURL,
+		Password:      opts.Password,
+		SearchTerms:   SearchTerms(),
+		Categories:    Categories(),
+		Address:       checkoutAddress,
+		PaymentMethod: opts.PaymentMethod,
+	}
+	for i, user := range seeded.Users {
+		data.Users = append(data.Users, user.Email)
+		data.AddressIDs = append(data.AddressIDs, seeded.Addresses[i].ID)
+	}
+	for _, product := range seeded.Products {
+		data.ProductIDs = append(data.ProductIDs, product.ID)
+	}
+	for _, product := range stocked {
+		data.CheckoutProductIDs = append(data.CheckoutProductIDs, product.ID)
+	}
+	if err := writeJSON(filepath.Join(opts.OutDir, "data.json"), data); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(opts.OutDir, "k6.js"), []byte(k6Script), 0o644); err != nil {
+		return nil, err
+	}
+	written = append(written, "data.json", "k6.js")
+
+	scenarios := map[string]func() (vegetaTarget, error){
+		"login": func() (vegetaTarget, error) {
+			user := seeded.Users[rng.Intn(len(seeded.Users))]
+			return jsonTarget(http.MethodPost, baseURL+"/login", "", models.LoginRequest{Email: user.Email, Password: opts.Password})
+		},
+		"browse": func() (vegetaTarget, error) {
+			if rng.Intn(2) == 0 {
+				product := seeded.Products[rng.Intn(len(seeded.Products))]
+				return vegetaTarget{Method: http.MethodGet, URL: baseURL + "/products/" + product.ID}, nil
+			}
+			term := data.SearchTerms[rng.Intn(len(data.SearchTerms))]
+			url := fmt.Sprintf("%s/products/search?q=%s&limit=20", baseURL, strings.ReplaceAll(term, " ", "+"))
+			return vegetaTarget{Method: http.MethodGet, URL: url}, nil
+		},
+		// vegeta can't chain requests, so checkout mixes quotes with pre-authenticated checkouts at
+		// the seeded prices; checkouts carry no Idempotency-Key, so every replay places a new order
+		"checkout": func() (vegetaTarget, error) {
+			i := rng.Intn(len(seeded.Users))
+			user := seeded.Users[i]
+			product := stocked[rng.Intn(len(stocked))]
+			items := []models.CartLine{{ProductID: product.ID, Quantity: 1, UnitPrice: product.Price}}
+			token, _, err := tokens.Issue(&user)
+			if err != nil {
+				return vegetaTarget{}, err
+			}
+			if rng.Intn(2) == 0 {
+				return jsonTarget(http.MethodPost, baseURL+"/cart/quote", token, models.CartQuoteRequest{Items: items})
+			}
+			return jsonTarget(http.MethodPost, baseURL+"/checkout", token, models.CheckoutRequest{
+				Items:         items,
+				AddressID:     seeded.Addresses[i].ID,
+				PaymentMethod: opts.PaymentMethod,
+			})
+		},
+	}
+	for name, next := range scenarios {
+		file := "vegeta-" + name + ".jsonl"
+		if err := writeTargets(filepath.Join(opts.OutDir, file), next); err != nil {
+			return nil, fmt.Errorf("write %s targets: %w", name, err)
+		}
+		written = append(written, file)
+	}
+	return written, nil
+}
+
+func jsonTarget(method, url, token string, body interface{}) (vegetaTarget, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return vegetaTarget{}, err
+	}
+	header := map[string][]string{"Content-Type": {"application/json"}}
+	if token != "" {
+		header["Authorization"] = []string{"Bearer " + token}
+	}
+	return vegetaTarget{
+		Method: method,
+		URL:    url,
+		Body:   base64.StdEncoding.EncodeToString(data),
+		Header: header,
+	}, nil
+}
+
+func writeTargets(path string, next func() (vegetaTarget, error)) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	for i := 0; i < vegetaTargetsPerScenario; i++ {
+		target, err := next()
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(target); err != nil {
+			return err
+		}
+	}
+	return f.Close()
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// k6Script runs the three scenarios as separate k6 scenarios with their own load profiles
+// Tune stages with k6 --env, e.g. k6 run -e BROWSE_RATE=200 k6.js
+const k6Script = `import http from 'k6/http';
+import { check, group, sleep } from 'k6';
+import { SharedArray } from 'k6/data';
+
+const data = JSON.parse(open('./data.json'));
+const users = new SharedArray('users', () => data.users);
+const addressIDs = new SharedArray('addresses', () => data.address_ids);
+const productIDs = new SharedArray('products', () => data.product_ids);
+const checkoutProductIDs = new SharedArray('checkout products', () => data.checkout_product_ids);
+const json = { headers: { 'Content-Type': 'application/json' } };
+
+const rate = (name, fallback) => parseInt(__ENV[name] || fallback, 10);
+
+export const options = {
+  scenarios: {
+    login: {
+      executor: 'constant-arrival-rate', exec: 'login',
+      rate: rate('LOGIN_RATE', 10), timeUnit: '1s', duration: __ENV.DURATION || '2m',
+      preAllocatedVUs: 20, maxVUs: 200,
+    },
+    browse: {
+      executor: 'ramping-arrival-rate', exec: 'browse',
+      startRate: 10, timeUnit: '1s', preAllocatedVUs: 50, maxVUs: 500,
+      stages: [
+        { target: rate('BROWSE_RATE', 100), duration: '30s' },
+        { target: rate('BROWSE_RATE', 100), duration: __ENV.DURATION || '2m' },
+        { target: 0, duration: '15s' },
+      ],
+    },
+    checkout: {
+      executor: 'constant-vus', exec: 'checkout',
+      vus: rate('CHECKOUT_VUS', 10), duration: __ENV.DURATION || '2m',
+    },
+  },
+  thresholds: {
+    'http_req_failed': ['rate<0.01'],
+    'http_req_duration{scenario:browse}': ['p(95)<300'],
+    'http_req_duration{scenario:login}': ['p(95)<500'],
+    'http_req_duration{scenario:checkout}': ['p(95)<800'],
+  },
+};
+
+const pick = (list) => list[Math.floor(Math.random() * list.length)];
+
+// signIn signs in as the i-th seeded user (a random one by default)
+function signIn(i = Math.floor(Math.random() * users.length)) {
+  const res = http.post(data.base_url + '/login',
+    JSON.stringify({ email: users[i], password: data.password }), json);
+  check(res, { 'login 200': (r) => r.status === 200 });
+  return res.status === 200 ? res.json('token') : null;
+}
+
+export function login() {
+  signIn();
+}
+
+export function browse() {
+  const q = encodeURIComponent(pick(data.search_terms));
+  const res = http.get(data.base_url + '/products/search?limit=20&q=' + q +
+    (Math.random() < 0.3 ? '&category=' + pick(data.categories) : ''), { tags: { name: 'search' } });
+  check(res, { 'search 200': (r) => r.status === 200 });
+
+  for (let i = 0; i < 3; i++) {
+    const product = http.get(data.base_url + '/products/' + pick(productIDs), { tags: { name: 'product' } });
+    check(product, { 'product 200': (r) => r.status === 200 });
+  }
+}
+
+export function checkout() {
+  const user = Math.floor(Math.random() * users.length);
+  const token = signIn(user);
+  if (!token) {
+    return;
+  }
+  const auth = { headers: { 'Content-Type': 'application/json', Authorization: 'Bearer ' + token } };
+
+  group('browse', () => {
+    browse();
+  });
+  sleep(1);
+
+  group('delivery address', () => {
+    const validated = http.post(data.base_url + '/addresses/validate', JSON.stringify(data.address), auth);
+    check(validated, { 'validate 200': (r) => r.status === 200 });
+  });
+
+  // The storefront quotes the cart when it shows it, then pays at the quoted prices
+  let quote = null;
+  group('cart quote', () => {
+    const items = [];
+    for (let lines = 1 + Math.floor(Math.random() * 3); lines > 0; lines--) {
+      items.push({ product_id: pick(checkoutProductIDs), quantity: 1 });
+    }
+    const res = http.post(data.base_url + '/cart/quote', JSON.stringify({ items }), auth);
+    check(res, { 'quote 200': (r) => r.status === 200 });
+    if (res.status === 200) {
+      quote = res.json();
+    }
+  });
+  if (!quote) {
+    return;
+  }
+  sleep(1);
+
+  group('place order', () => {
+    const params = {
+      headers: Object.assign({ 'Idempotency-Key': 'k6-' + __VU + '-' + __ITER + '-' + Date.now() }, auth.headers),
+      tags: { name: 'checkout' },
+    };
+    const res = http.post(data.base_url + '/checkout', JSON.stringify({
+      items: quote.items.map((line) => ({ product_id: line.product_id, quantity: line.quantity, unit_price: line.unit_price })),
+      quote_token: quote.quote_token,
+      address_id: addressIDs[user],
+      payment_method: data.payment_method,
+    }), params);
+    check(res, { 'checkout placed': (r) => r.status === 201 || r.status === 202 });
+
+    const orders = http.get(data.base_url + '/orders?limit=10', auth);
+    check(orders, { 'orders 200': (r) => r.status === 200 });
+  });
+  sleep(1);
+}
+`
@@ -0,0 +1,287 @@
+// Package loadgen seeds realistic data volumes and writes k6 and vegeta scenarios
+// for capacity planning. Run it with `go run cmd/main.go loadgen` against a
+// non-production database; seeded rows are tagged so reruns reuse them.
+package loadgen
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// batchSize is how many rows are inserted per statement
+const batchSize = 500
+
+// WarehouseCode is the warehouse seeded products are stocked in, so checkouts can allocate them
+const WarehouseCode = "LOAD-NBO-1"
+
+// orderHistory is how far back seeded orders are spread
+const orderHistory = 90 * 24 * time.Hour
+
+// Options controls how much data is seeded and where scenarios point
+type Options struct {
+	Users    int
+	Products int
+	Orders   int    // past orders spread over the seeded users, for order history and reports
+	Password string // shared by every seeded user
+	// PaymentMethod is what checkout scenarios pay with; the sandbox provider captures anything
+	// but "decline" and "error"
+	PaymentMethod string
+	Currency      string
+	BaseURL       string // API base, e.g. http://localhost:8085/api/v1
+	OutDir        string
+	Seed          int64 // makes generated names, prices and search terms reproducible
+}
+
+// Seeded is what scenarios are generated from
+type Seeded struct {
+	Users     []models.User
+	Addresses []models.Address // Addresses[i] is the delivery address of Users[i]
+	Products  []models.Product
+	Orders    []models.Order
+}
+
+// UserEmail is the address of the i-th seeded user (1-based)
+func UserEmail(i int) string {
+	return fmt.Sprintf("loadtest+%06d@example.com", i)
+}
+
+// ProductSKU is the SKU of the i-th seeded product (1-based)
+func ProductSKU(i int) string {
+	return fmt.Sprintf("LOAD-%06d", i)
+}
+
+// OrderNumber is the order number of the i-th seeded order (1-based)
+func OrderNumber(i int) string {
+	return fmt.Sprintf("LOAD-ORD-%06d", i)
+}
+
+// seededID is a stable ID for rows without a natural unique key, so reruns find them again
+func seededID(kind, key string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte("loadgen:"+kind+":"+key)).String()
+}
+
+var (
+	firstNames = []string{"Amina", "Brian", "Chloe", "David", "Esther", "Felix", "Grace", "Hassan", "Irene", "James", "Kamau", "Linet", "Moses", "Njeri", "Otieno", "Wanjiku"}
+	lastNames  = []string{"Achieng", "Baraka", "Chebet", "Kariuki", "Mutua", "Njoroge", "Odhiambo", "Wafula"}
+	categories = []string{"electronics", "fashion", "groceries", "home", "beauty", "sports", "books", "toys"}
+	adjectives = []string{"Classic", "Compact", "Deluxe", "Eco", "Essential", "Premium", "Smart", "Wireless"}
+	nouns      = map[string][]string{
+		"electronics": {"Headphones", "Charger", "Speaker", "Power Bank", "Smartwatch"},
+		"fashion":     {"Sneakers", "Jacket", "Kikoy", "Backpack", "Sunglasses"},
+		"groceries":   {"Coffee", "Tea Leaves", "Honey", "Rice", "Olive Oil"},
+		"home":        {"Blender", "Kettle", "Lamp", "Cushion", "Cookware Set"},
+		"beauty":      {"Shea Butter", "Shampoo", "Perfume", "Face Serum", "Lotion"},
+		"sports":      {"Football", "Yoga Mat", "Dumbbells", "Running Shorts", "Water Bottle"},
+		"books":       {"Cookbook", "Novel", "Atlas", "Notebook", "Biography"},
+		"toys":        {"Puzzle", "Building Blocks", "Kite", "Board Game", "Plush Toy"},
+	}
+)
+
+// SearchTerms are the words seeded product names are built from, used by the browse scenario
+func SearchTerms() []string {
+	terms := append([]string{}, adjectives...)
+	for _, category := range categories {
+		terms = append(terms, nouns[category]...)
+	}
+	return terms
+}
+
+// Categories lists the categories seeded products are spread over
+func Categories() []string {
+	return append([]string{}, categories...)
+}
+
+// PasswordHash hashes password like UserService.hashPassword, so seeded users can sign in
+func PasswordHash(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+// Seed inserts opts.Users customers with a Nairobi delivery address each, opts.Products active
+// products stocked in the WarehouseCode warehouse, and opts.Orders past orders
+// Rows are written straight to the database: no user.registered or order.placed events, welcome
+// emails or search indexing jobs, so seeding 100k users doesn't flood the queues. Existing rows are kept.
+func Seed(ctx context.Context, db *gorm.DB, opts Options, log *zap.Logger) (*Seeded, error) {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	start := time.Now()
+
+	users := make([]models.User, 0, opts.Users)
+	passwordHash := PasswordHash(opts.Password)
+	for i := 1; i <= opts.Users; i++ {
+		users = append(users, models.User{
+			Email:        UserEmail(i),
+			PasswordHash: passwordHash,
+			FirstName:    firstNames[rng.Intn(len(firstNames))],
+			LastName:     lastNames[rng.Intn(len(lastNames))],
+			Role:         models.RoleCustomer,
+		})
+	}
+	if err := insert(ctx, db, &users, "email", func(u models.User) string { return u.Email }); err != nil {
+		return nil, fmt.Errorf("seed users: %w", err)
+	}
+
+	addresses := make([]models.Address, 0, len(users))
+	for _, user := range users {
+		addresses = append(addresses, models.Address{
+			ID:         seededID("address", user.Email),
+			UserID:     user.ID,
+			Label:      checkoutAddress.Label,
+			Recipient:  user.FirstName + " " + user.LastName,
+			Phone:      checkoutAddress.Phone,
+			Line1:      checkoutAddress.Line1,
+			City:       checkoutAddress.City,
+			Region:     checkoutAddress.Region,
+			PostalCode: checkoutAddress.PostalCode,
+			Country:    checkoutAddress.Country,
+			IsDefault:  true,
+		})
+	}
+	if err := insert(ctx, db, &addresses, "id", func(a models.Address) string { return a.ID }); err != nil {
+		return nil, fmt.Errorf("seed addresses: %w", err)
+	}
+	// Re-read rows come back ordered by ID; keep them lined up with users
+	byUser := make(map[string]models.Address, len(addresses))
+	for _, address := range addresses {
+		byUser[address.UserID] = address
+	}
+	for i, user := range users {
+		addresses[i] = byUser[user.ID]
+	}
+
+	products := make([]models.Product, 0, opts.Products)
+	for i := 1; i <= opts.Products; i++ {
+		category := categories[rng.Intn(len(categories))]
+		name := fmt.Sprintf("%s %s", adjectives[rng.Intn(len(adjectives))], nouns[category][rng.Intn(len(nouns[category]))])
+		products = append(products, models.Product{
+			SKU:         ProductSKU(i),
+			Name:        name,
+			Description: fmt.Sprintf("%s from the %s range.", name, category),
+			Category:    category,
+			// Roughly log-normal spread: mostly cheap items with a long tail of expensive ones
+			Price:    int64(100 * (1 + rng.ExpFloat64()*25)),
+			Currency: opts.Currency,
+			Stock:    rng.Intn(500),
+			Active:   true,
+		})
+	}
+	if err := insert(ctx, db, &products, "sku", func(p models.Product) string { return p.SKU }); err != nil {
+		return nil, fmt.Errorf("seed products: %w", err)
+	}
+	if err := stock(ctx, db, products); err != nil {
+		return nil, fmt.Errorf("seed warehouse stock: %w", err)
+	}
+
+	var orders []models.Order
+	if len(users) > 0 && len(products) > 0 {
+		orders = make([]models.Order, 0, opts.Orders)
+		for i := 1; i <= opts.Orders; i++ {
+			orders = append(orders, seedOrder(rng, i, users[rng.Intn(len(users))], products, start))
+		}
+	}
+	if err := insert(ctx, db, &orders, "id", func(o models.Order) string { return o.ID }); err != nil {
+		return nil, fmt.Errorf("seed orders: %w", err)
+	}
+
+	log.Info("Load test data seeded",
+		zap.Int("users", len(users)),
+		zap.Int("products", len(products)),
+		zap.Int("orders", len(orders)),
+		zap.Duration("took", time.Since(start)),
+	)
+	return &Seeded{Users: users, Addresses: addresses, Products: products, Orders: orders}, nil
+}
+
+// orderStatuses weights seeded order states like a store's history: most orders are delivered
+var orderStatuses = []string{
+	models.OrderStatusDelivered, models.OrderStatusDelivered, models.OrderStatusDelivered, models.OrderStatusDelivered,
+	models.OrderStatusShipped, models.OrderStatusPaid, models.OrderStatusPlaced, models.OrderStatusRefunded,
+}
+
+// seedOrder builds the i-th seeded order: one to four lines of products at their list price,
+// placed by user some time in the orderHistory before now
+func seedOrder(rng *rand.Rand, i int, user models.User, products []models.Product, now time.Time) models.Order {
+	order := models.Order{
+		ID:           seededID("order", OrderNumber(i)),
+		OrderNumber:  OrderNumber(i),
+		UserID:       user.ID,
+		Status:       orderStatuses[rng.Intn(len(orderStatuses))],
+		Channel:      models.ChannelWeb,
+		TaxTreatment: models.TaxInclusive,
+		PlacedAt:     now.Add(-time.Duration(rng.Int63n(int64(orderHistory)))).UTC(),
+	}
+	for lines := 1 + rng.Intn(4); lines > 0; lines-- {
+		product := products[rng.Intn(len(products))]
+		line := models.OrderLine{ProductID: product.ID, Quantity: 1 + rng.Intn(3), UnitPrice: product.Price}
+		order.Items = append(order.Items, line)
+		order.Total += line.UnitPrice * int64(line.Quantity)
+		order.Currency = product.Currency
+	}
+	return order
+}
+
+// stock puts every product's units in the WarehouseCode warehouse, which checkout allocates from
+// Products stocked on an earlier run keep their warehouse stock
+func stock(ctx context.Context, db *gorm.DB, products []models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	warehouses := []models.Warehouse{{
+		Code:    WarehouseCode,
+		Name:    "Load test warehouse",
+		Line1:   "Enterprise Road 1",
+		City:    "Nairobi",
+		Region:  "Nairobi",
+		Country: "KE",
+		Active:  true,
+	}}
+	if err := insert(ctx, db, &warehouses, "code", func(w models.Warehouse) string { return w.Code }); err != nil {
+		return err
+	}
+
+	levels := make([]models.WarehouseStock, 0, len(products))
+	for _, product := range products {
+		levels = append(levels, models.WarehouseStock{WarehouseID: warehouses[0].ID, ProductID: product.ID, Quantity: product.Stock})
+	}
+	return db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&levels, batchSize).Error
+}
+
+// insert creates rows in batches, skipping rows whose unique column already exists
+// Skipped rows keep the ID generated in memory, so they are re-read afterwards
+func insert[T any](ctx context.Context, db *gorm.DB, rows *[]T, uniqueColumn string, key func(T) string) error {
+	if len(*rows) == 0 {
+		return nil
+	}
+
+	err := db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: uniqueColumn}}, DoNothing: true}).
+		CreateInBatches(rows, batchSize).Error
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(*rows))
+	for _, row := range *rows {
+		keys = append(keys, key(row))
+	}
+	var stored []T
+	for i := 0; i < len(keys); i += batchSize {
+		end := min(i+batchSize, len(keys))
+		var batch []T
+		if err := db.WithContext(ctx).Where(uniqueColumn+" IN ?", keys[i:end]).Order(uniqueColumn).Find(&batch).Error; err != nil {
+			return err
+		}
+		stored = append(stored, batch...)
+	}
+	*rows = stored
+	return nil
+}
@@ -0,0 +1,157 @@
+package loadgen
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func newDB(t *testing.T) *gorm.DB {
+	return testutil.NewDB(t, &models.User{}, &models.Address{}, &models.Product{}, &models.Order{},
+		&models.Warehouse{}, &models.WarehouseRate{}, &models.WarehouseStock{})
+}
+
+// TestSeed checks that seeding creates orders, addresses and warehouse stock, and that a rerun
+// reuses every row instead of adding more
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+	db := newDB(t)
+	opts := Options{Users: 20, Products: 50, Orders: 40, Password: "pw", Currency: "KES", Seed: 1}
+
+	for run := 1; run <= 2; run++ {
+		seeded, err := Seed(ctx, db, opts, zap.NewNop())
+		if err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+		if len(seeded.Users) != 20 || len(seeded.Addresses) != 20 || len(seeded.Products) != 50 || len(seeded.Orders) != 40 {
+			t.Fatalf("run %d seeded %d users, %d addresses, %d products, %d orders", run,
+				len(seeded.Users), len(seeded.Addresses), len(seeded.Products), len(seeded.Orders))
+		}
+		for i, user := range seeded.Users {
+			if seeded.Addresses[i].UserID != user.ID {
+				t.Fatalf("run %d: address %d belongs to %s, want %s", run, i, seeded.Addresses[i].UserID, user.ID)
+			}
+		}
+		for _, order := range seeded.Orders {
+			var total int64
+			for _, line := range order.Items {
+				total += line.UnitPrice * int64(line.Quantity)
+			}
+			if len(order.Items) == 0 || order.Total != total || order.Currency != "KES" {
+				t.Fatalf("run %d: order %s has %d lines totalling %d %s, want total %d KES", run,
+					order.OrderNumber, len(order.Items), order.Total, order.Currency, total)
+			}
+		}
+	}
+
+	counts := map[string]int64{}
+	for table, model := range map[string]interface{}{
+		"users": &models.User{}, "addresses": &models.Address{}, "orders": &models.Order{},
+		"warehouses": &models.Warehouse{}, "warehouse_stock": &models.WarehouseStock{},
+	} {
+		var n int64
+		if err := db.Model(model).Count(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+		counts[table] = n
+	}
+	want := map[string]int64{"users": 20, "addresses": 20, "orders": 40, "warehouses": 1, "warehouse_stock": 50}
+	for table, n := range want {
+		if counts[table] != n {
+			t.Errorf("%s has %d rows after two runs, want %d", table, counts[table], n)
+		}
+	}
+
+	// Each product's stock is all in the seeded warehouse, so checkouts can allocate it
+	var mismatched int64
+	err := db.Raw(`SELECT COUNT(*) FROM products p JOIN warehouse_stock s ON s.product_id = p.id
+		WHERE s.quantity != p.stock`).Scan(&mismatched).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mismatched != 0 {
+		t.Errorf("%d products have warehouse stock that differs from their stock", mismatched)
+	}
+}
+
+// TestWriteScenarios checks that checkout targets drive POST /cart/quote and POST /checkout with
+// the signed-in user's own seeded address and in-stock products
+func TestWriteScenarios(t *testing.T) {
+	ctx := context.Background()
+	opts := Options{Users: 10, Products: 100, Orders: 5, Password: "pw", Currency: "KES", Seed: 1,
+		PaymentMethod: "loadtest", BaseURL: "http://localhost:8085/api/v1/", OutDir: t.TempDir()}
+	seeded, err := Seed(ctx, newDB(t), opts, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := testutil.NewTokenManager(clock.System)
+	if _, err := WriteScenarios(seeded, opts, tokens); err != nil {
+		t.Fatal(err)
+	}
+
+	addresses := map[string]string{} // user ID -> address ID
+	for i, user := range seeded.Users {
+		addresses[user.ID] = seeded.Addresses[i].ID
+	}
+	stock := map[string]int{}
+	for _, product := range seeded.Products {
+		stock[product.ID] = product.Stock
+	}
+
+	f, err := os.Open(filepath.Join(opts.OutDir, "vegeta-checkout.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	urls := map[string]int{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var target vegetaTarget
+		if err := json.Unmarshal(scanner.Bytes(), &target); err != nil {
+			t.Fatal(err)
+		}
+		urls[target.URL]++
+		body, err := base64.StdEncoding.DecodeString(target.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var req models.CheckoutRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range req.Items {
+			if stock[line.ProductID] < checkoutMinStock {
+				t.Fatalf("checkout target buys %s with %d in stock", line.ProductID, stock[line.ProductID])
+			}
+		}
+		if target.URL != "http://localhost:8085/api/v1/checkout" {
+			continue
+		}
+		if len(target.Header["Authorization"]) != 1 || len(target.Header["Authorization"][0]) <= len("Bearer ") {
+			t.Fatalf("checkout target without a token: %v", target.Header)
+		}
+		claims, err := tokens.Verify(context.Background(), target.Header["Authorization"][0][len("Bearer "):])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.AddressID == "" || req.AddressID != addresses[claims.UserID()] || req.PaymentMethod != "loadtest" {
+			t.Fatalf("checkout as %s to address %q with %q", claims.UserID(), req.AddressID, req.PaymentMethod)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if urls["http://localhost:8085/api/v1/cart/quote"] == 0 || urls["http://localhost:8085/api/v1/checkout"] == 0 {
+		t.Errorf("checkout targets = %v, want quotes and checkouts", urls)
+	}
+}
@@ -0,0 +1,117 @@
+package lock
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DBLocker takes session-level advisory locks in the primary database
+// Each held lock pins one pooled connection; the database frees the lock when that connection
+// closes, so a crashed instance never blocks others. The ttl is not used - a lock lasts until
+// Release or until its session dies, which Refresh detects.
+type DBLocker struct {
+	db      *sql.DB
+	dialect string
+}
+
+func NewDBLocker(db *gorm.DB, dialect string) (*DBLocker, error) {
+	if dialect != "mysql" && dialect != "postgres" {
+		return nil, fmt.Errorf("advisory locks are not supported on %s", dialect)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return &DBLocker{db: sqlDB, dialect: dialect}, nil
+}
+
+func (l *DBLocker) Backend() string {
+	return l.dialect + "-advisory"
+}
+
+func (l *DBLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var acquired bool
+	switch l.dialect {
+	case "postgres":
+		err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", postgresLockID(key)).Scan(&acquired)
+	default:
+		// GET_LOCK returns 1 when acquired, 0 on timeout (immediately here) and NULL on error
+		var result sql.NullInt64
+		err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", mysqlLockName(key)).Scan(&result)
+		acquired = result.Valid && result.Int64 == 1
+	}
+	if err != nil || !acquired {
+		conn.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrNotAcquired
+	}
+
+	return &dbLock{conn: conn, dialect: l.dialect, key: key}, nil
+}
+
+// dbLock is an advisory lock held by a dedicated connection
+type dbLock struct {
+	conn    *sql.Conn
+	dialect string
+	key     string
+}
+
+// Refresh checks the session holding the lock is still alive; ttl is ignored
+func (l *dbLock) Refresh(ctx context.Context, ttl time.Duration) error {
+	if err := l.conn.PingContext(ctx); err != nil {
+		// The session is gone and the database has released the lock with it
+		return ErrLost
+	}
+	return nil
+}
+
+func (l *dbLock) Release(ctx context.Context) error {
+	var err error
+	switch l.dialect {
+	case "postgres":
+		_, err = l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", postgresLockID(l.key))
+	default:
+		_, err = l.conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", mysqlLockName(l.key))
+	}
+	// Closing returns the connection to the pool; if unlocking failed the session still holds
+	// the lock, so discard the connection instead to make the database release it
+	if err != nil {
+		l.conn.Raw(func(driverConn interface{}) error { return driver.ErrBadConn })
+	}
+	closeErr := l.conn.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// postgresLockID maps a key to the bigint advisory lock space
+func postgresLockID(key string) int64 {
+	sum := sha256.Sum256([]byte("ecomgo:" + key))
+	return int64(binary.BigEndian.Uint64(sum[:8]))
+}
+
+// mysqlLockName keeps lock names under MySQL's 64 character limit
+func mysqlLockName(key string) string {
+	name := "ecomgo:" + key
+	if len(name) <= 64 {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	return "ecomgo:" + hex.EncodeToString(sum[:16])
+}
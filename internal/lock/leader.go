@@ -0,0 +1,103 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Service is a long-running background component (same shape as module.Service)
+type Service interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+// Singleton wraps svc so that across all instances only the current leader runs it
+// Use it for schedulers, pollers and reconcilers whose work must not be done twice
+func Singleton(locker Locker, svc Service, ttl time.Duration, log *zap.Logger) Service {
+	return &singleton{locker: locker, svc: svc, ttl: ttl, log: log}
+}
+
+type singleton struct {
+	locker Locker
+	svc    Service
+	ttl    time.Duration
+	log    *zap.Logger
+}
+
+func (s *singleton) Name() string {
+	return s.svc.Name()
+}
+
+func (s *singleton) Run(ctx context.Context) error {
+	return RunAsLeader(ctx, s.locker, "leader:"+s.svc.Name(), s.ttl, s.log, s.svc.Run)
+}
+
+// RunAsLeader campaigns for key until ctx is cancelled and calls fn each time leadership is won
+// The lease is renewed every ttl/3; fn's context is cancelled as soon as a renewal fails, so
+// a partitioned leader stops before its lease can expire and a successor takes over.
+// Followers retry every ttl/3, so a crashed leader is replaced within about ttl.
+func RunAsLeader(ctx context.Context, locker Locker, key string, ttl time.Duration, log *zap.Logger,
+	fn func(ctx context.Context) error) error {
+	retry := time.NewTicker(ttl / 3)
+	defer retry.Stop()
+
+	for {
+		held, err := locker.TryLock(ctx, key, ttl)
+		switch {
+		case err == nil:
+			log.Info("Acquired leadership", zap.String("key", key))
+			lead(ctx, held, ttl, log, key, fn)
+			log.Info("Gave up leadership", zap.String("key", key))
+		case !errors.Is(err, ErrNotAcquired) && ctx.Err() == nil:
+			log.Warn("Leader election failed", zap.String("key", key), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-retry.C:
+		}
+	}
+}
+
+// lead runs fn while renewing held, then releases it
+func lead(ctx context.Context, held Lock, ttl time.Duration, log *zap.Logger, key string,
+	fn func(ctx context.Context) error) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(leaderCtx)
+	}()
+
+	renew := time.NewTicker(ttl / 3)
+	defer renew.Stop()
+	for {
+		select {
+		case err := <-done:
+			if err != nil && leaderCtx.Err() == nil {
+				log.Error("Leader task stopped", zap.String("key", key), zap.Error(err))
+			}
+			// Released with a fresh context: ctx may already be cancelled by shutdown
+			releaseCtx, cancelRelease := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancelRelease()
+			if err := held.Release(releaseCtx); err != nil {
+				log.Warn("Failed to release leadership", zap.String("key", key), zap.Error(err))
+			}
+			return
+		case <-renew.C:
+			// Once cancelled (shutdown or lost lease) just wait for fn to return
+			if leaderCtx.Err() != nil {
+				continue
+			}
+			if err := held.Refresh(leaderCtx, ttl); err != nil {
+				log.Warn("Lost leadership", zap.String("key", key), zap.Error(err))
+				cancel()
+			}
+		}
+	}
+}
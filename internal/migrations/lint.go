@@ -0,0 +1,252 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Migrations run while the previous release is still serving traffic (blue/green and rolling
+// deploys), so every schema change has to keep working for the old code as well as the new.
+// The linter watches the DDL each migration issues and rejects the statements that don't:
+//
+//   - dropping a table or column a model still maps
+//   - renaming a table or column (the old release still uses the old name)
+//   - changing a column's type or making it NOT NULL on an existing table (rewrites or scans it under lock)
+//   - adding a NOT NULL column without a default (the old release inserts rows without it)
+//   - creating an index on an existing Postgres table without CONCURRENTLY (blocks writes while it builds)
+//
+// Statements against tables created earlier in the same run are never rejected: nothing reads them yet.
+// A migration that is meant to do one of these things is wrapped in Unsafe.
+const (
+	LintEnforce = "enforce" // an unsafe statement fails the migration (default)
+	LintWarn    = "warn"    // unsafe statements are logged and run
+	LintOff     = "off"
+)
+
+var (
+	ErrUnsafeMigration     = errors.New("unsafe migration")
+	ErrUnsupportedLintMode = errors.New("unsupported migration lint mode")
+)
+
+// ParseLintMode validates DB_MIGRATION_LINT; empty means enforce
+func ParseLintMode(mode string) (string, error) {
+	switch mode {
+	case "", LintEnforce:
+		return LintEnforce, nil
+	case LintWarn, LintOff:
+		return mode, nil
+	}
+	return "", ErrUnsupportedLintMode
+}
+
+// Unsafe flags a migration whose destructive or locking statements are intended, e.g. dropping
+// a column one release after the models stopped mapping it. The linter lets its statements
+// through and logs each one with reason.
+func Unsafe(reason string, migration Migration) Migration {
+	return func(db *gorm.DB) error {
+		return migration(db.WithContext(context.WithValue(db.Statement.Context, unsafeKey{}, reason)))
+	}
+}
+
+type linterKey struct{}
+type unsafeKey struct{}
+
+const lintCallback = "migrations:lint"
+
+var (
+	createTablePattern = regexp.MustCompile(`(?is)^CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(\S+)`)
+	createIndexPattern = regexp.MustCompile(`(?is)^CREATE\s+(?:\w+\s+)?INDEX\s+(CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?\S+\s+ON\s+(\S+)`)
+	dropTablePattern   = regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?(\S+)`)
+	alterTablePattern  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(\S+)\s+(.*)$`)
+
+	dropColumnPattern   = regexp.MustCompile(`(?is)^DROP\s+(?:COLUMN\s+)?(\S+)`)
+	renamePattern       = regexp.MustCompile(`(?is)^(?:RENAME\s+(?:COLUMN|TO)\s|CHANGE\s)`)
+	changeTypePattern   = regexp.MustCompile(`(?is)^(?:ALTER\s+COLUMN\s+\S+\s+(?:SET\s+DATA\s+)?TYPE\s|MODIFY\s)`)
+	setNotNullPattern   = regexp.MustCompile(`(?is)^ALTER\s+COLUMN\s+(\S+)\s+SET\s+NOT\s+NULL`)
+	addColumnPattern    = regexp.MustCompile(`(?is)^ADD\s+(?:COLUMN\s+)?(\S+)\s+(.*)$`)
+	notNullPattern      = regexp.MustCompile(`(?i)\bNOT\s+NULL\b`)
+	defaultPattern      = regexp.MustCompile(`(?i)\bDEFAULT\b`)
+	constraintOrIndexes = regexp.MustCompile(`(?i)^(?:CONSTRAINT|INDEX|UNIQUE|PRIMARY|FOREIGN|CHECK)$`)
+)
+
+// linter follows one migration run; migrations run one after another, so it needs no locking
+type linter struct {
+	dialect string
+	mode    string
+	log     *zap.Logger
+
+	created map[string]bool              // tables created in this run
+	mapped  map[string]map[string]string // table -> column -> model mapping it
+	dropped map[string]map[string]bool   // table -> columns dropped in this run
+}
+
+func newLinter(dialect, mode string, log *zap.Logger) *linter {
+	return &linter{
+		dialect: dialect,
+		mode:    mode,
+		log:     log,
+		created: make(map[string]bool),
+		mapped:  make(map[string]map[string]string),
+		dropped: make(map[string]map[string]bool),
+	}
+}
+
+// lintDB returns db with a linter attached to every statement run through it
+// SQLite is only the throwaway development database, so it is never linted
+func lintDB(db *gorm.DB, mode string, log *zap.Logger) (*gorm.DB, error) {
+	if mode == LintOff || db.Dialector.Name() == "sqlite" {
+		return db, nil
+	}
+	if db.Callback().Raw().Get(lintCallback) == nil {
+		if err := db.Callback().Raw().Before("gorm:raw").Register(lintCallback, lintStatement); err != nil {
+			return nil, err
+		}
+	}
+	l := newLinter(db.Dialector.Name(), mode, log)
+	return db.WithContext(context.WithValue(db.Statement.Context, linterKey{}, l)), nil
+}
+
+// lintStatement is the Raw callback: GORM's migrator issues all of its DDL through Exec
+func lintStatement(db *gorm.DB) {
+	l, ok := db.Statement.Context.Value(linterKey{}).(*linter)
+	if !ok || db.Error != nil {
+		return
+	}
+	if err := l.check(db.Statement.Context, db.Statement.SQL.String()); err != nil {
+		_ = db.AddError(err)
+	}
+}
+
+// mapModels records the columns models map, before AutoMigrate runs for them
+func (l *linter) mapModels(db *gorm.DB, models []interface{}) error {
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		table := stmt.Schema.Table
+		if l.mapped[table] == nil {
+			l.mapped[table] = make(map[string]string)
+		}
+		for _, column := range stmt.Schema.DBNames {
+			l.mapped[table][column] = stmt.Schema.Name
+			if l.dropped[table][column] {
+				// AutoMigrate would add the column back, empty
+				problem := fmt.Sprintf("column %s.%s was dropped earlier in this run but %s still maps it",
+					table, column, stmt.Schema.Name)
+				if err := l.report(db.Statement.Context, problem, "AutoMigrate "+stmt.Schema.Name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// check returns ErrUnsafeMigration when sql would break the release still serving traffic
+func (l *linter) check(ctx context.Context, sql string) error {
+	sql = strings.TrimSpace(sql)
+
+	if m := createTablePattern.FindStringSubmatch(sql); m != nil {
+		l.created[identifier(m[1])] = true
+		return nil
+	}
+
+	if m := createIndexPattern.FindStringSubmatch(sql); m != nil {
+		table := identifier(m[2])
+		if l.dialect == "postgres" && m[1] == "" && !l.created[table] {
+			return l.report(ctx, fmt.Sprintf("index on %s is built without CONCURRENTLY and blocks writes to it until done "+
+				"(tag the field with option:CONCURRENTLY)", table), sql)
+		}
+		return nil
+	}
+
+	if m := dropTablePattern.FindStringSubmatch(sql); m != nil {
+		table := identifier(m[1])
+		if columns, ok := l.mapped[table]; ok && !l.created[table] {
+			return l.report(ctx, fmt.Sprintf("table %s is dropped but %s still maps it", table, anyModel(columns)), sql)
+		}
+		return nil
+	}
+
+	m := alterTablePattern.FindStringSubmatch(sql)
+	if m == nil {
+		return nil
+	}
+	table, change := identifier(m[1]), strings.TrimSpace(m[2])
+	if l.created[table] {
+		return nil
+	}
+
+	switch {
+	case renamePattern.MatchString(change):
+		return l.report(ctx, fmt.Sprintf("%s is renamed; the running release still uses the old name", table), sql)
+
+	case changeTypePattern.MatchString(change):
+		return l.report(ctx, fmt.Sprintf("a column type change rewrites %s under lock and may not suit the running release", table), sql)
+
+	case setNotNullPattern.MatchString(change):
+		column := identifier(setNotNullPattern.FindStringSubmatch(change)[1])
+		return l.report(ctx, fmt.Sprintf("making %s.%s NOT NULL scans the table under lock and breaks inserts from "+
+			"the running release", table, column), sql)
+	}
+
+	if d := dropColumnPattern.FindStringSubmatch(change); d != nil {
+		column := identifier(d[1])
+		if constraintOrIndexes.MatchString(column) {
+			return nil
+		}
+		if l.dropped[table] == nil {
+			l.dropped[table] = make(map[string]bool)
+		}
+		l.dropped[table][column] = true
+		if model, ok := l.mapped[table][column]; ok {
+			return l.report(ctx, fmt.Sprintf("column %s.%s is dropped but %s still maps it", table, column, model), sql)
+		}
+		return nil
+	}
+
+	if a := addColumnPattern.FindStringSubmatch(change); a != nil && !constraintOrIndexes.MatchString(identifier(a[1])) {
+		if notNullPattern.MatchString(a[2]) && !defaultPattern.MatchString(a[2]) {
+			return l.report(ctx, fmt.Sprintf("column %s.%s is added NOT NULL without a default; inserts from the running "+
+				"release fail", table, identifier(a[1])), sql)
+		}
+	}
+	return nil
+}
+
+// report applies the Unsafe flag and the lint mode to one problem
+func (l *linter) report(ctx context.Context, problem, statement string) error {
+	if reason, ok := ctx.Value(unsafeKey{}).(string); ok {
+		l.log.Warn("Running unsafe migration statement",
+			zap.String("problem", problem), zap.String("statement", statement), zap.String("reason", reason))
+		return nil
+	}
+	if l.mode == LintWarn {
+		l.log.Warn("Unsafe migration statement", zap.String("problem", problem), zap.String("statement", statement))
+		return nil
+	}
+	return fmt.Errorf("%w: %s; wrap the migration in migrations.Unsafe if this is intended", ErrUnsafeMigration, problem)
+}
+
+// identifier strips quoting and any schema qualifier from a table or column name
+func identifier(name string) string {
+	name = strings.Trim(name, "`\"(;")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.Trim(name, "`\"")
+}
+
+func anyModel(columns map[string]string) string {
+	for _, model := range columns {
+		return model
+	}
+	return "a model"
+}
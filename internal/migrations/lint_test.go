@@ -0,0 +1,135 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestLinterCheck runs each statement through a fresh linter that knows the products table is
+// mapped by Product and that the orders table was created earlier in the run
+func TestLinterCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		sql     string
+		unsafe  bool
+	}{
+		{"create table", "postgres", `CREATE TABLE "carts" ("id" text)`, false},
+
+		{"index without CONCURRENTLY", "postgres", `CREATE INDEX IF NOT EXISTS "idx_products_sku" ON "products" ("sku")`, true},
+		{"unique index without CONCURRENTLY", "postgres", `CREATE UNIQUE INDEX "idx_products_sku" ON "products" ("sku")`, true},
+		{"index CONCURRENTLY", "postgres", `CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_products_sku" ON "products" ("sku")`, false},
+		{"index on a table created in the run", "postgres", `CREATE INDEX "idx_orders_status" ON "orders" ("status")`, false},
+		{"index on MySQL", "mysql", "CREATE INDEX `idx_products_sku` ON `products`(`sku`)", false},
+
+		{"drop mapped table", "postgres", `DROP TABLE IF EXISTS "products" CASCADE`, true},
+		{"drop unmapped table", "postgres", `DROP TABLE "legacy_carts"`, false},
+		{"drop table created in the run", "postgres", `DROP TABLE "orders"`, false},
+
+		{"rename column", "postgres", `ALTER TABLE "products" RENAME COLUMN "name" TO "title"`, true},
+		{"rename table", "postgres", `ALTER TABLE "products" RENAME TO "items"`, true},
+		{"rename column on MySQL", "mysql", "ALTER TABLE `products` CHANGE `name` `title` varchar(255)", true},
+
+		{"change type", "postgres", `ALTER TABLE "products" ALTER COLUMN "price" TYPE numeric(12,2)`, true},
+		{"change type with SET DATA", "postgres", `ALTER TABLE "products" ALTER COLUMN "price" SET DATA TYPE bigint`, true},
+		{"change type on MySQL", "mysql", "ALTER TABLE `products` MODIFY `price` bigint", true},
+		{"set NOT NULL", "postgres", `ALTER TABLE "products" ALTER COLUMN "sku" SET NOT NULL`, true},
+		{"drop NOT NULL", "postgres", `ALTER TABLE "products" ALTER COLUMN "sku" DROP NOT NULL`, false},
+
+		{"drop mapped column", "postgres", `ALTER TABLE "products" DROP COLUMN "sku"`, true},
+		{"drop unmapped column", "postgres", `ALTER TABLE "products" DROP COLUMN "legacy_code"`, false},
+		{"drop constraint", "postgres", `ALTER TABLE "products" DROP CONSTRAINT "fk_products_vendor"`, false},
+
+		{"add NOT NULL column without default", "postgres", `ALTER TABLE "products" ADD "vendor_id" text NOT NULL`, true},
+		{"add NOT NULL column with default", "postgres", `ALTER TABLE "products" ADD "status" text NOT NULL DEFAULT 'active'`, false},
+		{"add nullable column", "postgres", `ALTER TABLE "products" ADD COLUMN "vendor_id" text`, false},
+		{"add constraint", "postgres", `ALTER TABLE "products" ADD CONSTRAINT "fk_products_vendor" FOREIGN KEY ("vendor_id") REFERENCES "vendors"("id")`, false},
+
+		{"alter table created in the run", "postgres", `ALTER TABLE "orders" ALTER COLUMN "total" SET NOT NULL`, false},
+		{"select", "postgres", `SELECT count(*) FROM "products"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestLinter := func(mode string) (*linter, *observer.ObservedLogs) {
+				core, logs := observer.New(zap.WarnLevel)
+				l := newLinter(tt.dialect, mode, zap.New(core))
+				l.created["orders"] = true
+				l.mapped["products"] = map[string]string{"id": "Product", "name": "Product", "sku": "Product", "price": "Product"}
+				return l, logs
+			}
+
+			l, _ := newTestLinter(LintEnforce)
+			err := l.check(context.Background(), tt.sql)
+			if tt.unsafe != errors.Is(err, ErrUnsafeMigration) {
+				t.Fatalf("check(%s) = %v, want unsafe %v", tt.sql, err, tt.unsafe)
+			}
+			if !tt.unsafe && err != nil {
+				t.Fatalf("check(%s) = %v", tt.sql, err)
+			}
+			if !tt.unsafe {
+				return
+			}
+
+			// Unsafe lets the statement through, logged with its reason
+			l, logs := newTestLinter(LintEnforce)
+			ctx := context.WithValue(context.Background(), unsafeKey{}, "contract step")
+			if err := l.check(ctx, tt.sql); err != nil {
+				t.Fatalf("check(%s) in Unsafe = %v", tt.sql, err)
+			}
+			if entries := logs.FilterField(zap.String("reason", "contract step")).Len(); entries != 1 {
+				t.Fatalf("Unsafe statement logged %d times with its reason, want once", entries)
+			}
+
+			// Warn mode runs it, logged
+			l, logs = newTestLinter(LintWarn)
+			if err := l.check(context.Background(), tt.sql); err != nil {
+				t.Fatalf("check(%s) in warn mode = %v", tt.sql, err)
+			}
+			if logs.Len() != 1 {
+				t.Fatalf("warn mode logged %d entries, want 1", logs.Len())
+			}
+		})
+	}
+}
+
+// TestLinterDroppedColumnRemapped checks that AutoMigrate can't bring back, empty, a column an
+// earlier migration in the run dropped
+func TestLinterDroppedColumnRemapped(t *testing.T) {
+	type Product struct {
+		ID  string
+		SKU string
+	}
+	db := testutil.NewDB(t)
+	l := newLinter("postgres", LintEnforce, zap.NewNop())
+	if err := l.check(context.Background(), `ALTER TABLE "products" DROP COLUMN "sku"`); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.mapModels(db, []interface{}{&Product{}}); !errors.Is(err, ErrUnsafeMigration) {
+		t.Fatalf("mapModels after dropping a mapped column = %v, want ErrUnsafeMigration", err)
+	}
+}
+
+func TestStripConcurrently(t *testing.T) {
+	tests := []struct {
+		sql, want string
+	}{
+		{"CREATE UNIQUE INDEX `idx_users_username` ON `users`(`username`) CONCURRENTLY",
+			"CREATE UNIQUE INDEX `idx_users_username` ON `users`(`username`)"},
+		{"CREATE TABLE `users` (`id` char(36),`phone` varchar(20),INDEX `idx_users_phone` (`phone`) CONCURRENTLY,PRIMARY KEY (`id`))",
+			"CREATE TABLE `users` (`id` char(36),`phone` varchar(20),INDEX `idx_users_phone` (`phone`),PRIMARY KEY (`id`))"},
+		{"CREATE INDEX `idx_users_email` ON `users`(`email`)",
+			"CREATE INDEX `idx_users_email` ON `users`(`email`)"},
+		{"UPDATE `notes` SET `body`='(draft) concurrently'",
+			"UPDATE `notes` SET `body`='(draft) concurrently'"},
+	}
+	for _, tt := range tests {
+		if got := stripConcurrently(tt.sql); got != tt.want {
+			t.Errorf("stripConcurrently(%s) = %s, want %s", tt.sql, got, tt.want)
+		}
+	}
+}
@@ -1,29 +1,33 @@
 package migrations
 
 import (
-	"github.com/Jason-Omondi/ecomgo/internal/models"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// Migration applies a single schema change
+// Modules contribute their own migrations (see internal/module)
+type Migration func(*gorm.DB) error
+
 // MigrateDB runs all database migrations using GORM
 // GORM auto-generates correct SQL for MySQL, PostgreSQL, etc.
 // Migrations ensure schema is consistent across environments
 // Returns: error if any migration fails
 // Why here: keeps schema changes version-controlled and reversible
-func MigrateDB(db *gorm.DB, log *zap.Logger) error {
-	log.Info("Running database migrations")
-
-	// Auto-migrate creates/updates table schema based on struct tags
-	// GORM generates appropriate SQL for configured database type
-	// This is simpler than raw SQL migrations for most use cases
-	migrations := []func(*gorm.DB) error{
-		migrateUsersTable,
-		// Add future migrations here:
-		// migrateProductsTable,
-		// migrateOrdersTable,
+// lintMode (DB_MIGRATION_LINT) decides what happens to statements unsafe for rolling deploys (see lint.go)
+func MigrateDB(db *gorm.DB, log *zap.Logger, migrations []Migration, lintMode string) error {
+	log.Info("Running database migrations", zap.Int("count", len(migrations)), zap.String("lint", lintMode))
+
+	if err := withoutConcurrently(db); err != nil {
+		return err
+	}
+	db, err := lintDB(db, lintMode, log)
+	if err != nil {
+		return err
 	}
 
+	// Migrations run in the order modules were registered
+	// so a module may depend on tables created by an earlier one
 	for _, migration := range migrations {
 		if err := migration(db); err != nil {
 			log.Error("Migration failed", zap.Error(err))
@@ -35,16 +39,20 @@ func MigrateDB(db *gorm.DB, log *zap.Logger) error {
 	return nil
 }
 
-// migrateUsersTable creates/updates users table
-// GORM reads User struct tags and creates appropriate schema
+// AutoMigrate returns a migration that creates/updates tables for the given models
+// GORM reads struct tags and creates appropriate schema
 // Works identically for MySQL and PostgreSQL
-func migrateUsersTable(db *gorm.DB) error {
-	// AutoMigrate creates table if not exists, adds new columns, creates indexes
-	// It does NOT drop existing columns (safe for production)
-	if err := db.AutoMigrate(&models.User{}); err != nil {
-		return err
+func AutoMigrate(models ...interface{}) Migration {
+	return func(db *gorm.DB) error {
+		// AutoMigrate creates table if not exists, adds new columns, creates indexes
+		// It does NOT drop existing columns (safe for production)
+		if l, ok := db.Statement.Context.Value(linterKey{}).(*linter); ok {
+			if err := l.mapModels(db, models); err != nil {
+				return err
+			}
+		}
+		return db.AutoMigrate(models...)
 	}
-	return nil
 }
 
 // For complex migrations, use raw SQL that works across databases:
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const mysqlCallback = "migrations:mysql-index-options"
+
+// concurrentlyPattern matches the CONCURRENTLY option GORM appends after an index's columns
+// on dialects other than Postgres, in CREATE INDEX and in CREATE TABLE's inline indexes
+var concurrentlyPattern = regexp.MustCompile(`(?i)\)\s+CONCURRENTLY\b`)
+
+// withoutConcurrently drops the option:CONCURRENTLY index tag from MySQL's DDL
+// Models carry the tag for Postgres (see lint.go); MySQL builds indexes online without it and
+// rejects the keyword
+func withoutConcurrently(db *gorm.DB) error {
+	if db.Dialector.Name() != "mysql" || db.Callback().Raw().Get(mysqlCallback) != nil {
+		return nil
+	}
+	return db.Callback().Raw().Before("gorm:raw").Register(mysqlCallback, func(db *gorm.DB) {
+		sql := db.Statement.SQL.String()
+		if stripped := stripConcurrently(sql); stripped != sql {
+			db.Statement.SQL.Reset()
+			db.Statement.SQL.WriteString(stripped)
+		}
+	})
+}
+
+func stripConcurrently(sql string) string {
+	if trimmed := strings.TrimSpace(sql); !createTablePattern.MatchString(trimmed) && !createIndexPattern.MatchString(trimmed) {
+		return sql
+	}
+	return concurrentlyPattern.ReplaceAllString(sql, ")")
+}
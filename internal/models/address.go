@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Address is a saved shipping address in a customer's address book
+// Stored in the normalized form returned by the address validator (see internal/address)
+type Address struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	UserID     string    `json:"user_id" gorm:"not null;type:char(36);index"`
+	Label      string    `json:"label" gorm:"type:varchar(64)"` // "Home", "Office"
+	Recipient  string    `json:"recipient" gorm:"type:varchar(255)"`
+	Phone      string    `json:"phone" gorm:"type:varchar(32)"`
+	Line1      string    `json:"line1" gorm:"not null;type:varchar(255)"`
+	Line2      string    `json:"line2" gorm:"type:varchar(255)"`
+	City       string    `json:"city" gorm:"type:varchar(128)"`
+	Region     string    `json:"region" gorm:"type:varchar(128)"` // state, county or province
+	PostalCode string    `json:"postal_code" gorm:"type:varchar(32)"`
+	Country    string    `json:"country" gorm:"not null;type:char(2)"` // ISO 3166-1 alpha-2
+	Latitude   *float64  `json:"latitude,omitempty"`
+	Longitude  *float64  `json:"longitude,omitempty"`
+	Zone       string    `json:"zone" gorm:"type:varchar(64)"` // delivery zone used for shipping rates
+	Verified   bool      `json:"verified" gorm:"not null;default:false"`
+	IsDefault  bool      `json:"is_default" gorm:"not null;default:false"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (a *Address) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Address) TableName() string {
+	return "addresses"
+}
+
+// AddressRequest is the payload for saving or validating an address
+type AddressRequest struct {
+	Label      string `json:"label"`
+	Recipient  string `json:"recipient"`
+	Phone      string `json:"phone"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+// AddressValidationResponse is returned by POST /addresses/validate (used at checkout)
+type AddressValidationResponse struct {
+	Address  *Address `json:"address"`  // normalized address (not saved)
+	Verified bool     `json:"verified"` // false when the provider was unavailable or is disabled
+	Zone     string   `json:"zone"`
+}
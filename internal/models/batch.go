@@ -0,0 +1,38 @@
+package models
+
+import "encoding/json"
+
+// BatchRequest is the body of POST /batch
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations"`
+	// StopOnError skips the remaining operations after the first failure (status 424)
+	StopOnError bool `json:"stop_on_error"`
+}
+
+// BatchOperation is one API call inside a batch, e.g. {"method": "PUT", "path": "/admin/products/42", "body": {...}}
+// path is relative to the API root (/api/v1) and may carry a query string
+type BatchOperation struct {
+	ID     string          `json:"id,omitempty"` // client reference echoed in the result
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	// Headers are sent with this operation only, e.g. {"Idempotency-Key": "..."} for a checkout
+	// Only headers that identify one call are accepted; everything else comes from the batch request
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// BatchResult is the outcome of one operation, in request order
+// Body is the operation's JSON response; Error holds a plain text error message instead
+type BatchResult struct {
+	ID     string          `json:"id,omitempty"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// BatchResponse is the 207 Multi-Status body of POST /batch
+type BatchResponse struct {
+	Results   []BatchResult `json:"results"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Datasets exported to the data warehouse (see the analytics module)
+const (
+	BIDatasetOrders   = "orders"
+	BIDatasetUsers    = "users"
+	BIDatasetProducts = "products"
+)
+
+// BIExportWatermark is how far a dataset has been exported
+// Rows are exported in (updated_at, id) order, so the last exported row is where the next
+// export resumes; soft deletes don't touch updated_at and are tracked by deleted_at the same way
+type BIExportWatermark struct {
+	Dataset        string     `json:"dataset" gorm:"primaryKey;type:varchar(32)"`
+	ChangedThrough *time.Time `json:"changed_through"`        // updated_at of the last exported row
+	ChangedID      string     `json:"-" gorm:"type:char(36)"` // its ID, which orders rows with the same updated_at
+	DeletedThrough *time.Time `json:"deleted_through,omitempty"`
+	DeletedID      string     `json:"-" gorm:"type:char(36)"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`                          // last time the dataset was checked for changes
+	LastExportAt   *time.Time `json:"last_export_at,omitempty"`                       // last time a file was written
+	LastObject     string     `json:"last_object,omitempty" gorm:"type:varchar(255)"` // storage key of that file
+	LastRows       int64      `json:"last_rows" gorm:"not null;default:0"`
+	TotalRows      int64      `json:"total_rows" gorm:"not null;default:0"` // since the watermark was last reset
+}
+
+func (BIExportWatermark) TableName() string {
+	return "bi_export_watermarks"
+}
+
+// BIExportStatus is GET /admin/bi/exports
+type BIExportStatus struct {
+	Format   string              `json:"format"`
+	Interval string              `json:"interval,omitempty"` // empty when exports only run on request
+	Datasets []BIExportWatermark `json:"datasets"`
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Campaign states, derived from the window and the current time
+const (
+	CampaignScheduled = "scheduled"
+	CampaignActive    = "active"
+	CampaignEnded     = "ended"
+)
+
+// Campaign is a flash sale: between StartsAt and EndsAt its item prices replace the list prices
+// of their products. Where campaigns overlap on a product the lowest price wins.
+// A campaign with a SegmentID is a targeted promotion: its prices apply to the segment's members
+// only and never show on the public catalog.
+type Campaign struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	Name      string    `json:"name" gorm:"not null;type:varchar(255)"`
+	SegmentID string    `json:"segment_id,omitempty" gorm:"type:char(36);index"`
+	StartsAt  time.Time `json:"starts_at" gorm:"not null;index"`
+	EndsAt    time.Time `json:"ends_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+
+	Status string         `json:"status" gorm:"-"`
+	Items  []CampaignItem `json:"items" gorm:"foreignKey:CampaignID"`
+}
+
+func (c *Campaign) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Campaign) TableName() string {
+	return "campaigns"
+}
+
+// SetStatus derives Status from the window at now before the campaign is returned
+func (c *Campaign) SetStatus(now time.Time) {
+	switch {
+	case now.Before(c.StartsAt):
+		c.Status = CampaignScheduled
+	case now.Before(c.EndsAt):
+		c.Status = CampaignActive
+	default:
+		c.Status = CampaignEnded
+	}
+}
+
+// CampaignItem is one product's price during a campaign, in minor units of the product's currency
+type CampaignItem struct {
+	CampaignID string `json:"-" gorm:"primaryKey;type:char(36)"`
+	ProductID  string `json:"product_id" gorm:"primaryKey;type:char(36);index"`
+	Price      int64  `json:"price" gorm:"not null"`
+}
+
+func (CampaignItem) TableName() string {
+	return "campaign_items"
+}
+
+// CampaignRequest creates or replaces a campaign (admin only)
+type CampaignRequest struct {
+	Name      string                `json:"name"`
+	SegmentID string                `json:"segment_id"` // optional; limits the sale to a customer segment
+	StartsAt  time.Time             `json:"starts_at"`
+	EndsAt    time.Time             `json:"ends_at"`
+	Items     []CampaignItemRequest `json:"items"`
+}
+
+type CampaignItemRequest struct {
+	ProductID string `json:"product_id"`
+	Price     int64  `json:"price"` // minor units
+}
+
+// CampaignListResponse is a page of campaigns, latest start first
+type CampaignListResponse struct {
+	Campaigns []Campaign `json:"campaigns"`
+	Total     int64      `json:"total"`
+	Limit     int        `json:"limit"`
+	Offset    int        `json:"offset"`
+}
+
+// CampaignPrice is one entry of the precomputed price schedule: productID costs Price
+// from StartsAt until EndsAt
+type CampaignPrice struct {
+	ProductID  string    `json:"product_id"`
+	CampaignID string    `json:"campaign_id"`
+	Campaign   string    `json:"campaign"`
+	SegmentID  string    `json:"segment_id,omitempty"`
+	Price      int64     `json:"price"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+}
+
+// ProductSale tells a product is on sale; the product's price field then holds the sale price
+type ProductSale struct {
+	CampaignID string    `json:"campaign_id"`
+	Campaign   string    `json:"campaign"`
+	ListPrice  int64     `json:"list_price"`
+	EndsAt     time.Time `json:"ends_at"`
+}
+
+// PublicCampaign is a running or upcoming campaign as shown to shoppers (GET /campaigns)
+type PublicCampaign struct {
+	ID       string               `json:"id"`
+	Name     string               `json:"name"`
+	Status   string               `json:"status"`
+	StartsAt time.Time            `json:"starts_at"`
+	EndsAt   time.Time            `json:"ends_at"`
+	Items    []PublicCampaignItem `json:"items"`
+}
+
+type PublicCampaignItem struct {
+	ProductID string `json:"product_id"`
+	Price     int64  `json:"price"`
+}
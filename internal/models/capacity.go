@@ -0,0 +1,20 @@
+package models
+
+// CapacityLimits are an instance's runtime-adjustable concurrency limits; 0 means unlimited
+type CapacityLimits struct {
+	HTTPMaxInFlight     int `json:"http_max_in_flight"`
+	HTTPMaxQueued       int `json:"http_max_queued"`
+	OutboundMaxInFlight int `json:"outbound_max_in_flight"`
+	OutboundMaxQueued   int `json:"outbound_max_queued"`
+	JobConcurrency      int `json:"job_concurrency"`
+}
+
+// UpdateCapacityRequest changes limits on every instance until they restart (admin only)
+// Omitted fields keep their current value
+type UpdateCapacityRequest struct {
+	HTTPMaxInFlight     *int `json:"http_max_in_flight"`
+	HTTPMaxQueued       *int `json:"http_max_queued"`
+	OutboundMaxInFlight *int `json:"outbound_max_in_flight"`
+	OutboundMaxQueued   *int `json:"outbound_max_queued"`
+	JobConcurrency      *int `json:"job_concurrency"` // at least 1
+}
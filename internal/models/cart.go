@@ -0,0 +1,127 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reasons a cart line changed between add-to-cart and checkout
+const (
+	CartPriceChanged = "price_changed" // the unit price differs from the one the shopper saw
+	CartUnavailable  = "unavailable"   // the product was removed, deactivated or hidden on the channel
+	CartRestricted   = "restricted"    // the product is not sold in the shopper's country
+
+	// Only when a cart is rebuilt from a past order or a saved cart
+	CartOutOfStock       = "out_of_stock"      // fewer units in stock than the line asks for; trimmed or dropped
+	CartQuantityAdjusted = "quantity_adjusted" // the product's quantity rules changed; trimmed or dropped
+)
+
+// CartLine is one product and quantity of a cart
+type CartLine struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice int64  `json:"unit_price,omitempty"` // price the shopper was shown, minor units; 0 when never quoted
+}
+
+// CartValidationRequest checks a cart against quantity rules before it is saved or checked out
+type CartValidationRequest struct {
+	Items []CartLine `json:"items"`
+}
+
+// CartQuoteRequest prices a cart
+// QuoteToken is the token of the last quote; while it is valid its prices are honored
+type CartQuoteRequest struct {
+	Items      []CartLine `json:"items"`
+	QuoteToken string     `json:"quote_token,omitempty"`
+}
+
+// CartQuote is a priced cart; amounts are in minor units of Currency
+// Its prices hold until ExpiresAt for whoever presents Token
+type CartQuote struct {
+	Items     []CartQuoteLine `json:"items"`
+	Subtotal  int64           `json:"subtotal"`
+	Currency  string          `json:"currency"`
+	Token     string          `json:"quote_token"`
+	ExpiresAt time.Time       `json:"expires_at"`
+
+	// CustomerGroup prices the quote; TaxTreatment tells whether Subtotal includes tax
+	CustomerGroup string `json:"customer_group"`
+	TaxTreatment  string `json:"tax_treatment"`
+}
+
+// CartQuoteLine is one priced line of a quote
+type CartQuoteLine struct {
+	ProductID string       `json:"product_id"`
+	SKU       string       `json:"sku"`
+	Name      string       `json:"name"`
+	Quantity  int          `json:"quantity"`
+	UnitPrice int64        `json:"unit_price"`
+	LineTotal int64        `json:"line_total"`
+	Sale      *ProductSale `json:"sale,omitempty"`
+	Tier      *PriceTier   `json:"tier,omitempty"` // quantity break applied to UnitPrice
+}
+
+// CartChange is one line that changed since the shopper last saw the cart
+type CartChange struct {
+	ProductID     string `json:"product_id"`
+	Reason        string `json:"reason"`                   // CartPriceChanged, CartUnavailable or CartRestricted
+	PreviousPrice int64  `json:"previous_price,omitempty"` // unit price the shopper saw
+	CurrentPrice  int64  `json:"current_price,omitempty"`  // unit price now; 0 for lines dropped from the quote
+
+	// Set for CartOutOfStock and CartQuantityAdjusted: the quantity asked for and the one kept (0 when dropped)
+	PreviousQuantity int `json:"previous_quantity,omitempty"`
+	CurrentQuantity  int `json:"current_quantity,omitempty"`
+}
+
+// CartChangedResponse answers a cart whose prices or availability changed (409)
+// Quote is the re-priced cart without unavailable lines; the shopper confirms it to continue
+type CartChangedResponse struct {
+	Error   string       `json:"error"`             // always "cart_changed"
+	Expired bool         `json:"expired,omitempty"` // the presented quote had expired
+	Changes []CartChange `json:"changes"`
+	Quote   *CartQuote   `json:"quote"`
+}
+
+// SavedCart is a named list of products a customer keeps for recurring purchases
+// Items keep no prices; they are priced when the cart is rebuilt
+type SavedCart struct {
+	ID        string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	UserID    string     `json:"user_id" gorm:"not null;type:char(36);uniqueIndex:idx_saved_carts_user_name"`
+	Name      string     `json:"name" gorm:"not null;type:varchar(100);uniqueIndex:idx_saved_carts_user_name"`
+	Items     []CartLine `json:"items" gorm:"serializer:json;type:text"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (c *SavedCart) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (SavedCart) TableName() string {
+	return "saved_carts"
+}
+
+// SavedCartRequest creates or replaces a saved cart; unit_price on items is ignored
+type SavedCartRequest struct {
+	Name  string     `json:"name"`
+	Items []CartLine `json:"items"`
+}
+
+// ReorderRequest rebuilds a cart from a past order; with SaveAs it is also kept as a saved cart
+type ReorderRequest struct {
+	SaveAs string `json:"save_as"`
+}
+
+// CartRebuild is a cart rebuilt from a past order or a saved cart at current prices and stock
+type CartRebuild struct {
+	Items     []CartLine   `json:"items"`                // what can be bought now, at current unit prices
+	Changes   []CartChange `json:"changes"`              // lines that differ from the order or saved cart
+	Quote     *CartQuote   `json:"quote,omitempty"`      // the priced cart; nil when nothing can be bought
+	OrderID   string       `json:"order_id,omitempty"`   // the order rebuilt
+	SavedCart *SavedCart   `json:"saved_cart,omitempty"` // the saved cart rebuilt, or the one save_as created
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Built-in sales channels, created with the channels table
+const (
+	ChannelWeb = "web" // the default for requests and orders that don't name one
+	ChannelApp = "app"
+	ChannelPOS = "pos"
+)
+
+// Channel is a storefront orders come through: the website, the mobile app, a point of sale...
+// Clients name theirs with the X-Channel header; products can be hidden or repriced per channel
+type Channel struct {
+	Code      string    `json:"code" gorm:"primaryKey;type:varchar(32)"`
+	Name      string    `json:"name" gorm:"not null;type:varchar(255)"`
+	Active    bool      `json:"active" gorm:"not null;default:true"` // inactive channels are refused
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (Channel) TableName() string {
+	return "channels"
+}
+
+// ChannelProduct overrides a product on one channel
+// Price replaces the product's list price there; flash sale prices still apply on top
+type ChannelProduct struct {
+	ChannelCode string    `json:"channel" gorm:"primaryKey;type:varchar(32)"`
+	ProductID   string    `json:"product_id" gorm:"primaryKey;type:char(36);index"`
+	Hidden      bool      `json:"hidden" gorm:"not null;default:false"`
+	Price       *int64    `json:"price,omitempty"` // minor units of the product's currency; nil keeps the list price
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (ChannelProduct) TableName() string {
+	return "channel_products"
+}
+
+// ChannelOrder is the part of an order channel reporting needs, recorded from order events
+type ChannelOrder struct {
+	OrderID     string    `json:"order_id" gorm:"primaryKey;type:char(36)"`
+	ChannelCode string    `json:"channel" gorm:"not null;type:varchar(32);index:idx_channel_orders_channel_placed,priority:1"`
+	Total       int64     `json:"total" gorm:"not null"`
+	Currency    string    `json:"currency" gorm:"not null;type:char(3)"`
+	PlacedAt    time.Time `json:"placed_at" gorm:"not null;index:idx_channel_orders_channel_placed,priority:2;index"`
+}
+
+func (ChannelOrder) TableName() string {
+	return "channel_orders"
+}
+
+// ChannelRequest creates or updates a channel (admin only)
+type ChannelRequest struct {
+	Code   string `json:"code"` // ignored on update
+	Name   string `json:"name"`
+	Active *bool  `json:"active"` // defaults to true on create, unchanged on update
+}
+
+// ChannelProductRequest sets a product's visibility and price on a channel (admin only)
+type ChannelProductRequest struct {
+	Hidden bool   `json:"hidden"`
+	Price  *int64 `json:"price"` // omit or null to sell at the list price
+}
+
+// ChannelSales totals the orders placed on one channel in one currency, in minor units
+type ChannelSales struct {
+	Channel  string `json:"channel"`
+	Currency string `json:"currency"`
+	Orders   int64  `json:"orders"`
+	Revenue  int64  `json:"revenue"` // order totals as placed; refunds are not deducted
+}
+
+// ChannelSalesReport is GET /admin/reports/channels over [From, To)
+type ChannelSalesReport struct {
+	From  time.Time      `json:"from"`
+	To    time.Time      `json:"to"`
+	Sales []ChannelSales `json:"sales"`
+}
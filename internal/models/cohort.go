@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// CohortHorizonMonths is how many months after signup the cohort report follows customers,
+// and the horizon projected lifetime value is estimated over
+const CohortHorizonMonths = 12
+
+// CohortSummary is one signup-month cohort as of the last nightly rebuild
+// Customers are bucketed by the UTC month they registered; revenue is order totals net of
+// refunds, converted into Currency (the store currency) at the rate on the day of the rebuild
+type CohortSummary struct {
+	Cohort       string    `json:"cohort" gorm:"primaryKey;type:char(7)"` // YYYY-MM
+	Customers    int64     `json:"customers" gorm:"not null"`
+	Buyers       int64     `json:"buyers" gorm:"not null"`        // customers with at least one order
+	RepeatBuyers int64     `json:"repeat_buyers" gorm:"not null"` // customers with two or more orders
+	Orders       int64     `json:"orders" gorm:"not null"`
+	Revenue      int64     `json:"revenue" gorm:"not null"`
+	Currency     string    `json:"currency" gorm:"not null;type:char(3)"`
+	RepeatRate   float64   `json:"repeat_rate" gorm:"not null"`        // repeat buyers / buyers
+	LTV          int64     `json:"ltv" gorm:"not null"`                // revenue per customer to date
+	ProjectedLTV int64     `json:"projected_ltv" gorm:"not null"`      // estimated revenue per customer by CohortHorizonMonths
+	Unconverted  int64     `json:"unconverted_orders" gorm:"not null"` // orders left out of revenue for lack of an exchange rate
+	ComputedAt   time.Time `json:"computed_at" gorm:"not null"`
+
+	Months []CohortMonth `json:"months" gorm:"-"`
+}
+
+func (CohortSummary) TableName() string {
+	return "cohort_summaries"
+}
+
+// CohortMonth is a cohort's activity Month months after signup (0 is the signup month)
+type CohortMonth struct {
+	Cohort        string  `json:"-" gorm:"primaryKey;type:char(7)"`
+	Month         int     `json:"month" gorm:"primaryKey;autoIncrement:false"`
+	ActiveBuyers  int64   `json:"active_buyers" gorm:"not null"`
+	Orders        int64   `json:"orders" gorm:"not null"`
+	Revenue       int64   `json:"revenue" gorm:"not null"`
+	Retention     float64 `json:"retention" gorm:"not null"`      // active buyers / cohort customers
+	CumulativeLTV int64   `json:"cumulative_ltv" gorm:"not null"` // revenue per customer through this month
+}
+
+func (CohortMonth) TableName() string {
+	return "cohort_months"
+}
+
+// CohortReport is GET /admin/reports/cohorts
+type CohortReport struct {
+	Currency      string          `json:"currency"`
+	HorizonMonths int             `json:"horizon_months"`
+	ComputedAt    *time.Time      `json:"computed_at"` // nil until the first rebuild
+	Cohorts       []CohortSummary `json:"cohorts"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// ConfigBundleVersion is the bundle format this build writes and reads
+const ConfigBundleVersion = 1
+
+// Sections of a configuration bundle
+const (
+	ConfigSectionSettings       = "settings"
+	ConfigSectionCustomerGroups = "customer_groups"
+	ConfigSectionAdmins         = "admins"
+	ConfigSectionShipping       = "shipping"
+)
+
+// Import actions
+const (
+	ConfigCreate = "create"
+	ConfigUpdate = "update"
+	ConfigSkip   = "skip"   // can't be applied here, e.g. an admin without an account in this environment
+	ConfigManual = "manual" // environment config that differs; change the variable named in the note
+)
+
+// ConfigBundle is the store's configuration as one JSON document, exported from one
+// environment and imported into another (GET /admin/config/export, `main config export`)
+type ConfigBundle struct {
+	Version        int                    `json:"version"`
+	ExportedAt     time.Time              `json:"exported_at"`
+	Settings       map[string]interface{} `json:"settings"`        // every setting's current value, by key
+	CustomerGroups []ConfigCustomerGroup  `json:"customer_groups"` // customer groups and the tax treatment of their prices
+	Admins         []string               `json:"admins"`          // emails of the accounts with the admin role
+	Shipping       ConfigShipping         `json:"shipping"`        // from the environment; compared on import, never changed
+}
+
+// ConfigCustomerGroup is a customer group in a bundle; price lists and members stay behind
+type ConfigCustomerGroup struct {
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	TaxTreatment string `json:"tax_treatment"`
+}
+
+// ConfigShipping is the shipping setup of the exporting environment
+type ConfigShipping struct {
+	Carriers      []string `json:"carriers"`       // SHIPPING_CARRIERS
+	DeliveryZones []string `json:"delivery_zones"` // DELIVERY_ZONES
+}
+
+// ConfigImportResult lists what an import changed, or would change on a dry run
+type ConfigImportResult struct {
+	DryRun  bool           `json:"dry_run"`
+	Changes []ConfigChange `json:"changes"`
+}
+
+// ConfigChange is one difference between a bundle and this environment
+type ConfigChange struct {
+	Section string      `json:"section"`
+	Key     string      `json:"key"`
+	Action  string      `json:"action"`
+	From    interface{} `json:"from,omitempty"`
+	To      interface{} `json:"to,omitempty"`
+	Note    string      `json:"note,omitempty"`
+}
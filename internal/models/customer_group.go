@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Built-in customer groups, created with the customer_groups table
+// Customers without a group are retail
+const (
+	GroupRetail    = "retail"
+	GroupWholesale = "wholesale"
+	GroupVIP       = "vip"
+)
+
+// Tax treatments of a customer group
+const (
+	TaxInclusive = "inclusive" // prices include tax (consumers)
+	TaxExclusive = "exclusive" // prices exclude tax, which checkout adds (businesses)
+	TaxExempt    = "exempt"    // no tax is charged
+)
+
+// CustomerGroup is a class of customers with its own price list and tax treatment, e.g. wholesale
+type CustomerGroup struct {
+	Code         string    `json:"code" gorm:"primaryKey;type:varchar(32)"`
+	Name         string    `json:"name" gorm:"not null;type:varchar(255)"`
+	TaxTreatment string    `json:"tax_treatment" gorm:"not null;type:varchar(16);default:inclusive"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (CustomerGroup) TableName() string {
+	return "customer_groups"
+}
+
+// CustomerGroupPrice is a product's price for one customer group
+// It replaces the list and channel price; flash sale prices still apply when lower
+type CustomerGroupPrice struct {
+	GroupCode string    `json:"group" gorm:"primaryKey;type:varchar(32)"`
+	ProductID string    `json:"product_id" gorm:"primaryKey;type:char(36);index"`
+	Price     int64     `json:"price" gorm:"not null"` // minor units of the product's currency
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (CustomerGroupPrice) TableName() string {
+	return "customer_group_prices"
+}
+
+// CustomerGroupMember puts a customer in a group; a customer is in at most one
+type CustomerGroupMember struct {
+	UserID     string    `json:"user_id" gorm:"primaryKey;type:char(36)"`
+	GroupCode  string    `json:"group" gorm:"not null;type:varchar(32);index"`
+	AssignedBy string    `json:"assigned_by" gorm:"type:char(36)"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (CustomerGroupMember) TableName() string {
+	return "customer_group_members"
+}
+
+// CustomerGroupRequest creates or updates a customer group (admin only)
+type CustomerGroupRequest struct {
+	Code         string `json:"code"` // ignored on update
+	Name         string `json:"name"`
+	TaxTreatment string `json:"tax_treatment"` // inclusive (default on create), exclusive or exempt; unchanged on update when empty
+}
+
+// CustomerGroupPriceRequest sets a product's price for a group (admin only)
+type CustomerGroupPriceRequest struct {
+	Price int64 `json:"price"`
+}
+
+// CustomerGroupAssignRequest moves a customer into a group (admin only)
+type CustomerGroupAssignRequest struct {
+	Group string `json:"group"` // retail or empty removes the customer from their group
+}
+
+// CustomerGroupMembership is a customer's group as shown to admins
+type CustomerGroupMembership struct {
+	UserID       string `json:"user_id"`
+	Group        string `json:"group"`
+	TaxTreatment string `json:"tax_treatment"`
+}
@@ -0,0 +1,135 @@
+package models
+
+import "time"
+
+// Dashboard read models, kept up to date from domain events by the dashboard module's projections
+// Days are UTC dates (YYYY-MM-DD). Amounts stay in the currency they were charged in
+
+// DailySales is one day's orders and refunds in one currency
+type DailySales struct {
+	Day      string `json:"day,omitempty" gorm:"primaryKey;type:char(10)"`
+	Currency string `json:"currency" gorm:"primaryKey;type:char(3)"`
+	Orders   int64  `json:"orders" gorm:"not null;default:0"`
+	Units    int64  `json:"units" gorm:"not null;default:0"`
+	Gross    int64  `json:"gross" gorm:"not null;default:0"` // order totals as placed
+	Refunds  int64  `json:"refunds" gorm:"not null;default:0"`
+	Refunded int64  `json:"refunded" gorm:"not null;default:0"` // refunded that day, whenever the order was placed
+	Net      int64  `json:"net" gorm:"-"`                       // gross - refunded
+}
+
+func (DailySales) TableName() string {
+	return "dashboard_daily_sales"
+}
+
+// DailyProductSales is one day's sales of a product in one currency
+type DailyProductSales struct {
+	Day           string `gorm:"primaryKey;type:char(10)"`
+	ProductID     string `gorm:"primaryKey;type:char(36)"`
+	Currency      string `gorm:"primaryKey;type:char(3)"`
+	Orders        int64  `gorm:"not null;default:0"`
+	Units         int64  `gorm:"not null;default:0"`
+	Revenue       int64  `gorm:"not null;default:0"` // units x unit price paid
+	RefundedUnits int64  `gorm:"not null;default:0"` // units on refunds issued that day that list their items
+}
+
+func (DailyProductSales) TableName() string {
+	return "dashboard_product_sales"
+}
+
+// DailyCustomers is one day's signups and orders by new and returning customers
+type DailyCustomers struct {
+	Day          string `json:"day,omitempty" gorm:"primaryKey;type:char(10)"`
+	Signups      int64  `json:"signups" gorm:"not null;default:0"`
+	FirstOrders  int64  `json:"first_orders" gorm:"not null;default:0"`  // customers' first orders
+	RepeatOrders int64  `json:"repeat_orders" gorm:"not null;default:0"` // orders by customers who had ordered before
+}
+
+func (DailyCustomers) TableName() string {
+	return "dashboard_daily_customers"
+}
+
+// CustomerStats is one customer's orders since the projections started
+type CustomerStats struct {
+	UserID       string    `json:"user_id" gorm:"primaryKey;type:char(36)"`
+	Orders       int64     `json:"orders" gorm:"not null;default:0"`
+	FirstOrderAt time.Time `json:"first_order_at" gorm:"not null"`
+	LastOrderAt  time.Time `json:"last_order_at" gorm:"not null"`
+}
+
+func (CustomerStats) TableName() string {
+	return "dashboard_customers"
+}
+
+// CustomerSpend is what a customer spent in one currency
+type CustomerSpend struct {
+	UserID   string `gorm:"primaryKey;type:char(36)"`
+	Currency string `gorm:"primaryKey;type:char(3);index"`
+	Orders   int64  `gorm:"not null;default:0"`
+	Spent    int64  `gorm:"not null;default:0"`
+	Refunded int64  `gorm:"not null;default:0"`
+}
+
+func (CustomerSpend) TableName() string {
+	return "dashboard_customer_spend"
+}
+
+// ProjectedEvent records an event the projections have applied, so a redelivery is skipped
+// Rows are pruned after a week; redeliveries come much sooner
+type ProjectedEvent struct {
+	EventID     string    `gorm:"primaryKey;type:varchar(64)"`
+	ProjectedAt time.Time `gorm:"not null;index"`
+}
+
+func (ProjectedEvent) TableName() string {
+	return "dashboard_projected_events"
+}
+
+// SalesReport is GET /admin/dashboard/sales over [From, To]
+type SalesReport struct {
+	From   string       `json:"from"`
+	To     string       `json:"to"`
+	Days   []DailySales `json:"days"`   // days without orders or refunds are left out
+	Totals []DailySales `json:"totals"` // per currency over the range; day is empty
+}
+
+// TopProduct is one product of GET /admin/dashboard/top-products
+type TopProduct struct {
+	ProductID     string `json:"product_id"`
+	Name          string `json:"name,omitempty"` // empty when the product was deleted
+	Orders        int64  `json:"orders"`
+	Units         int64  `json:"units"`
+	Revenue       int64  `json:"revenue"`
+	RefundedUnits int64  `json:"refunded_units"`
+}
+
+// TopProductsReport is GET /admin/dashboard/top-products over [From, To]
+type TopProductsReport struct {
+	From     string       `json:"from"`
+	To       string       `json:"to"`
+	Currency string       `json:"currency"`
+	Sort     string       `json:"sort"` // units or revenue
+	Products []TopProduct `json:"products"`
+}
+
+// TopCustomer is one customer of GET /admin/dashboard/customers, by spend in the report currency
+type TopCustomer struct {
+	UserID   string `json:"user_id"`
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Orders   int64  `json:"orders"`
+	Spent    int64  `json:"spent"`
+	Refunded int64  `json:"refunded"`
+}
+
+// CustomerReport is GET /admin/dashboard/customers
+// Days and Totals cover [From, To]; Customers, RepeatCustomers and TopCustomers cover everything projected
+type CustomerReport struct {
+	From            string           `json:"from"`
+	To              string           `json:"to"`
+	Days            []DailyCustomers `json:"days"`
+	Totals          DailyCustomers   `json:"totals"`
+	Customers       int64            `json:"customers"`        // customers with at least one order
+	RepeatCustomers int64            `json:"repeat_customers"` // customers with two or more
+	Currency        string           `json:"currency"`
+	TopCustomers    []TopCustomer    `json:"top_customers"`
+}
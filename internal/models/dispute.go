@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Dispute states
+const (
+	DisputeNeedsResponse = "needs_response" // open; evidence not submitted yet
+	DisputeUnderReview   = "under_review"   // evidence submitted, waiting for the bank's decision
+	DisputeWon           = "won"            // closed; the store keeps the money
+	DisputeLost          = "lost"           // closed; the money went back to the customer
+)
+
+// Dispute is a chargeback on a payment, reported by the payment provider's webhooks
+// Provider and ProviderDisputeID identify it across webhook deliveries
+type Dispute struct {
+	ID                  string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	Provider            string     `json:"provider" gorm:"not null;type:varchar(32);uniqueIndex:idx_disputes_provider_ref"`
+	ProviderDisputeID   string     `json:"provider_dispute_id" gorm:"not null;type:varchar(128);uniqueIndex:idx_disputes_provider_ref"`
+	PaymentID           string     `json:"payment_id" gorm:"type:varchar(128);index"`
+	OrderID             string     `json:"order_id,omitempty" gorm:"type:char(36);index"`
+	OrderNumber         string     `json:"order_number,omitempty" gorm:"type:varchar(64)"` // from the order record, when there is one
+	UserID              string     `json:"user_id,omitempty" gorm:"type:char(36)"`         // the order's customer
+	Amount              int64      `json:"amount" gorm:"not null"`                         // disputed, in minor units
+	Currency            string     `json:"currency" gorm:"not null;type:char(3)"`
+	Reason              string     `json:"reason,omitempty" gorm:"type:varchar(64)"` // provider's reason code
+	Status              string     `json:"status" gorm:"not null;type:varchar(16);index"`
+	EvidenceDueBy       *time.Time `json:"evidence_due_by,omitempty" gorm:"index"`
+	EvidenceSubmittedAt *time.Time `json:"evidence_submitted_at,omitempty"`
+	EvidenceSubmittedBy string     `json:"evidence_submitted_by,omitempty" gorm:"type:char(36)"`
+	EvidenceNote        string     `json:"evidence_note,omitempty" gorm:"type:text"`
+	RemindedAt          *time.Time `json:"-"` // deadline reminder sent; cleared when the deadline moves
+	OpenedAt            time.Time  `json:"opened_at" gorm:"not null"`
+	ClosedAt            *time.Time `json:"closed_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt           time.Time  `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (d *Dispute) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Dispute) TableName() string {
+	return "disputes"
+}
+
+// Open reports whether the dispute is still waiting for a decision
+func (d *Dispute) Open() bool {
+	return d.Status == DisputeNeedsResponse || d.Status == DisputeUnderReview
+}
+
+// DisputeEvidenceRequest records that evidence was sent to the provider
+type DisputeEvidenceRequest struct {
+	Note string `json:"note"` // what was sent, e.g. tracking number and signed delivery note
+}
+
+// DisputeListResponse is a page of disputes, nearest evidence deadline first
+type DisputeListResponse struct {
+	Disputes []Dispute `json:"disputes"`
+	Total    int64     `json:"total"`
+	Limit    int       `json:"limit"`
+	Offset   int       `json:"offset"`
+}
+
+// DisputeReminders is the result of a deadline reminder scan
+type DisputeReminders struct {
+	Reminded int `json:"reminded"` // disputes admins were reminded about
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EmailTemplate is one saved version of an admin-edited email template. Versions are never
+// changed once written; at most one version per name and locale is active, and it replaces
+// the built-in template until it is deactivated
+type EmailTemplate struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	Name      string    `json:"name" gorm:"not null;type:varchar(64);uniqueIndex:idx_email_templates_version"`
+	Locale    string    `json:"locale" gorm:"not null;type:varchar(8);uniqueIndex:idx_email_templates_version"`
+	Version   int       `json:"version" gorm:"not null;uniqueIndex:idx_email_templates_version"`
+	Text      string    `json:"text" gorm:"not null;type:text"` // text/template source; defines "subject"
+	HTML      string    `json:"html" gorm:"not null;type:text"` // html/template source
+	Active    bool      `json:"active" gorm:"not null;default:false"`
+	CreatedBy string    `json:"created_by,omitempty" gorm:"type:char(36)"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+}
+
+func (t *EmailTemplate) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (EmailTemplate) TableName() string {
+	return "email_templates"
+}
+
+// EmailTemplateSummary describes one template in one locale for the admin listing
+type EmailTemplateSummary struct {
+	Name          string `json:"name"`
+	Locale        string `json:"locale"`
+	ActiveVersion int    `json:"active_version"` // 0 when the built-in template is in use
+	LatestVersion int    `json:"latest_version"` // 0 when it has never been edited
+}
+
+// EmailTemplateSource is the template a name and locale currently render with
+type EmailTemplateSource struct {
+	Name    string `json:"name"`
+	Locale  string `json:"locale"`
+	Version int    `json:"version"` // 0 for the built-in template
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// SaveEmailTemplateRequest saves a new version of a template
+// Text must define the subject: {{define "subject"}}...{{end}}
+type SaveEmailTemplateRequest struct {
+	Locale   string `json:"locale"` // default en
+	Text     string `json:"text"`
+	HTML     string `json:"html"`
+	Activate bool   `json:"activate"` // use it for outgoing email right away
+}
+
+// EmailTemplatePreviewRequest renders a template with sample data. Text and HTML preview an
+// unsaved draft, Version a saved version; with neither, the template currently in use
+type EmailTemplatePreviewRequest struct {
+	Locale  string                 `json:"locale"`
+	Text    string                 `json:"text,omitempty"`
+	HTML    string                 `json:"html,omitempty"`
+	Version int                    `json:"version,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"` // overrides sample values by key
+}
+
+// EmailTemplatePreview is a rendered email
+type EmailTemplatePreview struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Fraud decisions, from least to most severe
+const (
+	FraudAllow  = "allow"
+	FraudReview = "review" // hold the order until an admin resolves it
+	FraudDeny   = "deny"
+)
+
+// FraudAssessment records a checkout risk check and, for reviews, how an admin resolved it
+type FraudAssessment struct {
+	ID         string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	OrderID    string     `json:"order_id" gorm:"not null;type:char(36);index"`
+	UserID     string     `json:"user_id" gorm:"not null;type:char(36);index"`
+	Provider   string     `json:"provider" gorm:"not null;type:varchar(32)"`
+	Score      int        `json:"score"`
+	Decision   string     `json:"decision" gorm:"not null;type:varchar(16);index"`
+	Reasons    []string   `json:"reasons" gorm:"serializer:json;type:text"`
+	Amount     int64      `json:"amount"`
+	Currency   string     `json:"currency" gorm:"type:char(3)"`
+	IP         string     `json:"ip" gorm:"type:varchar(45)"`
+	DeviceID   string     `json:"device_id" gorm:"type:varchar(128)"`
+	Resolution string     `json:"resolution,omitempty" gorm:"type:varchar(16)"` // allow or deny, set by an admin
+	ResolvedBy string     `json:"resolved_by,omitempty" gorm:"type:char(36)"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime:milli"`
+}
+
+func (a *FraudAssessment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (FraudAssessment) TableName() string {
+	return "fraud_assessments"
+}
+
+// ResolveFraudReviewRequest is an admin's verdict on an order held for review
+type ResolveFraudReviewRequest struct {
+	Decision string `json:"decision"` // allow or deny
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// ExchangeRate is the latest known rate of Currency against Base (1 Base = Rate Currency)
+// Persisted so pricing keeps working from the last fetch when the provider and cache are down
+type ExchangeRate struct {
+	Base      string    `json:"base" gorm:"primaryKey;type:char(3)"`
+	Currency  string    `json:"currency" gorm:"primaryKey;type:char(3)"`
+	Rate      float64   `json:"rate" gorm:"not null"`
+	FetchedAt time.Time `json:"fetched_at" gorm:"not null"`
+}
+
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// ExchangeRatesResponse is returned by GET /fx/rates
+type ExchangeRatesResponse struct {
+	Base      string             `json:"base"`
+	Rates     map[string]float64 `json:"rates"`
+	FetchedAt time.Time          `json:"fetched_at"`
+	Provider  string             `json:"provider"`
+}
+
+// ConversionResponse is returned by GET /fx/convert
+// Amounts are in minor units of their currency (cents, or whole units for zero-decimal currencies)
+type ConversionResponse struct {
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	Amount          int64   `json:"amount"`
+	ConvertedAmount int64   `json:"converted_amount"`
+	Rate            float64 `json:"rate"`
+}
+
+// GeoResponse is returned by GET /geo: storefront defaults for the caller's country
+type GeoResponse struct {
+	Country   string `json:"country"`    // ISO 3166-1 alpha-2 from GeoIP; "" when unknown
+	Currency  string `json:"currency"`   // display currency: the country's when rates cover it, else the store default
+	TaxRegion string `json:"tax_region"` // region taxes are estimated for until a shipping address is known
+}
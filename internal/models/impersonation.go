@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Impersonation audit actions
+const (
+	ImpersonationStarted = "started" // an admin was issued a token acting as the user
+	ImpersonationRequest = "request" // a request was made with that token
+)
+
+// ImpersonationAudit records support staff acting as a customer: one row when the
+// session starts and one per request made with the impersonation token
+type ImpersonationAudit struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	AdminID   string    `json:"admin_id" gorm:"not null;type:char(36);index"`
+	UserID    string    `json:"user_id" gorm:"not null;type:char(36);index"`
+	Action    string    `json:"action" gorm:"not null;type:varchar(16)"`
+	Reason    string    `json:"reason,omitempty" gorm:"type:varchar(512)"` // ticket/reason given when starting
+	Method    string    `json:"method,omitempty" gorm:"type:varchar(8)"`
+	Path      string    `json:"path,omitempty" gorm:"type:varchar(512)"`
+	IP        string    `json:"ip" gorm:"type:varchar(45)"`
+	Country   string    `json:"country,omitempty" gorm:"type:char(2)"` // GeoIP country of IP, if resolved
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime:milli;index"`
+}
+
+func (a *ImpersonationAudit) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (ImpersonationAudit) TableName() string {
+	return "impersonation_audits"
+}
+
+// ImpersonateRequest starts an impersonation session; the reason is kept in the audit log
+type ImpersonateRequest struct {
+	Reason string `json:"reason"` // e.g. support ticket number and what is being reproduced
+}
+
+// ImpersonateResponse is a short-lived token acting as the user
+type ImpersonateResponse struct {
+	Token     string   `json:"token"`
+	ExpiresAt int64    `json:"expires_at"`
+	User      *Account `json:"user"`
+}
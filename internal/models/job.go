@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Background job states
+const (
+	JobQueued    = "queued"    // waiting for first run or a retry (see RunAt)
+	JobRunning   = "running"   // claimed by a worker until LockedUntil
+	JobSucceeded = "succeeded" // handler returned nil
+	JobDead      = "dead"      // exhausted MaxAttempts - dead letter, needs manual retry
+)
+
+// Job is a unit of background work processed by internal/jobs workers
+// Payload is handler-specific JSON; LastError keeps the most recent failure for debugging
+type Job struct {
+	ID          string          `json:"id" gorm:"primaryKey;type:char(36)"`
+	Type        string          `json:"type" gorm:"not null;type:varchar(64);index"`
+	Payload     json.RawMessage `json:"payload" gorm:"type:text"`
+	Status      string          `json:"status" gorm:"not null;type:varchar(16);index:idx_jobs_due"`
+	Attempts    int             `json:"attempts" gorm:"not null;default:0"`
+	MaxAttempts int             `json:"max_attempts" gorm:"not null"`
+	RunAt       time.Time       `json:"run_at" gorm:"index:idx_jobs_due"`
+	LockedUntil *time.Time      `json:"locked_until,omitempty"`
+	LastError   string          `json:"last_error,omitempty" gorm:"type:text"`
+	Done        int             `json:"done,omitempty" gorm:"not null;default:0"`  // items processed, for handlers that report progress
+	Total       int             `json:"total,omitempty" gorm:"not null;default:0"` // items to process; 0 while unknown
+	Result      json.RawMessage `json:"result,omitempty" gorm:"type:text"`         // handler-specific summary, kept across retries
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt   time.Time       `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (j *Job) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == "" {
+		j.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// BulkAction is an entry of GET /admin/bulk-actions: a change an admin can run over many records
+// Parameters are action-specific and documented with the action
+type BulkAction struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Decode unmarshals the job payload into dest
+func (j *Job) Decode(dest interface{}) error {
+	return json.Unmarshal(j.Payload, dest)
+}
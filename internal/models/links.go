@@ -0,0 +1,10 @@
+package models
+
+// Link is a HAL-style hyperlink to a related resource or page
+type Link struct {
+	Href string `json:"href"`
+}
+
+// Links maps a relation (self, next, products...) to its link
+// Responses carry it as _links when SERVER_RESOURCE_LINKS=true, see internal/links
+type Links map[string]Link
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginCode is a one-time sign-in code, and optionally a magic link, sent to a user
+// Only HMACs of the code and link token are stored; a code is single use and dies after
+// too many wrong guesses, whichever of expiry, use or lockout comes first
+type LoginCode struct {
+	ID        string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	UserID    string     `json:"user_id" gorm:"not null;type:char(36);index"`
+	CodeHash  string     `json:"-" gorm:"not null;type:char(64)"`
+	LinkHash  string     `json:"-" gorm:"type:char(64);index"` // empty when no magic link was sent
+	Attempts  int        `json:"attempts" gorm:"not null;default:0"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime:milli"`
+}
+
+func (c *LoginCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (LoginCode) TableName() string {
+	return "login_codes"
+}
+
+// LoginCodeRequest asks for a sign-in code for the account with this email, username or phone
+type LoginCodeRequest struct {
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+	Link     bool   `json:"link,omitempty"` // also send a magic link
+}
+
+// LoginCodeResponse is the same whether or not the account exists
+type LoginCodeResponse struct {
+	ExpiresIn int64 `json:"expires_in"` // seconds
+}
+
+// LoginCodeVerifyRequest signs in with a code (and the identifier it was requested for)
+// or with the token of a magic link
+type LoginCodeVerifyRequest struct {
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// PasswordForgotRequest asks for a password reset link for the account with this email, username or phone
+type PasswordForgotRequest struct {
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+}
+
+// PasswordResetRequest sets a new password with the token of a reset link
+type PasswordResetRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"` // at least 6 characters
+}
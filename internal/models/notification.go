@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Notification delivery channels
+const (
+	ChannelEmail    = "email"
+	ChannelSMS      = "sms"
+	ChannelWhatsApp = "whatsapp"
+	ChannelNone     = "none" // opted out (not allowed for OTP)
+)
+
+// Notification categories users can route to different channels
+const (
+	NotifyOTP                  = "otp"
+	NotifyOrderUpdates         = "order_updates"
+	NotifyPaymentConfirmations = "payment_confirmations" // e.g. M-Pesa receipts
+	NotifyStockAlerts          = "stock_alerts"          // back-in-stock subscriptions; low-stock alerts for admins and vendors
+	NotifyMarketing            = "marketing"             // segment email campaigns
+	NotifyQuestions            = "questions"             // answers to and moderation of the user's product questions
+)
+
+// NotificationPreference stores which channel each category of message goes to for a user
+// Missing rows mean defaults (see DefaultNotificationPreference)
+type NotificationPreference struct {
+	UserID                      string    `json:"user_id" gorm:"primaryKey;type:char(36)"`
+	Phone                       string    `json:"phone" gorm:"type:varchar(20)"` // E.164 destination for SMS/WhatsApp
+	OTPChannel                  string    `json:"otp_channel" gorm:"type:varchar(16);not null;default:sms"`
+	OrderUpdatesChannel         string    `json:"order_updates_channel" gorm:"type:varchar(16);not null;default:email"`
+	PaymentConfirmationsChannel string    `json:"payment_confirmations_channel" gorm:"type:varchar(16);not null;default:sms"`
+	StockAlertsChannel          string    `json:"stock_alerts_channel" gorm:"type:varchar(16);not null;default:email"`
+	MarketingChannel            string    `json:"marketing_channel" gorm:"type:varchar(16);not null;default:email"`
+	QuestionsChannel            string    `json:"questions_channel" gorm:"type:varchar(16);not null;default:email"`
+	UpdatedAt                   time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// DefaultNotificationPreference returns the preferences used before a user customizes them
+func DefaultNotificationPreference(userID string) *NotificationPreference {
+	return &NotificationPreference{
+		UserID:                      userID,
+		OTPChannel:                  ChannelSMS,
+		OrderUpdatesChannel:         ChannelEmail,
+		PaymentConfirmationsChannel: ChannelSMS,
+		StockAlertsChannel:          ChannelEmail,
+		MarketingChannel:            ChannelEmail,
+		QuestionsChannel:            ChannelEmail,
+	}
+}
+
+// ChannelFor returns the configured channel for category (email if unknown)
+func (p *NotificationPreference) ChannelFor(category string) string {
+	switch category {
+	case NotifyOTP:
+		return p.OTPChannel
+	case NotifyOrderUpdates:
+		return p.OrderUpdatesChannel
+	case NotifyPaymentConfirmations:
+		return p.PaymentConfirmationsChannel
+	case NotifyStockAlerts:
+		return p.StockAlertsChannel
+	case NotifyMarketing:
+		return p.MarketingChannel
+	case NotifyQuestions:
+		return p.QuestionsChannel
+	default:
+		return ChannelEmail
+	}
+}
+
+// UpdateNotificationPreferenceRequest represents a partial preferences update
+// Omitted fields keep their current value
+type UpdateNotificationPreferenceRequest struct {
+	Phone                       *string `json:"phone"`
+	OTPChannel                  *string `json:"otp_channel"`
+	OrderUpdatesChannel         *string `json:"order_updates_channel"`
+	PaymentConfirmationsChannel *string `json:"payment_confirmations_channel"`
+	StockAlertsChannel          *string `json:"stock_alerts_channel"`
+	MarketingChannel            *string `json:"marketing_channel"`
+	QuestionsChannel            *string `json:"questions_channel"`
+}
+
+// Notification is an in-app message shown in the user's notification center
+type Notification struct {
+	ID        string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	UserID    string     `json:"-" gorm:"index:idx_notifications_user_read;not null;type:char(36)"`
+	Type      string     `json:"type" gorm:"not null;type:varchar(64)"` // source event type, e.g. order.shipped
+	Title     string     `json:"title" gorm:"not null;type:varchar(255)"`
+	Body      string     `json:"body" gorm:"type:text"`
+	Link      string     `json:"link,omitempty" gorm:"type:varchar(512)"` // client route, e.g. /orders/{id}
+	ReadAt    *time.Time `json:"read_at" gorm:"index:idx_notifications_user_read"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime:milli;index"`
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == "" {
+		n.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationListResponse is a page of notifications plus counters for the badge
+type NotificationListResponse struct {
+	Notifications []Notification `json:"notifications"`
+	Total         int64          `json:"total"`
+	UnreadCount   int64          `json:"unread_count"`
+	Limit         int            `json:"limit"`
+	Offset        int            `json:"offset"`
+}
@@ -0,0 +1,79 @@
+package models
+
+// OIDCAuthorizeRequest is an OpenID Connect authorization request, as the login page received it
+// in its query string and posts it back once the user is signed in
+type OIDCAuthorizeRequest struct {
+	ResponseType        string `json:"response_type"` // code
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"` // space-separated; must include openid
+	State               string `json:"state,omitempty"`
+	Nonce               string `json:"nonce,omitempty"`
+	CodeChallenge       string `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string `json:"code_challenge_method,omitempty"` // S256
+}
+
+// OIDCAuthorizeResponse is where the login page sends the browser next: the client's redirect URI
+// with the authorization code and state
+type OIDCAuthorizeResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// OIDCTokenResponse answers a successful code exchange
+type OIDCTokenResponse struct {
+	AccessToken string `json:"access_token"` // only good for userinfo
+	TokenType   string `json:"token_type"`   // Bearer
+	ExpiresIn   int64  `json:"expires_in"`   // seconds
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope"`
+}
+
+// OIDCError is an OAuth 2.0 error response from the token and userinfo endpoints
+type OIDCError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// OIDCUserInfo holds the signed-in user's claims, as far as the granted scopes allow
+type OIDCUserInfo struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email,omitempty"`              // email scope
+	Name              string `json:"name,omitempty"`               // profile scope
+	GivenName         string `json:"given_name,omitempty"`         // profile scope
+	FamilyName        string `json:"family_name,omitempty"`        // profile scope
+	PreferredUsername string `json:"preferred_username,omitempty"` // profile scope, when the user chose a username
+	Locale            string `json:"locale,omitempty"`             // profile scope
+	Role              string `json:"role"`                         // always; tools decide access from it
+}
+
+// OIDCDiscovery is the provider metadata at /oidc/.well-known/openid-configuration
+type OIDCDiscovery struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+}
+
+// JSONWebKeySet is the provider's public signing keys
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JSONWebKey is an RSA public key (RFC 7517)
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"` // base64url, big-endian
+	Exponent  string `json:"e"`
+}
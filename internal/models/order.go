@@ -0,0 +1,128 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Order states pushed to clients as the order progresses
+const (
+	OrderStatusPlaced    = "placed"
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusRefunded  = "refunded"
+)
+
+// Fiscal receipt states of a paid order; empty when no fiscal provider is configured
+const (
+	FiscalPending = "pending" // being issued; the job queue retries failed attempts
+	FiscalIssued  = "issued"
+	FiscalFailed  = "failed" // rejected, or out of attempts; an admin can retry
+)
+
+// OrderStatusUpdate is one state transition sent on GET /orders/{id}/events
+// Details carries the originating event payload (tracking number, amounts...)
+type OrderStatusUpdate struct {
+	OrderID     string          `json:"order_id"`
+	OrderNumber string          `json:"order_number,omitempty"` // customer-facing, e.g. ORD-2025-000123
+	Status      string          `json:"status"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	Details     json.RawMessage `json:"details"`
+	Links       Links           `json:"_links,omitempty"`
+}
+
+// OrderSequence is the last order number counter handed out in a period (2025, 20250314 or all)
+// Numbers are allocated by incrementing the row, which locks it until the transaction ends
+type OrderSequence struct {
+	Scope     string    `json:"scope" gorm:"primaryKey;type:varchar(16)"`
+	Value     int64     `json:"value" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (OrderSequence) TableName() string {
+	return "order_sequences"
+}
+
+// Order is the local record of an order, built from order events (order.placed creates it,
+// payment, shipping, delivery and refund events move its status)
+// Orders placed before the record existed aren't backfilled
+type Order struct {
+	ID          string      `json:"id" gorm:"primaryKey;type:char(36)"`
+	OrderNumber string      `json:"order_number,omitempty" gorm:"type:varchar(32);index"`
+	UserID      string      `json:"user_id" gorm:"not null;type:char(36);index"`
+	Status      string      `json:"status" gorm:"not null;type:varchar(16);index"`
+	Total       int64       `json:"total" gorm:"not null"`
+	Currency    string      `json:"currency" gorm:"not null;type:char(3)"`
+	Channel     string      `json:"channel,omitempty" gorm:"type:varchar(32)"`
+	Items       []OrderLine `json:"items" gorm:"serializer:json;type:text"`
+	QuoteID     string      `json:"quote_id,omitempty" gorm:"type:char(36)"`
+	// As when placed; the tax records for filings
+	TaxTreatment string `json:"tax_treatment,omitempty" gorm:"type:varchar(16);index"`
+	VATID        string `json:"vat_id,omitempty" gorm:"type:varchar(32)"`
+	// Fiscal receipt issued after payment, see internal/fiscal
+	FiscalStatus        string      `json:"fiscal_status,omitempty" gorm:"type:varchar(16);index"`
+	FiscalProvider      string      `json:"fiscal_provider,omitempty" gorm:"type:varchar(32)"`
+	FiscalReceiptNumber string      `json:"fiscal_receipt_number,omitempty" gorm:"type:varchar(64)"`
+	FiscalSignature     string      `json:"fiscal_signature,omitempty" gorm:"type:text"`
+	FiscalIssuedAt      *time.Time  `json:"fiscal_issued_at,omitempty"`
+	FiscalError         string      `json:"fiscal_error,omitempty" gorm:"type:varchar(512)"` // last failed attempt
+	PlacedAt            time.Time   `json:"placed_at" gorm:"not null;index"`
+	UpdatedAt           time.Time   `json:"updated_at" gorm:"autoUpdateTime:milli"`
+	Notes               []OrderNote `json:"notes,omitempty" gorm:"foreignKey:OrderID"` // only on order detail; customers don't get internal comments
+	Links               Links       `json:"_links,omitempty" gorm:"-"`
+}
+
+func (Order) TableName() string {
+	return "orders"
+}
+
+// OrderLine is one line of an Order
+type OrderLine struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice int64  `json:"unit_price"`
+	GiftWrap  bool   `json:"gift_wrap,omitempty"`
+}
+
+// OrderNote is a note on an order, by the customer or staff
+// Internal notes are admin-only comments; they are never shown to the customer
+type OrderNote struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	OrderID    string    `json:"order_id" gorm:"not null;type:char(36);index"`
+	AuthorID   string    `json:"author_id" gorm:"type:char(36)"`
+	AuthorName string    `json:"author_name,omitempty" gorm:"type:varchar(255)"` // as it was when the note was written
+	AuthorRole string    `json:"author_role" gorm:"not null;type:varchar(32)"`
+	Body       string    `json:"body" gorm:"not null;type:text"`
+	Internal   bool      `json:"internal" gorm:"not null;default:false"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime:milli;index"`
+}
+
+func (n *OrderNote) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == "" {
+		n.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (OrderNote) TableName() string {
+	return "order_notes"
+}
+
+// OrderNoteRequest adds a note to an order
+// Internal is only honoured from admins; customers always write customer-visible notes
+type OrderNoteRequest struct {
+	Body     string `json:"body"`
+	Internal bool   `json:"internal"`
+}
+
+// OrderListResponse is a page of orders, newest first, without their notes
+type OrderListResponse struct {
+	Orders []Order `json:"orders"`
+	Total  int64   `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Order event stream types, see OrderEvent
+const (
+	OrderEventPlaced        = "placed"         // Data: the Order as recorded from order.placed
+	OrderEventImported      = "imported"       // Data: the Order, notes included, as it was when its stream started
+	OrderEventStatusChanged = "status_changed" // Data: OrderStatusChange
+	OrderEventFiscalUpdated = "fiscal_updated" // Data: OrderFiscalChange
+	OrderEventNoteAdded     = "note_added"     // Data: the OrderNote
+)
+
+// OrderEvent is one change to an order, appended to the order's stream when ORDER_EVENT_SOURCING
+// is on; replaying the stream in Sequence order rebuilds the order as of any point in time
+// Events are never updated or deleted
+type OrderEvent struct {
+	OrderID    string          `json:"order_id" gorm:"primaryKey;type:char(36)"`
+	Sequence   int64           `json:"sequence" gorm:"primaryKey;autoIncrement:false"` // 1, 2... per order
+	Type       string          `json:"type" gorm:"not null;type:varchar(32)"`
+	Data       json.RawMessage `json:"data" gorm:"type:text"`
+	ActorID    string          `json:"actor_id,omitempty" gorm:"type:char(36)"` // caller of the API request that made it; empty for events and jobs
+	RequestID  string          `json:"request_id,omitempty" gorm:"type:varchar(128)"`
+	RecordedAt time.Time       `json:"recorded_at" gorm:"not null;index"`
+}
+
+func (OrderEvent) TableName() string {
+	return "order_events"
+}
+
+// OrderStatusChange is the data of a status_changed event
+type OrderStatusChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// OrderFiscalChange is the data of a fiscal_updated event: the order's fiscal fields after the change
+type OrderFiscalChange struct {
+	Status        string     `json:"status"`
+	Provider      string     `json:"provider,omitempty"`
+	ReceiptNumber string     `json:"receipt_number,omitempty"`
+	Signature     string     `json:"signature,omitempty"`
+	IssuedAt      *time.Time `json:"issued_at,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// OrderSnapshot is an order replayed through event Sequence, so rebuilding it later only
+// replays the events after that
+type OrderSnapshot struct {
+	OrderID    string    `gorm:"primaryKey;type:char(36)"`
+	Sequence   int64     `gorm:"primaryKey;autoIncrement:false"`
+	State      Order     `gorm:"serializer:json;type:text"`
+	RecordedAt time.Time `gorm:"not null"` // of event Sequence
+}
+
+func (OrderSnapshot) TableName() string {
+	return "order_snapshots"
+}
+
+// OrderHistory is GET /admin/orders/{id}/history
+type OrderHistory struct {
+	OrderID string       `json:"order_id"`
+	Events  []OrderEvent `json:"events"`
+}
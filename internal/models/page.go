@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Content page states
+const (
+	PageDraft     = "draft" // only admins see it
+	PagePublished = "published"
+)
+
+// Page is a piece of store content served to the storefront by slug: about, shipping, returns,
+// terms... Body is HTML from the admin's editor and is served as stored.
+type Page struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	Slug        string     `json:"slug" gorm:"not null;type:varchar(64);uniqueIndex"`
+	Title       string     `json:"title" gorm:"not null;type:varchar(255)"`
+	Body        string     `json:"body" gorm:"not null;type:text"`
+	Status      string     `json:"status" gorm:"not null;type:varchar(16);index"`
+	PublishedAt *time.Time `json:"published_at,omitempty"` // first publication; kept while unpublished
+	UpdatedBy   string     `json:"updated_by,omitempty" gorm:"type:char(36)"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (p *Page) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Page) TableName() string {
+	return "pages"
+}
+
+// PageRequest creates or replaces a page (admin only)
+type PageRequest struct {
+	Slug    string `json:"slug"` // lowercase letters, digits and dashes, e.g. "returns-policy"
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Publish bool   `json:"publish"` // false saves the page as a draft, unpublishing it if it was live
+}
+
+// PageSummary lists a published page without its body, e.g. for footer links
+type PageSummary struct {
+	Slug      string    `json:"slug"`
+	Title     string    `json:"title"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,228 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Product is a sellable catalog item
+// Price is in minor units (cents) of Currency to avoid floating point rounding
+type Product struct {
+	ID          string         `json:"id" gorm:"primaryKey;type:char(36)"`
+	SKU         string         `json:"sku" gorm:"uniqueIndex;not null;type:varchar(64)"`
+	Name        string         `json:"name" gorm:"not null;type:varchar(255)"`
+	Description string         `json:"description" gorm:"type:text"`
+	Category    string         `json:"category" gorm:"type:varchar(128);index"`
+	Price       int64          `json:"price" gorm:"not null"`
+	Currency    string         `json:"currency" gorm:"not null;type:char(3)"`
+	Stock       int            `json:"stock" gorm:"not null;default:0"`
+	Active      bool           `json:"active" gorm:"not null"`
+	VendorID    string         `json:"vendor_id,omitempty" gorm:"type:char(36);index"` // marketplace seller; empty for the store's own products
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt   time.Time      `json:"updated_at" gorm:"autoUpdateTime:milli;index"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	Sale        *ProductSale   `json:"sale,omitempty" gorm:"-"`       // set on public reads during a campaign
+	Restricted  bool           `json:"restricted,omitempty" gorm:"-"` // set on public reads: not sold in the shopper's country
+	Links       Links          `json:"_links,omitempty" gorm:"-"`
+
+	// RestrictedCountries are ISO 3166-1 alpha-2 countries the product must not be sold to
+	RestrictedCountries []string `json:"restricted_countries,omitempty" gorm:"serializer:json;type:text"`
+
+	// Quantity rules for one order; 0 leaves a rule off
+	MinQuantity  int `json:"min_quantity,omitempty" gorm:"not null;default:0"`
+	MaxQuantity  int `json:"max_quantity,omitempty" gorm:"not null;default:0"`
+	QuantityStep int `json:"quantity_step,omitempty" gorm:"not null;default:0"` // sold in multiples of it, e.g. 6 for a case
+
+	// PriceTiers are quantity breaks; public reads only list the caller's, priced for them
+	PriceTiers []PriceTier `json:"price_tiers,omitempty" gorm:"serializer:json;type:text"`
+
+	// Set on public reads: the caller's customer group and whether its price includes tax
+	CustomerGroup string `json:"customer_group,omitempty" gorm:"-"`
+	TaxTreatment  string `json:"tax_treatment,omitempty" gorm:"-"` // inclusive, exclusive or exempt
+}
+
+// PriceTier is a quantity break: MinQuantity or more units in one order take DiscountBPS off
+// the unit price. With SegmentID only members of that customer segment get it.
+type PriceTier struct {
+	MinQuantity int    `json:"min_quantity"`
+	DiscountBPS int    `json:"discount_bps"` // 1000 = 10% off
+	SegmentID   string `json:"segment_id,omitempty"`
+	UnitPrice   int64  `json:"unit_price,omitempty"` // set on public reads: the caller's price at this tier
+}
+
+// Discount applies the tier to a unit price, rounding in the customer's favour
+func (t PriceTier) Discount(price int64) int64 {
+	return price * int64(10000-t.DiscountBPS) / 10000
+}
+
+// TierFor returns the best of the product's tiers for quantity units
+// Returns: nil when no tier is reached
+func (p *Product) TierFor(quantity int) *PriceTier {
+	var best *PriceTier
+	for i := range p.PriceTiers {
+		tier := &p.PriceTiers[i]
+		if quantity >= tier.MinQuantity && (best == nil || tier.DiscountBPS > best.DiscountBPS) {
+			best = tier
+		}
+	}
+	return best
+}
+
+func (p *Product) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Product) TableName() string {
+	return "products"
+}
+
+// SellableIn tells whether the product may be sold to country
+// An unknown country ("") is not restricted: the check can only be as good as the geolocation
+func (p *Product) SellableIn(country string) bool {
+	return country == "" || !slices.Contains(p.RestrictedCountries, country)
+}
+
+// QuantityProblem explains why quantity of the product can't be ordered
+// Returns: "" when the quantity meets the product's rules
+func (p *Product) QuantityProblem(quantity int) string {
+	switch {
+	case quantity <= 0:
+		return "quantity must be positive"
+	case p.MinQuantity > 0 && quantity < p.MinQuantity:
+		return fmt.Sprintf("at least %d must be ordered", p.MinQuantity)
+	case p.MaxQuantity > 0 && quantity > p.MaxQuantity:
+		return fmt.Sprintf("at most %d can be ordered", p.MaxQuantity)
+	case p.QuantityStep > 1 && quantity%p.QuantityStep != 0:
+		return fmt.Sprintf("sold in multiples of %d", p.QuantityStep)
+	}
+	return ""
+}
+
+// ProductRequest creates or replaces a product (admin only)
+type ProductRequest struct {
+	SKU         string `json:"sku"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Price       int64  `json:"price"`    // minor units
+	Currency    string `json:"currency"` // ISO 4217; defaults to the default_currency setting
+	Stock       int    `json:"stock"`
+	Active      *bool  `json:"active"` // defaults to true
+
+	RestrictedCountries []string `json:"restricted_countries"` // ISO 3166-1 alpha-2, e.g. ["US", "IR"]
+
+	MinQuantity  int `json:"min_quantity"`
+	MaxQuantity  int `json:"max_quantity"`
+	QuantityStep int `json:"quantity_step"`
+
+	PriceTiers []PriceTier `json:"price_tiers"` // unit_price is ignored
+}
+
+// StockAdjustmentRequest changes stock relative to its current value (admin only)
+type StockAdjustmentRequest struct {
+	Delta int `json:"delta"` // positive to restock, negative to take units out
+}
+
+// ProductDocument is the search index representation of a product
+// Only active products are indexed
+type ProductDocument struct {
+	ID          string       `json:"id"`
+	SKU         string       `json:"sku"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Category    string       `json:"category"`
+	Price       int64        `json:"price"`
+	Currency    string       `json:"currency"`
+	InStock     bool         `json:"in_stock"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	Sale        *ProductSale `json:"sale,omitempty"` // set on search hits during a campaign, never indexed
+}
+
+// NewProductDocument builds the indexed form of p
+func NewProductDocument(p *Product) ProductDocument {
+	return ProductDocument{
+		ID:          p.ID,
+		SKU:         p.SKU,
+		Name:        p.Name,
+		Description: p.Description,
+		Category:    p.Category,
+		Price:       p.Price,
+		Currency:    p.Currency,
+		InStock:     p.Stock > 0,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+// ProductSearchResponse is a page of search hits
+type ProductSearchResponse struct {
+	Hits   []ProductDocument `json:"hits"`
+	Total  int64             `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
+// ProductListing is the denormalized read model behind GET /products
+// One row per active product, rebuilt from the catalog on product events,
+// so a listing page is a single indexed query without joins or filters on products
+type ProductListing struct {
+	ProductID string       `json:"id" gorm:"primaryKey;type:char(36)"`
+	SKU       string       `json:"sku" gorm:"not null;type:varchar(64)"`
+	Name      string       `json:"name" gorm:"not null;type:varchar(255);index:idx_product_listings_name;index:idx_product_listings_category_name,priority:2"`
+	Category  string       `json:"category" gorm:"not null;type:varchar(128);index:idx_product_listings_category_name,priority:1"`
+	Price     int64        `json:"price" gorm:"not null"`
+	Currency  string       `json:"currency" gorm:"not null;type:char(3)"`
+	InStock   bool         `json:"in_stock" gorm:"not null"`
+	UpdatedAt time.Time    `json:"updated_at" gorm:"autoUpdateTime:false"` // the product's, not the row's
+	Sale      *ProductSale `json:"sale,omitempty" gorm:"-"`
+	Links     Links        `json:"_links,omitempty" gorm:"-"`
+}
+
+func (ProductListing) TableName() string {
+	return "product_listings"
+}
+
+// NewProductListing builds the listing row of p
+func NewProductListing(p *Product) ProductListing {
+	return ProductListing{
+		ProductID: p.ID,
+		SKU:       p.SKU,
+		Name:      p.Name,
+		Category:  p.Category,
+		Price:     p.Price,
+		Currency:  p.Currency,
+		InStock:   p.Stock > 0,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+// ProductListingResponse is a page of the public product listing
+// There is no total: counting would cost a second query on every page
+type ProductListingResponse struct {
+	Products []ProductListing `json:"products"`
+	HasMore  bool             `json:"has_more"`
+	Limit    int              `json:"limit"`
+	Offset   int              `json:"offset"`
+	Links    Links            `json:"_links,omitempty"`
+}
+
+// CategoryCount is the number of active products in one category
+type CategoryCount struct {
+	Category string `json:"category"`
+	Count    int64  `json:"count"`
+}
+
+// Category is a node of the category tree returned by GET /products/categories
+// Categories are paths like "fashion/shoes"; each segment becomes a level of the tree
+type Category struct {
+	Name         string     `json:"name"`
+	Path         string     `json:"path"`          // value to pass as ?category= in search
+	ProductCount int64      `json:"product_count"` // includes products in subcategories
+	Children     []Category `json:"children,omitempty"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Product question states
+const (
+	QuestionPending   = "pending"   // waiting for moderation; only the asker and staff see it
+	QuestionPublished = "published" // shown on the product page
+	QuestionRejected  = "rejected"
+)
+
+// ProductQuestion is a customer's question about a product
+// Answers come from the store's admins and, for vendor products, the vendor
+type ProductQuestion struct {
+	ID           string          `json:"id" gorm:"primaryKey;type:char(36)"`
+	ProductID    string          `json:"product_id" gorm:"not null;type:char(36);index:idx_questions_product_status"`
+	UserID       string          `json:"-" gorm:"not null;type:char(36);index"`
+	AuthorName   string          `json:"author_name" gorm:"not null;type:varchar(255)"` // first name and last initial, as when asked
+	Body         string          `json:"body" gorm:"not null;type:text"`
+	Status       string          `json:"status" gorm:"not null;type:varchar(16);index:idx_questions_product_status"`
+	RejectReason string          `json:"reject_reason,omitempty" gorm:"type:varchar(255)"`
+	AnswerCount  int             `json:"answer_count" gorm:"not null;default:0"`
+	ModeratedBy  string          `json:"moderated_by,omitempty" gorm:"type:char(36)"`
+	ModeratedAt  *time.Time      `json:"moderated_at,omitempty"`
+	CreatedAt    time.Time       `json:"created_at" gorm:"autoCreateTime:milli;index"`
+	UpdatedAt    time.Time       `json:"updated_at" gorm:"autoUpdateTime:milli"`
+	Answers      []ProductAnswer `json:"answers" gorm:"foreignKey:QuestionID"`
+}
+
+func (q *ProductQuestion) BeforeCreate(tx *gorm.DB) error {
+	if q.ID == "" {
+		q.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (ProductQuestion) TableName() string {
+	return "product_questions"
+}
+
+// Answer authors
+const (
+	AnswerByStore  = "store"
+	AnswerByVendor = "vendor"
+)
+
+// ProductAnswer answers a product question; it shows as soon as its question is published
+type ProductAnswer struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	QuestionID string    `json:"question_id" gorm:"not null;type:char(36);index"`
+	UserID     string    `json:"-" gorm:"not null;type:char(36)"`
+	AuthorType string    `json:"author_type" gorm:"not null;type:varchar(16)"` // store or vendor
+	AuthorName string    `json:"author_name" gorm:"not null;type:varchar(255)"`
+	Body       string    `json:"body" gorm:"not null;type:text"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+}
+
+func (a *ProductAnswer) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (ProductAnswer) TableName() string {
+	return "product_answers"
+}
+
+// QuestionRequest asks a question about a product
+type QuestionRequest struct {
+	Body string `json:"body"`
+}
+
+// AnswerRequest answers a question
+type AnswerRequest struct {
+	Body string `json:"body"`
+}
+
+// RejectQuestionRequest rejects a pending or published question (admin only)
+type RejectQuestionRequest struct {
+	Reason string `json:"reason"` // optional; told to the asker
+}
+
+// QuestionListResponse is a page of questions with their answers, newest first
+type QuestionListResponse struct {
+	Questions []ProductQuestion `json:"questions"`
+	Total     int64             `json:"total"`
+	Limit     int               `json:"limit"`
+	Offset    int               `json:"offset"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// QuotaUsage is GET /admin/usage: the tenant's plan and what it has used of it so far
+type QuotaUsage struct {
+	Tenant   string       `json:"tenant"` // "" for the default tenant
+	Plan     string       `json:"plan"`   // "" when the tenant has no plan and nothing is capped
+	Enforced bool         `json:"enforced"`
+	Requests QuotaCounter `json:"requests"` // API requests today (UTC)
+	Orders   QuotaCounter `json:"orders"`   // orders placed this month (UTC)
+}
+
+// QuotaCounter is one usage counter; Limit 0 means unlimited, and Remaining is then left out
+type QuotaCounter struct {
+	Used      int64     `json:"used"`
+	Limit     int64     `json:"limit"`
+	Remaining *int64    `json:"remaining,omitempty"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
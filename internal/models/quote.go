@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Quote states
+const (
+	QuoteRequested = "requested" // waiting for an admin to price it
+	QuoteApproved  = "approved"  // the customer can order it until valid_until
+	QuoteRejected  = "rejected"
+	QuoteOrdered   = "ordered"
+	QuoteCancelled = "cancelled" // withdrawn by the customer
+)
+
+// Quote is a B2B customer's request for a price on a set of products
+// Admins adjust the prices and approve it; the customer then orders it at exactly those prices
+type Quote struct {
+	ID            string      `json:"id" gorm:"primaryKey;type:char(36)"`
+	UserID        string      `json:"user_id" gorm:"not null;type:char(36);index"`
+	Status        string      `json:"status" gorm:"not null;type:varchar(16);index"`
+	CustomerGroup string      `json:"customer_group" gorm:"not null;type:varchar(32)"` // as when requested
+	TaxTreatment  string      `json:"tax_treatment" gorm:"not null;type:varchar(16)"`  // whether the prices include tax; exempt for exempt customers
+	VATID         string      `json:"vat_id,omitempty" gorm:"type:varchar(32)"`        // the customer's VAT number when requested
+	Currency      string      `json:"currency" gorm:"not null;type:char(3)"`
+	Lines         []QuoteLine `json:"lines" gorm:"serializer:json;type:text"`
+	Subtotal      int64       `json:"subtotal" gorm:"not null"`
+	Note          string      `json:"note,omitempty" gorm:"type:text"`              // from the customer
+	Response      string      `json:"response,omitempty" gorm:"type:varchar(1000)"` // from the store, e.g. terms or why it was rejected
+	ValidUntil    *time.Time  `json:"valid_until,omitempty"`
+	ReviewedBy    string      `json:"reviewed_by,omitempty" gorm:"type:char(36)"`
+	ReviewedAt    *time.Time  `json:"reviewed_at,omitempty"`
+	OrderID       string      `json:"order_id,omitempty" gorm:"type:char(36)"`
+	OrderNumber   string      `json:"order_number,omitempty" gorm:"type:varchar(64)"`
+	OrderedAt     *time.Time  `json:"ordered_at,omitempty"`
+	CreatedAt     time.Time   `json:"created_at" gorm:"autoCreateTime:milli;index"`
+	UpdatedAt     time.Time   `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (q *Quote) BeforeCreate(tx *gorm.DB) error {
+	if q.ID == "" {
+		q.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Quote) TableName() string {
+	return "quotes"
+}
+
+// QuoteLine is one product of a quote
+// ListPrice is the customer's price when they asked; UnitPrice is the quoted price
+type QuoteLine struct {
+	ProductID string `json:"product_id"`
+	SKU       string `json:"sku"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+	ListPrice int64  `json:"list_price"`
+	UnitPrice int64  `json:"unit_price"`
+	LineTotal int64  `json:"line_total"`
+}
+
+// QuoteRequest asks for a quote
+type QuoteRequest struct {
+	Items []CartLine `json:"items"` // product_id and quantity; unit_price is ignored
+	Note  string     `json:"note"`
+}
+
+// QuotePricesRequest sets quoted unit prices (admin only)
+type QuotePricesRequest struct {
+	Lines    []QuotePrice `json:"lines"`    // lines not listed keep their price
+	Response *string      `json:"response"` // replaces the message to the customer when set
+}
+
+// QuotePrice is the quoted unit price of one product
+type QuotePrice struct {
+	ProductID string `json:"product_id"`
+	UnitPrice int64  `json:"unit_price"`
+}
+
+// ApproveQuoteRequest approves a quote at its current prices (admin only)
+type ApproveQuoteRequest struct {
+	ValidUntil *time.Time `json:"valid_until"` // defaults to the quote_validity_days setting
+	Response   string     `json:"response"`
+}
+
+// RejectQuoteRequest rejects a quote (admin only)
+type RejectQuoteRequest struct {
+	Reason string `json:"reason"` // optional; told to the customer
+}
+
+// QuoteListResponse is a page of quotes, newest first
+type QuoteListResponse struct {
+	Quotes []Quote `json:"quotes"`
+	Total  int64   `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
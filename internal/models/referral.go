@@ -0,0 +1,145 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReferralCode is a customer's personal code for inviting others, one per user
+type ReferralCode struct {
+	UserID    string    `json:"user_id" gorm:"primaryKey;type:char(36)"`
+	Code      string    `json:"code" gorm:"not null;type:varchar(16);uniqueIndex"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+}
+
+func (ReferralCode) TableName() string {
+	return "referral_codes"
+}
+
+// Referral states
+const (
+	ReferralSignedUp = "signed_up" // the referred customer has an account
+	ReferralOrdered  = "ordered"   // and has placed a first order
+)
+
+// Referral attributes a signup to the customer whose code was used
+type Referral struct {
+	ID           string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	ReferrerID   string     `json:"referrer_id" gorm:"not null;type:char(36);index"`
+	RefereeID    string     `json:"referee_id" gorm:"not null;type:char(36);uniqueIndex"` // a customer is referred once
+	Code         string     `json:"code" gorm:"not null;type:varchar(16)"`
+	Status       string     `json:"status" gorm:"not null;type:varchar(16);index"`
+	FirstOrderID string     `json:"first_order_id,omitempty" gorm:"type:char(36)"`
+	SignedUpAt   time.Time  `json:"signed_up_at" gorm:"not null;index"`
+	OrderedAt    *time.Time `json:"ordered_at,omitempty"`
+}
+
+func (r *Referral) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Referral) TableName() string {
+	return "referrals"
+}
+
+// Referral reward reasons
+const (
+	ReferralRewardSignup  = "signup"      // to the referrer, when the referral signs up
+	ReferralRewardOrder   = "first_order" // to the referrer, on the referral's first order
+	ReferralRewardWelcome = "welcome"     // to the referred customer, on their first order
+)
+
+// ReferralReward is points or store credit granted by the referral program
+// Unique per referral and reason, so redelivered events grant it once
+type ReferralReward struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	UserID     string    `json:"user_id" gorm:"not null;type:char(36);index"`
+	ReferralID string    `json:"referral_id" gorm:"not null;type:char(36);uniqueIndex:idx_referral_reward"`
+	Reason     string    `json:"reason" gorm:"not null;type:varchar(16);uniqueIndex:idx_referral_reward"`
+	Type       string    `json:"type" gorm:"not null;type:varchar(8)"` // points or credit (settings.RewardPoints, RewardCredit)
+	Amount     int64     `json:"amount" gorm:"not null"`
+	Currency   string    `json:"currency,omitempty" gorm:"type:char(3)"` // credit only
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+}
+
+func (r *ReferralReward) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (ReferralReward) TableName() string {
+	return "referral_rewards"
+}
+
+// ReferralCredit is an amount of store credit in one currency
+type ReferralCredit struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"` // minor units
+}
+
+// ReferralSummaryResponse is the caller's code, link and what it has earned
+type ReferralSummaryResponse struct {
+	Code        string           `json:"code"`
+	Link        string           `json:"link,omitempty"` // storefront signup URL carrying the code
+	Signups     int64            `json:"signups"`
+	FirstOrders int64            `json:"first_orders"`
+	Points      int64            `json:"points"`           // points earned from the program
+	Credit      []ReferralCredit `json:"credit,omitempty"` // store credit earned, per currency
+}
+
+// ReferralCodeResponse confirms a code on the signup page
+type ReferralCodeResponse struct {
+	Code     string `json:"code"`
+	Referrer string `json:"referrer"` // first name of the code's owner
+}
+
+// ReferredCustomer is someone the caller referred, named by first name and last initial
+type ReferredCustomer struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	SignedUpAt time.Time  `json:"signed_up_at"`
+	OrderedAt  *time.Time `json:"ordered_at,omitempty"`
+}
+
+// ReferredCustomerListResponse is a page of the caller's referrals, newest first
+type ReferredCustomerListResponse struct {
+	Referrals []ReferredCustomer `json:"referrals"`
+	Total     int64              `json:"total"`
+	Limit     int                `json:"limit"`
+	Offset    int                `json:"offset"`
+}
+
+// ReferralListResponse is a page of referrals for admins, newest first
+type ReferralListResponse struct {
+	Referrals []Referral `json:"referrals"`
+	Total     int64      `json:"total"`
+	Limit     int        `json:"limit"`
+	Offset    int        `json:"offset"`
+}
+
+// ReferrerStats is one referrer's results
+type ReferrerStats struct {
+	UserID      string `json:"user_id"`
+	Email       string `json:"email"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Signups     int64  `json:"signups"`
+	FirstOrders int64  `json:"first_orders"`
+}
+
+// ReferralStatsResponse sums up the referral program
+type ReferralStatsResponse struct {
+	Referrers    int64            `json:"referrers"` // customers with at least one referral
+	Signups      int64            `json:"signups"`
+	FirstOrders  int64            `json:"first_orders"`
+	Points       int64            `json:"points"` // points granted
+	Credit       []ReferralCredit `json:"credit"` // store credit granted, per currency
+	TopReferrers []ReferrerStats  `json:"top_referrers"`
+}
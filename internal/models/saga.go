@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Saga states, see Saga
+const (
+	SagaRunning      = "running"      // steps are being carried out
+	SagaCompensating = "compensating" // a step failed for good; the completed steps are being undone
+	SagaCompleted    = "completed"    // every step succeeded
+	SagaCompensated  = "compensated"  // a step failed and every completed step was undone
+	SagaFailed       = "failed"       // compensation gave up; the remaining steps need an admin
+)
+
+// Saga is the persisted state of a multi-step operation run by internal/saga
+// Step is the next step to run; while compensating, the steps before it are undone last to first.
+// A saga is only ever advanced by the instance holding its lease (LockedBy until LockedUntil), so a crash
+// leaves it exactly at the last recorded step for recovery to resume
+type Saga struct {
+	ID             string          `json:"id" gorm:"primaryKey;type:char(36)"`
+	Type           string          `json:"type" gorm:"not null;type:varchar(32);uniqueIndex:idx_sagas_key"`
+	IdempotencyKey *string         `json:"-" gorm:"type:varchar(191);uniqueIndex:idx_sagas_key"` // caller's key, unique per Type
+	Status         string          `json:"status" gorm:"not null;type:varchar(16);index:idx_sagas_due,priority:1"`
+	Step           int             `json:"step" gorm:"not null;default:0"`
+	Data           json.RawMessage `json:"data" gorm:"type:text"`
+	Error          string          `json:"error,omitempty" gorm:"type:varchar(512)"` // why it is compensating
+	Attempts       int             `json:"attempts" gorm:"not null;default:0"`       // failed tries of the current step
+	NextAttemptAt  time.Time       `json:"next_attempt_at" gorm:"not null;index:idx_sagas_due,priority:2"`
+	LockedBy       string          `json:"-" gorm:"type:char(36)"` // lease holder's token
+	LockedUntil    *time.Time      `json:"-"`
+	CreatedAt      time.Time       `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt      time.Time       `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (Saga) TableName() string {
+	return "sagas"
+}
+
+// Checkout states shown to customers, derived from the checkout saga
+const (
+	CheckoutProcessing = "processing" // still running, or waiting to be retried
+	CheckoutCompleted  = "completed"  // paid and ordered
+	CheckoutFailed     = "failed"     // nothing was charged, or the charge was refunded
+)
+
+// Checkout failure reasons, see Checkout.Reason
+const (
+	CheckoutOutOfStock      = "out_of_stock"
+	CheckoutPaymentDeclined = "payment_declined"
+	CheckoutPaymentFailed   = "payment_failed" // the payment provider kept failing
+)
+
+// CheckoutRequest pays for a cart and places the order
+// Items and QuoteToken are what the last cart quote returned; the cart is re-priced like POST /cart/quote
+type CheckoutRequest struct {
+	Items         []CartLine `json:"items"`
+	QuoteToken    string     `json:"quote_token,omitempty"`
+	AddressID     string     `json:"address_id"`     // one of the caller's saved addresses
+	PaymentMethod string     `json:"payment_method"` // provider-specific token, phone number or test card
+}
+
+// Checkout is where a checkout stands
+type Checkout struct {
+	ID          string    `json:"id"`
+	Status      string    `json:"status"`           // CheckoutProcessing, CheckoutCompleted or CheckoutFailed
+	Reason      string    `json:"reason,omitempty"` // why it failed
+	OrderID     string    `json:"order_id"`
+	OrderNumber string    `json:"order_number,omitempty"` // once completed
+	Total       int64     `json:"total"`
+	Currency    string    `json:"currency"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
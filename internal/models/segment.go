@@ -0,0 +1,190 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Segment rule fields
+const (
+	SegmentFieldTotalSpend = "total_spend"     // order totals less refunds, in minor units of Currency
+	SegmentFieldOrderCount = "order_count"     // orders placed, in any currency
+	SegmentFieldLastOrder  = "last_order_days" // days since the latest order; customers without orders never match
+	SegmentFieldCountry    = "country"         // of the default address, ISO 3166-1 alpha-2
+	SegmentFieldRegion     = "region"          // of the default address
+	SegmentFieldCity       = "city"            // of the default address
+	SegmentFieldZone       = "zone"            // delivery zone of the default address
+)
+
+// Segment rule operators: gte/lte compare numbers, in/not_in match any of Values
+const (
+	SegmentOpGte   = "gte"
+	SegmentOpLte   = "lte"
+	SegmentOpIn    = "in"
+	SegmentOpNotIn = "not_in"
+)
+
+// Segment is a named group of customers matching every one of its rules
+// Membership is materialized in segment_members: recomputed when the rules change and every
+// SEGMENT_REFRESH_INTERVAL, so promotions and email campaigns read it without evaluating rules
+type Segment struct {
+	ID          string        `json:"id" gorm:"primaryKey;type:char(36)"`
+	Name        string        `json:"name" gorm:"not null;type:varchar(255);uniqueIndex"`
+	Description string        `json:"description,omitempty" gorm:"type:text"`
+	Rules       []SegmentRule `json:"rules" gorm:"serializer:json;type:text"`
+	Members     int           `json:"members" gorm:"not null;default:0"`
+	EvaluatedAt *time.Time    `json:"evaluated_at,omitempty"` // when membership was last recomputed
+	CreatedAt   time.Time     `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt   time.Time     `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (s *Segment) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Segment) TableName() string {
+	return "segments"
+}
+
+// SegmentRule is one condition on a customer
+// Numeric fields take Value with gte or lte; location fields take Values with in or not_in
+type SegmentRule struct {
+	Field    string   `json:"field"`
+	Op       string   `json:"op"`
+	Value    int64    `json:"value"`
+	Values   []string `json:"values,omitempty"`
+	Currency string   `json:"currency,omitempty"` // total_spend only; the store currency when empty
+}
+
+// SegmentMember is a customer in a segment as of the last evaluation
+type SegmentMember struct {
+	SegmentID string    `json:"-" gorm:"primaryKey;type:char(36)"`
+	UserID    string    `json:"user_id" gorm:"primaryKey;type:char(36);index"`
+	AddedAt   time.Time `json:"added_at" gorm:"not null"`
+}
+
+func (SegmentMember) TableName() string {
+	return "segment_members"
+}
+
+// CustomerOrder is the part of an order segmentation needs, recorded from order events
+// Orders aren't stored locally; spend, order count and recency are computed from these rows
+type CustomerOrder struct {
+	OrderID  string    `json:"order_id" gorm:"primaryKey;type:char(36)"`
+	UserID   string    `json:"user_id" gorm:"not null;type:char(36);index"`
+	Total    int64     `json:"total" gorm:"not null"`
+	Refunded int64     `json:"refunded" gorm:"not null;default:0"` // at most Total
+	Currency string    `json:"currency" gorm:"not null;type:char(3)"`
+	PlacedAt time.Time `json:"placed_at" gorm:"not null;index"`
+}
+
+func (CustomerOrder) TableName() string {
+	return "customer_orders"
+}
+
+// CustomerRefund records a refund applied to a CustomerOrder, so redelivered events apply once
+type CustomerRefund struct {
+	RefundID  string    `json:"refund_id" gorm:"primaryKey;type:varchar(64)"`
+	OrderID   string    `json:"order_id" gorm:"not null;type:char(36)"`
+	Amount    int64     `json:"amount" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+}
+
+func (CustomerRefund) TableName() string {
+	return "customer_refunds"
+}
+
+// Segment email campaign states
+const (
+	SegmentEmailQueued  = "queued"
+	SegmentEmailSending = "sending"
+	SegmentEmailSent    = "sent"
+)
+
+// SegmentEmail is a message sent to the members of a segment
+// Each member gets it on their marketing channel (notification preferences); opted-out members are skipped
+type SegmentEmail struct {
+	ID         string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	SegmentID  string     `json:"segment_id" gorm:"not null;type:char(36);index"`
+	Subject    string     `json:"subject" gorm:"not null;type:varchar(255)"`
+	Body       string     `json:"body" gorm:"not null;type:text"`
+	Status     string     `json:"status" gorm:"not null;type:varchar(16)"`
+	Recipients int        `json:"recipients" gorm:"not null;default:0"` // members handed to the notifier so far
+	Failed     int        `json:"failed" gorm:"not null;default:0"`
+	Cursor     string     `json:"-" gorm:"type:char(36)"` // last member done, so a retried job resumes after it
+	CreatedBy  string     `json:"created_by" gorm:"type:char(36)"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime:milli;index"`
+	SentAt     *time.Time `json:"sent_at,omitempty"`
+}
+
+func (e *SegmentEmail) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (SegmentEmail) TableName() string {
+	return "segment_emails"
+}
+
+// SegmentRequest creates or replaces a segment (admin only)
+type SegmentRequest struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Rules       []SegmentRule `json:"rules"`
+}
+
+// SegmentPreviewRequest evaluates rules without saving a segment
+type SegmentPreviewRequest struct {
+	Rules []SegmentRule `json:"rules"`
+}
+
+// SegmentPreviewResponse tells how many customers the rules match now
+type SegmentPreviewResponse struct {
+	Members int `json:"members"`
+}
+
+// SegmentEmailRequest sends a message to a segment's members
+type SegmentEmailRequest struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// SegmentListResponse is a page of segments by name
+type SegmentListResponse struct {
+	Segments []Segment `json:"segments"`
+	Total    int64     `json:"total"`
+	Limit    int       `json:"limit"`
+	Offset   int       `json:"offset"`
+}
+
+// SegmentMemberResponse is a member with the user's contact details
+type SegmentMemberResponse struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+// SegmentMemberListResponse is a page of members, longest-standing first
+type SegmentMemberListResponse struct {
+	Members []SegmentMemberResponse `json:"members"`
+	Total   int64                   `json:"total"`
+	Limit   int                     `json:"limit"`
+	Offset  int                     `json:"offset"`
+}
+
+// SegmentEmailListResponse is a page of a segment's email campaigns, newest first
+type SegmentEmailListResponse struct {
+	Emails []SegmentEmail `json:"emails"`
+	Total  int64          `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
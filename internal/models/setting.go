@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Setting is an admin-edited store-wide value, stored as text and typed by its definition
+// Only settings changed from their default have a row
+type Setting struct {
+	Key       string    `json:"key" gorm:"primaryKey;type:varchar(64)"`
+	Value     string    `json:"value" gorm:"type:text;not null"`
+	UpdatedBy string    `json:"updated_by" gorm:"type:char(36)"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Setting) TableName() string {
+	return "settings"
+}
+
+// SettingResponse is one entry of GET /admin/settings
+// Value and Default are JSON-typed per Type (string, email, currency, int or bool)
+type SettingResponse struct {
+	Key         string      `json:"key"`
+	Type        string      `json:"type"`
+	Value       interface{} `json:"value"`
+	Default     interface{} `json:"default"`
+	Description string      `json:"description"`
+	UpdatedBy   string      `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time  `json:"updated_at,omitempty"` // nil while the default is in effect
+}
+
+// UpdateSettingsRequest sets several settings at once, keyed by setting key (admin only)
+// Either every value is valid and saved, or none is; null resets a setting to its default
+type UpdateSettingsRequest map[string]interface{}
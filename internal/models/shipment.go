@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Shipment states, in the order they normally happen
+// Exception (failed delivery, return) can follow any state except delivered
+const (
+	ShipmentCreated        = "created"
+	ShipmentInTransit      = "in_transit"
+	ShipmentOutForDelivery = "out_for_delivery"
+	ShipmentDelivered      = "delivered"
+	ShipmentException      = "exception"
+)
+
+// Shipment is a parcel booked with a carrier for an order
+// Destination fields are a snapshot - later address book edits don't change where it goes
+type Shipment struct {
+	ID             string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	OrderID        string     `json:"order_id" gorm:"not null;type:char(36);index"`
+	UserID         string     `json:"user_id" gorm:"not null;type:char(36);index"`
+	Carrier        string     `json:"carrier" gorm:"not null;type:varchar(32);uniqueIndex:idx_shipments_tracking"`
+	TrackingNumber string     `json:"tracking_number" gorm:"not null;type:varchar(64);uniqueIndex:idx_shipments_tracking"`
+	Status         string     `json:"status" gorm:"not null;type:varchar(32)"`
+	Recipient      string     `json:"recipient" gorm:"type:varchar(255)"`
+	Line1          string     `json:"line1" gorm:"type:varchar(255)"`
+	City           string     `json:"city" gorm:"type:varchar(128)"`
+	Country        string     `json:"country" gorm:"type:char(2)"`
+	WeightGrams    int        `json:"weight_grams"`
+	WarehouseID    string     `json:"warehouse_id,omitempty" gorm:"type:char(36);index"` // ships from; empty is SHIPPING_ORIGIN
+	LabelKey       string     `json:"-" gorm:"type:varchar(255)"`                        // object storage key of the printable label
+	PackingSlipKey string     `json:"-" gorm:"type:varchar(255)"`                        // object storage key of the packing slip
+	Gift           bool       `json:"gift,omitempty"`                                    // packing slip printed without prices
+	ShippedAt      *time.Time `json:"shipped_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime:milli"`
+
+	Events []ShipmentEvent `json:"events,omitempty" gorm:"foreignKey:ShipmentID"`
+}
+
+func (s *Shipment) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Shipment) TableName() string {
+	return "shipments"
+}
+
+// ShipmentEvent is one tracking scan reported by the carrier
+// Unique per (shipment, status, time) so redelivered webhooks don't duplicate history
+type ShipmentEvent struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	ShipmentID  string    `json:"-" gorm:"not null;type:char(36);uniqueIndex:idx_shipment_events_dedupe"`
+	Status      string    `json:"status" gorm:"not null;type:varchar(32);uniqueIndex:idx_shipment_events_dedupe"`
+	Description string    `json:"description" gorm:"type:varchar(512)"`
+	Location    string    `json:"location" gorm:"type:varchar(255)"`
+	OccurredAt  time.Time `json:"occurred_at" gorm:"uniqueIndex:idx_shipment_events_dedupe"`
+	CreatedAt   time.Time `json:"-" gorm:"autoCreateTime:milli"`
+}
+
+func (e *ShipmentEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (ShipmentEvent) TableName() string {
+	return "shipment_events"
+}
+
+// CreateShipmentRequest books a shipment for an order (admin)
+type CreateShipmentRequest struct {
+	OrderID     string `json:"order_id"`
+	UserID      string `json:"user_id"`    // order owner
+	AddressID   string `json:"address_id"` // one of the owner's saved addresses
+	Carrier     string `json:"carrier"`    // dhl, sendy or manual
+	WeightGrams int    `json:"weight_grams"`
+	WarehouseID string `json:"warehouse_id"` // optional; ships from this warehouse instead of SHIPPING_ORIGIN
+
+	// Packing slip; without items no slip is printed
+	Items       []PackingSlipItem `json:"items,omitempty"`
+	Currency    string            `json:"currency,omitempty"`     // of the unit prices
+	Gift        bool              `json:"gift,omitempty"`         // hides prices on the slip
+	GiftMessage string            `json:"gift_message,omitempty"` // printed on the slip of a gift
+}
+
+// PackingSlipItem is one line on a shipment's packing slip
+type PackingSlipItem struct {
+	Name      string `json:"name"`
+	SKU       string `json:"sku,omitempty"`
+	Quantity  int    `json:"quantity"`
+	UnitPrice int64  `json:"unit_price"` // minor units; not printed on gift slips
+	GiftWrap  bool   `json:"gift_wrap,omitempty"`
+}
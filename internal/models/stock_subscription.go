@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Stock subscription states
+const (
+	StockSubscriptionActive   = "active"   // waiting for the product to be restocked
+	StockSubscriptionNotified = "notified" // the customer was told it's back; no further alerts
+)
+
+// StockSubscription asks to be told once when an out-of-stock product is available again
+// A user has at most one per product; subscribing again after an alert reactivates it
+type StockSubscription struct {
+	ID         string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	UserID     string     `json:"-" gorm:"not null;type:char(36);uniqueIndex:idx_stock_subscriptions_user_product"`
+	ProductID  string     `json:"product_id" gorm:"not null;type:char(36);uniqueIndex:idx_stock_subscriptions_user_product;index:idx_stock_subscriptions_product_notified"`
+	NotifiedAt *time.Time `json:"notified_at,omitempty" gorm:"index:idx_stock_subscriptions_product_notified"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime:milli"`
+
+	Status  string                    `json:"status" gorm:"-"`
+	Product *StockSubscriptionProduct `json:"product,omitempty" gorm:"-"`
+}
+
+func (s *StockSubscription) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (StockSubscription) TableName() string {
+	return "stock_subscriptions"
+}
+
+// SetStatus derives Status from NotifiedAt before the subscription is returned
+func (s *StockSubscription) SetStatus() {
+	s.Status = StockSubscriptionActive
+	if s.NotifiedAt != nil {
+		s.Status = StockSubscriptionNotified
+	}
+}
+
+// StockSubscriptionProduct summarizes the product in subscription listings
+type StockSubscriptionProduct struct {
+	Name    string `json:"name"`
+	InStock bool   `json:"in_stock"`
+}
+
+// StockSubscriptionListResponse is a page of the caller's stock subscriptions
+type StockSubscriptionListResponse struct {
+	Subscriptions []StockSubscription `json:"subscriptions"`
+	Total         int64               `json:"total"`
+	Limit         int                 `json:"limit"`
+	Offset        int                 `json:"offset"`
+}
+
+// StockDemandResponse is how many customers wait for a product to be restocked (admin only)
+type StockDemandResponse struct {
+	ProductID string `json:"product_id"`
+	Waiting   int64  `json:"waiting"`
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Stock adjustment reasons
+const (
+	AdjustDamaged    = "damaged"
+	AdjustLost       = "lost"
+	AdjustTheft      = "theft"
+	AdjustExpired    = "expired"
+	AdjustFound      = "found"
+	AdjustReturned   = "returned"   // back on the shelf outside a refund
+	AdjustCorrection = "correction" // fixes a booking mistake
+	AdjustStocktake  = "stocktake"  // variance booked by completing a stocktake; not accepted on manual adjustments
+)
+
+// StockAdjustment is one entry of the stock audit log: a manual change to a product's stock, or
+// a stocktake variance, with who made it and why
+// Without WarehouseID the change was to the product's unassigned stock
+type StockAdjustment struct {
+	ID             string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	ProductID      string    `json:"product_id" gorm:"not null;type:char(36);index"`
+	WarehouseID    string    `json:"warehouse_id,omitempty" gorm:"type:char(36);index"`
+	Reason         string    `json:"reason" gorm:"not null;type:varchar(16);index"`
+	Delta          int       `json:"delta" gorm:"not null"`
+	QuantityBefore int       `json:"quantity_before" gorm:"not null"` // of the warehouse, or the product's total without one
+	QuantityAfter  int       `json:"quantity_after" gorm:"not null"`
+	Note           string    `json:"note,omitempty" gorm:"type:varchar(500)"`
+	StocktakeID    string    `json:"stocktake_id,omitempty" gorm:"type:char(36);index"`
+	CreatedBy      string    `json:"created_by" gorm:"type:char(36);index"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime:milli;index"`
+}
+
+func (a *StockAdjustment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (StockAdjustment) TableName() string {
+	return "stock_adjustments"
+}
+
+// Stocktake states
+const (
+	StocktakeOpen      = "open" // counts are being recorded
+	StocktakeCompleted = "completed"
+	StocktakeCancelled = "cancelled"
+)
+
+// Stocktake is a physical count of a warehouse's stock
+// Each line keeps the system quantity at the time it was counted, so sales during the count
+// don't show up as variance. Completing books every counted variance as a stock adjustment.
+type Stocktake struct {
+	ID          string          `json:"id" gorm:"primaryKey;type:char(36)"`
+	WarehouseID string          `json:"warehouse_id" gorm:"not null;type:char(36);index"`
+	Status      string          `json:"status" gorm:"not null;type:varchar(16);index"`
+	Note        string          `json:"note,omitempty" gorm:"type:varchar(500)"`
+	CreatedBy   string          `json:"created_by" gorm:"type:char(36)"`
+	CompletedBy string          `json:"completed_by,omitempty" gorm:"type:char(36)"`
+	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime:milli;index"`
+	UpdatedAt   time.Time       `json:"updated_at" gorm:"autoUpdateTime:milli"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	Lines       []StocktakeLine `json:"lines,omitempty" gorm:"foreignKey:StocktakeID"`
+}
+
+func (s *Stocktake) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Stocktake) TableName() string {
+	return "stocktakes"
+}
+
+// StocktakeLine is one product of a stocktake; Counted is nil until it is counted
+type StocktakeLine struct {
+	StocktakeID    string     `json:"-" gorm:"primaryKey;type:char(36)"`
+	ProductID      string     `json:"product_id" gorm:"primaryKey;type:char(36)"`
+	SystemQuantity int        `json:"system_quantity" gorm:"not null"` // what the warehouse held when counted (or when the stocktake started)
+	Counted        *int       `json:"counted"`
+	Variance       int        `json:"variance" gorm:"not null;default:0"` // counted - system_quantity
+	CountedAt      *time.Time `json:"counted_at,omitempty"`
+}
+
+func (StocktakeLine) TableName() string {
+	return "stocktake_lines"
+}
+
+// StockAdjustRequest records a manual stock change with a reason code (admin only)
+type StockAdjustRequest struct {
+	ProductID   string `json:"product_id"`
+	WarehouseID string `json:"warehouse_id"` // optional; without it the product's unassigned stock changes
+	Delta       int    `json:"delta"`        // positive adds units, negative removes them
+	Reason      string `json:"reason"`       // damaged, lost, theft, expired, found, returned or correction
+	Note        string `json:"note"`
+}
+
+// StockAdjustmentListResponse is a page of the stock audit log, newest first
+type StockAdjustmentListResponse struct {
+	Adjustments []StockAdjustment `json:"adjustments"`
+	Total       int64             `json:"total"`
+	Limit       int               `json:"limit"`
+	Offset      int               `json:"offset"`
+}
+
+// StocktakeRequest starts a stocktake (admin only)
+type StocktakeRequest struct {
+	WarehouseID string   `json:"warehouse_id"`
+	ProductIDs  []string `json:"product_ids"` // optional; defaults to every product the warehouse holds
+	Note        string   `json:"note"`
+}
+
+// StocktakeCountRequest records counted quantities; counting a product again replaces its count
+// Products not on the stocktake are added, e.g. stock found where the system had none
+type StocktakeCountRequest struct {
+	Counts []StocktakeCount `json:"counts"`
+}
+
+type StocktakeCount struct {
+	ProductID string `json:"product_id"`
+	Counted   int    `json:"counted"`
+}
+
+// StocktakeListResponse is a page of stocktakes, newest first
+type StocktakeListResponse struct {
+	Stocktakes []Stocktake `json:"stocktakes"`
+	Total      int64       `json:"total"`
+	Limit      int         `json:"limit"`
+	Offset     int         `json:"offset"`
+}
+
+// VarianceReport compares a stocktake's counts with the system quantities
+type VarianceReport struct {
+	StocktakeID string          `json:"stocktake_id"`
+	WarehouseID string          `json:"warehouse_id"`
+	Status      string          `json:"status"`
+	Lines       int             `json:"lines"`
+	Counted     int             `json:"counted"`
+	Matched     int             `json:"matched"`    // counted lines without variance
+	Over        int             `json:"over"`       // counted lines with more units than the system had
+	Short       int             `json:"short"`      // counted lines with fewer
+	UnitsOver   int             `json:"units_over"` // sum of positive variances
+	UnitsShort  int             `json:"units_short"`
+	NetVariance int             `json:"net_variance"`
+	Variances   []StocktakeLine `json:"variances"` // counted lines with variance, largest first
+	Uncounted   []string        `json:"uncounted"` // product IDs not counted yet; completing leaves them as they are
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// PresignUploadRequest asks for a URL to upload a file directly to object storage
+type PresignUploadRequest struct {
+	Prefix      string `json:"prefix"`       // products or digital
+	Filename    string `json:"filename"`     // only the extension is kept
+	ContentType string `json:"content_type"` // e.g. image/webp
+}
+
+// PresignUploadResponse is a presigned upload; PUT the file body to UploadURL before ExpiresAt
+// then reference Key when creating the product/image/download record
+type PresignUploadResponse struct {
+	Key         string    `json:"key"`
+	UploadURL   string    `json:"upload_url"`
+	Method      string    `json:"method"`
+	ContentType string    `json:"content_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Supplier is a business the store buys stock from
+type Supplier struct {
+	ID           string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	Name         string    `json:"name" gorm:"not null;type:varchar(255)"`
+	Email        string    `json:"email" gorm:"type:varchar(255)"`
+	Phone        string    `json:"phone" gorm:"type:varchar(32)"`
+	Currency     string    `json:"currency" gorm:"not null;type:char(3)"`    // purchase orders are priced in it
+	LeadTimeDays int       `json:"lead_time_days" gorm:"not null;default:0"` // promised days from order to delivery
+	Active       bool      `json:"active" gorm:"not null"`                   // inactive suppliers get no new purchase orders
+	Notes        string    `json:"notes,omitempty" gorm:"type:text"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+
+	// Set on reads: how long fully received purchase orders actually took
+	ActualLeadTimeDays *float64 `json:"actual_lead_time_days,omitempty" gorm:"-"`
+	ReceivedOrders     int      `json:"received_orders" gorm:"-"`
+}
+
+func (s *Supplier) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Supplier) TableName() string {
+	return "suppliers"
+}
+
+// SupplierProduct is what a supplier charges for a product and how fast they deliver it
+// UnitCost follows the last receipt; admins can also set it directly
+type SupplierProduct struct {
+	SupplierID   string    `json:"supplier_id" gorm:"primaryKey;type:char(36)"`
+	ProductID    string    `json:"product_id" gorm:"primaryKey;type:char(36);index"`
+	SupplierSKU  string    `json:"supplier_sku,omitempty" gorm:"type:varchar(64)"`
+	UnitCost     int64     `json:"unit_cost" gorm:"not null"`                // minor units of the supplier's currency
+	LeadTimeDays int       `json:"lead_time_days" gorm:"not null;default:0"` // 0 uses the supplier's
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (SupplierProduct) TableName() string {
+	return "supplier_products"
+}
+
+// Purchase order states
+const (
+	PurchaseOrderDraft     = "draft"   // being prepared; lines can still change
+	PurchaseOrderOrdered   = "ordered" // sent to the supplier
+	PurchaseOrderPartial   = "partially_received"
+	PurchaseOrderReceived  = "received"
+	PurchaseOrderCancelled = "cancelled" // nothing more is expected; received stock stays
+)
+
+// PurchaseOrder restocks products from a supplier into a warehouse
+// Without a warehouse, received units are added to the products' unassigned stock
+type PurchaseOrder struct {
+	ID          string              `json:"id" gorm:"primaryKey;type:char(36)"`
+	SupplierID  string              `json:"supplier_id" gorm:"not null;type:char(36);index"`
+	WarehouseID string              `json:"warehouse_id,omitempty" gorm:"type:char(36)"`
+	Status      string              `json:"status" gorm:"not null;type:varchar(24);index"`
+	Currency    string              `json:"currency" gorm:"not null;type:char(3)"`
+	Total       int64               `json:"total" gorm:"not null"` // cost of every ordered unit
+	Note        string              `json:"note,omitempty" gorm:"type:varchar(1000)"`
+	OrderedAt   *time.Time          `json:"ordered_at,omitempty"`
+	ExpectedAt  *time.Time          `json:"expected_at,omitempty"` // ordered_at plus the longest lead time of its lines
+	ReceivedAt  *time.Time          `json:"received_at,omitempty"` // when the last units arrived
+	CreatedBy   string              `json:"created_by" gorm:"type:char(36)"`
+	CreatedAt   time.Time           `json:"created_at" gorm:"autoCreateTime:milli;index"`
+	UpdatedAt   time.Time           `json:"updated_at" gorm:"autoUpdateTime:milli"`
+	Lines       []PurchaseOrderLine `json:"lines" gorm:"foreignKey:PurchaseOrderID"`
+}
+
+func (o *PurchaseOrder) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == "" {
+		o.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (PurchaseOrder) TableName() string {
+	return "purchase_orders"
+}
+
+// PurchaseOrderLine is one product of a purchase order
+type PurchaseOrderLine struct {
+	PurchaseOrderID string `json:"-" gorm:"primaryKey;type:char(36)"`
+	ProductID       string `json:"product_id" gorm:"primaryKey;type:char(36);index"`
+	Quantity        int    `json:"quantity" gorm:"not null"`
+	Received        int    `json:"received" gorm:"not null;default:0"`
+	UnitCost        int64  `json:"unit_cost" gorm:"not null"`
+}
+
+func (PurchaseOrderLine) TableName() string {
+	return "purchase_order_lines"
+}
+
+// ReorderRule is a product's reorder point: at or below ReorderPoint units in stock the product
+// is low, admins and its vendor are alerted, and with AutoDraft a purchase order for
+// ReorderQuantity units is drafted to the preferred supplier
+type ReorderRule struct {
+	ProductID       string     `json:"product_id" gorm:"primaryKey;type:char(36)"`
+	ReorderPoint    int        `json:"reorder_point" gorm:"not null"`
+	ReorderQuantity int        `json:"reorder_quantity" gorm:"not null;default:0"`
+	SupplierID      string     `json:"supplier_id,omitempty" gorm:"type:char(36);index"` // preferred supplier
+	AutoDraft       bool       `json:"auto_draft" gorm:"not null;default:false"`
+	AlertedAt       *time.Time `json:"alerted_at,omitempty"` // last low-stock alert; cleared once stock is back above the point
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (ReorderRule) TableName() string {
+	return "reorder_rules"
+}
+
+// LowStockItem is a product at or below its reorder point
+type LowStockItem struct {
+	ReorderRule
+	SKU      string `json:"sku"`
+	Name     string `json:"name"`
+	VendorID string `json:"vendor_id,omitempty"`
+	Stock    int    `json:"stock"`
+	OnOrder  int    `json:"on_order"` // units on open purchase orders, drafts included, not yet received
+}
+
+// LowStockScan is what one low-stock scan did
+type LowStockScan struct {
+	LowStock       int      `json:"low_stock"`       // products at or below their reorder point
+	Alerted        int      `json:"alerted"`         // of which newly low, and alerted
+	PurchaseOrders []string `json:"purchase_orders"` // IDs of the drafts it created
+}
+
+// SupplierRequest creates or replaces a supplier (admin only)
+type SupplierRequest struct {
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	Currency     string `json:"currency"` // defaults to the store's default currency
+	LeadTimeDays int    `json:"lead_time_days"`
+	Active       *bool  `json:"active"` // defaults to true
+	Notes        string `json:"notes"`
+}
+
+// SupplierProductRequest sets a supplier's terms for a product (admin only)
+type SupplierProductRequest struct {
+	SupplierSKU  string `json:"supplier_sku"`
+	UnitCost     int64  `json:"unit_cost"`
+	LeadTimeDays int    `json:"lead_time_days"`
+}
+
+// PurchaseOrderRequest drafts a purchase order (admin only)
+type PurchaseOrderRequest struct {
+	SupplierID  string                  `json:"supplier_id"`
+	WarehouseID string                  `json:"warehouse_id"` // optional
+	Lines       []PurchaseOrderLineItem `json:"lines"`
+	Note        string                  `json:"note"`
+}
+
+type PurchaseOrderLineItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	UnitCost  *int64 `json:"unit_cost"` // defaults to the supplier's cost for the product
+}
+
+// ReorderRuleRequest sets a product's reorder point (admin only)
+type ReorderRuleRequest struct {
+	ReorderPoint    int    `json:"reorder_point"`
+	ReorderQuantity int    `json:"reorder_quantity"` // required with auto_draft
+	SupplierID      string `json:"supplier_id"`      // preferred supplier; required with auto_draft
+	AutoDraft       bool   `json:"auto_draft"`
+}
+
+// ReceiveRequest books units of a purchase order into stock (admin only)
+type ReceiveRequest struct {
+	Lines []AllocationItem `json:"lines"` // product_id and quantity arrived
+}
+
+// PurchaseOrderListResponse is a page of purchase orders, newest first
+type PurchaseOrderListResponse struct {
+	PurchaseOrders []PurchaseOrder `json:"purchase_orders"`
+	Total          int64           `json:"total"`
+	Limit          int             `json:"limit"`
+	Offset         int             `json:"offset"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// VAT number states
+const (
+	VATUnverified = "unverified" // format is valid; no registry answer (no provider, unsupported country or registry down)
+	VATValid      = "valid"      // the registry confirmed the number
+	VATInvalid    = "invalid"    // the registry doesn't know the number, or it was deregistered
+)
+
+// TaxProfile is a customer's tax identity: their VAT/TIN number and whether they are exempt
+// from tax. Exemption is set by admins, e.g. for B2B reverse charge or an exemption certificate;
+// checkout prices an exempt customer with the exempt tax treatment whatever their group
+type TaxProfile struct {
+	UserID          string     `json:"user_id" gorm:"primaryKey;type:char(36)"`
+	VATID           string     `json:"vat_id,omitempty" gorm:"type:varchar(32);index"` // as written on invoices, e.g. DE123456789
+	Country         string     `json:"country,omitempty" gorm:"type:char(2)"`          // issuing country
+	VATStatus       string     `json:"vat_status,omitempty" gorm:"type:varchar(16)"`
+	VerifiedName    string     `json:"verified_name,omitempty" gorm:"type:varchar(255)"` // as registered, when the registry shares it
+	VerifiedAddress string     `json:"verified_address,omitempty" gorm:"type:text"`
+	CheckedAt       *time.Time `json:"checked_at,omitempty"` // last registry answer
+	Exempt          bool       `json:"exempt" gorm:"not null;default:false"`
+	ExemptReason    string     `json:"exempt_reason,omitempty" gorm:"type:varchar(255)"` // e.g. certificate number; recorded for filings
+	ExemptUntil     *time.Time `json:"exempt_until,omitempty"`                           // the exemption lapses then; nil means open-ended
+	ExemptSetBy     string     `json:"exempt_set_by,omitempty" gorm:"type:char(36)"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (TaxProfile) TableName() string {
+	return "customer_tax_profiles"
+}
+
+// TaxProfileRequest replaces a customer's tax profile
+// The exemption fields are admin-only; they are ignored when customers update their own profile
+type TaxProfileRequest struct {
+	VATID        string     `json:"vat_id"`  // empty removes the number
+	Country      string     `json:"country"` // needed for numbers without a country prefix, e.g. a KRA PIN
+	Exempt       bool       `json:"exempt"`
+	ExemptReason string     `json:"exempt_reason"` // required when exempt
+	ExemptUntil  *time.Time `json:"exempt_until"`
+}
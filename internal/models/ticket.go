@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Support ticket states
+const (
+	TicketOpen     = "open"     // waiting for the store
+	TicketAnswered = "answered" // the store replied; waiting for the customer
+	TicketClosed   = "closed"   // a reply from either side opens it again
+)
+
+// Ticket is a customer's support request, optionally about one of their orders
+type Ticket struct {
+	ID            string          `json:"id" gorm:"primaryKey;type:char(36)"`
+	UserID        string          `json:"user_id" gorm:"not null;type:char(36);index"`
+	OrderID       string          `json:"order_id,omitempty" gorm:"type:char(36);index"`
+	OrderNumber   string          `json:"order_number,omitempty" gorm:"type:varchar(32)"` // as when the ticket was raised
+	Subject       string          `json:"subject" gorm:"not null;type:varchar(200)"`
+	Status        string          `json:"status" gorm:"not null;type:varchar(16);index"`
+	LastMessageAt time.Time       `json:"last_message_at" gorm:"index"`
+	ClosedAt      *time.Time      `json:"closed_at,omitempty"`
+	Messages      []TicketMessage `json:"messages,omitempty" gorm:"foreignKey:TicketID"` // only on ticket detail
+	CreatedAt     time.Time       `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt     time.Time       `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (t *Ticket) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Ticket) TableName() string {
+	return "tickets"
+}
+
+// TicketMessage is one message in a ticket's thread, from the customer or the store
+type TicketMessage struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	TicketID   string    `json:"ticket_id" gorm:"not null;type:char(36);index"`
+	AuthorID   string    `json:"author_id" gorm:"type:char(36)"`
+	AuthorName string    `json:"author_name,omitempty" gorm:"type:varchar(255)"` // as it was when the message was written
+	AuthorRole string    `json:"author_role" gorm:"not null;type:varchar(32)"`
+	Body       string    `json:"body" gorm:"not null;type:text"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime:milli;index"`
+}
+
+func (m *TicketMessage) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (TicketMessage) TableName() string {
+	return "ticket_messages"
+}
+
+// CreateTicketRequest raises a ticket with its first message
+type CreateTicketRequest struct {
+	Subject string `json:"subject"`
+	OrderID string `json:"order_id,omitempty"` // one of the caller's orders
+	Message string `json:"message"`
+}
+
+// TicketReplyRequest adds a message to a ticket
+type TicketReplyRequest struct {
+	Message string `json:"message"`
+}
+
+// TicketListResponse is a page of tickets, most recent activity first, without their messages
+type TicketListResponse struct {
+	Tickets []Ticket `json:"tickets"`
+	Total   int64    `json:"total"`
+	Limit   int      `json:"limit"`
+	Offset  int      `json:"offset"`
+}
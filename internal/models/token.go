@@ -0,0 +1,16 @@
+package models
+
+// ScopedTokenRequest asks for a token limited to scopes, e.g. for an integration
+type ScopedTokenRequest struct {
+	Scopes    []string `json:"scopes"`               // e.g. ["read:orders", "write:products"]
+	ExpiresIn int64    `json:"expires_in,omitempty"` // seconds; 0 means the longest allowed
+	Tenant    string   `json:"tenant,omitempty"`     // tenant the integration acts for; admins only
+}
+
+// ScopedTokenResponse is a token limited to the granted scopes
+type ScopedTokenResponse struct {
+	Token     string   `json:"token"`
+	ExpiresAt int64    `json:"expires_at"`
+	Scopes    []string `json:"scopes"`
+	Tenant    string   `json:"tenant,omitempty"`
+}
@@ -3,21 +3,54 @@ package models
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// User roles
+// Carried in access tokens and checked by auth.RequireRole
+const (
+	RoleCustomer = "customer"
+	RoleAdmin    = "admin"
+	RoleVendor   = "vendor" // marketplace seller; granted by creating a vendor profile
+)
+
 // User represents a user in the system
 // GORM model: automatically manages ID, created_at, updated_at, deleted_at
 // Kept separate from database/HTTP representations for flexibility
 type User struct {
 	ID           string    `json:"id" gorm:"primaryKey;type:char(36)"`
 	Email        string    `json:"email" gorm:"uniqueIndex;not null;type:varchar(255)"`
+	Username     *string   `json:"username,omitempty" gorm:"uniqueIndex:,option:CONCURRENTLY;type:varchar(32)"` // lower case; NULL until chosen, so the index allows many
+	Phone        string    `json:"-" gorm:"type:varchar(20);index:,option:CONCURRENTLY"`                        // E.164, see internal/phone; only serialized in an Account
 	PasswordHash string    `json:"-" gorm:"not null;type:varchar(255)"`
 	FirstName    string    `json:"first_name" gorm:"type:varchar(255)"`
 	LastName     string    `json:"last_name" gorm:"type:varchar(255)"`
+	Locale       string    `json:"locale" gorm:"type:varchar(16);not null;default:en"` // language for emails and notifications
+	AvatarKey    string    `json:"-" gorm:"type:varchar(255)"`                         // storage prefix of the current avatar; empty shows the identicon
+	AvatarURL    string    `json:"avatar_url,omitempty" gorm:"-"`                      // set by handlers, see user.AvatarService.URL
+	Role         string    `json:"role" gorm:"type:varchar(32);not null;default:customer"`
+	KeycloakID   string    `json:"-" gorm:"type:varchar(36);index:,option:CONCURRENTLY"` // set once provisioned in Keycloak
+	SyncedRole   string    `json:"-" gorm:"type:varchar(32)"`                            // role both sides agreed on at the last sync
 	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
 	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	Links        Links          `json:"_links,omitempty" gorm:"-"`
+}
+
+// BeforeCreate assigns a UUID primary key
+// id is char(36) with no database default, so it must be set before insert
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == "" {
+		u.ID = uuid.NewString()
+	}
+	if u.Role == "" {
+		u.Role = RoleCustomer
+	}
+	if u.Locale == "" {
+		u.Locale = "en"
+	}
+	return nil
 }
 
 // TableName specifies the table name in database
@@ -26,23 +59,83 @@ func (User) TableName() string {
 	return "users"
 }
 
+// UpdateRoleRequest changes a user's role (admin only)
+type UpdateRoleRequest struct {
+	Role string `json:"role"` // customer or admin
+}
+
 // LoginRequest represents incoming login request payload
+// Sign in with email, username or phone; an email value without @ is taken as a username
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+	Phone    string `json:"phone,omitempty"` // any format Register accepts, e.g. 0712 345 678
 	Password string `json:"password" binding:"required,min=6"`
 }
 
 // RegisterRequest represents incoming registration request payload
 type RegisterRequest struct {
-	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=6"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	Email        string `json:"email" binding:"required,email"`
+	Password     string `json:"password" binding:"required,min=6"`
+	Username     string `json:"username,omitempty"` // optional; 3-30 of a-z, 0-9, _ and ., starting with a letter
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Phone        string `json:"phone,omitempty"`         // normalized to E.164; national numbers use PHONE_DEFAULT_REGION
+	Locale       string `json:"locale,omitempty"`        // e.g. sw; defaults to the request's Accept-Language
+	ReferralCode string `json:"referral_code,omitempty"` // optional; credits the customer who shared it
+}
+
+// UserBatchResponse is returned by GET /users?ids=...
+// Users follow the order of the requested IDs; IDs with no user are listed in Missing
+type UserBatchResponse struct {
+	Users   []UserProfile `json:"users"`
+	Missing []string      `json:"missing"`
+}
+
+// UserProfile is what anyone may see of a user, e.g. the author of a review or question
+// Contact details stay out: only the user themselves and admins get an Account
+type UserProfile struct {
+	ID        string  `json:"id"`
+	Username  *string `json:"username,omitempty"`
+	FirstName string  `json:"first_name"`
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Links     Links   `json:"_links,omitempty"`
+}
+
+// ProfileOf returns the public profile of user
+func ProfileOf(user *User) UserProfile {
+	return UserProfile{
+		ID:        user.ID,
+		Username:  user.Username,
+		FirstName: user.FirstName,
+		AvatarURL: user.AvatarURL,
+		Links:     user.Links,
+	}
 }
 
 // AuthResponse represents successful authentication response
 type AuthResponse struct {
-	Token     string `json:"token"`
-	User      *User  `json:"user"`
-	ExpiresAt int64  `json:"expires_at"`
+	Token     string   `json:"token"`
+	User      *Account `json:"user"`
+	ExpiresAt int64    `json:"expires_at"`
+}
+
+// Account is a user as the user themselves and admins see it: User plus the phone number,
+// which User never serializes so it can't leak through responses about other people
+type Account struct {
+	*User
+	Phone string `json:"phone,omitempty"`
+}
+
+// AccountOf returns the account view of user
+func AccountOf(user *User) *Account {
+	return &Account{User: user, Phone: user.Phone}
+}
+
+// UsernameAvailability is returned by GET /username-available
+// Reason explains why an unavailable name can't be used (taken, reserved or invalid)
+type UsernameAvailability struct {
+	Username  string `json:"username"` // normalized form that would be saved
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
 }
@@ -0,0 +1,292 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Vendor states; suspended vendors keep their products and earnings but can't use the dashboard
+const (
+	VendorActive    = "active"
+	VendorSuspended = "suspended"
+)
+
+// Vendor sale states
+const (
+	VendorSalePending   = "pending"   // order not delivered yet
+	VendorSaleDelivered = "delivered" // earned; counts toward the next payout
+)
+
+// Vendor payout states
+const (
+	VendorPayoutPending    = "pending"    // lines reserved; the money is about to be sent
+	VendorPayoutProcessing = "processing" // handed to the provider (or to an admin, with the manual provider), result not in yet
+	VendorPayoutPaid       = "paid"
+	VendorPayoutFailed     = "failed" // lines released to the next payout
+)
+
+// Vendor is a marketplace seller, run by one user with the vendor role
+type Vendor struct {
+	ID            string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	UserID        string    `json:"user_id" gorm:"uniqueIndex;not null;type:char(36)"`
+	Name          string    `json:"name" gorm:"not null;type:varchar(255)"`
+	Email         string    `json:"email" gorm:"type:varchar(255)"`
+	Phone         string    `json:"phone" gorm:"type:varchar(20)"`
+	PayoutAccount string    `json:"payout_account" gorm:"type:varchar(255)"` // where payouts go, e.g. an M-Pesa number or IBAN
+	CommissionBPS *int      `json:"commission_bps"`                          // basis points; nil uses the vendor_commission_bps setting
+	Status        string    `json:"status" gorm:"not null;type:varchar(16);default:active"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (v *Vendor) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == "" {
+		v.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Vendor) TableName() string {
+	return "vendors"
+}
+
+// VendorSale is one order line of a vendor's product and what the vendor earns from it
+// The commission rate is fixed when the order is placed; refunds reduce the line's amounts,
+// and payouts settle the difference between Net and PaidNet
+type VendorSale struct {
+	ID               string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	VendorID         string     `json:"vendor_id" gorm:"not null;type:char(36);index:idx_vendor_sales_vendor,priority:1"`
+	OrderID          string     `json:"order_id" gorm:"not null;type:char(36);uniqueIndex:idx_vendor_sales_line,priority:1"`
+	Line             int        `json:"line" gorm:"not null;uniqueIndex:idx_vendor_sales_line,priority:2"` // position in the order
+	OrderNumber      string     `json:"order_number,omitempty" gorm:"type:varchar(32)"`
+	ProductID        string     `json:"product_id" gorm:"not null;type:char(36);index"`
+	ProductName      string     `json:"product_name" gorm:"type:varchar(255)"` // at the time of the order
+	Quantity         int        `json:"quantity" gorm:"not null"`
+	RefundedQuantity int        `json:"refunded_quantity" gorm:"not null;default:0"`
+	UnitPrice        int64      `json:"unit_price" gorm:"not null"`
+	Currency         string     `json:"currency" gorm:"not null;type:char(3)"`
+	CommissionBPS    int        `json:"commission_bps" gorm:"not null"`
+	Gross            int64      `json:"gross" gorm:"not null"`      // unit price × quantity not refunded
+	Commission       int64      `json:"commission" gorm:"not null"` // the store's share of gross
+	Net              int64      `json:"net" gorm:"not null"`        // gross − commission, owed to the vendor
+	PaidNet          int64      `json:"paid_net" gorm:"not null;default:0"`
+	LastPayoutID     string     `json:"last_payout_id,omitempty" gorm:"type:char(36)"`
+	Status           string     `json:"status" gorm:"not null;type:varchar(16)"`
+	DeliveredAt      *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"autoCreateTime:milli;index:idx_vendor_sales_vendor,priority:2"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (s *VendorSale) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (VendorSale) TableName() string {
+	return "vendor_sales"
+}
+
+// SetAmounts derives Gross, Commission and Net from the quantity not refunded
+// Commission is rounded half up to the minor unit
+func (s *VendorSale) SetAmounts() {
+	s.Gross = s.UnitPrice * int64(s.Quantity-s.RefundedQuantity)
+	s.Commission = (s.Gross*int64(s.CommissionBPS) + 5000) / 10000
+	s.Net = s.Gross - s.Commission
+}
+
+// VendorSaleRefund records that a refund already reduced a sale, so redelivered
+// refund events are applied once
+type VendorSaleRefund struct {
+	RefundID  string    `json:"refund_id" gorm:"primaryKey;type:varchar(64)"`
+	SaleID    string    `json:"sale_id" gorm:"primaryKey;type:char(36)"`
+	Quantity  int       `json:"quantity" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+}
+
+func (VendorSaleRefund) TableName() string {
+	return "vendor_sale_refunds"
+}
+
+// VendorPayout is money sent to a vendor for delivered sales in one currency
+// Payouts recorded by an admin are paid at once; batch payouts go through the disbursement provider
+type VendorPayout struct {
+	ID            string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	VendorID      string     `json:"vendor_id" gorm:"not null;type:char(36);index"`
+	BatchID       string     `json:"batch_id,omitempty" gorm:"type:char(36);index"`
+	Currency      string     `json:"currency" gorm:"not null;type:char(3)"`
+	Amount        int64      `json:"amount" gorm:"not null"`
+	Lines         int        `json:"lines" gorm:"not null"` // sales settled
+	Provider      string     `json:"provider" gorm:"not null;type:varchar(32);default:manual"`
+	Status        string     `json:"status" gorm:"not null;type:varchar(16);default:paid;index"`
+	Reference     string     `json:"reference,omitempty" gorm:"type:varchar(255)"` // bank or M-Pesa transaction reference
+	FailureReason string     `json:"failure_reason,omitempty" gorm:"type:varchar(255)"`
+	CreatedBy     string     `json:"created_by,omitempty" gorm:"type:char(36)"` // empty for scheduled batches
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"autoCreateTime:milli;index"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (p *VendorPayout) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (VendorPayout) TableName() string {
+	return "vendor_payouts"
+}
+
+// VendorPayoutLine is one sale settled by a payout, as it stood when paid
+// Statements read these snapshots, so later refunds don't rewrite past statements
+type VendorPayoutLine struct {
+	PayoutID         string `json:"-" gorm:"primaryKey;type:char(36)"`
+	SaleID           string `json:"sale_id" gorm:"primaryKey;type:char(36)"`
+	OrderID          string `json:"order_id" gorm:"not null;type:varchar(64)"`
+	OrderNumber      string `json:"order_number,omitempty" gorm:"type:varchar(64)"`
+	ProductName      string `json:"product_name" gorm:"type:varchar(255)"`
+	Quantity         int    `json:"quantity" gorm:"not null"`
+	RefundedQuantity int    `json:"refunded_quantity" gorm:"not null"`
+	UnitPrice        int64  `json:"unit_price" gorm:"not null"`
+	Gross            int64  `json:"gross" gorm:"not null"`
+	Commission       int64  `json:"commission" gorm:"not null"`
+	Net              int64  `json:"net" gorm:"not null"`
+	PaidBefore       int64  `json:"paid_before" gorm:"not null"` // paid on this line by earlier payouts
+	Amount           int64  `json:"amount" gorm:"not null"`      // paid by this payout; negative claws back a refund
+}
+
+func (VendorPayoutLine) TableName() string {
+	return "vendor_payout_lines"
+}
+
+// VendorPayoutBatch is one run of the payout engine, paying every vendor their settled earnings
+type VendorPayoutBatch struct {
+	ID            string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	SettledBefore time.Time `json:"settled_before"` // lines delivered up to this time were included
+	Payouts       int       `json:"payouts" gorm:"not null"`
+	CreatedBy     string    `json:"created_by,omitempty" gorm:"type:char(36)"` // empty when scheduled
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime:milli;index"`
+}
+
+func (b *VendorPayoutBatch) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == "" {
+		b.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (VendorPayoutBatch) TableName() string {
+	return "vendor_payout_batches"
+}
+
+// VendorRequest creates or updates a vendor (admin only)
+type VendorRequest struct {
+	UserID        string `json:"user_id"` // on create: the user who runs the vendor; ignored on update
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	Phone         string `json:"phone"`
+	PayoutAccount string `json:"payout_account"`
+	CommissionBPS *int   `json:"commission_bps"` // 0-10000; null uses the store default
+	Status        string `json:"status"`         // on update: active or suspended
+}
+
+// VendorPayoutRequest pays a vendor everything available in one currency
+type VendorPayoutRequest struct {
+	Currency  string `json:"currency"`
+	Reference string `json:"reference"`
+}
+
+// VendorPayoutConfirmRequest settles a pending payout by hand (admin only)
+// Reference is required to mark it paid; Reason explains a failure
+type VendorPayoutConfirmRequest struct {
+	Reference string `json:"reference"`
+	Reason    string `json:"reason"`
+}
+
+// VendorPayoutStatement is a payout with the sales it settled
+type VendorPayoutStatement struct {
+	VendorPayout
+	VendorName string                `json:"vendor_name"`
+	Items      []VendorPayoutLine    `json:"items"`
+	Totals     VendorStatementTotals `json:"totals"`
+}
+
+// VendorStatementTotals sums a statement's lines, in minor units
+// Net − PaidBefore − Amount is carried to the next payout
+type VendorStatementTotals struct {
+	Sales      int64 `json:"sales"`   // unit price × quantity ordered
+	Refunds    int64 `json:"refunds"` // unit price × quantity refunded
+	Commission int64 `json:"commission"`
+	Net        int64 `json:"net"`
+	PaidBefore int64 `json:"paid_before"`
+	Amount     int64 `json:"amount"`
+	Carried    int64 `json:"carried"`
+}
+
+// VendorPayoutBatchResponse is a batch with its payouts
+type VendorPayoutBatchResponse struct {
+	VendorPayoutBatch
+	Items []VendorPayout `json:"items"`
+}
+
+// VendorPayoutBatchListResponse is a page of payout batches, newest first
+type VendorPayoutBatchListResponse struct {
+	Batches []VendorPayoutBatch `json:"batches"`
+	Total   int64               `json:"total"`
+	Limit   int                 `json:"limit"`
+	Offset  int                 `json:"offset"`
+}
+
+// VendorListResponse is a page of vendors by name
+type VendorListResponse struct {
+	Vendors []Vendor `json:"vendors"`
+	Total   int64    `json:"total"`
+	Limit   int      `json:"limit"`
+	Offset  int      `json:"offset"`
+}
+
+// VendorSaleListResponse is a page of a vendor's order lines, newest first
+type VendorSaleListResponse struct {
+	Sales  []VendorSale `json:"sales"`
+	Total  int64        `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
+
+// VendorPayoutListResponse is a page of a vendor's payouts, newest first
+type VendorPayoutListResponse struct {
+	Payouts []VendorPayout `json:"payouts"`
+	Total   int64          `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+}
+
+// VendorProductListResponse is a page of a vendor's own products, active or not
+type VendorProductListResponse struct {
+	Products []Product `json:"products"`
+	Total    int64     `json:"total"`
+	Limit    int       `json:"limit"`
+	Offset   int       `json:"offset"`
+}
+
+// VendorSummary totals a vendor's sales per currency
+type VendorSummary struct {
+	VendorID string          `json:"vendor_id"`
+	Balances []VendorBalance `json:"balances"`
+}
+
+// VendorBalance is one currency of a VendorSummary, in minor units
+type VendorBalance struct {
+	Currency   string `json:"currency"`
+	Gross      int64  `json:"gross"`
+	Commission int64  `json:"commission"`
+	Net        int64  `json:"net"`
+	Pending    int64  `json:"pending"`   // net of orders not delivered yet
+	Available  int64  `json:"available"` // delivered net not paid out; negative after refunds of paid sales
+	Settled    int64  `json:"settled"`   // the part of available past the payout hold, paid by the next batch
+	Paid       int64  `json:"paid"`
+}
@@ -0,0 +1,242 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Allocation rules: which warehouse ships an order when several have the stock
+const (
+	AllocateNearest  = "nearest"  // shortest distance to the delivery address
+	AllocateCheapest = "cheapest" // lowest shipping rate to the address's delivery zone
+)
+
+// Warehouse is a stock location orders ship from
+// Coordinates come from the address validator (or the admin) and drive nearest allocation
+type Warehouse struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	Code       string    `json:"code" gorm:"uniqueIndex;not null;type:varchar(32)"` // short name used by staff, e.g. NBO-1
+	Name       string    `json:"name" gorm:"not null;type:varchar(255)"`
+	Phone      string    `json:"phone" gorm:"type:varchar(32)"`
+	Line1      string    `json:"line1" gorm:"not null;type:varchar(255)"`
+	City       string    `json:"city" gorm:"type:varchar(128)"`
+	Region     string    `json:"region" gorm:"type:varchar(128)"`
+	PostalCode string    `json:"postal_code" gorm:"type:varchar(32)"`
+	Country    string    `json:"country" gorm:"not null;type:char(2)"`
+	Latitude   *float64  `json:"latitude,omitempty"`
+	Longitude  *float64  `json:"longitude,omitempty"`
+	Active     bool      `json:"active" gorm:"not null"` // inactive warehouses keep their stock but are never allocated
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+
+	Rates []WarehouseRate `json:"rates" gorm:"foreignKey:WarehouseID"`
+}
+
+func (w *Warehouse) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (Warehouse) TableName() string {
+	return "warehouses"
+}
+
+// Address returns the warehouse as a ship-from address for carriers
+func (w *Warehouse) Address() Address {
+	return Address{
+		Recipient:  w.Name,
+		Phone:      w.Phone,
+		Line1:      w.Line1,
+		City:       w.City,
+		Region:     w.Region,
+		PostalCode: w.PostalCode,
+		Country:    w.Country,
+		Latitude:   w.Latitude,
+		Longitude:  w.Longitude,
+	}
+}
+
+// WarehouseRate is what shipping from a warehouse to a delivery zone costs, in minor units of
+// the store's default currency; used by cheapest allocation
+// MinDays and MaxDays are the transit time in days behind delivery estimates; 0 and 0 means unknown
+type WarehouseRate struct {
+	WarehouseID string `json:"-" gorm:"primaryKey;type:char(36)"`
+	Zone        string `json:"zone" gorm:"primaryKey;type:varchar(64)"`
+	Cost        int64  `json:"cost" gorm:"not null"`
+	MinDays     int    `json:"min_days,omitempty" gorm:"not null;default:0"`
+	MaxDays     int    `json:"max_days,omitempty" gorm:"not null;default:0"`
+}
+
+func (WarehouseRate) TableName() string {
+	return "warehouse_rates"
+}
+
+// WarehouseStock is how many units of a product a warehouse holds
+// The product's own stock is the total over all warehouses and changes in the same transaction
+type WarehouseStock struct {
+	WarehouseID string    `json:"warehouse_id" gorm:"primaryKey;type:char(36)"`
+	ProductID   string    `json:"product_id" gorm:"primaryKey;type:char(36);index"`
+	Quantity    int       `json:"quantity" gorm:"not null;default:0"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (WarehouseStock) TableName() string {
+	return "warehouse_stock"
+}
+
+// StockTransfer records units moved from one warehouse to another
+type StockTransfer struct {
+	ID              string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	ProductID       string    `json:"product_id" gorm:"not null;type:char(36);index"`
+	FromWarehouseID string    `json:"from_warehouse_id" gorm:"not null;type:char(36);index"`
+	ToWarehouseID   string    `json:"to_warehouse_id" gorm:"not null;type:char(36);index"`
+	Quantity        int       `json:"quantity" gorm:"not null"`
+	Note            string    `json:"note,omitempty" gorm:"type:varchar(255)"`
+	CreatedBy       string    `json:"created_by" gorm:"type:char(36)"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime:milli;index"`
+}
+
+func (t *StockTransfer) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (StockTransfer) TableName() string {
+	return "stock_transfers"
+}
+
+// WarehouseRequest creates or replaces a warehouse (admin only)
+type WarehouseRequest struct {
+	Code       string          `json:"code"`
+	Name       string          `json:"name"`
+	Phone      string          `json:"phone"`
+	Line1      string          `json:"line1"`
+	City       string          `json:"city"`
+	Region     string          `json:"region"`
+	PostalCode string          `json:"postal_code"`
+	Country    string          `json:"country"`
+	Latitude   *float64        `json:"latitude"`  // optional; geocoded from the address when omitted
+	Longitude  *float64        `json:"longitude"` // optional; geocoded from the address when omitted
+	Active     *bool           `json:"active"`    // defaults to true
+	Rates      []WarehouseRate `json:"rates"`     // shipping cost per delivery zone
+}
+
+// WarehouseStockRequest changes a warehouse's stock of a product relative to its current value
+type WarehouseStockRequest struct {
+	ProductID string `json:"product_id"`
+	Delta     int    `json:"delta"`  // positive to receive, negative to write off
+	Assign    bool   `json:"assign"` // place units the product counts but no warehouse holds; the total stays
+}
+
+// ProductStockResponse breaks a product's stock down by warehouse (admin only)
+type ProductStockResponse struct {
+	ProductID  string                `json:"product_id"`
+	Total      int                   `json:"total"`      // the product's stock
+	Unassigned int                   `json:"unassigned"` // counted in total but held by no warehouse
+	Warehouses []ProductStockByPlace `json:"warehouses"`
+}
+
+type ProductStockByPlace struct {
+	WarehouseID   string `json:"warehouse_id"`
+	WarehouseCode string `json:"warehouse_code"`
+	Quantity      int    `json:"quantity"`
+}
+
+// WarehouseStockListResponse is a page of a warehouse's stock, by product ID
+type WarehouseStockListResponse struct {
+	Stock  []WarehouseStock `json:"stock"`
+	Total  int64            `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+}
+
+// StockTransferRequest moves units of a product between warehouses
+type StockTransferRequest struct {
+	ProductID       string `json:"product_id"`
+	FromWarehouseID string `json:"from_warehouse_id"`
+	ToWarehouseID   string `json:"to_warehouse_id"`
+	Quantity        int    `json:"quantity"`
+	Note            string `json:"note"`
+}
+
+// StockTransferListResponse is a page of transfers, newest first
+type StockTransferListResponse struct {
+	Transfers []StockTransfer `json:"transfers"`
+	Total     int64           `json:"total"`
+	Limit     int             `json:"limit"`
+	Offset    int             `json:"offset"`
+}
+
+// AllocationRequest asks which warehouses would ship a basket to one of the caller's addresses
+type AllocationRequest struct {
+	AddressID string           `json:"address_id"`
+	Rule      string           `json:"rule"` // nearest or cheapest; defaults to INVENTORY_ALLOCATION
+	Items     []AllocationItem `json:"items"`
+}
+
+type AllocationItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Allocation is the plan for shipping a basket: one shipment per warehouse used
+type Allocation struct {
+	Rule      string               `json:"rule"`
+	Zone      string               `json:"zone"`
+	Shipments []AllocationShipment `json:"shipments"`
+}
+
+// AllocationShipment is the part of a basket one warehouse ships
+type AllocationShipment struct {
+	WarehouseID   string            `json:"warehouse_id"`
+	WarehouseCode string            `json:"warehouse_code"`
+	DistanceKm    *float64          `json:"distance_km,omitempty"`   // when both ends have coordinates
+	ShippingCost  *int64            `json:"shipping_cost,omitempty"` // when the warehouse has a rate for the zone
+	Delivery      *DeliveryEstimate `json:"delivery,omitempty"`      // when the rate has a transit time
+	Items         []AllocationItem  `json:"items"`
+}
+
+// DeliveryEstimate is when a shipment or shipment group should arrive, counted in days from today
+type DeliveryEstimate struct {
+	MinDays  int    `json:"min_days"`
+	MaxDays  int    `json:"max_days"`
+	Earliest string `json:"earliest"` // YYYY-MM-DD, UTC
+	Latest   string `json:"latest"`
+}
+
+// SplitAllocationRequest splits one checkout's basket across several of the caller's addresses
+type SplitAllocationRequest struct {
+	Rule   string                 `json:"rule"` // nearest or cheapest; defaults to INVENTORY_ALLOCATION
+	Groups []ShipmentGroupRequest `json:"groups"`
+}
+
+// ShipmentGroupRequest is the part of a basket that goes to one address
+type ShipmentGroupRequest struct {
+	AddressID string           `json:"address_id"`
+	Items     []AllocationItem `json:"items"`
+}
+
+// SplitAllocation is the plan for a split checkout: one shipment group per address
+// ShippingCost is the sum over all groups, left out when any shipment's cost is unknown
+type SplitAllocation struct {
+	Rule         string          `json:"rule"`
+	Groups       []ShipmentGroup `json:"groups"`
+	ShippingCost *int64          `json:"shipping_cost,omitempty"`
+}
+
+// ShipmentGroup is what goes to one address, possibly from several warehouses
+// The group's cost is the sum of its shipments' and its delivery estimate runs until the last
+// shipment arrives; each is left out when any shipment lacks it
+type ShipmentGroup struct {
+	AddressID    string               `json:"address_id"`
+	Zone         string               `json:"zone"`
+	Shipments    []AllocationShipment `json:"shipments"`
+	ShippingCost *int64               `json:"shipping_cost,omitempty"`
+	Delivery     *DeliveryEstimate    `json:"delivery,omitempty"`
+}
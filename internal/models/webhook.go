@@ -0,0 +1,115 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Public webhook event types consumers can subscribe to
+// Mapped from internal domain events by the webhook module
+const (
+	WebhookEventOrderCreated   = "order.created"
+	WebhookEventOrderPaid      = "order.paid"
+	WebhookEventProductUpdated = "product.updated"
+)
+
+// Webhook delivery states
+const (
+	DeliveryPending   = "pending"   // waiting for first attempt or a retry
+	DeliverySucceeded = "succeeded" // consumer answered 2xx
+	DeliveryFailed    = "failed"    // gave up after max attempts
+)
+
+// WebhookSubscription is a callback URL registered by an API consumer for a set of event types
+// Secret signs every delivery so consumers can verify it came from us
+type WebhookSubscription struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:char(36)"`
+	OwnerID    string    `json:"owner_id" gorm:"index;not null;type:char(36)"`
+	URL        string    `json:"url" gorm:"not null;type:varchar(2048)"`
+	EventTypes []string  `json:"event_types" gorm:"serializer:json;type:text"`
+	Secret     string    `json:"-" gorm:"not null;type:varchar(128)"`
+	Active     bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (s *WebhookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Subscribes reports whether the subscription wants eventType
+func (s *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent (or to be sent) to one subscription
+// Rows double as the retry queue (status + next_attempt_at) and the queryable delivery log
+type WebhookDelivery struct {
+	ID             string     `json:"id" gorm:"primaryKey;type:char(36)"`
+	SubscriptionID string     `json:"subscription_id" gorm:"index;not null;type:char(36)"`
+	EventID        string     `json:"event_id" gorm:"not null;type:char(36)"`
+	EventType      string     `json:"event_type" gorm:"not null;type:varchar(64)"`
+	Payload        string     `json:"payload" gorm:"type:text"`
+	Status         string     `json:"status" gorm:"index:idx_webhook_deliveries_due;not null;type:varchar(16)"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	ResponseCode   int        `json:"response_code"`
+	ResponseBody   string     `json:"response_body" gorm:"type:text"`
+	LastError      string     `json:"last_error" gorm:"type:text"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_due"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	ReplayOf       string     `json:"replay_of,omitempty" gorm:"type:char(36)"` // delivery this one manually replays
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime:milli"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"autoUpdateTime:milli"`
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookReplayRequest replays a subscription's deliveries created in [From, To)
+// Replays themselves are never selected, so replaying a range twice sends each event twice, not more
+type WebhookReplayRequest struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`     // zero means now
+	Status string    `json:"status"` // deliveries to replay: failed (default), succeeded or all
+}
+
+// WebhookReplayResponse lists the deliveries a replay queued
+type WebhookReplayResponse struct {
+	Replayed   int               `json:"replayed"`
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// CreateWebhookRequest represents incoming webhook registration payload
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,url"`
+	EventTypes []string `json:"event_types" binding:"required"`
+}
+
+// CreateWebhookResponse returns the subscription plus its signing secret
+// The secret is only shown once, at creation time
+type CreateWebhookResponse struct {
+	*WebhookSubscription
+	Secret string `json:"secret"`
+}
@@ -0,0 +1,117 @@
+package module
+
+import (
+	"context"
+
+	"github.com/Jason-Omondi/ecomgo/internal/address"
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/bulk"
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/campaign"
+	"github.com/Jason-Omondi/ecomgo/internal/captcha"
+	"github.com/Jason-Omondi/ecomgo/internal/channel"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/customergroup"
+	"github.com/Jason-Omondi/ecomgo/internal/disbursement"
+	"github.com/Jason-Omondi/ecomgo/internal/email"
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"github.com/Jason-Omondi/ecomgo/internal/fiscal"
+	"github.com/Jason-Omondi/ecomgo/internal/fraud"
+	"github.com/Jason-Omondi/ecomgo/internal/fx"
+	"github.com/Jason-Omondi/ecomgo/internal/inventory"
+	"github.com/Jason-Omondi/ecomgo/internal/jobs"
+	"github.com/Jason-Omondi/ecomgo/internal/keycloak"
+	"github.com/Jason-Omondi/ecomgo/internal/limits"
+	"github.com/Jason-Omondi/ecomgo/internal/links"
+	"github.com/Jason-Omondi/ecomgo/internal/lock"
+	"github.com/Jason-Omondi/ecomgo/internal/migrations"
+	"github.com/Jason-Omondi/ecomgo/internal/notify"
+	"github.com/Jason-Omondi/ecomgo/internal/ordernumber"
+	"github.com/Jason-Omondi/ecomgo/internal/payment"
+	"github.com/Jason-Omondi/ecomgo/internal/quota"
+	"github.com/Jason-Omondi/ecomgo/internal/search"
+	"github.com/Jason-Omondi/ecomgo/internal/settings"
+	"github.com/Jason-Omondi/ecomgo/internal/shipping"
+	"github.com/Jason-Omondi/ecomgo/internal/storage"
+	"github.com/Jason-Omondi/ecomgo/internal/taxid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Module is a self-contained feature (users, products, orders, cart, payments...)
+// Each module owns its schema, HTTP routes and background services
+// Why here: new features are added by appending a module to the slice handed to APIServer,
+// instead of editing APIServer.Start for every feature
+type Module interface {
+	// Migrations returns schema migrations run before the server accepts requests
+	Migrations() []migrations.Migration
+
+	// RegisterRoutes mounts the module's handlers on the versioned API router (/api/v1)
+	RegisterRoutes(router *mux.Router)
+
+	// Services returns background services started alongside the HTTP server
+	// Return nil when the module has nothing to run in the background
+	Services() []Service
+}
+
+// Service is a long-running background component owned by a module
+// Examples: job workers, outbox pollers, schedulers
+type Service interface {
+	// Name identifies the service in logs
+	Name() string
+
+	// Run blocks until ctx is cancelled or the service fails
+	Run(ctx context.Context) error
+}
+
+// Warmer is implemented by modules that prime caches at startup
+// Warm runs after migrations, while /readyz still reports not ready; errors are logged,
+// not fatal - a cold cache is only slower
+type Warmer interface {
+	Warm(ctx context.Context) error
+}
+
+// Deps bundles shared infrastructure handed to every module constructor
+// Built once in main so all modules share the same connections and config
+type Deps struct {
+	DB       *gorm.DB
+	Config   *config.Config
+	Log      *zap.Logger
+	Clock    clock.Clock       // Current time; clock.System in production, clock.Fake in tests
+	IDs      clock.IDGenerator // IDs for records created by services; UUIDs in production
+	Cache    cache.Cache       // Redis or in-memory, see internal/cache
+	Locks    lock.Locker       // Distributed locks; wrap run-once services in lock.Singleton
+	Events   events.Bus        // Kafka, NATS or in-process, see internal/events
+	Tokens   *auth.TokenManager
+	Mailer   *email.Mailer    // Templated transactional email, see internal/email
+	Notifier *notify.Notifier // Routes user notifications to email/SMS/WhatsApp by preference
+	Jobs     *jobs.Processor  // Background job queue; modules Register handlers and Enqueue work
+	Bulk     *bulk.Registry   // Admin bulk actions; modules Register theirs, run as jobs
+	FX       *fx.Converter    // Exchange rates and currency conversion for pricing and reporting
+	Settings *settings.Store  // Admin-edited store settings (name, support email, currency...); read per use
+
+	OrderNumbers *ordernumber.Generator // Customer-facing order numbers; checkout calls NextTx in the order transaction
+	Campaigns    *campaign.Pricing      // Flash sale prices in force; checkout prices items with Current(ctx).Sale
+	Channels     *channel.Catalog       // Per-channel visibility and list prices; checkout resolves the order's channel first
+	Groups       *customergroup.Pricing // Customer group price lists and tax treatment; checkout prices for ForUser(ctx, buyer)
+	Inventory    *inventory.Allocator   // Picks shipping warehouses; checkout calls Allocate, then Commit in the order transaction
+
+	Addresses address.Validator     // Address normalization/geocoding (no-op, Google or HERE)
+	Zones     *address.Zones        // Delivery zone rules from DELIVERY_ZONES
+	TaxIDs    taxid.Validator       // VAT/TIN registry checks (TAX_ID_PROVIDER)
+	Carriers  shipping.Carriers     // Enabled shipping carriers (SHIPPING_CARRIERS)
+	Keycloak  *keycloak.AdminClient // Keycloak Admin API; nil unless KEYCLOAK_SYNC_ENABLED=true
+	Storage   storage.Storage       // Object storage for images, invoices, exports and labels (STORAGE_BACKEND)
+	Search    search.Engine         // Product search engine; nil when SEARCH_BACKEND=none
+	Fraud     *fraud.Screener       // Checkout risk scoring; call Screen before capturing payment
+	Captcha   *captcha.Guard        // Bot check for sign-up and other abuse-prone endpoints (CAPTCHA_PROVIDER)
+	Payments  payment.Provider      // Payment capture and refunds (PAYMENT_PROVIDER)
+	Payouts   disbursement.Provider // Vendor payout transfers (PAYOUT_PROVIDER)
+	Fiscal    fiscal.Provider       // Fiscal receipts for paid orders (FISCAL_PROVIDER); nil when FISCAL_PROVIDER=none
+
+	HTTPLimiter *limits.Limiter // API request admission; applied by the API server, tuned at runtime
+	Quotas      *quota.Quotas   // Per-tenant plan quotas; enforced by the API server, reported by the usage module
+	Links       *links.Builder  // HAL-style _links for responses; returns nil unless SERVER_RESOURCE_LINKS=true
+}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Jason-Omondi/ecomgo/internal/email"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/sms"
+	"go.uber.org/zap"
+)
+
+// Notification is a short user-facing message (OTP code, order status, payment receipt)
+type Notification struct {
+	// Category picks the user's channel: models.NotifyOTP, NotifyOrderUpdates, NotifyPaymentConfirmations,
+	// NotifyStockAlerts, NotifyMarketing or NotifyQuestions
+	Category string
+	Subject  string // email subject; ignored for SMS/WhatsApp
+	Body     string
+}
+
+// Notifier delivers notifications on the channel each user prefers
+// Falls back to email when SMS/WhatsApp is preferred but no phone number is on file
+type Notifier struct {
+	prefs  *repository.NotificationRepository
+	users  repository.UserStore
+	sms    sms.Sender
+	mailer *email.Mailer
+	log    *zap.Logger
+}
+
+func NewNotifier(prefs *repository.NotificationRepository, users repository.UserStore,
+	smsSender sms.Sender, mailer *email.Mailer, log *zap.Logger) *Notifier {
+	return &Notifier{
+		prefs:  prefs,
+		users:  users,
+		sms:    smsSender,
+		mailer: mailer,
+		log:    log,
+	}
+}
+
+// Notify sends n to userID using their preferred channel for n.Category
+// Returns: error if the user or preferences can't be loaded or the provider rejects the message
+func (n *Notifier) Notify(ctx context.Context, userID string, notification Notification) error {
+	pref, err := n.prefs.GetPreferences(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	channel := pref.ChannelFor(notification.Category)
+	if (channel == models.ChannelSMS || channel == models.ChannelWhatsApp) && pref.Phone == "" {
+		channel = models.ChannelEmail
+	}
+
+	switch channel {
+	case models.ChannelNone:
+		return nil
+	case models.ChannelSMS, models.ChannelWhatsApp:
+		err = n.sms.Send(ctx, sms.Message{To: pref.Phone, Body: notification.Body, Channel: channel})
+	case models.ChannelEmail:
+		err = n.sendEmail(ctx, userID, notification)
+	default:
+		err = fmt.Errorf("unknown notification channel: %s", channel)
+	}
+
+	if err != nil {
+		n.log.Error("Failed to deliver notification", zap.String("user_id", userID),
+			zap.String("category", notification.Category), zap.String("channel", channel), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// sendEmail queues the notification via the generic email template
+func (n *Notifier) sendEmail(ctx context.Context, userID string, notification Notification) error {
+	user, err := n.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return n.mailer.SendAsync(ctx, email.TemplateNotification, user.Locale, user.Email, user.FirstName, email.Data{
+		"Subject": notification.Subject,
+		"Body":    notification.Body,
+	})
+}
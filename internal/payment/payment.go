@@ -0,0 +1,158 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+)
+
+var (
+	// ErrDeclined is returned when the provider refuses a capture (insufficient funds, blocked card...)
+	ErrDeclined = errors.New("payment declined")
+
+	// ErrPaymentNotFound is returned when refunding a payment the provider doesn't know
+	ErrPaymentNotFound = errors.New("payment not found")
+
+	// ErrRefundExceedsCapture is returned when refunds would total more than the captured amount
+	ErrRefundExceedsCapture = errors.New("refund exceeds captured amount")
+
+	// ErrIdempotencyConflict is returned when an idempotency key is reused with different parameters
+	ErrIdempotencyConflict = errors.New("idempotency key reused with different parameters")
+
+	// ErrInvalidSignature is returned when a payment webhook fails authentication
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// Payment statuses
+const (
+	StatusCaptured          = "captured"
+	StatusPartiallyRefunded = "partially_refunded"
+	StatusRefunded          = "refunded"
+)
+
+// Webhook event types, normalized across providers
+const (
+	EventPaymentCaptured = "payment.captured"
+	EventPaymentFailed   = "payment.failed"
+	EventRefundSucceeded = "refund.succeeded"
+	EventDisputeOpened   = "dispute.opened"  // a customer's bank disputed a payment (chargeback)
+	EventDisputeUpdated  = "dispute.updated" // amount, reason or evidence deadline changed
+	EventDisputeClosed   = "dispute.closed"  // decided; Outcome says who won
+)
+
+// Dispute outcomes on EventDisputeClosed
+const (
+	DisputeWon  = "won"  // the store keeps the money
+	DisputeLost = "lost" // the money went back to the customer
+)
+
+// CaptureRequest charges a customer for an order
+// Amounts are in minor units (cents) to avoid floating point rounding
+type CaptureRequest struct {
+	IdempotencyKey string // retries with the same key return the original payment instead of charging twice
+	OrderID        string
+	Amount         int64
+	Currency       string
+	Method         string // provider-specific token, phone number or test card
+}
+
+// Payment is a captured charge
+type Payment struct {
+	ID       string
+	OrderID  string
+	Amount   int64
+	Currency string
+	Refunded int64 // total refunded so far
+	Status   string
+}
+
+// RefundRequest returns part or all of a captured payment
+type RefundRequest struct {
+	IdempotencyKey string
+	PaymentID      string
+	Amount         int64
+	Reason         string
+}
+
+// Refund is a completed refund
+type Refund struct {
+	ID        string
+	PaymentID string
+	Amount    int64
+	Currency  string
+}
+
+// WebhookEvent is a provider notification normalized for checkout
+type WebhookEvent struct {
+	ID         string // provider's event ID - identical on redelivery, used for deduplication
+	Type       string // one of the Event* constants
+	PaymentID  string
+	OrderID    string
+	Amount     int64
+	Currency   string
+	OccurredAt time.Time
+
+	// Dispute events only
+	DisputeID     string     // provider's dispute ID, the same on every event of one dispute
+	Reason        string     // provider's reason code, e.g. fraudulent or product_not_received
+	EvidenceDueBy *time.Time // last moment evidence can be submitted
+	Outcome       string     // DisputeWon or DisputeLost on EventDisputeClosed
+}
+
+// Provider captures and refunds payments with one payment service
+// Every implementation must pass paymenttest.RunConformance:
+//   - Capture is idempotent per IdempotencyKey; reusing a key with other parameters is ErrIdempotencyConflict
+//   - Refunds are idempotent per IdempotencyKey, may be partial, and never total more than the capture
+//   - ParseWebhook returns the same event ID for redeliveries of one event
+type Provider interface {
+	// Name is the provider code stored on payments and used in webhook URLs (sandbox, mpesa, stripe...)
+	Name() string
+
+	// Capture charges req.Amount
+	// Returns: ErrDeclined when the provider refuses the charge
+	Capture(ctx context.Context, req CaptureRequest) (*Payment, error)
+
+	// Refund returns req.Amount of a captured payment
+	// Returns: ErrPaymentNotFound or ErrRefundExceedsCapture
+	Refund(ctx context.Context, req RefundRequest) (*Refund, error)
+
+	// ParseWebhook authenticates a provider notification and normalizes it
+	// Returns: ErrInvalidSignature when authentication fails
+	ParseWebhook(r *http.Request, body []byte) (*WebhookEvent, error)
+}
+
+// New creates the provider selected by PAYMENT_PROVIDER
+func New(cfg config.Payment) (Provider, error) {
+	switch cfg.Provider {
+	case "sandbox":
+		return NewSandbox(cfg.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unsupported PAYMENT_PROVIDER: %s (must be 'sandbox')", cfg.Provider)
+	}
+}
+
+// Deduplicator drops webhook redeliveries: providers retry until they get a 2xx,
+// so the same event can arrive several times and across instances
+type Deduplicator struct {
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+// NewDeduplicator remembers event IDs for ttl; it should exceed the provider's retry window
+func NewDeduplicator(appCache cache.Cache, ttl time.Duration) *Deduplicator {
+	return &Deduplicator{cache: appCache, ttl: ttl}
+}
+
+// First reports whether this is the first delivery of event
+func (d *Deduplicator) First(ctx context.Context, provider string, event *WebhookEvent) (bool, error) {
+	count, err := d.cache.Incr(ctx, "payment:webhook:"+provider+":"+event.ID, d.ttl)
+	if err != nil {
+		return false, err
+	}
+	return count == 1, nil
+}
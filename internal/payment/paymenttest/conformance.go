@@ -0,0 +1,275 @@
+// Package paymenttest is the contract every payment.Provider must satisfy.
+// A provider package runs it from its own tests:
+//
+//	func TestConformance(t *testing.T) {
+//		paymenttest.RunConformance(t, func(t *testing.T) paymenttest.Harness {
+//			sandbox := payment.NewSandbox("secret")
+//			return paymenttest.Harness{Provider: sandbox, Method: "card", Currency: "KES", Webhook: sandbox.Webhook}
+//		})
+//	}
+//
+// Providers backed by a remote test environment should skip when its credentials are unset.
+package paymenttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/payment"
+)
+
+// Harness wires one provider into the suite
+type Harness struct {
+	Provider payment.Provider
+	Method   string // payment method the provider captures successfully in test mode
+	Currency string
+
+	// Webhook builds a signed delivery of event exactly as the provider sends it
+	// Event IDs set by the suite must be kept so redeliveries can be simulated
+	Webhook func(event payment.WebhookEvent) (*http.Request, []byte, error)
+}
+
+// RunConformance runs the contract as subtests; newHarness is called once per subtest
+func RunConformance(t *testing.T, newHarness func(t *testing.T) Harness) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, h Harness)
+	}{
+		{"CaptureIsIdempotent", testCaptureIsIdempotent},
+		{"CaptureKeyReuseConflicts", testCaptureKeyReuseConflicts},
+		{"PartialThenFullRefund", testPartialThenFullRefund},
+		{"RefundIsIdempotent", testRefundIsIdempotent},
+		{"RefundCannotExceedCapture", testRefundCannotExceedCapture},
+		{"RefundUnknownPayment", testRefundUnknownPayment},
+		{"WebhookRedeliveryKeepsEventID", testWebhookRedeliveryKeepsEventID},
+		{"WebhookRejectsTampering", testWebhookRejectsTampering},
+		{"WebhookDeduplication", testWebhookDeduplication},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newHarness(t))
+		})
+	}
+}
+
+// uniqueKey makes idempotency keys unique per run, so remote test environments can be reused
+func uniqueKey(t *testing.T, suffix string) string {
+	return fmt.Sprintf("conformance-%s-%d-%s", t.Name(), time.Now().UnixNano(), suffix)
+}
+
+func capture(t *testing.T, h Harness, amount int64) *payment.Payment {
+	t.Helper()
+
+	p, err := h.Provider.Capture(context.Background(), payment.CaptureRequest{
+		IdempotencyKey: uniqueKey(t, "capture"),
+		OrderID:        "order-" + uniqueKey(t, "order"),
+		Amount:         amount,
+		Currency:       h.Currency,
+		Method:         h.Method,
+	})
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	return p
+}
+
+func testCaptureIsIdempotent(t *testing.T, h Harness) {
+	ctx := context.Background()
+	req := payment.CaptureRequest{
+		IdempotencyKey: uniqueKey(t, "capture"),
+		OrderID:        "order-1",
+		Amount:         2500,
+		Currency:       h.Currency,
+		Method:         h.Method,
+	}
+
+	first, err := h.Provider.Capture(ctx, req)
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if first.ID == "" || first.Amount != req.Amount || first.Status != payment.StatusCaptured {
+		t.Fatalf("Capture = %+v, want a captured payment of %d", first, req.Amount)
+	}
+
+	retry, err := h.Provider.Capture(ctx, req)
+	if err != nil {
+		t.Fatalf("retried Capture: %v", err)
+	}
+	if retry.ID != first.ID {
+		t.Fatalf("retried Capture created payment %s, want original %s", retry.ID, first.ID)
+	}
+}
+
+func testCaptureKeyReuseConflicts(t *testing.T, h Harness) {
+	ctx := context.Background()
+	req := payment.CaptureRequest{
+		IdempotencyKey: uniqueKey(t, "capture"),
+		OrderID:        "order-1",
+		Amount:         2500,
+		Currency:       h.Currency,
+		Method:         h.Method,
+	}
+	if _, err := h.Provider.Capture(ctx, req); err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+
+	req.Amount = 9900
+	if _, err := h.Provider.Capture(ctx, req); !errors.Is(err, payment.ErrIdempotencyConflict) {
+		t.Fatalf("Capture with reused key and new amount: err = %v, want ErrIdempotencyConflict", err)
+	}
+}
+
+func testPartialThenFullRefund(t *testing.T, h Harness) {
+	ctx := context.Background()
+	p := capture(t, h, 5000)
+
+	refund, err := h.Provider.Refund(ctx, payment.RefundRequest{
+		IdempotencyKey: uniqueKey(t, "refund-1"), PaymentID: p.ID, Amount: 2000,
+	})
+	if err != nil {
+		t.Fatalf("partial Refund: %v", err)
+	}
+	if refund.ID == "" || refund.PaymentID != p.ID || refund.Amount != 2000 {
+		t.Fatalf("partial Refund = %+v, want 2000 of %s", refund, p.ID)
+	}
+
+	if _, err := h.Provider.Refund(ctx, payment.RefundRequest{
+		IdempotencyKey: uniqueKey(t, "refund-2"), PaymentID: p.ID, Amount: 3000,
+	}); err != nil {
+		t.Fatalf("refund of the remainder: %v", err)
+	}
+}
+
+func testRefundIsIdempotent(t *testing.T, h Harness) {
+	ctx := context.Background()
+	p := capture(t, h, 5000)
+	req := payment.RefundRequest{IdempotencyKey: uniqueKey(t, "refund"), PaymentID: p.ID, Amount: 3000}
+
+	first, err := h.Provider.Refund(ctx, req)
+	if err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	retry, err := h.Provider.Refund(ctx, req)
+	if err != nil {
+		t.Fatalf("retried Refund: %v", err)
+	}
+	if retry.ID != first.ID {
+		t.Fatalf("retried Refund created refund %s, want original %s", retry.ID, first.ID)
+	}
+
+	// Had the retry refunded again, only 0 would be left - 2000 must still be refundable
+	if _, err := h.Provider.Refund(ctx, payment.RefundRequest{
+		IdempotencyKey: uniqueKey(t, "refund-rest"), PaymentID: p.ID, Amount: 2000,
+	}); err != nil {
+		t.Fatalf("refund of the remainder after a retried refund: %v", err)
+	}
+}
+
+func testRefundCannotExceedCapture(t *testing.T, h Harness) {
+	ctx := context.Background()
+	p := capture(t, h, 5000)
+
+	if _, err := h.Provider.Refund(ctx, payment.RefundRequest{
+		IdempotencyKey: uniqueKey(t, "refund-over"), PaymentID: p.ID, Amount: 5001,
+	}); !errors.Is(err, payment.ErrRefundExceedsCapture) {
+		t.Fatalf("Refund above capture: err = %v, want ErrRefundExceedsCapture", err)
+	}
+
+	if _, err := h.Provider.Refund(ctx, payment.RefundRequest{
+		IdempotencyKey: uniqueKey(t, "refund-1"), PaymentID: p.ID, Amount: 4000,
+	}); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if _, err := h.Provider.Refund(ctx, payment.RefundRequest{
+		IdempotencyKey: uniqueKey(t, "refund-2"), PaymentID: p.ID, Amount: 1001,
+	}); !errors.Is(err, payment.ErrRefundExceedsCapture) {
+		t.Fatalf("cumulative Refund above capture: err = %v, want ErrRefundExceedsCapture", err)
+	}
+}
+
+func testRefundUnknownPayment(t *testing.T, h Harness) {
+	_, err := h.Provider.Refund(context.Background(), payment.RefundRequest{
+		IdempotencyKey: uniqueKey(t, "refund"), PaymentID: "pay_does_not_exist", Amount: 100,
+	})
+	if !errors.Is(err, payment.ErrPaymentNotFound) {
+		t.Fatalf("Refund of unknown payment: err = %v, want ErrPaymentNotFound", err)
+	}
+}
+
+func captureEvent(p *payment.Payment) payment.WebhookEvent {
+	return payment.WebhookEvent{
+		ID:         "evt_" + p.ID,
+		Type:       payment.EventPaymentCaptured,
+		PaymentID:  p.ID,
+		OrderID:    p.OrderID,
+		Amount:     p.Amount,
+		Currency:   p.Currency,
+		OccurredAt: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func testWebhookRedeliveryKeepsEventID(t *testing.T, h Harness) {
+	event := captureEvent(capture(t, h, 1500))
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		r, body, err := h.Webhook(event)
+		if err != nil {
+			t.Fatalf("build webhook: %v", err)
+		}
+		got, err := h.Provider.ParseWebhook(r, body)
+		if err != nil {
+			t.Fatalf("ParseWebhook delivery %d: %v", i+1, err)
+		}
+		if got.Type != event.Type || got.PaymentID != event.PaymentID || got.Amount != event.Amount {
+			t.Fatalf("ParseWebhook = %+v, want %+v", got, event)
+		}
+		ids = append(ids, got.ID)
+	}
+	if ids[0] == "" || ids[0] != ids[1] {
+		t.Fatalf("redelivered webhook event IDs = %q, want the same non-empty ID", ids)
+	}
+}
+
+func testWebhookRejectsTampering(t *testing.T, h Harness) {
+	event := captureEvent(capture(t, h, 1500))
+	r, body, err := h.Webhook(event)
+	if err != nil {
+		t.Fatalf("build webhook: %v", err)
+	}
+
+	tampered := append([]byte{}, body...)
+	tampered[len(tampered)/2] ^= 0x01
+	if _, err := h.Provider.ParseWebhook(r, tampered); !errors.Is(err, payment.ErrInvalidSignature) {
+		t.Fatalf("ParseWebhook of tampered body: err = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func testWebhookDeduplication(t *testing.T, h Harness) {
+	ctx := context.Background()
+	dedup := payment.NewDeduplicator(cache.NewMemoryCache("test:"), time.Hour)
+	event := captureEvent(capture(t, h, 1500))
+
+	for i, want := range []bool{true, false} {
+		r, body, err := h.Webhook(event)
+		if err != nil {
+			t.Fatalf("build webhook: %v", err)
+		}
+		parsed, err := h.Provider.ParseWebhook(r, body)
+		if err != nil {
+			t.Fatalf("ParseWebhook: %v", err)
+		}
+		first, err := dedup.First(ctx, h.Provider.Name(), parsed)
+		if err != nil {
+			t.Fatalf("Deduplicator.First: %v", err)
+		}
+		if first != want {
+			t.Fatalf("delivery %d: First = %v, want %v", i+1, first, want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/hmacsig"
+)
+
+// Sandbox test methods: any other method is captured successfully
+const (
+	SandboxMethodDecline = "decline" // card declined
+	SandboxMethodError   = "error"   // provider outage
+)
+
+// Sandbox stands in for a card processor: captures and refunds settle at once in memory, keyed
+// by idempotency key like the real thing, and Webhook builds the notifications a processor sends
+// later, JSON signed with X-Signature (hex HMAC-SHA256 of the body).
+// Pay with the method "decline" for ErrDeclined, or "error" for an outage checkout retries.
+type Sandbox struct {
+	webhookSecret string
+
+	mu       sync.Mutex
+	payments map[string]*Payment
+	captures map[string]captured // idempotency key -> original request and payment ID
+	refunds  map[string]refunded
+}
+
+type captured struct {
+	req       CaptureRequest
+	paymentID string
+}
+
+type refunded struct {
+	req    RefundRequest
+	refund Refund
+}
+
+func NewSandbox(webhookSecret string) *Sandbox {
+	return &Sandbox{
+		webhookSecret: webhookSecret,
+		payments:      make(map[string]*Payment),
+		captures:      make(map[string]captured),
+		refunds:       make(map[string]refunded),
+	}
+}
+
+func (s *Sandbox) Name() string {
+	return "sandbox"
+}
+
+func (s *Sandbox) Capture(ctx context.Context, req CaptureRequest) (*Payment, error) {
+	if req.IdempotencyKey == "" || req.Amount <= 0 || req.Currency == "" {
+		return nil, fmt.Errorf("sandbox: idempotency key, positive amount and currency are required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prev, ok := s.captures[req.IdempotencyKey]; ok {
+		if prev.req != req {
+			return nil, ErrIdempotencyConflict
+		}
+		payment := *s.payments[prev.paymentID]
+		return &payment, nil
+	}
+
+	switch req.Method {
+	case SandboxMethodDecline:
+		return nil, ErrDeclined
+	case SandboxMethodError:
+		return nil, fmt.Errorf("sandbox: simulated provider outage")
+	}
+
+	payment := &Payment{
+		ID:       "pay_" + randomID(),
+		OrderID:  req.OrderID,
+		Amount:   req.Amount,
+		Currency: strings.ToUpper(req.Currency),
+		Status:   StatusCaptured,
+	}
+	s.payments[payment.ID] = payment
+	s.captures[req.IdempotencyKey] = captured{req: req, paymentID: payment.ID}
+
+	result := *payment
+	return &result, nil
+}
+
+func (s *Sandbox) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	if req.IdempotencyKey == "" || req.Amount <= 0 {
+		return nil, fmt.Errorf("sandbox: idempotency key and positive amount are required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prev, ok := s.refunds[req.IdempotencyKey]; ok {
+		if prev.req != req {
+			return nil, ErrIdempotencyConflict
+		}
+		refund := prev.refund
+		return &refund, nil
+	}
+
+	payment, ok := s.payments[req.PaymentID]
+	if !ok {
+		return nil, ErrPaymentNotFound
+	}
+	if payment.Refunded+req.Amount > payment.Amount {
+		return nil, ErrRefundExceedsCapture
+	}
+
+	payment.Refunded += req.Amount
+	payment.Status = StatusPartiallyRefunded
+	if payment.Refunded == payment.Amount {
+		payment.Status = StatusRefunded
+	}
+
+	refund := Refund{
+		ID:        "re_" + randomID(),
+		PaymentID: payment.ID,
+		Amount:    req.Amount,
+		Currency:  payment.Currency,
+	}
+	s.refunds[req.IdempotencyKey] = refunded{req: req, refund: refund}
+	return &refund, nil
+}
+
+// sandboxWebhook is the body of a sandbox notification
+type sandboxWebhook struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	PaymentID  string    `json:"payment_id"`
+	OrderID    string    `json:"order_id"`
+	Amount     int64     `json:"amount"`
+	Currency   string    `json:"currency"`
+	OccurredAt time.Time `json:"occurred_at"`
+
+	DisputeID     string     `json:"dispute_id,omitempty"`
+	Reason        string     `json:"reason,omitempty"`
+	EvidenceDueBy *time.Time `json:"evidence_due_by,omitempty"`
+	Outcome       string     `json:"outcome,omitempty"`
+}
+
+func (s *Sandbox) ParseWebhook(r *http.Request, body []byte) (*WebhookEvent, error) {
+	if !hmacsig.Verify(s.webhookSecret, r.Header.Get("X-Signature"), body) {
+		return nil, ErrInvalidSignature
+	}
+
+	var payload sandboxWebhook
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	if payload.ID == "" {
+		return nil, fmt.Errorf("sandbox: webhook without event id")
+	}
+
+	event := WebhookEvent(payload)
+	return &event, nil
+}
+
+// Webhook builds a signed notification for event, as the sandbox "provider" would deliver it
+// Local tools and tests use it to simulate asynchronous confirmations
+func (s *Sandbox) Webhook(event WebhookEvent) (*http.Request, []byte, error) {
+	if event.ID == "" {
+		event.ID = "evt_" + randomID()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	body, err := json.Marshal(sandboxWebhook(event))
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := http.NewRequest(http.MethodPost, "/payments/webhooks/sandbox", strings.NewReader(string(body)))
+	if err != nil {
+		return nil, nil, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-Signature", hmacsig.Sign(s.webhookSecret, body))
+	return r, body, nil
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
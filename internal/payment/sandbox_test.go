@@ -0,0 +1,15 @@
+package payment_test
+
+import (
+	"testing"
+
+	"github.com/Jason-Omondi/ecomgo/internal/payment"
+	"github.com/Jason-Omondi/ecomgo/internal/payment/paymenttest"
+)
+
+func TestSandboxConformance(t *testing.T) {
+	paymenttest.RunConformance(t, func(t *testing.T) paymenttest.Harness {
+		sandbox := payment.NewSandbox("conformance-secret")
+		return paymenttest.Harness{Provider: sandbox, Method: "card", Currency: "KES", Webhook: sandbox.Webhook}
+	})
+}
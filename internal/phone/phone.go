@@ -0,0 +1,110 @@
+// Package phone normalizes phone numbers to E.164 (+254712345678)
+// It follows libphonenumber's parsing rules for the common cases - punctuation, the 00
+// international prefix and national numbers with a trunk 0 - without its full metadata:
+// number lengths are checked for the regions in the table below, others only against E.164.
+package phone
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalid is returned for anything that can't be normalized to a plausible E.164 number
+var ErrInvalid = errors.New("phone must be in E.164 format, e.g. +254712345678")
+
+// e164Pattern matches +<country code><number>, max 15 digits
+var e164Pattern = regexp.MustCompile(`^\+[1-9]\d{6,14}$`)
+
+// region describes national numbering for a default region
+type region struct {
+	code      string // country calling code
+	trunk     string // national prefix dropped when dialing internationally, e.g. 0
+	minLength int    // national significant number length, without the trunk prefix
+	maxLength int
+}
+
+// regions is keyed by ISO 3166-1 alpha-2 code
+var regions = map[string]region{
+	"KE": {code: "254", trunk: "0", minLength: 9, maxLength: 9},
+	"UG": {code: "256", trunk: "0", minLength: 9, maxLength: 9},
+	"TZ": {code: "255", trunk: "0", minLength: 9, maxLength: 9},
+	"RW": {code: "250", trunk: "0", minLength: 9, maxLength: 9},
+	"ET": {code: "251", trunk: "0", minLength: 9, maxLength: 9},
+	"NG": {code: "234", trunk: "0", minLength: 8, maxLength: 10},
+	"GH": {code: "233", trunk: "0", minLength: 9, maxLength: 9},
+	"ZA": {code: "27", trunk: "0", minLength: 9, maxLength: 9},
+	"GB": {code: "44", trunk: "0", minLength: 9, maxLength: 10},
+	"DE": {code: "49", trunk: "0", minLength: 6, maxLength: 13},
+	"FR": {code: "33", trunk: "0", minLength: 9, maxLength: 9},
+	"IN": {code: "91", trunk: "0", minLength: 10, maxLength: 10},
+	"US": {code: "1", trunk: "1", minLength: 10, maxLength: 10},
+	"CA": {code: "1", trunk: "1", minLength: 10, maxLength: 10},
+}
+
+// KnownRegion reports whether numbers without a country code can be parsed for region
+func KnownRegion(code string) bool {
+	_, ok := regions[strings.ToUpper(code)]
+	return ok
+}
+
+// Normalize returns raw in E.164 form
+// "+254 712-345-678", "00254712345678" and, with defaultRegion KE, "0712 345 678" all
+// become +254712345678. An empty defaultRegion only accepts international numbers.
+// Returns: ErrInvalid when raw is not a plausible phone number
+func Normalize(raw, defaultRegion string) (string, error) {
+	var digits strings.Builder
+	international := false
+	for _, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && digits.Len() == 0 && !international:
+			international = true
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/':
+			// visual separators
+		default:
+			return "", ErrInvalid
+		}
+	}
+
+	number := digits.String()
+	if !international && strings.HasPrefix(number, "00") {
+		number, international = number[2:], true
+	}
+
+	if !international {
+		reg, ok := regions[strings.ToUpper(defaultRegion)]
+		if !ok {
+			return "", ErrInvalid
+		}
+		national := strings.TrimPrefix(number, reg.trunk)
+		if len(national) < reg.minLength || len(national) > reg.maxLength {
+			return "", ErrInvalid
+		}
+		number = reg.code + national
+	}
+
+	e164 := "+" + number
+	if !e164Pattern.MatchString(e164) || !plausible(number) {
+		return "", ErrInvalid
+	}
+	return e164, nil
+}
+
+// plausible checks the national number length for regions in the table
+// Numbers for other country codes pass on the E.164 pattern alone
+func plausible(number string) bool {
+	for _, reg := range regions {
+		if !strings.HasPrefix(number, reg.code) {
+			continue
+		}
+		national := number[len(reg.code):]
+		// A trunk prefix kept after the country code (+254 0712...) is a common typo
+		if reg.trunk == "0" && strings.HasPrefix(national, "0") {
+			return false
+		}
+		return len(national) >= reg.minLength && len(national) <= reg.maxLength
+	}
+	return true
+}
@@ -0,0 +1,245 @@
+// Package quota enforces per-tenant API quotas in multi-tenant mode. Each tenant (the tenant of
+// the caller's token, see auth.Tenant) is on a plan that caps its API requests per UTC day and
+// the orders it places per UTC month.
+// Usage is counted in the shared cache, so every instance enforces the same totals; counters
+// live in keys named after their period and simply expire once it is over.
+package quota
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Response headers describing the daily request quota, sent whenever the tenant's plan has one
+const (
+	LimitHeader     = "X-Quota-Limit"
+	RemainingHeader = "X-Quota-Remaining"
+	ResetHeader     = "X-Quota-Reset" // Unix time the counter starts over
+)
+
+// usageRoute is the usage report; it is never counted or refused, so a tenant over quota can see why
+const usageRoute = "GET /admin/usage"
+
+// defaultTenantKey names the default tenant in counter keys; tenant IDs can't start with "_"
+const defaultTenantKey = "_"
+
+// Quotas looks up tenants' plans and keeps their usage counters
+type Quotas struct {
+	cfg    config.Quotas
+	cache  cache.Cache
+	clock  clock.Clock
+	routes map[string]bool // order routes, "METHOD /path/{template}" relative to prefix
+	prefix string
+	log    *zap.Logger
+}
+
+// New returns the quotas of cfg; prefix is the API path prefix the order routes are relative to
+func New(cfg config.Quotas, c cache.Cache, clk clock.Clock, prefix string, log *zap.Logger) *Quotas {
+	routes := make(map[string]bool, len(cfg.OrderRoutes))
+	for _, route := range cfg.OrderRoutes {
+		route = strings.Join(strings.Fields(route), " ")
+		if method, path, found := strings.Cut(route, " "); found {
+			route = strings.ToUpper(method) + " " + path
+		}
+		routes[route] = true
+	}
+	return &Quotas{cfg: cfg, cache: c, clock: clk, routes: routes, prefix: prefix, log: log}
+}
+
+// Plan returns the name and limits of the plan tenant is on; "" and zero limits when unlimited
+func (q *Quotas) Plan(tenant string) (string, config.QuotaPlan) {
+	name, ok := q.cfg.Tenants[tenant]
+	if !ok {
+		name = q.cfg.DefaultPlan
+	}
+	return name, q.cfg.Plans[name]
+}
+
+// Middleware counts every request against the tenant's daily quota and refuses it with 429 once
+// the quota is spent. Order routes are also refused once the month's orders are used up; they
+// count as an order when answered with 2xx. Limits are checked before the order is placed and
+// counted after, so checkouts racing at the boundary can overshoot by the number in flight.
+// When the cache can't be reached the request is let through: an outage never blocks traffic.
+func (q *Quotas) Middleware() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !q.cfg.Enabled || q.route(r) == usageRoute {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := r.Context()
+			tenant := httpctx.TenantFromContext(ctx)
+			_, plan := q.Plan(tenant)
+			now := q.clock.Now()
+
+			if plan.RequestsPerDay > 0 {
+				reset := nextDay(now)
+				used, err := q.cache.Incr(ctx, requestsKey(tenant, now), reset.Sub(now)+time.Hour)
+				if err != nil {
+					q.log.Warn("Request quota counter unavailable", zap.String("tenant", tenant), zap.Error(err))
+				} else {
+					w.Header().Set(LimitHeader, strconv.FormatInt(plan.RequestsPerDay, 10))
+					w.Header().Set(RemainingHeader, strconv.FormatInt(max(plan.RequestsPerDay-used, 0), 10))
+					w.Header().Set(ResetHeader, strconv.FormatInt(reset.Unix(), 10))
+					if used > plan.RequestsPerDay {
+						refuse(w, "Daily request quota exceeded", now, reset)
+						return
+					}
+				}
+			}
+
+			if plan.OrdersPerMonth <= 0 || !q.isOrderRoute(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			reset := nextMonth(now)
+			if used, err := q.count(ctx, ordersKey(tenant, now)); err != nil {
+				q.log.Warn("Order quota counter unavailable", zap.String("tenant", tenant), zap.Error(err))
+			} else if used >= plan.OrdersPerMonth {
+				refuse(w, "Monthly order quota reached", now, reset)
+				return
+			}
+
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+			if sw.status >= 200 && sw.status < 300 {
+				// The order is placed whether or not the client is still there to hear it
+				if _, err := q.cache.Incr(context.WithoutCancel(ctx), ordersKey(tenant, now), reset.Sub(now)+time.Hour); err != nil {
+					q.log.Warn("Failed to count order against quota", zap.String("tenant", tenant), zap.Error(err))
+				}
+			}
+		})
+	}
+}
+
+// Usage reports tenant's plan and what it has used of it in the current day and month
+func (q *Quotas) Usage(ctx context.Context, tenant string) (*models.QuotaUsage, error) {
+	now := q.clock.Now()
+	name, plan := q.Plan(tenant)
+
+	requests, err := q.count(ctx, requestsKey(tenant, now))
+	if err != nil {
+		return nil, err
+	}
+	orders, err := q.count(ctx, ordersKey(tenant, now))
+	if err != nil {
+		return nil, err
+	}
+	return &models.QuotaUsage{
+		Tenant:   tenant,
+		Plan:     name,
+		Enforced: q.cfg.Enabled,
+		Requests: counter(requests, plan.RequestsPerDay, nextDay(now)),
+		Orders:   counter(orders, plan.OrdersPerMonth, nextMonth(now)),
+	}, nil
+}
+
+// count reads a usage counter; a counter that was never incremented is 0
+func (q *Quotas) count(ctx context.Context, key string) (int64, error) {
+	raw, err := q.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(raw), 10, 64)
+}
+
+func (q *Quotas) isOrderRoute(r *http.Request) bool {
+	route := q.route(r)
+	if route == "" {
+		return false
+	}
+	_, template, _ := strings.Cut(route, " ")
+	return q.routes[route] || q.routes[template]
+}
+
+// route returns "METHOD /path/{template}" of the route r matched, relative to prefix
+func (q *Quotas) route(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return r.Method + " " + strings.TrimPrefix(template, q.prefix)
+}
+
+func counter(used, limit int64, reset time.Time) models.QuotaCounter {
+	c := models.QuotaCounter{Used: used, Limit: limit, ResetsAt: reset}
+	if limit > 0 {
+		remaining := max(limit-used, 0)
+		c.Remaining = &remaining
+	}
+	return c
+}
+
+func refuse(w http.ResponseWriter, message string, now, reset time.Time) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(reset.Sub(now).Seconds())+1))
+	http.Error(w, message, http.StatusTooManyRequests)
+}
+
+func requestsKey(tenant string, now time.Time) string {
+	return "quota:requests:" + tenantKey(tenant) + ":" + now.UTC().Format("2006-01-02")
+}
+
+func ordersKey(tenant string, now time.Time) string {
+	return "quota:orders:" + tenantKey(tenant) + ":" + now.UTC().Format("2006-01")
+}
+
+func tenantKey(tenant string) string {
+	if tenant == "" {
+		return defaultTenantKey
+	}
+	return tenant
+}
+
+func nextDay(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+func nextMonth(now time.Time) time.Time {
+	y, m, _ := now.UTC().Date()
+	return time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// statusWriter remembers the status an order route answered with
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
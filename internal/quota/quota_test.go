@@ -0,0 +1,183 @@
+package quota
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// tenantHeader names the tenant of a test request, standing in for the tenant of its token
+const tenantHeader = "X-Test-Tenant"
+
+type quotaServer struct {
+	router *mux.Router
+	clock  *clock.Fake
+}
+
+// newQuotaServer serves /api/v1 behind the quotas of cfg, with its counters in a memory cache
+// driven by a fake clock. POST /checkout places an order unless asked to fail with ?fail=1
+func newQuotaServer(t *testing.T, cfg config.Quotas) *quotaServer {
+	t.Helper()
+	clk := clock.NewFake(time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC))
+	counters := cache.NewMemoryCache("quota-test")
+	counters.UseClock(clk)
+	quotas := New(cfg, counters, clk, "/api/v1", zap.NewNop())
+
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api/v1").Subrouter()
+	api.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(httpctx.WithTenant(r.Context(), r.Header.Get(tenantHeader))))
+		})
+	})
+	api.Use(quotas.Middleware())
+	api.HandleFunc("/products", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}).Methods(http.MethodGet)
+	api.HandleFunc("/checkout", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}).Methods(http.MethodPost)
+	api.HandleFunc("/admin/usage", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}).Methods(http.MethodGet)
+	return &quotaServer{router: router, clock: clk}
+}
+
+func (s *quotaServer) do(t *testing.T, tenant, method, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := testutil.NewRequest(t, method, target, nil)
+	req.Header.Set(tenantHeader, tenant)
+	return testutil.Serve(s.router, req)
+}
+
+func quotas(enabled bool) config.Quotas {
+	return config.Quotas{
+		Enabled:     enabled,
+		Plans:       map[string]config.QuotaPlan{"basic": {RequestsPerDay: 3, OrdersPerMonth: 2}, "unlimited": {}},
+		Tenants:     map[string]string{"acme": "basic", "bigco": "unlimited"},
+		DefaultPlan: "basic",
+		OrderRoutes: []string{"post /checkout"},
+	}
+}
+
+func wantHeader(t *testing.T, rec *httptest.ResponseRecorder, name, want string) {
+	t.Helper()
+	if got := rec.Header().Get(name); got != want {
+		t.Fatalf("%s = %q, want %q", name, got, want)
+	}
+}
+
+func TestRequestQuota(t *testing.T) {
+	s := newQuotaServer(t, quotas(true))
+	midnight := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 1; i <= 3; i++ {
+		rec := s.do(t, "acme", http.MethodGet, "/api/v1/products")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d within the quota = %d", i, rec.Code)
+		}
+		wantHeader(t, rec, LimitHeader, "3")
+		wantHeader(t, rec, RemainingHeader, strconv.Itoa(3-i))
+		wantHeader(t, rec, ResetHeader, strconv.FormatInt(midnight.Unix(), 10))
+		wantHeader(t, rec, "Retry-After", "")
+	}
+
+	rec := s.do(t, "acme", http.MethodGet, "/api/v1/products")
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "Daily request quota exceeded") {
+		t.Fatalf("request over the quota = %d %q", rec.Code, rec.Body.String())
+	}
+	wantHeader(t, rec, RemainingHeader, "0")
+	wantHeader(t, rec, "Retry-After", strconv.Itoa(15*60*60+1)) // 15 hours to midnight, rounded up
+
+	// The usage report is neither counted nor refused
+	if rec := s.do(t, "acme", http.MethodGet, "/api/v1/admin/usage"); rec.Code != http.StatusOK {
+		t.Fatalf("usage report over the quota = %d", rec.Code)
+	}
+	// Other tenants keep their own counters, and unlimited plans have none
+	if rec := s.do(t, "other", http.MethodGet, "/api/v1/products"); rec.Code != http.StatusOK {
+		t.Fatalf("another tenant on the default plan = %d", rec.Code)
+	}
+	for i := 0; i < 5; i++ {
+		rec := s.do(t, "bigco", http.MethodGet, "/api/v1/products")
+		if rec.Code != http.StatusOK || rec.Header().Get(LimitHeader) != "" {
+			t.Fatalf("request %d on an unlimited plan = %d with limit %q", i+1, rec.Code, rec.Header().Get(LimitHeader))
+		}
+	}
+}
+
+func TestRequestQuotaResetsDaily(t *testing.T) {
+	s := newQuotaServer(t, quotas(true))
+	for i := 0; i < 3; i++ {
+		s.do(t, "acme", http.MethodGet, "/api/v1/products")
+	}
+
+	s.clock.Set(time.Date(2026, 3, 31, 23, 59, 59, 0, time.UTC))
+	rec := s.do(t, "acme", http.MethodGet, "/api/v1/products")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("request a second before midnight = %d, want 429", rec.Code)
+	}
+	wantHeader(t, rec, "Retry-After", "2")
+
+	s.clock.Advance(time.Second)
+	rec = s.do(t, "acme", http.MethodGet, "/api/v1/products")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("request after midnight = %d, want 200", rec.Code)
+	}
+	wantHeader(t, rec, RemainingHeader, "2")
+	wantHeader(t, rec, ResetHeader, strconv.FormatInt(time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC).Unix(), 10))
+}
+
+func TestOrderQuota(t *testing.T) {
+	cfg := quotas(true)
+	cfg.Plans["basic"] = config.QuotaPlan{OrdersPerMonth: 2}
+	s := newQuotaServer(t, cfg)
+
+	// Only orders that were placed count
+	if rec := s.do(t, "acme", http.MethodPost, "/api/v1/checkout?fail=1"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("failed checkout = %d", rec.Code)
+	}
+	for i := 1; i <= 2; i++ {
+		if rec := s.do(t, "acme", http.MethodPost, "/api/v1/checkout"); rec.Code != http.StatusCreated {
+			t.Fatalf("order %d within the quota = %d", i, rec.Code)
+		}
+	}
+
+	rec := s.do(t, "acme", http.MethodPost, "/api/v1/checkout")
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "Monthly order quota reached") {
+		t.Fatalf("order over the quota = %d %q", rec.Code, rec.Body.String())
+	}
+	wantHeader(t, rec, "Retry-After", strconv.Itoa(15*60*60+1)) // the month ends at midnight
+	if rec := s.do(t, "acme", http.MethodGet, "/api/v1/products"); rec.Code != http.StatusOK {
+		t.Fatalf("other routes over the order quota = %d", rec.Code)
+	}
+
+	s.clock.Advance(15 * time.Hour)
+	if rec := s.do(t, "acme", http.MethodPost, "/api/v1/checkout"); rec.Code != http.StatusCreated {
+		t.Fatalf("order in the next month = %d", rec.Code)
+	}
+}
+
+func TestQuotaDisabled(t *testing.T) {
+	s := newQuotaServer(t, quotas(false))
+	for i := 0; i < 5; i++ {
+		rec := s.do(t, "acme", http.MethodPost, "/api/v1/checkout")
+		if rec.Code != http.StatusCreated || rec.Header().Get(LimitHeader) != "" {
+			t.Fatalf("request %d with quotas off = %d with limit %q", i+1, rec.Code, rec.Header().Get(LimitHeader))
+		}
+	}
+}
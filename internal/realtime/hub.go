@@ -0,0 +1,76 @@
+package realtime
+
+import (
+	"sync"
+
+	"github.com/Jason-Omondi/ecomgo/internal/events"
+	"go.uber.org/zap"
+)
+
+// Hub fans events out to connections (SSE streams, WebSockets) held by this instance
+// Keys group listeners by topic, e.g. an order ID
+// Feed it from events.Subscriber.SubscribeAll so every instance sees every event
+type Hub struct {
+	mu   sync.RWMutex
+	subs map[string]map[*Subscription]struct{}
+	log  *zap.Logger
+}
+
+// Subscription receives events published under its key until Close
+type Subscription struct {
+	C   <-chan events.Event
+	ch  chan events.Event
+	key string
+	hub *Hub
+}
+
+func NewHub(log *zap.Logger) *Hub {
+	return &Hub{
+		subs: make(map[string]map[*Subscription]struct{}),
+		log:  log,
+	}
+}
+
+// Subscribe registers a listener for key with room for buffer undelivered events
+func (h *Hub) Subscribe(key string, buffer int) *Subscription {
+	ch := make(chan events.Event, buffer)
+	sub := &Subscription{C: ch, ch: ch, key: key, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[key] == nil {
+		h.subs[key] = make(map[*Subscription]struct{})
+	}
+	h.subs[key][sub] = struct{}{}
+	return sub
+}
+
+// Publish delivers event to every listener of key without blocking
+// A listener whose buffer is full (slow client) misses the event rather than stalling the others
+func (h *Hub) Publish(key string, event events.Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subs[key] {
+		select {
+		case sub.ch <- event:
+		default:
+			h.log.Warn("Dropping event for slow listener", zap.String("key", key), zap.String("event_id", event.ID))
+		}
+	}
+}
+
+// Close unregisters the subscription; safe to call more than once
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	listeners := s.hub.subs[s.key]
+	if _, ok := listeners[s]; !ok {
+		return
+	}
+	delete(listeners, s)
+	if len(listeners) == 0 {
+		delete(s.hub.subs, s.key)
+	}
+}
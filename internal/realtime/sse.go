@@ -0,0 +1,57 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// SSE writes a text/event-stream response
+// Each write is flushed immediately so events reach the client without buffering
+type SSE struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// NewSSE sends the stream headers
+// Returns: error if the ResponseWriter can't flush (streaming unsupported)
+func NewSSE(w http.ResponseWriter) (*SSE, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("streaming unsupported")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disable nginx proxy buffering
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &SSE{w: w, flusher: flusher}, nil
+}
+
+// Send writes one event with data encoded as JSON
+func (s *SSE) Send(id, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(s.w, "id: %s\nevent: %s\ndata: %s\n\n", id, eventType, payload); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// Heartbeat writes a comment line; keeps proxies from closing idle streams
+// and surfaces disconnected clients as write errors
+func (s *SSE) Heartbeat() error {
+	if _, err := fmt.Fprint(s.w, ": ping\n\n"); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
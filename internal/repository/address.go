@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrAddressNotFound is returned when an address doesn't exist or belongs to another user
+var ErrAddressNotFound = errors.New("address not found")
+
+type AddressRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewAddressRepository(db *gorm.DB, log *zap.Logger) *AddressRepository {
+	return &AddressRepository{db: db, log: log}
+}
+
+// Create inserts an address; when it is the default, the user's other addresses lose the flag
+func (r *AddressRepository) Create(ctx context.Context, addr *models.Address) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if addr.IsDefault {
+			if err := tx.Model(&models.Address{}).
+				Where("user_id = ? AND is_default = ?", addr.UserID, true).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(addr).Error
+	})
+	if err != nil {
+		r.log.Error("Failed to create address", zap.String("user_id", addr.UserID), zap.Error(err))
+	}
+	return err
+}
+
+// ListByUser returns the user's addresses, default first
+func (r *AddressRepository) ListByUser(ctx context.Context, userID string) ([]models.Address, error) {
+	var addresses []models.Address
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("is_default DESC, created_at DESC").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+// GetByID returns one of the user's addresses
+func (r *AddressRepository) GetByID(ctx context.Context, id, userID string) (*models.Address, error) {
+	addr := &models.Address{}
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(addr).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAddressNotFound
+	}
+	if err != nil {
+		r.log.Error("Failed to fetch address", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return addr, nil
+}
+
+// CountByUser returns how many addresses the user has saved
+func (r *AddressRepository) CountByUser(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Address{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Delete removes one of the user's addresses
+func (r *AddressRepository) Delete(ctx context.Context, id, userID string) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.Address{})
+	if result.Error != nil {
+		r.log.Error("Failed to delete address", zap.String("id", id), zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAddressNotFound
+	}
+	return nil
+}
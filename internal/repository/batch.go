@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// eachBatch calls fn with consecutive batches of up to batchSize rows of query, ordered by primary key
+// Why not FindInBatches alone: it only stops once the next query fails, and how a cancelled query
+// fails is up to the driver. ctx is checked before every query instead, so a cancelled export or
+// report stops without touching the database again and callers can tell it apart with errors.Is.
+func eachBatch[T any](ctx context.Context, query *gorm.DB, batchSize int, fn func([]T) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var rows []T
+	return query.WithContext(ctx).FindInBatches(&rows, batchSize, func(tx *gorm.DB, batch int) error {
+		if err := fn(rows); err != nil {
+			return err
+		}
+		return ctx.Err()
+	}).Error
+}
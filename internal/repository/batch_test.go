@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"gorm.io/gorm"
+)
+
+type batchRow struct {
+	ID int
+}
+
+// newBatchRows returns a table of n rows, inserted out of key order, and a count of the
+// queries run against it
+func newBatchRows(t *testing.T, n int) (*gorm.DB, *int) {
+	t.Helper()
+	db := testutil.NewDB(t, &batchRow{})
+	for i := n; i > 0; i-- {
+		if err := db.Create(&batchRow{ID: i}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	queries := 0
+	if err := db.Callback().Query().After("gorm:query").Register("test:count", func(*gorm.DB) { queries++ }); err != nil {
+		t.Fatal(err)
+	}
+	return db, &queries
+}
+
+// collect runs eachBatch and returns the IDs of each batch it was given
+func collect(ctx context.Context, db *gorm.DB, batchSize int, fn func([]batchRow) error) ([][]int, error) {
+	var batches [][]int
+	err := eachBatch(ctx, db.Model(&batchRow{}), batchSize, func(rows []batchRow) error {
+		ids := make([]int, len(rows))
+		for i, row := range rows {
+			ids[i] = row.ID
+		}
+		batches = append(batches, ids)
+		if fn != nil {
+			return fn(rows)
+		}
+		return nil
+	})
+	return batches, err
+}
+
+func TestEachBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		rows      int
+		batchSize int
+		want      [][]int
+	}{
+		{"last batch partial", 7, 3, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}},
+		{"last batch full", 6, 3, [][]int{{1, 2, 3}, {4, 5, 6}}},
+		{"one partial batch", 2, 3, [][]int{{1, 2}}},
+		{"no rows", 0, 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := newBatchRows(t, tt.rows)
+			batches, err := collect(context.Background(), db, tt.batchSize, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(batches, tt.want) {
+				t.Fatalf("batches = %v, want %v", batches, tt.want)
+			}
+		})
+	}
+}
+
+func TestEachBatchStopsOnCancel(t *testing.T) {
+	t.Run("between batches", func(t *testing.T) {
+		db, queries := newBatchRows(t, 7)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		batches, err := collect(ctx, db, 3, func([]batchRow) error {
+			cancel()
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("eachBatch = %v, want context.Canceled", err)
+		}
+		if len(batches) != 1 || *queries != 1 {
+			t.Fatalf("got %d batches from %d queries after cancelling in the first, want 1 of each", len(batches), *queries)
+		}
+	})
+
+	t.Run("before the first batch", func(t *testing.T) {
+		db, queries := newBatchRows(t, 7)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		batches, err := collect(ctx, db, 3, nil)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("eachBatch = %v, want context.Canceled", err)
+		}
+		if len(batches) != 0 || *queries != 0 {
+			t.Fatalf("got %d batches from %d queries with a cancelled context, want none", len(batches), *queries)
+		}
+	})
+}
+
+func TestEachBatchStopsOnError(t *testing.T) {
+	db, _ := newBatchRows(t, 7)
+	failed := errors.New("write failed")
+
+	batches, err := collect(context.Background(), db, 3, func(rows []batchRow) error {
+		if rows[0].ID == 4 {
+			return failed
+		}
+		return nil
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("eachBatch = %v, want the callback's error", err)
+	}
+	if len(batches) != 2 {
+		t.Fatalf("got %d batches, want 2", len(batches))
+	}
+}
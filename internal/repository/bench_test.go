@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Jason-Omondi/ecomgo/internal/loadgen"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Fixture size of the repository benchmarks
+const (
+	benchUsers    = 1000
+	benchProducts = 5000
+)
+
+// benchFixture is the seeded in-memory database the repository benchmarks share
+type benchFixture struct {
+	users    *UserRepository
+	products *ProductRepository
+	listings *ProductListingRepository
+	seeded   *loadgen.Seeded
+}
+
+var (
+	benchOnce sync.Once
+	bench     *benchFixture
+	benchErr  error
+)
+
+// newBenchFixture seeds the fixture on first use, so plain `go test` never pays for it
+func newBenchFixture(b *testing.B) *benchFixture {
+	b.Helper()
+	benchOnce.Do(func() {
+		bench, benchErr = seedBenchFixture(context.Background())
+	})
+	if benchErr != nil {
+		b.Fatal(benchErr)
+	}
+	return bench
+}
+
+func seedBenchFixture(ctx context.Context) (*benchFixture, error) {
+	db, err := gorm.Open(sqlite.Open("file:bench?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// One connection: every query sees the same in-memory database
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&models.User{}, &models.Address{}, &models.Product{}, &models.ProductListing{},
+		&models.Warehouse{}, &models.WarehouseRate{}, &models.WarehouseStock{}); err != nil {
+		return nil, err
+	}
+	// Repositories log misses at warn level; keep the output to the results
+	quiet := zap.NewNop()
+	seeded, err := loadgen.Seed(ctx, db, loadgen.Options{
+		Users:    benchUsers,
+		Products: benchProducts,
+		Password: "bench-password",
+		Currency: "USD",
+		Seed:     1,
+	}, quiet)
+	if err != nil {
+		return nil, err
+	}
+	listings := NewProductListingRepository(db, quiet)
+	if _, err := listings.Backfill(ctx); err != nil {
+		return nil, err
+	}
+
+	return &benchFixture{
+		users:    NewUserRepository(db, quiet),
+		products: NewProductRepository(db, quiet),
+		listings: listings,
+		seeded:   seeded,
+	}, nil
+}
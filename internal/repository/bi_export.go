@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Cursor is a position in a table ordered by a timestamp column and then ID
+type Cursor struct {
+	At time.Time
+	ID string
+}
+
+// BIExportRepository reads rows changed since a cursor for data warehouse exports, and keeps
+// each dataset's watermark
+type BIExportRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewBIExportRepository(db *gorm.DB, log *zap.Logger) *BIExportRepository {
+	return &BIExportRepository{db: db, log: log}
+}
+
+// Watermarks returns every exported dataset's watermark by dataset
+func (r *BIExportRepository) Watermarks(ctx context.Context) ([]models.BIExportWatermark, error) {
+	var marks []models.BIExportWatermark
+	err := r.db.WithContext(ctx).Order("dataset ASC").Find(&marks).Error
+	return marks, err
+}
+
+// Watermark returns dataset's watermark; a dataset never exported gets an empty one
+func (r *BIExportRepository) Watermark(ctx context.Context, dataset string) (*models.BIExportWatermark, error) {
+	var mark models.BIExportWatermark
+	err := r.db.WithContext(ctx).Where("dataset = ?", dataset).First(&mark).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.BIExportWatermark{Dataset: dataset}, nil
+	}
+	return &mark, err
+}
+
+// SaveWatermark stores mark, replacing the dataset's previous one
+func (r *BIExportRepository) SaveWatermark(ctx context.Context, mark *models.BIExportWatermark) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(mark).Error
+	if err != nil {
+		r.log.Error("Failed to save export watermark", zap.String("dataset", mark.Dataset), zap.Error(err))
+	}
+	return err
+}
+
+// ResetWatermark forgets dataset's watermark, so its next export is a full snapshot
+func (r *BIExportRepository) ResetWatermark(ctx context.Context, dataset string) error {
+	return r.db.WithContext(ctx).Where("dataset = ?", dataset).Delete(&models.BIExportWatermark{}).Error
+}
+
+// EachOrderChanged calls fn with batches of orders updated after cursor and at or before until
+func (r *BIExportRepository) EachOrderChanged(ctx context.Context, after Cursor, until time.Time, batchSize int, fn func([]models.Order) error) (Cursor, error) {
+	return eachSince(ctx, r, "updated_at", after, until, batchSize, fn, func(o models.Order) Cursor {
+		return Cursor{At: o.UpdatedAt, ID: o.ID}
+	})
+}
+
+// EachUserChanged calls fn with batches of users, deleted ones included, updated after cursor and at or before until
+func (r *BIExportRepository) EachUserChanged(ctx context.Context, after Cursor, until time.Time, batchSize int, fn func([]models.User) error) (Cursor, error) {
+	return eachSince(ctx, r, "updated_at", after, until, batchSize, fn, func(u models.User) Cursor {
+		return Cursor{At: u.UpdatedAt, ID: u.ID}
+	})
+}
+
+// EachUserDeleted calls fn with batches of users deleted after cursor and at or before until
+func (r *BIExportRepository) EachUserDeleted(ctx context.Context, after Cursor, until time.Time, batchSize int, fn func([]models.User) error) (Cursor, error) {
+	return eachSince(ctx, r, "deleted_at", after, until, batchSize, fn, func(u models.User) Cursor {
+		return Cursor{At: u.DeletedAt.Time, ID: u.ID}
+	})
+}
+
+// EachProductChanged calls fn with batches of products, deleted ones included, updated after cursor and at or before until
+func (r *BIExportRepository) EachProductChanged(ctx context.Context, after Cursor, until time.Time, batchSize int, fn func([]models.Product) error) (Cursor, error) {
+	return eachSince(ctx, r, "updated_at", after, until, batchSize, fn, func(p models.Product) Cursor {
+		return Cursor{At: p.UpdatedAt, ID: p.ID}
+	})
+}
+
+// EachProductDeleted calls fn with batches of products deleted after cursor and at or before until
+func (r *BIExportRepository) EachProductDeleted(ctx context.Context, after Cursor, until time.Time, batchSize int, fn func([]models.Product) error) (Cursor, error) {
+	return eachSince(ctx, r, "deleted_at", after, until, batchSize, fn, func(p models.Product) Cursor {
+		return Cursor{At: p.DeletedAt.Time, ID: p.ID}
+	})
+}
+
+// eachSince pages through the rows of T with column in (after, until], ordered by column and ID
+// Why keyset paging: FindInBatches pages by primary key only, and rows must come in column order
+// for the last one to be a watermark. Soft-deleted rows are included.
+// Returns: the cursor of the last row passed to fn, which is where the next export resumes
+func eachSince[T any](ctx context.Context, r *BIExportRepository, column string, after Cursor, until time.Time,
+	batchSize int, fn func([]T) error, cursor func(T) Cursor) (Cursor, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return after, err
+		}
+		query := r.db.WithContext(ctx).Unscoped().Where(column+" <= ?", until)
+		if !after.At.IsZero() {
+			query = query.Where("("+column+" > ? OR ("+column+" = ? AND id > ?))", after.At, after.At, after.ID)
+		}
+		var rows []T
+		if err := query.Order(column + " ASC, id ASC").Limit(batchSize).Find(&rows).Error; err != nil {
+			if ctx.Err() == nil {
+				r.log.Error("Failed to read changed rows", zap.String("column", column), zap.Error(err))
+			}
+			return after, err
+		}
+		if len(rows) == 0 {
+			return after, nil
+		}
+		if err := fn(rows); err != nil {
+			return after, err
+		}
+		after = cursor(rows[len(rows)-1])
+		if len(rows) < batchSize {
+			return after, nil
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrCampaignNotFound is returned when a campaign doesn't exist
+var ErrCampaignNotFound = errors.New("campaign not found")
+
+type CampaignRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewCampaignRepository(db *gorm.DB, log *zap.Logger) *CampaignRepository {
+	return &CampaignRepository{db: db, log: log}
+}
+
+// Create inserts a campaign with its items
+func (r *CampaignRepository) Create(ctx context.Context, campaign *models.Campaign) error {
+	if err := r.db.WithContext(ctx).Create(campaign).Error; err != nil {
+		r.log.Error("Failed to create campaign", zap.String("name", campaign.Name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetByID loads a campaign with its items
+func (r *CampaignRepository) GetByID(ctx context.Context, id string) (*models.Campaign, error) {
+	campaign := &models.Campaign{}
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("product_id ASC") }).
+		Where("id = ?", id).First(campaign).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCampaignNotFound
+	}
+	return campaign, err
+}
+
+// Replace saves a campaign's fields and swaps its items for campaign.Items atomically
+func (r *CampaignRepository) Replace(ctx context.Context, campaign *models.Campaign) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(campaign).Select("name", "segment_id", "starts_at", "ends_at", "updated_at").Updates(campaign)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCampaignNotFound
+		}
+		if err := tx.Where("campaign_id = ?", campaign.ID).Delete(&models.CampaignItem{}).Error; err != nil {
+			return err
+		}
+		for i := range campaign.Items {
+			campaign.Items[i].CampaignID = campaign.ID
+		}
+		return tx.Create(&campaign.Items).Error
+	})
+	if err != nil && !errors.Is(err, ErrCampaignNotFound) {
+		r.log.Error("Failed to update campaign", zap.String("id", campaign.ID), zap.Error(err))
+	}
+	return err
+}
+
+// Delete removes a campaign and its items
+func (r *CampaignRepository) Delete(ctx context.Context, id string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("campaign_id = ?", id).Delete(&models.CampaignItem{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&models.Campaign{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCampaignNotFound
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrCampaignNotFound) {
+		r.log.Error("Failed to delete campaign", zap.String("id", id), zap.Error(err))
+	}
+	return err
+}
+
+// List returns a page of campaigns with their items, latest start first
+// status filters by the state at now (models.CampaignScheduled, CampaignActive, CampaignEnded); empty means all
+// Returns: page, total matching rows
+func (r *CampaignRepository) List(ctx context.Context, status string, now time.Time,
+	limit, offset int) ([]models.Campaign, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Campaign{})
+	switch status {
+	case models.CampaignScheduled:
+		query = query.Where("starts_at > ?", now)
+	case models.CampaignActive:
+		query = query.Where("starts_at <= ? AND ends_at > ?", now, now)
+	case models.CampaignEnded:
+		query = query.Where("ends_at <= ?", now)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var campaigns []models.Campaign
+	err := query.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("product_id ASC") }).
+		Order("starts_at DESC").Limit(limit).Offset(offset).Find(&campaigns).Error
+	if err != nil {
+		r.log.Error("Failed to list campaigns", zap.Error(err))
+		return nil, 0, err
+	}
+	return campaigns, total, nil
+}
+
+// Schedule returns every campaign price that hasn't ended by now, earliest start first
+// One join over the campaigns still to come; this is what the price cache holds
+func (r *CampaignRepository) Schedule(ctx context.Context, now time.Time) ([]models.CampaignPrice, error) {
+	var prices []models.CampaignPrice
+	err := r.db.WithContext(ctx).Table("campaign_items").
+		Select("campaign_items.product_id, campaigns.id AS campaign_id, campaigns.name AS campaign, "+
+			"campaigns.segment_id, campaign_items.price, campaigns.starts_at, campaigns.ends_at").
+		Joins("JOIN campaigns ON campaigns.id = campaign_items.campaign_id").
+		Where("campaigns.ends_at > ?", now).
+		Order("campaigns.starts_at ASC").
+		Scan(&prices).Error
+	return prices, err
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrChannelNotFound is returned when a sales channel doesn't exist
+	ErrChannelNotFound = errors.New("channel not found")
+	// ErrChannelProductNotFound is returned when a product has no override on the channel
+	ErrChannelProductNotFound = errors.New("channel product override not found")
+)
+
+type ChannelRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewChannelRepository(db *gorm.DB, log *zap.Logger) *ChannelRepository {
+	return &ChannelRepository{db: db, log: log}
+}
+
+// Create inserts a channel; an existing code is left as it is
+// Returns: false when the code is already taken
+func (r *ChannelRepository) Create(ctx context.Context, channel *models.Channel) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(channel)
+	if result.Error != nil {
+		r.log.Error("Failed to create channel", zap.String("code", channel.Code), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *ChannelRepository) Update(ctx context.Context, channel *models.Channel) error {
+	return r.db.WithContext(ctx).Save(channel).Error
+}
+
+func (r *ChannelRepository) Get(ctx context.Context, code string) (*models.Channel, error) {
+	var channel models.Channel
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&channel).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrChannelNotFound
+	}
+	return &channel, err
+}
+
+// List returns every channel ordered by code
+func (r *ChannelRepository) List(ctx context.Context) ([]models.Channel, error) {
+	var channels []models.Channel
+	err := r.db.WithContext(ctx).Order("code ASC").Find(&channels).Error
+	return channels, err
+}
+
+// Products returns the product overrides of a channel
+func (r *ChannelRepository) Products(ctx context.Context, code string) ([]models.ChannelProduct, error) {
+	var overrides []models.ChannelProduct
+	err := r.db.WithContext(ctx).Where("channel_code = ?", code).Order("product_id ASC").Find(&overrides).Error
+	return overrides, err
+}
+
+// SetProduct inserts or replaces a product's override on a channel
+func (r *ChannelRepository) SetProduct(ctx context.Context, override *models.ChannelProduct) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "channel_code"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"hidden", "price", "updated_at"}),
+	}).Create(override).Error
+	if err != nil {
+		r.log.Error("Failed to set channel product", zap.String("channel", override.ChannelCode),
+			zap.String("product_id", override.ProductID), zap.Error(err))
+	}
+	return err
+}
+
+// DeleteProduct removes a product's override on a channel
+func (r *ChannelRepository) DeleteProduct(ctx context.Context, code, productID string) error {
+	result := r.db.WithContext(ctx).Where("channel_code = ? AND product_id = ?", code, productID).Delete(&models.ChannelProduct{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrChannelProductNotFound
+	}
+	return nil
+}
+
+// DeleteProductEverywhere removes a deleted product's overrides on every channel
+func (r *ChannelRepository) DeleteProductEverywhere(ctx context.Context, productID string) (int64, error) {
+	result := r.db.WithContext(ctx).Where("product_id = ?", productID).Delete(&models.ChannelProduct{})
+	return result.RowsAffected, result.Error
+}
+
+// RecordOrder stores an order's channel once; redelivered events change nothing
+func (r *ChannelRepository) RecordOrder(ctx context.Context, order *models.ChannelOrder) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(order).Error
+	if err != nil {
+		r.log.Error("Failed to record channel order", zap.String("order_id", order.OrderID), zap.Error(err))
+	}
+	return err
+}
+
+// Sales totals orders placed in [from, to) per channel and currency, optionally on one channel
+func (r *ChannelRepository) Sales(ctx context.Context, code string, from, to time.Time) ([]models.ChannelSales, error) {
+	query := r.db.WithContext(ctx).Model(&models.ChannelOrder{}).
+		Select("channel_code AS channel, currency, COUNT(*) AS orders, COALESCE(SUM(total), 0) AS revenue").
+		Where("placed_at >= ? AND placed_at < ?", from, to)
+	if code != "" {
+		query = query.Where("channel_code = ?", code)
+	}
+
+	var sales []models.ChannelSales
+	err := query.Group("channel_code, currency").Order("channel_code ASC, currency ASC").Scan(&sales).Error
+	if err != nil {
+		r.log.Error("Failed to total channel sales", zap.Error(err))
+	}
+	return sales, err
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// cohortBatch bounds the summary rows written per statement when the report is replaced
+const cohortBatch = 500
+
+// CohortRepository reads the customers and order ledger the cohort report is built from,
+// and keeps the precomputed report
+type CohortRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewCohortRepository(db *gorm.DB, log *zap.Logger) *CohortRepository {
+	return &CohortRepository{db: db, log: log}
+}
+
+// EachCustomer calls fn with batches of up to batchSize customers (ID and signup time only), ordered by ID
+func (r *CohortRepository) EachCustomer(ctx context.Context, batchSize int, fn func([]models.User) error) error {
+	err := eachBatch(ctx, r.db.Select("id", "created_at").Where("role = ?", models.RoleCustomer), batchSize, fn)
+	if err != nil && ctx.Err() == nil {
+		r.log.Error("Failed to iterate customers", zap.Error(err))
+	}
+	return err
+}
+
+// EachOrder calls fn with batches of up to batchSize rows of the customer order ledger, ordered by order ID
+func (r *CohortRepository) EachOrder(ctx context.Context, batchSize int, fn func([]models.CustomerOrder) error) error {
+	err := eachBatch(ctx, r.db, batchSize, fn)
+	if err != nil && ctx.Err() == nil {
+		r.log.Error("Failed to iterate customer orders", zap.Error(err))
+	}
+	return err
+}
+
+// Replace swaps the stored report for summaries and months in one transaction, so readers
+// see either the previous rebuild or this one
+func (r *CohortRepository) Replace(ctx context.Context, summaries []models.CohortSummary, months []models.CohortMonth) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		all := tx.Session(&gorm.Session{AllowGlobalUpdate: true})
+		if err := all.Delete(&models.CohortMonth{}).Error; err != nil {
+			return err
+		}
+		if err := all.Delete(&models.CohortSummary{}).Error; err != nil {
+			return err
+		}
+		if len(summaries) > 0 {
+			if err := tx.Omit("Months").CreateInBatches(summaries, cohortBatch).Error; err != nil {
+				return err
+			}
+		}
+		if len(months) > 0 {
+			return tx.CreateInBatches(months, cohortBatch).Error
+		}
+		return nil
+	})
+	if err != nil {
+		r.log.Error("Failed to store cohort report", zap.Error(err))
+	}
+	return err
+}
+
+// List returns the cohorts from through to (YYYY-MM, inclusive), oldest first, with their months
+func (r *CohortRepository) List(ctx context.Context, from, to string) ([]models.CohortSummary, error) {
+	var summaries []models.CohortSummary
+	err := r.db.WithContext(ctx).Where("cohort >= ? AND cohort <= ?", from, to).
+		Order("cohort ASC").Find(&summaries).Error
+	if err != nil || len(summaries) == 0 {
+		return summaries, err
+	}
+
+	var months []models.CohortMonth
+	err = r.db.WithContext(ctx).Where("cohort >= ? AND cohort <= ?", from, to).
+		Order("cohort ASC, month ASC").Find(&months).Error
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(summaries))
+	for i := range summaries {
+		index[summaries[i].Cohort] = i
+		summaries[i].Months = []models.CohortMonth{}
+	}
+	for _, month := range months {
+		if i, ok := index[month.Cohort]; ok {
+			summaries[i].Months = append(summaries[i].Months, month)
+		}
+	}
+	return summaries, nil
+}
+
+// Latest returns the newest cohort summary, to tell when the report was last rebuilt
+// Returns: nil when the report has never been built
+func (r *CohortRepository) Latest(ctx context.Context) (*models.CohortSummary, error) {
+	var summaries []models.CohortSummary
+	if err := r.db.WithContext(ctx).Order("cohort DESC").Limit(1).Find(&summaries).Error; err != nil {
+		return nil, err
+	}
+	if len(summaries) == 0 {
+		return nil, nil
+	}
+	return &summaries[0], nil
+}
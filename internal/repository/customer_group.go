@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrCustomerGroupNotFound is returned when a customer group doesn't exist
+	ErrCustomerGroupNotFound = errors.New("customer group not found")
+	// ErrCustomerGroupPriceNotFound is returned when a product has no price for the group
+	ErrCustomerGroupPriceNotFound = errors.New("customer group price not found")
+)
+
+type CustomerGroupRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewCustomerGroupRepository(db *gorm.DB, log *zap.Logger) *CustomerGroupRepository {
+	return &CustomerGroupRepository{db: db, log: log}
+}
+
+// Create inserts a group; an existing code is left as it is
+// Returns: false when the code is already taken
+func (r *CustomerGroupRepository) Create(ctx context.Context, group *models.CustomerGroup) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(group)
+	if result.Error != nil {
+		r.log.Error("Failed to create customer group", zap.String("code", group.Code), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *CustomerGroupRepository) Update(ctx context.Context, group *models.CustomerGroup) error {
+	return r.db.WithContext(ctx).Save(group).Error
+}
+
+func (r *CustomerGroupRepository) Get(ctx context.Context, code string) (*models.CustomerGroup, error) {
+	var group models.CustomerGroup
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&group).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCustomerGroupNotFound
+	}
+	return &group, err
+}
+
+// List returns every group ordered by code
+func (r *CustomerGroupRepository) List(ctx context.Context) ([]models.CustomerGroup, error) {
+	var groups []models.CustomerGroup
+	err := r.db.WithContext(ctx).Order("code ASC").Find(&groups).Error
+	return groups, err
+}
+
+// Prices returns the price list of a group
+func (r *CustomerGroupRepository) Prices(ctx context.Context, code string) ([]models.CustomerGroupPrice, error) {
+	var prices []models.CustomerGroupPrice
+	err := r.db.WithContext(ctx).Where("group_code = ?", code).Order("product_id ASC").Find(&prices).Error
+	return prices, err
+}
+
+// SetPrice inserts or replaces a product's price for a group
+func (r *CustomerGroupRepository) SetPrice(ctx context.Context, price *models.CustomerGroupPrice) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "group_code"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price", "updated_at"}),
+	}).Create(price).Error
+	if err != nil {
+		r.log.Error("Failed to set customer group price", zap.String("group", price.GroupCode),
+			zap.String("product_id", price.ProductID), zap.Error(err))
+	}
+	return err
+}
+
+// DeletePrice removes a product from a group's price list
+func (r *CustomerGroupRepository) DeletePrice(ctx context.Context, code, productID string) error {
+	result := r.db.WithContext(ctx).Where("group_code = ? AND product_id = ?", code, productID).Delete(&models.CustomerGroupPrice{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCustomerGroupPriceNotFound
+	}
+	return nil
+}
+
+// DeleteProductEverywhere removes a deleted product from every group's price list
+// Returns: the groups whose price list changed
+func (r *CustomerGroupRepository) DeleteProductEverywhere(ctx context.Context, productID string) ([]string, error) {
+	var codes []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.CustomerGroupPrice{}).Where("product_id = ?", productID).Pluck("group_code", &codes).Error; err != nil {
+			return err
+		}
+		return tx.Where("product_id = ?", productID).Delete(&models.CustomerGroupPrice{}).Error
+	})
+	return codes, err
+}
+
+// GroupOf returns the code of the group userID is in
+// Returns: "" for customers without a group
+func (r *CustomerGroupRepository) GroupOf(ctx context.Context, userID string) (string, error) {
+	var codes []string
+	err := r.db.WithContext(ctx).Model(&models.CustomerGroupMember{}).Where("user_id = ?", userID).Limit(1).Pluck("group_code", &codes).Error
+	if err != nil || len(codes) == 0 {
+		return "", err
+	}
+	return codes[0], nil
+}
+
+// Assign puts a customer in a group, replacing their previous one
+func (r *CustomerGroupRepository) Assign(ctx context.Context, member *models.CustomerGroupMember) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"group_code", "assigned_by", "updated_at"}),
+	}).Create(member).Error
+	if err != nil {
+		r.log.Error("Failed to assign customer group", zap.String("user_id", member.UserID), zap.Error(err))
+	}
+	return err
+}
+
+// Unassign takes a customer out of their group
+func (r *CustomerGroupRepository) Unassign(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.CustomerGroupMember{}).Error
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dashboardDay is how the read models key days
+const dashboardDay = "2006-01-02"
+
+// ProjectedOrder is what the projections take from order.placed
+type ProjectedOrder struct {
+	UserID   string
+	Currency string
+	Total    int64
+	Items    []models.OrderLine
+	PlacedAt time.Time
+}
+
+// ProjectedRefund is what the projections take from refund.issued
+type ProjectedRefund struct {
+	UserID   string
+	Currency string
+	Amount   int64
+	Items    []models.OrderLine // only product and quantity are used
+	IssuedAt time.Time
+}
+
+// DashboardRepository keeps the dashboard read models
+// Every Project method applies one event in a transaction that first claims the event ID in
+// dashboard_projected_events, so a redelivered event changes nothing
+type DashboardRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewDashboardRepository(db *gorm.DB, log *zap.Logger) *DashboardRepository {
+	return &DashboardRepository{db: db, log: log}
+}
+
+// project runs apply in a transaction once per event ID
+// Returns: false when the event was already applied
+func (r *DashboardRepository) project(ctx context.Context, eventID string, apply func(tx *gorm.DB) error) (bool, error) {
+	applied := false
+	err := withRetry(ctx, func() error {
+		applied = false
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			claim := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ProjectedEvent{
+				EventID:     eventID,
+				ProjectedAt: time.Now().UTC(),
+			})
+			if claim.Error != nil || claim.RowsAffected == 0 {
+				return claim.Error
+			}
+			applied = true
+			return apply(tx)
+		})
+	})
+	if err != nil {
+		r.log.Error("Failed to project event", zap.String("event_id", eventID), zap.Error(err))
+	}
+	return applied, err
+}
+
+// increment adds columns to row's counters, inserting row (keyed by keys) with them when it's new
+func increment(tx *gorm.DB, row interface{}, keys []string, columns map[string]int64) error {
+	updates := make(map[string]interface{}, len(columns))
+	for column, delta := range columns {
+		updates[column] = gorm.Expr(column+" + ?", delta)
+	}
+	conflict := clause.OnConflict{DoUpdates: clause.Assignments(updates)}
+	for _, key := range keys {
+		conflict.Columns = append(conflict.Columns, clause.Column{Name: key})
+	}
+	return tx.Clauses(conflict).Create(row).Error
+}
+
+// ProjectSignup counts a new account on the day it registered
+func (r *DashboardRepository) ProjectSignup(ctx context.Context, eventID string, at time.Time) (bool, error) {
+	return r.project(ctx, eventID, func(tx *gorm.DB) error {
+		day := at.UTC().Format(dashboardDay)
+		return increment(tx, &models.DailyCustomers{Day: day, Signups: 1}, []string{"day"}, map[string]int64{"signups": 1})
+	})
+}
+
+// ProjectOrder adds a placed order to the day's sales, its products' sales and its customer's stats
+func (r *DashboardRepository) ProjectOrder(ctx context.Context, eventID string, order ProjectedOrder) (bool, error) {
+	return r.project(ctx, eventID, func(tx *gorm.DB) error {
+		day := order.PlacedAt.UTC().Format(dashboardDay)
+		var units int64
+		for _, item := range order.Items {
+			units += int64(item.Quantity)
+		}
+
+		sales := &models.DailySales{Day: day, Currency: order.Currency, Orders: 1, Units: units, Gross: order.Total}
+		err := increment(tx, sales, []string{"day", "currency"}, map[string]int64{"orders": 1, "units": units, "gross": order.Total})
+		if err != nil {
+			return err
+		}
+
+		// One row per product, so an order listing a product twice counts as one order of it
+		lines := make(map[string]*models.DailyProductSales)
+		for _, item := range order.Items {
+			line, ok := lines[item.ProductID]
+			if !ok {
+				line = &models.DailyProductSales{Day: day, ProductID: item.ProductID, Currency: order.Currency, Orders: 1}
+				lines[item.ProductID] = line
+			}
+			line.Units += int64(item.Quantity)
+			line.Revenue += int64(item.Quantity) * item.UnitPrice
+		}
+		for _, line := range lines {
+			err := increment(tx, line, []string{"day", "product_id", "currency"},
+				map[string]int64{"orders": 1, "units": line.Units, "revenue": line.Revenue})
+			if err != nil {
+				return err
+			}
+		}
+
+		return r.projectCustomerOrder(tx, day, order)
+	})
+}
+
+// projectCustomerOrder counts the order against its customer and as a first or repeat order of the day
+// A concurrent first order of the same customer fails on the key and is retried as a repeat
+func (r *DashboardRepository) projectCustomerOrder(tx *gorm.DB, day string, order ProjectedOrder) error {
+	if order.UserID == "" {
+		return nil
+	}
+	placedAt := order.PlacedAt.UTC()
+	var stats []models.CustomerStats
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", order.UserID).Limit(1).Find(&stats).Error
+	if err != nil {
+		return err
+	}
+
+	counter := "repeat_orders"
+	if len(stats) == 0 {
+		counter = "first_orders"
+		err = tx.Create(&models.CustomerStats{UserID: order.UserID, Orders: 1, FirstOrderAt: placedAt, LastOrderAt: placedAt}).Error
+	} else {
+		existing := stats[0]
+		err = tx.Model(&existing).Updates(map[string]interface{}{
+			"orders":         gorm.Expr("orders + 1"),
+			"first_order_at": minTime(existing.FirstOrderAt, placedAt),
+			"last_order_at":  maxTime(existing.LastOrderAt, placedAt),
+		}).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	daily := &models.DailyCustomers{Day: day}
+	if counter == "first_orders" {
+		daily.FirstOrders = 1
+	} else {
+		daily.RepeatOrders = 1
+	}
+	if err := increment(tx, daily, []string{"day"}, map[string]int64{counter: 1}); err != nil {
+		return err
+	}
+	spend := &models.CustomerSpend{UserID: order.UserID, Currency: order.Currency, Orders: 1, Spent: order.Total}
+	return increment(tx, spend, []string{"user_id", "currency"}, map[string]int64{"orders": 1, "spent": order.Total})
+}
+
+// ProjectRefund adds a refund to the day's refunds, its listed products' refunded units and its
+// customer's refunded spend
+func (r *DashboardRepository) ProjectRefund(ctx context.Context, eventID string, refund ProjectedRefund) (bool, error) {
+	return r.project(ctx, eventID, func(tx *gorm.DB) error {
+		day := refund.IssuedAt.UTC().Format(dashboardDay)
+		sales := &models.DailySales{Day: day, Currency: refund.Currency, Refunds: 1, Refunded: refund.Amount}
+		err := increment(tx, sales, []string{"day", "currency"}, map[string]int64{"refunds": 1, "refunded": refund.Amount})
+		if err != nil {
+			return err
+		}
+		for _, item := range refund.Items {
+			line := &models.DailyProductSales{Day: day, ProductID: item.ProductID, Currency: refund.Currency,
+				RefundedUnits: int64(item.Quantity)}
+			err := increment(tx, line, []string{"day", "product_id", "currency"}, map[string]int64{"refunded_units": int64(item.Quantity)})
+			if err != nil {
+				return err
+			}
+		}
+		if refund.UserID == "" {
+			return nil
+		}
+		spend := &models.CustomerSpend{UserID: refund.UserID, Currency: refund.Currency, Refunded: refund.Amount}
+		return increment(tx, spend, []string{"user_id", "currency"}, map[string]int64{"refunded": refund.Amount})
+	})
+}
+
+// PruneProjected forgets the events applied before cutoff
+// Returns: rows deleted
+func (r *DashboardRepository) PruneProjected(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("projected_at < ?", cutoff).Delete(&models.ProjectedEvent{})
+	if result.Error != nil {
+		r.log.Error("Failed to prune projected events", zap.Error(result.Error))
+	}
+	return result.RowsAffected, result.Error
+}
+
+// Sales returns the days from through to (YYYY-MM-DD, inclusive) that had orders or refunds, oldest first
+func (r *DashboardRepository) Sales(ctx context.Context, from, to string) ([]models.DailySales, error) {
+	var days []models.DailySales
+	err := r.db.WithContext(ctx).Where("day >= ? AND day <= ?", from, to).
+		Order("day ASC, currency ASC").Find(&days).Error
+	return days, err
+}
+
+// TopProducts returns up to limit products sold in currency from through to, by units or revenue
+func (r *DashboardRepository) TopProducts(ctx context.Context, from, to, currency string, byRevenue bool, limit int) ([]models.TopProduct, error) {
+	order := "units DESC, revenue DESC, product_id ASC"
+	if byRevenue {
+		order = "revenue DESC, units DESC, product_id ASC"
+	}
+	var products []models.TopProduct
+	err := r.db.WithContext(ctx).Model(&models.DailyProductSales{}).
+		Select("product_id, SUM(orders) AS orders, SUM(units) AS units, SUM(revenue) AS revenue, SUM(refunded_units) AS refunded_units").
+		Where("day >= ? AND day <= ? AND currency = ?", from, to, currency).
+		Group("product_id").Having("SUM(units) > 0").Order(order).Limit(limit).Scan(&products).Error
+	return products, err
+}
+
+// CustomerDays returns the days from through to that had signups or orders, oldest first
+func (r *DashboardRepository) CustomerDays(ctx context.Context, from, to string) ([]models.DailyCustomers, error) {
+	var days []models.DailyCustomers
+	err := r.db.WithContext(ctx).Where("day >= ? AND day <= ?", from, to).Order("day ASC").Find(&days).Error
+	return days, err
+}
+
+// CustomerCounts returns how many customers have ordered, and how many more than once
+func (r *DashboardRepository) CustomerCounts(ctx context.Context) (customers, repeat int64, err error) {
+	var counts struct {
+		Customers       int64
+		RepeatCustomers int64
+	}
+	err = r.db.WithContext(ctx).Model(&models.CustomerStats{}).
+		Select("COUNT(*) AS customers, COALESCE(SUM(CASE WHEN orders > 1 THEN 1 ELSE 0 END), 0) AS repeat_customers").
+		Scan(&counts).Error
+	return counts.Customers, counts.RepeatCustomers, err
+}
+
+// TopCustomers returns up to limit customers by net spend in currency
+func (r *DashboardRepository) TopCustomers(ctx context.Context, currency string, limit int) ([]models.TopCustomer, error) {
+	var customers []models.TopCustomer
+	err := r.db.WithContext(ctx).Model(&models.CustomerSpend{}).
+		Select("user_id, orders, spent, refunded").
+		Where("currency = ? AND orders > 0", currency).
+		Order("spent - refunded DESC, user_id ASC").Limit(limit).Scan(&customers).Error
+	return customers, err
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
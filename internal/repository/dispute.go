@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrDisputeNotFound is returned when a dispute isn't on record
+var ErrDisputeNotFound = errors.New("dispute not found")
+
+// openDisputeStates are the states a dispute can still change from; a decided dispute never reopens
+var openDisputeStates = []string{models.DisputeNeedsResponse, models.DisputeUnderReview}
+
+// DisputeFilter narrows a dispute listing; empty fields match everything
+// Status "open" matches needs_response and under_review
+type DisputeFilter struct {
+	Status  string
+	OrderID string
+}
+
+// DisputeRepository keeps chargebacks reported by the payment provider
+// Every change after creation is conditional on the dispute's state, so webhooks delivered late
+// or twice can't undo a decision or a recorded evidence submission
+type DisputeRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewDisputeRepository(db *gorm.DB, log *zap.Logger) *DisputeRepository {
+	return &DisputeRepository{db: db, log: log}
+}
+
+// Create stores a new dispute
+// Returns: ErrAlreadyExists when the provider's dispute is already on record
+func (r *DisputeRepository) Create(ctx context.Context, dispute *models.Dispute) error {
+	err := r.db.WithContext(ctx).Create(dispute).Error
+	if err != nil && !errors.Is(err, ErrAlreadyExists) {
+		r.log.Error("Failed to create dispute", zap.String("provider_dispute_id", dispute.ProviderDisputeID), zap.Error(err))
+	}
+	return err
+}
+
+func (r *DisputeRepository) Get(ctx context.Context, id string) (*models.Dispute, error) {
+	var dispute models.Dispute
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&dispute).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDisputeNotFound
+	}
+	return &dispute, err
+}
+
+// GetByProviderRef returns the dispute a provider knows as ref
+func (r *DisputeRepository) GetByProviderRef(ctx context.Context, provider, ref string) (*models.Dispute, error) {
+	var dispute models.Dispute
+	err := r.db.WithContext(ctx).Where("provider = ? AND provider_dispute_id = ?", provider, ref).First(&dispute).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDisputeNotFound
+	}
+	return &dispute, err
+}
+
+// List returns a page of disputes, nearest evidence deadline first and those without one last
+// Returns: page, total matching rows
+func (r *DisputeRepository) List(ctx context.Context, filter DisputeFilter, limit, offset int) ([]models.Dispute, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Dispute{})
+	switch filter.Status {
+	case "":
+	case "open":
+		query = query.Where("status IN ?", openDisputeStates)
+	default:
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.OrderID != "" {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var disputes []models.Dispute
+	err := query.Order("evidence_due_by IS NULL, evidence_due_by ASC, opened_at DESC, id ASC").
+		Limit(limit).Offset(offset).Find(&disputes).Error
+	return disputes, total, err
+}
+
+// Revise stores a provider update to an open dispute's amount, reason and evidence deadline
+// Returns: whether it changed (false once the dispute is decided)
+func (r *DisputeRepository) Revise(ctx context.Context, dispute *models.Dispute) (bool, error) {
+	result := r.db.WithContext(ctx).Model(dispute).Where("status IN ?", openDisputeStates).
+		Select("amount", "reason", "evidence_due_by", "reminded_at", "updated_at").Updates(dispute)
+	if result.Error != nil {
+		r.log.Error("Failed to update dispute", zap.String("id", dispute.ID), zap.Error(result.Error))
+	}
+	return result.RowsAffected > 0, result.Error
+}
+
+// Close records the decision on an open dispute
+// Returns: whether it closed (false when it was already decided)
+func (r *DisputeRepository) Close(ctx context.Context, id, status string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Dispute{}).
+		Where("id = ? AND status IN ?", id, openDisputeStates).
+		Updates(map[string]any{"status": status, "closed_at": at})
+	if result.Error != nil {
+		r.log.Error("Failed to close dispute", zap.String("id", id), zap.Error(result.Error))
+	}
+	return result.RowsAffected > 0, result.Error
+}
+
+// SubmitEvidence moves a dispute that needs a response to under_review
+// Returns: whether it moved (false when evidence was already submitted or it is decided)
+func (r *DisputeRepository) SubmitEvidence(ctx context.Context, id, userID, note string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Dispute{}).
+		Where("id = ? AND status = ?", id, models.DisputeNeedsResponse).
+		Updates(map[string]any{
+			"status":                models.DisputeUnderReview,
+			"evidence_submitted_at": at,
+			"evidence_submitted_by": userID,
+			"evidence_note":         note,
+		})
+	if result.Error != nil {
+		r.log.Error("Failed to record dispute evidence", zap.String("id", id), zap.Error(result.Error))
+	}
+	return result.RowsAffected > 0, result.Error
+}
+
+// DueForReminder returns the disputes still needing a response whose deadline is before the
+// given time and that haven't been reminded about yet, nearest deadline first
+func (r *DisputeRepository) DueForReminder(ctx context.Context, before time.Time) ([]models.Dispute, error) {
+	var disputes []models.Dispute
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND evidence_due_by IS NOT NULL AND evidence_due_by <= ? AND reminded_at IS NULL",
+			models.DisputeNeedsResponse, before).
+		Order("evidence_due_by ASC, id ASC").Find(&disputes).Error
+	return disputes, err
+}
+
+func (r *DisputeRepository) MarkReminded(ctx context.Context, ids []string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.Dispute{}).Where("id IN ?", ids).Update("reminded_at", at).Error
+}
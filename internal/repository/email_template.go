@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrEmailTemplateNotFound is returned when a template version doesn't exist
+var ErrEmailTemplateNotFound = errors.New("email template not found")
+
+type EmailTemplateRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewEmailTemplateRepository(db *gorm.DB, log *zap.Logger) *EmailTemplateRepository {
+	return &EmailTemplateRepository{db: db, log: log}
+}
+
+// ListActive returns the active version of every edited template
+func (r *EmailTemplateRepository) ListActive(ctx context.Context) ([]models.EmailTemplate, error) {
+	var templates []models.EmailTemplate
+	if err := r.db.WithContext(ctx).Where("active = ?", true).Find(&templates).Error; err != nil {
+		r.log.Error("Failed to list active email templates", zap.Error(err))
+		return nil, err
+	}
+	return templates, nil
+}
+
+// LatestVersions returns the highest version of every edited name and locale
+func (r *EmailTemplateRepository) LatestVersions(ctx context.Context) ([]models.EmailTemplateSummary, error) {
+	var summaries []models.EmailTemplateSummary
+	err := r.db.WithContext(ctx).Model(&models.EmailTemplate{}).Select("name, locale, MAX(version) AS latest_version").
+		Group("name, locale").Scan(&summaries).Error
+	return summaries, err
+}
+
+// ListVersions returns every version of a template, newest first
+func (r *EmailTemplateRepository) ListVersions(ctx context.Context, name, locale string) ([]models.EmailTemplate, error) {
+	var templates []models.EmailTemplate
+	err := r.db.WithContext(ctx).Where("name = ? AND locale = ?", name, locale).Order("version DESC").Find(&templates).Error
+	if err != nil {
+		r.log.Error("Failed to list email template versions", zap.String("name", name), zap.String("locale", locale), zap.Error(err))
+		return nil, err
+	}
+	return templates, nil
+}
+
+// GetActive returns the version in use for name and locale
+// Returns: ErrEmailTemplateNotFound when the built-in template is in use
+func (r *EmailTemplateRepository) GetActive(ctx context.Context, name, locale string) (*models.EmailTemplate, error) {
+	var template models.EmailTemplate
+	err := r.db.WithContext(ctx).Where("name = ? AND locale = ? AND active = ?", name, locale, true).First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrEmailTemplateNotFound
+	}
+	return &template, err
+}
+
+func (r *EmailTemplateRepository) GetVersion(ctx context.Context, name, locale string, version int) (*models.EmailTemplate, error) {
+	var template models.EmailTemplate
+	err := r.db.WithContext(ctx).Where("name = ? AND locale = ? AND version = ?", name, locale, version).First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrEmailTemplateNotFound
+	}
+	return &template, err
+}
+
+// Create saves template as the next version of its name and locale, and makes it the active
+// one when it is marked Active
+// Returns: ErrAlreadyExists when another save took the same version number first
+func (r *EmailTemplateRepository) Create(ctx context.Context, template *models.EmailTemplate) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest int
+		err := tx.Model(&models.EmailTemplate{}).Where("name = ? AND locale = ?", template.Name, template.Locale).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+		template.Version = latest + 1
+
+		if template.Active {
+			if err := deactivateEmailTemplate(tx, template.Name, template.Locale); err != nil {
+				return err
+			}
+		}
+		return tx.Create(template).Error
+	})
+	if err != nil {
+		r.log.Error("Failed to save email template", zap.String("name", template.Name), zap.String("locale", template.Locale), zap.Error(err))
+	}
+	return err
+}
+
+// Activate makes version the one in use for its name and locale
+func (r *EmailTemplateRepository) Activate(ctx context.Context, name, locale string, version int) (*models.EmailTemplate, error) {
+	var template models.EmailTemplate
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("name = ? AND locale = ? AND version = ?", name, locale, version).First(&template).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrEmailTemplateNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := deactivateEmailTemplate(tx, name, locale); err != nil {
+			return err
+		}
+		template.Active = true
+		return tx.Model(&template).Update("active", true).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// Deactivate puts the built-in template back in use for name and locale; versions are kept
+// Returns: false when no version was active
+func (r *EmailTemplateRepository) Deactivate(ctx context.Context, name, locale string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.EmailTemplate{}).
+		Where("name = ? AND locale = ? AND active = ?", name, locale, true).Update("active", false)
+	return result.RowsAffected > 0, result.Error
+}
+
+func deactivateEmailTemplate(tx *gorm.DB, name, locale string) error {
+	return tx.Model(&models.EmailTemplate{}).Where("name = ? AND locale = ? AND active = ?", name, locale, true).
+		Update("active", false).Error
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// ErrAlreadyExists is returned when a write violates a unique index (duplicate email, SKU, slug...)
+// Services check for a taken value first to give a precise error; this catches the writes that
+// race past that check, so the loser gets a conflict instead of an internal error
+var ErrAlreadyExists = errors.New("already exists")
+
+// RegisterErrorTranslation makes every create, update and raw statement on db return
+// ErrAlreadyExists (wrapping the driver error) for unique-constraint violations
+func RegisterErrorTranslation(db *gorm.DB) error {
+	translate := func(tx *gorm.DB) {
+		if tx.Error != nil && !errors.Is(tx.Error, ErrAlreadyExists) && isUniqueViolation(tx.Error) {
+			tx.Error = fmt.Errorf("%w: %v", ErrAlreadyExists, tx.Error)
+		}
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().After("gorm:commit_or_rollback_transaction").Register("ecomgo:unique_create", translate),
+		callbacks.Update().After("gorm:commit_or_rollback_transaction").Register("ecomgo:unique_update", translate),
+		callbacks.Raw().After("gorm:raw").Register("ecomgo:unique_raw", translate),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isUniqueViolation recognizes duplicate-key errors of MySQL (1062), PostgreSQL (23505) and SQLite
+func isUniqueViolation(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1062
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505"
+	}
+	// SQLite (serve --dev) only exposes the constraint in its message
+	return errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrAssessmentNotFound is returned when a fraud assessment doesn't exist
+var ErrAssessmentNotFound = errors.New("fraud assessment not found")
+
+type FraudRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewFraudRepository(db *gorm.DB, log *zap.Logger) *FraudRepository {
+	return &FraudRepository{db: db, log: log}
+}
+
+func (r *FraudRepository) Create(ctx context.Context, assessment *models.FraudAssessment) error {
+	if err := r.db.WithContext(ctx).Create(assessment).Error; err != nil {
+		r.log.Error("Failed to save fraud assessment", zap.String("order_id", assessment.OrderID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *FraudRepository) GetByID(ctx context.Context, id string) (*models.FraudAssessment, error) {
+	assessment := &models.FraudAssessment{}
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(assessment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAssessmentNotFound
+	}
+	return assessment, err
+}
+
+// List returns assessments newest first; unresolvedOnly limits reviews to those still pending
+func (r *FraudRepository) List(ctx context.Context, decision string, unresolvedOnly bool, limit, offset int) ([]models.FraudAssessment, error) {
+	query := r.db.WithContext(ctx)
+	if decision != "" {
+		query = query.Where("decision = ?", decision)
+	}
+	if unresolvedOnly {
+		query = query.Where("resolved_at IS NULL")
+	}
+
+	var assessments []models.FraudAssessment
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&assessments).Error
+	return assessments, err
+}
+
+// Resolve records an admin's verdict, only if the assessment is still unresolved
+// Returns: false when it was already resolved
+func (r *FraudRepository) Resolve(ctx context.Context, id, resolution, adminID string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.FraudAssessment{}).
+		Where("id = ? AND resolved_at IS NULL", id).
+		Updates(map[string]interface{}{"resolution": resolution, "resolved_by": adminID, "resolved_at": at})
+	if result.Error != nil {
+		r.log.Error("Failed to resolve fraud assessment", zap.String("id", id), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
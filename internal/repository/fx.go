@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExchangeRateRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewExchangeRateRepository(db *gorm.DB, log *zap.Logger) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db, log: log}
+}
+
+// SaveRates upserts the latest rate of every currency against base
+func (r *ExchangeRateRepository) SaveRates(ctx context.Context, base string, rates map[string]float64, fetchedAt time.Time) error {
+	rows := make([]models.ExchangeRate, 0, len(rates))
+	for currency, rate := range rates {
+		rows = append(rows, models.ExchangeRate{Base: base, Currency: currency, Rate: rate, FetchedAt: fetchedAt})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(&rows).Error
+	if err != nil {
+		r.log.Error("Failed to save exchange rates", zap.String("base", base), zap.Error(err))
+	}
+	return err
+}
+
+// ListRates returns the stored rates against base (empty if never fetched)
+func (r *ExchangeRateRepository) ListRates(ctx context.Context, base string) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	err := r.db.WithContext(ctx).Where("base = ?", base).Find(&rates).Error
+	return rates, err
+}
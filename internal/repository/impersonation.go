@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ImpersonationRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewImpersonationRepository(db *gorm.DB, log *zap.Logger) *ImpersonationRepository {
+	return &ImpersonationRepository{db: db, log: log}
+}
+
+func (r *ImpersonationRepository) Create(ctx context.Context, audit *models.ImpersonationAudit) error {
+	if err := r.db.WithContext(ctx).Create(audit).Error; err != nil {
+		r.log.Error("Failed to save impersonation audit", zap.String("admin_id", audit.AdminID),
+			zap.String("user_id", audit.UserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// List returns audit entries newest first, optionally filtered by impersonated user and/or admin
+func (r *ImpersonationRepository) List(ctx context.Context, userID, adminID string, limit, offset int) ([]models.ImpersonationAudit, error) {
+	query := r.db.WithContext(ctx)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if adminID != "" {
+		query = query.Where("admin_id = ?", adminID)
+	}
+
+	var audits []models.ImpersonationAudit
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&audits).Error
+	return audits, err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProductListingRepository stores the product_listings read model
+// Rows are derived from products; see catalog.ListingProjector for how they are kept in step
+type ProductListingRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewProductListingRepository(db *gorm.DB, log *zap.Logger) *ProductListingRepository {
+	return &ProductListingRepository{db: db, log: log}
+}
+
+// Upsert inserts or replaces the listing row of a product
+func (r *ProductListingRepository) Upsert(ctx context.Context, listing *models.ProductListing) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(listing).Error
+	if err != nil {
+		r.log.Error("Failed to upsert product listing", zap.String("product_id", listing.ProductID), zap.Error(err))
+	}
+	return err
+}
+
+// Delete removes a product from the listing; deleting a missing row is not an error
+func (r *ProductListingRepository) Delete(ctx context.Context, productID string) error {
+	err := r.db.WithContext(ctx).Where("product_id = ?", productID).Delete(&models.ProductListing{}).Error
+	if err != nil {
+		r.log.Error("Failed to delete product listing", zap.String("product_id", productID), zap.Error(err))
+	}
+	return err
+}
+
+// List returns up to limit listings ordered by name, optionally in one category, leaving out
+// the products in exclude (those hidden on a sales channel)
+// Served by idx_product_listings_name or idx_product_listings_category_name
+func (r *ProductListingRepository) List(ctx context.Context, category string, exclude []string, limit, offset int) ([]models.ProductListing, error) {
+	db := r.db.WithContext(ctx)
+	if category != "" {
+		db = db.Where("category = ?", category)
+	}
+	if len(exclude) > 0 {
+		db = db.Where("product_id NOT IN ?", exclude)
+	}
+
+	var listings []models.ProductListing
+	err := db.Order("name ASC, product_id ASC").Limit(limit).Offset(offset).Find(&listings).Error
+	return listings, err
+}
+
+// Backfill copies every active product into the listing in one statement
+// Used once, when the table is created next to an existing catalog
+func (r *ProductListingRepository) Backfill(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(`INSERT INTO product_listings
+		(product_id, sku, name, category, price, currency, in_stock, updated_at)
+		SELECT id, sku, name, category, price, currency, stock > 0, updated_at
+		FROM products WHERE active = ? AND deleted_at IS NULL`, true)
+	if result.Error != nil {
+		r.log.Error("Failed to backfill product listings", zap.Error(result.Error))
+	}
+	return result.RowsAffected, result.Error
+}
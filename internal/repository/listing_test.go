@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jason-Omondi/ecomgo/internal/loadgen"
+)
+
+// BenchmarkListingList pages through the storefront listing
+func BenchmarkListingList(b *testing.B) {
+	f := newBenchFixture(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.listings.List(ctx, "", nil, 20, (i%10)*20); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkListingListCategory(b *testing.B) {
+	f := newBenchFixture(b)
+	ctx := context.Background()
+	categories := loadgen.Categories()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.listings.List(ctx, categories[i%len(categories)], nil, 20, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
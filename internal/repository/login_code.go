@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrLoginCodeNotFound is returned when there is no live code to check against
+var ErrLoginCodeNotFound = errors.New("login code not found")
+
+type LoginCodeRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewLoginCodeRepository(db *gorm.DB, log *zap.Logger) *LoginCodeRepository {
+	return &LoginCodeRepository{db: db, log: log}
+}
+
+// Replace stores code in place of the user's earlier codes, so only the latest one works
+// and the table holds at most one row per user
+func (r *LoginCodeRepository) Replace(ctx context.Context, code *models.LoginCode) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", code.UserID).Delete(&models.LoginCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(code).Error
+	})
+	if err != nil {
+		r.log.Error("Failed to save login code", zap.String("user_id", code.UserID), zap.Error(err))
+	}
+	return err
+}
+
+// GetLive returns the user's code unless it is used, expired or locked by maxAttempts failures
+func (r *LoginCodeRepository) GetLive(ctx context.Context, userID string, maxAttempts int, now time.Time) (*models.LoginCode, error) {
+	return r.first(r.db.WithContext(ctx).Where("user_id = ?", userID), maxAttempts, now)
+}
+
+// GetLiveByLink is GetLive for the code a magic link token belongs to
+func (r *LoginCodeRepository) GetLiveByLink(ctx context.Context, linkHash string, maxAttempts int, now time.Time) (*models.LoginCode, error) {
+	return r.first(r.db.WithContext(ctx).Where("link_hash = ?", linkHash), maxAttempts, now)
+}
+
+func (r *LoginCodeRepository) first(query *gorm.DB, maxAttempts int, now time.Time) (*models.LoginCode, error) {
+	code := &models.LoginCode{}
+	err := query.Where("used_at IS NULL AND expires_at > ? AND attempts < ?", now, maxAttempts).First(code).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLoginCodeNotFound
+		}
+		return nil, err
+	}
+	return code, nil
+}
+
+// RecordFailure counts a wrong guess in one atomic UPDATE
+func (r *LoginCodeRepository) RecordFailure(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&models.LoginCode{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// Use marks the code used
+// The guard on attempts re-checks the lockout in the same statement: parallel guesses counted
+// after the code was read still lock it
+// Returns: false when another request used it first or it got locked meanwhile
+func (r *LoginCodeRepository) Use(ctx context.Context, id string, maxAttempts int, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.LoginCode{}).
+		Where("id = ? AND used_at IS NULL AND attempts < ?", id, maxAttempts).
+		Update("used_at", now)
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+)
+
+// AddressRepository is an in-memory repository.AddressStore
+type AddressRepository struct {
+	mu        sync.RWMutex
+	addresses map[string]models.Address
+}
+
+func NewAddressRepository() *AddressRepository {
+	return &AddressRepository{addresses: make(map[string]models.Address)}
+}
+
+var _ repository.AddressStore = (*AddressRepository)(nil)
+
+// Create inserts an address; when it is the default, the user's other addresses lose the flag
+func (r *AddressRepository) Create(ctx context.Context, addr *models.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := addr.BeforeCreate(nil); err != nil {
+		return err
+	}
+	if _, exists := r.addresses[addr.ID]; exists {
+		return ErrDuplicateKey
+	}
+
+	if addr.IsDefault {
+		for id, other := range r.addresses {
+			if other.UserID == addr.UserID && other.IsDefault {
+				other.IsDefault = false
+				r.addresses[id] = other
+			}
+		}
+	}
+
+	addr.CreatedAt = time.Now()
+	r.addresses[addr.ID] = *addr
+	return nil
+}
+
+// ListByUser returns the user's addresses, default first, then newest first
+func (r *AddressRepository) ListByUser(ctx context.Context, userID string) ([]models.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var addresses []models.Address
+	for _, addr := range r.addresses {
+		if addr.UserID == userID {
+			addresses = append(addresses, addr)
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		if addresses[i].IsDefault != addresses[j].IsDefault {
+			return addresses[i].IsDefault
+		}
+		return addresses[i].CreatedAt.After(addresses[j].CreatedAt)
+	})
+	return addresses, nil
+}
+
+func (r *AddressRepository) GetByID(ctx context.Context, id, userID string) (*models.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	addr, ok := r.addresses[id]
+	if !ok || addr.UserID != userID {
+		return nil, repository.ErrAddressNotFound
+	}
+	return &addr, nil
+}
+
+func (r *AddressRepository) CountByUser(ctx context.Context, userID string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, addr := range r.addresses {
+		if addr.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *AddressRepository) Delete(ctx context.Context, id, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	addr, ok := r.addresses[id]
+	if !ok || addr.UserID != userID {
+		return repository.ErrAddressNotFound
+	}
+	delete(r.addresses, id)
+	return nil
+}
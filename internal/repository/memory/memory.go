@@ -0,0 +1,62 @@
+// Package memory provides map-backed implementations of the repository store interfaces
+// for service-layer tests. They follow the GORM repositories' semantics: the same
+// not-found sentinels, unique constraints, soft deletes, create hooks (ID and defaults)
+// and auto timestamps. Values are copied in and out, so callers can't mutate stored rows.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"gorm.io/gorm/schema"
+)
+
+// ErrDuplicateKey is returned when an insert or update violates a unique index
+// Like the GORM repositories' duplicate errors, it wraps repository.ErrAlreadyExists
+var ErrDuplicateKey = fmt.Errorf("memory: duplicate key: %w", repository.ErrAlreadyExists)
+
+// naming maps struct fields to column names exactly like GORM's default naming strategy
+var naming = schema.NamingStrategy{}
+
+// applyFields sets the columns in fields on the struct dst points to (UpdateFields semantics)
+func applyFields(dst interface{}, fields map[string]interface{}) error {
+	value := reflect.ValueOf(dst).Elem()
+	columns := make(map[string]reflect.Value, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		columns[naming.ColumnName("", field.Name)] = value.Field(i)
+	}
+
+	for column, v := range fields {
+		field, ok := columns[column]
+		if !ok {
+			return fmt.Errorf("memory: unknown column %q", column)
+		}
+		newValue := reflect.ValueOf(v)
+		if !newValue.Type().AssignableTo(field.Type()) {
+			if !newValue.Type().ConvertibleTo(field.Type()) {
+				return fmt.Errorf("memory: cannot assign %T to column %q", v, column)
+			}
+			newValue = newValue.Convert(field.Type())
+		}
+		field.Set(newValue)
+	}
+	return nil
+}
+
+// eachBatch calls fn with consecutive slices of up to size rows, stopping once ctx is done
+// (repository.eachBatch semantics)
+func eachBatch[T any](ctx context.Context, rows []T, size int, fn func([]T) error) error {
+	for start := 0; start < len(rows); start += size {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := min(start+size, len(rows))
+		if err := fn(rows[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
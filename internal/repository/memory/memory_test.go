@@ -0,0 +1,27 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/repository/memory"
+	"github.com/Jason-Omondi/ecomgo/internal/repository/storetest"
+)
+
+func TestUserStore(t *testing.T) {
+	storetest.RunUserStore(t, func(*testing.T) repository.UserStore {
+		return memory.NewUserRepository()
+	})
+}
+
+func TestAddressStore(t *testing.T) {
+	storetest.RunAddressStore(t, func(*testing.T) repository.AddressStore {
+		return memory.NewAddressRepository()
+	})
+}
+
+func TestProductStore(t *testing.T) {
+	storetest.RunProductStore(t, func(*testing.T) repository.ProductStore {
+		return memory.NewProductRepository()
+	})
+}
@@ -0,0 +1,258 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ProductRepository is an in-memory repository.ProductStore
+type ProductRepository struct {
+	mu       sync.RWMutex
+	products map[string]models.Product
+}
+
+func NewProductRepository() *ProductRepository {
+	return &ProductRepository{products: make(map[string]models.Product)}
+}
+
+var _ repository.ProductStore = (*ProductRepository)(nil)
+
+func (r *ProductRepository) Create(ctx context.Context, product *models.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := product.BeforeCreate(nil); err != nil {
+		return err
+	}
+	if _, exists := r.products[product.ID]; exists || r.skuTaken(product.SKU, product.ID) {
+		return ErrDuplicateKey
+	}
+
+	now := time.Now()
+	product.CreatedAt, product.UpdatedAt = now, now
+	r.products[product.ID] = *product
+	return nil
+}
+
+// Update keeps the stored stock, like the GORM repository
+func (r *ProductRepository) Update(ctx context.Context, product *models.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.skuTaken(product.SKU, product.ID) {
+		return ErrDuplicateKey
+	}
+	if existing, ok := r.products[product.ID]; ok {
+		product.Stock = existing.Stock
+	}
+	product.UpdatedAt = time.Now()
+	r.products[product.ID] = *product
+	return nil
+}
+
+func (r *ProductRepository) AdjustStock(ctx context.Context, id string, delta int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || product.DeletedAt.Valid {
+		return repository.ErrProductNotFound
+	}
+	if product.Stock+delta < 0 {
+		return repository.ErrInsufficientStock
+	}
+	product.Stock += delta
+	product.UpdatedAt = time.Now()
+	r.products[id] = product
+	return nil
+}
+
+// Delete soft-deletes a product
+func (r *ProductRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || product.DeletedAt.Valid {
+		return repository.ErrProductNotFound
+	}
+	product.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.products[id] = product
+	return nil
+}
+
+func (r *ProductRepository) GetByID(ctx context.Context, id string) (*models.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	product, ok := r.products[id]
+	if !ok || product.DeletedAt.Valid {
+		return nil, repository.ErrProductNotFound
+	}
+	return &product, nil
+}
+
+func (r *ProductRepository) GetByIDs(ctx context.Context, ids []string) ([]models.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var products []models.Product
+	for _, id := range ids {
+		if product, ok := r.products[id]; ok && !product.DeletedAt.Valid {
+			products = append(products, product)
+		}
+	}
+	return products, nil
+}
+
+func (r *ProductRepository) ListAfter(ctx context.Context, afterID string, limit int) ([]models.Product, error) {
+	products := r.filter(func(p models.Product) bool {
+		return p.ID > afterID && p.Active && !p.DeletedAt.Valid
+	})
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	if len(products) > limit {
+		products = products[:limit]
+	}
+	return products, nil
+}
+
+func (r *ProductRepository) Each(ctx context.Context, batchSize int, fn func([]models.Product) error) error {
+	products := r.filter(func(p models.Product) bool { return !p.DeletedAt.Valid })
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return eachBatch(ctx, products, batchSize, fn)
+}
+
+func (r *ProductRepository) ListInCategoryAfter(ctx context.Context, category, afterID string, limit int) ([]models.Product, error) {
+	products := r.filter(func(p models.Product) bool {
+		return p.Category == category && p.ID > afterID && !p.DeletedAt.Valid
+	})
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	if len(products) > limit {
+		products = products[:limit]
+	}
+	return products, nil
+}
+
+func (r *ProductRepository) CountInCategory(ctx context.Context, category string) (int64, error) {
+	products := r.filter(func(p models.Product) bool { return p.Category == category && !p.DeletedAt.Valid })
+	return int64(len(products)), nil
+}
+
+func (r *ProductRepository) Reprice(ctx context.Context, id string, price int64, unchangedSince time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || product.DeletedAt.Valid || product.UpdatedAt.After(unchangedSince) {
+		return false, nil
+	}
+	product.Price = price
+	product.UpdatedAt = time.Now()
+	r.products[id] = product
+	return true, nil
+}
+
+func (r *ProductRepository) Deactivate(ctx context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || product.DeletedAt.Valid || !product.Active {
+		return false, nil
+	}
+	product.Active = false
+	product.UpdatedAt = time.Now()
+	r.products[id] = product
+	return true, nil
+}
+
+func (r *ProductRepository) CategoryCounts(ctx context.Context) ([]models.CategoryCount, error) {
+	byCategory := make(map[string]int64)
+	for _, p := range r.filter(func(p models.Product) bool { return p.Active && !p.DeletedAt.Valid && p.Category != "" }) {
+		byCategory[p.Category]++
+	}
+
+	counts := make([]models.CategoryCount, 0, len(byCategory))
+	for category, count := range byCategory {
+		counts = append(counts, models.CategoryCount{Category: category, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Category < counts[j].Category })
+	return counts, nil
+}
+
+// ListChangedSince includes soft-deleted products, like the Unscoped GORM query
+func (r *ProductRepository) ListChangedSince(ctx context.Context, since time.Time) ([]models.Product, error) {
+	return r.filter(func(p models.Product) bool {
+		return !p.UpdatedAt.Before(since) || (p.DeletedAt.Valid && !p.DeletedAt.Time.Before(since))
+	}), nil
+}
+
+// Search matches case-insensitively on name, SKU and description, ordered by name
+func (r *ProductRepository) Search(ctx context.Context, query, category string, limit, offset int) ([]models.Product, int64, error) {
+	query = strings.ToLower(query)
+	products := r.filter(func(p models.Product) bool {
+		if !p.Active || p.DeletedAt.Valid || (category != "" && p.Category != category) {
+			return false
+		}
+		return query == "" ||
+			strings.Contains(strings.ToLower(p.Name), query) ||
+			strings.Contains(strings.ToLower(p.SKU), query) ||
+			strings.Contains(strings.ToLower(p.Description), query)
+	})
+	sort.Slice(products, func(i, j int) bool { return products[i].Name < products[j].Name })
+
+	total := int64(len(products))
+	if offset >= len(products) {
+		return nil, total, nil
+	}
+	products = products[offset:]
+	if len(products) > limit {
+		products = products[:limit]
+	}
+	return products, total, nil
+}
+
+func (r *ProductRepository) ListByVendor(ctx context.Context, vendorID string, limit, offset int) ([]models.Product, int64, error) {
+	products := r.filter(func(p models.Product) bool { return p.VendorID == vendorID && !p.DeletedAt.Valid })
+	sort.Slice(products, func(i, j int) bool { return products[i].Name < products[j].Name })
+
+	total := int64(len(products))
+	if offset >= len(products) {
+		return nil, total, nil
+	}
+	products = products[offset:]
+	if len(products) > limit {
+		products = products[:limit]
+	}
+	return products, total, nil
+}
+
+func (r *ProductRepository) filter(keep func(models.Product) bool) []models.Product {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var products []models.Product
+	for _, product := range r.products {
+		if keep(product) {
+			products = append(products, product)
+		}
+	}
+	return products
+}
+
+// skuTaken mirrors the unique index on products.sku (which also covers soft-deleted rows)
+func (r *ProductRepository) skuTaken(sku, exceptID string) bool {
+	for id, product := range r.products {
+		if id != exceptID && product.SKU == sku {
+			return true
+		}
+	}
+	return false
+}
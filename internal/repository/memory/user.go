@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+)
+
+// UserRepository is an in-memory repository.UserStore
+type UserRepository struct {
+	mu    sync.RWMutex
+	users map[string]models.User
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{users: make(map[string]models.User)}
+}
+
+var _ repository.UserStore = (*UserRepository)(nil)
+
+func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := user.BeforeCreate(nil); err != nil {
+		return err
+	}
+	if _, exists := r.users[user.ID]; exists {
+		return ErrDuplicateKey
+	}
+	if r.emailTaken(user.Email, user.ID) || r.usernameTaken(user.Username, user.ID) {
+		return ErrDuplicateKey
+	}
+
+	now := time.Now()
+	user.CreatedAt, user.UpdatedAt = now, now
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email && !user.DeletedAt.Valid {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Username != nil && *user.Username == username && !user.DeletedAt.Valid {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *UserRepository) GetUsersByPhone(ctx context.Context, phone string) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []models.User
+	for _, user := range r.users {
+		if user.Phone == phone && !user.DeletedAt.Valid {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].CreatedAt.Before(users[j].CreatedAt) })
+	return users, nil
+}
+
+func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, repository.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetUsersByIDs(ctx context.Context, ids []string) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []models.User
+	for _, id := range ids {
+		if user, ok := r.users[id]; ok && !user.DeletedAt.Valid {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// UpdateUser saves all fields, inserting the user if it doesn't exist (like gorm Save)
+func (r *UserRepository) UpdateUser(ctx context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.emailTaken(user.Email, user.ID) || r.usernameTaken(user.Username, user.ID) {
+		return ErrDuplicateKey
+	}
+	user.UpdatedAt = time.Now()
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *UserRepository) ListUsersAfter(ctx context.Context, afterID string, limit int) ([]models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []models.User
+	for _, user := range r.users {
+		if user.ID > afterID && !user.DeletedAt.Valid {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func (r *UserRepository) EachUser(ctx context.Context, batchSize int, fn func([]models.User) error) error {
+	r.mu.RLock()
+	var users []models.User
+	for _, user := range r.users {
+		if !user.DeletedAt.Valid {
+			users = append(users, user)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return eachBatch(ctx, users, batchSize, fn)
+}
+
+// UpdateFields updates the given columns; updating a missing user is a no-op, as with GORM
+func (r *UserRepository) UpdateFields(ctx context.Context, id string, fields map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil
+	}
+	if err := applyFields(&user, fields); err != nil {
+		return err
+	}
+	if r.emailTaken(user.Email, user.ID) || r.usernameTaken(user.Username, user.ID) {
+		return ErrDuplicateKey
+	}
+	user.UpdatedAt = time.Now()
+	r.users[id] = user
+	return nil
+}
+
+// emailTaken mirrors the unique index on users.email (which also covers soft-deleted rows)
+func (r *UserRepository) emailTaken(email, exceptID string) bool {
+	for id, user := range r.users {
+		if id != exceptID && user.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+// usernameTaken mirrors the unique index on users.username, where NULLs never collide
+func (r *UserRepository) usernameTaken(username *string, exceptID string) bool {
+	if username == nil {
+		return false
+	}
+	for id, user := range r.users {
+		if id != exceptID && user.Username != nil && *user.Username == *username {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrNotificationNotFound is returned when a notification doesn't exist or belongs to another user
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationRepository handles in-app notifications and per-user notification settings
+type NotificationRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewNotificationRepository(db *gorm.DB, log *zap.Logger) *NotificationRepository {
+	return &NotificationRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// GetPreferences returns userID's preferences, or defaults if never saved
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID string) (*models.NotificationPreference, error) {
+	pref := &models.NotificationPreference{}
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(pref).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.DefaultNotificationPreference(userID), nil
+		}
+		r.log.Error("Failed to fetch notification preferences", zap.String("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+	return pref, nil
+}
+
+// SavePreferences upserts preferences (primary key is user_id)
+func (r *NotificationRepository) SavePreferences(ctx context.Context, pref *models.NotificationPreference) error {
+	if err := r.db.WithContext(ctx).Save(pref).Error; err != nil {
+		r.log.Error("Failed to save notification preferences", zap.String("user_id", pref.UserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// CreateNotification inserts an in-app notification
+func (r *NotificationRepository) CreateNotification(ctx context.Context, n *models.Notification) error {
+	if err := r.db.WithContext(ctx).Create(n).Error; err != nil {
+		r.log.Error("Failed to create notification", zap.String("user_id", n.UserID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListNotifications returns a page of userID's notifications, newest first
+// Returns: page, total matching rows
+func (r *NotificationRepository) ListNotifications(ctx context.Context, userID string, unreadOnly bool,
+	limit, offset int) ([]models.Notification, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.log.Error("Failed to count notifications", zap.String("user_id", userID), zap.Error(err))
+		return nil, 0, err
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&notifications).Error; err != nil {
+		r.log.Error("Failed to list notifications", zap.String("user_id", userID), zap.Error(err))
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+// CountUnread returns the number of unread notifications for userID
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	if err != nil {
+		r.log.Error("Failed to count unread notifications", zap.String("user_id", userID), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkRead marks one of userID's notifications as read (idempotent)
+// Returns: ErrNotificationNotFound if it doesn't exist or belongs to someone else
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id string, at time.Time) error {
+	n := &models.Notification{}
+	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(n).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotificationNotFound
+		}
+		r.log.Error("Failed to fetch notification", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	if n.ReadAt != nil {
+		return nil
+	}
+
+	if err := r.db.WithContext(ctx).Model(n).Update("read_at", at).Error; err != nil {
+		r.log.Error("Failed to mark notification read", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of userID as read
+// Returns: number of notifications updated
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", at)
+	if result.Error != nil {
+		r.log.Error("Failed to mark notifications read", zap.String("user_id", userID), zap.Error(result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
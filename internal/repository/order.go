@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrOrderNotFound is returned when an order isn't on record
+var ErrOrderNotFound = errors.New("order not found")
+
+// orderStatusRank orders the states an order moves through; a status never moves back,
+// so events delivered late or twice can't undo a later state
+var orderStatusRank = map[string]int{
+	models.OrderStatusPlaced:    0,
+	models.OrderStatusPaid:      1,
+	models.OrderStatusShipped:   2,
+	models.OrderStatusDelivered: 3,
+	models.OrderStatusRefunded:  4,
+}
+
+// OrderFilter narrows an order listing; empty fields match everything
+type OrderFilter struct {
+	UserID       string
+	Status       string
+	TaxTreatment string
+	FiscalStatus string
+}
+
+// OrderRepository keeps the order records built from order events, and their notes
+// WithEventStream also appends every change it makes to the order's event stream
+type OrderRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+
+	snapshotEvery int64 // 0 without an event stream
+}
+
+func NewOrderRepository(db *gorm.DB, log *zap.Logger) *OrderRepository {
+	return &OrderRepository{db: db, log: log}
+}
+
+// Record stores a placed order; a redelivered order.placed leaves the existing record alone
+func (r *OrderRepository) Record(ctx context.Context, order *models.Order) error {
+	_, err := r.change(ctx, order.ID, func(tx *gorm.DB, before models.Order) (*orderChange, error) {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(order)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return nil, result.Error
+		}
+		return &orderChange{kind: models.OrderEventPlaced, data: order}, nil
+	})
+	if err != nil {
+		r.log.Error("Failed to record order", zap.String("order_id", order.ID), zap.Error(err))
+	}
+	return err
+}
+
+// UpdateStatus moves an order forward to status
+// Returns: whether it moved (false when the order is unknown or already at or past status)
+func (r *OrderRepository) UpdateStatus(ctx context.Context, orderID, status string) (bool, error) {
+	rank, ok := orderStatusRank[status]
+	if !ok {
+		return false, nil
+	}
+	var earlier []string
+	for s, n := range orderStatusRank {
+		if n < rank {
+			earlier = append(earlier, s)
+		}
+	}
+	if len(earlier) == 0 {
+		return false, nil
+	}
+
+	changed, err := r.change(ctx, orderID, func(tx *gorm.DB, before models.Order) (*orderChange, error) {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND status IN ?", orderID, earlier).
+			Update("status", status)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return nil, result.Error
+		}
+		return &orderChange{kind: models.OrderEventStatusChanged, data: models.OrderStatusChange{From: before.Status, To: status}}, nil
+	})
+	if err != nil {
+		r.log.Error("Failed to update order status", zap.String("order_id", orderID), zap.Error(err))
+	}
+	return changed, err
+}
+
+// Get returns an order with its notes, oldest first; internal notes only when withInternal
+func (r *OrderRepository) Get(ctx context.Context, id string, withInternal bool) (*models.Order, error) {
+	var order models.Order
+	err := r.db.WithContext(ctx).Preload("Notes", func(db *gorm.DB) *gorm.DB {
+		if !withInternal {
+			db = db.Where("internal = ?", false)
+		}
+		return db.Order("created_at ASC, id ASC")
+	}).Where("id = ?", id).First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrderNotFound
+	}
+	return &order, err
+}
+
+// List returns a page of orders without their notes, newest first
+// Returns: page, total matching rows
+func (r *OrderRepository) List(ctx context.Context, filter OrderFilter, limit, offset int) ([]models.Order, int64, error) {
+	query := r.filtered(ctx, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var orders []models.Order
+	err := query.Order("placed_at DESC, id ASC").Limit(limit).Offset(offset).Find(&orders).Error
+	return orders, total, err
+}
+
+// Each calls fn with batches of up to batchSize orders matching filter, without their notes, ordered by ID
+// Used by admin exports, which must not load every order into memory; stops between batches once ctx is done
+func (r *OrderRepository) Each(ctx context.Context, filter OrderFilter, batchSize int, fn func([]models.Order) error) error {
+	err := eachBatch(ctx, r.filtered(ctx, filter), batchSize, fn)
+	if err != nil && ctx.Err() == nil {
+		r.log.Error("Failed to iterate orders", zap.Error(err))
+	}
+	return err
+}
+
+// filtered returns a query for the orders matching filter
+func (r *OrderRepository) filtered(ctx context.Context, filter OrderFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.Order{})
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.TaxTreatment != "" {
+		query = query.Where("tax_treatment = ?", filter.TaxTreatment)
+	}
+	if filter.FiscalStatus != "" {
+		query = query.Where("fiscal_status = ?", filter.FiscalStatus)
+	}
+	return query
+}
+
+// SetFiscalStatus records a fiscal receipt attempt (pending or failed) and its error
+// Returns: false when the order is unknown or already has its receipt
+func (r *OrderRepository) SetFiscalStatus(ctx context.Context, orderID, status, provider, message string) (bool, error) {
+	changed, err := r.change(ctx, orderID, func(tx *gorm.DB, before models.Order) (*orderChange, error) {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND (fiscal_status IS NULL OR fiscal_status <> ?)", orderID, models.FiscalIssued).
+			Updates(map[string]interface{}{"fiscal_status": status, "fiscal_provider": provider, "fiscal_error": message})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return nil, result.Error
+		}
+		return &orderChange{kind: models.OrderEventFiscalUpdated, data: models.OrderFiscalChange{
+			Status:        status,
+			Provider:      provider,
+			ReceiptNumber: before.FiscalReceiptNumber,
+			Signature:     before.FiscalSignature,
+			IssuedAt:      before.FiscalIssuedAt,
+			Error:         message,
+		}}, nil
+	})
+	if err != nil {
+		r.log.Error("Failed to update fiscal status", zap.String("order_id", orderID), zap.Error(err))
+	}
+	return changed, err
+}
+
+// SetFiscalReceipt stores an order's fiscal receipt
+// Returns: false when the order is unknown or already has its receipt
+func (r *OrderRepository) SetFiscalReceipt(ctx context.Context, orderID, provider, number, signature string, issuedAt time.Time) (bool, error) {
+	changed, err := r.change(ctx, orderID, func(tx *gorm.DB, before models.Order) (*orderChange, error) {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND (fiscal_status IS NULL OR fiscal_status <> ?)", orderID, models.FiscalIssued).
+			Updates(map[string]interface{}{
+				"fiscal_status":         models.FiscalIssued,
+				"fiscal_provider":       provider,
+				"fiscal_receipt_number": number,
+				"fiscal_signature":      signature,
+				"fiscal_issued_at":      issuedAt,
+				"fiscal_error":          "",
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return nil, result.Error
+		}
+		return &orderChange{kind: models.OrderEventFiscalUpdated, data: models.OrderFiscalChange{
+			Status:        models.FiscalIssued,
+			Provider:      provider,
+			ReceiptNumber: number,
+			Signature:     signature,
+			IssuedAt:      &issuedAt,
+		}}, nil
+	})
+	if err != nil {
+		r.log.Error("Failed to store fiscal receipt", zap.String("order_id", orderID), zap.Error(err))
+	}
+	return changed, err
+}
+
+// AddNote stores a note on an order
+func (r *OrderRepository) AddNote(ctx context.Context, note *models.OrderNote) error {
+	_, err := r.change(ctx, note.OrderID, func(tx *gorm.DB, before models.Order) (*orderChange, error) {
+		if err := tx.Create(note).Error; err != nil {
+			return nil, err
+		}
+		return &orderChange{kind: models.OrderEventNoteAdded, data: note}, nil
+	})
+	if err != nil {
+		r.log.Error("Failed to add order note", zap.String("order_id", note.OrderID), zap.Error(err))
+	}
+	return err
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/httpctx"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// orderChange is one change to an order, as its event stream records it
+type orderChange struct {
+	kind string
+	data any
+}
+
+// WithEventStream returns a copy of r that appends every change it makes to an order to the
+// order's stream in order_events, in the transaction that makes it, and snapshots the replayed
+// order every snapshotEvery events
+func (r *OrderRepository) WithEventStream(snapshotEvery int) *OrderRepository {
+	stream := *r
+	stream.snapshotEvery = int64(max(snapshotEvery, 1))
+	return &stream
+}
+
+// change runs fn, which changes order orderID and describes the change (nil when nothing changed)
+// With an event stream, fn runs in a transaction holding the order row, gets the order as it was
+// (zero when it isn't on record yet), and the change is appended to the stream
+// Returns: whether fn changed the order
+func (r *OrderRepository) change(ctx context.Context, orderID string,
+	fn func(tx *gorm.DB, before models.Order) (*orderChange, error)) (bool, error) {
+	if r.snapshotEvery == 0 {
+		change, err := fn(r.db.WithContext(ctx), models.Order{})
+		return change != nil, err
+	}
+
+	changed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var before []models.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Notes", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).Where("id = ?", orderID).Limit(1).Find(&before).Error
+		if err != nil {
+			return err
+		}
+		var current models.Order
+		if len(before) > 0 {
+			current = before[0]
+		}
+
+		change, err := fn(tx, current)
+		if err != nil || change == nil {
+			return err
+		}
+		changed = true
+		return r.appendEvent(ctx, tx, current, orderID, change)
+	})
+	return changed, err
+}
+
+// appendEvent adds change to the end of the order's stream, after an imported event holding
+// before when the order was recorded before its stream started
+// The (order_id, sequence) key makes a concurrent append fail rather than fork the stream
+func (r *OrderRepository) appendEvent(ctx context.Context, tx *gorm.DB, before models.Order, orderID string, change *orderChange) error {
+	var last int64
+	err := tx.Model(&models.OrderEvent{}).Where("order_id = ?", orderID).
+		Select("COALESCE(MAX(sequence), 0)").Scan(&last).Error
+	if err != nil {
+		return err
+	}
+
+	changes := []*orderChange{change}
+	if last == 0 && before.ID != "" {
+		changes = []*orderChange{{kind: models.OrderEventImported, data: before}, change}
+	}
+	user, _ := httpctx.UserFromContext(ctx)
+	now := time.Now().UTC()
+	for _, c := range changes {
+		data, err := json.Marshal(c.data)
+		if err != nil {
+			return err
+		}
+		last++
+		event := models.OrderEvent{
+			OrderID:    orderID,
+			Sequence:   last,
+			Type:       c.kind,
+			Data:       data,
+			ActorID:    user.ID,
+			RequestID:  httpctx.RequestIDFromContext(ctx),
+			RecordedAt: now,
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		if last%r.snapshotEvery == 0 {
+			if err := r.snapshot(tx, orderID, event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// snapshot stores the order replayed through event
+func (r *OrderRepository) snapshot(tx *gorm.DB, orderID string, event models.OrderEvent) error {
+	state, err := r.replay(tx, orderID, nil)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.OrderSnapshot{
+		OrderID:    orderID,
+		Sequence:   event.Sequence,
+		State:      *state,
+		RecordedAt: event.RecordedAt,
+	}).Error
+}
+
+// Events returns an order's event stream, oldest first; empty for orders without one
+func (r *OrderRepository) Events(ctx context.Context, orderID string) ([]models.OrderEvent, error) {
+	var events []models.OrderEvent
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("sequence ASC").Find(&events).Error
+	return events, err
+}
+
+// StateAt rebuilds an order as it was at a point in time by replaying its event stream from the
+// latest snapshot taken by then; notes are included, internal ones too
+// Returns: ErrOrderNotFound when the stream has no events by then
+func (r *OrderRepository) StateAt(ctx context.Context, orderID string, at time.Time) (*models.Order, error) {
+	state, err := r.replay(r.db.WithContext(ctx), orderID, &at)
+	if err != nil && !errors.Is(err, ErrOrderNotFound) {
+		r.log.Error("Failed to replay order events", zap.String("order_id", orderID), zap.Error(err))
+	}
+	return state, err
+}
+
+// replay folds an order's events recorded by until (all of them when nil) onto the latest
+// snapshot before that
+func (r *OrderRepository) replay(db *gorm.DB, orderID string, until *time.Time) (*models.Order, error) {
+	snapshots := db.Where("order_id = ?", orderID)
+	if until != nil {
+		snapshots = snapshots.Where("recorded_at <= ?", *until)
+	}
+	var latest []models.OrderSnapshot
+	if err := snapshots.Order("sequence DESC").Limit(1).Find(&latest).Error; err != nil {
+		return nil, err
+	}
+	state := &models.Order{}
+	var from int64
+	if len(latest) > 0 {
+		*state = latest[0].State
+		from = latest[0].Sequence
+	}
+
+	events := db.Where("order_id = ? AND sequence > ?", orderID, from)
+	if until != nil {
+		events = events.Where("recorded_at <= ?", *until)
+	}
+	var stream []models.OrderEvent
+	if err := events.Order("sequence ASC").Find(&stream).Error; err != nil {
+		return nil, err
+	}
+	if from == 0 && len(stream) == 0 {
+		return nil, ErrOrderNotFound
+	}
+	for _, event := range stream {
+		if err := applyOrderEvent(state, event); err != nil {
+			return nil, fmt.Errorf("order %s event %d: %w", orderID, event.Sequence, err)
+		}
+	}
+	return state, nil
+}
+
+// applyOrderEvent applies one event of an order's stream to the order as it was before it
+func applyOrderEvent(state *models.Order, event models.OrderEvent) error {
+	switch event.Type {
+	case models.OrderEventPlaced, models.OrderEventImported:
+		var order models.Order
+		if err := json.Unmarshal(event.Data, &order); err != nil {
+			return err
+		}
+		*state = order
+	case models.OrderEventStatusChanged:
+		var change models.OrderStatusChange
+		if err := json.Unmarshal(event.Data, &change); err != nil {
+			return err
+		}
+		state.Status = change.To
+	case models.OrderEventFiscalUpdated:
+		var change models.OrderFiscalChange
+		if err := json.Unmarshal(event.Data, &change); err != nil {
+			return err
+		}
+		state.FiscalStatus = change.Status
+		state.FiscalProvider = change.Provider
+		state.FiscalReceiptNumber = change.ReceiptNumber
+		state.FiscalSignature = change.Signature
+		state.FiscalIssuedAt = change.IssuedAt
+		state.FiscalError = change.Error
+	case models.OrderEventNoteAdded:
+		var note models.OrderNote
+		if err := json.Unmarshal(event.Data, &note); err != nil {
+			return err
+		}
+		state.Notes = append(state.Notes, note)
+	default:
+		return fmt.Errorf("unknown order event type %q", event.Type)
+	}
+	state.UpdatedAt = event.RecordedAt
+	return nil
+}
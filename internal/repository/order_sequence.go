@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderSequenceRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewOrderSequenceRepository(db *gorm.DB, log *zap.Logger) *OrderSequenceRepository {
+	return &OrderSequenceRepository{db: db, log: log}
+}
+
+// Next increments the counter of scope and returns the new value, starting at 1
+// With tx (the caller's order transaction) the row stays locked until it commits, so a rolled
+// back order gives its number back and numbers are gapless. Without tx the increment commits
+// at once: concurrent callers never wait on each other's orders, but a failed order leaves a gap.
+func (r *OrderSequenceRepository) Next(ctx context.Context, tx *gorm.DB, scope string) (int64, error) {
+	if tx != nil {
+		return r.next(tx.WithContext(ctx), scope)
+	}
+
+	var value int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		value, err = r.next(tx, scope)
+		return err
+	})
+	return value, err
+}
+
+// next works the same on MySQL, Postgres and SQLite: ensure the row, bump it (taking the row
+// lock), then read back the value this transaction wrote
+func (r *OrderSequenceRepository) next(tx *gorm.DB, scope string) (int64, error) {
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.OrderSequence{Scope: scope}).Error
+	if err != nil {
+		r.log.Error("Failed to create order sequence", zap.String("scope", scope), zap.Error(err))
+		return 0, err
+	}
+
+	err = tx.Model(&models.OrderSequence{}).Where("scope = ?", scope).
+		Update("value", gorm.Expr("value + 1")).Error
+	if err != nil {
+		r.log.Error("Failed to increment order sequence", zap.String("scope", scope), zap.Error(err))
+		return 0, err
+	}
+
+	var seq models.OrderSequence
+	if err := tx.Where("scope = ?", scope).First(&seq).Error; err != nil {
+		return 0, err
+	}
+	return seq.Value, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"go.uber.org/zap"
+)
+
+func newOrderRepository(t *testing.T) *OrderRepository {
+	return NewOrderRepository(testutil.NewDB(t, &models.Order{}, &models.OrderNote{}), zap.NewNop())
+}
+
+// TestOrderEach checks that exports see every matching order once, in batches, and stop on cancel
+func TestOrderEach(t *testing.T) {
+	ctx := context.Background()
+	repo := newOrderRepository(t)
+	for i := 0; i < 7; i++ {
+		status := models.OrderStatusPlaced
+		if i%2 == 0 {
+			status = models.OrderStatusPaid
+		}
+		if err := repo.Record(ctx, &models.Order{
+			ID: fmt.Sprintf("order-%d", i), UserID: "user-1", Status: status,
+			Total: 100, Currency: "KES", PlacedAt: time.Now(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		filter  OrderFilter
+		want    int
+		batches int
+	}{
+		{"all", OrderFilter{}, 7, 3},
+		{"status", OrderFilter{Status: models.OrderStatusPaid}, 4, 2},
+		{"user", OrderFilter{UserID: "user-2"}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := map[string]bool{}
+			batches := 0
+			err := repo.Each(ctx, tt.filter, 3, func(orders []models.Order) error {
+				batches++
+				for _, o := range orders {
+					if seen[o.ID] {
+						t.Errorf("order %s seen twice", o.ID)
+					}
+					if tt.filter.Status != "" && o.Status != tt.filter.Status {
+						t.Errorf("order %s has status %s", o.ID, o.Status)
+					}
+					seen[o.ID] = true
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(seen) != tt.want || batches != tt.batches {
+				t.Errorf("got %d orders in %d batches, want %d in %d", len(seen), batches, tt.want, tt.batches)
+			}
+		})
+	}
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		batches := 0
+		err := repo.Each(ctx, OrderFilter{}, 3, func([]models.Order) error {
+			batches++
+			cancel()
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) || batches != 1 {
+			t.Errorf("Each after cancel = %v after %d batches, want context.Canceled after 1", err, batches)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrPageNotFound is returned when a content page doesn't exist
+var ErrPageNotFound = errors.New("page not found")
+
+type PageRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewPageRepository(db *gorm.DB, log *zap.Logger) *PageRepository {
+	return &PageRepository{db: db, log: log}
+}
+
+func (r *PageRepository) Create(ctx context.Context, page *models.Page) error {
+	if err := r.db.WithContext(ctx).Create(page).Error; err != nil {
+		r.log.Error("Failed to create page", zap.String("slug", page.Slug), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *PageRepository) GetByID(ctx context.Context, id string) (*models.Page, error) {
+	var page models.Page
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&page).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPageNotFound
+	}
+	return &page, err
+}
+
+// GetBySlug returns the page at slug in any state
+func (r *PageRepository) GetBySlug(ctx context.Context, slug string) (*models.Page, error) {
+	var page models.Page
+	err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&page).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPageNotFound
+	}
+	return &page, err
+}
+
+// List returns pages ordered by slug, optionally only those in status
+func (r *PageRepository) List(ctx context.Context, status string) ([]models.Page, error) {
+	query := r.db.WithContext(ctx)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var pages []models.Page
+	if err := query.Order("slug ASC").Find(&pages).Error; err != nil {
+		r.log.Error("Failed to list pages", zap.Error(err))
+		return nil, err
+	}
+	return pages, nil
+}
+
+// Summaries returns published pages without their bodies, ordered by slug
+func (r *PageRepository) Summaries(ctx context.Context) ([]models.PageSummary, error) {
+	var summaries []models.PageSummary
+	err := r.db.WithContext(ctx).Model(&models.Page{}).Select("slug, title, updated_at").
+		Where("status = ?", models.PagePublished).Order("slug ASC").Scan(&summaries).Error
+	return summaries, err
+}
+
+func (r *PageRepository) Save(ctx context.Context, page *models.Page) error {
+	if err := r.db.WithContext(ctx).Save(page).Error; err != nil {
+		r.log.Error("Failed to save page", zap.String("id", page.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *PageRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Page{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPageNotFound
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrProductNotFound is returned when a product doesn't exist (or was deleted)
+	ErrProductNotFound = errors.New("product not found")
+	// ErrInsufficientStock is returned when a stock decrement would go below zero
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+type ProductRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewProductRepository(db *gorm.DB, log *zap.Logger) *ProductRepository {
+	return &ProductRepository{db: db, log: log}
+}
+
+func (r *ProductRepository) Create(ctx context.Context, product *models.Product) error {
+	if err := r.db.WithContext(ctx).Create(product).Error; err != nil {
+		r.log.Error("Failed to create product", zap.String("sku", product.SKU), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Update saves every field except stock, which only changes through AdjustStock
+// Writing back a stock value read earlier would undo sales made in the meantime
+func (r *ProductRepository) Update(ctx context.Context, product *models.Product) error {
+	if err := r.db.WithContext(ctx).Omit("stock").Save(product).Error; err != nil {
+		r.log.Error("Failed to update product", zap.String("id", product.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// AdjustStock adds delta (negative to decrement) to a product's stock in one atomic UPDATE
+// A decrement only matches while enough stock is left, so concurrent buyers can't oversell;
+// no row is read or locked first, and deadlocks or lock timeouts are retried
+func (r *ProductRepository) AdjustStock(ctx context.Context, id string, delta int) error {
+	var affected int64
+	err := withRetry(ctx, func() error {
+		db := r.db.WithContext(ctx).Model(&models.Product{}).Where("id = ?", id)
+		if delta < 0 {
+			db = db.Where("stock >= ?", -delta)
+		}
+		result := db.Update("stock", gorm.Expr("stock + ?", delta))
+		affected = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		r.log.Error("Failed to adjust product stock", zap.String("id", id), zap.Int("delta", delta), zap.Error(err))
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	// Nothing matched: either the product is gone or there isn't enough stock
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return ErrInsufficientStock
+}
+
+// Delete soft-deletes a product so order history keeps its reference
+func (r *ProductRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Product{})
+	if result.Error != nil {
+		r.log.Error("Failed to delete product", zap.String("id", id), zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrProductNotFound
+	}
+	return nil
+}
+
+func (r *ProductRepository) GetByID(ctx context.Context, id string) (*models.Product, error) {
+	product := &models.Product{}
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(product).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrProductNotFound
+	}
+	return product, err
+}
+
+// GetByIDs returns the existing products among ids, in no particular order
+func (r *ProductRepository) GetByIDs(ctx context.Context, ids []string) ([]models.Product, error) {
+	var products []models.Product
+	if len(ids) == 0 {
+		return products, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&products).Error
+	return products, err
+}
+
+// ListAfter returns up to limit active products with ID greater than afterID, ordered by ID
+// Used to stream the catalog into a fresh search index
+func (r *ProductRepository) ListAfter(ctx context.Context, afterID string, limit int) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.WithContext(ctx).
+		Where("id > ? AND active = ?", afterID, true).
+		Order("id ASC").
+		Limit(limit).
+		Find(&products).Error
+	return products, err
+}
+
+// Each calls fn with batches of up to batchSize products (active or not), ordered by ID
+// Used by admin exports, which must not load the whole catalog into memory; stops between batches once ctx is done
+func (r *ProductRepository) Each(ctx context.Context, batchSize int, fn func([]models.Product) error) error {
+	err := eachBatch(ctx, r.db, batchSize, fn)
+	if err != nil && ctx.Err() == nil {
+		r.log.Error("Failed to iterate products", zap.Error(err))
+	}
+	return err
+}
+
+// ListInCategoryAfter returns up to limit products (active or not) in category with ID greater
+// than afterID, ordered by ID; used by bulk actions to walk a category in batches
+func (r *ProductRepository) ListInCategoryAfter(ctx context.Context, category, afterID string, limit int) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.WithContext(ctx).
+		Where("category = ? AND id > ?", category, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&products).Error
+	return products, err
+}
+
+// CountInCategory returns the number of products (active or not) in category
+func (r *ProductRepository) CountInCategory(ctx context.Context, category string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Product{}).Where("category = ?", category).Count(&count).Error
+	return count, err
+}
+
+// Reprice sets a product's price unless the product changed after unchangedSince
+// It reports whether the price was set; a product changed since (or gone) is left alone, which
+// also keeps a retried bulk reprice from applying its change twice
+func (r *ProductRepository) Reprice(ctx context.Context, id string, price int64, unchangedSince time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Product{}).
+		Where("id = ? AND updated_at <= ?", id, unchangedSince).
+		Update("price", price)
+	if result.Error != nil {
+		r.log.Error("Failed to reprice product", zap.String("id", id), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Deactivate hides a product from the storefront; it reports false when it was already inactive or is gone
+func (r *ProductRepository) Deactivate(ctx context.Context, id string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Product{}).
+		Where("id = ? AND active = ?", id, true).
+		Update("active", false)
+	if result.Error != nil {
+		r.log.Error("Failed to deactivate product", zap.String("id", id), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListChangedSince returns products updated or deleted at or after since, including soft-deleted ones
+func (r *ProductRepository) ListChangedSince(ctx context.Context, since time.Time) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("updated_at >= ? OR deleted_at >= ?", since, since).
+		Find(&products).Error
+	return products, err
+}
+
+// CategoryCounts returns the number of active products per non-empty category
+func (r *ProductRepository) CategoryCounts(ctx context.Context) ([]models.CategoryCount, error) {
+	var counts []models.CategoryCount
+	err := r.db.WithContext(ctx).Model(&models.Product{}).
+		Select("category, COUNT(*) AS count").
+		Where("active = ? AND category <> ?", true, "").
+		Group("category").
+		Order("category ASC").
+		Scan(&counts).Error
+	if err != nil {
+		r.log.Error("Failed to count products per category", zap.Error(err))
+	}
+	return counts, err
+}
+
+// Search matches query against name, SKU and description with LIKE
+// Fallback when no search engine is configured - fine for small catalogs only
+func (r *ProductRepository) Search(ctx context.Context, query, category string, limit, offset int) ([]models.Product, int64, error) {
+	db := r.db.WithContext(ctx).Model(&models.Product{}).Where("active = ?", true)
+	if query != "" {
+		like := "%" + strings.ToLower(query) + "%"
+		db = db.Where("LOWER(name) LIKE ? OR LOWER(sku) LIKE ? OR LOWER(description) LIKE ?", like, like, like)
+	}
+	if category != "" {
+		db = db.Where("category = ?", category)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var products []models.Product
+	err := db.Order("name ASC").Limit(limit).Offset(offset).Find(&products).Error
+	return products, total, err
+}
+
+// ListByVendor returns a page of a vendor's products, active or not, ordered by name
+// Returns: page, total
+func (r *ProductRepository) ListByVendor(ctx context.Context, vendorID string, limit, offset int) ([]models.Product, int64, error) {
+	db := r.db.WithContext(ctx).Model(&models.Product{}).Where("vendor_id = ?", vendorID)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var products []models.Product
+	err := db.Order("name ASC").Limit(limit).Offset(offset).Find(&products).Error
+	return products, total, err
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jason-Omondi/ecomgo/internal/loadgen"
+)
+
+// BenchmarkSearch is the database search used while no search engine is configured
+func BenchmarkSearch(b *testing.B) {
+	f := newBenchFixture(b)
+	ctx := context.Background()
+	terms := loadgen.SearchTerms()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := f.products.Search(ctx, terms[i%len(terms)], "", 20, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrQuestionNotFound is returned when a product question doesn't exist
+	ErrQuestionNotFound = errors.New("question not found")
+	// ErrAnswerNotFound is returned when an answer doesn't exist on the question
+	ErrAnswerNotFound = errors.New("answer not found")
+)
+
+// QuestionFilter narrows question listings; empty fields match everything
+type QuestionFilter struct {
+	ProductID string
+	UserID    string // the asker
+	VendorID  string // questions on this vendor's products
+	Status    string
+}
+
+type QuestionRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewQuestionRepository(db *gorm.DB, log *zap.Logger) *QuestionRepository {
+	return &QuestionRepository{db: db, log: log}
+}
+
+func answersOldestFirst(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at ASC, id ASC")
+}
+
+func (r *QuestionRepository) Create(ctx context.Context, question *models.ProductQuestion) error {
+	err := r.db.WithContext(ctx).Omit("Answers").Create(question).Error
+	if err != nil {
+		r.log.Error("Failed to create question", zap.String("product_id", question.ProductID), zap.Error(err))
+	}
+	return err
+}
+
+// GetByID returns a question with its answers, oldest first
+func (r *QuestionRepository) GetByID(ctx context.Context, id string) (*models.ProductQuestion, error) {
+	var question models.ProductQuestion
+	err := r.db.WithContext(ctx).Preload("Answers", answersOldestFirst).Where("id = ?", id).First(&question).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrQuestionNotFound
+	}
+	return &question, err
+}
+
+// List returns a page of questions with their answers, newest first
+// Returns: page, total rows
+func (r *QuestionRepository) List(ctx context.Context, filter QuestionFilter, limit, offset int) ([]models.ProductQuestion, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.ProductQuestion{})
+	if filter.ProductID != "" {
+		query = query.Where("product_questions.product_id = ?", filter.ProductID)
+	}
+	if filter.UserID != "" {
+		query = query.Where("product_questions.user_id = ?", filter.UserID)
+	}
+	if filter.VendorID != "" {
+		query = query.Where("product_questions.product_id IN (?)",
+			r.db.Model(&models.Product{}).Select("id").Where("vendor_id = ?", filter.VendorID))
+	}
+	if filter.Status != "" {
+		query = query.Where("product_questions.status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var questions []models.ProductQuestion
+	err := query.Preload("Answers", answersOldestFirst).
+		Order("product_questions.created_at DESC, product_questions.id ASC").Limit(limit).Offset(offset).Find(&questions).Error
+	if err != nil {
+		r.log.Error("Failed to list questions", zap.Error(err))
+		return nil, 0, err
+	}
+	return questions, total, nil
+}
+
+// Moderate moves a question to status if it is in one of from
+// Returns: false when the question isn't in any of from (or doesn't exist)
+func (r *QuestionRepository) Moderate(ctx context.Context, id string, from []string, status, reason, moderatorID string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.ProductQuestion{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(map[string]interface{}{"status": status, "reject_reason": reason, "moderated_by": moderatorID, "moderated_at": at})
+	if result.Error != nil {
+		r.log.Error("Failed to moderate question", zap.String("id", id), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// AddAnswer saves an answer to a published question and counts it, in one transaction
+// With publish, a pending question is published by moderatorID first
+// Returns: false when the question isn't published, in which case nothing is saved
+func (r *QuestionRepository) AddAnswer(ctx context.Context, answer *models.ProductAnswer, publish bool, moderatorID string, at time.Time) (bool, error) {
+	added := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if publish {
+			err := tx.Model(&models.ProductQuestion{}).
+				Where("id = ? AND status = ?", answer.QuestionID, models.QuestionPending).
+				Updates(map[string]interface{}{"status": models.QuestionPublished, "moderated_by": moderatorID, "moderated_at": at}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		result := tx.Model(&models.ProductQuestion{}).
+			Where("id = ? AND status = ?", answer.QuestionID, models.QuestionPublished).
+			Update("answer_count", gorm.Expr("answer_count + 1"))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		added = true
+		return tx.Create(answer).Error
+	})
+	if err != nil {
+		r.log.Error("Failed to add answer", zap.String("question_id", answer.QuestionID), zap.Error(err))
+		return false, err
+	}
+	return added, nil
+}
+
+// DeleteAnswer removes an answer from its question
+func (r *QuestionRepository) DeleteAnswer(ctx context.Context, questionID, answerID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND question_id = ?", answerID, questionID).Delete(&models.ProductAnswer{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAnswerNotFound
+		}
+		return tx.Model(&models.ProductQuestion{}).Where("id = ?", questionID).
+			Update("answer_count", gorm.Expr("answer_count - 1")).Error
+	})
+}
+
+// Delete removes a question with its answers
+func (r *QuestionRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&models.ProductQuestion{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrQuestionNotFound
+		}
+		return tx.Where("question_id = ?", id).Delete(&models.ProductAnswer{}).Error
+	})
+}
+
+// DeleteForProduct removes every question on a deleted product, with the answers
+// Returns: how many questions were removed
+func (r *QuestionRepository) DeleteForProduct(ctx context.Context, productID string) (int64, error) {
+	var removed int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		questions := tx.Model(&models.ProductQuestion{}).Select("id").Where("product_id = ?", productID)
+		if err := tx.Where("question_id IN (?)", questions).Delete(&models.ProductAnswer{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("product_id = ?", productID).Delete(&models.ProductQuestion{})
+		removed = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		r.log.Error("Failed to remove questions of deleted product", zap.String("product_id", productID), zap.Error(err))
+	}
+	return removed, err
+}
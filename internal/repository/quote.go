@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrQuoteNotFound is returned when a quote doesn't exist (or isn't the customer's)
+var ErrQuoteNotFound = errors.New("quote not found")
+
+// QuoteFilter narrows quote listings; empty fields match everything
+type QuoteFilter struct {
+	UserID string
+	Status string
+}
+
+type QuoteRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewQuoteRepository(db *gorm.DB, log *zap.Logger) *QuoteRepository {
+	return &QuoteRepository{db: db, log: log}
+}
+
+func (r *QuoteRepository) Create(ctx context.Context, quote *models.Quote) error {
+	err := r.db.WithContext(ctx).Create(quote).Error
+	if err != nil {
+		r.log.Error("Failed to create quote", zap.String("user_id", quote.UserID), zap.Error(err))
+	}
+	return err
+}
+
+func (r *QuoteRepository) GetByID(ctx context.Context, id string) (*models.Quote, error) {
+	var quote models.Quote
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&quote).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrQuoteNotFound
+	}
+	return &quote, err
+}
+
+// List returns a page of quotes, newest first
+// Returns: page, total rows
+func (r *QuoteRepository) List(ctx context.Context, filter QuoteFilter, limit, offset int) ([]models.Quote, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Quote{})
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var quotes []models.Quote
+	if err := query.Order("created_at DESC, id ASC").Limit(limit).Offset(offset).Find(&quotes).Error; err != nil {
+		r.log.Error("Failed to list quotes", zap.Error(err))
+		return nil, 0, err
+	}
+	return quotes, total, nil
+}
+
+// Update saves changed fields of a quote if it is still in one of the states from
+// Returns: false when the quote moved on meanwhile (or doesn't exist)
+func (r *QuoteRepository) Update(ctx context.Context, id string, from []string, fields map[string]interface{}) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Quote{}).Where("id = ? AND status IN ?", id, from).Updates(fields)
+	if result.Error != nil {
+		r.log.Error("Failed to update quote", zap.String("id", id), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// SetLines replaces the lines and subtotal of a quote still waiting for review
+// Returns: false when the quote was reviewed or cancelled meanwhile
+func (r *QuoteRepository) SetLines(ctx context.Context, quote *models.Quote) (bool, error) {
+	result := r.db.WithContext(ctx).Model(quote).Where("status = ?", models.QuoteRequested).
+		Select("Lines", "Subtotal", "Response").Updates(quote)
+	if result.Error != nil {
+		r.log.Error("Failed to update quote prices", zap.String("id", quote.ID), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// MarkOrdered moves userID's approved, unexpired quote to ordered as orderID, in one transaction
+// with number, which allocates the order number in it (ordernumber.Generator.NextTx)
+// Returns: the order number; "" when the quote isn't approved, has expired or isn't the user's
+func (r *QuoteRepository) MarkOrdered(ctx context.Context, id, userID, orderID string, at time.Time,
+	number func(tx *gorm.DB) (string, error)) (string, error) {
+	var orderNumber string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Quote{}).
+			Where("id = ? AND user_id = ? AND status = ? AND valid_until > ?", id, userID, models.QuoteApproved, at).
+			Updates(map[string]interface{}{"status": models.QuoteOrdered, "order_id": orderID, "ordered_at": at})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		n, err := number(tx)
+		if err != nil {
+			return err
+		}
+		orderNumber = n
+		return tx.Model(&models.Quote{}).Where("id = ?", id).Update("order_number", n).Error
+	})
+	if err != nil {
+		r.log.Error("Failed to order quote", zap.String("id", id), zap.Error(err))
+		return "", err
+	}
+	return orderNumber, nil
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrReferralCodeNotFound is returned when no customer has the code, or the user has none yet
+	ErrReferralCodeNotFound = errors.New("referral code not found")
+	// ErrReferralNotFound is returned when a customer wasn't referred
+	ErrReferralNotFound = errors.New("referral not found")
+)
+
+type ReferralRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewReferralRepository(db *gorm.DB, log *zap.Logger) *ReferralRepository {
+	return &ReferralRepository{db: db, log: log}
+}
+
+// CodeOf returns userID's referral code
+func (r *ReferralRepository) CodeOf(ctx context.Context, userID string) (*models.ReferralCode, error) {
+	var code models.ReferralCode
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&code).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReferralCodeNotFound
+	}
+	return &code, err
+}
+
+// GetCode returns a code with its owner's first name, if the owner's account still exists
+func (r *ReferralRepository) GetCode(ctx context.Context, code string) (*models.ReferralCode, string, error) {
+	var row struct {
+		models.ReferralCode
+		FirstName string
+	}
+	err := r.db.WithContext(ctx).Table("referral_codes").
+		Joins("JOIN users ON users.id = referral_codes.user_id AND users.deleted_at IS NULL").
+		Select("referral_codes.*, users.first_name").
+		Where("referral_codes.code = ?", code).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", ErrReferralCodeNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return &row.ReferralCode, row.FirstName, nil
+}
+
+// CreateCode saves a user's code
+// Returns: false without error when the user already has one or another user has the code
+func (r *ReferralRepository) CreateCode(ctx context.Context, code *models.ReferralCode) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(code)
+	if result.Error != nil {
+		r.log.Error("Failed to create referral code", zap.String("user_id", code.UserID), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetByReferee returns the referral that brought refereeID in
+func (r *ReferralRepository) GetByReferee(ctx context.Context, refereeID string) (*models.Referral, error) {
+	var referral models.Referral
+	err := r.db.WithContext(ctx).Where("referee_id = ?", refereeID).First(&referral).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReferralNotFound
+	}
+	return &referral, err
+}
+
+// Attribute records a signup with the rewards it earns, in one transaction
+// The rewards are tied to the new referral
+// Returns: false when the referee was already attributed, in which case nothing is granted
+func (r *ReferralRepository) Attribute(ctx context.Context, referral *models.Referral, rewards []models.ReferralReward) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(referral)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		created = true
+		for i := range rewards {
+			rewards[i].ReferralID = referral.ID
+		}
+		return grantRewards(tx, rewards)
+	})
+	if err != nil {
+		r.log.Error("Failed to record referral", zap.String("referee_id", referral.RefereeID), zap.Error(err))
+	}
+	return created, err
+}
+
+// Qualify marks a signed-up referral as ordered with the rewards it earns, in one transaction
+// Returns: false when the referral isn't signed_up any more, in which case nothing is granted
+func (r *ReferralRepository) Qualify(ctx context.Context, referral *models.Referral, orderID string, at time.Time,
+	rewards []models.ReferralReward) (bool, error) {
+	qualified := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Referral{}).
+			Where("id = ? AND status = ?", referral.ID, models.ReferralSignedUp).
+			Updates(map[string]interface{}{"status": models.ReferralOrdered, "first_order_id": orderID, "ordered_at": at})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		qualified = true
+		return grantRewards(tx, rewards)
+	})
+	if err != nil {
+		r.log.Error("Failed to qualify referral", zap.String("id", referral.ID), zap.Error(err))
+		return false, err
+	}
+	if qualified {
+		referral.Status, referral.FirstOrderID, referral.OrderedAt = models.ReferralOrdered, orderID, &at
+	}
+	return qualified, nil
+}
+
+func grantRewards(tx *gorm.DB, rewards []models.ReferralReward) error {
+	if len(rewards) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rewards).Error
+}
+
+// Counts returns how many customers referrerID brought in, and how many of them have ordered
+func (r *ReferralRepository) Counts(ctx context.Context, referrerID string) (signups, ordered int64, err error) {
+	var row struct {
+		Signups int64
+		Ordered int64
+	}
+	err = r.db.WithContext(ctx).Model(&models.Referral{}).
+		Select("COUNT(*) AS signups, COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS ordered", models.ReferralOrdered).
+		Where("referrer_id = ?", referrerID).Scan(&row).Error
+	return row.Signups, row.Ordered, err
+}
+
+// Earned returns the points and the credit per currency granted to userID, or to everyone when
+// userID is empty
+func (r *ReferralRepository) Earned(ctx context.Context, userID string) (int64, []models.ReferralCredit, error) {
+	var rows []struct {
+		Type     string
+		Currency string
+		Amount   int64
+	}
+	query := r.db.WithContext(ctx).Model(&models.ReferralReward{}).
+		Select("type, COALESCE(currency, '') AS currency, SUM(amount) AS amount").
+		Group("type, currency").Order("currency ASC")
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return 0, nil, err
+	}
+
+	var points int64
+	credit := []models.ReferralCredit{}
+	for _, row := range rows {
+		// Only credit carries a currency
+		if row.Currency == "" {
+			points += row.Amount
+			continue
+		}
+		credit = append(credit, models.ReferralCredit{Currency: row.Currency, Amount: row.Amount})
+	}
+	return points, credit, nil
+}
+
+// Referred returns a page of the customers referrerID brought in, newest first
+// Returns: page, total rows
+func (r *ReferralRepository) Referred(ctx context.Context, referrerID string, limit, offset int) ([]models.ReferredCustomer, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Referral{}).Where("referrals.referrer_id = ?", referrerID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		FirstName  string
+		LastName   string
+		Status     string
+		SignedUpAt time.Time
+		OrderedAt  *time.Time
+	}
+	err := query.Joins("LEFT JOIN users ON users.id = referrals.referee_id").
+		Select("COALESCE(users.first_name, '') AS first_name, COALESCE(users.last_name, '') AS last_name, referrals.status, referrals.signed_up_at, referrals.ordered_at").
+		Order("referrals.signed_up_at DESC, referrals.id ASC").Limit(limit).Offset(offset).Scan(&rows).Error
+	if err != nil {
+		r.log.Error("Failed to list referred customers", zap.String("referrer_id", referrerID), zap.Error(err))
+		return nil, 0, err
+	}
+
+	referred := make([]models.ReferredCustomer, len(rows))
+	for i, row := range rows {
+		name := row.FirstName
+		if initial := []rune(row.LastName); len(initial) > 0 {
+			name += " " + string(initial[0]) + "."
+		}
+		referred[i] = models.ReferredCustomer{Name: name, Status: row.Status, SignedUpAt: row.SignedUpAt, OrderedAt: row.OrderedAt}
+	}
+	return referred, total, nil
+}
+
+// List returns a page of referrals, newest first, optionally of one referrer and in one state
+// Returns: page, total rows
+func (r *ReferralRepository) List(ctx context.Context, referrerID, status string, limit, offset int) ([]models.Referral, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Referral{})
+	if referrerID != "" {
+		query = query.Where("referrer_id = ?", referrerID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var referrals []models.Referral
+	if err := query.Order("signed_up_at DESC, id ASC").Limit(limit).Offset(offset).Find(&referrals).Error; err != nil {
+		r.log.Error("Failed to list referrals", zap.Error(err))
+		return nil, 0, err
+	}
+	return referrals, total, nil
+}
+
+// Stats sums up every referral, with the top referrers by first orders, then signups
+func (r *ReferralRepository) Stats(ctx context.Context, top int) (*models.ReferralStatsResponse, error) {
+	stats := &models.ReferralStatsResponse{}
+	var totals struct {
+		Referrers int64
+		Signups   int64
+		Ordered   int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.Referral{}).
+		Select("COUNT(DISTINCT referrer_id) AS referrers, COUNT(*) AS signups, "+
+			"COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS ordered", models.ReferralOrdered).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.Referrers, stats.Signups, stats.FirstOrders = totals.Referrers, totals.Signups, totals.Ordered
+
+	if stats.Points, stats.Credit, err = r.Earned(ctx, ""); err != nil {
+		return nil, err
+	}
+
+	stats.TopReferrers = []models.ReferrerStats{}
+	err = r.db.WithContext(ctx).Table("referrals").
+		Joins("JOIN users ON users.id = referrals.referrer_id").
+		Select("referrals.referrer_id AS user_id, users.email, users.first_name, users.last_name, "+
+			"COUNT(*) AS signups, SUM(CASE WHEN referrals.status = ? THEN 1 ELSE 0 END) AS first_orders", models.ReferralOrdered).
+		Group("referrals.referrer_id, users.email, users.first_name, users.last_name").
+		Order("first_orders DESC, signups DESC, user_id ASC").Limit(top).Scan(&stats.TopReferrers).Error
+	if err != nil {
+		r.log.Error("Failed to load top referrers", zap.Error(err))
+		return nil, err
+	}
+	return stats, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// retryAttempts is how often a statement runs when the database reports a transient conflict
+const retryAttempts = 4
+
+// withRetry runs fn again when it fails with a deadlock, serialization failure or lock timeout
+// Only use it for single statements (or whole transactions) that are safe to repeat
+func withRetry(ctx context.Context, fn func() error) error {
+	delay := 10 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt == retryAttempts || !isTransient(err) {
+			return err
+		}
+
+		// Jittered so the colliding transactions don't retry in lockstep
+		wait := delay/2 + time.Duration(rand.Int63n(int64(delay)))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// isTransient reports whether err is a lock conflict that succeeds when retried
+func isTransient(err error) bool {
+	// pgx errors expose the SQLSTATE code
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		switch pgErr.SQLState() {
+		case "40001", "40P01", "55P03": // serialization_failure, deadlock_detected, lock_not_available
+			return true
+		}
+		return false
+	}
+
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return myErr.Number == 1213 || myErr.Number == 1205 // ER_LOCK_DEADLOCK, ER_LOCK_WAIT_TIMEOUT
+	}
+
+	// SQLite serializes writers and reports contention as SQLITE_BUSY
+	msg := err.Error()
+	return strings.Contains(msg, "SQLITE_BUSY") || strings.Contains(msg, "database is locked")
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSagaNotFound is returned when a saga doesn't exist
+	ErrSagaNotFound = errors.New("saga not found")
+	// ErrSagaLeaseLost is returned by Save when another instance took the saga over,
+	// because the lease expired while a step was running
+	ErrSagaLeaseLost = errors.New("saga lease lost")
+)
+
+// activeSagaStatuses are the states a saga still has work in
+var activeSagaStatuses = []string{models.SagaRunning, models.SagaCompensating}
+
+type SagaRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewSagaRepository(db *gorm.DB, log *zap.Logger) *SagaRepository {
+	return &SagaRepository{db: db, log: log}
+}
+
+// Create inserts a saga, normally already leased to its creator
+// Returns: ErrAlreadyExists when a saga of the type already has the idempotency key
+func (r *SagaRepository) Create(ctx context.Context, saga *models.Saga) error {
+	err := r.db.WithContext(ctx).Create(saga).Error
+	if err != nil && !errors.Is(err, ErrAlreadyExists) {
+		r.log.Error("Failed to create saga", zap.String("type", saga.Type), zap.Error(err))
+	}
+	return err
+}
+
+func (r *SagaRepository) GetByID(ctx context.Context, id string) (*models.Saga, error) {
+	var saga models.Saga
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&saga).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSagaNotFound
+		}
+		return nil, err
+	}
+	return &saga, nil
+}
+
+// GetByKey finds the saga of sagaType started with an idempotency key
+func (r *SagaRepository) GetByKey(ctx context.Context, sagaType, key string) (*models.Saga, error) {
+	var saga models.Saga
+	err := r.db.WithContext(ctx).Where("type = ? AND idempotency_key = ?", sagaType, key).First(&saga).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSagaNotFound
+		}
+		return nil, err
+	}
+	return &saga, nil
+}
+
+// Claim leases an unfinished saga to token until until, unless another lease is still running
+// Returns: false when the saga is finished or leased to someone else
+func (r *SagaRepository) Claim(ctx context.Context, id, token string, now, until time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Saga{}).
+		Where("id = ? AND status IN ?", id, activeSagaStatuses).
+		Where("locked_until IS NULL OR locked_until <= ?", now).
+		Updates(map[string]interface{}{"locked_by": token, "locked_until": until})
+	if result.Error != nil {
+		r.log.Error("Failed to claim saga", zap.String("saga_id", id), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Save records a saga's progress while its lease is held by token
+// local runs first in the same transaction, so a step's database work commits with the record
+// of the step or not at all; nil skips it
+// Returns: ErrSagaLeaseLost when token no longer holds the lease
+func (r *SagaRepository) Save(ctx context.Context, saga *models.Saga, token string, local func(tx *gorm.DB) error) error {
+	err := withRetry(ctx, func() error {
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if local != nil {
+				if err := local(tx); err != nil {
+					return err
+				}
+			}
+			result := tx.Model(&models.Saga{}).Where("id = ? AND locked_by = ?", saga.ID, token).
+				Updates(map[string]interface{}{
+					"status":          saga.Status,
+					"step":            saga.Step,
+					"data":            saga.Data,
+					"error":           saga.Error,
+					"attempts":        saga.Attempts,
+					"next_attempt_at": saga.NextAttemptAt,
+					"locked_by":       saga.LockedBy,
+					"locked_until":    saga.LockedUntil,
+					"updated_at":      time.Now().UTC(),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrSagaLeaseLost
+			}
+			return nil
+		})
+	})
+	if err != nil && local == nil && !errors.Is(err, ErrSagaLeaseLost) {
+		r.log.Error("Failed to save saga", zap.String("saga_id", saga.ID), zap.Error(err))
+	}
+	return err
+}
+
+// Due returns the IDs of up to limit unfinished sagas whose next attempt is due and whose
+// lease, if any, has expired: retries after transient failures and sagas left by a crashed instance
+func (r *SagaRepository) Due(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Model(&models.Saga{}).
+		Where("status IN ? AND next_attempt_at <= ?", activeSagaStatuses, now).
+		Where("locked_until IS NULL OR locked_until <= ?", now).
+		Order("next_attempt_at").Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrSavedCartNotFound is returned when a saved cart doesn't exist or isn't the caller's
+var ErrSavedCartNotFound = errors.New("saved cart not found")
+
+// SavedCartRepository keeps customers' saved carts
+// Every lookup is scoped to the owner, so one customer can't reach another's carts
+type SavedCartRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewSavedCartRepository(db *gorm.DB, log *zap.Logger) *SavedCartRepository {
+	return &SavedCartRepository{db: db, log: log}
+}
+
+// Create stores a saved cart
+// Returns: ErrAlreadyExists when the customer already has a cart with that name
+func (r *SavedCartRepository) Create(ctx context.Context, cart *models.SavedCart) error {
+	err := r.db.WithContext(ctx).Create(cart).Error
+	if err != nil && !errors.Is(err, ErrAlreadyExists) {
+		r.log.Error("Failed to create saved cart", zap.String("user_id", cart.UserID), zap.Error(err))
+	}
+	return err
+}
+
+func (r *SavedCartRepository) Get(ctx context.Context, userID, id string) (*models.SavedCart, error) {
+	var cart models.SavedCart
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&cart).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSavedCartNotFound
+	}
+	return &cart, err
+}
+
+// GetByName returns the customer's saved cart with that name
+func (r *SavedCartRepository) GetByName(ctx context.Context, userID, name string) (*models.SavedCart, error) {
+	var cart models.SavedCart
+	err := r.db.WithContext(ctx).Where("user_id = ? AND name = ?", userID, name).First(&cart).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSavedCartNotFound
+	}
+	return &cart, err
+}
+
+// List returns a customer's saved carts by name
+func (r *SavedCartRepository) List(ctx context.Context, userID string) ([]models.SavedCart, error) {
+	var carts []models.SavedCart
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("name ASC").Find(&carts).Error
+	return carts, err
+}
+
+func (r *SavedCartRepository) Count(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.SavedCart{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Update replaces a saved cart's name and items
+// Returns: ErrAlreadyExists when the new name is taken
+func (r *SavedCartRepository) Update(ctx context.Context, cart *models.SavedCart) error {
+	err := r.db.WithContext(ctx).Model(cart).Select("name", "items", "updated_at").Updates(cart).Error
+	if err != nil && !errors.Is(err, ErrAlreadyExists) {
+		r.log.Error("Failed to update saved cart", zap.String("id", cart.ID), zap.Error(err))
+	}
+	return err
+}
+
+func (r *SavedCartRepository) Delete(ctx context.Context, userID, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.SavedCart{})
+	if result.Error != nil {
+		r.log.Error("Failed to delete saved cart", zap.String("id", id), zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSavedCartNotFound
+	}
+	return nil
+}
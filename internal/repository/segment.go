@@ -0,0 +1,375 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrSegmentNotFound is returned when a segment doesn't exist
+	ErrSegmentNotFound = errors.New("segment not found")
+	// ErrSegmentInUse is returned when deleting a segment a running or upcoming campaign targets
+	ErrSegmentInUse = errors.New("segment is used by a campaign")
+	// ErrSegmentEmailNotFound is returned when a segment email campaign doesn't exist
+	ErrSegmentEmailNotFound = errors.New("segment email not found")
+)
+
+// memberBatch bounds the rows written or deleted per statement when membership changes
+const memberBatch = 500
+
+type SegmentRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewSegmentRepository(db *gorm.DB, log *zap.Logger) *SegmentRepository {
+	return &SegmentRepository{db: db, log: log}
+}
+
+func (r *SegmentRepository) Create(ctx context.Context, segment *models.Segment) error {
+	err := r.db.WithContext(ctx).Create(segment).Error
+	if err != nil {
+		r.log.Error("Failed to create segment", zap.String("name", segment.Name), zap.Error(err))
+	}
+	return err
+}
+
+// Update saves a segment's name, description and rules; membership is left to ReplaceMembers
+func (r *SegmentRepository) Update(ctx context.Context, segment *models.Segment) error {
+	err := r.db.WithContext(ctx).Model(segment).Select("name", "description", "rules").Updates(segment).Error
+	if err != nil {
+		r.log.Error("Failed to update segment", zap.String("id", segment.ID), zap.Error(err))
+	}
+	return err
+}
+
+func (r *SegmentRepository) GetByID(ctx context.Context, id string) (*models.Segment, error) {
+	var segment models.Segment
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&segment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSegmentNotFound
+		}
+		return nil, err
+	}
+	return &segment, nil
+}
+
+func (r *SegmentRepository) GetByName(ctx context.Context, name string) (*models.Segment, error) {
+	var segment models.Segment
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&segment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSegmentNotFound
+		}
+		return nil, err
+	}
+	return &segment, nil
+}
+
+// List returns a page of segments by name
+// Returns: page, total rows
+func (r *SegmentRepository) List(ctx context.Context, limit, offset int) ([]models.Segment, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Segment{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var segments []models.Segment
+	if err := query.Order("name ASC").Limit(limit).Offset(offset).Find(&segments).Error; err != nil {
+		r.log.Error("Failed to list segments", zap.Error(err))
+		return nil, 0, err
+	}
+	return segments, total, nil
+}
+
+// IDs returns the ID of every segment, for the periodic refresh
+func (r *SegmentRepository) IDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Model(&models.Segment{}).Order("name ASC").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Delete removes a segment with its members and email campaigns
+// Returns: ErrSegmentInUse while a campaign that hasn't ended at now targets it
+func (r *SegmentRepository) Delete(ctx context.Context, id string, now time.Time) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var campaigns int64
+		err := tx.Model(&models.Campaign{}).Where("segment_id = ? AND ends_at > ?", id, now).Count(&campaigns).Error
+		if err != nil {
+			return err
+		}
+		if campaigns > 0 {
+			return ErrSegmentInUse
+		}
+
+		result := tx.Where("id = ?", id).Delete(&models.Segment{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSegmentNotFound
+		}
+		if err := tx.Where("segment_id = ?", id).Delete(&models.SegmentMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("segment_id = ?", id).Delete(&models.SegmentEmail{}).Error
+	})
+	if err != nil && !errors.Is(err, ErrSegmentNotFound) && !errors.Is(err, ErrSegmentInUse) {
+		r.log.Error("Failed to delete segment", zap.String("id", id), zap.Error(err))
+	}
+	return err
+}
+
+// Match returns the IDs of the customers matching every rule at now
+// Rules must be validated first (see the segments service). Each rule becomes a correlated
+// subquery over customer_orders or the customer's default address, so evaluation is one query.
+func (r *SegmentRepository) Match(ctx context.Context, rules []models.SegmentRule, now time.Time) ([]string, error) {
+	query := r.db.WithContext(ctx).Model(&models.User{}).Where("users.role = ?", models.RoleCustomer)
+	for _, rule := range rules {
+		condition, args, err := ruleCondition(rule, now)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(condition, args...)
+	}
+
+	var ids []string
+	if err := query.Order("users.id ASC").Pluck("users.id", &ids).Error; err != nil {
+		r.log.Error("Failed to evaluate segment rules", zap.Error(err))
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ruleCondition translates one rule into a WHERE condition on users
+func ruleCondition(rule models.SegmentRule, now time.Time) (string, []interface{}, error) {
+	cmp := ">="
+	if rule.Op == models.SegmentOpLte {
+		cmp = "<="
+	}
+
+	switch rule.Field {
+	case models.SegmentFieldTotalSpend:
+		return "COALESCE((SELECT SUM(co.total - co.refunded) FROM customer_orders co " +
+			"WHERE co.user_id = users.id AND co.currency = ?), 0) " + cmp + " ?", []interface{}{rule.Currency, rule.Value}, nil
+	case models.SegmentFieldOrderCount:
+		return "(SELECT COUNT(*) FROM customer_orders co WHERE co.user_id = users.id) " + cmp + " ?",
+			[]interface{}{rule.Value}, nil
+	case models.SegmentFieldLastOrder:
+		cutoff := now.Add(-time.Duration(rule.Value) * 24 * time.Hour)
+		if rule.Op == models.SegmentOpLte {
+			return "EXISTS (SELECT 1 FROM customer_orders co WHERE co.user_id = users.id AND co.placed_at >= ?)",
+				[]interface{}{cutoff}, nil
+		}
+		lapsed := "EXISTS (SELECT 1 FROM customer_orders co WHERE co.user_id = users.id) AND " +
+			"NOT EXISTS (SELECT 1 FROM customer_orders co WHERE co.user_id = users.id AND co.placed_at > ?)"
+		return lapsed, []interface{}{cutoff}, nil
+	case models.SegmentFieldCountry, models.SegmentFieldRegion, models.SegmentFieldCity, models.SegmentFieldZone:
+		values := make([]string, len(rule.Values))
+		for i, v := range rule.Values {
+			values[i] = strings.ToLower(v)
+		}
+		// rule.Field is one of the constants above, so it is safe to use as the column name
+		condition := "EXISTS (SELECT 1 FROM addresses a WHERE a.user_id = users.id AND a.is_default = ? AND LOWER(a." +
+			rule.Field + ") IN ?)"
+		if rule.Op == models.SegmentOpNotIn {
+			condition = "NOT " + condition
+		}
+		return condition, []interface{}{true, values}, nil
+	}
+	return "", nil, fmt.Errorf("unknown segment field: %s", rule.Field)
+}
+
+// ReplaceMembers makes userIDs the segment's members, keeping the added_at of those already in
+// Concurrent refreshes of one segment are serialized by locking its row
+// Returns: members added and removed
+func (r *SegmentRepository) ReplaceMembers(ctx context.Context, segmentID string, userIDs []string, now time.Time) (int, int, error) {
+	added, removed := 0, 0
+	err := withRetry(ctx, func() error {
+		added, removed = 0, 0
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var segment models.Segment
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", segmentID).First(&segment).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSegmentNotFound
+			}
+			if err != nil {
+				return err
+			}
+
+			var current []string
+			if err := tx.Model(&models.SegmentMember{}).Where("segment_id = ?", segmentID).Pluck("user_id", &current).Error; err != nil {
+				return err
+			}
+			want := make(map[string]bool, len(userIDs))
+			for _, id := range userIDs {
+				want[id] = true
+			}
+			have := make(map[string]bool, len(current))
+			var gone []string
+			for _, id := range current {
+				have[id] = true
+				if !want[id] {
+					gone = append(gone, id)
+				}
+			}
+			var joined []models.SegmentMember
+			for _, id := range userIDs {
+				if !have[id] {
+					joined = append(joined, models.SegmentMember{SegmentID: segmentID, UserID: id, AddedAt: now})
+				}
+			}
+
+			for start := 0; start < len(gone); start += memberBatch {
+				end := min(start+memberBatch, len(gone))
+				err := tx.Where("segment_id = ? AND user_id IN ?", segmentID, gone[start:end]).Delete(&models.SegmentMember{}).Error
+				if err != nil {
+					return err
+				}
+			}
+			if len(joined) > 0 {
+				if err := tx.CreateInBatches(joined, memberBatch).Error; err != nil {
+					return err
+				}
+			}
+			added, removed = len(joined), len(gone)
+
+			return tx.Model(&models.Segment{}).Where("id = ?", segmentID).
+				Updates(map[string]interface{}{"members": len(userIDs), "evaluated_at": now}).Error
+		})
+	})
+	if err != nil && !errors.Is(err, ErrSegmentNotFound) {
+		r.log.Error("Failed to replace segment members", zap.String("segment_id", segmentID), zap.Error(err))
+	}
+	return added, removed, err
+}
+
+// Members returns a page of a segment's members with their contact details, longest-standing first
+// Returns: page, total members
+func (r *SegmentRepository) Members(ctx context.Context, segmentID string, limit, offset int) ([]models.SegmentMemberResponse, int64, error) {
+	query := r.db.WithContext(ctx).Table("segment_members").
+		Joins("JOIN users ON users.id = segment_members.user_id AND users.deleted_at IS NULL").
+		Where("segment_members.segment_id = ?", segmentID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var members []models.SegmentMemberResponse
+	err := query.Select("segment_members.user_id, users.email, users.first_name, users.last_name, segment_members.added_at").
+		Order("segment_members.added_at ASC, segment_members.user_id ASC").Limit(limit).Offset(offset).Scan(&members).Error
+	if err != nil {
+		r.log.Error("Failed to list segment members", zap.String("segment_id", segmentID), zap.Error(err))
+		return nil, 0, err
+	}
+	return members, total, nil
+}
+
+// MemberIDs returns up to limit member IDs after the given one, in ID order, for paging through
+// a whole segment
+func (r *SegmentRepository) MemberIDs(ctx context.Context, segmentID, after string, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Model(&models.SegmentMember{}).
+		Where("segment_id = ? AND user_id > ?", segmentID, after).
+		Order("user_id ASC").Limit(limit).Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// SegmentsOf returns the IDs of the segments userID is a member of
+func (r *SegmentRepository) SegmentsOf(ctx context.Context, userID string) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Model(&models.SegmentMember{}).Where("user_id = ?", userID).Pluck("segment_id", &ids).Error
+	return ids, err
+}
+
+// RecordOrder adds an order to the customer ledger; an order already recorded is left alone
+func (r *SegmentRepository) RecordOrder(ctx context.Context, order *models.CustomerOrder) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(order).Error
+	if err != nil {
+		r.log.Error("Failed to record customer order", zap.String("order_id", order.OrderID), zap.Error(err))
+	}
+	return err
+}
+
+// RecordRefund takes a refund off its order's spend, once per refund and never below zero
+// Returns: whether the refund applied (false when already recorded or the order is unknown)
+func (r *SegmentRepository) RecordRefund(ctx context.Context, refund *models.CustomerRefund) (bool, error) {
+	applied := false
+	err := withRetry(ctx, func() error {
+		applied = false
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var order models.CustomerOrder
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", refund.OrderID).First(&order).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil // placed before the ledger existed
+			}
+			if err != nil {
+				return err
+			}
+
+			record := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(refund)
+			if record.Error != nil || record.RowsAffected == 0 {
+				return record.Error
+			}
+			applied = true
+			return tx.Model(&order).Update("refunded", min(order.Refunded+refund.Amount, order.Total)).Error
+		})
+	})
+	if err != nil {
+		r.log.Error("Failed to record customer refund", zap.String("refund_id", refund.RefundID), zap.Error(err))
+	}
+	return applied, err
+}
+
+func (r *SegmentRepository) CreateEmail(ctx context.Context, email *models.SegmentEmail) error {
+	err := r.db.WithContext(ctx).Create(email).Error
+	if err != nil {
+		r.log.Error("Failed to create segment email", zap.String("segment_id", email.SegmentID), zap.Error(err))
+	}
+	return err
+}
+
+func (r *SegmentRepository) GetEmail(ctx context.Context, id string) (*models.SegmentEmail, error) {
+	var email models.SegmentEmail
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&email).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSegmentEmailNotFound
+		}
+		return nil, err
+	}
+	return &email, nil
+}
+
+// ListEmails returns a page of a segment's email campaigns, newest first
+// Returns: page, total rows
+func (r *SegmentRepository) ListEmails(ctx context.Context, segmentID string, limit, offset int) ([]models.SegmentEmail, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.SegmentEmail{}).Where("segment_id = ?", segmentID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var emails []models.SegmentEmail
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&emails).Error; err != nil {
+		r.log.Error("Failed to list segment emails", zap.String("segment_id", segmentID), zap.Error(err))
+		return nil, 0, err
+	}
+	return emails, total, nil
+}
+
+// SaveEmailProgress records how far sending got: status, counters, cursor and sent_at
+func (r *SegmentRepository) SaveEmailProgress(ctx context.Context, email *models.SegmentEmail) error {
+	return r.db.WithContext(ctx).Model(email).
+		Select("status", "recipients", "failed", "cursor", "sent_at").Updates(email).Error
+}
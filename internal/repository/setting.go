@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SettingRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewSettingRepository(db *gorm.DB, log *zap.Logger) *SettingRepository {
+	return &SettingRepository{db: db, log: log}
+}
+
+// List returns every stored setting (settings still at their default have no row)
+func (r *SettingRepository) List(ctx context.Context) ([]models.Setting, error) {
+	var settings []models.Setting
+	err := r.db.WithContext(ctx).Order(clause.OrderByColumn{Column: clause.Column{Name: "key"}}).Find(&settings).Error
+	return settings, err
+}
+
+// Save upserts set and deletes the rows of reset in one transaction
+func (r *SettingRepository) Save(ctx context.Context, set []models.Setting, reset []string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(set) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "key"}},
+				DoUpdates: clause.AssignmentColumns([]string{"value", "updated_by", "updated_at"}),
+			}).Create(&set).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(reset) > 0 {
+			values := make([]interface{}, len(reset))
+			for i, key := range reset {
+				values[i] = key
+			}
+			// key is reserved in MySQL; clauses make GORM quote it
+			return tx.Where(clause.IN{Column: clause.Column{Name: "key"}, Values: values}).
+				Delete(&models.Setting{}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		r.log.Error("Failed to save settings", zap.Error(err))
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrShipmentNotFound is returned when a shipment doesn't exist (or isn't visible to the caller)
+var ErrShipmentNotFound = errors.New("shipment not found")
+
+type ShipmentRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewShipmentRepository(db *gorm.DB, log *zap.Logger) *ShipmentRepository {
+	return &ShipmentRepository{db: db, log: log}
+}
+
+func (r *ShipmentRepository) Create(ctx context.Context, shipment *models.Shipment) error {
+	if err := r.db.WithContext(ctx).Create(shipment).Error; err != nil {
+		r.log.Error("Failed to create shipment", zap.String("order_id", shipment.OrderID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetByID loads a shipment
+func (r *ShipmentRepository) GetByID(ctx context.Context, id string) (*models.Shipment, error) {
+	shipment := &models.Shipment{}
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(shipment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrShipmentNotFound
+	}
+	return shipment, err
+}
+
+// GetByTracking finds the shipment a carrier webhook refers to
+func (r *ShipmentRepository) GetByTracking(ctx context.Context, carrier, trackingNumber string) (*models.Shipment, error) {
+	shipment := &models.Shipment{}
+	err := r.db.WithContext(ctx).
+		Where("carrier = ? AND tracking_number = ?", carrier, trackingNumber).
+		First(shipment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrShipmentNotFound
+	}
+	return shipment, err
+}
+
+// ListByOrder returns an order's shipments with their tracking history, oldest event first
+// userID restricts results to the order owner; empty means any owner (admin)
+func (r *ShipmentRepository) ListByOrder(ctx context.Context, orderID, userID string) ([]models.Shipment, error) {
+	query := r.db.WithContext(ctx).
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("occurred_at ASC") }).
+		Where("order_id = ?", orderID)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var shipments []models.Shipment
+	err := query.Order("created_at ASC").Find(&shipments).Error
+	return shipments, err
+}
+
+// RecordEvent stores a tracking event and saves the shipment's (possibly advanced) status atomically
+// Returns: false if the event was already recorded (webhook redelivery) - the shipment is left untouched
+func (r *ShipmentRepository) RecordEvent(ctx context.Context, shipment *models.Shipment, event *models.ShipmentEvent) (bool, error) {
+	recorded := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		event.ShipmentID = shipment.ID
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		recorded = true
+		return tx.Model(shipment).Select("status", "shipped_at", "delivered_at").Updates(shipment).Error
+	})
+	return recorded, err
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrStockSubscriptionNotFound is returned when the user has no subscription to the product
+var ErrStockSubscriptionNotFound = errors.New("stock subscription not found")
+
+type StockSubscriptionRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewStockSubscriptionRepository(db *gorm.DB, log *zap.Logger) *StockSubscriptionRepository {
+	return &StockSubscriptionRepository{db: db, log: log}
+}
+
+// Get returns userID's subscription to productID, active or not
+func (r *StockSubscriptionRepository) Get(ctx context.Context, userID, productID string) (*models.StockSubscription, error) {
+	sub := &models.StockSubscription{}
+	err := r.db.WithContext(ctx).Where("user_id = ? AND product_id = ?", userID, productID).First(sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrStockSubscriptionNotFound
+	}
+	return sub, err
+}
+
+func (r *StockSubscriptionRepository) Create(ctx context.Context, sub *models.StockSubscription) error {
+	if err := r.db.WithContext(ctx).Create(sub).Error; err != nil {
+		r.log.Error("Failed to create stock subscription", zap.String("product_id", sub.ProductID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Reactivate makes a notified subscription wait for the next restock, as if created at
+func (r *StockSubscriptionRepository) Reactivate(ctx context.Context, sub *models.StockSubscription, at time.Time) error {
+	err := r.db.WithContext(ctx).Model(sub).Updates(map[string]interface{}{"notified_at": nil, "created_at": at}).Error
+	if err != nil {
+		r.log.Error("Failed to reactivate stock subscription", zap.String("id", sub.ID), zap.Error(err))
+		return err
+	}
+	sub.NotifiedAt = nil
+	sub.CreatedAt = at
+	return nil
+}
+
+// Delete removes userID's subscription to productID
+// Returns: ErrStockSubscriptionNotFound if there is none
+func (r *StockSubscriptionRepository) Delete(ctx context.Context, userID, productID string) error {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND product_id = ?", userID, productID).
+		Delete(&models.StockSubscription{})
+	if result.Error != nil {
+		r.log.Error("Failed to delete stock subscription", zap.String("product_id", productID), zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStockSubscriptionNotFound
+	}
+	return nil
+}
+
+// DeleteByProduct removes every subscription to a product that no longer exists
+func (r *StockSubscriptionRepository) DeleteByProduct(ctx context.Context, productID string) (int64, error) {
+	result := r.db.WithContext(ctx).Where("product_id = ?", productID).Delete(&models.StockSubscription{})
+	return result.RowsAffected, result.Error
+}
+
+// ListByUser returns a page of userID's subscriptions, newest first
+// status filters to models.StockSubscriptionActive or StockSubscriptionNotified; empty means both
+// Returns: page, total matching rows
+func (r *StockSubscriptionRepository) ListByUser(ctx context.Context, userID, status string,
+	limit, offset int) ([]models.StockSubscription, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.StockSubscription{}).Where("user_id = ?", userID)
+	switch status {
+	case models.StockSubscriptionActive:
+		query = query.Where("notified_at IS NULL")
+	case models.StockSubscriptionNotified:
+		query = query.Where("notified_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var subs []models.StockSubscription
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&subs).Error; err != nil {
+		r.log.Error("Failed to list stock subscriptions", zap.String("user_id", userID), zap.Error(err))
+		return nil, 0, err
+	}
+	return subs, total, nil
+}
+
+// ListWaiting returns up to limit active subscriptions to productID, oldest first
+func (r *StockSubscriptionRepository) ListWaiting(ctx context.Context, productID string, limit int) ([]models.StockSubscription, error) {
+	var subs []models.StockSubscription
+	err := r.db.WithContext(ctx).
+		Where("product_id = ? AND notified_at IS NULL", productID).
+		Order("created_at ASC").Limit(limit).Find(&subs).Error
+	return subs, err
+}
+
+// CountWaiting returns how many active subscriptions productID has
+func (r *StockSubscriptionRepository) CountWaiting(ctx context.Context, productID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.StockSubscription{}).
+		Where("product_id = ? AND notified_at IS NULL", productID).Count(&count).Error
+	return count, err
+}
+
+// MarkNotified claims an active subscription for its alert
+// Returns: false if it was already notified (or removed), e.g. by a concurrent job
+func (r *StockSubscriptionRepository) MarkNotified(ctx context.Context, id string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.StockSubscription{}).
+		Where("id = ? AND notified_at IS NULL", id).
+		Update("notified_at", at)
+	if result.Error != nil {
+		r.log.Error("Failed to mark stock subscription notified", zap.String("id", id), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Unmark puts a claimed subscription back after its alert could not be delivered
+func (r *StockSubscriptionRepository) Unmark(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&models.StockSubscription{}).
+		Where("id = ?", id).Update("notified_at", nil).Error
+}
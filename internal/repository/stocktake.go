@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrStocktakeNotFound is returned when a stocktake doesn't exist
+	ErrStocktakeNotFound = errors.New("stocktake not found")
+	// ErrStocktakeClosed is returned when counting, completing or cancelling a stocktake that isn't open
+	ErrStocktakeClosed = errors.New("stocktake is not open")
+)
+
+// StockAdjustmentFilter narrows the stock audit log; empty fields match everything
+type StockAdjustmentFilter struct {
+	ProductID   string
+	WarehouseID string
+	Reason      string
+	StocktakeID string
+}
+
+// StocktakeRepository keeps the stock audit log and stocktakes
+// Every change it makes to stock is written to the log in the same transaction
+type StocktakeRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewStocktakeRepository(db *gorm.DB, log *zap.Logger) *StocktakeRepository {
+	return &StocktakeRepository{db: db, log: log}
+}
+
+// Adjust applies adjustment.Delta and logs it, in one transaction
+// With a warehouse, its stock and the product's total move together; without one, only the
+// product's unassigned stock changes, so removals can't take units a warehouse holds.
+// QuantityBefore and QuantityAfter are set from the stock as changed.
+// Returns: ErrInsufficientStock when the units aren't there
+func (r *StocktakeRepository) Adjust(ctx context.Context, adjustment *models.StockAdjustment) error {
+	err := withRetry(ctx, func() error {
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			after, err := adjustStock(tx, adjustment.WarehouseID, adjustment.ProductID, adjustment.Delta)
+			if err != nil {
+				return err
+			}
+			adjustment.ID = ""
+			adjustment.QuantityBefore = after - adjustment.Delta
+			adjustment.QuantityAfter = after
+			return tx.Create(adjustment).Error
+		})
+	})
+	if err != nil && !errors.Is(err, ErrInsufficientStock) {
+		r.log.Error("Failed to adjust stock", zap.String("product_id", adjustment.ProductID),
+			zap.String("warehouse_id", adjustment.WarehouseID), zap.Int("delta", adjustment.Delta), zap.Error(err))
+	}
+	return err
+}
+
+// adjustStock moves stock by delta and returns the quantity after: the warehouse's, or the product's total
+func adjustStock(tx *gorm.DB, warehouseID, productID string, delta int) (int, error) {
+	if warehouseID != "" {
+		if err := moveStock(tx, warehouseID, productID, delta); err != nil {
+			return 0, err
+		}
+		if err := adjustProductStock(tx, productID, delta); err != nil {
+			return 0, err
+		}
+		return warehouseQuantity(tx, warehouseID, productID)
+	}
+
+	if err := adjustProductStock(tx, productID, delta); err != nil {
+		return 0, err
+	}
+	var product models.Product
+	if err := tx.Select("stock").Where("id = ?", productID).First(&product).Error; err != nil {
+		return 0, err
+	}
+	if delta < 0 {
+		// The warehouses may hold at most the product's total
+		var held int64
+		err := tx.Model(&models.WarehouseStock{}).Where("product_id = ?", productID).
+			Select("COALESCE(SUM(quantity), 0)").Scan(&held).Error
+		if err != nil {
+			return 0, err
+		}
+		if held > int64(product.Stock) {
+			return 0, ErrInsufficientStock
+		}
+	}
+	return product.Stock, nil
+}
+
+func warehouseQuantity(tx *gorm.DB, warehouseID, productID string) (int, error) {
+	var quantity int
+	err := tx.Model(&models.WarehouseStock{}).Where("warehouse_id = ? AND product_id = ?", warehouseID, productID).
+		Select("COALESCE(MAX(quantity), 0)").Scan(&quantity).Error
+	return quantity, err
+}
+
+// ListAdjustments returns a page of the stock audit log, newest first
+// Returns: page, total matching rows
+func (r *StocktakeRepository) ListAdjustments(ctx context.Context, filter StockAdjustmentFilter,
+	limit, offset int) ([]models.StockAdjustment, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.StockAdjustment{})
+	if filter.ProductID != "" {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.WarehouseID != "" {
+		query = query.Where("warehouse_id = ?", filter.WarehouseID)
+	}
+	if filter.Reason != "" {
+		query = query.Where("reason = ?", filter.Reason)
+	}
+	if filter.StocktakeID != "" {
+		query = query.Where("stocktake_id = ?", filter.StocktakeID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var adjustments []models.StockAdjustment
+	err := query.Order("created_at DESC, id ASC").Limit(limit).Offset(offset).Find(&adjustments).Error
+	return adjustments, total, err
+}
+
+// Create starts a stocktake of productIDs, or of every product the warehouse holds when empty,
+// with the system quantities as of now
+func (r *StocktakeRepository) Create(ctx context.Context, stocktake *models.Stocktake, productIDs []string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("warehouse_id = ?", stocktake.WarehouseID)
+		if len(productIDs) > 0 {
+			query = query.Where("product_id IN ?", productIDs)
+		} else {
+			query = query.Where("quantity > 0")
+		}
+		var rows []models.WarehouseStock
+		if err := query.Find(&rows).Error; err != nil {
+			return err
+		}
+		held := make(map[string]int, len(rows))
+		for _, row := range rows {
+			held[row.ProductID] = row.Quantity
+		}
+		if len(productIDs) == 0 {
+			for _, row := range rows {
+				productIDs = append(productIDs, row.ProductID)
+			}
+		}
+
+		stocktake.Lines = make([]models.StocktakeLine, 0, len(productIDs))
+		for _, id := range productIDs {
+			stocktake.Lines = append(stocktake.Lines, models.StocktakeLine{ProductID: id, SystemQuantity: held[id]})
+		}
+		return tx.Create(stocktake).Error
+	})
+	if err != nil {
+		r.log.Error("Failed to create stocktake", zap.String("warehouse_id", stocktake.WarehouseID), zap.Error(err))
+	}
+	return err
+}
+
+// Get loads a stocktake with its lines
+func (r *StocktakeRepository) Get(ctx context.Context, id string) (*models.Stocktake, error) {
+	var stocktake models.Stocktake
+	err := r.db.WithContext(ctx).Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("product_id ASC")
+	}).Where("id = ?", id).First(&stocktake).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrStocktakeNotFound
+	}
+	return &stocktake, err
+}
+
+// List returns a page of stocktakes without their lines, newest first
+// Returns: page, total matching rows
+func (r *StocktakeRepository) List(ctx context.Context, warehouseID, status string, limit, offset int) ([]models.Stocktake, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Stocktake{})
+	if warehouseID != "" {
+		query = query.Where("warehouse_id = ?", warehouseID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var stocktakes []models.Stocktake
+	err := query.Order("created_at DESC, id ASC").Limit(limit).Offset(offset).Find(&stocktakes).Error
+	return stocktakes, total, err
+}
+
+// RecordCounts saves counted quantities against what the warehouse holds right now
+// Returns: ErrStocktakeClosed when the stocktake isn't open
+func (r *StocktakeRepository) RecordCounts(ctx context.Context, stocktake *models.Stocktake, counts []models.StocktakeCount, at time.Time) error {
+	err := withRetry(ctx, func() error {
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := claimOpen(tx, stocktake.ID, map[string]interface{}{"updated_at": at}); err != nil {
+				return err
+			}
+			for _, count := range counts {
+				held, err := warehouseQuantity(tx, stocktake.WarehouseID, count.ProductID)
+				if err != nil {
+					return err
+				}
+				counted := count.Counted
+				line := models.StocktakeLine{
+					StocktakeID:    stocktake.ID,
+					ProductID:      count.ProductID,
+					SystemQuantity: held,
+					Counted:        &counted,
+					Variance:       counted - held,
+					CountedAt:      &at,
+				}
+				err = tx.Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "stocktake_id"}, {Name: "product_id"}},
+					DoUpdates: clause.AssignmentColumns([]string{"system_quantity", "counted", "variance", "counted_at"}),
+				}).Create(&line).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil && !errors.Is(err, ErrStocktakeClosed) {
+		r.log.Error("Failed to record stocktake counts", zap.String("id", stocktake.ID), zap.Error(err))
+	}
+	return err
+}
+
+// Complete closes a stocktake and books each counted variance into the warehouse, logged as a
+// stocktake adjustment, in one transaction
+// A shortfall larger than what the warehouse still holds (sold since the count) empties it;
+// products deleted since are skipped.
+// Returns: the adjustments made; ErrStocktakeClosed when the stocktake isn't open
+func (r *StocktakeRepository) Complete(ctx context.Context, stocktake *models.Stocktake, adminID string, at time.Time) ([]models.StockAdjustment, error) {
+	var adjustments []models.StockAdjustment
+	err := withRetry(ctx, func() error {
+		adjustments = nil
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			fields := map[string]interface{}{"status": models.StocktakeCompleted, "completed_by": adminID, "completed_at": at}
+			if err := claimOpen(tx, stocktake.ID, fields); err != nil {
+				return err
+			}
+
+			var lines []models.StocktakeLine
+			err := tx.Where("stocktake_id = ? AND counted IS NOT NULL AND variance <> 0", stocktake.ID).
+				Order("product_id ASC").Find(&lines).Error
+			if err != nil || len(lines) == 0 {
+				return err
+			}
+			productIDs := make([]string, 0, len(lines))
+			for _, line := range lines {
+				productIDs = append(productIDs, line.ProductID)
+			}
+			var existing []string
+			if err := tx.Model(&models.Product{}).Where("id IN ?", productIDs).Pluck("id", &existing).Error; err != nil {
+				return err
+			}
+			live := make(map[string]bool, len(existing))
+			for _, id := range existing {
+				live[id] = true
+			}
+
+			for _, line := range lines {
+				if !live[line.ProductID] {
+					continue
+				}
+				held, err := warehouseQuantity(tx, stocktake.WarehouseID, line.ProductID)
+				if err != nil {
+					return err
+				}
+				delta := max(line.Variance, -held)
+				if delta == 0 {
+					continue
+				}
+				after, err := adjustStock(tx, stocktake.WarehouseID, line.ProductID, delta)
+				if err != nil {
+					return err
+				}
+				adjustment := models.StockAdjustment{
+					ProductID:      line.ProductID,
+					WarehouseID:    stocktake.WarehouseID,
+					Reason:         models.AdjustStocktake,
+					Delta:          delta,
+					QuantityBefore: after - delta,
+					QuantityAfter:  after,
+					StocktakeID:    stocktake.ID,
+					CreatedBy:      adminID,
+				}
+				if err := tx.Create(&adjustment).Error; err != nil {
+					return err
+				}
+				adjustments = append(adjustments, adjustment)
+			}
+			return nil
+		})
+	})
+	if err != nil && !errors.Is(err, ErrStocktakeClosed) {
+		r.log.Error("Failed to complete stocktake", zap.String("id", stocktake.ID), zap.Error(err))
+	}
+	return adjustments, err
+}
+
+// Cancel closes an open stocktake without touching stock
+// Returns: ErrStocktakeClosed when it isn't open
+func (r *StocktakeRepository) Cancel(ctx context.Context, id string, at time.Time) error {
+	return claimOpen(r.db.WithContext(ctx), id, map[string]interface{}{"status": models.StocktakeCancelled, "updated_at": at})
+}
+
+// claimOpen updates fields of a stocktake only while it is open; inside a transaction this also
+// holds the row, so concurrent counts and completion queue behind each other
+func claimOpen(tx *gorm.DB, id string, fields map[string]interface{}) error {
+	result := tx.Model(&models.Stocktake{}).Where("id = ? AND status = ?", id, models.StocktakeOpen).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStocktakeClosed
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+// Store interfaces describe the data access services depend on
+// Implemented by the GORM repositories in this package and by the map-backed
+// fakes in internal/repository/memory, so service logic can run without a database
+
+// UserStore is implemented by UserRepository
+type UserStore interface {
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	GetUsersByPhone(ctx context.Context, phone string) ([]models.User, error)
+	GetUserByID(ctx context.Context, id string) (*models.User, error)
+	GetUsersByIDs(ctx context.Context, ids []string) ([]models.User, error)
+	UpdateUser(ctx context.Context, user *models.User) error
+	ListUsersAfter(ctx context.Context, afterID string, limit int) ([]models.User, error)
+	EachUser(ctx context.Context, batchSize int, fn func([]models.User) error) error
+	UpdateFields(ctx context.Context, id string, fields map[string]interface{}) error
+}
+
+// AddressStore is implemented by AddressRepository
+type AddressStore interface {
+	Create(ctx context.Context, addr *models.Address) error
+	ListByUser(ctx context.Context, userID string) ([]models.Address, error)
+	GetByID(ctx context.Context, id, userID string) (*models.Address, error)
+	CountByUser(ctx context.Context, userID string) (int64, error)
+	Delete(ctx context.Context, id, userID string) error
+}
+
+// ProductStore is implemented by ProductRepository
+type ProductStore interface {
+	Create(ctx context.Context, product *models.Product) error
+	Update(ctx context.Context, product *models.Product) error
+	AdjustStock(ctx context.Context, id string, delta int) error
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*models.Product, error)
+	GetByIDs(ctx context.Context, ids []string) ([]models.Product, error)
+	ListAfter(ctx context.Context, afterID string, limit int) ([]models.Product, error)
+	Each(ctx context.Context, batchSize int, fn func([]models.Product) error) error
+	ListInCategoryAfter(ctx context.Context, category, afterID string, limit int) ([]models.Product, error)
+	CountInCategory(ctx context.Context, category string) (int64, error)
+	Reprice(ctx context.Context, id string, price int64, unchangedSince time.Time) (bool, error)
+	Deactivate(ctx context.Context, id string) (bool, error)
+	ListChangedSince(ctx context.Context, since time.Time) ([]models.Product, error)
+	Search(ctx context.Context, query, category string, limit, offset int) ([]models.Product, int64, error)
+	ListByVendor(ctx context.Context, vendorID string, limit, offset int) ([]models.Product, int64, error)
+	CategoryCounts(ctx context.Context) ([]models.CategoryCount, error)
+}
+
+var (
+	_ UserStore    = (*UserRepository)(nil)
+	_ AddressStore = (*AddressRepository)(nil)
+	_ ProductStore = (*ProductRepository)(nil)
+)
@@ -0,0 +1,38 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/repository/storetest"
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func newDB(t *testing.T, models ...interface{}) *gorm.DB {
+	db := testutil.NewDB(t, models...)
+	if err := repository.RegisterErrorTranslation(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestUserStore(t *testing.T) {
+	storetest.RunUserStore(t, func(t *testing.T) repository.UserStore {
+		return repository.NewUserRepository(newDB(t, &models.User{}), zap.NewNop())
+	})
+}
+
+func TestAddressStore(t *testing.T) {
+	storetest.RunAddressStore(t, func(t *testing.T) repository.AddressStore {
+		return repository.NewAddressRepository(newDB(t, &models.Address{}), zap.NewNop())
+	})
+}
+
+func TestProductStore(t *testing.T) {
+	storetest.RunProductStore(t, func(t *testing.T) repository.ProductStore {
+		return repository.NewProductRepository(newDB(t, &models.Product{}), zap.NewNop())
+	})
+}
@@ -0,0 +1,666 @@
+// Package storetest is the contract every implementation of the repository store interfaces
+// must satisfy. The GORM repositories (on SQLite) and the in-memory fakes in
+// internal/repository/memory both run it, so service tests written against the fakes see the
+// same not-found errors, unique constraints, soft deletes and orderings as production:
+//
+//	func TestUserStore(t *testing.T) {
+//		storetest.RunUserStore(t, func(t *testing.T) repository.UserStore {
+//			return memory.NewUserRepository()
+//		})
+//	}
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+)
+
+// tick separates timestamps the contract orders by; the GORM models keep milliseconds
+const tick = 5 * time.Millisecond
+
+type contract[S any] struct {
+	name string
+	run  func(t *testing.T, store S)
+}
+
+func run[S any](t *testing.T, newStore func(t *testing.T) S, tests []contract[S]) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newStore(t))
+		})
+	}
+}
+
+// RunUserStore runs the UserStore contract as subtests; newStore must return an empty store
+// and is called once per subtest
+func RunUserStore(t *testing.T, newStore func(t *testing.T) repository.UserStore) {
+	run(t, newStore, []contract[repository.UserStore]{
+		{"CreateSetsIDAndDefaults", testCreateUser},
+		{"UniqueEmail", testUniqueEmail},
+		{"UniqueUsername", testUniqueUsername},
+		{"NotFound", testUserNotFound},
+		{"GetUsersByPhone", testGetUsersByPhone},
+		{"GetUsersByIDs", testGetUsersByIDs},
+		{"UpdateUser", testUpdateUser},
+		{"UpdateFields", testUpdateUserFields},
+		{"ListUsersAfter", testListUsersAfter},
+		{"EachUser", testEachUser},
+	})
+}
+
+// RunAddressStore runs the AddressStore contract as subtests; see RunUserStore
+func RunAddressStore(t *testing.T, newStore func(t *testing.T) repository.AddressStore) {
+	run(t, newStore, []contract[repository.AddressStore]{
+		{"DefaultFirstThenNewest", testAddressOrder},
+		{"OwnedByUser", testAddressOwnership},
+		{"Delete", testDeleteAddress},
+	})
+}
+
+// RunProductStore runs the ProductStore contract as subtests; see RunUserStore
+func RunProductStore(t *testing.T, newStore func(t *testing.T) repository.ProductStore) {
+	run(t, newStore, []contract[repository.ProductStore]{
+		{"UniqueSKU", testUniqueSKU},
+		{"UpdateKeepsStock", testUpdateKeepsStock},
+		{"AdjustStock", testAdjustStock},
+		{"SoftDelete", testSoftDeleteProduct},
+		{"ListAfterActiveOnly", testListProductsAfter},
+		{"Each", testEachProduct},
+		{"Category", testProductCategory},
+		{"Reprice", testReprice},
+		{"Deactivate", testDeactivate},
+		{"Search", testSearch},
+		{"ListByVendor", testListByVendor},
+	})
+}
+
+func createUser(t *testing.T, store repository.UserStore, email string) *models.User {
+	t.Helper()
+	user := &models.User{Email: email, PasswordHash: "hash", FirstName: "Test"}
+	if err := store.CreateUser(context.Background(), user); err != nil {
+		t.Fatalf("CreateUser(%s): %v", email, err)
+	}
+	return user
+}
+
+func testCreateUser(t *testing.T, store repository.UserStore) {
+	user := createUser(t, store, "ada@example.com")
+	if user.ID == "" || user.Role != models.RoleCustomer || user.CreatedAt.IsZero() {
+		t.Fatalf("created user = id %q, role %q, created %v; want an ID, the customer role and a timestamp",
+			user.ID, user.Role, user.CreatedAt)
+	}
+
+	got, err := store.GetUserByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID: %v", err)
+	}
+	if got.Email != user.Email || got.Role != models.RoleCustomer {
+		t.Fatalf("GetUserByID = %s/%s, want %s/%s", got.Email, got.Role, user.Email, models.RoleCustomer)
+	}
+	// Stored rows are not the caller's: changing what came back doesn't change the store
+	got.Email = "changed@example.com"
+	if again, _ := store.GetUserByEmail(context.Background(), user.Email); again == nil {
+		t.Fatal("changing a returned user changed the stored one")
+	}
+}
+
+func testUniqueEmail(t *testing.T, store repository.UserStore) {
+	createUser(t, store, "ada@example.com")
+	err := store.CreateUser(context.Background(), &models.User{Email: "ada@example.com", PasswordHash: "hash"})
+	if !errors.Is(err, repository.ErrAlreadyExists) {
+		t.Fatalf("CreateUser with a taken email: err = %v, want ErrAlreadyExists", err)
+	}
+}
+
+func testUniqueUsername(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	// Users without a username never collide
+	createUser(t, store, "a@example.com")
+	createUser(t, store, "b@example.com")
+
+	name := "ada"
+	if err := store.CreateUser(ctx, &models.User{Email: "c@example.com", PasswordHash: "hash", Username: &name}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	taken := "ada"
+	err := store.CreateUser(ctx, &models.User{Email: "d@example.com", PasswordHash: "hash", Username: &taken})
+	if !errors.Is(err, repository.ErrAlreadyExists) {
+		t.Fatalf("CreateUser with a taken username: err = %v, want ErrAlreadyExists", err)
+	}
+
+	got, err := store.GetUserByUsername(ctx, "ada")
+	if err != nil || got.Email != "c@example.com" {
+		t.Fatalf("GetUserByUsername = %v, %v; want c@example.com", got, err)
+	}
+}
+
+func testUserNotFound(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	createUser(t, store, "ada@example.com")
+
+	if _, err := store.GetUserByID(ctx, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("GetUserByID(unknown): err = %v, want ErrUserNotFound", err)
+	}
+	if _, err := store.GetUserByEmail(ctx, "nobody@example.com"); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("GetUserByEmail(unknown): err = %v, want ErrUserNotFound", err)
+	}
+	if _, err := store.GetUserByUsername(ctx, "nobody"); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("GetUserByUsername(unknown): err = %v, want ErrUserNotFound", err)
+	}
+}
+
+func testGetUsersByPhone(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	var want []string
+	for i := 0; i < 3; i++ {
+		user := &models.User{Email: fmt.Sprintf("u%d@example.com", i), PasswordHash: "hash", Phone: "+254700000001"}
+		if err := store.CreateUser(ctx, user); err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		want = append(want, user.ID)
+		time.Sleep(tick)
+	}
+	createUser(t, store, "other@example.com")
+
+	users, err := store.GetUsersByPhone(ctx, "+254700000001")
+	if err != nil {
+		t.Fatalf("GetUsersByPhone: %v", err)
+	}
+	if got := ids(users); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("GetUsersByPhone = %v, want %v (oldest first)", got, want)
+	}
+	if users, err := store.GetUsersByPhone(ctx, "+254700000009"); err != nil || len(users) != 0 {
+		t.Fatalf("GetUsersByPhone(unknown) = %d users, %v; want none", len(users), err)
+	}
+}
+
+func testGetUsersByIDs(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	a := createUser(t, store, "a@example.com")
+	b := createUser(t, store, "b@example.com")
+	createUser(t, store, "c@example.com")
+
+	users, err := store.GetUsersByIDs(ctx, []string{a.ID, "00000000-0000-0000-0000-000000000000", b.ID})
+	if err != nil {
+		t.Fatalf("GetUsersByIDs: %v", err)
+	}
+	if got := set(ids(users)); len(got) != 2 || !got[a.ID] || !got[b.ID] {
+		t.Fatalf("GetUsersByIDs = %v, want %s and %s", ids(users), a.ID, b.ID)
+	}
+	if users, err := store.GetUsersByIDs(ctx, nil); err != nil || len(users) != 0 {
+		t.Fatalf("GetUsersByIDs(nil) = %d users, %v; want none", len(users), err)
+	}
+}
+
+func testUpdateUser(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	user := createUser(t, store, "ada@example.com")
+	createUser(t, store, "taken@example.com")
+
+	user.FirstName = "Ada"
+	if err := store.UpdateUser(ctx, user); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if got, err := store.GetUserByID(ctx, user.ID); err != nil || got.FirstName != "Ada" {
+		t.Fatalf("GetUserByID after UpdateUser = %v, %v; want first name Ada", got, err)
+	}
+
+	user.Email = "taken@example.com"
+	if err := store.UpdateUser(ctx, user); !errors.Is(err, repository.ErrAlreadyExists) {
+		t.Fatalf("UpdateUser to a taken email: err = %v, want ErrAlreadyExists", err)
+	}
+}
+
+func testUpdateUserFields(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	user := createUser(t, store, "ada@example.com")
+	createUser(t, store, "taken@example.com")
+
+	if err := store.UpdateFields(ctx, user.ID, map[string]interface{}{"first_name": "Ada", "locale": "sw"}); err != nil {
+		t.Fatalf("UpdateFields: %v", err)
+	}
+	got, err := store.GetUserByID(ctx, user.ID)
+	if err != nil || got.FirstName != "Ada" || got.Locale != "sw" || got.Email != user.Email {
+		t.Fatalf("GetUserByID after UpdateFields = %+v, %v; want Ada/sw with the email unchanged", got, err)
+	}
+
+	err = store.UpdateFields(ctx, user.ID, map[string]interface{}{"email": "taken@example.com"})
+	if !errors.Is(err, repository.ErrAlreadyExists) {
+		t.Fatalf("UpdateFields to a taken email: err = %v, want ErrAlreadyExists", err)
+	}
+	// Updating a user that isn't there changes nothing and isn't an error
+	if err := store.UpdateFields(ctx, "00000000-0000-0000-0000-000000000000", map[string]interface{}{"first_name": "X"}); err != nil {
+		t.Fatalf("UpdateFields(unknown): %v", err)
+	}
+}
+
+func testListUsersAfter(t *testing.T, store repository.UserStore) {
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		createUser(t, store, fmt.Sprintf("u%d@example.com", i))
+	}
+
+	var walked []string
+	after := ""
+	for {
+		page, err := store.ListUsersAfter(ctx, after, 2)
+		if err != nil {
+			t.Fatalf("ListUsersAfter: %v", err)
+		}
+		if len(page) > 2 {
+			t.Fatalf("ListUsersAfter returned %d users, limit 2", len(page))
+		}
+		if len(page) == 0 {
+			break
+		}
+		walked = append(walked, ids(page)...)
+		after = page[len(page)-1].ID
+	}
+	if !ascending(walked) || len(walked) != 5 {
+		t.Fatalf("walking ListUsersAfter = %v, want all 5 users in ID order", walked)
+	}
+}
+
+func testEachUser(t *testing.T, store repository.UserStore) {
+	for i := 0; i < 5; i++ {
+		createUser(t, store, fmt.Sprintf("u%d@example.com", i))
+	}
+	testEach(t, 5, func(ctx context.Context, fn func([]string) error) error {
+		return store.EachUser(ctx, 2, func(users []models.User) error { return fn(ids(users)) })
+	})
+}
+
+// testEach checks that each walks want rows in ID order in batches of 2, and stops once ctx is cancelled
+func testEach(t *testing.T, want int, each func(ctx context.Context, fn func([]string) error) error) {
+	t.Helper()
+	var walked []string
+	batches := 0
+	err := each(context.Background(), func(batch []string) error {
+		batches++
+		walked = append(walked, batch...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("each: %v", err)
+	}
+	if len(walked) != want || !ascending(walked) || batches != (want+1)/2 {
+		t.Fatalf("each walked %v in %d batches, want %d rows in ID order in batches of 2", walked, batches, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	batches = 0
+	err = each(ctx, func([]string) error {
+		batches++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || batches != 1 {
+		t.Fatalf("each after cancel = %v after %d batches, want context.Canceled after 1", err, batches)
+	}
+}
+
+func createAddress(t *testing.T, store repository.AddressStore, userID, label string, isDefault bool) *models.Address {
+	t.Helper()
+	addr := &models.Address{UserID: userID, Label: label, Line1: "Kenyatta Avenue 1", City: "Nairobi", Country: "KE", IsDefault: isDefault}
+	if err := store.Create(context.Background(), addr); err != nil {
+		t.Fatalf("Create(%s): %v", label, err)
+	}
+	time.Sleep(tick)
+	return addr
+}
+
+func testAddressOrder(t *testing.T, store repository.AddressStore) {
+	ctx := context.Background()
+	createAddress(t, store, "user-1", "home", true)
+	createAddress(t, store, "user-1", "office", false)
+	createAddress(t, store, "user-1", "gym", true) // takes the default from home
+	createAddress(t, store, "user-1", "cabin", false)
+	createAddress(t, store, "user-2", "elsewhere", true)
+
+	addresses, err := store.ListByUser(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	var labels []string
+	defaults := 0
+	for _, addr := range addresses {
+		labels = append(labels, addr.Label)
+		if addr.IsDefault {
+			defaults++
+		}
+	}
+	if want := "[gym cabin office home]"; fmt.Sprint(labels) != want || defaults != 1 {
+		t.Fatalf("ListByUser = %v with %d defaults, want %s with 1", labels, defaults, want)
+	}
+	if n, err := store.CountByUser(ctx, "user-1"); err != nil || n != 4 {
+		t.Fatalf("CountByUser = %d, %v; want 4", n, err)
+	}
+}
+
+func testAddressOwnership(t *testing.T, store repository.AddressStore) {
+	ctx := context.Background()
+	addr := createAddress(t, store, "user-1", "home", true)
+
+	if got, err := store.GetByID(ctx, addr.ID, "user-1"); err != nil || got.Label != "home" {
+		t.Fatalf("GetByID = %v, %v; want home", got, err)
+	}
+	if _, err := store.GetByID(ctx, addr.ID, "user-2"); !errors.Is(err, repository.ErrAddressNotFound) {
+		t.Fatalf("GetByID of another user's address: err = %v, want ErrAddressNotFound", err)
+	}
+	if err := store.Delete(ctx, addr.ID, "user-2"); !errors.Is(err, repository.ErrAddressNotFound) {
+		t.Fatalf("Delete of another user's address: err = %v, want ErrAddressNotFound", err)
+	}
+}
+
+func testDeleteAddress(t *testing.T, store repository.AddressStore) {
+	ctx := context.Background()
+	addr := createAddress(t, store, "user-1", "home", true)
+
+	if err := store.Delete(ctx, addr.ID, "user-1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.GetByID(ctx, addr.ID, "user-1"); !errors.Is(err, repository.ErrAddressNotFound) {
+		t.Fatalf("GetByID after Delete: err = %v, want ErrAddressNotFound", err)
+	}
+	if err := store.Delete(ctx, addr.ID, "user-1"); !errors.Is(err, repository.ErrAddressNotFound) {
+		t.Fatalf("second Delete: err = %v, want ErrAddressNotFound", err)
+	}
+	if n, err := store.CountByUser(ctx, "user-1"); err != nil || n != 0 {
+		t.Fatalf("CountByUser after Delete = %d, %v; want 0", n, err)
+	}
+}
+
+func createProduct(t *testing.T, store repository.ProductStore, product models.Product) *models.Product {
+	t.Helper()
+	if product.Name == "" {
+		product.Name = product.SKU
+	}
+	product.Currency = "KES"
+	if err := store.Create(context.Background(), &product); err != nil {
+		t.Fatalf("Create(%s): %v", product.SKU, err)
+	}
+	return &product
+}
+
+func testUniqueSKU(t *testing.T, store repository.ProductStore) {
+	createProduct(t, store, models.Product{SKU: "TEA", Price: 500, Active: true})
+	err := store.Create(context.Background(), &models.Product{SKU: "TEA", Name: "Tea", Price: 500, Currency: "KES"})
+	if !errors.Is(err, repository.ErrAlreadyExists) {
+		t.Fatalf("Create with a taken SKU: err = %v, want ErrAlreadyExists", err)
+	}
+}
+
+func testUpdateKeepsStock(t *testing.T, store repository.ProductStore) {
+	ctx := context.Background()
+	product := createProduct(t, store, models.Product{SKU: "TEA", Price: 500, Stock: 10, Active: true})
+
+	// A stale copy must not undo a sale made since it was read
+	if err := store.AdjustStock(ctx, product.ID, -3); err != nil {
+		t.Fatalf("AdjustStock: %v", err)
+	}
+	product.Price = 600
+	if err := store.Update(ctx, product); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err := store.GetByID(ctx, product.ID)
+	if err != nil || got.Price != 600 || got.Stock != 7 {
+		t.Fatalf("GetByID after Update = %+v, %v; want price 600 and stock 7", got, err)
+	}
+}
+
+func testAdjustStock(t *testing.T, store repository.ProductStore) {
+	ctx := context.Background()
+	product := createProduct(t, store, models.Product{SKU: "TEA", Price: 500, Stock: 5, Active: true})
+
+	if err := store.AdjustStock(ctx, product.ID, 3); err != nil {
+		t.Fatalf("AdjustStock(+3): %v", err)
+	}
+	if err := store.AdjustStock(ctx, product.ID, -8); err != nil {
+		t.Fatalf("AdjustStock(-8): %v", err)
+	}
+	if err := store.AdjustStock(ctx, product.ID, -1); !errors.Is(err, repository.ErrInsufficientStock) {
+		t.Fatalf("AdjustStock below zero: err = %v, want ErrInsufficientStock", err)
+	}
+	if got, err := store.GetByID(ctx, product.ID); err != nil || got.Stock != 0 {
+		t.Fatalf("GetByID = %v, %v; want stock 0", got, err)
+	}
+	if err := store.AdjustStock(ctx, "00000000-0000-0000-0000-000000000000", 1); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Fatalf("AdjustStock(unknown): err = %v, want ErrProductNotFound", err)
+	}
+}
+
+func testSoftDeleteProduct(t *testing.T, store repository.ProductStore) {
+	ctx := context.Background()
+	gone := createProduct(t, store, models.Product{SKU: "GONE", Price: 500, Active: true})
+	kept := createProduct(t, store, models.Product{SKU: "KEPT", Price: 500, Active: true})
+	time.Sleep(tick)
+	since := time.Now()
+	time.Sleep(tick)
+
+	if err := store.Delete(ctx, gone.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.GetByID(ctx, gone.ID); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Fatalf("GetByID after Delete: err = %v, want ErrProductNotFound", err)
+	}
+	if err := store.Delete(ctx, gone.ID); !errors.Is(err, repository.ErrProductNotFound) {
+		t.Fatalf("second Delete: err = %v, want ErrProductNotFound", err)
+	}
+	if products, err := store.GetByIDs(ctx, []string{gone.ID, kept.ID}); err != nil || len(products) != 1 || products[0].ID != kept.ID {
+		t.Fatalf("GetByIDs after Delete = %v, %v; want only %s", ids(products), err, kept.ID)
+	}
+	// The SKU index covers deleted products, and exports still see the deletion
+	err := store.Create(ctx, &models.Product{SKU: "GONE", Name: "Again", Price: 500, Currency: "KES"})
+	if !errors.Is(err, repository.ErrAlreadyExists) {
+		t.Fatalf("Create with a deleted product's SKU: err = %v, want ErrAlreadyExists", err)
+	}
+	changed, err := store.ListChangedSince(ctx, since)
+	if err != nil || len(changed) != 1 || changed[0].ID != gone.ID || !changed[0].DeletedAt.Valid {
+		t.Fatalf("ListChangedSince = %v, %v; want only the deleted %s", ids(changed), err, gone.ID)
+	}
+}
+
+func testListProductsAfter(t *testing.T, store repository.ProductStore) {
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		createProduct(t, store, models.Product{SKU: fmt.Sprintf("P%d", i), Price: 100, Active: i != 2})
+	}
+	deleted := createProduct(t, store, models.Product{SKU: "DELETED", Price: 100, Active: true})
+	if err := store.Delete(ctx, deleted.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	var walked []string
+	after := ""
+	for {
+		page, err := store.ListAfter(ctx, after, 2)
+		if err != nil {
+			t.Fatalf("ListAfter: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, p := range page {
+			if !p.Active {
+				t.Fatalf("ListAfter returned inactive %s", p.SKU)
+			}
+		}
+		walked = append(walked, ids(page)...)
+		after = page[len(page)-1].ID
+	}
+	if len(walked) != 4 || !ascending(walked) {
+		t.Fatalf("walking ListAfter = %v, want the 4 active products in ID order", walked)
+	}
+}
+
+func testEachProduct(t *testing.T, store repository.ProductStore) {
+	for i := 0; i < 4; i++ {
+		createProduct(t, store, models.Product{SKU: fmt.Sprintf("P%d", i), Price: 100, Active: i != 0})
+	}
+	deleted := createProduct(t, store, models.Product{SKU: "DELETED", Price: 100, Active: true})
+	if err := store.Delete(context.Background(), deleted.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	// Inactive products are exported too; deleted ones aren't
+	testEach(t, 4, func(ctx context.Context, fn func([]string) error) error {
+		return store.Each(ctx, 2, func(products []models.Product) error { return fn(ids(products)) })
+	})
+}
+
+func testProductCategory(t *testing.T, store repository.ProductStore) {
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		createProduct(t, store, models.Product{SKU: fmt.Sprintf("TEA%d", i), Category: "tea", Price: 100, Active: i != 0})
+	}
+	createProduct(t, store, models.Product{SKU: "MUG", Category: "home", Price: 100, Active: true})
+	createProduct(t, store, models.Product{SKU: "MISC", Price: 100, Active: true})
+	deleted := createProduct(t, store, models.Product{SKU: "TEA-OLD", Category: "tea", Price: 100, Active: true})
+	if err := store.Delete(ctx, deleted.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if n, err := store.CountInCategory(ctx, "tea"); err != nil || n != 3 {
+		t.Fatalf("CountInCategory(tea) = %d, %v; want 3 (inactive included, deleted not)", n, err)
+	}
+	first, err := store.ListInCategoryAfter(ctx, "tea", "", 2)
+	if err != nil || len(first) != 2 {
+		t.Fatalf("ListInCategoryAfter = %d products, %v; want 2", len(first), err)
+	}
+	rest, err := store.ListInCategoryAfter(ctx, "tea", first[1].ID, 2)
+	if err != nil || len(rest) != 1 || !ascending(append(ids(first), ids(rest)...)) {
+		t.Fatalf("ListInCategoryAfter pages = %v then %v, %v; want 3 products in ID order", ids(first), ids(rest), err)
+	}
+
+	counts, err := store.CategoryCounts(ctx)
+	if err != nil {
+		t.Fatalf("CategoryCounts: %v", err)
+	}
+	if got := fmt.Sprint(counts); got != "[{home 1} {tea 2}]" {
+		t.Fatalf("CategoryCounts = %s, want [{home 1} {tea 2}] (active products with a category, by name)", got)
+	}
+}
+
+func testReprice(t *testing.T, store repository.ProductStore) {
+	ctx := context.Background()
+	created := createProduct(t, store, models.Product{SKU: "TEA", Price: 500, Active: true})
+	product, err := store.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+
+	if ok, err := store.Reprice(ctx, product.ID, 700, product.UpdatedAt.Add(-time.Second)); err != nil || ok {
+		t.Fatalf("Reprice of a product changed since = %v, %v; want false", ok, err)
+	}
+	if ok, err := store.Reprice(ctx, product.ID, 700, product.UpdatedAt); err != nil || !ok {
+		t.Fatalf("Reprice = %v, %v; want true", ok, err)
+	}
+	if got, err := store.GetByID(ctx, product.ID); err != nil || got.Price != 700 {
+		t.Fatalf("GetByID after Reprice = %v, %v; want price 700", got, err)
+	}
+	if ok, err := store.Reprice(ctx, "00000000-0000-0000-0000-000000000000", 700, time.Now()); err != nil || ok {
+		t.Fatalf("Reprice(unknown) = %v, %v; want false", ok, err)
+	}
+}
+
+func testDeactivate(t *testing.T, store repository.ProductStore) {
+	ctx := context.Background()
+	product := createProduct(t, store, models.Product{SKU: "TEA", Price: 500, Active: true})
+
+	if ok, err := store.Deactivate(ctx, product.ID); err != nil || !ok {
+		t.Fatalf("Deactivate = %v, %v; want true", ok, err)
+	}
+	if ok, err := store.Deactivate(ctx, product.ID); err != nil || ok {
+		t.Fatalf("second Deactivate = %v, %v; want false", ok, err)
+	}
+	if got, err := store.GetByID(ctx, product.ID); err != nil || got.Active {
+		t.Fatalf("GetByID after Deactivate = %v, %v; want inactive", got, err)
+	}
+	if ok, err := store.Deactivate(ctx, "00000000-0000-0000-0000-000000000000"); err != nil || ok {
+		t.Fatalf("Deactivate(unknown) = %v, %v; want false", ok, err)
+	}
+}
+
+func testSearch(t *testing.T, store repository.ProductStore) {
+	ctx := context.Background()
+	createProduct(t, store, models.Product{SKU: "TEA-1", Name: "Green Tea", Category: "drinks", Price: 100, Active: true})
+	createProduct(t, store, models.Product{SKU: "TEA-2", Name: "Black Tea", Category: "drinks", Price: 100, Active: true})
+	createProduct(t, store, models.Product{SKU: "MUG", Name: "Mug", Description: "For your TEA", Category: "home", Price: 100, Active: true})
+	createProduct(t, store, models.Product{SKU: "TEA-3", Name: "Old Tea", Category: "drinks", Price: 100})
+	createProduct(t, store, models.Product{SKU: "COFFEE", Name: "Coffee", Category: "drinks", Price: 100, Active: true})
+
+	tests := []struct {
+		query, category string
+		limit, offset   int
+		want            string
+		total           int64
+	}{
+		{"tea", "", 10, 0, "[Black Tea Green Tea Mug]", 3},
+		{"TEA-2", "", 10, 0, "[Black Tea]", 1},
+		{"tea", "drinks", 10, 0, "[Black Tea Green Tea]", 2},
+		{"tea", "", 2, 1, "[Green Tea Mug]", 3},
+		{"tea", "", 10, 5, "[]", 3},
+		{"", "drinks", 10, 0, "[Black Tea Coffee Green Tea]", 3},
+	}
+	for _, tt := range tests {
+		products, total, err := store.Search(ctx, tt.query, tt.category, tt.limit, tt.offset)
+		if err != nil {
+			t.Fatalf("Search(%q, %q): %v", tt.query, tt.category, err)
+		}
+		var names []string
+		for _, p := range products {
+			names = append(names, p.Name)
+		}
+		if got := fmt.Sprint(names); got != tt.want || total != tt.total {
+			t.Errorf("Search(%q, %q, %d, %d) = %s of %d, want %s of %d",
+				tt.query, tt.category, tt.limit, tt.offset, got, total, tt.want, tt.total)
+		}
+	}
+}
+
+func testListByVendor(t *testing.T, store repository.ProductStore) {
+	ctx := context.Background()
+	createProduct(t, store, models.Product{SKU: "B", Name: "Basket", VendorID: "vendor-1", Price: 100})
+	createProduct(t, store, models.Product{SKU: "A", Name: "Apron", VendorID: "vendor-1", Price: 100, Active: true})
+	createProduct(t, store, models.Product{SKU: "C", Name: "Candle", VendorID: "vendor-2", Price: 100, Active: true})
+
+	products, total, err := store.ListByVendor(ctx, "vendor-1", 1, 1)
+	if err != nil || total != 2 || len(products) != 1 || products[0].Name != "Basket" {
+		t.Fatalf("ListByVendor page 2 = %v of %d, %v; want [Basket] of 2 (inactive included, by name)", ids(products), total, err)
+	}
+}
+
+func ids[T models.User | models.Product](rows []T) []string {
+	out := make([]string, 0, len(rows))
+	for _, row := range rows {
+		switch row := any(row).(type) {
+		case models.User:
+			out = append(out, row.ID)
+		case models.Product:
+			out = append(out, row.ID)
+		}
+	}
+	return out
+}
+
+func set(values []string) map[string]bool {
+	out := make(map[string]bool, len(values))
+	for _, v := range values {
+		out[v] = true
+	}
+	return out
+}
+
+func ascending(values []string) bool {
+	for i := 1; i < len(values); i++ {
+		if values[i-1] >= values[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,361 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrSupplierNotFound is returned when a supplier doesn't exist
+	ErrSupplierNotFound = errors.New("supplier not found")
+	// ErrSupplierProductNotFound is returned when a supplier has no terms for a product
+	ErrSupplierProductNotFound = errors.New("supplier product not found")
+	// ErrPurchaseOrderNotFound is returned when a purchase order doesn't exist
+	ErrPurchaseOrderNotFound = errors.New("purchase order not found")
+	// ErrNotReceivable is returned by Receive when the order isn't open for receipts, or a line
+	// would receive more than was ordered
+	ErrNotReceivable = errors.New("purchase order can't receive these units")
+	// ErrReorderRuleNotFound is returned when a product has no reorder point
+	ErrReorderRuleNotFound = errors.New("reorder rule not found")
+)
+
+// leadTimeSample is how many of a supplier's latest received orders their actual lead time averages
+const leadTimeSample = 50
+
+// PurchaseOrderFilter narrows purchase order listings; empty fields match everything
+type PurchaseOrderFilter struct {
+	SupplierID string
+	Status     string
+}
+
+type SupplierRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewSupplierRepository(db *gorm.DB, log *zap.Logger) *SupplierRepository {
+	return &SupplierRepository{db: db, log: log}
+}
+
+func (r *SupplierRepository) Create(ctx context.Context, supplier *models.Supplier) error {
+	err := r.db.WithContext(ctx).Create(supplier).Error
+	if err != nil {
+		r.log.Error("Failed to create supplier", zap.String("name", supplier.Name), zap.Error(err))
+	}
+	return err
+}
+
+// Save writes every field of an existing supplier
+func (r *SupplierRepository) Save(ctx context.Context, supplier *models.Supplier) error {
+	err := r.db.WithContext(ctx).Save(supplier).Error
+	if err != nil {
+		r.log.Error("Failed to update supplier", zap.String("id", supplier.ID), zap.Error(err))
+	}
+	return err
+}
+
+func (r *SupplierRepository) GetByID(ctx context.Context, id string) (*models.Supplier, error) {
+	var supplier models.Supplier
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&supplier).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSupplierNotFound
+	}
+	return &supplier, err
+}
+
+// List returns suppliers by name; activeOnly skips inactive ones
+func (r *SupplierRepository) List(ctx context.Context, activeOnly bool) ([]models.Supplier, error) {
+	query := r.db.WithContext(ctx).Order("name ASC, id ASC")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	var suppliers []models.Supplier
+	err := query.Find(&suppliers).Error
+	return suppliers, err
+}
+
+// LeadTimes returns how long the supplier's latest fully received orders took, newest first
+func (r *SupplierRepository) LeadTimes(ctx context.Context, supplierID string) ([]time.Duration, error) {
+	var orders []models.PurchaseOrder
+	err := r.db.WithContext(ctx).Select("ordered_at", "received_at").
+		Where("supplier_id = ? AND status = ? AND ordered_at IS NOT NULL AND received_at IS NOT NULL", supplierID, models.PurchaseOrderReceived).
+		Order("received_at DESC").Limit(leadTimeSample).Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	durations := make([]time.Duration, 0, len(orders))
+	for _, order := range orders {
+		durations = append(durations, order.ReceivedAt.Sub(*order.OrderedAt))
+	}
+	return durations, nil
+}
+
+// Products returns a supplier's terms for every product they supply
+func (r *SupplierRepository) Products(ctx context.Context, supplierID string) ([]models.SupplierProduct, error) {
+	var products []models.SupplierProduct
+	err := r.db.WithContext(ctx).Where("supplier_id = ?", supplierID).Order("product_id ASC").Find(&products).Error
+	return products, err
+}
+
+// ProductTerms returns a supplier's terms for the given products, by product ID
+func (r *SupplierRepository) ProductTerms(ctx context.Context, supplierID string, productIDs []string) (map[string]models.SupplierProduct, error) {
+	var rows []models.SupplierProduct
+	err := r.db.WithContext(ctx).Where("supplier_id = ? AND product_id IN ?", supplierID, productIDs).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	terms := make(map[string]models.SupplierProduct, len(rows))
+	for _, row := range rows {
+		terms[row.ProductID] = row
+	}
+	return terms, nil
+}
+
+// SetProduct creates or replaces a supplier's terms for a product
+func (r *SupplierRepository) SetProduct(ctx context.Context, product *models.SupplierProduct) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "supplier_id"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"supplier_sku", "unit_cost", "lead_time_days", "updated_at"}),
+	}).Create(product).Error
+	if err != nil {
+		r.log.Error("Failed to set supplier product", zap.String("supplier_id", product.SupplierID),
+			zap.String("product_id", product.ProductID), zap.Error(err))
+	}
+	return err
+}
+
+func (r *SupplierRepository) DeleteProduct(ctx context.Context, supplierID, productID string) error {
+	result := r.db.WithContext(ctx).Where("supplier_id = ? AND product_id = ?", supplierID, productID).Delete(&models.SupplierProduct{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSupplierProductNotFound
+	}
+	return nil
+}
+
+// DeleteProductEverywhere removes a deleted product from every supplier's list and drops its reorder point
+// Purchase orders keep their lines as a record of what was bought
+func (r *SupplierRepository) DeleteProductEverywhere(ctx context.Context, productID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productID).Delete(&models.SupplierProduct{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("product_id = ?", productID).Delete(&models.ReorderRule{}).Error
+	})
+}
+
+func (r *SupplierRepository) GetReorderRule(ctx context.Context, productID string) (*models.ReorderRule, error) {
+	var rule models.ReorderRule
+	err := r.db.WithContext(ctx).Where("product_id = ?", productID).First(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReorderRuleNotFound
+	}
+	return &rule, err
+}
+
+// SetReorderRule creates or replaces a product's reorder point
+// A pending alert is kept, so changing the point of a low product doesn't alert again
+func (r *SupplierRepository) SetReorderRule(ctx context.Context, rule *models.ReorderRule) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reorder_point", "reorder_quantity", "supplier_id", "auto_draft", "updated_at"}),
+	}).Create(rule).Error
+	if err != nil {
+		r.log.Error("Failed to set reorder rule", zap.String("product_id", rule.ProductID), zap.Error(err))
+	}
+	return err
+}
+
+func (r *SupplierRepository) DeleteReorderRule(ctx context.Context, productID string) error {
+	result := r.db.WithContext(ctx).Where("product_id = ?", productID).Delete(&models.ReorderRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrReorderRuleNotFound
+	}
+	return nil
+}
+
+// LowStock returns the products at or below their reorder point, by SKU, with the units on order
+func (r *SupplierRepository) LowStock(ctx context.Context) ([]models.LowStockItem, error) {
+	var items []models.LowStockItem
+	err := r.db.WithContext(ctx).Table("reorder_rules").
+		Select("reorder_rules.*, products.sku, products.name, products.vendor_id, products.stock").
+		Joins("JOIN products ON products.id = reorder_rules.product_id AND products.deleted_at IS NULL").
+		Where("products.stock <= reorder_rules.reorder_point").
+		Order("products.sku ASC").Scan(&items).Error
+	if err != nil || len(items) == 0 {
+		return items, err
+	}
+
+	productIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+	var rows []struct {
+		ProductID string
+		Units     int
+	}
+	err = r.db.WithContext(ctx).Table("purchase_order_lines").
+		Select("purchase_order_lines.product_id, SUM(purchase_order_lines.quantity - purchase_order_lines.received) AS units").
+		Joins("JOIN purchase_orders ON purchase_orders.id = purchase_order_lines.purchase_order_id").
+		Where("purchase_orders.status IN ? AND purchase_order_lines.product_id IN ?",
+			[]string{models.PurchaseOrderDraft, models.PurchaseOrderOrdered, models.PurchaseOrderPartial}, productIDs).
+		Group("purchase_order_lines.product_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	onOrder := make(map[string]int, len(rows))
+	for _, row := range rows {
+		onOrder[row.ProductID] = row.Units
+	}
+	for i := range items {
+		items[i].OnOrder = onOrder[items[i].ProductID]
+	}
+	return items, nil
+}
+
+// MarkAlerted records that the products' low stock was alerted
+func (r *SupplierRepository) MarkAlerted(ctx context.Context, productIDs []string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.ReorderRule{}).Where("product_id IN ?", productIDs).
+		Update("alerted_at", at).Error
+}
+
+// ClearRecoveredAlerts forgets the alerts of products back above their reorder point, so they are
+// alerted again the next time they run low
+func (r *SupplierRepository) ClearRecoveredAlerts(ctx context.Context) error {
+	return r.db.WithContext(ctx).Model(&models.ReorderRule{}).
+		Where("alerted_at IS NOT NULL AND reorder_point < (SELECT stock FROM products WHERE products.id = reorder_rules.product_id)").
+		Update("alerted_at", nil).Error
+}
+
+// CreatePurchaseOrder inserts a purchase order with its lines
+func (r *SupplierRepository) CreatePurchaseOrder(ctx context.Context, order *models.PurchaseOrder) error {
+	err := r.db.WithContext(ctx).Create(order).Error
+	if err != nil {
+		r.log.Error("Failed to create purchase order", zap.String("supplier_id", order.SupplierID), zap.Error(err))
+	}
+	return err
+}
+
+// GetPurchaseOrder loads a purchase order with its lines
+func (r *SupplierRepository) GetPurchaseOrder(ctx context.Context, id string) (*models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+	err := r.db.WithContext(ctx).Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("product_id ASC")
+	}).Where("id = ?", id).First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPurchaseOrderNotFound
+	}
+	return &order, err
+}
+
+// ListPurchaseOrders returns a page of purchase orders with their lines, newest first
+// Returns: page, total matching rows
+func (r *SupplierRepository) ListPurchaseOrders(ctx context.Context, filter PurchaseOrderFilter,
+	limit, offset int) ([]models.PurchaseOrder, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.PurchaseOrder{})
+	if filter.SupplierID != "" {
+		query = query.Where("supplier_id = ?", filter.SupplierID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var orders []models.PurchaseOrder
+	err := query.Preload("Lines").Order("created_at DESC, id ASC").Limit(limit).Offset(offset).Find(&orders).Error
+	return orders, total, err
+}
+
+// UpdatePurchaseOrder saves changed fields of a purchase order if it is in one of the states from
+// Returns: false when the order moved on meanwhile (or doesn't exist)
+func (r *SupplierRepository) UpdatePurchaseOrder(ctx context.Context, id string, from []string, fields map[string]interface{}) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.PurchaseOrder{}).Where("id = ? AND status IN ?", id, from).Updates(fields)
+	if result.Error != nil {
+		r.log.Error("Failed to update purchase order", zap.String("id", id), zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Receive books units of an ordered purchase order into stock, in one transaction
+// Each unit goes to the order's warehouse (and the product's total), or to the product's
+// unassigned stock without one. The supplier's unit cost for each product follows the receipt,
+// and the order becomes received once every line is complete.
+// Returns: ErrNotReceivable when the order isn't open for receipts or a line would be over-received
+func (r *SupplierRepository) Receive(ctx context.Context, order *models.PurchaseOrder, items []models.AllocationItem, at time.Time) error {
+	costs := make(map[string]int64, len(order.Lines))
+	for _, line := range order.Lines {
+		costs[line.ProductID] = line.UnitCost
+	}
+
+	err := withRetry(ctx, func() error {
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			// Claims the order for this receipt; concurrent receipts queue on the row
+			open := []string{models.PurchaseOrderOrdered, models.PurchaseOrderPartial}
+			result := tx.Model(&models.PurchaseOrder{}).Where("id = ? AND status IN ?", order.ID, open).Update("updated_at", at)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrNotReceivable
+			}
+
+			for _, item := range items {
+				result := tx.Model(&models.PurchaseOrderLine{}).
+					Where("purchase_order_id = ? AND product_id = ? AND received + ? <= quantity", order.ID, item.ProductID, item.Quantity).
+					Update("received", gorm.Expr("received + ?", item.Quantity))
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected == 0 {
+					return ErrNotReceivable
+				}
+
+				if order.WarehouseID != "" {
+					if err := moveStock(tx, order.WarehouseID, item.ProductID, item.Quantity); err != nil {
+						return err
+					}
+				}
+				if err := adjustProductStock(tx, item.ProductID, item.Quantity); err != nil {
+					return err
+				}
+
+				err := tx.Model(&models.SupplierProduct{}).Where("supplier_id = ? AND product_id = ?", order.SupplierID, item.ProductID).
+					Update("unit_cost", costs[item.ProductID]).Error
+				if err != nil {
+					return err
+				}
+			}
+
+			var outstanding int64
+			err := tx.Model(&models.PurchaseOrderLine{}).Where("purchase_order_id = ?", order.ID).
+				Select("COALESCE(SUM(quantity - received), 0)").Scan(&outstanding).Error
+			if err != nil {
+				return err
+			}
+			fields := map[string]interface{}{"status": models.PurchaseOrderPartial}
+			if outstanding == 0 {
+				fields = map[string]interface{}{"status": models.PurchaseOrderReceived, "received_at": at}
+			}
+			return tx.Model(&models.PurchaseOrder{}).Where("id = ?", order.ID).Updates(fields).Error
+		})
+	})
+	if err != nil && !errors.Is(err, ErrNotReceivable) {
+		r.log.Error("Failed to receive purchase order", zap.String("id", order.ID), zap.Error(err))
+	}
+	return err
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTaxProfileNotFound is returned when a customer has no tax profile
+var ErrTaxProfileNotFound = errors.New("tax profile not found")
+
+// TaxProfileRepository keeps customers' VAT numbers and tax exemptions
+type TaxProfileRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewTaxProfileRepository(db *gorm.DB, log *zap.Logger) *TaxProfileRepository {
+	return &TaxProfileRepository{db: db, log: log}
+}
+
+func (r *TaxProfileRepository) Get(ctx context.Context, userID string) (*models.TaxProfile, error) {
+	var profile models.TaxProfile
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTaxProfileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Save creates or replaces a customer's tax profile
+func (r *TaxProfileRepository) Save(ctx context.Context, profile *models.TaxProfile) error {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		UpdateAll: true,
+	}).Create(profile).Error
+	if err != nil {
+		r.log.Error("Failed to save tax profile", zap.String("user_id", profile.UserID), zap.Error(err))
+	}
+	return err
+}
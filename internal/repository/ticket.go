@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrTicketNotFound is returned when a support ticket doesn't exist
+var ErrTicketNotFound = errors.New("ticket not found")
+
+// TicketFilter narrows a ticket listing; empty fields match everything
+type TicketFilter struct {
+	UserID  string
+	Status  string
+	OrderID string
+}
+
+type TicketRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewTicketRepository(db *gorm.DB, log *zap.Logger) *TicketRepository {
+	return &TicketRepository{db: db, log: log}
+}
+
+// Create saves a ticket and its first message in one transaction
+func (r *TicketRepository) Create(ctx context.Context, ticket *models.Ticket, message *models.TicketMessage) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Messages").Create(ticket).Error; err != nil {
+			return err
+		}
+		message.TicketID = ticket.ID
+		return tx.Create(message).Error
+	})
+	if err != nil {
+		r.log.Error("Failed to create ticket", zap.String("user_id", ticket.UserID), zap.Error(err))
+	}
+	return err
+}
+
+// Get returns a ticket with its messages, oldest first
+func (r *TicketRepository) Get(ctx context.Context, id string) (*models.Ticket, error) {
+	var ticket models.Ticket
+	err := r.db.WithContext(ctx).Preload("Messages", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	}).Where("id = ?", id).First(&ticket).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTicketNotFound
+	}
+	return &ticket, err
+}
+
+// List returns a page of tickets without their messages, most recent activity first
+// Returns: page, total matching rows
+func (r *TicketRepository) List(ctx context.Context, filter TicketFilter, limit, offset int) ([]models.Ticket, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Ticket{})
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.OrderID != "" {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var tickets []models.Ticket
+	err := query.Order("last_message_at DESC, id ASC").Limit(limit).Offset(offset).Find(&tickets).Error
+	return tickets, total, err
+}
+
+// AddMessage appends message to its ticket and moves the ticket to status
+func (r *TicketRepository) AddMessage(ctx context.Context, message *models.TicketMessage, status string, at time.Time) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.Ticket{}).Where("id = ?", message.TicketID).
+			Updates(map[string]interface{}{"status": status, "last_message_at": at, "closed_at": nil})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTicketNotFound
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrTicketNotFound) {
+		r.log.Error("Failed to add ticket message", zap.String("ticket_id", message.TicketID), zap.Error(err))
+	}
+	return err
+}
+
+// Close closes a ticket that isn't closed yet
+// Returns: false when it was already closed
+func (r *TicketRepository) Close(ctx context.Context, id string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Ticket{}).Where("id = ? AND status <> ?", id, models.TicketClosed).
+		Updates(map[string]interface{}{"status": models.TicketClosed, "closed_at": at})
+	return result.RowsAffected > 0, result.Error
+}
@@ -9,6 +9,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrUserNotFound is returned when no user matches a lookup
+var ErrUserNotFound = errors.New("user not found")
+
 // UserRepository handles all user-related database operations
 // Repository pattern: abstracts data access logic with GORM
 // GORM provides database-agnostic queries - switch MySQL↔PostgreSQL seamlessly
@@ -49,7 +52,7 @@ func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*mod
 	if err := r.db.WithContext(ctx).Where("email = ?", email).First(user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			r.log.Warn("User not found", zap.String("email", email))
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		r.log.Error("Failed to fetch user", zap.String("email", email), zap.Error(err))
 		return nil, err
@@ -58,6 +61,33 @@ func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*mod
 	return user, nil
 }
 
+// GetUserByUsername retrieves a user by lower-case username
+// Returns: ErrUserNotFound if no user has it
+func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	user := &models.User{}
+
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		r.log.Error("Failed to fetch user", zap.String("username", username), zap.Error(err))
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// GetUsersByPhone returns the users with an E.164 phone number
+// A slice because uniqueness is optional (PHONE_UNIQUE); callers decide what several matches mean
+func (r *UserRepository) GetUsersByPhone(ctx context.Context, phone string) ([]models.User, error) {
+	var users []models.User
+	if err := r.db.WithContext(ctx).Where("phone = ?", phone).Order("created_at").Find(&users).Error; err != nil {
+		r.log.Error("Failed to fetch users by phone", zap.Error(err))
+		return nil, err
+	}
+	return users, nil
+}
+
 // GetUserByID retrieves a user from database by ID
 // Returns: user object if found, error if not found or query fails
 // Why here: ID-based lookup common in auth flows after token validation
@@ -67,7 +97,7 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*models.Us
 	if err := r.db.WithContext(ctx).Where("id = ?", id).First(user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			r.log.Warn("User not found", zap.String("id", id))
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		r.log.Error("Failed to fetch user", zap.String("id", id), zap.Error(err))
 		return nil, err
@@ -76,6 +106,43 @@ func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*models.Us
 	return user, nil
 }
 
+// GetUsersByIDs retrieves all users whose ID is in ids with a single IN query
+// Returns: found users in no particular order; unknown IDs are simply absent
+// Why here: lets listings resolve many profiles at once instead of one query per user
+func (r *UserRepository) GetUsersByIDs(ctx context.Context, ids []string) ([]models.User, error) {
+	var users []models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error; err != nil {
+		r.log.Error("Failed to fetch users", zap.Int("count", len(ids)), zap.Error(err))
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetUsersByRole retrieves every user with role, ordered by ID
+// Why here: staff notifications (e.g. low-stock alerts) go to every admin
+func (r *UserRepository) GetUsersByRole(ctx context.Context, role string) ([]models.User, error) {
+	var users []models.User
+	if err := r.db.WithContext(ctx).Where("role = ?", role).Order("id ASC").Find(&users).Error; err != nil {
+		r.log.Error("Failed to fetch users by role", zap.String("role", role), zap.Error(err))
+		return nil, err
+	}
+	return users, nil
+}
+
+// EachUser calls fn with batches of up to batchSize users, ordered by ID
+// Why here: exports walk the whole table without holding it in memory, and stop between batches
+// once the client is gone
+func (r *UserRepository) EachUser(ctx context.Context, batchSize int, fn func([]models.User) error) error {
+	err := eachBatch(ctx, r.db, batchSize, fn)
+	if err != nil && ctx.Err() == nil {
+		r.log.Error("Failed to iterate users", zap.Error(err))
+	}
+	return err
+}
+
 // UpdateUser updates an existing user
 // Returns: error if update fails
 // Why here: provides abstraction for user updates across different databases
@@ -88,3 +155,28 @@ func (r *UserRepository) UpdateUser(ctx context.Context, user *models.User) erro
 	r.log.Info("User updated successfully", zap.String("id", user.ID))
 	return nil
 }
+
+// ListUsersAfter returns up to limit users with ID greater than afterID, ordered by ID
+// Keyset pagination keeps batch jobs stable while rows are inserted concurrently
+func (r *UserRepository) ListUsersAfter(ctx context.Context, afterID string, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		r.log.Error("Failed to list users", zap.Error(err))
+	}
+	return users, err
+}
+
+// UpdateFields updates only the given columns of a user
+// Avoids Save overwriting concurrent changes to unrelated columns
+func (r *UserRepository) UpdateFields(ctx context.Context, id string, fields map[string]interface{}) error {
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Updates(fields).Error
+	if err != nil {
+		r.log.Error("Failed to update user", zap.String("id", id), zap.Error(err))
+	}
+	return err
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jason-Omondi/ecomgo/internal/loadgen"
+)
+
+// BenchmarkGetUserByEmail is the lookup behind every password sign-in
+func BenchmarkGetUserByEmail(b *testing.B) {
+	f := newBenchFixture(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.users.GetUserByEmail(ctx, loadgen.UserEmail(i%len(f.seeded.Users)+1)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,485 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrVendorNotFound is returned when a vendor doesn't exist
+	ErrVendorNotFound = errors.New("vendor not found")
+	// ErrNothingToPay is returned when a vendor has no delivered earnings left to pay out
+	ErrNothingToPay = errors.New("nothing to pay out")
+	// ErrPayoutNotFound is returned when a vendor payout doesn't exist
+	ErrPayoutNotFound = errors.New("payout not found")
+	// ErrPayoutBatchNotFound is returned when a payout batch doesn't exist
+	ErrPayoutBatchNotFound = errors.New("payout batch not found")
+)
+
+type VendorRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewVendorRepository(db *gorm.DB, log *zap.Logger) *VendorRepository {
+	return &VendorRepository{db: db, log: log}
+}
+
+// Create inserts a vendor and gives its user the vendor role in one transaction
+func (r *VendorRepository) Create(ctx context.Context, vendor *models.Vendor) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(vendor).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", vendor.UserID).Update("role", models.RoleVendor).Error
+	})
+	if err != nil {
+		r.log.Error("Failed to create vendor", zap.String("user_id", vendor.UserID), zap.Error(err))
+	}
+	return err
+}
+
+// Update saves a vendor's editable fields
+func (r *VendorRepository) Update(ctx context.Context, vendor *models.Vendor) error {
+	err := r.db.WithContext(ctx).Model(vendor).
+		Select("name", "email", "phone", "payout_account", "commission_bps", "status").
+		Updates(vendor).Error
+	if err != nil {
+		r.log.Error("Failed to update vendor", zap.String("id", vendor.ID), zap.Error(err))
+	}
+	return err
+}
+
+func (r *VendorRepository) GetByID(ctx context.Context, id string) (*models.Vendor, error) {
+	vendor := &models.Vendor{}
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(vendor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVendorNotFound
+	}
+	return vendor, err
+}
+
+// GetByUserID returns the vendor run by a user
+func (r *VendorRepository) GetByUserID(ctx context.Context, userID string) (*models.Vendor, error) {
+	vendor := &models.Vendor{}
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(vendor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVendorNotFound
+	}
+	return vendor, err
+}
+
+// GetByIDs returns the vendors whose ID is in ids; unknown IDs are absent
+func (r *VendorRepository) GetByIDs(ctx context.Context, ids []string) ([]models.Vendor, error) {
+	var vendors []models.Vendor
+	if len(ids) == 0 {
+		return vendors, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&vendors).Error
+	return vendors, err
+}
+
+// List returns a page of vendors by name; status filters when not empty
+// Returns: page, total matching rows
+func (r *VendorRepository) List(ctx context.Context, status string, limit, offset int) ([]models.Vendor, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Vendor{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var vendors []models.Vendor
+	err := query.Order("name ASC").Limit(limit).Offset(offset).Find(&vendors).Error
+	return vendors, total, err
+}
+
+// ProductOwners returns the vendor products among ids (ID, name and vendor only)
+// Soft-deleted products are included: a product removed after the order still earns its vendor
+func (r *VendorRepository) ProductOwners(ctx context.Context, ids []string) ([]models.Product, error) {
+	var products []models.Product
+	if len(ids) == 0 {
+		return products, nil
+	}
+	err := r.db.WithContext(ctx).Unscoped().Select("id", "name", "vendor_id").
+		Where("id IN ? AND vendor_id <> ''", ids).Find(&products).Error
+	return products, err
+}
+
+// RecordSales inserts order lines; lines already recorded (redelivered events) are skipped
+func (r *VendorRepository) RecordSales(ctx context.Context, sales []models.VendorSale) error {
+	if len(sales) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&sales).Error
+	if err != nil {
+		r.log.Error("Failed to record vendor sales", zap.String("order_id", sales[0].OrderID), zap.Error(err))
+	}
+	return err
+}
+
+// MarkDelivered makes an order's pending lines payable
+// Returns: lines changed
+func (r *VendorRepository) MarkDelivered(ctx context.Context, orderID string, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.VendorSale{}).
+		Where("order_id = ? AND status = ?", orderID, models.VendorSalePending).
+		Updates(map[string]interface{}{"status": models.VendorSaleDelivered, "delivered_at": at})
+	return result.RowsAffected, result.Error
+}
+
+// Refund takes refunded units of each product off the order's lines, in line order
+// Each refund applies once per line however often it is delivered
+// Returns: lines changed
+func (r *VendorRepository) Refund(ctx context.Context, refundID, orderID string, quantities map[string]int) (int, error) {
+	changed := 0
+	err := withRetry(ctx, func() error {
+		changed = 0
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var sales []models.VendorSale
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("order_id = ?", orderID).Order("line ASC").Find(&sales).Error
+			if err != nil {
+				return err
+			}
+
+			remaining := make(map[string]int, len(quantities))
+			for id, quantity := range quantities {
+				remaining[id] = quantity
+			}
+			for i := range sales {
+				sale := &sales[i]
+				take := min(remaining[sale.ProductID], sale.Quantity-sale.RefundedQuantity)
+				if take <= 0 {
+					continue
+				}
+				remaining[sale.ProductID] -= take
+
+				record := tx.Clauses(clause.OnConflict{DoNothing: true}).
+					Create(&models.VendorSaleRefund{RefundID: refundID, SaleID: sale.ID, Quantity: take})
+				if record.Error != nil {
+					return record.Error
+				}
+				if record.RowsAffected == 0 {
+					continue // already applied
+				}
+
+				sale.RefundedQuantity += take
+				sale.SetAmounts()
+				err := tx.Model(sale).Select("refunded_quantity", "gross", "commission", "net").Updates(sale).Error
+				if err != nil {
+					return err
+				}
+				changed++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		r.log.Error("Failed to apply refund to vendor sales", zap.String("refund_id", refundID), zap.Error(err))
+	}
+	return changed, err
+}
+
+// ListSales returns a page of a vendor's order lines, newest first; status filters when not empty
+// Returns: page, total matching rows
+func (r *VendorRepository) ListSales(ctx context.Context, vendorID, status string, limit, offset int) ([]models.VendorSale, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.VendorSale{}).Where("vendor_id = ?", vendorID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var sales []models.VendorSale
+	err := query.Order("created_at DESC, line ASC").Limit(limit).Offset(offset).Find(&sales).Error
+	return sales, total, err
+}
+
+// Balances totals a vendor's sales per currency; lines delivered by settledBefore count as settled
+func (r *VendorRepository) Balances(ctx context.Context, vendorID string, settledBefore time.Time) ([]models.VendorBalance, error) {
+	var rows []struct {
+		Currency   string
+		Status     string
+		Gross      int64
+		Commission int64
+		Net        int64
+		PaidNet    int64
+		Settled    int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.VendorSale{}).
+		Select("currency, status, SUM(gross) AS gross, SUM(commission) AS commission, SUM(net) AS net, SUM(paid_net) AS paid_net, "+
+			"SUM(CASE WHEN delivered_at <= ? THEN net - paid_net ELSE 0 END) AS settled", settledBefore).
+		Where("vendor_id = ?", vendorID).Group("currency, status").Order("currency ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	balances := []models.VendorBalance{}
+	for _, row := range rows {
+		if len(balances) == 0 || balances[len(balances)-1].Currency != row.Currency {
+			balances = append(balances, models.VendorBalance{Currency: row.Currency})
+		}
+		b := &balances[len(balances)-1]
+		b.Gross += row.Gross
+		b.Commission += row.Commission
+		b.Net += row.Net
+		b.Paid += row.PaidNet
+		if row.Status == models.VendorSaleDelivered {
+			b.Available += row.Net - row.PaidNet
+			b.Settled += row.Settled
+		} else {
+			b.Pending += row.Net
+		}
+	}
+	return balances, nil
+}
+
+// Payout settles what is due in payout.Currency: the difference between net and paid net of
+// every delivered line, which refunds after an earlier payout make negative
+// A non-zero settledBefore limits it to lines delivered by then. The total is rounded down to
+// a multiple of quantum (the provider's smallest unit); the rest stays due on the last line.
+// The lines are locked, so a refund or another payout can't change them between summing and settling.
+// payout.Status, Provider and the like are saved as given.
+// Returns: ErrNothingToPay when the total isn't positive
+func (r *VendorRepository) Payout(ctx context.Context, payout *models.VendorPayout, settledBefore time.Time, quantum int64) error {
+	if quantum < 1 {
+		quantum = 1
+	}
+	err := withRetry(ctx, func() error {
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("vendor_id = ? AND currency = ? AND status = ? AND net <> paid_net",
+					payout.VendorID, payout.Currency, models.VendorSaleDelivered)
+			if !settledBefore.IsZero() {
+				query = query.Where("delivered_at <= ?", settledBefore)
+			}
+			var sales []models.VendorSale
+			if err := query.Order("delivered_at ASC, id ASC").Find(&sales).Error; err != nil {
+				return err
+			}
+
+			var total int64
+			for _, sale := range sales {
+				total += sale.Net - sale.PaidNet
+			}
+			amount := total - total%quantum
+			if amount <= 0 {
+				return ErrNothingToPay
+			}
+
+			// Clawbacks first, so the amount left covers the lines that follow
+			sort.SliceStable(sales, func(i, j int) bool {
+				return sales[i].Net-sales[i].PaidNet < 0 && sales[j].Net-sales[j].PaidNet >= 0
+			})
+			var lines []models.VendorPayoutLine
+			remaining := amount
+			for _, sale := range sales {
+				pay := min(sale.Net-sale.PaidNet, remaining)
+				if pay == 0 {
+					continue
+				}
+				remaining -= pay
+				lines = append(lines, models.VendorPayoutLine{
+					SaleID:           sale.ID,
+					OrderID:          sale.OrderID,
+					OrderNumber:      sale.OrderNumber,
+					ProductName:      sale.ProductName,
+					Quantity:         sale.Quantity,
+					RefundedQuantity: sale.RefundedQuantity,
+					UnitPrice:        sale.UnitPrice,
+					Gross:            sale.Gross,
+					Commission:       sale.Commission,
+					Net:              sale.Net,
+					PaidBefore:       sale.PaidNet,
+					Amount:           pay,
+				})
+			}
+
+			payout.ID = ""
+			payout.Amount, payout.Lines = amount, len(lines)
+			if err := tx.Create(payout).Error; err != nil {
+				return err
+			}
+			for i := range lines {
+				lines[i].PayoutID = payout.ID
+			}
+			if err := tx.Create(&lines).Error; err != nil {
+				return err
+			}
+			for _, line := range lines {
+				err := tx.Model(&models.VendorSale{}).Where("id = ?", line.SaleID).
+					Updates(map[string]interface{}{"paid_net": line.PaidBefore + line.Amount, "last_payout_id": payout.ID}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil && !errors.Is(err, ErrNothingToPay) {
+		r.log.Error("Failed to pay out vendor", zap.String("vendor_id", payout.VendorID), zap.Error(err))
+	}
+	return err
+}
+
+// Payable returns the vendors owed settled earnings: pairs of active vendor and currency
+// with lines delivered by settledBefore whose net differs from their paid net
+func (r *VendorRepository) Payable(ctx context.Context, settledBefore time.Time) ([]models.VendorPayout, error) {
+	var rows []models.VendorPayout
+	err := r.db.WithContext(ctx).Model(&models.VendorSale{}).
+		Select("DISTINCT vendor_sales.vendor_id, vendor_sales.currency").
+		Joins("JOIN vendors ON vendors.id = vendor_sales.vendor_id").
+		Where("vendors.status = ? AND vendor_sales.status = ? AND vendor_sales.delivered_at <= ? AND vendor_sales.net <> vendor_sales.paid_net",
+			models.VendorActive, models.VendorSaleDelivered, settledBefore).
+		Order("vendor_sales.vendor_id ASC, vendor_sales.currency ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CreateBatch records a payout batch
+func (r *VendorRepository) CreateBatch(ctx context.Context, batch *models.VendorPayoutBatch) error {
+	if err := r.db.WithContext(ctx).Create(batch).Error; err != nil {
+		r.log.Error("Failed to create payout batch", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateBatchCount records how many payouts a batch made
+func (r *VendorRepository) UpdateBatchCount(ctx context.Context, id string, payouts int) error {
+	return r.db.WithContext(ctx).Model(&models.VendorPayoutBatch{}).Where("id = ?", id).Update("payouts", payouts).Error
+}
+
+func (r *VendorRepository) GetBatch(ctx context.Context, id string) (*models.VendorPayoutBatch, error) {
+	batch := &models.VendorPayoutBatch{}
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(batch).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPayoutBatchNotFound
+	}
+	return batch, err
+}
+
+// ListBatches returns a page of payout batches, newest first
+// Returns: page, total rows
+func (r *VendorRepository) ListBatches(ctx context.Context, limit, offset int) ([]models.VendorPayoutBatch, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.VendorPayoutBatch{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var batches []models.VendorPayoutBatch
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&batches).Error
+	return batches, total, err
+}
+
+// BatchPayouts returns the payouts of a batch by vendor
+func (r *VendorRepository) BatchPayouts(ctx context.Context, batchID string) ([]models.VendorPayout, error) {
+	var payouts []models.VendorPayout
+	err := r.db.WithContext(ctx).Where("batch_id = ?", batchID).Order("vendor_id ASC, currency ASC").Find(&payouts).Error
+	return payouts, err
+}
+
+func (r *VendorRepository) GetPayout(ctx context.Context, id string) (*models.VendorPayout, error) {
+	payout := &models.VendorPayout{}
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(payout).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPayoutNotFound
+	}
+	return payout, err
+}
+
+// PayoutLines returns the sales a payout settled, in the order they were settled
+func (r *VendorRepository) PayoutLines(ctx context.Context, payoutID string) ([]models.VendorPayoutLine, error) {
+	var lines []models.VendorPayoutLine
+	err := r.db.WithContext(ctx).Where("payout_id = ?", payoutID).Order("order_id ASC, sale_id ASC").Find(&lines).Error
+	return lines, err
+}
+
+// MarkPayoutProcessing records that the provider accepted a pending payout
+// Returns: false when the payout had already moved on (e.g. its callback came first)
+func (r *VendorRepository) MarkPayoutProcessing(ctx context.Context, id, reference string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.VendorPayout{}).
+		Where("id = ? AND status = ?", id, models.VendorPayoutPending).
+		Updates(map[string]interface{}{"status": models.VendorPayoutProcessing, "reference": reference})
+	return result.RowsAffected == 1, result.Error
+}
+
+// CompletePayout marks a pending or processing payout paid
+// An empty reference keeps the one recorded when the provider accepted it
+// Returns: false when the payout wasn't pending or processing
+func (r *VendorRepository) CompletePayout(ctx context.Context, id, reference string, at time.Time) (bool, error) {
+	updates := map[string]interface{}{"status": models.VendorPayoutPaid, "paid_at": at}
+	if reference != "" {
+		updates["reference"] = reference
+	}
+	result := r.db.WithContext(ctx).Model(&models.VendorPayout{}).
+		Where("id = ? AND status IN ?", id, []string{models.VendorPayoutPending, models.VendorPayoutProcessing}).
+		Updates(updates)
+	if result.Error != nil {
+		r.log.Error("Failed to complete vendor payout", zap.String("id", id), zap.Error(result.Error))
+	}
+	return result.RowsAffected == 1, result.Error
+}
+
+// FailPayout marks a pending or processing payout failed and releases its lines: what it
+// would have paid is due again and goes into the next payout
+// Returns: false when the payout wasn't pending or processing
+func (r *VendorRepository) FailPayout(ctx context.Context, id, reason string) (bool, error) {
+	failed := false
+	err := withRetry(ctx, func() error {
+		failed = false
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.VendorPayout{}).
+				Where("id = ? AND status IN ?", id, []string{models.VendorPayoutPending, models.VendorPayoutProcessing}).
+				Updates(map[string]interface{}{"status": models.VendorPayoutFailed, "failure_reason": reason})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			var lines []models.VendorPayoutLine
+			if err := tx.Where("payout_id = ?", id).Find(&lines).Error; err != nil {
+				return err
+			}
+			for _, line := range lines {
+				err := tx.Model(&models.VendorSale{}).Where("id = ?", line.SaleID).
+					Update("paid_net", gorm.Expr("paid_net - ?", line.Amount)).Error
+				if err != nil {
+					return err
+				}
+			}
+			failed = true
+			return nil
+		})
+	})
+	if err != nil {
+		r.log.Error("Failed to fail vendor payout", zap.String("id", id), zap.Error(err))
+	}
+	return failed, err
+}
+
+// ListPayouts returns a page of a vendor's payouts, newest first
+// Returns: page, total matching rows
+func (r *VendorRepository) ListPayouts(ctx context.Context, vendorID string, limit, offset int) ([]models.VendorPayout, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.VendorPayout{}).Where("vendor_id = ?", vendorID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var payouts []models.VendorPayout
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&payouts).Error
+	return payouts, total, err
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"go.uber.org/zap"
+)
+
+const payoutVendor = "vendor-1"
+
+func newVendorRepository(t *testing.T) *VendorRepository {
+	return NewVendorRepository(testutil.NewDB(t, &models.VendorSale{}, &models.VendorSaleRefund{},
+		&models.VendorPayout{}, &models.VendorPayoutLine{}), zap.NewNop())
+}
+
+// deliver records a delivered order of one line worth net to the vendor (at no commission)
+func deliver(t *testing.T, repo *VendorRepository, orderID string, net int64, at time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	sale := models.VendorSale{
+		ID: orderID + "-1", VendorID: payoutVendor, OrderID: orderID, Line: 1, ProductID: "product-1",
+		Quantity: 1, UnitPrice: net, Currency: "KES", Status: models.VendorSalePending,
+	}
+	sale.SetAmounts()
+	if err := repo.RecordSales(ctx, []models.VendorSale{sale}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.MarkDelivered(ctx, orderID, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// available returns the vendor's delivered net not paid out
+func available(t *testing.T, repo *VendorRepository) int64 {
+	t.Helper()
+	balances, err := repo.Balances(context.Background(), payoutVendor, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 1 {
+		t.Fatalf("balances = %+v, want one currency", balances)
+	}
+	return balances[0].Available
+}
+
+func payout(repo *VendorRepository, quantum int64) (*models.VendorPayout, error) {
+	p := &models.VendorPayout{VendorID: payoutVendor, Currency: "KES", Provider: "sandbox", Status: models.VendorPayoutPending}
+	return p, repo.Payout(context.Background(), p, time.Time{}, quantum)
+}
+
+// TestPayoutDebitsBalance checks that a payout takes what is due off the balance and that
+// failing it puts it back for the next payout
+func TestPayoutDebitsBalance(t *testing.T) {
+	ctx := context.Background()
+	repo := newVendorRepository(t)
+	deliver(t, repo, "order-1", 700, time.Now())
+	deliver(t, repo, "order-2", 300, time.Now())
+
+	first, err := payout(repo, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Amount != 1000 || first.Lines != 2 {
+		t.Fatalf("payout = %d over %d lines, want 1000 over 2", first.Amount, first.Lines)
+	}
+	if got := available(t, repo); got != 0 {
+		t.Fatalf("available after the payout = %d, want 0", got)
+	}
+	if _, err := payout(repo, 1); !errors.Is(err, ErrNothingToPay) {
+		t.Fatalf("second payout = %v, want ErrNothingToPay", err)
+	}
+
+	failed, err := repo.FailPayout(ctx, first.ID, "account closed")
+	if err != nil || !failed {
+		t.Fatalf("FailPayout = %v, %v", failed, err)
+	}
+	if got := available(t, repo); got != 1000 {
+		t.Fatalf("available after the payout failed = %d, want 1000", got)
+	}
+	stored, err := repo.GetPayout(ctx, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != models.VendorPayoutFailed || stored.FailureReason != "account closed" {
+		t.Fatalf("failed payout = %+v", stored)
+	}
+
+	// Failing it again releases nothing twice
+	if failed, err := repo.FailPayout(ctx, first.ID, "again"); err != nil || failed {
+		t.Fatalf("FailPayout of a failed payout = %v, %v", failed, err)
+	}
+	if got := available(t, repo); got != 1000 {
+		t.Fatalf("available after failing twice = %d, want 1000", got)
+	}
+
+	// Neither does failing a paid one
+	second, err := payout(repo, 1)
+	if err != nil || second.Amount != 1000 {
+		t.Fatalf("payout after the failure = %+v, %v", second, err)
+	}
+	if _, err := repo.CompletePayout(ctx, second.ID, "ref-1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if failed, err := repo.FailPayout(ctx, second.ID, "late failure"); err != nil || failed {
+		t.Fatalf("FailPayout of a paid payout = %v, %v", failed, err)
+	}
+	if got := available(t, repo); got != 0 {
+		t.Fatalf("available after a paid payout = %d, want 0", got)
+	}
+}
+
+// TestPayoutConcurrent runs payouts of one balance at once: it is paid exactly once
+func TestPayoutConcurrent(t *testing.T) {
+	repo := newVendorRepository(t)
+	for i := 0; i < 5; i++ {
+		deliver(t, repo, fmt.Sprintf("order-%d", i), 200, time.Now())
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		paid    int64
+		payouts int
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := payout(repo, 1)
+			if errors.Is(err, ErrNothingToPay) {
+				return
+			}
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			paid += p.Amount
+			payouts++
+		}()
+	}
+	wg.Wait()
+
+	if payouts != 1 || paid != 1000 {
+		t.Fatalf("%d payouts paid %d, want one of 1000", payouts, paid)
+	}
+	if got := available(t, repo); got != 0 {
+		t.Fatalf("available = %d, want 0", got)
+	}
+}
+
+// TestPayoutNeverExceedsBalance checks that rounding to the provider's unit and refunds of paid
+// sales never make a payout larger than what is due
+func TestPayoutNeverExceedsBalance(t *testing.T) {
+	ctx := context.Background()
+	repo := newVendorRepository(t)
+	deliver(t, repo, "order-1", 1050, time.Now())
+
+	// Rounded down; the rest stays due
+	p, err := payout(repo, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Amount != 1000 {
+		t.Fatalf("payout = %d, want 1000", p.Amount)
+	}
+	if got := available(t, repo); got != 50 {
+		t.Fatalf("available = %d, want 50", got)
+	}
+	if _, err := payout(repo, 100); !errors.Is(err, ErrNothingToPay) {
+		t.Fatalf("payout of less than the quantum = %v, want ErrNothingToPay", err)
+	}
+
+	// A refund of the paid sale leaves the vendor owing, and nothing is paid until new sales cover it
+	if _, err := repo.Refund(ctx, "refund-1", "order-1", map[string]int{"product-1": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got := available(t, repo); got != -1000 {
+		t.Fatalf("available after the refund = %d, want -1000", got)
+	}
+	if _, err := payout(repo, 1); !errors.Is(err, ErrNothingToPay) {
+		t.Fatalf("payout of a negative balance = %v, want ErrNothingToPay", err)
+	}
+
+	deliver(t, repo, "order-2", 1500, time.Now())
+	p, err = payout(repo, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Amount != 500 {
+		t.Fatalf("payout after the clawback = %d, want 500", p.Amount)
+	}
+	if got := available(t, repo); got != 0 {
+		t.Fatalf("available = %d, want 0", got)
+	}
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrWarehouseNotFound is returned when a warehouse doesn't exist
+var ErrWarehouseNotFound = errors.New("warehouse not found")
+
+// StockMove is a quantity of one product leaving one warehouse, e.g. one line of an allocation
+type StockMove struct {
+	WarehouseID string
+	ProductID   string
+	Quantity    int
+}
+
+type WarehouseRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewWarehouseRepository(db *gorm.DB, log *zap.Logger) *WarehouseRepository {
+	return &WarehouseRepository{db: db, log: log}
+}
+
+// Create inserts a warehouse with its shipping rates
+func (r *WarehouseRepository) Create(ctx context.Context, warehouse *models.Warehouse) error {
+	if err := r.db.WithContext(ctx).Create(warehouse).Error; err != nil {
+		r.log.Error("Failed to create warehouse", zap.String("code", warehouse.Code), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetByID loads a warehouse with its shipping rates
+func (r *WarehouseRepository) GetByID(ctx context.Context, id string) (*models.Warehouse, error) {
+	warehouse := &models.Warehouse{}
+	err := r.db.WithContext(ctx).Preload("Rates").Where("id = ?", id).First(warehouse).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWarehouseNotFound
+	}
+	return warehouse, err
+}
+
+// GetByCode loads a warehouse by its staff-facing code
+func (r *WarehouseRepository) GetByCode(ctx context.Context, code string) (*models.Warehouse, error) {
+	warehouse := &models.Warehouse{}
+	err := r.db.WithContext(ctx).Preload("Rates").Where("code = ?", code).First(warehouse).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWarehouseNotFound
+	}
+	return warehouse, err
+}
+
+// List returns warehouses with their rates ordered by code; activeOnly skips inactive ones
+func (r *WarehouseRepository) List(ctx context.Context, activeOnly bool) ([]models.Warehouse, error) {
+	query := r.db.WithContext(ctx).Preload("Rates")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	var warehouses []models.Warehouse
+	err := query.Order("code ASC").Find(&warehouses).Error
+	return warehouses, err
+}
+
+// Replace saves a warehouse's fields and swaps its rates for warehouse.Rates atomically
+func (r *WarehouseRepository) Replace(ctx context.Context, warehouse *models.Warehouse) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(warehouse).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("warehouse_id = ?", warehouse.ID).Delete(&models.WarehouseRate{}).Error; err != nil {
+			return err
+		}
+		if len(warehouse.Rates) == 0 {
+			return nil
+		}
+		for i := range warehouse.Rates {
+			warehouse.Rates[i].WarehouseID = warehouse.ID
+		}
+		return tx.Create(&warehouse.Rates).Error
+	})
+	if err != nil {
+		r.log.Error("Failed to update warehouse", zap.String("id", warehouse.ID), zap.Error(err))
+	}
+	return err
+}
+
+// Delete removes a warehouse, its rates and its stock rows
+// Callers check with Units that the warehouse holds nothing first
+func (r *WarehouseRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("warehouse_id = ?", id).Delete(&models.WarehouseRate{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("warehouse_id = ?", id).Delete(&models.WarehouseStock{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&models.Warehouse{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrWarehouseNotFound
+		}
+		return nil
+	})
+}
+
+// Units returns how many units a warehouse holds over all products
+func (r *WarehouseRepository) Units(ctx context.Context, warehouseID string) (int64, error) {
+	var units int64
+	err := r.db.WithContext(ctx).Model(&models.WarehouseStock{}).
+		Where("warehouse_id = ?", warehouseID).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&units).Error
+	return units, err
+}
+
+// ListStock returns a page of a warehouse's non-empty stock rows by product ID
+// Returns: page, total matching rows
+func (r *WarehouseRepository) ListStock(ctx context.Context, warehouseID string,
+	limit, offset int) ([]models.WarehouseStock, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.WarehouseStock{}).
+		Where("warehouse_id = ? AND quantity > 0", warehouseID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []models.WarehouseStock
+	err := query.Order("product_id ASC").Limit(limit).Offset(offset).Find(&rows).Error
+	return rows, total, err
+}
+
+// StockOf returns the stock rows of the given products in every warehouse
+func (r *WarehouseRepository) StockOf(ctx context.Context, productIDs []string) ([]models.WarehouseStock, error) {
+	var rows []models.WarehouseStock
+	if len(productIDs) == 0 {
+		return rows, nil
+	}
+	err := r.db.WithContext(ctx).Where("product_id IN ? AND quantity > 0", productIDs).Find(&rows).Error
+	return rows, err
+}
+
+// AdjustStock adds delta to a warehouse's stock of a product
+// The product's total stock moves by the same delta in the same transaction, unless
+// fromUnassigned: then positive delta assigns units the product already counts but no
+// warehouse holds (stock from before warehouses, or set on the product directly)
+// Returns: ErrInsufficientStock when the warehouse (or the unassigned stock) lacks the units
+func (r *WarehouseRepository) AdjustStock(ctx context.Context, warehouseID, productID string, delta int, fromUnassigned bool) error {
+	err := withRetry(ctx, func() error {
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := moveStock(tx, warehouseID, productID, delta); err != nil {
+				return err
+			}
+			if !fromUnassigned {
+				return adjustProductStock(tx, productID, delta)
+			}
+
+			// The warehouses may now hold at most the product's total
+			var held int64
+			err := tx.Model(&models.WarehouseStock{}).Where("product_id = ?", productID).
+				Select("COALESCE(SUM(quantity), 0)").Scan(&held).Error
+			if err != nil {
+				return err
+			}
+			var product models.Product
+			if err := tx.Select("stock").Where("id = ?", productID).First(&product).Error; err != nil {
+				return err
+			}
+			if held > int64(product.Stock) {
+				return ErrInsufficientStock
+			}
+			return nil
+		})
+	})
+	if err != nil && !errors.Is(err, ErrInsufficientStock) {
+		r.log.Error("Failed to adjust warehouse stock", zap.String("warehouse_id", warehouseID),
+			zap.String("product_id", productID), zap.Int("delta", delta), zap.Error(err))
+	}
+	return err
+}
+
+// Transfer moves transfer.Quantity units between warehouses and records the transfer atomically
+// Returns: ErrInsufficientStock when the source warehouse lacks the units
+func (r *WarehouseRepository) Transfer(ctx context.Context, transfer *models.StockTransfer) error {
+	err := withRetry(ctx, func() error {
+		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := moveStock(tx, transfer.FromWarehouseID, transfer.ProductID, -transfer.Quantity); err != nil {
+				return err
+			}
+			if err := moveStock(tx, transfer.ToWarehouseID, transfer.ProductID, transfer.Quantity); err != nil {
+				return err
+			}
+			return tx.Create(transfer).Error
+		})
+	})
+	if err != nil && !errors.Is(err, ErrInsufficientStock) {
+		r.log.Error("Failed to transfer stock", zap.String("product_id", transfer.ProductID), zap.Error(err))
+	}
+	return err
+}
+
+// ListTransfers returns a page of transfers, newest first
+// productID and warehouseID (either end) filter when not empty
+// Returns: page, total matching rows
+func (r *WarehouseRepository) ListTransfers(ctx context.Context, productID, warehouseID string,
+	limit, offset int) ([]models.StockTransfer, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.StockTransfer{})
+	if productID != "" {
+		query = query.Where("product_id = ?", productID)
+	}
+	if warehouseID != "" {
+		query = query.Where("from_warehouse_id = ? OR to_warehouse_id = ?", warehouseID, warehouseID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var transfers []models.StockTransfer
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&transfers).Error
+	return transfers, total, err
+}
+
+// Deduct takes allocated stock out of its warehouses and the products' totals
+// With tx (the order transaction) it commits or rolls back with the order; with nil it
+// runs in its own transaction. Either way nothing is deducted unless every move succeeds.
+// Returns: ErrInsufficientStock when a warehouse was emptied since the allocation
+func (r *WarehouseRepository) Deduct(ctx context.Context, tx *gorm.DB, moves []StockMove) error {
+	deduct := func(tx *gorm.DB) error {
+		for _, move := range moves {
+			if err := moveStock(tx, move.WarehouseID, move.ProductID, -move.Quantity); err != nil {
+				return err
+			}
+			if err := adjustProductStock(tx, move.ProductID, -move.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if tx != nil {
+		return deduct(tx.WithContext(ctx))
+	}
+	return withRetry(ctx, func() error {
+		return r.db.WithContext(ctx).Transaction(deduct)
+	})
+}
+
+// Restock puts deducted stock back into its warehouses and the products' totals
+// It undoes Deduct, e.g. when a checkout is compensated after its stock was taken;
+// like Deduct it joins tx when given one
+func (r *WarehouseRepository) Restock(ctx context.Context, tx *gorm.DB, moves []StockMove) error {
+	restock := func(tx *gorm.DB) error {
+		for _, move := range moves {
+			if err := moveStock(tx, move.WarehouseID, move.ProductID, move.Quantity); err != nil {
+				return err
+			}
+			if err := adjustProductStock(tx, move.ProductID, move.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if tx != nil {
+		return restock(tx.WithContext(ctx))
+	}
+	return withRetry(ctx, func() error {
+		return r.db.WithContext(ctx).Transaction(restock)
+	})
+}
+
+// moveStock adds delta to one warehouse stock row, creating it at zero first if needed
+// A decrement only matches while enough is left, like ProductRepository.AdjustStock
+func moveStock(tx *gorm.DB, warehouseID, productID string, delta int) error {
+	if delta > 0 {
+		row := models.WarehouseStock{WarehouseID: warehouseID, ProductID: productID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error; err != nil {
+			return err
+		}
+	}
+
+	query := tx.Model(&models.WarehouseStock{}).Where("warehouse_id = ? AND product_id = ?", warehouseID, productID)
+	if delta < 0 {
+		query = query.Where("quantity >= ?", -delta)
+	}
+	result := query.Update("quantity", gorm.Expr("quantity + ?", delta))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+// adjustProductStock keeps the product's total in step with its warehouses
+func adjustProductStock(tx *gorm.DB, productID string, delta int) error {
+	query := tx.Model(&models.Product{}).Where("id = ?", productID)
+	if delta < 0 {
+		query = query.Where("stock >= ?", -delta)
+	}
+	result := query.Update("stock", gorm.Expr("stock + ?", delta))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientStock
+	}
+	return nil
+}
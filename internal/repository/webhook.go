@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrWebhookNotFound is returned when a subscription doesn't exist or belongs to another owner
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookDeliveryNotFound is returned when a delivery doesn't exist or belongs to another subscription
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookRepository handles webhook subscriptions and delivery logs
+type WebhookRepository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+func NewWebhookRepository(db *gorm.DB, log *zap.Logger) *WebhookRepository {
+	return &WebhookRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// CreateSubscription inserts a new webhook subscription
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	if err := r.db.WithContext(ctx).Create(sub).Error; err != nil {
+		r.log.Error("Failed to create webhook", zap.String("owner_id", sub.OwnerID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListSubscriptionsByOwner returns all subscriptions registered by ownerID, newest first
+func (r *WebhookRepository) ListSubscriptionsByOwner(ctx context.Context, ownerID string) ([]models.WebhookSubscription, error) {
+	var subs []models.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("owner_id = ?", ownerID).Order("created_at DESC").Find(&subs).Error; err != nil {
+		r.log.Error("Failed to list webhooks", zap.String("owner_id", ownerID), zap.Error(err))
+		return nil, err
+	}
+	return subs, nil
+}
+
+// GetSubscription retrieves a subscription by ID scoped to its owner
+// Returns: ErrWebhookNotFound if missing or owned by someone else
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id, ownerID string) (*models.WebhookSubscription, error) {
+	sub := &models.WebhookSubscription{}
+	if err := r.db.WithContext(ctx).Where("id = ? AND owner_id = ?", id, ownerID).First(sub).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		r.log.Error("Failed to fetch webhook", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ListActiveSubscriptions returns every active subscription (used to fan out events)
+func (r *WebhookRepository) ListActiveSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var subs []models.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("active = ?", true).Find(&subs).Error; err != nil {
+		r.log.Error("Failed to list active webhooks", zap.Error(err))
+		return nil, err
+	}
+	return subs, nil
+}
+
+// DeleteSubscription removes a subscription and its delivery log
+// Returns: ErrWebhookNotFound if missing or owned by someone else
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id, ownerID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND owner_id = ?", id, ownerID).Delete(&models.WebhookSubscription{})
+		if result.Error != nil {
+			r.log.Error("Failed to delete webhook", zap.String("id", id), zap.Error(result.Error))
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrWebhookNotFound
+		}
+		return tx.Where("subscription_id = ?", id).Delete(&models.WebhookDelivery{}).Error
+	})
+}
+
+// CreateDeliveries queues deliveries in a single insert
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Create(&deliveries).Error; err != nil {
+		r.log.Error("Failed to queue webhook deliveries", zap.Int("count", len(deliveries)), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListDueDeliveries returns pending deliveries whose next attempt is due, oldest first
+func (r *WebhookRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		r.log.Error("Failed to list due webhook deliveries", zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// GetSubscriptionByID retrieves a subscription without owner scoping (dispatcher use only)
+func (r *WebhookRepository) GetSubscriptionByID(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	sub := &models.WebhookSubscription{}
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(sub).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+// UpdateDelivery saves the outcome of a delivery attempt
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if err := r.db.WithContext(ctx).Save(delivery).Error; err != nil {
+		r.log.Error("Failed to update webhook delivery", zap.String("id", delivery.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ListDeliveries returns the delivery log for a subscription, newest first
+// status filters by delivery state when non-empty
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID, status string, limit, offset int) ([]models.WebhookDelivery, error) {
+	query := r.db.WithContext(ctx).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+		r.log.Error("Failed to list webhook deliveries", zap.String("subscription_id", subscriptionID), zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// GetDelivery returns one delivery of a subscription
+func (r *WebhookRepository) GetDelivery(ctx context.Context, subscriptionID, id string) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	err := r.db.WithContext(ctx).Where("id = ? AND subscription_id = ?", id, subscriptionID).First(delivery).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	return delivery, err
+}
+
+// ListDeliveriesByOwner returns the deliveries of all of ownerID's subscriptions, newest first
+// status filters by delivery state when non-empty
+func (r *WebhookRepository) ListDeliveriesByOwner(ctx context.Context, ownerID, status string, limit, offset int) ([]models.WebhookDelivery, error) {
+	owned := r.db.Model(&models.WebhookSubscription{}).Select("id").Where("owner_id = ?", ownerID)
+	query := r.db.WithContext(ctx).Where("subscription_id IN (?)", owned)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&deliveries).Error; err != nil {
+		r.log.Error("Failed to list webhook deliveries", zap.String("owner_id", ownerID), zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ListReplayable returns up to limit of a subscription's original (not replayed) deliveries
+// in one of statuses, created in [from, to), oldest first
+func (r *WebhookRepository) ListReplayable(ctx context.Context, subscriptionID string, statuses []string,
+	from, to time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("subscription_id = ? AND status IN ? AND created_at >= ? AND created_at < ?", subscriptionID, statuses, from, to).
+		Where("replay_of IS NULL OR replay_of = ?", "").
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		r.log.Error("Failed to list replayable webhook deliveries", zap.String("subscription_id", subscriptionID), zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
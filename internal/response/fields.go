@@ -0,0 +1,333 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxFieldDepth caps how deeply a selection nests; each ( or . is a level
+	maxFieldDepth = 8
+	// maxFields caps the field names in one selection, counting repeats
+	maxFields = 100
+)
+
+// Selection is a parsed ?fields= value: each selected key maps to the selection of its
+// nested fields, or to nil when the whole value is kept
+type Selection map[string]Selection
+
+// ParseFields parses a field selection such as "id,name,user(id,email),items.sku"
+// Parentheses select nested fields of an object (or of every element of an array);
+// a.b is shorthand for a(b). Selections nested deeper than 8 levels or naming more than
+// 100 fields are rejected, so a query string can't make the parser or filter do unbounded work
+func ParseFields(s string) (Selection, error) {
+	p := &fieldParser{in: s}
+	sel, err := p.list()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.in) {
+		return nil, fmt.Errorf("fields: unexpected %q at %d", p.in[p.pos], p.pos)
+	}
+	return sel, nil
+}
+
+type fieldParser struct {
+	in     string
+	pos    int
+	depth  int
+	fields int
+}
+
+func (p *fieldParser) list() (Selection, error) {
+	sel := Selection{}
+	for {
+		name, sub, err := p.item()
+		if err != nil {
+			return nil, err
+		}
+		sel.merge(name, sub)
+
+		p.space()
+		if p.pos >= len(p.in) || p.in[p.pos] != ',' {
+			return sel, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *fieldParser) item() (string, Selection, error) {
+	p.space()
+	start := p.pos
+	for p.pos < len(p.in) && isFieldChar(p.in[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		if p.pos < len(p.in) {
+			return "", nil, fmt.Errorf("fields: expected a field name at %d, got %q", p.pos, p.in[p.pos])
+		}
+		return "", nil, errors.New("fields: expected a field name")
+	}
+	name := p.in[start:p.pos]
+	p.fields++
+	if p.fields > maxFields {
+		return "", nil, fmt.Errorf("fields: more than %d fields selected", maxFields)
+	}
+
+	p.space()
+	if p.pos >= len(p.in) {
+		return name, nil, nil
+	}
+	switch p.in[p.pos] {
+	case '(':
+		if err := p.descend(name); err != nil {
+			return "", nil, err
+		}
+		sub, err := p.list()
+		p.depth--
+		if err != nil {
+			return "", nil, err
+		}
+		p.space()
+		if p.pos >= len(p.in) || p.in[p.pos] != ')' {
+			return "", nil, fmt.Errorf("fields: missing ) for %s", name)
+		}
+		p.pos++
+		return name, sub, nil
+	case '.':
+		if err := p.descend(name); err != nil {
+			return "", nil, err
+		}
+		child, sub, err := p.item()
+		p.depth--
+		if err != nil {
+			return "", nil, err
+		}
+		return name, Selection{child: sub}, nil
+	}
+	return name, nil, nil
+}
+
+// descend steps past the ( or . opening name's nested fields; the caller undoes the depth
+func (p *fieldParser) descend(name string) error {
+	if p.depth == maxFieldDepth {
+		return fmt.Errorf("fields: %s nests deeper than %d levels", name, maxFieldDepth)
+	}
+	p.pos++
+	p.depth++
+	return nil
+}
+
+func (p *fieldParser) space() {
+	for p.pos < len(p.in) && p.in[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func isFieldChar(c byte) bool {
+	return c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// merge adds name to s; selecting a whole field wins over selecting part of it
+func (s Selection) merge(name string, sub Selection) {
+	existing, ok := s[name]
+	switch {
+	case !ok:
+		s[name] = sub
+	case existing == nil || sub == nil:
+		s[name] = nil
+	default:
+		for child, childSub := range sub {
+			existing.merge(child, childSub)
+		}
+	}
+}
+
+// Filter writes the JSON document read from r to w, keeping only the selected fields
+// Key order and number formatting are preserved; selections on scalars are ignored
+func Filter(w *bytes.Buffer, r io.Reader, sel Selection) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return filterValue(dec, w, sel)
+}
+
+func filterValue(dec *json.Decoder, w *bytes.Buffer, sel Selection) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		w.WriteByte('{')
+		first := true
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := keyTok.(string)
+			sub, selected := sel[key]
+			if !selected {
+				if err := dec.Decode(&json.RawMessage{}); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if !first {
+				w.WriteByte(',')
+			}
+			first = false
+			writeString(w, key)
+			w.WriteByte(':')
+			if sub == nil {
+				err = copyValue(dec, w)
+			} else {
+				err = filterValue(dec, w, sub)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		w.WriteByte('}')
+
+	case json.Delim('['):
+		w.WriteByte('[')
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := filterValue(dec, w, sel); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		w.WriteByte(']')
+
+	default:
+		return writeScalar(w, tok)
+	}
+	return nil
+}
+
+func copyValue(dec *json.Decoder, w *bytes.Buffer) error {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	w.Write(raw)
+	return nil
+}
+
+func writeScalar(w *bytes.Buffer, tok json.Token) error {
+	switch v := tok.(type) {
+	case nil:
+		w.WriteString("null")
+	case bool:
+		w.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		w.WriteString(v.String())
+	case string:
+		writeString(w, v)
+	default:
+		return fmt.Errorf("fields: unexpected token %v", tok)
+	}
+	return nil
+}
+
+func writeString(w *bytes.Buffer, s string) {
+	encoded, _ := json.Marshal(s)
+	w.Write(encoded)
+}
+
+// SelectFields trims successful JSON responses of GET requests to the ?fields= selection,
+// e.g. GET /products?fields=products(id,name,price),has_more
+// Other responses (errors, CSV exports, event streams) pass through untouched
+func SelectFields(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fields := strings.TrimSpace(r.URL.Query().Get("fields"))
+		if r.Method != http.MethodGet || fields == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		sel, err := ParseFields(fields)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		fw := &fieldsWriter{ResponseWriter: w}
+		next.ServeHTTP(fw, r)
+		if !fw.buffering {
+			return
+		}
+
+		e := encoders.Get().(*encoder)
+		defer release(e)
+		if err := Filter(&e.buf, &fw.body, sel); err != nil {
+			// Not the JSON it claimed to be - send it as it was
+			e.buf.Reset()
+			e.buf.Write(fw.body.Bytes())
+		} else {
+			e.buf.WriteByte('\n')
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(e.buf.Len()))
+		w.WriteHeader(fw.status)
+		w.Write(e.buf.Bytes())
+	})
+}
+
+// fieldsWriter holds back a 2xx JSON body so SelectFields can filter it
+type fieldsWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+func (fw *fieldsWriter) WriteHeader(status int) {
+	if fw.wroteHeader {
+		return
+	}
+	fw.wroteHeader = true
+	fw.status = status
+	fw.buffering = status >= 200 && status < 300 && status != http.StatusNoContent &&
+		strings.HasPrefix(fw.Header().Get("Content-Type"), "application/json")
+	if !fw.buffering {
+		fw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (fw *fieldsWriter) Write(p []byte) (int, error) {
+	if !fw.wroteHeader {
+		fw.WriteHeader(http.StatusOK)
+	}
+	if fw.buffering {
+		return fw.body.Write(p)
+	}
+	return fw.ResponseWriter.Write(p)
+}
+
+// Flush keeps streaming responses working; a buffered body is only sent once complete
+func (fw *fieldsWriter) Flush() {
+	if fw.buffering {
+		return
+	}
+	if f, ok := fw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
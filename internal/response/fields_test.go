@@ -0,0 +1,63 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	deep := strings.Repeat("a.", maxFieldDepth) + "a"
+	tests := []struct {
+		name   string
+		fields string
+		want   string // Selection as %v, or "" for an error
+	}{
+		{"flat", "id, name", "map[id:map[] name:map[]]"},
+		{"nested", "user(id,email),items.sku", "map[items:map[sku:map[]] user:map[email:map[] id:map[]]]"},
+		{"whole wins", "user(id),user", "map[user:map[]]"},
+		{"max depth", deep, strings.Repeat("map[a:", maxFieldDepth+1) + "map[]" + strings.Repeat("]", maxFieldDepth+1)},
+		{"too deep", "x." + deep, ""},
+		{"too deep in parentheses", strings.Repeat("a(", maxFieldDepth+1) + "a" + strings.Repeat(")", maxFieldDepth+1), ""},
+		{"max fields", strings.Repeat("a,", maxFields-1) + "a", "map[a:map[]]"},
+		{"too many fields", strings.Repeat("a,", maxFields) + "a", ""},
+		{"missing paren", "user(id", ""},
+		{"empty name", "id,,name", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel, err := ParseFields(tt.fields)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("ParseFields(%q) = %v, want an error", tt.fields, sel)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFields(%q): %v", tt.fields, err)
+			}
+			if got := fmt.Sprint(sel); got != tt.want {
+				t.Fatalf("ParseFields(%q) = %s, want %s", tt.fields, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSelectFieldsRejectsOversizedSelection checks that a selection over the caps is answered 400
+// before the handler runs
+func TestSelectFieldsRejectsOversizedSelection(t *testing.T) {
+	called := false
+	handler := SelectFields(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		JSON(w, http.StatusOK, map[string]string{"id": "1"})
+	}))
+	for _, fields := range []string{strings.Repeat("a.", maxFieldDepth+1) + "a", strings.Repeat("a,", maxFields) + "a"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1?fields="+fields, nil))
+		if rec.Code != http.StatusBadRequest || called {
+			t.Fatalf("fields=%s: status %d, handler called %v; want 400 without calling it", fields, rec.Code, called)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Package response writes handler responses
+// JSON bodies are encoded into pooled buffers, so large lists don't allocate a fresh
+// encoder and growing byte slices on every request, and are sent with a Content-Length.
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// maxPooledBuffer caps the buffers kept for reuse; one huge export shouldn't pin its memory
+const maxPooledBuffer = 4 << 20
+
+// encoder is a reusable buffer with a JSON encoder writing into it
+type encoder struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+var encoders = sync.Pool{
+	New: func() interface{} {
+		e := &encoder{}
+		e.enc = json.NewEncoder(&e.buf)
+		return e
+	},
+}
+
+// JSON writes v as a JSON body with status
+// The body is encoded before anything is written, so an encoding failure still becomes a 500
+func JSON(w http.ResponseWriter, status int, v interface{}) error {
+	e := encoders.Get().(*encoder)
+	defer release(e)
+
+	if err := e.enc.Encode(v); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(e.buf.Len()))
+	w.WriteHeader(status)
+	_, err := w.Write(e.buf.Bytes())
+	return err
+}
+
+func release(e *encoder) {
+	if e.buf.Cap() > maxPooledBuffer {
+		return
+	}
+	e.buf.Reset()
+	encoders.Put(e)
+}
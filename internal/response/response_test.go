@@ -0,0 +1,66 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+// listSize is the number of items in the large-response benchmarks
+const listSize = 1000
+
+func BenchmarkJSONProductListing(b *testing.B) {
+	resp := models.ProductListingResponse{Products: make([]models.ProductListing, listSize), Limit: listSize}
+	for i := range resp.Products {
+		resp.Products[i] = models.ProductListing{
+			ProductID: fmt.Sprintf("00000000-0000-4000-8000-%012d", i),
+			SKU:       fmt.Sprintf("LOAD-%06d", i),
+			Name:      "Wireless Headphones",
+			Category:  "electronics",
+			Price:     int64(100 + i*37),
+			Currency:  "USD",
+			InStock:   i%7 != 0,
+			UpdatedAt: time.Date(2026, 1, 1, 0, 0, i, 0, time.UTC),
+		}
+	}
+	benchJSON(b, resp)
+}
+
+func BenchmarkJSONUsers(b *testing.B) {
+	users := make([]models.User, listSize)
+	for i := range users {
+		users[i] = models.User{
+			ID:        fmt.Sprintf("00000000-0000-4000-8000-%012d", i),
+			Email:     fmt.Sprintf("loadtest+%06d@example.com", i),
+			FirstName: "Amina",
+			LastName:  "Odhiambo",
+			Role:      models.RoleCustomer,
+			CreatedAt: time.Date(2026, 1, 1, 0, 0, i, 0, time.UTC),
+		}
+	}
+	benchJSON(b, users)
+}
+
+// benchJSON measures writing v as a handler response
+func benchJSON(b *testing.B, v interface{}) {
+	w := discardWriter{header: make(http.Header)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := JSON(w, http.StatusOK, v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// discardWriter is an http.ResponseWriter that drops the body, like a fast client
+type discardWriter struct {
+	header http.Header
+}
+
+func (w discardWriter) Header() http.Header         { return w.header }
+func (w discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w discardWriter) WriteHeader(int)             {}
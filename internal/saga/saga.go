@@ -0,0 +1,363 @@
+// Package saga runs operations that span several services as a sequence of steps, each with a
+// compensation that undoes it. Progress is recorded in the sagas table after every step, in
+// the same transaction as the step's own database work, so an instance that crashes midway
+// leaves the saga at a known step; recovery resumes it from there, forward or backward.
+// Remote side effects (payment captures, refunds) cannot share that transaction, so they run
+// before their step is recorded and must be idempotent: a crash in between repeats them.
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// lease is how long an instance owns a saga while running it; longer than any one step,
+	// so a saga is only taken over once its runner is gone
+	lease = 2 * time.Minute
+	// maxErrorLength bounds the failure kept on the saga
+	maxErrorLength = 512
+)
+
+// Step is one step of a saga over data T
+// Action and Local do the step, Compensate and CompensateLocal undo it; any of them may be nil.
+// Action runs first and must be idempotent. Local runs in the transaction that records the
+// step, so its writes commit exactly once. A step whose Action kept failing is compensated
+// too, so its compensations must cope with an Action that never took effect (and a Local
+// that never ran): give a step with an Action nothing in Local its compensations can't skip.
+type Step[T any] struct {
+	Name            string
+	Action          func(ctx context.Context, data *T) error
+	Local           func(ctx context.Context, tx *gorm.DB, data *T) error
+	Compensate      func(ctx context.Context, data *T) error
+	CompensateLocal func(ctx context.Context, tx *gorm.DB, data *T) error
+}
+
+// abortError marks a step failure that retrying won't fix
+type abortError struct {
+	err error
+}
+
+func (e *abortError) Error() string { return e.err.Error() }
+func (e *abortError) Unwrap() error { return e.err }
+
+// Abort makes a step fail for good (payment declined, out of stock): the saga stops retrying
+// and compensates the steps done before it. Any other step error is retried with backoff
+func Abort(err error) error {
+	return &abortError{err: err}
+}
+
+// Orchestrator runs the sagas of one type
+type Orchestrator[T any] struct {
+	sagaType    string
+	steps       []Step[T]
+	repo        *repository.SagaRepository
+	ids         clock.IDGenerator
+	clock       clock.Clock
+	maxAttempts int
+	backoff     time.Duration
+	log         *zap.Logger
+}
+
+// New creates the orchestrator of sagaType
+// A step that fails maxAttempts times in a row is given up on; retries wait backoff, doubling
+func New[T any](sagaType string, steps []Step[T], repo *repository.SagaRepository, ids clock.IDGenerator,
+	clk clock.Clock, maxAttempts int, backoff time.Duration, log *zap.Logger) *Orchestrator[T] {
+	return &Orchestrator[T]{
+		sagaType:    sagaType,
+		steps:       steps,
+		repo:        repo,
+		ids:         ids,
+		clock:       clk,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		log:         log,
+	}
+}
+
+// Start records a new saga over data and runs it as far as it goes now
+// With a key, starting again returns (and resumes) the saga first started with that key.
+// Returns: the saga as it stands; running or compensating when a step is waiting for a retry
+func (o *Orchestrator[T]) Start(ctx context.Context, key string, data *T) (*models.Saga, error) {
+	if key != "" {
+		existing, err := o.ResumeKey(ctx, key)
+		if !errors.Is(err, repository.ErrSagaNotFound) {
+			return existing, err
+		}
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s saga: %w", o.sagaType, err)
+	}
+	now := o.clock.Now().UTC()
+	until := now.Add(lease)
+	saga := &models.Saga{
+		ID:            o.ids.NewID(),
+		Type:          o.sagaType,
+		Status:        models.SagaRunning,
+		Data:          encoded,
+		NextAttemptAt: now,
+		LockedBy:      o.ids.NewID(),
+		LockedUntil:   &until,
+	}
+	if key != "" {
+		saga.IdempotencyKey = &key
+	}
+	if err := o.repo.Create(ctx, saga); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) && key != "" {
+			// Lost a race with a concurrent start with the same key
+			return o.ResumeKey(ctx, key)
+		}
+		return nil, err
+	}
+	return saga, o.run(ctx, saga, data)
+}
+
+// Resume runs a saga on from where it was recorded, if no one else is running it
+// Returns: the saga as it stands; unchanged when it is finished or leased elsewhere
+func (o *Orchestrator[T]) Resume(ctx context.Context, id string) (*models.Saga, error) {
+	now := o.clock.Now().UTC()
+	token := o.ids.NewID()
+	claimed, err := o.repo.Claim(ctx, id, token, now, now.Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	saga, err := o.repo.GetByID(ctx, id)
+	if err != nil || !claimed {
+		return saga, err
+	}
+	if saga.Type != o.sagaType {
+		return nil, fmt.Errorf("saga %s is a %s saga, not %s", id, saga.Type, o.sagaType)
+	}
+
+	data, err := Decode[T](saga)
+	if err != nil {
+		return nil, err
+	}
+	return saga, o.run(ctx, saga, data)
+}
+
+// ResumeKey resumes the saga started with an idempotency key, see Resume
+// Returns: repository.ErrSagaNotFound when no saga has the key
+func (o *Orchestrator[T]) ResumeKey(ctx context.Context, key string) (*models.Saga, error) {
+	existing, err := o.repo.GetByKey(ctx, o.sagaType, key)
+	if err != nil {
+		return nil, err
+	}
+	return o.Resume(ctx, existing.ID)
+}
+
+// Get returns a saga as recorded
+// Returns: repository.ErrSagaNotFound, also for sagas of another type
+func (o *Orchestrator[T]) Get(ctx context.Context, id string) (*models.Saga, error) {
+	saga, err := o.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if saga.Type != o.sagaType {
+		return nil, repository.ErrSagaNotFound
+	}
+	return saga, nil
+}
+
+// Recover resumes up to limit sagas that are due: retries after transient failures and sagas
+// whose runner crashed (their lease expired). Returns: how many were resumed
+func (o *Orchestrator[T]) Recover(ctx context.Context, limit int) (int, error) {
+	ids, err := o.repo.Due(ctx, o.clock.Now().UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+	resumed := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, err := o.Resume(ctx, id); err != nil {
+			o.log.Error("Failed to resume saga", zap.String("type", o.sagaType), zap.String("saga_id", id), zap.Error(err))
+			continue
+		}
+		resumed++
+	}
+	return resumed, nil
+}
+
+// Decode returns a saga's data
+func Decode[T any](saga *models.Saga) (*T, error) {
+	var data T
+	if err := json.Unmarshal(saga.Data, &data); err != nil {
+		return nil, fmt.Errorf("decode %s saga %s: %w", saga.Type, saga.ID, err)
+	}
+	return &data, nil
+}
+
+// run carries out steps until the saga finishes or a step has to wait for a retry
+// The caller holds the lease; it is given up when run returns
+// Runs detached from ctx's cancellation, so a client hanging up doesn't stop a checkout halfway
+func (o *Orchestrator[T]) run(ctx context.Context, saga *models.Saga, data *T) error {
+	ctx = context.WithoutCancel(ctx)
+	token := saga.LockedBy
+	for {
+		switch saga.Status {
+		case models.SagaRunning:
+			if saga.Step == len(o.steps) {
+				saga.Status = models.SagaCompleted
+				return o.release(ctx, saga, token)
+			}
+			if err := o.forward(ctx, saga, token, data); err != nil {
+				if errors.Is(err, repository.ErrSagaLeaseLost) {
+					return err
+				}
+				if o.retryLater(saga, err, true) {
+					return o.release(ctx, saga, token)
+				}
+				// Keep what the failed step's Action learned (a payment ID) for its compensation
+				if err := o.save(ctx, saga, token, data, nil); err != nil {
+					return err
+				}
+			}
+
+		case models.SagaCompensating:
+			if saga.Step == 0 {
+				saga.Status = models.SagaCompensated
+				return o.release(ctx, saga, token)
+			}
+			if err := o.backward(ctx, saga, token, data); err != nil {
+				if errors.Is(err, repository.ErrSagaLeaseLost) {
+					return err
+				}
+				o.retryLater(saga, err, false)
+				return o.release(ctx, saga, token)
+			}
+
+		default:
+			return nil
+		}
+	}
+}
+
+// forward runs the current step and records it as done
+func (o *Orchestrator[T]) forward(ctx context.Context, saga *models.Saga, token string, data *T) error {
+	step := o.steps[saga.Step]
+	if step.Action != nil {
+		if err := step.Action(ctx, data); err != nil {
+			return err
+		}
+	}
+
+	next := *saga
+	next.Step++
+	next.Attempts = 0
+	var local func(tx *gorm.DB) error
+	if step.Local != nil {
+		local = func(tx *gorm.DB) error { return step.Local(ctx, tx, data) }
+	}
+	if err := o.save(ctx, &next, token, data, local); err != nil {
+		return err
+	}
+	*saga = next
+	return nil
+}
+
+// backward undoes the last completed step and records it as undone
+func (o *Orchestrator[T]) backward(ctx context.Context, saga *models.Saga, token string, data *T) error {
+	step := o.steps[saga.Step-1]
+	if step.Compensate != nil {
+		if err := step.Compensate(ctx, data); err != nil {
+			return err
+		}
+	}
+
+	next := *saga
+	next.Step--
+	next.Attempts = 0
+	var local func(tx *gorm.DB) error
+	if step.CompensateLocal != nil {
+		local = func(tx *gorm.DB) error { return step.CompensateLocal(ctx, tx, data) }
+	}
+	if err := o.save(ctx, &next, token, data, local); err != nil {
+		return err
+	}
+	*saga = next
+	return nil
+}
+
+// retryLater books a failed attempt at the current step
+// Returns: true when the step should be tried again later. Otherwise a forward step turns the
+// saga to compensating, and a compensation that keeps failing leaves the saga failed
+func (o *Orchestrator[T]) retryLater(saga *models.Saga, err error, forward bool) bool {
+	name := o.stepName(saga, forward)
+	var abort *abortError
+	aborted := errors.As(err, &abort)
+	if !aborted {
+		saga.Attempts++
+		if saga.Attempts < o.maxAttempts {
+			saga.NextAttemptAt = o.clock.Now().UTC().Add(o.backoff << (saga.Attempts - 1))
+			o.log.Warn("Saga step failed; retrying later", zap.String("type", o.sagaType), zap.String("saga_id", saga.ID),
+				zap.String("step", name), zap.Int("attempts", saga.Attempts), zap.Error(err))
+			return true
+		}
+	}
+
+	saga.Attempts = 0
+	saga.NextAttemptAt = o.clock.Now().UTC()
+	if !forward {
+		saga.Status = models.SagaFailed
+		o.log.Error("Saga compensation gave up; needs manual follow-up", zap.String("type", o.sagaType),
+			zap.String("saga_id", saga.ID), zap.String("step", name), zap.Error(err))
+		return false
+	}
+
+	saga.Status = models.SagaCompensating
+	saga.Error = truncate(fmt.Sprintf("%s: %v", name, err))
+	if !aborted && o.steps[saga.Step].Action != nil {
+		// The Action's outcome is unknown (its last attempt may have taken effect), so the step
+		// is compensated too; a Local that failed was rolled back and needs no undoing
+		saga.Step++
+	}
+	o.log.Warn("Saga step failed; compensating", zap.String("type", o.sagaType), zap.String("saga_id", saga.ID),
+		zap.String("step", name), zap.Bool("aborted", aborted), zap.Error(err))
+	return false
+}
+
+// release records the saga and gives up its lease
+func (o *Orchestrator[T]) release(ctx context.Context, saga *models.Saga, token string) error {
+	saga.LockedBy = ""
+	saga.LockedUntil = nil
+	return o.save(ctx, saga, token, nil, nil)
+}
+
+// save records the saga (with data, unless nil) while token holds its lease
+func (o *Orchestrator[T]) save(ctx context.Context, saga *models.Saga, token string, data *T, local func(tx *gorm.DB) error) error {
+	if data != nil {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("encode %s saga: %w", o.sagaType, err)
+		}
+		saga.Data = encoded
+	}
+	return o.repo.Save(ctx, saga, token, local)
+}
+
+func (o *Orchestrator[T]) stepName(saga *models.Saga, forward bool) string {
+	if forward {
+		return o.steps[saga.Step].Name
+	}
+	return o.steps[saga.Step-1].Name
+}
+
+func truncate(message string) string {
+	if len(message) > maxErrorLength {
+		return message[:maxErrorLength]
+	}
+	return message
+}
@@ -0,0 +1,303 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"github.com/Jason-Omondi/ecomgo/internal/testutil"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	testAttempts = 3
+	testBackoff  = time.Minute
+)
+
+// order is the data the test sagas carry; Notes shows that data written by a step is kept
+type order struct {
+	Notes []string
+}
+
+// journal records the step functions called, in order
+type journal struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (j *journal) add(entry string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = append(j.entries, entry)
+}
+
+func (j *journal) want(t *testing.T, want ...string) {
+	t.Helper()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if want == nil {
+		want = []string{}
+	}
+	got := append([]string{}, j.entries...)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+}
+
+type harness struct {
+	orchestrator *Orchestrator[order]
+	journal      *journal
+	clock        *clock.Fake
+	// fail holds, per step name, the error its Action returns; a step not in it succeeds
+	fail map[string]func() error
+}
+
+// newHarness runs sagas of the steps named: each has an Action, a Local and both compensations,
+// and records every call in the journal
+func newHarness(t *testing.T, names ...string) *harness {
+	t.Helper()
+	h := &harness{
+		journal: &journal{},
+		clock:   clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)),
+		fail:    map[string]func() error{},
+	}
+	var steps []Step[order]
+	for _, name := range names {
+		steps = append(steps, Step[order]{
+			Name: name,
+			Action: func(_ context.Context, data *order) error {
+				h.journal.add(name)
+				if fail := h.fail[name]; fail != nil {
+					if err := fail(); err != nil {
+						return err
+					}
+				}
+				data.Notes = append(data.Notes, name)
+				return nil
+			},
+			Local: func(context.Context, *gorm.DB, *order) error {
+				h.journal.add(name + " local")
+				return nil
+			},
+			Compensate: func(context.Context, *order) error {
+				h.journal.add("undo " + name)
+				return nil
+			},
+			CompensateLocal: func(context.Context, *gorm.DB, *order) error {
+				h.journal.add("undo " + name + " local")
+				return nil
+			},
+		})
+	}
+	db := testutil.NewDB(t, &models.Saga{})
+	h.orchestrator = New("test", steps, repository.NewSagaRepository(db, zap.NewNop()),
+		clock.NewSequence("saga"), h.clock, testAttempts, testBackoff, zap.NewNop())
+	return h
+}
+
+func (h *harness) recover(t *testing.T) int {
+	t.Helper()
+	resumed, err := h.orchestrator.Recover(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resumed
+}
+
+func (h *harness) get(t *testing.T, id string) *models.Saga {
+	t.Helper()
+	saga, err := h.orchestrator.Get(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saga
+}
+
+func wantState(t *testing.T, saga *models.Saga, status string, step int) {
+	t.Helper()
+	if saga.Status != status || saga.Step != step {
+		t.Fatalf("saga is %s at step %d, want %s at step %d", saga.Status, saga.Step, status, step)
+	}
+}
+
+func TestSagaCompletes(t *testing.T) {
+	h := newHarness(t, "reserve", "charge", "confirm")
+	saga, err := h.orchestrator.Start(context.Background(), "", &order{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantState(t, h.get(t, saga.ID), models.SagaCompleted, 3)
+	h.journal.want(t, "reserve", "reserve local", "charge", "charge local", "confirm", "confirm local")
+	data, err := Decode[order](h.get(t, saga.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"reserve", "charge", "confirm"}; !reflect.DeepEqual(data.Notes, want) {
+		t.Fatalf("recorded data = %v, want %v", data.Notes, want)
+	}
+}
+
+func TestSagaCompensatesInReverse(t *testing.T) {
+	t.Run("aborted step", func(t *testing.T) {
+		h := newHarness(t, "reserve", "hold", "charge", "confirm")
+		h.fail["charge"] = func() error { return Abort(errors.New("declined")) }
+
+		saga, err := h.orchestrator.Start(context.Background(), "", &order{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The aborted step took no effect, so only the steps before it are undone
+		saga = h.get(t, saga.ID)
+		wantState(t, saga, models.SagaCompensated, 0)
+		if saga.Error != "charge: declined" {
+			t.Fatalf("saga error = %q", saga.Error)
+		}
+		h.journal.want(t, "reserve", "reserve local", "hold", "hold local", "charge",
+			"undo hold", "undo hold local", "undo reserve", "undo reserve local")
+	})
+
+	t.Run("step out of retries", func(t *testing.T) {
+		h := newHarness(t, "reserve", "charge", "confirm")
+		h.fail["charge"] = func() error { return errors.New("provider unavailable") }
+
+		saga, err := h.orchestrator.Start(context.Background(), "", &order{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for attempt := 2; attempt <= testAttempts; attempt++ {
+			wantState(t, h.get(t, saga.ID), models.SagaRunning, 1)
+			h.clock.Advance(testBackoff << (attempt - 2))
+			if resumed := h.recover(t); resumed != 1 {
+				t.Fatalf("attempt %d: resumed %d sagas, want 1", attempt, resumed)
+			}
+		}
+
+		// The last attempt may have charged, so the failing step is undone as well
+		wantState(t, h.get(t, saga.ID), models.SagaCompensated, 0)
+		h.journal.want(t, "reserve", "reserve local", "charge", "charge", "charge",
+			"undo charge", "undo charge local", "undo reserve", "undo reserve local")
+	})
+}
+
+func TestSagaRetriesWithBackoff(t *testing.T) {
+	h := newHarness(t, "reserve", "charge")
+	failures := 1
+	h.fail["charge"] = func() error {
+		if failures == 0 {
+			return nil
+		}
+		failures--
+		return errors.New("provider unavailable")
+	}
+
+	saga, err := h.orchestrator.Start(context.Background(), "", &order{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saga := h.get(t, saga.ID); saga.Attempts != 1 {
+		t.Fatalf("attempts = %d, want 1", saga.Attempts)
+	}
+
+	h.clock.Advance(testBackoff - time.Second)
+	if resumed := h.recover(t); resumed != 0 {
+		t.Fatalf("resumed %d sagas before the backoff ran out", resumed)
+	}
+	h.clock.Advance(time.Second)
+	if resumed := h.recover(t); resumed != 1 {
+		t.Fatalf("resumed %d sagas after the backoff, want 1", resumed)
+	}
+	wantState(t, h.get(t, saga.ID), models.SagaCompleted, 2)
+	h.journal.want(t, "reserve", "reserve local", "charge", "charge", "charge local")
+}
+
+// TestSagaResumesAfterCrash stops an instance dead in the middle of a step, leaving the saga
+// leased to it, and has another instance pick the saga up once the lease runs out
+func TestSagaResumesAfterCrash(t *testing.T) {
+	h := newHarness(t, "reserve", "charge", "confirm")
+	crashed := false
+	h.fail["charge"] = func() error {
+		if !crashed {
+			crashed = true
+			panic("instance crashed")
+		}
+		return nil
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("the step did not crash")
+			}
+		}()
+		_, _ = h.orchestrator.Start(context.Background(), "order-1", &order{})
+	}()
+	saga, err := h.orchestrator.repo.GetByKey(context.Background(), "test", "order-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantState(t, saga, models.SagaRunning, 1)
+
+	// Nobody takes over while the crashed instance's lease runs
+	if resumed := h.recover(t); resumed != 0 {
+		t.Fatalf("resumed %d sagas under a live lease", resumed)
+	}
+	if resumed, err := h.orchestrator.Resume(context.Background(), saga.ID); err != nil || resumed.Status != models.SagaRunning {
+		t.Fatalf("Resume under a live lease = %v, %v", resumed, err)
+	}
+
+	h.clock.Advance(lease)
+	if resumed := h.recover(t); resumed != 1 {
+		t.Fatalf("resumed %d sagas after the lease ran out, want 1", resumed)
+	}
+
+	// The recorded step isn't repeated; the interrupted one is, since it never got recorded
+	saga = h.get(t, saga.ID)
+	wantState(t, saga, models.SagaCompleted, 3)
+	if saga.LockedBy != "" || saga.LockedUntil != nil {
+		t.Fatalf("finished saga still leased to %q until %v", saga.LockedBy, saga.LockedUntil)
+	}
+	h.journal.want(t, "reserve", "reserve local", "charge", "charge", "charge local", "confirm", "confirm local")
+	data, err := Decode[order](saga)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"reserve", "charge", "confirm"}; !reflect.DeepEqual(data.Notes, want) {
+		t.Fatalf("recorded data = %v, want %v", data.Notes, want)
+	}
+}
+
+func TestSagaStartIsIdempotent(t *testing.T) {
+	h := newHarness(t, "reserve", "charge")
+	first, err := h.orchestrator.Start(context.Background(), "order-1", &order{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := h.orchestrator.Start(context.Background(), "order-1", &order{Notes: []string{"other"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != first.ID {
+		t.Fatalf("second start made saga %s, want %s", again.ID, first.ID)
+	}
+	wantState(t, again, models.SagaCompleted, 2)
+	h.journal.want(t, "reserve", "reserve local", "charge", "charge local")
+
+	// Another key is another saga
+	other, err := h.orchestrator.Start(context.Background(), "order-2", &order{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.ID == first.ID {
+		t.Fatal("a new key resumed the old saga")
+	}
+}
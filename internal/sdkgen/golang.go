@@ -0,0 +1,461 @@
+package sdkgen
+
+import (
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Go renders the Go client as a single package named pkg
+func Go(spec *Spec, pkg string) ([]byte, error) {
+	endpoints, _ := spec.Endpoints()
+	g := &goWriter{spec: spec}
+
+	g.line("// Code generated by `go run ./cmd sdk`. DO NOT EDIT.")
+	g.line("")
+	g.line("// Package %s is a client for the %s %s, generated from docs/swagger.json", pkg, spec.Info.Title, spec.Info.Version)
+	g.line("package %s", pkg)
+	g.line("")
+	g.line("import (")
+	for _, imp := range []string{"bytes", "context", "encoding/json", "fmt", "io", "net/http", "net/url", "strings", "sync", "time"} {
+		g.line("%q", imp)
+	}
+	g.line(")")
+	g.line("")
+	g.line("// DefaultBaseURL is the API root documented by the spec")
+	g.line("const DefaultBaseURL = %q", spec.DefaultBaseURL())
+	g.raw(goRuntime)
+
+	for _, name := range spec.DefinitionNames() {
+		g.model(name, spec.Definitions[name])
+	}
+	for _, ep := range endpoints {
+		if len(ep.QueryParams) > 0 {
+			g.params(ep)
+		}
+		g.method(ep)
+	}
+
+	src, err := format.Source([]byte(g.b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("format generated Go client: %w", err)
+	}
+	return src, nil
+}
+
+type goWriter struct {
+	spec *Spec
+	b    strings.Builder
+}
+
+func (g *goWriter) line(format string, args ...interface{}) {
+	fmt.Fprintf(&g.b, format, args...)
+	g.b.WriteByte('\n')
+}
+
+func (g *goWriter) raw(s string) {
+	g.b.WriteString(s)
+}
+
+func (g *goWriter) comment(lines ...string) {
+	for _, text := range lines {
+		for _, l := range strings.Split(strings.TrimSpace(text), "\n") {
+			if l != "" {
+				g.line("// %s", l)
+			}
+		}
+	}
+}
+
+// model renders a definition as a struct, or as a named type for non-object schemas
+func (g *goWriter) model(definition string, schema *Schema) {
+	name := g.spec.TypeName(definition)
+	g.line("")
+	if schema.Description != "" {
+		g.comment(name + " " + schema.Description)
+	} else {
+		g.comment(fmt.Sprintf("%s mirrors %s", name, definition))
+	}
+	if schema.Type != "object" || len(schema.Properties) == 0 {
+		g.line("type %s %s", name, g.goType(schema))
+		return
+	}
+
+	required := map[string]bool{}
+	for _, r := range schema.Required {
+		required[r] = true
+	}
+	props := make([]string, 0, len(schema.Properties))
+	for prop := range schema.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+
+	g.line("type %s struct {", name)
+	for _, prop := range props {
+		field := schema.Properties[prop]
+		if field.Description != "" {
+			g.comment(field.Description)
+		}
+		tag := prop
+		if !required[prop] {
+			tag += ",omitempty"
+		}
+		typ := g.goType(field)
+		if !required[prop] {
+			typ = g.goArgType(field)
+		}
+		g.line("%s %s `json:%q`", exportedName(prop), typ, tag)
+	}
+	g.line("}")
+}
+
+// params renders the struct holding an endpoint's query and header parameters
+func (g *goWriter) params(ep Endpoint) {
+	name := ep.Name + "Params"
+	g.line("")
+	g.comment(fmt.Sprintf("%s holds the optional parameters of %s; zero values are not sent", name, ep.Name))
+	g.line("type %s struct {", name)
+	for _, p := range ep.QueryParams {
+		if p.Description != "" {
+			g.comment(p.Description)
+		}
+		g.line("%s %s", exportedName(p.Name), g.goType(paramSchema(p)))
+	}
+	g.line("}")
+	g.line("")
+	g.line("func (p *%s) encode() (url.Values, http.Header) {", name)
+	g.line("query, header := url.Values{}, http.Header{}")
+	g.line("if p == nil {")
+	g.line("return query, header")
+	g.line("}")
+	for _, p := range ep.QueryParams {
+		field := "p." + exportedName(p.Name)
+		target := "query"
+		if p.In == "header" {
+			target = "header"
+		}
+		switch paramSchema(p).Type {
+		case "array":
+			g.line("for _, v := range %s {", field)
+			g.line("%s.Add(%q, fmt.Sprint(v))", target, p.Name)
+			g.line("}")
+		case "boolean":
+			g.line("if %s {", field)
+			g.line("%s.Set(%q, \"true\")", target, p.Name)
+			g.line("}")
+		case "string":
+			g.line("if %s != \"\" {", field)
+			g.line("%s.Set(%q, %s)", target, p.Name, field)
+			g.line("}")
+		default:
+			g.line("if %s != 0 {", field)
+			g.line("%s.Set(%q, fmt.Sprint(%s))", target, p.Name, field)
+			g.line("}")
+		}
+	}
+	g.line("return query, header")
+	g.line("}")
+}
+
+// method renders the client method for one endpoint
+func (g *goWriter) method(ep Endpoint) {
+	args := []string{"ctx context.Context"}
+	for _, p := range ep.PathParams {
+		args = append(args, fmt.Sprintf("%s %s", goParamName(p.Name), g.goType(paramSchema(p))))
+	}
+	if ep.Body != nil {
+		args = append(args, "body "+g.goArgType(ep.Body.Schema))
+	}
+	if len(ep.QueryParams) > 0 {
+		args = append(args, fmt.Sprintf("params *%sParams", ep.Name))
+	}
+
+	resultType, pointer := "", false
+	if ep.Result != nil {
+		resultType = g.goType(ep.Result)
+		pointer = ep.Result.Ref != ""
+	}
+
+	g.line("")
+	summary := ep.Summary
+	if summary == "" {
+		summary = ep.Method + " " + ep.Path
+	}
+	g.comment(fmt.Sprintf("%s sends %s %s - %s", ep.Name, ep.Method, ep.Path, summary))
+	if ep.Description != "" && ep.Description != ep.Summary {
+		g.comment(ep.Description)
+	}
+	if ep.Secured {
+		g.comment("Requires a bearer token, see SetToken")
+	}
+	if ep.SetsToken {
+		g.comment("On success the returned token is kept and sent with later requests")
+	}
+
+	switch {
+	case resultType == "":
+		g.line("func (c *Client) %s(%s) error {", ep.Name, strings.Join(args, ", "))
+	case pointer:
+		g.line("func (c *Client) %s(%s) (*%s, error) {", ep.Name, strings.Join(args, ", "), resultType)
+	default:
+		g.line("func (c *Client) %s(%s) (%s, error) {", ep.Name, strings.Join(args, ", "), resultType)
+	}
+
+	query, header := "nil", "nil"
+	if len(ep.QueryParams) > 0 {
+		g.line("query, header := params.encode()")
+		query, header = "query", "header"
+	}
+	body := "nil"
+	if ep.Body != nil {
+		body = "body"
+	}
+
+	call := fmt.Sprintf("c.do(ctx, %q, %s, %s, %s, %s", ep.Method, goPathExpr(ep), query, header, body)
+	switch {
+	case resultType == "":
+		g.line("return %s, nil)", call)
+	default:
+		g.line("var out %s", resultType)
+		g.line("if err := %s, &out); err != nil {", call)
+		if pointer {
+			g.line("return nil, err")
+		} else {
+			g.line("return out, err")
+		}
+		g.line("}")
+		if ep.SetsToken {
+			g.line("c.SetToken(out.Token)")
+		}
+		if pointer {
+			g.line("return &out, nil")
+		} else {
+			g.line("return out, nil")
+		}
+	}
+	g.line("}")
+}
+
+// goType maps a schema to a Go type expression
+func (g *goWriter) goType(s *Schema) string {
+	if s == nil {
+		return "json.RawMessage"
+	}
+	if s.Ref != "" {
+		return g.spec.TypeName(RefName(s.Ref))
+	}
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			return "time.Time"
+		}
+		return "string"
+	case "integer":
+		if s.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(s.Items)
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "map[string]" + g.goType(s.AdditionalProperties)
+		}
+	}
+	return "json.RawMessage"
+}
+
+// goArgType is goType, but named structs are pointers - for arguments and optional fields
+func (g *goWriter) goArgType(s *Schema) string {
+	if s != nil && s.Ref != "" {
+		if def := g.spec.Definitions[RefName(s.Ref)]; def != nil && def.Type == "object" {
+			return "*" + g.goType(s)
+		}
+	}
+	return g.goType(s)
+}
+
+// goPathExpr builds the request path, escaping each path parameter
+func goPathExpr(ep Endpoint) string {
+	expr := []string{}
+	rest := ep.Path
+	for {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}") + start
+		expr = append(expr, fmt.Sprintf("%q", rest[:start]))
+		expr = append(expr, fmt.Sprintf("pathEscape(%s)", goParamName(rest[start+1:end])))
+		rest = rest[end+1:]
+	}
+	if rest != "" || len(expr) == 0 {
+		expr = append(expr, fmt.Sprintf("%q", rest))
+	}
+	return strings.Join(expr, "+")
+}
+
+// goParamName turns a parameter name into an unexported identifier that is not a keyword
+func goParamName(name string) string {
+	ident := lowerFirst(exportedName(name))
+	switch ident {
+	case "type", "func", "range", "map", "default", "select", "case", "go", "chan", "var", "const", "package", "import", "interface", "struct", "return", "break", "continue", "for", "if", "else", "switch", "goto", "fallthrough", "defer":
+		return ident + "Param"
+	}
+	return ident
+}
+
+// paramSchema gives non-body parameters the same shape as a schema
+func paramSchema(p Parameter) *Schema {
+	if p.Schema != nil {
+		return p.Schema
+	}
+	return &Schema{Type: p.Type, Format: p.Format, Items: p.Items}
+}
+
+// GoFile is where the Go client is written below the output directory
+func GoFile(outDir string) string {
+	return filepath.Join(outDir, "ecomgo", "client.go")
+}
+
+// goRuntime is the hand-written part of the Go client: transport, auth and errors
+const goRuntime = `
+// Client calls the API. It is safe for concurrent use
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	userAgent  string
+
+	mu    sync.RWMutex
+	token string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests (default: 30s timeout)
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithToken starts the client with a bearer token, e.g. a service account's JWT
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithUserAgent identifies the calling service in the server's access logs
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+// NewClient returns a client for the API at baseURL, e.g. https://shop.example.com/api/v1
+// An empty baseURL means DefaultBaseURL
+func NewClient(baseURL string, opts ...Option) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// SetToken replaces the bearer token sent with every request; empty clears it
+// Methods that sign in (their response carries a token) call it for you
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+}
+
+// Token returns the bearer token currently in use
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// APIError is returned for any non-2xx response
+// The API answers errors with a plain text message, kept in Message
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("ecomgo: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// do sends one request and decodes a JSON (or, for string results, plain text) response into out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body, out interface{}) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if token := c.Token(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if text, ok := out.(*string); ok {
+		data, err := io.ReadAll(resp.Body)
+		*text = strings.TrimSpace(string(data))
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func pathEscape(v interface{}) string {
+	return url.PathEscape(fmt.Sprint(v))
+}
+`
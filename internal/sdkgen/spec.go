@@ -0,0 +1,316 @@
+// Package sdkgen generates Go and TypeScript API clients from the Swagger 2.0 document
+// swag writes to docs/swagger.json (`go run ./cmd sdk`, or `make sdk`). The generated
+// clients are committed under sdk/ so internal services and the storefront can import
+// them instead of hand-rolling HTTP calls; regenerate them whenever the spec changes.
+package sdkgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Spec is the subset of a Swagger 2.0 document the generators understand
+type Spec struct {
+	Info struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Version     string `json:"version"`
+	} `json:"info"`
+	Host        string                          `json:"host"`
+	BasePath    string                          `json:"basePath"`
+	Schemes     []string                        `json:"schemes"`
+	Paths       map[string]map[string]Operation `json:"paths"`
+	Definitions map[string]*Schema              `json:"definitions"`
+}
+
+// Operation is one method on one path
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description"`
+	Tags        []string              `json:"tags"`
+	Parameters  []Parameter           `json:"parameters"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+// Parameter is a path, query, header or body parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Type        string  `json:"type"`
+	Format      string  `json:"format"`
+	Items       *Schema `json:"items"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Response is the documented response for one status code
+type Response struct {
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Schema is a JSON schema as used by Swagger 2.0 definitions
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Description          string             `json:"description"`
+	Items                *Schema            `json:"items"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	Required             []string           `json:"required"`
+	Enum                 []interface{}      `json:"enum"`
+}
+
+// Load reads and parses a Swagger 2.0 JSON document
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw struct {
+		Swagger string `json:"swagger"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if raw.Swagger != "2.0" {
+		return nil, fmt.Errorf("%s: unsupported swagger version %q (want 2.0)", path, raw.Swagger)
+	}
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &spec, nil
+}
+
+// Endpoint is an operation resolved into the shape both generators emit
+type Endpoint struct {
+	Name        string // exported method name, e.g. GetUserByID
+	Method      string // upper case HTTP method
+	Path        string // path below basePath, with {param} placeholders
+	Summary     string
+	Description string
+	Secured     bool
+	PathParams  []Parameter
+	QueryParams []Parameter // query and header parameters, sent from the params struct
+	Body        *Parameter
+	Result      *Schema // schema of the success response; nil when it has no body
+	SetsToken   bool    // the result carries a token the client should keep, e.g. login
+}
+
+// Endpoints returns every supported operation sorted by path and method
+// Operations with formData parameters (multipart uploads) are reported in skipped
+func (s *Spec) Endpoints() (endpoints []Endpoint, skipped []string) {
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	used := map[string]int{}
+	for _, path := range paths {
+		methods := make([]string, 0, len(s.Paths[path]))
+		for method := range s.Paths[path] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			op := s.Paths[path][method]
+			ep := Endpoint{
+				Method:      strings.ToUpper(method),
+				Path:        path,
+				Summary:     op.Summary,
+				Description: op.Description,
+				Secured:     len(op.Security) > 0,
+			}
+
+			supported := true
+			for i := range op.Parameters {
+				param := op.Parameters[i]
+				switch param.In {
+				case "path":
+					ep.PathParams = append(ep.PathParams, param)
+				case "query", "header":
+					ep.QueryParams = append(ep.QueryParams, param)
+				case "body":
+					ep.Body = &param
+				default:
+					supported = false
+				}
+			}
+			if !supported {
+				skipped = append(skipped, ep.Method+" "+path)
+				continue
+			}
+
+			ep.Result = successSchema(op.Responses)
+			ep.SetsToken = s.hasTokenField(ep.Result)
+
+			ep.Name = operationName(op, method, path)
+			used[ep.Name]++
+			if n := used[ep.Name]; n > 1 {
+				ep.Name += strconv.Itoa(n)
+			}
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints, skipped
+}
+
+// DefinitionNames returns the definition keys sorted, so output is stable between runs
+func (s *Spec) DefinitionNames() []string {
+	names := make([]string, 0, len(s.Definitions))
+	for name := range s.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TypeName maps a definition key such as models.User to the generated type name
+// The package qualifier is dropped unless two definitions would then share a name
+func (s *Spec) TypeName(definition string) string {
+	short := definition[strings.LastIndex(definition, ".")+1:]
+	for other := range s.Definitions {
+		if other != definition && other[strings.LastIndex(other, ".")+1:] == short {
+			return exportedName(definition)
+		}
+	}
+	return exportedName(short)
+}
+
+// RefName returns the definition key a $ref points to
+func RefName(ref string) string {
+	return strings.TrimPrefix(ref, "#/definitions/")
+}
+
+// DefaultBaseURL is the API root the spec advertises, e.g. http://localhost:8085/api/v1
+func (s *Spec) DefaultBaseURL() string {
+	if s.Host == "" {
+		return s.BasePath
+	}
+	scheme := "http"
+	if len(s.Schemes) > 0 {
+		scheme = s.Schemes[0]
+	}
+	return scheme + "://" + s.Host + s.BasePath
+}
+
+// successSchema picks the lowest documented 2xx response
+func successSchema(responses map[string]Response) *Schema {
+	best := ""
+	for code := range responses {
+		if strings.HasPrefix(code, "2") && (best == "" || code < best) {
+			best = code
+		}
+	}
+	if best == "" {
+		return nil
+	}
+	return responses[best].Schema
+}
+
+// hasTokenField reports whether schema is an object with a string "token" property
+func (s *Spec) hasTokenField(schema *Schema) bool {
+	if schema == nil {
+		return false
+	}
+	if schema.Ref != "" {
+		schema = s.Definitions[RefName(schema.Ref)]
+		if schema == nil {
+			return false
+		}
+	}
+	token, ok := schema.Properties["token"]
+	return ok && token.Type == "string"
+}
+
+// operationName prefers an explicit @ID, then the summary, then method and path
+func operationName(op Operation, method, path string) string {
+	if op.OperationID != "" {
+		return exportedName(op.OperationID)
+	}
+	if name := exportedName(op.Summary); name != "" {
+		return name
+	}
+	return exportedName(method + " " + strings.NewReplacer("{", "by ", "}", "").Replace(path))
+}
+
+// initialisms are kept upper case in generated names, as Go style asks
+var initialisms = map[string]bool{
+	"API": true, "HTTP": true, "ID": true, "IP": true, "JSON": true, "JWT": true,
+	"OTP": true, "SKU": true, "SMS": true, "URL": true, "UUID": true,
+}
+
+// exportedName converts "get user by id", "created_at" or "models.User" to GetUserByID, CreatedAt, ModelsUser
+func exportedName(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, word := range words {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	name := b.String()
+	if name != "" && unicode.IsDigit([]rune(name)[0]) {
+		name = "N" + name
+	}
+	return name
+}
+
+// lowerFirst turns an exported name into a method or variable name: GetUserByID -> getUserByID
+func lowerFirst(name string) string {
+	for prefix := range initialisms {
+		if strings.HasPrefix(name, prefix) && (len(name) == len(prefix) || unicode.IsUpper(rune(name[len(prefix)]))) {
+			return strings.ToLower(prefix) + name[len(prefix):]
+		}
+	}
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// Generate writes both clients below outDir and returns the files written
+func Generate(spec *Spec, outDir string) ([]string, error) {
+	goSrc, err := Go(spec, "ecomgo")
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{
+		GoFile(outDir):         goSrc,
+		TypeScriptFile(outDir): TypeScript(spec),
+	}
+
+	written := make([]string, 0, len(files))
+	for path, data := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return nil, err
+		}
+		written = append(written, path)
+	}
+	sort.Strings(written)
+	return written, nil
+}
@@ -0,0 +1,371 @@
+package sdkgen
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TypeScript renders the TypeScript client as a single fetch-based module
+func TypeScript(spec *Spec) []byte {
+	endpoints, _ := spec.Endpoints()
+	t := &tsWriter{spec: spec}
+	client := exportedName(strings.TrimSuffix(spec.Info.Title, " API")) + "Client"
+
+	t.line("// Code generated by `go run ./cmd sdk`. DO NOT EDIT.")
+	t.line("// Client for the %s %s, generated from docs/swagger.json", spec.Info.Title, spec.Info.Version)
+	t.line("")
+	t.line("export const DEFAULT_BASE_URL = %q;", spec.DefaultBaseURL())
+	t.raw(tsRuntime)
+
+	for _, name := range spec.DefinitionNames() {
+		t.model(name, spec.Definitions[name])
+	}
+	for _, ep := range endpoints {
+		if len(ep.QueryParams) > 0 {
+			t.params(ep)
+		}
+	}
+
+	t.line("")
+	t.line("/** Calls the API. Keeps the bearer token between calls; sign-in methods set it for you. */")
+	t.line("export class %s extends BaseClient {", client)
+	for i, ep := range endpoints {
+		if i > 0 {
+			t.line("")
+		}
+		t.method(ep)
+	}
+	t.line("}")
+	return []byte(t.b.String())
+}
+
+type tsWriter struct {
+	spec *Spec
+	b    strings.Builder
+}
+
+func (t *tsWriter) line(format string, args ...interface{}) {
+	fmt.Fprintf(&t.b, format, args...)
+	t.b.WriteByte('\n')
+}
+
+func (t *tsWriter) raw(s string) {
+	t.b.WriteString(s)
+}
+
+func (t *tsWriter) doc(indent string, lines ...string) {
+	var text []string
+	for _, l := range lines {
+		if l = strings.TrimSpace(l); l != "" {
+			text = append(text, strings.Split(l, "\n")...)
+		}
+	}
+	switch len(text) {
+	case 0:
+	case 1:
+		t.line("%s/** %s */", indent, text[0])
+	default:
+		t.line("%s/**", indent)
+		for _, l := range text {
+			t.line("%s * %s", indent, l)
+		}
+		t.line("%s */", indent)
+	}
+}
+
+// model renders a definition as an interface, or a type alias for non-object schemas
+func (t *tsWriter) model(definition string, schema *Schema) {
+	name := t.spec.TypeName(definition)
+	t.line("")
+	t.doc("", schema.Description)
+	if schema.Type != "object" || len(schema.Properties) == 0 {
+		t.line("export type %s = %s;", name, t.tsType(schema))
+		return
+	}
+
+	required := map[string]bool{}
+	for _, r := range schema.Required {
+		required[r] = true
+	}
+	props := make([]string, 0, len(schema.Properties))
+	for prop := range schema.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+
+	t.line("export interface %s {", name)
+	for _, prop := range props {
+		field := schema.Properties[prop]
+		t.doc("  ", field.Description)
+		optional := "?"
+		if required[prop] {
+			optional = ""
+		}
+		t.line("  %s%s: %s;", tsPropName(prop), optional, t.tsType(field))
+	}
+	t.line("}")
+}
+
+// params renders the interface holding an endpoint's query and header parameters
+func (t *tsWriter) params(ep Endpoint) {
+	t.line("")
+	t.line("export interface %sParams {", ep.Name)
+	for _, p := range ep.QueryParams {
+		t.doc("  ", p.Description)
+		optional := "?"
+		if p.Required {
+			optional = ""
+		}
+		t.line("  %s%s: %s;", tsPropName(p.Name), optional, t.tsType(paramSchema(p)))
+	}
+	t.line("}")
+}
+
+// method renders the client method for one endpoint
+func (t *tsWriter) method(ep Endpoint) {
+	var args []string
+	for _, p := range ep.PathParams {
+		args = append(args, fmt.Sprintf("%s: %s", lowerFirst(exportedName(p.Name)), t.tsType(paramSchema(p))))
+	}
+	if ep.Body != nil {
+		args = append(args, "body: "+t.tsType(ep.Body.Schema))
+	}
+	if len(ep.QueryParams) > 0 {
+		args = append(args, fmt.Sprintf("params: %sParams = {}", ep.Name))
+	}
+	args = append(args, "options: CallOptions = {}")
+
+	result := "void"
+	if ep.Result != nil {
+		result = t.tsType(ep.Result)
+	}
+
+	var notes []string
+	summary := ep.Summary
+	if summary == "" {
+		summary = ep.Method + " " + ep.Path
+	}
+	notes = append(notes, fmt.Sprintf("%s (%s %s)", summary, ep.Method, ep.Path))
+	if ep.Description != "" && ep.Description != ep.Summary {
+		notes = append(notes, ep.Description)
+	}
+	if ep.Secured {
+		notes = append(notes, "Requires a bearer token, see setToken")
+	}
+	if ep.SetsToken {
+		notes = append(notes, "On success the returned token is kept and sent with later requests")
+	}
+	t.doc("  ", strings.Join(notes, "\n"))
+
+	t.line("  async %s(%s): Promise<%s> {", lowerFirst(ep.Name), strings.Join(args, ", "), result)
+	var query, header []string
+	for _, p := range ep.QueryParams {
+		entry := fmt.Sprintf("%q: params%s", p.Name, tsAccessor(p.Name))
+		if p.In == "header" {
+			header = append(header, entry)
+		} else {
+			query = append(query, entry)
+		}
+	}
+	call := []string{fmt.Sprintf("method: %q", ep.Method), "path: " + tsPathExpr(ep)}
+	if len(query) > 0 {
+		call = append(call, "query: { "+strings.Join(query, ", ")+" }")
+	}
+	if len(header) > 0 {
+		call = append(call, "headers: { "+strings.Join(header, ", ")+" }")
+	}
+	if ep.Body != nil {
+		call = append(call, "body")
+	}
+	if ep.Result != nil && ep.Result.Type == "string" {
+		call = append(call, "text: true")
+	}
+	call = append(call, "...options")
+
+	if ep.SetsToken {
+		t.line("    const result = await this.request<%s>({ %s });", result, strings.Join(call, ", "))
+		t.line("    this.setToken(result.token);")
+		t.line("    return result;")
+	} else {
+		t.line("    return this.request<%s>({ %s });", result, strings.Join(call, ", "))
+	}
+	t.line("  }")
+}
+
+// tsType maps a schema to a TypeScript type expression
+func (t *tsWriter) tsType(s *Schema) string {
+	if s == nil {
+		return "unknown"
+	}
+	if s.Ref != "" {
+		return t.spec.TypeName(RefName(s.Ref))
+	}
+	switch s.Type {
+	case "string":
+		if len(s.Enum) > 0 {
+			values := make([]string, len(s.Enum))
+			for i, v := range s.Enum {
+				values[i] = fmt.Sprintf("%q", fmt.Sprint(v))
+			}
+			return strings.Join(values, " | ")
+		}
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		item := t.tsType(s.Items)
+		if strings.Contains(item, " ") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "Record<string, " + t.tsType(s.AdditionalProperties) + ">"
+		}
+		return "Record<string, unknown>"
+	}
+	return "unknown"
+}
+
+// tsPathExpr builds the request path as a template literal, encoding each path parameter
+func tsPathExpr(ep Endpoint) string {
+	if !strings.Contains(ep.Path, "{") {
+		return fmt.Sprintf("%q", ep.Path)
+	}
+	var b strings.Builder
+	b.WriteByte('`')
+	rest := ep.Path
+	for {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "}") + start
+		b.WriteString(rest[:start])
+		fmt.Fprintf(&b, "${encodeURIComponent(String(%s))}", lowerFirst(exportedName(rest[start+1:end])))
+		rest = rest[end+1:]
+	}
+	b.WriteString(rest)
+	b.WriteByte('`')
+	return b.String()
+}
+
+// tsPropName quotes property names that are not valid identifiers, e.g. X-Request-ID
+func tsPropName(name string) string {
+	for i, r := range name {
+		if !(r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return fmt.Sprintf("%q", name)
+		}
+	}
+	return name
+}
+
+func tsAccessor(name string) string {
+	if prop := tsPropName(name); prop != name {
+		return "[" + prop + "]"
+	}
+	return "." + name
+}
+
+// TypeScriptFile is where the TypeScript client is written below the output directory
+func TypeScriptFile(outDir string) string {
+	return filepath.Join(outDir, "typescript", "src", "index.ts")
+}
+
+// tsRuntime is the hand-written part of the TypeScript client: transport, auth and errors
+const tsRuntime = `
+export interface ClientOptions {
+  /** API root, e.g. https://shop.example.com/api/v1 (default: DEFAULT_BASE_URL) */
+  baseUrl?: string;
+  /** Initial bearer token, e.g. restored from storage */
+  token?: string;
+  /** fetch implementation; defaults to the global fetch */
+  fetch?: typeof fetch;
+  /** Called whenever the token changes, so the storefront can persist or clear it */
+  onTokenChange?: (token: string | undefined) => void;
+}
+
+export interface CallOptions {
+  signal?: AbortSignal;
+}
+
+/** Thrown for any non-2xx response. The API answers errors with a plain text message. */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    message: string,
+  ) {
+    super(message || ` + "`HTTP ${status}`" + `);
+    this.name = "ApiError";
+  }
+}
+
+type QueryValue = string | number | boolean | Array<string | number | boolean> | undefined;
+
+interface RequestSpec extends CallOptions {
+  method: string;
+  path: string;
+  query?: Record<string, QueryValue>;
+  headers?: Record<string, QueryValue>;
+  body?: unknown;
+  text?: boolean;
+}
+
+class BaseClient {
+  private readonly baseUrl: string;
+  private readonly fetchImpl: typeof fetch;
+  private readonly onTokenChange?: (token: string | undefined) => void;
+  private token?: string;
+
+  constructor(options: ClientOptions = {}) {
+    this.baseUrl = (options.baseUrl ?? DEFAULT_BASE_URL).replace(/\/+$/, "");
+    this.fetchImpl = options.fetch ?? ((input, init) => fetch(input, init));
+    this.onTokenChange = options.onTokenChange;
+    this.token = options.token;
+  }
+
+  /** Replaces the bearer token sent with every request; undefined clears it. */
+  setToken(token: string | undefined): void {
+    this.token = token || undefined;
+    this.onTokenChange?.(this.token);
+  }
+
+  getToken(): string | undefined {
+    return this.token;
+  }
+
+  protected async request<T>(spec: RequestSpec): Promise<T> {
+    const query = new URLSearchParams();
+    for (const [key, value] of Object.entries(spec.query ?? {})) {
+      for (const v of Array.isArray(value) ? value : [value]) {
+        if (v !== undefined && v !== "") query.append(key, String(v));
+      }
+    }
+    const headers: Record<string, string> = { Accept: "application/json" };
+    for (const [key, value] of Object.entries(spec.headers ?? {})) {
+      if (value !== undefined && value !== "") headers[key] = String(value);
+    }
+    if (spec.body !== undefined) headers["Content-Type"] = "application/json";
+    if (this.token) headers["Authorization"] = ` + "`Bearer ${this.token}`" + `;
+
+    const qs = query.toString();
+    const response = await this.fetchImpl(this.baseUrl + spec.path + (qs ? "?" + qs : ""), {
+      method: spec.method,
+      headers,
+      body: spec.body === undefined ? undefined : JSON.stringify(spec.body),
+      signal: spec.signal,
+    });
+
+    if (!response.ok) {
+      throw new ApiError(response.status, (await response.text()).trim());
+    }
+    if (response.status === 204) return undefined as T;
+    if (spec.text) return (await response.text()).trim() as T;
+    return (await response.json()) as T;
+  }
+}
+`
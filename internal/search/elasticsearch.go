@@ -0,0 +1,268 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+// errESNotFound is returned for 404 responses (missing index or alias)
+var errESNotFound = errors.New("elasticsearch: not found")
+
+// esMapping keeps SKU and category exact-match while name/description are analyzed text
+var esMapping = map[string]interface{}{
+	"mappings": map[string]interface{}{
+		"properties": map[string]interface{}{
+			"id":          map[string]string{"type": "keyword"},
+			"sku":         map[string]string{"type": "keyword"},
+			"name":        map[string]string{"type": "text"},
+			"description": map[string]string{"type": "text"},
+			"category":    map[string]string{"type": "keyword"},
+			"price":       map[string]string{"type": "long"},
+			"currency":    map[string]string{"type": "keyword"},
+			"in_stock":    map[string]string{"type": "boolean"},
+			"updated_at":  map[string]string{"type": "date"},
+		},
+	},
+}
+
+// Elasticsearch indexes products in Elasticsearch (or OpenSearch)
+// The alias points at one physical index; rebuilds repoint it with a single _aliases call
+type Elasticsearch struct {
+	baseURL  string
+	apiKey   string
+	username string
+	password string
+	client   *http.Client
+}
+
+func NewElasticsearch(cfg config.Search) *Elasticsearch {
+	return &Elasticsearch{
+		baseURL:  cfg.URL,
+		apiKey:   cfg.APIKey,
+		username: cfg.Username,
+		password: cfg.Password,
+		client:   httpclient.New(15 * time.Second),
+	}
+}
+
+func (e *Elasticsearch) Name() string {
+	return "elasticsearch"
+}
+
+// do sends a request; body is JSON unless it is already a *bytes.Buffer (NDJSON for _bulk)
+func (e *Elasticsearch) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	contentType := "application/json"
+	switch b := body.(type) {
+	case nil:
+	case *bytes.Buffer:
+		reader = b
+		contentType = "application/x-ndjson"
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, e.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	switch {
+	case e.apiKey != "":
+		req.Header.Set("Authorization", "ApiKey "+e.apiKey)
+	case e.username != "":
+		req.SetBasicAuth(e.username, e.password)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("elasticsearch %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errESNotFound
+	}
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("elasticsearch %s %s: status %d: %s", method, path, resp.StatusCode, detail)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// aliasTargets returns the physical indexes alias currently points at
+func (e *Elasticsearch) aliasTargets(ctx context.Context, alias string) ([]string, error) {
+	var out map[string]json.RawMessage
+	err := e.do(ctx, http.MethodGet, "/_alias/"+url.PathEscape(alias), nil, &out)
+	if errors.Is(err, errESNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([]string, 0, len(out))
+	for index := range out {
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+func (e *Elasticsearch) EnsureIndex(ctx context.Context, alias string) error {
+	targets, err := e.aliasTargets(ctx, alias)
+	if err != nil || len(targets) > 0 {
+		return err
+	}
+
+	index := BuildName(alias)
+	if err := e.CreateIndex(ctx, index); err != nil {
+		return err
+	}
+	return e.Promote(ctx, alias, index)
+}
+
+func (e *Elasticsearch) CreateIndex(ctx context.Context, index string) error {
+	return e.do(ctx, http.MethodPut, "/"+url.PathEscape(index), esMapping, nil)
+}
+
+func (e *Elasticsearch) Upsert(ctx context.Context, index string, docs []models.ProductDocument) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, doc := range docs {
+		enc.Encode(map[string]interface{}{"index": map[string]string{"_id": doc.ID}})
+		enc.Encode(doc)
+	}
+	return e.bulk(ctx, index, &body)
+}
+
+func (e *Elasticsearch) Delete(ctx context.Context, index string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, id := range ids {
+		enc.Encode(map[string]interface{}{"delete": map[string]string{"_id": id}})
+	}
+	return e.bulk(ctx, index, &body)
+}
+
+// bulk sends an NDJSON _bulk request; per-item failures (other than deleting missing docs) are errors
+func (e *Elasticsearch) bulk(ctx context.Context, index string, body *bytes.Buffer) error {
+	var out struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := e.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_bulk", body, &out); err != nil {
+		return err
+	}
+	if !out.Errors {
+		return nil
+	}
+
+	for _, item := range out.Items {
+		for action, result := range item {
+			if result.Status >= 300 && !(action == "delete" && result.Status == http.StatusNotFound) {
+				return fmt.Errorf("elasticsearch bulk %s: status %d: %s", action, result.Status, result.Error)
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Elasticsearch) Search(ctx context.Context, index string, q Query) (*Results, error) {
+	must := []interface{}{map[string]interface{}{"match_all": map[string]interface{}{}}}
+	if q.Text != "" {
+		must = []interface{}{map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":     q.Text,
+				"fields":    []string{"name^3", "sku^4", "category^2", "description"},
+				"fuzziness": "AUTO",
+			},
+		}}
+	}
+	boolQuery := map[string]interface{}{"must": must}
+	if q.Category != "" {
+		boolQuery["filter"] = []interface{}{map[string]interface{}{"term": map[string]string{"category": q.Category}}}
+	}
+
+	body := map[string]interface{}{
+		"query": map[string]interface{}{"bool": boolQuery},
+		"from":  q.Offset,
+		"size":  q.Limit,
+	}
+
+	var out struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Source models.ProductDocument `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := e.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", body, &out); err != nil {
+		return nil, err
+	}
+
+	results := &Results{Total: out.Hits.Total.Value, Hits: make([]models.ProductDocument, 0, len(out.Hits.Hits))}
+	for _, hit := range out.Hits.Hits {
+		results.Hits = append(results.Hits, hit.Source)
+	}
+	return results, nil
+}
+
+// Promote repoints alias to index in one atomic _aliases call, then deletes the previous indexes
+func (e *Elasticsearch) Promote(ctx context.Context, alias, index string) error {
+	previous, err := e.aliasTargets(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	actions := []interface{}{map[string]interface{}{"add": map[string]interface{}{
+		"index": index, "alias": alias, "is_write_index": true,
+	}}}
+	for _, old := range previous {
+		actions = append(actions, map[string]interface{}{"remove": map[string]string{"index": old, "alias": alias}})
+	}
+	if err := e.do(ctx, http.MethodPost, "/_aliases", map[string]interface{}{"actions": actions}, nil); err != nil {
+		return err
+	}
+
+	for _, old := range previous {
+		if old == index {
+			continue
+		}
+		if err := e.do(ctx, http.MethodDelete, "/"+url.PathEscape(old), nil, nil); err != nil && !errors.Is(err, errESNotFound) {
+			return err
+		}
+	}
+	return nil
+}
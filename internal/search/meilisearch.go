@@ -0,0 +1,204 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+// errMeiliNotFound is returned for 404 responses (missing index or task)
+var errMeiliNotFound = errors.New("meilisearch: not found")
+
+// meiliSettings makes the fields used by search filters filterable
+var meiliSettings = map[string]interface{}{
+	"searchableAttributes": []string{"name", "sku", "category", "description"},
+	"filterableAttributes": []string{"category", "in_stock"},
+	"sortableAttributes":   []string{"price", "updated_at"},
+}
+
+// Meilisearch indexes products in Meilisearch
+// Meilisearch has no aliases: the alias is a real index and rebuilds are exchanged with /swap-indexes
+// Writes are asynchronous tasks; only index management waits for completion
+type Meilisearch struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewMeilisearch(cfg config.Search) *Meilisearch {
+	return &Meilisearch{
+		baseURL: cfg.URL,
+		apiKey:  cfg.APIKey,
+		client:  httpclient.New(15 * time.Second),
+	}
+}
+
+func (m *Meilisearch) Name() string {
+	return "meilisearch"
+}
+
+// task is the summarized task returned by asynchronous Meilisearch operations
+type task struct {
+	TaskUID int64 `json:"taskUid"`
+}
+
+func (m *Meilisearch) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, m.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("meilisearch %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errMeiliNotFound
+	}
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("meilisearch %s %s: status %d: %s", method, path, resp.StatusCode, detail)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// wait polls a task until it finishes
+func (m *Meilisearch) wait(ctx context.Context, t task) error {
+	for {
+		var status struct {
+			Status string `json:"status"`
+			Error  *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := m.do(ctx, http.MethodGet, "/tasks/"+strconv.FormatInt(t.TaskUID, 10), nil, &status); err != nil {
+			return err
+		}
+
+		switch status.Status {
+		case "succeeded":
+			return nil
+		case "failed", "canceled":
+			if status.Error != nil {
+				return fmt.Errorf("meilisearch task %d %s: %s", t.TaskUID, status.Status, status.Error.Message)
+			}
+			return fmt.Errorf("meilisearch task %d %s", t.TaskUID, status.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+func (m *Meilisearch) EnsureIndex(ctx context.Context, alias string) error {
+	err := m.do(ctx, http.MethodGet, "/indexes/"+url.PathEscape(alias), nil, nil)
+	if errors.Is(err, errMeiliNotFound) {
+		return m.CreateIndex(ctx, alias)
+	}
+	return err
+}
+
+func (m *Meilisearch) CreateIndex(ctx context.Context, index string) error {
+	var created task
+	if err := m.do(ctx, http.MethodPost, "/indexes", map[string]string{"uid": index, "primaryKey": "id"}, &created); err != nil {
+		return err
+	}
+	if err := m.wait(ctx, created); err != nil {
+		return err
+	}
+
+	var configured task
+	if err := m.do(ctx, http.MethodPatch, "/indexes/"+url.PathEscape(index)+"/settings", meiliSettings, &configured); err != nil {
+		return err
+	}
+	return m.wait(ctx, configured)
+}
+
+func (m *Meilisearch) Upsert(ctx context.Context, index string, docs []models.ProductDocument) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	return m.do(ctx, http.MethodPost, "/indexes/"+url.PathEscape(index)+"/documents", docs, nil)
+}
+
+func (m *Meilisearch) Delete(ctx context.Context, index string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return m.do(ctx, http.MethodPost, "/indexes/"+url.PathEscape(index)+"/documents/delete-batch", ids, nil)
+}
+
+func (m *Meilisearch) Search(ctx context.Context, index string, q Query) (*Results, error) {
+	body := map[string]interface{}{
+		"q":      q.Text,
+		"limit":  q.Limit,
+		"offset": q.Offset,
+	}
+	if q.Category != "" {
+		body["filter"] = "category = " + strconv.Quote(q.Category)
+	}
+
+	var out struct {
+		Hits               []models.ProductDocument `json:"hits"`
+		EstimatedTotalHits int64                    `json:"estimatedTotalHits"`
+	}
+	if err := m.do(ctx, http.MethodPost, "/indexes/"+url.PathEscape(index)+"/search", body, &out); err != nil {
+		return nil, err
+	}
+	return &Results{Hits: out.Hits, Total: out.EstimatedTotalHits}, nil
+}
+
+// Promote swaps the rebuilt index's contents into alias, then deletes index (now holding the old data)
+// Tasks run in enqueue order, so documents added to index before the swap are included
+func (m *Meilisearch) Promote(ctx context.Context, alias, index string) error {
+	if err := m.EnsureIndex(ctx, alias); err != nil {
+		return err
+	}
+
+	var swapped task
+	swap := []map[string][]string{{"indexes": {alias, index}}}
+	if err := m.do(ctx, http.MethodPost, "/swap-indexes", swap, &swapped); err != nil {
+		return err
+	}
+	if err := m.wait(ctx, swapped); err != nil {
+		return err
+	}
+
+	var deleted task
+	if err := m.do(ctx, http.MethodDelete, "/indexes/"+url.PathEscape(index), nil, &deleted); err != nil {
+		return err
+	}
+	return m.wait(ctx, deleted)
+}
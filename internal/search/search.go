@@ -0,0 +1,74 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+// Query is a product search request
+type Query struct {
+	Text     string
+	Category string // exact match filter; empty means any
+	Limit    int
+	Offset   int
+}
+
+// Results is a page of matching documents
+type Results struct {
+	Hits  []models.ProductDocument
+	Total int64
+}
+
+// Engine is a product search backend (Meilisearch, Elasticsearch)
+// Reads and writes go through an alias so full rebuilds can be swapped in atomically
+type Engine interface {
+	// Name returns the backend name for logging, e.g. "meilisearch"
+	Name() string
+
+	// EnsureIndex makes alias usable, creating a first index behind it if needed
+	EnsureIndex(ctx context.Context, alias string) error
+
+	// CreateIndex creates an empty, configured index to rebuild into
+	CreateIndex(ctx context.Context, index string) error
+
+	// Upsert adds or replaces documents by ID
+	Upsert(ctx context.Context, index string, docs []models.ProductDocument) error
+
+	// Delete removes documents by ID; missing IDs are ignored
+	Delete(ctx context.Context, index string, ids []string) error
+
+	// Search runs q against index
+	Search(ctx context.Context, index string, q Query) (*Results, error)
+
+	// Promote atomically serves index under alias and drops the index previously behind it
+	Promote(ctx context.Context, alias, index string) error
+}
+
+// New returns the engine selected by SEARCH_BACKEND, or nil for none
+func New(cfg config.Search) (Engine, error) {
+	switch cfg.Backend {
+	case "none", "":
+		return nil, nil
+	case "meilisearch":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("SEARCH_URL must be set for the meilisearch backend")
+		}
+		return NewMeilisearch(cfg), nil
+	case "elasticsearch":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("SEARCH_URL must be set for the elasticsearch backend")
+		}
+		return NewElasticsearch(cfg), nil
+	default:
+		return nil, fmt.Errorf("unsupported SEARCH_BACKEND: %s (must be none, meilisearch or elasticsearch)", cfg.Backend)
+	}
+}
+
+// BuildName returns a unique physical index name for a rebuild of alias
+func BuildName(alias string) string {
+	return fmt.Sprintf("%s_%s", alias, time.Now().UTC().Format("20060102150405"))
+}
@@ -0,0 +1,311 @@
+// Package settings holds store-wide values admins change at runtime (store name, support
+// email, default currency, order number prefix, vendor commission, referral rewards, Q&A
+// moderation). Services read them through Store on every use instead of from config, so an
+// edit applies everywhere without a restart.
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+)
+
+// Keys of the built-in settings
+const (
+	KeyStoreName         = "store_name"
+	KeySupportEmail      = "support_email"
+	KeyDefaultCurrency   = "default_currency"
+	KeyOrderNumberPrefix = "order_number_prefix"
+	KeyVendorCommission  = "vendor_commission_bps"
+
+	KeyReferralRewardType   = "referral_reward_type"
+	KeyReferralSignupReward = "referral_signup_reward"
+	KeyReferralOrderReward  = "referral_order_reward"
+	KeyReferralWelcome      = "referral_welcome_reward"
+
+	KeyQuestionModeration = "question_moderation"
+	KeyGiftWrapFee        = "gift_wrap_fee"
+
+	KeyMaxOrderQuantity = "max_order_quantity"
+	KeyMaxOrderLines    = "max_order_lines"
+
+	KeyQuoteValidityDays = "quote_validity_days"
+
+	KeyEmailOrderConfirmation = "email_order_confirmation"
+	KeyEmailPaymentReceipt    = "email_payment_receipt"
+	KeyEmailOrderShipped      = "email_order_shipped"
+	KeyEmailOrderDelivered    = "email_order_delivered"
+)
+
+// Referral reward types: points are loyalty points, credit is store credit in minor units
+// of the default currency
+const (
+	RewardPoints = "points"
+	RewardCredit = "credit"
+)
+
+// Value types; they decide validation and the JSON type of the value
+const (
+	TypeString   = "string"
+	TypeEmail    = "email"
+	TypeCurrency = "currency"
+	TypeInt      = "int"
+	TypeBool     = "bool"
+)
+
+var (
+	// ErrUnknownSetting is returned for keys without a definition
+	ErrUnknownSetting = errors.New("unknown setting")
+
+	// ErrInvalidValue wraps validation problems with a new value
+	ErrInvalidValue = errors.New("invalid setting value")
+)
+
+// maxStringLength bounds free-text values so they fit in subjects and headers
+const maxStringLength = 255
+
+var orderPrefixPattern = regexp.MustCompile(`^[A-Z0-9_-]{0,12}$`)
+
+// Definition describes one setting: its type, default and any extra validation
+type Definition struct {
+	Key         string
+	Type        string
+	Default     string
+	Description string
+	// Check rejects values that are well-typed but still not acceptable (optional)
+	Check func(value string) error
+}
+
+// Definitions returns the built-in settings, defaulting to the matching environment config
+func Definitions(cfg *config.Config) []Definition {
+	return []Definition{
+		{
+			Key:         KeyStoreName,
+			Type:        TypeString,
+			Default:     cfg.Email.FromName,
+			Description: "Store name shown in emails and on invoices",
+			Check: func(value string) error {
+				if value == "" {
+					return errors.New("store_name cannot be empty")
+				}
+				return nil
+			},
+		},
+		{
+			Key:         KeySupportEmail,
+			Type:        TypeEmail,
+			Description: "Address customers are told to contact; empty hides it",
+		},
+		{
+			Key:         KeyDefaultCurrency,
+			Type:        TypeCurrency,
+			Default:     cfg.FX.BaseCurrency,
+			Description: "Currency of new products that don't specify one",
+		},
+		{
+			Key:         KeyOrderNumberPrefix,
+			Type:        TypeString,
+			Default:     "ORD-",
+			Description: "Prefix of customer-facing order numbers",
+			Check: func(value string) error {
+				if !orderPrefixPattern.MatchString(value) {
+					return errors.New("order_number_prefix must be up to 12 characters of A-Z, 0-9, - and _")
+				}
+				return nil
+			},
+		},
+		{
+			Key:         KeyVendorCommission,
+			Type:        TypeInt,
+			Default:     "1000",
+			Description: "Commission kept on marketplace vendors' sales, in basis points (1000 = 10%); vendors can override it",
+			Check: func(value string) error {
+				if n, _ := strconv.Atoi(value); n < 0 || n > 10000 {
+					return errors.New("vendor_commission_bps must be between 0 and 10000")
+				}
+				return nil
+			},
+		},
+		{
+			Key:         KeyReferralRewardType,
+			Type:        TypeString,
+			Default:     RewardPoints,
+			Description: "What referral rewards are paid in: points, or credit in minor units of the default currency",
+			Check: func(value string) error {
+				if value != RewardPoints && value != RewardCredit {
+					return errors.New("referral_reward_type must be points or credit")
+				}
+				return nil
+			},
+		},
+		{
+			Key:         KeyReferralSignupReward,
+			Type:        TypeInt,
+			Default:     "0",
+			Description: "Reward to the referrer when someone signs up with their code",
+			Check:       nonNegative(KeyReferralSignupReward),
+		},
+		{
+			Key:         KeyReferralOrderReward,
+			Type:        TypeInt,
+			Default:     "500",
+			Description: "Reward to the referrer when their referral places a first order",
+			Check:       nonNegative(KeyReferralOrderReward),
+		},
+		{
+			Key:         KeyReferralWelcome,
+			Type:        TypeInt,
+			Default:     "0",
+			Description: "Reward to the referred customer on their first order",
+			Check:       nonNegative(KeyReferralWelcome),
+		},
+		{
+			Key:         KeyQuestionModeration,
+			Type:        TypeBool,
+			Default:     "true",
+			Description: "Hold new product questions until an admin publishes them; false shows them at once",
+		},
+		{
+			Key:         KeyGiftWrapFee,
+			Type:        TypeInt,
+			Default:     "0",
+			Description: "Gift-wrap charge per wrapped unit, in minor units of the order currency; 0 wraps for free",
+			Check:       nonNegative(KeyGiftWrapFee),
+		},
+		{
+			Key:         KeyMaxOrderQuantity,
+			Type:        TypeInt,
+			Default:     "0",
+			Description: "Most units one order may hold across all products; 0 is unlimited",
+			Check:       nonNegative(KeyMaxOrderQuantity),
+		},
+		{
+			Key:         KeyMaxOrderLines,
+			Type:        TypeInt,
+			Default:     "0",
+			Description: "Most distinct products one order may hold; 0 is unlimited",
+			Check:       nonNegative(KeyMaxOrderLines),
+		},
+		{
+			Key:         KeyQuoteValidityDays,
+			Type:        TypeInt,
+			Default:     "14",
+			Description: "Days an approved B2B quote can be turned into an order, unless the approval sets its own date",
+			Check:       positive(KeyQuoteValidityDays),
+		},
+		{
+			Key:         KeyEmailOrderConfirmation,
+			Type:        TypeBool,
+			Default:     "true",
+			Description: "Email customers a confirmation with the order summary when they place an order",
+		},
+		{
+			Key:         KeyEmailPaymentReceipt,
+			Type:        TypeBool,
+			Default:     "true",
+			Description: "Email customers a receipt when their payment is captured",
+		},
+		{
+			Key:         KeyEmailOrderShipped,
+			Type:        TypeBool,
+			Default:     "true",
+			Description: "Email customers the carrier and tracking number when their order ships",
+		},
+		{
+			Key:         KeyEmailOrderDelivered,
+			Type:        TypeBool,
+			Default:     "true",
+			Description: "Email customers when the carrier confirms delivery",
+		},
+	}
+}
+
+// nonNegative is a Check rejecting negative values of an int setting
+func nonNegative(key string) func(string) error {
+	return func(value string) error {
+		if n, _ := strconv.ParseInt(value, 10, 64); n < 0 {
+			return fmt.Errorf("%s cannot be negative", key)
+		}
+		return nil
+	}
+}
+
+// positive is a Check rejecting zero and negative values of an int setting
+func positive(key string) func(string) error {
+	return func(value string) error {
+		if n, _ := strconv.ParseInt(value, 10, 64); n <= 0 {
+			return fmt.Errorf("%s must be at least 1", key)
+		}
+		return nil
+	}
+}
+
+// normalize validates a JSON-decoded value against def
+// Returns: the value in its stored text form
+func (def Definition) normalize(raw interface{}) (string, error) {
+	var value string
+	switch def.Type {
+	case TypeString, TypeEmail, TypeCurrency:
+		s, ok := raw.(string)
+		if !ok {
+			return "", fmt.Errorf("%w: %s must be a string", ErrInvalidValue, def.Key)
+		}
+		value = strings.TrimSpace(s)
+		if len(value) > maxStringLength {
+			return "", fmt.Errorf("%w: %s is too long", ErrInvalidValue, def.Key)
+		}
+	case TypeInt:
+		n, ok := raw.(float64)
+		if !ok || n != float64(int64(n)) {
+			return "", fmt.Errorf("%w: %s must be an integer", ErrInvalidValue, def.Key)
+		}
+		value = strconv.FormatInt(int64(n), 10)
+	case TypeBool:
+		b, ok := raw.(bool)
+		if !ok {
+			return "", fmt.Errorf("%w: %s must be true or false", ErrInvalidValue, def.Key)
+		}
+		value = strconv.FormatBool(b)
+	}
+
+	switch def.Type {
+	case TypeEmail:
+		if value != "" {
+			addr, err := mail.ParseAddress(value)
+			if err != nil || addr.Address != value {
+				return "", fmt.Errorf("%w: %s must be an email address", ErrInvalidValue, def.Key)
+			}
+		}
+	case TypeCurrency:
+		value = strings.ToUpper(value)
+		if len(value) != 3 || strings.Trim(value, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return "", fmt.Errorf("%w: %s must be an ISO 4217 code", ErrInvalidValue, def.Key)
+		}
+	}
+
+	if def.Check != nil {
+		if err := def.Check(value); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidValue, err)
+		}
+	}
+	return value, nil
+}
+
+// decode turns a stored value into its JSON type for responses
+func (def Definition) decode(value string) interface{} {
+	switch def.Type {
+	case TypeInt:
+		n, _ := strconv.ParseInt(value, 10, 64)
+		return n
+	case TypeBool:
+		b, _ := strconv.ParseBool(value)
+		return b
+	default:
+		return value
+	}
+}
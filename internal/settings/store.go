@@ -0,0 +1,272 @@
+package settings
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"github.com/Jason-Omondi/ecomgo/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	cacheKey = "settings:values"
+	// cacheTTL bounds how long a change made outside the API (e.g. SQL) takes to show up;
+	// changes through Update invalidate the cache immediately
+	cacheTTL = 10 * time.Minute
+)
+
+// Store reads and changes settings
+// Stored values are cached as one map, so a read is a cache hit in the common case
+// Read failures fall back to defaults: an unreachable settings table must not stop emails or checkout
+type Store struct {
+	repo  *repository.SettingRepository
+	cache *cache.Loader
+	defs  map[string]Definition
+	keys  []string // definition order, for listings
+	log   *zap.Logger
+}
+
+func NewStore(repo *repository.SettingRepository, c cache.Cache, cfg *config.Config, log *zap.Logger) *Store {
+	s := &Store{
+		repo:  repo,
+		cache: cache.NewLoader(c),
+		defs:  make(map[string]Definition),
+		log:   log,
+	}
+	for _, def := range Definitions(cfg) {
+		s.defs[def.Key] = def
+		s.keys = append(s.keys, def.Key)
+	}
+	return s
+}
+
+// values returns the stored (non-default) values by key
+func (s *Store) values(ctx context.Context) (map[string]string, error) {
+	return cache.LoadJSON(ctx, s.cache, cacheKey, cacheTTL, func(ctx context.Context) (map[string]string, error) {
+		rows, err := s.repo.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string]string, len(rows))
+		for _, row := range rows {
+			values[row.Key] = row.Value
+		}
+		return values, nil
+	})
+}
+
+// String returns the current value of key, or its default when unset or unreadable
+func (s *Store) String(ctx context.Context, key string) string {
+	def := s.defs[key]
+	values, err := s.values(ctx)
+	if err != nil {
+		s.log.Warn("Failed to load settings, using defaults", zap.String("key", key), zap.Error(err))
+		return def.Default
+	}
+	if value, ok := values[key]; ok {
+		return value
+	}
+	return def.Default
+}
+
+// Int returns the current value of an int setting
+func (s *Store) Int(ctx context.Context, key string) int64 {
+	n, _ := strconv.ParseInt(s.String(ctx, key), 10, 64)
+	return n
+}
+
+// Bool returns the current value of a bool setting
+func (s *Store) Bool(ctx context.Context, key string) bool {
+	b, _ := strconv.ParseBool(s.String(ctx, key))
+	return b
+}
+
+// StoreName is the store's display name
+func (s *Store) StoreName(ctx context.Context) string {
+	return s.String(ctx, KeyStoreName)
+}
+
+// SupportEmail is the customer support address, or "" when none is published
+func (s *Store) SupportEmail(ctx context.Context) string {
+	return s.String(ctx, KeySupportEmail)
+}
+
+// DefaultCurrency is the ISO 4217 code used when a price doesn't name one
+func (s *Store) DefaultCurrency(ctx context.Context) string {
+	return s.String(ctx, KeyDefaultCurrency)
+}
+
+// OrderNumberPrefix starts every order number, see internal/ordernumber
+func (s *Store) OrderNumberPrefix(ctx context.Context) string {
+	return s.String(ctx, KeyOrderNumberPrefix)
+}
+
+// VendorCommissionBPS is the commission on vendor sales for vendors without their own rate
+func (s *Store) VendorCommissionBPS(ctx context.Context) int {
+	return int(s.Int(ctx, KeyVendorCommission))
+}
+
+// ReferralRewardType is what referral rewards are paid in, RewardPoints or RewardCredit
+func (s *Store) ReferralRewardType(ctx context.Context) string {
+	return s.String(ctx, KeyReferralRewardType)
+}
+
+// QuestionModeration tells whether new product questions wait for an admin before showing
+func (s *Store) QuestionModeration(ctx context.Context) bool {
+	return s.Bool(ctx, KeyQuestionModeration)
+}
+
+// GiftWrapFee is the charge per gift-wrapped unit, see gift.Apply
+func (s *Store) GiftWrapFee(ctx context.Context) int64 {
+	return s.Int(ctx, KeyGiftWrapFee)
+}
+
+// MaxOrderQuantity is the most units one order may hold, 0 for no limit
+func (s *Store) MaxOrderQuantity(ctx context.Context) int {
+	return int(s.Int(ctx, KeyMaxOrderQuantity))
+}
+
+// MaxOrderLines is the most distinct products one order may hold, 0 for no limit
+func (s *Store) MaxOrderLines(ctx context.Context) int {
+	return int(s.Int(ctx, KeyMaxOrderLines))
+}
+
+// QuoteValidityDays is how long an approved quote stays open by default
+func (s *Store) QuoteValidityDays(ctx context.Context) int {
+	return int(s.Int(ctx, KeyQuoteValidityDays))
+}
+
+// List returns every setting with its current value, read from the database
+func (s *Store) List(ctx context.Context) ([]models.SettingResponse, error) {
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]models.Setting, len(rows))
+	for _, row := range rows {
+		stored[row.Key] = row
+	}
+
+	list := make([]models.SettingResponse, 0, len(s.keys))
+	for _, key := range s.keys {
+		def := s.defs[key]
+		item := models.SettingResponse{
+			Key:         key,
+			Type:        def.Type,
+			Value:       def.decode(def.Default),
+			Default:     def.decode(def.Default),
+			Description: def.Description,
+		}
+		if row, ok := stored[key]; ok {
+			updatedAt := row.UpdatedAt
+			item.Value = def.decode(row.Value)
+			item.UpdatedBy = row.UpdatedBy
+			item.UpdatedAt = &updatedAt
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// Update validates every value in req and saves them together; a nil value (or the
+// default) removes the override
+// Returns: ErrUnknownSetting or ErrInvalidValue without saving anything
+func (s *Store) Update(ctx context.Context, req models.UpdateSettingsRequest, adminID string) ([]models.SettingResponse, error) {
+	set, reset, err := s.split(req, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(ctx, set, reset); err != nil {
+		return nil, err
+	}
+	if err := s.cache.Invalidate(ctx, cacheKey); err != nil {
+		s.log.Warn("Failed to invalidate settings cache", zap.Error(err))
+	}
+
+	changed := make([]string, 0, len(req))
+	for key := range req {
+		changed = append(changed, key)
+	}
+	sort.Strings(changed)
+	s.log.Info("Settings updated", zap.String("admin_id", adminID), zap.Strings("keys", changed))
+	return s.List(ctx)
+}
+
+// Diff validates req like Update and returns the settings it would change, with their
+// current and new values, ordered by key; nothing is saved
+func (s *Store) Diff(ctx context.Context, req models.UpdateSettingsRequest) ([]models.ConfigChange, error) {
+	set, reset, err := s.split(req, "")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]string, len(rows))
+	for _, row := range rows {
+		stored[row.Key] = row.Value
+	}
+
+	var changes []models.ConfigChange
+	for _, setting := range set {
+		if current, ok := stored[setting.Key]; !ok || current != setting.Value {
+			changes = append(changes, s.change(setting.Key, stored, setting.Value))
+		}
+	}
+	for _, key := range reset {
+		if _, ok := stored[key]; ok {
+			changes = append(changes, s.change(key, stored, s.defs[key].Default))
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes, nil
+}
+
+func (s *Store) change(key string, stored map[string]string, to string) models.ConfigChange {
+	def := s.defs[key]
+	from, ok := stored[key]
+	if !ok {
+		from = def.Default
+	}
+	return models.ConfigChange{
+		Section: models.ConfigSectionSettings,
+		Key:     key,
+		Action:  models.ConfigUpdate,
+		From:    def.decode(from),
+		To:      def.decode(to),
+	}
+}
+
+// split validates req and sorts it into values to save and keys to reset to their default
+func (s *Store) split(req models.UpdateSettingsRequest, adminID string) ([]models.Setting, []string, error) {
+	var set []models.Setting
+	var reset []string
+	for key, raw := range req {
+		def, ok := s.defs[key]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %s", ErrUnknownSetting, key)
+		}
+		if raw == nil {
+			reset = append(reset, key)
+			continue
+		}
+		value, err := def.normalize(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		if value == def.Default {
+			reset = append(reset, key)
+			continue
+		}
+		set = append(set, models.Setting{Key: key, Value: value, UpdatedBy: adminID})
+	}
+	return set, reset, nil
+}
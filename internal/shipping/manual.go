@@ -0,0 +1,80 @@
+package shipping
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/hmacsig"
+)
+
+// ManualCarrier is for deliveries handled by the store's own riders or an unintegrated courier
+// Tracking numbers are generated locally; staff tools post status updates to the webhook
+type ManualCarrier struct {
+	webhookSecret string
+}
+
+func NewManualCarrier(webhookSecret string) *ManualCarrier {
+	return &ManualCarrier{webhookSecret: webhookSecret}
+}
+
+func (c *ManualCarrier) Name() string {
+	return "manual"
+}
+
+// CreateShipment issues a local tracking number and a plain-text packing label
+func (c *ManualCarrier) CreateShipment(ctx context.Context, req ShipmentRequest) (*ShipmentResult, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	tracking := "MAN-" + time.Now().UTC().Format("20060102") + "-" + strings.ToUpper(hex.EncodeToString(suffix))
+
+	dest := req.Destination
+	label := fmt.Sprintf("TRACKING: %s\nREF: %s\n\nTO: %s\n%s\n%s\n%s %s\n%s\nPHONE: %s\n\nWEIGHT: %dg\n",
+		tracking, req.Reference, dest.Recipient, dest.Line1, dest.Line2,
+		dest.City, dest.PostalCode, dest.Country, dest.Phone, req.WeightGrams)
+
+	return &ShipmentResult{
+		TrackingNumber: tracking,
+		Label:          []byte(label),
+		LabelFormat:    "text/plain; charset=utf-8",
+	}, nil
+}
+
+// manualWebhook is the payload staff tools post to /shipping/webhooks/manual
+type manualWebhook struct {
+	TrackingNumber string    `json:"tracking_number"`
+	Status         string    `json:"status"`
+	Description    string    `json:"description"`
+	Location       string    `json:"location"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+// ParseWebhook verifies X-Signature (hex HMAC-SHA256 of the body) and decodes one update
+func (c *ManualCarrier) ParseWebhook(r *http.Request, body []byte) ([]TrackingUpdate, error) {
+	if !hmacsig.Verify(c.webhookSecret, r.Header.Get("X-Signature"), body) {
+		return nil, ErrInvalidSignature
+	}
+
+	var payload manualWebhook
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	if payload.OccurredAt.IsZero() {
+		payload.OccurredAt = time.Now().UTC()
+	}
+
+	return []TrackingUpdate{{
+		TrackingNumber: payload.TrackingNumber,
+		Status:         payload.Status, // already one of our statuses
+		Description:    payload.Description,
+		Location:       payload.Location,
+		OccurredAt:     payload.OccurredAt,
+	}}, nil
+}
@@ -0,0 +1,155 @@
+package shipping
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/hmacsig"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+const sendyOrdersURL = "https://api.sendyit.com/v1/"
+
+// sendyStatuses maps Sendy order states to shipment statuses
+var sendyStatuses = map[string]string{
+	"confirmed":   models.ShipmentCreated,
+	"picked":      models.ShipmentInTransit,
+	"in_transit":  models.ShipmentOutForDelivery,
+	"delivered":   models.ShipmentDelivered,
+	"cancelled":   models.ShipmentException,
+	"undelivered": models.ShipmentException,
+}
+
+// SendyCarrier books same-day motorbike/van deliveries through the Sendy API (Kenya, Uganda)
+// Sendy issues no printable labels - riders use the order reference
+type SendyCarrier struct {
+	apiKey        string
+	username      string
+	webhookSecret string
+	client        *http.Client
+}
+
+func NewSendyCarrier(cfg config.Shipping) *SendyCarrier {
+	return &SendyCarrier{
+		apiKey:        cfg.SendyAPIKey,
+		username:      cfg.SendyUsername,
+		webhookSecret: cfg.SendyWebhookSecret,
+		client:        httpclient.New(15 * time.Second),
+	}
+}
+
+func (c *SendyCarrier) Name() string {
+	return "sendy"
+}
+
+func sendyLocation(addr models.Address) map[string]interface{} {
+	location := map[string]interface{}{
+		"name":        addr.Line1,
+		"description": addr.Line2,
+	}
+	if addr.Latitude != nil && addr.Longitude != nil {
+		location["lat"], location["long"] = *addr.Latitude, *addr.Longitude
+	}
+	return location
+}
+
+func (c *SendyCarrier) CreateShipment(ctx context.Context, req ShipmentRequest) (*ShipmentResult, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"command": "request",
+		"data": map[string]interface{}{
+			"api_key":      c.apiKey,
+			"api_username": c.username,
+			"vendor_type":  1,
+			"from":         sendyLocation(req.Origin),
+			"to":           sendyLocation(req.Destination),
+			"recepient": map[string]string{
+				"recepient_name":  req.Destination.Recipient,
+				"recepient_phone": req.Destination.Phone,
+			},
+			"delivery_details": map[string]interface{}{
+				"order_id":     req.Reference,
+				"pick_up_date": time.Now().Format("2006-01-02 15:04:05"),
+				"note":         "Weight: " + fmt.Sprint(req.WeightGrams) + "g",
+			},
+		},
+		"request_token_id": req.Reference,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, sendyOrdersURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("sendy create delivery: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("sendy create delivery: status %d: %s", resp.StatusCode, detail)
+	}
+
+	var out struct {
+		Status      bool   `json:"status"`
+		Description string `json:"description"`
+		Data        struct {
+			OrderNo string `json:"order_no"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("sendy create delivery: decode: %w", err)
+	}
+	if !out.Status || out.Data.OrderNo == "" {
+		return nil, fmt.Errorf("sendy create delivery: %s", out.Description)
+	}
+
+	return &ShipmentResult{TrackingNumber: out.Data.OrderNo}, nil
+}
+
+// sendyWebhook is Sendy's order status callback
+type sendyWebhook struct {
+	OrderNo     string    `json:"order_no"`
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ParseWebhook verifies X-Sendy-Signature (hex HMAC-SHA256 of the body)
+func (c *SendyCarrier) ParseWebhook(r *http.Request, body []byte) ([]TrackingUpdate, error) {
+	if !hmacsig.Verify(c.webhookSecret, r.Header.Get("X-Sendy-Signature"), body) {
+		return nil, ErrInvalidSignature
+	}
+
+	var payload sendyWebhook
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	status, ok := sendyStatuses[payload.Status]
+	if !ok {
+		return nil, nil
+	}
+	if payload.UpdatedAt.IsZero() {
+		payload.UpdatedAt = time.Now().UTC()
+	}
+
+	return []TrackingUpdate{{
+		TrackingNumber: payload.OrderNo,
+		Status:         status,
+		Description:    payload.Description,
+		OccurredAt:     payload.UpdatedAt,
+	}}, nil
+}
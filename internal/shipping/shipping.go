@@ -0,0 +1,98 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+var (
+	// ErrUnknownCarrier is returned for carriers that aren't enabled in SHIPPING_CARRIERS
+	ErrUnknownCarrier = errors.New("unknown carrier")
+
+	// ErrInvalidSignature is returned when a tracking webhook fails authentication
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// ShipmentRequest is what a carrier needs to book a pickup and produce a label
+type ShipmentRequest struct {
+	Reference   string         // our shipment ID, echoed back by carriers that support it
+	Origin      models.Address // warehouse / store address (SHIPPING_ORIGIN_*)
+	Destination models.Address
+	WeightGrams int
+}
+
+// ShipmentResult is a booked shipment
+type ShipmentResult struct {
+	TrackingNumber string
+	Label          []byte // printable label, may be empty when the carrier has no labels
+	LabelFormat    string // MIME type of Label, e.g. application/pdf
+}
+
+// TrackingUpdate is one status change reported by a carrier
+type TrackingUpdate struct {
+	TrackingNumber string
+	Status         string // one of models.Shipment* statuses
+	Description    string // carrier's own wording, shown to customers
+	Location       string
+	OccurredAt     time.Time
+}
+
+// Carrier books shipments and interprets tracking webhooks for one shipping company
+type Carrier interface {
+	// Name is the carrier code used in URLs and stored on shipments (dhl, sendy, manual)
+	Name() string
+
+	// CreateShipment books the shipment and returns its tracking number and label
+	CreateShipment(ctx context.Context, req ShipmentRequest) (*ShipmentResult, error)
+
+	// ParseWebhook authenticates a tracking webhook and extracts its updates
+	// Returns: ErrInvalidSignature when authentication fails
+	ParseWebhook(r *http.Request, body []byte) ([]TrackingUpdate, error)
+}
+
+// Carriers holds the enabled carriers by name
+type Carriers map[string]Carrier
+
+// NewCarriers creates the carriers listed in SHIPPING_CARRIERS
+// Returns: error if a carrier is unknown or missing credentials
+func NewCarriers(cfg config.Shipping) (Carriers, error) {
+	carriers := make(Carriers)
+	for _, name := range cfg.Carriers {
+		var carrier Carrier
+		switch name {
+		case "manual":
+			// Without SHIPPING_MANUAL_WEBHOOK_SECRET bookings work but tracking webhooks are rejected
+			carrier = NewManualCarrier(cfg.ManualWebhookSecret)
+		case "dhl":
+			if cfg.DHLAPIKey == "" || cfg.DHLAPISecret == "" || cfg.DHLAccountNumber == "" {
+				return nil, fmt.Errorf("DHL_API_KEY, DHL_API_SECRET and DHL_ACCOUNT_NUMBER must be set for the dhl carrier")
+			}
+			carrier = NewDHLCarrier(cfg)
+		case "sendy":
+			if cfg.SendyAPIKey == "" || cfg.SendyUsername == "" {
+				return nil, fmt.Errorf("SENDY_API_KEY and SENDY_USERNAME must be set for the sendy carrier")
+			}
+			carrier = NewSendyCarrier(cfg)
+		default:
+			return nil, fmt.Errorf("unsupported carrier in SHIPPING_CARRIERS: %s (must be 'manual', 'dhl' or 'sendy')", name)
+		}
+		carriers[name] = carrier
+	}
+	return carriers, nil
+}
+
+// Get returns the named carrier or ErrUnknownCarrier
+func (c Carriers) Get(name string) (Carrier, error) {
+	carrier, ok := c[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCarrier, name)
+	}
+	return carrier, nil
+}
@@ -0,0 +1,308 @@
+// Package smoke runs a short end-to-end check against a live deployment: health,
+// registration and login, catalog browsing and a checkout that places a real order.
+// It talks to the public API only, so it can run from a deploy pipeline with no
+// database or config; `go run cmd/main.go smoke -base-url=...` exits non-zero on failure.
+// Checkout pays, so only point it at deployments with PAYMENT_PROVIDER=sandbox.
+package smoke
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+	"go.uber.org/zap"
+)
+
+// pollInterval is how often a processing checkout and the order it places are checked again
+const pollInterval = 500 * time.Millisecond
+
+// Options configures a smoke run
+type Options struct {
+	BaseURL string // server root, e.g. https://shop.example.com (API is under /api/v1)
+	Timeout time.Duration
+
+	// Email/Password sign in as an existing smoke account; when Email is empty a fresh
+	// smoke+<timestamp>@example.com account is registered on every run
+	Email    string
+	Password string
+
+	// Address is validated and saved for the checkout, then deleted; must be in a delivery zone
+	Address models.AddressRequest
+	// ProductID is bought at checkout; empty picks the first in-stock search hit
+	ProductID string
+	// PaymentMethod pays for the order; the sandbox provider captures anything but "decline" and "error"
+	PaymentMethod string
+}
+
+// Result is the outcome of one step
+type Result struct {
+	Step     string
+	Err      error
+	Duration time.Duration
+}
+
+// Runner executes the smoke steps in order; later steps reuse the session of earlier ones
+type Runner struct {
+	opts   Options
+	api    string
+	client *http.Client
+	log    *zap.Logger
+
+	token     string
+	productID string
+	orderID   string
+}
+
+func NewRunner(opts Options, log *zap.Logger) *Runner {
+	return &Runner{
+		opts:   opts,
+		api:    strings.TrimSuffix(opts.BaseURL, "/") + "/api/v1",
+		client: &http.Client{Timeout: opts.Timeout},
+		log:    log,
+	}
+}
+
+// Run executes every step, stopping at the first failure since later steps depend on it
+// Returns: the step results and an error naming the failed step
+func (r *Runner) Run(ctx context.Context) ([]Result, error) {
+	steps := []struct {
+		name string
+		run  func(ctx context.Context) error
+	}{
+		{"health", r.health},
+		{"sign in", r.signIn},
+		{"browse products", r.browse},
+		{"checkout", r.checkout},
+	}
+
+	var results []Result
+	for _, step := range steps {
+		start := time.Now()
+		err := step.run(ctx)
+		result := Result{Step: step.name, Err: err, Duration: time.Since(start)}
+		results = append(results, result)
+
+		if err != nil {
+			r.log.Error("Smoke step failed", zap.String("step", step.name), zap.Duration("took", result.Duration), zap.Error(err))
+			return results, fmt.Errorf("smoke step %q failed: %w", step.name, err)
+		}
+		r.log.Info("Smoke step passed", zap.String("step", step.name), zap.Duration("took", result.Duration))
+	}
+	return results, nil
+}
+
+func (r *Runner) health(ctx context.Context) error {
+	return r.do(ctx, http.MethodGet, strings.TrimSuffix(r.opts.BaseURL, "/")+"/health", nil, http.StatusOK, nil)
+}
+
+// signIn logs in as the configured account, or registers a throwaway one
+func (r *Runner) signIn(ctx context.Context) error {
+	var auth models.AuthResponse
+	if r.opts.Email == "" {
+		req := models.RegisterRequest{
+			Email:     fmt.Sprintf("smoke+%d@example.com", time.Now().UnixNano()),
+			Password:  r.opts.Password,
+			FirstName: "Smoke",
+			LastName:  "Test",
+		}
+		if err := r.do(ctx, http.MethodPost, r.api+"/register", req, http.StatusCreated, &auth); err != nil {
+			return fmt.Errorf("register: %w", err)
+		}
+		r.opts.Email = req.Email
+	}
+
+	req := models.LoginRequest{Email: r.opts.Email, Password: r.opts.Password}
+	if err := r.do(ctx, http.MethodPost, r.api+"/login", req, http.StatusOK, &auth); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	if auth.Token == "" {
+		return fmt.Errorf("login returned no token")
+	}
+	r.token = auth.Token
+	return nil
+}
+
+func (r *Runner) browse(ctx context.Context) error {
+	var page models.ProductSearchResponse
+	if err := r.do(ctx, http.MethodGet, r.api+"/products/search?limit=5", nil, http.StatusOK, &page); err != nil {
+		return fmt.Errorf("search: %w", err)
+	}
+	r.productID = r.opts.ProductID
+	for _, hit := range page.Hits {
+		if r.productID == "" && hit.InStock {
+			r.productID = hit.ID
+		}
+	}
+	if r.productID == "" {
+		return fmt.Errorf("no product in stock to check out; pass one with -product")
+	}
+
+	var product models.Product
+	if err := r.do(ctx, http.MethodGet, r.api+"/products/"+url.PathEscape(r.productID), nil, http.StatusOK, &product); err != nil {
+		return fmt.Errorf("product detail: %w", err)
+	}
+	return nil
+}
+
+// checkout buys one unit of the browsed product the way a storefront does: it validates and
+// saves a delivery address, quotes the cart, pays for it and waits for the order to show up
+// The address is deleted again, so reruns with -email don't fill the address book
+func (r *Runner) checkout(ctx context.Context) (err error) {
+	var validation models.AddressValidationResponse
+	if err := r.do(ctx, http.MethodPost, r.api+"/addresses/validate", r.opts.Address, http.StatusOK, &validation); err != nil {
+		return fmt.Errorf("validate address: %w", err)
+	}
+
+	var saved models.Address
+	if err := r.do(ctx, http.MethodPost, r.api+"/users/me/addresses", r.opts.Address, http.StatusCreated, &saved); err != nil {
+		return fmt.Errorf("save address: %w", err)
+	}
+	defer func() {
+		deleteErr := r.do(ctx, http.MethodDelete, r.api+"/users/me/addresses/"+url.PathEscape(saved.ID), nil, http.StatusNoContent, nil)
+		if err == nil && deleteErr != nil {
+			err = fmt.Errorf("delete address: %w", deleteErr)
+		}
+	}()
+
+	var quote models.CartQuote
+	cart := models.CartQuoteRequest{Items: []models.CartLine{{ProductID: r.productID, Quantity: 1}}}
+	if err := r.do(ctx, http.MethodPost, r.api+"/cart/quote", cart, http.StatusOK, &quote); err != nil {
+		return fmt.Errorf("quote cart: %w", err)
+	}
+
+	req := models.CheckoutRequest{QuoteToken: quote.Token, AddressID: saved.ID, PaymentMethod: r.opts.PaymentMethod}
+	for _, line := range quote.Items {
+		req.Items = append(req.Items, models.CartLine{ProductID: line.ProductID, Quantity: line.Quantity, UnitPrice: line.UnitPrice})
+	}
+	placed, err := r.placeOrder(ctx, &req)
+	if err != nil {
+		return err
+	}
+	r.orderID = placed.OrderID
+
+	// Orders are recorded from order events, a moment after checkout completes
+	return r.poll(ctx, func() (bool, error) {
+		var order models.Order
+		err := r.do(ctx, http.MethodGet, r.api+"/orders/"+url.PathEscape(r.orderID), nil, http.StatusOK, &order)
+		var status *statusError
+		if errors.As(err, &status) && status.code == http.StatusNotFound {
+			return false, nil
+		}
+		return err == nil, err
+	}, "order "+placed.OrderNumber+" to be recorded")
+}
+
+// placeOrder checks out req and waits until the checkout completes
+func (r *Runner) placeOrder(ctx context.Context, req *models.CheckoutRequest) (*models.Checkout, error) {
+	var checkout models.Checkout
+	key := fmt.Sprintf("smoke-%d", time.Now().UnixNano())
+	err := r.send(ctx, http.MethodPost, r.api+"/checkout", map[string]string{"Idempotency-Key": key}, req,
+		[]int{http.StatusCreated, http.StatusAccepted}, &checkout)
+	if err != nil {
+		return nil, fmt.Errorf("checkout: %w", err)
+	}
+
+	err = r.poll(ctx, func() (bool, error) {
+		switch checkout.Status {
+		case models.CheckoutCompleted:
+			return true, nil
+		case models.CheckoutFailed:
+			return false, fmt.Errorf("checkout %s failed: %s", checkout.ID, checkout.Reason)
+		}
+		return false, r.do(ctx, http.MethodGet, r.api+"/checkout/"+url.PathEscape(checkout.ID), nil, http.StatusOK, &checkout)
+	}, "checkout to complete")
+	if err != nil {
+		return nil, err
+	}
+	return &checkout, nil
+}
+
+// poll calls done every pollInterval until it reports true or fails, for at most the request timeout
+func (r *Runner) poll(ctx context.Context, done func() (bool, error), what string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+	defer cancel()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		ok, err := done()
+		if ok || err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s", what)
+		case <-ticker.C:
+		}
+	}
+}
+
+// statusError is a response with an unexpected status
+type statusError struct {
+	method, path string
+	code         int
+	want         []int
+	body         string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s %s: status %d, want %v: %s", e.method, e.path, e.code, e.want, e.body)
+}
+
+// do sends a JSON request and decodes the JSON response into out when it has the wanted status
+func (r *Runner) do(ctx context.Context, method, target string, body interface{}, want int, out interface{}) error {
+	return r.send(ctx, method, target, nil, body, []int{want}, out)
+}
+
+// send is do with extra headers and several accepted statuses
+func (r *Runner) send(ctx context.Context, method, target string, header map[string]string, body interface{},
+	want []int, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "EcomGo-Smoke/1.0")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if !slices.Contains(want, resp.StatusCode) {
+		return &statusError{method: method, path: req.URL.Path, code: resp.StatusCode, want: want, body: strings.TrimSpace(string(data))}
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("%s %s: decode response: %w", method, req.URL.Path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+)
+
+const (
+	atLiveEndpoint    = "https://api.africastalking.com/version1/messaging"
+	atSandboxEndpoint = "https://api.sandbox.africastalking.com/version1/messaging"
+)
+
+// AfricasTalkingSender sends SMS through Africa's Talking (Kenya, Uganda, Nigeria...)
+// WhatsApp is not supported by this provider
+type AfricasTalkingSender struct {
+	endpoint string
+	username string
+	apiKey   string
+	senderID string
+	client   *http.Client
+}
+
+func NewAfricasTalkingSender(cfg config.SMS) *AfricasTalkingSender {
+	endpoint := atLiveEndpoint
+	// Africa's Talking reserves the "sandbox" username for its test environment
+	if cfg.ATUsername == "sandbox" {
+		endpoint = atSandboxEndpoint
+	}
+
+	return &AfricasTalkingSender{
+		endpoint: endpoint,
+		username: cfg.ATUsername,
+		apiKey:   cfg.ATAPIKey,
+		senderID: cfg.ATSenderID,
+		client:   httpclient.New(10 * time.Second),
+	}
+}
+
+// atResponse is the subset of the messaging response we inspect
+type atResponse struct {
+	SMSMessageData struct {
+		Recipients []struct {
+			Number string `json:"number"`
+			Status string `json:"status"`
+		} `json:"Recipients"`
+	} `json:"SMSMessageData"`
+}
+
+// Send posts the SMS and checks the per-recipient status
+// Africa's Talking returns 201 even when a recipient is rejected, so the body must be checked
+func (s *AfricasTalkingSender) Send(ctx context.Context, msg Message) error {
+	if msg.Channel == ChannelWhatsApp {
+		return ErrChannelUnsupported
+	}
+
+	form := url.Values{}
+	form.Set("username", s.username)
+	form.Set("to", msg.To)
+	form.Set("message", msg.Body)
+	if s.senderID != "" {
+		form.Set("from", s.senderID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("apiKey", s.apiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("africastalking send to %s: %w", msg.To, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("africastalking send to %s: status %d: %s", msg.To, resp.StatusCode, body)
+	}
+
+	var result atResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("africastalking send to %s: decode response: %w", msg.To, err)
+	}
+	for _, recipient := range result.SMSMessageData.Recipients {
+		if recipient.Status != "Success" {
+			return fmt.Errorf("africastalking send to %s: %s", msg.To, recipient.Status)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package sms
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// LogSender writes messages to the application log instead of sending them
+// Default provider for local development
+type LogSender struct {
+	log *zap.Logger
+}
+
+func NewLogSender(log *zap.Logger) *LogSender {
+	return &LogSender{log: log}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	s.log.Info("SMS (log provider, not sent)",
+		zap.String("to", msg.To),
+		zap.String("channel", msg.Channel),
+		zap.String("body", msg.Body),
+	)
+	return nil
+}
@@ -0,0 +1,54 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"go.uber.org/zap"
+)
+
+// Messaging channels supported by senders
+const (
+	ChannelSMS      = "sms"
+	ChannelWhatsApp = "whatsapp"
+)
+
+// ErrChannelUnsupported is returned when a provider can't deliver on the requested channel
+var ErrChannelUnsupported = errors.New("channel not supported by provider")
+
+// Message is a short text sent to a phone number
+// To must be in E.164 format (+254712345678)
+type Message struct {
+	To      string
+	Body    string
+	Channel string // sms (default) or whatsapp
+}
+
+// Sender delivers text messages (OTP codes, order updates, M-Pesa confirmations)
+// Implementations: Twilio (SMS + WhatsApp), Africa's Talking (SMS), log (development)
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender creates the sender selected by SMS_PROVIDER
+// Returns: error if provider is unknown or missing credentials
+func NewSender(cfg config.SMS, log *zap.Logger) (Sender, error) {
+	switch cfg.Provider {
+	case "twilio":
+		if cfg.TwilioAccountSID == "" || cfg.TwilioAuthToken == "" {
+			return nil, fmt.Errorf("TWILIO_ACCOUNT_SID and TWILIO_AUTH_TOKEN must be set for SMS_PROVIDER=twilio")
+		}
+		return NewTwilioSender(cfg), nil
+	case "africastalking":
+		if cfg.ATUsername == "" || cfg.ATAPIKey == "" {
+			return nil, fmt.Errorf("AT_USERNAME and AT_API_KEY must be set for SMS_PROVIDER=africastalking")
+		}
+		return NewAfricasTalkingSender(cfg), nil
+	case "log", "":
+		return NewLogSender(log), nil
+	default:
+		return nil, fmt.Errorf("unsupported SMS_PROVIDER: %s (must be 'twilio', 'africastalking' or 'log')", cfg.Provider)
+	}
+}
@@ -0,0 +1,71 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+)
+
+const twilioAPIBase = "https://api.twilio.com/2010-04-01/Accounts/"
+
+// TwilioSender sends SMS and WhatsApp messages through the Twilio Messages API
+type TwilioSender struct {
+	accountSID   string
+	authToken    string
+	from         string
+	whatsAppFrom string
+	client       *http.Client
+}
+
+func NewTwilioSender(cfg config.SMS) *TwilioSender {
+	return &TwilioSender{
+		accountSID:   cfg.TwilioAccountSID,
+		authToken:    cfg.TwilioAuthToken,
+		from:         cfg.TwilioFrom,
+		whatsAppFrom: cfg.TwilioWhatsAppFrom,
+		client:       httpclient.New(10 * time.Second),
+	}
+}
+
+// Send posts the message; WhatsApp uses the same endpoint with "whatsapp:" prefixed numbers
+func (s *TwilioSender) Send(ctx context.Context, msg Message) error {
+	from, to := s.from, msg.To
+	if msg.Channel == ChannelWhatsApp {
+		if s.whatsAppFrom == "" {
+			return fmt.Errorf("%w: TWILIO_WHATSAPP_FROM not set", ErrChannelUnsupported)
+		}
+		from, to = "whatsapp:"+s.whatsAppFrom, "whatsapp:"+msg.To
+	}
+
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", from)
+	form.Set("Body", msg.Body)
+
+	endpoint := twilioAPIBase + s.accountSID + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.accountSID, s.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("twilio send to %s: %w", msg.To, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("twilio send to %s: status %d: %s", msg.To, resp.StatusCode, detail)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3 stores objects in an S3-compatible bucket: AWS S3, MinIO, or GCS through its XML API
+// GCS needs HMAC keys (Cloud Storage > Settings > Interoperability)
+type S3 struct {
+	backend string
+	bucket  string
+	client  *minio.Client
+}
+
+// defaultEndpoints are used when STORAGE_ENDPOINT is empty
+var defaultEndpoints = map[string]string{
+	"s3":  "s3.amazonaws.com",
+	"gcs": "storage.googleapis.com",
+}
+
+func NewS3(cfg config.Storage) (*S3, error) {
+	if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("STORAGE_BUCKET, STORAGE_ACCESS_KEY and STORAGE_SECRET_KEY must be set for the %s backend", cfg.Backend)
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoints[cfg.Backend]
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("STORAGE_ENDPOINT must be set for the %s backend", cfg.Backend)
+	}
+
+	// MinIO deployments rarely have wildcard DNS for virtual-hosted buckets
+	lookup := minio.BucketLookupAuto
+	if cfg.Backend == "minio" {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:       cfg.UseSSL,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3{
+		backend: cfg.Backend,
+		bucket:  cfg.Bucket,
+		client:  client,
+	}, nil
+}
+
+func (s *S3) Name() string {
+	return s.backend
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, nil, err
+	}
+
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, mapS3Error(err)
+	}
+	// GetObject is lazy - Stat performs the request and surfaces missing keys
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, nil, mapS3Error(err)
+	}
+	return obj, s3Object(info), nil
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (*Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, mapS3Error(err)
+	}
+	return s3Object(info), nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	return mapS3Error(s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}))
+}
+
+func (s *S3) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, ttl, url.Values{})
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// PresignPut signs an upload URL; the uploader should send contentType as its Content-Type header
+func (s *S3) PresignPut(ctx context.Context, key, contentType string, ttl time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	u, err := s.client.PresignedPutObject(ctx, s.bucket, key, ttl)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// mapS3Error converts missing-object responses to ErrNotFound
+func mapS3Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	resp := minio.ToErrorResponse(err)
+	if resp.Code == "NoSuchKey" || resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+func s3Object(info minio.ObjectInfo) *Object {
+	return &Object{
+		Key:         info.Key,
+		ContentType: info.ContentType,
+		Size:        info.Size,
+		ModTime:     info.LastModified,
+	}
+}
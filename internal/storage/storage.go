@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+)
+
+// ErrNotFound is returned when no object exists under a key
+var ErrNotFound = errors.New("storage: object not found")
+
+// ErrInvalidKey is returned for keys that are empty, absolute or escape their prefix
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// Key prefixes - each kind of file lives under its own prefix so retention and
+// bucket policies (e.g. public product images) can be set per prefix
+const (
+	PrefixProductImages   = "products"
+	PrefixInvoices        = "invoices"
+	PrefixExports         = "exports"
+	PrefixDigitalProducts = "digital"
+	PrefixShippingLabels  = "labels"
+	PrefixPackingSlips    = "packing-slips"
+	PrefixAvatars         = "avatars"
+	PrefixBI              = "bi"
+)
+
+// Object describes a stored file
+type Object struct {
+	Key         string    `json:"key"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"mod_time"`
+}
+
+// Storage stores files by key in a bucket-like namespace
+// Backends: local filesystem, S3, MinIO and GCS (S3-compatible API)
+type Storage interface {
+	// Name returns the backend name for logging, e.g. "s3"
+	Name() string
+
+	// Put stores r under key; size may be -1 when unknown
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Get opens the object under key, or returns ErrNotFound
+	// Callers must close the returned reader
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+
+	// Stat returns metadata of the object under key, or ErrNotFound
+	Stat(ctx context.Context, key string) (*Object, error)
+
+	// Delete removes the object under key; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+
+	// PresignGet returns a URL that downloads key without credentials until ttl elapses
+	PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error)
+
+	// PresignPut returns a URL that accepts an HTTP PUT of key until ttl elapses
+	PresignPut(ctx context.Context, key, contentType string, ttl time.Duration) (string, error)
+}
+
+// New returns the backend selected by STORAGE_BACKEND
+func New(cfg config.Storage) (Storage, error) {
+	switch cfg.Backend {
+	case "local":
+		return NewLocal(cfg)
+	case "s3", "minio", "gcs":
+		return NewS3(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported STORAGE_BACKEND: %s (must be local, s3, minio or gcs)", cfg.Backend)
+	}
+}
+
+// Key joins a prefix and name parts into an object key, e.g. Key(PrefixInvoices, orderID+".pdf")
+func Key(prefix string, parts ...string) string {
+	return path.Join(append([]string{prefix}, parts...)...)
+}
+
+// ValidateKey rejects keys that could escape the bucket/root when used as paths
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." || part == "." {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Package taxid parses and checks customers' VAT and tax identification numbers. Numbers are
+// normalized and format-checked locally; a Validator then looks them up in a tax registry
+// (EU VIES) where one is configured. Registry outages never block a customer: the number is
+// kept as unverified.
+package taxid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+)
+
+var (
+	// ErrInvalidFormat is returned when a number can't be a tax ID of its country
+	ErrInvalidFormat = errors.New("invalid tax ID format")
+	// ErrUnsupported is returned by validators without a registry for the number's country
+	ErrUnsupported = errors.New("no tax registry for this country")
+)
+
+// formats holds the shape of each country's number without its prefix
+// Countries not listed accept 4 to 20 letters and digits
+var formats = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^U\d{8}$`),
+	"BE": regexp.MustCompile(`^[01]\d{9}$`),
+	"DE": regexp.MustCompile(`^\d{9}$`),
+	"DK": regexp.MustCompile(`^\d{8}$`),
+	"ES": regexp.MustCompile(`^[A-Z0-9]\d{7}[A-Z0-9]$`),
+	"FI": regexp.MustCompile(`^\d{8}$`),
+	"FR": regexp.MustCompile(`^[A-Z0-9]{2}\d{9}$`),
+	"GR": regexp.MustCompile(`^\d{9}$`),
+	"IE": regexp.MustCompile(`^\d[A-Z0-9+*]\d{5}[A-Z]{1,2}$`),
+	"IT": regexp.MustCompile(`^\d{11}$`),
+	"NL": regexp.MustCompile(`^\d{9}B\d{2}$`),
+	"PL": regexp.MustCompile(`^\d{10}$`),
+	"PT": regexp.MustCompile(`^\d{9}$`),
+	"SE": regexp.MustCompile(`^\d{12}$`),
+	"GB": regexp.MustCompile(`^(\d{9}|\d{12}|GD\d{3}|HA\d{3})$`),
+	"KE": regexp.MustCompile(`^[AP]\d{9}[A-Z]$`), // KRA PIN
+	"UG": regexp.MustCompile(`^\d{10}$`),
+	"TZ": regexp.MustCompile(`^\d{9}$`),
+	"RW": regexp.MustCompile(`^\d{9}$`),
+}
+
+var otherFormat = regexp.MustCompile(`^[A-Z0-9]{4,20}$`)
+
+// prefixed are the countries whose VAT numbers are written with a country prefix (DE123456789)
+// Greece writes EL
+var prefixed = map[string]string{
+	"AT": "AT", "BE": "BE", "DE": "DE", "DK": "DK", "ES": "ES", "FI": "FI", "FR": "FR", "EL": "GR",
+	"IE": "IE", "IT": "IT", "NL": "NL", "PL": "PL", "PT": "PT", "SE": "SE", "GB": "GB",
+}
+
+// ID is a normalized tax number
+type ID struct {
+	Country string // ISO 3166-1 alpha-2
+	Number  string // without the country prefix
+}
+
+// String is the number as it is written on invoices: with its prefix where the country uses one
+func (id ID) String() string {
+	for prefix, country := range prefixed {
+		if country == id.Country {
+			return prefix + id.Number
+		}
+	}
+	return id.Number
+}
+
+// Parse normalizes a number and checks its format
+// A prefixed VAT number (DE123456789, EL123456789) gives its own country; other numbers
+// need country, e.g. a KRA PIN with KE
+// Returns: ErrInvalidFormat
+func Parse(number, country string) (ID, error) {
+	number = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-', '/':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(number)))
+	country = strings.ToUpper(strings.TrimSpace(country))
+
+	if len(number) > 2 {
+		if c, ok := prefixed[number[:2]]; ok {
+			if country != "" && country != c {
+				return ID{}, fmt.Errorf("%w: a %s number was given for country %s", ErrInvalidFormat, c, country)
+			}
+			country, number = c, number[2:]
+		}
+	}
+	if len(country) != 2 {
+		return ID{}, fmt.Errorf("%w: country is required for numbers without a country prefix", ErrInvalidFormat)
+	}
+
+	format, ok := formats[country]
+	if !ok {
+		format = otherFormat
+	}
+	if !format.MatchString(number) {
+		return ID{}, fmt.Errorf("%w: not a %s tax number", ErrInvalidFormat, country)
+	}
+	return ID{Country: country, Number: number}, nil
+}
+
+// Result is what a tax registry knows about a number
+type Result struct {
+	Valid   bool   // registered and active
+	Name    string // registered name, when the registry shares it
+	Address string
+}
+
+// Validator looks tax numbers up in a registry
+type Validator interface {
+	// Check returns the registry's answer for id
+	// Returns: ErrUnsupported or a provider error; callers should keep the number as unverified
+	// rather than block the customer
+	Check(ctx context.Context, id ID) (*Result, error)
+}
+
+// NewValidator creates the validator selected by TAX_ID_PROVIDER
+func NewValidator(cfg config.TaxIDs) (Validator, error) {
+	switch cfg.Provider {
+	case "vies":
+		if cfg.VIESURL == "" {
+			return nil, fmt.Errorf("TAX_ID_VIES_URL must be set for TAX_ID_PROVIDER=vies")
+		}
+		return NewVIESValidator(cfg.VIESURL), nil
+	case "none", "":
+		return NoopValidator{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported TAX_ID_PROVIDER: %s (must be 'none' or 'vies')", cfg.Provider)
+	}
+}
+
+// NoopValidator checks nothing beyond the format; every number stays unverified
+type NoopValidator struct{}
+
+func (NoopValidator) Check(ctx context.Context, id ID) (*Result, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,93 @@
+package taxid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/httpclient"
+)
+
+// viesCountries are the members of the EU VAT registry; VIES knows Greece as EL
+var viesCountries = map[string]string{
+	"AT": "AT", "BE": "BE", "DE": "DE", "DK": "DK", "ES": "ES", "FI": "FI", "FR": "FR", "GR": "EL",
+	"IE": "IE", "IT": "IT", "NL": "NL", "PL": "PL", "PT": "PT", "SE": "SE",
+}
+
+// VIESValidator checks EU VAT numbers with the European Commission's VIES REST API
+// Other countries are ErrUnsupported
+type VIESValidator struct {
+	url    string
+	client *http.Client
+}
+
+func NewVIESValidator(url string) *VIESValidator {
+	return &VIESValidator{url: url, client: httpclient.New(10 * time.Second)}
+}
+
+type viesRequest struct {
+	CountryCode string `json:"countryCode"`
+	VATNumber   string `json:"vatNumber"`
+}
+
+type viesResponse struct {
+	Valid     bool   `json:"valid"`
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	ErrorWrap *struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	} `json:"errorWrappers,omitempty"`
+}
+
+func (v *VIESValidator) Check(ctx context.Context, id ID) (*Result, error) {
+	code, ok := viesCountries[id.Country]
+	if !ok {
+		return nil, ErrUnsupported
+	}
+
+	body, err := json.Marshal(viesRequest{CountryCode: code, VATNumber: id.Number})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vies: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("vies: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var out viesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("vies: %w", err)
+	}
+	// Member state registries go offline regularly (MS_UNAVAILABLE); that isn't an invalid number
+	if out.ErrorWrap != nil {
+		return nil, fmt.Errorf("vies: %s", out.ErrorWrap.Error)
+	}
+	// VIES answers "---" when a member state doesn't share the trader's details
+	return &Result{Valid: out.Valid, Name: viesField(out.Name), Address: viesField(out.Address)}, nil
+}
+
+func viesField(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "---" {
+		return ""
+	}
+	return s
+}
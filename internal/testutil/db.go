@@ -0,0 +1,37 @@
+package testutil
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var databases atomic.Int64
+
+// NewDB returns a fresh in-memory SQLite database with tables for models, closed when t ends
+// Every call gets its own database, so tests and subtests never see each other's rows.
+// Repositories that rely on repository.RegisterErrorTranslation need it registered on the result.
+func NewDB(t testing.TB, models ...interface{}) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:testutil-%d?mode=memory&cache=shared&_pragma=busy_timeout(5000)", databases.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	// One connection: an in-memory database lives as long as a connection to it
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	return db
+}
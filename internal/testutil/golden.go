@@ -0,0 +1,85 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// update rewrites golden files with the actual output: go test ./... -update
+var update = flag.Bool("update", false, "update golden files")
+
+// maskedValue replaces the value of masked keys so generated IDs and timestamps don't break comparisons
+const maskedValue = "<masked>"
+
+// AssertGolden compares a JSON body with testdata/<name>.golden
+// Keys listed in mask (e.g. "id", "created_at") are replaced at any depth before comparing.
+// Both sides are re-indented, so golden files stay readable and key order doesn't matter.
+func AssertGolden(t testing.TB, name string, body []byte, mask ...string) {
+	t.Helper()
+
+	got := normalizeJSON(t, body, mask)
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create testdata dir: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	want := normalizeJSON(t, data, mask)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("response does not match %s\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+func normalizeJSON(t testing.TB, data []byte, mask []string) []byte {
+	t.Helper()
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatalf("invalid JSON: %v; body: %s", err, data)
+	}
+	if len(mask) > 0 {
+		keys := make(map[string]bool, len(mask))
+		for _, key := range mask {
+			keys[key] = true
+		}
+		value = maskKeys(value, keys)
+	}
+
+	out, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		t.Fatalf("encode JSON: %v", err)
+	}
+	return append(out, '\n')
+}
+
+func maskKeys(value interface{}, keys map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if keys[key] && child != nil {
+				v[key] = maskedValue
+				continue
+			}
+			v[key] = maskKeys(child, keys)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = maskKeys(child, keys)
+		}
+	}
+	return value
+}
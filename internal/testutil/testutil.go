@@ -0,0 +1,137 @@
+// Package testutil holds helpers for handler tests: building (authenticated) requests,
+// running them through a router, decoding error responses and comparing JSON bodies
+// against golden files in the test package's testdata directory. NewDB gives repository
+// and store contract tests a migrated in-memory database.
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jason-Omondi/ecomgo/internal/auth"
+	"github.com/Jason-Omondi/ecomgo/internal/cache"
+	"github.com/Jason-Omondi/ecomgo/internal/clock"
+	"github.com/Jason-Omondi/ecomgo/internal/config"
+	"github.com/Jason-Omondi/ecomgo/internal/models"
+)
+
+// NewTokenManager returns a token manager with a fixed test secret and a one hour TTL, keeping
+// revoked tokens in its own in-memory cache
+// Pass a clock.Fake to test expiry; advancing it past the TTL makes issued tokens invalid
+func NewTokenManager(clk clock.Clock) *auth.TokenManager {
+	tokens := auth.NewTokenManager(config.Auth{
+		JWTSecret: "testutil-secret",
+		Issuer:    "ecomgo-test",
+		TokenTTL:  time.Hour,
+	}, clk)
+	tokens.UseRevocations(cache.NewMemoryCache("testutil"))
+	return tokens
+}
+
+// NewRequest builds a request; body is JSON-encoded unless it is nil, a string or []byte
+func NewRequest(t testing.TB, method, target string, body interface{}) *http.Request {
+	t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = strings.NewReader(b)
+	case []byte:
+		reader = bytes.NewReader(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("encode request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req
+}
+
+// AuthRequest builds a request carrying a bearer token for a user with the given ID and role
+func AuthRequest(t testing.TB, tokens *auth.TokenManager, userID, role, method, target string, body interface{}) *http.Request {
+	t.Helper()
+
+	token, _, err := tokens.Issue(&models.User{ID: userID, Email: userID + "@example.com", Role: role})
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	req := NewRequest(t, method, target, body)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+// CustomerRequest is AuthRequest for a customer
+func CustomerRequest(t testing.TB, tokens *auth.TokenManager, userID, method, target string, body interface{}) *http.Request {
+	t.Helper()
+	return AuthRequest(t, tokens, userID, models.RoleCustomer, method, target, body)
+}
+
+// AdminRequest is AuthRequest for an admin
+func AdminRequest(t testing.TB, tokens *auth.TokenManager, userID, method, target string, body interface{}) *http.Request {
+	t.Helper()
+	return AuthRequest(t, tokens, userID, models.RoleAdmin, method, target, body)
+}
+
+// Serve runs req through handler (usually a mux.Router with routes registered) and returns the recorded response
+func Serve(handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// DecodeJSON decodes the response body into v, failing the test if the status differs from want
+func DecodeJSON(t testing.TB, rec *httptest.ResponseRecorder, want int, v interface{}) {
+	t.Helper()
+
+	if rec.Code != want {
+		t.Fatalf("status = %d, want %d; body: %s", rec.Code, want, rec.Body.String())
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode response body: %v; body: %s", err, rec.Body.String())
+	}
+}
+
+// ErrorResponse is an error written by http.Error: a status and a plain-text message
+type ErrorResponse struct {
+	Status  int
+	Message string
+}
+
+// DecodeError reads an error response, failing the test if it isn't one
+func DecodeError(t testing.TB, rec *httptest.ResponseRecorder) ErrorResponse {
+	t.Helper()
+
+	if rec.Code < http.StatusBadRequest {
+		t.Fatalf("status = %d, want an error status; body: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatalf("error Content-Type = %q, want text/plain", ct)
+	}
+	return ErrorResponse{
+		Status:  rec.Code,
+		Message: strings.TrimSuffix(rec.Body.String(), "\n"),
+	}
+}
+
+// AssertError fails the test unless the response is an error with the given status and message
+func AssertError(t testing.TB, rec *httptest.ResponseRecorder, status int, message string) {
+	t.Helper()
+
+	got := DecodeError(t, rec)
+	if got.Status != status || got.Message != message {
+		t.Fatalf("error = %d %q, want %d %q", got.Status, got.Message, status, message)
+	}
+}
@@ -0,0 +1,47 @@
+// Package timezone resolves the client timezone hints accepted by reporting endpoints
+// Responses always carry RFC 3339 UTC timestamps; a hint only changes how reports
+// (CSV exports) render times, never what is stored or returned as JSON.
+package timezone
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	// Embedded zone database, so IANA names resolve on minimal images without /usr/share/zoneinfo
+	_ "time/tzdata"
+)
+
+// Header is the request header carrying an IANA timezone name, e.g. Time-Zone: Africa/Nairobi
+const Header = "Time-Zone"
+
+// ErrInvalid is returned for a name that is not an IANA timezone
+var ErrInvalid = errors.New("invalid timezone")
+
+// FromRequest returns the timezone from the tz query parameter or the Time-Zone header
+// Returns: UTC when neither is set
+func FromRequest(r *http.Request) (*time.Location, error) {
+	name := strings.TrimSpace(r.URL.Query().Get("tz"))
+	if name == "" {
+		name = strings.TrimSpace(r.Header.Get(Header))
+	}
+	return Load(name)
+}
+
+// Load resolves an IANA timezone name; empty and "UTC" mean UTC
+// "Local" is rejected: the server's zone is never a meaningful answer for a client
+func Load(name string) (*time.Location, error) {
+	switch name {
+	case "", "UTC", "Etc/UTC", "Z":
+		return time.UTC, nil
+	case "Local":
+		return nil, fmt.Errorf("%w: %s", ErrInvalid, name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalid, name)
+	}
+	return loc, nil
+}